@@ -25,6 +25,7 @@ const (
 	AuthService_RefreshToken_FullMethodName     = "/auth.v1.AuthService/RefreshToken"
 	AuthService_Logout_FullMethodName           = "/auth.v1.AuthService/Logout"
 	AuthService_ValidateToken_FullMethodName    = "/auth.v1.AuthService/ValidateToken"
+	AuthService_ValidateTokens_FullMethodName   = "/auth.v1.AuthService/ValidateTokens"
 	AuthService_GetUserFromToken_FullMethodName = "/auth.v1.AuthService/GetUserFromToken"
 )
 
@@ -42,6 +43,11 @@ type AuthServiceClient interface {
 	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// ValidateToken validates an access token
 	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
+	// ValidateTokens validates a stream of access tokens over one call, so
+	// gateways and sidecars can pipeline validations instead of paying for a
+	// unary call per token. Responses come back in request order; a rejected
+	// token is reported in its response rather than ending the stream.
+	ValidateTokens(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateTokensRequest, ValidateTokensResponse], error)
 	// GetUserFromToken retrieves a user from an access token
 	GetUserFromToken(ctx context.Context, in *GetUserFromTokenRequest, opts ...grpc.CallOption) (*v1.User, error)
 }
@@ -94,6 +100,19 @@ func (c *authServiceClient) ValidateToken(ctx context.Context, in *ValidateToken
 	return out, nil
 }
 
+func (c *authServiceClient) ValidateTokens(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ValidateTokensRequest, ValidateTokensResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuthService_ServiceDesc.Streams[0], AuthService_ValidateTokens_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ValidateTokensRequest, ValidateTokensResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_ValidateTokensClient = grpc.BidiStreamingClient[ValidateTokensRequest, ValidateTokensResponse]
+
 func (c *authServiceClient) GetUserFromToken(ctx context.Context, in *GetUserFromTokenRequest, opts ...grpc.CallOption) (*v1.User, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(v1.User)
@@ -118,6 +137,11 @@ type AuthServiceServer interface {
 	Logout(context.Context, *LogoutRequest) (*emptypb.Empty, error)
 	// ValidateToken validates an access token
 	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
+	// ValidateTokens validates a stream of access tokens over one call, so
+	// gateways and sidecars can pipeline validations instead of paying for a
+	// unary call per token. Responses come back in request order; a rejected
+	// token is reported in its response rather than ending the stream.
+	ValidateTokens(grpc.BidiStreamingServer[ValidateTokensRequest, ValidateTokensResponse]) error
 	// GetUserFromToken retrieves a user from an access token
 	GetUserFromToken(context.Context, *GetUserFromTokenRequest) (*v1.User, error)
 	mustEmbedUnimplementedAuthServiceServer()
@@ -142,6 +166,9 @@ func (UnimplementedAuthServiceServer) Logout(context.Context, *LogoutRequest) (*
 func (UnimplementedAuthServiceServer) ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ValidateToken not implemented")
 }
+func (UnimplementedAuthServiceServer) ValidateTokens(grpc.BidiStreamingServer[ValidateTokensRequest, ValidateTokensResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ValidateTokens not implemented")
+}
 func (UnimplementedAuthServiceServer) GetUserFromToken(context.Context, *GetUserFromTokenRequest) (*v1.User, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserFromToken not implemented")
 }
@@ -238,6 +265,13 @@ func _AuthService_ValidateToken_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_ValidateTokens_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AuthServiceServer).ValidateTokens(&grpc.GenericServerStream[ValidateTokensRequest, ValidateTokensResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_ValidateTokensServer = grpc.BidiStreamingServer[ValidateTokensRequest, ValidateTokensResponse]
+
 func _AuthService_GetUserFromToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetUserFromTokenRequest)
 	if err := dec(in); err != nil {
@@ -284,6 +318,13 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _AuthService_GetUserFromToken_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ValidateTokens",
+			Handler:       _AuthService_ValidateTokens_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "auth/v1/auth.proto",
 }
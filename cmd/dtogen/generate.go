@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	modulePath      = "github.com/yi-tech/go-user-service"
+	idgenPath       = modulePath + "/internal/idgen"
+	timestamppbPath = "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"lowerFirst": lowerFirst,
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// templateData is passed to every template
+type templateData struct {
+	Source  string // Schema path as recorded in the generated header
+	Schema  *Schema
+	Target  Target
+	Std     []Import // Standard library imports
+	Imports []Import // Third-party imports
+	Local   []Import // Imports from this module
+}
+
+// Generate renders every target of the schema, returning the formatted
+// source keyed by its path relative to the repository root
+func Generate(schema *Schema, source string) (map[string][]byte, error) {
+	files := make(map[string][]byte, 2)
+
+	httpImports := []Import{schema.Domain}
+	if uses(schema.Outputs, typeID, Message.HTTPFields) {
+		httpImports = append(httpImports, Import{Path: idgenPath})
+	}
+	if uses(schema.Outputs, typeTime, Message.HTTPFields) {
+		httpImports = append(httpImports, Import{Path: "time"})
+	}
+	if err := render(files, "http.go.tmpl", newTemplateData(source, schema, schema.HTTP, httpImports)); err != nil {
+		return nil, err
+	}
+
+	grpcImports := []Import{schema.GRPC.PB, schema.Domain}
+	if uses(schema.Outputs, typeID, Message.ProtoFields) {
+		grpcImports = append(grpcImports, Import{Path: idgenPath})
+	}
+	if uses(schema.Outputs, typeTime, Message.ProtoFields) {
+		grpcImports = append(grpcImports, Import{Path: timestamppbPath})
+	}
+	if err := render(files, "grpc.go.tmpl", newTemplateData(source, schema, schema.GRPC, grpcImports)); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// newTemplateData groups imports the way the rest of the repository does:
+// standard library, third-party, then this module. format.Source sorts each group.
+func newTemplateData(source string, schema *Schema, target Target, imports []Import) templateData {
+	data := templateData{Source: source, Schema: schema, Target: target}
+	for _, imp := range imports {
+		switch {
+		case strings.HasPrefix(imp.Path, modulePath+"/"):
+			data.Local = append(data.Local, imp)
+		case strings.Contains(strings.SplitN(imp.Path, "/", 2)[0], "."):
+			data.Imports = append(data.Imports, imp)
+		default:
+			data.Std = append(data.Std, imp)
+		}
+	}
+	return data
+}
+
+func render(files map[string][]byte, name string, data templateData) error {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s output: %w\n%s", name, err, buf.Bytes())
+	}
+	files[filepath.Join(data.Target.Dir, data.Target.File)] = src
+	return nil
+}
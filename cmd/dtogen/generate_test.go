@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+)
+
+// repoRoot is the repository root relative to this package
+const repoRoot = "../.."
+
+func loadUserSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := LoadSchema(filepath.Join(repoRoot, "api/schema/user.yaml"))
+	require.NoError(t, err)
+	return schema
+}
+
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	files, err := Generate(loadUserSchema(t), "api/schema/user.yaml")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	for path, want := range files {
+		got, err := os.ReadFile(filepath.Join(repoRoot, path))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is stale, run `make dto-gen`", path)
+	}
+}
+
+// The protobuf messages are generated from api/proto, so the schema has to
+// agree with them field for field
+func TestSchemaMatchesProto(t *testing.T) {
+	schema := loadUserSchema(t)
+	messages := append(append([]Message{}, schema.Inputs...), schema.Outputs...)
+
+	for _, m := range messages {
+		t.Run(m.Proto, func(t *testing.T) {
+			desc := userpb.File_user_v1_user_proto.Messages().ByName(protoreflect.Name(m.Proto))
+			require.NotNil(t, desc, "message %s not found in user/v1/user.proto", m.Proto)
+
+			mapped := make(map[string]bool)
+			for _, f := range m.ProtoFields() {
+				mapped[f.Proto] = true
+
+				field := desc.Fields().ByName(protoreflect.Name(f.Proto))
+				require.NotNil(t, field, "%s has no field %s", m.Proto, f.Proto)
+				assert.Equal(t, expectedKind(f), field.Kind(), "%s.%s", m.Proto, f.Proto)
+				assert.Equal(t, f.Type == typeMap, field.IsMap(), "%s.%s", m.Proto, f.Proto)
+			}
+
+			fields := desc.Fields()
+			for i := 0; i < fields.Len(); i++ {
+				name := string(fields.Get(i).Name())
+				assert.True(t, mapped[name], "%s.%s is not in the schema", m.Proto, name)
+			}
+		})
+	}
+}
+
+func expectedKind(f Field) protoreflect.Kind {
+	switch f.Type {
+	case typeBool:
+		return protoreflect.BoolKind
+	case typeTime, typeMap:
+		return protoreflect.MessageKind
+	default:
+		return protoreflect.StringKind
+	}
+}
+
+func TestLoadSchema_UnknownType(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+outputs:
+  - domain: User
+    http: UserResponse
+    proto: User
+    fields:
+      - name: Age
+        type: int
+`), 0o600))
+
+	_, err := LoadSchema(path)
+	assert.ErrorContains(t, err, `User.Age: unknown type "int"`)
+}
+
+func TestProtoGoName(t *testing.T) {
+	assert.Equal(t, "Id", Field{Proto: "id"}.ProtoGoName())
+	assert.Equal(t, "FirstName", Field{Proto: "first_name"}.ProtoGoName())
+	assert.Equal(t, "CreatedAt", Field{Proto: "created_at"}.ProtoGoName())
+}
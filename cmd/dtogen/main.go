@@ -0,0 +1,63 @@
+// Command dtogen generates the HTTP DTOs and the domain, DTO and protobuf
+// converters from api/schema/user.yaml, so the transports cannot drift apart.
+// Run it from the repository root, usually through `make dto-gen`.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "api/schema/user.yaml", "schema file, relative to the repository root")
+	check := flag.Bool("check", false, "report stale generated files instead of writing them")
+	flag.Parse()
+
+	if err := run(*schemaPath, *check); err != nil {
+		fmt.Fprintf(os.Stderr, "dtogen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath string, check bool) error {
+	schema, err := LoadSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	files, err := Generate(schema, filepath.ToSlash(schemaPath))
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var stale []string
+	for _, path := range paths {
+		current, err := os.ReadFile(path)
+		if err == nil && bytes.Equal(current, files[path]) {
+			continue
+		}
+		if check {
+			stale = append(stale, path)
+			continue
+		}
+		if err := os.WriteFile(path, files[path], 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Println("wrote", path)
+	}
+
+	if len(stale) > 0 {
+		return fmt.Errorf("generated files are out of date, run `make dto-gen`: %v", stale)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field types understood by the templates
+const (
+	typeString = "string"
+	typeBool   = "bool"
+	typeTime   = "time"
+	typeID     = "id"
+	typeMap    = "map" // Of strings to strings
+)
+
+// Schema describes the messages shared by the HTTP and gRPC transports
+type Schema struct {
+	Domain  Import    `yaml:"domain"`
+	HTTP    Target    `yaml:"http"`
+	GRPC    Target    `yaml:"grpc"`
+	Inputs  []Message `yaml:"inputs"`  // Requests converted into domain types
+	Outputs []Message `yaml:"outputs"` // Domain types rendered as responses
+}
+
+// Import is a Go package imported by generated code
+type Import struct {
+	Path  string `yaml:"path"`
+	Alias string `yaml:"alias"`
+}
+
+// Target is a generated file
+type Target struct {
+	Dir     string `yaml:"dir"`
+	File    string `yaml:"file"`
+	Package string `yaml:"package"`
+	PB      Import `yaml:"pb"` // Protobuf package, gRPC target only
+}
+
+// Message maps a domain type to its HTTP DTO and protobuf message
+type Message struct {
+	Domain string  `yaml:"domain"`
+	HTTP   string  `yaml:"http"`
+	Proto  string  `yaml:"proto"`
+	Doc    string  `yaml:"doc"`
+	Fields []Field `yaml:"fields"`
+}
+
+// Field is a single field of a Message
+type Field struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`
+	JSON    string `yaml:"json"`
+	Binding string `yaml:"binding"`
+	Proto   string `yaml:"proto"`
+	Comment string `yaml:"comment"`
+}
+
+// LoadSchema reads and validates a schema file
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := schema.validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+func (s *Schema) validate() error {
+	messages := append(append([]Message{}, s.Inputs...), s.Outputs...)
+	for i := range messages {
+		m := &messages[i]
+		if m.Domain == "" || m.HTTP == "" || m.Proto == "" {
+			return fmt.Errorf("message %q needs domain, http and proto names", m.Domain)
+		}
+		for j := range m.Fields {
+			f := &m.Fields[j]
+			if f.Name == "" {
+				return fmt.Errorf("%s: field %d has no name", m.Domain, j)
+			}
+			if f.Type == "" {
+				f.Type = typeString
+			}
+			switch f.Type {
+			case typeString, typeBool, typeTime, typeID, typeMap:
+			default:
+				return fmt.Errorf("%s.%s: unknown type %q", m.Domain, f.Name, f.Type)
+			}
+		}
+	}
+
+	// Inputs are bound from requests, so IDs and timestamps never appear there
+	for _, m := range s.Inputs {
+		for _, f := range m.Fields {
+			if f.Type != typeString && f.Type != typeBool {
+				return fmt.Errorf("%s.%s: input fields must be string or bool", m.Domain, f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// HTTPFields returns the fields present in the HTTP DTO
+func (m Message) HTTPFields() []Field {
+	var fields []Field
+	for _, f := range m.Fields {
+		if f.JSON != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// ProtoFields returns the fields present in the protobuf message
+func (m Message) ProtoFields() []Field {
+	var fields []Field
+	for _, f := range m.Fields {
+		if f.Proto != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// GoType is the type of the field in the HTTP DTO
+func (f Field) GoType() string {
+	switch f.Type {
+	case typeBool:
+		return "bool"
+	case typeTime:
+		return "time.Time"
+	case typeMap:
+		return "map[string]string"
+	default:
+		return "string"
+	}
+}
+
+// Tag is the struct tag of the field in the HTTP DTO
+func (f Field) Tag() string {
+	tag := fmt.Sprintf(`json:"%s"`, f.JSON)
+	if f.Binding != "" {
+		tag += fmt.Sprintf(` binding:"%s"`, f.Binding)
+	}
+	return "`" + tag + "`"
+}
+
+// ProtoGoName is the Go name protoc-gen-go gives the protobuf field
+func (f Field) ProtoGoName() string {
+	var b strings.Builder
+	for _, part := range strings.Split(f.Proto, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// uses reports whether any field of messages has the given type
+func uses(messages []Message, fieldType string, fields func(Message) []Field) bool {
+	for _, m := range messages {
+		for _, f := range fields(m) {
+			if f.Type == fieldType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lowerFirst turns an exported name into an unexported one
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
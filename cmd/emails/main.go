@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/provider"
+	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+const usage = `Usage: emails <command> [flags]
+
+Commands:
+  normalize   Rewrite the normalized emails of users with the configured normalization
+
+Run 'emails normalize -h' for normalize flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "normalize":
+		if err := normalize(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "normalize: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// normalize runs the normalize command against the database and email
+// settings of the configuration selected by APP_ENV. Run it after migrating
+// to users.normalized_email and whenever email.collapse_gmail_aliases is
+// turned on; until then accounts are only found by the spelling they were
+// normalized with, and the server refuses to start with Gmail aliases
+// collapsed. A run without conflicts records the normalization as completed.
+func normalize(args []string) error {
+	fs := flag.NewFlagSet("normalize", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report how many users would change without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	logger, err := provider.ProvideLogger(cfg, provider.ProvideLogLevels(cfg))
+	if err != nil {
+		return err
+	}
+	db, err := provider.NewDatabaseProvider(cfg, logger).GetDB()
+	if err != nil {
+		return err
+	}
+
+	addresses := emailaddr.Normalizer{CollapseGmailAliases: cfg.Email.CollapseGmailAliases}
+	result, err := serviceUser.NormalizeEmails(context.Background(), repoUser.NewUserRepository(db, nil), repoUser.NewNormalizationRepository(db), addresses, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Rewrote"
+	if *dryRun {
+		verb = "Would rewrite"
+	}
+	fmt.Printf("Scanned %d users\n", result.Scanned)
+	fmt.Printf("%s the normalized email of %d users\n", verb, result.Updated)
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("Skipped %d users whose normalized email another account already has; merge them and run again:\n", len(result.Conflicts))
+		for _, email := range result.Conflicts {
+			fmt.Printf("  %s\n", email)
+		}
+	}
+	if result.Completed {
+		fmt.Printf("Recorded the %s normalization as completed\n", addresses.Name())
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/password"
+)
+
+const usage = `Usage: hash <command> [flags]
+
+Commands:
+  calibrate   Measure bcrypt hashing time on this host and suggest a cost
+
+Run 'hash calibrate -h' for calibrate flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "calibrate":
+		if err := calibrate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "calibrate: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// calibrate runs the calibrate command
+func calibrate(args []string) error {
+	// Same default as config.LoadConfig so -write edits the file the server reads
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	target := fs.Duration("target", 250*time.Millisecond, "latency budget for hashing one password")
+	samples := fs.Int("samples", 3, "hashes timed per parameter set (median is used)")
+	write := fs.Bool("write", false, "write the suggested bcrypt cost to the config file")
+	configPath := fs.String("config", fmt.Sprintf("configs/config.%s.yaml", env), "config file updated by -write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	calibrator := password.NewCalibrator(*samples)
+
+	result, err := calibrator.Bcrypt(*target)
+	if err != nil {
+		return err
+	}
+	printMeasurements(result.Measurements)
+	fmt.Printf("\nSuggested bcrypt cost: %d (%s per hash, target %s)\n", result.Cost, result.Duration.Round(time.Millisecond), *target)
+	if result.Duration > *target {
+		fmt.Printf("Warning: this host cannot meet the target at the minimum recommended cost %d\n", password.MinRecommendedBcryptCost)
+	}
+
+	if *write {
+		if err := config.SetFileValue(*configPath, "password", "bcrypt_cost", strconv.Itoa(result.Cost)); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote password.bcrypt_cost=%d to %s\n", result.Cost, *configPath)
+	}
+
+	return nil
+}
+
+// printMeasurements prints one line per measured parameter set
+func printMeasurements(measurements []password.Measurement) {
+	for _, m := range measurements {
+		fmt.Printf("  %-24s %s\n", m.Params, m.Duration.Round(time.Millisecond))
+	}
+}
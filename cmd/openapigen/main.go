@@ -0,0 +1,44 @@
+// Command openapigen writes the OpenAPI 3 spec of the HTTP gateway, generated
+// from the google.api.http annotations of the gRPC services, to
+// internal/openapi/openapi.json. Run it from the repository root, usually
+// through `make openapi-gen`.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yi-tech/go-user-service/internal/openapi"
+)
+
+func main() {
+	check := flag.Bool("check", false, "report a stale spec instead of writing it")
+	flag.Parse()
+
+	if err := run(*check); err != nil {
+		fmt.Fprintf(os.Stderr, "openapigen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(check bool) error {
+	spec, err := openapi.Build()
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(openapi.SpecPath)
+	if err == nil && bytes.Equal(current, spec) {
+		return nil
+	}
+	if check {
+		return fmt.Errorf("%s is out of date, run `make openapi-gen`", openapi.SpecPath)
+	}
+	if err := os.WriteFile(openapi.SpecPath, spec, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", openapi.SpecPath, err)
+	}
+	fmt.Println("wrote", openapi.SpecPath)
+	return nil
+}
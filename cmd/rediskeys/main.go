@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/provider"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+const usage = `Usage: rediskeys <command> [flags]
+
+Commands:
+  migrate   Rename unversioned auth keys to the versioned key schema
+
+Run 'rediskeys migrate -h' for migrate flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		if err := migrate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// migrate runs the migrate command against the Redis server and key prefix
+// of the configuration selected by APP_ENV
+func migrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be migrated without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	schema, err := rediskey.New(cfg.Redis.KeyPrefix)
+	if err != nil {
+		return err
+	}
+	client, err := provider.NewRedisProvider(cfg).GetRedisClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	result, err := rediskey.Migrate(context.Background(), client, schema, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Moved"
+	if *dryRun {
+		verb = "Would move"
+	}
+	fmt.Printf("Scanned %d legacy keys under %s*\n", result.Scanned, config.RedisKeyPrefix)
+	fmt.Printf("%s %d keys under %s*\n", verb, result.Moved, schema.Prefix())
+	fmt.Printf("Discarded %d keys superseded by newer ones\n", result.Discarded)
+	fmt.Printf("Skipped %d keys with unrecognised names\n", result.Skipped)
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	appwire "github.com/yi-tech/go-user-service/cmd/server/wire"
+	"github.com/yi-tech/go-user-service/internal/config"
+	serviceSeed "github.com/yi-tech/go-user-service/internal/service/seed"
+)
+
+const usage = `Usage: seed <command> [flags]
+
+Commands:
+  load       Create the users and organizations of a fixtures file
+
+Run 'seed load -h' for load flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "load":
+		if err := load(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "load: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// load creates the fixtures of a file through the services against the
+// database of the configuration selected by APP_ENV. Fixtures that already
+// exist are skipped, so it is safe to run again.
+func load(args []string) error {
+	fs := flag.NewFlagSet("load", flag.ExitOnError)
+	file := fs.String("file", "configs/fixtures.dev.yaml", "YAML or JSON fixtures file")
+	dryRun := fs.Bool("dry-run", false, "validate the fixtures file without loading it")
+	var opts config.Options
+	opts.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fixtures, err := serviceSeed.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Printf("%s is valid: %d users, %d organizations\n", *file, len(fixtures.Users), len(fixtures.Organizations))
+		return nil
+	}
+
+	app, err := appwire.InitializeSeeder(opts)
+	if err != nil {
+		return err
+	}
+
+	result, err := app.Loader.Load(context.Background(), fixtures)
+	if result != nil {
+		fmt.Printf("Users: %d created, %d existing\n", result.UsersCreated, result.UsersExisting)
+		fmt.Printf("Organizations: %d created, %d existing\n", result.OrganizationsCreated, result.OrganizationsExisting)
+		fmt.Printf("Members: %d added, %d existing\n", result.MembersAdded, result.MembersExisting)
+	}
+	return err
+}
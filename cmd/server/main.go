@@ -2,24 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"go.uber.org/zap"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	appwire "github.com/yi-tech/go-user-service/cmd/server/wire"
+	"github.com/yi-tech/go-user-service/internal/config"
 
 	// Import for swagger docs
 	_ "github.com/yi-tech/go-user-service/docs"
 )
 
+// shutdownTimeout bounds how long in-flight requests may drain on shutdown
+const shutdownTimeout = 10 * time.Second
+
 // @title User Service API
 // @version 1.0
 // @description This is a sample user service server.
@@ -40,71 +47,104 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
+	// Flags override the config file and environment variables
+	var opts config.Options
+	opts.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
 	// Initialize the application
-	app, err := appwire.InitializeApp()
+	app, err := appwire.InitializeApp(opts)
 	if err != nil {
 		log.Fatalf("Failed to initialize app: %v", err)
 	}
+	appwire.NewStartupReport(app.Config).Log(app.Logger)
 
 	// Set up Swagger UI
 	app.HTTPServer.Router().GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Create error channel to capture server errors
-	errChan := make(chan error, 2)
-
-	// Start gRPC server in a goroutine
-	go func() {
-		app.Logger.Info("Starting gRPC server", 
-			zap.Int("grpcPort", app.Config.GRPC.Port),
-			zap.Int("grpcGatewayPort", app.Config.GRPC.Port+1))
-			
-		if err := app.GRPCServer.Start(); err != nil {
-			app.Logger.Error("Failed to start gRPC server", zap.Error(err))
-			errChan <- fmt.Errorf("gRPC server error: %w", err)
-		}
-	}()
-
-	// Start HTTP server in a goroutine
-	go func() {
-		httpPort := app.Config.App.Port
-		app.Logger.Info("Starting HTTP server", zap.Int("port", httpPort))
-		
-		if err := app.HTTPServer.Start(); err != nil && err != http.ErrServerClosed {
-			app.Logger.Error("Failed to start HTTP server", zap.Error(err))
-			errChan <- fmt.Errorf("HTTP server error: %w", err)
-		}
-	}()
+	// Cancelled on SIGINT/SIGTERM or when either server fails
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	g, gctx := errgroup.WithContext(ctx)
 
-	app.Logger.Info("Application started successfully", 
+	app.Logger.Info("Starting servers",
+		zap.Int("httpPort", app.Config.App.Port),
+		zap.Int("grpcPort", app.Config.GRPC.Port),
+		zap.Int("grpcGatewayPort", app.Config.GRPC.Port+1),
 		zap.String("swagger", fmt.Sprintf("http://localhost:%d/swagger/index.html", app.Config.App.Port)))
 
-	// Wait for interrupt signal or error
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case err := <-errChan:
-		app.Logger.Error("Server error", zap.Error(err))
-	case sig := <-quit:
-		app.Logger.Info("Received signal", zap.String("signal", sig.String()))
+	g.Go(app.GRPCServer.Serve)
+	g.Go(app.HTTPServer.Serve)
+	if app.Config.App.AdminPort != 0 {
+		app.Logger.Info("Serving ops endpoints on admin port", zap.Int("adminPort", app.Config.App.AdminPort))
 	}
-
-	app.Logger.Info("Shutting down servers...")
-
-	// Create a context with a timeout for graceful shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-
-	// Shutdown the HTTP server
-	app.Logger.Info("Shutting down HTTP server...")
-	if err := app.HTTPServer.Shutdown(shutdownCtx); err != nil {
-		app.Logger.Error("HTTP server shutdown error", zap.Error(err))
+	if app.MetricsServer != nil {
+		app.Logger.Info("Serving metrics on internal port", zap.Int("metricsPort", app.Config.Metrics.Port))
+		g.Go(app.MetricsServer.Serve)
 	}
-
-	// Shutdown the gRPC server
-	app.Logger.Info("Shutting down gRPC server...")
-	if err := app.GRPCServer.Stop(); err != nil {
-		app.Logger.Error("gRPC server shutdown error", zap.Error(err))
+	g.Go(func() error { return app.HealthMonitor.Run(gctx) })
+	g.Go(func() error { return app.LoginHistoryPruner.Run(gctx) })
+	g.Go(func() error { return app.Notifications.Run(gctx) })
+	g.Go(func() error { return app.Events.Run(gctx) })
+	g.Go(func() error { return app.ConfigWatcher.Run(gctx) })
+
+	// SIGHUP reloads the config file, resetting log levels changed through
+	// PUT /admin/v1/logging/level to the configured ones
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	g.Go(func() error {
+		for {
+			select {
+			case <-gctx.Done():
+				return nil
+			case <-hangup:
+				app.Logger.Info("Received SIGHUP, reloading config")
+				app.ConfigWatcher.Reload()
+			}
+		}
+	})
+
+	// Drain the servers once a signal arrives or a server fails to start
+	g.Go(func() error {
+		<-gctx.Done()
+		app.Logger.Info("Shutting down servers...", zap.Duration("timeout", shutdownTimeout))
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		var httpErr, grpcErr, metricsErr error
+		var wg sync.WaitGroup
+		if app.MetricsServer != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if metricsErr = app.MetricsServer.Shutdown(shutdownCtx); metricsErr != nil {
+					metricsErr = fmt.Errorf("metrics server shutdown: %w", metricsErr)
+				}
+			}()
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if httpErr = app.HTTPServer.Shutdown(shutdownCtx); httpErr != nil {
+				httpErr = fmt.Errorf("HTTP server shutdown: %w", httpErr)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if grpcErr = app.GRPCServer.Shutdown(shutdownCtx); grpcErr != nil {
+				grpcErr = fmt.Errorf("gRPC server shutdown: %w", grpcErr)
+			}
+		}()
+		wg.Wait()
+
+		return errors.Join(httpErr, grpcErr, metricsErr)
+	})
+
+	if err := g.Wait(); err != nil {
+		app.Logger.Error("Server exited with error", zap.Error(err))
+		os.Exit(1)
 	}
 
 	app.Logger.Info("Server exiting")
@@ -0,0 +1,274 @@
+package wire
+
+import (
+	"fmt"
+	"net/url"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// providers lists the providers InitializeApp passes to wire.Build, in order.
+// TestProvidersMatchWireBuild keeps it and workerProviders in sync with wire.go.
+var providers = []string{
+	"provider.ProvideConfig",
+	"provider.ProvideLogLevels",
+	"provider.ProvideLogger",
+	"provider.ProvideDatabase",
+	"provider.ProvideReadReplicas",
+	"provider.ProvideRedisClient",
+	"ProvideRedisKeys",
+	"ProvideUserRepository",
+	"ProvideNormalizationRepository",
+	"ProvideAuthStore",
+	"ProvideAuthRepository",
+	"ProvideSessionRepository",
+	"ProvideSigningKeyRepository",
+	"ProvideLoginAttemptRepository",
+	"ProvideKeyInspector",
+	"ProvideAuditRepository",
+	"ProvideLoginHistoryRepository",
+	"ProvideImpersonationRepository",
+	"ProvideDeadLetterRepository",
+	"ProvideMessageRepository",
+	"ProvideAPIKeyRepository",
+	"ProvideOrganizationRepository",
+	"ProvideFeatureFlagStore",
+	"ProvideWebhookRepository",
+	"ProvideWebhookDeliveryRepository",
+	"ProvideSAMLRepository",
+	"ProvideSAMLAssertionCache",
+	"ProvideTxManager",
+	"ProvideKeyRing",
+	"ProvideKeyManager",
+	"ProvideIDStrategy",
+	"ProvideIDGenerator",
+	"ProvideResidencyPolicy",
+	"ProvideEmailPolicy",
+	"ProvideReadOnlySwitch",
+	"ProvideFeatureFlags",
+	"ProvideNotificationService",
+	"ProvideJobBroker",
+	"ProvideJobQueue",
+	"ProvideNotifier",
+	"ProvideEventBus",
+	"ProvideEventPublisher",
+	"ProvideSecurityLog",
+	"ProvideUserService",
+	"ProvideAvailabilityChecker",
+	"ProvideCaptchaVerifier",
+	"ProvideAuthService",
+	"ProvideRoleService",
+	"ProvideAdminService",
+	"ProvideMessageService",
+	"ProvideWebhookService",
+	"ProvideSAMLService",
+	"ProvideAPIKeyService",
+	"ProvideOrganizationService",
+	"ProvideImportService",
+	"ProvideExportService",
+	"ProvideExportGenerator",
+	"ProvideUserHttpHandler",
+	"ProvideAvailabilityHttpHandler",
+	"ProvideAvailabilityLimiter",
+	"ProvideAuthHttpHandler",
+	"ProvideAdminHttpHandler",
+	"ProvideAccountHttpHandler",
+	"ProvideReadOnlyHttpHandler",
+	"ProvideFeatureFlagHttpHandler",
+	"ProvideLoggingHttpHandler",
+	"ProvideWebhookHttpHandler",
+	"ProvideSAMLHttpHandler",
+	"ProvideSAMLProviderHttpHandler",
+	"ProvideImportHttpHandler",
+	"ProvideExportHttpHandler",
+	"ProvideMessageHttpHandler",
+	"ProvideOrgHttpHandler",
+	"ProvideOrganizationHttpHandler",
+	"ProvideAccountCenterHttpHandler",
+	"ProvideJWKSHttpHandler",
+	"ProvideMetricsRegistry",
+	"ProvideErrorReporter",
+	"ProvidePanicRecorder",
+	"ProvideCacheMetrics",
+	"ProvideAuthMetrics",
+	"ProvideCircuitBreakers",
+	"ProvideMetricsServer",
+	"ProvideHealthMonitor",
+	"ProvideConfigWatcher",
+	"ProvideLoginHistoryPruner",
+	"ProvideHealthHttpHandler",
+	"ProvideRealtimeHub",
+	"ProvideAdminEventFeed",
+	"ProvideRealtimeHttpHandler",
+	"ProvideRouter",
+	"ProvideGRPCConfig",
+	"ProvideCompressor",
+	"ProvideGRPCServer",
+	"ProvideHTTPServer",
+}
+
+// workerProviders lists the providers InitializeWorker passes to wire.Build, in order
+var workerProviders = []string{
+	"provider.ProvideConfig",
+	"provider.ProvideLogLevels",
+	"provider.ProvideLogger",
+	"provider.ProvideDatabase",
+	"provider.ProvideReadReplicas",
+	"provider.ProvideRedisClient",
+	"ProvideRedisKeys",
+	"ProvideUserRepository",
+	"ProvideAuthStore",
+	"ProvideSessionRepository",
+	"ProvideAuthRepository",
+	"ProvideAuditRepository",
+	"ProvideDeadLetterRepository",
+	"ProvideIDStrategy",
+	"ProvideIDGenerator",
+	"ProvideResidencyPolicy",
+	"ProvideMetricsRegistry",
+	"ProvideCacheMetrics",
+	"ProvideCircuitBreakers",
+	"ProvideNotificationService",
+	"ProvideExportService",
+	"ProvideJobBroker",
+	"ProvideJobQueue",
+	"ProvideExportGenerator",
+	"ProvideMaintenanceMetrics",
+	"ProvideMaintenanceTasks",
+	"ProvideWebhookRepository",
+	"ProvideWebhookDeliveryRepository",
+	"ProvideWebhookDispatcher",
+	"ProvideJobWorker",
+	"ProvideJobScheduler",
+	"ProvideWorkerMetricsServer",
+}
+
+// Module is an optional subsystem and whether the configuration enables it
+type Module struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"` // Non-secret settings, e.g. a host or a threshold
+}
+
+// StartupReport describes how the app was assembled: where its configuration
+// came from, the providers wired together and which optional modules are
+// enabled. It never contains secrets.
+type StartupReport struct {
+	Environment   string
+	ConfigSources []string
+	Providers     []string
+	Modules       []Module
+}
+
+// NewStartupReport describes the app assembled from cfg
+func NewStartupReport(cfg *config.Config) StartupReport {
+	login := cfg.Login
+	notification := cfg.Notification
+	modules := []Module{
+		{Name: "metrics", Enabled: cfg.Metrics.Port > 0, Detail: portDetail(cfg.Metrics.Port)},
+		{Name: "admin_listener", Enabled: cfg.App.AdminPort > 0, Detail: portDetail(cfg.App.AdminPort)},
+		{Name: "read_replicas", Enabled: len(cfg.Database.ReplicaSources) > 0, Detail: countDetail(len(cfg.Database.ReplicaSources), "replica")},
+		{Name: "redis_sentinel", Enabled: cfg.Redis.Failover.SentinelMaster != "", Detail: cfg.Redis.Failover.SentinelMaster},
+		{Name: "stateless_sign_in_fallback", Enabled: cfg.Redis.Failover.StatelessFallback},
+		{Name: "redis_user_cache", Enabled: cfg.Cache.Redis.Enabled},
+		{Name: "circuit_breakers", Enabled: cfg.Breaker.Enabled, Detail: countDetail(cfg.Breaker.Threshold(), "failure")},
+		{Name: "login_captcha", Enabled: login.Captcha.Enabled && login.CaptchaAfterFailures > 0, Detail: countDetail(login.CaptchaAfterFailures, "failure")},
+		{Name: "availability_captcha", Enabled: cfg.Availability.Captcha.Enabled, Detail: cfg.Availability.Captcha.Provider},
+		{Name: "registration_captcha", Enabled: cfg.Registration.Captcha.Enabled, Detail: cfg.Registration.Captcha.Provider},
+		{Name: "email_notifications", Enabled: notification.SMTP.Host != "", Detail: hostDetail(notification.SMTP.Host, notification.SMTP.Addr())},
+		{Name: "webhook_notifications", Enabled: notification.Webhook.URL != "", Detail: urlHost(notification.Webhook.URL)},
+		{Name: "user_webhooks", Enabled: cfg.Webhooks.Enabled},
+		{Name: "saml_sign_in", Enabled: cfg.SAML.Enabled, Detail: urlHost(cfg.SAML.BaseURL)},
+		{Name: "job_notifications", Enabled: cfg.Jobs.DeliverNotifications, Detail: "queue " + cfg.Jobs.QueueName()},
+		{Name: "feature_flag_overrides", Enabled: cfg.FeatureFlags.OverrideSecret != ""},
+		{Name: "grpc_reflection", Enabled: cfg.GRPC.Reflection},
+		{Name: "read_only_mode", Enabled: cfg.App.ReadOnly},
+		{Name: "response_compression", Enabled: cfg.Compression.Enabled},
+		{Name: "sentry_error_reports", Enabled: cfg.Errors.SentryDSN != "", Detail: urlHost(cfg.Errors.SentryDSN)},
+	}
+
+	return StartupReport{
+		Environment:   cfg.App.Env,
+		ConfigSources: cfg.Sources,
+		Providers:     providers,
+		Modules:       modules,
+	}
+}
+
+// NewWorkerStartupReport describes the worker assembled from cfg
+func NewWorkerStartupReport(cfg *config.Config) StartupReport {
+	report := NewStartupReport(cfg)
+	report.Providers = workerProviders
+	// The worker serves its metrics on a port of its own, and no HTTP API
+	modules := report.Modules[:0]
+	for _, m := range report.Modules {
+		switch m.Name {
+		case "metrics":
+			m = Module{Name: "metrics", Enabled: cfg.Jobs.MetricsPort > 0, Detail: portDetail(cfg.Jobs.MetricsPort)}
+		case "admin_listener":
+			continue
+		}
+		modules = append(modules, m)
+	}
+	report.Modules = modules
+	return report
+}
+
+// Enabled returns the names of the enabled modules
+func (r StartupReport) Enabled() []string {
+	enabled := []string{}
+	for _, m := range r.Modules {
+		if m.Enabled {
+			enabled = append(enabled, m.Name)
+		}
+	}
+	return enabled
+}
+
+// Log writes the report as a single structured entry, so log pipelines can
+// tell which subsystems a deployment runs
+func (r StartupReport) Log(logger *zap.Logger) {
+	logger.Info("Application assembled",
+		zap.String("environment", r.Environment),
+		zap.Strings("config_sources", r.ConfigSources),
+		zap.Strings("enabled_modules", r.Enabled()),
+		zap.Any("modules", r.Modules),
+		zap.Strings("providers", r.Providers))
+}
+
+func portDetail(port int) string {
+	if port <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("port %d", port)
+}
+
+func countDetail(n int, noun string) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n == 1:
+		return fmt.Sprintf("1 %s", noun)
+	default:
+		return fmt.Sprintf("%d %ss", n, noun)
+	}
+}
+
+func hostDetail(host, addr string) string {
+	if host == "" {
+		return ""
+	}
+	return addr
+}
+
+// urlHost returns the host of rawURL only, since paths and queries of
+// webhook URLs often carry tokens
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
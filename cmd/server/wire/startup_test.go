@@ -0,0 +1,80 @@
+package wire
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// wireBuildProviders returns the providers the injector passes to wire.Build in wire.go
+func wireBuildProviders(t *testing.T, injector string) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "wire.go", nil, 0)
+	require.NoError(t, err)
+
+	var names []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			return fn.Name.Name == injector
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Build" {
+			return true
+		}
+		for _, arg := range call.Args {
+			switch a := arg.(type) {
+			case *ast.Ident:
+				names = append(names, a.Name)
+			case *ast.SelectorExpr:
+				names = append(names, a.X.(*ast.Ident).Name+"."+a.Sel.Name)
+			}
+		}
+		return false
+	})
+	return names
+}
+
+func TestProvidersMatchWireBuild(t *testing.T) {
+	assert.Equal(t, wireBuildProviders(t, "InitializeApp"), providers)
+	assert.Equal(t, wireBuildProviders(t, "InitializeWorker"), workerProviders)
+}
+
+func TestNewStartupReport(t *testing.T) {
+	cfg := &config.Config{Sources: []string{"configs/config.dev.yaml"}}
+	cfg.App.Env = "development"
+	cfg.Metrics.Port = 9090
+	cfg.App.AdminPort = 8082
+	cfg.Database.ReplicaSources = []string{"replica-1", "replica-2"}
+	cfg.Login.CaptchaAfterFailures = 3
+	cfg.Notification.Webhook.URL = "https://hooks.example.com/notify?token=secret"
+
+	report := NewStartupReport(cfg)
+
+	assert.Equal(t, "development", report.Environment)
+	assert.Equal(t, []string{"configs/config.dev.yaml"}, report.ConfigSources)
+	assert.Equal(t, []string{"metrics", "admin_listener", "read_replicas", "webhook_notifications"}, report.Enabled())
+
+	modules := map[string]Module{}
+	for _, m := range report.Modules {
+		modules[m.Name] = m
+	}
+	assert.Equal(t, "port 9090", modules["metrics"].Detail)
+	assert.Equal(t, "port 8082", modules["admin_listener"].Detail)
+	assert.Equal(t, "2 replicas", modules["read_replicas"].Detail)
+	assert.Equal(t, "hooks.example.com", modules["webhook_notifications"].Detail)
+	assert.Equal(t, workerProviders, NewWorkerStartupReport(cfg).Providers)
+	// The worker only serves metrics on jobs.metrics_port
+	assert.NotContains(t, NewWorkerStartupReport(cfg).Enabled(), "metrics")
+	assert.NotContains(t, NewWorkerStartupReport(cfg).Enabled(), "admin_listener")
+	// CAPTCHA escalation needs a verifier as well as a threshold
+	assert.False(t, modules["login_captcha"].Enabled)
+}
@@ -4,67 +4,365 @@
 package wire
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	"github.com/yi-tech/go-user-service/internal/cache"
+	"github.com/yi-tech/go-user-service/internal/clientip"
 	"github.com/yi-tech/go-user-service/internal/config"
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainCompliance "github.com/yi-tech/go-user-service/internal/domain/compliance"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	domainOrganization "github.com/yi-tech/go-user-service/internal/domain/organization"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/eventbus"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/health"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+	"github.com/yi-tech/go-user-service/internal/logging"
+	"github.com/yi-tech/go-user-service/internal/metrics"
 	"github.com/yi-tech/go-user-service/internal/middleware"
+	"github.com/yi-tech/go-user-service/internal/password"
 	"github.com/yi-tech/go-user-service/internal/provider"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+	repoAPIKey "github.com/yi-tech/go-user-service/internal/repository/apikey"
+	repoAudit "github.com/yi-tech/go-user-service/internal/repository/audit"
 	repoAuth "github.com/yi-tech/go-user-service/internal/repository/auth"
+	repoFeatureFlag "github.com/yi-tech/go-user-service/internal/repository/featureflag"
+	repoImpersonation "github.com/yi-tech/go-user-service/internal/repository/impersonation"
+	repoLoginHistory "github.com/yi-tech/go-user-service/internal/repository/loginhistory"
+	repoMessage "github.com/yi-tech/go-user-service/internal/repository/message"
+	repoNotification "github.com/yi-tech/go-user-service/internal/repository/notification"
+	repoOrganization "github.com/yi-tech/go-user-service/internal/repository/organization"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"github.com/yi-tech/go-user-service/internal/repository/retry"
+	repoSAML "github.com/yi-tech/go-user-service/internal/repository/saml"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
 	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+	repoWebhook "github.com/yi-tech/go-user-service/internal/repository/webhook"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	serviceAPIKey "github.com/yi-tech/go-user-service/internal/service/apikey"
 	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+	serviceCaptcha "github.com/yi-tech/go-user-service/internal/service/captcha"
+	serviceCompliance "github.com/yi-tech/go-user-service/internal/service/compliance"
+	serviceDeadline "github.com/yi-tech/go-user-service/internal/service/deadline"
+	serviceEmailPolicy "github.com/yi-tech/go-user-service/internal/service/emailpolicy"
+	"github.com/yi-tech/go-user-service/internal/service/maintenance"
+	serviceMessage "github.com/yi-tech/go-user-service/internal/service/message"
+	serviceNotification "github.com/yi-tech/go-user-service/internal/service/notification"
+	serviceOrganization "github.com/yi-tech/go-user-service/internal/service/organization"
+	serviceRBAC "github.com/yi-tech/go-user-service/internal/service/rbac"
+	serviceSAML "github.com/yi-tech/go-user-service/internal/service/saml"
+	serviceSecurity "github.com/yi-tech/go-user-service/internal/service/security"
+	serviceSeed "github.com/yi-tech/go-user-service/internal/service/seed"
 	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	serviceExport "github.com/yi-tech/go-user-service/internal/service/userexport"
+	serviceImport "github.com/yi-tech/go-user-service/internal/service/userimport"
+	serviceWebhook "github.com/yi-tech/go-user-service/internal/service/webhook"
 	grpc "github.com/yi-tech/go-user-service/internal/transport/grpc"
 	grpcAuth "github.com/yi-tech/go-user-service/internal/transport/grpc/auth"
+	"github.com/yi-tech/go-user-service/internal/transport/grpc/interceptor"
 	grpcUser "github.com/yi-tech/go-user-service/internal/transport/grpc/user"
 	http "github.com/yi-tech/go-user-service/internal/transport/http"
+	httpAccount "github.com/yi-tech/go-user-service/internal/transport/http/account"
+	httpAdmin "github.com/yi-tech/go-user-service/internal/transport/http/admin"
 	httpAuth "github.com/yi-tech/go-user-service/internal/transport/http/auth"
+	httpHealth "github.com/yi-tech/go-user-service/internal/transport/http/health"
+	httpJWKS "github.com/yi-tech/go-user-service/internal/transport/http/jwks"
+	httpMessage "github.com/yi-tech/go-user-service/internal/transport/http/message"
+	httpOrg "github.com/yi-tech/go-user-service/internal/transport/http/org"
+	httpOrganization "github.com/yi-tech/go-user-service/internal/transport/http/organization"
+	httpRealtime "github.com/yi-tech/go-user-service/internal/transport/http/realtime"
 	httpUser "github.com/yi-tech/go-user-service/internal/transport/http/user"
+	"github.com/yi-tech/go-user-service/internal/tx"
 )
 
 // ProvideGRPCConfig provides the gRPC server configuration
 func ProvideGRPCConfig(cfg *config.Config) *grpc.Config {
 	return &grpc.Config{
-		GRPCPort: cfg.GRPC.Port,
-		HTTPPort: cfg.GRPC.Port + 1, // Use next port for HTTP gateway to avoid conflict with main HTTP server
+		GRPCPort:          cfg.GRPC.Port,
+		HTTPPort:          cfg.GRPC.Port + 1, // Use next port for HTTP gateway to avoid conflict with main HTTP server
+		Reflection:        cfg.GRPC.Reflection,
+		MaxRecvMsgSize:    cfg.GRPC.MaxRecvMsgSizeBytes,
+		MaxSendMsgSize:    cfg.GRPC.MaxSendMsgSizeBytes,
+		ConnectionTimeout: seconds(cfg.GRPC.ConnectionTimeoutSeconds),
+		Keepalive: grpc.KeepaliveConfig{
+			Time:                  seconds(cfg.GRPC.Keepalive.TimeSeconds),
+			Timeout:               seconds(cfg.GRPC.Keepalive.TimeoutSeconds),
+			MinTime:               seconds(cfg.GRPC.Keepalive.MinTimeSeconds),
+			PermitWithoutStream:   cfg.GRPC.Keepalive.PermitWithoutStream,
+			MaxConnectionIdle:     seconds(cfg.GRPC.Keepalive.MaxConnectionIdleSeconds),
+			MaxConnectionAge:      seconds(cfg.GRPC.Keepalive.MaxConnectionAgeSeconds),
+			MaxConnectionAgeGrace: seconds(cfg.GRPC.Keepalive.MaxConnectionAgeGraceSeconds),
+		},
+		MaxInFlight:     cfg.GRPC.MaxInFlight,
+		AuthMaxInFlight: cfg.GRPC.AuthMaxInFlight,
 	}
 }
 
+// seconds converts a config value in seconds to a time.Duration
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
 // ProvideGRPCServer creates a new gRPC server
-func ProvideGRPCServer(userService serviceUser.UserService, authService domainAuth.AuthService, logger *zap.Logger, cfg *grpc.Config) *grpc.Server {
-	return grpc.NewServer(userService, authService, logger, cfg)
+func ProvideGRPCServer(userService serviceUser.UserService, authService domainAuth.AuthService, adminService serviceAdmin.AdminService, organizationService serviceOrganization.Service, ids idgen.Strategy, logger *zap.Logger, cfg *grpc.Config, registry *prometheus.Registry, readOnlySwitch *readonly.Switch, compressor *middleware.Compressor, panics *recovery.Recorder, errorReporter errorreport.Reporter, settings *config.Config) (*grpc.Server, error) {
+	metricsInterceptor, err := interceptor.NewMetricsInterceptor(registry)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.Option{
+		grpc.WithRecovery(panics),
+		grpc.WithIDFormat(ids),
+		grpc.WithReadOnly(readOnlySwitch),
+		grpc.WithSettings(settings),
+		grpc.WithUnaryInterceptors(metricsInterceptor.Unary()),
+		grpc.WithStreamInterceptors(metricsInterceptor.Stream()),
+	}
+	if errorReporter != nil {
+		opts = append(opts, grpc.WithErrorReporting(errorReporter))
+	}
+	if compressor != nil {
+		opts = append(opts, grpc.WithGatewayMiddleware(compressor.Handler))
+	}
+	// The load balancers in front of the gRPC port are trusted like those of the REST API
+	resolver, err := clientip.NewResolver(append(slices.Clone(settings.App.TrustedProxies), grpc.GatewayProxies...), settings.App.ClientIPHeaders)
+	if err != nil {
+		return nil, err
+	}
+	global, groups, err := ipfilter.FromConfig(settings.IPFilter)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithClientIPResolver(resolver), grpc.WithIPFilter(global, groups))
+	return grpc.NewServer(userService, authService, adminService, organizationService, logger.Named(logging.GRPC), cfg, opts...), nil
+}
+
+// ProvideCompressor creates the response compressor of the HTTP API and the
+// gRPC gateway, or nil when compression is disabled
+func ProvideCompressor(cfg *config.Config) *middleware.Compressor {
+	if !cfg.Compression.Enabled {
+		return nil
+	}
+	return middleware.NewCompressor(cfg.Compression.MinSize(), cfg.Compression.Types())
+}
+
+// ProvideErrorReporter creates the reporter of internal errors and panics,
+// or nil when no error tracker is configured
+func ProvideErrorReporter(cfg *config.Config, logger *zap.Logger) (errorreport.Reporter, error) {
+	if cfg.Errors.SentryDSN == "" {
+		return nil, nil
+	}
+	return errorreport.NewSentryReporter(cfg.Errors.SentryDSN, cfg.App.Env, logger)
+}
+
+// ProvidePanicRecorder creates the recorder of panics recovered from HTTP
+// handlers and gRPC methods
+func ProvidePanicRecorder(registry *prometheus.Registry, reporter errorreport.Reporter, logger *zap.Logger) (*recovery.Recorder, error) {
+	return recovery.NewRecorder(logger, registry, reporter)
+}
+
+// ProvideMetricsRegistry creates the registry shared by HTTP and gRPC metrics
+func ProvideMetricsRegistry() *prometheus.Registry {
+	return metrics.NewRegistry()
+}
+
+// ProvideCacheMetrics registers the metrics shared by the in-process caches
+func ProvideCacheMetrics(registry *prometheus.Registry) (*cache.Metrics, error) {
+	return cache.NewMetrics(registry)
+}
+
+// ProvideCircuitBreakers creates the circuit breakers around Redis and the
+// database, exporting their state with the registry
+func ProvideCircuitBreakers(cfg *config.Config, registry *prometheus.Registry, logger *zap.Logger) (*breaker.Group, error) {
+	breakerMetrics, err := breaker.NewMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	opts := breaker.Options{FailureThreshold: cfg.Breaker.Threshold(), OpenTimeout: cfg.Breaker.OpenTimeout()}
+	return breaker.NewGroup(cfg.Breaker.Enabled, opts, breakerMetrics, logger), nil
+}
+
+// cacheConfig converts configured cache limits
+func cacheConfig(limits config.CacheLimits) cache.Config {
+	return cache.Config{MaxEntries: limits.MaxEntries, TTL: limits.TTL(), Jitter: limits.Jitter}
+}
+
+// ProvideMetricsServer creates the internal metrics listener, or nil when it is disabled
+func ProvideMetricsServer(cfg *config.Config, registry *prometheus.Registry) *metrics.Server {
+	if cfg.Metrics.Port == 0 {
+		return nil
+	}
+	return metrics.NewServer(fmt.Sprintf(":%d", cfg.Metrics.Port), registry)
+}
+
+// ProvideHealthMonitor probes the database and Redis for the health details endpoint
+func ProvideHealthMonitor(db *gorm.DB, redis *redis.Client, cfg *config.Config, logger *zap.Logger) (*health.Monitor, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	opts := health.Options{
+		Interval:      cfg.Health.CheckInterval(),
+		Timeout:       cfg.Health.Timeout(),
+		SlowThreshold: cfg.Health.SlowThreshold(),
+		Debounce:      cfg.Health.DebounceChecks(),
+	}
+	return health.NewMonitor(opts, logger,
+		health.Check{Name: "database", Probe: sqlDB.PingContext},
+		health.Check{Name: "redis", Probe: func(ctx context.Context) error { return redis.Ping(ctx).Err() }},
+	), nil
+}
+
+// ProvideConfigWatcher applies the log levels and rate limits of a changed
+// config file without a restart
+func ProvideConfigWatcher(cfg *config.Config, levels *logging.Levels, availabilityLimiter *middleware.RateLimiter, logger *zap.Logger) *config.Watcher {
+	watcher := config.NewWatcher(cfg, logger)
+	watcher.OnChange(func(updated *config.Config) {
+		levels.Apply(updated.LogLevel(), updated.LogModuleLevels())
+		availabilityLimiter.SetLimit(updated.Availability.RateLimit())
+	})
+	return watcher
 }
 
 // App represents the main application structure.
 type App struct {
-	HTTPServer *http.Server // HTTP server (Gin) instance
-	GRPCServer *grpc.Server // gRPC server instance
-	DB         *gorm.DB
-	Config     *config.Config
-	Logger     *zap.Logger
+	HTTPServer    *http.Server    // HTTP server (Gin) instance
+	GRPCServer    *grpc.Server    // gRPC server instance
+	MetricsServer *metrics.Server // Internal metrics listener; nil when disabled
+	HealthMonitor *health.Monitor // Probes the dependencies until the app shuts down
+	// LoginHistoryPruner deletes expired sign-in history until the app shuts down
+	LoginHistoryPruner *serviceAuth.LoginHistoryPruner
+	Notifications      *serviceNotification.Service // Sends queued notifications until the app shuts down
+	Events             *eventbus.Bus                // Relays user events between instances until the app shuts down
+	ConfigWatcher      *config.Watcher              // Applies config file changes that need no restart until the app shuts down
+	DB                 *gorm.DB
+	Config             *config.Config
+	Logger             *zap.Logger
 }
 
 // InitializeApp creates the application dependencies.
-func InitializeApp() (*App, error) {
+func InitializeApp(opts config.Options) (*App, error) {
 	wire.Build(
 		provider.ProvideConfig,
+		provider.ProvideLogLevels,
 		provider.ProvideLogger, // Now takes config as parameter
 		provider.ProvideDatabase,
+		provider.ProvideReadReplicas,
 		provider.ProvideRedisClient,
+		ProvideRedisKeys,
 		ProvideUserRepository,
+		ProvideNormalizationRepository,
+		ProvideAuthStore,
 		ProvideAuthRepository,
+		ProvideSessionRepository,
+		ProvideSigningKeyRepository,
+		ProvideLoginAttemptRepository,
+		ProvideKeyInspector,
+		ProvideAuditRepository,
+		ProvideLoginHistoryRepository,
+		ProvideImpersonationRepository,
+		ProvideDeadLetterRepository,
+		ProvideMessageRepository,
+		ProvideAPIKeyRepository,
+		ProvideOrganizationRepository,
+		ProvideFeatureFlagStore,
+		ProvideWebhookRepository,
+		ProvideWebhookDeliveryRepository,
+		ProvideSAMLRepository,
+		ProvideSAMLAssertionCache,
+		ProvideTxManager,
+		ProvideKeyRing,
+		ProvideKeyManager,
 
+		ProvideIDStrategy,
+		ProvideIDGenerator,
+		ProvideResidencyPolicy,
+		ProvideEmailPolicy,
+		ProvideReadOnlySwitch,
+		ProvideFeatureFlags,
+		ProvideNotificationService,
+		ProvideJobBroker,
+		ProvideJobQueue,
+		ProvideNotifier,
+		ProvideEventBus,
+		ProvideEventPublisher,
+		ProvideSecurityLog,
 		ProvideUserService,
+		ProvideAvailabilityChecker,
+		ProvideCaptchaVerifier,
 		ProvideAuthService,
+		ProvideRoleService,
+		ProvideAdminService,
+		ProvideMessageService,
+		ProvideWebhookService,
+		ProvideSAMLService,
+		ProvideAPIKeyService,
+		ProvideOrganizationService,
+		ProvideImportService,
+		ProvideExportService,
+		ProvideExportGenerator,
 		ProvideUserHttpHandler,
+		ProvideAvailabilityHttpHandler,
+		ProvideAvailabilityLimiter,
 		ProvideAuthHttpHandler,
+		ProvideAdminHttpHandler,
+		ProvideAccountHttpHandler,
+		ProvideReadOnlyHttpHandler,
+		ProvideFeatureFlagHttpHandler,
+		ProvideLoggingHttpHandler,
+		ProvideWebhookHttpHandler,
+		ProvideSAMLHttpHandler,
+		ProvideSAMLProviderHttpHandler,
+		ProvideImportHttpHandler,
+		ProvideExportHttpHandler,
+		ProvideMessageHttpHandler,
+		ProvideOrgHttpHandler,
+		ProvideOrganizationHttpHandler,
+		ProvideAccountCenterHttpHandler,
+		ProvideJWKSHttpHandler,
+		ProvideMetricsRegistry,
+		ProvideErrorReporter,
+		ProvidePanicRecorder,
+		ProvideCacheMetrics,
+		ProvideAuthMetrics,
+		ProvideCircuitBreakers,
+		ProvideMetricsServer,
+		ProvideHealthMonitor,
+		ProvideConfigWatcher,
+		ProvideLoginHistoryPruner,
+		ProvideHealthHttpHandler,
+		ProvideRealtimeHub,
+		ProvideAdminEventFeed,
+		ProvideRealtimeHttpHandler,
 		ProvideRouter,
 		ProvideGRPCConfig,
+		ProvideCompressor,
 		ProvideGRPCServer,
 		ProvideHTTPServer,
 		wire.Struct(new(App), "*"),
@@ -73,40 +371,627 @@ func InitializeApp() (*App, error) {
 	return &App{}, nil // Wire will provide the actual implementation
 }
 
+// WorkerApp represents the background job worker run by cmd/worker.
+type WorkerApp struct {
+	Worker        *jobs.Worker    // Runs queued jobs until the worker shuts down
+	Queue         *jobs.Queue     // Enqueues jobs from runbooks
+	Scheduler     *jobs.Scheduler // Enqueues the housekeeping jobs at their intervals
+	MetricsServer *metrics.Server // Worker metrics listener; nil when disabled
+	DB            *gorm.DB
+	Config        *config.Config
+	Logger        *zap.Logger
+}
+
+// InitializeWorker creates the worker dependencies from the same providers as the app.
+func InitializeWorker(opts config.Options) (*WorkerApp, error) {
+	wire.Build(
+		provider.ProvideConfig,
+		provider.ProvideLogLevels,
+		provider.ProvideLogger,
+		provider.ProvideDatabase,
+		provider.ProvideReadReplicas,
+		provider.ProvideRedisClient,
+		ProvideRedisKeys,
+		ProvideUserRepository,
+		ProvideAuthStore,
+		ProvideSessionRepository,
+		ProvideAuthRepository,
+		ProvideAuditRepository,
+		ProvideDeadLetterRepository,
+		ProvideIDStrategy,
+		ProvideIDGenerator,
+		ProvideResidencyPolicy,
+		ProvideMetricsRegistry,
+		ProvideCacheMetrics,
+		ProvideCircuitBreakers,
+		ProvideNotificationService,
+		ProvideExportService,
+		ProvideJobBroker,
+		ProvideJobQueue,
+		ProvideExportGenerator,
+		ProvideMaintenanceMetrics,
+		ProvideMaintenanceTasks,
+		ProvideWebhookRepository,
+		ProvideWebhookDeliveryRepository,
+		ProvideWebhookDispatcher,
+		ProvideJobWorker,
+		ProvideJobScheduler,
+		ProvideWorkerMetricsServer,
+		wire.Struct(new(WorkerApp), "*"),
+	)
+
+	return &WorkerApp{}, nil // Wire will provide the actual implementation
+}
+
+// SeedApp loads development fixtures for cmd/seed.
+type SeedApp struct {
+	Loader *serviceSeed.Loader // Stores fixtures through the user and organization services
+	DB     *gorm.DB
+	Config *config.Config
+	Logger *zap.Logger
+}
+
+// InitializeSeeder creates the fixture loader from the same providers as the app.
+func InitializeSeeder(opts config.Options) (*SeedApp, error) {
+	wire.Build(
+		provider.ProvideConfig,
+		provider.ProvideLogLevels,
+		provider.ProvideLogger,
+		provider.ProvideDatabase,
+		provider.ProvideReadReplicas,
+		provider.ProvideRedisClient,
+		ProvideRedisKeys,
+		ProvideUserRepository,
+		ProvideNormalizationRepository,
+		ProvideOrganizationRepository,
+		ProvideAuditRepository,
+		ProvideDeadLetterRepository,
+		ProvideIDStrategy,
+		ProvideIDGenerator,
+		ProvideResidencyPolicy,
+		ProvideEmailPolicy,
+		ProvideMetricsRegistry,
+		ProvideCacheMetrics,
+		ProvideCircuitBreakers,
+		ProvideNotificationService,
+		ProvideJobBroker,
+		ProvideJobQueue,
+		ProvideNotifier,
+		ProvideEventBus,
+		ProvideEventPublisher,
+		ProvideSecurityLog,
+		ProvideTxManager,
+		ProvideUserService,
+		ProvideOrganizationService,
+		ProvideSeedLoader,
+		wire.Struct(new(SeedApp), "*"),
+	)
+
+	return &SeedApp{}, nil // Wire will provide the actual implementation
+}
+
 // Provider functions for repositories
-func ProvideUserRepository(db *gorm.DB) domainUser.Repository {
-	return repoUser.NewUserRepository(db)
+
+// ProvideUserRepository reads users through the in-process cache, then the
+// Redis cache, then the database; either cache may be disabled. Database
+// lookups and listings go to the read replicas when any are configured and
+// are retried on transient errors, behind the database circuit breaker.
+func ProvideUserRepository(db *gorm.DB, replicas *replica.Pool, breakers *breaker.Group, redis *redis.Client, keys rediskey.Schema, cacheMetrics *cache.Metrics, cfg *config.Config) domainUser.Repository {
+	stored := repoUser.NewRetryRepository(repoUser.NewUserRepository(db, replicas), retryPolicy(cfg.Database.Retry.Reads), retryPolicy(cfg.Database.Retry.Writes))
+	repo := repoUser.NewRedisCachedRepository(repoUser.NewBreakerRepository(stored, breakers.Breaker("database")), redis, keys, cfg.Cache.Redis.TTL(), cacheMetrics)
+	return repoUser.NewCachedRepository(repo, cache.New[uuid.UUID, domainUser.User]("users", cacheConfig(cfg.Cache.Users), cacheMetrics))
+}
+
+// ProvideNormalizationRepository records which email normalizations stored
+// addresses were rewritten with
+func ProvideNormalizationRepository(db *gorm.DB) domainUser.NormalizationRepository {
+	return repoUser.NewNormalizationRepository(db)
+}
+
+// ProvideRedisKeys builds the Redis key schema of this deployment
+func ProvideRedisKeys(cfg *config.Config) (rediskey.Schema, error) {
+	return rediskey.New(cfg.Redis.KeyPrefix)
+}
+
+// ProvideAuthStore opens the refresh token and session store selected by auth_store.driver
+func ProvideAuthStore(redis *redis.Client, keys rediskey.Schema, db *gorm.DB, breakers *breaker.Group, cfg *config.Config) (repoAuth.Store, error) {
+	return repoAuth.OpenStore(cfg.AuthStore.Driver, repoAuth.Backends{
+		Redis: redis,
+		Keys:  keys,
+		Retry: redisRetryPolicy(cfg.Redis.Failover, breakers.Breaker("redis")),
+		DB:    db,
+	})
+}
+
+func ProvideAuthRepository(store repoAuth.Store) domainAuth.AuthRepository {
+	return store.Tokens
+}
+
+func ProvideSessionRepository(store repoAuth.Store) domainAuth.SessionRepository {
+	return store.Sessions
+}
+
+func ProvideSigningKeyRepository(store repoAuth.Store) domainAuth.SigningKeyRepository {
+	return store.SigningKeys
+}
+
+// retryPolicy converts the configured retries of a kind of repository call
+func retryPolicy(cfg config.RetryConfig) retry.Policy {
+	return retry.Policy{Attempts: cfg.Attempts, Backoff: cfg.Backoff(), MaxBackoff: cfg.MaxBackoff()}
 }
 
-func ProvideAuthRepository(redis *redis.Client) domainAuth.AuthRepository {
-	return repoAuth.NewAuthRepository(redis)
+// redisRetryPolicy retries token and session commands while Redis fails
+// over, failing them straight away while the Redis circuit breaker is open
+func redisRetryPolicy(cfg config.RedisFailoverConfig, b *breaker.Breaker) repoAuth.RetryPolicy {
+	return repoAuth.RetryPolicy{Attempts: cfg.Attempts(), Backoff: cfg.Backoff(), Breaker: b}
+}
+
+func ProvideLoginAttemptRepository(redis *redis.Client, keys rediskey.Schema) domainAuth.LoginAttemptRepository {
+	return repoAuth.NewLoginAttemptRepository(redis, keys)
+}
+
+func ProvideKeyInspector(redis *redis.Client, keys rediskey.Schema) domainAuth.KeyInspector {
+	return repoAuth.NewKeyInspector(redis, keys)
+}
+
+func ProvideAuditRepository(db *gorm.DB) domainAudit.Repository {
+	return repoAudit.NewAuditRepository(db)
+}
+
+func ProvideLoginHistoryRepository(db *gorm.DB) domainAuth.LoginHistoryRepository {
+	return repoLoginHistory.NewLoginHistoryRepository(db)
+}
+
+func ProvideImpersonationRepository(db *gorm.DB) domainAuth.ImpersonationRepository {
+	return repoImpersonation.NewImpersonationRepository(db)
+}
+
+// ProvideLoginHistoryPruner deletes sign-in history older than login.history.retention_days
+func ProvideLoginHistoryPruner(history domainAuth.LoginHistoryRepository, cfg *config.Config, logger *zap.Logger) *serviceAuth.LoginHistoryPruner {
+	return serviceAuth.NewLoginHistoryPruner(history, cfg.Login.History.Retention(), cfg.Login.History.PruneInterval(), logger)
+}
+
+func ProvideDeadLetterRepository(db *gorm.DB) domainNotification.DeadLetterRepository {
+	return repoNotification.NewDeadLetterRepository(db)
+}
+
+func ProvideMessageRepository(db *gorm.DB) domainMessage.Repository {
+	return repoMessage.NewMessageRepository(db)
+}
+
+func ProvideWebhookRepository(db *gorm.DB) domainWebhook.Repository {
+	return repoWebhook.NewWebhookRepository(db)
+}
+
+func ProvideWebhookDeliveryRepository(db *gorm.DB) domainWebhook.DeliveryRepository {
+	return repoWebhook.NewDeliveryRepository(db)
+}
+
+func ProvideSAMLRepository(db *gorm.DB) domainSAML.Repository {
+	return repoSAML.NewSAMLRepository(db)
+}
+
+// ProvideSAMLAssertionCache remembers the assertions users signed in with
+// until they expire, so that none is accepted twice
+func ProvideSAMLAssertionCache(redis *redis.Client, keys rediskey.Schema) domainSAML.AssertionCache {
+	return repoSAML.NewAssertionCache(redis, keys)
+}
+
+func ProvideAPIKeyRepository(db *gorm.DB) domainAPIKey.Repository {
+	return repoAPIKey.NewAPIKeyRepository(db)
+}
+
+func ProvideOrganizationRepository(db *gorm.DB) domainOrganization.Repository {
+	return repoOrganization.NewOrganizationRepository(db)
+}
+
+func ProvideFeatureFlagStore(db *gorm.DB) featureflag.Store {
+	return repoFeatureFlag.NewFlagRepository(db)
+}
+
+// ProvideFeatureFlags evaluates feature flags: values set through the admin API over the defaults configured for this environment
+func ProvideFeatureFlags(store featureflag.Store, cfg *config.Config, logger *zap.Logger) *featureflag.Flags {
+	return featureflag.New(store, cfg.FeatureFlags.Defaults, cfg.FeatureFlags.Refresh(), logger)
+}
+
+// ProvideTxManager shares one unit-of-work manager among the services
+func ProvideTxManager(db *gorm.DB) tx.Manager {
+	return transaction.NewManager(db)
 }
 
 // Provider functions for services
-func ProvideUserService(repo domainUser.Repository) serviceUser.UserService {
-	return serviceUser.NewUserService(repo)
+// ProvideNotificationService sends notifications through the providers
+// enabled under notification in the configuration
+func ProvideNotificationService(deadLetters domainNotification.DeadLetterRepository, cfg *config.Config, ids idgen.Generator, logger *zap.Logger) *serviceNotification.Service {
+	return serviceNotification.NewService(serviceNotification.NewProviders(cfg.Notification), deadLetters, cfg.Notification, ids, logger)
+}
+
+// ProvideNotifier lets the services send notifications through the
+// notification service, or through cmd/worker when jobs.deliver_notifications is set
+func ProvideNotifier(notifications *serviceNotification.Service, queue *jobs.Queue, cfg *config.Config, logger *zap.Logger) domainNotification.Notifier {
+	if cfg.Jobs.DeliverNotifications {
+		return serviceNotification.NewJobNotifier(queue, serviceNotification.NewProviders(cfg.Notification), logger)
+	}
+	return notifications
+}
+
+// ProvideSecurityLog records the security events of accounts in the audit log
+func ProvideSecurityLog(auditRepo domainAudit.Repository, ids idgen.Generator, logger *zap.Logger) domainAudit.SecurityLog {
+	return serviceSecurity.NewLog(auditRepo, ids, logger)
+}
+
+// ProvideEventBus relays the events of users between instances through Redis Pub/Sub
+func ProvideEventBus(redis *redis.Client, keys rediskey.Schema, ids idgen.Generator, logger *zap.Logger) *eventbus.Bus {
+	return eventbus.NewBus(redis, keys, ids, logger)
+}
+
+// ProvideEventPublisher publishes the events of users on the bus, also
+// dispatching user lifecycle events to webhooks when webhooks.enabled is set
+func ProvideEventPublisher(bus *eventbus.Bus, queue *jobs.Queue, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) domainEvent.Publisher {
+	if !cfg.Webhooks.Enabled {
+		return bus
+	}
+	return serviceWebhook.NewPublisher(bus, queue, ids, logger)
 }
 
-func ProvideAuthService(userService serviceUser.UserService, authRepo domainAuth.AuthRepository, cfg *config.Config) domainAuth.AuthService {
-	return serviceAuth.NewService(userService, authRepo, cfg)
+// ProvideJobBroker keeps the background jobs of jobs.queue in Redis
+func ProvideJobBroker(redis *redis.Client, keys rediskey.Schema, cfg *config.Config) jobs.Broker {
+	return jobs.NewRedisBroker(redis, keys, cfg.Jobs.QueueName())
+}
+
+func ProvideJobQueue(broker jobs.Broker, ids idgen.Generator, cfg *config.Config) *jobs.Queue {
+	return jobs.NewQueue(broker, ids, cfg.Jobs.Attempts())
+}
+
+// ProvideAuthMetrics registers the counters of failed best-effort sign-in
+// and sign-out steps
+func ProvideAuthMetrics(registry *prometheus.Registry) (*serviceAuth.Metrics, error) {
+	return serviceAuth.NewMetrics(registry)
+}
+
+// ProvideMaintenanceMetrics registers the counters of rows the housekeeping jobs clean
+func ProvideMaintenanceMetrics(registry *prometheus.Registry) (*maintenance.Metrics, error) {
+	return maintenance.NewMetrics(registry)
+}
+
+// ProvideMaintenanceTasks creates the housekeeping jobs, keeping the audit log
+// for audit.retention_days
+func ProvideMaintenanceTasks(sessions domainAuth.SessionRepository, tokens domainAuth.AuthRepository, auditRepo domainAudit.Repository, maintenanceMetrics *maintenance.Metrics, cfg *config.Config, logger *zap.Logger) *maintenance.Tasks {
+	return maintenance.NewTasks(sessions, tokens, auditRepo, cfg.Audit.Retention(), maintenanceMetrics, logger)
+}
+
+// ProvideJobScheduler enqueues the housekeeping jobs at the intervals of
+// jobs.schedule; Redis elects a single scheduler across the workers
+func ProvideJobScheduler(queue *jobs.Queue, redis *redis.Client, keys rediskey.Schema, tasks *maintenance.Tasks, cfg *config.Config, logger *zap.Logger) *jobs.Scheduler {
+	s := jobs.NewScheduler(queue, jobs.NewRedisClaimer(redis, keys), logger)
+	tasks.Schedule(s, cfg.Jobs.Schedule)
+	return s
+}
+
+// ProvideWorkerMetricsServer serves the worker metrics on jobs.metrics_port, or
+// returns nil when it is disabled
+func ProvideWorkerMetricsServer(cfg *config.Config, registry *prometheus.Registry) *metrics.Server {
+	if cfg.Jobs.MetricsPort <= 0 {
+		return nil
+	}
+	return metrics.NewServer(fmt.Sprintf(":%d", cfg.Jobs.MetricsPort), registry)
+}
+
+// ProvideJobWorker registers the handler of every job type
+func ProvideJobWorker(broker jobs.Broker, notifications *serviceNotification.Service, exports *serviceExport.Files, tasks *maintenance.Tasks, webhooks *serviceWebhook.Dispatcher, cfg *config.Config, logger *zap.Logger) *jobs.Worker {
+	w := jobs.NewWorker(broker, cfg.Jobs, logger)
+	w.Register(serviceNotification.SendJob, notifications.HandleSendJob)
+	w.Register(serviceExport.GenerateJob, exports.Generate)
+	tasks.Register(w)
+	webhooks.Register(w)
+	return w
+}
+
+// ProvideWebhookDispatcher delivers the user events enqueued by the servers
+// to webhook subscriptions
+func ProvideWebhookDispatcher(repo domainWebhook.Repository, deliveries domainWebhook.DeliveryRepository, queue *jobs.Queue, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) *serviceWebhook.Dispatcher {
+	return serviceWebhook.NewDispatcher(repo, deliveries, queue, cfg.Webhooks.Timeout(), ids, logger)
+}
+
+// ProvideUserService creates the user service. Registrations must pass a
+// CAPTCHA challenge when registration.captcha is enabled and are stored
+// together with their security event, and operations run within the
+// deadlines of the deadlines configuration. It fails while Gmail aliases
+// are to be collapsed but the stored addresses were not normalized that way.
+func ProvideUserService(repo domainUser.Repository, normalizations domainUser.NormalizationRepository, ids idgen.Generator, residency domainCompliance.ResidencyPolicy, notifier domainNotification.Notifier, events domainEvent.Publisher, securityLog domainAudit.SecurityLog, emails domainUser.EmailPolicy, tx tx.Manager, cfg *config.Config) (serviceUser.UserService, error) {
+	addresses := emailNormalizer(cfg.Email)
+	if err := serviceUser.CheckEmailNormalization(context.Background(), normalizations, addresses); err != nil {
+		return nil, err
+	}
+	hasher, err := passwordHasher(cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+	opts := []serviceUser.Option{
+		serviceUser.WithIDGenerator(ids),
+		serviceUser.WithResidencyPolicy(residency),
+		serviceUser.WithPasswordHasher(hasher),
+		serviceUser.WithNotifier(notifier),
+		serviceUser.WithEventPublisher(events),
+		serviceUser.WithSecurityLog(securityLog),
+		serviceUser.WithTxManager(tx),
+		serviceUser.WithEmailPolicy(emails),
+		serviceUser.WithEmailNormalizer(addresses),
+		serviceUser.WithDeadlines(serviceDeadline.FromConfig(cfg.Deadlines)),
+	}
+	verifier, err := serviceCaptcha.NewVerifier(cfg.Registration.Captcha)
+	if err != nil {
+		return nil, err
+	}
+	if verifier != nil {
+		opts = append(opts, serviceUser.WithRegistrationCaptcha(verifier))
+	}
+	return serviceUser.NewUserService(repo, opts...), nil
+}
+
+// emailNormalizer builds the normalizer of email addresses from configuration
+func emailNormalizer(cfg config.EmailConfig) emailaddr.Normalizer {
+	return emailaddr.Normalizer{CollapseGmailAliases: cfg.CollapseGmailAliases}
+}
+
+// passwordHasher builds the hasher of new passwords from configuration
+func passwordHasher(cfg config.PasswordConfig) (*password.Hasher, error) {
+	switch password.Algorithm(cfg.HashAlgorithm()) {
+	case password.AlgorithmArgon2id:
+		params := password.DefaultArgon2Params
+		if cfg.Argon2.MemoryKiB > 0 {
+			params.MemoryKiB = cfg.Argon2.MemoryKiB
+		}
+		if cfg.Argon2.Iterations > 0 {
+			params.Iterations = cfg.Argon2.Iterations
+		}
+		if cfg.Argon2.Parallelism > 0 {
+			params.Parallelism = cfg.Argon2.Parallelism
+		}
+		return password.NewArgon2idHasher(params)
+	case password.AlgorithmBcrypt:
+		return password.NewBcryptHasher(cfg.Cost())
+	default:
+		return nil, fmt.Errorf("unknown password algorithm %q", cfg.Algorithm)
+	}
+}
+
+// ProvideResidencyPolicy creates the data residency policy from configuration
+func ProvideResidencyPolicy(cfg *config.Config) (domainCompliance.ResidencyPolicy, error) {
+	return serviceCompliance.NewResidencyPolicy(cfg.Compliance)
+}
+
+// ProvideEmailPolicy restricts the email addresses accounts sign up with to
+// the registration.email_domains rules
+func ProvideEmailPolicy(cfg *config.Config) (domainUser.EmailPolicy, error) {
+	return serviceEmailPolicy.FromConfig(cfg.Registration.EmailDomains)
+}
+
+// ProvideReadOnlySwitch creates the read-only mode switch, starting in the configured mode
+func ProvideReadOnlySwitch(cfg *config.Config) *readonly.Switch {
+	return readonly.NewSwitch(cfg.App.ReadOnly)
+}
+
+// ProvideIDStrategy validates the configured ID strategy
+func ProvideIDStrategy(cfg *config.Config) (idgen.Strategy, error) {
+	return idgen.ParseStrategy(cfg.App.IDStrategy)
+}
+
+// ProvideIDGenerator creates IDs for new entities with the configured strategy
+func ProvideIDGenerator(strategy idgen.Strategy) idgen.Generator {
+	return idgen.NewGenerator(strategy)
+}
+
+// ProvideAvailabilityChecker creates the signup availability checker
+func ProvideAvailabilityChecker(repo domainUser.Repository, cfg *config.Config) serviceUser.AvailabilityChecker {
+	return serviceUser.NewAvailabilityChecker(repo, emailNormalizer(cfg.Email), cfg.Availability.MinResponse())
+}
+
+// ProvideCaptchaVerifier returns nil when CAPTCHA gating is disabled
+func ProvideCaptchaVerifier(cfg *config.Config) (serviceCaptcha.Verifier, error) {
+	return serviceCaptcha.NewVerifier(cfg.Availability.Captcha)
+}
+
+// ProvideAuthService creates the auth service. Sign-ins escalate to a CAPTCHA
+// challenge after repeated failures when login.captcha_after_failures is set,
+// users are alerted to sign-ins from new devices when
+// login.new_device_alerts is set, and operations run within the deadlines of
+// the deadlines configuration.
+func ProvideAuthService(userService serviceUser.UserService, authRepo domainAuth.AuthRepository, sessions domainAuth.SessionRepository, attempts domainAuth.LoginAttemptRepository, history domainAuth.LoginHistoryRepository, impersonations domainAuth.ImpersonationRepository, events domainEvent.Publisher, securityLog domainAudit.SecurityLog, notifier domainNotification.Notifier, cfg *config.Config, keyRing *serviceAuth.KeyRing, cacheMetrics *cache.Metrics, authMetrics *serviceAuth.Metrics, logger *zap.Logger) (domainAuth.AuthService, error) {
+	tokens := cache.New[[sha256.Size]byte, uuid.UUID]("tokens", cacheConfig(cfg.Cache.Tokens), cacheMetrics)
+	opts := []serviceAuth.Option{serviceAuth.WithTokenCache(tokens), serviceAuth.WithMetrics(authMetrics), serviceAuth.WithLoginHistory(history), serviceAuth.WithImpersonation(impersonations), serviceAuth.WithEventPublisher(events)}
+	if cfg.Login.CaptchaAfterFailures > 0 {
+		verifier, err := serviceCaptcha.NewVerifier(cfg.Login.Captcha)
+		if err != nil {
+			return nil, err
+		}
+		if verifier == nil {
+			return nil, fmt.Errorf("login: captcha must be enabled when captcha_after_failures is set")
+		}
+		opts = append(opts, serviceAuth.WithCaptchaEscalation(attempts, verifier, cfg.Login.CaptchaAfterFailures, cfg.Login.FailureWindow()))
+	}
+	if cfg.Login.NewDeviceAlerts {
+		opts = append(opts, serviceAuth.WithNewDeviceAlerts(securityLog, notifier))
+	}
+	opts = append(opts, serviceAuth.WithDeadlines(serviceDeadline.FromConfig(cfg.Deadlines)))
+	return serviceAuth.NewService(userService, authRepo, sessions, cfg, keyRing, logger, opts...)
+}
+
+// ProvideKeyRing builds the access token signing key ring from configuration
+// and the keys generated by rotation, which signingKeys shares between instances
+func ProvideKeyRing(cfg *config.Config, signingKeys domainAuth.SigningKeyRepository, logger *zap.Logger) (*serviceAuth.KeyRing, error) {
+	return serviceAuth.NewSharedKeyRing(context.Background(), cfg.JWT, signingKeys, cfg.JWT.KeyRefresh(), logger)
+}
+
+func ProvideKeyManager(keyRing *serviceAuth.KeyRing) domainAuth.KeyManager {
+	return keyRing
+}
+
+func ProvideRoleService() domainRBAC.RoleService {
+	return serviceRBAC.NewService()
+}
+
+// ProvideAdminService creates the account management service; revoking
+// sessions goes through the auth service so tokens and sessions stay in sync,
+// and operations run within the deadlines of the deadlines configuration
+func ProvideAdminService(repo domainUser.Repository, sessions domainAuth.SessionRepository, keys domainAuth.KeyInspector, authService domainAuth.AuthService, auditRepo domainAudit.Repository, history domainAuth.LoginHistoryRepository, notifier domainNotification.Notifier, events domainEvent.Publisher, tx tx.Manager, ids idgen.Generator, cfg *config.Config) serviceAdmin.AdminService {
+	return serviceAdmin.NewAdminService(repo, sessions, keys, authService, authService, auditRepo, history, notifier, events, tx, ids, serviceAdmin.WithDeadlines(serviceDeadline.FromConfig(cfg.Deadlines)))
+}
+
+func ProvideMessageService(repo domainMessage.Repository, ids idgen.Generator) serviceMessage.MessageService {
+	return serviceMessage.NewMessageService(repo, ids)
+}
+
+func ProvideWebhookService(repo domainWebhook.Repository, deliveries domainWebhook.DeliveryRepository, ids idgen.Generator) serviceWebhook.Service {
+	return serviceWebhook.NewWebhookService(repo, deliveries, ids)
+}
+
+// ProvideSAMLService creates the SAML sign-in service; the sessions of SAML
+// users are issued by the auth service like those of password sign-ins
+func ProvideSAMLService(repo domainSAML.Repository, assertions domainSAML.AssertionCache, userService serviceUser.UserService, authService domainAuth.AuthService, events domainEvent.Publisher, emails domainUser.EmailPolicy, cfg *config.Config, logger *zap.Logger) serviceSAML.Service {
+	return serviceSAML.NewSAMLService(repo, assertions, userService, authService, events, cfg.SAML, logger, serviceSAML.WithEmailPolicy(emails))
+}
+
+// ProvideAPIKeyService creates the organization API key service
+func ProvideAPIKeyService(repo domainAPIKey.Repository, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) serviceAPIKey.Service {
+	return serviceAPIKey.NewService(repo, ids, cfg.APIKeys.RotationOverlap(), logger)
+}
+
+// ProvideOrganizationService creates the organization service; invitations
+// find accounts through the user service, and operations run within the
+// deadlines of the deadlines configuration
+func ProvideOrganizationService(repo domainOrganization.Repository, userService serviceUser.UserService, ids idgen.Generator, logger *zap.Logger, cfg *config.Config) serviceOrganization.Service {
+	return serviceOrganization.NewService(repo, userService, ids, logger, serviceOrganization.WithDeadlines(serviceDeadline.FromConfig(cfg.Deadlines)))
+}
+
+// ProvideSeedLoader creates the development fixture loader
+func ProvideSeedLoader(userService serviceUser.UserService, organizationService serviceOrganization.Service, logger *zap.Logger) *serviceSeed.Loader {
+	return serviceSeed.NewLoader(userService, organizationService, logger)
+}
+
+// ProvideImportService creates the bulk user import service
+func ProvideImportService(userService serviceUser.UserService, auditRepo domainAudit.Repository, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) serviceImport.Service {
+	return serviceImport.NewService(userService, auditRepo, ids, emailNormalizer(cfg.Email), cfg.Import.Batch(), logger)
+}
+
+// ProvideExportService creates the bulk user export service
+func ProvideExportService(repo domainUser.Repository, residency domainCompliance.ResidencyPolicy, auditRepo domainAudit.Repository, ids idgen.Generator, idFormat idgen.Strategy, cfg *config.Config, logger *zap.Logger) serviceExport.Service {
+	return serviceExport.NewService(repo, residency, auditRepo, ids, idFormat, cfg.Export.Batch(), logger)
+}
+
+// ProvideExportGenerator writes background exports to export.directory
+func ProvideExportGenerator(exporter serviceExport.Service, queue *jobs.Queue, cfg *config.Config, logger *zap.Logger) *serviceExport.Files {
+	return serviceExport.NewFiles(exporter, queue, cfg.Export.Dir(), logger)
 }
 
 // Provider functions for HTTP handlers
-func ProvideUserHttpHandler(userService serviceUser.UserService, logger *zap.Logger) *httpUser.Handler {
-	return httpUser.NewHandler(userService, logger)
+func ProvideUserHttpHandler(userService serviceUser.UserService, ids idgen.Strategy, logger *zap.Logger) *httpUser.Handler {
+	return httpUser.NewHandler(userService, ids, logger)
+}
+
+func ProvideAvailabilityHttpHandler(checker serviceUser.AvailabilityChecker, verifier serviceCaptcha.Verifier, logger *zap.Logger) *httpUser.AvailabilityHandler {
+	return httpUser.NewAvailabilityHandler(checker, verifier, logger)
+}
+
+// ProvideAvailabilityLimiter throttles the signup availability check per
+// client; the config watcher adjusts its limit
+func ProvideAvailabilityLimiter(cfg *config.Config) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(cfg.Availability.RateLimit(), time.Minute)
 }
 
 func ProvideAuthHttpHandler(authService domainAuth.AuthService, logger *zap.Logger) *httpAuth.Handler {
 	return httpAuth.NewHandler(authService, logger)
 }
 
+func ProvideJWKSHttpHandler(keyManager domainAuth.KeyManager) *httpJWKS.Handler {
+	return httpJWKS.NewHandler(keyManager)
+}
+
+func ProvideAdminHttpHandler(roleService domainRBAC.RoleService, keyManager domainAuth.KeyManager, logger *zap.Logger) *httpAdmin.Handler {
+	return httpAdmin.NewHandler(roleService, keyManager, logger)
+}
+
+func ProvideAccountHttpHandler(adminService serviceAdmin.AdminService, ids idgen.Strategy, logger *zap.Logger) *httpAdmin.AccountHandler {
+	return httpAdmin.NewAccountHandler(adminService, ids, logger)
+}
+
+func ProvideFeatureFlagHttpHandler(flags *featureflag.Flags, ids idgen.Strategy, logger *zap.Logger) *httpAdmin.FeatureFlagHandler {
+	return httpAdmin.NewFeatureFlagHandler(flags, ids, logger)
+}
+
+func ProvideWebhookHttpHandler(webhooks serviceWebhook.Service, ids idgen.Strategy, logger *zap.Logger) *httpAdmin.WebhookHandler {
+	return httpAdmin.NewWebhookHandler(webhooks, ids, logger)
+}
+
+func ProvideSAMLHttpHandler(saml serviceSAML.Service, logger *zap.Logger) *httpAuth.SAMLHandler {
+	return httpAuth.NewSAMLHandler(saml, logger)
+}
+
+func ProvideSAMLProviderHttpHandler(saml serviceSAML.Service, logger *zap.Logger) *httpAdmin.SAMLProviderHandler {
+	return httpAdmin.NewSAMLProviderHandler(saml, logger)
+}
+
+func ProvideLoggingHttpHandler(levels *logging.Levels, logger *zap.Logger) *httpAdmin.LoggingHandler {
+	return httpAdmin.NewLoggingHandler(levels, logger)
+}
+
+func ProvideReadOnlyHttpHandler(sw *readonly.Switch, logger *zap.Logger) *httpAdmin.ReadOnlyHandler {
+	return httpAdmin.NewReadOnlyHandler(sw, logger)
+}
+
+func ProvideImportHttpHandler(importer serviceImport.Service, ids idgen.Strategy, cfg *config.Config, logger *zap.Logger) *httpAdmin.ImportHandler {
+	return httpAdmin.NewImportHandler(importer, ids, cfg.Import.MaxFileSize(), cfg.Import.SyncMax(), logger)
+}
+
+func ProvideExportHttpHandler(exporter serviceExport.Service, generator *serviceExport.Files, ids idgen.Strategy, logger *zap.Logger) *httpAdmin.ExportHandler {
+	return httpAdmin.NewExportHandler(exporter, generator, ids, logger)
+}
+
+func ProvideMessageHttpHandler(messageService serviceMessage.MessageService, userService serviceUser.UserService, ids idgen.Strategy, logger *zap.Logger) *httpMessage.Handler {
+	return httpMessage.NewHandler(messageService, userService, ids, logger)
+}
+
+func ProvideOrgHttpHandler(apiKeys serviceAPIKey.Service, userService serviceUser.UserService, adminService serviceAdmin.AdminService, ids idgen.Strategy, logger *zap.Logger) *httpOrg.Handler {
+	return httpOrg.NewHandler(apiKeys, userService, adminService, ids, logger)
+}
+
+func ProvideOrganizationHttpHandler(organizationService serviceOrganization.Service, ids idgen.Strategy, logger *zap.Logger) *httpOrganization.Handler {
+	return httpOrganization.NewHandler(organizationService, ids, logger)
+}
+
+func ProvideAccountCenterHttpHandler(userService serviceUser.UserService, adminService serviceAdmin.AdminService, authService domainAuth.AuthService, ids idgen.Strategy, logger *zap.Logger) *httpAccount.Handler {
+	return httpAccount.NewHandler(userService, adminService, adminService, adminService, adminService, authService, ids, logger)
+}
+
+func ProvideHealthHttpHandler(monitor *health.Monitor, breakers *breaker.Group, cfg *config.Config) *httpHealth.Handler {
+	return httpHealth.NewHandler(monitor, breakers, cfg)
+}
+
+// ProvideRealtimeHub hands the events on the bus to the WebSocket connections of this instance
+func ProvideRealtimeHub(bus *eventbus.Bus, logger *zap.Logger) *httpRealtime.Hub {
+	hub := httpRealtime.NewHub(logger)
+	bus.Subscribe(hub.Dispatch)
+	return hub
+}
+
+// ProvideAdminEventFeed hands the account activity on the bus to the admin dashboards streaming from this instance
+func ProvideAdminEventFeed(bus *eventbus.Bus, cfg *config.Config, logger *zap.Logger) *httpRealtime.Feed {
+	feed := httpRealtime.NewFeed(cfg.Realtime.History(), logger)
+	bus.Subscribe(feed.Dispatch)
+	return feed
+}
+
+func ProvideRealtimeHttpHandler(hub *httpRealtime.Hub, feed *httpRealtime.Feed, cfg *config.Config, ids idgen.Strategy, logger *zap.Logger) *httpRealtime.Handler {
+	return httpRealtime.NewHandler(hub, feed, cfg.Realtime, ids, logger)
+}
+
 // Provider functions for gRPC handlers
-func ProvideUserGrpcHandler(userService serviceUser.UserService, logger *zap.Logger) *grpcUser.Handler {
-	return grpcUser.NewHandler(userService, logger)
+func ProvideUserGrpcHandler(userService serviceUser.UserService, authService domainAuth.AuthService, adminService serviceAdmin.AdminService, ids idgen.Strategy, logger *zap.Logger) *grpcUser.Handler {
+	return grpcUser.NewHandler(userService, authService, adminService, ids, logger)
 }
 
-func ProvideAuthGrpcHandler(authService domainAuth.AuthService, logger *zap.Logger) *grpcAuth.Handler {
-	return grpcAuth.NewHandler(authService, logger)
+func ProvideAuthGrpcHandler(authService domainAuth.AuthService, userService serviceUser.UserService, ids idgen.Strategy, logger *zap.Logger) *grpcAuth.Handler {
+	return grpcAuth.NewHandler(authService, userService, ids, logger)
 }
 
 // Provider function for middleware
@@ -115,11 +1000,19 @@ func ProvideAuthMiddleware(authService domainAuth.AuthService, logger *zap.Logge
 }
 
 // Provider function for router
-func ProvideRouter(userHandler *httpUser.Handler, authHandler *httpAuth.Handler, authService domainAuth.AuthService, logger *zap.Logger) *gin.Engine {
-	return http.NewRouter(userHandler, authHandler, authService, logger)
+func ProvideRouter(userHandler *httpUser.Handler, availabilityHandler *httpUser.AvailabilityHandler, availabilityLimiter *middleware.RateLimiter, authHandler *httpAuth.Handler, adminHandler *httpAdmin.Handler, accountHandler *httpAdmin.AccountHandler, messageHandler *httpMessage.Handler, jwksHandler *httpJWKS.Handler, readOnlyHandler *httpAdmin.ReadOnlyHandler, importHandler *httpAdmin.ImportHandler, exportHandler *httpAdmin.ExportHandler, orgHandler *httpOrg.Handler, organizationHandler *httpOrganization.Handler, accountCenterHandler *httpAccount.Handler, healthHandler *httpHealth.Handler, realtimeHandler *httpRealtime.Handler, featureFlagHandler *httpAdmin.FeatureFlagHandler, loggingHandler *httpAdmin.LoggingHandler, webhookHandler *httpAdmin.WebhookHandler, samlHandler *httpAuth.SAMLHandler, samlProviderHandler *httpAdmin.SAMLProviderHandler, authService domainAuth.AuthService, userService serviceUser.UserService, apiKeys serviceAPIKey.Service, readOnlySwitch *readonly.Switch, auditRepo domainAudit.Repository, ids idgen.Generator, panics *recovery.Recorder, errorReporter errorreport.Reporter, registry *prometheus.Registry, cfg *config.Config, logger *zap.Logger) (*http.Routers, error) {
+	routers, err := http.NewRouter(userHandler, availabilityHandler, availabilityLimiter, authHandler, adminHandler, accountHandler, messageHandler, jwksHandler, readOnlyHandler, importHandler, exportHandler, orgHandler, organizationHandler, accountCenterHandler, healthHandler, realtimeHandler, featureFlagHandler, loggingHandler, webhookHandler, samlHandler, samlProviderHandler, authService, userService, apiKeys, readOnlySwitch, auditRepo, ids, panics, errorReporter, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	// The admin listener serves the metrics too, so one port carries the ops surface
+	if routers.Admin != nil {
+		routers.Admin.GET("/metrics", gin.WrapH(metrics.NewHandler(registry)))
+	}
+	return routers, nil
 }
 
 // ProvideHTTPServer creates a new HTTP server
-func ProvideHTTPServer(router *gin.Engine, cfg *config.Config) *http.Server {
-	return http.NewServer(router, cfg)
+func ProvideHTTPServer(routers *http.Routers, cfg *config.Config) *http.Server {
+	return http.NewServer(routers, cfg)
 }
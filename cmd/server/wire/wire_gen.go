@@ -7,23 +7,98 @@
 package wire
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	"github.com/yi-tech/go-user-service/internal/cache"
+	"github.com/yi-tech/go-user-service/internal/clientip"
 	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/domain/audit"
 	"github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/domain/compliance"
+	"github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/domain/message"
+	"github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/domain/saml"
 	user2 "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/eventbus"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/health"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+	"github.com/yi-tech/go-user-service/internal/logging"
+	"github.com/yi-tech/go-user-service/internal/metrics"
 	"github.com/yi-tech/go-user-service/internal/middleware"
+	"github.com/yi-tech/go-user-service/internal/password"
 	"github.com/yi-tech/go-user-service/internal/provider"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+	apikey2 "github.com/yi-tech/go-user-service/internal/repository/apikey"
+	audit2 "github.com/yi-tech/go-user-service/internal/repository/audit"
 	auth2 "github.com/yi-tech/go-user-service/internal/repository/auth"
+	featureflag2 "github.com/yi-tech/go-user-service/internal/repository/featureflag"
+	"github.com/yi-tech/go-user-service/internal/repository/impersonation"
+	"github.com/yi-tech/go-user-service/internal/repository/loginhistory"
+	message2 "github.com/yi-tech/go-user-service/internal/repository/message"
+	notification2 "github.com/yi-tech/go-user-service/internal/repository/notification"
+	organization2 "github.com/yi-tech/go-user-service/internal/repository/organization"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"github.com/yi-tech/go-user-service/internal/repository/retry"
+	saml2 "github.com/yi-tech/go-user-service/internal/repository/saml"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
 	user3 "github.com/yi-tech/go-user-service/internal/repository/user"
+	webhook2 "github.com/yi-tech/go-user-service/internal/repository/webhook"
+	admin2 "github.com/yi-tech/go-user-service/internal/service/admin"
+	apikey3 "github.com/yi-tech/go-user-service/internal/service/apikey"
 	auth3 "github.com/yi-tech/go-user-service/internal/service/auth"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	compliance2 "github.com/yi-tech/go-user-service/internal/service/compliance"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+	"github.com/yi-tech/go-user-service/internal/service/emailpolicy"
+	"github.com/yi-tech/go-user-service/internal/service/maintenance"
+	message3 "github.com/yi-tech/go-user-service/internal/service/message"
+	notification3 "github.com/yi-tech/go-user-service/internal/service/notification"
+	organization3 "github.com/yi-tech/go-user-service/internal/service/organization"
+	rbac2 "github.com/yi-tech/go-user-service/internal/service/rbac"
+	saml3 "github.com/yi-tech/go-user-service/internal/service/saml"
+	"github.com/yi-tech/go-user-service/internal/service/security"
+	"github.com/yi-tech/go-user-service/internal/service/seed"
 	"github.com/yi-tech/go-user-service/internal/service/user"
+	"github.com/yi-tech/go-user-service/internal/service/userexport"
+	"github.com/yi-tech/go-user-service/internal/service/userimport"
+	webhook3 "github.com/yi-tech/go-user-service/internal/service/webhook"
 	"github.com/yi-tech/go-user-service/internal/transport/grpc"
 	auth5 "github.com/yi-tech/go-user-service/internal/transport/grpc/auth"
+	"github.com/yi-tech/go-user-service/internal/transport/grpc/interceptor"
 	user5 "github.com/yi-tech/go-user-service/internal/transport/grpc/user"
 	"github.com/yi-tech/go-user-service/internal/transport/http"
+	"github.com/yi-tech/go-user-service/internal/transport/http/account"
+	"github.com/yi-tech/go-user-service/internal/transport/http/admin"
 	auth4 "github.com/yi-tech/go-user-service/internal/transport/http/auth"
+	health2 "github.com/yi-tech/go-user-service/internal/transport/http/health"
+	"github.com/yi-tech/go-user-service/internal/transport/http/jwks"
+	message4 "github.com/yi-tech/go-user-service/internal/transport/http/message"
+	"github.com/yi-tech/go-user-service/internal/transport/http/org"
+	organization4 "github.com/yi-tech/go-user-service/internal/transport/http/organization"
+	"github.com/yi-tech/go-user-service/internal/transport/http/realtime"
 	user4 "github.com/yi-tech/go-user-service/internal/transport/http/user"
+	"github.com/yi-tech/go-user-service/internal/tx"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -31,101 +106,1050 @@ import (
 // Injectors from wire.go:
 
 // InitializeApp creates the application dependencies.
-func InitializeApp() (*App, error) {
-	config, err := provider.ProvideConfig()
+func InitializeApp(opts config.Options) (*App, error) {
+	config, err := provider.ProvideConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	levels := provider.ProvideLogLevels(config)
+	logger, err := provider.ProvideLogger(config, levels)
+	if err != nil {
+		return nil, err
+	}
+	db, err := provider.ProvideDatabase(config, logger)
 	if err != nil {
 		return nil, err
 	}
-	db, err := provider.ProvideDatabase(config)
+	pool, err := provider.ProvideReadReplicas(config, logger)
 	if err != nil {
 		return nil, err
 	}
-	repository := ProvideUserRepository(db)
-	userService := ProvideUserService(repository)
-	logger, err := provider.ProvideLogger(config)
+	registry := ProvideMetricsRegistry()
+	cacheMetrics, err := ProvideCacheMetrics(registry)
 	if err != nil {
 		return nil, err
 	}
-	handler := ProvideUserHttpHandler(userService, logger)
 	client, err := provider.ProvideRedisClient(config)
 	if err != nil {
 		return nil, err
 	}
-	authRepository := ProvideAuthRepository(client)
-	authService := ProvideAuthService(userService, authRepository, config)
+	schema, err := ProvideRedisKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	group, err := ProvideCircuitBreakers(config, registry, logger)
+	if err != nil {
+		return nil, err
+	}
+	repository := ProvideUserRepository(db, pool, group, client, schema, cacheMetrics, config)
+	normalizationRepository := ProvideNormalizationRepository(db)
+	strategy, err := ProvideIDStrategy(config)
+	if err != nil {
+		return nil, err
+	}
+	generator := ProvideIDGenerator(strategy)
+	residencyPolicy, err := ProvideResidencyPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	emailPolicy, err := ProvideEmailPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	deadLetterRepository := ProvideDeadLetterRepository(db)
+	service := ProvideNotificationService(deadLetterRepository, config, generator, logger)
+	broker := ProvideJobBroker(client, schema, config)
+	queue := ProvideJobQueue(broker, generator, config)
+	notifier := ProvideNotifier(service, queue, config, logger)
+	bus := ProvideEventBus(client, schema, generator, logger)
+	publisher := ProvideEventPublisher(bus, queue, generator, config, logger)
+	auditRepository := ProvideAuditRepository(db)
+	securityLog := ProvideSecurityLog(auditRepository, generator, logger)
+	txManager := ProvideTxManager(db)
+	userService, err := ProvideUserService(repository, normalizationRepository, generator, residencyPolicy, notifier, publisher, securityLog, emailPolicy, txManager, config)
+	if err != nil {
+		return nil, err
+	}
+	handler := ProvideUserHttpHandler(userService, strategy, logger)
+	availabilityChecker := ProvideAvailabilityChecker(repository, config)
+	verifier, err := ProvideCaptchaVerifier(config)
+	if err != nil {
+		return nil, err
+	}
+	availabilityHandler := ProvideAvailabilityHttpHandler(availabilityChecker, verifier, logger)
+	rateLimiter := ProvideAvailabilityLimiter(config)
+	store, err := ProvideAuthStore(client, schema, db, group, config)
+	if err != nil {
+		return nil, err
+	}
+	authRepository := ProvideAuthRepository(store)
+	signingKeyRepository := ProvideSigningKeyRepository(store)
+	keyRing, err := ProvideKeyRing(config, signingKeyRepository, logger)
+	if err != nil {
+		return nil, err
+	}
+	sessionRepository := ProvideSessionRepository(store)
+	loginAttemptRepository := ProvideLoginAttemptRepository(client, schema)
+	loginHistoryRepository := ProvideLoginHistoryRepository(db)
+	impersonationRepository := ProvideImpersonationRepository(db)
+	authMetrics, err := ProvideAuthMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	authService, err := ProvideAuthService(userService, authRepository, sessionRepository, loginAttemptRepository, loginHistoryRepository, impersonationRepository, publisher, securityLog, notifier, config, keyRing, cacheMetrics, authMetrics, logger)
+	if err != nil {
+		return nil, err
+	}
 	authHandler := ProvideAuthHttpHandler(authService, logger)
-	engine := ProvideRouter(handler, authHandler, authService, logger)
-	server := ProvideHTTPServer(engine, config)
+	roleService := ProvideRoleService()
+	keyManager := ProvideKeyManager(keyRing)
+	adminHandler := ProvideAdminHttpHandler(roleService, keyManager, logger)
+	keyInspector := ProvideKeyInspector(client, schema)
+	adminService := ProvideAdminService(repository, sessionRepository, keyInspector, authService, auditRepository, loginHistoryRepository, notifier, publisher, txManager, generator, config)
+	accountHandler := ProvideAccountHttpHandler(adminService, strategy, logger)
+	messageRepository := ProvideMessageRepository(db)
+	messageService := ProvideMessageService(messageRepository, generator)
+	messageHandler := ProvideMessageHttpHandler(messageService, userService, strategy, logger)
+	jwksHandler := ProvideJWKSHttpHandler(keyManager)
+	readOnlySwitch := ProvideReadOnlySwitch(config)
+	readOnlyHandler := ProvideReadOnlyHttpHandler(readOnlySwitch, logger)
+	store2 := ProvideFeatureFlagStore(db)
+	flags := ProvideFeatureFlags(store2, config, logger)
+	featureFlagHandler := ProvideFeatureFlagHttpHandler(flags, strategy, logger)
+	loggingHandler := ProvideLoggingHttpHandler(levels, logger)
+	webhookRepository := ProvideWebhookRepository(db)
+	deliveryRepository := ProvideWebhookDeliveryRepository(db)
+	webhookService := ProvideWebhookService(webhookRepository, deliveryRepository, generator)
+	webhookHandler := ProvideWebhookHttpHandler(webhookService, strategy, logger)
+	samlRepository := ProvideSAMLRepository(db)
+	assertionCache := ProvideSAMLAssertionCache(client, schema)
+	samlService := ProvideSAMLService(samlRepository, assertionCache, userService, authService, publisher, emailPolicy, config, logger)
+	samlHandler := ProvideSAMLHttpHandler(samlService, logger)
+	samlProviderHandler := ProvideSAMLProviderHttpHandler(samlService, logger)
+	userimportService := ProvideImportService(userService, auditRepository, generator, config, logger)
+	importHandler := ProvideImportHttpHandler(userimportService, strategy, config, logger)
+	userexportService := ProvideExportService(repository, residencyPolicy, auditRepository, generator, strategy, config, logger)
+	files := ProvideExportGenerator(userexportService, queue, config, logger)
+	exportHandler := ProvideExportHttpHandler(userexportService, files, strategy, logger)
+	apikeyRepository := ProvideAPIKeyRepository(db)
+	service2 := ProvideAPIKeyService(apikeyRepository, generator, config, logger)
+	orgHandler := ProvideOrgHttpHandler(service2, userService, adminService, strategy, logger)
+	organizationRepository := ProvideOrganizationRepository(db)
+	service3 := ProvideOrganizationService(organizationRepository, userService, generator, logger, config)
+	organizationHandler := ProvideOrganizationHttpHandler(service3, strategy, logger)
+	handler2 := ProvideAccountCenterHttpHandler(userService, adminService, authService, strategy, logger)
+	monitor, err := ProvideHealthMonitor(db, client, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	watcher := ProvideConfigWatcher(config, levels, rateLimiter, logger)
+	handler3 := ProvideHealthHttpHandler(monitor, group, config)
+	hub := ProvideRealtimeHub(bus, logger)
+	feed := ProvideAdminEventFeed(bus, config, logger)
+	handler4 := ProvideRealtimeHttpHandler(hub, feed, config, strategy, logger)
+	reporter, err := ProvideErrorReporter(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	recorder, err := ProvidePanicRecorder(registry, reporter, logger)
+	if err != nil {
+		return nil, err
+	}
+	routers, err := ProvideRouter(handler, availabilityHandler, rateLimiter, authHandler, adminHandler, accountHandler, messageHandler, jwksHandler, readOnlyHandler, importHandler, exportHandler, orgHandler, organizationHandler, handler2, handler3, handler4, featureFlagHandler, loggingHandler, webhookHandler, samlHandler, samlProviderHandler, authService, userService, service2, readOnlySwitch, auditRepository, generator, recorder, reporter, registry, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	server := ProvideHTTPServer(routers, config)
 	grpcConfig := ProvideGRPCConfig(config)
-	grpcServer := ProvideGRPCServer(userService, authService, logger, grpcConfig)
+	compressor := ProvideCompressor(config)
+	grpcServer, err := ProvideGRPCServer(userService, authService, adminService, service3, strategy, logger, grpcConfig, registry, readOnlySwitch, compressor, recorder, reporter, config)
+	if err != nil {
+		return nil, err
+	}
+	metricsServer := ProvideMetricsServer(config, registry)
+	loginHistoryPruner := ProvideLoginHistoryPruner(loginHistoryRepository, config, logger)
 	app := &App{
-		HTTPServer: server,
-		GRPCServer: grpcServer,
-		DB:         db,
-		Config:     config,
-		Logger:     logger,
+		HTTPServer:         server,
+		GRPCServer:         grpcServer,
+		MetricsServer:      metricsServer,
+		HealthMonitor:      monitor,
+		LoginHistoryPruner: loginHistoryPruner,
+		Notifications:      service,
+		Events:             bus,
+		ConfigWatcher:      watcher,
+		DB:                 db,
+		Config:             config,
+		Logger:             logger,
 	}
 	return app, nil
 }
 
+// InitializeWorker creates the worker dependencies from the same providers as the app.
+func InitializeWorker(opts config.Options) (*WorkerApp, error) {
+	config, err := provider.ProvideConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	levels := provider.ProvideLogLevels(config)
+	logger, err := provider.ProvideLogger(config, levels)
+	if err != nil {
+		return nil, err
+	}
+	client, err := provider.ProvideRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ProvideRedisKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	broker := ProvideJobBroker(client, schema, config)
+	db, err := provider.ProvideDatabase(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	deadLetterRepository := ProvideDeadLetterRepository(db)
+	strategy, err := ProvideIDStrategy(config)
+	if err != nil {
+		return nil, err
+	}
+	generator := ProvideIDGenerator(strategy)
+	service := ProvideNotificationService(deadLetterRepository, config, generator, logger)
+	pool, err := provider.ProvideReadReplicas(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	registry := ProvideMetricsRegistry()
+	cacheMetrics, err := ProvideCacheMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	group, err := ProvideCircuitBreakers(config, registry, logger)
+	if err != nil {
+		return nil, err
+	}
+	repository := ProvideUserRepository(db, pool, group, client, schema, cacheMetrics, config)
+	residencyPolicy, err := ProvideResidencyPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	auditRepository := ProvideAuditRepository(db)
+	userexportService := ProvideExportService(repository, residencyPolicy, auditRepository, generator, strategy, config, logger)
+	queue := ProvideJobQueue(broker, generator, config)
+	files := ProvideExportGenerator(userexportService, queue, config, logger)
+	store, err := ProvideAuthStore(client, schema, db, group, config)
+	if err != nil {
+		return nil, err
+	}
+	sessionRepository := ProvideSessionRepository(store)
+	authRepository := ProvideAuthRepository(store)
+	maintenanceMetrics, err := ProvideMaintenanceMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	tasks := ProvideMaintenanceTasks(sessionRepository, authRepository, auditRepository, maintenanceMetrics, config, logger)
+	webhookRepository := ProvideWebhookRepository(db)
+	deliveryRepository := ProvideWebhookDeliveryRepository(db)
+	dispatcher := ProvideWebhookDispatcher(webhookRepository, deliveryRepository, queue, generator, config, logger)
+	worker := ProvideJobWorker(broker, service, files, tasks, dispatcher, config, logger)
+	scheduler := ProvideJobScheduler(queue, client, schema, tasks, config, logger)
+	metricsServer := ProvideWorkerMetricsServer(config, registry)
+	workerApp := &WorkerApp{
+		Worker:        worker,
+		Queue:         queue,
+		Scheduler:     scheduler,
+		MetricsServer: metricsServer,
+		DB:            db,
+		Config:        config,
+		Logger:        logger,
+	}
+	return workerApp, nil
+}
+
+// InitializeSeeder creates the fixture loader from the same providers as the app.
+func InitializeSeeder(opts config.Options) (*SeedApp, error) {
+	config, err := provider.ProvideConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	levels := provider.ProvideLogLevels(config)
+	logger, err := provider.ProvideLogger(config, levels)
+	if err != nil {
+		return nil, err
+	}
+	db, err := provider.ProvideDatabase(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := provider.ProvideReadReplicas(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	client, err := provider.ProvideRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ProvideRedisKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	registry := ProvideMetricsRegistry()
+	cacheMetrics, err := ProvideCacheMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	group, err := ProvideCircuitBreakers(config, registry, logger)
+	if err != nil {
+		return nil, err
+	}
+	repository := ProvideUserRepository(db, pool, group, client, schema, cacheMetrics, config)
+	normalizationRepository := ProvideNormalizationRepository(db)
+	strategy, err := ProvideIDStrategy(config)
+	if err != nil {
+		return nil, err
+	}
+	generator := ProvideIDGenerator(strategy)
+	residencyPolicy, err := ProvideResidencyPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	emailPolicy, err := ProvideEmailPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	deadLetterRepository := ProvideDeadLetterRepository(db)
+	service := ProvideNotificationService(deadLetterRepository, config, generator, logger)
+	broker := ProvideJobBroker(client, schema, config)
+	queue := ProvideJobQueue(broker, generator, config)
+	notifier := ProvideNotifier(service, queue, config, logger)
+	bus := ProvideEventBus(client, schema, generator, logger)
+	publisher := ProvideEventPublisher(bus, queue, generator, config, logger)
+	auditRepository := ProvideAuditRepository(db)
+	securityLog := ProvideSecurityLog(auditRepository, generator, logger)
+	txManager := ProvideTxManager(db)
+	userService, err := ProvideUserService(repository, normalizationRepository, generator, residencyPolicy, notifier, publisher, securityLog, emailPolicy, txManager, config)
+	if err != nil {
+		return nil, err
+	}
+	organizationRepository := ProvideOrganizationRepository(db)
+	service2 := ProvideOrganizationService(organizationRepository, userService, generator, logger, config)
+	loader := ProvideSeedLoader(userService, service2, logger)
+	seedApp := &SeedApp{
+		Loader: loader,
+		DB:     db,
+		Config: config,
+		Logger: logger,
+	}
+	return seedApp, nil
+}
+
 // wire.go:
 
 // ProvideGRPCConfig provides the gRPC server configuration
 func ProvideGRPCConfig(cfg *config.Config) *grpc.Config {
 	return &grpc.Config{
-		GRPCPort: cfg.GRPC.Port,
-		HTTPPort: cfg.GRPC.Port + 1,
+		GRPCPort:          cfg.GRPC.Port,
+		HTTPPort:          cfg.GRPC.Port + 1,
+		Reflection:        cfg.GRPC.Reflection,
+		MaxRecvMsgSize:    cfg.GRPC.MaxRecvMsgSizeBytes,
+		MaxSendMsgSize:    cfg.GRPC.MaxSendMsgSizeBytes,
+		ConnectionTimeout: seconds(cfg.GRPC.ConnectionTimeoutSeconds),
+		Keepalive: grpc.KeepaliveConfig{
+			Time:                  seconds(cfg.GRPC.Keepalive.TimeSeconds),
+			Timeout:               seconds(cfg.GRPC.Keepalive.TimeoutSeconds),
+			MinTime:               seconds(cfg.GRPC.Keepalive.MinTimeSeconds),
+			PermitWithoutStream:   cfg.GRPC.Keepalive.PermitWithoutStream,
+			MaxConnectionIdle:     seconds(cfg.GRPC.Keepalive.MaxConnectionIdleSeconds),
+			MaxConnectionAge:      seconds(cfg.GRPC.Keepalive.MaxConnectionAgeSeconds),
+			MaxConnectionAgeGrace: seconds(cfg.GRPC.Keepalive.MaxConnectionAgeGraceSeconds),
+		},
+		MaxInFlight:     cfg.GRPC.MaxInFlight,
+		AuthMaxInFlight: cfg.GRPC.AuthMaxInFlight,
 	}
 }
 
+// seconds converts a config value in seconds to a time.Duration
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
 // ProvideGRPCServer creates a new gRPC server
-func ProvideGRPCServer(userService user.UserService, authService auth.AuthService, logger *zap.Logger, cfg *grpc.Config) *grpc.Server {
-	return grpc.NewServer(userService, authService, logger, cfg)
+func ProvideGRPCServer(userService user.UserService, authService auth.AuthService, adminService admin2.AdminService, organizationService organization3.Service, ids idgen.Strategy, logger *zap.Logger, cfg *grpc.Config, registry *prometheus.Registry, readOnlySwitch *readonly.Switch, compressor *middleware.Compressor, panics *recovery.Recorder, errorReporter errorreport.Reporter, settings *config.Config) (*grpc.Server, error) {
+	metricsInterceptor, err := interceptor.NewMetricsInterceptor(registry)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.Option{
+		grpc.WithRecovery(panics),
+		grpc.WithIDFormat(ids),
+		grpc.WithReadOnly(readOnlySwitch),
+		grpc.WithSettings(settings),
+		grpc.WithUnaryInterceptors(metricsInterceptor.Unary()),
+		grpc.WithStreamInterceptors(metricsInterceptor.Stream()),
+	}
+	if errorReporter != nil {
+		opts = append(opts, grpc.WithErrorReporting(errorReporter))
+	}
+	if compressor != nil {
+		opts = append(opts, grpc.WithGatewayMiddleware(compressor.Handler))
+	}
+	// The load balancers in front of the gRPC port are trusted like those of the REST API
+	resolver, err := clientip.NewResolver(append(slices.Clone(settings.App.TrustedProxies), grpc.GatewayProxies...), settings.App.ClientIPHeaders)
+	if err != nil {
+		return nil, err
+	}
+	global, groups, err := ipfilter.FromConfig(settings.IPFilter)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithClientIPResolver(resolver), grpc.WithIPFilter(global, groups))
+	return grpc.NewServer(userService, authService, adminService, organizationService, logger.Named(logging.GRPC), cfg, opts...), nil
+}
+
+// ProvideCompressor creates the response compressor of the HTTP API and the
+// gRPC gateway, or nil when compression is disabled
+func ProvideCompressor(cfg *config.Config) *middleware.Compressor {
+	if !cfg.Compression.Enabled {
+		return nil
+	}
+	return middleware.NewCompressor(cfg.Compression.MinSize(), cfg.Compression.Types())
+}
+
+// ProvideErrorReporter creates the reporter of internal errors and panics,
+// or nil when no error tracker is configured
+func ProvideErrorReporter(cfg *config.Config, logger *zap.Logger) (errorreport.Reporter, error) {
+	if cfg.Errors.SentryDSN == "" {
+		return nil, nil
+	}
+	return errorreport.NewSentryReporter(cfg.Errors.SentryDSN, cfg.App.Env, logger)
+}
+
+// ProvidePanicRecorder creates the recorder of panics recovered from HTTP
+// handlers and gRPC methods
+func ProvidePanicRecorder(registry *prometheus.Registry, reporter errorreport.Reporter, logger *zap.Logger) (*recovery.Recorder, error) {
+	return recovery.NewRecorder(logger, registry, reporter)
+}
+
+// ProvideMetricsRegistry creates the registry shared by HTTP and gRPC metrics
+func ProvideMetricsRegistry() *prometheus.Registry {
+	return metrics.NewRegistry()
+}
+
+// ProvideCacheMetrics registers the metrics shared by the in-process caches
+func ProvideCacheMetrics(registry *prometheus.Registry) (*cache.Metrics, error) {
+	return cache.NewMetrics(registry)
+}
+
+// ProvideCircuitBreakers creates the circuit breakers around Redis and the
+// database, exporting their state with the registry
+func ProvideCircuitBreakers(cfg *config.Config, registry *prometheus.Registry, logger *zap.Logger) (*breaker.Group, error) {
+	breakerMetrics, err := breaker.NewMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+	opts := breaker.Options{FailureThreshold: cfg.Breaker.Threshold(), OpenTimeout: cfg.Breaker.OpenTimeout()}
+	return breaker.NewGroup(cfg.Breaker.Enabled, opts, breakerMetrics, logger), nil
+}
+
+// cacheConfig converts configured cache limits
+func cacheConfig(limits config.CacheLimits) cache.Config {
+	return cache.Config{MaxEntries: limits.MaxEntries, TTL: limits.TTL(), Jitter: limits.Jitter}
+}
+
+// ProvideMetricsServer creates the internal metrics listener, or nil when it is disabled
+func ProvideMetricsServer(cfg *config.Config, registry *prometheus.Registry) *metrics.Server {
+	if cfg.Metrics.Port == 0 {
+		return nil
+	}
+	return metrics.NewServer(fmt.Sprintf(":%d", cfg.Metrics.Port), registry)
+}
+
+// ProvideHealthMonitor probes the database and Redis for the health details endpoint
+func ProvideHealthMonitor(db *gorm.DB, redis2 *redis.Client, cfg *config.Config, logger *zap.Logger) (*health.Monitor, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	opts := health.Options{
+		Interval:      cfg.Health.CheckInterval(),
+		Timeout:       cfg.Health.Timeout(),
+		SlowThreshold: cfg.Health.SlowThreshold(),
+		Debounce:      cfg.Health.DebounceChecks(),
+	}
+	return health.NewMonitor(opts, logger,
+		health.Check{Name: "database", Probe: sqlDB.PingContext},
+		health.Check{Name: "redis", Probe: func(ctx context.Context) error { return redis2.Ping(ctx).Err() }},
+	), nil
+}
+
+// ProvideConfigWatcher applies the log levels and rate limits of a changed
+// config file without a restart
+func ProvideConfigWatcher(cfg *config.Config, levels *logging.Levels, availabilityLimiter *middleware.RateLimiter, logger *zap.Logger) *config.Watcher {
+	watcher := config.NewWatcher(cfg, logger)
+	watcher.OnChange(func(updated *config.Config) {
+		levels.Apply(updated.LogLevel(), updated.LogModuleLevels())
+		availabilityLimiter.SetLimit(updated.Availability.RateLimit())
+	})
+	return watcher
 }
 
 // App represents the main application structure.
 type App struct {
-	HTTPServer *http.Server // HTTP server (Gin) instance
-	GRPCServer *grpc.Server // gRPC server instance
-	DB         *gorm.DB
-	Config     *config.Config
-	Logger     *zap.Logger
+	HTTPServer    *http.Server    // HTTP server (Gin) instance
+	GRPCServer    *grpc.Server    // gRPC server instance
+	MetricsServer *metrics.Server // Internal metrics listener; nil when disabled
+	HealthMonitor *health.Monitor // Probes the dependencies until the app shuts down
+	// LoginHistoryPruner deletes expired sign-in history until the app shuts down
+	LoginHistoryPruner *auth3.LoginHistoryPruner
+	Notifications      *notification3.Service // Sends queued notifications until the app shuts down
+	Events             *eventbus.Bus          // Relays user events between instances until the app shuts down
+	ConfigWatcher      *config.Watcher        // Applies config file changes that need no restart until the app shuts down
+	DB                 *gorm.DB
+	Config             *config.Config
+	Logger             *zap.Logger
+}
+
+// WorkerApp represents the background job worker run by cmd/worker.
+type WorkerApp struct {
+	Worker        *jobs.Worker    // Runs queued jobs until the worker shuts down
+	Queue         *jobs.Queue     // Enqueues jobs from runbooks
+	Scheduler     *jobs.Scheduler // Enqueues the housekeeping jobs at their intervals
+	MetricsServer *metrics.Server // Worker metrics listener; nil when disabled
+	DB            *gorm.DB
+	Config        *config.Config
+	Logger        *zap.Logger
+}
+
+// SeedApp loads development fixtures for cmd/seed.
+type SeedApp struct {
+	Loader *seed.Loader // Stores fixtures through the user and organization services
+	DB     *gorm.DB
+	Config *config.Config
+	Logger *zap.Logger
 }
 
 // Provider functions for repositories
-func ProvideUserRepository(db *gorm.DB) user2.Repository {
-	return user3.NewUserRepository(db)
+
+// ProvideUserRepository reads users through the in-process cache, then the
+// Redis cache, then the database; either cache may be disabled. Database
+// lookups and listings go to the read replicas when any are configured and
+// are retried on transient errors, behind the database circuit breaker.
+func ProvideUserRepository(db *gorm.DB, replicas *replica.Pool, breakers *breaker.Group, redis2 *redis.Client, keys rediskey.Schema, cacheMetrics *cache.Metrics, cfg *config.Config) user2.Repository {
+	stored := user3.NewRetryRepository(user3.NewUserRepository(db, replicas), retryPolicy(cfg.Database.Retry.Reads), retryPolicy(cfg.Database.Retry.Writes))
+	repo := user3.NewRedisCachedRepository(user3.NewBreakerRepository(stored, breakers.Breaker("database")), redis2, keys, cfg.Cache.Redis.TTL(), cacheMetrics)
+	return user3.NewCachedRepository(repo, cache.New[uuid.UUID, user2.User]("users", cacheConfig(cfg.Cache.Users), cacheMetrics))
+}
+
+// ProvideNormalizationRepository records which email normalizations stored
+// addresses were rewritten with
+func ProvideNormalizationRepository(db *gorm.DB) user2.NormalizationRepository {
+	return user3.NewNormalizationRepository(db)
+}
+
+// ProvideRedisKeys builds the Redis key schema of this deployment
+func ProvideRedisKeys(cfg *config.Config) (rediskey.Schema, error) {
+	return rediskey.New(cfg.Redis.KeyPrefix)
+}
+
+// ProvideAuthStore opens the refresh token and session store selected by auth_store.driver
+func ProvideAuthStore(redis2 *redis.Client, keys rediskey.Schema, db *gorm.DB, breakers *breaker.Group, cfg *config.Config) (auth2.Store, error) {
+	return auth2.OpenStore(cfg.AuthStore.Driver, auth2.Backends{
+		Redis: redis2,
+		Keys:  keys,
+		Retry: redisRetryPolicy(cfg.Redis.Failover, breakers.Breaker("redis")),
+		DB:    db,
+	})
+}
+
+func ProvideAuthRepository(store auth2.Store) auth.AuthRepository {
+	return store.Tokens
+}
+
+func ProvideSessionRepository(store auth2.Store) auth.SessionRepository {
+	return store.Sessions
+}
+
+func ProvideSigningKeyRepository(store auth2.Store) auth.SigningKeyRepository {
+	return store.SigningKeys
+}
+
+// retryPolicy converts the configured retries of a kind of repository call
+func retryPolicy(cfg config.RetryConfig) retry.Policy {
+	return retry.Policy{Attempts: cfg.Attempts, Backoff: cfg.Backoff(), MaxBackoff: cfg.MaxBackoff()}
+}
+
+// redisRetryPolicy retries token and session commands while Redis fails
+// over, failing them straight away while the Redis circuit breaker is open
+func redisRetryPolicy(cfg config.RedisFailoverConfig, b *breaker.Breaker) auth2.RetryPolicy {
+	return auth2.RetryPolicy{Attempts: cfg.Attempts(), Backoff: cfg.Backoff(), Breaker: b}
+}
+
+func ProvideLoginAttemptRepository(redis2 *redis.Client, keys rediskey.Schema) auth.LoginAttemptRepository {
+	return auth2.NewLoginAttemptRepository(redis2, keys)
+}
+
+func ProvideKeyInspector(redis2 *redis.Client, keys rediskey.Schema) auth.KeyInspector {
+	return auth2.NewKeyInspector(redis2, keys)
+}
+
+func ProvideAuditRepository(db *gorm.DB) audit.Repository {
+	return audit2.NewAuditRepository(db)
+}
+
+func ProvideLoginHistoryRepository(db *gorm.DB) auth.LoginHistoryRepository {
+	return loginhistory.NewLoginHistoryRepository(db)
+}
+
+func ProvideImpersonationRepository(db *gorm.DB) auth.ImpersonationRepository {
+	return impersonation.NewImpersonationRepository(db)
+}
+
+// ProvideLoginHistoryPruner deletes sign-in history older than login.history.retention_days
+func ProvideLoginHistoryPruner(history auth.LoginHistoryRepository, cfg *config.Config, logger *zap.Logger) *auth3.LoginHistoryPruner {
+	return auth3.NewLoginHistoryPruner(history, cfg.Login.History.Retention(), cfg.Login.History.PruneInterval(), logger)
+}
+
+func ProvideDeadLetterRepository(db *gorm.DB) notification.DeadLetterRepository {
+	return notification2.NewDeadLetterRepository(db)
+}
+
+func ProvideMessageRepository(db *gorm.DB) message.Repository {
+	return message2.NewMessageRepository(db)
+}
+
+func ProvideWebhookRepository(db *gorm.DB) webhook.Repository {
+	return webhook2.NewWebhookRepository(db)
+}
+
+func ProvideWebhookDeliveryRepository(db *gorm.DB) webhook.DeliveryRepository {
+	return webhook2.NewDeliveryRepository(db)
+}
+
+func ProvideSAMLRepository(db *gorm.DB) saml.Repository {
+	return saml2.NewSAMLRepository(db)
+}
+
+// ProvideSAMLAssertionCache remembers the assertions users signed in with
+// until they expire, so that none is accepted twice
+func ProvideSAMLAssertionCache(redis *redis.Client, keys rediskey.Schema) saml.AssertionCache {
+	return saml2.NewAssertionCache(redis, keys)
+}
+
+func ProvideAPIKeyRepository(db *gorm.DB) apikey.Repository {
+	return apikey2.NewAPIKeyRepository(db)
+}
+
+func ProvideOrganizationRepository(db *gorm.DB) organization.Repository {
+	return organization2.NewOrganizationRepository(db)
+}
+
+func ProvideFeatureFlagStore(db *gorm.DB) featureflag.Store {
+	return featureflag2.NewFlagRepository(db)
+}
+
+// ProvideFeatureFlags evaluates feature flags: values set through the admin API over the defaults configured for this environment
+func ProvideFeatureFlags(store featureflag.Store, cfg *config.Config, logger *zap.Logger) *featureflag.Flags {
+	return featureflag.New(store, cfg.FeatureFlags.Defaults, cfg.FeatureFlags.Refresh(), logger)
 }
 
-func ProvideAuthRepository(redis2 *redis.Client) auth.AuthRepository {
-	return auth2.NewAuthRepository(redis2)
+// ProvideTxManager shares one unit-of-work manager among the services
+func ProvideTxManager(db *gorm.DB) tx.Manager {
+	return transaction.NewManager(db)
 }
 
 // Provider functions for services
-func ProvideUserService(repo user2.Repository) user.UserService {
-	return user.NewUserService(repo)
+// ProvideNotificationService sends notifications through the providers
+// enabled under notification in the configuration
+func ProvideNotificationService(deadLetters notification.DeadLetterRepository, cfg *config.Config, ids idgen.Generator, logger *zap.Logger) *notification3.Service {
+	return notification3.NewService(notification3.NewProviders(cfg.Notification), deadLetters, cfg.Notification, ids, logger)
+}
+
+// ProvideNotifier lets the services send notifications through the
+// notification service, or through cmd/worker when jobs.deliver_notifications is set
+func ProvideNotifier(notifications *notification3.Service, queue *jobs.Queue, cfg *config.Config, logger *zap.Logger) notification.Notifier {
+	if cfg.Jobs.DeliverNotifications {
+		return notification3.NewJobNotifier(queue, notification3.NewProviders(cfg.Notification), logger)
+	}
+	return notifications
+}
+
+// ProvideSecurityLog records the security events of accounts in the audit log
+func ProvideSecurityLog(auditRepo audit.Repository, ids idgen.Generator, logger *zap.Logger) audit.SecurityLog {
+	return security.NewLog(auditRepo, ids, logger)
+}
+
+// ProvideEventBus relays the events of users between instances through Redis Pub/Sub
+func ProvideEventBus(redis2 *redis.Client, keys rediskey.Schema, ids idgen.Generator, logger *zap.Logger) *eventbus.Bus {
+	return eventbus.NewBus(redis2, keys, ids, logger)
+}
+
+// ProvideEventPublisher publishes the events of users on the bus, also
+// dispatching user lifecycle events to webhooks when webhooks.enabled is set
+func ProvideEventPublisher(bus *eventbus.Bus, queue *jobs.Queue, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) event.Publisher {
+	if !cfg.Webhooks.Enabled {
+		return bus
+	}
+	return webhook3.NewPublisher(bus, queue, ids, logger)
+}
+
+// ProvideJobBroker keeps the background jobs of jobs.queue in Redis
+func ProvideJobBroker(redis2 *redis.Client, keys rediskey.Schema, cfg *config.Config) jobs.Broker {
+	return jobs.NewRedisBroker(redis2, keys, cfg.Jobs.QueueName())
+}
+
+func ProvideJobQueue(broker jobs.Broker, ids idgen.Generator, cfg *config.Config) *jobs.Queue {
+	return jobs.NewQueue(broker, ids, cfg.Jobs.Attempts())
+}
+
+// ProvideAuthMetrics registers the counters of failed best-effort sign-in
+// and sign-out steps
+func ProvideAuthMetrics(registry *prometheus.Registry) (*auth3.Metrics, error) {
+	return auth3.NewMetrics(registry)
+}
+
+// ProvideMaintenanceMetrics registers the counters of rows the housekeeping jobs clean
+func ProvideMaintenanceMetrics(registry *prometheus.Registry) (*maintenance.Metrics, error) {
+	return maintenance.NewMetrics(registry)
+}
+
+// ProvideMaintenanceTasks creates the housekeeping jobs, keeping the audit log
+// for audit.retention_days
+func ProvideMaintenanceTasks(sessions auth.SessionRepository, tokens auth.AuthRepository, auditRepo audit.Repository, maintenanceMetrics *maintenance.Metrics, cfg *config.Config, logger *zap.Logger) *maintenance.Tasks {
+	return maintenance.NewTasks(sessions, tokens, auditRepo, cfg.Audit.Retention(), maintenanceMetrics, logger)
 }
 
-func ProvideAuthService(userService user.UserService, authRepo auth.AuthRepository, cfg *config.Config) auth.AuthService {
-	return auth3.NewService(userService, authRepo, cfg)
+// ProvideJobScheduler enqueues the housekeeping jobs at the intervals of
+// jobs.schedule; Redis elects a single scheduler across the workers
+func ProvideJobScheduler(queue *jobs.Queue, redis2 *redis.Client, keys rediskey.Schema, tasks *maintenance.Tasks, cfg *config.Config, logger *zap.Logger) *jobs.Scheduler {
+	s := jobs.NewScheduler(queue, jobs.NewRedisClaimer(redis2, keys), logger)
+	tasks.Schedule(s, cfg.Jobs.Schedule)
+	return s
+}
+
+// ProvideWorkerMetricsServer serves the worker metrics on jobs.metrics_port, or
+// returns nil when it is disabled
+func ProvideWorkerMetricsServer(cfg *config.Config, registry *prometheus.Registry) *metrics.Server {
+	if cfg.Jobs.MetricsPort <= 0 {
+		return nil
+	}
+	return metrics.NewServer(fmt.Sprintf(":%d", cfg.Jobs.MetricsPort), registry)
+}
+
+// ProvideJobWorker registers the handler of every job type
+func ProvideJobWorker(broker jobs.Broker, notifications *notification3.Service, exports *userexport.Files, tasks *maintenance.Tasks, webhooks *webhook3.Dispatcher, cfg *config.Config, logger *zap.Logger) *jobs.Worker {
+	w := jobs.NewWorker(broker, cfg.Jobs, logger)
+	w.Register(notification3.SendJob, notifications.HandleSendJob)
+	w.Register(userexport.GenerateJob, exports.Generate)
+	tasks.Register(w)
+	webhooks.Register(w)
+	return w
+}
+
+// ProvideWebhookDispatcher delivers the user events enqueued by the servers
+// to webhook subscriptions
+func ProvideWebhookDispatcher(repo webhook.Repository, deliveries webhook.DeliveryRepository, queue *jobs.Queue, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) *webhook3.Dispatcher {
+	return webhook3.NewDispatcher(repo, deliveries, queue, cfg.Webhooks.Timeout(), ids, logger)
+}
+
+// ProvideUserService creates the user service. Registrations must pass a
+// CAPTCHA challenge when registration.captcha is enabled and are stored
+// together with their security event, and operations run within the
+// deadlines of the deadlines configuration. It fails while Gmail aliases
+// are to be collapsed but the stored addresses were not normalized that way.
+func ProvideUserService(repo user2.Repository, normalizations user2.NormalizationRepository, ids idgen.Generator, residency compliance.ResidencyPolicy, notifier notification.Notifier, events event.Publisher, securityLog audit.SecurityLog, emails user2.EmailPolicy, tx tx.Manager, cfg *config.Config) (user.UserService, error) {
+	addresses := emailNormalizer(cfg.Email)
+	if err := user.CheckEmailNormalization(context.Background(), normalizations, addresses); err != nil {
+		return nil, err
+	}
+	hasher, err := passwordHasher(cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+	opts := []user.Option{
+		user.WithIDGenerator(ids),
+		user.WithResidencyPolicy(residency),
+		user.WithPasswordHasher(hasher),
+		user.WithNotifier(notifier),
+		user.WithEventPublisher(events),
+		user.WithSecurityLog(securityLog),
+		user.WithTxManager(tx),
+		user.WithEmailPolicy(emails),
+		user.WithEmailNormalizer(addresses),
+		user.WithDeadlines(deadline.FromConfig(cfg.Deadlines)),
+	}
+	verifier, err := captcha.NewVerifier(cfg.Registration.Captcha)
+	if err != nil {
+		return nil, err
+	}
+	if verifier != nil {
+		opts = append(opts, user.WithRegistrationCaptcha(verifier))
+	}
+	return user.NewUserService(repo, opts...), nil
+}
+
+// emailNormalizer builds the normalizer of email addresses from configuration
+func emailNormalizer(cfg config.EmailConfig) emailaddr.Normalizer {
+	return emailaddr.Normalizer{CollapseGmailAliases: cfg.CollapseGmailAliases}
+}
+
+// passwordHasher builds the hasher of new passwords from configuration
+func passwordHasher(cfg config.PasswordConfig) (*password.Hasher, error) {
+	switch password.Algorithm(cfg.HashAlgorithm()) {
+	case password.AlgorithmArgon2id:
+		params := password.DefaultArgon2Params
+		if cfg.Argon2.MemoryKiB > 0 {
+			params.MemoryKiB = cfg.Argon2.MemoryKiB
+		}
+		if cfg.Argon2.Iterations > 0 {
+			params.Iterations = cfg.Argon2.Iterations
+		}
+		if cfg.Argon2.Parallelism > 0 {
+			params.Parallelism = cfg.Argon2.Parallelism
+		}
+		return password.NewArgon2idHasher(params)
+	case password.AlgorithmBcrypt:
+		return password.NewBcryptHasher(cfg.Cost())
+	default:
+		return nil, fmt.Errorf("unknown password algorithm %q", cfg.Algorithm)
+	}
+}
+
+// ProvideResidencyPolicy creates the data residency policy from configuration
+func ProvideResidencyPolicy(cfg *config.Config) (compliance.ResidencyPolicy, error) {
+	return compliance2.NewResidencyPolicy(cfg.Compliance)
+}
+
+// ProvideEmailPolicy restricts the email addresses accounts sign up with to
+// the registration.email_domains rules
+func ProvideEmailPolicy(cfg *config.Config) (user2.EmailPolicy, error) {
+	return emailpolicy.FromConfig(cfg.Registration.EmailDomains)
+}
+
+// ProvideReadOnlySwitch creates the read-only mode switch, starting in the configured mode
+func ProvideReadOnlySwitch(cfg *config.Config) *readonly.Switch {
+	return readonly.NewSwitch(cfg.App.ReadOnly)
+}
+
+// ProvideIDStrategy validates the configured ID strategy
+func ProvideIDStrategy(cfg *config.Config) (idgen.Strategy, error) {
+	return idgen.ParseStrategy(cfg.App.IDStrategy)
+}
+
+// ProvideIDGenerator creates IDs for new entities with the configured strategy
+func ProvideIDGenerator(strategy idgen.Strategy) idgen.Generator {
+	return idgen.NewGenerator(strategy)
+}
+
+// ProvideAvailabilityChecker creates the signup availability checker
+func ProvideAvailabilityChecker(repo user2.Repository, cfg *config.Config) user.AvailabilityChecker {
+	return user.NewAvailabilityChecker(repo, emailNormalizer(cfg.Email), cfg.Availability.MinResponse())
+}
+
+// ProvideCaptchaVerifier returns nil when CAPTCHA gating is disabled
+func ProvideCaptchaVerifier(cfg *config.Config) (captcha.Verifier, error) {
+	return captcha.NewVerifier(cfg.Availability.Captcha)
+}
+
+// ProvideAuthService creates the auth service. Sign-ins escalate to a CAPTCHA
+// challenge after repeated failures when login.captcha_after_failures is set,
+// users are alerted to sign-ins from new devices when
+// login.new_device_alerts is set, and operations run within the deadlines of
+// the deadlines configuration.
+func ProvideAuthService(userService user.UserService, authRepo auth.AuthRepository, sessions auth.SessionRepository, attempts auth.LoginAttemptRepository, history auth.LoginHistoryRepository, impersonations auth.ImpersonationRepository, events event.Publisher, securityLog audit.SecurityLog, notifier notification.Notifier, cfg *config.Config, keyRing *auth3.KeyRing, cacheMetrics *cache.Metrics, authMetrics *auth3.Metrics, logger *zap.Logger) (auth.AuthService, error) {
+	tokens := cache.New[[sha256.Size]byte, uuid.UUID]("tokens", cacheConfig(cfg.Cache.Tokens), cacheMetrics)
+	opts := []auth3.Option{auth3.WithTokenCache(tokens), auth3.WithMetrics(authMetrics), auth3.WithLoginHistory(history), auth3.WithImpersonation(impersonations), auth3.WithEventPublisher(events)}
+	if cfg.Login.CaptchaAfterFailures > 0 {
+		verifier, err := captcha.NewVerifier(cfg.Login.Captcha)
+		if err != nil {
+			return nil, err
+		}
+		if verifier == nil {
+			return nil, fmt.Errorf("login: captcha must be enabled when captcha_after_failures is set")
+		}
+		opts = append(opts, auth3.WithCaptchaEscalation(attempts, verifier, cfg.Login.CaptchaAfterFailures, cfg.Login.FailureWindow()))
+	}
+	if cfg.Login.NewDeviceAlerts {
+		opts = append(opts, auth3.WithNewDeviceAlerts(securityLog, notifier))
+	}
+	opts = append(opts, auth3.WithDeadlines(deadline.FromConfig(cfg.Deadlines)))
+	return auth3.NewService(userService, authRepo, sessions, cfg, keyRing, logger, opts...)
+}
+
+// ProvideKeyRing builds the access token signing key ring from configuration
+// and the keys generated by rotation, which signingKeys shares between instances
+func ProvideKeyRing(cfg *config.Config, signingKeys auth.SigningKeyRepository, logger *zap.Logger) (*auth3.KeyRing, error) {
+	return auth3.NewSharedKeyRing(context.Background(), cfg.JWT, signingKeys, cfg.JWT.KeyRefresh(), logger)
+}
+
+func ProvideKeyManager(keyRing *auth3.KeyRing) auth.KeyManager {
+	return keyRing
+}
+
+func ProvideRoleService() rbac.RoleService {
+	return rbac2.NewService()
+}
+
+// ProvideAdminService creates the account management service; revoking
+// sessions goes through the auth service so tokens and sessions stay in sync,
+// and operations run within the deadlines of the deadlines configuration
+func ProvideAdminService(repo user2.Repository, sessions auth.SessionRepository, keys auth.KeyInspector, authService auth.AuthService, auditRepo audit.Repository, history auth.LoginHistoryRepository, notifier notification.Notifier, events event.Publisher, tx tx.Manager, ids idgen.Generator, cfg *config.Config) admin2.AdminService {
+	return admin2.NewAdminService(repo, sessions, keys, authService, authService, auditRepo, history, notifier, events, tx, ids, admin2.WithDeadlines(deadline.FromConfig(cfg.Deadlines)))
+}
+
+func ProvideMessageService(repo message.Repository, ids idgen.Generator) message3.MessageService {
+	return message3.NewMessageService(repo, ids)
+}
+
+func ProvideWebhookService(repo webhook.Repository, deliveries webhook.DeliveryRepository, ids idgen.Generator) webhook3.Service {
+	return webhook3.NewWebhookService(repo, deliveries, ids)
+}
+
+// ProvideSAMLService creates the SAML sign-in service; the sessions of SAML
+// users are issued by the auth service like those of password sign-ins
+func ProvideSAMLService(repo saml.Repository, assertions saml.AssertionCache, userService user.UserService, authService auth.AuthService, events event.Publisher, emails user2.EmailPolicy, cfg *config.Config, logger *zap.Logger) saml3.Service {
+	return saml3.NewSAMLService(repo, assertions, userService, authService, events, cfg.SAML, logger, saml3.WithEmailPolicy(emails))
+}
+
+// ProvideAPIKeyService creates the organization API key service
+func ProvideAPIKeyService(repo apikey.Repository, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) apikey3.Service {
+	return apikey3.NewService(repo, ids, cfg.APIKeys.RotationOverlap(), logger)
+}
+
+// ProvideOrganizationService creates the organization service; invitations
+// find accounts through the user service, and operations run within the
+// deadlines of the deadlines configuration
+func ProvideOrganizationService(repo organization.Repository, userService user.UserService, ids idgen.Generator, logger *zap.Logger, cfg *config.Config) organization3.Service {
+	return organization3.NewService(repo, userService, ids, logger, organization3.WithDeadlines(deadline.FromConfig(cfg.Deadlines)))
+}
+
+// ProvideSeedLoader creates the development fixture loader
+func ProvideSeedLoader(userService user.UserService, organizationService organization3.Service, logger *zap.Logger) *seed.Loader {
+	return seed.NewLoader(userService, organizationService, logger)
+}
+
+// ProvideImportService creates the bulk user import service
+func ProvideImportService(userService user.UserService, auditRepo audit.Repository, ids idgen.Generator, cfg *config.Config, logger *zap.Logger) userimport.Service {
+	return userimport.NewService(userService, auditRepo, ids, emailNormalizer(cfg.Email), cfg.Import.Batch(), logger)
+}
+
+// ProvideExportService creates the bulk user export service
+func ProvideExportService(repo user2.Repository, residency compliance.ResidencyPolicy, auditRepo audit.Repository, ids idgen.Generator, idFormat idgen.Strategy, cfg *config.Config, logger *zap.Logger) userexport.Service {
+	return userexport.NewService(repo, residency, auditRepo, ids, idFormat, cfg.Export.Batch(), logger)
+}
+
+// ProvideExportGenerator writes background exports to export.directory
+func ProvideExportGenerator(exporter userexport.Service, queue *jobs.Queue, cfg *config.Config, logger *zap.Logger) *userexport.Files {
+	return userexport.NewFiles(exporter, queue, cfg.Export.Dir(), logger)
 }
 
 // Provider functions for HTTP handlers
-func ProvideUserHttpHandler(userService user.UserService, logger *zap.Logger) *user4.Handler {
-	return user4.NewHandler(userService, logger)
+func ProvideUserHttpHandler(userService user.UserService, ids idgen.Strategy, logger *zap.Logger) *user4.Handler {
+	return user4.NewHandler(userService, ids, logger)
+}
+
+func ProvideAvailabilityHttpHandler(checker user.AvailabilityChecker, verifier captcha.Verifier, logger *zap.Logger) *user4.AvailabilityHandler {
+	return user4.NewAvailabilityHandler(checker, verifier, logger)
+}
+
+// ProvideAvailabilityLimiter throttles the signup availability check per
+// client; the config watcher adjusts its limit
+func ProvideAvailabilityLimiter(cfg *config.Config) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(cfg.Availability.RateLimit(), time.Minute)
 }
 
 func ProvideAuthHttpHandler(authService auth.AuthService, logger *zap.Logger) *auth4.Handler {
 	return auth4.NewHandler(authService, logger)
 }
 
+func ProvideJWKSHttpHandler(keyManager auth.KeyManager) *jwks.Handler {
+	return jwks.NewHandler(keyManager)
+}
+
+func ProvideAdminHttpHandler(roleService rbac.RoleService, keyManager auth.KeyManager, logger *zap.Logger) *admin.Handler {
+	return admin.NewHandler(roleService, keyManager, logger)
+}
+
+func ProvideAccountHttpHandler(adminService admin2.AdminService, ids idgen.Strategy, logger *zap.Logger) *admin.AccountHandler {
+	return admin.NewAccountHandler(adminService, ids, logger)
+}
+
+func ProvideFeatureFlagHttpHandler(flags *featureflag.Flags, ids idgen.Strategy, logger *zap.Logger) *admin.FeatureFlagHandler {
+	return admin.NewFeatureFlagHandler(flags, ids, logger)
+}
+
+func ProvideWebhookHttpHandler(webhooks webhook3.Service, ids idgen.Strategy, logger *zap.Logger) *admin.WebhookHandler {
+	return admin.NewWebhookHandler(webhooks, ids, logger)
+}
+
+func ProvideSAMLHttpHandler(saml saml3.Service, logger *zap.Logger) *auth4.SAMLHandler {
+	return auth4.NewSAMLHandler(saml, logger)
+}
+
+func ProvideSAMLProviderHttpHandler(saml saml3.Service, logger *zap.Logger) *admin.SAMLProviderHandler {
+	return admin.NewSAMLProviderHandler(saml, logger)
+}
+
+func ProvideLoggingHttpHandler(levels *logging.Levels, logger *zap.Logger) *admin.LoggingHandler {
+	return admin.NewLoggingHandler(levels, logger)
+}
+
+func ProvideReadOnlyHttpHandler(sw *readonly.Switch, logger *zap.Logger) *admin.ReadOnlyHandler {
+	return admin.NewReadOnlyHandler(sw, logger)
+}
+
+func ProvideImportHttpHandler(importer userimport.Service, ids idgen.Strategy, cfg *config.Config, logger *zap.Logger) *admin.ImportHandler {
+	return admin.NewImportHandler(importer, ids, cfg.Import.MaxFileSize(), cfg.Import.SyncMax(), logger)
+}
+
+func ProvideExportHttpHandler(exporter userexport.Service, generator *userexport.Files, ids idgen.Strategy, logger *zap.Logger) *admin.ExportHandler {
+	return admin.NewExportHandler(exporter, generator, ids, logger)
+}
+
+func ProvideMessageHttpHandler(messageService message3.MessageService, userService user.UserService, ids idgen.Strategy, logger *zap.Logger) *message4.Handler {
+	return message4.NewHandler(messageService, userService, ids, logger)
+}
+
+func ProvideOrgHttpHandler(apiKeys apikey3.Service, userService user.UserService, adminService admin2.AdminService, ids idgen.Strategy, logger *zap.Logger) *org.Handler {
+	return org.NewHandler(apiKeys, userService, adminService, ids, logger)
+}
+
+func ProvideOrganizationHttpHandler(organizationService organization3.Service, ids idgen.Strategy, logger *zap.Logger) *organization4.Handler {
+	return organization4.NewHandler(organizationService, ids, logger)
+}
+
+func ProvideAccountCenterHttpHandler(userService user.UserService, adminService admin2.AdminService, authService auth.AuthService, ids idgen.Strategy, logger *zap.Logger) *account.Handler {
+	return account.NewHandler(userService, adminService, adminService, adminService, adminService, authService, ids, logger)
+}
+
+func ProvideHealthHttpHandler(monitor *health.Monitor, breakers *breaker.Group, cfg *config.Config) *health2.Handler {
+	return health2.NewHandler(monitor, breakers, cfg)
+}
+
+// ProvideRealtimeHub hands the events on the bus to the WebSocket connections of this instance
+func ProvideRealtimeHub(bus *eventbus.Bus, logger *zap.Logger) *realtime.Hub {
+	hub := realtime.NewHub(logger)
+	bus.Subscribe(hub.Dispatch)
+	return hub
+}
+
+// ProvideAdminEventFeed hands the account activity on the bus to the admin dashboards streaming from this instance
+func ProvideAdminEventFeed(bus *eventbus.Bus, cfg *config.Config, logger *zap.Logger) *realtime.Feed {
+	feed := realtime.NewFeed(cfg.Realtime.History(), logger)
+	bus.Subscribe(feed.Dispatch)
+	return feed
+}
+
+func ProvideRealtimeHttpHandler(hub *realtime.Hub, feed *realtime.Feed, cfg *config.Config, ids idgen.Strategy, logger *zap.Logger) *realtime.Handler {
+	return realtime.NewHandler(hub, feed, cfg.Realtime, ids, logger)
+}
+
 // Provider functions for gRPC handlers
-func ProvideUserGrpcHandler(userService user.UserService, logger *zap.Logger) *user5.Handler {
-	return user5.NewHandler(userService, logger)
+func ProvideUserGrpcHandler(userService user.UserService, authService auth.AuthService, adminService admin2.AdminService, ids idgen.Strategy, logger *zap.Logger) *user5.Handler {
+	return user5.NewHandler(userService, authService, adminService, ids, logger)
 }
 
-func ProvideAuthGrpcHandler(authService auth.AuthService, logger *zap.Logger) *auth5.Handler {
-	return auth5.NewHandler(authService, logger)
+func ProvideAuthGrpcHandler(authService auth.AuthService, userService user.UserService, ids idgen.Strategy, logger *zap.Logger) *auth5.Handler {
+	return auth5.NewHandler(authService, userService, ids, logger)
 }
 
 // Provider function for middleware
@@ -134,11 +1158,19 @@ func ProvideAuthMiddleware(authService auth.AuthService, logger *zap.Logger) gin
 }
 
 // Provider function for router
-func ProvideRouter(userHandler *user4.Handler, authHandler *auth4.Handler, authService auth.AuthService, logger *zap.Logger) *gin.Engine {
-	return http.NewRouter(userHandler, authHandler, authService, logger)
+func ProvideRouter(userHandler *user4.Handler, availabilityHandler *user4.AvailabilityHandler, availabilityLimiter *middleware.RateLimiter, authHandler *auth4.Handler, adminHandler *admin.Handler, accountHandler *admin.AccountHandler, messageHandler *message4.Handler, jwksHandler *jwks.Handler, readOnlyHandler *admin.ReadOnlyHandler, importHandler *admin.ImportHandler, exportHandler *admin.ExportHandler, orgHandler *org.Handler, organizationHandler *organization4.Handler, accountCenterHandler *account.Handler, healthHandler *health2.Handler, realtimeHandler *realtime.Handler, featureFlagHandler *admin.FeatureFlagHandler, loggingHandler *admin.LoggingHandler, webhookHandler *admin.WebhookHandler, samlHandler *auth4.SAMLHandler, samlProviderHandler *admin.SAMLProviderHandler, authService auth.AuthService, userService user.UserService, apiKeys apikey3.Service, readOnlySwitch *readonly.Switch, auditRepo audit.Repository, ids idgen.Generator, panics *recovery.Recorder, errorReporter errorreport.Reporter, registry *prometheus.Registry, cfg *config.Config, logger *zap.Logger) (*http.Routers, error) {
+	routers, err := http.NewRouter(userHandler, availabilityHandler, availabilityLimiter, authHandler, adminHandler, accountHandler, messageHandler, jwksHandler, readOnlyHandler, importHandler, exportHandler, orgHandler, organizationHandler, accountCenterHandler, healthHandler, realtimeHandler, featureFlagHandler, loggingHandler, webhookHandler, samlHandler, samlProviderHandler, authService, userService, apiKeys, readOnlySwitch, auditRepo, ids, panics, errorReporter, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	// The admin listener serves the metrics too, so one port carries the ops surface
+	if routers.Admin != nil {
+		routers.Admin.GET("/metrics", gin.WrapH(metrics.NewHandler(registry)))
+	}
+	return routers, nil
 }
 
 // ProvideHTTPServer creates a new HTTP server
-func ProvideHTTPServer(router *gin.Engine, cfg *config.Config) *http.Server {
-	return http.NewServer(router, cfg)
+func ProvideHTTPServer(routers *http.Routers, cfg *config.Config) *http.Server {
+	return http.NewServer(routers, cfg)
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/eventbus"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/provider"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+	repoAuth "github.com/yi-tech/go-user-service/internal/repository/auth"
+)
+
+const usage = `Usage: sessions <command> [flags]
+
+Commands:
+  revoke    Invalidate the refresh token and sign-in sessions of a user and
+            disconnect the clients it has open on GET /ws
+
+Run 'sessions revoke -h' for revoke flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "revoke":
+		if err := revoke(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "revoke: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// revoke runs the revoke command against the auth store and Redis server of
+// the configuration selected by APP_ENV. It works on the store directly, so it
+// can be used from runbooks while the HTTP and gRPC admin APIs are unavailable.
+func revoke(args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	user := fs.String("user", "", "ID of the user to sign out (UUID or ULID)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *user == "" {
+		return errors.New("-user is required")
+	}
+	userID, err := idgen.Parse(*user)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", *user, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.AuthStore.Driver == "memory" {
+		return errors.New("auth_store.driver memory keeps sessions inside the server; sign the user out through the admin API")
+	}
+	schema, err := rediskey.New(cfg.Redis.KeyPrefix)
+	if err != nil {
+		return err
+	}
+	client, err := provider.NewRedisProvider(cfg).GetRedisClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	logger, err := provider.ProvideLogger(cfg, provider.ProvideLogLevels(cfg))
+	if err != nil {
+		return err
+	}
+
+	backends := repoAuth.Backends{
+		Redis: client,
+		Keys:  schema,
+		Retry: repoAuth.RetryPolicy{Attempts: cfg.Redis.Failover.Attempts(), Backoff: cfg.Redis.Failover.Backoff()},
+	}
+	if cfg.AuthStore.Driver == "postgres" {
+		if backends.DB, err = provider.NewDatabaseProvider(cfg, logger).GetDB(); err != nil {
+			return err
+		}
+	}
+	store, err := repoAuth.OpenStore(cfg.AuthStore.Driver, backends)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := revokeUser(context.Background(), store.Tokens, store.Sessions, userID)
+	if err != nil {
+		return err
+	}
+
+	// Tell the clients the user has connected on GET /ws, which then close
+	strategy, err := idgen.ParseStrategy(cfg.App.IDStrategy)
+	if err != nil {
+		return err
+	}
+	events := eventbus.NewBus(client, schema, idgen.NewGenerator(strategy), logger)
+	events.Publish(context.Background(), domainEvent.Event{Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: domainEvent.ReasonRevoked})
+
+	fmt.Printf("Revoked %d sessions of user %s\n", revoked, userID)
+	fmt.Println("Access tokens already issued stay valid until they expire")
+	return nil
+}
+
+// revokeUser deletes the refresh token mappings and sessions of a user, as
+// signing out does, and returns how many unexpired sessions were ended. The
+// refresh token of every session stops working, not only the latest one.
+func revokeUser(ctx context.Context, tokens domainAuth.AuthRepository, sessions domainAuth.SessionRepository, userID uuid.UUID) (int, error) {
+	active, err := sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshToken, err := tokens.GetUserRefreshToken(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	refreshTokens := []string{refreshToken}
+	for _, session := range active {
+		refreshTokens = append(refreshTokens, session.RefreshToken)
+	}
+	for _, token := range refreshTokens {
+		if token == "" {
+			continue
+		}
+		if err := tokens.DeleteRefreshTokenUserID(ctx, token); err != nil {
+			return 0, err
+		}
+	}
+	if err := tokens.DeleteUserRefreshToken(ctx, userID); err != nil {
+		return 0, err
+	}
+	if err := sessions.DeleteSessions(ctx, userID); err != nil {
+		return 0, err
+	}
+	return len(active), nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	repoAuth "github.com/yi-tech/go-user-service/internal/repository/auth"
+)
+
+func TestRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	sessions := repoAuth.NewMemorySessionRepository()
+	tokens := repoAuth.NewMemoryAuthRepository(sessions)
+	userID := uuid.New()
+
+	// The laptop signed in before the phone, whose token is the current one
+	for _, device := range []string{"laptop", "phone"} {
+		token := device + "-token"
+		require.NoError(t, tokens.SetUserRefreshToken(ctx, userID, token, time.Hour))
+		require.NoError(t, tokens.SetRefreshTokenUserID(ctx, token, userID, time.Hour))
+		require.NoError(t, sessions.SaveSession(ctx, domainAuth.NewSession(userID, token, domainAuth.SessionStandard, device, "10.0.0.1", time.Hour)))
+	}
+
+	revoked, err := revokeUser(ctx, tokens, sessions, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+
+	for _, token := range []string{"laptop-token", "phone-token"} {
+		owner, err := tokens.GetUserIDByRefreshToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Nil, owner, "%s no longer refreshes", token)
+	}
+	active, err := sessions.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
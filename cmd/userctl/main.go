@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+)
+
+const usage = `Usage: userctl <command> [flags]
+
+Commands:
+  create           Register a user account
+  list             List user accounts, newest first
+  reset-password   Require a user to choose a new password and sign them out
+  deactivate       Disable a user account and sign the user out
+  activate         Re-enable a deactivated user account
+  delete           Delete a user account and sign the user out
+
+Every command accepts -endpoint, -token, -tls, -timeout and -o; the endpoint
+and token default to $USERCTL_ENDPOINT and $USERCTL_TOKEN. Every command but
+create requires the access token of an administrator.
+Run 'userctl <command> -h' for the flags of a command.
+`
+
+// defaultEndpoint is the gRPC address of a locally running server
+const defaultEndpoint = "localhost:50051"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	commands := map[string]func(args []string) error{
+		"create":         create,
+		"list":           list,
+		"reset-password": resetPassword,
+		"deactivate":     func(args []string) error { return setStatus("deactivate", args, false) },
+		"activate":       func(args []string) error { return setStatus("activate", args, true) },
+		"delete":         deleteUser,
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// connection holds the flags shared by every command
+type connection struct {
+	endpoint string
+	token    string
+	tls      bool
+	timeout  time.Duration
+	output   string
+}
+
+func (c *connection) registerFlags(fs *flag.FlagSet) {
+	endpoint := os.Getenv("USERCTL_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	fs.StringVar(&c.endpoint, "endpoint", endpoint, "gRPC address of the server")
+	fs.StringVar(&c.token, "token", os.Getenv("USERCTL_TOKEN"), "access token sent as the bearer token")
+	fs.BoolVar(&c.tls, "tls", false, "connect with TLS, e.g. through a load balancer")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "deadline of the call")
+	fs.StringVar(&c.output, "o", "table", "output format: table or json")
+}
+
+// dial connects to the server and returns a context carrying the token and
+// the deadline of the call; the caller closes the connection and cancels
+func (c *connection) dial() (*grpc.ClientConn, context.Context, context.CancelFunc, error) {
+	if c.output != "table" && c.output != "json" {
+		return nil, nil, nil, fmt.Errorf("-o must be table or json, not %q", c.output)
+	}
+	creds := insecure.NewCredentials()
+	if c.tls {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	conn, err := grpc.NewClient(c.endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	}
+	return conn, ctx, cancel, nil
+}
+
+// create registers a user account through the public Register RPC
+func create(args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	email := fs.String("email", "", "email address of the account")
+	password := fs.String("password", "", "password of the account; read from stdin when empty")
+	firstName := fs.String("first-name", "", "first name")
+	lastName := fs.String("last-name", "", "last name")
+	var c connection
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	if *password == "" {
+		var err error
+		if *password, err = readLine(os.Stdin); err != nil {
+			return fmt.Errorf("read password from stdin: %w", err)
+		}
+	}
+
+	conn, ctx, cancel, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer cancel()
+
+	resp, err := userpb.NewUserServiceClient(conn).Register(ctx, &userpb.RegisterRequest{
+		Email:     *email,
+		Password:  *password,
+		FirstName: *firstName,
+		LastName:  *lastName,
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.User, func(w io.Writer) { printUsers(w, resp.User) })
+}
+
+// list prints a page of user accounts
+func list(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	page := fs.Int("page", 1, "page number")
+	pageSize := fs.Int("page-size", 20, "users per page, at most 100")
+	query := fs.String("q", "", "substring of the email, username or name")
+	role := fs.String("role", "", "only users with this role")
+	status := fs.String("status", "", "only active or inactive users")
+	var c connection
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, ctx, cancel, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer cancel()
+
+	resp, err := adminpb.NewAdminServiceClient(conn).ListUsers(ctx, &adminpb.ListUsersRequest{
+		Page:     int32(*page),
+		PageSize: int32(*pageSize),
+		Query:    *query,
+		Role:     *role,
+		Status:   *status,
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, func(w io.Writer) {
+		printAdminUsers(w, resp.Users...)
+		fmt.Fprintf(w, "\nPage %d, %d users in total (%d per page)\n", resp.Page, resp.Total, resp.PageSize)
+	})
+}
+
+// resetPassword flags a user to choose a new password
+func resetPassword(args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	id := fs.String("id", "", "ID of the user")
+	var c connection
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+
+	conn, ctx, cancel, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer cancel()
+
+	user, err := adminpb.NewAdminServiceClient(conn).ForcePasswordReset(ctx, &adminpb.ForcePasswordResetRequest{Id: *id})
+	if err != nil {
+		return err
+	}
+	return c.print(user, func(w io.Writer) { printAdminUsers(w, user) })
+}
+
+// setStatus activates or deactivates a user account
+func setStatus(name string, args []string, active bool) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	id := fs.String("id", "", "ID of the user")
+	var c connection
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+
+	conn, ctx, cancel, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer cancel()
+
+	resp, err := userpb.NewUserServiceClient(conn).SetUserStatus(ctx, &userpb.SetUserStatusRequest{Id: *id, IsActive: active})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.User, func(w io.Writer) { printUsers(w, resp.User) })
+}
+
+// deleteUser deletes a user account
+func deleteUser(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	id := fs.String("id", "", "ID of the user")
+	var c connection
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+
+	conn, ctx, cancel, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer cancel()
+
+	resp, err := adminpb.NewAdminServiceClient(conn).DeleteUser(ctx, &adminpb.DeleteUserRequest{Id: *id})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, func(w io.Writer) { fmt.Fprintf(w, "Deleted user %s\n", *id) })
+}
+
+// print writes msg as JSON, or calls table with a tab-aligned writer
+func (c *connection) print(msg proto.Message, table func(w io.Writer)) error {
+	if c.output == "json" {
+		data, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Printf("%s\n", data)
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func printUsers(w io.Writer, users ...*userpb.User) {
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tACTIVE\tCREATED")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", u.Id, u.Email, fullName(u.FirstName, u.LastName), u.IsActive, u.CreatedAt.AsTime().Format(time.RFC3339))
+	}
+}
+
+func printAdminUsers(w io.Writer, users ...*adminpb.User) {
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tACTIVE\tRESET REQUIRED\tCREATED")
+	for _, u := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%t\t%s\n", u.Id, u.Email, fullName(u.FirstName, u.LastName), u.Role, u.IsActive, u.PasswordResetRequired, u.CreatedAt.AsTime().Format(time.RFC3339))
+	}
+}
+
+func fullName(first, last string) string {
+	return strings.TrimSpace(first + " " + last)
+}
+
+// readLine reads one line, without its line ending, e.g. a piped password
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("password is empty")
+	}
+	return line, nil
+}
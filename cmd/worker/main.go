@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	appwire "github.com/yi-tech/go-user-service/cmd/server/wire"
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+const usage = `Usage: worker <command> [flags]
+
+Commands:
+  run        Run queued background jobs until SIGINT or SIGTERM
+  enqueue    Queue a job, e.g. a housekeeping job from a runbook
+
+Both commands accept -config and -set to override the configuration.
+Run 'worker enqueue -h' for enqueue flags.
+`
+
+// metricsShutdownTimeout bounds how long the metrics listener drains on exit
+const metricsShutdownTimeout = 5 * time.Second
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "run":
+		run(os.Args[2:])
+	case "enqueue":
+		if err := enqueue(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "enqueue: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// run claims and runs jobs from the queue of the configuration selected by
+// APP_ENV, enqueueing the housekeeping jobs of jobs.schedule. On a signal it
+// stops claiming jobs and waits for the running ones.
+func run(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	var opts config.Options
+	opts.RegisterFlags(fs)
+	_ = fs.Parse(args) // Exits on error
+
+	app, err := appwire.InitializeWorker(opts)
+	if err != nil {
+		log.Fatalf("Failed to initialize worker: %v", err)
+	}
+	appwire.NewWorkerStartupReport(app.Config).Log(app.Logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	g, gctx := errgroup.WithContext(ctx)
+
+	app.Logger.Info("Starting worker",
+		zap.String("queue", app.Config.Jobs.QueueName()),
+		zap.Int("concurrency", app.Config.Jobs.Workers()),
+		zap.Strings("job_types", app.Worker.Types()),
+		zap.Any("schedule", app.Scheduler.Scheduled()))
+
+	g.Go(func() error { return app.Worker.Run(gctx) })
+	g.Go(func() error { return app.Scheduler.Run(gctx) })
+	if app.MetricsServer != nil {
+		app.Logger.Info("Serving metrics on internal port", zap.Int("metricsPort", app.Config.Jobs.MetricsPort))
+		g.Go(app.MetricsServer.Serve)
+		g.Go(func() error {
+			<-gctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+			defer cancel()
+			if err := app.MetricsServer.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("metrics server shutdown: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		app.Logger.Error("Worker exited with error", zap.Error(err))
+		os.Exit(1)
+	}
+
+	app.Logger.Info("Worker exiting")
+}
+
+// enqueue queues one job of a registered type with an optional JSON payload
+func enqueue(args []string) error {
+	fs := flag.NewFlagSet("enqueue", flag.ExitOnError)
+	jobType := fs.String("type", "", "Type of the job, e.g. sessions.cleanup, tokens.cleanup or audit.prune")
+	payload := fs.String("payload", "", "JSON payload of the job; empty for none")
+	var opts config.Options
+	opts.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *jobType == "" {
+		return errors.New("-type is required")
+	}
+
+	app, err := appwire.InitializeWorker(opts)
+	if err != nil {
+		return err
+	}
+	if !registered(app.Worker.Types(), *jobType) {
+		return fmt.Errorf("unknown job type %q; known types: %s", *jobType, strings.Join(app.Worker.Types(), ", "))
+	}
+
+	var data any
+	if *payload != "" {
+		data = json.RawMessage(*payload)
+	}
+	job, err := app.Queue.Enqueue(context.Background(), *jobType, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Queued %s job %s\n", job.Type, job.ID)
+	return nil
+}
+
+func registered(types []string, jobType string) bool {
+	for _, t := range types {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
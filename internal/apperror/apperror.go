@@ -0,0 +1,118 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is a stable, machine-readable identifier for an application error.
+// Codes are shared by the HTTP and gRPC transports so clients can rely on them
+// regardless of the protocol they use.
+type Code string
+
+// Error codes known to the application
+const (
+	CodeInternal              Code = "INTERNAL"
+	CodeInvalidArgument       Code = "INVALID_ARGUMENT"
+	CodeUnauthenticated       Code = "UNAUTHENTICATED"
+	CodePermissionDenied      Code = "PERMISSION_DENIED"
+	CodeUserNotFound          Code = "USER_NOT_FOUND"
+	CodeUserAlreadyExists     Code = "USER_ALREADY_EXISTS"
+	CodeEmailInUse            Code = "EMAIL_IN_USE"
+	CodeIncorrectPassword     Code = "INCORRECT_PASSWORD"
+	CodeInvalidCredentials    Code = "INVALID_CREDENTIALS"
+	CodeInvalidToken          Code = "INVALID_TOKEN"
+	CodeInvalidOrExpiredToken Code = "INVALID_OR_EXPIRED_TOKEN"
+	CodeTokenExpired          Code = "TOKEN_EXPIRED"
+	CodeTokenNotYetValid      Code = "TOKEN_NOT_YET_VALID"
+	CodeTokenMalformed        Code = "TOKEN_MALFORMED"
+	CodeSessionNotFound       Code = "SESSION_NOT_FOUND"
+	CodeRateLimited           Code = "RATE_LIMITED"
+	CodeCaptchaFailed         Code = "CAPTCHA_FAILED"
+	CodeCaptchaRequired       Code = "CAPTCHA_REQUIRED"
+	CodeAccountDisabled       Code = "ACCOUNT_DISABLED"
+	CodeMessageNotFound       Code = "MESSAGE_NOT_FOUND"
+	CodePasswordResetRequired Code = "PASSWORD_RESET_REQUIRED"
+	CodeReadOnly              Code = "READ_ONLY"
+	CodeImportJobNotFound     Code = "IMPORT_JOB_NOT_FOUND"
+	CodeAPIKeyNotFound        Code = "API_KEY_NOT_FOUND"
+	CodeInvalidAPIKey         Code = "INVALID_API_KEY"
+	CodeServiceUnavailable    Code = "SERVICE_UNAVAILABLE"
+	CodeExportJobNotFound     Code = "EXPORT_JOB_NOT_FOUND"
+	CodeExportNotReady        Code = "EXPORT_NOT_READY"
+	CodeOrganizationNotFound  Code = "ORGANIZATION_NOT_FOUND"
+	CodeOrganizationSlugInUse Code = "ORGANIZATION_SLUG_IN_USE"
+	CodeMemberNotFound        Code = "MEMBER_NOT_FOUND"
+	CodeInvitationNotFound    Code = "INVITATION_NOT_FOUND"
+	CodeAlreadyMember         Code = "ALREADY_MEMBER"
+	CodeLastOwner             Code = "LAST_OWNER"
+	CodeFeatureFlagNotFound   Code = "FEATURE_FLAG_NOT_FOUND"
+	CodeVersionMismatch       Code = "VERSION_MISMATCH"
+	CodePreconditionRequired  Code = "PRECONDITION_REQUIRED"
+	CodeTimeout               Code = "TIMEOUT"
+	CodeImpersonationNotFound Code = "IMPERSONATION_NOT_FOUND"
+	CodeWebhookNotFound       Code = "WEBHOOK_NOT_FOUND"
+	CodeSAMLProviderNotFound  Code = "SAML_PROVIDER_NOT_FOUND"
+	CodeInvalidAssertion      Code = "INVALID_ASSERTION"
+	CodePasswordExpired       Code = "PASSWORD_EXPIRED"
+	CodeSecurityEventNotFound Code = "SECURITY_EVENT_NOT_FOUND"
+	CodeEmailPolicyViolation  Code = "EMAIL_POLICY_VIOLATION"
+)
+
+// Error is an application error carrying a Code and a client-safe message.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error // Optional underlying cause, never exposed to clients
+}
+
+// New creates a new application error with the given code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates a new application error that wraps an underlying cause
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an application error with the same code,
+// so errors.Is matches on the code rather than on pointer identity.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
+
+// As returns the first application error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeOf returns the code of the first application error in err's chain.
+// Errors that are not application errors are reported as CodeInternal.
+func CodeOf(err error) Code {
+	if appErr, ok := As(err); ok {
+		return appErr.Code
+	}
+	return CodeInternal
+}
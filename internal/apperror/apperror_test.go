@@ -0,0 +1,62 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorIsMatchesOnCode(t *testing.T) {
+	sentinel := New(CodeUserNotFound, "user not found")
+	wrapped := fmt.Errorf("repository: %w", Wrap(CodeUserNotFound, "user not found", errors.New("no rows")))
+
+	assert.True(t, errors.Is(wrapped, sentinel))
+	assert.False(t, errors.Is(wrapped, New(CodeEmailInUse, "email already in use")))
+	assert.Equal(t, CodeUserNotFound, CodeOf(wrapped))
+	assert.Equal(t, CodeInternal, CodeOf(errors.New("database error")))
+}
+
+func TestTransportMapping(t *testing.T) {
+	tests := []struct {
+		code       Code
+		httpStatus int
+		grpcCode   codes.Code
+	}{
+		{CodeUserNotFound, http.StatusNotFound, codes.NotFound},
+		{CodeEmailInUse, http.StatusConflict, codes.AlreadyExists},
+		{CodeInvalidCredentials, http.StatusUnauthorized, codes.Unauthenticated},
+		{Code("UNKNOWN"), http.StatusInternalServerError, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			assert.Equal(t, tt.httpStatus, HTTPStatus(tt.code))
+			assert.Equal(t, tt.grpcCode, GRPCCode(tt.code))
+		})
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	st, ok := status.FromError(GRPCStatus(New(CodeInvalidToken, "invalid token")))
+	assert.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+	assert.Equal(t, "invalid token", st.Message())
+	code, ok := CodeFromGRPCStatus(st)
+	assert.True(t, ok)
+	assert.Equal(t, CodeInvalidToken, code)
+
+	cause := errors.New("connection refused")
+	err := GRPCStatus(cause)
+	st, ok = status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.NotContains(t, st.Message(), "connection refused")
+	assert.ErrorIs(t, err, cause, "the cause is kept for error reporting")
+	_, ok = CodeFromGRPCStatus(st)
+	assert.False(t, ok)
+}
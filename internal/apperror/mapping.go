@@ -0,0 +1,136 @@
+package apperror
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mapping holds the transport representations of a Code
+type mapping struct {
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+// catalog is the single source of truth for how error codes are exposed
+var catalog = map[Code]mapping{
+	CodeInternal:              {http.StatusInternalServerError, codes.Internal},
+	CodeInvalidArgument:       {http.StatusBadRequest, codes.InvalidArgument},
+	CodeUnauthenticated:       {http.StatusUnauthorized, codes.Unauthenticated},
+	CodePermissionDenied:      {http.StatusForbidden, codes.PermissionDenied},
+	CodeUserNotFound:          {http.StatusNotFound, codes.NotFound},
+	CodeUserAlreadyExists:     {http.StatusConflict, codes.AlreadyExists},
+	CodeEmailInUse:            {http.StatusConflict, codes.AlreadyExists},
+	CodeIncorrectPassword:     {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeInvalidCredentials:    {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeInvalidToken:          {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeInvalidOrExpiredToken: {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeTokenExpired:          {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeTokenNotYetValid:      {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeTokenMalformed:        {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeSessionNotFound:       {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeRateLimited:           {http.StatusTooManyRequests, codes.ResourceExhausted},
+	CodeCaptchaFailed:         {http.StatusForbidden, codes.PermissionDenied},
+	CodeCaptchaRequired:       {http.StatusForbidden, codes.PermissionDenied},
+	CodeAccountDisabled:       {http.StatusForbidden, codes.PermissionDenied},
+	CodeMessageNotFound:       {http.StatusNotFound, codes.NotFound},
+	CodePasswordResetRequired: {http.StatusForbidden, codes.PermissionDenied},
+	CodeReadOnly:              {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeImportJobNotFound:     {http.StatusNotFound, codes.NotFound},
+	CodeAPIKeyNotFound:        {http.StatusNotFound, codes.NotFound},
+	CodeInvalidAPIKey:         {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeServiceUnavailable:    {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeExportJobNotFound:     {http.StatusNotFound, codes.NotFound},
+	CodeExportNotReady:        {http.StatusConflict, codes.FailedPrecondition},
+	CodeOrganizationNotFound:  {http.StatusNotFound, codes.NotFound},
+	CodeOrganizationSlugInUse: {http.StatusConflict, codes.AlreadyExists},
+	CodeMemberNotFound:        {http.StatusNotFound, codes.NotFound},
+	CodeInvitationNotFound:    {http.StatusNotFound, codes.NotFound},
+	CodeAlreadyMember:         {http.StatusConflict, codes.AlreadyExists},
+	CodeLastOwner:             {http.StatusConflict, codes.FailedPrecondition},
+	CodeFeatureFlagNotFound:   {http.StatusNotFound, codes.NotFound},
+	CodeVersionMismatch:       {http.StatusPreconditionFailed, codes.FailedPrecondition},
+	CodePreconditionRequired:  {http.StatusPreconditionRequired, codes.FailedPrecondition},
+	CodeTimeout:               {http.StatusGatewayTimeout, codes.DeadlineExceeded},
+	CodeImpersonationNotFound: {http.StatusNotFound, codes.NotFound},
+	CodeWebhookNotFound:       {http.StatusNotFound, codes.NotFound},
+	CodeSAMLProviderNotFound:  {http.StatusNotFound, codes.NotFound},
+	CodeInvalidAssertion:      {http.StatusUnauthorized, codes.Unauthenticated},
+	CodePasswordExpired:       {http.StatusForbidden, codes.PermissionDenied},
+	CodeSecurityEventNotFound: {http.StatusNotFound, codes.NotFound},
+	CodeEmailPolicyViolation:  {http.StatusForbidden, codes.PermissionDenied},
+}
+
+// Codes returns every error code in the catalog, sorted
+func Codes() []Code {
+	return slices.Sorted(maps.Keys(catalog))
+}
+
+// HTTPStatus returns the HTTP status code for an error code
+func HTTPStatus(code Code) int {
+	if m, ok := catalog[code]; ok {
+		return m.httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode returns the gRPC status code for an error code
+func GRPCCode(code Code) codes.Code {
+	if m, ok := catalog[code]; ok {
+		return m.grpcCode
+	}
+	return codes.Internal
+}
+
+// ErrorDomain identifies this service in the ErrorInfo detail of gRPC statuses
+const ErrorDomain = "go-user-service"
+
+// GRPCStatus converts err into a gRPC status error. Application errors keep
+// their message and carry their code as the reason of an ErrorInfo detail;
+// any other error is reported as an opaque internal error, which unwraps to
+// err for error reporting but never sends it to the client.
+func GRPCStatus(err error) error {
+	appErr, ok := As(err)
+	if !ok {
+		return &internalError{status: status.New(codes.Internal, "Internal server error"), cause: err}
+	}
+	st := status.New(GRPCCode(appErr.Code), appErr.Message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(appErr.Code), Domain: ErrorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// internalError is the status of an error that is not an application error
+type internalError struct {
+	status *status.Status
+	cause  error
+}
+
+func (e *internalError) Error() string {
+	return e.status.Err().Error()
+}
+
+// GRPCStatus lets the gRPC server send the opaque status
+func (e *internalError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// Unwrap returns the error behind the status
+func (e *internalError) Unwrap() error {
+	return e.cause
+}
+
+// CodeFromGRPCStatus returns the application error code carried by st, if any
+func CodeFromGRPCStatus(st *status.Status) (Code, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == ErrorDomain {
+			return Code(info.Reason), true
+		}
+	}
+	return "", false
+}
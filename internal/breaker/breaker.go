@@ -0,0 +1,161 @@
+// Package breaker stops calling a backend that keeps failing. After a run of
+// consecutive failures a Breaker opens and fails calls straight away instead
+// of letting each wait for its own timeout; once the open period passes a
+// single trial call is let through, closing the breaker again when it
+// succeeds.
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// ErrOpen is returned for calls refused while a breaker is open
+var ErrOpen = apperror.New(apperror.CodeServiceUnavailable, "The service is temporarily unavailable. Please try again later.")
+
+// State is the state of a Breaker
+type State string
+
+// Breaker states
+const (
+	StateClosed   State = "closed"    // Calls go through
+	StateOpen     State = "open"      // Calls are refused with ErrOpen
+	StateHalfOpen State = "half_open" // A trial call decides whether to close or open again
+)
+
+// Options tunes a Breaker
+type Options struct {
+	FailureThreshold int           // Consecutive failures opening the breaker; below 1 means 5
+	OpenTimeout      time.Duration // How long calls are refused before a trial; 0 means 10 seconds
+}
+
+// Snapshot is the state of a breaker at a point in time
+type Snapshot struct {
+	Name  string
+	State State
+	Since time.Time // When the breaker entered State
+}
+
+// Breaker guards the calls to one backend. A nil Breaker lets every call
+// through, so a disabled breaker needs no special case.
+type Breaker struct {
+	name    string
+	opts    Options
+	metrics breakerMetrics
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	since    time.Time
+	failures int       // Consecutive failures while closed
+	trialAt  time.Time // Start of the trial call in flight while half open; zero when there is none
+}
+
+func newBreaker(name string, opts Options, metrics breakerMetrics, logger *zap.Logger) *Breaker {
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 10 * time.Second
+	}
+	b := &Breaker{name: name, opts: opts, metrics: metrics, logger: logger, now: time.Now, state: StateClosed}
+	b.since = b.now()
+	b.metrics.setState(StateClosed)
+	return b
+}
+
+// Do calls fn unless the breaker is open, in which case it returns ErrOpen.
+// failed tells the backend failing, e.g. a lost connection, from errors that
+// show it answering, such as a missing row; only the former count towards
+// opening the breaker, the latter reset the count like a success.
+func (b *Breaker) Do(fn func() error, failed func(error) bool) error {
+	if b == nil {
+		return fn()
+	}
+	if !b.allow() {
+		b.metrics.reject()
+		return ErrOpen
+	}
+	err := fn()
+	b.done(err != nil && failed(err))
+	return err
+}
+
+// allow reports whether a call may go ahead, letting the trial call through
+// once the open period has passed
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.since) < b.opts.OpenTimeout {
+			return false
+		}
+		b.transition(StateHalfOpen, now)
+		b.trialAt = now
+		return true
+	case StateHalfOpen:
+		// A trial that never reported back, e.g. because it panicked, is
+		// given up on after another open period
+		if !b.trialAt.IsZero() && now.Sub(b.trialAt) < b.opts.OpenTimeout {
+			return false
+		}
+		b.trialAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+// done records the outcome of a call let through by allow
+func (b *Breaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	switch {
+	case !failed:
+		b.failures = 0
+		if b.state != StateClosed {
+			b.transition(StateClosed, now)
+		}
+	case b.state == StateHalfOpen:
+		b.transition(StateOpen, now)
+	case b.state == StateClosed:
+		b.failures++
+		if b.failures >= b.opts.FailureThreshold {
+			b.transition(StateOpen, now)
+		}
+	}
+	if b.state != StateHalfOpen {
+		b.trialAt = time.Time{}
+	}
+}
+
+// transition moves the breaker to state; the caller holds mu
+func (b *Breaker) transition(state State, at time.Time) {
+	from := b.state
+	b.state, b.since = state, at
+	b.failures = 0
+	b.metrics.setState(state)
+
+	fields := []zap.Field{zap.String("breaker", b.name), zap.String("from", string(from)), zap.String("to", string(state))}
+	switch state {
+	case StateOpen:
+		b.logger.Warn("Circuit breaker opened", append(fields, zap.Duration("open_for", b.opts.OpenTimeout))...)
+	case StateClosed:
+		b.logger.Info("Circuit breaker closed", fields...)
+	}
+}
+
+// Snapshot returns the current state of the breaker
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Snapshot{Name: b.name, State: b.state, Since: b.since}
+}
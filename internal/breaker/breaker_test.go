@@ -0,0 +1,105 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errDown = errors.New("connection refused")
+
+func always(error) bool { return true }
+
+func TestBreaker(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	require.NoError(t, err)
+	group := NewGroup(true, Options{FailureThreshold: 3, OpenTimeout: time.Minute}, metrics, zap.NewNop())
+	b := group.Breaker("database")
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	assert.Same(t, b, group.Breaker("database"))
+
+	calls := 0
+	fail := func() error { calls++; return errDown }
+	succeed := func() error { calls++; return nil }
+
+	// A success resets the run of failures, as does an error saying nothing
+	// about the health of the backend
+	assert.ErrorIs(t, b.Do(fail, always), errDown)
+	assert.ErrorIs(t, b.Do(fail, always), errDown)
+	assert.NoError(t, b.Do(succeed, always))
+	assert.ErrorIs(t, b.Do(fail, always), errDown)
+	assert.ErrorIs(t, b.Do(fail, always), errDown)
+	assert.ErrorIs(t, b.Do(fail, func(error) bool { return false }), errDown)
+	assert.Equal(t, StateClosed, b.Snapshot().State)
+
+	for range 3 {
+		assert.ErrorIs(t, b.Do(fail, always), errDown)
+	}
+	assert.Equal(t, Snapshot{Name: "database", State: StateOpen, Since: now}, b.Snapshot())
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.state.WithLabelValues("database")))
+
+	calls = 0
+	assert.ErrorIs(t, b.Do(succeed, always), ErrOpen)
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.rejected.WithLabelValues("database")))
+
+	// A failed trial opens the breaker again
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, b.Do(fail, always), errDown)
+	assert.Equal(t, StateOpen, b.Snapshot().State)
+	assert.ErrorIs(t, b.Do(succeed, always), ErrOpen)
+
+	// A successful one closes it
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Do(succeed, always))
+	assert.Equal(t, Snapshot{Name: "database", State: StateClosed, Since: now}, b.Snapshot())
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.state.WithLabelValues("database")))
+	assert.Equal(t, 2, calls)
+}
+
+func TestBreaker_SingleTrial(t *testing.T) {
+	b := newBreaker("redis", Options{FailureThreshold: 1, OpenTimeout: time.Minute}, breakerMetrics{}, zap.NewNop())
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	assert.ErrorIs(t, b.Do(func() error { return errDown }, always), errDown)
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.Equal(t, StateHalfOpen, b.Snapshot().State)
+	assert.False(t, b.allow(), "only one trial at a time")
+
+	// A trial that never reports back is given up on
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+}
+
+func TestGroup_Disabled(t *testing.T) {
+	group := NewGroup(false, Options{}, nil, zap.NewNop())
+	b := group.Breaker("redis")
+
+	assert.Nil(t, b)
+	for range 100 {
+		assert.ErrorIs(t, b.Do(func() error { return errDown }, always), errDown)
+	}
+	assert.Empty(t, group.Snapshot())
+}
+
+func TestGroup_Snapshot(t *testing.T) {
+	group := NewGroup(true, Options{}, nil, zap.NewNop())
+	group.Breaker("redis")
+	group.Breaker("database")
+
+	snapshots := group.Snapshot()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "database", snapshots[0].Name)
+	assert.Equal(t, "redis", snapshots[1].Name)
+	assert.Equal(t, StateClosed, snapshots[1].State)
+}
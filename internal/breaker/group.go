@@ -0,0 +1,113 @@
+package breaker
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// stateValues are the values of the circuit_breaker_state gauge
+var stateValues = map[State]float64{StateClosed: 0, StateHalfOpen: 1, StateOpen: 2}
+
+// Metrics reports the state of every breaker and the calls they refused
+// using the breaker label
+type Metrics struct {
+	state    *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+// NewMetrics creates breaker metrics and registers them
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuit_breaker_state",
+			Help: "State of a circuit breaker: 0 closed, 1 half open, 2 open.",
+		}, []string{"breaker"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuit_breaker_rejected_total",
+			Help: "Total number of calls refused because a circuit breaker was open.",
+		}, []string{"breaker"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.state, m.rejected} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// breakerMetrics are the series of a single breaker. The zero value records nothing.
+type breakerMetrics struct {
+	state    prometheus.Gauge
+	rejected prometheus.Counter
+}
+
+func (m *Metrics) forBreaker(name string) breakerMetrics {
+	if m == nil {
+		return breakerMetrics{}
+	}
+	return breakerMetrics{state: m.state.WithLabelValues(name), rejected: m.rejected.WithLabelValues(name)}
+}
+
+func (m breakerMetrics) setState(state State) {
+	if m.state != nil {
+		m.state.Set(stateValues[state])
+	}
+}
+
+func (m breakerMetrics) reject() {
+	if m.rejected != nil {
+		m.rejected.Inc()
+	}
+}
+
+// Group creates the breakers of a process, one per backend, with the same
+// options, and reports their state to the readiness probe
+type Group struct {
+	enabled bool
+	opts    Options
+	metrics *Metrics
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup creates a Group. When enabled is false every breaker it hands
+// out is nil and lets all calls through. metrics may be nil.
+func NewGroup(enabled bool, opts Options, metrics *Metrics, logger *zap.Logger) *Group {
+	return &Group{enabled: enabled, opts: opts, metrics: metrics, logger: logger, breakers: map[string]*Breaker{}}
+}
+
+// Breaker returns the breaker of the named backend, creating it on first use
+func (g *Group) Breaker(name string) *Breaker {
+	if g == nil || !g.enabled {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[name]
+	if !ok {
+		b = newBreaker(name, g.opts, g.metrics.forBreaker(name), g.logger)
+		g.breakers[name] = b
+	}
+	return b
+}
+
+// Snapshot returns the state of every breaker, ordered by name
+func (g *Group) Snapshot() []Snapshot {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	snapshots := make([]Snapshot, 0, len(g.breakers))
+	for _, b := range g.breakers {
+		snapshots = append(snapshots, b.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots
+}
@@ -0,0 +1,70 @@
+// Package buildinfo describes the running binary. Version, Commit and Date
+// are set when building, e.g. by make build:
+//
+//	go build -ldflags "-X github.com/yi-tech/go-user-service/internal/buildinfo.Version=v1.2.0"
+//
+// Binaries built without them, such as with go run, fall back to the VCS
+// information the Go toolchain stamps into the binary.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X"
+var (
+	Version = "" // Release version, e.g. the output of git describe
+	Commit  = "" // Git commit the binary was built from
+	Date    = "" // When the binary was built, in RFC 3339 and UTC
+)
+
+// Info identifies the build of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build of the running binary. Values set with -ldflags
+// take precedence over the VCS information stamped by the Go toolchain;
+// Version is "dev" when neither knows it.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildDate: Date, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info = withVCS(info, build)
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// withVCS fills the fields of info left unset from the settings the Go
+// toolchain stamped into build
+func withVCS(info Info, build *debug.BuildInfo) Info {
+	if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	var revision, modified string
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if info.Commit == "" && revision != "" {
+		info.Commit = revision
+		if modified == "true" {
+			info.Commit += "-dirty"
+		}
+	}
+	return info
+}
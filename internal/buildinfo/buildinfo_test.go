@@ -0,0 +1,56 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithVCS(t *testing.T) {
+	build := &debug.BuildInfo{
+		Main: debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0d8e677"},
+			{Key: "vcs.time", Value: "2026-10-16T08:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		info     Info
+		build    *debug.BuildInfo
+		expected Info
+	}{
+		{
+			name:     "Stamped By The Toolchain",
+			build:    build,
+			expected: Info{Commit: "0d8e677-dirty", BuildDate: "2026-10-16T08:00:00Z"},
+		},
+		{
+			name:     "Set With Ldflags",
+			info:     Info{Version: "v1.2.0", Commit: "abc123", BuildDate: "2026-10-15_12:00:00"},
+			build:    build,
+			expected: Info{Version: "v1.2.0", Commit: "abc123", BuildDate: "2026-10-15_12:00:00"},
+		},
+		{
+			name:     "Module Version",
+			build:    &debug.BuildInfo{Main: debug.Module{Version: "v1.3.0"}},
+			expected: Info{Version: "v1.3.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, withVCS(tt.info, tt.build))
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	info := Get()
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
@@ -0,0 +1,182 @@
+// Package cache provides the bounded in-process cache used wherever the
+// service keeps data in memory, so that every cache has a size limit, expires
+// its entries and reports hit, miss and eviction metrics.
+package cache
+
+import (
+	"container/list"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Config bounds a cache. A cache without a positive MaxEntries and TTL is
+// disabled: lookups always miss and nothing is stored.
+type Config struct {
+	MaxEntries int           // Least recently used entries are evicted beyond this
+	TTL        time.Duration // Longest time an entry is served
+	// Jitter shortens each entry's TTL by a random fraction of up to Jitter
+	// (0 to 1), so that entries loaded together do not all expire together
+	Jitter float64
+}
+
+// Cache is a size-limited LRU cache whose entries expire. It is safe for
+// concurrent use. Values are returned as stored, so callers must not modify
+// values that other callers can see.
+type Cache[K comparable, V any] struct {
+	cfg     Config
+	metrics cacheMetrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	lru     *list.List // Front is the most recently used
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a cache. name labels its metrics; metrics may be nil.
+func New[K comparable, V any](name string, cfg Config, metrics *Metrics) *Cache[K, V] {
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Jitter > 1 {
+		cfg.Jitter = 1
+	}
+	return &Cache[K, V]{
+		cfg:     cfg,
+		metrics: metrics.forCache(name),
+		now:     time.Now,
+		entries: make(map[K]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Enabled reports whether the cache stores anything
+func (c *Cache[K, V]) Enabled() bool {
+	return c != nil && c.cfg.MaxEntries > 0 && c.cfg.TTL > 0
+}
+
+// Get returns the value stored for key if it has not expired
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if !c.Enabled() {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		inc(c.metrics.misses)
+		return zero, false
+	}
+	e := elem.Value.(*entry[K, V])
+	if !c.now().Before(e.expiresAt) {
+		c.remove(elem)
+		inc(c.metrics.evictedTTL)
+		inc(c.metrics.misses)
+		return zero, false
+	}
+
+	c.lru.MoveToFront(elem)
+	inc(c.metrics.hits)
+	return e.value, true
+}
+
+// Set stores value for key for the configured TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.cfgTTL())
+}
+
+// SetWithTTL stores value for key for at most ttl, and never longer than the
+// configured TTL. It suits values with their own expiry, such as tokens.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if !c.Enabled() {
+		return
+	}
+	if ttl > c.cfg.TTL {
+		ttl = c.cfg.TTL
+	}
+	if c.cfg.Jitter > 0 {
+		ttl -= time.Duration(rand.Float64() * c.cfg.Jitter * float64(ttl))
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	for c.lru.Len() >= c.cfg.MaxEntries {
+		c.evictOldest()
+	}
+	c.entries[key] = c.lru.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	c.metrics.setEntries(c.lru.Len())
+}
+
+// Delete removes the value stored for key, if any
+func (c *Cache[K, V]) Delete(key K) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of stored entries, including expired ones not yet removed
+func (c *Cache[K, V]) Len() int {
+	if !c.Enabled() {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache[K, V]) cfgTTL() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.cfg.TTL
+}
+
+// evictOldest makes room by removing the least recently used entry, counting
+// it as expired when it already was
+func (c *Cache[K, V]) evictOldest() {
+	elem := c.lru.Back()
+	if elem == nil {
+		return
+	}
+	if c.now().Before(elem.Value.(*entry[K, V]).expiresAt) {
+		inc(c.metrics.evictedFull)
+	} else {
+		inc(c.metrics.evictedTTL)
+	}
+	c.remove(elem)
+}
+
+func (c *Cache[K, V]) remove(elem *list.Element) {
+	delete(c.entries, elem.Value.(*entry[K, V]).key)
+	c.lru.Remove(elem)
+	c.metrics.setEntries(c.lru.Len())
+}
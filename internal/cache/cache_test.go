@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func newTestCache(t *testing.T, cfg Config) (*Cache[string, int], *Metrics, *fakeClock) {
+	t.Helper()
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	clock := &fakeClock{t: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	c := New[string, int]("test", cfg, metrics)
+	c.now = clock.now
+	return c, metrics, clock
+}
+
+func counter(m *Metrics, name string, labels ...string) float64 {
+	switch name {
+	case "hits":
+		return testutil.ToFloat64(m.hits.WithLabelValues(labels...))
+	case "misses":
+		return testutil.ToFloat64(m.misses.WithLabelValues(labels...))
+	case "entries":
+		return testutil.ToFloat64(m.entries.WithLabelValues(labels...))
+	default:
+		return testutil.ToFloat64(m.evictions.WithLabelValues(labels...))
+	}
+}
+
+func TestCache_GetSet(t *testing.T) {
+	c, m, _ := newTestCache(t, Config{MaxEntries: 10, TTL: time.Minute})
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Set("a", 1)
+	c.Set("a", 2)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, c.Len())
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	assert.Equal(t, float64(1), counter(m, "hits", "test"))
+	assert.Equal(t, float64(2), counter(m, "misses", "test"))
+	assert.Equal(t, float64(0), counter(m, "entries", "test"))
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, m, _ := newTestCache(t, Config{MaxEntries: 2, TTL: time.Minute})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // b is now the least recently used
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, float64(1), counter(m, "evictions", "test", evictedCapacity))
+	assert.Equal(t, float64(2), counter(m, "entries", "test"))
+}
+
+func TestCache_Expiry(t *testing.T) {
+	c, m, clock := newTestCache(t, Config{MaxEntries: 10, TTL: time.Minute})
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, 10*time.Second)
+	c.SetWithTTL("c", 3, time.Hour) // Capped at the configured TTL
+
+	clock.t = clock.t.Add(10 * time.Second)
+	_, ok := c.Get("b")
+	assert.False(t, ok, "expires at its own TTL")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+
+	clock.t = clock.t.Add(50 * time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	_, ok = c.Get("c")
+	assert.False(t, ok, "never outlives the configured TTL")
+
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, float64(3), counter(m, "evictions", "test", evictedExpired))
+}
+
+func TestCache_ExpiredEntriesMakeRoomFirst(t *testing.T) {
+	c, m, clock := newTestCache(t, Config{MaxEntries: 1, TTL: time.Minute})
+
+	c.Set("a", 1)
+	clock.t = clock.t.Add(time.Minute)
+	c.Set("b", 2)
+
+	assert.Equal(t, float64(1), counter(m, "evictions", "test", evictedExpired))
+	assert.Equal(t, float64(0), counter(m, "evictions", "test", evictedCapacity))
+}
+
+func TestCache_Jitter(t *testing.T) {
+	c, _, clock := newTestCache(t, Config{MaxEntries: 1000, TTL: time.Minute, Jitter: 0.5})
+
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprint(i), i)
+	}
+
+	// Every entry lives between half and all of the TTL, and not all the same time
+	clock.t = clock.t.Add(30*time.Second - time.Nanosecond)
+	for i := 0; i < 1000; i++ {
+		_, ok := c.Get(fmt.Sprint(i))
+		require.True(t, ok)
+	}
+	clock.t = clock.t.Add(15 * time.Second)
+	expired := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := c.Get(fmt.Sprint(i)); !ok {
+			expired++
+		}
+	}
+	assert.Greater(t, expired, 0)
+	assert.Less(t, expired, 1000)
+
+	clock.t = clock.t.Add(15 * time.Second)
+	assert.Equal(t, 0, countLive(c))
+}
+
+// countLive returns the number of entries that have not expired
+func countLive(c *Cache[string, int]) int {
+	live := 0
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		if c.now().Before(e.Value.(*entry[string, int]).expiresAt) {
+			live++
+		}
+	}
+	return live
+}
+
+func TestCache_Disabled(t *testing.T) {
+	for _, cfg := range []Config{{}, {MaxEntries: 10}, {TTL: time.Minute}} {
+		c := New[string, int]("disabled", cfg, nil)
+		c.Set("a", 1)
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.False(t, c.Enabled())
+	}
+
+	var c *Cache[string, int]
+	c.Set("a", 1)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New[int, int]("concurrent", Config{MaxEntries: 50, TTL: time.Minute}, nil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Set(g*1000+i, i)
+				c.Get(g*1000 + i/2)
+				c.Delete(g*1000 + i/3)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, c.Len(), 50)
+}
+
+func TestNewMetrics_SharedByCaches(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	a := New[string, int]("a", Config{MaxEntries: 1, TTL: time.Minute}, metrics)
+	b := New[string, int]("b", Config{MaxEntries: 1, TTL: time.Minute}, metrics)
+	a.Get("x")
+	b.Set("x", 1)
+	b.Get("x")
+
+	assert.Equal(t, float64(1), counter(metrics, "misses", "a"))
+	assert.Equal(t, float64(1), counter(metrics, "hits", "b"))
+}
+
+func TestMetrics_Counter(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	c := metrics.Counter("remote")
+	c.Hit()
+	c.Hit()
+	c.Miss()
+
+	assert.Equal(t, float64(2), counter(metrics, "hits", "remote"))
+	assert.Equal(t, float64(1), counter(metrics, "misses", "remote"))
+
+	// A counter without metrics records nothing
+	(*Metrics)(nil).Counter("remote").Hit()
+}
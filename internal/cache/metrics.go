@@ -0,0 +1,100 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Eviction reasons reported in the reason label
+const (
+	evictedCapacity = "capacity"
+	evictedExpired  = "expired"
+)
+
+// Metrics counts hits, misses and evictions per cache using the cache label.
+// One Metrics is shared by every cache registered with the same registry.
+type Metrics struct {
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	entries   *prometheus.GaugeVec
+}
+
+// NewMetrics creates cache metrics and registers them
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total number of lookups served from a cache.",
+		}, []string{"cache"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total number of lookups not found in a cache, including expired entries.",
+		}, []string{"cache"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of entries removed from an in-process cache to stay within its size limit or because they expired.",
+		}, []string{"cache", "reason"}),
+		entries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cache_entries",
+			Help: "Number of entries currently held by an in-process cache.",
+		}, []string{"cache"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.hits, m.misses, m.evictions, m.entries} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// cacheMetrics are the series of a single cache. The zero value records nothing.
+type cacheMetrics struct {
+	hits, misses            prometheus.Counter
+	evictedFull, evictedTTL prometheus.Counter
+	entries                 prometheus.Gauge
+}
+
+func (m *Metrics) forCache(name string) cacheMetrics {
+	if m == nil {
+		return cacheMetrics{}
+	}
+	return cacheMetrics{
+		hits:        m.hits.WithLabelValues(name),
+		misses:      m.misses.WithLabelValues(name),
+		evictedFull: m.evictions.WithLabelValues(name, evictedCapacity),
+		evictedTTL:  m.evictions.WithLabelValues(name, evictedExpired),
+		entries:     m.entries.WithLabelValues(name),
+	}
+}
+
+// Counter records the hits and misses of a cache kept outside this package,
+// such as one in Redis, under the same metrics as the in-process caches
+type Counter struct {
+	metrics cacheMetrics
+}
+
+// Counter returns the hit and miss counter of the named cache; m may be nil
+func (m *Metrics) Counter(name string) Counter {
+	return Counter{metrics: m.forCache(name)}
+}
+
+// Hit records a lookup served from the cache
+func (c Counter) Hit() {
+	inc(c.metrics.hits)
+}
+
+// Miss records a lookup not found in the cache
+func (c Counter) Miss() {
+	inc(c.metrics.misses)
+}
+
+func inc(c prometheus.Counter) {
+	if c != nil {
+		c.Inc()
+	}
+}
+
+func (m cacheMetrics) setEntries(n int) {
+	if m.entries != nil {
+		m.entries.Set(float64(n))
+	}
+}
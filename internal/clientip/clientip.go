@@ -0,0 +1,37 @@
+// Package clientip resolves the address of the client behind the load
+// balancers and proxies the service is deployed behind. The HTTP middleware
+// and the gRPC interceptor resolve it once per request and attach it to the
+// request context, so sessions, audit logs, rate limits and IP filters all
+// see the same address.
+package clientip
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the client address ip
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client address attached to ctx, or an empty
+// string when there is none, as in background jobs
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
+
+// FromRequest returns the client address of r: the address attached to its
+// context, or the peer address when none was resolved
+func FromRequest(r *http.Request) string {
+	if ip, ok := r.Context().Value(contextKey{}).(string); ok {
+		return ip
+	}
+	if addr := ParseAddr(r.RemoteAddr); addr.IsValid() {
+		return addr.String()
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package clientip
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "203.0.113.7", FromContext(NewContext(context.Background(), "203.0.113.7")))
+}
+
+func TestFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.2:4711"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	// Without a resolved address only the peer counts
+	assert.Equal(t, "10.0.0.2", FromRequest(req))
+
+	resolved := req.WithContext(NewContext(req.Context(), "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", FromRequest(resolved))
+}
@@ -0,0 +1,142 @@
+package clientip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// DefaultHeaders are the forwarding headers read when none are configured
+var DefaultHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// Resolver finds the client address of a request. Like Gin's ClientIP, it
+// walks each forwarding header from the nearest hop and stops at the first
+// address that is not a trusted proxy; when every hop is trusted it takes
+// the farthest one. Headers are ignored unless the peer itself is a trusted
+// proxy, so clients cannot pick the address they are identified by.
+type Resolver struct {
+	trusted []netip.Prefix
+	headers []string
+}
+
+// NewResolver creates a Resolver trusting the headers, DefaultHeaders when
+// none are given, set by the given proxy addresses and CIDR ranges
+func NewResolver(trustedProxies, headers []string) (*Resolver, error) {
+	trusted, err := ParsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	canonical := make([]string, 0, len(headers))
+	for _, header := range headers {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			return nil, errors.New("client IP header names must not be empty")
+		}
+		canonical = append(canonical, http.CanonicalHeaderKey(header))
+	}
+	return &Resolver{trusted: trusted, headers: canonical}, nil
+}
+
+// Headers returns the forwarding headers read, in canonical form
+func (r *Resolver) Headers() []string {
+	return r.headers
+}
+
+// Resolve returns the client address of a request received from remote, a
+// host or host:port. values returns the values of a forwarding header, such
+// as http.Header.Values. The result is empty when remote holds no IP address.
+func (r *Resolver) Resolve(remote string, values func(header string) []string) string {
+	peer := ParseAddr(remote)
+	if !peer.IsValid() {
+		return ""
+	}
+	if !Contains(r.trusted, peer) {
+		return peer.String()
+	}
+
+	// The client is the nearest hop that is not one of our proxies. A header
+	// listing proxies only, as when the gateway forwards a request a proxy
+	// named the client of in X-Real-IP, defers to the next header.
+	var farthest netip.Addr
+	for _, header := range r.headers {
+		var hops []string
+		for _, value := range values(header) {
+			hops = append(hops, strings.Split(value, ",")...)
+		}
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// A proxy we trust would not have written this; skip the header
+				break
+			}
+			hop = hop.Unmap()
+			if !Contains(r.trusted, hop) {
+				return hop.String()
+			}
+			if i == 0 && !farthest.IsValid() {
+				farthest = hop
+			}
+		}
+	}
+	if farthest.IsValid() {
+		return farthest.String()
+	}
+	return peer.String()
+}
+
+// ParsePrefixes parses addresses, such as "203.0.113.7", and CIDR ranges,
+// such as "10.0.0.0/8". A single address is a range of its own.
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+			}
+			if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+				prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ParseAddr parses a host or host:port, such as a peer or RemoteAddr, into
+// an address. The result is invalid when addr holds no IP address.
+func ParseAddr(addr string) netip.Addr {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+// Contains reports whether any of prefixes contains addr
+func Contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
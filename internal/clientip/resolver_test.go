@@ -0,0 +1,68 @@
+package clientip
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "127.0.0.1"}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remote       string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{"Direct Client", "198.51.100.1:4711", nil, "", "198.51.100.1"},
+		{"Untrusted Peer Cannot Forward", "198.51.100.1:4711", []string{"203.0.113.7"}, "203.0.113.8", "198.51.100.1"},
+		{"Behind Trusted Proxy", "10.0.0.2:4711", []string{"203.0.113.7"}, "", "203.0.113.7"},
+		{"Nearest Untrusted Hop", "10.0.0.2:4711", []string{"192.0.2.1, 203.0.113.7, 10.0.0.3"}, "", "203.0.113.7"},
+		{"Hops Across Headers", "10.0.0.2:4711", []string{"203.0.113.7", "10.0.0.3"}, "", "203.0.113.7"},
+		{"X-Real-IP", "10.0.0.2:4711", nil, "203.0.113.9", "203.0.113.9"},
+		{"X-Real-IP Behind Gateway", "127.0.0.1:4711", []string{"10.0.0.2"}, "203.0.113.9", "203.0.113.9"},
+		{"Only Proxies", "10.0.0.2:4711", []string{"10.0.0.4, 10.0.0.3"}, "", "10.0.0.4"},
+		{"Invalid Hop", "10.0.0.2:4711", []string{"unknown, 10.0.0.3"}, "", "10.0.0.2"},
+		{"IPv4-Mapped Peer", "[::ffff:10.0.0.2]:4711", []string{"203.0.113.7"}, "", "203.0.113.7"},
+		{"Peer Without Port", "198.51.100.1", nil, "", "198.51.100.1"},
+		{"Peer Without Address", "bufconn", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, value := range tt.forwardedFor {
+				header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				header.Set("X-Real-IP", tt.realIP)
+			}
+
+			assert.Equal(t, tt.want, resolver.Resolve(tt.remote, header.Values))
+		})
+	}
+}
+
+func TestResolver_ConfiguredHeaders(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8"}, []string{"cf-connecting-ip"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Cf-Connecting-Ip"}, resolver.Headers())
+
+	header := http.Header{}
+	header.Set("CF-Connecting-IP", "203.0.113.7")
+	header.Set("X-Forwarded-For", "198.51.100.1")
+
+	assert.Equal(t, "203.0.113.7", resolver.Resolve("10.0.0.2:4711", header.Values))
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	_, err := NewResolver([]string{"proxy.internal"}, nil)
+	assert.ErrorContains(t, err, `invalid trusted proxies: "proxy.internal" is not an IP address or CIDR range`)
+
+	_, err = NewResolver(nil, []string{" "})
+	assert.ErrorContains(t, err, "client IP header names must not be empty")
+}
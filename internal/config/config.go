@@ -3,64 +3,1142 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	GRPC     GRPCConfig     `mapstructure:"grpc"`
+	App          AppConfig          `mapstructure:"app"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Redis        RedisConfig        `mapstructure:"redis"`
+	AuthStore    AuthStoreConfig    `mapstructure:"auth_store"`
+	JWT          JWTConfig          `mapstructure:"jwt"`
+	GRPC         GRPCConfig         `mapstructure:"grpc"`
+	Metrics      MetricsConfig      `mapstructure:"metrics"`
+	Debug        DebugConfig        `mapstructure:"debug"`
+	Response     ResponseConfig     `mapstructure:"response"`
+	CacheControl CacheControlConfig `mapstructure:"cache_control"`
+	Limits       LimitsConfig       `mapstructure:"limits"`
+	Deadlines    DeadlinesConfig    `mapstructure:"deadlines"`
+	IPFilter     IPFilterConfig     `mapstructure:"ip_filter"`
+	Availability AvailabilityConfig `mapstructure:"availability"`
+	Registration RegistrationConfig `mapstructure:"registration"`
+	Email        EmailConfig        `mapstructure:"email"`
+	Login        LoginConfig        `mapstructure:"login"`
+	Compliance   ComplianceConfig   `mapstructure:"compliance"`
+	Password     PasswordConfig     `mapstructure:"password"`
+	Import       ImportConfig       `mapstructure:"import"`
+	Export       ExportConfig       `mapstructure:"export"`
+	Cache        CacheConfig        `mapstructure:"cache"`
+	APIKeys      APIKeysConfig      `mapstructure:"api_keys"`
+	Health       HealthConfig       `mapstructure:"health"`
+	Breaker      BreakerConfig      `mapstructure:"circuit_breaker"`
+	FeatureFlags FeatureFlagsConfig `mapstructure:"feature_flags"`
+	Notification NotificationConfig `mapstructure:"notification"`
+	Jobs         JobsConfig         `mapstructure:"jobs"`
+	Webhooks     WebhooksConfig     `mapstructure:"webhooks"`
+	SAML         SAMLConfig         `mapstructure:"saml"`
+	Audit        AuditConfig        `mapstructure:"audit"`
+	Realtime     RealtimeConfig     `mapstructure:"realtime"`
+	Compression  CompressionConfig  `mapstructure:"compression"`
+	Errors       ErrorsConfig       `mapstructure:"error_reporting"`
+	Log          LogConfig          `mapstructure:"log"`
+	Watch        WatchConfig        `mapstructure:"config_watch"`
+
+	// Sources lists where the configuration was read from, for the startup report
+	Sources []string `mapstructure:"-"`
+
+	options Options // How the configuration was loaded, so a Watcher can reload it alike
 }
 
 type AppConfig struct {
-	Name string `mapstructure:"name"`
-	Env  string `mapstructure:"env"`
-	Port int    `mapstructure:"port"`
+	Name       string `mapstructure:"name"`
+	Env        string `mapstructure:"env"`
+	Port       int    `mapstructure:"port"`
+	IDStrategy string `mapstructure:"id_strategy"` // uuidv4 (default), uuidv7 or ulid
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For and
+	// X-Real-IP headers are trusted; when empty the client IP is the peer address
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// ClientIPHeaders are the headers trusted proxies name the client in, in
+	// order of preference; X-Forwarded-For and X-Real-IP when empty
+	ClientIPHeaders []string `mapstructure:"client_ip_headers"`
+	// AdminPort serves the ops endpoints, /metrics, health, /debug and the
+	// admin API, on a listener of their own so they can be firewalled apart
+	// from the API; 0 serves them on Port as well
+	AdminPort int `mapstructure:"admin_port"`
+	// ReadOnly starts the API in read-only mode, e.g. during a database
+	// failover; administrators can toggle it at runtime
+	ReadOnly bool `mapstructure:"read_only"`
 }
 
 type DatabaseConfig struct {
-	Driver string `mapstructure:"driver"`
-	Source string `mapstructure:"source"`
+	Driver                 string              `mapstructure:"driver"`
+	Source                 string              `mapstructure:"source"`
+	ReplicaSources         []string            `mapstructure:"replica_sources"`           // Read replicas serving user lookups and listings; empty reads from the primary
+	MaxOpenConns           int                 `mapstructure:"max_open_conns"`            // 0 means 100
+	MaxIdleConns           int                 `mapstructure:"max_idle_conns"`            // 0 means 10
+	ConnMaxLifetimeSeconds int                 `mapstructure:"conn_max_lifetime_seconds"` // 0 keeps connections open indefinitely
+	SlowQueryThresholdMs   int                 `mapstructure:"slow_query_threshold_ms"`   // Queries taking longer are logged; 0 disables the log
+	Retry                  DatabaseRetryConfig `mapstructure:"retry"`
+}
+
+// DatabaseRetryConfig controls how repository calls failing with a transient
+// error, such as a reset connection, a deadlock or a serialization failure,
+// are retried, separately for reads and writes
+type DatabaseRetryConfig struct {
+	Reads  RetryConfig `mapstructure:"reads"`
+	Writes RetryConfig `mapstructure:"writes"`
+}
+
+// RetryConfig controls the retries of a kind of operation
+type RetryConfig struct {
+	Attempts     int `mapstructure:"attempts"`       // Tries per call, including the first; 0 or 1 disables retries
+	BackoffMs    int `mapstructure:"backoff_ms"`     // Wait before the second try, doubling for each further one, with jitter
+	MaxBackoffMs int `mapstructure:"max_backoff_ms"` // Longest wait between tries; 0 means no limit
+}
+
+// Backoff returns the wait before the second try
+func (c RetryConfig) Backoff() time.Duration {
+	return time.Duration(c.BackoffMs) * time.Millisecond
+}
+
+// MaxBackoff returns the longest wait between tries
+func (c RetryConfig) MaxBackoff() time.Duration {
+	return time.Duration(c.MaxBackoffMs) * time.Millisecond
+}
+
+// Default connection pool limits
+const (
+	defaultMaxOpenConns = 100
+	defaultMaxIdleConns = 10
+)
+
+// OpenConns returns the most connections the pool may open
+func (c DatabaseConfig) OpenConns() int {
+	if c.MaxOpenConns <= 0 {
+		return defaultMaxOpenConns
+	}
+	return c.MaxOpenConns
+}
+
+// IdleConns returns the most idle connections the pool keeps, never more than it may open
+func (c DatabaseConfig) IdleConns() int {
+	idle := c.MaxIdleConns
+	if idle <= 0 {
+		idle = defaultMaxIdleConns
+	}
+	return min(idle, c.OpenConns())
+}
+
+// ConnMaxLifetime returns how long a connection may be reused
+func (c DatabaseConfig) ConnMaxLifetime() time.Duration {
+	return time.Duration(c.ConnMaxLifetimeSeconds) * time.Second
+}
+
+// SlowQueryThreshold returns the duration above which queries are logged as slow
+func (c DatabaseConfig) SlowQueryThreshold() time.Duration {
+	return time.Duration(c.SlowQueryThresholdMs) * time.Millisecond
 }
 
 type RedisConfig struct {
-	Addr     string `mapstructure:"addr"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	Addr      string              `mapstructure:"addr"`
+	Password  string              `mapstructure:"password"`
+	DB        int                 `mapstructure:"db"`
+	KeyPrefix string              `mapstructure:"key_prefix"` // Deployment name such as "prod" or "prod:acme" prefixed to every key
+	Failover  RedisFailoverConfig `mapstructure:"failover"`
+}
+
+// AuthStoreConfig selects where refresh tokens and sign-in sessions are kept
+type AuthStoreConfig struct {
+	// Driver is redis (default), postgres or memory. The memory store is
+	// lost on restart and not shared between instances.
+	Driver string `mapstructure:"driver"`
+}
+
+// RedisFailoverConfig controls how the auth paths ride out a Redis failover
+type RedisFailoverConfig struct {
+	// SentinelMaster and SentinelAddrs connect through Redis Sentinel, which
+	// points the client at the new primary after a failover; Addr is ignored
+	SentinelMaster string   `mapstructure:"sentinel_master"`
+	SentinelAddrs  []string `mapstructure:"sentinel_addrs"`
+	RetryAttempts  int      `mapstructure:"retry_attempts"`   // Tries per command while failing over
+	RetryBackoffMs int      `mapstructure:"retry_backoff_ms"` // Wait before the first retry, doubling after each
+	// StatelessFallback signs users in with an access token only while Redis
+	// is unreachable; access tokens keep validating as they need no Redis.
+	// Refreshing and signing out fail until Redis is back.
+	StatelessFallback bool `mapstructure:"stateless_fallback"`
+}
+
+// Attempts returns the tries per command, defaulting to 3
+func (c RedisFailoverConfig) Attempts() int {
+	if c.RetryAttempts <= 0 {
+		return 3
+	}
+	return c.RetryAttempts
+}
+
+// Backoff returns the wait before the first retry, defaulting to 100ms
+func (c RedisFailoverConfig) Backoff() time.Duration {
+	if c.RetryBackoffMs <= 0 {
+		return 100 * time.Millisecond
+	}
+	return time.Duration(c.RetryBackoffMs) * time.Millisecond
 }
 
 type JWTConfig struct {
-	Secret                   string `mapstructure:"secret"`
-	AccessTokenExpireMinutes int    `mapstructure:"access_token_expire_minutes"`
-	RefreshTokenExpireDays   int    `mapstructure:"refresh_token_expire_days"`
+	Secret                           string         `mapstructure:"secret"`
+	AccessTokenExpireMinutes         int            `mapstructure:"access_token_expire_minutes"`
+	RefreshTokenExpireDays           int            `mapstructure:"refresh_token_expire_days"`
+	RememberMeRefreshTokenExpireDays int            `mapstructure:"remember_me_refresh_token_expire_days"` // Sign-ins with "remember me"
+	ImpersonationTokenExpireMinutes  int            `mapstructure:"impersonation_token_expire_minutes"`    // Tokens minted for support staff
+	Algorithm                        string         `mapstructure:"algorithm"`                             // HS256 (default), RS256 or ES256
+	CurrentKeyID                     string         `mapstructure:"current_key_id"`
+	Keys                             []JWTKeyConfig `mapstructure:"keys"`
+	KeyRefreshSeconds                int            `mapstructure:"key_refresh_seconds"` // How long keys generated by rotation are cached
+}
+
+// AccessTokenExpiry returns how long access tokens are valid
+func (c JWTConfig) AccessTokenExpiry() time.Duration {
+	return time.Duration(c.AccessTokenExpireMinutes) * time.Minute
+}
+
+// RefreshTokenExpiry returns how long refresh tokens are valid. Those of
+// "remember me" sign-ins default to 30 days.
+func (c JWTConfig) RefreshTokenExpiry(rememberMe bool) time.Duration {
+	if !rememberMe {
+		return time.Duration(c.RefreshTokenExpireDays) * 24 * time.Hour
+	}
+	if c.RememberMeRefreshTokenExpireDays <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.RememberMeRefreshTokenExpireDays) * 24 * time.Hour
+}
+
+// KeyRefresh returns how long the signing keys generated by rotation are
+// cached before they are loaded again, 60 seconds by default
+func (c JWTConfig) KeyRefresh() time.Duration {
+	if c.KeyRefreshSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.KeyRefreshSeconds) * time.Second
+}
+
+// ImpersonationTokenExpiry returns how long impersonation tokens are valid,
+// 30 minutes by default
+func (c JWTConfig) ImpersonationTokenExpiry() time.Duration {
+	if c.ImpersonationTokenExpireMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.ImpersonationTokenExpireMinutes) * time.Minute
+}
+
+// JWTKeyConfig is a named key used to sign or verify access tokens.
+// The key matching CurrentKeyID signs new tokens; the others are only
+// accepted for verification so tokens survive a key rotation.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Secret         string `mapstructure:"secret"`           // HS256 shared secret
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM private key for RS256/ES256
 }
 
 type GRPCConfig struct {
+	Port                     int                 `mapstructure:"port"`
+	Reflection               bool                `mapstructure:"reflection"`
+	MaxRecvMsgSizeBytes      int                 `mapstructure:"max_recv_msg_size_bytes"`
+	MaxSendMsgSizeBytes      int                 `mapstructure:"max_send_msg_size_bytes"`
+	ConnectionTimeoutSeconds int                 `mapstructure:"connection_timeout_seconds"`
+	Keepalive                GRPCKeepaliveConfig `mapstructure:"keepalive"`
+	// MaxInFlight caps the unary RPCs served at once and AuthMaxInFlight,
+	// separately, the sign-in, registration, refresh and sign-out RPCs; the
+	// rest get UNAVAILABLE. 0 is unlimited.
+	MaxInFlight     int `mapstructure:"max_in_flight"`
+	AuthMaxInFlight int `mapstructure:"auth_max_in_flight"`
+}
+
+// MetricsConfig holds the internal listener serving Prometheus metrics. It is
+// separate from the public HTTP port; 0 disables the listener.
+type MetricsConfig struct {
 	Port int `mapstructure:"port"`
 }
 
-func LoadConfig() (*Config, error) {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "dev" // Default to dev environment
+// DebugConfig holds the diagnostics served to administrators on the public
+// HTTP port, next to /debug/info
+type DebugConfig struct {
+	// Pprof serves the net/http/pprof profiles under /debug/pprof. Profiling
+	// costs CPU while a profile is taken, so it is off unless needed.
+	Pprof bool `mapstructure:"pprof"`
+}
+
+// GRPCKeepaliveConfig holds gRPC server keepalive settings; zero values keep the gRPC defaults
+type GRPCKeepaliveConfig struct {
+	TimeSeconds                  int  `mapstructure:"time_seconds"`
+	TimeoutSeconds               int  `mapstructure:"timeout_seconds"`
+	MinTimeSeconds               int  `mapstructure:"min_time_seconds"`
+	PermitWithoutStream          bool `mapstructure:"permit_without_stream"`
+	MaxConnectionIdleSeconds     int  `mapstructure:"max_connection_idle_seconds"`
+	MaxConnectionAgeSeconds      int  `mapstructure:"max_connection_age_seconds"`
+	MaxConnectionAgeGraceSeconds int  `mapstructure:"max_connection_age_grace_seconds"`
+}
+
+// ResponseConfig selects the HTTP response envelope ("default" or "jsonapi"),
+// globally and per route group ("system", "users", "auth", "profile", "account", "org", "orgs" or "admin").
+type ResponseConfig struct {
+	Format string            `mapstructure:"format"`
+	Groups map[string]string `mapstructure:"groups"`
+}
+
+// FormatFor returns the response format configured for a route group
+func (c ResponseConfig) FormatFor(group string) string {
+	if format, ok := c.Groups[group]; ok {
+		return format
+	}
+	return c.Format
+}
+
+// CacheControlConfig sets the caching headers of successful responses per route group
+type CacheControlConfig struct {
+	Default CachePolicyConfig            `mapstructure:"default"`
+	Groups  map[string]CachePolicyConfig `mapstructure:"groups"`
+}
+
+// CachePolicyConfig is the caching policy of a route group
+type CachePolicyConfig struct {
+	CacheControl string   `mapstructure:"cache_control"` // e.g. "no-store" or "private, max-age=30"; empty sets no headers
+	Vary         []string `mapstructure:"vary"`          // Request headers the responses depend on
+}
+
+// PolicyFor returns the caching policy configured for a route group
+func (c CacheControlConfig) PolicyFor(group string) CachePolicyConfig {
+	if policy, ok := c.Groups[group]; ok {
+		return policy
+	}
+	return c.Default
+}
+
+// LimitsConfig bounds the time and request body size of requests per route group
+type LimitsConfig struct {
+	Default LimitConfig            `mapstructure:"default"`
+	Groups  map[string]LimitConfig `mapstructure:"groups"`
+}
+
+// LimitConfig holds the request limits of a route group; zero fields take the default
+type LimitConfig struct {
+	TimeoutSeconds int   `mapstructure:"timeout_seconds"`
+	MaxBodyBytes   int64 `mapstructure:"max_body_bytes"`
+	// MaxInFlight caps the requests of the group served at once; the rest
+	// get 503 with Retry-After. Each group has its own budget.
+	MaxInFlight int `mapstructure:"max_in_flight"`
+}
+
+// LimitFor returns the request limits of a route group, filling what the
+// group leaves unset from the default: 30 seconds, 1 MiB and no cap on
+// requests in flight
+func (c LimitsConfig) LimitFor(group string) LimitConfig {
+	limit := c.Groups[group]
+	if limit.TimeoutSeconds <= 0 {
+		limit.TimeoutSeconds = c.Default.TimeoutSeconds
+	}
+	if limit.TimeoutSeconds <= 0 {
+		limit.TimeoutSeconds = 30
+	}
+	if limit.MaxBodyBytes <= 0 {
+		limit.MaxBodyBytes = c.Default.MaxBodyBytes
+	}
+	if limit.MaxBodyBytes <= 0 {
+		limit.MaxBodyBytes = 1 << 20
+	}
+	if limit.MaxInFlight <= 0 {
+		limit.MaxInFlight = c.Default.MaxInFlight
+	}
+	return limit
+}
+
+// Timeout returns how long requests of the route group may take
+func (c LimitConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// DeadlinesConfig bounds how long a service operation may wait on the
+// database, within the request timeout of its route group. Operations that
+// run out of time fail with 504 TIMEOUT (gRPC DEADLINE_EXCEEDED). A negative
+// value removes the deadline.
+type DeadlinesConfig struct {
+	ReadMs  int `mapstructure:"read_ms"`  // Lookups
+	WriteMs int `mapstructure:"write_ms"` // Registrations, updates and deletions
+}
+
+// Read returns how long a lookup may take: 2 seconds when unset, and 0,
+// meaning no deadline, when the configured value is negative
+func (c DeadlinesConfig) Read() time.Duration {
+	return deadlineOf(c.ReadMs, 2*time.Second)
+}
+
+// Write returns how long a change may take: 5 seconds when unset, and 0,
+// meaning no deadline, when the configured value is negative
+func (c DeadlinesConfig) Write() time.Duration {
+	return deadlineOf(c.WriteMs, 5*time.Second)
+}
+
+// deadlineOf converts a configured deadline in milliseconds
+func deadlineOf(ms int, fallback time.Duration) time.Duration {
+	switch {
+	case ms < 0:
+		return 0
+	case ms == 0:
+		return fallback
 	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// IPFilterConfig restricts the client addresses served. Requests must pass
+// the global rules and the rules of their route group; over gRPC the user,
+// auth, organization and admin services take the rules of the users, auth,
+// orgs and admin groups.
+type IPFilterConfig struct {
+	Global IPRules            `mapstructure:"global"`
+	Groups map[string]IPRules `mapstructure:"groups"`
+}
+
+// IPRules lists IP addresses and CIDR ranges to allow and deny. Denied
+// addresses are rejected even when allowed; with an allow list, only the
+// addresses on it are served.
+type IPRules struct {
+	Allow []string `mapstructure:"allow"`
+	Deny  []string `mapstructure:"deny"`
+}
 
-	v := viper.New()
-	v.SetConfigName(fmt.Sprintf("config.%s", env))
-	v.SetConfigType("yaml")
-	v.AddConfigPath("./configs") // Look for config in the configs directory
+// AvailabilityConfig throttles the public signup availability check
+type AvailabilityConfig struct {
+	RequestsPerMinute int           `mapstructure:"requests_per_minute"` // per client IP
+	MinResponseMs     int           `mapstructure:"min_response_ms"`     // masks lookup timing
+	Captcha           CaptchaConfig `mapstructure:"captcha"`
+}
 
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// RateLimit returns the per-client request budget, defaulting to 10 per minute
+func (c AvailabilityConfig) RateLimit() int {
+	if c.RequestsPerMinute <= 0 {
+		return 10
 	}
+	return c.RequestsPerMinute
+}
 
-	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// MinResponse returns the minimum response time, defaulting to 250ms
+func (c AvailabilityConfig) MinResponse() time.Duration {
+	if c.MinResponseMs <= 0 {
+		return 250 * time.Millisecond
 	}
+	return time.Duration(c.MinResponseMs) * time.Millisecond
+}
+
+// CaptchaConfig configures a reCAPTCHA/hCaptcha/Turnstile compatible verifier.
+// Provider is "hcaptcha", "recaptcha" or "turnstile" and supplies the
+// provider's siteverify endpoint unless VerifyURL overrides it; without a
+// provider, VerifyURL is required. MinScore rejects reCAPTCHA v3 tokens
+// scored below it; 0 accepts any score.
+type CaptchaConfig struct {
+	Enabled   bool    `mapstructure:"enabled"`
+	Provider  string  `mapstructure:"provider"`
+	VerifyURL string  `mapstructure:"verify_url"`
+	Secret    string  `mapstructure:"secret"`
+	MinScore  float64 `mapstructure:"min_score"`
+}
 
-	return &cfg, nil
+// RegistrationConfig configures self-service sign-up
+type RegistrationConfig struct {
+	// Captcha requires a CAPTCHA token (X-Captcha-Token header,
+	// x-captcha-token gRPC metadata) on every registration when enabled
+	Captcha      CaptchaConfig      `mapstructure:"captcha"`
+	EmailDomains EmailDomainsConfig `mapstructure:"email_domains"`
+}
+
+// EmailConfig controls how email addresses are normalized. Accounts are
+// looked up and kept unique by the normalized form of their address, which
+// is always lower-cased with its domain in punycode. CollapseGmailAliases
+// also ignores the dots and +tags Gmail ignores; addresses stored before it
+// was enabled keep their form until `make emails-normalize` rewrites them,
+// and are only found by the spelling they were stored with until then.
+type EmailConfig struct {
+	CollapseGmailAliases bool `mapstructure:"collapse_gmail_aliases"`
+}
+
+// EmailDomainsConfig restricts the email addresses accounts may sign up
+// with. Every address must pass the global rules and the rules of the
+// tenant it signs up for, if any; self-service registrations have no tenant.
+type EmailDomainsConfig struct {
+	Global  EmailDomainRules            `mapstructure:"global"`
+	Tenants map[string]EmailDomainRules `mapstructure:"tenants"`
+}
+
+// EmailDomainRules lists email domains to allow and deny; a domain also
+// covers its subdomains. Denied domains are rejected even when allowed; with
+// an allow list, only the domains on it may sign up. BlockDisposable rejects
+// the domains of known disposable email services.
+type EmailDomainRules struct {
+	Allow           []string `mapstructure:"allow"`
+	Deny            []string `mapstructure:"deny"`
+	BlockDisposable bool     `mapstructure:"block_disposable"`
+}
+
+// LoginConfig escalates sign-in to a CAPTCHA challenge. Once a client IP or
+// an account has CaptchaAfterFailures failed attempts, each further attempt
+// must carry a CAPTCHA token until no attempt has failed for the failure
+// window; 0 disables the escalation.
+type LoginConfig struct {
+	CaptchaAfterFailures int                `mapstructure:"captcha_after_failures"`
+	FailureWindowSeconds int                `mapstructure:"failure_window_seconds"`
+	Captcha              CaptchaConfig      `mapstructure:"captcha"`
+	History              LoginHistoryConfig `mapstructure:"history"`
+	// NewDeviceAlerts records a security event and notifies the user when
+	// they sign in from a device or IP address none of their recent
+	// successful sign-ins came from
+	NewDeviceAlerts bool `mapstructure:"new_device_alerts"`
+}
+
+// LoginHistoryConfig controls how long the sign-in history is kept
+type LoginHistoryConfig struct {
+	RetentionDays        int `mapstructure:"retention_days"`
+	PruneIntervalMinutes int `mapstructure:"prune_interval_minutes"`
+}
+
+// Retention returns how long sign-in attempts are kept, defaulting to 90 days
+func (c LoginHistoryConfig) Retention() time.Duration {
+	if c.RetentionDays <= 0 {
+		return 90 * 24 * time.Hour
+	}
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// PruneInterval returns how often expired attempts are deleted, defaulting to 1 hour
+func (c LoginHistoryConfig) PruneInterval() time.Duration {
+	if c.PruneIntervalMinutes <= 0 {
+		return time.Hour
+	}
+	return time.Duration(c.PruneIntervalMinutes) * time.Minute
+}
+
+// FailureWindow returns how long failed attempts are remembered, defaulting to 15 minutes
+func (c LoginConfig) FailureWindow() time.Duration {
+	if c.FailureWindowSeconds <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(c.FailureWindowSeconds) * time.Second
+}
+
+// ComplianceConfig holds data residency rules for data leaving the service.
+// Regions lists the residency regions users may be assigned. Destinations maps
+// an external destination (export, event sink) to the regions allowed to flow
+// to it; "*" allows every region and unlisted destinations receive no data.
+type ComplianceConfig struct {
+	Regions          []string            `mapstructure:"regions"`
+	DefaultResidency string              `mapstructure:"default_residency"`
+	Destinations     map[string][]string `mapstructure:"destinations"`
+}
+
+// PasswordConfig holds password hashing parameters. New passwords are hashed
+// with Algorithm; hashes made otherwise keep verifying and are upgraded when
+// their owner next signs in. Use `go run ./cmd/hash calibrate` to pick a
+// bcrypt cost for the deployment hardware. Passwords older than MaxAgeDays
+// must be changed before their owner can sign in again.
+type PasswordConfig struct {
+	Algorithm  string       `mapstructure:"algorithm"` // argon2id (default) or bcrypt
+	BcryptCost int          `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+	MaxAgeDays int          `mapstructure:"max_age_days"` // 0 lets passwords live forever
+}
+
+// MaxAge returns how long a password may be used before it expires; zero
+// means passwords only expire when an administrator expires them
+func (c PasswordConfig) MaxAge() time.Duration {
+	return time.Duration(max(c.MaxAgeDays, 0)) * 24 * time.Hour
+}
+
+// HashAlgorithm returns the algorithm of new password hashes, defaulting to argon2id
+func (c PasswordConfig) HashAlgorithm() string {
+	if c.Algorithm == "" {
+		return "argon2id"
+	}
+	return c.Algorithm
+}
+
+// Argon2Config holds Argon2id parameters; zero values keep the defaults of
+// 64 MiB of memory, 3 iterations and 2 lanes
+type Argon2Config struct {
+	MemoryKiB   uint32 `mapstructure:"memory_kib"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+}
+
+// Cost returns the bcrypt cost, defaulting to 10
+func (c PasswordConfig) Cost() int {
+	if c.BcryptCost == 0 {
+		return 10
+	}
+	return c.BcryptCost
+}
+
+// ImportConfig limits bulk user imports. Files larger than SyncMaxBytes are
+// imported in the background and polled through a job ID.
+type ImportConfig struct {
+	MaxFileSizeBytes int64 `mapstructure:"max_file_size_bytes"`
+	SyncMaxBytes     int64 `mapstructure:"sync_max_bytes"`
+	BatchSize        int   `mapstructure:"batch_size"` // users inserted per transaction
+}
+
+// MaxFileSize returns the largest accepted upload, defaulting to 10MB
+func (c ImportConfig) MaxFileSize() int64 {
+	if c.MaxFileSizeBytes <= 0 {
+		return 10 << 20
+	}
+	return c.MaxFileSizeBytes
+}
+
+// SyncMax returns the largest upload imported within the request, defaulting to 64KB
+func (c ImportConfig) SyncMax() int64 {
+	if c.SyncMaxBytes <= 0 {
+		return 64 << 10
+	}
+	return c.SyncMaxBytes
+}
+
+// Batch returns the number of users inserted per transaction, defaulting to 100
+func (c ImportConfig) Batch() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// ExportConfig tunes bulk user exports
+type ExportConfig struct {
+	BatchSize int    `mapstructure:"batch_size"` // users read per query
+	Directory string `mapstructure:"directory"`  // Where background exports are written; shared by the server and the worker
+}
+
+// Dir returns where background exports are written, defaulting to
+// user-exports in the temporary directory
+func (c ExportConfig) Dir() string {
+	if c.Directory == "" {
+		return filepath.Join(os.TempDir(), "user-exports")
+	}
+	return c.Directory
+}
+
+// Batch returns the number of users read per query, defaulting to 500
+func (c ExportConfig) Batch() int {
+	if c.BatchSize <= 0 {
+		return 500
+	}
+	return c.BatchSize
+}
+
+// CacheConfig bounds the in-process caches and configures the Redis cache
+type CacheConfig struct {
+	Tokens CacheLimits      `mapstructure:"tokens"` // Validated access tokens
+	Users  CacheLimits      `mapstructure:"users"`  // Users looked up by ID
+	Redis  RedisCacheConfig `mapstructure:"redis"`  // Users looked up by ID or email, shared by every instance
+}
+
+// CacheLimits bounds a single cache; a zero max_entries or ttl_seconds disables it
+type CacheLimits struct {
+	MaxEntries int     `mapstructure:"max_entries"`
+	TTLSeconds int     `mapstructure:"ttl_seconds"`
+	Jitter     float64 `mapstructure:"jitter"` // Up to this fraction of the TTL is randomly taken off each entry
+}
+
+// TTL returns the longest time an entry is served
+func (c CacheLimits) TTL() time.Duration {
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// RedisCacheConfig configures the read-through user cache in Redis; a zero
+// ttl_seconds disables it like enabled: false does
+type RedisCacheConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	TTLSeconds int  `mapstructure:"ttl_seconds"`
+}
+
+// TTL returns how long an entry is kept, or zero when the cache is disabled
+func (c RedisCacheConfig) TTL() time.Duration {
+	if !c.Enabled {
+		return 0
+	}
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// APIKeysConfig tunes organization API keys
+type APIKeysConfig struct {
+	RotationOverlapSeconds int `mapstructure:"rotation_overlap_seconds"` // How long a rotated key stays valid
+}
+
+// RotationOverlap returns how long a rotated key keeps working next to its
+// replacement, defaulting to 24 hours
+func (c APIKeysConfig) RotationOverlap() time.Duration {
+	if c.RotationOverlapSeconds <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(c.RotationOverlapSeconds) * time.Second
+}
+
+// HealthConfig tunes the dependency health monitor
+type HealthConfig struct {
+	CheckIntervalSeconds int `mapstructure:"check_interval_seconds"`
+	TimeoutMs            int `mapstructure:"timeout_ms"`        // Slower probes count as down
+	SlowThresholdMs      int `mapstructure:"slow_threshold_ms"` // Slower probes count as degraded
+	Debounce             int `mapstructure:"debounce"`          // Consecutive probes needed to change status
+}
+
+// CheckInterval returns the time between probes, defaulting to 15 seconds
+func (c HealthConfig) CheckInterval() time.Duration {
+	if c.CheckIntervalSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(c.CheckIntervalSeconds) * time.Second
+}
+
+// Timeout returns how long a probe may run, defaulting to 2 seconds
+func (c HealthConfig) Timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// SlowThreshold returns the probe duration above which a dependency is
+// degraded, defaulting to 500ms
+func (c HealthConfig) SlowThreshold() time.Duration {
+	if c.SlowThresholdMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.SlowThresholdMs) * time.Millisecond
+}
+
+// DebounceChecks returns how many consecutive probes must agree before a
+// dependency changes status, defaulting to 3
+func (c HealthConfig) DebounceChecks() int {
+	if c.Debounce <= 0 {
+		return 3
+	}
+	return c.Debounce
+}
+
+// BreakerConfig configures the circuit breakers around Redis and the
+// database. An open breaker fails calls straight away with 503 instead of
+// letting each wait for the backend to time out.
+type BreakerConfig struct {
+	Enabled          bool `mapstructure:"enabled"`
+	FailureThreshold int  `mapstructure:"failure_threshold"` // Consecutive failures opening a breaker
+	OpenSeconds      int  `mapstructure:"open_seconds"`      // How long an open breaker refuses calls before a trial
+}
+
+// Threshold returns the consecutive failures opening a breaker, defaulting to 5
+func (c BreakerConfig) Threshold() int {
+	if c.FailureThreshold <= 0 {
+		return 5
+	}
+	return c.FailureThreshold
+}
+
+// OpenTimeout returns how long an open breaker refuses calls, defaulting to 10 seconds
+func (c BreakerConfig) OpenTimeout() time.Duration {
+	if c.OpenSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.OpenSeconds) * time.Second
+}
+
+// FeatureFlagsConfig configures feature flags
+type FeatureFlagsConfig struct {
+	// Defaults are the flag values of this environment; values set through
+	// the admin API take precedence until they are deleted
+	Defaults       map[string]bool `mapstructure:"defaults"`
+	RefreshSeconds int             `mapstructure:"refresh_seconds"` // How long flag values set through the admin API are cached
+	// OverrideSecret signs per-request flag overrides for end-to-end tests;
+	// leave it empty outside test environments to ignore overrides
+	OverrideSecret        string `mapstructure:"override_secret"`
+	OverrideMaxTTLSeconds int    `mapstructure:"override_max_ttl_seconds"` // Longest validity of an override signature
+}
+
+// Refresh returns how long flag values set through the admin API are
+// cached, defaulting to 30 seconds
+func (c FeatureFlagsConfig) Refresh() time.Duration {
+	if c.RefreshSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.RefreshSeconds) * time.Second
+}
+
+// OverrideMaxTTL returns the longest validity of an override signature,
+// defaulting to 1 hour
+func (c FeatureFlagsConfig) OverrideMaxTTL() time.Duration {
+	if c.OverrideMaxTTLSeconds <= 0 {
+		return time.Hour
+	}
+	return time.Duration(c.OverrideMaxTTLSeconds) * time.Second
+}
+
+// NotificationConfig configures how notifications reach users. Each provider
+// is enabled by setting its address; with none enabled notifications are
+// dropped. Failed sends are retried RetryAttempts times with a backoff
+// doubling from RetryBackoffMs, then recorded as dead letters.
+type NotificationConfig struct {
+	SMTP           SMTPConfig    `mapstructure:"smtp"`
+	Webhook        WebhookConfig `mapstructure:"webhook"`
+	QueueSize      int           `mapstructure:"queue_size"` // Notifications waiting to be sent; further ones are dead-lettered
+	Workers        int           `mapstructure:"workers"`
+	RetryAttempts  int           `mapstructure:"retry_attempts"`
+	RetryBackoffMs int           `mapstructure:"retry_backoff_ms"`
+}
+
+// SMTPConfig configures email delivery through an SMTP relay
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"` // Empty disables email notifications
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"` // Empty sends without authentication
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// WebhookConfig configures delivery of notifications as signed JSON POSTs,
+// e.g. to an SMS gateway or a messaging integration
+type WebhookConfig struct {
+	URL            string `mapstructure:"url"`    // Empty disables webhook notifications
+	Secret         string `mapstructure:"secret"` // Signs the payload in X-Notification-Signature; empty sends unsigned
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+}
+
+// Addr returns the host:port of the SMTP relay, defaulting to the submission port 587
+func (c SMTPConfig) Addr() string {
+	port := c.Port
+	if port <= 0 {
+		port = 587
+	}
+	return fmt.Sprintf("%s:%d", c.Host, port)
+}
+
+// Timeout returns how long a webhook call may take, defaulting to 5 seconds
+func (c WebhookConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Queue returns how many notifications may wait to be sent, defaulting to 256
+func (c NotificationConfig) Queue() int {
+	if c.QueueSize <= 0 {
+		return 256
+	}
+	return c.QueueSize
+}
+
+// WorkerCount returns how many notifications are sent concurrently, defaulting to 2
+func (c NotificationConfig) WorkerCount() int {
+	if c.Workers <= 0 {
+		return 2
+	}
+	return c.Workers
+}
+
+// Attempts returns how many times a send is tried, defaulting to 5
+func (c NotificationConfig) Attempts() int {
+	if c.RetryAttempts <= 0 {
+		return 5
+	}
+	return c.RetryAttempts
+}
+
+// Backoff returns the wait before the first retry, defaulting to 1 second
+func (c NotificationConfig) Backoff() time.Duration {
+	if c.RetryBackoffMs <= 0 {
+		return time.Second
+	}
+	return time.Duration(c.RetryBackoffMs) * time.Millisecond
+}
+
+// JobsConfig configures the Redis-backed background job queue served by
+// cmd/worker. A job that fails is retried MaxAttempts times in total with a
+// backoff doubling from RetryBackoffMs; a job whose worker stops for longer
+// than the visibility timeout is handed to another worker.
+type JobsConfig struct {
+	Queue                    string `mapstructure:"queue"`
+	Concurrency              int    `mapstructure:"concurrency"`
+	MaxAttempts              int    `mapstructure:"max_attempts"`
+	RetryBackoffMs           int    `mapstructure:"retry_backoff_ms"`
+	VisibilityTimeoutSeconds int    `mapstructure:"visibility_timeout_seconds"`
+	PollIntervalMs           int    `mapstructure:"poll_interval_ms"`
+	// DeliverNotifications hands notifications to the worker through the
+	// queue instead of sending them from the server process
+	DeliverNotifications bool `mapstructure:"deliver_notifications"`
+	// MetricsPort is the port of the worker's internal metrics listener; 0 disables it
+	MetricsPort int            `mapstructure:"metrics_port"`
+	Schedule    ScheduleConfig `mapstructure:"schedule"`
+}
+
+// WebhooksConfig configures the webhook subscriptions administrators
+// register to receive user lifecycle events. Deliveries run in cmd/worker and
+// are retried like any other job, as set in JobsConfig.
+type WebhooksConfig struct {
+	Enabled        bool `mapstructure:"enabled"` // Enqueues the deliveries of user events; subscriptions can be managed either way
+	TimeoutSeconds int  `mapstructure:"timeout_seconds"`
+}
+
+// Timeout returns how long a delivery may take, defaulting to 10 seconds
+func (c WebhooksConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// SAMLConfig configures sign-in through the SAML identity provider of each
+// tenant. The entity ID and assertion consumer service URL of the service
+// provider of a tenant are built from BaseURL, the public URL of the API.
+type SAMLConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	BaseURL          string `mapstructure:"base_url"` // e.g. https://api.example.com
+	ClockSkewSeconds int    `mapstructure:"clock_skew_seconds"`
+}
+
+// ClockSkew returns how far the clock of an identity provider may differ
+// when checking the validity window of assertions, defaulting to 1 minute
+func (c SAMLConfig) ClockSkew() time.Duration {
+	if c.ClockSkewSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.ClockSkewSeconds) * time.Second
+}
+
+// ScheduleConfig sets how often cmd/worker enqueues each housekeeping job.
+// An interval of 0 uses the default and a negative one disables the job.
+type ScheduleConfig struct {
+	SessionCleanupMinutes      int `mapstructure:"session_cleanup_minutes"`
+	RefreshTokenCleanupMinutes int `mapstructure:"refresh_token_cleanup_minutes"`
+	AuditPruneMinutes          int `mapstructure:"audit_prune_minutes"`
+}
+
+// SessionCleanup returns how often expired sessions are purged, defaulting
+// to 1 hour; zero when disabled
+func (c ScheduleConfig) SessionCleanup() time.Duration {
+	return scheduleInterval(c.SessionCleanupMinutes, time.Hour)
+}
+
+// RefreshTokenCleanup returns how often orphaned refresh tokens are purged,
+// defaulting to 1 hour; zero when disabled
+func (c ScheduleConfig) RefreshTokenCleanup() time.Duration {
+	return scheduleInterval(c.RefreshTokenCleanupMinutes, time.Hour)
+}
+
+// AuditPrune returns how often the audit log is pruned, defaulting to
+// 1 day; zero when disabled
+func (c ScheduleConfig) AuditPrune() time.Duration {
+	return scheduleInterval(c.AuditPruneMinutes, 24*time.Hour)
+}
+
+func scheduleInterval(minutes int, fallback time.Duration) time.Duration {
+	switch {
+	case minutes < 0:
+		return 0
+	case minutes == 0:
+		return fallback
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// QueueName returns the name of the queue, defaulting to "default"
+func (c JobsConfig) QueueName() string {
+	if c.Queue == "" {
+		return "default"
+	}
+	return c.Queue
+}
+
+// Workers returns how many jobs run concurrently, defaulting to 4
+func (c JobsConfig) Workers() int {
+	if c.Concurrency <= 0 {
+		return 4
+	}
+	return c.Concurrency
+}
+
+// Attempts returns how many times a job is tried, defaulting to 5
+func (c JobsConfig) Attempts() int {
+	if c.MaxAttempts <= 0 {
+		return 5
+	}
+	return c.MaxAttempts
+}
+
+// Backoff returns the wait before the first retry, defaulting to 1 second
+func (c JobsConfig) Backoff() time.Duration {
+	if c.RetryBackoffMs <= 0 {
+		return time.Second
+	}
+	return time.Duration(c.RetryBackoffMs) * time.Millisecond
+}
+
+// VisibilityTimeout returns how long a job may run before it is handed to
+// another worker, defaulting to 5 minutes
+func (c JobsConfig) VisibilityTimeout() time.Duration {
+	if c.VisibilityTimeoutSeconds <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(c.VisibilityTimeoutSeconds) * time.Second
+}
+
+// PollInterval returns how often an idle worker checks for jobs, defaulting to 500ms
+func (c JobsConfig) PollInterval() time.Duration {
+	if c.PollIntervalMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.PollIntervalMs) * time.Millisecond
+}
+
+// AuditConfig controls how long the audit log is kept
+type AuditConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 0 keeps entries forever
+}
+
+// Retention returns how long audit log entries are kept, or zero to keep them forever
+func (c AuditConfig) Retention() time.Duration {
+	if c.RetentionDays <= 0 {
+		return 0
+	}
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// RealtimeConfig configures the WebSocket connections clients open on GET /ws
+// to receive the events of their user, and the event stream of admin dashboards
+type RealtimeConfig struct {
+	// AllowedOrigins lists the origins browsers may connect from, e.g.
+	// "https://app.example.com"; when empty any origin may connect.
+	// Connections without an Origin header are not browsers and are accepted.
+	AllowedOrigins      []string `mapstructure:"allowed_origins"`
+	SendBuffer          int      `mapstructure:"send_buffer"`           // Events queued per connection before it is dropped as too slow
+	WriteTimeoutSeconds int      `mapstructure:"write_timeout_seconds"` // Longest a client may take to receive an event
+	// AdminHistory is how many admin dashboard events each instance keeps
+	// for streams resuming with Last-Event-ID
+	AdminHistory     int `mapstructure:"admin_history"`
+	HeartbeatSeconds int `mapstructure:"heartbeat_seconds"` // Interval of keep-alive comments on idle event streams
+}
+
+// Buffer returns how many events may wait for a connection, defaulting to 16
+func (c RealtimeConfig) Buffer() int {
+	if c.SendBuffer <= 0 {
+		return 16
+	}
+	return c.SendBuffer
+}
+
+// History returns how many admin events are kept for resuming streams,
+// defaulting to 1000
+func (c RealtimeConfig) History() int {
+	if c.AdminHistory <= 0 {
+		return 1000
+	}
+	return c.AdminHistory
+}
+
+// Heartbeat returns the interval of keep-alive comments, defaulting to 15 seconds
+func (c RealtimeConfig) Heartbeat() time.Duration {
+	if c.HeartbeatSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(c.HeartbeatSeconds) * time.Second
+}
+
+// WriteTimeout returns how long sending an event may take, defaulting to 10 seconds
+func (c RealtimeConfig) WriteTimeout() time.Duration {
+	if c.WriteTimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.WriteTimeoutSeconds) * time.Second
+}
+
+// CompressionConfig configures compressing responses of the HTTP API and
+// the gRPC gateway with Brotli or gzip
+type CompressionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MinSizeBytes int      `mapstructure:"min_size_bytes"` // Smaller responses are sent uncompressed
+	ContentTypes []string `mapstructure:"content_types"`  // Media types compressed, without parameters
+}
+
+// MinSize returns the smallest response compressed, defaulting to 1 KiB
+func (c CompressionConfig) MinSize() int {
+	if c.MinSizeBytes <= 0 {
+		return 1024
+	}
+	return c.MinSizeBytes
+}
+
+// Types returns the media types compressed, defaulting to JSON, JSON:API,
+// CSV and plain text
+func (c CompressionConfig) Types() []string {
+	if len(c.ContentTypes) == 0 {
+		return []string{"application/json", "application/vnd.api+json", "text/csv", "text/plain"}
+	}
+	return c.ContentTypes
+}
+
+// ErrorsConfig configures reporting internal errors and recovered panics of
+// HTTP handlers and gRPC methods to an error tracker
+type ErrorsConfig struct {
+	SentryDSN string `mapstructure:"sentry_dsn"` // Reports to Sentry when set
+}
+
+// LogConfig configures the application logger
+type LogConfig struct {
+	Level string `mapstructure:"level"` // debug, info, warn or error; reloaded without a restart
+	// Modules sets the levels of the http (access log), grpc and gorm logs;
+	// modules not listed log at Level
+	Modules map[string]string `mapstructure:"modules"`
+}
+
+// WatchConfig configures reloading the config file without a restart
+type WatchConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	IntervalSeconds int  `mapstructure:"interval_seconds"` // How often the file is checked for changes
+}
+
+// Interval returns how often the config file is checked, defaulting to 5 seconds
+func (c WatchConfig) Interval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// LogLevel returns the configured log level, defaulting to info in
+// production and debug elsewhere. Validate rejects unknown levels.
+func (c *Config) LogLevel() zapcore.Level {
+	if c.Log.Level == "" {
+		if c.App.Env == "production" {
+			return zapcore.InfoLevel
+		}
+		return zapcore.DebugLevel
+	}
+	level, err := zapcore.ParseLevel(c.Log.Level)
+	if err != nil {
+		return zapcore.InfoLevel
+	}
+	return level
+}
+
+// LogModuleLevels returns the levels configured for the http, grpc and gorm
+// logs. Validate rejects unknown modules and levels.
+func (c *Config) LogModuleLevels() map[string]zapcore.Level {
+	levels := make(map[string]zapcore.Level, len(c.Log.Modules))
+	for module, level := range c.Log.Modules {
+		if parsed, err := zapcore.ParseLevel(level); err == nil {
+			levels[module] = parsed
+		}
+	}
+	return levels
+}
+
+// LoadConfig loads the configuration selected by APP_ENV, defaulting to the
+// dev environment, with environment variable overrides
+func LoadConfig() (*Config, error) {
+	return Load(Options{DefaultEnv: "dev"})
 }
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/logging"
+)
+
+// DefaultDir is the directory searched for config.<env>.yaml
+const DefaultDir = "./configs"
+
+// envKeyReplacer maps a key such as jwt.secret to its environment variable JWT_SECRET
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// Options selects the configuration sources. Each source overrides the ones
+// before it: the config file, then environment variables named after the
+// keys (jwt.secret is JWT_SECRET), then Overrides. Environment variables only
+// apply to keys present in the config file.
+type Options struct {
+	File       string   // Config file to read; empty reads config.<env>.yaml from Dir
+	Dir        string   // Directory of the config files, defaulting to DefaultDir
+	Env        string   // Environment selecting the file, defaulting to APP_ENV, then DefaultEnv
+	DefaultEnv string   // Environment used when neither Env nor APP_ENV is set
+	Overrides  []string // key=value pairs, e.g. from -set flags
+}
+
+// RegisterFlags adds the -config and -set flags to fs
+func (o *Options) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.File, "config", o.File, "Config file to read instead of configs/config.<APP_ENV>.yaml")
+	fs.Var((*overrideFlag)(&o.Overrides), "set", "Override a config value, e.g. -set app.port=9090; may be repeated")
+}
+
+// overrideFlag collects repeated -set flags
+type overrideFlag []string
+
+func (f *overrideFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *overrideFlag) Set(value string) error {
+	if _, _, err := splitOverride(value); err != nil {
+		return err
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// splitOverride splits a key=value override, lowercasing the key like viper does
+func splitOverride(override string) (string, string, error) {
+	key, value, ok := strings.Cut(override, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid config override %q: expected key=value", override)
+	}
+	return key, value, nil
+}
+
+// Load reads the configuration from the sources selected by opts and validates it
+func Load(opts Options) (*Config, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if opts.File != "" {
+		v.SetConfigFile(opts.File)
+	} else {
+		env := opts.Env
+		if env == "" {
+			env = os.Getenv("APP_ENV")
+		}
+		if env == "" {
+			env = opts.DefaultEnv
+		}
+		dir := opts.Dir
+		if dir == "" {
+			dir = DefaultDir
+		}
+		v.SetConfigName(fmt.Sprintf("config.%s", env))
+		v.AddConfigPath(dir)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	sources := []string{v.ConfigFileUsed()}
+
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+	for _, key := range slices.Sorted(slices.Values(v.AllKeys())) {
+		name := strings.ToUpper(envKeyReplacer.Replace(key))
+		if _, ok := os.LookupEnv(name); ok {
+			sources = append(sources, "env:"+name)
+		}
+	}
+
+	for _, override := range opts.Overrides {
+		key, value, err := splitOverride(override)
+		if err != nil {
+			return nil, err
+		}
+		v.Set(key, value)
+		sources = append(sources, "flag:"+key)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", v.ConfigFileUsed(), err)
+	}
+	// Reloads read the same file even if APP_ENV changes meanwhile
+	opts.File = v.ConfigFileUsed()
+	opts.Overrides = slices.Clone(opts.Overrides)
+	cfg.options = opts
+	cfg.Sources = sources
+
+	return &cfg, nil
+}
+
+// Validate reports the settings the service cannot start with
+func (c *Config) Validate() error {
+	var errs []error
+	if c.JWT.Secret == "" && len(c.JWT.Keys) == 0 {
+		errs = append(errs, errors.New("jwt.secret is required unless jwt.keys are configured"))
+	}
+	if c.App.Port <= 0 || c.App.Port > 65535 {
+		errs = append(errs, fmt.Errorf("app.port %d is not a valid port", c.App.Port))
+	}
+	switch {
+	case c.App.AdminPort < 0 || c.App.AdminPort > 65535:
+		errs = append(errs, fmt.Errorf("app.admin_port %d is not a valid port", c.App.AdminPort))
+	case c.App.AdminPort != 0 && c.App.AdminPort == c.App.Port:
+		errs = append(errs, fmt.Errorf("app.admin_port %d must differ from app.port", c.App.AdminPort))
+	}
+	if _, err := clientip.NewResolver(c.App.TrustedProxies, c.App.ClientIPHeaders); err != nil {
+		errs = append(errs, fmt.Errorf("app.trusted_proxies or app.client_ip_headers: %w", err))
+	}
+	if c.SAML.Enabled {
+		if u, err := url.Parse(c.SAML.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("saml.base_url %q must be the absolute http or https URL of the API when saml is enabled", c.SAML.BaseURL))
+		}
+	}
+	if c.Log.Level != "" && !validLogLevel(c.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level %q is not one of debug, info, warn or error", c.Log.Level))
+	}
+	for _, module := range slices.Sorted(maps.Keys(c.Log.Modules)) {
+		if module == logging.App || !slices.Contains(logging.Modules, module) {
+			errs = append(errs, fmt.Errorf("log.modules.%s is not one of http, grpc or gorm", module))
+		} else if !validLogLevel(c.Log.Modules[module]) {
+			errs = append(errs, fmt.Errorf("log.modules.%s %q is not one of debug, info, warn or error", module, c.Log.Modules[module]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validLogLevel reports whether level names one of the levels the service logs at
+func validLogLevel(level string) bool {
+	parsed, err := zapcore.ParseLevel(level)
+	return err == nil && parsed <= zapcore.ErrorLevel
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `app:
+  env: "test"
+  port: 8080
+jwt:
+  secret: "file_secret"
+availability:
+  requests_per_minute: 10
+log:
+  level: "info"
+`
+
+// writeConfig writes content to config.test.yaml in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.test.yaml"), []byte(content), 0o644))
+	return dir
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := writeConfig(t, testConfig)
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("JWT_SECRET", "env_secret")
+
+	cfg, err := Load(Options{Dir: dir, Env: "test", Overrides: []string{"app.port=7070", "log.level=warn"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "env_secret", cfg.JWT.Secret)     // Environment beats file
+	assert.Equal(t, 7070, cfg.App.Port)               // Flag beats environment
+	assert.Equal(t, "warn", cfg.Log.Level)            // Flag beats file
+	assert.Equal(t, 10, cfg.Availability.RateLimit()) // File only
+	assert.Equal(t, []string{filepath.Join(dir, "config.test.yaml"), "env:APP_PORT", "env:JWT_SECRET", "flag:app.port", "flag:log.level"}, cfg.Sources)
+}
+
+func TestLoad_EnvironmentSelectsFile(t *testing.T) {
+	dir := writeConfig(t, testConfig)
+	t.Setenv("APP_ENV", "test")
+
+	cfg, err := Load(Options{Dir: dir, DefaultEnv: "missing"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test", cfg.App.Env)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []string
+		expected  string
+	}{
+		{name: "Missing JWT Secret", overrides: []string{"jwt.secret="}, expected: "jwt.secret is required"},
+		{name: "Invalid Port", overrides: []string{"app.port=0"}, expected: "app.port 0 is not a valid port"},
+		{name: "Invalid Admin Port", overrides: []string{"app.admin_port=70000"}, expected: "app.admin_port 70000 is not a valid port"},
+		{name: "Admin Port Shared", overrides: []string{"app.port=8080", "app.admin_port=8080"}, expected: "app.admin_port 8080 must differ from app.port"},
+		{name: "Invalid Trusted Proxy", overrides: []string{"app.trusted_proxies=lb.internal"}, expected: `app.trusted_proxies or app.client_ip_headers: invalid trusted proxies: "lb.internal"`},
+		{name: "SAML Without Base URL", overrides: []string{"saml.enabled=true"}, expected: `saml.base_url ""`},
+		{name: "Unknown Log Level", overrides: []string{"log.level=verbose"}, expected: `log.level "verbose"`},
+		{name: "Unknown Log Module", overrides: []string{"log.modules.redis=debug"}, expected: "log.modules.redis is not one of http, grpc or gorm"},
+		{name: "Invalid Module Level", overrides: []string{"log.modules.gorm=fatal"}, expected: `log.modules.gorm "fatal"`},
+		{name: "Malformed Override", overrides: []string{"app.port"}, expected: "expected key=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(Options{Dir: writeConfig(t, testConfig), Env: "test", Overrides: tt.overrides})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestValidate_JWTKeysReplaceSecret(t *testing.T) {
+	cfg := &Config{App: AppConfig{Port: 8080}, JWT: JWTConfig{Keys: []JWTKeyConfig{{ID: "2025-06", Secret: "key_secret"}}}}
+
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestOptions_RegisterFlags(t *testing.T) {
+	var opts Options
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-config", "configs/config.prod.yaml", "-set", "app.port=9090", "-set", "log.level=debug"}))
+	assert.Equal(t, "configs/config.prod.yaml", opts.File)
+	assert.Equal(t, []string{"app.port=9090", "log.level=debug"}, opts.Overrides)
+
+	fs.SetOutput(new(nopWriter))
+	assert.Error(t, fs.Parse([]string{"-set", "=9090"}))
+}
+
+// nopWriter discards the usage printed for invalid flags
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
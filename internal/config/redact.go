@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the values of secret settings in Redacted
+const RedactedValue = "[REDACTED]"
+
+// secretKeys are the substrings that mark a setting as secret: credentials,
+// and connection strings and URLs, which often embed them
+var secretKeys = []string{"password", "secret", "token", "dsn", "source", "url"}
+
+// Redacted returns the settings of c keyed by their names in the config
+// files, with the values of secret settings replaced by RedactedValue.
+// Secrets that are not set stay empty, so that it shows whether they are.
+// The result holds only maps, slices and scalars, so it can be encoded as
+// JSON or as a protobuf Struct.
+func (c *Config) Redacted() map[string]any {
+	return redactValue(reflect.ValueOf(*c), false).(map[string]any)
+}
+
+// redactValue converts v into plain maps, slices and scalars, redacting the
+// strings below a secret key. Sections are judged by the keys of their own
+// settings, so the password section keeps its hashing settings.
+func redactValue(v reflect.Value, secret bool) any {
+	switch v.Kind() {
+	case reflect.Struct:
+		settings := map[string]any{}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if !field.IsExported() || key == "" || key == "-" {
+				continue
+			}
+			settings[key] = redactValue(v.Field(i), isSecretKey(key))
+		}
+		return settings
+	case reflect.Map:
+		settings := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			settings[key] = redactValue(iter.Value(), secret || isSecretKey(key))
+		}
+		return settings
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = redactValue(v.Index(i), secret)
+		}
+		return items
+	case reflect.String:
+		if secret && v.String() != "" {
+			return RedactedValue
+		}
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), secret)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// isSecretKey reports whether the setting named key holds a secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
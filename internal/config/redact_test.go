@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{
+		App:      AppConfig{Name: "user-service", Port: 8080, TrustedProxies: []string{"10.0.0.0/8"}},
+		Database: DatabaseConfig{Source: "host=db password=hunter2", ReplicaSources: []string{"host=replica password=hunter2"}},
+		Redis:    RedisConfig{Addr: "redis:6379", Password: ""},
+		JWT: JWTConfig{
+			Secret:                   "signing-secret",
+			AccessTokenExpireMinutes: 15,
+			Keys:                     []JWTKeyConfig{{ID: "k1", Secret: "key-secret", PrivateKeyFile: "/keys/k2.pem"}},
+		},
+		Password:     PasswordConfig{Algorithm: "argon2id"},
+		Notification: NotificationConfig{Webhook: WebhookConfig{URL: "https://hooks.example.com/T0/secret-path"}},
+		Errors:       ErrorsConfig{SentryDSN: "https://key@sentry.example.com/1"},
+		Log:          LogConfig{Modules: map[string]string{"gorm": "debug"}},
+		Sources:      []string{"configs/config.dev.yaml"},
+	}
+
+	settings := cfg.Redacted()
+
+	app := settings["app"].(map[string]any)
+	assert.Equal(t, "user-service", app["name"])
+	assert.Equal(t, int64(8080), app["port"])
+	assert.Equal(t, []any{"10.0.0.0/8"}, app["trusted_proxies"])
+
+	database := settings["database"].(map[string]any)
+	assert.Equal(t, RedactedValue, database["source"])
+	assert.Equal(t, []any{RedactedValue}, database["replica_sources"])
+
+	assert.Equal(t, "", settings["redis"].(map[string]any)["password"], "unset secrets stay empty")
+
+	jwt := settings["jwt"].(map[string]any)
+	assert.Equal(t, RedactedValue, jwt["secret"])
+	assert.Equal(t, int64(15), jwt["access_token_expire_minutes"])
+	key := jwt["keys"].([]any)[0].(map[string]any)
+	assert.Equal(t, "k1", key["id"])
+	assert.Equal(t, RedactedValue, key["secret"])
+	assert.Equal(t, "/keys/k2.pem", key["private_key_file"])
+
+	assert.Equal(t, "argon2id", settings["password"].(map[string]any)["algorithm"], "sections are judged by their own keys")
+	assert.Equal(t, RedactedValue, settings["notification"].(map[string]any)["webhook"].(map[string]any)["url"])
+	assert.Equal(t, RedactedValue, settings["error_reporting"].(map[string]any)["sentry_dsn"])
+	assert.Equal(t, map[string]any{"gorm": "debug"}, settings["log"].(map[string]any)["modules"])
+	assert.NotContains(t, settings, "Sources")
+
+	_, err := structpb.NewStruct(settings)
+	require.NoError(t, err, "settings must be representable as a protobuf Struct")
+}
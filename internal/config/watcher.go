@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Watcher reloads the configuration when its file changes and hands the new
+// configuration to its subscribers, which apply the settings that can change
+// without a restart: the log levels and availability.requests_per_minute. Other
+// changes are logged and take effect on the next restart. The file is polled
+// rather than watched with inotify, which misses the symlink swaps of
+// Kubernetes ConfigMap volumes.
+type Watcher struct {
+	current  *Config
+	interval time.Duration
+	enabled  bool
+	logger   *zap.Logger
+
+	subscribers []func(*Config)
+
+	mu      sync.Mutex // Serializes reloads from Run and Reload
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a watcher reloading cfg from the sources it was loaded from
+func NewWatcher(cfg *Config, logger *zap.Logger) *Watcher {
+	w := &Watcher{
+		current:  cfg,
+		interval: cfg.Watch.Interval(),
+		enabled:  cfg.Watch.Enabled && cfg.options.File != "",
+		logger:   logger,
+	}
+	w.modTime, w.size, _ = w.stat()
+	return w
+}
+
+// OnChange registers fn to receive each configuration reloaded from a changed file
+func (w *Watcher) OnChange(fn func(*Config)) {
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Run checks the config file until ctx is done; it returns at once when
+// watching is disabled
+func (w *Watcher) Run(ctx context.Context) error {
+	if !w.enabled {
+		return nil
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// Reload reloads the configuration even if the file did not change, e.g. on
+// SIGHUP, which also resets settings changed at runtime to the configured ones
+func (w *Watcher) Reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.modTime, w.size, _ = w.stat()
+	w.reload()
+}
+
+// check reloads the configuration if the file changed since the last check
+func (w *Watcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	modTime, size, ok := w.stat()
+	if !ok || (modTime.Equal(w.modTime) && size == w.size) {
+		return
+	}
+	w.modTime, w.size = modTime, size
+	w.reload()
+}
+
+// reload loads the configuration and hands it to the subscribers. An invalid
+// file is logged and skipped, keeping the current settings.
+func (w *Watcher) reload() {
+	updated, err := Load(w.current.options)
+	if err != nil {
+		w.logger.Error("Failed to reload config; keeping the current settings",
+			zap.String("file", w.current.options.File), zap.Error(err))
+		return
+	}
+	if restartRequired(w.current, updated) {
+		w.logger.Warn("Config file changed settings that only take effect after a restart",
+			zap.String("file", w.current.options.File))
+	}
+
+	w.logger.Info("Reloaded config",
+		zap.String("file", w.current.options.File),
+		zap.Stringer("log_level", updated.LogLevel()),
+		zap.Any("log_modules", updated.Log.Modules),
+		zap.Int("availability_requests_per_minute", updated.Availability.RateLimit()))
+	w.current = updated
+	for _, fn := range w.subscribers {
+		fn(updated)
+	}
+}
+
+// stat returns the modification time and size of the config file; ok is
+// false while the file cannot be read, e.g. while it is being replaced
+func (w *Watcher) stat() (modTime time.Time, size int64, ok bool) {
+	info, err := os.Stat(w.current.options.File)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return info.ModTime(), info.Size(), true
+}
+
+// restartRequired reports whether updated differs from current in settings
+// other than the ones applied on reload
+func restartRequired(current, updated *Config) bool {
+	a, b := *current, *updated
+	a.Log, b.Log = LogConfig{}, LogConfig{}
+	a.Availability.RequestsPerMinute, b.Availability.RequestsPerMinute = 0, 0
+	a.Sources, b.Sources = nil, nil
+	a.options, b.options = Options{}, Options{}
+	return !reflect.DeepEqual(a, b)
+}
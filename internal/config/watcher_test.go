@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWatcher_Check(t *testing.T) {
+	dir := writeConfig(t, testConfig)
+	file := filepath.Join(dir, "config.test.yaml")
+	cfg, err := Load(Options{Dir: dir, Env: "test"})
+	require.NoError(t, err)
+
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t))
+	var reloaded []*Config
+	watcher.OnChange(func(updated *Config) { reloaded = append(reloaded, updated) })
+
+	// rewrite replaces the file, moving its modification time forward
+	modTime := time.Now()
+	rewrite := func(content string) {
+		modTime = modTime.Add(time.Second)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+
+	// An unchanged file is not reloaded
+	watcher.check()
+	assert.Empty(t, reloaded)
+
+	rewrite(strings.NewReplacer(`level: "info"`, `level: "debug"`, "requests_per_minute: 10", "requests_per_minute: 20").Replace(testConfig))
+	watcher.check()
+	require.Len(t, reloaded, 1)
+	assert.Equal(t, zapcore.DebugLevel, reloaded[0].LogLevel())
+	assert.Equal(t, 20, reloaded[0].Availability.RateLimit())
+
+	// An invalid file keeps the current settings
+	rewrite(strings.Replace(testConfig, `secret: "file_secret"`, `secret: ""`, 1))
+	watcher.check()
+	assert.Len(t, reloaded, 1)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	cfg, err := Load(Options{Dir: writeConfig(t, testConfig), Env: "test"})
+	require.NoError(t, err)
+
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t))
+	reloads := 0
+	watcher.OnChange(func(*Config) { reloads++ })
+
+	// Unlike the periodic check, Reload does not wait for the file to change
+	watcher.Reload()
+	assert.Equal(t, 1, reloads)
+	watcher.check()
+	assert.Equal(t, 1, reloads)
+}
+
+func TestWatcher_EnvironmentStillOverridesFile(t *testing.T) {
+	dir := writeConfig(t, testConfig)
+	file := filepath.Join(dir, "config.test.yaml")
+	t.Setenv("LOG_LEVEL", "error")
+	cfg, err := Load(Options{Dir: dir, Env: "test"})
+	require.NoError(t, err)
+
+	watcher := NewWatcher(cfg, zaptest.NewLogger(t))
+	var reloaded *Config
+	watcher.OnChange(func(updated *Config) { reloaded = updated })
+
+	modTime := time.Now().Add(time.Second)
+	require.NoError(t, os.WriteFile(file, []byte(strings.Replace(testConfig, "requests_per_minute: 10", "requests_per_minute: 5", 1)), 0o644))
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+	watcher.check()
+
+	require.NotNil(t, reloaded)
+	assert.Equal(t, zapcore.ErrorLevel, reloaded.LogLevel())
+	assert.Equal(t, 5, reloaded.Availability.RateLimit())
+}
+
+func TestRestartRequired(t *testing.T) {
+	current := &Config{App: AppConfig{Port: 8080}, Log: LogConfig{Level: "info"}}
+
+	assert.False(t, restartRequired(current, &Config{
+		App:          AppConfig{Port: 8080},
+		Log:          LogConfig{Level: "debug", Modules: map[string]string{"gorm": "warn"}},
+		Availability: AvailabilityConfig{RequestsPerMinute: 20},
+	}))
+	assert.True(t, restartRequired(current, &Config{App: AppConfig{Port: 9090}, Log: LogConfig{Level: "info"}}))
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetFileValue sets section.key to value in a YAML config file, keeping the
+// rest of the file (including comments and blank lines) untouched. section
+// must be a top-level mapping and key a scalar directly below it.
+func SetFileValue(path, section, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	updated, err := setYAMLValue(string(data), section, key, value)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(updated), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// setYAMLValue locates section.key with the YAML parser and rewrites only the
+// lines involved, since re-encoding the document would drop blank lines and
+// reflow comments
+func setYAMLValue(content, section, key, value string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	lines := strings.Split(content, "\n")
+	entry := fmt.Sprintf("%s: %s", key, value)
+
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root != nil && root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("config file is not a YAML mapping")
+	}
+
+	sectionKey, sectionValue := lookup(root, section)
+	if sectionKey == nil {
+		content = strings.TrimRight(content, "\n")
+		if content == "" {
+			return fmt.Sprintf("%s:\n  %s\n", section, entry), nil
+		}
+		return fmt.Sprintf("%s\n\n%s:\n  %s\n", content, section, entry), nil
+	}
+
+	switch {
+	case sectionValue.Kind == yaml.ScalarNode && sectionValue.Tag == "!!null":
+		// Empty section: the key becomes its first entry
+	case sectionValue.Kind != yaml.MappingNode || sectionValue.Style == yaml.FlowStyle:
+		return "", fmt.Errorf("%s is not a block mapping", section)
+	}
+
+	keyNode, valueNode := lookup(sectionValue, key)
+	if keyNode == nil {
+		indent := "  "
+		if len(sectionValue.Content) > 0 {
+			indent = strings.Repeat(" ", sectionValue.Content[0].Column-1)
+		}
+		at := sectionKey.Line // Insert right below the section header
+		lines = append(lines[:at], append([]string{indent + entry}, lines[at:]...)...)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	if valueNode.Kind != yaml.ScalarNode || valueNode.Line != keyNode.Line {
+		return "", fmt.Errorf("%s.%s is not a single-line scalar", section, key)
+	}
+	line := lines[valueNode.Line-1]
+	start := valueNode.Column - 1
+	end, err := scalarEnd(line, start, valueNode)
+	if err != nil {
+		return "", fmt.Errorf("%s.%s: %w", section, key, err)
+	}
+	lines[valueNode.Line-1] = line[:start] + value + line[end:]
+	return strings.Join(lines, "\n"), nil
+}
+
+// lookup returns the key and value nodes for key in a mapping node
+func lookup(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// scalarEnd returns the offset just past the scalar token starting at start
+func scalarEnd(line string, start int, node *yaml.Node) (int, error) {
+	switch node.Style {
+	case 0:
+		return start + len(node.Value), nil
+	case yaml.SingleQuotedStyle, yaml.DoubleQuotedStyle:
+		quote := line[start]
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' && quote == '"' {
+				i++
+				continue
+			}
+			if line[i] == quote {
+				if quote == '\'' && i+1 < len(line) && line[i+1] == '\'' {
+					i++ // '' escapes a single quote
+					continue
+				}
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated quoted value")
+	default:
+		return 0, fmt.Errorf("unsupported value style")
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetYAMLValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Replace Existing Key",
+			content:  "app:\n  port: 8080\n\npassword:\n  # tuned for prod\n  bcrypt_cost: 10\n\ngrpc:\n  port: 50051\n",
+			expected: "app:\n  port: 8080\n\npassword:\n  # tuned for prod\n  bcrypt_cost: 12\n\ngrpc:\n  port: 50051\n",
+		},
+		{
+			name:     "Header With Trailing Comment",
+			content:  "password: # hashing\n  bcrypt_cost: 10 # old value\n",
+			expected: "password: # hashing\n  bcrypt_cost: 12 # old value\n",
+		},
+		{
+			name:     "Quoted Value",
+			content:  "password:\n  bcrypt_cost: \"10\"\n",
+			expected: "password:\n  bcrypt_cost: 12\n",
+		},
+		{
+			name:     "Nested Key With Same Name",
+			content:  "password:\n  legacy:\n    bcrypt_cost: 8\n  bcrypt_cost: 10\n",
+			expected: "password:\n  legacy:\n    bcrypt_cost: 8\n  bcrypt_cost: 12\n",
+		},
+		{
+			name:     "Insert Missing Key",
+			content:  "password:\n    other: 1\ngrpc:\n  bcrypt_cost: 99\n",
+			expected: "password:\n    bcrypt_cost: 12\n    other: 1\ngrpc:\n  bcrypt_cost: 99\n",
+		},
+		{
+			name:     "Empty Section",
+			content:  "password:\ngrpc:\n  port: 50051\n",
+			expected: "password:\n  bcrypt_cost: 12\ngrpc:\n  port: 50051\n",
+		},
+		{
+			name:     "Append Missing Section",
+			content:  "app:\n  port: 8080\n",
+			expected: "app:\n  port: 8080\n\npassword:\n  bcrypt_cost: 12\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, err := setYAMLValue(tc.content, "password", "bcrypt_cost", "12")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, updated)
+		})
+	}
+
+	t.Run("Unsupported Layouts", func(t *testing.T) {
+		for _, content := range []string{
+			"password: {bcrypt_cost: 10}\n",
+			"password: 10\n",
+			"password:\n  bcrypt_cost:\n    nested: 1\n",
+			"- not\n- a mapping\n",
+			"password: [\n",
+		} {
+			_, err := setYAMLValue(content, "password", "bcrypt_cost", "12")
+			assert.Error(t, err, content)
+		}
+	})
+}
+
+func TestSetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.test.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("password:\n  bcrypt_cost: 10\n"), 0o644))
+
+	require.NoError(t, SetFileValue(path, "password", "bcrypt_cost", "11"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "password:\n  bcrypt_cost: 11\n", string(data))
+
+	assert.Error(t, SetFileValue(filepath.Join(t.TempDir(), "missing.yaml"), "password", "bcrypt_cost", "11"))
+}
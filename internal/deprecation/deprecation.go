@@ -0,0 +1,49 @@
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response headers announcing a deprecated endpoint
+const (
+	HeaderDeprecation = "Deprecation" // RFC 9745
+	HeaderSunset      = "Sunset"      // RFC 8594
+	HeaderLink        = "Link"
+)
+
+// Notice describes a deprecated route or RPC and where its clients should go
+type Notice struct {
+	Since       time.Time // When the endpoint was deprecated
+	Sunset      time.Time // When the endpoint will be removed; zero if no date is set yet
+	Link        string    // Migration guide or successor endpoint; optional
+	Replacement string    // Short description of what to use instead, e.g. "PATCH /api/v1/users/{id}/status"
+}
+
+// Headers returns the response headers announcing the deprecation
+func (n Notice) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderDeprecation: "@" + strconv.FormatInt(n.Since.Unix(), 10),
+	}
+	if !n.Sunset.IsZero() {
+		headers[HeaderSunset] = n.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if n.Link != "" {
+		headers[HeaderLink] = fmt.Sprintf("<%s>; rel=\"deprecation\"", n.Link)
+	}
+	return headers
+}
+
+// Warning returns a human-readable warning for response bodies
+func (n Notice) Warning() string {
+	warning := "This endpoint is deprecated"
+	if !n.Sunset.IsZero() {
+		warning += " and will be removed on " + n.Sunset.UTC().Format(time.DateOnly)
+	}
+	if n.Replacement != "" {
+		warning += "; use " + n.Replacement + " instead"
+	}
+	return warning + "."
+}
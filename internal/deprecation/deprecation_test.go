@@ -0,0 +1,51 @@
+package deprecation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotice(t *testing.T) {
+	since := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		notice          Notice
+		expectedHeaders map[string]string
+		expectedWarning string
+	}{
+		{
+			name:   "Deprecation Only",
+			notice: Notice{Since: since},
+			expectedHeaders: map[string]string{
+				HeaderDeprecation: "@1792108800",
+			},
+			expectedWarning: "This endpoint is deprecated.",
+		},
+		{
+			name: "Full Notice",
+			notice: Notice{
+				Since:       since,
+				Sunset:      sunset,
+				Link:        "https://example.com/migrations/status",
+				Replacement: "PATCH /api/v1/users/{id}/status",
+			},
+			expectedHeaders: map[string]string{
+				HeaderDeprecation: "@1792108800",
+				HeaderSunset:      "Fri, 16 Apr 2027 00:00:00 GMT",
+				HeaderLink:        `<https://example.com/migrations/status>; rel="deprecation"`,
+			},
+			expectedWarning: "This endpoint is deprecated and will be removed on 2027-04-16; use PATCH /api/v1/users/{id}/status instead.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedHeaders, tt.notice.Headers())
+			assert.Equal(t, tt.expectedWarning, tt.notice.Warning())
+		})
+	}
+}
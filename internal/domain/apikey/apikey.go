@@ -0,0 +1,96 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope identifies what an API key may be used for
+type Scope string
+
+// Supported scopes
+const (
+	ScopeUsersRead Scope = "users:read" // List the users of the key's organization
+)
+
+// Scopes lists every scope an API key can be granted, in display order
+var Scopes = []Scope{ScopeUsersRead}
+
+// Valid reports whether s is a known scope
+func (s Scope) Valid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a credential issued to an organization rather than to a person,
+// for server-to-server access. Only a hash of the secret is stored.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Tenant     string     `json:"tenant"` // Organization the key acts for
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Leading characters of the secret, to tell keys apart
+	SecretHash string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil never expires
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RotatedTo  *uuid.UUID `json:"rotated_to,omitempty"` // Key that replaced this one
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the key is accepted at now
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Input holds the fields of a new API key chosen by an organization admin
+type Input struct {
+	Name      string
+	Scopes    []Scope
+	ExpiresAt *time.Time
+}
+
+// Repository defines the interface for API key storage
+type Repository interface {
+	// Create stores a new key
+	Create(ctx context.Context, key *APIKey) error
+
+	// GetByID retrieves a key by ID, returning nil if it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+
+	// GetBySecretHash retrieves a key by the hash of its secret, returning nil
+	// if it does not exist
+	GetBySecretHash(ctx context.Context, hash string) (*APIKey, error)
+
+	// ListByTenant returns every key of an organization, newest first
+	ListByTenant(ctx context.Context, tenant string) ([]*APIKey, error)
+
+	// Update replaces an existing key
+	Update(ctx context.Context, key *APIKey) error
+
+	// Rotate stores the replacement of a key and updates the key atomically
+	Rotate(ctx context.Context, old, replacement *APIKey) error
+
+	// TouchLastUsed records when a key was last used
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
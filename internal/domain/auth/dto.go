@@ -1,7 +1,31 @@
 package auth
 
+import "github.com/google/uuid"
+
 // LoginInput represents the data required for a user to log in.
 type LoginInput struct {
-	Email    string
-	Password string
+	Email        string
+	Password     string
+	UserAgent    string // Recorded on the session; optional
+	ClientIP     string // Recorded on the session and counts failed attempts; optional
+	CaptchaToken string // Required after repeated failed attempts when CAPTCHA escalation is enabled
+	RememberMe   bool   // Issues a refresh token with the longer "remember me" lifetime
+}
+
+// PasswordResetInput represents the data required to complete an
+// administrator-forced password reset without an access token.
+type PasswordResetInput struct {
+	Email           string
+	CurrentPassword string
+	NewPassword     string
+	UserAgent       string // Recorded on the session; optional
+	ClientIP        string // Recorded on the session; optional
+}
+
+// ExternalLoginInput represents a user signing in with an identity verified
+// outside the service, such as by the SAML identity provider of a tenant.
+type ExternalLoginInput struct {
+	UserID    uuid.UUID
+	UserAgent string // Recorded on the session; optional
+	ClientIP  string // Recorded on the session; optional
 }
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is a time-boxed grant letting an administrator act as
+// another user, e.g. to reproduce a problem the user reported
+type Impersonation struct {
+	ID        uuid.UUID  `json:"id"`         // Carried by the access token as its jti claim
+	ActorID   uuid.UUID  `json:"actor_id"`   // The administrator acting as the user
+	SubjectID uuid.UUID  `json:"subject_id"` // The user being impersonated
+	Reason    string     `json:"reason"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the token of the impersonation is accepted at now
+func (i *Impersonation) IsActive(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// ImpersonationToken is the access token minted for an impersonation.
+// It cannot be refreshed.
+type ImpersonationToken struct {
+	Impersonation *Impersonation
+	AccessToken   string
+}
+
+// Principal is the identity an access token authenticates
+type Principal struct {
+	UserID uuid.UUID // The subject, whose account the request acts on
+	// ActorID is the administrator acting as the subject through an
+	// impersonation token; uuid.Nil for the subject's own tokens
+	ActorID         uuid.UUID
+	ImpersonationID uuid.UUID // Set along with ActorID
+}
+
+// Impersonated reports whether someone other than the subject is acting
+func (p Principal) Impersonated() bool {
+	return p.ActorID != uuid.Nil
+}
+
+// ImpersonationRepository stores impersonations so that their tokens can be
+// revoked before they expire
+type ImpersonationRepository interface {
+	// Create records a new impersonation
+	Create(ctx context.Context, impersonation *Impersonation) error
+
+	// GetByID returns an impersonation, or nil when there is none with that ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Impersonation, error)
+
+	// Revoke marks an impersonation revoked at the given time
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
@@ -4,32 +4,49 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/useragent"
 )
 
 // TokenPair represents an access and refresh token pair
 type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"` // Zero when no refresh token was issued
 }
 
+// SessionType tells which refresh token lifetime a session gets
+type SessionType string
+
+// Session types
+const (
+	SessionStandard   SessionType = "standard"    // jwt.refresh_token_expire_days
+	SessionRememberMe SessionType = "remember_me" // jwt.remember_me_refresh_token_expire_days
+)
+
 // Session represents a user authentication session
 type Session struct {
-	ID           string    `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	RefreshToken string    `json:"-"` // Never expose in JSON
-	UserAgent    string    `json:"user_agent"`
-	ClientIP     string    `json:"client_ip"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           string      `json:"id"`
+	UserID       uuid.UUID   `json:"user_id"`
+	RefreshToken string      `json:"-"` // Never expose in JSON
+	Type         SessionType `json:"type"`
+	UserAgent    string      `json:"user_agent"`
+	DeviceLabel  string      `json:"device_label"` // e.g. "Chrome on macOS"
+	ClientIP     string      `json:"client_ip"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	CreatedAt    time.Time   `json:"created_at"`
 }
 
 // NewSession creates a new user session
-func NewSession(userID uuid.UUID, refreshToken, userAgent, clientIP string, expiry time.Duration) *Session {
+func NewSession(userID uuid.UUID, refreshToken string, sessionType SessionType, userAgent, clientIP string, expiry time.Duration) *Session {
 	return &Session{
 		ID:           uuid.New().String(),
 		UserID:       userID,
 		RefreshToken: refreshToken,
+		Type:         sessionType,
 		UserAgent:    userAgent,
+		DeviceLabel:  useragent.Label(userAgent),
 		ClientIP:     clientIP,
 		ExpiresAt:    time.Now().Add(expiry),
 		CreatedAt:    time.Now(),
@@ -40,3 +57,60 @@ func NewSession(userID uuid.UUID, refreshToken, userAgent, clientIP string, expi
 func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
+
+// LoginResult is the outcome of a sign-in attempt
+type LoginResult string
+
+// Recorded sign-in outcomes
+const (
+	LoginSucceeded             LoginResult = "success"
+	LoginInvalidPassword       LoginResult = "invalid_password"
+	LoginAccountDisabled       LoginResult = "account_disabled"
+	LoginPasswordResetRequired LoginResult = "password_reset_required"
+	LoginPasswordExpired       LoginResult = "password_expired"
+)
+
+// LoginRecord is an entry in the sign-in history of a user
+type LoginRecord struct {
+	ID        uuid.UUID   `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Result    LoginResult `json:"result"`
+	ClientIP  string      `json:"client_ip"`
+	UserAgent string      `json:"user_agent"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Succeeded reports whether the sign-in went through
+func (r *LoginRecord) Succeeded() bool {
+	return r.Result == LoginSucceeded
+}
+
+// JSONWebKey is a public verification key in JWK format (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA public exponent
+	Curve     string `json:"crv,omitempty"` // EC curve name
+	X         string `json:"x,omitempty"`   // EC x coordinate
+	Y         string `json:"y,omitempty"`   // EC y coordinate
+}
+
+// KeyInfo describes a Redis key holding auth state. Refresh tokens never
+// appear in it, whether in key names or values; they are replaced by their
+// fingerprint so keys can still be matched up.
+type KeyInfo struct {
+	Key       string
+	Namespace string
+	Type      string        // Redis type, "none" when the key does not exist
+	TTL       time.Duration // Negative when the key does not expire or does not exist
+	Value     string        // String keys
+	Fields    map[string]string
+}
+
+// Exists reports whether the key is present in Redis
+func (k *KeyInfo) Exists() bool {
+	return k.Type != "none"
+}
@@ -2,11 +2,16 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrStoreUnavailable is wrapped by repository errors when the token store
+// cannot be reached, even after retrying, e.g. during a Redis outage
+var ErrStoreUnavailable = errors.New("auth store unavailable")
+
 // AuthRepository defines the interface for authentication data access
 type AuthRepository interface {
 	// UserID -> RefreshToken mapping
@@ -18,4 +23,93 @@ type AuthRepository interface {
 	SetRefreshTokenUserID(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error
 	GetUserIDByRefreshToken(ctx context.Context, token string) (uuid.UUID, error)
 	DeleteRefreshTokenUserID(ctx context.Context, token string) error
+
+	// PruneOrphanedRefreshTokens deletes the RefreshToken -> UserID mappings
+	// of tokens that are neither the current token of their user nor held by
+	// a live session, returning how many were deleted
+	PruneOrphanedRefreshTokens(ctx context.Context) (int64, error)
+}
+
+// SessionRepository stores the sign-in sessions of each user
+type SessionRepository interface {
+	// SaveSession records a session until it expires
+	SaveSession(ctx context.Context, session *Session) error
+
+	// ListSessions returns the unexpired sessions of a user
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+
+	// DeleteSessions removes every session of a user
+	DeleteSessions(ctx context.Context, userID uuid.UUID) error
+
+	// PruneExpired removes the expired sessions of every user and returns
+	// how many were removed
+	PruneExpired(ctx context.Context) (int64, error)
+}
+
+// SigningKey is an access token signing key generated by rotation
+type SigningKey struct {
+	ID        string
+	Algorithm string // JWT algorithm the key signs with, e.g. HS256
+	Material  []byte // HMAC secret, or PEM encoded RSA or EC private key
+	CreatedAt time.Time
+}
+
+// SigningKeyRepository keeps the signing keys generated by rotation where
+// every instance of the service loads them from
+type SigningKeyRepository interface {
+	// SaveSigningKey stores a new key
+	SaveSigningKey(ctx context.Context, key *SigningKey) error
+
+	// ListSigningKeys returns up to limit keys of algorithm, newest first
+	ListSigningKeys(ctx context.Context, algorithm string, limit int) ([]*SigningKey, error)
+}
+
+// LoginAttemptRepository counts failed sign-in attempts per client IP and
+// per account so repeated failures can be escalated to a CAPTCHA challenge
+type LoginAttemptRepository interface {
+	// CountFailures returns the failed attempts recorded for the client IP and
+	// for the account; an empty clientIP counts as no failures
+	CountFailures(ctx context.Context, clientIP, email string) (ipFailures, accountFailures int64, err error)
+
+	// RecordFailure counts a failed attempt against the client IP and the
+	// account, forgetting them once no attempt has failed for window
+	RecordFailure(ctx context.Context, clientIP, email string, window time.Duration) error
+
+	// ResetAccountFailures forgets the failed attempts against an account
+	ResetAccountFailures(ctx context.Context, email string) error
+}
+
+// LoginHistoryFilter selects a page of a user's sign-in history
+type LoginHistoryFilter struct {
+	UserID uuid.UUID
+	Offset int
+	Limit  int
+}
+
+// LoginHistoryRepository keeps the sign-in attempts made against each
+// account. Attempts naming no existing account are not recorded.
+type LoginHistoryRepository interface {
+	// Create records a sign-in attempt
+	Create(ctx context.Context, record *LoginRecord) error
+
+	// List returns a page of a user's attempts, newest first, along with the total number of matches
+	List(ctx context.Context, filter LoginHistoryFilter) ([]*LoginRecord, int64, error)
+
+	// Prune deletes the attempts recorded before cutoff and returns how many were deleted
+	Prune(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// Namespaces of the auth keys kept in Redis; rediskey.Schema builds the key names
+const (
+	KeyNamespaceRefreshToken = "refresh_token" // Current refresh token of a user
+	KeyNamespaceTokenOwner   = "user_id"       // User a refresh token belongs to
+	KeyNamespaceSessions     = "sessions"      // Sign-in sessions of a user
+)
+
+// KeyInspector reads the auth keys held in Redis for a user, for debugging
+// token problems without direct Redis access
+type KeyInspector interface {
+	// InspectUserKeys describes every auth key of the user, including the
+	// keys that do not exist
+	InspectUserKeys(ctx context.Context, userID uuid.UUID) ([]*KeyInfo, error)
 }
@@ -11,12 +11,53 @@ type AuthService interface {
 	// Login authenticates a user and returns a token pair
 	Login(ctx context.Context, input LoginInput) (*TokenPair, error)
 
+	// LoginExternal returns a token pair for a user whose identity an external
+	// identity provider has vouched for, without checking a password
+	LoginExternal(ctx context.Context, input ExternalLoginInput) (*TokenPair, error)
+
 	// RefreshToken refreshes an access token using a refresh token and returns a new token pair
 	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 
 	// Logout invalidates a session
 	Logout(ctx context.Context, userID uuid.UUID) error
 
+	// LogoutByRefreshToken invalidates the session a refresh token belongs
+	// to, for clients whose access token has already expired
+	LogoutByRefreshToken(ctx context.Context, refreshToken string) error
+
 	// ValidateToken validates an access token and returns the user ID
 	ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error)
+
+	// Authenticate validates an access token and checks that its user may
+	// still use the API, i.e. is active and has no pending password reset
+	Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error)
+
+	// AuthenticatePrincipal is Authenticate for callers that accept
+	// impersonation tokens; the principal tells the administrator acting
+	// from the user acted as
+	AuthenticatePrincipal(ctx context.Context, accessToken string) (Principal, error)
+
+	// Impersonate lets an administrator act as another user through a
+	// time-boxed access token
+	Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*ImpersonationToken, error)
+
+	// RevokeImpersonation rejects the token of an impersonation from now on
+	RevokeImpersonation(ctx context.Context, id uuid.UUID) (*Impersonation, error)
+
+	// CompletePasswordReset replaces the password of a user flagged for a
+	// reset and returns a token pair; it is the only way such a user can sign in
+	CompletePasswordReset(ctx context.Context, input PasswordResetInput) (*TokenPair, error)
+}
+
+// KeyManager defines the interface for managing access token signing keys
+type KeyManager interface {
+	// ActiveKeyID returns the ID of the key used to sign new tokens
+	ActiveKeyID() string
+
+	// Rotate generates a new signing key, shares it with every instance,
+	// makes it current and returns its ID
+	Rotate(ctx context.Context) (string, error)
+
+	// PublicKeys returns the public keys that verify access tokens (empty for HMAC)
+	PublicKeys() []JSONWebKey
 }
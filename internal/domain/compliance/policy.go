@@ -0,0 +1,30 @@
+package compliance
+
+import (
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// Destinations that user data can be sent to. Deployments grant regions
+// access to them in the compliance configuration.
+const (
+	DestinationExport = "export" // Bulk user exports
+	DestinationEvents = "events" // Events published to external systems
+)
+
+// ResidencyPolicy decides whether user data may leave the service for an
+// external destination (an export job, an event bus, a third-party sync).
+// Every component that ships user data out of the service should consult it.
+type ResidencyPolicy interface {
+	// KnownRegion reports whether region is one of the configured residency regions
+	KnownRegion(region string) bool
+
+	// ResidencyOf returns the effective residency region of a user
+	ResidencyOf(user *domainUser.User) string
+
+	// Allows reports whether data resident in region may be sent to destination.
+	// Destinations without a configured rule are denied.
+	Allows(region, destination string) bool
+
+	// FilterUsers returns the users whose data may be sent to destination
+	FilterUsers(users []*domainUser.User, destination string) []*domainUser.User
+}
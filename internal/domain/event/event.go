@@ -0,0 +1,83 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies what happened to a user
+type Type string
+
+// Supported types
+const (
+	TypeProfileUpdated Type = "profile.updated"       // The profile changed; clients reload it
+	TypeSessionRevoked Type = "session.revoked"       // The user signed out everywhere; refresh tokens no longer work
+	TypeForcedLogout   Type = "session.forced_logout" // The user was signed out by someone else, see Reason
+
+	TypeUserRegistered Type = "user.registered" // A new account signed up
+	TypeUserLoggedIn   Type = "user.logged_in"  // An account signed in with its password
+	TypeUserDeleted    Type = "user.deleted"    // An account was deleted
+)
+
+// ForUser reports whether events of type t are sent to the clients of the
+// user they are about
+func (t Type) ForUser() bool {
+	switch t {
+	case TypeProfileUpdated, TypeSessionRevoked, TypeForcedLogout:
+		return true
+	default:
+		return false
+	}
+}
+
+// ForAdmins reports whether events of type t are shown on admin dashboards
+func (t Type) ForAdmins() bool {
+	switch t {
+	case TypeUserRegistered, TypeUserLoggedIn, TypeUserDeleted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reasons of a TypeForcedLogout event
+const (
+	ReasonPasswordResetRequired = "password_reset_required" // An administrator requires a new password
+	ReasonDeactivated           = "deactivated"             // An administrator deactivated the account
+	ReasonDeleted               = "deleted"                 // An administrator deleted the account
+	ReasonRevoked               = "revoked"                 // An operator revoked the sessions from a runbook
+)
+
+// Event is something that happened to a user, delivered to the clients the
+// user has connected or to admin dashboards depending on its type
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       Type      `json:"type"`
+	UserID     uuid.UUID `json:"user_id"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Publisher hands events to every subscriber, on every instance. Publishing
+// is best effort: events only inform clients, so a failure is logged by the
+// publisher and never fails the operation that raised the event.
+type Publisher interface {
+	// Publish sends e, assigning its ID and OccurredAt when they are unset
+	Publish(ctx context.Context, e Event)
+}
+
+// Subscriber delivers the published events to handlers
+type Subscriber interface {
+	// Subscribe calls handler with every event until unsubscribe is called.
+	// Handlers run one at a time and must not block.
+	Subscribe(handler func(Event)) (unsubscribe func())
+}
+
+// Discard is a Publisher that drops every event, for callers without an event bus
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, Event) {}
@@ -0,0 +1,126 @@
+package message
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+)
+
+// Severity tells clients how prominently to display a message
+type Severity string
+
+// Supported severities
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Valid reports whether s is a known severity
+func (s Severity) Valid() bool {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return true
+	default:
+		return false
+	}
+}
+
+// SystemMessage is an announcement shown in client applications, such as a
+// maintenance notice or a policy update
+type SystemMessage struct {
+	ID        uuid.UUID   `json:"id"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	Severity  Severity    `json:"severity"`
+	Roles     []rbac.Role `json:"roles,omitempty"`   // Empty targets every role, including anonymous clients
+	Tenants   []string    `json:"tenants,omitempty"` // Empty targets every tenant; names never contain commas
+	StartsAt  *time.Time  `json:"starts_at,omitempty"`
+	EndsAt    *time.Time  `json:"ends_at,omitempty"`
+	CreatedBy uuid.UUID   `json:"created_by"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Audience identifies who is asking for messages. An empty Role is an
+// anonymous client; an empty Tenant matches only untargeted messages.
+// Both come from the caller's account, never from client-supplied input.
+type Audience struct {
+	Role   rbac.Role
+	Tenant string
+}
+
+// IsActive reports whether the message is within its display window at now
+func (m *SystemMessage) IsActive(now time.Time) bool {
+	if m.StartsAt != nil && now.Before(*m.StartsAt) {
+		return false
+	}
+	if m.EndsAt != nil && !now.Before(*m.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// VisibleTo reports whether the message targets the audience
+func (m *SystemMessage) VisibleTo(audience Audience) bool {
+	if len(m.Roles) > 0 && !containsRole(m.Roles, audience.Role) {
+		return false
+	}
+	if len(m.Tenants) > 0 && !containsString(m.Tenants, audience.Tenant) {
+		return false
+	}
+	return true
+}
+
+func containsRole(roles []rbac.Role, role rbac.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Input holds the administrator-editable fields of a message
+type Input struct {
+	Title    string
+	Body     string
+	Severity Severity
+	Roles    []rbac.Role
+	Tenants  []string
+	StartsAt *time.Time
+	EndsAt   *time.Time
+}
+
+// Repository defines the interface for system message storage
+type Repository interface {
+	// Create stores a new message
+	Create(ctx context.Context, message *SystemMessage) error
+
+	// GetByID retrieves a message by ID, returning nil if it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*SystemMessage, error)
+
+	// Update replaces an existing message
+	Update(ctx context.Context, message *SystemMessage) error
+
+	// Delete removes a message by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List returns every message, newest first
+	List(ctx context.Context) ([]*SystemMessage, error)
+
+	// ListActive returns the messages whose display window contains now, newest first
+	ListActive(ctx context.Context, now time.Time) ([]*SystemMessage, error)
+}
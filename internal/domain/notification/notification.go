@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// Kind identifies what a notification tells the user; it selects the
+// template the notification is rendered with
+type Kind string
+
+// Supported kinds
+const (
+	KindPasswordResetRequired Kind = "password_reset_required" // An administrator requires a new password at next sign-in
+	KindPasswordChanged       Kind = "password_changed"        // Security alert sent after the password was changed
+	KindNewDeviceLogin        Kind = "new_device_login"        // Security alert sent after a sign-in from a new device or IP address
+)
+
+// Channel is the medium a notification is delivered through
+type Channel string
+
+// Supported channels
+const (
+	ChannelEmail   Channel = "email"
+	ChannelWebhook Channel = "webhook"
+)
+
+// Recipient is the user a notification is about and addressed to
+type Recipient struct {
+	UserID uuid.UUID
+	Email  string
+	Name   string
+}
+
+// RecipientOf addresses a notification to u
+func RecipientOf(u *user.User) Recipient {
+	return Recipient{
+		UserID: u.ID,
+		Email:  u.Email,
+		Name:   strings.TrimSpace(u.FirstName + " " + u.LastName),
+	}
+}
+
+// Notification is a message to deliver to a user on every configured channel
+type Notification struct {
+	ID        uuid.UUID
+	Kind      Kind
+	Recipient Recipient
+	Subject   string
+	Body      string
+	CreatedAt time.Time
+}
+
+// DeadLetter records a notification that could not be delivered on a
+// channel, so it can be inspected and re-sent by hand
+type DeadLetter struct {
+	ID             uuid.UUID
+	NotificationID uuid.UUID
+	Kind           Kind
+	Channel        Channel
+	UserID         uuid.UUID
+	Email          string
+	Subject        string
+	Body           string
+	Attempts       int
+	LastError      string
+	CreatedAt      time.Time
+}
+
+// Notifier sends notifications to users. Delivery is asynchronous and best
+// effort: the notifier retries failed sends itself and records those that
+// fail for good as dead letters, so callers never wait on or handle them.
+type Notifier interface {
+	Notify(ctx context.Context, kind Kind, recipient Recipient)
+}
+
+// Discard is a Notifier that drops every notification, for deployments
+// without notification providers
+var Discard Notifier = discard{}
+
+type discard struct{}
+
+func (discard) Notify(context.Context, Kind, Recipient) {}
+
+// DeadLetterRepository stores notifications whose delivery failed for good
+type DeadLetterRepository interface {
+	Create(ctx context.Context, letter *DeadLetter) error
+}
@@ -0,0 +1,127 @@
+package organization
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is what a member may do within an organization. Roles are ranked:
+// owners can do everything admins can, and admins everything members can.
+type Role string
+
+// Supported roles, highest first
+const (
+	RoleOwner  Role = "owner"  // Manages members of every role
+	RoleAdmin  Role = "admin"  // Manages admins and members
+	RoleMember Role = "member" // Sees the organization and its members
+)
+
+// Roles lists every role a member can hold, highest first
+var Roles = []Role{RoleOwner, RoleAdmin, RoleMember}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// CanManage reports whether a member with role r may invite, change or
+// remove members holding role other. Only owners and admins manage members,
+// and never members ranked above themselves.
+func (r Role) CanManage(other Role) bool {
+	return r.rank() >= RoleAdmin.rank() && r.rank() >= other.rank()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleAdmin:
+		return 2
+	case RoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Status tells whether a membership is in effect
+type Status string
+
+// Supported statuses
+const (
+	StatusInvited Status = "invited" // Waiting for the user to accept
+	StatusActive  Status = "active"
+)
+
+// Organization is a group of users, such as a company or a team
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"` // Unique, URL-safe name
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Member is a user's membership of an organization. Invitations are
+// memberships in StatusInvited until the user accepts them.
+type Member struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Email          string     `json:"email"` // Of the user; read only
+	Role           Role       `json:"role"`
+	Status         Status     `json:"status"`
+	InvitedBy      *uuid.UUID `json:"invited_by,omitempty"` // Nil for the creator of the organization
+	CreatedAt      time.Time  `json:"created_at"`
+	JoinedAt       *time.Time `json:"joined_at,omitempty"` // Nil until the invitation is accepted
+}
+
+// IsActive reports whether the membership is in effect
+func (m *Member) IsActive() bool {
+	return m.Status == StatusActive
+}
+
+// Input holds the fields of a new organization chosen by its creator
+type Input struct {
+	Name string
+	Slug string // Derived from Name when empty
+}
+
+// Repository defines the interface for organization and membership storage
+type Repository interface {
+	// Create stores a new organization together with its first member, atomically
+	Create(ctx context.Context, org *Organization, owner *Member) error
+
+	// GetByID retrieves an organization by ID, returning nil if it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*Organization, error)
+
+	// GetBySlug retrieves an organization by slug, returning nil if it does not exist
+	GetBySlug(ctx context.Context, slug string) (*Organization, error)
+
+	// ListByUser returns the organizations a user is an active member of, by name
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Organization, error)
+
+	// GetMember retrieves the membership of a user, returning nil if there is none
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*Member, error)
+
+	// ListMembers returns every membership of an organization, including
+	// invitations, oldest first
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*Member, error)
+
+	// ListInvitations returns the pending invitations of a user, newest first
+	ListInvitations(ctx context.Context, userID uuid.UUID) ([]*Member, error)
+
+	// AddMember stores a new membership
+	AddMember(ctx context.Context, member *Member) error
+
+	// UpdateMember replaces the role, status and join time of a membership
+	UpdateMember(ctx context.Context, member *Member) error
+
+	// RemoveMember deletes a membership
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+
+	// CountOwners returns how many active owners an organization has
+	CountOwners(ctx context.Context, orgID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,89 @@
+package rbac
+
+// Role identifies a named set of permissions
+type Role string
+
+// Permission identifies a single action that can be granted to a role
+type Permission string
+
+// Built-in roles
+const (
+	RoleAdmin    Role = "admin"
+	RoleSupport  Role = "support"
+	RoleOrgAdmin Role = "org_admin"
+	RoleUser     Role = "user"
+)
+
+// Built-in permissions
+const (
+	PermissionUsersRead      Permission = "users:read"
+	PermissionUsersWrite     Permission = "users:write"
+	PermissionUsersDelete    Permission = "users:delete"
+	PermissionSessionsRevoke Permission = "sessions:revoke"
+	PermissionRolesRead      Permission = "roles:read"
+	PermissionProfileRead    Permission = "profile:read"
+	PermissionProfileWrite   Permission = "profile:write"
+	PermissionAPIKeysManage  Permission = "api_keys:manage"
+)
+
+// RoleDefinition describes a role and the permissions it grants
+type RoleDefinition struct {
+	Name        Role
+	Description string
+	Permissions []Permission
+}
+
+// PermissionDefinition describes a permission
+type PermissionDefinition struct {
+	Name        Permission
+	Description string
+}
+
+// Permissions lists every permission known to the system, in display order
+var Permissions = []PermissionDefinition{
+	{Name: PermissionUsersRead, Description: "View any user account"},
+	{Name: PermissionUsersWrite, Description: "Modify any user account"},
+	{Name: PermissionUsersDelete, Description: "Delete any user account"},
+	{Name: PermissionSessionsRevoke, Description: "Revoke user sessions and tokens"},
+	{Name: PermissionRolesRead, Description: "View roles and the permission matrix"},
+	{Name: PermissionProfileRead, Description: "View own profile"},
+	{Name: PermissionProfileWrite, Description: "Modify own profile"},
+	{Name: PermissionAPIKeysManage, Description: "Manage the API keys of own organization"},
+}
+
+// Roles lists every role known to the system, in display order
+var Roles = []RoleDefinition{
+	{
+		Name:        RoleAdmin,
+		Description: "Full administrative access",
+		Permissions: []Permission{
+			PermissionUsersRead, PermissionUsersWrite, PermissionUsersDelete,
+			PermissionSessionsRevoke, PermissionRolesRead,
+			PermissionProfileRead, PermissionProfileWrite,
+			PermissionAPIKeysManage,
+		},
+	},
+	{
+		Name:        RoleSupport,
+		Description: "Customer support staff",
+		Permissions: []Permission{
+			PermissionUsersRead, PermissionSessionsRevoke, PermissionRolesRead,
+			PermissionProfileRead, PermissionProfileWrite,
+		},
+	},
+	{
+		Name:        RoleOrgAdmin,
+		Description: "Administrator of an organization",
+		Permissions: []Permission{
+			PermissionProfileRead, PermissionProfileWrite,
+			PermissionAPIKeysManage,
+		},
+	},
+	{
+		Name:        RoleUser,
+		Description: "Regular end user",
+		Permissions: []Permission{
+			PermissionProfileRead, PermissionProfileWrite,
+		},
+	},
+}
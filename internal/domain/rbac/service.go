@@ -0,0 +1,21 @@
+package rbac
+
+import "context"
+
+// RoleService defines the interface for role and permission queries
+type RoleService interface {
+	// ListRoles returns every role with the permissions it grants
+	ListRoles(ctx context.Context) ([]RoleDefinition, error)
+
+	// ListPermissions returns every permission with the roles that grant it
+	ListPermissions(ctx context.Context) ([]PermissionGrant, error)
+
+	// HasPermission reports whether the role grants the permission
+	HasPermission(role Role, permission Permission) bool
+}
+
+// PermissionGrant is a row of the effective permission matrix
+type PermissionGrant struct {
+	Permission PermissionDefinition
+	Roles      []Role
+}
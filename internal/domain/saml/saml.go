@@ -0,0 +1,71 @@
+// Package saml defines the SAML identity providers through which the users
+// of a tenant sign in with their enterprise accounts
+package saml
+
+import (
+	"context"
+	"time"
+)
+
+// IdentityProvider is the SAML identity provider of a tenant
+type IdentityProvider struct {
+	Tenant      string `json:"tenant"`
+	EntityID    string `json:"entityId"`         // Issuer of the assertions
+	SSOURL      string `json:"ssoUrl,omitempty"` // Where users start signing in at the identity provider
+	Certificate string `json:"certificate"`      // PEM of the certificate assertions are signed with
+	// Attributes holding the profile of the user; an empty EmailAttribute
+	// takes the email from the name identifier of the assertion
+	EmailAttribute     string `json:"emailAttribute,omitempty"`
+	FirstNameAttribute string `json:"firstNameAttribute,omitempty"`
+	LastNameAttribute  string `json:"lastNameAttribute,omitempty"`
+	// JITProvisioning creates the account of a user signing in for the first
+	// time; otherwise only existing accounts of the tenant can sign in
+	JITProvisioning bool      `json:"jitProvisioning"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// Input holds the administrator-editable fields of an identity provider
+type Input struct {
+	EntityID           string
+	SSOURL             string
+	Certificate        string // PEM, or the base64 of the DER as found in identity provider metadata
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	JITProvisioning    bool
+	Enabled            bool
+}
+
+// Repository defines the interface for identity provider storage
+type Repository interface {
+	// Get returns the identity provider of tenant, or nil when it has none
+	Get(ctx context.Context, tenant string) (*IdentityProvider, error)
+
+	// Save creates or replaces the identity provider of its tenant
+	Save(ctx context.Context, provider *IdentityProvider) error
+
+	// Delete removes the identity provider of tenant
+	Delete(ctx context.Context, tenant string) error
+
+	// List returns every identity provider, ordered by tenant
+	List(ctx context.Context) ([]*IdentityProvider, error)
+}
+
+// AssertionCache remembers the assertions that signed users in, so that an
+// intercepted assertion cannot be posted again while it is still valid
+type AssertionCache interface {
+	// Claim records an assertion of tenant until expiresAt, reporting false
+	// when it was already recorded
+	Claim(ctx context.Context, tenant, assertionID string, expiresAt time.Time) (bool, error)
+}
+
+// SignInInput represents a SAML response posted to the assertion consumer
+// service of a tenant
+type SignInInput struct {
+	Tenant       string
+	SAMLResponse string // Base64, as posted by the browser
+	UserAgent    string // Recorded on the session; optional
+	ClientIP     string // Recorded on the session; optional
+}
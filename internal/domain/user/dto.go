@@ -6,4 +6,15 @@ type RegisterUserInput struct {
 	Password  string
 	FirstName string
 	LastName  string
+	Residency string // Optional data residency region
+	// CaptchaToken is required when registration CAPTCHA is enabled
+	CaptchaToken string
+	ClientIP     string // Passed to the CAPTCHA provider
+}
+
+// Availability reports whether signup identifiers are free to use.
+// A nil field means the identifier was not checked.
+type Availability struct {
+	EmailAvailable    *bool
+	UsernameAvailable *bool
 }
@@ -0,0 +1,10 @@
+package user
+
+// EmailPolicy decides which email addresses may be used to sign up, either
+// through self-service registration or by provisioning for a tenant.
+// Implementations may be plugged in per tenant.
+type EmailPolicy interface {
+	// CheckEmail returns an EMAIL_POLICY_VIOLATION error if email may not
+	// be used by an account of tenant; self-service accounts have no tenant
+	CheckEmail(tenant, email string) error
+}
@@ -2,24 +2,65 @@ package user
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 )
 
+// ErrDuplicate is wrapped by repository errors when a write conflicts with
+// the ID, username or normalized email of another user, e.g. when
+// concurrent registrations of an address both passed the existence check
+var ErrDuplicate = errors.New("user already exists")
+
 // Repository defines the interface for user data access
 type Repository interface {
-	// Create stores a new user
+	// Create stores a new user. Conflicts with another user fail with
+	// ErrDuplicate, as do those of CreateBatch and Update.
 	Create(ctx context.Context, user *User) error
 
+	// CreateBatch stores several new users atomically: either all are
+	// stored or none is
+	CreateBatch(ctx context.Context, users []*User) error
+
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 
-	// GetByEmail retrieves a user by email
+	// GetByIDs retrieves the users with the given IDs in no particular
+	// order; IDs of missing users are skipped
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*User, error)
+
+	// GetByEmail retrieves the user whose normalized email is email
 	GetByEmail(ctx context.Context, email string) (*User, error)
 
+	// GetByUsername retrieves a user by username
+	GetByUsername(ctx context.Context, username string) (*User, error)
+
 	// Update updates an existing user
 	Update(ctx context.Context, user *User) error
 
 	// Delete removes a user by ID
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List returns a page of users ordered by creation time, newest first,
+	// along with the total number of users
+	List(ctx context.Context, filter ListFilter) ([]*User, int64, error)
+
+	// ListAfter returns up to filter.Limit users matching filter whose ID sorts
+	// after afterID, ordered by ID. Pass uuid.Nil for the first page. Unlike
+	// List it ignores filter.Offset, so every match can be paged through
+	// without skipping or repeating users that are created meanwhile.
+	ListAfter(ctx context.Context, filter ListFilter, afterID uuid.UUID) ([]*User, error)
+}
+
+// NormalizationRepository records which normalizations of email addresses
+// every stored address has been rewritten with, so that a normalization is
+// only used for lookups once existing accounts can be found by it
+type NormalizationRepository interface {
+	// RecordNormalization records that every stored address has the form
+	// the named normalization produces
+	RecordNormalization(ctx context.Context, name string) error
+
+	// NormalizationCompleted reports whether the named normalization was
+	// recorded
+	NormalizationCompleted(ctx context.Context, name string) (bool, error)
 }
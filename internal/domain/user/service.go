@@ -23,6 +23,11 @@ type UserService interface {
 	// UpdatePassword changes a user's password
 	UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error
 
+	// RehashPassword upgrades the stored hash of a user who has just proven
+	// their password, when it was made with another algorithm or other
+	// parameters than new hashes are
+	RehashPassword(ctx context.Context, user *User, password string) error
+
 	// DeleteUser removes a user
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
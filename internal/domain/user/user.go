@@ -2,10 +2,13 @@ package user
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/password"
 )
 
 // User represents a user in the system.
@@ -14,31 +17,92 @@ type User struct {
 	Username  string    `json:"username"`
 	FirstName string    `json:"first_name,omitempty"`
 	LastName  string    `json:"last_name,omitempty"`
-	Password  string    `json:"-"` // Store hashed password, exclude from JSON output
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	// DisplayName is how the user wants to be shown to others; empty means
+	// clients fall back to the first and last name
+	DisplayName string `json:"display_name,omitempty"`
+	Password    string `json:"-"` // Store hashed password, exclude from JSON output
+	Email       string `json:"email"`
+	// NormalizedEmail is the form of Email accounts are looked up and kept
+	// unique by, so differently spelled addresses of a mailbox match
+	NormalizedEmail string    `json:"-"`
+	Residency       string    `json:"residency,omitempty"` // Data residency region, e.g. "EU"; empty means the configured default
+	Tenant          string    `json:"tenant,omitempty"`    // Organisation the account belongs to; empty for accounts outside any tenant
+	Locale          string    `json:"locale,omitempty"`    // Preferred language of API messages, e.g. "zh"; empty follows Accept-Language
+	Role            rbac.Role `json:"role"`
+	IsActive        bool      `json:"is_active"`
+	// PasswordResetRequired is set by an administrator; the user should be
+	// prompted to choose a new password on their next sign-in
+	PasswordResetRequired bool `json:"password_reset_required"`
+	// PasswordChangedAt is when the current password was set; it expires
+	// once older than the configured password max age
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	// PasswordExpiresAt is set by an administrator to expire the current
+	// password before its max age; nil leaves it to the max age alone
+	PasswordExpiresAt *time.Time `json:"password_expires_at,omitempty"`
+	// Metadata holds custom attributes, so adopters can extend users
+	// without changing the schema
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ListFilter selects a page of users. Zero-valued criteria do not filter.
+type ListFilter struct {
+	Query  string    // Case-insensitive substring of the email, username or name
+	Role   rbac.Role // Exact role
+	Active *bool     // Account status
+	Tenant string    // Exact tenant; empty does not filter
+	// Metadata matches users having every one of these attributes
+	Metadata map[string]string
+	Offset   int
+	Limit    int
 }
 
-// UpdateUserParams represents the parameters for updating a user.
+// UpdateUserParams represents the parameters for updating a user. Nil fields
+// are left unchanged; an empty string clears an optional field.
 type UpdateUserParams struct {
-	FirstName string
-	LastName  string
-	Email     string
+	FirstName   *string
+	LastName    *string
+	DisplayName *string
+	Email       *string
+	Locale      *string
+	// Metadata sets the given attributes; nil values remove them and
+	// attributes not listed are left unchanged
+	Metadata map[string]*string
+	// Versions makes the update conditional on the user still being at one
+	// of these versions, so concurrent edits are not lost; empty updates any version
+	Versions []string
+}
+
+// Version identifies the stored state of the user and changes whenever the
+// user is updated. Timestamps are stored with microsecond precision, so the
+// version ignores anything finer.
+func (u *User) Version() string {
+	return strconv.FormatInt(u.UpdatedAt.UnixMicro(), 36)
 }
 
-// HashPassword hashes the user's password.
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+// HashPassword replaces the user's plain password with its hash. Existing
+// hashes keep verifying after the algorithm or its parameters change since
+// each hash records how it was made.
+func (u *User) HashPassword(hasher *password.Hasher) error {
+	hashedPassword, err := hasher.Hash(u.Password)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
-	u.Password = string(hashedPassword)
+	u.Password = hashedPassword
 	return nil
 }
 
+// PasswordExpired reports whether the password must be changed before the
+// user can sign in with it at now. A zero maxAge disables expiry by age.
+func (u *User) PasswordExpired(maxAge time.Duration, now time.Time) bool {
+	if u.PasswordExpiresAt != nil && !now.Before(*u.PasswordExpiresAt) {
+		return true
+	}
+	return maxAge > 0 && !u.PasswordChangedAt.IsZero() && !now.Before(u.PasswordChangedAt.Add(maxAge))
+}
+
 // CheckPassword checks if the provided password matches the hashed password.
-func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	return err == nil
+func (u *User) CheckPassword(plain string) bool {
+	return password.Verify(u.Password, plain)
 }
@@ -0,0 +1,140 @@
+// Package webhook defines the subscriptions through which API consumers
+// receive user lifecycle events as signed HTTP callbacks
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/domain/event"
+)
+
+// EventType names a user lifecycle event a subscription can receive
+type EventType string
+
+// Supported event types
+const (
+	EventUserRegistered EventType = "user.registered" // A new account signed up
+	EventUserUpdated    EventType = "user.updated"    // The profile of an account changed
+	EventUserDeleted    EventType = "user.deleted"    // An account was deleted
+)
+
+// EventTypes returns every event type a subscription can receive
+func EventTypes() []EventType {
+	return []EventType{EventUserRegistered, EventUserUpdated, EventUserDeleted}
+}
+
+// Valid reports whether t is a known event type
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTypeOf returns the webhook event type of a domain event, reporting
+// false for events that are not delivered to webhooks
+func EventTypeOf(t event.Type) (EventType, bool) {
+	switch t {
+	case event.TypeUserRegistered:
+		return EventUserRegistered, true
+	case event.TypeProfileUpdated:
+		return EventUserUpdated, true
+	case event.TypeUserDeleted:
+		return EventUserDeleted, true
+	default:
+		return "", false
+	}
+}
+
+// Subscription is an endpoint registered to receive events
+type Subscription struct {
+	ID          uuid.UUID   `json:"id"`
+	URL         string      `json:"url"`
+	Secret      string      `json:"-"` // Keys the HMAC signature of every delivery
+	EventTypes  []EventType `json:"event_types"`
+	Description string      `json:"description,omitempty"`
+	Active      bool        `json:"active"` // Inactive subscriptions receive nothing
+	CreatedBy   uuid.UUID   `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Receives reports whether the subscription is active and subscribed to t
+func (s *Subscription) Receives(t EventType) bool {
+	if !s.Active {
+		return false
+	}
+	for _, subscribed := range s.EventTypes {
+		if subscribed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Input holds the administrator-editable fields of a subscription. An empty
+// Secret generates one on create and keeps the current one on update.
+type Input struct {
+	URL         string
+	Secret      string
+	EventTypes  []EventType
+	Description string
+	Active      bool
+}
+
+// Delivery records one attempt to deliver an event to a subscription
+type Delivery struct {
+	ID             uuid.UUID     `json:"id"`
+	SubscriptionID uuid.UUID     `json:"subscription_id"`
+	EventID        uuid.UUID     `json:"event_id"`
+	EventType      EventType     `json:"event_type"`
+	Attempt        int           `json:"attempt"`               // 1 for the first try
+	StatusCode     int           `json:"status_code,omitempty"` // 0 when no response was received
+	Error          string        `json:"error,omitempty"`
+	Duration       time.Duration `json:"duration"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// Succeeded reports whether the endpoint accepted the delivery
+func (d *Delivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+// DeliveryFilter selects a page of the deliveries of a subscription
+type DeliveryFilter struct {
+	SubscriptionID uuid.UUID
+	Offset         int
+	Limit          int
+}
+
+// Repository defines the interface for webhook subscription storage
+type Repository interface {
+	// Create stores a new subscription
+	Create(ctx context.Context, subscription *Subscription) error
+
+	// GetByID retrieves a subscription by ID, returning nil if it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*Subscription, error)
+
+	// Update replaces an existing subscription
+	Update(ctx context.Context, subscription *Subscription) error
+
+	// Delete removes a subscription and its delivery log
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List returns every subscription, newest first
+	List(ctx context.Context) ([]*Subscription, error)
+}
+
+// DeliveryRepository defines the interface for the delivery log
+type DeliveryRepository interface {
+	// Create appends a delivery attempt to the log
+	Create(ctx context.Context, delivery *Delivery) error
+
+	// List returns a page of deliveries, newest first, along with the total number of matches
+	List(ctx context.Context, filter DeliveryFilter) ([]*Delivery, int64, error)
+}
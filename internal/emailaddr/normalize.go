@@ -0,0 +1,76 @@
+// Package emailaddr normalizes email addresses so that the spellings of an
+// address that reach the same mailbox identify the same account.
+package emailaddr
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// gmailDomains are the domains of Gmail mailboxes; googlemail.com addresses
+// reach the gmail.com mailbox of the same name
+var gmailDomains = map[string]bool{"gmail.com": true, "googlemail.com": true}
+
+// Normalizer turns email addresses into the form accounts are looked up and
+// kept unique by. The zero Normalizer folds case and converts international
+// domain names to punycode.
+type Normalizer struct {
+	// CollapseGmailAliases drops the dots and the +tag of the local part of
+	// Gmail addresses, which Gmail ignores, so j.doe+shop@googlemail.com
+	// becomes jdoe@gmail.com
+	CollapseGmailAliases bool
+}
+
+// Normalization names identify how a Normalizer normalizes addresses, so
+// that rewriting the stored addresses can be recorded against it
+const (
+	NormalizationStandard     = "standard"
+	NormalizationGmailAliases = "gmail_aliases"
+)
+
+// Name returns the name of the normalization n applies
+func (n Normalizer) Name() string {
+	if n.CollapseGmailAliases {
+		return NormalizationGmailAliases
+	}
+	return NormalizationStandard
+}
+
+// Normalize returns the normalized form of address. Surrounding whitespace
+// is dropped and the address is lower-cased; the domain is converted to
+// punycode when it is a valid international domain name. Addresses without
+// an @ are only trimmed and lower-cased.
+func (n Normalizer) Normalize(address string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	at := strings.LastIndexByte(address, '@')
+	if at < 0 {
+		return address
+	}
+	local, domain := address[:at], strings.TrimSuffix(address[at+1:], ".")
+	if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+		domain = ascii
+	}
+	if n.CollapseGmailAliases && gmailDomains[domain] {
+		local, _, _ = strings.Cut(local, "+")
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// Forms returns the normalized form of address to look it up by, followed
+// by its form without collapsed Gmail aliases when that differs, as
+// addresses stored before CollapseGmailAliases was enabled have it. The
+// fallback only finds such an address by the spelling it was stored with,
+// so stored addresses must be normalized again for aliases to be compared.
+func (n Normalizer) Forms(address string) []string {
+	normalized := n.Normalize(address)
+	if !n.CollapseGmailAliases {
+		return []string{normalized}
+	}
+	if plain := (Normalizer{}).Normalize(address); plain != normalized {
+		return []string{normalized, plain}
+	}
+	return []string{normalized}
+}
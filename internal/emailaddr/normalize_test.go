@@ -0,0 +1,47 @@
+package emailaddr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+	}{
+		{"Case Folding", "Foo@Bar.COM", "foo@bar.com"},
+		{"Surrounding Whitespace", "  jane@example.com ", "jane@example.com"},
+		{"Trailing Dot", "jane@example.com.", "jane@example.com"},
+		{"International Domain", "jane@Bücher.example", "jane@xn--bcher-kva.example"},
+		{"Unicode Local Part", "ÄRGER@example.com", "ärger@example.com"},
+		{"Gmail Untouched By Default", "J.Doe+shop@gmail.com", "j.doe+shop@gmail.com"},
+		{"Not An Address", " Not-An-Address ", "not-an-address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Normalizer{}.Normalize(tt.address))
+		})
+	}
+}
+
+func TestNormalize_CollapseGmailAliases(t *testing.T) {
+	n := Normalizer{CollapseGmailAliases: true}
+
+	assert.Equal(t, "jdoe@gmail.com", n.Normalize("J.Doe+shop@gmail.com"))
+	assert.Equal(t, "jdoe@gmail.com", n.Normalize("j.doe@googlemail.com"))
+	assert.Equal(t, "j.doe+shop@example.com", n.Normalize("j.doe+shop@example.com"), "other domains keep dots and tags")
+	assert.Equal(t, NormalizationGmailAliases, n.Name())
+	assert.Equal(t, NormalizationStandard, Normalizer{}.Name())
+}
+
+func TestForms(t *testing.T) {
+	assert.Equal(t, []string{"j.doe@gmail.com"}, Normalizer{}.Forms("J.Doe@gmail.com"))
+
+	n := Normalizer{CollapseGmailAliases: true}
+	assert.Equal(t, []string{"jdoe@gmail.com", "j.doe@gmail.com"}, n.Forms("J.Doe@gmail.com"))
+	assert.Equal(t, []string{"jdoe@gmail.com"}, n.Forms("jdoe@gmail.com"))
+	assert.Equal(t, []string{"jane@example.com"}, n.Forms("Jane@example.com"))
+}
@@ -0,0 +1,81 @@
+// Package errorreport sends unexpected errors and recovered panics to an
+// error tracker such as Sentry, with the context of the request they
+// occurred in.
+package errorreport
+
+import (
+	"context"
+	"sync"
+)
+
+// Levels of reported events
+const (
+	LevelError = "error" // An unexpected error the request failed with
+	LevelFatal = "fatal" // A panic recovered from a handler
+)
+
+// Transports reported with events
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Event is an error to report and the request it occurred in
+type Event struct {
+	Level     string // LevelError or LevelFatal
+	Err       error  // What went wrong
+	Stack     []byte // Stack of the panicking goroutine; empty for errors
+	Transport string // TransportHTTP or TransportGRPC
+	Route     string // Route path such as "/api/v1/users/:id", or full gRPC method
+	Method    string // HTTP method; empty for gRPC
+	RequestID string
+	UserID    string // Signed-in user; empty for anonymous requests
+}
+
+// Reporter sends events to an error tracker. Report must not block the
+// request it is called from.
+type Reporter interface {
+	Report(event Event)
+}
+
+// Scope collects what becomes known about a request while it is served,
+// such as the signed-in user, for the events reported from outside the
+// handlers that learned it
+type Scope struct {
+	mu     sync.Mutex
+	userID string
+}
+
+type scopeKey struct{}
+
+// NewScope returns a copy of ctx carrying a new, empty Scope
+func NewScope(ctx context.Context) (context.Context, *Scope) {
+	scope := &Scope{}
+	return context.WithValue(ctx, scopeKey{}, scope), scope
+}
+
+// ScopeFromContext returns the Scope carried by ctx, or nil when there is none
+func ScopeFromContext(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(scopeKey{}).(*Scope)
+	return scope
+}
+
+// SetUser records the signed-in user; it does nothing on a nil Scope
+func (s *Scope) SetUser(userID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userID = userID
+}
+
+// User returns the signed-in user, or "" when there is none
+func (s *Scope) User() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.userID
+}
@@ -0,0 +1,24 @@
+package errorreport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	ctx, scope := NewScope(context.Background())
+	assert.Same(t, scope, ScopeFromContext(ctx))
+	assert.Empty(t, scope.User())
+
+	ScopeFromContext(ctx).SetUser("user-1")
+	assert.Equal(t, "user-1", scope.User())
+
+	t.Run("No Scope", func(t *testing.T) {
+		missing := ScopeFromContext(context.Background())
+		assert.Nil(t, missing)
+		assert.NotPanics(t, func() { missing.SetUser("user-1") })
+		assert.Empty(t, missing.User())
+	})
+}
@@ -0,0 +1,175 @@
+package errorreport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxPendingReports bounds the reports in flight; events beyond it while
+// Sentry is slow or down are logged but not reported
+const maxPendingReports = 8
+
+// SentryReporter reports events to Sentry through its store endpoint
+type SentryReporter struct {
+	storeURL    string
+	auth        string // X-Sentry-Auth header
+	environment string
+	serverName  string
+	client      *http.Client
+	pending     chan struct{}
+	logger      *zap.Logger
+}
+
+// NewSentryReporter creates a reporter for a Sentry DSN such as
+// "https://<key>@o1.ingest.sentry.io/<project>". Events are tagged with
+// environment.
+func NewSentryReporter(dsn, environment string, logger *zap.Logger) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("errorreport: invalid sentry dsn: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("errorreport: sentry dsn must be an http or https URL")
+	}
+	key := u.User.Username()
+	path, project := "", strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		path, project = "/"+project[:i], project[i+1:]
+	}
+	if key == "" || project == "" {
+		return nil, errors.New("errorreport: sentry dsn must name a public key and a project")
+	}
+
+	serverName, _ := os.Hostname()
+	return &SentryReporter{
+		storeURL:    fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path, project),
+		auth:        "Sentry sentry_version=7, sentry_client=go-user-service/1.0, sentry_key=" + key,
+		environment: environment,
+		serverName:  serverName,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		pending:     make(chan struct{}, maxPendingReports),
+		logger:      logger,
+	}, nil
+}
+
+// sentryEvent is the subset of the Sentry event payload the reporter sends
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Request     *sentryRequest    `json:"request,omitempty"`
+	Exception   struct {
+		Values []sentryException `json:"values"`
+	} `json:"exception"`
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+// sentryRequest names the route rather than the URL, whose path and query
+// can hold personal data
+type sentryRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Report sends event in the background
+func (s *SentryReporter) Report(event Event) {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		s.logger.Warn("Dropped error report, too many reports pending", zap.String("route", event.Route))
+		return
+	}
+	go func() {
+		defer func() { <-s.pending }()
+		if err := s.send(s.event(event, time.Now())); err != nil {
+			s.logger.Warn("Failed to report error to Sentry", zap.Error(err))
+		}
+	}()
+}
+
+// event builds the Sentry event of e
+func (s *SentryReporter) event(e Event, now time.Time) sentryEvent {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   now.UTC().Format(time.RFC3339),
+		Level:       e.Level,
+		Platform:    "go",
+		Environment: s.environment,
+		ServerName:  s.serverName,
+		Transaction: e.Route,
+		Tags:        map[string]string{"transport": e.Transport},
+	}
+	if e.RequestID != "" {
+		event.Tags["request_id"] = e.RequestID
+	}
+	if e.UserID != "" {
+		event.User = &sentryUser{ID: e.UserID}
+	}
+	if e.Method != "" {
+		event.Request = &sentryRequest{Method: e.Method, URL: e.Route}
+	}
+
+	exception := sentryException{Type: fmt.Sprintf("%T", e.Err)}
+	if e.Err != nil {
+		exception.Value = e.Err.Error()
+	}
+	if e.Level == LevelFatal {
+		exception.Type = "panic"
+	}
+	if len(e.Stack) > 0 {
+		event.Extra = map[string]string{"stack": string(e.Stack)}
+	}
+	event.Exception.Values = []sentryException{exception}
+	return event
+}
+
+func (s *SentryReporter) send(event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode sentry event: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build sentry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sentry event: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sentry returned status %d", resp.StatusCode)
+	}
+	return nil
+}
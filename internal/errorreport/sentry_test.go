@@ -0,0 +1,105 @@
+package errorreport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewSentryReporter(t *testing.T) {
+	tests := []struct {
+		name             string
+		dsn              string
+		expectedStoreURL string
+		expectErr        bool
+	}{
+		{name: "Valid", dsn: "https://abc123@o1.ingest.sentry.io/42", expectedStoreURL: "https://o1.ingest.sentry.io/api/42/store/"},
+		{name: "Path Prefix", dsn: "http://abc123@sentry.internal/sentry/7", expectedStoreURL: "http://sentry.internal/sentry/api/7/store/"},
+		{name: "Missing Key", dsn: "https://o1.ingest.sentry.io/42", expectErr: true},
+		{name: "Missing Project", dsn: "https://abc123@o1.ingest.sentry.io/", expectErr: true},
+		{name: "Not A URL", dsn: "abc123", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter, err := NewSentryReporter(tt.dsn, "test", zap.NewNop())
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStoreURL, reporter.storeURL)
+		})
+	}
+}
+
+func TestSentryReporter_Report(t *testing.T) {
+	events := make(chan sentryEvent, 1)
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("X-Sentry-Auth")
+		var event sentryEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events <- event
+	}))
+	defer server.Close()
+
+	reporter, err := NewSentryReporter(strings.Replace(server.URL, "://", "://abc123@", 1)+"/42", "production", zap.NewNop())
+	require.NoError(t, err)
+	reporter.Report(Event{
+		Level:     LevelError,
+		Err:       errors.New("connection refused"),
+		Transport: TransportHTTP,
+		Route:     "/api/v1/users/:id",
+		Method:    http.MethodGet,
+		RequestID: "req-1",
+		UserID:    "user-1",
+	})
+
+	select {
+	case event := <-events:
+		assert.Contains(t, auth, "sentry_key=abc123")
+		assert.Len(t, event.EventID, 32)
+		assert.Equal(t, LevelError, event.Level)
+		assert.Equal(t, "production", event.Environment)
+		assert.Equal(t, "/api/v1/users/:id", event.Transaction)
+		assert.Equal(t, map[string]string{"transport": TransportHTTP, "request_id": "req-1"}, event.Tags)
+		assert.Equal(t, &sentryUser{ID: "user-1"}, event.User)
+		assert.Equal(t, &sentryRequest{Method: http.MethodGet, URL: "/api/v1/users/:id"}, event.Request)
+		assert.Equal(t, []sentryException{{Type: "*errors.errorString", Value: "connection refused"}}, event.Exception.Values)
+		assert.Empty(t, event.Extra)
+	case <-time.After(5 * time.Second):
+		t.Fatal("error was not reported")
+	}
+}
+
+func TestSentryReporter_PanicEvent(t *testing.T) {
+	reporter, err := NewSentryReporter("https://abc123@o1.ingest.sentry.io/42", "production", zap.NewNop())
+	require.NoError(t, err)
+
+	event := reporter.event(Event{
+		Level:     LevelFatal,
+		Err:       errors.New("boom"),
+		Stack:     []byte("goroutine 1"),
+		Transport: TransportGRPC,
+		Route:     "/user.v1.UserService/GetProfile",
+	}, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, "2026-10-16T12:00:00Z", event.Timestamp)
+	assert.Equal(t, LevelFatal, event.Level)
+	assert.Nil(t, event.User)
+	assert.Nil(t, event.Request, "gRPC calls have no HTTP request")
+	assert.Equal(t, []sentryException{{Type: "panic", Value: "boom"}}, event.Exception.Values)
+	assert.Equal(t, "goroutine 1", event.Extra["stack"])
+}
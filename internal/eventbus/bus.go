@@ -0,0 +1,120 @@
+// Package eventbus carries the domain events of users between instances
+// through Redis Pub/Sub.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// Bus publishes events to a Redis channel that every instance, the
+// publishing one included, subscribes to while Run is running. Delivery is
+// at most once: events published while an instance is not subscribed are
+// lost to it.
+type Bus struct {
+	client  redis.UniversalClient
+	channel string
+	ids     idgen.Generator
+	logger  *zap.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	handlers map[int]func(event.Event)
+	next     int
+}
+
+// NewBus creates a bus on the user events channel of keys
+func NewBus(client redis.UniversalClient, keys rediskey.Schema, ids idgen.Generator, logger *zap.Logger) *Bus {
+	return &Bus{
+		client:   client,
+		channel:  keys.UserEvents(),
+		ids:      ids,
+		logger:   logger,
+		now:      time.Now,
+		handlers: make(map[int]func(event.Event)),
+	}
+}
+
+func (b *Bus) Publish(ctx context.Context, e event.Event) {
+	fields := []zap.Field{zap.String("event_type", string(e.Type)), zap.String("user_id", e.UserID.String())}
+
+	if e.ID == uuid.Nil {
+		id, err := b.ids.NewID()
+		if err != nil {
+			b.logger.Warn("Failed to generate event ID", append(fields, zap.Error(err))...)
+			return
+		}
+		e.ID = id
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = b.now().UTC()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		b.logger.Warn("Failed to encode event", append(fields, zap.Error(err))...)
+		return
+	}
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		b.logger.Warn("Failed to publish event", append(fields, zap.Error(err))...)
+	}
+}
+
+func (b *Bus) Subscribe(handler func(event.Event)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Run hands the events published on any instance to the subscribers until
+// ctx is done. The subscription is re-established after connection errors.
+func (b *Bus) Run(ctx context.Context) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			b.deliver(msg.Payload)
+		}
+	}
+}
+
+// deliver decodes a published event and calls every handler with it
+func (b *Bus) deliver(payload string) {
+	var e event.Event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		b.logger.Warn("Dropping undecodable event", zap.Error(err))
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, handler := range b.handlers {
+		handler(e)
+	}
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+func newTestBus(t *testing.T, logger *zap.Logger) *Bus {
+	keys, err := rediskey.New("test")
+	require.NoError(t, err)
+	// Nothing listens on port 1, so publishing fails straight away
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return NewBus(client, keys, idgen.NewGenerator(idgen.StrategyUUIDv4), logger)
+}
+
+func TestDeliver(t *testing.T) {
+	bus := newTestBus(t, zap.NewNop())
+	userID := uuid.New()
+
+	var first, second []event.Event
+	bus.Subscribe(func(e event.Event) { first = append(first, e) })
+	unsubscribe := bus.Subscribe(func(e event.Event) { second = append(second, e) })
+
+	bus.deliver(`{"id":"11111111-1111-1111-1111-111111111111","type":"profile.updated","user_id":"` + userID.String() + `","occurred_at":"2026-10-16T09:00:00Z"}`)
+	unsubscribe()
+	bus.deliver(`{"type":"session.forced_logout","user_id":"` + userID.String() + `","reason":"deactivated"}`)
+
+	require.Len(t, first, 2)
+	assert.Equal(t, event.Event{
+		ID:         uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Type:       event.TypeProfileUpdated,
+		UserID:     userID,
+		OccurredAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}, first[0])
+	assert.Equal(t, event.ReasonDeactivated, first[1].Reason)
+	assert.Len(t, second, 1, "unsubscribed handlers get no more events")
+}
+
+func TestDeliver_DropsUndecodableEvents(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	bus := newTestBus(t, zap.New(core))
+	called := false
+	bus.Subscribe(func(event.Event) { called = true })
+
+	bus.deliver("not json")
+
+	assert.False(t, called)
+	assert.Equal(t, 1, logs.FilterMessage("Dropping undecodable event").Len())
+}
+
+func TestPublish_LogsFailures(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	bus := newTestBus(t, zap.New(core))
+
+	assert.NotPanics(t, func() {
+		bus.Publish(context.Background(), event.Event{Type: event.TypeSessionRevoked, UserID: uuid.New()})
+	})
+
+	entries := logs.FilterMessage("Failed to publish event").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, string(event.TypeSessionRevoked), entries[0].ContextMap()["event_type"])
+}
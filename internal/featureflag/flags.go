@@ -0,0 +1,222 @@
+package featureflag
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// maxDescriptionLen is the longest flag description accepted
+const maxDescriptionLen = 255
+
+// Errors for rejected flag changes
+var (
+	ErrFlagNotFound       = apperror.New(apperror.CodeFeatureFlagNotFound, "feature flag not found")
+	ErrInvalidName        = apperror.New(apperror.CodeInvalidArgument, "flag names are up to 64 lowercase letters, digits, '-', '_' and '.'")
+	ErrInvalidTenant      = apperror.New(apperror.CodeInvalidArgument, "tenants must be non-empty and must not contain commas")
+	ErrDescriptionTooLong = apperror.New(apperror.CodeInvalidArgument, "description must be at most 255 characters")
+)
+
+// Flag is a stored flag value. It takes precedence over the default
+// configured for the flag until it is deleted.
+type Flag struct {
+	Name        string
+	Enabled     bool     // On for everyone
+	Tenants     []string // On for these tenants even when Enabled is false; names never contain commas
+	Description string
+	UpdatedBy   uuid.UUID // Nil for flags that only have a configured default
+	UpdatedAt   time.Time
+}
+
+// EnabledFor reports whether the flag is on for tenant, which may be empty
+func (f *Flag) EnabledFor(tenant string) bool {
+	return f.Enabled || (tenant != "" && slices.Contains(f.Tenants, tenant))
+}
+
+// Update is the new value of a flag
+type Update struct {
+	Enabled     bool
+	Tenants     []string
+	Description string
+}
+
+// Store persists flag values
+type Store interface {
+	List(ctx context.Context) ([]*Flag, error)
+	Get(ctx context.Context, name string) (*Flag, error) // nil when the flag is not stored
+	Save(ctx context.Context, flag *Flag) error          // Creates or replaces the flag
+	Delete(ctx context.Context, name string) error
+}
+
+// WithTenant returns a copy of ctx evaluating flags for tenant, which is
+// the tenant the request is served for
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return requestctx.WithTenant(ctx, tenant)
+}
+
+// TenantFromContext returns the tenant flags are evaluated for in ctx, if any
+func TenantFromContext(ctx context.Context) string {
+	return requestctx.Tenant(ctx)
+}
+
+// Flags evaluates feature flags. A flag is on when the request overrides it
+// on (see Override), else when its stored value is on for everyone or for
+// the tenant in the context, else when it is configured on by default.
+// Stored values are cached for the refresh interval, so changes made on
+// another instance take effect within it.
+type Flags struct {
+	store    Store
+	defaults map[string]bool
+	refresh  time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	cached   map[string]*Flag
+	loadedAt time.Time
+}
+
+// New creates flags backed by store, falling back to the configured defaults
+// for flags that are not stored
+func New(store Store, defaults map[string]bool, refresh time.Duration, logger *zap.Logger) *Flags {
+	return &Flags{
+		store:    store,
+		defaults: maps.Clone(defaults),
+		refresh:  refresh,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether the named flag is on for the request ctx belongs to
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	if enabled, ok := Override(ctx, name); ok {
+		return enabled
+	}
+	if flag, ok := f.snapshot(ctx)[name]; ok {
+		return flag.EnabledFor(TenantFromContext(ctx))
+	}
+	return f.defaults[name]
+}
+
+// Default returns the value configured for the named flag, used when it is not stored
+func (f *Flags) Default(name string) bool {
+	return f.defaults[name]
+}
+
+// List returns the stored flags and the flags that only have a configured
+// default, by name
+func (f *Flags) List(ctx context.Context) ([]*Flag, error) {
+	stored, err := f.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make(map[string]*Flag, len(stored)+len(f.defaults))
+	for name, enabled := range f.defaults {
+		flags[name] = &Flag{Name: name, Enabled: enabled}
+	}
+	for _, flag := range stored {
+		flags[flag.Name] = flag
+	}
+	list := make([]*Flag, 0, len(flags))
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		list = append(list, flags[name])
+	}
+	return list, nil
+}
+
+// Set stores the value of the named flag on behalf of actorID
+func (f *Flags) Set(ctx context.Context, actorID uuid.UUID, name string, update Update) (*Flag, error) {
+	if !validName(name) {
+		return nil, ErrInvalidName
+	}
+	if len(update.Description) > maxDescriptionLen {
+		return nil, ErrDescriptionTooLong
+	}
+	tenants := make([]string, 0, len(update.Tenants))
+	for _, tenant := range update.Tenants {
+		tenant = strings.TrimSpace(tenant)
+		// Tenants are stored comma-separated
+		if tenant == "" || strings.Contains(tenant, ",") {
+			return nil, ErrInvalidTenant
+		}
+		if !slices.Contains(tenants, tenant) {
+			tenants = append(tenants, tenant)
+		}
+	}
+
+	flag := &Flag{
+		Name:        name,
+		Enabled:     update.Enabled,
+		Tenants:     tenants,
+		Description: strings.TrimSpace(update.Description),
+		UpdatedBy:   actorID,
+		UpdatedAt:   f.now(),
+	}
+	if err := f.store.Save(ctx, flag); err != nil {
+		return nil, err
+	}
+	f.invalidate()
+	return flag, nil
+}
+
+// Delete removes the stored value of the named flag, restoring its configured default
+func (f *Flags) Delete(ctx context.Context, name string) error {
+	flag, err := f.store.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if flag == nil {
+		return ErrFlagNotFound
+	}
+	if err := f.store.Delete(ctx, name); err != nil {
+		return err
+	}
+	f.invalidate()
+	return nil
+}
+
+// snapshot returns the stored flags, reloading them once the refresh
+// interval has passed. When the store fails the previous flags stay in use
+// until the next interval so a database outage does not flip features.
+func (f *Flags) snapshot(ctx context.Context) map[string]*Flag {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if f.cached != nil && now.Sub(f.loadedAt) < f.refresh {
+		return f.cached
+	}
+	f.loadedAt = now
+
+	stored, err := f.store.List(ctx)
+	if err != nil {
+		f.logger.Warn("Failed to load feature flags; using previous values", zap.Error(err))
+		if f.cached == nil {
+			f.cached = map[string]*Flag{} // Configured defaults until the store recovers
+		}
+		return f.cached
+	}
+	f.cached = make(map[string]*Flag, len(stored))
+	for _, flag := range stored {
+		f.cached[flag.Name] = flag
+	}
+	return f.cached
+}
+
+// invalidate makes the next evaluation reload the stored flags
+func (f *Flags) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loadedAt = time.Time{}
+}
@@ -0,0 +1,169 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryStore keeps flags in a map and counts the loads of all flags
+type memoryStore struct {
+	flags map[string]*Flag
+	err   error
+	loads int
+}
+
+func (s *memoryStore) List(_ context.Context) ([]*Flag, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	flags := make([]*Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, name string) (*Flag, error) {
+	return s.flags[name], s.err
+}
+
+func (s *memoryStore) Save(_ context.Context, flag *Flag) error {
+	s.flags[flag.Name] = flag
+	return s.err
+}
+
+func (s *memoryStore) Delete(_ context.Context, name string) error {
+	delete(s.flags, name)
+	return s.err
+}
+
+func TestFlags_Enabled(t *testing.T) {
+	store := &memoryStore{flags: map[string]*Flag{
+		"new-login-flow": {Name: "new-login-flow", Tenants: []string{"acme"}},
+		"legacy-export":  {Name: "legacy-export", Enabled: false},
+	}}
+	flags := New(store, map[string]bool{"legacy-export": true, "dark-mode": true}, time.Minute, zaptest.NewLogger(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		flag     string
+		expected bool
+	}{
+		{name: "Stored Off", ctx: ctx, flag: "new-login-flow", expected: false},
+		{name: "Stored On For Tenant", ctx: WithTenant(ctx, "acme"), flag: "new-login-flow", expected: true},
+		{name: "Stored Off For Other Tenant", ctx: WithTenant(ctx, "globex"), flag: "new-login-flow", expected: false},
+		{name: "Stored Value Beats Default", ctx: ctx, flag: "legacy-export", expected: false},
+		{name: "Configured Default", ctx: ctx, flag: "dark-mode", expected: true},
+		{name: "Unknown Flag", ctx: ctx, flag: "unknown", expected: false},
+		{name: "Override Beats Stored Value", ctx: NewContext(ctx, Overrides{"new-login-flow": true}), flag: "new-login-flow", expected: true},
+		{name: "Override Beats Default", ctx: NewContext(ctx, Overrides{"dark-mode": false}), flag: "dark-mode", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, flags.Enabled(tt.ctx, tt.flag))
+		})
+	}
+}
+
+func TestFlags_Caching(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	store := &memoryStore{flags: map[string]*Flag{}}
+	flags := New(store, map[string]bool{"dark-mode": true}, time.Minute, zaptest.NewLogger(t))
+	flags.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.False(t, flags.Enabled(ctx, "new-login-flow"))
+	assert.Equal(t, 1, store.loads)
+
+	// Changes made elsewhere show up once the refresh interval has passed
+	store.flags["new-login-flow"] = &Flag{Name: "new-login-flow", Enabled: true}
+	assert.False(t, flags.Enabled(ctx, "new-login-flow"))
+	now = now.Add(time.Minute)
+	assert.True(t, flags.Enabled(ctx, "new-login-flow"))
+	assert.Equal(t, 2, store.loads)
+
+	// Changes made through Set take effect immediately
+	_, err := flags.Set(ctx, uuid.New(), "new-login-flow", Update{Enabled: false})
+	require.NoError(t, err)
+	assert.False(t, flags.Enabled(ctx, "new-login-flow"))
+
+	// A failing store keeps the previous values until the next interval
+	store.err = errors.New("database unavailable")
+	now = now.Add(time.Minute)
+	assert.False(t, flags.Enabled(ctx, "new-login-flow"))
+	assert.True(t, flags.Enabled(ctx, "dark-mode"))
+	loads := store.loads
+	flags.Enabled(ctx, "new-login-flow")
+	assert.Equal(t, loads, store.loads)
+}
+
+func TestFlags_Set(t *testing.T) {
+	actorID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		store := &memoryStore{flags: map[string]*Flag{}}
+		flags := New(store, nil, time.Minute, zaptest.NewLogger(t))
+
+		flag, err := flags.Set(ctx, actorID, "new-login-flow", Update{Tenants: []string{" acme ", "globex", "acme"}, Description: " Passwordless sign-in "})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"acme", "globex"}, flag.Tenants)
+		assert.Equal(t, "Passwordless sign-in", flag.Description)
+		assert.Equal(t, actorID, flag.UpdatedBy)
+		assert.Same(t, flag, store.flags["new-login-flow"])
+	})
+
+	for name, tc := range map[string]struct {
+		flag     string
+		update   Update
+		expected error
+	}{
+		"Invalid Name":         {flag: "New Login Flow", expected: ErrInvalidName},
+		"Empty Tenant":         {flag: "new-login-flow", update: Update{Tenants: []string{" "}}, expected: ErrInvalidTenant},
+		"Tenant With Comma":    {flag: "new-login-flow", update: Update{Tenants: []string{"acme,globex"}}, expected: ErrInvalidTenant},
+		"Description Too Long": {flag: "new-login-flow", update: Update{Description: string(make([]byte, 256))}, expected: ErrDescriptionTooLong},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flags := New(&memoryStore{flags: map[string]*Flag{}}, nil, time.Minute, zaptest.NewLogger(t))
+
+			_, err := flags.Set(ctx, actorID, tc.flag, tc.update)
+
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestFlags_ListAndDelete(t *testing.T) {
+	store := &memoryStore{flags: map[string]*Flag{
+		"new-login-flow": {Name: "new-login-flow", Enabled: true},
+		"legacy-export":  {Name: "legacy-export"},
+	}}
+	flags := New(store, map[string]bool{"legacy-export": true, "dark-mode": true}, time.Minute, zaptest.NewLogger(t))
+	ctx := context.Background()
+
+	list, err := flags.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, []string{"dark-mode", "legacy-export", "new-login-flow"}, []string{list[0].Name, list[1].Name, list[2].Name})
+	assert.True(t, list[0].Enabled)
+	assert.False(t, list[1].Enabled)
+
+	// Deleting the stored value restores the configured default
+	assert.False(t, flags.Enabled(ctx, "legacy-export"))
+	require.NoError(t, flags.Delete(ctx, "legacy-export"))
+	assert.True(t, flags.Enabled(ctx, "legacy-export"))
+
+	assert.ErrorIs(t, flags.Delete(ctx, "dark-mode"), ErrFlagNotFound)
+}
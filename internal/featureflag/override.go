@@ -0,0 +1,170 @@
+// Package featureflag gates behavior behind named flags. Flags default to
+// the values configured for the environment and can be toggled at runtime,
+// for everyone or per tenant, through a Store (see Flags). A request may
+// also carry overrides that take precedence over both, so that end-to-end
+// tests can exercise gated features in a shared environment without
+// toggling them for everyone.
+package featureflag
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Override headers. OverrideHeader lists the flags to override, e.g.
+// "new-login-flow=on,legacy-export=off"; SignatureHeader carries
+// "<expiry unix seconds>.<hex HMAC-SHA256 of expiry.value>".
+const (
+	OverrideHeader  = "X-Feature-Overrides"
+	SignatureHeader = "X-Feature-Overrides-Signature"
+)
+
+// maxOverrides bounds the flags one request may override
+const maxOverrides = 32
+
+// Errors for rejected override headers
+var (
+	ErrOverridesDisabled = errors.New("feature flag overrides are disabled")
+	ErrInvalidSignature  = errors.New("invalid feature flag override signature")
+	ErrSignatureExpired  = errors.New("feature flag override signature expired")
+	ErrMalformedOverride = errors.New("malformed feature flag override")
+)
+
+// Overrides maps flag names to the value forced for one request
+type Overrides map[string]bool
+
+// String renders the overrides in header form, sorted by flag name
+func (o Overrides) String() string {
+	parts := make([]string, 0, len(o))
+	for _, name := range slices.Sorted(maps.Keys(o)) {
+		value := "off"
+		if o[name] {
+			value = "on"
+		}
+		parts = append(parts, name+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying overrides
+func NewContext(ctx context.Context, overrides Overrides) context.Context {
+	return context.WithValue(ctx, contextKey{}, overrides)
+}
+
+// Override returns the value forced for the named flag by the request ctx
+// belongs to, if any
+func Override(ctx context.Context, name string) (enabled, ok bool) {
+	overrides, _ := ctx.Value(contextKey{}).(Overrides)
+	enabled, ok = overrides[name]
+	return enabled, ok
+}
+
+// Verifier checks signed override headers. Only holders of the secret, i.e.
+// test tooling, can produce overrides, so clients cannot turn on features
+// that are not released to them.
+type Verifier struct {
+	secret []byte
+	maxTTL time.Duration
+	now    func() time.Time
+}
+
+// NewVerifier creates a verifier for signatures made with secret that expire
+// at most maxTTL from now. An empty secret disables overrides.
+func NewVerifier(secret string, maxTTL time.Duration) *Verifier {
+	return &Verifier{secret: []byte(secret), maxTTL: maxTTL, now: time.Now}
+}
+
+// Enabled reports whether overrides are accepted at all
+func (v *Verifier) Enabled() bool {
+	return len(v.secret) > 0
+}
+
+// Sign returns the signature header value admitting value until expires
+func (v *Verifier) Sign(value string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + hex.EncodeToString(v.mac(exp, value))
+}
+
+// Verify checks signature against value and parses the overrides it admits
+func (v *Verifier) Verify(value, signature string) (Overrides, error) {
+	if !v.Enabled() {
+		return nil, ErrOverridesDisabled
+	}
+
+	exp, sum, ok := strings.Cut(signature, ".")
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	mac, err := hex.DecodeString(sum)
+	if err != nil || !hmac.Equal(mac, v.mac(exp, value)) {
+		return nil, ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	expires, now := time.Unix(seconds, 0), v.now()
+	if !now.Before(expires) {
+		return nil, ErrSignatureExpired
+	}
+	// Long-lived signatures would amount to a standing toggle if leaked
+	if expires.Sub(now) > v.maxTTL {
+		return nil, fmt.Errorf("%w: expires more than %s from now", ErrInvalidSignature, v.maxTTL)
+	}
+
+	return Parse(value)
+}
+
+func (v *Verifier) mac(exp, value string) []byte {
+	h := hmac.New(sha256.New, v.secret)
+	h.Write([]byte(exp + "." + value))
+	return h.Sum(nil)
+}
+
+// Parse parses a comma-separated list of name=on|off pairs
+func Parse(value string) (Overrides, error) {
+	overrides := Overrides{}
+	for _, pair := range strings.Split(value, ",") {
+		name, state, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !validName(name) {
+			return nil, fmt.Errorf("%w: %q", ErrMalformedOverride, pair)
+		}
+		switch state {
+		case "on", "true", "1":
+			overrides[name] = true
+		case "off", "false", "0":
+			overrides[name] = false
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrMalformedOverride, pair)
+		}
+	}
+	if len(overrides) > maxOverrides {
+		return nil, fmt.Errorf("%w: more than %d flags", ErrMalformedOverride, maxOverrides)
+	}
+	return overrides, nil
+}
+
+// validName accepts lowercase flag names such as "new-login-flow"
+func validName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
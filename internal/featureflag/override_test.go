@@ -0,0 +1,69 @@
+package featureflag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	overrides, err := Parse("new-login-flow=on, legacy-export=off,beta.v2=true")
+	require.NoError(t, err)
+	assert.Equal(t, Overrides{"new-login-flow": true, "legacy-export": false, "beta.v2": true}, overrides)
+	assert.Equal(t, "beta.v2=on,legacy-export=off,new-login-flow=on", overrides.String())
+
+	for _, value := range []string{"", "new-login-flow", "new-login-flow=maybe", "New-Flow=on", "=on"} {
+		_, err := Parse(value)
+		assert.ErrorIs(t, err, ErrMalformedOverride, value)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := NewVerifier("secret", time.Hour)
+	v.now = func() time.Time { return now }
+	value := "new-login-flow=on"
+
+	tests := []struct {
+		name      string
+		verifier  *Verifier
+		value     string
+		signature string
+		expected  error
+	}{
+		{name: "Valid", verifier: v, value: value, signature: v.Sign(value, now.Add(time.Minute))},
+		{name: "Disabled", verifier: NewVerifier("", time.Hour), value: value, signature: v.Sign(value, now.Add(time.Minute)), expected: ErrOverridesDisabled},
+		{name: "Wrong Secret", verifier: v, value: value, signature: NewVerifier("other", time.Hour).Sign(value, now.Add(time.Minute)), expected: ErrInvalidSignature},
+		{name: "Tampered Value", verifier: v, value: "new-login-flow=off", signature: v.Sign(value, now.Add(time.Minute)), expected: ErrInvalidSignature},
+		{name: "Missing Signature", verifier: v, value: value, expected: ErrInvalidSignature},
+		{name: "Expired", verifier: v, value: value, signature: v.Sign(value, now.Add(-time.Second)), expected: ErrSignatureExpired},
+		{name: "Expiry Too Far Ahead", verifier: v, value: value, signature: v.Sign(value, now.Add(2*time.Hour)), expected: ErrInvalidSignature},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			overrides, err := tc.verifier.Verify(tc.value, tc.signature)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, Overrides{"new-login-flow": true}, overrides)
+		})
+	}
+}
+
+func TestOverride(t *testing.T) {
+	_, ok := Override(context.Background(), "new-login-flow")
+	assert.False(t, ok)
+
+	ctx := NewContext(context.Background(), Overrides{"new-login-flow": false})
+	enabled, ok := Override(ctx, "new-login-flow")
+	assert.True(t, ok)
+	assert.False(t, enabled)
+	_, ok = Override(ctx, "other")
+	assert.False(t, ok)
+}
@@ -0,0 +1,238 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Status is the health of a dependency
+type Status string
+
+// Dependency health, from best to worst
+const (
+	StatusHealthy  Status = "healthy"
+	StatusDegraded Status = "degraded" // Responding, but slower than the slow threshold
+	StatusDown     Status = "down"     // Failing or timing out
+)
+
+// severity orders statuses from best to worst
+func (s Status) severity() int {
+	switch s {
+	case StatusHealthy:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Check probes a dependency; Probe returns an error when it is unusable
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+// Transition records a dependency settling into a new status
+type Transition struct {
+	Dependency string
+	From       Status
+	To         Status
+	Since      time.Time // When the new status was first observed
+	Err        error     // Error of the latest probe, if it failed
+}
+
+// DependencyState is the current health of a dependency
+type DependencyState struct {
+	Name      string
+	Status    Status
+	Since     time.Time // When the dependency entered Status
+	CheckedAt time.Time
+	Latency   time.Duration // Duration of the latest probe
+}
+
+// Options tunes a Monitor
+type Options struct {
+	Interval      time.Duration // Time between probes
+	Timeout       time.Duration // Probes running longer count as down
+	SlowThreshold time.Duration // Probes running longer count as degraded; 0 never degrades
+	Debounce      int           // Consecutive probes needed to change status; below 1 means 1
+}
+
+// Monitor probes dependencies periodically and tracks their status. A new
+// status only takes effect after Options.Debounce consecutive probes agree,
+// so a single slow or failed probe does not flap the status; each change is
+// logged and passed to the transition listeners.
+type Monitor struct {
+	checks    []Check
+	opts      Options
+	logger    *zap.Logger
+	now       func() time.Time
+	mu        sync.RWMutex
+	states    map[string]*state
+	listeners []func(Transition)
+}
+
+// state is the tracked health of a dependency along with the status it may be moving to
+type state struct {
+	DependencyState
+	pending      Status
+	pendingSince time.Time
+	streak       int
+}
+
+// NewMonitor creates a monitor of checks. Dependencies have no status until
+// their first probe, which sets it without debouncing.
+func NewMonitor(opts Options, logger *zap.Logger, checks ...Check) *Monitor {
+	states := make(map[string]*state, len(checks))
+	for _, check := range checks {
+		states[check.Name] = &state{DependencyState: DependencyState{Name: check.Name}}
+	}
+	return &Monitor{
+		checks: checks,
+		opts:   opts,
+		logger: logger,
+		now:    time.Now,
+		states: states,
+	}
+}
+
+// OnTransition registers fn to be called whenever a dependency changes
+// status. It must be called before Run.
+func (m *Monitor) OnTransition(fn func(Transition)) {
+	m.listeners = append(m.listeners, fn)
+}
+
+// Run probes every dependency right away and then once per interval, until ctx is done
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes every dependency concurrently and records the results
+func (m *Monitor) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, check := range m.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.probe(ctx, check)
+		}()
+	}
+	wg.Wait()
+}
+
+// probe runs a single check and records its outcome
+func (m *Monitor) probe(ctx context.Context, check Check) {
+	probeCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	start := m.now()
+	err := check.Probe(probeCtx)
+	latency := m.now().Sub(start)
+	if ctx.Err() != nil {
+		return // Shutting down; the probe says nothing about the dependency
+	}
+
+	status := StatusHealthy
+	switch {
+	case err != nil:
+		status = StatusDown
+	case m.opts.SlowThreshold > 0 && latency > m.opts.SlowThreshold:
+		status = StatusDegraded
+	}
+	m.observe(check.Name, status, latency, err)
+}
+
+// observe records a probe outcome, changing the status of the dependency
+// once enough consecutive outcomes agree
+func (m *Monitor) observe(name string, status Status, latency time.Duration, err error) {
+	at := m.now()
+
+	m.mu.Lock()
+	s := m.states[name]
+	s.CheckedAt = at
+	s.Latency = latency
+
+	var transition *Transition
+	switch {
+	case s.Status == "":
+		s.Status, s.Since = status, at
+	case status == s.Status:
+		s.pending, s.streak = "", 0
+	default:
+		if status != s.pending {
+			s.pending, s.pendingSince, s.streak = status, at, 0
+		}
+		s.streak++
+		if s.streak >= max(m.opts.Debounce, 1) {
+			transition = &Transition{Dependency: name, From: s.Status, To: status, Since: s.pendingSince, Err: err}
+			s.Status, s.Since = status, s.pendingSince
+			s.pending, s.streak = "", 0
+		}
+	}
+	m.mu.Unlock()
+
+	if transition != nil {
+		m.emit(*transition)
+	}
+}
+
+// emit logs a transition and passes it to the listeners
+func (m *Monitor) emit(t Transition) {
+	fields := []zap.Field{
+		zap.String("dependency", t.Dependency),
+		zap.String("from", string(t.From)),
+		zap.String("to", string(t.To)),
+		zap.Time("since", t.Since),
+	}
+	if t.Err != nil {
+		fields = append(fields, zap.Error(t.Err))
+	}
+	if t.To.severity() > t.From.severity() {
+		m.logger.Warn("Dependency health worsened", fields...)
+	} else {
+		m.logger.Info("Dependency health improved", fields...)
+	}
+
+	for _, fn := range m.listeners {
+		fn(t)
+	}
+}
+
+// Snapshot returns the state of every dependency probed so far, in the order of the checks
+func (m *Monitor) Snapshot() []DependencyState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make([]DependencyState, 0, len(m.checks))
+	for _, check := range m.checks {
+		if s := m.states[check.Name]; s.Status != "" {
+			states = append(states, s.DependencyState)
+		}
+	}
+	return states
+}
+
+// Overall returns the worst status among states, or healthy when there are none
+func Overall(states []DependencyState) Status {
+	overall := StatusHealthy
+	for _, s := range states {
+		if s.Status.severity() > overall.severity() {
+			overall = s.Status
+		}
+	}
+	return overall
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDependency answers probes with a configurable error and latency, advancing a fake clock
+type fakeDependency struct {
+	clock   *time.Time
+	err     error
+	latency time.Duration
+}
+
+func (d *fakeDependency) probe(ctx context.Context) error {
+	*d.clock = d.clock.Add(d.latency)
+	return d.err
+}
+
+func newTestMonitor(debounce int) (*Monitor, *fakeDependency, *[]Transition) {
+	clock := time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+	db := &fakeDependency{clock: &clock, latency: 5 * time.Millisecond}
+	m := NewMonitor(Options{
+		Interval:      time.Second,
+		Timeout:       time.Second,
+		SlowThreshold: 100 * time.Millisecond,
+		Debounce:      debounce,
+	}, zap.NewNop(), Check{Name: "database", Probe: db.probe})
+	m.now = func() time.Time { return clock }
+
+	var transitions []Transition
+	m.OnTransition(func(t Transition) { transitions = append(transitions, t) })
+	return m, db, &transitions
+}
+
+// checkAt advances the clock to the next interval and probes
+func checkAt(m *Monitor, db *fakeDependency) {
+	*db.clock = db.clock.Add(time.Second)
+	m.CheckAll(context.Background())
+}
+
+func TestMonitor_Debounce(t *testing.T) {
+	m, db, transitions := newTestMonitor(3)
+
+	checkAt(m, db)
+	state := m.Snapshot()[0]
+	assert.Equal(t, StatusHealthy, state.Status)
+	started := state.Since
+	assert.Empty(t, *transitions, "the first probe sets the status without an event")
+
+	// A blip shorter than the debounce does not change the status
+	db.err = errors.New("connection refused")
+	checkAt(m, db)
+	checkAt(m, db)
+	db.err = nil
+	checkAt(m, db)
+	assert.Equal(t, StatusHealthy, m.Snapshot()[0].Status)
+	assert.Equal(t, started, m.Snapshot()[0].Since)
+	assert.Empty(t, *transitions)
+
+	// Enough consecutive failures move it down, dated from the first failure
+	db.err = errors.New("connection refused")
+	checkAt(m, db)
+	firstFailure := m.Snapshot()[0].CheckedAt
+	checkAt(m, db)
+	checkAt(m, db)
+	require.Len(t, *transitions, 1)
+	assert.Equal(t, Transition{Dependency: "database", From: StatusHealthy, To: StatusDown, Since: firstFailure, Err: db.err}, (*transitions)[0])
+	assert.Equal(t, StatusDown, m.Snapshot()[0].Status)
+	assert.Equal(t, firstFailure, m.Snapshot()[0].Since)
+}
+
+func TestMonitor_Degraded(t *testing.T) {
+	m, db, transitions := newTestMonitor(1)
+
+	checkAt(m, db)
+	db.latency = 300 * time.Millisecond
+	checkAt(m, db)
+
+	require.Len(t, *transitions, 1)
+	assert.Equal(t, StatusDegraded, (*transitions)[0].To)
+	assert.Equal(t, 300*time.Millisecond, m.Snapshot()[0].Latency)
+
+	db.err = errors.New("timeout")
+	checkAt(m, db)
+	db.err, db.latency = nil, time.Millisecond
+	checkAt(m, db)
+
+	require.Len(t, *transitions, 3)
+	assert.Equal(t, StatusDown, (*transitions)[1].To)
+	assert.Equal(t, StatusDegraded, (*transitions)[1].From)
+	assert.Equal(t, StatusHealthy, (*transitions)[2].To)
+}
+
+func TestOverall(t *testing.T) {
+	assert.Equal(t, StatusHealthy, Overall(nil))
+	assert.Equal(t, StatusDegraded, Overall([]DependencyState{{Status: StatusHealthy}, {Status: StatusDegraded}}))
+	assert.Equal(t, StatusDown, Overall([]DependencyState{{Status: StatusDown}, {Status: StatusDegraded}}))
+}
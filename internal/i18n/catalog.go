@@ -0,0 +1,214 @@
+package i18n
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// codeMessages holds the generic message of every error code in each
+// language other than English
+var codeMessages = map[Language]map[apperror.Code]string{
+	Chinese: {
+		apperror.CodeInternal:              "发生意外错误，请稍后重试。",
+		apperror.CodeInvalidArgument:       "请求参数无效",
+		apperror.CodeUnauthenticated:       "需要身份验证",
+		apperror.CodePermissionDenied:      "您无权访问此资源",
+		apperror.CodeUserNotFound:          "用户不存在",
+		apperror.CodeUserAlreadyExists:     "用户已存在",
+		apperror.CodeEmailInUse:            "邮箱已被使用",
+		apperror.CodeIncorrectPassword:     "当前密码不正确",
+		apperror.CodeInvalidCredentials:    "用户名或密码错误",
+		apperror.CodeInvalidToken:          "令牌无效",
+		apperror.CodeInvalidOrExpiredToken: "令牌无效或已过期",
+		apperror.CodeTokenExpired:          "令牌已过期",
+		apperror.CodeTokenNotYetValid:      "令牌尚未生效",
+		apperror.CodeTokenMalformed:        "令牌格式错误",
+		apperror.CodeSessionNotFound:       "会话不存在",
+		apperror.CodeRateLimited:           "请求过于频繁，请稍后重试。",
+		apperror.CodeCaptchaFailed:         "验证码校验失败",
+		apperror.CodeCaptchaRequired:       "登录失败次数过多，需要完成验证码",
+		apperror.CodeAccountDisabled:       "账户已停用",
+		apperror.CodeMessageNotFound:       "系统消息不存在",
+		apperror.CodePasswordResetRequired: "需要重置密码",
+		apperror.CodeReadOnly:              "服务暂时处于只读模式，请稍后重试。",
+		apperror.CodeImportJobNotFound:     "导入任务不存在",
+		apperror.CodeAPIKeyNotFound:        "API 密钥不存在",
+		apperror.CodeInvalidAPIKey:         "API 密钥无效、已过期或已吊销",
+		apperror.CodeServiceUnavailable:    "服务暂时不可用，请稍后重试。",
+		apperror.CodeExportJobNotFound:     "导出任务不存在",
+		apperror.CodeExportNotReady:        "导出尚未完成",
+		apperror.CodeOrganizationNotFound:  "组织不存在",
+		apperror.CodeOrganizationSlugInUse: "组织标识已被使用",
+		apperror.CodeMemberNotFound:        "成员不存在",
+		apperror.CodeInvitationNotFound:    "邀请不存在",
+		apperror.CodeAlreadyMember:         "用户已是成员或已被邀请",
+		apperror.CodeLastOwner:             "组织必须至少保留一位所有者",
+		apperror.CodeFeatureFlagNotFound:   "功能开关不存在",
+		apperror.CodeVersionMismatch:       "用户在读取后已被修改",
+		apperror.CodePreconditionRequired:  "缺少请求前提条件",
+		apperror.CodeTimeout:               "请求处理超时，请稍后重试。",
+		apperror.CodeImpersonationNotFound: "模拟登录不存在",
+		apperror.CodeWebhookNotFound:       "Webhook 不存在",
+		apperror.CodeSAMLProviderNotFound:  "SAML 身份提供方不存在",
+		apperror.CodeInvalidAssertion:      "SAML 断言无效",
+		apperror.CodePasswordExpired:       "密码已过期",
+		apperror.CodeSecurityEventNotFound: "安全事件不存在",
+		apperror.CodeEmailPolicyViolation:  "不允许使用该邮箱地址注册",
+	},
+}
+
+// messages holds the translations of response messages, keyed by their
+// English text, in each language other than English
+var messages = map[Language]map[string]string{
+	Chinese: {
+		// Success messages
+		"Success":                      "成功",
+		"User registered successfully": "注册成功",
+		"System message created":       "系统消息已创建",
+		"Organization created":         "组织已创建",
+		"Invitation created":           "邀请已创建",
+		"Import started":               "导入已开始",
+		"Export queued":                "导出已排队",
+		"API key created":              "API 密钥已创建",
+		"API key rotated":              "API 密钥已轮换",
+		"Impersonation started":        "模拟登录已开始",
+		"Webhook created":              "Webhook 已创建",
+
+		// Request errors raised by the handlers
+		"Something went wrong. Please try again later.": "出现问题，请稍后重试。",
+		"Invalid request data":                          "请求数据无效",
+		"Invalid query parameters":                      "查询参数无效",
+		"User not authenticated":                        "用户未登录",
+		"Authentication required":                       "需要身份验证",
+		"Invalid user ID format":                        "用户 ID 格式无效",
+		"Invalid job ID format":                         "任务 ID 格式无效",
+		"Invalid message ID format":                     "消息 ID 格式无效",
+		"Invalid API key ID format":                     "API 密钥 ID 格式无效",
+		"Invalid organization ID format":                "组织 ID 格式无效",
+		"Invalid impersonation ID format":               "模拟登录 ID 格式无效",
+		"Invalid webhook ID format":                     "Webhook ID 格式无效",
+		"Missing SAMLResponse":                          "缺少 SAMLResponse",
+		"Invalid Last-Event-ID":                         "Last-Event-ID 无效",
+		"Not found":                                     "未找到",
+		"Request body is too large":                     "请求体过大",
+		"Import file is too large":                      "导入文件过大",
+		"An import file is required":                    "需要上传导入文件",
+		"Email is required":                             "邮箱不能为空",
+		"Email or username is required":                 "邮箱或用户名不能为空",
+
+		// Application errors
+		"An unexpected error occurred. Please try again later.":                 "发生意外错误，请稍后重试。",
+		"Too many requests. Please try again later.":                            "请求过于频繁，请稍后重试。",
+		"The service is temporarily read-only. Please try again later.":         "服务暂时处于只读模式，请稍后重试。",
+		"The service is busy. Please try again shortly.":                        "服务繁忙，请稍后重试。",
+		"Access from your network address is not allowed.":                      "不允许从您的网络地址访问",
+		"email addresses of this domain may not sign up":                        "不允许使用该域名的邮箱地址注册",
+		"disposable email addresses may not sign up":                            "不允许使用一次性邮箱地址注册",
+		"The service is temporarily unavailable. Please try again later.":       "服务暂时不可用，请稍后重试。",
+		"The request took too long to complete. Please try again later.":        "请求处理超时，请稍后重试。",
+		"Authorization header is required":                                      "缺少 Authorization 请求头",
+		"Authorization header format must be Bearer {token}":                    "Authorization 请求头的格式必须为 Bearer {token}",
+		"X-API-Key header is required":                                          "缺少 X-API-Key 请求头",
+		"Invalid or expired token":                                              "令牌无效或已过期",
+		"invalid token":                                                         "令牌无效",
+		"token is expired":                                                      "令牌已过期",
+		"token is malformed":                                                    "令牌格式错误",
+		"token is not valid yet":                                                "令牌尚未生效",
+		"invalid or expired refresh token":                                      "刷新令牌无效或已过期",
+		"invalid credentials":                                                   "用户名或密码错误",
+		"incorrect current password":                                            "当前密码不正确",
+		"account is disabled":                                                   "账户已停用",
+		"password reset required":                                               "需要重置密码",
+		"password reset required; set a new password to sign in":                "需要重置密码，请设置新密码后登录",
+		"captcha verification failed":                                           "验证码校验失败",
+		"captcha required after repeated failed sign-in attempts":               "登录失败次数过多，需要完成验证码",
+		"sessions are temporarily unavailable; please try again later":          "会话服务暂时不可用，请稍后重试",
+		"session not found":                                                     "会话不存在",
+		"You do not have permission to access this resource":                    "您无权访问此资源",
+		"user not found":                                                        "用户不存在",
+		"user already exists":                                                   "用户已存在",
+		"email already in use":                                                  "邮箱已被使用",
+		"email cannot be cleared":                                               "邮箱不能清空",
+		"email must be a valid email address":                                   "邮箱格式无效",
+		"password must be at least 8 characters":                                "密码至少需要 8 个字符",
+		"first_name and last_name are required":                                 "first_name 和 last_name 不能为空",
+		"display name must be at most 64 characters":                            "显示名称最多 64 个字符",
+		"locale must be a supported language":                                   "locale 必须是受支持的语言",
+		"residency must be a supported region":                                  "residency 必须是受支持的区域",
+		"user has been modified since it was read":                              "用户在读取后已被修改",
+		"If-Match header with the ETag of the user is required":                 "需要携带用户 ETag 的 If-Match 请求头",
+		"administrators cannot deactivate their own account":                    "管理员不能停用自己的账户",
+		"administrators cannot delete their own account":                        "管理员不能删除自己的账户",
+		"administrators cannot force a password reset on their own account":     "管理员不能强制重置自己的密码",
+		"no password reset is pending for this account":                         "该账户没有待完成的密码重置",
+		"password has expired; set a new password to sign in":                   "密码已过期，请设置新密码后登录",
+		"the new password must differ from the current one":                     "新密码不能与当前密码相同",
+		"system message not found":                                              "系统消息不存在",
+		"title is required":                                                     "标题不能为空",
+		"severity must be one of info, warning, critical":                       "severity 必须是 info、warning 或 critical 之一",
+		"ends_at must be after starts_at":                                       "ends_at 必须晚于 starts_at",
+		"import job not found":                                                  "导入任务不存在",
+		"import files must be CSV or JSON":                                      "导入文件必须是 CSV 或 JSON 格式",
+		"the CSV file could not be parsed":                                      "无法解析 CSV 文件",
+		"the CSV header must include email, password, first_name and last_name": "CSV 表头必须包含 email、password、first_name 和 last_name",
+		"the JSON file must contain an array of user objects":                   "JSON 文件必须包含用户对象数组",
+		"export job not found":                                                  "导出任务不存在",
+		"the export has not completed":                                          "导出尚未完成",
+		"exports must be CSV or JSON":                                           "导出格式必须是 CSV 或 JSON",
+		"API key not found":                                                     "API 密钥不存在",
+		"API key is invalid, expired or revoked":                                "API 密钥无效、已过期或已吊销",
+		"API key is expired, revoked or already rotated":                        "API 密钥已过期、已吊销或已轮换",
+		"API key does not grant the required scope":                             "API 密钥未授予所需的权限范围",
+		"API keys can only be managed by members of an organization":            "只有组织成员可以管理 API 密钥",
+		"at least one scope is required":                                        "至少需要一个权限范围",
+		"scopes must be known scope names":                                      "权限范围必须是已知的名称",
+		"description must be at most 255 characters":                            "描述最多 255 个字符",
+		"expires_at must be in the future":                                      "expires_at 必须是将来的时间",
+		"organization not found":                                                "组织不存在",
+		"organization slug already in use":                                      "组织标识已被使用",
+		"slug must be 3 to 64 lowercase letters, digits and single hyphens":     "标识必须由 3 到 64 个小写字母、数字和单个连字符组成",
+		"name is required and must be at most 100 characters":                   "名称不能为空且最多 100 个字符",
+		"member not found":                                                      "成员不存在",
+		"invitation not found":                                                  "邀请不存在",
+		"user is already a member or invited":                                   "用户已是成员或已被邀请",
+		"an organization must keep at least one owner":                          "组织必须至少保留一位所有者",
+		"role must be owner, admin or member":                                   "角色必须是 owner、admin 或 member",
+		"your role in the organization does not allow this":                     "您在组织中的角色不允许此操作",
+		"impersonation not found":                                               "模拟登录不存在",
+		"the impersonation has ended":                                           "模拟登录已结束",
+		"impersonation tokens are only accepted by the HTTP API":                "模拟登录令牌只能用于 HTTP API",
+		"administrators cannot impersonate themselves":                          "管理员不能模拟自己",
+		"administrators cannot be impersonated":                                 "不能模拟管理员",
+		"webhook not found":                                                     "Webhook 不存在",
+		"url must be an absolute http or https URL":                             "url 必须是绝对的 http 或 https 地址",
+		"at least one event type is required":                                   "至少需要一个事件类型",
+		"event types must be user.registered, user.updated or user.deleted":     "事件类型必须是 user.registered、user.updated 或 user.deleted",
+		"secret must be at least 16 characters":                                 "密钥至少需要 16 个字符",
+		"SAML sign-in is not enabled":                                           "未启用 SAML 登录",
+		"identity provider not found":                                           "身份提供方不存在",
+		"invalid SAML assertion":                                                "SAML 断言无效",
+		"SAML assertion has already been used":                                  "SAML 断言已被使用",
+		"SAML assertion carries no email address":                               "SAML 断言中没有邮箱地址",
+		"no account exists for this user":                                       "该用户没有账户，请联系管理员创建",
+		"the account belongs to another tenant":                                 "该账户属于其他租户",
+		"tenant is required and must be at most 255 characters":                 "租户不能为空且最多 255 个字符",
+		"entityId is required and must be at most 1024 characters":              "entityId 不能为空且最多 1024 个字符",
+		"ssoUrl must be an absolute http or https URL":                          "ssoUrl 必须是绝对的 http 或 https 地址",
+		"certificate must be an RSA X.509 certificate in PEM or base64":         "certificate 必须是 PEM 或 base64 格式的 RSA X.509 证书",
+		"attribute names must be at most 255 characters":                        "属性名最多 255 个字符",
+		"feature flag not found":                                                "功能开关不存在",
+		"level must be one of debug, info, warn or error":                       "level 必须是 debug、info、warn 或 error 之一",
+		"module must be one of app, http, grpc or gorm":                         "module 必须是 app、http、grpc 或 gorm 之一",
+	},
+}
+
+// Message returns message in lang. Messages without a translation of their
+// own fall back to the generic message of their error code, so clients get a
+// less specific message in their language rather than one in English; code
+// is empty for messages that are not errors.
+func Message(lang Language, code apperror.Code, message string) string {
+	if translated, ok := messages[lang][message]; ok {
+		return translated
+	}
+	if translated, ok := codeMessages[lang][code]; ok {
+		return translated
+	}
+	return message
+}
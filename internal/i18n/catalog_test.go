@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+func TestEveryCodeTranslated(t *testing.T) {
+	for _, lang := range Supported() {
+		if lang == English {
+			continue
+		}
+		for _, code := range apperror.Codes() {
+			assert.NotEmpty(t, codeMessages[lang][code], "%s has no %s message", code, lang)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     Language
+		code     apperror.Code
+		message  string
+		expected string
+	}{
+		{name: "English Unchanged", lang: English, code: apperror.CodeUserNotFound, message: "user not found", expected: "user not found"},
+		{name: "Translated Message", lang: Chinese, code: apperror.CodeUserNotFound, message: "user not found", expected: "用户不存在"},
+		{name: "Success Message", lang: Chinese, message: "Success", expected: "成功"},
+		{name: "Code Fallback", lang: Chinese, code: apperror.CodeInvalidArgument, message: "metadata values must be at most 500 characters", expected: "请求参数无效"},
+		{name: "Untranslated Without Code", lang: Chinese, message: "Invalid request data: EOF", expected: "Invalid request data: EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Message(tt.lang, tt.code, tt.message))
+		})
+	}
+}
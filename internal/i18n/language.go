@@ -0,0 +1,71 @@
+// Package i18n translates the messages of API responses. Messages are
+// written in English in the code and translated through a catalog keyed by
+// their English text, falling back to the generic message of their error code.
+package i18n
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Language is the primary subtag of a BCP 47 language tag, e.g. "zh"
+type Language string
+
+// Supported languages
+const (
+	English Language = "en" // The language messages are written in
+	Chinese Language = "zh" // Simplified Chinese
+)
+
+// Default is the language of requests that do not ask for a supported one
+const Default = English
+
+// Supported returns the languages messages can be rendered in
+func Supported() []Language {
+	return []Language{English, Chinese}
+}
+
+// Parse returns the supported language of a tag such as "zh-CN", matching on
+// the primary language subtag
+func Parse(tag string) (Language, bool) {
+	primary := strings.TrimSpace(tag)
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	lang := Language(strings.ToLower(primary))
+	for _, supported := range Supported() {
+		if lang == supported {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate returns the supported language the client prefers most in an
+// Accept-Language header, e.g. "zh-CN,zh;q=0.9,en;q=0.8". It reports false
+// when the header names no supported language.
+func Negotiate(acceptLanguage string) (Language, bool) {
+	var (
+		best    Language
+		bestQ   float64
+		matched bool
+	)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, ok := Parse(tag)
+		// Earlier tags win ties, as clients list them by preference
+		if !ok || q <= 0 || (matched && q <= bestQ) {
+			continue
+		}
+		best, bestQ, matched = lang, q, true
+	}
+	return best, matched
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected Language
+		ok       bool
+	}{
+		{tag: "en", expected: English, ok: true},
+		{tag: "zh-CN", expected: Chinese, ok: true},
+		{tag: "ZH_hans", expected: Chinese, ok: true},
+		{tag: " en-GB ", expected: English, ok: true},
+		{tag: "fr", ok: false},
+		{tag: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			lang, ok := Parse(tt.tag)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, lang)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected Language
+		ok       bool
+	}{
+		{name: "Single", header: "zh-CN", expected: Chinese, ok: true},
+		{name: "Quality", header: "en;q=0.5, zh-TW;q=0.8", expected: Chinese, ok: true},
+		{name: "Unsupported Skipped", header: "fr-FR,fr;q=0.9,en;q=0.8", expected: English, ok: true},
+		{name: "Earlier Wins Tie", header: "en,zh", expected: English, ok: true},
+		{name: "Refused", header: "zh;q=0, en;q=0.1", expected: English, ok: true},
+		{name: "Invalid Quality Skipped", header: "zh;q=high, en;q=0.2", expected: English, ok: true},
+		{name: "Wildcard", header: "*", ok: false},
+		{name: "Empty", header: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, ok := Negotiate(tt.header)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, lang)
+		})
+	}
+}
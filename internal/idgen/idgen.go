@@ -0,0 +1,136 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Strategy names an ID generation scheme
+type Strategy string
+
+// Supported ID strategies
+const (
+	StrategyUUIDv4 Strategy = "uuidv4"
+	StrategyUUIDv7 Strategy = "uuidv7"
+	StrategyULID   Strategy = "ulid"
+)
+
+// ParseStrategy validates a configured strategy name. An empty name selects UUIDv4.
+func ParseStrategy(name string) (Strategy, error) {
+	switch s := Strategy(strings.ToLower(name)); s {
+	case "", StrategyUUIDv4:
+		return StrategyUUIDv4, nil
+	case StrategyUUIDv7, StrategyULID:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unsupported id strategy %q", name)
+	}
+}
+
+// Format renders an ID in the text form of the strategy. ULIDs are stored as
+// 128-bit values without UUID version or variant bits, so they are always
+// rendered as ULID text rather than as non-conforming UUIDs.
+func (s Strategy) Format(id uuid.UUID) string {
+	if s == StrategyULID {
+		return FormatULID(id)
+	}
+	return id.String()
+}
+
+// Generator creates IDs for new entities. All strategies produce 128-bit
+// values stored as uuid.UUID, so existing UUIDv4 IDs keep working.
+type Generator interface {
+	NewID() (uuid.UUID, error)
+}
+
+// GeneratorFunc adapts a function to the Generator interface
+type GeneratorFunc func() (uuid.UUID, error)
+
+// NewID implements Generator
+func (f GeneratorFunc) NewID() (uuid.UUID, error) {
+	return f()
+}
+
+// NewGenerator returns the Generator for a strategy
+func NewGenerator(strategy Strategy) Generator {
+	switch strategy {
+	case StrategyUUIDv7:
+		return GeneratorFunc(uuid.NewV7)
+	case StrategyULID:
+		return GeneratorFunc(newULID)
+	default:
+		return GeneratorFunc(uuid.NewRandom)
+	}
+}
+
+// newULID creates a ULID: a 48-bit millisecond timestamp followed by 80 random bits
+func newULID() (uuid.UUID, error) {
+	var id uuid.UUID
+	ms := uint64(time.Now().UnixMilli())
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(id[:6], ts[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return id, nil
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLength is the length of a ULID in its canonical text form
+const ulidLength = 26
+
+// Parse parses an ID in canonical UUID form (any version) or ULID text form
+func Parse(s string) (uuid.UUID, error) {
+	if len(s) == ulidLength {
+		return parseULID(s)
+	}
+	return uuid.Parse(s)
+}
+
+// FormatULID returns the ULID text form of an ID
+func FormatULID(id uuid.UUID) string {
+	out := make([]byte, ulidLength)
+	// 128 bits are encoded as 26 base32 characters, the first holding the top 3 bits
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	for i := ulidLength - 1; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+func parseULID(s string) (uuid.UUID, error) {
+	var hi, lo uint64
+	for i := 0; i < ulidLength; i++ {
+		v := strings.IndexByte(crockford, upper(s[i]))
+		if v < 0 {
+			return uuid.Nil, fmt.Errorf("invalid ULID character %q", s[i])
+		}
+		if i == 0 && v > 7 {
+			return uuid.Nil, fmt.Errorf("ULID overflows 128 bits")
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return id, nil
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
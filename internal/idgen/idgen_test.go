@@ -0,0 +1,99 @@
+package idgen
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		strategy        string
+		expectedVersion uuid.Version
+	}{
+		{strategy: "", expectedVersion: 4},
+		{strategy: "uuidv4", expectedVersion: 4},
+		{strategy: "UUIDv7", expectedVersion: 7},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.strategy, func(t *testing.T) {
+			strategy, err := ParseStrategy(tc.strategy)
+			require.NoError(t, err)
+			id, err := NewGenerator(strategy).NewID()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedVersion, id.Version())
+		})
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := ParseStrategy("snowflake")
+		assert.Error(t, err)
+	})
+}
+
+func TestTimeOrderedStrategies(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyUUIDv7, StrategyULID} {
+		t.Run(string(strategy), func(t *testing.T) {
+			gen := NewGenerator(strategy)
+			first, err := gen.NewID()
+			require.NoError(t, err)
+			// The leading 48 bits are a millisecond timestamp
+			second, err := gen.NewID()
+			require.NoError(t, err)
+			assert.LessOrEqual(t, string(first[:6]), string(second[:6]))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	v4 := uuid.New()
+
+	t.Run("UUIDv4", func(t *testing.T) {
+		parsed, err := Parse(v4.String())
+		require.NoError(t, err)
+		assert.Equal(t, v4, parsed)
+	})
+
+	t.Run("ULID Round Trip", func(t *testing.T) {
+		id, err := newULID()
+		require.NoError(t, err)
+		text := FormatULID(id)
+		assert.Len(t, text, 26)
+
+		parsed, err := Parse(text)
+		require.NoError(t, err)
+		assert.Equal(t, id, parsed)
+	})
+
+	t.Run("Known ULID", func(t *testing.T) {
+		parsed, err := Parse("00000000000000000000000001")
+		require.NoError(t, err)
+		assert.Equal(t, uuid.MustParse("00000000-0000-0000-0000-000000000001"), parsed)
+		assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", FormatULID(uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := Parse("8ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+		assert.Error(t, err)
+		_, err = Parse("not-an-id")
+		assert.Error(t, err)
+		_, err = Parse("0000000000000000000000000U")
+		assert.Error(t, err)
+	})
+}
+
+func TestStrategyFormat(t *testing.T) {
+	id := uuid.MustParse("01890a5d-ac96-774b-bcce-b302099a8057")
+
+	assert.Equal(t, id.String(), StrategyUUIDv4.Format(id))
+	assert.Equal(t, id.String(), StrategyUUIDv7.Format(id))
+
+	text := StrategyULID.Format(id)
+	assert.Equal(t, FormatULID(id), text)
+	parsed, err := Parse(text)
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+}
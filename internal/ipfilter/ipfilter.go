@@ -0,0 +1,76 @@
+// Package ipfilter restricts the client addresses a listener serves. Rules
+// list single addresses or CIDR ranges to allow and to deny; requests are
+// filtered by the client address clientip resolves behind trusted proxies.
+package ipfilter
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// ErrForbidden is returned for requests from a client address the rules reject
+var ErrForbidden = apperror.New(apperror.CodePermissionDenied, "Access from your network address is not allowed.")
+
+// Filter decides which client addresses are served. Denied addresses are
+// rejected even when they are also allowed; when there is an allow list,
+// only the addresses on it are served. A nil Filter serves every address,
+// so an unconfigured filter needs no special case.
+type Filter struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// New creates a Filter from lists of addresses and CIDR ranges, or nil,
+// serving every address, when both lists are empty
+func New(allow, deny []string) (*Filter, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	allowed, err := clientip.ParsePrefixes(allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow list: %w", err)
+	}
+	denied, err := clientip.ParsePrefixes(deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny list: %w", err)
+	}
+	return &Filter{allow: allowed, deny: denied}, nil
+}
+
+// Allows reports whether requests from addr are served. Invalid addresses
+// are only served when there are no rules.
+func (f *Filter) Allows(addr netip.Addr) bool {
+	if f == nil {
+		return true
+	}
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+	if clientip.Contains(f.deny, addr) {
+		return false
+	}
+	return len(f.allow) == 0 || clientip.Contains(f.allow, addr)
+}
+
+// FromConfig creates the global filter and the filters of the route groups
+// configured in cfg. Groups without rules have no filter.
+func FromConfig(cfg config.IPFilterConfig) (*Filter, map[string]*Filter, error) {
+	global, err := New(cfg.Global.Allow, cfg.Global.Deny)
+	if err != nil {
+		return nil, nil, fmt.Errorf("global IP filter: %w", err)
+	}
+	groups := make(map[string]*Filter, len(cfg.Groups))
+	for group, rules := range cfg.Groups {
+		filter, err := New(rules.Allow, rules.Deny)
+		if err != nil {
+			return nil, nil, fmt.Errorf("IP filter of route group %q: %w", group, err)
+		}
+		groups[group] = filter
+	}
+	return global, groups, nil
+}
@@ -0,0 +1,81 @@
+package ipfilter
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+func TestFilter_Allows(t *testing.T) {
+	filter, err := New([]string{"10.0.0.0/8", "203.0.113.7", "2001:db8::/32"}, []string{"10.0.0.13", "10.1.0.0/16"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.2.3.4", true},
+		{"203.0.113.7", true},
+		{"::ffff:203.0.113.7", true}, // IPv4-mapped addresses match IPv4 rules
+		{"2001:db8::1", true},
+		{"10.0.0.13", false}, // Denied although allowed
+		{"10.1.2.3", false},
+		{"203.0.113.8", false}, // Not on the allow list
+		{"2001:db9::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, filter.Allows(netip.MustParseAddr(tt.addr)))
+		})
+	}
+
+	assert.False(t, filter.Allows(netip.Addr{}), "invalid addresses are rejected")
+}
+
+func TestFilter_DenyOnly(t *testing.T) {
+	filter, err := New(nil, []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+
+	assert.False(t, filter.Allows(netip.MustParseAddr("192.0.2.10")))
+	assert.True(t, filter.Allows(netip.MustParseAddr("198.51.100.1")))
+}
+
+func TestNew_NoRules(t *testing.T) {
+	filter, err := New(nil, nil)
+	require.NoError(t, err)
+
+	assert.Nil(t, filter)
+	assert.True(t, filter.Allows(netip.MustParseAddr("198.51.100.1")))
+	assert.True(t, filter.Allows(netip.Addr{}))
+}
+
+func TestNew_InvalidEntry(t *testing.T) {
+	_, err := New([]string{"10.0.0.0/33"}, nil)
+	assert.ErrorContains(t, err, `invalid allow list: "10.0.0.0/33" is not an IP address or CIDR range`)
+
+	_, err = New(nil, []string{"example.com"})
+	assert.ErrorContains(t, err, `invalid deny list: "example.com" is not an IP address or CIDR range`)
+}
+
+func TestFromConfig(t *testing.T) {
+	global, groups, err := FromConfig(config.IPFilterConfig{
+		Global: config.IPRules{Deny: []string{"192.0.2.0/24"}},
+		Groups: map[string]config.IPRules{
+			"admin": {Allow: []string{"10.0.0.0/8"}},
+			"users": {},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, global.Allows(netip.MustParseAddr("192.0.2.1")))
+	assert.True(t, groups["admin"].Allows(netip.MustParseAddr("10.0.0.1")))
+	assert.False(t, groups["admin"].Allows(netip.MustParseAddr("198.51.100.1")))
+	assert.Nil(t, groups["users"])
+
+	_, _, err = FromConfig(config.IPFilterConfig{Groups: map[string]config.IPRules{"admin": {Allow: []string{"10.0.0"}}}})
+	assert.ErrorContains(t, err, `IP filter of route group "admin"`)
+}
@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// Broker stores jobs between the producers enqueueing them and the workers
+// running them. A claimed job stays with its worker until it is acked,
+// scheduled or buried, or until its deadline passes.
+type Broker interface {
+	// Push adds a job to the pending jobs
+	Push(ctx context.Context, job *Job) error
+
+	// Pop claims the oldest pending job until deadline, returning nil when
+	// no job is pending
+	Pop(ctx context.Context, deadline time.Time) (*Job, error)
+
+	// Ack removes a claimed job that has run
+	Ack(ctx context.Context, job *Job) error
+
+	// Schedule releases a claimed job to run again at the given time
+	Schedule(ctx context.Context, job *Job, at time.Time) error
+
+	// Bury moves a claimed job to the dead jobs, keeping the newest ones
+	Bury(ctx context.Context, job *Job) error
+
+	// Requeue makes pending the scheduled jobs that are due at now and the
+	// claimed jobs whose deadline has passed, returning how many were moved
+	Requeue(ctx context.Context, now time.Time) (int, error)
+}
+
+// maxDeadJobs bounds the dead jobs kept for inspection
+const maxDeadJobs = 10000
+
+// requeueBatch bounds the jobs moved per Requeue call and key
+const requeueBatch = 100
+
+// popScript claims the oldest pending job (KEYS[1]) by adding it to the
+// claimed jobs (KEYS[2]) scored with its deadline (ARGV[1])
+var popScript = redis.NewScript(`
+local raw = redis.call('RPOP', KEYS[1])
+if raw then
+  redis.call('ZADD', KEYS[2], ARGV[1], raw)
+end
+return raw
+`)
+
+// releaseScript removes a claimed job (ARGV[1]) from KEYS[1] and, if this
+// worker still held it, stores its new form (ARGV[2]) in the sorted set
+// KEYS[2] scored with ARGV[3], or in the list KEYS[2] trimmed to ARGV[3]
+// entries when ARGV[4] is "list"
+var releaseScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+  return 0
+end
+if ARGV[4] == 'list' then
+  redis.call('LPUSH', KEYS[2], ARGV[2])
+  redis.call('LTRIM', KEYS[2], 0, tonumber(ARGV[3]) - 1)
+else
+  redis.call('ZADD', KEYS[2], ARGV[3], ARGV[2])
+end
+return 1
+`)
+
+// moveDueScript moves up to ARGV[2] jobs scored at most ARGV[1] from the
+// sorted set KEYS[1] to the pending jobs KEYS[2]
+var moveDueScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, raw in ipairs(due) do
+  redis.call('ZREM', KEYS[1], raw)
+  redis.call('LPUSH', KEYS[2], raw)
+end
+return #due
+`)
+
+// redisBroker keeps a queue in four keys: a list of pending jobs, sorted sets
+// of claimed jobs by deadline and of scheduled jobs by due time, and a list of
+// dead jobs
+type redisBroker struct {
+	client    redis.UniversalClient
+	pending   string
+	claimed   string
+	scheduled string
+	dead      string
+}
+
+// NewRedisBroker creates a broker for the named queue
+func NewRedisBroker(client redis.UniversalClient, keys rediskey.Schema, queue string) Broker {
+	return &redisBroker{
+		client:    client,
+		pending:   keys.JobQueue(queue, "pending"),
+		claimed:   keys.JobQueue(queue, "claimed"),
+		scheduled: keys.JobQueue(queue, "scheduled"),
+		dead:      keys.JobQueue(queue, "dead"),
+	}
+}
+
+func (b *redisBroker) Push(ctx context.Context, job *Job) error {
+	raw, err := job.encode()
+	if err != nil {
+		return err
+	}
+	if err := b.client.LPush(ctx, b.pending, raw).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBroker) Pop(ctx context.Context, deadline time.Time) (*Job, error) {
+	raw, err := popScript.Run(ctx, b.client, []string{b.pending, b.claimed}, deadline.UnixMilli()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return decode(raw)
+}
+
+func (b *redisBroker) Ack(ctx context.Context, job *Job) error {
+	if err := b.client.ZRem(ctx, b.claimed, job.raw).Err(); err != nil {
+		return fmt.Errorf("failed to ack job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBroker) Schedule(ctx context.Context, job *Job, at time.Time) error {
+	raw, err := job.encode()
+	if err != nil {
+		return err
+	}
+	err = releaseScript.Run(ctx, b.client, []string{b.claimed, b.scheduled}, job.raw, raw, at.UnixMilli(), "zset").Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBroker) Bury(ctx context.Context, job *Job) error {
+	raw, err := job.encode()
+	if err != nil {
+		return err
+	}
+	err = releaseScript.Run(ctx, b.client, []string{b.claimed, b.dead}, job.raw, raw, maxDeadJobs, "list").Err()
+	if err != nil {
+		return fmt.Errorf("failed to bury job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBroker) Requeue(ctx context.Context, now time.Time) (int, error) {
+	moved := 0
+	for _, from := range []string{b.scheduled, b.claimed} {
+		n, err := moveDueScript.Run(ctx, b.client, []string{from, b.pending}, now.UnixMilli(), requeueBatch).Int()
+		if err != nil {
+			return moved, fmt.Errorf("failed to requeue jobs: %w", err)
+		}
+		moved += n
+	}
+	return moved, nil
+}
@@ -0,0 +1,84 @@
+// Package jobs runs background work through a Redis-backed queue. Producers
+// enqueue jobs with a Queue; cmd/worker runs them with a Worker, calling the
+// Handler registered for each job type.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a unit of background work
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Attempt     int             `json:"attempt"` // Tries made so far, including a running one
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+
+	raw string // Encoded form the broker stores the claimed job under
+}
+
+// Decode unmarshals the payload of the job into v
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s payload: %w", j.Type, err))
+	}
+	return nil
+}
+
+// LastAttempt reports whether a failure of the running attempt is final
+func (j *Job) LastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
+
+// encode returns the JSON form of the job
+func (j *Job) encode() (string, error) {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job: %w", err)
+	}
+	return string(data), nil
+}
+
+// decode parses a job stored by a broker
+func decode(raw string) (*Job, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	job.raw = raw
+	return &job, nil
+}
+
+// Handler runs jobs of one type. Returning an error retries the job with
+// backoff until its attempts run out; errors wrapped with Permanent are not
+// retried. Handlers must be idempotent: a job whose worker stops part way is
+// run again by another worker.
+type Handler func(ctx context.Context, job *Job) error
+
+// permanentError marks a job failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure that retrying cannot fix
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
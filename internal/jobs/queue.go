@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// Queue enqueues jobs for the workers
+type Queue struct {
+	broker      Broker
+	ids         idgen.Generator
+	maxAttempts int
+	now         func() time.Time
+}
+
+// NewQueue creates a queue pushing to broker jobs that are tried up to
+// maxAttempts times
+func NewQueue(broker Broker, ids idgen.Generator, maxAttempts int) *Queue {
+	return &Queue{broker: broker, ids: ids, maxAttempts: maxAttempts, now: time.Now}
+}
+
+// Enqueue adds a job of the given type with payload encoded as JSON; a nil
+// payload leaves it empty
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any) (*Job, error) {
+	id, err := q.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate job id: %w", err)
+	}
+
+	job := &Job{
+		ID:          id,
+		Type:        jobType,
+		MaxAttempts: q.maxAttempts,
+		EnqueuedAt:  q.now(),
+	}
+	if payload != nil {
+		if job.Payload, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+		}
+	}
+
+	if err := q.broker.Push(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
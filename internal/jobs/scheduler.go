@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// Claimer elects the instance that enqueues a scheduled job, so running
+// several schedulers does not multiply the jobs
+type Claimer interface {
+	// Claim reports whether the caller won the run of jobType; no other
+	// claim for it succeeds until ttl has passed
+	Claim(ctx context.Context, jobType string, ttl time.Duration) (bool, error)
+}
+
+// redisClaimer claims runs with SET NX on a key that expires after the interval
+type redisClaimer struct {
+	client redis.UniversalClient
+	keys   rediskey.Schema
+}
+
+// NewRedisClaimer creates a Claimer shared by every scheduler using the same Redis
+func NewRedisClaimer(client redis.UniversalClient, keys rediskey.Schema) Claimer {
+	return &redisClaimer{client: client, keys: keys}
+}
+
+func (c *redisClaimer) Claim(ctx context.Context, jobType string, ttl time.Duration) (bool, error) {
+	claimed, err := c.client.SetNX(ctx, c.keys.JobSchedule(jobType), time.Now().UTC().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim scheduled job: %w", err)
+	}
+	return claimed, nil
+}
+
+// schedule is a job enqueued every interval
+type schedule struct {
+	jobType  string
+	interval time.Duration
+}
+
+// Scheduler enqueues jobs at fixed intervals, like a cron table of the
+// housekeeping jobs. Each job is enqueued right away and then every interval
+// by whichever scheduler claims it first.
+type Scheduler struct {
+	queue     *Queue
+	claimer   Claimer
+	schedules []schedule
+	logger    *zap.Logger
+}
+
+// NewScheduler creates a scheduler enqueueing to queue
+func NewScheduler(queue *Queue, claimer Claimer, logger *zap.Logger) *Scheduler {
+	return &Scheduler{queue: queue, claimer: claimer, logger: logger}
+}
+
+// Every enqueues a job of jobType, without payload, every interval. A
+// non-positive interval leaves the job unscheduled. It must be called before Run.
+func (s *Scheduler) Every(jobType string, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.schedules = append(s.schedules, schedule{jobType: jobType, interval: interval})
+}
+
+// Scheduled returns the scheduled job types with their intervals
+func (s *Scheduler) Scheduled() map[string]time.Duration {
+	scheduled := make(map[string]time.Duration, len(s.schedules))
+	for _, sc := range s.schedules {
+		scheduled[sc.jobType] = sc.interval
+	}
+	return scheduled
+}
+
+// Run enqueues the scheduled jobs until ctx is done. Failures are logged and
+// retried at the next interval.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, sc := range s.schedules {
+		wg.Add(1)
+		go func(sc schedule) {
+			defer wg.Done()
+			s.run(ctx, sc)
+		}(sc)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context, sc schedule) {
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+
+	for {
+		s.enqueue(ctx, sc)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueue adds the job if this scheduler wins the current interval
+func (s *Scheduler) enqueue(ctx context.Context, sc schedule) {
+	fields := []zap.Field{zap.String("job_type", sc.jobType), zap.Duration("interval", sc.interval)}
+
+	// The claim lapses a little early so the winner's next tick is not refused
+	claimed, err := s.claimer.Claim(ctx, sc.jobType, sc.interval-sc.interval/10)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Failed to claim scheduled job", append(fields, zap.Error(err))...)
+		}
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	job, err := s.queue.Enqueue(ctx, sc.jobType, nil)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Failed to enqueue scheduled job", append(fields, zap.Error(err))...)
+		}
+		return
+	}
+	s.logger.Debug("Enqueued scheduled job", append(fields, zap.String("job_id", job.ID.String()))...)
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// memClaimer is an in-memory Claimer shared by the schedulers of a test
+type memClaimer struct {
+	mu      sync.Mutex
+	claimed map[string]time.Time // Expiry of the current claim of each job type
+}
+
+func newMemClaimer() *memClaimer {
+	return &memClaimer{claimed: make(map[string]time.Time)}
+}
+
+func (c *memClaimer) Claim(_ context.Context, jobType string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Before(c.claimed[jobType]) {
+		return false, nil
+	}
+	c.claimed[jobType] = now.Add(ttl)
+	return true, nil
+}
+
+func (b *memBroker) countPending(jobType string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, job := range b.pending {
+		if job.Type == jobType {
+			n++
+		}
+	}
+	return n
+}
+
+func TestScheduler(t *testing.T) {
+	ids := idgen.GeneratorFunc(uuid.NewRandom)
+
+	t.Run("Enqueues Every Interval", func(t *testing.T) {
+		broker := newMemBroker()
+		s := NewScheduler(NewQueue(broker, ids, 3), newMemClaimer(), zap.NewNop())
+		s.Every("cleanup", 10*time.Millisecond)
+		s.Every("disabled", 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+		defer cancel()
+		assert.NoError(t, s.Run(ctx))
+
+		assert.GreaterOrEqual(t, broker.countPending("cleanup"), 4)
+		assert.Zero(t, broker.countPending("disabled"))
+		assert.Equal(t, map[string]time.Duration{"cleanup": 10 * time.Millisecond}, s.Scheduled())
+	})
+
+	t.Run("One Scheduler Wins Each Interval", func(t *testing.T) {
+		broker := newMemBroker()
+		claimer := newMemClaimer()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			s := NewScheduler(NewQueue(broker, ids, 3), claimer, zap.NewNop())
+			s.Every("audit", time.Hour)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = s.Run(ctx)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, broker.countPending("audit"))
+	})
+}
@@ -0,0 +1,184 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// Worker runs the jobs of a queue with the handlers registered for their types
+type Worker struct {
+	broker      Broker
+	handlers    map[string]Handler
+	concurrency int
+	backoff     time.Duration
+	visibility  time.Duration
+	poll        time.Duration
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewWorker creates a worker with the concurrency, retry and polling settings in cfg
+func NewWorker(broker Broker, cfg config.JobsConfig, logger *zap.Logger) *Worker {
+	return &Worker{
+		broker:      broker,
+		handlers:    make(map[string]Handler),
+		concurrency: cfg.Workers(),
+		backoff:     cfg.Backoff(),
+		visibility:  cfg.VisibilityTimeout(),
+		poll:        cfg.PollInterval(),
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Register runs jobs of jobType with handler. It must be called before Run.
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Types returns the registered job types, sorted
+func (w *Worker) Types() []string {
+	types := make([]string, 0, len(w.handlers))
+	for t := range w.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Run claims and runs jobs until ctx is done, then waits for the running jobs
+// to finish. Running jobs are not cancelled by ctx; they are bounded by the
+// visibility timeout instead, after which another worker would take them.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.requeue(ctx)
+	}()
+
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.consume(ctx)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// requeue moves due scheduled jobs and jobs of stopped workers back to the
+// pending jobs every poll interval
+func (w *Worker) requeue(ctx context.Context) {
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+
+	for {
+		moved, err := w.broker.Requeue(ctx, w.now())
+		if err != nil && ctx.Err() == nil {
+			w.logger.Warn("Failed to requeue jobs", zap.Error(err))
+		}
+		if moved > 0 {
+			w.logger.Debug("Requeued jobs", zap.Int("jobs", moved))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// consume claims jobs one at a time, waiting a poll interval whenever the
+// queue is empty or unreachable
+func (w *Worker) consume(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.broker.Pop(ctx, w.now().Add(w.visibility))
+		if err != nil && ctx.Err() == nil {
+			w.logger.Warn("Failed to claim job", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.poll):
+			}
+			continue
+		}
+
+		w.process(context.WithoutCancel(ctx), job)
+	}
+}
+
+// process runs a claimed job and acks, reschedules or buries it depending
+// on the outcome
+func (w *Worker) process(ctx context.Context, job *Job) {
+	job.Attempt++
+	fields := []zap.Field{
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_type", job.Type),
+		zap.Int("attempt", job.Attempt),
+	}
+
+	started := w.now()
+	err := w.run(ctx, job)
+	fields = append(fields, zap.Duration("duration", w.now().Sub(started)))
+
+	if err == nil {
+		if err := w.broker.Ack(ctx, job); err != nil {
+			w.logger.Error("Failed to ack job", append(fields, zap.Error(err))...)
+			return
+		}
+		w.logger.Info("Job completed", fields...)
+		return
+	}
+
+	job.LastError = err.Error()
+	if IsPermanent(err) || job.LastAttempt() {
+		if err := w.broker.Bury(ctx, job); err != nil {
+			w.logger.Error("Failed to bury job", append(fields, zap.Error(err))...)
+		}
+		w.logger.Error("Job failed", append(fields, zap.Error(err))...)
+		return
+	}
+
+	at := w.now().Add(w.backoff << (job.Attempt - 1))
+	if err := w.broker.Schedule(ctx, job, at); err != nil {
+		w.logger.Error("Failed to schedule job retry", append(fields, zap.Error(err))...)
+	}
+	w.logger.Warn("Job failed, retrying", append(fields, zap.Time("retry_at", at), zap.Error(err))...)
+}
+
+// run calls the handler of the job within the visibility timeout, turning
+// panics into errors so one bad job cannot stop the worker
+func (w *Worker) run(ctx context.Context, job *Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.visibility)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+
+	err = handler(ctx, job)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+		return fmt.Errorf("job exceeded the visibility timeout of %s: %w", w.visibility, err)
+	}
+	return err
+}
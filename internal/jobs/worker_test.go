@@ -0,0 +1,305 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// memBroker is an in-memory Broker; claimed jobs are tracked by ID
+type memBroker struct {
+	mu        sync.Mutex
+	pending   []*Job
+	claimed   map[uuid.UUID]time.Time
+	scheduled map[uuid.UUID]*Job
+	dueAt     map[uuid.UUID]time.Time
+	dead      []*Job
+	acked     []*Job
+	changed   chan struct{}
+}
+
+func newMemBroker() *memBroker {
+	return &memBroker{
+		claimed:   make(map[uuid.UUID]time.Time),
+		scheduled: make(map[uuid.UUID]*Job),
+		dueAt:     make(map[uuid.UUID]time.Time),
+		changed:   make(chan struct{}, 100),
+	}
+}
+
+func (b *memBroker) Push(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	copied := *job
+	b.pending = append(b.pending, &copied)
+	return nil
+}
+
+func (b *memBroker) Pop(_ context.Context, deadline time.Time) (*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.pending) == 0 {
+		return nil, nil
+	}
+	job := b.pending[0]
+	b.pending = b.pending[1:]
+	b.claimed[job.ID] = deadline
+	copied := *job
+	return &copied, nil
+}
+
+func (b *memBroker) release(job *Job) {
+	delete(b.claimed, job.ID)
+	b.changed <- struct{}{}
+}
+
+func (b *memBroker) Ack(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acked = append(b.acked, job)
+	b.release(job)
+	return nil
+}
+
+func (b *memBroker) Schedule(_ context.Context, job *Job, at time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.scheduled[job.ID] = job
+	b.dueAt[job.ID] = at
+	b.release(job)
+	return nil
+}
+
+func (b *memBroker) Bury(_ context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dead = append(b.dead, job)
+	b.release(job)
+	return nil
+}
+
+func (b *memBroker) Requeue(_ context.Context, now time.Time) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	moved := 0
+	for id, job := range b.scheduled {
+		if !b.dueAt[id].After(now) {
+			b.pending = append(b.pending, job)
+			delete(b.scheduled, id)
+			delete(b.dueAt, id)
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// waitFor waits until the broker has released n jobs
+func (b *memBroker) waitFor(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-b.changed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for jobs")
+		}
+	}
+}
+
+func testWorker(broker Broker) *Worker {
+	return NewWorker(broker, config.JobsConfig{Concurrency: 2, RetryBackoffMs: 1, PollIntervalMs: 1}, zap.NewNop())
+}
+
+func startWorker(t *testing.T, w *Worker) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = w.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestQueueEnqueue(t *testing.T) {
+	broker := newMemBroker()
+	q := NewQueue(broker, idgen.GeneratorFunc(uuid.NewRandom), 3)
+
+	job, err := q.Enqueue(context.Background(), "greet", map[string]string{"name": "Ada"})
+
+	require.NoError(t, err)
+	require.Len(t, broker.pending, 1)
+	assert.Equal(t, job.ID, broker.pending[0].ID)
+	assert.Equal(t, "greet", job.Type)
+	assert.Equal(t, 3, job.MaxAttempts)
+	assert.JSONEq(t, `{"name":"Ada"}`, string(job.Payload))
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+	ids := idgen.GeneratorFunc(uuid.NewRandom)
+
+	t.Run("Runs Registered Handler", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		var got string
+		w.Register("greet", func(_ context.Context, job *Job) error {
+			var payload struct{ Name string }
+			if err := job.Decode(&payload); err != nil {
+				return err
+			}
+			got = payload.Name
+			return nil
+		})
+		_, err := NewQueue(broker, ids, 3).Enqueue(ctx, "greet", map[string]string{"name": "Ada"})
+		require.NoError(t, err)
+
+		startWorker(t, w)
+		broker.waitFor(t, 1)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		assert.Equal(t, "Ada", got)
+		require.Len(t, broker.acked, 1)
+		assert.Equal(t, 1, broker.acked[0].Attempt)
+		assert.Empty(t, broker.claimed)
+	})
+
+	t.Run("Retries Until Success", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		calls := 0
+		w.Register("flaky", func(context.Context, *Job) error {
+			calls++
+			if calls < 3 {
+				return errors.New("temporarily unavailable")
+			}
+			return nil
+		})
+		_, err := NewQueue(broker, ids, 5).Enqueue(ctx, "flaky", nil)
+		require.NoError(t, err)
+
+		startWorker(t, w)
+		broker.waitFor(t, 3)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		require.Len(t, broker.acked, 1)
+		assert.Equal(t, 3, broker.acked[0].Attempt)
+		assert.Equal(t, "temporarily unavailable", broker.acked[0].LastError)
+		assert.Empty(t, broker.dead)
+	})
+
+	t.Run("Buries After Last Attempt", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		w.Register("broken", func(context.Context, *Job) error { return errors.New("boom") })
+		_, err := NewQueue(broker, ids, 2).Enqueue(ctx, "broken", nil)
+		require.NoError(t, err)
+
+		startWorker(t, w)
+		broker.waitFor(t, 2)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		require.Len(t, broker.dead, 1)
+		assert.Equal(t, 2, broker.dead[0].Attempt)
+		assert.Equal(t, "boom", broker.dead[0].LastError)
+	})
+
+	t.Run("Permanent Error Is Not Retried", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		w.Register("greet", func(_ context.Context, job *Job) error {
+			var payload struct{ Name string }
+			return job.Decode(&payload)
+		})
+		require.NoError(t, broker.Push(ctx, &Job{ID: uuid.New(), Type: "greet", Payload: []byte(`"not an object"`), MaxAttempts: 5}))
+
+		startWorker(t, w)
+		broker.waitFor(t, 1)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		require.Len(t, broker.dead, 1)
+		assert.Equal(t, 1, broker.dead[0].Attempt)
+		assert.Contains(t, broker.dead[0].LastError, "invalid greet payload")
+	})
+
+	t.Run("Unknown Type Is Buried", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		_, err := NewQueue(broker, ids, 5).Enqueue(ctx, "unknown", nil)
+		require.NoError(t, err)
+
+		startWorker(t, w)
+		broker.waitFor(t, 1)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		require.Len(t, broker.dead, 1)
+		assert.Contains(t, broker.dead[0].LastError, `no handler registered for job type "unknown"`)
+	})
+
+	t.Run("Panic Is Retried", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		w.Register("panics", func(context.Context, *Job) error { panic("nil map") })
+		_, err := NewQueue(broker, ids, 1).Enqueue(ctx, "panics", nil)
+		require.NoError(t, err)
+
+		startWorker(t, w)
+		broker.waitFor(t, 1)
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		require.Len(t, broker.dead, 1)
+		assert.Equal(t, "job handler panicked: nil map", broker.dead[0].LastError)
+	})
+
+	t.Run("Shutdown Waits For Running Jobs", func(t *testing.T) {
+		broker := newMemBroker()
+		w := testWorker(broker)
+		started := make(chan struct{})
+		w.Register("slow", func(ctx context.Context, _ *Job) error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return ctx.Err()
+		})
+		_, err := NewQueue(broker, ids, 1).Enqueue(ctx, "slow", nil)
+		require.NoError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			_ = w.Run(runCtx)
+			close(done)
+		}()
+		<-started
+		cancel()
+		<-done
+
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		assert.Len(t, broker.acked, 1)
+	})
+}
+
+func TestWorkerTypes(t *testing.T) {
+	w := testWorker(newMemBroker())
+	w.Register("b", func(context.Context, *Job) error { return nil })
+	w.Register("a", func(context.Context, *Job) error { return nil })
+
+	assert.Equal(t, []string{"a", "b"}, w.Types())
+}
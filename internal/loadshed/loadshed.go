@@ -0,0 +1,70 @@
+// Package loadshed caps the requests served at once. Once a Limiter is
+// saturated further requests are rejected straight away with 503 and a
+// Retry-After hint instead of queueing on the database connection pool,
+// so a burst degrades into fast retries rather than timeouts for everyone.
+package loadshed
+
+import (
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// RetryAfter is how long shed clients are asked to wait; in-flight requests
+// finish within a few hundred milliseconds, so capacity frees up quickly
+const RetryAfter = time.Second
+
+// ErrOverloaded is returned for requests shed because the limit is reached
+var ErrOverloaded = apperror.New(apperror.CodeServiceUnavailable, "The service is busy. Please try again shortly.")
+
+// Limiter admits at most a fixed number of requests at a time. A nil
+// Limiter admits every request, so an unset limit needs no special case.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter creates a Limiter admitting max requests at a time, or nil,
+// admitting every request, when max is not positive
+func NewLimiter(max int) *Limiter {
+	if max <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire takes a slot without waiting and reports whether one was free.
+// Every successful Acquire must be followed by a Release.
+func (l *Limiter) Acquire() bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *Limiter) Release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// InFlight returns the number of requests holding a slot
+func (l *Limiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.slots)
+}
+
+// Max returns the number of requests admitted at a time; 0 means unlimited
+func (l *Limiter) Max() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
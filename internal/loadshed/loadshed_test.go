@@ -0,0 +1,32 @@
+package loadshed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2)
+
+	assert.True(t, l.Acquire())
+	assert.True(t, l.Acquire())
+	assert.False(t, l.Acquire())
+	assert.Equal(t, 2, l.InFlight())
+
+	l.Release()
+	assert.True(t, l.Acquire())
+	assert.Equal(t, 2, l.Max())
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := NewLimiter(0)
+
+	assert.Nil(t, l)
+	for range 1000 {
+		assert.True(t, l.Acquire())
+	}
+	l.Release()
+	assert.Equal(t, 0, l.InFlight())
+	assert.Equal(t, 0, l.Max())
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"maps"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// Modules whose level can be set apart from the application logs. A log
+// entry belongs to the module its logger is named after, e.g. the
+// zap.Logger.Named("gorm") loggers; entries of other loggers belong to App.
+const (
+	App  = "app"
+	HTTP = "http" // HTTP access logs
+	GRPC = "grpc"
+	GORM = "gorm"
+)
+
+// Modules lists the modules in the order they are reported
+var Modules = []string{App, HTTP, GRPC, GORM}
+
+// Errors for rejected level changes
+var (
+	ErrUnknownModule = apperror.New(apperror.CodeInvalidArgument, "module must be one of app, http, grpc or gorm")
+	ErrInvalidLevel  = apperror.New(apperror.CodeInvalidArgument, "level must be one of debug, info, warn or error")
+)
+
+// Levels holds the log level of each module. Levels can be changed while
+// the loggers are in use; changes only affect this process.
+type Levels struct {
+	levels map[string]zap.AtomicLevel
+}
+
+// NewLevels creates levels logging app at level and the other modules at
+// their level in modules, or at level when they have none
+func NewLevels(level zapcore.Level, modules map[string]zapcore.Level) *Levels {
+	l := &Levels{levels: make(map[string]zap.AtomicLevel, len(Modules))}
+	for _, module := range Modules {
+		l.levels[module] = zap.NewAtomicLevelAt(level)
+	}
+	l.Apply(level, modules)
+	return l
+}
+
+// Apply sets app to level and the other modules to their level in modules,
+// or to level when they have none, e.g. when the configuration is reloaded
+func (l *Levels) Apply(level zapcore.Level, modules map[string]zapcore.Level) {
+	for module, atomic := range l.levels {
+		if moduleLevel, ok := modules[module]; ok && module != App {
+			atomic.SetLevel(moduleLevel)
+		} else {
+			atomic.SetLevel(level)
+		}
+	}
+}
+
+// Set changes the level of module
+func (l *Levels) Set(module, level string) error {
+	atomic, ok := l.levels[module]
+	if !ok {
+		return ErrUnknownModule
+	}
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil || parsed > zapcore.ErrorLevel {
+		return ErrInvalidLevel
+	}
+	atomic.SetLevel(parsed)
+	return nil
+}
+
+// Levels returns the current level of each module
+func (l *Levels) Levels() map[string]string {
+	levels := make(map[string]string, len(l.levels))
+	for module, atomic := range l.levels {
+		levels[module] = atomic.String()
+	}
+	return levels
+}
+
+// Core wraps core, which must accept every level, to filter each entry by
+// the level of its module
+func (l *Levels) Core(core zapcore.Core) zapcore.Core {
+	return &levelCore{Core: core, levels: maps.Clone(l.levels)}
+}
+
+// levelCore filters entries by the level of the module of their logger
+type levelCore struct {
+	zapcore.Core
+	levels map[string]zap.AtomicLevel
+}
+
+// Enabled reports whether any module logs at level; Check decides per entry
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	for _, atomic := range c.levels {
+		if atomic.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levelOf(entry.LoggerName).Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// levelOf returns the level of the module a logger name belongs to
+func (c *levelCore) levelOf(loggerName string) zap.AtomicLevel {
+	module, _, _ := strings.Cut(loggerName, ".")
+	if atomic, ok := c.levels[module]; ok {
+		return atomic
+	}
+	return c.levels[App]
+}
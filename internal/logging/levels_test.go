@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevels_Core(t *testing.T) {
+	levels := NewLevels(zapcore.InfoLevel, map[string]zapcore.Level{GORM: zapcore.DebugLevel, HTTP: zapcore.WarnLevel})
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(levels.Core(core))
+
+	logger.Debug("app debug")
+	logger.Info("app info")
+	logger.Named("gorm").Debug("gorm debug")
+	logger.Named("http").Info("http info")
+	logger.Named("http").Warn("http warn")
+	logger.Named("grpc").With(zap.String("method", "/user.v1.UserService/GetUser")).Named("auth").Debug("grpc debug")
+	logger.Named("worker").Info("worker info") // Not a module, logged as app
+
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Equal(t, []string{"app info", "gorm debug", "http warn", "worker info"}, messages)
+
+	// Changes apply to loggers created before them
+	assert.NoError(t, levels.Set(GRPC, "debug"))
+	logger.Named("grpc").Debug("grpc debug")
+	assert.Equal(t, 5, logs.Len())
+}
+
+func TestLevels_Set(t *testing.T) {
+	levels := NewLevels(zapcore.InfoLevel, nil)
+
+	assert.NoError(t, levels.Set(GORM, "warn"))
+	assert.ErrorIs(t, levels.Set("redis", "debug"), ErrUnknownModule)
+	assert.ErrorIs(t, levels.Set(App, "verbose"), ErrInvalidLevel)
+	assert.ErrorIs(t, levels.Set(App, "fatal"), ErrInvalidLevel)
+	assert.Equal(t, map[string]string{App: "info", HTTP: "info", GRPC: "info", GORM: "warn"}, levels.Levels())
+
+	// Applying the configuration resets the levels changed since
+	levels.Apply(zapcore.ErrorLevel, map[string]zapcore.Level{HTTP: zapcore.DebugLevel})
+	assert.Equal(t, map[string]string{App: "error", HTTP: "debug", GRPC: "error", GORM: "error"}, levels.Levels())
+}
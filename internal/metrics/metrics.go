@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewRegistry creates a Prometheus registry with the Go runtime and process collectors
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// NewHandler returns the HTTP handler serving the metrics of registry
+func NewHandler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Server exposes /metrics on an internal listener, separate from the public
+// API so scrape data is never served to API clients
+type Server struct {
+	server *http.Server
+}
+
+// NewServer creates a metrics server listening on addr (e.g. ":9090")
+func NewServer(addr string, registry *prometheus.Registry) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", NewHandler(registry))
+	return &Server{
+		// Created up front so Shutdown is safe even if Serve has not started yet
+		server: &http.Server{Addr: addr, Handler: mux},
+	}
+}
+
+// Serve runs the metrics server until it fails or is shut down. A graceful
+// Shutdown makes Serve return nil.
+func (s *Server) Serve() error {
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server error: %w", err)
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the metrics server
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Handler returns the HTTP handler serving /metrics
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerHandler(t *testing.T) {
+	registry := NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_events_total", Help: "Events seen by the test."})
+	registry.MustRegister(counter)
+	counter.Inc()
+
+	handler := NewServer(":0", registry).Handler()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "test_events_total 1")
+	assert.Contains(t, rr.Body.String(), "go_goroutines")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
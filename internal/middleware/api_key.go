@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader carries the secret of an organization API key
+const APIKeyHeader = "X-API-Key"
+
+// Errors returned by APIKeyMiddleware
+var (
+	ErrAPIKeyRequired       = apperror.New(apperror.CodeUnauthenticated, "X-API-Key header is required")
+	ErrInvalidAPIKey        = apperror.New(apperror.CodeInvalidAPIKey, "API key is invalid, expired or revoked")
+	ErrInsufficientKeyScope = apperror.New(apperror.CodePermissionDenied, "API key does not grant the required scope")
+)
+
+// APIKeyAuthenticator resolves the secret of an API key.
+// serviceAPIKey.Service satisfies it.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*apikey.APIKey, error)
+}
+
+// APIKeyMiddleware authenticates requests with an organization API key that
+// grants scope. It sets "api_key_id" in the context and the key's tenant in
+// the request context; handlers must only serve data of that tenant.
+func APIKeyMiddleware(keys APIKeyAuthenticator, logger *zap.Logger, scope apikey.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := c.GetHeader(APIKeyHeader)
+		if secret == "" {
+			response.AppError(c, ErrAPIKeyRequired)
+			c.Abort()
+			return
+		}
+
+		key, err := keys.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			if apperror.CodeOf(err) != apperror.CodeInvalidAPIKey {
+				logger.Error("Failed to authenticate API key", zap.Error(err))
+				_ = c.Error(err)
+				response.InternalServerError(c, "Something went wrong. Please try again later.")
+				c.Abort()
+				return
+			}
+			response.AppError(c, ErrInvalidAPIKey)
+			c.Abort()
+			return
+		}
+
+		if !key.HasScope(scope) {
+			logger.Warn("API key scope check failed",
+				zap.String("api_key_id", key.ID.String()),
+				zap.String("scope", string(scope)),
+				zap.String("path", c.FullPath()))
+			response.AppError(c, ErrInsufficientKeyScope)
+			c.Abort()
+			return
+		}
+
+		c.Set("api_key_id", key.ID)
+		requestctx.SetTenant(c, key.Tenant)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// stubAPIKeys returns a fixed key or error
+type stubAPIKeys struct {
+	key *apikey.APIKey
+	err error
+}
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, secret string) (*apikey.APIKey, error) {
+	return s.key, s.err
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := &apikey.APIKey{ID: uuid.New(), Tenant: "acme", Scopes: []apikey.Scope{apikey.ScopeUsersRead}}
+
+	tests := []struct {
+		name         string
+		header       string
+		keys         stubAPIKeys
+		scope        apikey.Scope
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Success",
+			header:       "usk_valid",
+			keys:         stubAPIKeys{key: key},
+			scope:        apikey.ScopeUsersRead,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"tenant":"acme"}`,
+		},
+		{
+			name:         "Missing Header",
+			keys:         stubAPIKeys{key: key},
+			scope:        apikey.ScopeUsersRead,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"X-API-Key header is required","errorCode":"UNAUTHENTICATED"}`,
+		},
+		{
+			name:         "Invalid Key",
+			header:       "usk_revoked",
+			keys:         stubAPIKeys{err: apperror.New(apperror.CodeInvalidAPIKey, "revoked")},
+			scope:        apikey.ScopeUsersRead,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"API key is invalid, expired or revoked","errorCode":"INVALID_API_KEY"}`,
+		},
+		{
+			name:         "Missing Scope",
+			header:       "usk_valid",
+			keys:         stubAPIKeys{key: key},
+			scope:        apikey.Scope("users:write"),
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"code":403,"message":"API key does not grant the required scope","errorCode":"PERMISSION_DENIED"}`,
+		},
+		{
+			name:         "Lookup Error",
+			header:       "usk_valid",
+			keys:         stubAPIKeys{err: errors.New("database down")},
+			scope:        apikey.ScopeUsersRead,
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/org", APIKeyMiddleware(tt.keys, zap.NewNop(), tt.scope), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"tenant": requestctx.Tenant(c.Request.Context())})
+			})
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/org", nil)
+			if tt.header != "" {
+				req.Header.Set(APIKeyHeader, tt.header)
+			}
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
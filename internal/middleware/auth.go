@@ -1,21 +1,30 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
 	"github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
 	"go.uber.org/zap"
 )
 
+// Errors returned by AuthMiddleware
+var (
+	ErrAuthorizationHeaderRequired = apperror.New(apperror.CodeUnauthenticated, "Authorization header is required")
+	ErrInvalidAuthorizationHeader  = apperror.New(apperror.CodeUnauthenticated, "Authorization header format must be Bearer {token}")
+	ErrInvalidAccessToken          = apperror.New(apperror.CodeInvalidToken, "Invalid or expired token")
+)
+
 // AuthMiddleware creates a Gin middleware for authentication
 func AuthMiddleware(authService auth.AuthService, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			response.AppError(c, ErrAuthorizationHeaderRequired)
 			c.Abort()
 			return
 		}
@@ -23,7 +32,7 @@ func AuthMiddleware(authService auth.AuthService, logger *zap.Logger) gin.Handle
 		// Check if the header has the Bearer prefix
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			response.AppError(c, ErrInvalidAuthorizationHeader)
 			c.Abort()
 			return
 		}
@@ -31,17 +40,52 @@ func AuthMiddleware(authService auth.AuthService, logger *zap.Logger) gin.Handle
 		// Extract the token
 		tokenString := parts[1]
 
-		// Validate the token
-		userID, err := authService.ValidateToken(c.Request.Context(), tokenString)
+		// Validate the token and reject deactivated or reset-pending accounts
+		principal, err := authService.AuthenticatePrincipal(c.Request.Context(), tokenString)
 		if err != nil {
-			logger.Warn("Invalid token", zap.Error(err))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			logger.Warn("Authentication failed", zap.Error(err))
+			// Errors carry specific codes such as TOKEN_EXPIRED or
+			// PASSWORD_RESET_REQUIRED so clients know how to recover
+			appErr, ok := apperror.As(err)
+			if !ok {
+				appErr = ErrInvalidAccessToken
+			}
+			response.AppError(c, appErr)
 			c.Abort()
 			return
 		}
 
 		// Set the user ID in the context for handlers to use
-		c.Set("user_id", userID)
+		setPrincipal(c, principal)
+
+		c.Next()
+	}
+}
+
+// setPrincipal stores the authenticated user in the context, along with the
+// administrator acting as them when the token is an impersonation token
+func setPrincipal(c *gin.Context, principal auth.Principal) {
+	requestctx.SetUserID(c, principal.UserID)
+	if principal.Impersonated() {
+		c.Set(ImpersonatorIDKey, principal.ActorID)
+		c.Set(ImpersonationIDKey, principal.ImpersonationID)
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid bearer token is
+// present and lets every request through. Handlers serve anonymous content
+// when no user ID is set.
+func OptionalAuthMiddleware(authService auth.AuthService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			principal, err := authService.AuthenticatePrincipal(c.Request.Context(), parts[1])
+			if err != nil {
+				logger.Debug("Ignoring rejected token on optional auth route", zap.Error(err))
+			} else {
+				setPrincipal(c, principal)
+			}
+		}
 
 		c.Next()
 	}
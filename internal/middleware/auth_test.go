@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// stubAuthService validates tokens with a fixed result
+type stubAuthService struct {
+	auth.AuthService
+	userID uuid.UUID
+	actor  uuid.UUID // Set for impersonation tokens
+	err    error
+}
+
+func (s stubAuthService) AuthenticatePrincipal(ctx context.Context, accessToken string) (auth.Principal, error) {
+	if s.err != nil {
+		return auth.Principal{}, s.err
+	}
+	principal := auth.Principal{UserID: s.userID}
+	if s.actor != uuid.Nil {
+		principal.ActorID = s.actor
+		principal.ImpersonationID = uuid.New()
+	}
+	return principal, nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		header       string
+		authService  stubAuthService
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Valid Token",
+			header:       "Bearer valid",
+			authService:  stubAuthService{userID: userID},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"user_id":"` + userID.String() + `"}`,
+		},
+		{
+			name:         "Missing Header",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"Authorization header is required","errorCode":"UNAUTHENTICATED"}`,
+		},
+		{
+			name:         "Malformed Header",
+			header:       "Token abc",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"Authorization header format must be Bearer {token}","errorCode":"UNAUTHENTICATED"}`,
+		},
+		{
+			name:         "Expired Token",
+			header:       "Bearer expired",
+			authService:  stubAuthService{err: apperror.New(apperror.CodeTokenExpired, "token is expired")},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"token is expired","errorCode":"TOKEN_EXPIRED"}`,
+		},
+		{
+			name:         "Password Reset Required",
+			header:       "Bearer flagged",
+			authService:  stubAuthService{err: apperror.New(apperror.CodePasswordResetRequired, "password reset required")},
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"code":403,"message":"password reset required","errorCode":"PASSWORD_RESET_REQUIRED"}`,
+		},
+		{
+			name:         "Unexpected Error",
+			header:       "Bearer broken",
+			authService:  stubAuthService{err: errors.New("boom")},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"code":401,"message":"Invalid or expired token","errorCode":"INVALID_TOKEN"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/protected", AuthMiddleware(tt.authService, zap.NewNop()), func(c *gin.Context) {
+				userID, _ := requestctx.UserID(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"user_id": userID})
+			})
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
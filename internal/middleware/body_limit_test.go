@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(16, "/import"))
+	read := func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+	router.POST("/users", read)
+	router.POST("/import", read)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{name: "Within Limit", path: "/users", body: `{"a":1}`, expectedStatus: http.StatusNoContent},
+		{name: "Declared Too Large", path: "/users", body: strings.Repeat("a", 17), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Chunked Too Large", path: "/users", body: strings.Repeat("a", 17), chunked: true, expectedStatus: http.StatusBadRequest},
+		{name: "Exempt", path: "/import", body: strings.Repeat("a", 17), expectedStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
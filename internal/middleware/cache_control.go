@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy sets the caching headers of successful responses
+type CachePolicy struct {
+	CacheControl string   // Cache-Control value, e.g. "no-store" or "private, max-age=30"
+	Vary         []string // Request headers the response depends on, e.g. Authorization
+}
+
+// CacheControlMiddleware applies policy to the responses of the routes it is
+// attached to. Error responses are sent with no-store so a cache never keeps
+// them, and responses whose handler set a Cache-Control of its own keep it.
+// An empty policy leaves the headers alone.
+func CacheControlMiddleware(policy CachePolicy) gin.HandlerFunc {
+	if policy.CacheControl == "" {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		writer := &cacheHeaderWriter{ResponseWriter: c.Writer, policy: policy}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+		c.Next()
+		// Gin writes the headers of responses without a body itself, past this writer
+		writer.apply()
+	}
+}
+
+// cacheHeaderWriter sets the caching headers once the status is known, just
+// before the headers are written
+type cacheHeaderWriter struct {
+	gin.ResponseWriter
+	policy  CachePolicy
+	applied bool
+}
+
+func (w *cacheHeaderWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+
+	header := w.Header()
+	if header.Get("Cache-Control") != "" {
+		return
+	}
+	status := w.Status()
+	if status >= http.StatusBadRequest || (status >= http.StatusMultipleChoices && status != http.StatusNotModified) {
+		header.Set("Cache-Control", "no-store")
+		header.Set("Expires", "0")
+		return
+	}
+
+	header.Set("Cache-Control", w.policy.CacheControl)
+	if expires := expiresFor(w.policy.CacheControl, time.Now()); expires != "" {
+		header.Set("Expires", expires)
+	}
+	for _, name := range w.policy.Vary {
+		if !slices.Contains(header.Values("Vary"), name) {
+			header.Add("Vary", name)
+		}
+	}
+}
+
+func (w *cacheHeaderWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheHeaderWriter) Write(p []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *cacheHeaderWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheHeaderWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
+
+// expiresFor returns the Expires header matching a Cache-Control value, for
+// HTTP/1.0 caches that ignore Cache-Control: already expired when the
+// response must not be reused or is private to one client, and max-age
+// seconds from now otherwise. It returns "" when cacheControl sets no age.
+func expiresFor(cacheControl string, now time.Time) string {
+	var maxAge string
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache", "private":
+			return "0"
+		case "max-age":
+			maxAge = value
+		}
+	}
+	seconds, err := strconv.Atoi(maxAge)
+	if err != nil {
+		return ""
+	}
+	return now.Add(time.Duration(seconds) * time.Second).UTC().Format(http.TimeFormat)
+}
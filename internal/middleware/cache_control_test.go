@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControlMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CacheControlMiddleware(CachePolicy{CacheControl: "private, max-age=30", Vary: []string{"Authorization"}}))
+	router.GET("/profile", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"name": "Ada"}) })
+	router.GET("/unchanged", func(c *gin.Context) { c.Status(http.StatusNotModified) })
+	router.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"message": "not found"}) })
+	router.GET("/unauthenticated", func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+	router.GET("/own", func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.String(http.StatusOK, "event")
+	})
+
+	tests := []struct {
+		name                 string
+		path                 string
+		expectedCacheControl string
+		expectedExpires      string
+		expectedVary         string
+	}{
+		{name: "Success", path: "/profile", expectedCacheControl: "private, max-age=30", expectedExpires: "0", expectedVary: "Authorization"},
+		{name: "Not Modified", path: "/unchanged", expectedCacheControl: "private, max-age=30", expectedExpires: "0", expectedVary: "Authorization"},
+		{name: "Error", path: "/missing", expectedCacheControl: "no-store", expectedExpires: "0"},
+		{name: "Error Without Body", path: "/unauthenticated", expectedCacheControl: "no-store", expectedExpires: "0"},
+		{name: "Set By Handler", path: "/own", expectedCacheControl: "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedCacheControl, w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.expectedExpires, w.Header().Get("Expires"))
+			assert.Equal(t, tt.expectedVary, w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestCacheControlMiddleware_EmptyPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CacheControlMiddleware(CachePolicy{}))
+	router.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"message": "not found"}) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestExpiresFor(t *testing.T) {
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cacheControl string
+		expected     string
+	}{
+		{cacheControl: "no-store", expected: "0"},
+		{cacheControl: "no-cache", expected: "0"},
+		{cacheControl: "private, max-age=30", expected: "0"},
+		{cacheControl: "public, max-age=60", expected: "Fri, 16 Oct 2026 08:01:00 GMT"},
+		{cacheControl: "Max-Age=5", expected: "Fri, 16 Oct 2026 08:00:05 GMT"},
+		{cacheControl: "public", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cacheControl, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expiresFor(tt.cacheControl, now))
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+)
+
+// ClientIPMiddleware resolves the client address of every request behind
+// the trusted proxies of resolver and carries it in the request context.
+// Handlers and services read it with clientip.FromRequest and
+// clientip.FromContext rather than Gin's ClientIP.
+func ClientIPMiddleware(resolver *clientip.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := resolver.Resolve(c.Request.RemoteAddr, c.Request.Header.Values)
+		c.Request = c.Request.WithContext(clientip.NewContext(c.Request.Context(), ip))
+		c.Next()
+	}
+}
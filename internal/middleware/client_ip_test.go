@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	var resolved string
+	router := gin.New()
+	router.Use(ClientIPMiddleware(resolver))
+	router.GET("/test", func(c *gin.Context) {
+		resolved = clientip.FromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{"Behind Trusted Proxy", "10.0.0.2:4711", "203.0.113.7"},
+		{"Untrusted Peer", "198.51.100.1:4711", "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, resolved)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings a Compressor can apply, in order of preference
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// encoder is the part of gzip.Writer and brotli.Writer a compressWriter uses
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Encoders are reused across responses; each allocates sizable buffers
+var encoders = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression) }},
+	encodingGzip: {New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+// Compressor compresses responses with Brotli or gzip, whichever the client
+// prefers. Only responses of the allowed content types and of at least
+// minSize bytes are compressed; responses flushed before they reach minSize,
+// such as streams, are compressed from the first flush on.
+type Compressor struct {
+	minSize int
+	types   []string
+}
+
+// NewCompressor creates a compressor for responses of at least minSize bytes
+// whose media type, ignoring parameters such as charset, is in contentTypes
+func NewCompressor(minSize int, contentTypes []string) *Compressor {
+	return &Compressor{minSize: minSize, types: contentTypes}
+}
+
+// CompressionMiddleware compresses the responses of the routes it is attached to
+func CompressionMiddleware(compressor *Compressor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if skipCompression(c.Request) {
+			c.Next()
+			return
+		}
+
+		cw := compressor.newWriter(c.Writer, c.Request)
+		writer := &ginCompressWriter{ResponseWriter: c.Writer, cw: cw}
+		c.Writer = writer
+		defer func() {
+			// The client may be gone; there is nobody left to report to
+			_ = cw.Close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// Handler compresses the responses of next, e.g. the gRPC gateway
+func (c *Compressor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skipCompression(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := c.newWriter(w, r)
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// skipCompression reports whether a request can have no compressed response:
+// HEAD requests have no body and upgraded connections, such as WebSockets,
+// take over the response
+func skipCompression(r *http.Request) bool {
+	return r.Method == http.MethodHead || r.Header.Get("Upgrade") != ""
+}
+
+func (c *Compressor) newWriter(w http.ResponseWriter, r *http.Request) *compressWriter {
+	return &compressWriter{
+		ResponseWriter: w,
+		compressor:     c,
+		encoding:       negotiateEncoding(r.Header.Get("Accept-Encoding")),
+	}
+}
+
+// negotiateEncoding picks the coding to compress with from an Accept-Encoding
+// header, preferring Brotli when the client weighs both alike; it returns ""
+// when the client accepts neither
+func negotiateEncoding(acceptEncoding string) string {
+	brotliQ, gzipQ, anyQ := -1.0, -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case encodingBrotli:
+			brotliQ = q
+		case encodingGzip, "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if brotliQ < 0 {
+		brotliQ = anyQ
+	}
+	if gzipQ < 0 {
+		gzipQ = anyQ
+	}
+
+	switch {
+	case brotliQ > 0 && brotliQ >= gzipQ:
+		return encodingBrotli
+	case gzipQ > 0:
+		return encodingGzip
+	}
+	return ""
+}
+
+// compressWriter holds back the status and the start of the body until it
+// knows whether the response is compressed
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *Compressor
+	encoding   string // Negotiated with the client; empty sends the response as is
+
+	status  int
+	buf     []byte
+	started bool    // Whether the status and headers were passed on
+	encoder encoder // nil when the response is sent as is
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.started {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		if w.encoding != "" && w.compressible() && len(w.buf)+len(p) < w.compressor.minSize {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+		if err := w.start(false); err != nil {
+			return 0, err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far, compressing it if the response is
+// compressible regardless of its size
+func (w *compressWriter) Flush() {
+	if !w.started {
+		if err := w.start(false); err != nil {
+			return
+		}
+	}
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Close ends the response, sending a response still held back
+func (w *compressWriter) Close() error {
+	if !w.started {
+		if err := w.start(true); err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+	err := w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	encoders[w.encoding].Put(w.encoder)
+	w.encoder = nil
+	return err
+}
+
+// start decides whether to compress, then passes on the status and what was
+// held back of the body. complete tells whether the whole body was written,
+// so a response below the minimum size is sent as is.
+func (w *compressWriter) start(complete bool) error {
+	w.started = true
+	if w.compressible() {
+		header := w.Header()
+		if !slices.Contains(header.Values("Vary"), "Accept-Encoding") {
+			header.Add("Vary", "Accept-Encoding")
+		}
+		if w.encoding != "" && (!complete || len(w.buf) >= w.compressor.minSize) {
+			header.Del("Content-Length")
+			header.Set("Content-Encoding", w.encoding)
+			w.encoder = encoders[w.encoding].Get().(encoder)
+			w.encoder.Reset(w.ResponseWriter)
+		}
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible reports whether the response has a body of an allowed content
+// type that is not encoded already
+func (w *compressWriter) compressible() bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && slices.Contains(w.compressor.types, mediaType)
+}
+
+// ginCompressWriter routes the body of a Gin response through a compressWriter
+type ginCompressWriter struct {
+	gin.ResponseWriter
+	cw *compressWriter
+}
+
+func (w *ginCompressWriter) WriteHeader(code int) {
+	// Gin only records the status until the body is written, so Status
+	// reports it while the compressWriter holds the response back
+	w.ResponseWriter.WriteHeader(code)
+	w.cw.WriteHeader(code)
+}
+
+func (w *ginCompressWriter) WriteHeaderNow() {
+	if !w.cw.started {
+		_ = w.cw.start(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *ginCompressWriter) Write(p []byte) (int, error) {
+	return w.cw.Write(p)
+}
+
+func (w *ginCompressWriter) WriteString(s string) (int, error) {
+	return w.cw.Write([]byte(s))
+}
+
+func (w *ginCompressWriter) Flush() {
+	w.cw.Flush()
+}
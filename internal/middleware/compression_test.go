@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decompress returns the body of a recorded response, decoded by its Content-Encoding
+func decompress(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case "br":
+		reader = brotli.NewReader(w.Body)
+	case "gzip":
+		gz, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		reader = gz
+	}
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "", expected: ""},
+		{acceptEncoding: "gzip, deflate, br", expected: "br"},
+		{acceptEncoding: "gzip", expected: "gzip"},
+		{acceptEncoding: "br;q=0.5, gzip;q=0.8", expected: "gzip"},
+		{acceptEncoding: "br;q=0, gzip", expected: "gzip"},
+		{acceptEncoding: "*", expected: "br"},
+		{acceptEncoding: "gzip;q=0, *;q=0.1", expected: "br"},
+		{acceptEncoding: "br;q=0, gzip;q=0", expected: ""},
+		{acceptEncoding: "identity, deflate", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateEncoding(tt.acceptEncoding))
+		})
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("a", 2048)
+
+	router := gin.New()
+	router.Use(CompressionMiddleware(NewCompressor(1024, []string{"application/json", "text/csv"})))
+	router.Match([]string{http.MethodGet, http.MethodHead}, "/large", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": large}) })
+	router.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": "a"}) })
+	router.GET("/created", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{"data": large}) })
+	router.GET("/csv", func(c *gin.Context) {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		for i := 0; i < 100; i++ {
+			c.Writer.WriteString("id,email,name\n")
+		}
+	})
+	router.GET("/binary", func(c *gin.Context) { c.Data(http.StatusOK, "application/octet-stream", []byte(large)) })
+	router.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", []byte(large))
+	})
+	router.GET("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Writer.WriteString("data: {}\n\n")
+		c.Writer.Flush()
+	})
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		acceptEncoding   string
+		expectedStatus   int
+		expectedEncoding string
+		expectedVary     bool
+	}{
+		{name: "Brotli Preferred", path: "/large", acceptEncoding: "gzip, br", expectedStatus: http.StatusOK, expectedEncoding: "br", expectedVary: true},
+		{name: "Gzip", path: "/large", acceptEncoding: "gzip", expectedStatus: http.StatusOK, expectedEncoding: "gzip", expectedVary: true},
+		{name: "Status Kept", path: "/created", acceptEncoding: "gzip", expectedStatus: http.StatusCreated, expectedEncoding: "gzip", expectedVary: true},
+		{name: "Written In Parts", path: "/csv", acceptEncoding: "br", expectedStatus: http.StatusOK, expectedEncoding: "br", expectedVary: true},
+		{name: "Below Minimum Size", path: "/small", acceptEncoding: "br", expectedStatus: http.StatusOK, expectedVary: true},
+		{name: "Not Accepted", path: "/large", expectedStatus: http.StatusOK, expectedVary: true},
+		{name: "Content Type Not Allowed", path: "/binary", acceptEncoding: "br", expectedStatus: http.StatusOK},
+		{name: "Already Encoded", path: "/encoded", acceptEncoding: "br", expectedStatus: http.StatusOK, expectedEncoding: "gzip"},
+		{name: "No Content", path: "/empty", acceptEncoding: "br", expectedStatus: http.StatusNoContent},
+		{name: "Event Stream", path: "/stream", acceptEncoding: "br", expectedStatus: http.StatusOK},
+		{name: "HEAD", method: http.MethodHead, path: "/large", acceptEncoding: "br", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get("Content-Encoding"))
+			if tt.expectedVary {
+				assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			} else {
+				assert.Empty(t, w.Header().Get("Vary"))
+			}
+			if tt.expectedEncoding != "" && tt.path != "/encoded" {
+				assert.Empty(t, w.Header().Get("Content-Length"))
+				assert.NotEmpty(t, decompress(t, w))
+			}
+		})
+	}
+
+	t.Run("Body Round Trips", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/large", nil)
+		req.Header.Set("Accept-Encoding", "br")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, "br", w.Header().Get("Content-Encoding"))
+		assert.Less(t, w.Body.Len(), len(large))
+		assert.JSONEq(t, `{"data":"`+large+`"}`, decompress(t, w))
+	})
+}
+
+func TestCompressor_Handler(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+	handler := NewCompressor(1024, []string{"application/json"}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "2050")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `"`+large+`"`)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/users", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Empty(t, w.Header().Get("Content-Length"))
+	assert.Equal(t, `"`+large+`"`, decompress(t, w))
+}
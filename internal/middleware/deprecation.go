@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/deprecation"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// DeprecationMiddleware marks a route as deprecated: every response carries
+// the Deprecation, Sunset and Link headers of the notice and a warning in
+// the response envelope. Calls are logged so remaining clients can be found.
+func DeprecationMiddleware(notice deprecation.Notice, logger *zap.Logger) gin.HandlerFunc {
+	headers := notice.Headers()
+	warning := notice.Warning()
+
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		response.SetWarning(c, warning)
+
+		logger.Info("Deprecated route called",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.String("user_agent", c.Request.UserAgent()))
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/deprecation"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	notice := deprecation.Notice{
+		Since:       time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC),
+		Replacement: "GET /v2/things",
+	}
+
+	tests := []struct {
+		name         string
+		format       response.Format
+		handler      gin.HandlerFunc
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Success",
+			format:       response.FormatDefault,
+			handler:      func(c *gin.Context) { response.Success(c, gin.H{"id": "1"}) },
+			expectedCode: http.StatusOK,
+			expectedBody: `{"code":200,"message":"Success","data":{"id":"1"},"warning":"This endpoint is deprecated and will be removed on 2027-04-16; use GET /v2/things instead."}`,
+		},
+		{
+			name:         "Error",
+			format:       response.FormatDefault,
+			handler:      func(c *gin.Context) { response.NotFound(c, "thing not found") },
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"code":404,"message":"thing not found","warning":"This endpoint is deprecated and will be removed on 2027-04-16; use GET /v2/things instead."}`,
+		},
+		{
+			name:         "JSON:API",
+			format:       response.FormatJSONAPI,
+			handler:      func(c *gin.Context) { response.NotFound(c, "thing not found") },
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"errors":[{"status":"404","title":"thing not found"}],"meta":{"warning":"This endpoint is deprecated and will be removed on 2027-04-16; use GET /v2/things instead."}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/things", ResponseFormatMiddleware(tt.format), DeprecationMiddleware(notice, zap.NewNop()), tt.handler)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/things", nil))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, "@1792108800", rr.Header().Get("Deprecation"))
+			assert.Equal(t, "Fri, 16 Apr 2027 00:00:00 GMT", rr.Header().Get("Sunset"))
+		})
+	}
+}
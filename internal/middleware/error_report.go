@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// ErrorReportMiddleware reports requests that failed with 500 Internal Server
+// Error to reporter, with the route, request ID and signed-in user. Handlers
+// attach the error behind the response with c.Error; responses without one
+// are reported by their route alone. Panics are reported by
+// RecoveryMiddleware instead.
+func ErrorReportMiddleware(reporter errorreport.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() != http.StatusInternalServerError {
+			return
+		}
+		err := errors.New("internal server error")
+		if last := c.Errors.Last(); last != nil {
+			err = last.Err
+		}
+		requestID, _ := requestctx.RequestID(c.Request.Context())
+		reporter.Report(errorreport.Event{
+			Level:     errorreport.LevelError,
+			Err:       err,
+			Transport: errorreport.TransportHTTP,
+			Route:     c.FullPath(),
+			Method:    c.Request.Method,
+			RequestID: requestID,
+			UserID:    reportedUser(c),
+		})
+	}
+}
+
+// reportedUser returns the signed-in user of a request for error reports
+func reportedUser(c *gin.Context) string {
+	if id, ok := requestctx.UserID(c.Request.Context()); ok {
+		return id.String()
+	}
+	return ""
+}
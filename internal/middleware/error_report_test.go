@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// reportFunc adapts a function to an errorreport.Reporter
+type reportFunc func(event errorreport.Event)
+
+func (f reportFunc) Report(event errorreport.Event) { f(event) }
+
+func TestErrorReportMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	var reported []errorreport.Event
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), ErrorReportMiddleware(reportFunc(func(event errorreport.Event) { reported = append(reported, event) })))
+	router.GET("/users/:id", func(c *gin.Context) {
+		requestctx.SetUserID(c, userID)
+		_ = c.Error(errors.New("connection refused"))
+		c.Status(http.StatusInternalServerError)
+	})
+	router.GET("/unattached", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	router.GET("/unavailable", func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) })
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedEvent *errorreport.Event
+	}{
+		{
+			name: "Internal Error",
+			path: "/users/1",
+			expectedEvent: &errorreport.Event{
+				Level:     errorreport.LevelError,
+				Err:       errors.New("connection refused"),
+				Transport: errorreport.TransportHTTP,
+				Route:     "/users/:id",
+				Method:    http.MethodGet,
+				UserID:    userID.String(),
+			},
+		},
+		{
+			name: "No Error Attached",
+			path: "/unattached",
+			expectedEvent: &errorreport.Event{
+				Level:     errorreport.LevelError,
+				Err:       errors.New("internal server error"),
+				Transport: errorreport.TransportHTTP,
+				Route:     "/unattached",
+				Method:    http.MethodGet,
+			},
+		},
+		{name: "Expected Server Error", path: "/unavailable"},
+		{name: "Success", path: "/ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported = nil
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if tt.expectedEvent == nil {
+				assert.Empty(t, reported)
+				return
+			}
+			require.Len(t, reported, 1)
+			event := reported[0]
+			assert.Equal(t, w.Header().Get("X-Request-ID"), event.RequestID)
+			event.RequestID = ""
+			assert.Equal(t, *tt.expectedEvent, event)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// FeatureGate reports whether a feature flag is on.
+// *featureflag.Flags satisfies it.
+type FeatureGate interface {
+	Enabled(ctx context.Context, name string) bool
+}
+
+// RequireFeature serves routes only while the named flag is on, answering
+// 404 otherwise as if they did not exist. Flags are evaluated for the tenant
+// of the API key or, after AuthMiddleware, of the signed-in account, which is
+// also put in the request context for handlers checking flags themselves.
+// users may be nil on routes without user authentication.
+func RequireFeature(flags FeatureGate, users UserLookup, logger *zap.Logger, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenant := requestTenant(c, users, logger); tenant != "" {
+			requestctx.SetTenant(c, tenant)
+		}
+
+		if !flags.Enabled(c.Request.Context(), name) {
+			response.NotFound(c, "Not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestTenant returns the tenant of the API key or the signed-in account
+// of the request, or "" when there is none. The tenant of an account comes
+// from the account so clients cannot claim another one.
+func requestTenant(c *gin.Context, users UserLookup, logger *zap.Logger) string {
+	if tenant := requestctx.Tenant(c.Request.Context()); tenant != "" {
+		return tenant
+	}
+	id, ok := requestctx.UserID(c.Request.Context())
+	if !ok || users == nil {
+		return ""
+	}
+
+	user, err := users.GetByID(c.Request.Context(), id)
+	if err != nil || user == nil {
+		// Without the account the flag is evaluated for everyone
+		logger.Warn("Failed to load user tenant for feature flag",
+			zap.String("user_id", id.String()),
+			zap.Error(err))
+		return ""
+	}
+	return user.Tenant
+}
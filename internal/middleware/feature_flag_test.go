@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// tenantGate turns flags on for one tenant only
+type tenantGate string
+
+func (g tenantGate) Enabled(ctx context.Context, name string) bool {
+	return featureflag.TenantFromContext(ctx) == string(g)
+}
+
+func TestRequireFeature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		tenant       string // Set as by the API key middleware
+		setUserID    bool
+		lookup       UserLookup
+		expectedCode int
+	}{
+		{
+			name:         "API Key Tenant",
+			tenant:       "acme",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Account Tenant",
+			setUserID:    true,
+			lookup:       stubUserLookup{user: &domainUser.User{Tenant: "acme"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Other Tenant",
+			setUserID:    true,
+			lookup:       stubUserLookup{user: &domainUser.User{Tenant: "globex"}},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Anonymous",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "No User Lookup",
+			setUserID:    true,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/beta",
+				func(c *gin.Context) {
+					if tt.tenant != "" {
+						requestctx.SetTenant(c, tt.tenant)
+					}
+					if tt.setUserID {
+						requestctx.SetUserID(c, uuid.New())
+					}
+				},
+				RequireFeature(tenantGate("acme"), tt.lookup, zaptest.NewLogger(t), "beta"),
+				func(c *gin.Context) {
+					// Handlers evaluate flags for the same tenant
+					assert.Equal(t, "acme", featureflag.TenantFromContext(c.Request.Context()))
+					c.Status(http.StatusOK)
+				})
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/beta", nil))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"go.uber.org/zap"
+)
+
+// FeatureOverridesAppliedHeader echoes the overrides a request was served with
+const FeatureOverridesAppliedHeader = "X-Feature-Overrides-Applied"
+
+// FeatureOverrideMiddleware applies the feature flag overrides of requests
+// carrying a validly signed X-Feature-Overrides header to their context, and
+// echoes them in X-Feature-Overrides-Applied. Headers are ignored while the
+// verifier is disabled, as it is outside test environments; unsigned, expired
+// or malformed ones are logged and ignored, so the request is served with the
+// regular flag values.
+func FeatureOverrideMiddleware(verifier *featureflag.Verifier, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(featureflag.OverrideHeader)
+		if value == "" || !verifier.Enabled() {
+			c.Next()
+			return
+		}
+
+		overrides, err := verifier.Verify(value, c.GetHeader(featureflag.SignatureHeader))
+		if err != nil {
+			logger.Warn("Ignored feature flag overrides",
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err))
+			c.Next()
+			return
+		}
+
+		c.Header(FeatureOverridesAppliedHeader, overrides.String())
+		c.Request = c.Request.WithContext(featureflag.NewContext(c.Request.Context(), overrides))
+		c.Next()
+	}
+}
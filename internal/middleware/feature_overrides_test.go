@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+)
+
+func TestFeatureOverrideMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifier := featureflag.NewVerifier("secret", time.Hour)
+	value := "new-login-flow=on"
+
+	tests := []struct {
+		name        string
+		verifier    *featureflag.Verifier
+		signature   string
+		expectApply bool
+	}{
+		{name: "Signed Overrides Apply", verifier: verifier, signature: verifier.Sign(value, time.Now().Add(time.Minute)), expectApply: true},
+		{name: "Bad Signature Is Ignored", verifier: verifier, signature: "123.abcd"},
+		{name: "Disabled Verifier Ignores Header", verifier: featureflag.NewVerifier("", time.Hour), signature: verifier.Sign(value, time.Now().Add(time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enabled, ok bool
+			router := gin.New()
+			router.Use(FeatureOverrideMiddleware(tt.verifier, zap.NewNop()))
+			router.GET("/", func(c *gin.Context) {
+				enabled, ok = featureflag.Override(c.Request.Context(), "new-login-flow")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(featureflag.OverrideHeader, value)
+			req.Header.Set(featureflag.SignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectApply, ok)
+			assert.Equal(t, tt.expectApply, enabled)
+			if tt.expectApply {
+				assert.Equal(t, value, w.Header().Get(FeatureOverridesAppliedHeader))
+			} else {
+				assert.Empty(t, w.Header().Get(FeatureOverridesAppliedHeader))
+			}
+		})
+	}
+}
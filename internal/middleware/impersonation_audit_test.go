@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+func TestImpersonationAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	adminID := uuid.New()
+
+	tests := []struct {
+		name           string
+		authService    stubAuthService
+		path           string
+		expectedRecord bool
+	}{
+		{
+			name:           "Impersonated Read",
+			authService:    stubAuthService{userID: userID, actor: adminID},
+			path:           "/profile?fields=email",
+			expectedRecord: true,
+		},
+		{
+			name:        "Own Token",
+			authService: stubAuthService{userID: userID},
+			path:        "/profile",
+		},
+		{
+			name:        "Rejected Token",
+			authService: stubAuthService{err: errors.New("invalid token")},
+			path:        "/profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingAuditRepository{}
+			router := gin.New()
+			router.Use(ImpersonationAuditMiddleware(repo, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop()))
+			router.GET("/profile", AuthMiddleware(tt.authService, zap.NewNop()), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer token")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.expectedRecord {
+				assert.Empty(t, repo.entries)
+				return
+			}
+			require.Len(t, repo.entries, 1)
+			entry := repo.entries[0]
+			assert.Equal(t, domainAudit.ActionImpersonatedRequest, entry.Action)
+			assert.Equal(t, adminID, entry.ActorID)
+			assert.Equal(t, userID, entry.TargetID)
+			assert.Contains(t, entry.Details, `"method":"GET","route":"/profile","path":"/profile","query":"fields=email","status":200`)
+			assert.Contains(t, entry.Details, `"impersonation_id":"`)
+		})
+	}
+}
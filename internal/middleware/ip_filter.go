@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// IPFilterMiddleware rejects requests from client addresses filter does not
+// serve with 403 PERMISSION_DENIED. The client address is the one
+// ClientIPMiddleware resolved, or the peer address without it.
+func IPFilterMiddleware(filter *ipfilter.Filter, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := clientip.FromRequest(c.Request)
+		addr, _ := netip.ParseAddr(ip)
+		if !filter.Allows(addr) {
+			logger.Warn("Rejected request from filtered address",
+				zap.String("client_ip", ip),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.FullPath()))
+			response.AppError(c, ipfilter.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+)
+
+func TestIPFilterMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	filter, err := ipfilter.New([]string{"203.0.113.0/24"}, []string{"203.0.113.66"})
+	require.NoError(t, err)
+
+	resolver, err := clientip.NewResolver([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(ClientIPMiddleware(resolver), IPFilterMiddleware(filter, zap.NewNop()))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{"Allowed Client", "203.0.113.7:4711", "", http.StatusOK},
+		{"Denied Client", "203.0.113.66:4711", "", http.StatusForbidden},
+		{"Client Not Allowed", "198.51.100.1:4711", "", http.StatusForbidden},
+		{"Allowed Client Behind Trusted Proxy", "10.0.0.2:4711", "203.0.113.7", http.StatusOK},
+		{"Denied Client Behind Trusted Proxy", "10.0.0.2:4711", "203.0.113.66", http.StatusForbidden},
+		{"Forwarded Address From Untrusted Peer", "198.51.100.1:4711", "203.0.113.7", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"code":403,"message":"Access from your network address is not allowed.","errorCode":"PERMISSION_DENIED"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestIPFilterMiddleware_NoRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(IPFilterMiddleware(nil, zap.NewNop()))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/i18n"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// LanguageMiddleware selects the language response messages are rendered
+// in: the preferred language of the authenticated user, then the one the
+// Accept-Language header prefers, then English. The caller is identified by
+// AuthMiddleware further down the chain, so the preference is only looked up
+// when the response is rendered; codes such as errorCode stay the same in
+// every language.
+func LanguageMiddleware(users UserLookup, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.SetLanguageFunc(c, func(c *gin.Context) i18n.Language {
+			if id, ok := requestctx.UserID(c.Request.Context()); ok {
+				user, err := users.GetByID(c.Request.Context(), id)
+				if err == nil {
+					if lang, ok := i18n.Parse(user.Locale); ok {
+						return lang
+					}
+				} else if apperror.CodeOf(err) != apperror.CodeUserNotFound {
+					logger.Warn("Failed to load user for language preference",
+						zap.String("user_id", id.String()),
+						zap.Error(err))
+				}
+			}
+			if lang, ok := i18n.Negotiate(c.GetHeader("Accept-Language")); ok {
+				return lang
+			}
+			return i18n.Default
+		})
+		c.Next()
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+func TestLanguageMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		setUserID      bool
+		lookup         stubUserLookup
+		expectedLang   string
+	}{
+		{
+			name:         "Default",
+			expectedLang: "en",
+		},
+		{
+			name:           "Accept-Language",
+			acceptLanguage: "fr;q=0.9, zh-CN;q=0.8",
+			expectedLang:   "zh",
+		},
+		{
+			name:           "User Preference Wins",
+			acceptLanguage: "en",
+			setUserID:      true,
+			lookup:         stubUserLookup{user: &domainUser.User{Locale: "zh"}},
+			expectedLang:   "zh",
+		},
+		{
+			name:           "No Preference",
+			acceptLanguage: "zh",
+			setUserID:      true,
+			lookup:         stubUserLookup{user: &domainUser.User{}},
+			expectedLang:   "zh",
+		},
+		{
+			name:           "Deleted User",
+			acceptLanguage: "zh",
+			setUserID:      true,
+			lookup:         stubUserLookup{err: apperror.New(apperror.CodeUserNotFound, "user not found")},
+			expectedLang:   "zh",
+		},
+		{
+			name:           "Lookup Error",
+			acceptLanguage: "zh",
+			setUserID:      true,
+			lookup:         stubUserLookup{err: errors.New("db error")},
+			expectedLang:   "zh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(LanguageMiddleware(tt.lookup, zap.NewNop()))
+			router.GET("/profile",
+				func(c *gin.Context) {
+					if tt.setUserID {
+						requestctx.SetUserID(c, uuid.New())
+					}
+					c.Next()
+				},
+				func(c *gin.Context) { response.NotFound(c, "Not found") })
+
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, tt.expectedLang, rr.Header().Get("Content-Language"))
+		})
+	}
+}
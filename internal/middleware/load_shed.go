@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/loadshed"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// LoadShedMiddleware caps the requests served at once by limiter and sheds
+// the rest with 503 SERVICE_UNAVAILABLE and Retry-After. exempt lists route
+// paths (as registered, e.g. "/admin/v1/events/stream") that would hold a
+// slot for as long as a client listens, such as streams.
+func LoadShedMiddleware(limiter *loadshed.Limiter, logger *zap.Logger, exempt ...string) gin.HandlerFunc {
+	retryAfter := strconv.Itoa(int(loadshed.RetryAfter.Seconds()))
+	return func(c *gin.Context) {
+		if slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+		if !limiter.Acquire() {
+			logger.Warn("Shed request at concurrency limit",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.FullPath()),
+				zap.Int("max_in_flight", limiter.Max()))
+			c.Header("Retry-After", retryAfter)
+			response.AppError(c, loadshed.ErrOverloaded)
+			c.Abort()
+			return
+		}
+		defer limiter.Release()
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/loadshed"
+)
+
+func TestLoadShedMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := loadshed.NewLimiter(1)
+	entered, release := make(chan struct{}), make(chan struct{})
+
+	router := gin.New()
+	router.Use(LoadShedMiddleware(limiter, zap.NewNop(), "/stream"))
+	block := func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	}
+	router.GET("/slow", block)
+	router.GET("/stream", block)
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	// Hold the only slot
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); assert.Equal(t, http.StatusOK, serve("/slow").Code) }()
+	<-entered
+	// Streams do not take a slot
+	go func() { defer wg.Done(); assert.Equal(t, http.StatusOK, serve("/stream").Code) }()
+	<-entered
+
+	rr := serve("/fast")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.JSONEq(t, `{"code":503,"message":"The service is busy. Please try again shortly.","errorCode":"SERVICE_UNAVAILABLE"}`, rr.Body.String())
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, serve("/fast").Code)
+	assert.Equal(t, 0, limiter.InFlight())
+}
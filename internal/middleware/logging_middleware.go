@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
 	"go.uber.org/zap"
 )
 
@@ -17,15 +19,17 @@ func LoggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 
 		duration := time.Since(start)
+		requestID, _ := requestctx.RequestID(c.Request.Context())
 
 		logger.Info("Request",
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
 			zap.String("query", query),
 			zap.Int("status", c.Writer.Status()),
-			zap.String("ip", c.ClientIP()),
+			zap.String("ip", clientip.FromRequest(c.Request)),
 			zap.String("user-agent", c.Request.UserAgent()),
 			zap.Duration("duration", duration),
+			zap.String("request_id", requestID),
 		)
 	}
 }
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// ErrRateLimited is returned to clients that exceed their request budget
+var ErrRateLimited = apperror.New(apperror.CodeRateLimited, "Too many requests. Please try again later.")
+
+// DefaultMaxTrackedClients bounds how many clients a RateLimiter counts per window
+const DefaultMaxTrackedClients = 10000
+
+// RateLimiter is an in-memory fixed-window limiter keyed by client.
+// Counters are per process, so the effective limit scales with the number of instances.
+type RateLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	maxKeys     int
+	windowStart time.Time
+	counts      map[string]int
+	now         func() time.Time
+}
+
+// NewRateLimiter creates a limiter allowing limit requests per key in each window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		maxKeys: DefaultMaxTrackedClients,
+		counts:  make(map[string]int),
+		now:     time.Now,
+	}
+}
+
+// SetLimit changes the number of requests allowed per key, taking effect for
+// the requests that follow, e.g. when the configuration is reloaded
+func (l *RateLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+// Allow records a request for key and reports whether it is within the limit,
+// along with the time remaining until the window resets
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.windowStart) >= l.window {
+		// Start a fresh window; dropping all counters keeps memory bounded
+		l.windowStart = now
+		l.counts = make(map[string]int)
+	}
+
+	retryAfter := l.windowStart.Add(l.window).Sub(now)
+	if _, tracked := l.counts[key]; !tracked && len(l.counts) >= l.maxKeys {
+		// Too many distinct clients in this window, most likely spoofed or
+		// rotated addresses; refuse new ones rather than grow without bound
+		return false, retryAfter
+	}
+
+	l.counts[key]++
+	return l.counts[key] <= l.limit, retryAfter
+}
+
+// RateLimitMiddleware rejects clients that exceed the limiter's budget
+func RateLimitMiddleware(limiter *RateLimiter, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(clientip.FromRequest(c.Request))
+		if !allowed {
+			logger.Warn("Rate limit exceeded",
+				zap.String("path", c.FullPath()),
+				zap.String("client_ip", clientip.FromRequest(c.Request)))
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			response.AppError(c, ErrRateLimited)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	allowed, _ := limiter.Allow("a")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.Allow("a")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	// Other clients have their own budget
+	allowed, _ = limiter.Allow("b")
+	assert.True(t, allowed)
+
+	// A new window resets every budget
+	now = now.Add(time.Minute)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterSetLimit(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Minute)
+
+	allowed, _ := limiter.Allow("a")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("a")
+	assert.False(t, allowed)
+
+	// A raised limit applies within the current window
+	limiter.SetLimit(3)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterBoundsTrackedClients(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(5, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.maxKeys = 2
+
+	allowed, _ := limiter.Allow("a")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("b")
+	assert.True(t, allowed)
+
+	// New clients are refused once the window is full; known ones keep their budget
+	allowed, _ = limiter.Allow("c")
+	assert.False(t, allowed)
+	allowed, _ = limiter.Allow("a")
+	assert.True(t, allowed)
+	assert.Len(t, limiter.counts, 2)
+
+	now = now.Add(time.Minute)
+	allowed, _ = limiter.Allow("c")
+	assert.True(t, allowed)
+}
+
+func TestRateLimitMiddlewareIgnoresUntrustedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	assert.NoError(t, router.SetTrustedProxies(nil))
+	router.GET("/limited", RateLimitMiddleware(NewRateLimiter(1, time.Minute), zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Rotating X-Forwarded-For must not grant a fresh budget
+	for i, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = "198.51.100.7:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(rr, req)
+
+		if i == 0 {
+			assert.Equal(t, http.StatusOK, rr.Code)
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/limited", RateLimitMiddleware(NewRateLimiter(1, time.Minute), zap.NewNop()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/limited", nil)
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"code":429,"message":"Too many requests. Please try again later.","errorCode":"RATE_LIMITED"}`, rr.Body.String())
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// ReadOnlyMiddleware rejects mutating requests with 503 READ_ONLY while the
+// switch is on. Safe methods always pass; exempt lists route paths (as
+// registered, e.g. "/api/v1/auth/login") that keep working because they do
+// not write to the database.
+func ReadOnlyMiddleware(sw *readonly.Switch, logger *zap.Logger, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !sw.Enabled() || isSafeMethod(c.Request.Method) || slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		logger.Info("Rejected write in read-only mode",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()))
+		c.Header("Retry-After", "30")
+		response.AppError(c, readonly.ErrReadOnly)
+		c.Abort()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/readonly"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sw := readonly.NewSwitch(false)
+
+	router := gin.New()
+	router.Use(ReadOnlyMiddleware(sw, zap.NewNop(), "/auth/login"))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/users/:id", ok)
+	router.PUT("/users/:id", ok)
+	router.POST("/auth/login", ok)
+
+	tests := []struct {
+		name         string
+		readOnly     bool
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{name: "Write Allowed When Off", method: http.MethodPut, path: "/users/1", expectedCode: http.StatusOK},
+		{name: "Read Allowed", readOnly: true, method: http.MethodGet, path: "/users/1", expectedCode: http.StatusOK},
+		{name: "Exempt Route Allowed", readOnly: true, method: http.MethodPost, path: "/auth/login", expectedCode: http.StatusOK},
+		{name: "Write Rejected", readOnly: true, method: http.MethodPut, path: "/users/1", expectedCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw.Set(tt.readOnly)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedCode == http.StatusServiceUnavailable {
+				assert.JSONEq(t, `{"code":503,"message":"The service is temporarily read-only. Please try again later.","errorCode":"READ_ONLY"}`, rr.Body.String())
+				assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// RecoveryMiddleware recovers from panics in the handlers after it, records
+// them and answers with 500 INTERNAL in the standard envelope. Panics from a
+// client that went away are recorded but not answered, and
+// http.ErrAbortHandler is passed on to abort the response as net/http does.
+func RecoveryMiddleware(recorder *recovery.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			recorder.Record(c.Request.Context(), recovery.Panic{
+				Value:     value,
+				Stack:     debug.Stack(),
+				Transport: errorreport.TransportHTTP,
+				Route:     c.FullPath(),
+				Method:    c.Request.Method,
+				UserID:    reportedUser(c),
+			})
+			if brokenConnection(value) || c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			response.AppError(c, recovery.ErrPanic)
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// brokenConnection reports whether a panic was caused by writing to a client
+// that closed the connection, which cannot be answered
+func brokenConnection(value any) bool {
+	err, ok := value.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	var syscallErr *os.SyscallError
+	if !errors.As(err, &opErr) || !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	message := strings.ToLower(syscallErr.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
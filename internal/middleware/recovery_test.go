@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/recovery"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder, err := recovery.NewRecorder(zap.NewNop(), prometheus.NewRegistry(), nil)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RecoveryMiddleware(recorder))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	router.GET("/partial", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+	router.GET("/abort", func(c *gin.Context) { panic(http.ErrAbortHandler) })
+
+	t.Run("No Panic", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "INTERNAL", body["errorCode"])
+		assert.NotContains(t, w.Body.String(), "boom")
+	})
+
+	t.Run("Response Already Started", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "partial", w.Body.String())
+	})
+
+	t.Run("Abort Handler Passed On", func(t *testing.T) {
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+		})
+	})
+}
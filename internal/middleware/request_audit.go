@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"go.uber.org/zap"
+)
+
+// maxAuditedBodyBytes caps the part of a request body kept in the audit log
+const maxAuditedBodyBytes = 64 << 10
+
+// redactedValue replaces the value of sensitive fields in audited bodies
+const redactedValue = "[REDACTED]"
+
+// sensitiveFields are the lowercased substrings that mark a JSON field as
+// sensitive, after removing "_" and "-" from its name
+var sensitiveFields = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "credential", "captcha"}
+
+// requestDetails is the audit entry detail of an admin request
+type requestDetails struct {
+	Method        string          `json:"method"`
+	Route         string          `json:"route"`
+	Path          string          `json:"path"`
+	Query         string          `json:"query,omitempty"`
+	Status        int             `json:"status"`
+	ContentType   string          `json:"content_type,omitempty"`
+	ContentLength int64           `json:"content_length"`
+	Body          json.RawMessage `json:"body,omitempty"`         // Redacted JSON body
+	BodyOmitted   string          `json:"body_omitted,omitempty"` // Why the body was not kept
+}
+
+// RequestAuditMiddleware records every mutating request, with its JSON body,
+// in the audit log as an admin.request entry once it has been handled,
+// including requests that were rejected. Sensitive fields such as passwords
+// and tokens are redacted; other bodies, such as file uploads, are described
+// by content type and length only. It must run after AuthMiddleware; the
+// target is the user named by a /users/:id route. Failing to record an entry
+// is logged and does not affect the response.
+func RequestAuditMiddleware(entries domainAudit.Repository, ids idgen.Generator, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		details := requestDetails{
+			Method:        c.Request.Method,
+			Route:         c.FullPath(),
+			Path:          c.Request.URL.Path,
+			Query:         c.Request.URL.RawQuery,
+			ContentType:   c.ContentType(),
+			ContentLength: c.Request.ContentLength,
+		}
+		details.Body, details.BodyOmitted = captureBody(c)
+
+		c.Next()
+
+		details.Status = c.Writer.Status()
+		if err := recordRequest(context.WithoutCancel(c.Request.Context()), entries, ids, c, details); err != nil {
+			logger.Error("Failed to record admin request in audit log",
+				zap.String("method", details.Method),
+				zap.String("path", details.Path),
+				zap.Error(err))
+		}
+	}
+}
+
+// captureBody reads the start of the request body, leaving it intact for the
+// handler, and returns it redacted when it is a complete JSON document
+func captureBody(c *gin.Context) (json.RawMessage, string) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, ""
+	}
+
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditedBodyBytes+1))
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body), Closer: c.Request.Body}
+	switch {
+	case err != nil:
+		return nil, "unreadable"
+	case len(head) == 0:
+		return nil, ""
+	case len(head) > maxAuditedBodyBytes:
+		return nil, "too large"
+	}
+
+	if mediaType := c.ContentType(); mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, "not json"
+	}
+	var body any
+	if err := json.Unmarshal(head, &body); err != nil {
+		return nil, "invalid json"
+	}
+	redacted, err := json.Marshal(redact(body))
+	if err != nil {
+		return nil, "invalid json"
+	}
+	return redacted, ""
+}
+
+// readCloser joins a reader with the Closer of the body it was built from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// redact replaces the values of sensitive fields anywhere in a decoded JSON value
+func redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redact(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func recordRequest(ctx context.Context, entries domainAudit.Repository, ids idgen.Generator, c *gin.Context, details requestDetails) error {
+	id, err := ids.NewID()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	entry := &domainAudit.Entry{
+		ID:        id,
+		Action:    domainAudit.ActionAdminRequest,
+		Details:   string(data),
+		ClientIP:  clientip.FromRequest(c.Request),
+		CreatedAt: time.Now(),
+	}
+	entry.ActorID, _ = requestctx.UserID(ctx)
+	if strings.Contains(details.Route, "/users/:id") {
+		if targetID, err := idgen.Parse(c.Param("id")); err == nil {
+			entry.TargetID = targetID
+		}
+	}
+	return entries.Create(ctx, entry)
+}
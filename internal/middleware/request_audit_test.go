@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// recordingAuditRepository keeps the entries it is given
+type recordingAuditRepository struct {
+	entries []*domainAudit.Entry
+	err     error
+}
+
+func (r *recordingAuditRepository) Create(ctx context.Context, entry *domainAudit.Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *recordingAuditRepository) List(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *recordingAuditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestRequestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	actorID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		contentType     string
+		body            string
+		expectedRecord  bool
+		expectedTarget  uuid.UUID
+		expectedDetails string
+	}{
+		{
+			name:           "Redacts Sensitive Fields",
+			method:         http.MethodPost,
+			path:           "/users/" + targetID.String() + "/password-reset",
+			contentType:    "application/json",
+			body:           `{"reason":"compromised","newPassword":"hunter22","nested":{"api_key":"k","items":[{"refresh_token":"t","note":"n"}]}}`,
+			expectedRecord: true,
+			expectedTarget: targetID,
+			expectedDetails: `{"method":"POST","route":"/users/:id/password-reset","path":"/users/` + targetID.String() + `/password-reset","status":200,"content_type":"application/json","content_length":117,` +
+				`"body":{"reason":"compromised","newPassword":"[REDACTED]","nested":{"api_key":"[REDACTED]","items":[{"refresh_token":"[REDACTED]","note":"n"}]}}}`,
+		},
+		{
+			name:            "Non JSON Body Is Described Only",
+			method:          http.MethodPost,
+			path:            "/users/import?dry_run=true",
+			contentType:     "text/csv",
+			body:            "email,password\nada@example.com,secret\n",
+			expectedRecord:  true,
+			expectedDetails: `{"method":"POST","route":"/users/import","path":"/users/import","query":"dry_run=true","status":200,"content_type":"text/csv","content_length":38,"body_omitted":"not json"}`,
+		},
+		{
+			name:            "Target Only For User Routes",
+			method:          http.MethodDelete,
+			path:            "/system-messages/" + targetID.String(),
+			expectedRecord:  true,
+			expectedDetails: `{"method":"DELETE","route":"/system-messages/:id","path":"/system-messages/` + targetID.String() + `","status":200,"content_length":0}`,
+		},
+		{
+			name:   "Reads Are Not Recorded",
+			method: http.MethodGet,
+			path:   "/users/" + targetID.String() + "/sessions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingAuditRepository{}
+			var handlerBody string
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) { requestctx.SetUserID(c, actorID) })
+			router.Use(RequestAuditMiddleware(repo, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop()))
+			handler := func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				handlerBody = string(body)
+				c.Status(http.StatusOK)
+			}
+			router.POST("/users/:id/password-reset", handler)
+			router.POST("/users/import", handler)
+			router.DELETE("/system-messages/:id", handler)
+			router.GET("/users/:id/sessions", handler)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.body, handlerBody, "the handler must still see the whole body")
+			if !tt.expectedRecord {
+				assert.Empty(t, repo.entries)
+				return
+			}
+			require.Len(t, repo.entries, 1)
+			entry := repo.entries[0]
+			assert.Equal(t, domainAudit.ActionAdminRequest, entry.Action)
+			assert.Equal(t, actorID, entry.ActorID)
+			assert.Equal(t, tt.expectedTarget, entry.TargetID)
+			assert.JSONEq(t, tt.expectedDetails, entry.Details)
+			assert.Equal(t, "192.0.2.1", entry.ClientIP, "the peer address of the test request")
+		})
+	}
+}
+
+func TestRequestAuditMiddleware_RecordsRejectedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &recordingAuditRepository{}
+
+	router := gin.New()
+	router.Use(RequestAuditMiddleware(repo, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop()))
+	router.Use(func(c *gin.Context) { c.AbortWithStatus(http.StatusServiceUnavailable) })
+	router.PUT("/read-only", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodPut, "/read-only", strings.NewReader(`{"enabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, repo.entries, 1)
+	assert.Contains(t, repo.entries[0].Details, `"status":503`)
+	assert.Contains(t, repo.entries[0].Details, `"body":{"enabled":false}`)
+}
+
+func TestRequestAuditMiddleware_LargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &recordingAuditRepository{}
+	body := `{"note":"` + strings.Repeat("a", maxAuditedBodyBytes) + `"}`
+	var handlerBody []byte
+
+	router := gin.New()
+	router.Use(RequestAuditMiddleware(repo, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop()))
+	router.POST("/system-messages", func(c *gin.Context) {
+		handlerBody, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/system-messages", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, body, string(handlerBody))
+	require.Len(t, repo.entries, 1)
+	assert.Contains(t, repo.entries[0].Details, `"body_omitted":"too large"`)
+}
+
+func TestRequestAuditMiddleware_StoreFailureDoesNotAffectResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &recordingAuditRepository{err: errors.New("database unavailable")}
+
+	router := gin.New()
+	router.Use(RequestAuditMiddleware(repo, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop()))
+	router.POST("/system-messages", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/system-messages", nil))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+// RequestIDMiddleware gives every request an ID, taken from the X-Request-ID
+// header when a valid one is supplied and generated otherwise. The ID is
+// echoed in the response header and carried by the request context, so that
+// logs written while serving the request, such as slow queries, include it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Header(requestid.Header, id)
+		requestctx.SetRequestID(c, id)
+		c.Next()
+	}
+}
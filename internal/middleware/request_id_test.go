@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		header     string
+		expectSame bool
+	}{
+		{name: "Supplied ID Is Kept", header: "req-42", expectSame: true},
+		{name: "Missing ID Is Generated"},
+		{name: "Invalid ID Is Replaced", header: "bad id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromContext string
+			router := gin.New()
+			router.Use(RequestIDMiddleware())
+			router.GET("/", func(c *gin.Context) {
+				fromContext, _ = requestid.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestid.Header, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			id := rr.Header().Get(requestid.Header)
+			assert.True(t, requestid.Valid(id))
+			assert.Equal(t, id, fromContext)
+			if tt.expectSame {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+			}
+		})
+	}
+}
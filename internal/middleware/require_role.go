@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// Errors returned by RequireRole and RequireSelfOrRole
+var (
+	ErrAuthenticationRequired = apperror.New(apperror.CodeUnauthenticated, "Authentication required")
+	ErrInsufficientRole       = apperror.New(apperror.CodePermissionDenied, "You do not have permission to access this resource")
+)
+
+// UserLookup loads the account of an authenticated user.
+// serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
+}
+
+// RequireRole rejects requests from users that do not hold one of the given
+// roles or whose account is inactive. It must run after AuthMiddleware.
+// The role is loaded on every request, so demotions take effect immediately, or
+// within the user cache TTL for changes made through another instance.
+func RequireRole(users UserLookup, logger *zap.Logger, roles ...domainRBAC.Role) gin.HandlerFunc {
+	allowed := roleSet(roles)
+	return func(c *gin.Context) {
+		if requireRole(c, users, logger, allowed) {
+			c.Next()
+		}
+	}
+}
+
+// RequireSelfOrRole lets users act on their own account, named by the path
+// parameter param, and otherwise rejects requests as RequireRole does. It
+// must run after AuthMiddleware.
+func RequireSelfOrRole(users UserLookup, logger *zap.Logger, param string, roles ...domainRBAC.Role) gin.HandlerFunc {
+	allowed := roleSet(roles)
+	return func(c *gin.Context) {
+		id, ok := requestctx.UserID(c.Request.Context())
+		if !ok {
+			response.AppError(c, ErrAuthenticationRequired)
+			c.Abort()
+			return
+		}
+		if target, err := idgen.Parse(c.Param(param)); err == nil && target == id {
+			c.Next()
+			return
+		}
+		if requireRole(c, users, logger, allowed) {
+			c.Next()
+		}
+	}
+}
+
+// roleSet indexes roles for the role check
+func roleSet(roles []domainRBAC.Role) map[domainRBAC.Role]struct{} {
+	allowed := make(map[domainRBAC.Role]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+	return allowed
+}
+
+// requireRole checks that the authenticated user holds one of the allowed
+// roles, aborting the request with an error response when they do not
+func requireRole(c *gin.Context, users UserLookup, logger *zap.Logger, allowed map[domainRBAC.Role]struct{}) bool {
+	id, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.AppError(c, ErrAuthenticationRequired)
+		c.Abort()
+		return false
+	}
+
+	user, err := users.GetByID(c.Request.Context(), id)
+	if err != nil {
+		// A user deleted after their token was issued is simply not authorized
+		if apperror.CodeOf(err) != apperror.CodeUserNotFound {
+			logger.Error("Failed to load user for role check",
+				zap.String("user_id", id.String()),
+				zap.Error(err))
+			_ = c.Error(err)
+			response.InternalServerError(c, "Something went wrong. Please try again later.")
+			c.Abort()
+			return false
+		}
+		user = nil
+	}
+
+	if user == nil || !user.IsActive {
+		response.AppError(c, ErrInsufficientRole)
+		c.Abort()
+		return false
+	}
+	if _, ok := allowed[user.Role]; !ok {
+		logger.Warn("Role check failed",
+			zap.String("user_id", id.String()),
+			zap.String("role", string(user.Role)),
+			zap.String("path", c.FullPath()))
+		response.AppError(c, ErrInsufficientRole)
+		c.Abort()
+		return false
+	}
+	requestctx.SetRoles(c, user.Role)
+	return true
+}
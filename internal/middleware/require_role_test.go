@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// stubUserLookup returns a fixed user or error
+type stubUserLookup struct {
+	user *domainUser.User
+	err  error
+}
+
+func (s stubUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	return s.user, s.err
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		setUserID    bool
+		lookup       stubUserLookup
+		expectedCode int
+	}{
+		{
+			name:         "Admin Allowed",
+			setUserID:    true,
+			lookup:       stubUserLookup{user: &domainUser.User{ID: userID, Role: domainRBAC.RoleAdmin, IsActive: true}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Regular User Forbidden",
+			setUserID:    true,
+			lookup:       stubUserLookup{user: &domainUser.User{ID: userID, Role: domainRBAC.RoleUser, IsActive: true}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Inactive Admin Forbidden",
+			setUserID:    true,
+			lookup:       stubUserLookup{user: &domainUser.User{ID: userID, Role: domainRBAC.RoleAdmin}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Deleted User Forbidden",
+			setUserID:    true,
+			lookup:       stubUserLookup{err: apperror.New(apperror.CodeUserNotFound, "user not found")},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Lookup Error",
+			setUserID:    true,
+			lookup:       stubUserLookup{err: errors.New("db error")},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "Unauthenticated",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin",
+				func(c *gin.Context) {
+					if tt.setUserID {
+						requestctx.SetUserID(c, userID)
+					}
+				},
+				RequireRole(tt.lookup, zap.NewNop(), domainRBAC.RoleAdmin),
+				func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestRequireSelfOrRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID, otherID := uuid.New(), uuid.New()
+	regular := stubUserLookup{user: &domainUser.User{ID: userID, Role: domainRBAC.RoleUser, IsActive: true}}
+
+	tests := []struct {
+		name         string
+		target       string
+		lookup       stubUserLookup
+		expectedCode int
+	}{
+		{name: "Own Account", target: userID.String(), lookup: regular, expectedCode: http.StatusOK},
+		{name: "Other Account Forbidden", target: otherID.String(), lookup: regular, expectedCode: http.StatusForbidden},
+		{
+			name:         "Admin On Other Account",
+			target:       otherID.String(),
+			lookup:       stubUserLookup{user: &domainUser.User{ID: userID, Role: domainRBAC.RoleAdmin, IsActive: true}},
+			expectedCode: http.StatusOK,
+		},
+		{name: "Invalid ID Forbidden", target: "not-an-id", lookup: regular, expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PATCH("/users/:id",
+				func(c *gin.Context) { requestctx.SetUserID(c, userID) },
+				RequireSelfOrRole(tt.lookup, zap.NewNop(), "id", domainRBAC.RoleAdmin),
+				func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, "/users/"+tt.target, nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// ResponseFormatMiddleware selects the envelope used to render responses
+// for every route it is attached to
+func ResponseFormatMiddleware(format response.Format) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.SetFormat(c, format)
+		c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// ErrRequestTimeout is returned for requests that ran out of time
+var ErrRequestTimeout = apperror.New(apperror.CodeTimeout, "The request took too long to complete. Please try again later.")
+
+// TimeoutMiddleware gives each request timeout to complete. The deadline is
+// set on the request context, so database and Redis calls made with it are
+// cancelled once it passes; the server error a handler then reports is
+// replaced by 504 TIMEOUT. Handlers that ignore the context are not
+// interrupted. exempt lists route paths (as registered, e.g.
+// "/admin/v1/events/stream") that may run longer, such as streams.
+func TimeoutMiddleware(timeout time.Duration, logger *zap.Logger, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.timedOut || (errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written()) {
+			logger.Warn("Request timed out",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.FullPath()),
+				zap.Duration("timeout", timeout))
+			response.AppError(c, ErrRequestTimeout)
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter drops the server error a handler reports once the deadline
+// has passed, so the timeout can be reported instead
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+// drop reports whether what the handler writes is dropped
+func (w *timeoutWriter) drop() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && w.Status() >= http.StatusInternalServerError {
+		w.timedOut = errors.Is(w.ctx.Err(), context.DeadlineExceeded)
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if !w.drop() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(p []byte) (int, error) {
+	if w.drop() {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.drop() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
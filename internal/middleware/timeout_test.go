@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(TimeoutMiddleware(20*time.Millisecond, zap.NewNop(), "/stream"))
+	// waitThenFail reports the cancelled context as a server error, as a
+	// repository call would
+	waitThenFail := func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"message": c.Request.Context().Err().Error()})
+	}
+	router.GET("/fast", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	router.GET("/slow", waitThenFail)
+	router.GET("/silent", func(c *gin.Context) { <-c.Request.Context().Done() })
+	router.GET("/client-error", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusBadRequest, gin.H{"message": "bad"})
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": hasDeadline})
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Within Timeout", path: "/fast", expectedStatus: http.StatusOK},
+		{name: "Server Error After Timeout", path: "/slow", expectedStatus: http.StatusGatewayTimeout, expectedCode: "TIMEOUT"},
+		{name: "Nothing Written", path: "/silent", expectedStatus: http.StatusGatewayTimeout, expectedCode: "TIMEOUT"},
+		{name: "Client Error Kept", path: "/client-error", expectedStatus: http.StatusBadRequest},
+		{name: "Exempt", path: "/stream", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, body["errorCode"])
+			}
+			if tt.path == "/stream" {
+				assert.Equal(t, false, body["deadline"])
+			}
+		})
+	}
+
+	t.Run("Deadline Set", func(t *testing.T) {
+		var deadline bool
+		r := gin.New()
+		r.Use(TimeoutMiddleware(time.Minute, zap.NewNop()))
+		r.GET("/", func(c *gin.Context) {
+			_, deadline = c.Request.Context().Deadline()
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, deadline)
+	})
+}
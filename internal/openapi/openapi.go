@@ -0,0 +1,430 @@
+// Package openapi describes the HTTP gateway as an OpenAPI 3 document,
+// generated from the google.api.http annotations of the gRPC services.
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path, keyed by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one HTTP binding of an RPC
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Tags        []string            `json:"tags"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema describes a JSON value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the schemas operations refer to
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+const jsonContent = "application/json"
+
+// errorSchema is the REST error envelope the gateway renders failed RPCs in
+var errorSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"code":      {Type: "integer", Format: "int32"},
+		"message":   {Type: "string"},
+		"errorCode": {Type: "string"},
+	},
+}
+
+// binding is an HTTP route of an RPC
+type binding struct {
+	method      protoreflect.MethodDescriptor
+	rule        *annotations.HttpRule
+	verb        string
+	path        string   // With each variable reduced to its name
+	params      []string // Path variables
+	operationID string
+}
+
+// Generate describes the HTTP bindings of the services in files. Files are
+// given in the order the gateway registers their handlers: as on the gateway
+// mux, a later binding of the same verb and path shadows an earlier one.
+// created lists the full method names the gateway answers with 201 Created.
+func Generate(info Info, files []protoreflect.FileDescriptor, created map[string]bool) (*Document, error) {
+	var order []string
+	routes := make(map[string]binding)
+	for _, file := range files {
+		inFile := make(map[string]protoreflect.FullName)
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			bindings, err := serviceBindings(services.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			for _, b := range bindings {
+				key := b.verb + " " + b.path
+				if other, ok := inFile[key]; ok {
+					return nil, fmt.Errorf("%s is bound by both %s and %s", key, other, b.method.FullName())
+				}
+				inFile[key] = b.method.FullName()
+				if _, ok := routes[key]; !ok {
+					order = append(order, key)
+				}
+				routes[key] = b
+			}
+		}
+	}
+
+	g := &generator{schemas: map[string]*Schema{"Error": errorSchema}}
+	doc := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: g.schemas},
+	}
+	for _, key := range order {
+		b := routes[key]
+		op, err := g.operation(b, created)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Paths[b.path] == nil {
+			doc.Paths[b.path] = make(PathItem)
+		}
+		doc.Paths[b.path][strings.ToLower(b.verb)] = op
+	}
+	return doc, nil
+}
+
+// serviceBindings returns the HTTP routes of the RPCs of svc. Operation IDs
+// follow protoc-gen-openapiv2: Service_Method, numbered from 2 for
+// additional bindings.
+func serviceBindings(svc protoreflect.ServiceDescriptor) ([]binding, error) {
+	var bindings []binding
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if rule == nil {
+			continue
+		}
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			return nil, fmt.Errorf("%s: streaming RPCs cannot be described", method.FullName())
+		}
+
+		rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+		for n, r := range rules {
+			verb, template, err := pattern(r)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method.FullName(), err)
+			}
+			path, params, err := pathParams(template)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", method.FullName(), err)
+			}
+			id := string(svc.Name()) + "_" + string(method.Name())
+			if n > 0 {
+				id += strconv.Itoa(n + 1)
+			}
+			bindings = append(bindings, binding{method: method, rule: r, verb: verb, path: path, params: params, operationID: id})
+		}
+	}
+	return bindings, nil
+}
+
+// pattern returns the HTTP verb and path template of rule
+func pattern(rule *annotations.HttpRule) (verb, path string, err error) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "GET", p.Get, nil
+	case *annotations.HttpRule_Put:
+		return "PUT", p.Put, nil
+	case *annotations.HttpRule_Post:
+		return "POST", p.Post, nil
+	case *annotations.HttpRule_Delete:
+		return "DELETE", p.Delete, nil
+	case *annotations.HttpRule_Patch:
+		return "PATCH", p.Patch, nil
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath(), nil
+	}
+	return "", "", fmt.Errorf("HTTP rule has no pattern")
+}
+
+// pathParams returns the variables of a path template, and the template with
+// each variable reduced to its name, e.g. {name=users/*} to {name}
+func pathParams(template string) (path string, params []string, err error) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			b.WriteString(template)
+			return b.String(), params, nil
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated variable in %q", template)
+		}
+		name, _, _ := strings.Cut(template[start+1:start+end], "=")
+		params = append(params, name)
+		b.WriteString(template[:start] + "{" + name + "}")
+		template = template[start+end+1:]
+	}
+}
+
+// generator collects the schemas of the messages operations refer to
+type generator struct {
+	schemas map[string]*Schema
+}
+
+// operation describes one binding. Path variables and, without a body, the
+// remaining scalar fields are parameters; the response is the REST envelope
+// around the resource the gateway renders under data.
+func (g *generator) operation(b binding, created map[string]bool) (*Operation, error) {
+	in := b.method.Input()
+	op := &Operation{
+		OperationID: b.operationID,
+		Tags:        []string{string(b.method.Parent().Name())},
+		Responses:   make(map[string]Response),
+	}
+	if opts, ok := b.method.Options().(*descriptorpb.MethodOptions); ok {
+		op.Deprecated = opts.GetDeprecated()
+	}
+	bound := make(map[protoreflect.Name]bool)
+	for _, name := range b.params {
+		field := in.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			return nil, fmt.Errorf("%s: path variable %q is not a field of %s", b.method.FullName(), name, in.FullName())
+		}
+		bound[field.Name()] = true
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: g.field(field)})
+	}
+
+	switch body := b.rule.GetBody(); body {
+	case "":
+		op.Parameters = append(op.Parameters, g.queryParams(in, bound)...)
+	case "*":
+		op.RequestBody = jsonBody(g.remaining(in, bound))
+	default:
+		field := in.Fields().ByName(protoreflect.Name(body))
+		if field == nil {
+			return nil, fmt.Errorf("%s: body %q is not a field of %s", b.method.FullName(), body, in.FullName())
+		}
+		bound[field.Name()] = true
+		op.RequestBody = jsonBody(g.field(field))
+		op.Parameters = append(op.Parameters, g.queryParams(in, bound)...)
+	}
+
+	if b.rule.GetResponseBody() != "" {
+		return nil, fmt.Errorf("%s: response_body is not supported", b.method.FullName())
+	}
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Format: "int32"},
+			"message": {Type: "string"},
+			"data":    g.message(resource(b.method.Output())),
+		},
+	}
+	fullMethod := fmt.Sprintf("/%s/%s", b.method.Parent().FullName(), b.method.Name())
+	if created[fullMethod] {
+		op.Responses["201"] = Response{Description: "Created", Content: jsonContentOf(envelope)}
+	} else {
+		op.Responses["200"] = Response{Description: "OK", Content: jsonContentOf(envelope)}
+	}
+	op.Responses["default"] = Response{Description: "Error", Content: jsonContentOf(&Schema{Ref: "#/components/schemas/Error"})}
+	return op, nil
+}
+
+// resource returns the message the gateway renders under data: the only
+// field of msg when it is a message, as for UserResponse, and msg otherwise
+func resource(msg protoreflect.MessageDescriptor) protoreflect.MessageDescriptor {
+	fields := msg.Fields()
+	if fields.Len() != 1 {
+		return msg
+	}
+	field := fields.Get(0)
+	if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() {
+		return msg
+	}
+	return field.Message()
+}
+
+// queryParams describes the fields of msg not bound elsewhere that the
+// gateway reads from the query string: scalars, and well-known types written
+// as strings, such as a FieldMask
+func (g *generator) queryParams(msg protoreflect.MessageDescriptor, bound map[protoreflect.Name]bool) []Parameter {
+	var params []Parameter
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if bound[field.Name()] || field.IsMap() {
+			continue
+		}
+		if field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind {
+			if schema, ok := wellKnown(field.Message()); !ok || schema.Type != "string" {
+				continue
+			}
+		}
+		params = append(params, Parameter{Name: field.JSONName(), In: "query", Schema: g.field(field)})
+	}
+	return params
+}
+
+// remaining describes a body of every field of msg not bound to the path
+func (g *generator) remaining(msg protoreflect.MessageDescriptor, bound map[protoreflect.Name]bool) *Schema {
+	if len(bound) == 0 {
+		return g.message(msg)
+	}
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if field := fields.Get(i); !bound[field.Name()] {
+			schema.Properties[field.JSONName()] = g.field(field)
+		}
+	}
+	return schema
+}
+
+// message refers to the schema of msg, adding it to the components
+func (g *generator) message(msg protoreflect.MessageDescriptor) *Schema {
+	if schema, ok := wellKnown(msg); ok {
+		return schema
+	}
+	name := string(msg.FullName())
+	if _, ok := g.schemas[name]; !ok {
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		g.schemas[name] = schema // Before the fields, so recursive messages terminate
+		fields := msg.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			schema.Properties[field.JSONName()] = g.field(field)
+		}
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// field describes the protojson form of a field
+func (g *generator) field(field protoreflect.FieldDescriptor) *Schema {
+	if field.IsMap() {
+		return &Schema{Type: "object", AdditionalProperties: g.value(field.MapValue())}
+	}
+	if field.IsList() {
+		return &Schema{Type: "array", Items: g.value(field)}
+	}
+	return g.value(field)
+}
+
+// value describes a single value of a field. 64-bit integers are strings in protojson.
+func (g *generator) value(field protoreflect.FieldDescriptor) *Schema {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		schema := &Schema{Type: "string"}
+		for i := 0; i < values.Len(); i++ {
+			schema.Enum = append(schema.Enum, string(values.Get(i).Name()))
+		}
+		return schema
+	default:
+		return g.message(field.Message())
+	}
+}
+
+// wellKnown describes the well-known types protojson renders as plain values
+func wellKnown(msg protoreflect.MessageDescriptor) (*Schema, bool) {
+	switch msg.FullName() {
+	case "google.protobuf.Timestamp":
+		return &Schema{Type: "string", Format: "date-time"}, true
+	case "google.protobuf.Duration", "google.protobuf.FieldMask":
+		return &Schema{Type: "string"}, true
+	case "google.protobuf.Empty", "google.protobuf.Struct":
+		return &Schema{Type: "object"}, true
+	}
+	return nil, false
+}
+
+// jsonBody is a required JSON request body
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: jsonContentOf(schema)}
+}
+
+// jsonContentOf is the content map of a JSON body
+func jsonContentOf(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{jsonContent: {Schema: schema}}
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+)
+
+func TestSpecUpToDate(t *testing.T) {
+	spec, err := Build()
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(spec, Spec()), "%s is out of date, run `make openapi-gen`", SpecPath)
+}
+
+func TestGenerate(t *testing.T) {
+	doc, err := Generate(Info{Title: "Test", Version: "v1"}, gatewayFiles, createdMethods)
+	require.NoError(t, err)
+
+	t.Run("Later Binding Shadows Earlier", func(t *testing.T) {
+		assert.Equal(t, "AuthService_Login", doc.Paths["/v1/auth/login"]["post"].OperationID)
+		assert.NotContains(t, doc.Components.Schemas, "user.v1.LoginResponse")
+
+		reversed, err := Generate(Info{}, []protoreflect.FileDescriptor{authpb.File_auth_v1_auth_proto, userpb.File_user_v1_user_proto}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "UserService_Login", reversed.Paths["/v1/auth/login"]["post"].OperationID)
+	})
+
+	t.Run("Additional Binding", func(t *testing.T) {
+		op := doc.Paths["/v1/profile"]["get"]
+		require.NotNil(t, op)
+		assert.Equal(t, "UserService_GetProfile2", op.OperationID)
+		assert.Equal(t, []Parameter{
+			{Name: "id", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "readMask", In: "query", Schema: &Schema{Type: "string"}},
+		}, op.Parameters)
+	})
+
+	t.Run("Path Parameter Excluded From Body", func(t *testing.T) {
+		op := doc.Paths["/v1/users/{id}"]["put"]
+		require.NotNil(t, op)
+		assert.Equal(t, []Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}}, op.Parameters)
+		body := op.RequestBody.Content[jsonContent].Schema
+		assert.NotContains(t, body.Properties, "id")
+		assert.Contains(t, body.Properties, "firstName")
+	})
+
+	t.Run("Resource Unwrapped Into Envelope", func(t *testing.T) {
+		op := doc.Paths["/v1/auth/register"]["post"]
+		require.NotNil(t, op)
+		require.Contains(t, op.Responses, "201")
+		assert.NotContains(t, op.Responses, "200")
+		data := op.Responses["201"].Content[jsonContent].Schema.Properties["data"]
+		assert.Equal(t, "#/components/schemas/user.v1.User", data.Ref)
+		assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, doc.Components.Schemas["user.v1.User"].Properties["createdAt"])
+	})
+
+	t.Run("Streaming RPCs Omitted", func(t *testing.T) {
+		for _, item := range doc.Paths {
+			for _, op := range item {
+				assert.NotEqual(t, "AuthService_ValidateTokens", op.OperationID)
+			}
+		}
+	})
+}
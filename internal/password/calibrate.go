@@ -0,0 +1,98 @@
+package password
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinRecommendedBcryptCost is the lowest bcrypt cost the calibrator will suggest,
+// even if the host is too slow to meet the target at that cost
+const MinRecommendedBcryptCost = 10
+
+// samplePassword is hashed during calibration; its content does not affect timing
+var samplePassword = []byte("calibration-sample-password")
+
+// Measurement is the observed hashing time for one parameter set
+type Measurement struct {
+	Params   string
+	Duration time.Duration
+}
+
+// BcryptResult is the outcome of a bcrypt calibration
+type BcryptResult struct {
+	Cost         int
+	Duration     time.Duration // median hashing time at Cost
+	Measurements []Measurement
+}
+
+// Calibrator measures bcrypt hashing time on the current host, for
+// deployments that hash passwords with bcrypt.
+type Calibrator struct {
+	// Samples is the number of hashes timed per parameter set; the median is used
+	Samples int
+
+	// hashBcrypt is replaced in tests to avoid real hashing
+	hashBcrypt func(cost int) error
+	now        func() time.Time
+}
+
+// NewCalibrator creates a Calibrator that times real hashes
+func NewCalibrator(samples int) *Calibrator {
+	if samples < 1 {
+		samples = 1
+	}
+	return &Calibrator{
+		Samples: samples,
+		hashBcrypt: func(cost int) error {
+			_, err := bcrypt.GenerateFromPassword(samplePassword, cost)
+			return err
+		},
+		now: time.Now,
+	}
+}
+
+// Bcrypt returns the highest bcrypt cost whose hashing time stays within target.
+// Each cost step doubles the work, so measurement stops at the first cost over budget.
+func (c *Calibrator) Bcrypt(target time.Duration) (*BcryptResult, error) {
+	result := &BcryptResult{Cost: MinRecommendedBcryptCost}
+
+	for cost := MinRecommendedBcryptCost; cost <= bcrypt.MaxCost; cost++ {
+		d, err := c.median(func() error { return c.hashBcrypt(cost) })
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash with cost %d: %w", cost, err)
+		}
+		result.Measurements = append(result.Measurements, Measurement{Params: fmt.Sprintf("cost=%d", cost), Duration: d})
+
+		if d > target {
+			if cost == MinRecommendedBcryptCost {
+				result.Duration = d
+			}
+			break
+		}
+		result.Cost = cost
+		result.Duration = d
+	}
+
+	return result, nil
+}
+
+// median times fn Samples times and returns the median duration
+func (c *Calibrator) median(fn func() error) (time.Duration, error) {
+	durations := make([]time.Duration, 0, c.Samples)
+	for i := 0; i < c.Samples; i++ {
+		start := c.now()
+		if err := fn(); err != nil {
+			return 0, err
+		}
+		durations = append(durations, c.now().Sub(start))
+	}
+	// Insertion sort; sample counts are tiny
+	for i := 1; i < len(durations); i++ {
+		for j := i; j > 0 && durations[j] < durations[j-1]; j-- {
+			durations[j], durations[j-1] = durations[j-1], durations[j]
+		}
+	}
+	return durations[len(durations)/2], nil
+}
@@ -0,0 +1,62 @@
+package password
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCalibrator returns a Calibrator whose hashes advance a fake clock
+func fakeCalibrator(bcryptTime func(cost int) time.Duration) *Calibrator {
+	clock := time.Unix(0, 0)
+	return &Calibrator{
+		Samples:    3,
+		hashBcrypt: func(cost int) error { clock = clock.Add(bcryptTime(cost)); return nil },
+		now:        func() time.Time { return clock },
+	}
+}
+
+func TestCalibrateBcrypt(t *testing.T) {
+	// 50ms at cost 10, doubling with each step
+	doubling := func(cost int) time.Duration { return 50 * time.Millisecond << (cost - 10) }
+
+	tests := []struct {
+		name         string
+		target       time.Duration
+		expectedCost int
+	}{
+		{name: "Within Budget", target: 250 * time.Millisecond, expectedCost: 12},
+		{name: "Exact Budget", target: 400 * time.Millisecond, expectedCost: 13},
+		{name: "Host Too Slow Keeps Minimum", target: 10 * time.Millisecond, expectedCost: MinRecommendedBcryptCost},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fakeCalibrator(doubling)
+			result, err := c.Bcrypt(tc.target)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCost, result.Cost)
+			assert.Equal(t, doubling(tc.expectedCost), result.Duration)
+		})
+	}
+
+	t.Run("Hash Error", func(t *testing.T) {
+		c := fakeCalibrator(doubling)
+		c.hashBcrypt = func(int) error { return errors.New("boom") }
+		_, err := c.Bcrypt(250 * time.Millisecond)
+		assert.Error(t, err)
+	})
+}
+
+func TestMedian(t *testing.T) {
+	durations := []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond}
+	i := 0
+	c := fakeCalibrator(func(int) time.Duration { d := durations[i]; i++; return d })
+
+	d, err := c.median(func() error { return c.hashBcrypt(0) })
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Millisecond, d)
+}
@@ -0,0 +1,176 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm names a password hashing algorithm
+type Algorithm string
+
+// Supported algorithms
+const (
+	AlgorithmArgon2id Algorithm = "argon2id"
+	AlgorithmBcrypt   Algorithm = "bcrypt"
+)
+
+// Argon2Params are the Argon2id cost parameters (RFC 9106)
+type Argon2Params struct {
+	MemoryKiB   uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the second recommended option of RFC 9106
+// with a smaller memory budget: 64 MiB, 3 passes
+var DefaultArgon2Params = Argon2Params{
+	MemoryKiB:   64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Validate checks that the parameters are usable
+func (p Argon2Params) Validate() error {
+	switch {
+	case p.MemoryKiB < 8*1024:
+		return fmt.Errorf("argon2id memory %d KiB is below 8 MiB", p.MemoryKiB)
+	case p.Iterations < 1:
+		return errors.New("argon2id iterations must be at least 1")
+	case p.Parallelism < 1:
+		return errors.New("argon2id parallelism must be at least 1")
+	case p.SaltLength < 8:
+		return fmt.Errorf("argon2id salt length %d is below 8 bytes", p.SaltLength)
+	case p.KeyLength < 16:
+		return fmt.Errorf("argon2id key length %d is below 16 bytes", p.KeyLength)
+	}
+	return nil
+}
+
+// ErrUnknownHash is returned for stored hashes of an unsupported format
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// argon2idPrefix starts every Argon2id hash in the PHC string format
+const argon2idPrefix = "$argon2id$"
+
+// Hasher hashes new passwords with one algorithm and verifies hashes of
+// every supported algorithm, so stored hashes keep working after the
+// algorithm or its parameters change. NeedsRehash reports the hashes to
+// upgrade.
+type Hasher struct {
+	algorithm  Algorithm
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+// NewArgon2idHasher creates a Hasher producing Argon2id hashes
+func NewArgon2idHasher(params Argon2Params) (*Hasher, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &Hasher{algorithm: AlgorithmArgon2id, argon2: params}, nil
+}
+
+// NewDefaultHasher creates a Hasher producing Argon2id hashes with DefaultArgon2Params
+func NewDefaultHasher() *Hasher {
+	return &Hasher{algorithm: AlgorithmArgon2id, argon2: DefaultArgon2Params}
+}
+
+// NewBcryptHasher creates a Hasher producing bcrypt hashes
+func NewBcryptHasher(cost int) (*Hasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost %d out of range [%d, %d]", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return &Hasher{algorithm: AlgorithmBcrypt, bcryptCost: cost}, nil
+}
+
+// Algorithm returns the algorithm of new hashes
+func (h *Hasher) Algorithm() Algorithm {
+	return h.algorithm
+}
+
+// Hash hashes plain with the configured algorithm
+func (h *Hasher) Hash(plain string) (string, error) {
+	if h.algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(plain), h.bcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, h.argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := h.argon2
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		p.MemoryKiB, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// NeedsRehash reports whether hash was made with another algorithm or other
+// parameters than new hashes are
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if h.algorithm == AlgorithmBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.bcryptCost
+	}
+
+	p, _, key, err := decodeArgon2id(hash)
+	return err != nil ||
+		p.MemoryKiB != h.argon2.MemoryKiB ||
+		p.Iterations != h.argon2.Iterations ||
+		p.Parallelism != h.argon2.Parallelism ||
+		uint32(len(key)) != h.argon2.KeyLength
+}
+
+// Verify reports whether plain matches hash, whichever supported algorithm made it
+func Verify(hash, plain string) bool {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
+	}
+
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	candidate := argon2.IDKey([]byte(plain), salt, p.Iterations, p.MemoryKiB, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1
+}
+
+// decodeArgon2id parses an Argon2id hash in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != string(AlgorithmArgon2id) {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrUnknownHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,116 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheapArgon2Params keeps the tests fast
+var cheapArgon2Params = Argon2Params{MemoryKiB: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher, err := NewArgon2idHasher(cheapArgon2Params)
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash("s3cret-pass")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	assert.True(t, Verify(hash, "s3cret-pass"))
+	assert.False(t, Verify(hash, "wrong-pass"))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	other, err := hasher.Hash("s3cret-pass")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salts should differ")
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret-pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	assert.True(t, Verify(string(hash), "s3cret-pass"))
+	assert.False(t, Verify(string(hash), "wrong-pass"))
+}
+
+func TestVerifyMalformedHash(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plain-text",
+		"$argon2id$v=19$m=8192,t=1,p=1$not base64$key",
+		"$argon2id$v=18$m=8192,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=8192,t=1$c2FsdHNhbHQ$a2V5a2V5",
+	} {
+		assert.False(t, Verify(hash, "s3cret-pass"), hash)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon, err := NewArgon2idHasher(cheapArgon2Params)
+	require.NoError(t, err)
+	stronger := cheapArgon2Params
+	stronger.Iterations = 2
+	strongerArgon, err := NewArgon2idHasher(stronger)
+	require.NoError(t, err)
+	bcryptHasher, err := NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+
+	argonHash, err := argon.Hash("s3cret-pass")
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("s3cret-pass")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		hasher   *Hasher
+		hash     string
+		expected bool
+	}{
+		{name: "Current Argon2id", hasher: argon, hash: argonHash, expected: false},
+		{name: "Weaker Argon2id", hasher: strongerArgon, hash: argonHash, expected: true},
+		{name: "Bcrypt To Argon2id", hasher: argon, hash: bcryptHash, expected: true},
+		{name: "Current Bcrypt", hasher: bcryptHasher, hash: bcryptHash, expected: false},
+		{name: "Argon2id To Bcrypt", hasher: bcryptHasher, hash: argonHash, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.hasher.NeedsRehash(tc.hash))
+		})
+	}
+}
+
+func TestArgon2ParamsValidate(t *testing.T) {
+	assert.NoError(t, DefaultArgon2Params.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(p *Argon2Params)
+	}{
+		{name: "Memory Too Low", mutate: func(p *Argon2Params) { p.MemoryKiB = 1024 }},
+		{name: "No Iterations", mutate: func(p *Argon2Params) { p.Iterations = 0 }},
+		{name: "No Parallelism", mutate: func(p *Argon2Params) { p.Parallelism = 0 }},
+		{name: "Short Salt", mutate: func(p *Argon2Params) { p.SaltLength = 4 }},
+		{name: "Short Key", mutate: func(p *Argon2Params) { p.KeyLength = 8 }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := DefaultArgon2Params
+			tc.mutate(&p)
+			_, err := NewArgon2idHasher(p)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewBcryptHasherRejectsCostOutOfRange(t *testing.T) {
+	_, err := NewBcryptHasher(bcrypt.MinCost - 1)
+	assert.Error(t, err)
+	_, err = NewBcryptHasher(bcrypt.MaxCost + 1)
+	assert.Error(t, err)
+}
@@ -1,10 +1,6 @@
 package provider
 
 import (
-	"fmt"
-	"os"
-
-	"github.com/spf13/viper"
 	"github.com/yi-tech/go-user-service/internal/config"
 )
 
@@ -13,52 +9,34 @@ type ConfigProvider interface {
 	GetConfig() (*config.Config, error)
 }
 
-// DefaultConfigProvider implements ConfigProvider using Viper
+// DefaultConfigProvider implements ConfigProvider using the layered sources of config.Load
 type DefaultConfigProvider struct {
-	configPath string
+	opts config.Options
 }
 
 // NewConfigProvider creates a new instance of DefaultConfigProvider
 // By default, it looks for config files in the ./configs directory
 func NewConfigProvider() ConfigProvider {
-	return &DefaultConfigProvider{
-		configPath: "./configs",
-	}
+	return NewConfigProviderWithOptions(config.Options{})
 }
 
 // NewConfigProviderWithPath creates a new instance of DefaultConfigProvider with a custom config path
 func NewConfigProviderWithPath(configPath string) ConfigProvider {
-	return &DefaultConfigProvider{
-		configPath: configPath,
-	}
+	return NewConfigProviderWithOptions(config.Options{Dir: configPath})
 }
 
-// GetConfig loads and returns the application configuration
-func (p *DefaultConfigProvider) GetConfig() (*config.Config, error) {
-	// Determine environment
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "local" // Default to local environment
-	}
-
-	// Initialize Viper
-	v := viper.New()
-	v.SetConfigName(fmt.Sprintf("config.%s", env))
-	v.SetConfigType("yaml")
-	v.AddConfigPath(p.configPath) // Look for config in the specified directory
-
-	// Read configuration file
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	// Unmarshal into Config struct
-	var cfg config.Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// NewConfigProviderWithOptions creates a new instance of DefaultConfigProvider
+// reading the sources selected by opts, e.g. from command-line flags
+func NewConfigProviderWithOptions(opts config.Options) ConfigProvider {
+	if opts.DefaultEnv == "" {
+		opts.DefaultEnv = "local" // Default to local environment
 	}
+	return &DefaultConfigProvider{opts: opts}
+}
 
-	return &cfg, nil
+// GetConfig loads, validates and returns the application configuration
+func (p *DefaultConfigProvider) GetConfig() (*config.Config, error) {
+	return config.Load(p.opts)
 }
 
 // Note: The actual Wire provider function is in provider.go
@@ -2,8 +2,11 @@ package provider
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -12,29 +15,54 @@ import (
 // DatabaseProvider defines methods for providing database connections
 type DatabaseProvider interface {
 	GetDB() (*gorm.DB, error)
+	GetReplicas() (*replica.Pool, error)
 }
 
 // GormDatabaseProvider implements DatabaseProvider using GORM
 type GormDatabaseProvider struct {
-	cfg *config.Config
+	cfg    *config.Config
+	logger *zap.Logger
 }
 
 // NewDatabaseProvider creates a new instance of GormDatabaseProvider
-func NewDatabaseProvider(cfg *config.Config) DatabaseProvider {
+func NewDatabaseProvider(cfg *config.Config, logger *zap.Logger) DatabaseProvider {
 	return &GormDatabaseProvider{
-		cfg: cfg,
+		cfg:    cfg,
+		logger: logger,
 	}
 }
 
 // GetDB creates and returns a configured database connection
 func (p *GormDatabaseProvider) GetDB() (*gorm.DB, error) {
-	// Configure GORM with more options for production readiness
+	return p.open(p.cfg.Database.Source)
+}
+
+// GetReplicas connects to every configured read replica. The pool is empty
+// when none are configured, so reads go to the primary.
+func (p *GormDatabaseProvider) GetReplicas() (*replica.Pool, error) {
+	replicas := make([]*gorm.DB, 0, len(p.cfg.Database.ReplicaSources))
+	for i, source := range p.cfg.Database.ReplicaSources {
+		db, err := p.open(source)
+		if err != nil {
+			return nil, fmt.Errorf("read replica %d: %w", i, err)
+		}
+		replicas = append(replicas, db)
+	}
+	return replica.NewPool(replicas...), nil
+}
+
+// open connects to the database at source with the configured logging and pool limits
+func (p *GormDatabaseProvider) open(source string) (*gorm.DB, error) {
+	// Failed and slow queries are always logged; every query is logged while
+	// the gorm logs are at debug level, by default outside production
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-		// Add other GORM configuration options as needed
+		Logger: newGormLogger(p.logger, logger.Info, p.cfg.Database.SlowQueryThreshold()),
+		// Postgres keeps microseconds; timestamps set on write then equal those
+		// read back, which user versions rely on
+		NowFunc: func() time.Time { return time.Now().Truncate(time.Microsecond) },
 	}
 
-	db, err := gorm.Open(postgres.Open(p.cfg.Database.Source), gormConfig)
+	db, err := gorm.Open(postgres.Open(source), gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -46,8 +74,9 @@ func (p *GormDatabaseProvider) GetDB() (*gorm.DB, error) {
 	}
 
 	// Set connection pool parameters
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	sqlDB.SetMaxOpenConns(p.cfg.Database.OpenConns())
+	sqlDB.SetMaxIdleConns(p.cfg.Database.IdleConns())
+	sqlDB.SetConnMaxLifetime(p.cfg.Database.ConnMaxLifetime())
 
 	return db, nil
 }
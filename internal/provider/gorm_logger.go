@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+// gormLogger writes GORM logs to zap. Failed and slow queries are logged with
+// the ID of the request that issued them; at the Info level every query is
+// logged at debug level. Query parameters are never logged, as they can hold
+// personal data and password hashes.
+type gormLogger struct {
+	logger        *zap.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration // 0 disables the slow query log
+}
+
+// newGormLogger creates a GORM logger that reports queries slower than slowThreshold
+func newGormLogger(zl *zap.Logger, level logger.LogLevel, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{logger: zl.Named("gorm"), level: level, slowThreshold: slowThreshold}
+}
+
+// LogMode implements logger.Interface
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info implements logger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.withRequest(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn implements logger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.withRequest(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error implements logger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.withRequest(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace implements logger.Interface. A missing record is not an error: the
+// repositories report it as a nil result.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		l.withRequest(ctx).Error("Query failed", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Error(err))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.withRequest(ctx).Warn("Slow query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed), zap.Duration("threshold", l.slowThreshold))
+	case l.level >= logger.Info:
+		// Skip rendering the query when the gorm logs are above debug level
+		if l.logger.Check(zap.DebugLevel, "Query") == nil {
+			return
+		}
+		sql, rows := fc()
+		l.withRequest(ctx).Debug("Query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+	}
+}
+
+// ParamsFilter implements gorm.ParamsFilter. Dropping the parameters leaves
+// their placeholders in the logged SQL.
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// withRequest adds the request ID carried by ctx, if any
+func (l *gormLogger) withRequest(ctx context.Context) *zap.Logger {
+	if id, ok := requestid.FromContext(ctx); ok {
+		return l.logger.With(zap.String("request_id", id))
+	}
+	return l.logger
+}
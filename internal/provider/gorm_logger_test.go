@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+func TestGormLogger_Trace(t *testing.T) {
+	query := func() (string, int64) { return `SELECT * FROM "users" WHERE email = $1`, 1 }
+	ctx := requestid.NewContext(context.Background(), "req-42")
+
+	tests := []struct {
+		name            string
+		level           logger.LogLevel
+		elapsed         time.Duration
+		err             error
+		expectedLevel   zapcore.Level
+		expectedMessage string
+	}{
+		{name: "Slow Query", level: logger.Warn, elapsed: 300 * time.Millisecond, expectedLevel: zapcore.WarnLevel, expectedMessage: "Slow query"},
+		{name: "Failed Query", level: logger.Warn, elapsed: time.Millisecond, err: errors.New("connection reset"), expectedLevel: zapcore.ErrorLevel, expectedMessage: "Query failed"},
+		{name: "Fast Query At Info", level: logger.Info, elapsed: time.Millisecond, expectedLevel: zapcore.DebugLevel, expectedMessage: "Query"},
+		{name: "Fast Query At Warn", level: logger.Warn, elapsed: time.Millisecond},
+		{name: "Record Not Found", level: logger.Warn, elapsed: time.Millisecond, err: gorm.ErrRecordNotFound},
+		{name: "Silent", level: logger.Silent, elapsed: time.Second, err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			l := newGormLogger(zap.New(core), logger.Info, 200*time.Millisecond).LogMode(tt.level)
+
+			l.Trace(ctx, time.Now().Add(-tt.elapsed), query, tt.err)
+
+			if tt.expectedMessage == "" {
+				assert.Zero(t, logs.Len())
+				return
+			}
+			require.Equal(t, 1, logs.Len())
+			entry := logs.All()[0]
+			assert.Equal(t, tt.expectedLevel, entry.Level)
+			assert.Equal(t, tt.expectedMessage, entry.Message)
+			assert.Equal(t, "req-42", entry.ContextMap()["request_id"])
+			assert.Equal(t, `SELECT * FROM "users" WHERE email = $1`, entry.ContextMap()["sql"])
+		})
+	}
+}
+
+func TestGormLogger_ParamsFilter(t *testing.T) {
+	sql, params := newGormLogger(zap.NewNop(), logger.Info, 0).ParamsFilter(context.Background(), "SELECT $1", "secret")
+
+	assert.Equal(t, "SELECT $1", sql)
+	assert.Empty(t, params)
+}
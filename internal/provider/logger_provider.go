@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/logging"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -15,13 +16,15 @@ type LoggerProvider interface {
 
 // ZapLoggerProvider implements LoggerProvider using Zap
 type ZapLoggerProvider struct {
-	cfg *config.Config
+	cfg    *config.Config
+	levels *logging.Levels
 }
 
-// NewLoggerProvider creates a new instance of ZapLoggerProvider
-func NewLoggerProvider(cfg *config.Config) LoggerProvider {
+// NewLoggerProvider creates a new instance of ZapLoggerProvider logging at the level of each module
+func NewLoggerProvider(cfg *config.Config, levels *logging.Levels) LoggerProvider {
 	return &ZapLoggerProvider{
-		cfg: cfg,
+		cfg:    cfg,
+		levels: levels,
 	}
 }
 
@@ -36,12 +39,14 @@ func (p *ZapLoggerProvider) GetLogger() (*zap.Logger, error) {
 		config := zap.NewProductionConfig()
 		config.EncoderConfig.TimeKey = "timestamp"
 		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		logger, err = config.Build()
+		config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel) // Filtered per module by levels
+		logger, err = config.Build(zap.WrapCore(p.levels.Core))
 	} else {
 		// Development configuration with console output
 		config := zap.NewDevelopmentConfig()
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-		logger, err = config.Build()
+		config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel) // Filtered per module by levels
+		logger, err = config.Build(zap.WrapCore(p.levels.Core))
 	}
 
 	if err != nil {
@@ -6,31 +6,47 @@ package provider
 import (
 	"github.com/go-redis/redis/v8"
 	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/logging"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // ProvideConfig is the Wire provider function for application configuration.
 // It delegates to the implementation in config_provider.go.
-func ProvideConfig() (*config.Config, error) {
-	provider := NewConfigProvider()
+func ProvideConfig(opts config.Options) (*config.Config, error) {
+	provider := NewConfigProviderWithOptions(opts)
 	return provider.GetConfig()
 }
 
+// ProvideLogLevels is the Wire provider function for the log levels of the
+// application, HTTP access, gRPC and GORM logs, which can be changed while
+// the logger is in use.
+func ProvideLogLevels(cfg *config.Config) *logging.Levels {
+	return logging.NewLevels(cfg.LogLevel(), cfg.LogModuleLevels())
+}
+
 // ProvideLogger is the Wire provider function for the logger.
 // It delegates to the implementation in logger_provider.go.
-func ProvideLogger(cfg *config.Config) (*zap.Logger, error) {
-	provider := NewLoggerProvider(cfg)
+func ProvideLogger(cfg *config.Config, levels *logging.Levels) (*zap.Logger, error) {
+	provider := NewLoggerProvider(cfg, levels)
 	return provider.GetLogger()
 }
 
 // ProvideDatabase is the Wire provider function for the database connection.
 // It delegates to the implementation in database_provider.go.
-func ProvideDatabase(cfg *config.Config) (*gorm.DB, error) {
-	provider := NewDatabaseProvider(cfg)
+func ProvideDatabase(cfg *config.Config, logger *zap.Logger) (*gorm.DB, error) {
+	provider := NewDatabaseProvider(cfg, logger)
 	return provider.GetDB()
 }
 
+// ProvideReadReplicas is the Wire provider function for the read replica pool.
+// It delegates to the implementation in database_provider.go.
+func ProvideReadReplicas(cfg *config.Config, logger *zap.Logger) (*replica.Pool, error) {
+	provider := NewDatabaseProvider(cfg, logger)
+	return provider.GetReplicas()
+}
+
 // ProvideRedisClient is the Wire provider function for the Redis client.
 // It delegates to the implementation in redis_provider.go.
 func ProvideRedisClient(cfg *config.Config) (*redis.Client, error) {
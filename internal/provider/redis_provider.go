@@ -29,16 +29,32 @@ func NewRedisProvider(cfg *config.Config) RedisProvider {
 // GetRedisClient creates and returns a configured Redis client
 func (p *DefaultRedisProvider) GetRedisClient() (*redis.Client, error) {
 	// Create Redis client with configuration
-	rdb := redis.NewClient(&redis.Options{
-		Addr:         p.cfg.Redis.Addr,
-		Password:     p.cfg.Redis.Password,
-		DB:           p.cfg.Redis.DB,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     10,
-		MinIdleConns: 5,
-	})
+	var rdb *redis.Client
+	if failover := p.cfg.Redis.Failover; failover.SentinelMaster != "" {
+		// Sentinel tells the client where the primary is after a failover
+		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    failover.SentinelMaster,
+			SentinelAddrs: failover.SentinelAddrs,
+			Password:      p.cfg.Redis.Password,
+			DB:            p.cfg.Redis.DB,
+			DialTimeout:   5 * time.Second,
+			ReadTimeout:   3 * time.Second,
+			WriteTimeout:  3 * time.Second,
+			PoolSize:      10,
+			MinIdleConns:  5,
+		})
+	} else {
+		rdb = redis.NewClient(&redis.Options{
+			Addr:         p.cfg.Redis.Addr,
+			Password:     p.cfg.Redis.Password,
+			DB:           p.cfg.Redis.DB,
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+			PoolSize:     10,
+			MinIdleConns: 5,
+		})
+	}
 
 	// Ping the Redis server to verify connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,34 @@
+package readonly
+
+import (
+	"sync/atomic"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// ErrReadOnly is returned for mutating requests while read-only mode is on
+var ErrReadOnly = apperror.New(apperror.CodeReadOnly, "The service is temporarily read-only. Please try again later.")
+
+// Switch holds the read-only mode of the API. While it is on, requests that
+// would write to the database are rejected; reads and sign-ins keep working.
+// The mode is per process, so it must be toggled on every instance.
+type Switch struct {
+	enabled atomic.Bool
+}
+
+// NewSwitch creates a switch with the given initial mode
+func NewSwitch(enabled bool) *Switch {
+	s := &Switch{}
+	s.enabled.Store(enabled)
+	return s
+}
+
+// Enabled reports whether read-only mode is on
+func (s *Switch) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// Set turns read-only mode on or off
+func (s *Switch) Set(enabled bool) {
+	s.enabled.Store(enabled)
+}
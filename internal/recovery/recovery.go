@@ -0,0 +1,94 @@
+// Package recovery records panics recovered from HTTP handlers and gRPC
+// methods: it logs them with their stack, counts them and reports them to
+// the error tracker.
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+// ErrPanic is returned for requests whose handler panicked
+var ErrPanic = apperror.New(apperror.CodeInternal, "An unexpected error occurred. Please try again later.")
+
+// Panic describes a recovered panic
+type Panic struct {
+	Value     any    // The value passed to panic
+	Stack     []byte // Stack of the panicking goroutine, from debug.Stack
+	Transport string // errorreport.TransportHTTP or errorreport.TransportGRPC
+	Route     string // Route path such as "/api/v1/users/:id", or full gRPC method
+	Method    string // HTTP method; empty for gRPC
+	UserID    string // Signed-in user; taken from the error report scope when empty
+}
+
+// Err returns the panic value as an error
+func (p Panic) Err() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return errors.New(fmt.Sprint(p.Value))
+}
+
+// Recorder logs, counts and reports recovered panics
+type Recorder struct {
+	logger    *zap.Logger
+	recovered *prometheus.CounterVec
+	reporter  errorreport.Reporter // nil when errors are not reported
+}
+
+// NewRecorder creates a Recorder and registers its metric. reporter may be nil.
+func NewRecorder(logger *zap.Logger, registerer prometheus.Registerer, reporter errorreport.Reporter) (*Recorder, error) {
+	r := &Recorder{
+		logger: logger,
+		recovered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "panics_recovered_total",
+			Help: "Total number of panics recovered from HTTP handlers and gRPC methods.",
+		}, []string{"transport", "route"}),
+		reporter: reporter,
+	}
+	if err := registerer.Register(r.recovered); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record logs p with its stack, counts it and reports it. The request ID,
+// and the user unless p names one, are taken from ctx.
+func (r *Recorder) Record(ctx context.Context, p Panic) {
+	requestID, _ := requestid.FromContext(ctx)
+	if p.UserID == "" {
+		p.UserID = errorreport.ScopeFromContext(ctx).User()
+	}
+	route := p.Route
+	if route == "" {
+		route = "unmatched"
+	}
+
+	r.logger.Error("Recovered from panic",
+		zap.String("panic", p.Err().Error()),
+		zap.String("transport", p.Transport),
+		zap.String("route", route),
+		zap.String("request_id", requestID),
+		zap.ByteString("stack", p.Stack))
+	r.recovered.WithLabelValues(p.Transport, route).Inc()
+	if r.reporter != nil {
+		r.reporter.Report(errorreport.Event{
+			Level:     errorreport.LevelFatal,
+			Err:       p.Err(),
+			Stack:     p.Stack,
+			Transport: p.Transport,
+			Route:     p.Route,
+			Method:    p.Method,
+			RequestID: requestID,
+			UserID:    p.UserID,
+		})
+	}
+}
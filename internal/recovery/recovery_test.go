@@ -0,0 +1,52 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+// reportFunc adapts a function to an errorreport.Reporter
+type reportFunc func(event errorreport.Event)
+
+func (f reportFunc) Report(event errorreport.Event) { f(event) }
+
+func TestRecorder_Record(t *testing.T) {
+	var reported []errorreport.Event
+	recorder, err := NewRecorder(zap.NewNop(), prometheus.NewRegistry(), reportFunc(func(event errorreport.Event) { reported = append(reported, event) }))
+	require.NoError(t, err)
+
+	ctx := requestid.NewContext(context.Background(), "req-1")
+	recorder.Record(ctx, Panic{Value: "boom", Transport: errorreport.TransportHTTP, Route: "/api/v1/users/:id", Method: "GET", UserID: "user-1"})
+	ctx, scope := errorreport.NewScope(context.Background())
+	scope.SetUser("user-2")
+	recorder.Record(ctx, Panic{Value: errors.New("nil map"), Transport: errorreport.TransportGRPC})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.recovered.WithLabelValues(errorreport.TransportHTTP, "/api/v1/users/:id")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recorder.recovered.WithLabelValues(errorreport.TransportGRPC, "unmatched")))
+	require.Len(t, reported, 2)
+	assert.Equal(t, errorreport.LevelFatal, reported[0].Level)
+	assert.Equal(t, "req-1", reported[0].RequestID)
+	assert.Equal(t, "user-1", reported[0].UserID)
+	assert.EqualError(t, reported[0].Err, "boom")
+	assert.Equal(t, "user-2", reported[1].UserID)
+	assert.EqualError(t, reported[1].Err, "nil map")
+}
+
+func TestNewRecorder_WithoutReporter(t *testing.T) {
+	recorder, err := NewRecorder(zap.NewNop(), prometheus.NewRegistry(), nil)
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		recorder.Record(context.Background(), Panic{Value: "boom", Transport: errorreport.TransportHTTP})
+	})
+}
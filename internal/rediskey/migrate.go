@@ -0,0 +1,116 @@
+package rediskey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// migrateScanCount is the SCAN page size hint used while migrating
+const migrateScanCount = 500
+
+// MigrationClient is the subset of the Redis client used by Migrate.
+// *redis.Client satisfies it.
+type MigrationClient interface {
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	RenameNX(ctx context.Context, key, newkey string) *redis.BoolCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// MigrationResult counts what Migrate did with the legacy keys it found
+type MigrationResult struct {
+	Scanned   int // Legacy keys found
+	Moved     int // Renamed to their schema key
+	Discarded int // Deleted because the schema key had already been written
+	Skipped   int // Left alone because their name could not be mapped
+}
+
+// legacyLayout maps the unversioned keys written before Schema existed,
+// config.RedisKeyPrefix + kind + ":" + id, to their schema keys
+type legacyLayout struct {
+	kind   string
+	target func(s Schema, id string) (string, bool)
+}
+
+var legacyLayouts = []legacyLayout{
+	{kind: "refresh_token", target: func(s Schema, id string) (string, bool) {
+		userID, err := uuid.Parse(id)
+		return s.UserRefreshToken(userID), err == nil
+	}},
+	{kind: "user_id", target: func(s Schema, id string) (string, bool) {
+		return s.RefreshTokenOwner(id), id != "" && !strings.Contains(id, ":")
+	}},
+	{kind: "sessions", target: func(s Schema, id string) (string, bool) {
+		userID, err := uuid.Parse(id)
+		return s.UserSessions(userID), err == nil
+	}},
+}
+
+// Migrate renames the legacy auth keys to the keys of schema. Renaming keeps
+// the expiry of each key. A legacy key whose schema key already exists is
+// older than it and is deleted. With dryRun set nothing is changed and the
+// result reports what would have been done.
+//
+// RENAME requires both keys to live on the same node, so on a Redis Cluster
+// the migration must be run against each primary separately.
+func Migrate(ctx context.Context, client MigrationClient, schema Schema, dryRun bool) (*MigrationResult, error) {
+	result := &MigrationResult{}
+	for _, layout := range legacyLayouts {
+		if err := migrateLayout(ctx, client, schema, layout, dryRun, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// migrateLayout migrates every legacy key of one kind
+func migrateLayout(ctx context.Context, client MigrationClient, schema Schema, layout legacyLayout, dryRun bool, result *MigrationResult) error {
+	prefix := config.RedisKeyPrefix + layout.kind + ":"
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, prefix+"*", migrateScanCount).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan %s keys: %w", layout.kind, err)
+		}
+
+		for _, key := range keys {
+			result.Scanned++
+			target, ok := layout.target(schema, strings.TrimPrefix(key, prefix))
+			if !ok {
+				result.Skipped++
+				continue
+			}
+			if dryRun {
+				result.Moved++
+				continue
+			}
+
+			moved, err := client.RenameNX(ctx, key, target).Result()
+			if err != nil && strings.Contains(err.Error(), "no such key") {
+				// Expired or migrated concurrently since the scan
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to rename %s key: %w", layout.kind, err)
+			}
+			if moved {
+				result.Moved++
+				continue
+			}
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return fmt.Errorf("failed to delete %s key: %w", layout.kind, err)
+			}
+			result.Discarded++
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
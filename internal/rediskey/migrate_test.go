@@ -0,0 +1,117 @@
+package rediskey
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-memory MigrationClient. SCAN returns one key per page
+// so that cursors are exercised; like Redis, it returns every key that
+// exists for the whole iteration even when others are renamed meanwhile.
+type fakeRedis struct {
+	keys     map[string]string
+	scanErr  error
+	snapshot []string // Keys matched when the current iteration started
+}
+
+func (f *fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	if f.scanErr != nil {
+		return redis.NewScanCmdResult(nil, 0, f.scanErr)
+	}
+	if cursor == 0 {
+		f.snapshot = nil
+		for key := range f.keys {
+			if strings.HasPrefix(key, strings.TrimSuffix(match, "*")) {
+				f.snapshot = append(f.snapshot, key)
+			}
+		}
+		sort.Strings(f.snapshot)
+	}
+	if int(cursor) >= len(f.snapshot) {
+		return redis.NewScanCmdResult(nil, 0, nil)
+	}
+
+	next := cursor + 1
+	if int(next) >= len(f.snapshot) {
+		next = 0
+	}
+	return redis.NewScanCmdResult(f.snapshot[cursor:cursor+1], next, nil)
+}
+
+func (f *fakeRedis) RenameNX(ctx context.Context, key, newkey string) *redis.BoolCmd {
+	value, ok := f.keys[key]
+	if !ok {
+		return redis.NewBoolResult(false, errors.New("ERR no such key"))
+	}
+	if _, exists := f.keys[newkey]; exists {
+		return redis.NewBoolResult(false, nil)
+	}
+	delete(f.keys, key)
+	f.keys[newkey] = value
+	return redis.NewBoolResult(true, nil)
+}
+
+func (f *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	for _, key := range keys {
+		delete(f.keys, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	userID := "22222222-2222-2222-2222-222222222222"
+	schema, err := New("prod")
+	require.NoError(t, err)
+
+	legacy := func() map[string]string {
+		return map[string]string{
+			"go-user-service:refresh_token:" + userID:                   "token",
+			"go-user-service:user_id:token":                             userID,
+			"go-user-service:sessions:" + userID:                        "sessions",
+			"go-user-service:sessions:not-a-uuid":                       "junk",
+			"go-user-service:prod:auth:v1:user:" + userID + ":sessions": "newer sessions",
+		}
+	}
+
+	t.Run("Migrates Legacy Keys", func(t *testing.T) {
+		client := &fakeRedis{keys: legacy()}
+
+		result, err := Migrate(ctx, client, schema, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, &MigrationResult{Scanned: 4, Moved: 2, Discarded: 1, Skipped: 1}, result)
+		assert.Equal(t, map[string]string{
+			"go-user-service:prod:auth:v1:user:" + userID + ":refresh":  "token",
+			"go-user-service:prod:auth:v1:refresh:token:user":           userID,
+			"go-user-service:prod:auth:v1:user:" + userID + ":sessions": "newer sessions",
+			"go-user-service:sessions:not-a-uuid":                       "junk",
+		}, client.keys)
+	})
+
+	t.Run("Dry Run", func(t *testing.T) {
+		client := &fakeRedis{keys: legacy()}
+
+		result, err := Migrate(ctx, client, schema, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, &MigrationResult{Scanned: 4, Moved: 3, Skipped: 1}, result)
+		assert.Equal(t, legacy(), client.keys)
+	})
+
+	t.Run("Scan Error", func(t *testing.T) {
+		client := &fakeRedis{keys: legacy(), scanErr: errors.New("connection refused")}
+
+		_, err := Migrate(ctx, client, schema, false)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to scan refresh_token keys")
+	})
+}
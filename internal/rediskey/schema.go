@@ -0,0 +1,144 @@
+// Package rediskey builds the name of every key the service keeps in Redis.
+//
+// Keys have the form
+//
+//	go-user-service:<prefix>:<domain>:<version>:<entity>:<id>:<field>
+//
+// where prefix names the deployment (environment and, optionally, tenant) so
+// several deployments can share a Redis cluster, and version changes whenever
+// the layout or encoding of a key does.
+package rediskey
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// Current versions of the key layout of each domain
+const (
+	AuthVersion   = "v1"
+	UsersVersion  = "v1"
+	JobsVersion   = "v1"
+	EventsVersion = "v1"
+)
+
+// Schema builds Redis keys for one deployment
+type Schema struct {
+	prefix string // Always ends with ":"
+}
+
+// New creates a schema for the deployment named by prefix, such as "prod" or
+// "prod:acme". An empty prefix leaves only the service name. Glob
+// metacharacters and whitespace are rejected so keys can be matched by SCAN.
+func New(prefix string) (Schema, error) {
+	prefix = strings.Trim(prefix, ":")
+	if strings.ContainsAny(prefix, "*?[]\\ \t\r\n") {
+		return Schema{}, fmt.Errorf("invalid redis key prefix %q", prefix)
+	}
+	if prefix == "" {
+		return Schema{prefix: config.RedisKeyPrefix}, nil
+	}
+	return Schema{prefix: config.RedisKeyPrefix + prefix + ":"}, nil
+}
+
+// Prefix returns the part shared by every key of the deployment
+func (s Schema) Prefix() string {
+	return s.prefix
+}
+
+// UserRefreshToken is the key holding the current refresh token of a user
+func (s Schema) UserRefreshToken(userID uuid.UUID) string {
+	return s.auth("user", userID.String(), "refresh")
+}
+
+// RefreshTokenOwner is the key holding the ID of the user a refresh token belongs to
+func (s Schema) RefreshTokenOwner(token string) string {
+	return s.auth("refresh", token, "user")
+}
+
+// AllRefreshTokenOwners matches the owner key of every refresh token, for SCAN
+func (s Schema) AllRefreshTokenOwners() string {
+	return s.auth("refresh", "*", "user")
+}
+
+// RefreshTokenOfOwner returns the refresh token named by a key built by
+// RefreshTokenOwner, reporting false for any other key
+func (s Schema) RefreshTokenOfOwner(key string) (string, bool) {
+	prefix := s.prefix + "auth:" + AuthVersion + ":refresh:"
+	const suffix = ":user"
+	if len(key) <= len(prefix)+len(suffix) || !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, suffix) {
+		return "", false
+	}
+	return key[len(prefix) : len(key)-len(suffix)], true
+}
+
+// UserSessions is the hash holding the sign-in sessions of a user
+func (s Schema) UserSessions(userID uuid.UUID) string {
+	return s.auth("user", userID.String(), "sessions")
+}
+
+// AllUserSessions matches the session hash of every user, for SCAN
+func (s Schema) AllUserSessions() string {
+	return s.auth("user", "*", "sessions")
+}
+
+// SigningKeys is the hash holding the access token signing keys of an
+// algorithm generated by rotation, keyed by key ID
+func (s Schema) SigningKeys(algorithm string) string {
+	return s.auth("signing", algorithm, "keys")
+}
+
+// LoginFailures is the counter of failed sign-in attempts made from a client
+// IP or against an account, named by scope ("ip" or "account")
+func (s Schema) LoginFailures(scope, id string) string {
+	return s.auth(scope, id, "login_failures")
+}
+
+// SAMLAssertion is the key marking an assertion of the identity provider of
+// a tenant as used, until the assertion expires
+func (s Schema) SAMLAssertion(tenant, assertionID string) string {
+	return s.auth("saml", tenant+"/"+assertionID, "used")
+}
+
+// User is the key caching the record of a user
+func (s Schema) User(userID uuid.UUID) string {
+	return s.users("user", userID.String(), "record")
+}
+
+// UserIDByEmail is the key caching the ID of the user with a normalized email address
+func (s Schema) UserIDByEmail(email string) string {
+	return s.users("email", email, "id")
+}
+
+// JobQueue is the key holding the jobs of a queue in one state, such as
+// "pending" or "scheduled". The queue name is a hash tag so every key of a
+// queue lives in the same Redis Cluster slot.
+func (s Schema) JobQueue(queue, state string) string {
+	return s.prefix + "jobs:" + JobsVersion + ":queue:{" + queue + "}:" + state
+}
+
+// JobSchedule is the key claimed by the instance enqueueing the scheduled
+// job of the given type for the current interval
+func (s Schema) JobSchedule(jobType string) string {
+	return s.prefix + "jobs:" + JobsVersion + ":schedule:" + jobType + ":claim"
+}
+
+// UserEvents is the Pub/Sub channel carrying the events of every user, so
+// that each instance can hand them to the clients connected to it
+func (s Schema) UserEvents() string {
+	return s.prefix + "events:" + EventsVersion + ":users"
+}
+
+// users builds a key in the users domain
+func (s Schema) users(entity, id, field string) string {
+	return s.prefix + "users:" + UsersVersion + ":" + entity + ":" + id + ":" + field
+}
+
+// auth builds a key in the auth domain
+func (s Schema) auth(entity, id, field string) string {
+	return s.prefix + "auth:" + AuthVersion + ":" + entity + ":" + id + ":" + field
+}
@@ -0,0 +1,71 @@
+package rediskey
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	userID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	tests := []struct {
+		name           string
+		prefix         string
+		expectedPrefix string
+	}{
+		{name: "No Prefix", prefix: "", expectedPrefix: "go-user-service:"},
+		{name: "Environment", prefix: "prod", expectedPrefix: "go-user-service:prod:"},
+		{name: "Environment And Tenant", prefix: "prod:acme", expectedPrefix: "go-user-service:prod:acme:"},
+		{name: "Surrounding Colons", prefix: ":prod:", expectedPrefix: "go-user-service:prod:"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := New(tc.prefix)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedPrefix, schema.Prefix())
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:user:22222222-2222-2222-2222-222222222222:refresh", schema.UserRefreshToken(userID))
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:refresh:token:user", schema.RefreshTokenOwner("token"))
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:user:22222222-2222-2222-2222-222222222222:sessions", schema.UserSessions(userID))
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:user:*:sessions", schema.AllUserSessions())
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:refresh:*:user", schema.AllRefreshTokenOwners())
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:signing:HS256:keys", schema.SigningKeys("HS256"))
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:ip:203.0.113.7:login_failures", schema.LoginFailures("ip", "203.0.113.7"))
+			assert.Equal(t, tc.expectedPrefix+"auth:v1:saml:acme/_a1b2:used", schema.SAMLAssertion("acme", "_a1b2"))
+			assert.Equal(t, tc.expectedPrefix+"users:v1:user:22222222-2222-2222-2222-222222222222:record", schema.User(userID))
+			assert.Equal(t, tc.expectedPrefix+"users:v1:email:ada@example.com:id", schema.UserIDByEmail("ada@example.com"))
+			assert.Equal(t, tc.expectedPrefix+"jobs:v1:queue:{default}:pending", schema.JobQueue("default", "pending"))
+			assert.Equal(t, tc.expectedPrefix+"jobs:v1:schedule:sessions.cleanup:claim", schema.JobSchedule("sessions.cleanup"))
+			assert.Equal(t, tc.expectedPrefix+"events:v1:users", schema.UserEvents())
+		})
+	}
+}
+
+func TestRefreshTokenOfOwner(t *testing.T) {
+	schema, err := New("prod")
+	require.NoError(t, err)
+
+	token, ok := schema.RefreshTokenOfOwner(schema.RefreshTokenOwner("a.b:c"))
+	assert.True(t, ok)
+	assert.Equal(t, "a.b:c", token)
+
+	for _, key := range []string{
+		schema.UserSessions(uuid.New()),
+		"go-user-service:staging:auth:v1:refresh:token:user",
+		schema.RefreshTokenOwner(""),
+	} {
+		_, ok := schema.RefreshTokenOfOwner(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestNew_InvalidPrefix(t *testing.T) {
+	for _, prefix := range []string{"prod*", "prod?", "[prod]", "prod acme"} {
+		_, err := New(prefix)
+		assert.Error(t, err, prefix)
+	}
+}
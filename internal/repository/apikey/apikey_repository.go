@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// KeyModel represents the API key structure for database interactions.
+// Scopes are stored comma-separated.
+type KeyModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Tenant     string    `gorm:"size:64;not null;index"`
+	Name       string    `gorm:"size:100;not null"`
+	Prefix     string    `gorm:"size:16;not null"`
+	SecretHash string    `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     string    `gorm:"not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	RotatedTo  *uuid.UUID `gorm:"type:uuid"`
+	CreatedBy  uuid.UUID  `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the KeyModel.
+func (KeyModel) TableName() string {
+	return "api_keys"
+}
+
+// toDomain converts a KeyModel to a domainAPIKey.APIKey.
+func toDomain(m *KeyModel) *domainAPIKey.APIKey {
+	var scopes []domainAPIKey.Scope
+	if m.Scopes != "" {
+		for _, s := range strings.Split(m.Scopes, ",") {
+			scopes = append(scopes, domainAPIKey.Scope(s))
+		}
+	}
+	return &domainAPIKey.APIKey{
+		ID:         m.ID,
+		Tenant:     m.Tenant,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		SecretHash: m.SecretHash,
+		Scopes:     scopes,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		RevokedAt:  m.RevokedAt,
+		RotatedTo:  m.RotatedTo,
+		CreatedBy:  m.CreatedBy,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// fromDomain converts a domainAPIKey.APIKey to a KeyModel.
+func fromDomain(k *domainAPIKey.APIKey) *KeyModel {
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	return &KeyModel{
+		ID:         k.ID,
+		Tenant:     k.Tenant,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		SecretHash: k.SecretHash,
+		Scopes:     strings.Join(scopes, ","),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		RotatedTo:  k.RotatedTo,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new instance of domainAPIKey.Repository.
+func NewAPIKeyRepository(db *gorm.DB) domainAPIKey.Repository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domainAPIKey.APIKey) error {
+	return transaction.DB(ctx, r.db).Create(fromDomain(key)).Error
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainAPIKey.APIKey, error) {
+	return r.first(transaction.DB(ctx, r.db).Where("id = ?", id))
+}
+
+func (r *apiKeyRepository) GetBySecretHash(ctx context.Context, hash string) (*domainAPIKey.APIKey, error) {
+	return r.first(transaction.DB(ctx, r.db).Where("secret_hash = ?", hash))
+}
+
+func (r *apiKeyRepository) ListByTenant(ctx context.Context, tenant string) ([]*domainAPIKey.APIKey, error) {
+	var models []KeyModel
+	err := transaction.DB(ctx, r.db).
+		Where("tenant = ?", tenant).
+		Order("created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domainAPIKey.APIKey, 0, len(models))
+	for i := range models {
+		keys = append(keys, toDomain(&models[i]))
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *domainAPIKey.APIKey) error {
+	return transaction.DB(ctx, r.db).Save(fromDomain(key)).Error
+}
+
+func (r *apiKeyRepository) Rotate(ctx context.Context, old, replacement *domainAPIKey.APIKey) error {
+	return transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(fromDomain(replacement)).Error; err != nil {
+			return err
+		}
+		return tx.Save(fromDomain(old)).Error
+	})
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return transaction.DB(ctx, r.db).Model(&KeyModel{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// first returns the first key matched by query, or nil when there is none
+func (r *apiKeyRepository) first(query *gorm.DB) (*domainAPIKey.APIKey, error) {
+	var model KeyModel
+	if err := query.First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Key not found
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// EntryModel represents the audit log structure for database interactions.
+type EntryModel struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	ActorID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Action    string     `gorm:"size:64;not null"`
+	TargetID  *uuid.UUID `gorm:"type:uuid;index"`
+	Details   string     `gorm:"not null"`
+	ClientIP  string     `gorm:"size:64;not null;default:''"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for the EntryModel.
+func (EntryModel) TableName() string {
+	return "audit_logs"
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new instance of domainAudit.Repository.
+func NewAuditRepository(db *gorm.DB) domainAudit.Repository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *domainAudit.Entry) error {
+	model := &EntryModel{
+		ID:        entry.ID,
+		ActorID:   entry.ActorID,
+		Action:    string(entry.Action),
+		Details:   entry.Details,
+		ClientIP:  entry.ClientIP,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.TargetID != uuid.Nil {
+		target := entry.TargetID
+		model.TargetID = &target
+	}
+	return transaction.DB(ctx, r.db).Create(model).Error
+}
+
+func (r *auditRepository) List(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	query := transaction.DB(ctx, r.db).Model(&EntryModel{})
+	if filter.ID != uuid.Nil {
+		query = query.Where("id = ?", filter.ID)
+	}
+	if filter.ActorID != uuid.Nil {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetID != uuid.Nil {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", string(filter.Action))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []EntryModel
+	err := query.
+		Order("created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]*domainAudit.Entry, 0, len(models))
+	for _, m := range models {
+		entry := &domainAudit.Entry{
+			ID:        m.ID,
+			ActorID:   m.ActorID,
+			Action:    domainAudit.Action(m.Action),
+			Details:   m.Details,
+			ClientIP:  m.ClientIP,
+			CreatedAt: m.CreatedAt,
+		}
+		if m.TargetID != nil {
+			entry.TargetID = *m.TargetID
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, nil
+}
+
+func (r *auditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := transaction.DB(ctx, r.db).Where("created_at < ?", cutoff).Delete(&EntryModel{})
+	return result.RowsAffected, result.Error
+}
@@ -7,23 +7,28 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
-	"github.com/yi-tech/go-user-service/internal/config"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
 )
 
 // authRepository struct implements the domainAuth.AuthRepository interface
 type AuthRepositoryImpl struct {
 	redisClient *redis.Client
+	keys        rediskey.Schema
+	retry       RetryPolicy
 }
 
-// NewAuthRepository creates a new instance of AuthRepository.
-func NewAuthRepository(redisClient *redis.Client) domainAuth.AuthRepository { // Return type changed to domain interface
-	return &AuthRepositoryImpl{redisClient: redisClient}
+// NewAuthRepository creates a new instance of AuthRepository. Commands are
+// retried according to retry while Redis fails over.
+func NewAuthRepository(redisClient *redis.Client, keys rediskey.Schema, retry RetryPolicy) domainAuth.AuthRepository { // Return type changed to domain interface
+	return &AuthRepositoryImpl{redisClient: redisClient, keys: keys, retry: retry}
 }
 
 func (r *AuthRepositoryImpl) SetUserRefreshToken(ctx context.Context, userID uuid.UUID, token string, expiration time.Duration) error { // userID type changed
-	key := fmt.Sprintf(config.RedisKeyPrefix+"refresh_token:%s", userID.String()) // key formatting changed
-	err := r.redisClient.Set(ctx, key, token, expiration).Err()
+	key := r.keys.UserRefreshToken(userID)
+	err := r.retry.do(ctx, func() error {
+		return r.redisClient.Set(ctx, key, token, expiration).Err()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set refresh token in redis: %w", err)
 	}
@@ -31,8 +36,12 @@ func (r *AuthRepositoryImpl) SetUserRefreshToken(ctx context.Context, userID uui
 }
 
 func (r *AuthRepositoryImpl) GetUserRefreshToken(ctx context.Context, userID uuid.UUID) (string, error) { // userID type changed
-	key := fmt.Sprintf(config.RedisKeyPrefix+"refresh_token:%s", userID.String()) // key formatting changed
-	token, err := r.redisClient.Get(ctx, key).Result()
+	key := r.keys.UserRefreshToken(userID)
+	var token string
+	err := r.retry.do(ctx, func() (err error) {
+		token, err = r.redisClient.Get(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		if err == redis.Nil {
 			return "", nil // Token not found, service layer should handle this
@@ -43,8 +52,10 @@ func (r *AuthRepositoryImpl) GetUserRefreshToken(ctx context.Context, userID uui
 }
 
 func (r *AuthRepositoryImpl) DeleteUserRefreshToken(ctx context.Context, userID uuid.UUID) error { // userID type changed
-	key := fmt.Sprintf(config.RedisKeyPrefix+"refresh_token:%s", userID.String()) // key formatting changed
-	err := r.redisClient.Del(ctx, key).Err()
+	key := r.keys.UserRefreshToken(userID)
+	err := r.retry.do(ctx, func() error {
+		return r.redisClient.Del(ctx, key).Err()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete refresh token from redis: %w", err)
 	}
@@ -52,8 +63,10 @@ func (r *AuthRepositoryImpl) DeleteUserRefreshToken(ctx context.Context, userID
 }
 
 func (r *AuthRepositoryImpl) SetRefreshTokenUserID(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error { // userID type changed
-	key := fmt.Sprintf(config.RedisKeyPrefix+"user_id:%s", token)
-	err := r.redisClient.Set(ctx, key, userID.String(), expiration).Err() // Store userID.String()
+	key := r.keys.RefreshTokenOwner(token)
+	err := r.retry.do(ctx, func() error {
+		return r.redisClient.Set(ctx, key, userID.String(), expiration).Err() // Store userID.String()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set user ID by refresh token in redis: %w", err)
 	}
@@ -61,8 +74,12 @@ func (r *AuthRepositoryImpl) SetRefreshTokenUserID(ctx context.Context, token st
 }
 
 func (r *AuthRepositoryImpl) GetUserIDByRefreshToken(ctx context.Context, token string) (uuid.UUID, error) { // return type and userID type changed
-	key := fmt.Sprintf(config.RedisKeyPrefix+"user_id:%s", token)
-	userIDStr, err := r.redisClient.Get(ctx, key).Result()
+	key := r.keys.RefreshTokenOwner(token)
+	var userIDStr string
+	err := r.retry.do(ctx, func() (err error) {
+		userIDStr, err = r.redisClient.Get(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		if err == redis.Nil {
 			return uuid.Nil, nil // User ID not found, service layer should handle this
@@ -78,10 +95,107 @@ func (r *AuthRepositoryImpl) GetUserIDByRefreshToken(ctx context.Context, token
 }
 
 func (r *AuthRepositoryImpl) DeleteRefreshTokenUserID(ctx context.Context, token string) error {
-	key := fmt.Sprintf(config.RedisKeyPrefix+"user_id:%s", token)
-	err := r.redisClient.Del(ctx, key).Err()
+	key := r.keys.RefreshTokenOwner(token)
+	err := r.retry.do(ctx, func() error {
+		return r.redisClient.Del(ctx, key).Err()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete user ID by refresh token from redis: %w", err)
 	}
 	return nil
 }
+
+// PruneOrphanedRefreshTokens walks the owner key of every refresh token with
+// SCAN. A mapping is orphaned when neither the current token of its user nor
+// a live session of the user holds the token, e.g. after signing out or when
+// a rotation failed part way; it would otherwise linger until its TTL runs
+// out. Tokens of other devices stay as long as their session does. Mappings
+// holding an unparsable user ID are removed too since
+// GetUserIDByRefreshToken cannot return them either.
+func (r *AuthRepositoryImpl) PruneOrphanedRefreshTokens(ctx context.Context) (int64, error) {
+	var pruned int64
+	var cursor uint64
+	for {
+		var keys []string
+		err := r.retry.do(ctx, func() (err error) {
+			keys, cursor, err = r.redisClient.Scan(ctx, cursor, r.keys.AllRefreshTokenOwners(), scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("failed to scan refresh tokens in redis: %w", err)
+		}
+
+		for _, key := range keys {
+			orphaned, err := r.orphaned(ctx, key)
+			if err != nil {
+				return pruned, err
+			}
+			if !orphaned {
+				continue
+			}
+			var deleted int64
+			err = r.retry.do(ctx, func() (err error) {
+				deleted, err = r.redisClient.Del(ctx, key).Result()
+				return err
+			})
+			if err != nil {
+				return pruned, fmt.Errorf("failed to delete orphaned refresh token from redis: %w", err)
+			}
+			pruned += deleted
+		}
+
+		if cursor == 0 {
+			return pruned, nil
+		}
+	}
+}
+
+// orphaned reports whether the refresh token owner key is no longer backed
+// by the current refresh token or a live session of its user
+func (r *AuthRepositoryImpl) orphaned(ctx context.Context, key string) (bool, error) {
+	token, ok := r.keys.RefreshTokenOfOwner(key)
+	if !ok {
+		return false, nil
+	}
+
+	var owner string
+	err := r.retry.do(ctx, func() (err error) {
+		owner, err = r.redisClient.Get(ctx, key).Result()
+		return err
+	})
+	if err == redis.Nil {
+		return false, nil // Expired since the scan
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user ID by refresh token from redis: %w", err)
+	}
+	userID, err := uuid.Parse(owner)
+	if err != nil {
+		return true, nil
+	}
+
+	current, err := r.GetUserRefreshToken(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if current == token {
+		return false, nil
+	}
+
+	var values map[string]string
+	err = r.retry.do(ctx, func() (err error) {
+		values, err = r.redisClient.HGetAll(ctx, r.keys.UserSessions(userID)).Result()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read sessions from redis: %w", err)
+	}
+	sessions := make([]*domainAuth.Session, 0, len(values))
+	for _, value := range values {
+		// Sessions that fail to decode are pruned with the expired ones
+		if session, err := decodeSession(value); err == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return !sessionsHold(sessions, token), nil
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// RetryPolicy controls how Redis commands are retried while the server fails
+// over, e.g. while a replica is promoted or a cluster slot moves
+type RetryPolicy struct {
+	Attempts int           // Tries per command, including the first; values below 1 mean 1
+	Backoff  time.Duration // Wait before the second try, doubling for each further one
+	// Breaker fails commands straight away while Redis keeps being
+	// unavailable; nil lets every command through
+	Breaker *breaker.Breaker
+}
+
+// failoverReplies are prefixes of the errors Redis replies with while the
+// node serving a key changes; the command succeeds once it is retried
+// against the new primary
+var failoverReplies = []string{"MOVED ", "ASK ", "READONLY ", "LOADING ", "MASTERDOWN ", "TRYAGAIN ", "CLUSTERDOWN "}
+
+// isFailoverError reports whether err means Redis is failing over or
+// unreachable rather than rejecting the command
+func isFailoverError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, redis.ErrClosed) {
+		return true
+	}
+	msg := err.Error()
+	for _, prefix := range failoverReplies {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "connection pool timeout")
+}
+
+// do runs fn, retrying it with exponential backoff while it fails with a
+// failover error. Once the tries are used up, or while the breaker is open,
+// the error is wrapped with domainAuth.ErrStoreUnavailable so the service
+// can degrade.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	err := p.Breaker.Do(func() error {
+		return p.retry(ctx, fn)
+	}, func(err error) bool {
+		return errors.Is(err, domainAuth.ErrStoreUnavailable)
+	})
+	if errors.Is(err, breaker.ErrOpen) {
+		return fmt.Errorf("%w: %w", domainAuth.ErrStoreUnavailable, err)
+	}
+	return err
+}
+
+// retry runs fn until it succeeds, fails with another than a failover error
+// or the tries are used up
+func (p RetryPolicy) retry(ctx context.Context, fn func() error) error {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !isFailoverError(err) {
+			return err
+		}
+		if attempt >= p.Attempts {
+			return fmt.Errorf("%w: %w", domainAuth.ErrStoreUnavailable, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", domainAuth.ErrStoreUnavailable, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
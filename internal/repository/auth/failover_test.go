@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+func TestIsFailoverError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil, expected: false},
+		{err: redis.Nil, expected: false},
+		{err: context.Canceled, expected: false},
+		{err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), expected: false},
+		{err: errors.New("MOVED 3999 127.0.0.1:6381"), expected: true},
+		{err: errors.New("READONLY You can't write against a read only replica."), expected: true},
+		{err: errors.New("LOADING Redis is loading the dataset in memory"), expected: true},
+		{err: errors.New("dial tcp 127.0.0.1:6379: connect: connection refused"), expected: true},
+		{err: io.EOF, expected: true},
+		{err: redis.ErrClosed, expected: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isFailoverError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+	moved := errors.New("MOVED 3999 127.0.0.1:6381")
+
+	t.Run("Retries Until New Primary Answers", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, func() error {
+			calls++
+			if calls < 3 {
+				return moved
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives Up As Unavailable", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, func() error { calls++; return moved })
+		assert.ErrorIs(t, err, domainAuth.ErrStoreUnavailable)
+		assert.ErrorIs(t, err, moved)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Other Errors Are Not Retried", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, func() error { calls++; return redis.Nil })
+		assert.Equal(t, redis.Nil, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Zero Policy Tries Once", func(t *testing.T) {
+		calls := 0
+		err := RetryPolicy{}.do(ctx, func() error { calls++; return moved })
+		assert.ErrorIs(t, err, domainAuth.ErrStoreUnavailable)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Stops When Context Is Done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		calls := 0
+		err := RetryPolicy{Attempts: 5, Backoff: time.Hour}.do(cancelled, func() error { calls++; return moved })
+		assert.ErrorIs(t, err, domainAuth.ErrStoreUnavailable)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("Open Breaker Fails Fast", func(t *testing.T) {
+		group := breaker.NewGroup(true, breaker.Options{FailureThreshold: 1, OpenTimeout: time.Hour}, nil, zap.NewNop())
+		guarded := RetryPolicy{Attempts: 1, Breaker: group.Breaker("redis")}
+		calls := 0
+		assert.ErrorIs(t, guarded.do(ctx, func() error { calls++; return moved }), domainAuth.ErrStoreUnavailable)
+
+		err := guarded.do(ctx, func() error { calls++; return nil })
+		assert.ErrorIs(t, err, domainAuth.ErrStoreUnavailable)
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// KeyInspectorImpl implements domainAuth.KeyInspector over the keys written
+// by the auth and session repositories
+type KeyInspectorImpl struct {
+	redisClient *redis.Client
+	keys        rediskey.Schema
+}
+
+// NewKeyInspector creates a new instance of KeyInspector.
+func NewKeyInspector(redisClient *redis.Client, keys rediskey.Schema) domainAuth.KeyInspector {
+	return &KeyInspectorImpl{redisClient: redisClient, keys: keys}
+}
+
+func (r *KeyInspectorImpl) InspectUserKeys(ctx context.Context, userID uuid.UUID) ([]*domainAuth.KeyInfo, error) {
+	refresh, err := r.inspect(ctx, r.keys.UserRefreshToken(userID), domainAuth.KeyNamespaceRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*domainAuth.KeyInfo{refresh}
+
+	// The owner key is named after the refresh token, so it is only reachable
+	// while the user has one
+	if token := refresh.Value; token != "" {
+		refresh.Value = fingerprint(token)
+
+		owner, err := r.inspect(ctx, r.keys.RefreshTokenOwner(token), domainAuth.KeyNamespaceTokenOwner)
+		if err != nil {
+			return nil, err
+		}
+		owner.Key = r.keys.RefreshTokenOwner(fingerprint(token))
+		keys = append(keys, owner)
+	}
+
+	sessions, err := r.inspect(ctx, r.keys.UserSessions(userID), domainAuth.KeyNamespaceSessions)
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, sessions), nil
+}
+
+// inspect reads the type, expiry and content of a key
+func (r *KeyInspectorImpl) inspect(ctx context.Context, key, namespace string) (*domainAuth.KeyInfo, error) {
+	pipe := r.redisClient.Pipeline()
+	keyType := pipe.Type(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to inspect %s key in redis: %w", namespace, err)
+	}
+
+	info := &domainAuth.KeyInfo{
+		Key:       key,
+		Namespace: namespace,
+		Type:      keyType.Val(),
+		TTL:       ttl.Val(),
+	}
+
+	var err error
+	switch info.Type {
+	case "string":
+		info.Value, err = r.redisClient.Get(ctx, key).Result()
+	case "hash":
+		info.Fields, err = r.redisClient.HGetAll(ctx, key).Result()
+	}
+	// The key may have expired between the calls
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read %s key from redis: %w", namespace, err)
+	}
+	return info, nil
+}
+
+// fingerprint identifies a secret without revealing it
+func fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// Scopes of the failed sign-in counters
+const (
+	loginScopeIP      = "ip"
+	loginScopeAccount = "account"
+)
+
+// LoginAttemptRepositoryImpl implements domainAuth.LoginAttemptRepository
+// with one Redis counter per client IP and per account
+type LoginAttemptRepositoryImpl struct {
+	redisClient *redis.Client
+	keys        rediskey.Schema
+}
+
+// NewLoginAttemptRepository creates a new instance of LoginAttemptRepository.
+func NewLoginAttemptRepository(redisClient *redis.Client, keys rediskey.Schema) domainAuth.LoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{redisClient: redisClient, keys: keys}
+}
+
+func (r *LoginAttemptRepositoryImpl) CountFailures(ctx context.Context, clientIP, email string) (int64, int64, error) {
+	keys := []string{r.accountKey(email)}
+	if clientIP != "" {
+		keys = append(keys, r.keys.LoginFailures(loginScopeIP, clientIP))
+	}
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get login failures from redis: %w", err)
+	}
+
+	counts := make([]int64, 2)
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue // Missing key
+		}
+		if counts[i], err = strconv.ParseInt(s, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("failed to parse login failures %q from redis: %w", s, err)
+		}
+	}
+	return counts[1], counts[0], nil
+}
+
+func (r *LoginAttemptRepositoryImpl) RecordFailure(ctx context.Context, clientIP, email string, window time.Duration) error {
+	keys := []string{r.accountKey(email)}
+	if clientIP != "" {
+		keys = append(keys, r.keys.LoginFailures(loginScopeIP, clientIP))
+	}
+	pipe := r.redisClient.TxPipeline()
+	for _, key := range keys {
+		pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, window)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record login failure in redis: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepositoryImpl) ResetAccountFailures(ctx context.Context, email string) error {
+	if err := r.redisClient.Del(ctx, r.accountKey(email)).Err(); err != nil {
+		return fmt.Errorf("failed to delete login failures from redis: %w", err)
+	}
+	return nil
+}
+
+// accountKey names the counter of an account; emails differing only in case
+// share it so the threshold cannot be sidestepped
+func (r *LoginAttemptRepositoryImpl) accountKey(email string) string {
+	return r.keys.LoginFailures(loginScopeAccount, strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+func init() {
+	RegisterDriver("memory", func(Backends) (Store, error) {
+		sessions := NewMemorySessionRepository()
+		return Store{Tokens: NewMemoryAuthRepository(sessions), Sessions: sessions, SigningKeys: NewMemorySigningKeyRepository()}, nil
+	})
+}
+
+// memoryEntry is a value held in memory until it expires
+type memoryEntry[V any] struct {
+	value     V
+	expiresAt time.Time // Zero when the entry never expires
+}
+
+// live reports whether the entry has not expired by now
+func (e memoryEntry[V]) live(now time.Time) bool {
+	return e.expiresAt.IsZero() || now.Before(e.expiresAt)
+}
+
+// expiryAfter returns when an entry stored at now for expiration expires;
+// expirations of zero or less never do, as with Redis
+func expiryAfter(now time.Time, expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return now.Add(expiration)
+}
+
+// MemoryAuthRepository implements domainAuth.AuthRepository in process
+// memory. Tokens are lost on restart and not shared between instances, so it
+// suits tests and single-instance deployments without Redis.
+type MemoryAuthRepository struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]memoryEntry[string] // Current refresh token of a user
+	owners map[string]memoryEntry[uuid.UUID] // User a refresh token belongs to
+	// sessions keeps the tokens of other devices from being pruned; nil when
+	// only the current token of a user is kept
+	sessions *MemorySessionRepository
+	now      func() time.Time
+}
+
+// NewMemoryAuthRepository creates an empty MemoryAuthRepository that prunes
+// the tokens no session of sessions holds. sessions may be nil.
+func NewMemoryAuthRepository(sessions *MemorySessionRepository) *MemoryAuthRepository {
+	return &MemoryAuthRepository{
+		tokens:   map[uuid.UUID]memoryEntry[string]{},
+		owners:   map[string]memoryEntry[uuid.UUID]{},
+		sessions: sessions,
+		now:      time.Now,
+	}
+}
+
+func (r *MemoryAuthRepository) SetUserRefreshToken(ctx context.Context, userID uuid.UUID, token string, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[userID] = memoryEntry[string]{value: token, expiresAt: expiryAfter(r.now(), expiration)}
+	return nil
+}
+
+func (r *MemoryAuthRepository) GetUserRefreshToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.tokens[userID]
+	if !ok || !entry.live(r.now()) {
+		return "", nil
+	}
+	return entry.value, nil
+}
+
+func (r *MemoryAuthRepository) DeleteUserRefreshToken(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, userID)
+	return nil
+}
+
+func (r *MemoryAuthRepository) SetRefreshTokenUserID(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.owners[token] = memoryEntry[uuid.UUID]{value: userID, expiresAt: expiryAfter(r.now(), expiration)}
+	return nil
+}
+
+func (r *MemoryAuthRepository) GetUserIDByRefreshToken(ctx context.Context, token string) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.owners[token]
+	if !ok || !entry.live(r.now()) {
+		return uuid.Nil, nil
+	}
+	return entry.value, nil
+}
+
+func (r *MemoryAuthRepository) DeleteRefreshTokenUserID(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.owners, token)
+	return nil
+}
+
+// PruneOrphanedRefreshTokens deletes the owner entries of tokens that are
+// neither the current token of their user nor held by a live session, along
+// with every expired entry
+func (r *MemoryAuthRepository) PruneOrphanedRefreshTokens(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	var pruned int64
+	for userID, entry := range r.tokens {
+		if !entry.live(now) {
+			delete(r.tokens, userID)
+			pruned++
+		}
+	}
+	for token, entry := range r.owners {
+		if entry.live(now) {
+			if current, ok := r.tokens[entry.value]; ok && current.value == token {
+				continue
+			}
+			if r.sessions != nil {
+				sessions, err := r.sessions.ListSessions(ctx, entry.value)
+				if err != nil {
+					return pruned, err
+				}
+				if sessionsHold(sessions, token) {
+					continue
+				}
+			}
+		}
+		delete(r.owners, token)
+		pruned++
+	}
+	return pruned, nil
+}
+
+// MemorySessionRepository implements domainAuth.SessionRepository in process
+// memory, with the same limits as MemoryAuthRepository
+type MemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]map[string]domainAuth.Session // Sessions of a user by ID
+}
+
+// NewMemorySessionRepository creates an empty MemorySessionRepository
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{sessions: map[uuid.UUID]map[string]domainAuth.Session{}}
+}
+
+func (r *MemorySessionRepository) SaveSession(ctx context.Context, session *domainAuth.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	userSessions, ok := r.sessions[session.UserID]
+	if !ok {
+		userSessions = map[string]domainAuth.Session{}
+		r.sessions[session.UserID] = userSessions
+	}
+	userSessions[session.ID] = *session
+	return nil
+}
+
+func (r *MemorySessionRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]*domainAuth.Session, 0, len(r.sessions[userID]))
+	for id, session := range r.sessions[userID] {
+		if session.IsExpired() {
+			delete(r.sessions[userID], id)
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (r *MemorySessionRepository) DeleteSessions(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, userID)
+	return nil
+}
+
+func (r *MemorySessionRepository) PruneExpired(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pruned int64
+	for userID, userSessions := range r.sessions {
+		for id, session := range userSessions {
+			if session.IsExpired() {
+				delete(userSessions, id)
+				pruned++
+			}
+		}
+		if len(userSessions) == 0 {
+			delete(r.sessions, userID)
+		}
+	}
+	return pruned, nil
+}
+
+// MemorySigningKeyRepository implements domainAuth.SigningKeyRepository in
+// process memory. Rotated keys are then only known to the instance that
+// generated them, until it restarts.
+type MemorySigningKeyRepository struct {
+	mu   sync.Mutex
+	keys []domainAuth.SigningKey // Ordered from oldest to newest
+}
+
+// NewMemorySigningKeyRepository creates an empty MemorySigningKeyRepository
+func NewMemorySigningKeyRepository() *MemorySigningKeyRepository {
+	return &MemorySigningKeyRepository{}
+}
+
+func (r *MemorySigningKeyRepository) SaveSigningKey(ctx context.Context, key *domainAuth.SigningKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *MemorySigningKeyRepository) ListSigningKeys(ctx context.Context, algorithm string, limit int) ([]*domainAuth.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []*domainAuth.SigningKey
+	for _, key := range slices.Backward(r.keys) {
+		if len(keys) == limit {
+			break
+		}
+		if key.Algorithm == algorithm {
+			keys = append(keys, &key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	RegisterDriver("postgres", func(b Backends) (Store, error) {
+		if b.DB == nil {
+			return Store{}, errors.New("auth store driver postgres needs a database")
+		}
+		return Store{
+			Tokens:      NewPostgresAuthRepository(b.DB),
+			Sessions:    NewPostgresSessionRepository(b.DB),
+			SigningKeys: NewPostgresSigningKeyRepository(b.DB),
+		}, nil
+	})
+}
+
+// RefreshTokenModel holds the current refresh token of a user
+type RefreshTokenModel struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Token     string     `gorm:"size:255;not null"`
+	ExpiresAt *time.Time // Nil when the token never expires
+}
+
+// TableName specifies the table name for the RefreshTokenModel.
+func (RefreshTokenModel) TableName() string {
+	return "auth_refresh_tokens"
+}
+
+// RefreshTokenOwnerModel holds the user a refresh token belongs to
+type RefreshTokenOwnerModel struct {
+	Token     string     `gorm:"size:255;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ExpiresAt *time.Time // Nil when the mapping never expires
+}
+
+// TableName specifies the table name for the RefreshTokenOwnerModel.
+func (RefreshTokenOwnerModel) TableName() string {
+	return "auth_refresh_token_owners"
+}
+
+// SessionModel represents a sign-in session for database interactions
+type SessionModel struct {
+	ID           string    `gorm:"size:64;primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	RefreshToken string    `gorm:"size:255;not null"` // Empty for sessions stored before it was kept
+	Type         string    `gorm:"size:32;not null"`
+	UserAgent    string    `gorm:"size:512;not null"`
+	DeviceLabel  string    `gorm:"size:255;not null"`
+	ClientIP     string    `gorm:"size:64;not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the SessionModel.
+func (SessionModel) TableName() string {
+	return "auth_sessions"
+}
+
+// SigningKeyModel holds an access token signing key generated by rotation
+type SigningKeyModel struct {
+	ID        string    `gorm:"size:64;primaryKey"`
+	Algorithm string    `gorm:"size:16;not null;index"`
+	Material  []byte    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the SigningKeyModel.
+func (SigningKeyModel) TableName() string {
+	return "auth_signing_keys"
+}
+
+// maxUserAgentLength matches the user_agent column of auth_sessions
+const maxUserAgentLength = 512
+
+// expiresAtColumn returns the expires_at value of a row stored for
+// expiration; expirations of zero or less never expire, as with Redis
+func expiresAtColumn(expiration time.Duration) *time.Time {
+	if expiration <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().Add(expiration)
+	return &expiresAt
+}
+
+// unexpired selects the rows whose expires_at has not passed
+const unexpired = "expires_at IS NULL OR expires_at > ?"
+
+// PostgresAuthRepository implements domainAuth.AuthRepository with two
+// tables, one per mapping, for deployments without Redis
+type PostgresAuthRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAuthRepository creates a new instance of PostgresAuthRepository
+func NewPostgresAuthRepository(db *gorm.DB) *PostgresAuthRepository {
+	return &PostgresAuthRepository{db: db}
+}
+
+func (r *PostgresAuthRepository) SetUserRefreshToken(ctx context.Context, userID uuid.UUID, token string, expiration time.Duration) error {
+	model := &RefreshTokenModel{UserID: userID, Token: token, ExpiresAt: expiresAtColumn(expiration)}
+	err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to set refresh token in postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) GetUserRefreshToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	var model RefreshTokenModel
+	err := transaction.DB(ctx, r.db).Where("user_id = ?", userID).Where(unexpired, time.Now()).Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil // Token not found, service layer should handle this
+		}
+		return "", fmt.Errorf("failed to get refresh token from postgres: %w", err)
+	}
+	return model.Token, nil
+}
+
+func (r *PostgresAuthRepository) DeleteUserRefreshToken(ctx context.Context, userID uuid.UUID) error {
+	err := transaction.DB(ctx, r.db).Where("user_id = ?", userID).Delete(&RefreshTokenModel{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete refresh token from postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) SetRefreshTokenUserID(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error {
+	model := &RefreshTokenOwnerModel{Token: token, UserID: userID, ExpiresAt: expiresAtColumn(expiration)}
+	err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to set user ID by refresh token in postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresAuthRepository) GetUserIDByRefreshToken(ctx context.Context, token string) (uuid.UUID, error) {
+	var model RefreshTokenOwnerModel
+	err := transaction.DB(ctx, r.db).Where("token = ?", token).Where(unexpired, time.Now()).Take(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, nil // User ID not found, service layer should handle this
+		}
+		return uuid.Nil, fmt.Errorf("failed to get user ID by refresh token from postgres: %w", err)
+	}
+	return model.UserID, nil
+}
+
+func (r *PostgresAuthRepository) DeleteRefreshTokenUserID(ctx context.Context, token string) error {
+	err := transaction.DB(ctx, r.db).Where("token = ?", token).Delete(&RefreshTokenOwnerModel{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete user ID by refresh token from postgres: %w", err)
+	}
+	return nil
+}
+
+// PruneOrphanedRefreshTokens deletes the owner rows of tokens that are
+// neither the current token of their user nor held by a live session. Rows
+// do not expire by themselves in Postgres, so expired rows of either table
+// are deleted too. Sessions stored before their refresh token was kept hold
+// on to every token of their user.
+func (r *PostgresAuthRepository) PruneOrphanedRefreshTokens(ctx context.Context) (int64, error) {
+	now := time.Now()
+	db := transaction.DB(ctx, r.db)
+	tokens := db.Where("expires_at <= ?", now).Delete(&RefreshTokenModel{})
+	if tokens.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens from postgres: %w", tokens.Error)
+	}
+
+	owners := db.Where(`expires_at <= ? OR (NOT EXISTS (
+		SELECT 1 FROM auth_refresh_tokens t
+		WHERE t.user_id = auth_refresh_token_owners.user_id AND t.token = auth_refresh_token_owners.token
+	) AND NOT EXISTS (
+		SELECT 1 FROM auth_sessions s
+		WHERE s.user_id = auth_refresh_token_owners.user_id AND s.expires_at > ?
+			AND s.refresh_token IN (auth_refresh_token_owners.token, '')
+	))`, now, now).Delete(&RefreshTokenOwnerModel{})
+	if owners.Error != nil {
+		return tokens.RowsAffected, fmt.Errorf("failed to delete orphaned refresh tokens from postgres: %w", owners.Error)
+	}
+	return tokens.RowsAffected + owners.RowsAffected, nil
+}
+
+// PostgresSessionRepository implements domainAuth.SessionRepository with one
+// row per session, for deployments without Redis
+type PostgresSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSessionRepository creates a new instance of PostgresSessionRepository
+func NewPostgresSessionRepository(db *gorm.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (r *PostgresSessionRepository) SaveSession(ctx context.Context, session *domainAuth.Session) error {
+	userAgent := session.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	model := &SessionModel{
+		ID:           session.ID,
+		UserID:       session.UserID,
+		RefreshToken: session.RefreshToken,
+		Type:         string(session.Type),
+		UserAgent:    userAgent,
+		DeviceLabel:  session.DeviceLabel,
+		ClientIP:     session.ClientIP,
+		ExpiresAt:    session.ExpiresAt,
+		CreatedAt:    session.CreatedAt,
+	}
+	err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to save session in postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresSessionRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	var models []SessionModel
+	err := transaction.DB(ctx, r.db).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions from postgres: %w", err)
+	}
+
+	sessions := make([]*domainAuth.Session, 0, len(models))
+	for _, m := range models {
+		sessions = append(sessions, &domainAuth.Session{
+			ID:           m.ID,
+			UserID:       m.UserID,
+			RefreshToken: m.RefreshToken,
+			Type:         domainAuth.SessionType(m.Type),
+			UserAgent:    m.UserAgent,
+			DeviceLabel:  m.DeviceLabel,
+			ClientIP:     m.ClientIP,
+			ExpiresAt:    m.ExpiresAt,
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+	return sessions, nil
+}
+
+func (r *PostgresSessionRepository) DeleteSessions(ctx context.Context, userID uuid.UUID) error {
+	err := transaction.DB(ctx, r.db).Where("user_id = ?", userID).Delete(&SessionModel{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions from postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresSessionRepository) PruneExpired(ctx context.Context) (int64, error) {
+	result := transaction.DB(ctx, r.db).Where("expires_at <= ?", time.Now()).Delete(&SessionModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune sessions in postgres: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// PostgresSigningKeyRepository implements domainAuth.SigningKeyRepository
+// with one row per key
+type PostgresSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSigningKeyRepository creates a new instance of PostgresSigningKeyRepository
+func NewPostgresSigningKeyRepository(db *gorm.DB) *PostgresSigningKeyRepository {
+	return &PostgresSigningKeyRepository{db: db}
+}
+
+func (r *PostgresSigningKeyRepository) SaveSigningKey(ctx context.Context, key *domainAuth.SigningKey) error {
+	model := &SigningKeyModel{ID: key.ID, Algorithm: key.Algorithm, Material: key.Material, CreatedAt: key.CreatedAt}
+	if err := transaction.DB(ctx, r.db).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to save signing key in postgres: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresSigningKeyRepository) ListSigningKeys(ctx context.Context, algorithm string, limit int) ([]*domainAuth.SigningKey, error) {
+	var models []SigningKeyModel
+	err := transaction.DB(ctx, r.db).
+		Where("algorithm = ?", algorithm).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys from postgres: %w", err)
+	}
+
+	keys := make([]*domainAuth.SigningKey, 0, len(models))
+	for _, m := range models {
+		keys = append(keys, &domainAuth.SigningKey{ID: m.ID, Algorithm: m.Algorithm, Material: m.Material, CreatedAt: m.CreatedAt})
+	}
+	return keys, nil
+}
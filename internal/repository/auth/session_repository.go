@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// SessionRepositoryImpl implements domainAuth.SessionRepository with one
+// Redis hash per user, keyed by session ID
+type SessionRepositoryImpl struct {
+	redisClient *redis.Client
+	keys        rediskey.Schema
+	retry       RetryPolicy
+}
+
+// NewSessionRepository creates a new instance of SessionRepository. Commands
+// are retried according to retry while Redis fails over.
+func NewSessionRepository(redisClient *redis.Client, keys rediskey.Schema, retry RetryPolicy) domainAuth.SessionRepository {
+	return &SessionRepositoryImpl{redisClient: redisClient, keys: keys, retry: retry}
+}
+
+// storedSession is the encoding of a session in Redis. It keeps the refresh
+// token, which the JSON of domainAuth.Session leaves out.
+type storedSession struct {
+	domainAuth.Session
+	RefreshToken string `json:"refresh_token,omitempty"` // Empty for sessions stored before it was kept
+}
+
+// decodeSession decodes a session stored by SaveSession
+func decodeSession(value string) (*domainAuth.Session, error) {
+	var stored storedSession
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		return nil, err
+	}
+	session := stored.Session
+	session.RefreshToken = stored.RefreshToken
+	return &session, nil
+}
+
+func (r *SessionRepositoryImpl) SaveSession(ctx context.Context, session *domainAuth.Session) error {
+	data, err := json.Marshal(storedSession{Session: *session, RefreshToken: session.RefreshToken})
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	key := r.keys.UserSessions(session.UserID)
+	err = r.retry.do(ctx, func() error {
+		pipe := r.redisClient.TxPipeline()
+		pipe.HSet(ctx, key, session.ID, data)
+		// Keep the hash around as long as its newest session
+		pipe.ExpireAt(ctx, key, session.ExpiresAt)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save session in redis: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepositoryImpl) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	key := r.keys.UserSessions(userID)
+	var values map[string]string
+	err := r.retry.do(ctx, func() (err error) {
+		values, err = r.redisClient.HGetAll(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions from redis: %w", err)
+	}
+
+	sessions := make([]*domainAuth.Session, 0, len(values))
+	var expired []string
+	for id, value := range values {
+		session, err := decodeSession(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode session %s: %w", id, err)
+		}
+		if session.IsExpired() {
+			expired = append(expired, id)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	// Prune expired sessions lazily; failure only leaves stale entries behind
+	if len(expired) > 0 {
+		r.redisClient.HDel(ctx, key, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+func (r *SessionRepositoryImpl) DeleteSessions(ctx context.Context, userID uuid.UUID) error {
+	err := r.retry.do(ctx, func() error {
+		return r.redisClient.Del(ctx, r.keys.UserSessions(userID)).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions from redis: %w", err)
+	}
+	return nil
+}
+
+// scanCount is the number of keys requested per SCAN call while pruning
+// sessions and refresh tokens
+const scanCount = 100
+
+// PruneExpired walks the session hash of every user with SCAN, so Redis is
+// never blocked, and deletes the expired entries. Entries that fail to
+// decode are removed too since ListSessions cannot return them either.
+func (r *SessionRepositoryImpl) PruneExpired(ctx context.Context) (int64, error) {
+	var pruned int64
+	var cursor uint64
+	for {
+		var keys []string
+		err := r.retry.do(ctx, func() (err error) {
+			keys, cursor, err = r.redisClient.Scan(ctx, cursor, r.keys.AllUserSessions(), scanCount).Result()
+			return err
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("failed to scan sessions in redis: %w", err)
+		}
+
+		for _, key := range keys {
+			n, err := r.pruneKey(ctx, key)
+			if err != nil {
+				return pruned, err
+			}
+			pruned += n
+		}
+
+		if cursor == 0 {
+			return pruned, nil
+		}
+	}
+}
+
+// pruneKey deletes the expired sessions held in one user's hash
+func (r *SessionRepositoryImpl) pruneKey(ctx context.Context, key string) (int64, error) {
+	var values map[string]string
+	err := r.retry.do(ctx, func() (err error) {
+		values, err = r.redisClient.HGetAll(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sessions from redis: %w", err)
+	}
+
+	var expired []string
+	for id, value := range values {
+		session, err := decodeSession(value)
+		if err != nil || session.IsExpired() {
+			expired = append(expired, id)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	err = r.retry.do(ctx, func() (err error) {
+		deleted, err = r.redisClient.HDel(ctx, key, expired...).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions from redis: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// SigningKeyRepositoryImpl implements domainAuth.SigningKeyRepository with
+// one Redis hash per algorithm, keyed by key ID. Keys never expire.
+type SigningKeyRepositoryImpl struct {
+	redisClient *redis.Client
+	keys        rediskey.Schema
+	retry       RetryPolicy
+}
+
+// NewSigningKeyRepository creates a new instance of SigningKeyRepository.
+// Commands are retried according to retry while Redis fails over.
+func NewSigningKeyRepository(redisClient *redis.Client, keys rediskey.Schema, retry RetryPolicy) domainAuth.SigningKeyRepository {
+	return &SigningKeyRepositoryImpl{redisClient: redisClient, keys: keys, retry: retry}
+}
+
+// storedSigningKey is the encoding of a signing key in Redis
+type storedSigningKey struct {
+	ID        string    `json:"id"`
+	Material  []byte    `json:"material"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *SigningKeyRepositoryImpl) SaveSigningKey(ctx context.Context, key *domainAuth.SigningKey) error {
+	data, err := json.Marshal(storedSigningKey{ID: key.ID, Material: key.Material, CreatedAt: key.CreatedAt})
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	err = r.retry.do(ctx, func() error {
+		return r.redisClient.HSet(ctx, r.keys.SigningKeys(key.Algorithm), key.ID, data).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save signing key in redis: %w", err)
+	}
+	return nil
+}
+
+func (r *SigningKeyRepositoryImpl) ListSigningKeys(ctx context.Context, algorithm string, limit int) ([]*domainAuth.SigningKey, error) {
+	var values map[string]string
+	err := r.retry.do(ctx, func() (err error) {
+		values, err = r.redisClient.HGetAll(ctx, r.keys.SigningKeys(algorithm)).Result()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys from redis: %w", err)
+	}
+
+	keys := make([]*domainAuth.SigningKey, 0, len(values))
+	for id, value := range values {
+		var stored storedSigningKey
+		if err := json.Unmarshal([]byte(value), &stored); err != nil {
+			return nil, fmt.Errorf("failed to decode signing key %s: %w", id, err)
+		}
+		keys = append(keys, &domainAuth.SigningKey{ID: stored.ID, Algorithm: algorithm, Material: stored.Material, CreatedAt: stored.CreatedAt})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
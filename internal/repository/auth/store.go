@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+	"gorm.io/gorm"
+)
+
+// DefaultDriver is the auth store driver used when none is configured
+const DefaultDriver = "redis"
+
+// Store holds the refresh token, session and signing key repositories of
+// one storage backend
+type Store struct {
+	Tokens      domainAuth.AuthRepository
+	Sessions    domainAuth.SessionRepository
+	SigningKeys domainAuth.SigningKeyRepository
+}
+
+// Backends are the connections a driver may build its store on. Drivers
+// only use the ones they need; the others may be nil.
+type Backends struct {
+	Redis *redis.Client
+	Keys  rediskey.Schema
+	Retry RetryPolicy // Retries Redis commands while Redis fails over
+	DB    *gorm.DB
+}
+
+// sessionsHold reports whether a live session among sessions holds the
+// refresh token. Sessions stored before their refresh token was kept may
+// hold any token of their user, so they count as holding it.
+func sessionsHold(sessions []*domainAuth.Session, token string) bool {
+	for _, session := range sessions {
+		if session.IsExpired() {
+			continue
+		}
+		if session.RefreshToken == token || session.RefreshToken == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Driver builds the store of a storage backend
+type Driver func(backends Backends) (Store, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{}
+)
+
+// RegisterDriver makes a storage backend available under name. It panics
+// when name is already registered.
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, ok := drivers[name]; ok {
+		panic("auth store driver " + name + " registered twice")
+	}
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	return slices.Sorted(maps.Keys(drivers))
+}
+
+// OpenStore builds the store of the named driver; an empty name selects
+// DefaultDriver
+func OpenStore(name string, backends Backends) (Store, error) {
+	if name == "" {
+		name = DefaultDriver
+	}
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return Store{}, fmt.Errorf("auth store driver %q is not one of %s", name, strings.Join(Drivers(), ", "))
+	}
+	return driver(backends)
+}
+
+func init() {
+	RegisterDriver("redis", func(b Backends) (Store, error) {
+		if b.Redis == nil {
+			return Store{}, fmt.Errorf("auth store driver redis needs a Redis client")
+		}
+		return Store{
+			Tokens:      NewAuthRepository(b.Redis, b.Keys, b.Retry),
+			Sessions:    NewSessionRepository(b.Redis, b.Keys, b.Retry),
+			SigningKeys: NewSigningKeyRepository(b.Redis, b.Keys, b.Retry),
+		}, nil
+	})
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+func TestOpenStore(t *testing.T) {
+	assert.Equal(t, []string{"memory", "postgres", "redis"}, Drivers())
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+
+	tests := []struct {
+		name     string
+		driver   string
+		backends Backends
+		expected any
+		err      string
+	}{
+		{name: "Default", backends: Backends{Redis: client}, expected: &AuthRepositoryImpl{}},
+		{name: "Redis", driver: "redis", backends: Backends{Redis: client}, expected: &AuthRepositoryImpl{}},
+		{name: "Memory", driver: "memory", expected: &MemoryAuthRepository{}},
+		{name: "Redis Without Client", driver: "redis", err: "needs a Redis client"},
+		{name: "Postgres Without Database", driver: "postgres", err: "needs a database"},
+		{name: "Unknown", driver: "etcd", err: `auth store driver "etcd" is not one of memory, postgres, redis`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := OpenStore(tt.driver, tt.backends)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, store.Tokens)
+			assert.NotNil(t, store.Sessions)
+			assert.NotNil(t, store.SigningKeys)
+		})
+	}
+
+	assert.Panics(t, func() { RegisterDriver("memory", nil) })
+}
+
+func TestMemoryAuthRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewMemoryAuthRepository(nil)
+	repo.now = func() time.Time { return now }
+	alice, bob := uuid.New(), uuid.New()
+
+	require.NoError(t, repo.SetUserRefreshToken(ctx, alice, "alice-1", time.Hour))
+	require.NoError(t, repo.SetRefreshTokenUserID(ctx, "alice-1", alice, time.Hour))
+	token, err := repo.GetUserRefreshToken(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, "alice-1", token)
+	owner, err := repo.GetUserIDByRefreshToken(ctx, "alice-1")
+	require.NoError(t, err)
+	assert.Equal(t, alice, owner)
+
+	// Rotating leaves the owner of the replaced token orphaned
+	require.NoError(t, repo.SetUserRefreshToken(ctx, alice, "alice-2", time.Hour))
+	require.NoError(t, repo.SetRefreshTokenUserID(ctx, "alice-2", alice, time.Hour))
+	require.NoError(t, repo.SetUserRefreshToken(ctx, bob, "bob-1", 0))
+	require.NoError(t, repo.SetRefreshTokenUserID(ctx, "bob-1", bob, 0))
+
+	pruned, err := repo.PruneOrphanedRefreshTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+	owner, err = repo.GetUserIDByRefreshToken(ctx, "alice-1")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, owner)
+
+	// Entries expire; an expiration of zero never does
+	now = now.Add(2 * time.Hour)
+	token, err = repo.GetUserRefreshToken(ctx, alice)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+	token, err = repo.GetUserRefreshToken(ctx, bob)
+	require.NoError(t, err)
+	assert.Equal(t, "bob-1", token)
+
+	pruned, err = repo.PruneOrphanedRefreshTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruned)
+
+	require.NoError(t, repo.DeleteRefreshTokenUserID(ctx, "bob-1"))
+	require.NoError(t, repo.DeleteUserRefreshToken(ctx, bob))
+	token, err = repo.GetUserRefreshToken(ctx, bob)
+	require.NoError(t, err)
+	assert.Empty(t, token)
+}
+
+func TestMemoryAuthRepository_PruneKeepsOtherDevices(t *testing.T) {
+	ctx := context.Background()
+	sessions := NewMemorySessionRepository()
+	repo := NewMemoryAuthRepository(sessions)
+	userID := uuid.New()
+	signIn := func(device, token string) {
+		require.NoError(t, repo.SetUserRefreshToken(ctx, userID, token, time.Hour))
+		require.NoError(t, repo.SetRefreshTokenUserID(ctx, token, userID, time.Hour))
+		require.NoError(t, sessions.SaveSession(ctx, domainAuth.NewSession(userID, token, domainAuth.SessionStandard, device, "10.0.0.1", time.Hour)))
+	}
+
+	// The laptop signed in before the phone; a rotation of the phone failed
+	// to delete the mapping of the token it replaced
+	signIn("laptop", "laptop-token")
+	signIn("phone", "phone-token")
+	require.NoError(t, repo.SetRefreshTokenUserID(ctx, "replaced-token", userID, time.Hour))
+
+	pruned, err := repo.PruneOrphanedRefreshTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+	for _, token := range []string{"laptop-token", "phone-token"} {
+		owner, err := repo.GetUserIDByRefreshToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, userID, owner, "%s survives pruning", token)
+	}
+	owner, err := repo.GetUserIDByRefreshToken(ctx, "replaced-token")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, owner)
+
+	// Once the sessions are gone, only the current token is kept
+	require.NoError(t, sessions.DeleteSessions(ctx, userID))
+	pruned, err = repo.PruneOrphanedRefreshTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+	owner, err = repo.GetUserIDByRefreshToken(ctx, "laptop-token")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, owner)
+}
+
+func TestMemorySessionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemorySessionRepository()
+	userID := uuid.New()
+
+	older := domainAuth.NewSession(userID, "token-1", domainAuth.SessionStandard, "curl/8.0", "10.0.0.1", time.Hour)
+	older.CreatedAt = older.CreatedAt.Add(-time.Minute)
+	newer := domainAuth.NewSession(userID, "token-2", domainAuth.SessionRememberMe, "curl/8.0", "10.0.0.2", time.Hour)
+	expired := domainAuth.NewSession(userID, "token-3", domainAuth.SessionStandard, "curl/8.0", "10.0.0.3", -time.Minute)
+	for _, session := range []*domainAuth.Session{older, newer, expired} {
+		require.NoError(t, repo.SaveSession(ctx, session))
+	}
+
+	sessions, err := repo.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+	assert.Equal(t, older.ID, sessions[1].ID)
+	assert.Equal(t, "token-2", sessions[0].RefreshToken)
+
+	require.NoError(t, repo.SaveSession(ctx, expired))
+	pruned, err := repo.PruneExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	require.NoError(t, repo.DeleteSessions(ctx, userID))
+	sessions, err = repo.ListSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
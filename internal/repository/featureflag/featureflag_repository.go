@@ -0,0 +1,97 @@
+package featureflag
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"gorm.io/gorm"
+)
+
+// FlagModel represents the feature flag structure for database interactions.
+// Tenant lists are stored comma-separated.
+type FlagModel struct {
+	Name        string    `gorm:"size:64;primaryKey"`
+	Enabled     bool      `gorm:"not null"`
+	Tenants     string    `gorm:"not null"`
+	Description string    `gorm:"size:255;not null"`
+	UpdatedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	UpdatedAt   time.Time
+}
+
+// TableName specifies the table name for the FlagModel.
+func (FlagModel) TableName() string {
+	return "feature_flags"
+}
+
+// toDomain converts a FlagModel to a featureflag.Flag.
+func toDomain(m *FlagModel) *featureflag.Flag {
+	var tenants []string
+	if m.Tenants != "" {
+		tenants = strings.Split(m.Tenants, ",")
+	}
+	return &featureflag.Flag{
+		Name:        m.Name,
+		Enabled:     m.Enabled,
+		Tenants:     tenants,
+		Description: m.Description,
+		UpdatedBy:   m.UpdatedBy,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// fromDomain converts a featureflag.Flag to a FlagModel.
+func fromDomain(f *featureflag.Flag) *FlagModel {
+	return &FlagModel{
+		Name:        f.Name,
+		Enabled:     f.Enabled,
+		Tenants:     strings.Join(f.Tenants, ","),
+		Description: f.Description,
+		UpdatedBy:   f.UpdatedBy,
+		UpdatedAt:   f.UpdatedAt,
+	}
+}
+
+type flagRepository struct {
+	db *gorm.DB
+}
+
+// NewFlagRepository creates a new instance of featureflag.Store.
+func NewFlagRepository(db *gorm.DB) featureflag.Store {
+	return &flagRepository{db: db}
+}
+
+func (r *flagRepository) List(ctx context.Context) ([]*featureflag.Flag, error) {
+	var models []FlagModel
+	if err := r.db.WithContext(ctx).Order("name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	flags := make([]*featureflag.Flag, 0, len(models))
+	for i := range models {
+		flags = append(flags, toDomain(&models[i]))
+	}
+	return flags, nil
+}
+
+func (r *flagRepository) Get(ctx context.Context, name string) (*featureflag.Flag, error) {
+	var model FlagModel
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Flag not stored
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
+
+func (r *flagRepository) Save(ctx context.Context, flag *featureflag.Flag) error {
+	return r.db.WithContext(ctx).Save(fromDomain(flag)).Error
+}
+
+func (r *flagRepository) Delete(ctx context.Context, name string) error {
+	return r.db.WithContext(ctx).Where("name = ?", name).Delete(&FlagModel{}).Error
+}
@@ -0,0 +1,75 @@
+package impersonation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// ImpersonationModel represents the impersonation structure for database interactions.
+type ImpersonationModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ActorID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	SubjectID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Reason    string    `gorm:"size:255;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the ImpersonationModel.
+func (ImpersonationModel) TableName() string {
+	return "impersonations"
+}
+
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+// NewImpersonationRepository creates a new instance of domainAuth.ImpersonationRepository.
+func NewImpersonationRepository(db *gorm.DB) domainAuth.ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
+
+func (r *impersonationRepository) Create(ctx context.Context, impersonation *domainAuth.Impersonation) error {
+	model := &ImpersonationModel{
+		ID:        impersonation.ID,
+		ActorID:   impersonation.ActorID,
+		SubjectID: impersonation.SubjectID,
+		Reason:    impersonation.Reason,
+		ExpiresAt: impersonation.ExpiresAt,
+		RevokedAt: impersonation.RevokedAt,
+		CreatedAt: impersonation.CreatedAt,
+	}
+	return transaction.DB(ctx, r.db).Create(model).Error
+}
+
+func (r *impersonationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	var model ImpersonationModel
+	if err := transaction.DB(ctx, r.db).Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Impersonation not found
+		}
+		return nil, err
+	}
+	return &domainAuth.Impersonation{
+		ID:        model.ID,
+		ActorID:   model.ActorID,
+		SubjectID: model.SubjectID,
+		Reason:    model.Reason,
+		ExpiresAt: model.ExpiresAt,
+		RevokedAt: model.RevokedAt,
+		CreatedAt: model.CreatedAt,
+	}, nil
+}
+
+// Revoke keeps the time of the first revocation
+func (r *impersonationRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return transaction.DB(ctx, r.db).Model(&ImpersonationModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
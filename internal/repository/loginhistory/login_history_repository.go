@@ -0,0 +1,91 @@
+package loginhistory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// RecordModel represents the login history structure for database interactions.
+type RecordModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Result    string    `gorm:"size:32;not null"`
+	ClientIP  string    `gorm:"size:64;not null"`
+	UserAgent string    `gorm:"size:512;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for the RecordModel.
+func (RecordModel) TableName() string {
+	return "login_history"
+}
+
+// maxUserAgentLength matches the user_agent column
+const maxUserAgentLength = 512
+
+type loginHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginHistoryRepository creates a new instance of domainAuth.LoginHistoryRepository.
+func NewLoginHistoryRepository(db *gorm.DB) domainAuth.LoginHistoryRepository {
+	return &loginHistoryRepository{db: db}
+}
+
+func (r *loginHistoryRepository) Create(ctx context.Context, record *domainAuth.LoginRecord) error {
+	userAgent := record.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	model := &RecordModel{
+		ID:        record.ID,
+		UserID:    record.UserID,
+		Result:    string(record.Result),
+		ClientIP:  record.ClientIP,
+		UserAgent: userAgent,
+		CreatedAt: record.CreatedAt,
+	}
+	return transaction.DB(ctx, r.db).Create(model).Error
+}
+
+func (r *loginHistoryRepository) List(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	query := transaction.DB(ctx, r.db).Model(&RecordModel{}).Where("user_id = ?", filter.UserID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []RecordModel
+	err := query.
+		Order("created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	records := make([]*domainAuth.LoginRecord, 0, len(models))
+	for _, m := range models {
+		records = append(records, &domainAuth.LoginRecord{
+			ID:        m.ID,
+			UserID:    m.UserID,
+			Result:    domainAuth.LoginResult(m.Result),
+			ClientIP:  m.ClientIP,
+			UserAgent: m.UserAgent,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return records, total, nil
+}
+
+func (r *loginHistoryRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := transaction.DB(ctx, r.db).Where("created_at < ?", cutoff).Delete(&RecordModel{})
+	return result.RowsAffected, result.Error
+}
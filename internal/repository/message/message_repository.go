@@ -0,0 +1,140 @@
+package message
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"gorm.io/gorm"
+)
+
+// SystemMessageModel represents the system message structure for database interactions.
+// Audience lists are stored comma-separated; the table is small and targeting
+// is evaluated in the service.
+type SystemMessageModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Title     string    `gorm:"size:255;not null"`
+	Body      string    `gorm:"not null"`
+	Severity  string    `gorm:"size:16;not null"`
+	Roles     string    `gorm:"not null"`
+	Tenants   string    `gorm:"not null"`
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the SystemMessageModel.
+func (SystemMessageModel) TableName() string {
+	return "system_messages"
+}
+
+// toDomain converts a SystemMessageModel to a domainMessage.SystemMessage.
+func toDomain(m *SystemMessageModel) *domainMessage.SystemMessage {
+	var roles []rbac.Role
+	for _, r := range splitList(m.Roles) {
+		roles = append(roles, rbac.Role(r))
+	}
+	return &domainMessage.SystemMessage{
+		ID:        m.ID,
+		Title:     m.Title,
+		Body:      m.Body,
+		Severity:  domainMessage.Severity(m.Severity),
+		Roles:     roles,
+		Tenants:   splitList(m.Tenants),
+		StartsAt:  m.StartsAt,
+		EndsAt:    m.EndsAt,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// fromDomain converts a domainMessage.SystemMessage to a SystemMessageModel.
+func fromDomain(m *domainMessage.SystemMessage) *SystemMessageModel {
+	roles := make([]string, 0, len(m.Roles))
+	for _, r := range m.Roles {
+		roles = append(roles, string(r))
+	}
+	return &SystemMessageModel{
+		ID:        m.ID,
+		Title:     m.Title,
+		Body:      m.Body,
+		Severity:  string(m.Severity),
+		Roles:     strings.Join(roles, ","),
+		Tenants:   strings.Join(m.Tenants, ","),
+		StartsAt:  m.StartsAt,
+		EndsAt:    m.EndsAt,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository creates a new instance of domainMessage.Repository.
+func NewMessageRepository(db *gorm.DB) domainMessage.Repository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) Create(ctx context.Context, message *domainMessage.SystemMessage) error {
+	return r.db.WithContext(ctx).Create(fromDomain(message)).Error
+}
+
+func (r *messageRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainMessage.SystemMessage, error) {
+	var model SystemMessageModel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Message not found
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
+
+func (r *messageRepository) Update(ctx context.Context, message *domainMessage.SystemMessage) error {
+	return r.db.WithContext(ctx).Save(fromDomain(message)).Error
+}
+
+func (r *messageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&SystemMessageModel{}).Error
+}
+
+func (r *messageRepository) List(ctx context.Context) ([]*domainMessage.SystemMessage, error) {
+	return r.find(r.db.WithContext(ctx))
+}
+
+func (r *messageRepository) ListActive(ctx context.Context, now time.Time) ([]*domainMessage.SystemMessage, error) {
+	query := r.db.WithContext(ctx).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now)
+	return r.find(query)
+}
+
+func (r *messageRepository) find(query *gorm.DB) ([]*domainMessage.SystemMessage, error) {
+	var models []SystemMessageModel
+	if err := query.Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	messages := make([]*domainMessage.SystemMessage, 0, len(models))
+	for i := range models {
+		messages = append(messages, toDomain(&models[i]))
+	}
+	return messages, nil
+}
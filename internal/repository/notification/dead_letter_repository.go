@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// DeadLetterModel represents the dead-letter structure for database interactions.
+type DeadLetterModel struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	NotificationID uuid.UUID `gorm:"type:uuid;not null"`
+	Kind           string    `gorm:"size:64;not null"`
+	Channel        string    `gorm:"size:32;not null"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email          string    `gorm:"size:255;not null"`
+	Subject        string    `gorm:"size:255;not null"`
+	Body           string    `gorm:"not null"`
+	Attempts       int       `gorm:"not null"`
+	LastError      string    `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for the DeadLetterModel.
+func (DeadLetterModel) TableName() string {
+	return "notification_dead_letters"
+}
+
+type deadLetterRepository struct {
+	db *gorm.DB
+}
+
+// NewDeadLetterRepository creates a new instance of domainNotification.DeadLetterRepository.
+func NewDeadLetterRepository(db *gorm.DB) domainNotification.DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+func (r *deadLetterRepository) Create(ctx context.Context, letter *domainNotification.DeadLetter) error {
+	model := &DeadLetterModel{
+		ID:             letter.ID,
+		NotificationID: letter.NotificationID,
+		Kind:           string(letter.Kind),
+		Channel:        string(letter.Channel),
+		UserID:         letter.UserID,
+		Email:          letter.Email,
+		Subject:        letter.Subject,
+		Body:           letter.Body,
+		Attempts:       letter.Attempts,
+		LastError:      letter.LastError,
+		CreatedAt:      letter.CreatedAt,
+	}
+	return transaction.DB(ctx, r.db).Create(model).Error
+}
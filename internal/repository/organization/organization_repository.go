@@ -0,0 +1,237 @@
+package organization
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+)
+
+// OrganizationModel represents the organization structure for database interactions.
+type OrganizationModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"size:100;not null"`
+	Slug      string    `gorm:"size:64;not null;uniqueIndex"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the OrganizationModel.
+func (OrganizationModel) TableName() string {
+	return "organizations"
+}
+
+// MemberModel represents the membership structure for database interactions.
+type MemberModel struct {
+	OrganizationID uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID  `gorm:"type:uuid;primaryKey;index"`
+	Role           string     `gorm:"size:16;not null"`
+	Status         string     `gorm:"size:16;not null"`
+	InvitedBy      *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	JoinedAt       *time.Time
+}
+
+// TableName specifies the table name for the MemberModel.
+func (MemberModel) TableName() string {
+	return "organization_members"
+}
+
+// memberRow is a membership joined with the email of its user
+type memberRow struct {
+	MemberModel `gorm:"embedded"`
+	Email       string
+}
+
+// toDomain converts an OrganizationModel to a domainOrg.Organization.
+func toDomain(m *OrganizationModel) *domainOrg.Organization {
+	return &domainOrg.Organization{
+		ID:        m.ID,
+		Name:      m.Name,
+		Slug:      m.Slug,
+		CreatedBy: m.CreatedBy,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// fromDomain converts a domainOrg.Organization to an OrganizationModel.
+func fromDomain(o *domainOrg.Organization) *OrganizationModel {
+	return &OrganizationModel{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		CreatedBy: o.CreatedBy,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// memberToDomain converts a memberRow to a domainOrg.Member.
+func memberToDomain(r *memberRow) *domainOrg.Member {
+	return &domainOrg.Member{
+		OrganizationID: r.OrganizationID,
+		UserID:         r.UserID,
+		Email:          r.Email,
+		Role:           domainOrg.Role(r.Role),
+		Status:         domainOrg.Status(r.Status),
+		InvitedBy:      r.InvitedBy,
+		CreatedAt:      r.CreatedAt,
+		JoinedAt:       r.JoinedAt,
+	}
+}
+
+// memberFromDomain converts a domainOrg.Member to a MemberModel.
+func memberFromDomain(m *domainOrg.Member) *MemberModel {
+	return &MemberModel{
+		OrganizationID: m.OrganizationID,
+		UserID:         m.UserID,
+		Role:           string(m.Role),
+		Status:         string(m.Status),
+		InvitedBy:      m.InvitedBy,
+		CreatedAt:      m.CreatedAt,
+		JoinedAt:       m.JoinedAt,
+	}
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new instance of domainOrg.Repository.
+func NewOrganizationRepository(db *gorm.DB) domainOrg.Repository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *domainOrg.Organization, owner *domainOrg.Member) error {
+	return transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		model := fromDomain(org)
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+		member := memberFromDomain(owner)
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		org.CreatedAt, org.UpdatedAt = model.CreatedAt, model.UpdatedAt
+		owner.CreatedAt = member.CreatedAt
+		return nil
+	})
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainOrg.Organization, error) {
+	return r.first(transaction.DB(ctx, r.db).Where("id = ?", id))
+}
+
+func (r *organizationRepository) GetBySlug(ctx context.Context, slug string) (*domainOrg.Organization, error) {
+	return r.first(transaction.DB(ctx, r.db).Where("slug = ?", slug))
+}
+
+func (r *organizationRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domainOrg.Organization, error) {
+	var models []OrganizationModel
+	err := transaction.DB(ctx, r.db).
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ? AND organization_members.status = ?", userID, domainOrg.StatusActive).
+		Order("organizations.name").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := make([]*domainOrg.Organization, 0, len(models))
+	for i := range models {
+		orgs = append(orgs, toDomain(&models[i]))
+	}
+	return orgs, nil
+}
+
+func (r *organizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*domainOrg.Member, error) {
+	members, err := r.members(ctx, func(q *gorm.DB) *gorm.DB {
+		return q.Where("organization_members.organization_id = ? AND organization_members.user_id = ?", orgID, userID)
+	})
+	if err != nil || len(members) == 0 {
+		return nil, err // Member not found when err is nil
+	}
+	return members[0], nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*domainOrg.Member, error) {
+	return r.members(ctx, func(q *gorm.DB) *gorm.DB {
+		return q.Where("organization_members.organization_id = ?", orgID).
+			Order("organization_members.created_at")
+	})
+}
+
+func (r *organizationRepository) ListInvitations(ctx context.Context, userID uuid.UUID) ([]*domainOrg.Member, error) {
+	return r.members(ctx, func(q *gorm.DB) *gorm.DB {
+		return q.Where("organization_members.user_id = ? AND organization_members.status = ?", userID, domainOrg.StatusInvited).
+			Order("organization_members.created_at DESC")
+	})
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, member *domainOrg.Member) error {
+	model := memberFromDomain(member)
+	if err := transaction.DB(ctx, r.db).Create(model).Error; err != nil {
+		return err
+	}
+	member.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *organizationRepository) UpdateMember(ctx context.Context, member *domainOrg.Member) error {
+	return transaction.DB(ctx, r.db).Model(&MemberModel{}).
+		Where("organization_id = ? AND user_id = ?", member.OrganizationID, member.UserID).
+		Updates(map[string]interface{}{
+			"role":      string(member.Role),
+			"status":    string(member.Status),
+			"joined_at": member.JoinedAt,
+		}).Error
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return transaction.DB(ctx, r.db).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Delete(&MemberModel{}).Error
+}
+
+func (r *organizationRepository) CountOwners(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	var count int64
+	err := transaction.DB(ctx, r.db).Model(&MemberModel{}).
+		Where("organization_id = ? AND role = ? AND status = ?", orgID, domainOrg.RoleOwner, domainOrg.StatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// members returns the memberships matched by scope with the emails of their users
+func (r *organizationRepository) members(ctx context.Context, scope func(*gorm.DB) *gorm.DB) ([]*domainOrg.Member, error) {
+	var rows []memberRow
+	query := transaction.DB(ctx, r.db).
+		Table("organization_members").
+		Select("organization_members.*, users.email").
+		Joins("JOIN users ON users.id = organization_members.user_id")
+	if err := scope(query).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]*domainOrg.Member, 0, len(rows))
+	for i := range rows {
+		members = append(members, memberToDomain(&rows[i]))
+	}
+	return members, nil
+}
+
+// first returns the first organization matched by query, or nil when there is none
+func (r *organizationRepository) first(query *gorm.DB) (*domainOrg.Organization, error) {
+	var model OrganizationModel
+	if err := query.First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Organization not found
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
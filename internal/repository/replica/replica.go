@@ -0,0 +1,56 @@
+package replica
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+)
+
+// primaryKey is the context key forcing reads onto the primary
+type primaryKey struct{}
+
+// WithPrimary returns a context whose reads go to the primary database.
+// Use it to read back data just written, which replicas may not have yet.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryForced reports whether ctx forces reads onto the primary
+func PrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// Pool spreads reads over the read replicas of the primary database in turn.
+// A nil or empty Pool reads from the primary.
+type Pool struct {
+	replicas []*gorm.DB
+	next     atomic.Uint64
+}
+
+// NewPool creates a pool of read replicas
+func NewPool(replicas ...*gorm.DB) *Pool {
+	return &Pool{replicas: replicas}
+}
+
+// Len returns the number of replicas in the pool
+func (p *Pool) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.replicas)
+}
+
+// Reader returns the connection a read made with ctx should use: the
+// transaction carried by ctx, the primary when ctx forces it or there are no
+// replicas, and otherwise the next replica
+func (p *Pool) Reader(ctx context.Context, primary *gorm.DB) *gorm.DB {
+	if transaction.Active(ctx) || PrimaryForced(ctx) || p.Len() == 0 {
+		return transaction.DB(ctx, primary)
+	}
+	n := p.next.Add(1) - 1
+	return p.replicas[n%uint64(len(p.replicas))].WithContext(ctx)
+}
@@ -0,0 +1,47 @@
+package replica
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openDB returns a handle that never connects
+func openDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=unused"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+// assertReadsFrom checks that got uses the connection pool of want
+func assertReadsFrom(t *testing.T, want, got *gorm.DB) {
+	t.Helper()
+	assert.Same(t, want.ConnPool, got.Statement.ConnPool)
+}
+
+func TestReader(t *testing.T) {
+	primary, first, second := openDB(t), openDB(t), openDB(t)
+	ctx := context.Background()
+
+	t.Run("Replicas In Turn", func(t *testing.T) {
+		pool := NewPool(first, second)
+		assertReadsFrom(t, first, pool.Reader(ctx, primary))
+		assertReadsFrom(t, second, pool.Reader(ctx, primary))
+		assertReadsFrom(t, first, pool.Reader(ctx, primary))
+	})
+
+	t.Run("Primary Forced", func(t *testing.T) {
+		pool := NewPool(first, second)
+		assertReadsFrom(t, primary, pool.Reader(WithPrimary(ctx), primary))
+	})
+
+	t.Run("No Replicas", func(t *testing.T) {
+		var pool *Pool
+		assertReadsFrom(t, primary, pool.Reader(ctx, primary))
+		assertReadsFrom(t, primary, NewPool().Reader(ctx, primary))
+	})
+}
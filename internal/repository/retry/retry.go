@@ -0,0 +1,93 @@
+// Package retry retries repository calls that fail with a transient database
+// error, such as a reset connection, a deadlock or a serialization failure,
+// which the same call is likely to get past when it is repeated.
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+)
+
+// Transient Postgres SQLSTATE codes
+var transientStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown, e.g. a failover terminating connections
+	"08000": true, // connection_exception
+	"08003": true, // connection_does_not_exist
+	"08006": true, // connection_failure
+}
+
+// sqlStateError is implemented by driver errors carrying a SQLSTATE code,
+// such as *pgconn.PgError
+type sqlStateError interface {
+	SQLState() string
+}
+
+// IsTransient reports whether err is worth retrying: the database dropped
+// the connection or aborted the statement in favour of another one. The
+// caller giving up, constraint violations and other errors the same call
+// would get again are not.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return transientStates[stateErr.SQLState()]
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection reset by peer")
+}
+
+// Policy controls how a kind of repository call is retried
+type Policy struct {
+	Attempts   int           // Tries per call, including the first; values below 1 mean 1
+	Backoff    time.Duration // Wait before the second try, doubling for each further one
+	MaxBackoff time.Duration // Longest wait between tries; 0 means no limit
+}
+
+// Do runs fn, retrying it while it fails with a transient error. Each wait
+// is between half and all of the backoff, so callers failing together do
+// not retry together. Calls inside a transaction are not retried: the
+// database aborted the transaction, so only running all of it again helps.
+func (p Policy) Do(ctx context.Context, fn func() error) error {
+	if transaction.Active(ctx) {
+		return fn()
+	}
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if attempt >= p.Attempts || !IsTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(jitter(backoff)):
+		}
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// jitter returns a random duration between half of d and d
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,100 @@
+package retry
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// stateError is a driver error with a SQLSTATE code, like *pgconn.PgError
+type stateError string
+
+func (e stateError) Error() string    { return "ERROR (SQLSTATE " + string(e) + ")" }
+func (e stateError) SQLState() string { return string(e) }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil, expected: false},
+		{err: gorm.ErrDuplicatedKey, expected: false},
+		{err: context.Canceled, expected: false},
+		{err: context.DeadlineExceeded, expected: false},
+		{err: stateError("23505"), expected: false}, // unique_violation
+		{err: stateError("40001"), expected: true},
+		{err: fmt.Errorf("update user: %w", stateError("40P01")), expected: true},
+		{err: driver.ErrBadConn, expected: true},
+		{err: syscall.ECONNRESET, expected: true},
+		{err: errors.New("read tcp 10.0.0.2:5432: read: connection reset by peer"), expected: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsTransient(tt.err), "%v", tt.err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	deadlock := stateError("40P01")
+
+	t.Run("Retries Until It Succeeds", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(ctx, func() error {
+			calls++
+			if calls < 3 {
+				return deadlock
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives Up After Attempts", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(ctx, func() error { calls++; return deadlock })
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Other Errors Are Not Retried", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(ctx, func() error { calls++; return gorm.ErrDuplicatedKey })
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Zero Policy Tries Once", func(t *testing.T) {
+		calls := 0
+		err := Policy{}.Do(ctx, func() error { calls++; return deadlock })
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Stops When Context Is Done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		calls := 0
+		err := Policy{Attempts: 5, Backoff: time.Hour}.Do(cancelled, func() error { calls++; return deadlock })
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestJitter(t *testing.T) {
+	assert.Zero(t, jitter(0))
+	for range 100 {
+		d := jitter(10 * time.Millisecond)
+		assert.GreaterOrEqual(t, d, 5*time.Millisecond)
+		assert.LessOrEqual(t, d, 10*time.Millisecond)
+	}
+}
@@ -0,0 +1,36 @@
+package saml
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// assertionCache implements domainSAML.AssertionCache with one Redis key per
+// assertion, expiring along with it
+type assertionCache struct {
+	redisClient *redis.Client
+	keys        rediskey.Schema
+	now         func() time.Time
+}
+
+// NewAssertionCache creates a new instance of domainSAML.AssertionCache.
+func NewAssertionCache(redisClient *redis.Client, keys rediskey.Schema) domainSAML.AssertionCache {
+	return &assertionCache{redisClient: redisClient, keys: keys, now: time.Now}
+}
+
+func (c *assertionCache) Claim(ctx context.Context, tenant, assertionID string, expiresAt time.Time) (bool, error) {
+	ttl := expiresAt.Sub(c.now())
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	claimed, err := c.redisClient.SetNX(ctx, c.keys.SAMLAssertion(tenant, assertionID), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record SAML assertion in redis: %w", err)
+	}
+	return claimed, nil
+}
@@ -0,0 +1,106 @@
+package saml
+
+import (
+	"context"
+	"time"
+
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	"gorm.io/gorm"
+)
+
+// IdentityProviderModel represents the SAML identity provider structure for database interactions.
+// A tenant has at most one identity provider, so the tenant is the key.
+type IdentityProviderModel struct {
+	Tenant             string    `gorm:"size:255;primaryKey"`
+	EntityID           string    `gorm:"size:1024;not null"`
+	SSOURL             string    `gorm:"column:sso_url;size:2048;not null"`
+	Certificate        string    `gorm:"not null"`
+	EmailAttribute     string    `gorm:"size:255;not null"`
+	FirstNameAttribute string    `gorm:"size:255;not null"`
+	LastNameAttribute  string    `gorm:"size:255;not null"`
+	JITProvisioning    bool      `gorm:"column:jit_provisioning;not null"`
+	Enabled            bool      `gorm:"not null"`
+	CreatedAt          time.Time `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the IdentityProviderModel.
+func (IdentityProviderModel) TableName() string {
+	return "saml_identity_providers"
+}
+
+// toDomain converts an IdentityProviderModel to a domainSAML.IdentityProvider.
+func toDomain(m *IdentityProviderModel) *domainSAML.IdentityProvider {
+	return &domainSAML.IdentityProvider{
+		Tenant:             m.Tenant,
+		EntityID:           m.EntityID,
+		SSOURL:             m.SSOURL,
+		Certificate:        m.Certificate,
+		EmailAttribute:     m.EmailAttribute,
+		FirstNameAttribute: m.FirstNameAttribute,
+		LastNameAttribute:  m.LastNameAttribute,
+		JITProvisioning:    m.JITProvisioning,
+		Enabled:            m.Enabled,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+	}
+}
+
+// fromDomain converts a domainSAML.IdentityProvider to an IdentityProviderModel.
+func fromDomain(p *domainSAML.IdentityProvider) *IdentityProviderModel {
+	return &IdentityProviderModel{
+		Tenant:             p.Tenant,
+		EntityID:           p.EntityID,
+		SSOURL:             p.SSOURL,
+		Certificate:        p.Certificate,
+		EmailAttribute:     p.EmailAttribute,
+		FirstNameAttribute: p.FirstNameAttribute,
+		LastNameAttribute:  p.LastNameAttribute,
+		JITProvisioning:    p.JITProvisioning,
+		Enabled:            p.Enabled,
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
+	}
+}
+
+type samlRepository struct {
+	db *gorm.DB
+}
+
+// NewSAMLRepository creates a new instance of domainSAML.Repository.
+func NewSAMLRepository(db *gorm.DB) domainSAML.Repository {
+	return &samlRepository{db: db}
+}
+
+func (r *samlRepository) Get(ctx context.Context, tenant string) (*domainSAML.IdentityProvider, error) {
+	var model IdentityProviderModel
+	err := r.db.WithContext(ctx).Where("tenant = ?", tenant).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Identity provider not found
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
+
+// Save updates the row of the tenant, inserting it when there is none
+func (r *samlRepository) Save(ctx context.Context, provider *domainSAML.IdentityProvider) error {
+	return r.db.WithContext(ctx).Save(fromDomain(provider)).Error
+}
+
+func (r *samlRepository) Delete(ctx context.Context, tenant string) error {
+	return r.db.WithContext(ctx).Where("tenant = ?", tenant).Delete(&IdentityProviderModel{}).Error
+}
+
+func (r *samlRepository) List(ctx context.Context) ([]*domainSAML.IdentityProvider, error) {
+	var models []IdentityProviderModel
+	if err := r.db.WithContext(ctx).Order("tenant").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	providers := make([]*domainSAML.IdentityProvider, 0, len(models))
+	for i := range models {
+		providers = append(providers, toDomain(&models[i]))
+	}
+	return providers, nil
+}
@@ -0,0 +1,60 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/yi-tech/go-user-service/internal/tx"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key for the active transaction
+type txKey struct{}
+
+// Direct is a tx.Manager running every unit of work without a transaction,
+// for services used without a database
+var Direct tx.Manager = direct{}
+
+type direct struct{}
+
+func (direct) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Manager is the tx.Manager of the GORM repositories: it runs functions
+// inside a database transaction
+type Manager struct {
+	db *gorm.DB
+}
+
+var _ tx.Manager = (*Manager)(nil)
+
+// NewManager creates a new transaction manager
+func NewManager(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// WithinTransaction calls fn with a context carrying a transaction. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Repositories join it by resolving their connection through DB. Called
+// with a context already carrying a transaction, fn runs inside it under a
+// savepoint, so a failed inner unit of work leaves the outer one usable.
+func (m *Manager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return DB(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// DB returns the transaction carried by ctx, or db bound to ctx when there is none
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// Active reports whether ctx carries a transaction. Reads made inside one may
+// see uncommitted writes, so they must not be cached.
+func Active(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
@@ -0,0 +1,145 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingConnector opens connections that record the statements they run
+// instead of talking to a database
+type recordingConnector struct {
+	statements []string
+}
+
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func (c *recordingConnector) record(statement string) {
+	c.statements = append(c.statements, statement)
+}
+
+// executed returns the recorded statements with savepoint names made stable
+func (c *recordingConnector) executed() []string {
+	statements := make([]string, len(c.statements))
+	for i, statement := range c.statements {
+		statements[i] = savepointName.ReplaceAllString(statement, "SAVEPOINT sp")
+	}
+	return statements
+}
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.connector.record("BEGIN")
+	return recordingTx{c.connector}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.connector.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+type recordingTx struct {
+	connector *recordingConnector
+}
+
+func (t recordingTx) Commit() error {
+	t.connector.record("COMMIT")
+	return nil
+}
+
+func (t recordingTx) Rollback() error {
+	t.connector.record("ROLLBACK")
+	return nil
+}
+
+// savepointName matches the random names GORM gives savepoints
+var savepointName = regexp.MustCompile(`SAVEPOINT sp\d+`)
+
+func newTestManager(t *testing.T) (*Manager, *recordingConnector) {
+	t.Helper()
+	connector := &recordingConnector{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), &gorm.Config{})
+	require.NoError(t, err)
+	return NewManager(db), connector
+}
+
+func TestWithinTransaction(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("Commit", func(t *testing.T) {
+		manager, connector := newTestManager(t)
+		ctx := context.Background()
+
+		err := manager.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.True(t, Active(ctx))
+			require.NoError(t, DB(ctx, manager.db).Exec("INSERT INTO users").Error)
+			return manager.WithinTransaction(ctx, func(ctx context.Context) error {
+				return DB(ctx, manager.db).Exec("INSERT INTO roles").Error
+			})
+		})
+
+		require.NoError(t, err)
+		assert.False(t, Active(ctx))
+		assert.Equal(t, []string{"BEGIN", "INSERT INTO users", "SAVEPOINT sp", "INSERT INTO roles", "COMMIT"}, connector.executed())
+	})
+
+	t.Run("Inner Rollback Keeps Outer", func(t *testing.T) {
+		manager, connector := newTestManager(t)
+
+		err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			require.NoError(t, DB(ctx, manager.db).Exec("INSERT INTO users").Error)
+			err := manager.WithinTransaction(ctx, func(ctx context.Context) error {
+				require.NoError(t, DB(ctx, manager.db).Exec("INSERT INTO roles").Error)
+				return errFailed
+			})
+			assert.ErrorIs(t, err, errFailed)
+			return DB(ctx, manager.db).Exec("INSERT INTO events").Error
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"BEGIN",
+			"INSERT INTO users",
+			"SAVEPOINT sp",
+			"INSERT INTO roles",
+			"ROLLBACK TO SAVEPOINT sp",
+			"INSERT INTO events",
+			"COMMIT",
+		}, connector.executed())
+	})
+
+	t.Run("Outer Rollback", func(t *testing.T) {
+		manager, connector := newTestManager(t)
+
+		err := manager.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			require.NoError(t, manager.WithinTransaction(ctx, func(ctx context.Context) error {
+				return DB(ctx, manager.db).Exec("INSERT INTO roles").Error
+			}))
+			return errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed)
+		assert.Equal(t, []string{"BEGIN", "SAVEPOINT sp", "INSERT INTO roles", "ROLLBACK"}, connector.executed())
+	})
+}
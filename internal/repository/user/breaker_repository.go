@@ -0,0 +1,93 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// breakerRepository guards the calls to repo with a circuit breaker, so
+// that while the database is unreachable calls fail with breaker.ErrOpen
+// instead of each waiting for a connection
+type breakerRepository struct {
+	repo    domainUser.Repository
+	breaker *breaker.Breaker
+}
+
+// NewBreakerRepository wraps repo with b; a nil breaker returns repo as is
+func NewBreakerRepository(repo domainUser.Repository, b *breaker.Breaker) domainUser.Repository {
+	if b == nil {
+		return repo
+	}
+	return &breakerRepository{repo: repo, breaker: b}
+}
+
+// isUnavailable reports whether err means the database could not be reached
+// or did not answer in time, rather than rejecting the statement
+func isUnavailable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false // The caller went away
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "failed to connect")
+}
+
+func (r *breakerRepository) Create(ctx context.Context, user *domainUser.User) error {
+	return r.breaker.Do(func() error { return r.repo.Create(ctx, user) }, isUnavailable)
+}
+
+func (r *breakerRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	return r.breaker.Do(func() error { return r.repo.CreateBatch(ctx, users) }, isUnavailable)
+}
+
+func (r *breakerRepository) GetByID(ctx context.Context, id uuid.UUID) (user *domainUser.User, err error) {
+	err = r.breaker.Do(func() error { user, err = r.repo.GetByID(ctx, id); return err }, isUnavailable)
+	return user, err
+}
+
+func (r *breakerRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (users []*domainUser.User, err error) {
+	err = r.breaker.Do(func() error { users, err = r.repo.GetByIDs(ctx, ids); return err }, isUnavailable)
+	return users, err
+}
+
+func (r *breakerRepository) GetByEmail(ctx context.Context, email string) (user *domainUser.User, err error) {
+	err = r.breaker.Do(func() error { user, err = r.repo.GetByEmail(ctx, email); return err }, isUnavailable)
+	return user, err
+}
+
+func (r *breakerRepository) GetByUsername(ctx context.Context, username string) (user *domainUser.User, err error) {
+	err = r.breaker.Do(func() error { user, err = r.repo.GetByUsername(ctx, username); return err }, isUnavailable)
+	return user, err
+}
+
+func (r *breakerRepository) Update(ctx context.Context, user *domainUser.User) error {
+	return r.breaker.Do(func() error { return r.repo.Update(ctx, user) }, isUnavailable)
+}
+
+func (r *breakerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.breaker.Do(func() error { return r.repo.Delete(ctx, id) }, isUnavailable)
+}
+
+func (r *breakerRepository) List(ctx context.Context, filter domainUser.ListFilter) (users []*domainUser.User, total int64, err error) {
+	err = r.breaker.Do(func() error { users, total, err = r.repo.List(ctx, filter); return err }, isUnavailable)
+	return users, total, err
+}
+
+func (r *breakerRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) (users []*domainUser.User, err error) {
+	err = r.breaker.Do(func() error { users, err = r.repo.ListAfter(ctx, filter, afterID); return err }, isUnavailable)
+	return users, err
+}
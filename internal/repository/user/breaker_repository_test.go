@@ -0,0 +1,88 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{err: gorm.ErrDuplicatedKey, expected: false},
+		{err: gorm.ErrRecordNotFound, expected: false},
+		{err: context.Canceled, expected: false},
+		{err: context.DeadlineExceeded, expected: true},
+		{err: driver.ErrBadConn, expected: true},
+		{err: fmt.Errorf("query users: %w", driver.ErrBadConn), expected: true},
+		{err: errors.New("failed to connect to `host=localhost user=app database=users`: dial error"), expected: true},
+		{err: errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"), expected: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, isUnavailable(tt.err), "%v", tt.err)
+	}
+}
+
+// flakyRepository fails every call with err until it is cleared
+type flakyRepository struct {
+	domainUser.Repository
+	err   error
+	calls int
+}
+
+func (r *flakyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.Repository.GetByID(ctx, id)
+}
+
+func TestBreakerRepository(t *testing.T) {
+	ctx := context.Background()
+	alice, _ := NewUserBuilder().Build()
+	flaky := &flakyRepository{Repository: NewInMemoryRepository(alice), err: driver.ErrBadConn}
+	group := breaker.NewGroup(true, breaker.Options{FailureThreshold: 2, OpenTimeout: time.Hour}, nil, zap.NewNop())
+	repo := NewBreakerRepository(flaky, group.Breaker("database"))
+
+	for range 2 {
+		_, err := repo.GetByID(ctx, alice.ID)
+		assert.ErrorIs(t, err, driver.ErrBadConn)
+	}
+
+	// Open: calls fail without reaching the database
+	flaky.err = nil
+	user, err := repo.GetByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Nil(t, user)
+	assert.Equal(t, 2, flaky.calls)
+	assert.Equal(t, breaker.StateOpen, group.Snapshot()[0].State)
+
+	// Lookups of missing users succeed, so they never open the breaker
+	fresh := NewBreakerRepository(flaky, breaker.NewGroup(true, breaker.Options{FailureThreshold: 1}, nil, zap.NewNop()).Breaker("database"))
+	for range 3 {
+		user, err := fresh.GetByID(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Nil(t, user)
+	}
+	user, err = fresh.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+
+	assert.Same(t, flaky, NewBreakerRepository(flaky, nil))
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/cache"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+)
+
+// cachedRepository serves GetByID from an in-process cache. Entries are
+// dropped when the user is updated or deleted through this instance; changes
+// made by other instances show up once the entry expires.
+type cachedRepository struct {
+	domainUser.Repository
+	users *cache.Cache[uuid.UUID, domainUser.User]
+}
+
+// NewCachedRepository wraps repo so that user lookups by ID are cached.
+// Users are cached by value, so callers may modify the users they get back.
+func NewCachedRepository(repo domainUser.Repository, users *cache.Cache[uuid.UUID, domainUser.User]) domainUser.Repository {
+	if !users.Enabled() {
+		return repo
+	}
+	return &cachedRepository{Repository: repo, users: users}
+}
+
+func (r *cachedRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	// Inside a transaction the row may hold uncommitted changes, and callers
+	// forcing primary reads want the latest write
+	if transaction.Active(ctx) || replica.PrimaryForced(ctx) {
+		return r.Repository.GetByID(ctx, id)
+	}
+
+	if user, ok := r.users.Get(id); ok {
+		return clone(&user), nil
+	}
+
+	user, err := r.Repository.GetByID(ctx, id)
+	if err != nil || user == nil {
+		return user, err
+	}
+	r.users.Set(id, *clone(user))
+	return user, nil
+}
+
+func (r *cachedRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	if transaction.Active(ctx) || replica.PrimaryForced(ctx) {
+		return r.Repository.GetByIDs(ctx, ids)
+	}
+
+	// Serve what the cache holds and load the rest in one call
+	users := make([]*domainUser.User, 0, len(ids))
+	var missing []uuid.UUID
+	for _, id := range ids {
+		if user, ok := r.users.Get(id); ok {
+			users = append(users, clone(&user))
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	loaded, err := r.Repository.GetByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range loaded {
+		r.users.Set(user.ID, *clone(user))
+	}
+	return append(users, loaded...), nil
+}
+
+func (r *cachedRepository) Update(ctx context.Context, user *domainUser.User) error {
+	// Dropped again afterwards so a lookup racing the write cannot keep the old
+	// row. Writes in a transaction land on commit, so a lookup between the
+	// write and the commit can still cache the old row until it expires.
+	r.users.Delete(user.ID)
+	err := r.Repository.Update(ctx, user)
+	r.users.Delete(user.ID)
+	return err
+}
+
+func (r *cachedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.users.Delete(id)
+	err := r.Repository.Delete(ctx, id)
+	r.users.Delete(id)
+	return err
+}
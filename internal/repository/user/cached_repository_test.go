@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/cache"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// countingRepository serves users from memory and counts lookups
+type countingRepository struct {
+	domainUser.Repository
+	users   map[uuid.UUID]domainUser.User
+	lookups int
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	r.lookups++
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *countingRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	r.lookups++
+	users := []*domainUser.User{}
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users = append(users, &user)
+		}
+	}
+	return users, nil
+}
+
+func (r *countingRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	r.lookups++
+	for _, user := range r.users {
+		if user.NormalizedEmail == email {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *countingRepository) Update(ctx context.Context, user *domainUser.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *countingRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.users, id)
+	return nil
+}
+
+func newCachedTestRepository() (domainUser.Repository, *countingRepository, uuid.UUID) {
+	id := uuid.New()
+	inner := &countingRepository{users: map[uuid.UUID]domainUser.User{
+		id: {ID: id, Email: "ada@example.com", NormalizedEmail: "ada@example.com", IsActive: true},
+	}}
+	users := cache.New[uuid.UUID, domainUser.User]("users", cache.Config{MaxEntries: 10, TTL: time.Minute}, nil)
+	return NewCachedRepository(inner, users), inner, id
+}
+
+func TestCachedRepository_GetByID(t *testing.T) {
+	repo, inner, id := newCachedTestRepository()
+	ctx := context.Background()
+
+	first, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	first.Email = "changed@example.com" // Callers own the users they get back
+
+	second, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", second.Email)
+	assert.Equal(t, 1, inner.lookups)
+
+	// Missing users are not cached
+	missing, err := repo.GetByID(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	assert.Equal(t, 2, inner.lookups)
+}
+
+func TestCachedRepository_GetByIDs(t *testing.T) {
+	repo, inner, id := newCachedTestRepository()
+	ctx := context.Background()
+	other := uuid.New()
+	inner.users[other] = domainUser.User{ID: other, Email: "grace@example.com"}
+
+	_, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+
+	// Only the users missing from the cache are loaded, in one call
+	users, err := repo.GetByIDs(ctx, []uuid.UUID{id, other, uuid.New()})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, id, users[0].ID)
+	assert.Equal(t, other, users[1].ID)
+	assert.Equal(t, 2, inner.lookups)
+
+	users, err = repo.GetByIDs(ctx, []uuid.UUID{other, id})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, 2, inner.lookups)
+}
+
+func TestCachedRepository_WritesInvalidate(t *testing.T) {
+	repo, inner, id := newCachedTestRepository()
+	ctx := context.Background()
+
+	user, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	user.IsActive = false
+	require.NoError(t, repo.Update(ctx, user))
+
+	user, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, 2, inner.lookups)
+
+	require.NoError(t, repo.Delete(ctx, id))
+	user, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+func TestNewCachedRepository_Disabled(t *testing.T) {
+	inner := &countingRepository{}
+	users := cache.New[uuid.UUID, domainUser.User]("users", cache.Config{}, nil)
+
+	assert.Same(t, inner, NewCachedRepository(inner, users))
+}
@@ -0,0 +1,146 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/password"
+)
+
+// fixtureSeq numbers the users built by UserBuilder so their emails and
+// usernames never collide
+var fixtureSeq atomic.Int64
+
+// UserBuilder builds users for tests and seed data. The defaults are an
+// active user account with a unique ID, email and username and no password;
+// the With methods override them.
+//
+//	user, err := repoUser.NewUserBuilder().WithRole(rbac.RoleAdmin).Inactive().Create(ctx, repo)
+type UserBuilder struct {
+	user     domainUser.User
+	password string // Plain password hashed by Build
+}
+
+// NewUserBuilder creates a UserBuilder with the defaults
+func NewUserBuilder() *UserBuilder {
+	n := fixtureSeq.Add(1)
+	return &UserBuilder{user: domainUser.User{
+		ID:        uuid.New(),
+		Username:  fmt.Sprintf("user%d", n),
+		FirstName: "Test",
+		LastName:  fmt.Sprintf("User %d", n),
+		Email:     fmt.Sprintf("user%d@example.com", n),
+		Role:      rbac.RoleUser,
+		IsActive:  true,
+	}}
+}
+
+// WithID sets the ID of the user
+func (b *UserBuilder) WithID(id uuid.UUID) *UserBuilder {
+	b.user.ID = id
+	return b
+}
+
+// WithEmail sets the email of the user
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+// WithUsername sets the username of the user
+func (b *UserBuilder) WithUsername(username string) *UserBuilder {
+	b.user.Username = username
+	return b
+}
+
+// WithName sets the first and last name of the user
+func (b *UserBuilder) WithName(firstName, lastName string) *UserBuilder {
+	b.user.FirstName = firstName
+	b.user.LastName = lastName
+	return b
+}
+
+// WithPassword sets the plain password the user signs in with; Build stores its hash
+func (b *UserBuilder) WithPassword(plain string) *UserBuilder {
+	b.password = plain
+	return b
+}
+
+// WithRole sets the role of the user
+func (b *UserBuilder) WithRole(role rbac.Role) *UserBuilder {
+	b.user.Role = role
+	return b
+}
+
+// WithTenant sets the tenant the user belongs to
+func (b *UserBuilder) WithTenant(tenant string) *UserBuilder {
+	b.user.Tenant = tenant
+	return b
+}
+
+// WithMetadata sets a custom attribute of the user
+func (b *UserBuilder) WithMetadata(key, value string) *UserBuilder {
+	if b.user.Metadata == nil {
+		b.user.Metadata = map[string]string{}
+	}
+	b.user.Metadata[key] = value
+	return b
+}
+
+// WithResidency sets the data residency region of the user
+func (b *UserBuilder) WithResidency(residency string) *UserBuilder {
+	b.user.Residency = residency
+	return b
+}
+
+// WithCreatedAt sets when the user was created and last updated
+func (b *UserBuilder) WithCreatedAt(createdAt time.Time) *UserBuilder {
+	b.user.CreatedAt = createdAt
+	b.user.UpdatedAt = createdAt
+	return b
+}
+
+// Inactive builds a deactivated account
+func (b *UserBuilder) Inactive() *UserBuilder {
+	b.user.IsActive = false
+	return b
+}
+
+// PasswordResetRequired builds an account an administrator asked to choose a new password
+func (b *UserBuilder) PasswordResetRequired() *UserBuilder {
+	b.user.PasswordResetRequired = true
+	return b
+}
+
+// Build returns a new user with the settings of the builder. The password
+// is hashed with password.NewDefaultHasher and the email normalized by the
+// zero emailaddr.Normalizer.
+func (b *UserBuilder) Build() (*domainUser.User, error) {
+	user := b.user
+	user.NormalizedEmail = emailaddr.Normalizer{}.Normalize(user.Email)
+	if b.password != "" {
+		user.Password = b.password
+		if err := user.HashPassword(password.NewDefaultHasher()); err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
+
+// Create builds the user and stores it in repo
+func (b *UserBuilder) Create(ctx context.Context, repo domainUser.Repository) (*domainUser.User, error) {
+	user, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
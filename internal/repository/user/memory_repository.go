@@ -0,0 +1,222 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"gorm.io/gorm"
+)
+
+// errDuplicate is returned for conflicting writes, as translateError
+// reports the unique violations of the GORM repository
+var errDuplicate = fmt.Errorf("%w: %w", domainUser.ErrDuplicate, gorm.ErrDuplicatedKey)
+
+// inMemoryRepository keeps users in process memory. It behaves like the
+// GORM repository: lookups of missing users return nil without an error,
+// IDs, emails and usernames are unique, and timestamps are kept with
+// microsecond precision, as Postgres keeps them.
+type inMemoryRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]domainUser.User
+}
+
+// NewInMemoryRepository creates an empty in-memory domainUser.Repository,
+// for tests and local tooling that should not need a database. Every call
+// returns a copy, so callers cannot change stored users by accident.
+func NewInMemoryRepository(users ...*domainUser.User) domainUser.Repository {
+	r := &inMemoryRepository{users: map[uuid.UUID]domainUser.User{}}
+	for _, user := range users {
+		r.users[user.ID] = *clone(user)
+	}
+	return r
+}
+
+// now returns the current time at the precision users are stored with
+func (r *inMemoryRepository) now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func (r *inMemoryRepository) Create(ctx context.Context, user *domainUser.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(user)
+}
+
+func (r *inMemoryRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Roll back on a conflict so either all users are stored or none is
+	created := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		if err := r.create(user); err != nil {
+			for _, id := range created {
+				delete(r.users, id)
+			}
+			return err
+		}
+		created = append(created, user.ID)
+	}
+	return nil
+}
+
+// create stores a new user, setting its timestamps as autoCreateTime does
+func (r *inMemoryRepository) create(user *domainUser.User) error {
+	if _, ok := r.users[user.ID]; ok || r.conflicts(user) {
+		return errDuplicate
+	}
+	now := r.now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	r.users[user.ID] = *clone(user)
+	return nil
+}
+
+// conflicts reports whether another user already has the email, normalized
+// email or username of user
+func (r *inMemoryRepository) conflicts(user *domainUser.User) bool {
+	for id, other := range r.users {
+		if id != user.ID && (other.Email == user.Email || other.NormalizedEmail == user.NormalizedEmail || other.Username == user.Username) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *inMemoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil // User not found
+	}
+	return clone(&user), nil
+}
+
+func (r *inMemoryRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*domainUser.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok {
+			users = append(users, clone(&user))
+		}
+	}
+	return users, nil
+}
+
+func (r *inMemoryRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	return r.find(func(u *domainUser.User) bool { return u.NormalizedEmail == email }), nil
+}
+
+func (r *inMemoryRepository) GetByUsername(ctx context.Context, username string) (*domainUser.User, error) {
+	return r.find(func(u *domainUser.User) bool { return u.Username == username }), nil
+}
+
+// find returns a copy of the first user matching match, or nil
+func (r *inMemoryRepository) find(match func(*domainUser.User) bool) *domainUser.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if match(&user) {
+			return clone(&user)
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) Update(ctx context.Context, user *domainUser.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conflicts(user) {
+		return errDuplicate
+	}
+	// Callers render the new version of the user
+	user.UpdatedAt = r.now()
+	r.users[user.ID] = *clone(user)
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+func (r *inMemoryRepository) List(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	users := r.filtered(filter)
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+	return page(users, filter.Offset, filter.Limit), int64(len(users)), nil
+}
+
+func (r *inMemoryRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	users := r.filtered(filter)
+	sort.Slice(users, func(i, j int) bool {
+		return bytes.Compare(users[i].ID[:], users[j].ID[:]) < 0
+	})
+	if afterID != uuid.Nil {
+		start := sort.Search(len(users), func(i int) bool {
+			return bytes.Compare(users[i].ID[:], afterID[:]) > 0
+		})
+		users = users[start:]
+	}
+	return page(users, 0, filter.Limit), nil
+}
+
+// filtered returns copies of the users matching the criteria of filter, in no particular order
+func (r *inMemoryRepository) filtered(filter domainUser.ListFilter) []*domainUser.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	query := strings.ToLower(filter.Query)
+	users := make([]*domainUser.User, 0, len(r.users))
+	for _, user := range r.users {
+		switch {
+		case query != "" && !strings.Contains(strings.ToLower(user.Email), query) &&
+			!strings.Contains(strings.ToLower(user.Username), query) &&
+			!strings.Contains(strings.ToLower(user.FirstName), query) &&
+			!strings.Contains(strings.ToLower(user.LastName), query):
+		case filter.Role != "" && user.Role != filter.Role:
+		case filter.Active != nil && user.IsActive != *filter.Active:
+		case filter.Tenant != "" && user.Tenant != filter.Tenant:
+		case !hasMetadata(user, filter.Metadata):
+		default:
+			users = append(users, clone(&user))
+		}
+	}
+	return users
+}
+
+// hasMetadata reports whether user has every one of the attributes
+func hasMetadata(user domainUser.User, attributes map[string]string) bool {
+	for key, value := range attributes {
+		if actual, ok := user.Metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// page applies SQL OFFSET and LIMIT to users; a negative limit returns every user after offset
+func page(users []*domainUser.User, offset, limit int) []*domainUser.User {
+	if offset >= len(users) {
+		return []*domainUser.User{}
+	}
+	users = users[max(offset, 0):]
+	if limit >= 0 && limit < len(users) {
+		users = users[:limit]
+	}
+	return users
+}
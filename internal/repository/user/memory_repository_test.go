@@ -0,0 +1,137 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+func TestInMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	alice, err := NewUserBuilder().WithEmail("alice@example.com").WithName("Alice", "Smith").WithPassword("s3cret!").WithCreatedAt(start).Create(ctx, repo)
+	require.NoError(t, err)
+	bob, err := NewUserBuilder().WithRole(rbac.RoleAdmin).WithTenant("acme").WithMetadata("plan", "pro").WithMetadata("team", "core").WithCreatedAt(start.Add(time.Hour)).Create(ctx, repo)
+	require.NoError(t, err)
+	carol, err := NewUserBuilder().Inactive().WithTenant("acme").Create(ctx, repo)
+	require.NoError(t, err)
+	assert.False(t, carol.CreatedAt.IsZero())
+
+	t.Run("Lookups", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, "alice@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, alice, found)
+		assert.True(t, found.CheckPassword("s3cret!"))
+
+		found, err = repo.GetByUsername(ctx, bob.Username)
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, found.ID)
+
+		found, err = repo.GetByID(ctx, uuid.New())
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		users, err := repo.GetByIDs(ctx, []uuid.UUID{bob.ID, uuid.New(), alice.ID})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*domainUser.User{alice, bob}, users)
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		err := repo.Create(ctx, &domainUser.User{ID: uuid.New(), Email: "alice@example.com", Username: "other"})
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+		assert.ErrorIs(t, err, domainUser.ErrDuplicate)
+
+		// A batch with a conflict stores nothing
+		fresh, _ := NewUserBuilder().Build()
+		err = repo.CreateBatch(ctx, []*domainUser.User{fresh, {ID: uuid.New(), Email: "x@example.com", Username: bob.Username}})
+		assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+		found, err := repo.GetByID(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		active := true
+		tests := []struct {
+			name     string
+			filter   domainUser.ListFilter
+			expected []uuid.UUID
+			total    int64
+		}{
+			{name: "Newest First", filter: domainUser.ListFilter{Limit: 10}, expected: []uuid.UUID{carol.ID, bob.ID, alice.ID}, total: 3},
+			{name: "Paged", filter: domainUser.ListFilter{Offset: 1, Limit: 1}, expected: []uuid.UUID{bob.ID}, total: 3},
+			{name: "Query", filter: domainUser.ListFilter{Query: "SMITH", Limit: 10}, expected: []uuid.UUID{alice.ID}, total: 1},
+			{name: "Tenant And Active", filter: domainUser.ListFilter{Tenant: "acme", Active: &active, Limit: 10}, expected: []uuid.UUID{bob.ID}, total: 1},
+			{name: "Role", filter: domainUser.ListFilter{Role: rbac.RoleAdmin, Limit: 10}, expected: []uuid.UUID{bob.ID}, total: 1},
+			{name: "Metadata", filter: domainUser.ListFilter{Metadata: map[string]string{"plan": "pro", "team": "core"}, Limit: 10}, expected: []uuid.UUID{bob.ID}, total: 1},
+			{name: "Metadata Mismatch", filter: domainUser.ListFilter{Metadata: map[string]string{"plan": "pro", "team": "sales"}, Limit: 10}, expected: []uuid.UUID{}, total: 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				users, total, err := repo.List(ctx, tt.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tt.total, total)
+				ids := make([]uuid.UUID, 0, len(users))
+				for _, u := range users {
+					ids = append(ids, u.ID)
+				}
+				assert.Equal(t, tt.expected, ids)
+			})
+		}
+	})
+
+	t.Run("ListAfter", func(t *testing.T) {
+		var seen []uuid.UUID
+		after := uuid.Nil
+		for {
+			users, err := repo.ListAfter(ctx, domainUser.ListFilter{Limit: 2}, after)
+			require.NoError(t, err)
+			if len(users) == 0 {
+				break
+			}
+			for _, u := range users {
+				seen = append(seen, u.ID)
+			}
+			after = users[len(users)-1].ID
+		}
+		assert.ElementsMatch(t, []uuid.UUID{alice.ID, bob.ID, carol.ID}, seen)
+	})
+
+	t.Run("Update And Delete", func(t *testing.T) {
+		found, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		found.FirstName = "Alicia"
+		require.NoError(t, repo.Update(ctx, found))
+		assert.NotEqual(t, alice.Version(), found.Version())
+
+		stored, err := repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alicia", stored.FirstName)
+
+		found, err = repo.GetByID(ctx, bob.ID)
+		require.NoError(t, err)
+		found.Metadata["plan"] = "free"
+		stored, err = repo.GetByID(ctx, bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "pro", stored.Metadata["plan"], "metadata of returned users must not alias the stored user")
+
+		stored.Email = alice.Email
+		assert.ErrorIs(t, repo.Update(ctx, stored), gorm.ErrDuplicatedKey)
+
+		require.NoError(t, repo.Delete(ctx, alice.ID))
+		stored, err = repo.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+}
@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NormalizationModel records a normalization every stored email address
+// has been rewritten with
+type NormalizationModel struct {
+	Name        string    `gorm:"size:32;primaryKey"`
+	CompletedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the NormalizationModel.
+func (NormalizationModel) TableName() string {
+	return "email_normalizations"
+}
+
+type normalizationRepository struct {
+	db *gorm.DB
+}
+
+// NewNormalizationRepository creates a new instance of
+// domainUser.NormalizationRepository. It always reads from db, as a
+// completion recorded moments ago must not be missed on a replica.
+func NewNormalizationRepository(db *gorm.DB) domainUser.NormalizationRepository {
+	return &normalizationRepository{db: db}
+}
+
+func (r *normalizationRepository) RecordNormalization(ctx context.Context, name string) error {
+	model := &NormalizationModel{Name: name, CompletedAt: time.Now()}
+	if err := transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to record email normalization %s: %w", name, err)
+	}
+	return nil
+}
+
+func (r *normalizationRepository) NormalizationCompleted(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := transaction.DB(ctx, r.db).Model(&NormalizationModel{}).Where("name = ?", name).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to look up email normalization %s: %w", name, err)
+	}
+	return count > 0, nil
+}
+
+// inMemoryNormalizationRepository keeps recorded normalizations in process
+// memory, alongside the users of an in-memory domainUser.Repository
+type inMemoryNormalizationRepository struct {
+	mu        sync.Mutex
+	completed map[string]bool
+}
+
+// NewInMemoryNormalizationRepository creates a
+// domainUser.NormalizationRepository in process memory with names already
+// recorded, for tests and local tooling that should not need a database
+func NewInMemoryNormalizationRepository(names ...string) domainUser.NormalizationRepository {
+	r := &inMemoryNormalizationRepository{completed: map[string]bool{}}
+	for _, name := range names {
+		r.completed[name] = true
+	}
+	return r
+}
+
+func (r *inMemoryNormalizationRepository) RecordNormalization(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed[name] = true
+	return nil
+}
+
+func (r *inMemoryNormalizationRepository) NormalizationCompleted(ctx context.Context, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.completed[name], nil
+}
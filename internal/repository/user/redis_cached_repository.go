@@ -0,0 +1,215 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/cache"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
+)
+
+// Names of the Redis user caches in the cache metrics
+const (
+	redisUsersCache      = "redis_users"
+	redisUserEmailsCache = "redis_user_emails"
+)
+
+// RedisCache is the part of the Redis client used by the user cache
+type RedisCache interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// redisCachedRepository reads users through Redis, where every instance
+// sees the same entries. Users are cached by ID; lookups by email go through
+// an index from email to ID.
+type redisCachedRepository struct {
+	domainUser.Repository
+	client   RedisCache
+	keys     rediskey.Schema
+	ttl      time.Duration
+	byID     cache.Counter
+	byEmails cache.Counter
+}
+
+// NewRedisCachedRepository wraps repo so that user lookups by ID and email
+// are read through Redis for up to ttl. Entries are dropped when a user is
+// updated, including password changes, or deleted. Redis errors are not
+// reported: lookups fall through to repo. A zero ttl disables the cache.
+func NewRedisCachedRepository(repo domainUser.Repository, client RedisCache, keys rediskey.Schema, ttl time.Duration, metrics *cache.Metrics) domainUser.Repository {
+	if ttl <= 0 {
+		return repo
+	}
+	return &redisCachedRepository{
+		Repository: repo,
+		client:     client,
+		keys:       keys,
+		ttl:        ttl,
+		byID:       metrics.Counter(redisUsersCache),
+		byEmails:   metrics.Counter(redisUserEmailsCache),
+	}
+}
+
+func (r *redisCachedRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	// Inside a transaction the row may hold uncommitted changes, and callers
+	// forcing primary reads want the latest write
+	if transaction.Active(ctx) || replica.PrimaryForced(ctx) {
+		return r.Repository.GetByID(ctx, id)
+	}
+
+	if user, ok := r.cached(ctx, id); ok {
+		r.byID.Hit()
+		return user, nil
+	}
+	r.byID.Miss()
+
+	user, err := r.Repository.GetByID(ctx, id)
+	if err != nil || user == nil {
+		return user, err
+	}
+	r.store(ctx, user)
+	return user, nil
+}
+
+func (r *redisCachedRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	if transaction.Active(ctx) || replica.PrimaryForced(ctx) {
+		return r.Repository.GetByEmail(ctx, email)
+	}
+
+	if id, err := uuid.Parse(r.client.Get(ctx, r.keys.UserIDByEmail(email)).Val()); err == nil {
+		// The index outlives email changes, so the user must still have the email
+		if user, ok := r.cached(ctx, id); ok && user.NormalizedEmail == email {
+			r.byEmails.Hit()
+			return user, nil
+		}
+	}
+	r.byEmails.Miss()
+
+	user, err := r.Repository.GetByEmail(ctx, email)
+	if err != nil || user == nil {
+		return user, err
+	}
+	r.store(ctx, user)
+	return user, nil
+}
+
+func (r *redisCachedRepository) Update(ctx context.Context, user *domainUser.User) error {
+	// Dropped again afterwards so a lookup racing the write cannot keep the old
+	// row. Writes in a transaction land on commit, so a lookup between the
+	// write and the commit can still cache the old row until it expires.
+	r.client.Del(ctx, r.keys.User(user.ID))
+	err := r.Repository.Update(ctx, user)
+	r.client.Del(ctx, r.keys.User(user.ID))
+	return err
+}
+
+func (r *redisCachedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.client.Del(ctx, r.keys.User(id))
+	err := r.Repository.Delete(ctx, id)
+	r.client.Del(ctx, r.keys.User(id))
+	return err
+}
+
+// cached returns the cached user with id, if any
+func (r *redisCachedRepository) cached(ctx context.Context, id uuid.UUID) (*domainUser.User, bool) {
+	data, err := r.client.Get(ctx, r.keys.User(id)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var entry redisUser
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return entry.toDomain(), true
+}
+
+// store caches user by ID and indexes it by normalized email
+func (r *redisCachedRepository) store(ctx context.Context, user *domainUser.User) {
+	data, err := json.Marshal(newRedisUser(user))
+	if err != nil {
+		return
+	}
+	r.client.Set(ctx, r.keys.User(user.ID), data, r.ttl)
+	r.client.Set(ctx, r.keys.UserIDByEmail(user.NormalizedEmail), user.ID.String(), r.ttl)
+}
+
+// redisUser is the cached form of a user. Unlike the JSON form of
+// domainUser.User it keeps the password hash, which sign-in reads.
+type redisUser struct {
+	ID                    uuid.UUID         `json:"id"`
+	Username              string            `json:"username"`
+	FirstName             string            `json:"first_name"`
+	LastName              string            `json:"last_name"`
+	DisplayName           string            `json:"display_name"`
+	PasswordHash          string            `json:"password_hash"`
+	Email                 string            `json:"email"`
+	NormalizedEmail       string            `json:"normalized_email"`
+	Residency             string            `json:"residency"`
+	Tenant                string            `json:"tenant"`
+	Locale                string            `json:"locale"`
+	Role                  rbac.Role         `json:"role"`
+	IsActive              bool              `json:"is_active"`
+	PasswordResetRequired bool              `json:"password_reset_required"`
+	PasswordChangedAt     time.Time         `json:"password_changed_at"`
+	PasswordExpiresAt     *time.Time        `json:"password_expires_at,omitempty"`
+	Metadata              map[string]string `json:"metadata,omitempty"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
+}
+
+func newRedisUser(user *domainUser.User) redisUser {
+	return redisUser{
+		ID:                    user.ID,
+		Username:              user.Username,
+		FirstName:             user.FirstName,
+		LastName:              user.LastName,
+		DisplayName:           user.DisplayName,
+		PasswordHash:          user.Password,
+		Email:                 user.Email,
+		NormalizedEmail:       user.NormalizedEmail,
+		Residency:             user.Residency,
+		Tenant:                user.Tenant,
+		Locale:                user.Locale,
+		Role:                  user.Role,
+		IsActive:              user.IsActive,
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordChangedAt:     user.PasswordChangedAt,
+		PasswordExpiresAt:     user.PasswordExpiresAt,
+		Metadata:              user.Metadata,
+		CreatedAt:             user.CreatedAt,
+		UpdatedAt:             user.UpdatedAt,
+	}
+}
+
+func (u redisUser) toDomain() *domainUser.User {
+	return &domainUser.User{
+		ID:                    u.ID,
+		Username:              u.Username,
+		FirstName:             u.FirstName,
+		LastName:              u.LastName,
+		DisplayName:           u.DisplayName,
+		Password:              u.PasswordHash,
+		Email:                 u.Email,
+		NormalizedEmail:       u.NormalizedEmail,
+		Residency:             u.Residency,
+		Tenant:                u.Tenant,
+		Locale:                u.Locale,
+		Role:                  u.Role,
+		IsActive:              u.IsActive,
+		PasswordResetRequired: u.PasswordResetRequired,
+		PasswordChangedAt:     u.PasswordChangedAt,
+		PasswordExpiresAt:     u.PasswordExpiresAt,
+		Metadata:              u.Metadata,
+		CreatedAt:             u.CreatedAt,
+		UpdatedAt:             u.UpdatedAt,
+	}
+}
@@ -0,0 +1,214 @@
+package user
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/cache"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/rediskey"
+)
+
+// fakeRedisCache keeps string values in memory and records their TTLs
+type fakeRedisCache struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func newFakeRedisCache() *fakeRedisCache {
+	return &fakeRedisCache{values: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeRedisCache) Get(ctx context.Context, key string) *redis.StringCmd {
+	value, ok := f.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (f *fakeRedisCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	switch v := value.(type) {
+	case []byte:
+		f.values[key] = string(v)
+	default:
+		f.values[key] = v.(string)
+	}
+	f.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedisCache) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var n int64
+	for _, key := range keys {
+		if _, ok := f.values[key]; ok {
+			delete(f.values, key)
+			n++
+		}
+	}
+	return redis.NewIntResult(n, nil)
+}
+
+// cacheCounts returns the hits and misses recorded for a cache
+func cacheCounts(t *testing.T, registry *prometheus.Registry, name string) (hits, misses float64) {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() != "cache" || label.GetValue() != name {
+					continue
+				}
+				switch family.GetName() {
+				case "cache_hits_total":
+					hits = metric.GetCounter().GetValue()
+				case "cache_misses_total":
+					misses = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return hits, misses
+}
+
+func newRedisCachedTestRepository(t *testing.T) (domainUser.Repository, *countingRepository, *fakeRedisCache, *prometheus.Registry, uuid.UUID) {
+	t.Helper()
+	id := uuid.New()
+	inner := &countingRepository{users: map[uuid.UUID]domainUser.User{
+		id: {ID: id, Email: "Ada@example.com", NormalizedEmail: "ada@example.com", Password: "hash", IsActive: true},
+	}}
+	registry := prometheus.NewRegistry()
+	metrics, err := cache.NewMetrics(registry)
+	require.NoError(t, err)
+	keys, err := rediskey.New("test")
+	require.NoError(t, err)
+	client := newFakeRedisCache()
+	return NewRedisCachedRepository(inner, client, keys, time.Minute, metrics), inner, client, registry, id
+}
+
+func TestRedisCachedRepository_GetByID(t *testing.T) {
+	repo, inner, client, registry, id := newRedisCachedTestRepository(t)
+	ctx := context.Background()
+
+	first, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, client.ttls["go-user-service:test:users:v1:user:"+id.String()+":record"])
+
+	second, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "hash", second.Password, "sign-in needs the password hash")
+	assert.Equal(t, 1, inner.lookups)
+
+	// Missing users are not cached
+	missing, err := repo.GetByID(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	assert.Equal(t, 2, inner.lookups)
+
+	hits, misses := cacheCounts(t, registry, "redis_users")
+	assert.Equal(t, float64(1), hits)
+	assert.Equal(t, float64(2), misses)
+}
+
+// Every field must survive the cache, since updates save the cached user back
+func TestRedisCachedRepository_KeepsEveryField(t *testing.T) {
+	repo, inner, _, _, id := newRedisCachedTestRepository(t)
+	ctx := context.Background()
+	changedAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	expiresAt := changedAt.Add(90 * 24 * time.Hour)
+	inner.users[id] = domainUser.User{
+		ID:                    id,
+		Username:              "ada",
+		FirstName:             "Ada",
+		LastName:              "Lovelace",
+		DisplayName:           "Countess",
+		Password:              "hash",
+		Email:                 "Ada@example.com",
+		NormalizedEmail:       "ada@example.com",
+		Residency:             "EU",
+		Tenant:                "acme",
+		Locale:                "zh",
+		Role:                  rbac.RoleAdmin,
+		IsActive:              true,
+		PasswordResetRequired: true,
+		PasswordChangedAt:     changedAt,
+		PasswordExpiresAt:     &expiresAt,
+		Metadata:              map[string]string{"plan": "pro"},
+		CreatedAt:             changedAt,
+		UpdatedAt:             changedAt.Add(time.Hour),
+	}
+	fields := reflect.ValueOf(inner.users[id])
+	for i := 0; i < fields.NumField(); i++ {
+		require.False(t, fields.Field(i).IsZero(), "set User.%s so the cache is checked to keep it", fields.Type().Field(i).Name)
+	}
+
+	_, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	cached, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.lookups)
+	assert.Equal(t, inner.users[id], *cached)
+}
+
+func TestRedisCachedRepository_GetByEmail(t *testing.T) {
+	repo, inner, _, registry, id := newRedisCachedTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.GetByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, id, user.ID)
+
+	_, err = repo.GetByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.lookups, "a lookup by email also caches the user by ID")
+
+	hits, misses := cacheCounts(t, registry, "redis_user_emails")
+	assert.Equal(t, float64(1), hits)
+	assert.Equal(t, float64(1), misses)
+}
+
+func TestRedisCachedRepository_WritesInvalidate(t *testing.T) {
+	repo, inner, _, _, id := newRedisCachedTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.GetByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	user.Email = "lovelace@example.com"
+	user.NormalizedEmail = "lovelace@example.com"
+	user.Password = "new-hash"
+	require.NoError(t, repo.Update(ctx, user))
+
+	user, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", user.Password)
+
+	// The old email no longer finds the user although its index entry remains
+	user, err = repo.GetByEmail(ctx, "ada@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	require.NoError(t, repo.Delete(ctx, id))
+	user, err = repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+	assert.Equal(t, 4, inner.lookups)
+}
+
+func TestNewRedisCachedRepository_Disabled(t *testing.T) {
+	inner := &countingRepository{}
+
+	assert.Same(t, inner, NewRedisCachedRepository(inner, newFakeRedisCache(), rediskey.Schema{}, 0, nil))
+}
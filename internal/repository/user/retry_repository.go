@@ -0,0 +1,70 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/repository/retry"
+)
+
+// retryRepository retries the calls to repo that fail with a transient
+// database error, with the policy of their kind of operation
+type retryRepository struct {
+	repo   domainUser.Repository
+	reads  retry.Policy
+	writes retry.Policy
+}
+
+// NewRetryRepository wraps repo so that lookups and listings are retried
+// with reads, and creations, updates and deletions with writes
+func NewRetryRepository(repo domainUser.Repository, reads, writes retry.Policy) domainUser.Repository {
+	return &retryRepository{repo: repo, reads: reads, writes: writes}
+}
+
+func (r *retryRepository) Create(ctx context.Context, user *domainUser.User) error {
+	return r.writes.Do(ctx, func() error { return r.repo.Create(ctx, user) })
+}
+
+func (r *retryRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	return r.writes.Do(ctx, func() error { return r.repo.CreateBatch(ctx, users) })
+}
+
+func (r *retryRepository) GetByID(ctx context.Context, id uuid.UUID) (user *domainUser.User, err error) {
+	err = r.reads.Do(ctx, func() error { user, err = r.repo.GetByID(ctx, id); return err })
+	return user, err
+}
+
+func (r *retryRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (users []*domainUser.User, err error) {
+	err = r.reads.Do(ctx, func() error { users, err = r.repo.GetByIDs(ctx, ids); return err })
+	return users, err
+}
+
+func (r *retryRepository) GetByEmail(ctx context.Context, email string) (user *domainUser.User, err error) {
+	err = r.reads.Do(ctx, func() error { user, err = r.repo.GetByEmail(ctx, email); return err })
+	return user, err
+}
+
+func (r *retryRepository) GetByUsername(ctx context.Context, username string) (user *domainUser.User, err error) {
+	err = r.reads.Do(ctx, func() error { user, err = r.repo.GetByUsername(ctx, username); return err })
+	return user, err
+}
+
+func (r *retryRepository) Update(ctx context.Context, user *domainUser.User) error {
+	return r.writes.Do(ctx, func() error { return r.repo.Update(ctx, user) })
+}
+
+func (r *retryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.writes.Do(ctx, func() error { return r.repo.Delete(ctx, id) })
+}
+
+func (r *retryRepository) List(ctx context.Context, filter domainUser.ListFilter) (users []*domainUser.User, total int64, err error) {
+	err = r.reads.Do(ctx, func() error { users, total, err = r.repo.List(ctx, filter); return err })
+	return users, total, err
+}
+
+func (r *retryRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) (users []*domainUser.User, err error) {
+	err = r.reads.Do(ctx, func() error { users, err = r.repo.ListAfter(ctx, filter, afterID); return err })
+	return users, err
+}
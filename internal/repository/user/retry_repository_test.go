@@ -0,0 +1,57 @@
+package user
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/repository/retry"
+)
+
+// droppingRepository loses the connection on the first failures calls to Update
+type droppingRepository struct {
+	flakyRepository
+	failures int
+	updates  int
+}
+
+func (r *droppingRepository) Update(ctx context.Context, user *domainUser.User) error {
+	r.updates++
+	if r.updates <= r.failures {
+		return driver.ErrBadConn
+	}
+	return r.Repository.Update(ctx, user)
+}
+
+func TestRetryRepository(t *testing.T) {
+	ctx := context.Background()
+	alice, _ := NewUserBuilder().Build()
+	stub := &droppingRepository{flakyRepository: flakyRepository{Repository: NewInMemoryRepository(alice), err: driver.ErrBadConn}, failures: 1}
+	reads := retry.Policy{Attempts: 3, Backoff: time.Millisecond}
+	writes := retry.Policy{Attempts: 1}
+	repo := NewRetryRepository(stub, reads, writes)
+
+	// Reads use their own policy
+	_, err := repo.GetByID(ctx, alice.ID)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 3, stub.calls)
+
+	stub.err = nil
+	user, err := repo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+
+	// Writes are tried once
+	assert.ErrorIs(t, repo.Update(ctx, user), driver.ErrBadConn)
+	assert.Equal(t, 1, stub.updates)
+
+	stub.updates = 0
+	repo = NewRetryRepository(stub, reads, retry.Policy{Attempts: 2, Backoff: time.Millisecond})
+	require.NoError(t, repo.Update(ctx, user))
+	assert.Equal(t, 2, stub.updates)
+}
@@ -1,22 +1,38 @@
 package user
 
 import (
+	"maps"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
 )
 
 // UserModel represents the user structure for database interactions.
 type UserModel struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	Username  string    `gorm:"uniqueIndex;not null"`
-	FirstName string
-	LastName  string
-	Password  string `gorm:"not null"`
-	Email     string `gorm:"uniqueIndex;not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Username    string    `gorm:"uniqueIndex;not null"`
+	FirstName   string
+	LastName    string
+	DisplayName string `gorm:"size:64;not null;default:''"`
+	Password    string `gorm:"not null"`
+	Email       string `gorm:"uniqueIndex;not null"`
+	// NormalizedEmail is unique so addresses differing only in spelling
+	// cannot create separate accounts
+	NormalizedEmail string `gorm:"size:255;uniqueIndex;not null"`
+	Residency       string `gorm:"size:16;index"`
+	Tenant          string `gorm:"size:64;index"`
+	Locale          string `gorm:"size:16;not null;default:''"`
+	Role            string `gorm:"size:32;not null;default:user"`
+	// No gorm default: it would turn an explicit false into true on create
+	IsActive              bool      `gorm:"not null"`
+	PasswordResetRequired bool      `gorm:"not null"`
+	PasswordChangedAt     time.Time `gorm:"not null"`
+	PasswordExpiresAt     *time.Time
+	Metadata              map[string]string `gorm:"type:jsonb;serializer:json;not null;default:'{}'"`
+	CreatedAt             time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt             time.Time         `gorm:"autoUpdateTime"`
 }
 
 // TableName specifies the table name for the UserModel.
@@ -30,14 +46,25 @@ func ToDomainUser(userModel *UserModel) *domainUser.User {
 		return nil
 	}
 	return &domainUser.User{
-		ID:        userModel.ID,
-		Username:  userModel.Username,
-		FirstName: userModel.FirstName,
-		LastName:  userModel.LastName,
-		Password:  userModel.Password,
-		Email:     userModel.Email,
-		CreatedAt: userModel.CreatedAt,
-		UpdatedAt: userModel.UpdatedAt,
+		ID:                    userModel.ID,
+		Username:              userModel.Username,
+		FirstName:             userModel.FirstName,
+		LastName:              userModel.LastName,
+		DisplayName:           userModel.DisplayName,
+		Password:              userModel.Password,
+		Email:                 userModel.Email,
+		NormalizedEmail:       userModel.NormalizedEmail,
+		Residency:             userModel.Residency,
+		Tenant:                userModel.Tenant,
+		Locale:                userModel.Locale,
+		Role:                  rbac.Role(userModel.Role),
+		IsActive:              userModel.IsActive,
+		PasswordResetRequired: userModel.PasswordResetRequired,
+		PasswordChangedAt:     userModel.PasswordChangedAt,
+		PasswordExpiresAt:     userModel.PasswordExpiresAt,
+		Metadata:              userModel.Metadata,
+		CreatedAt:             userModel.CreatedAt,
+		UpdatedAt:             userModel.UpdatedAt,
 	}
 }
 
@@ -47,13 +74,31 @@ func FromDomainUser(domainUser *domainUser.User) *UserModel {
 		return nil
 	}
 	return &UserModel{
-		ID:        domainUser.ID,
-		Username:  domainUser.Username,
-		FirstName: domainUser.FirstName,
-		LastName:  domainUser.LastName,
-		Password:  domainUser.Password,
-		Email:     domainUser.Email,
-		CreatedAt: domainUser.CreatedAt,
-		UpdatedAt: domainUser.UpdatedAt,
+		ID:                    domainUser.ID,
+		Username:              domainUser.Username,
+		FirstName:             domainUser.FirstName,
+		LastName:              domainUser.LastName,
+		DisplayName:           domainUser.DisplayName,
+		Password:              domainUser.Password,
+		Email:                 domainUser.Email,
+		NormalizedEmail:       domainUser.NormalizedEmail,
+		Residency:             domainUser.Residency,
+		Tenant:                domainUser.Tenant,
+		Locale:                domainUser.Locale,
+		Role:                  string(domainUser.Role),
+		IsActive:              domainUser.IsActive,
+		PasswordResetRequired: domainUser.PasswordResetRequired,
+		PasswordChangedAt:     domainUser.PasswordChangedAt,
+		PasswordExpiresAt:     domainUser.PasswordExpiresAt,
+		Metadata:              domainUser.Metadata,
+		CreatedAt:             domainUser.CreatedAt,
+		UpdatedAt:             domainUser.UpdatedAt,
 	}
 }
+
+// clone copies user along with its metadata, so the copy shares nothing with user
+func clone(user *domainUser.User) *domainUser.User {
+	c := *user
+	c.Metadata = maps.Clone(user.Metadata)
+	return &c
+}
@@ -2,29 +2,64 @@ package user
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/repository/replica"
+	"github.com/yi-tech/go-user-service/internal/repository/transaction"
 	"gorm.io/gorm"
 )
 
 type userRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	replicas *replica.Pool
 }
 
 // NewUserRepository creates a new instance of domainUser.Repository.
-func NewUserRepository(db *gorm.DB) domainUser.Repository {
-	return &userRepository{db: db}
+// Lookups by ID and email and listings read from replicas, which may lag
+// behind the primary; use replica.WithPrimary to read back a fresh write.
+// A nil pool reads everything from db.
+func NewUserRepository(db *gorm.DB, replicas *replica.Pool) domainUser.Repository {
+	return &userRepository{db: db, replicas: replicas}
 }
 
 func (r *userRepository) Create(ctx context.Context, user *domainUser.User) error {
 	userModel := FromDomainUser(user)
-	return r.db.WithContext(ctx).Create(userModel).Error
+	return translateError(transaction.DB(ctx, r.db).Create(userModel).Error)
+}
+
+func (r *userRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	models := make([]*UserModel, len(users))
+	for i, user := range users {
+		models[i] = FromDomainUser(user)
+	}
+	return translateError(transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(models, len(models)).Error
+	}))
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
 	var userModel UserModel
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&userModel).Error
+	err := r.replicas.Reader(ctx, r.db).Where("normalized_email = ?", email).First(&userModel).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+	return ToDomainUser(&userModel), nil
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domainUser.User, error) {
+	var userModel UserModel
+	err := transaction.DB(ctx, r.db).Where("username = ?", username).First(&userModel).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil // User not found
@@ -36,7 +71,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domainU
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
 	var userModel UserModel
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&userModel).Error
+	err := r.replicas.Reader(ctx, r.db).Where("id = ?", id).First(&userModel).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil // User not found
@@ -46,11 +81,121 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser
 	return ToDomainUser(&userModel), nil
 }
 
+func (r *userRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	if len(ids) == 0 {
+		return []*domainUser.User{}, nil
+	}
+	var userModels []UserModel
+	if err := r.replicas.Reader(ctx, r.db).Where("id IN ?", ids).Find(&userModels).Error; err != nil {
+		return nil, err
+	}
+	return toDomainUsers(userModels), nil
+}
+
 func (r *userRepository) Update(ctx context.Context, user *domainUser.User) error {
 	userModel := FromDomainUser(user)
-	return r.db.WithContext(ctx).Save(userModel).Error
+	if err := transaction.DB(ctx, r.db).Save(userModel).Error; err != nil {
+		return translateError(err)
+	}
+	// Callers render the new version of the user
+	user.UpdatedAt = userModel.UpdatedAt
+	return nil
 }
 
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&UserModel{}).Error
+	return transaction.DB(ctx, r.db).Where("id = ?", id).Delete(&UserModel{}).Error
+}
+
+func (r *userRepository) List(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	query := r.filtered(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var userModels []UserModel
+	err := query.
+		Order("created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&userModels).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return toDomainUsers(userModels), total, nil
+}
+
+func (r *userRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	query := r.filtered(ctx, filter)
+	if afterID != uuid.Nil {
+		query = query.Where("id > ?", afterID)
+	}
+
+	var userModels []UserModel
+	if err := query.Order("id ASC").Limit(filter.Limit).Find(&userModels).Error; err != nil {
+		return nil, err
+	}
+	return toDomainUsers(userModels), nil
+}
+
+// filtered applies the criteria of filter, leaving paging to the caller
+func (r *userRepository) filtered(ctx context.Context, filter domainUser.ListFilter) *gorm.DB {
+	query := r.replicas.Reader(ctx, r.db).Model(&UserModel{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where(
+			"(LOWER(email) LIKE ? OR LOWER(username) LIKE ? OR LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?)",
+			pattern, pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", string(filter.Role))
+	}
+	if filter.Active != nil {
+		query = query.Where("is_active = ?", *filter.Active)
+	}
+	if filter.Tenant != "" {
+		query = query.Where("tenant = ?", filter.Tenant)
+	}
+	if len(filter.Metadata) > 0 {
+		// Containment is served by the GIN index on metadata
+		attributes, _ := json.Marshal(filter.Metadata) // A map of strings always marshals
+		query = query.Where("metadata @> ?", string(attributes))
+	}
+	return query
+}
+
+// escapeLike makes LIKE wildcards in s match literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func toDomainUsers(userModels []UserModel) []*domainUser.User {
+	users := make([]*domainUser.User, 0, len(userModels))
+	for i := range userModels {
+		users = append(users, ToDomainUser(&userModels[i]))
+	}
+	return users
+}
+
+// uniqueViolation is the Postgres SQLSTATE of unique constraint violations
+const uniqueViolation = "23505"
+
+// sqlStateError is implemented by driver errors carrying a SQLSTATE code,
+// such as *pgconn.PgError
+type sqlStateError interface {
+	SQLState() string
+}
+
+// translateError wraps unique constraint violations with
+// domainUser.ErrDuplicate, keeping the driver error for logs
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var stateErr sqlStateError
+	if errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &stateErr) && stateErr.SQLState() == uniqueViolation) {
+		return fmt.Errorf("%w: %w", domainUser.ErrDuplicate, err)
+	}
+	return err
 }
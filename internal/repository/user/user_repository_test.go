@@ -0,0 +1,36 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// stateError is a driver error with a SQLSTATE code, like *pgconn.PgError
+type stateError string
+
+func (e stateError) Error() string    { return "ERROR (SQLSTATE " + string(e) + ")" }
+func (e stateError) SQLState() string { return string(e) }
+
+func TestTranslateError(t *testing.T) {
+	assert.NoError(t, translateError(nil))
+
+	for _, err := range []error{
+		stateError(uniqueViolation),
+		fmt.Errorf("insert: %w", stateError(uniqueViolation)),
+		gorm.ErrDuplicatedKey,
+	} {
+		translated := translateError(err)
+		assert.ErrorIs(t, translated, domainUser.ErrDuplicate, "%v", err)
+		assert.ErrorIs(t, translated, err, "the driver error is kept")
+	}
+
+	for _, err := range []error{stateError("23502"), errors.New("db error")} {
+		assert.Equal(t, err, translateError(err))
+	}
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"gorm.io/gorm"
+)
+
+// DeliveryModel represents the webhook delivery log structure for database interactions.
+type DeliveryModel struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID `gorm:"type:uuid;not null"`
+	EventType      string    `gorm:"size:64;not null"`
+	Attempt        int       `gorm:"not null"`
+	StatusCode     int       `gorm:"not null"`
+	Error          string    `gorm:"size:1024;not null"`
+	DurationMs     int64     `gorm:"not null"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for the DeliveryModel.
+func (DeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+// maxErrorLength matches the error column
+const maxErrorLength = 1024
+
+type deliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRepository creates a new instance of domainWebhook.DeliveryRepository.
+func NewDeliveryRepository(db *gorm.DB) domainWebhook.DeliveryRepository {
+	return &deliveryRepository{db: db}
+}
+
+func (r *deliveryRepository) Create(ctx context.Context, delivery *domainWebhook.Delivery) error {
+	message := delivery.Error
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	model := &DeliveryModel{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Attempt:        delivery.Attempt,
+		StatusCode:     delivery.StatusCode,
+		Error:          message,
+		DurationMs:     delivery.Duration.Milliseconds(),
+		CreatedAt:      delivery.CreatedAt,
+	}
+	return r.db.WithContext(ctx).Create(model).Error
+}
+
+func (r *deliveryRepository) List(ctx context.Context, filter domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, int64, error) {
+	query := r.db.WithContext(ctx).Model(&DeliveryModel{}).Where("subscription_id = ?", filter.SubscriptionID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var models []DeliveryModel
+	err := query.
+		Order("created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := make([]*domainWebhook.Delivery, 0, len(models))
+	for _, m := range models {
+		deliveries = append(deliveries, &domainWebhook.Delivery{
+			ID:             m.ID,
+			SubscriptionID: m.SubscriptionID,
+			EventID:        m.EventID,
+			EventType:      domainWebhook.EventType(m.EventType),
+			Attempt:        m.Attempt,
+			StatusCode:     m.StatusCode,
+			Error:          m.Error,
+			Duration:       time.Duration(m.DurationMs) * time.Millisecond,
+			CreatedAt:      m.CreatedAt,
+		})
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"gorm.io/gorm"
+)
+
+// SubscriptionModel represents the webhook subscription structure for database interactions.
+// Event types are stored comma-separated; the table is small and matching
+// events to subscriptions is done in the service.
+type SubscriptionModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	URL         string    `gorm:"size:2048;not null"`
+	Secret      string    `gorm:"size:255;not null"`
+	EventTypes  string    `gorm:"not null"`
+	Description string    `gorm:"size:255;not null"`
+	Active      bool      `gorm:"not null"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the SubscriptionModel.
+func (SubscriptionModel) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// toDomain converts a SubscriptionModel to a domainWebhook.Subscription.
+func toDomain(m *SubscriptionModel) *domainWebhook.Subscription {
+	var eventTypes []domainWebhook.EventType
+	if m.EventTypes != "" {
+		for _, t := range strings.Split(m.EventTypes, ",") {
+			eventTypes = append(eventTypes, domainWebhook.EventType(t))
+		}
+	}
+	return &domainWebhook.Subscription{
+		ID:          m.ID,
+		URL:         m.URL,
+		Secret:      m.Secret,
+		EventTypes:  eventTypes,
+		Description: m.Description,
+		Active:      m.Active,
+		CreatedBy:   m.CreatedBy,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+// fromDomain converts a domainWebhook.Subscription to a SubscriptionModel.
+func fromDomain(s *domainWebhook.Subscription) *SubscriptionModel {
+	eventTypes := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	return &SubscriptionModel{
+		ID:          s.ID,
+		URL:         s.URL,
+		Secret:      s.Secret,
+		EventTypes:  strings.Join(eventTypes, ","),
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of domainWebhook.Repository.
+func NewWebhookRepository(db *gorm.DB) domainWebhook.Repository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, subscription *domainWebhook.Subscription) error {
+	return r.db.WithContext(ctx).Create(fromDomain(subscription)).Error
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainWebhook.Subscription, error) {
+	var model SubscriptionModel
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Subscription not found
+		}
+		return nil, err
+	}
+	return toDomain(&model), nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, subscription *domainWebhook.Subscription) error {
+	return r.db.WithContext(ctx).Save(fromDomain(subscription)).Error
+}
+
+// Delete relies on the foreign key to remove the delivery log
+func (r *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&SubscriptionModel{}).Error
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]*domainWebhook.Subscription, error) {
+	var models []SubscriptionModel
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	subscriptions := make([]*domainWebhook.Subscription, 0, len(models))
+	for i := range models {
+		subscriptions = append(subscriptions, toDomain(&models[i]))
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,36 @@
+package requestctx
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+)
+
+// SetUserID attaches the ID of the authenticated user to the request of c
+func SetUserID(c *gin.Context, id uuid.UUID) {
+	update(c, func(ctx context.Context) context.Context { return WithUserID(ctx, id) })
+}
+
+// SetRoles attaches the roles of the authenticated user to the request of c
+func SetRoles(c *gin.Context, roles ...rbac.Role) {
+	update(c, func(ctx context.Context) context.Context { return WithRoles(ctx, roles...) })
+}
+
+// SetTenant attaches the tenant the request of c is served for
+func SetTenant(c *gin.Context, tenant string) {
+	update(c, func(ctx context.Context) context.Context { return WithTenant(ctx, tenant) })
+}
+
+// SetRequestID attaches the request ID to the request of c
+func SetRequestID(c *gin.Context, id string) {
+	update(c, func(ctx context.Context) context.Context { return WithRequestID(ctx, id) })
+}
+
+// update replaces the request of c with one carrying the context wrap
+// derives from its own
+func update(c *gin.Context, wrap func(context.Context) context.Context) {
+	c.Request = c.Request.WithContext(wrap(c.Request.Context()))
+}
@@ -0,0 +1,75 @@
+// Package requestctx carries who a request is served for through its
+// context: the authenticated user, their roles, the tenant and the request
+// ID. Middleware and interceptors set the values once; handlers, services
+// and repositories read them with typed getters instead of string keys.
+package requestctx
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+type (
+	userIDKey struct{}
+	rolesKey  struct{}
+	tenantKey struct{}
+)
+
+// WithUserID returns a copy of ctx carrying the ID of the authenticated user
+func WithUserID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+// UserID returns the ID of the authenticated user, if any
+func UserID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(userIDKey{}).(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+// WithRoles returns a copy of ctx carrying the roles of the authenticated user
+func WithRoles(ctx context.Context, roles ...rbac.Role) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+// Roles returns the roles of the authenticated user, or nil when they were
+// not loaded for the request
+func Roles(ctx context.Context) []rbac.Role {
+	roles, _ := ctx.Value(rolesKey{}).([]rbac.Role)
+	return roles
+}
+
+// HasRole reports whether the authenticated user is known to hold role
+func HasRole(ctx context.Context, role rbac.Role) bool {
+	for _, r := range Roles(ctx) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTenant returns a copy of ctx carrying the tenant the request is served for
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant the request is served for, or an empty string
+// when it is served outside any tenant
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return requestid.NewContext(ctx, id)
+}
+
+// RequestID returns the ID of the request being served, if any
+func RequestID(ctx context.Context) (string, bool) {
+	return requestid.FromContext(ctx)
+}
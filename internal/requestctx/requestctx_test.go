@@ -0,0 +1,72 @@
+package requestctx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/requestid"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := UserID(ctx)
+	assert.False(t, ok)
+	assert.Nil(t, Roles(ctx))
+	assert.False(t, HasRole(ctx, rbac.RoleAdmin))
+	assert.Empty(t, Tenant(ctx))
+	_, ok = RequestID(ctx)
+	assert.False(t, ok)
+
+	// A nil user ID is no authenticated user
+	_, ok = UserID(WithUserID(ctx, uuid.Nil))
+	assert.False(t, ok)
+
+	userID := uuid.New()
+	ctx = WithUserID(ctx, userID)
+	ctx = WithRoles(ctx, rbac.RoleAdmin)
+	ctx = WithTenant(ctx, "acme")
+	ctx = WithRequestID(ctx, "req-42")
+
+	id, ok := UserID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, userID, id)
+	assert.Equal(t, []rbac.Role{rbac.RoleAdmin}, Roles(ctx))
+	assert.True(t, HasRole(ctx, rbac.RoleAdmin))
+	assert.False(t, HasRole(ctx, rbac.RoleUser))
+	assert.Equal(t, "acme", Tenant(ctx))
+	requestID, ok := RequestID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "req-42", requestID)
+
+	// The request ID is shared with code reading it through requestid
+	requestID, _ = requestid.FromContext(ctx)
+	assert.Equal(t, "req-42", requestID)
+}
+
+func TestGinSetters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	SetUserID(c, userID)
+	SetRoles(c, rbac.RoleUser)
+	SetTenant(c, "acme")
+	SetRequestID(c, "req-42")
+
+	ctx := c.Request.Context()
+	id, ok := UserID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, userID, id)
+	assert.True(t, HasRole(ctx, rbac.RoleUser))
+	assert.Equal(t, "acme", Tenant(ctx))
+	requestID, _ := RequestID(ctx)
+	assert.Equal(t, "req-42", requestID)
+}
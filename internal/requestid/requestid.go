@@ -0,0 +1,47 @@
+// Package requestid carries the ID of the request being served through its
+// context, so that logs written while serving it can be correlated.
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header is the HTTP header a request ID is read from and echoed in
+const Header = "X-Request-ID"
+
+// maxLength bounds the IDs accepted from clients
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a new random request ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether id, typically supplied by a client or proxy, may be
+// used as a request ID: a non-empty string of printable ASCII without spaces
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,38 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected bool
+	}{
+		{id: New(), expected: true},
+		{id: "req-42_a.b:c", expected: true},
+		{id: "", expected: false},
+		{id: "has space", expected: false},
+		{id: "line\nbreak", expected: false},
+		{id: "naïve", expected: false},
+		{id: strings.Repeat("a", maxLength), expected: true},
+		{id: strings.Repeat("a", maxLength+1), expected: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Valid(tt.id), "%q", tt.id)
+	}
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	id, ok := FromContext(NewContext(context.Background(), "abc"))
+	assert.True(t, ok)
+	assert.Equal(t, "abc", id)
+}
@@ -0,0 +1,47 @@
+package saml
+
+import "encoding/xml"
+
+// entityDescriptor is the metadata of a service provider
+type entityDescriptor struct {
+	XMLName  xml.Name        `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID string          `xml:"entityID,attr"`
+	SP       spSSODescriptor `xml:"SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool                     `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                     `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                   `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormats              []string                 `xml:"NameIDFormat"`
+	AssertionConsumerServices  []assertionConsumerEntry `xml:"AssertionConsumerService"`
+}
+
+type assertionConsumerEntry struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// Metadata returns the metadata document identity providers are configured
+// with: the entity ID of the service provider and its assertion consumer
+// service, which accepts signed assertions through the HTTP-POST binding
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	descriptor := entityDescriptor{
+		EntityID: sp.EntityID,
+		SP: spSSODescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: protocolNamespace,
+			NameIDFormats:              []string{NameIDFormatEmail},
+			AssertionConsumerServices: []assertionConsumerEntry{
+				{Binding: postBinding, Location: sp.ACSURL, Index: 0, IsDefault: true},
+			},
+		},
+	}
+	data, err := xml.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,305 @@
+// Package saml implements the parts of a SAML 2.0 service provider that sign
+// users in from an identity provider: validating the responses the identity
+// provider posts to the assertion consumer service (HTTP-POST binding) and
+// describing the service provider in metadata.
+//
+// Only IdP-initiated sign-in is supported: the service provider sends no
+// authentication requests, so a response answering one is rejected.
+// Encrypted assertions are not supported. The response or its assertion must
+// carry an enveloped XML signature made with RSA-SHA256 or RSA-SHA512 over
+// Exclusive XML Canonicalization.
+package saml
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SAML 2.0 namespaces and the URIs used in messages
+const (
+	assertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	protocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+
+	statusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bearerMethod  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	postBinding   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+
+	// NameIDFormatEmail is the name identifier format of email addresses
+	NameIDFormatEmail = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+// ErrInvalidResponse is returned when a response is malformed, unsuccessful
+// or not meant for this service provider now
+var ErrInvalidResponse = errors.New("invalid SAML response")
+
+// ServiceProvider validates the responses an identity provider posts to the
+// assertion consumer service
+type ServiceProvider struct {
+	EntityID       string            // Audience assertions must be restricted to
+	ACSURL         string            // Where responses are posted; the expected destination and recipient
+	IdPEntityID    string            // Issuer of responses and assertions
+	IdPCertificate *x509.Certificate // Verifies the signature of responses or assertions
+	ClockSkew      time.Duration     // Tolerated difference between the clocks of both parties
+	Now            func() time.Time  // Defaults to time.Now
+}
+
+// Assertion is what a validated response asserts about the user signing in
+type Assertion struct {
+	ID           string              // Unique per assertion; used to reject replays
+	NameID       string              // Identifier of the user at the identity provider
+	NameIDFormat string              // Format of NameID, such as NameIDFormatEmail
+	SessionIndex string              // Session of the user at the identity provider, when given
+	Attributes   map[string][]string // Attribute values by attribute name
+	NotOnOrAfter time.Time           // When the assertion stops being valid
+}
+
+// Attribute returns the first value of the attribute named name, or ""
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ParseResponse decodes the base64 SAMLResponse form value posted to the
+// assertion consumer service and returns its assertion once the response
+// has been validated. Failures wrap ErrInvalidResponse or ErrSignature.
+func (sp *ServiceProvider) ParseResponse(encoded string) (*Assertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: SAMLResponse is not base64", ErrInvalidResponse)
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if !root.is(protocolNamespace, "Response") {
+		return nil, fmt.Errorf("%w: not a response", ErrInvalidResponse)
+	}
+	if root.attr("Version") != "2.0" {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidResponse, root.attr("Version"))
+	}
+	if root.attr("InResponseTo") != "" {
+		return nil, fmt.Errorf("%w: responses to authentication requests are not supported", ErrInvalidResponse)
+	}
+	if destination := root.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("%w: destination %q is not this service provider", ErrInvalidResponse, destination)
+	}
+	if issuer := root.child(assertionNamespace, "Issuer"); issuer != nil && issuer.text() != sp.IdPEntityID {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidResponse, issuer.text())
+	}
+	if err := checkStatus(root); err != nil {
+		return nil, err
+	}
+
+	if len(root.childElements(assertionNamespace, "EncryptedAssertion")) > 0 {
+		return nil, fmt.Errorf("%w: encrypted assertions are not supported", ErrInvalidResponse)
+	}
+	assertions := root.childElements(assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one assertion", ErrInvalidResponse)
+	}
+	assertion := assertions[0]
+
+	// The assertion read below is covered either by its own signature or by
+	// the signature of the response it is a child of. Every signature
+	// present must verify.
+	signed := false
+	for _, e := range []*element{root, assertion} {
+		if signature(e) == nil {
+			continue
+		}
+		if err := verify(e, sp.IdPCertificate); err != nil {
+			return nil, err
+		}
+		signed = true
+	}
+	if !signed {
+		return nil, fmt.Errorf("%w: neither the response nor the assertion is signed", ErrSignature)
+	}
+
+	return sp.readAssertion(assertion)
+}
+
+// checkStatus rejects a response whose status is not success
+func checkStatus(root *element) error {
+	status := root.child(protocolNamespace, "Status")
+	if status == nil {
+		return fmt.Errorf("%w: missing status", ErrInvalidResponse)
+	}
+	code := status.child(protocolNamespace, "StatusCode")
+	if code == nil {
+		return fmt.Errorf("%w: missing status code", ErrInvalidResponse)
+	}
+	if value := code.attr("Value"); value != statusSuccess {
+		return fmt.Errorf("%w: identity provider answered %q", ErrInvalidResponse, value)
+	}
+	return nil
+}
+
+// readAssertion validates the issuer, subject and conditions of a signed
+// assertion and returns what it asserts
+func (sp *ServiceProvider) readAssertion(e *element) (*Assertion, error) {
+	now := time.Now
+	if sp.Now != nil {
+		now = sp.Now
+	}
+	current := now()
+
+	a := &Assertion{ID: e.attr("ID"), Attributes: make(map[string][]string)}
+	if a.ID == "" {
+		return nil, fmt.Errorf("%w: assertion has no ID", ErrInvalidResponse)
+	}
+	if issuer := e.child(assertionNamespace, "Issuer"); issuer == nil || issuer.text() != sp.IdPEntityID {
+		return nil, fmt.Errorf("%w: assertion is not issued by the identity provider", ErrInvalidResponse)
+	}
+
+	subject := e.child(assertionNamespace, "Subject")
+	if subject == nil {
+		return nil, fmt.Errorf("%w: assertion has no subject", ErrInvalidResponse)
+	}
+	nameID := subject.child(assertionNamespace, "NameID")
+	if nameID == nil || nameID.text() == "" {
+		return nil, fmt.Errorf("%w: assertion has no name identifier", ErrInvalidResponse)
+	}
+	a.NameID = nameID.text()
+	a.NameIDFormat = nameID.attr("Format")
+
+	notOnOrAfter, err := sp.checkSubjectConfirmation(subject, current)
+	if err != nil {
+		return nil, err
+	}
+	a.NotOnOrAfter = notOnOrAfter
+
+	conditions := e.child(assertionNamespace, "Conditions")
+	if conditions == nil {
+		return nil, fmt.Errorf("%w: assertion has no conditions", ErrInvalidResponse)
+	}
+	notBefore, err := parseTime(conditions.attr("NotBefore"))
+	if err != nil {
+		return nil, err
+	}
+	if !notBefore.IsZero() && current.Add(sp.ClockSkew).Before(notBefore) {
+		return nil, fmt.Errorf("%w: assertion is not valid yet", ErrInvalidResponse)
+	}
+	expiry, err := parseTime(conditions.attr("NotOnOrAfter"))
+	if err != nil {
+		return nil, err
+	}
+	if !expiry.IsZero() {
+		if !current.Add(-sp.ClockSkew).Before(expiry) {
+			return nil, fmt.Errorf("%w: assertion has expired", ErrInvalidResponse)
+		}
+		if expiry.Before(a.NotOnOrAfter) {
+			a.NotOnOrAfter = expiry
+		}
+	}
+	if !sp.isAudience(conditions) {
+		return nil, fmt.Errorf("%w: assertion is not restricted to this service provider", ErrInvalidResponse)
+	}
+
+	authn := e.child(assertionNamespace, "AuthnStatement")
+	if authn == nil {
+		return nil, fmt.Errorf("%w: assertion has no authentication statement", ErrInvalidResponse)
+	}
+	a.SessionIndex = authn.attr("SessionIndex")
+
+	for _, statement := range e.childElements(assertionNamespace, "AttributeStatement") {
+		for _, attribute := range statement.childElements(assertionNamespace, "Attribute") {
+			name := attribute.attr("Name")
+			for _, value := range attribute.childElements(assertionNamespace, "AttributeValue") {
+				a.Attributes[name] = append(a.Attributes[name], value.text())
+			}
+		}
+	}
+	return a, nil
+}
+
+// checkSubjectConfirmation requires a bearer confirmation addressed to the
+// assertion consumer service that has not expired, and returns its expiry
+func (sp *ServiceProvider) checkSubjectConfirmation(subject *element, current time.Time) (time.Time, error) {
+	for _, confirmation := range subject.childElements(assertionNamespace, "SubjectConfirmation") {
+		if confirmation.attr("Method") != bearerMethod {
+			continue
+		}
+		data := confirmation.child(assertionNamespace, "SubjectConfirmationData")
+		if data == nil || data.attr("Recipient") != sp.ACSURL || data.attr("InResponseTo") != "" {
+			continue
+		}
+		notOnOrAfter, err := parseTime(data.attr("NotOnOrAfter"))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if notOnOrAfter.IsZero() || !current.Add(-sp.ClockSkew).Before(notOnOrAfter) {
+			continue
+		}
+		return notOnOrAfter, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: no valid bearer subject confirmation", ErrInvalidResponse)
+}
+
+// isAudience reports whether the conditions restrict the assertion to this
+// service provider. Every audience restriction must include it.
+func (sp *ServiceProvider) isAudience(conditions *element) bool {
+	restrictions := conditions.childElements(assertionNamespace, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return false
+	}
+	for _, restriction := range restrictions {
+		included := false
+		for _, audience := range restriction.childElements(assertionNamespace, "Audience") {
+			if audience.text() == sp.EntityID {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTime parses an xs:dateTime attribute; an empty one is the zero time
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: malformed time %q", ErrInvalidResponse, s)
+	}
+	return t, nil
+}
+
+// ParseCertificate parses the signing certificate of an identity provider,
+// given in PEM or as the bare base64 of its DER encoding, as found in the
+// X509Certificate element of identity provider metadata
+func ParseCertificate(s string) (*x509.Certificate, error) {
+	s = strings.TrimSpace(s)
+	var der []byte
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+		}
+		der = block.Bytes
+	} else {
+		var err error
+		if der, err = decodeBase64(s); err != nil {
+			return nil, errors.New("certificate is neither PEM nor base64")
+		}
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cert.PublicKey.(*rsa.PublicKey); !ok {
+		return nil, errors.New("certificate does not hold an RSA key")
+	}
+	return cert, nil
+}
@@ -0,0 +1,288 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 7, 6, 9, 0, 0, 0, time.UTC)
+
+// testIdP signs responses as an identity provider would
+type testIdP struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    testNow.Add(-time.Hour),
+		NotAfter:     testNow.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testIdP{key: key, cert: cert}
+}
+
+// sign replaces the <Signature id="..."/> placeholder of doc with the
+// enveloped signature of the element with that ID
+func (idp *testIdP) sign(t *testing.T, doc, id string) string {
+	t.Helper()
+	placeholder := `<Signature id="` + id + `"/>`
+	require.Contains(t, doc, placeholder)
+
+	root, err := parseXML([]byte(strings.Replace(doc, placeholder, "", 1)))
+	require.NoError(t, err)
+	digest := sha256.Sum256(findByID(root, id).canonicalize(nil, []string{"xs"}))
+	signed := strings.Replace(doc, placeholder, `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`+
+		`<ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>`+
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>`+
+		`<ds:Reference URI="#`+id+`"><ds:Transforms>`+
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>`+
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"><ec:InclusiveNamespaces xmlns:ec="http://www.w3.org/2001/10/xml-exc-c14n#" PrefixList="xs"/></ds:Transform>`+
+		`</ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>`+
+		`<ds:DigestValue>`+base64.StdEncoding.EncodeToString(digest[:])+`</ds:DigestValue></ds:Reference></ds:SignedInfo>`+
+		`<ds:SignatureValue>SIGNATURE</ds:SignatureValue></ds:Signature>`, 1)
+
+	root, err = parseXML([]byte(signed))
+	require.NoError(t, err)
+	signedInfo := signature(findByID(root, id)).child(dsigNamespace, "SignedInfo")
+	hashed := sha256.Sum256(signedInfo.canonicalize(nil, nil))
+	value, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	require.NoError(t, err)
+	return strings.Replace(signed, "SIGNATURE", base64.StdEncoding.EncodeToString(value), 1)
+}
+
+func findByID(e *element, id string) *element {
+	if e.attr("ID") == id {
+		return e
+	}
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok {
+			if found := findByID(el, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// testResponse is an IdP-initiated response with its assertion; the
+// replacements tweak it per test
+func testResponse(replacements ...string) string {
+	doc := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response" Version="2.0" IssueInstant="2025-07-06T08:59:58Z" Destination="https://api.example.com/api/v1/auth/saml/acme/acs">
+  <saml:Issuer>https://idp.example.com</saml:Issuer>
+  <Signature id="_response"/>
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+  <saml:Assertion xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_assertion" Version="2.0" IssueInstant="2025-07-06T08:59:58Z">
+    <saml:Issuer>https://idp.example.com</saml:Issuer>
+    <Signature id="_assertion"/>
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">ada@acme.example</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData NotOnOrAfter="2025-07-06T09:04:58Z" Recipient="https://api.example.com/api/v1/auth/saml/acme/acs"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="2025-07-06T08:59:28Z" NotOnOrAfter="2025-07-06T10:00:00Z">
+      <saml:AudienceRestriction><saml:Audience>https://api.example.com/api/v1/auth/saml/acme/metadata</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AuthnStatement AuthnInstant="2025-07-06T08:59:57Z" SessionIndex="_session"/>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="firstName"><saml:AttributeValue xsi:type="xs:string">Ada</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="groups"><saml:AttributeValue>admins</saml:AttributeValue><saml:AttributeValue>engineers</saml:AttributeValue></saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`
+	return strings.NewReplacer(replacements...).Replace(doc)
+}
+
+func testServiceProvider(cert *x509.Certificate) *ServiceProvider {
+	return &ServiceProvider{
+		EntityID:       "https://api.example.com/api/v1/auth/saml/acme/metadata",
+		ACSURL:         "https://api.example.com/api/v1/auth/saml/acme/acs",
+		IdPEntityID:    "https://idp.example.com",
+		IdPCertificate: cert,
+		ClockSkew:      time.Minute,
+		Now:            func() time.Time { return testNow },
+	}
+}
+
+func encode(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestParseResponse(t *testing.T) {
+	idp := newTestIdP(t)
+	sp := testServiceProvider(idp.cert)
+
+	// signAssertion signs only the assertion of doc
+	signAssertion := func(doc string) string {
+		return idp.sign(t, strings.Replace(doc, `<Signature id="_response"/>`, "", 1), "_assertion")
+	}
+
+	t.Run("Signed Assertion", func(t *testing.T) {
+		assertion, err := sp.ParseResponse(encode(signAssertion(testResponse())))
+
+		require.NoError(t, err)
+		assert.Equal(t, &Assertion{
+			ID:           "_assertion",
+			NameID:       "ada@acme.example",
+			NameIDFormat: NameIDFormatEmail,
+			SessionIndex: "_session",
+			Attributes:   map[string][]string{"firstName": {"Ada"}, "groups": {"admins", "engineers"}},
+			NotOnOrAfter: time.Date(2025, 7, 6, 9, 4, 58, 0, time.UTC),
+		}, assertion)
+		assert.Equal(t, "Ada", assertion.Attribute("firstName"))
+		assert.Empty(t, assertion.Attribute("lastName"))
+	})
+
+	t.Run("Signed Response", func(t *testing.T) {
+		doc := idp.sign(t, strings.Replace(testResponse(), `<Signature id="_assertion"/>`, "", 1), "_response")
+
+		assertion, err := sp.ParseResponse(encode(doc))
+
+		require.NoError(t, err)
+		assert.Equal(t, "ada@acme.example", assertion.NameID)
+	})
+
+	t.Run("Signed Response And Assertion", func(t *testing.T) {
+		doc := idp.sign(t, idp.sign(t, testResponse(), "_assertion"), "_response")
+
+		_, err := sp.ParseResponse(encode(doc))
+
+		assert.NoError(t, err)
+	})
+
+	signatureTests := []struct {
+		name string
+		doc  func() string
+		sp   *ServiceProvider
+	}{
+		{
+			name: "Unsigned",
+			doc: func() string {
+				return strings.NewReplacer(`<Signature id="_response"/>`, "", `<Signature id="_assertion"/>`, "").Replace(testResponse())
+			},
+		},
+		{
+			name: "Tampered Assertion",
+			doc: func() string {
+				return strings.Replace(signAssertion(testResponse()), "ada@acme.example", "root@acme.example", 1)
+			},
+		},
+		{
+			name: "Signed By Another Key",
+			doc:  func() string { return signAssertion(testResponse()) },
+			sp:   testServiceProvider(newTestIdP(t).cert),
+		},
+		{
+			name: "Signature Wrapping",
+			doc: func() string {
+				// A signed assertion moved aside for a forged one
+				signed := signAssertion(testResponse())
+				start := strings.Index(signed, "<saml:Assertion ")
+				end := strings.Index(signed, "</saml:Assertion>") + len("</saml:Assertion>")
+				original := signed[start:end]
+				forged := strings.NewReplacer(`ID="_assertion"`, `ID="_forged"`, "ada@acme.example", "root@acme.example").Replace(original)
+				return signed[:start] + forged + "<samlp:Extensions>" + original + "</samlp:Extensions>" + signed[end:]
+			},
+		},
+	}
+	for _, tt := range signatureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := sp
+			if tt.sp != nil {
+				provider = tt.sp
+			}
+
+			_, err := provider.ParseResponse(encode(tt.doc()))
+
+			assert.ErrorIs(t, err, ErrSignature)
+		})
+	}
+
+	validationTests := []struct {
+		name         string
+		replacements []string
+		now          time.Time
+	}{
+		{name: "Expired", now: testNow.Add(10 * time.Minute)},
+		{name: "Not Valid Yet", now: testNow.Add(-5 * time.Minute)},
+		{name: "Other Audience", replacements: []string{"<saml:Audience>https://api.example.com/api/v1/auth/saml/acme/metadata", "<saml:Audience>https://other.example.com"}},
+		{name: "Other Recipient", replacements: []string{`Recipient="https://api.example.com/api/v1/auth/saml/acme/acs"`, `Recipient="https://other.example.com/acs"`}},
+		{name: "Other Destination", replacements: []string{`Destination="https://api.example.com/api/v1/auth/saml/acme/acs"`, `Destination="https://other.example.com/acs"`}},
+		{name: "Other Issuer", replacements: []string{"https://idp.example.com", "https://evil.example.com"}},
+		{name: "Answers A Request", replacements: []string{`ID="_response"`, `ID="_response" InResponseTo="_request"`}},
+		{name: "Failed Status", replacements: []string{"status:Success", "status:Requester"}},
+		{name: "No Authentication Statement", replacements: []string{`<saml:AuthnStatement AuthnInstant="2025-07-06T08:59:57Z" SessionIndex="_session"/>`, ""}},
+	}
+	for _, tt := range validationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := *sp
+			if !tt.now.IsZero() {
+				provider.Now = func() time.Time { return tt.now }
+			}
+
+			_, err := provider.ParseResponse(encode(signAssertion(testResponse(tt.replacements...))))
+
+			assert.ErrorIs(t, err, ErrInvalidResponse)
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := sp.ParseResponse("not base64!")
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+
+		_, err = sp.ParseResponse(encode("<samlp:Response"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestParseCertificate(t *testing.T) {
+	idp := newTestIdP(t)
+	encoded := base64.StdEncoding.EncodeToString(idp.cert.Raw)
+
+	fromPEM, err := ParseCertificate(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: idp.cert.Raw})))
+	require.NoError(t, err)
+	assert.Equal(t, idp.cert.Raw, fromPEM.Raw)
+
+	// As copied out of identity provider metadata, wrapped over lines
+	fromBase64, err := ParseCertificate(encoded[:64] + "\n  " + encoded[64:])
+	require.NoError(t, err)
+	assert.Equal(t, idp.cert.Raw, fromBase64.Raw)
+
+	_, err = ParseCertificate("not a certificate")
+	assert.Error(t, err)
+}
+
+func TestMetadata(t *testing.T) {
+	metadata, err := testServiceProvider(nil).Metadata()
+
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://api.example.com/api/v1/auth/saml/acme/metadata">
+  <SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</NameIDFormat>
+    <AssertionConsumerService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://api.example.com/api/v1/auth/saml/acme/acs" index="0" isDefault="true"></AssertionConsumerService>
+  </SPSSODescriptor>
+</EntityDescriptor>`, string(metadata))
+}
@@ -0,0 +1,167 @@
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// XML Signature namespace and the algorithms verify accepts. SHA-1 based
+// algorithms are not accepted.
+const (
+	dsigNamespace = "http://www.w3.org/2000/09/xmldsig#"
+
+	excC14N            = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	envelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+
+	rsaSHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	rsaSHA512 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	sha256URI = "http://www.w3.org/2001/04/xmlenc#sha256"
+	sha512URI = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// ErrSignature is returned when a message is not signed as required or its
+// signature does not verify against the certificate of the identity provider
+var ErrSignature = errors.New("invalid SAML signature")
+
+// signature returns the enveloped signature of e, or nil when it has none
+func signature(e *element) *element {
+	return e.child(dsigNamespace, "Signature")
+}
+
+// verify checks the enveloped signature of e against cert. Only a signature
+// that is a child of e and references e by its ID is considered, so the
+// element whose content is then read is the one that was signed.
+func verify(e *element, cert *x509.Certificate) error {
+	sig := signature(e)
+	if sig == nil {
+		return fmt.Errorf("%w: element is not signed", ErrSignature)
+	}
+	signedInfo := sig.child(dsigNamespace, "SignedInfo")
+	if signedInfo == nil {
+		return fmt.Errorf("%w: missing SignedInfo", ErrSignature)
+	}
+
+	method := signedInfo.child(dsigNamespace, "CanonicalizationMethod")
+	if method == nil || method.attr("Algorithm") != excC14N {
+		return fmt.Errorf("%w: unsupported canonicalization method", ErrSignature)
+	}
+	references := signedInfo.childElements(dsigNamespace, "Reference")
+	if len(references) != 1 {
+		return fmt.Errorf("%w: expected exactly one reference", ErrSignature)
+	}
+	if err := verifyReference(e, sig, references[0]); err != nil {
+		return err
+	}
+
+	signatureMethod := signedInfo.child(dsigNamespace, "SignatureMethod")
+	if signatureMethod == nil {
+		return fmt.Errorf("%w: missing signature method", ErrSignature)
+	}
+	var hash crypto.Hash
+	switch signatureMethod.attr("Algorithm") {
+	case rsaSHA256:
+		hash = crypto.SHA256
+	case rsaSHA512:
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported signature method %q", ErrSignature, signatureMethod.attr("Algorithm"))
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: certificate does not hold an RSA key", ErrSignature)
+	}
+	value := sig.child(dsigNamespace, "SignatureValue")
+	if value == nil {
+		return fmt.Errorf("%w: missing signature value", ErrSignature)
+	}
+	signatureValue, err := decodeBase64(value.text())
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature value", ErrSignature)
+	}
+
+	h := hash.New()
+	h.Write(signedInfo.canonicalize(nil, inclusivePrefixes(method)))
+	if err := rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), signatureValue); err != nil {
+		return fmt.Errorf("%w: signature does not match", ErrSignature)
+	}
+	return nil
+}
+
+// verifyReference checks that reference covers e and that e still has the
+// digest it was signed with
+func verifyReference(e, sig, reference *element) error {
+	id := e.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return fmt.Errorf("%w: reference does not cover the signed element", ErrSignature)
+	}
+
+	var inclusive []string
+	enveloped := false
+	transforms := reference.child(dsigNamespace, "Transforms")
+	if transforms == nil {
+		return fmt.Errorf("%w: missing transforms", ErrSignature)
+	}
+	for _, transform := range transforms.childElements(dsigNamespace, "Transform") {
+		switch transform.attr("Algorithm") {
+		case envelopedSignature:
+			enveloped = true
+		case excC14N:
+			inclusive = inclusivePrefixes(transform)
+		default:
+			return fmt.Errorf("%w: unsupported transform %q", ErrSignature, transform.attr("Algorithm"))
+		}
+	}
+	if !enveloped {
+		return fmt.Errorf("%w: signature is not enveloped", ErrSignature)
+	}
+
+	digestMethod := reference.child(dsigNamespace, "DigestMethod")
+	if digestMethod == nil {
+		return fmt.Errorf("%w: missing digest method", ErrSignature)
+	}
+	var digest []byte
+	canonical := e.canonicalize(sig, inclusive)
+	switch digestMethod.attr("Algorithm") {
+	case sha256URI:
+		sum := sha256.Sum256(canonical)
+		digest = sum[:]
+	case sha512URI:
+		sum := sha512.Sum512(canonical)
+		digest = sum[:]
+	default:
+		return fmt.Errorf("%w: unsupported digest method %q", ErrSignature, digestMethod.attr("Algorithm"))
+	}
+	value := reference.child(dsigNamespace, "DigestValue")
+	if value == nil {
+		return fmt.Errorf("%w: missing digest value", ErrSignature)
+	}
+	expected, err := decodeBase64(value.text())
+	if err != nil || !bytes.Equal(digest, expected) {
+		return fmt.Errorf("%w: digest does not match", ErrSignature)
+	}
+	return nil
+}
+
+// inclusivePrefixes returns the PrefixList of the InclusiveNamespaces
+// parameter of an exclusive canonicalization method
+func inclusivePrefixes(method *element) []string {
+	for _, child := range method.children {
+		if el, ok := child.(*element); ok && el.is(excC14N, "InclusiveNamespaces") {
+			return strings.Fields(el.attr("PrefixList"))
+		}
+	}
+	return nil
+}
+
+// decodeBase64 decodes base64 that may be wrapped over several lines
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
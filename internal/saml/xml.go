@@ -0,0 +1,275 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNamespace is bound to the xml prefix without being declared
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// element is a node of the tree parsed from a SAML message. Unlike
+// encoding/xml unmarshalling it keeps prefixes and namespace declarations as
+// written, which canonicalization needs to reproduce the signed bytes.
+type element struct {
+	prefix   string
+	local    string
+	nsDecls  []attr // Prefix "" is the default namespace
+	attrs    []attr
+	children []any // *element or string for character data
+	parent   *element
+}
+
+// attr is an attribute or a namespace declaration
+type attr struct {
+	prefix string
+	local  string
+	value  string
+}
+
+// parseXML parses a document into a tree. Comments and processing
+// instructions are dropped; a document type declaration is rejected so no
+// entity can be declared.
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *element
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed XML: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &element{prefix: t.Name.Space, local: t.Name.Local, parent: current}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.nsDecls = append(el.nsDecls, attr{value: a.Value})
+				case a.Name.Space == "xmlns":
+					el.nsDecls = append(el.nsDecls, attr{prefix: a.Name.Local, value: a.Value})
+				default:
+					el.attrs = append(el.attrs, attr{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+				}
+			}
+			if current != nil {
+				current.children = append(current.children, el)
+			} else if root != nil {
+				return nil, errors.New("malformed XML: more than one root element")
+			} else {
+				root = el
+			}
+			current = el
+		case xml.EndElement:
+			if current == nil || t.Name.Space != current.prefix || t.Name.Local != current.local {
+				return nil, errors.New("malformed XML: unbalanced end element")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.Directive:
+			return nil, errors.New("document type declarations are not allowed")
+		}
+	}
+	if root == nil || current != nil {
+		return nil, errors.New("malformed XML: incomplete document")
+	}
+	return root, nil
+}
+
+// lookupNamespace returns the namespace bound to prefix where e is
+func (e *element) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for el := e; el != nil; el = el.parent {
+		for _, ns := range el.nsDecls {
+			if ns.prefix == prefix {
+				return ns.value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+// namespace returns the namespace of the element name
+func (e *element) namespace() string {
+	ns, _ := e.lookupNamespace(e.prefix)
+	return ns
+}
+
+// is reports whether e is named local in namespace
+func (e *element) is(namespace, local string) bool {
+	return e.local == local && e.namespace() == namespace
+}
+
+// attr returns the value of the unqualified attribute named local
+func (e *element) attr(local string) string {
+	for _, a := range e.attrs {
+		if a.prefix == "" && a.local == local {
+			return a.value
+		}
+	}
+	return ""
+}
+
+// childElements returns the child elements of e named local in namespace
+func (e *element) childElements(namespace, local string) []*element {
+	var matches []*element
+	for _, child := range e.children {
+		if el, ok := child.(*element); ok && el.is(namespace, local) {
+			matches = append(matches, el)
+		}
+	}
+	return matches
+}
+
+// child returns the first child element of e named local in namespace, or nil
+func (e *element) child(namespace, local string) *element {
+	if matches := e.childElements(namespace, local); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+// text returns the character data directly inside e, trimmed of surrounding whitespace
+func (e *element) text() string {
+	var b strings.Builder
+	for _, child := range e.children {
+		if s, ok := child.(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// canonicalize serializes the subtree rooted at e with Exclusive XML
+// Canonicalization 1.0 without comments. skip, when not nil, is left out
+// along with its descendants, as the enveloped signature transform requires.
+// inclusive lists the prefixes ("#default" for the default namespace)
+// treated as in inclusive canonicalization.
+func (e *element) canonicalize(skip *element, inclusive []string) []byte {
+	c := canonicalizer{skip: skip, inclusive: inclusive}
+	c.element(e, map[string]string{})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	skip      *element
+	inclusive []string
+}
+
+// element writes e. rendered holds the namespace declarations in effect in
+// the output of its ancestors.
+func (c *canonicalizer) element(e *element, rendered map[string]string) {
+	// Namespaces visibly utilized by the element, plus the inclusive ones
+	used := map[string]bool{e.prefix: true}
+	for _, a := range e.attrs {
+		if a.prefix != "" {
+			used[a.prefix] = true
+		}
+	}
+	for _, prefix := range c.inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		if _, ok := e.lookupNamespace(prefix); ok {
+			used[prefix] = true
+		}
+	}
+
+	var decls []attr
+	for prefix := range used {
+		if prefix == "xml" {
+			continue
+		}
+		ns, _ := e.lookupNamespace(prefix)
+		current, ok := rendered[prefix]
+		if ok && current == ns || !ok && prefix == "" && ns == "" {
+			continue // Already in effect; an empty default is the initial state
+		}
+		decls = append(decls, attr{prefix: prefix, value: ns})
+	}
+	sort.Slice(decls, func(i, j int) bool { return decls[i].prefix < decls[j].prefix })
+
+	attrs := make([]attr, len(e.attrs))
+	copy(attrs, e.attrs)
+	attrNamespace := func(a attr) string {
+		if a.prefix == "" {
+			return ""
+		}
+		ns, _ := e.lookupNamespace(a.prefix)
+		return ns
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		ni, nj := attrNamespace(attrs[i]), attrNamespace(attrs[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	c.buf.WriteByte('<')
+	c.buf.WriteString(qualifiedName(e.prefix, e.local))
+	if len(decls) > 0 {
+		scoped := make(map[string]string, len(rendered)+len(decls))
+		for prefix, ns := range rendered {
+			scoped[prefix] = ns
+		}
+		for _, d := range decls {
+			if d.prefix == "" {
+				c.buf.WriteString(` xmlns="`)
+			} else {
+				c.buf.WriteString(` xmlns:` + d.prefix + `="`)
+			}
+			c.buf.WriteString(escapeAttr(d.value))
+			c.buf.WriteByte('"')
+			scoped[d.prefix] = d.value
+		}
+		rendered = scoped
+	}
+	for _, a := range attrs {
+		c.buf.WriteString(" " + qualifiedName(a.prefix, a.local) + `="`)
+		c.buf.WriteString(escapeAttr(a.value))
+		c.buf.WriteByte('"')
+	}
+	c.buf.WriteByte('>')
+
+	for _, child := range e.children {
+		switch n := child.(type) {
+		case *element:
+			if n != c.skip {
+				c.element(n, rendered)
+			}
+		case string:
+			c.buf.WriteString(escapeText(n))
+		}
+	}
+	c.buf.WriteString("</" + qualifiedName(e.prefix, e.local) + ">")
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func escapeText(s string) string { return textEscaper.Replace(s) }
+
+func escapeAttr(s string) string { return attrEscaper.Replace(s) }
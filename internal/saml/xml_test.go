@@ -0,0 +1,70 @@
+package saml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const canonicalizationInput = `<?xml version="1.0"?>
+<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:unused="urn:x" ID="_r1" Version="2.0"   IssueInstant="2025-07-06T09:00:00Z" Destination="https://sp.example.com/acs?a=1&amp;b=2">
+  <!-- dropped -->
+  <saml:Issuer>https://idp.example.com</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+  <saml:Assertion xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_a1" b:z="1" xmlns:b="urn:b" a="2" xmlns:a="urn:a" a:y="3">
+    <saml:AttributeValue xsi:type="xs:string">Ada &lt;&gt; &amp; "q" 'a' &#13;</saml:AttributeValue>
+    <x xmlns="urn:default"><y xmlns=""><z/></y><w xml:lang="en" attr="a&#9;b&#10;c"/></x>
+    <![CDATA[raw <text> & stuff]]>
+  </saml:Assertion>
+</samlp:Response>`
+
+// canonicalAssertion is the canonical form of the assertion of canonicalizationInput
+const canonicalAssertion = `<saml:Assertion xmlns:a="urn:a" xmlns:b="urn:b" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1" a="2" a:y="3" b:z="1">
+    <saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="xs:string">Ada &lt;&gt; &amp; "q" 'a' &#xD;</saml:AttributeValue>
+    <x xmlns="urn:default"><y xmlns=""><z></z></y><w attr="a&#x9;b&#xA;c" xml:lang="en"></w></x>
+    raw &lt;text&gt; &amp; stuff
+  </saml:Assertion>`
+
+func TestCanonicalize(t *testing.T) {
+	root, err := parseXML([]byte(canonicalizationInput))
+	require.NoError(t, err)
+	assertion := root.child(assertionNamespace, "Assertion")
+	require.NotNil(t, assertion)
+
+	t.Run("Document", func(t *testing.T) {
+		// As produced by xmllint --exc-c14n, less the comment
+		expected := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" Destination="https://sp.example.com/acs?a=1&amp;b=2" ID="_r1" IssueInstant="2025-07-06T09:00:00Z" Version="2.0">` + "\n  \n" + `  <saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">https://idp.example.com</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode></samlp:Status>
+  ` + canonicalAssertion + `
+</samlp:Response>`
+
+		assert.Equal(t, expected, string(root.canonicalize(nil, nil)))
+	})
+
+	t.Run("Subtree Declares Inherited Namespaces", func(t *testing.T) {
+		assert.Equal(t, canonicalAssertion, string(assertion.canonicalize(nil, nil)))
+	})
+
+	t.Run("Inclusive Prefixes", func(t *testing.T) {
+		canonical := string(assertion.canonicalize(nil, []string{"xs", "unused", "#default"}))
+
+		assert.Contains(t, canonical, `<saml:Assertion xmlns:a="urn:a" xmlns:b="urn:b" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:unused="urn:x" xmlns:xs="http://www.w3.org/2001/XMLSchema" ID="_a1"`)
+		assert.Contains(t, canonical, `<saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type`)
+	})
+
+	t.Run("Skips Enveloped Element", func(t *testing.T) {
+		value := assertion.child(assertionNamespace, "AttributeValue")
+
+		canonical := string(assertion.canonicalize(value, nil))
+
+		assert.NotContains(t, canonical, "AttributeValue")
+		assert.Contains(t, canonical, `<x xmlns="urn:default">`)
+	})
+}
+
+func TestParseXMLRejectsDoctype(t *testing.T) {
+	_, err := parseXML([]byte(`<!DOCTYPE r [<!ENTITY e "x">]><r>&e;</r>`))
+
+	assert.Error(t, err)
+}
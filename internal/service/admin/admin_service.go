@@ -0,0 +1,463 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	"github.com/yi-tech/go-user-service/internal/tx"
+)
+
+// AdminService defines the account management operations available to administrators.
+// Every mutating operation is recorded in the audit log under the acting administrator.
+type AdminService interface {
+	// ListUsers returns a page of users along with the total number of users
+	ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error)
+
+	// ListUsersAfter returns up to filter.Limit users whose ID sorts after
+	// afterID, ordered by ID, so every match can be paged through. Pass
+	// uuid.Nil for the first page.
+	ListUsersAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error)
+
+	// ForcePasswordReset flags the user to choose a new password and signs them out everywhere
+	ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+
+	// ExpirePassword expires the password of the user now, so that they must
+	// choose a new one at their next sign-in; their sessions are kept
+	ExpirePassword(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+
+	// DeactivateUser disables the account and signs the user out everywhere
+	DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+
+	// ActivateUser re-enables a deactivated account
+	ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+
+	// DeleteUser deletes the account and signs the user out everywhere
+	DeleteUser(ctx context.Context, actorID, userID uuid.UUID) error
+
+	// ListSessions returns the active sessions of a user
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error)
+
+	// InspectAuthKeys returns the auth-related Redis keys of a user. Reading them is
+	// audited because the keys describe live credentials.
+	InspectAuthKeys(ctx context.Context, actorID, userID uuid.UUID) ([]*domainAuth.KeyInfo, error)
+
+	// ListAuditLogs returns a page of audit log entries along with the total number of matches
+	ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error)
+
+	// ListLoginHistory returns a page of a user's sign-in attempts, newest
+	// first, along with the total number of attempts
+	ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error)
+
+	// ImpersonateUser mints a short-lived access token letting the
+	// administrator act as the user, for the reason given
+	ImpersonateUser(ctx context.Context, actorID, userID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error)
+
+	// RevokeImpersonation rejects the token of an impersonation from now on
+	RevokeImpersonation(ctx context.Context, actorID, impersonationID uuid.UUID) (*domainAuth.Impersonation, error)
+
+	// ReportSecurityEvent lets a user flag an audit log entry about their
+	// own account as suspicious activity, recording the report in the audit
+	// log for administrators to follow up
+	ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error
+}
+
+// TokenRevoker invalidates every refresh token and session of a user.
+// domainAuth.AuthService satisfies it through Logout.
+type TokenRevoker interface {
+	Logout(ctx context.Context, userID uuid.UUID) error
+}
+
+// Impersonator mints and revokes impersonation tokens.
+// domainAuth.AuthService satisfies it.
+type Impersonator interface {
+	Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error)
+	RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error)
+}
+
+// impersonationDetails is the audit entry detail of an impersonation
+type impersonationDetails struct {
+	ImpersonationID uuid.UUID  `json:"impersonation_id"`
+	Reason          string     `json:"reason,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+// reportDetails is the audit entry detail of a security event report
+type reportDetails struct {
+	EventID uuid.UUID          `json:"event_id"`
+	Action  domainAudit.Action `json:"action"`
+	Comment string             `json:"comment,omitempty"`
+}
+
+type adminService struct {
+	userRepo     domainUser.Repository
+	sessions     domainAuth.SessionRepository
+	keys         domainAuth.KeyInspector
+	revoker      TokenRevoker
+	impersonator Impersonator
+	auditRepo    domainAudit.Repository
+	history      domainAuth.LoginHistoryRepository
+	notifier     domainNotification.Notifier
+	events       domainEvent.Publisher
+	tx           tx.Manager
+	ids          idgen.Generator
+	deadlines    deadline.Deadlines
+}
+
+// Option configures optional behaviour of the admin service
+type Option func(*adminService)
+
+// WithDeadlines runs listings within the read deadline and account changes
+// within the write deadline of deadlines. Operations that run out of time
+// fail with deadline.ErrTimeout.
+func WithDeadlines(deadlines deadline.Deadlines) Option {
+	return func(s *adminService) {
+		s.deadlines = deadlines
+	}
+}
+
+// NewAdminService creates a new instance of AdminService
+func NewAdminService(userRepo domainUser.Repository, sessions domainAuth.SessionRepository, keys domainAuth.KeyInspector, revoker TokenRevoker, impersonator Impersonator, auditRepo domainAudit.Repository, history domainAuth.LoginHistoryRepository, notifier domainNotification.Notifier, events domainEvent.Publisher, tx tx.Manager, ids idgen.Generator, opts ...Option) AdminService {
+	s := &adminService{
+		userRepo:     userRepo,
+		sessions:     sessions,
+		keys:         keys,
+		revoker:      revoker,
+		impersonator: impersonator,
+		auditRepo:    auditRepo,
+		history:      history,
+		notifier:     notifier,
+		events:       events,
+		tx:           tx,
+		ids:          ids,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !s.deadlines.IsZero() {
+		return &deadlineService{AdminService: s, deadlines: s.deadlines}
+	}
+	return s
+}
+
+func (s *adminService) ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	users, total, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
+
+func (s *adminService) ListUsersAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	users, err := s.userRepo.ListAfter(ctx, filter, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+func (s *adminService) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	// An administrator flagging themselves would be locked out of the admin API
+	if actorID == userID {
+		return nil, ErrSelfPasswordReset
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordResetRequired = true
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to flag password reset: %w", err)
+		}
+		return s.record(ctx, actorID, domainAudit.ActionForcePasswordReset, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.revoker.Logout(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.forceLogout(ctx, userID, domainEvent.ReasonPasswordResetRequired)
+
+	s.notifier.Notify(ctx, domainNotification.KindPasswordResetRequired, domainNotification.RecipientOf(user))
+	return user, nil
+}
+
+func (s *adminService) ExpirePassword(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.PasswordExpiresAt = &now
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to expire password: %w", err)
+		}
+		return s.record(ctx, actorID, domainAudit.ActionExpirePassword, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *adminService) DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	if actorID == userID {
+		return nil, ErrSelfDeactivation
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = false
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+		return s.record(ctx, actorID, domainAudit.ActionDeactivateUser, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Access tokens are rejected by the account check as soon as the
+	// transaction commits; revoking stops refresh tokens and sessions
+	if err := s.revoker.Logout(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.forceLogout(ctx, userID, domainEvent.ReasonDeactivated)
+	return user, nil
+}
+
+func (s *adminService) ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive {
+		return user, nil
+	}
+
+	user.IsActive = true
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to activate user: %w", err)
+		}
+		return s.record(ctx, actorID, domainAudit.ActionActivateUser, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *adminService) DeleteUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	if actorID == userID {
+		return ErrSelfDeletion
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Delete(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return s.record(ctx, actorID, domainAudit.ActionDeleteUser, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.revoker.Logout(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.forceLogout(ctx, userID, domainEvent.ReasonDeleted)
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserDeleted, UserID: userID})
+	return nil
+}
+
+func (s *adminService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *adminService) InspectAuthKeys(ctx context.Context, actorID, userID uuid.UUID) ([]*domainAuth.KeyInfo, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.keys.InspectUserKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect auth keys: %w", err)
+	}
+	if err := s.record(ctx, actorID, domainAudit.ActionInspectAuthKeys, userID); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *adminService) ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	entries, total, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return entries, total, nil
+}
+
+func (s *adminService) ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	if _, err := s.getUser(ctx, filter.UserID); err != nil {
+		return nil, 0, err
+	}
+
+	records, total, err := s.history.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list login history: %w", err)
+	}
+	return records, total, nil
+}
+
+func (s *adminService) ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error {
+	entries, _, err := s.auditRepo.List(ctx, domainAudit.ListFilter{ID: eventID, TargetID: userID, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to get security event: %w", err)
+	}
+	if len(entries) == 0 {
+		return ErrSecurityEventNotFound
+	}
+	return s.recordDetails(ctx, userID, domainAudit.ActionReportActivity, userID, reportDetails{
+		EventID: eventID,
+		Action:  entries[0].Action,
+		Comment: comment,
+	})
+}
+
+func (s *adminService) ImpersonateUser(ctx context.Context, actorID, userID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	if actorID == userID {
+		return nil, ErrSelfImpersonation
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == rbac.RoleAdmin {
+		return nil, ErrAdminImpersonation
+	}
+
+	var token *domainAuth.ImpersonationToken
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		token, err = s.impersonator.Impersonate(ctx, actorID, userID, reason)
+		if err != nil {
+			return err
+		}
+		return s.recordDetails(ctx, actorID, domainAudit.ActionImpersonateUser, userID, impersonationDetails{
+			ImpersonationID: token.Impersonation.ID,
+			Reason:          reason,
+			ExpiresAt:       &token.Impersonation.ExpiresAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *adminService) RevokeImpersonation(ctx context.Context, actorID, impersonationID uuid.UUID) (*domainAuth.Impersonation, error) {
+	var impersonation *domainAuth.Impersonation
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		impersonation, err = s.impersonator.RevokeImpersonation(ctx, impersonationID)
+		if err != nil {
+			return err
+		}
+		return s.recordDetails(ctx, actorID, domainAudit.ActionRevokeImpersonation, impersonation.SubjectID, impersonationDetails{
+			ImpersonationID: impersonation.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return impersonation, nil
+}
+
+// getUser loads a user, translating a missing record into ErrUserNotFound
+func (s *adminService) getUser(ctx context.Context, userID uuid.UUID) (*domainUser.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, serviceUser.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// forceLogout tells the clients connected by a user that an administrator
+// signed them out, so they close instead of waiting for their token to expire
+func (s *adminService) forceLogout(ctx context.Context, userID uuid.UUID, reason string) {
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: reason})
+}
+
+// record appends an audit log entry for an action taken by actorID
+func (s *adminService) record(ctx context.Context, actorID uuid.UUID, action domainAudit.Action, targetID uuid.UUID) error {
+	return s.recordDetails(ctx, actorID, action, targetID, nil)
+}
+
+// recordDetails is record with details stored as JSON; nil details are omitted
+func (s *adminService) recordDetails(ctx context.Context, actorID uuid.UUID, action domainAudit.Action, targetID uuid.UUID, details any) error {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return fmt.Errorf("failed to generate audit log id: %w", err)
+	}
+
+	entry := &domainAudit.Entry{
+		ID:        id,
+		ActorID:   actorID,
+		Action:    action,
+		TargetID:  targetID,
+		ClientIP:  clientip.FromContext(ctx),
+		CreatedAt: time.Now(),
+	}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit log details: %w", err)
+		}
+		entry.Details = string(data)
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,817 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// MockUserRepository is a mock implementation of the domainUser.Repository interface
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *domainUser.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*domainUser.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *domainUser.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainUser.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, filter, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
+// MockSessionRepository is a mock implementation of the domainAuth.SessionRepository interface
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) SaveSession(ctx context.Context, session *domainAuth.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAuth.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) DeleteSessions(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) PruneExpired(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockKeyInspector is a mock implementation of the domainAuth.KeyInspector interface
+type MockKeyInspector struct {
+	mock.Mock
+}
+
+func (m *MockKeyInspector) InspectUserKeys(ctx context.Context, userID uuid.UUID) ([]*domainAuth.KeyInfo, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAuth.KeyInfo), args.Error(1)
+}
+
+// MockTokenRevoker is a mock implementation of the TokenRevoker interface
+type MockTokenRevoker struct {
+	mock.Mock
+}
+
+func (m *MockTokenRevoker) Logout(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// MockImpersonator is a mock implementation of the Impersonator interface
+type MockImpersonator struct {
+	mock.Mock
+}
+
+func (m *MockImpersonator) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	args := m.Called(ctx, actorID, subjectID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.ImpersonationToken), args.Error(1)
+}
+
+func (m *MockImpersonator) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.Impersonation), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, kind domainNotification.Kind, recipient domainNotification.Recipient) {
+	m.Called(ctx, kind, recipient)
+}
+
+// MockAuditRepository is a mock implementation of the domainAudit.Repository interface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry *domainAudit.Entry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainAudit.Entry), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAuditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockLoginHistoryRepository is a mock implementation of the domainAuth.LoginHistoryRepository interface
+type MockLoginHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginHistoryRepository) Create(ctx context.Context, record *domainAuth.LoginRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockLoginHistoryRepository) List(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainAuth.LoginRecord), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockLoginHistoryRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// txKey marks contexts handed out by fakeTxManager
+type txKey struct{}
+
+// fakeTxManager runs fn with a marked context and records whether the
+// transaction would have been committed
+type fakeTxManager struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (f *fakeTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		f.rolledBack = true
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+// inTx matches contexts that belong to a fakeTxManager transaction
+var inTx = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+})
+
+// recordingPublisher keeps the published events
+type recordingPublisher struct {
+	events []domainEvent.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domainEvent.Event) {
+	p.events = append(p.events, e)
+}
+
+type testDeps struct {
+	users        *MockUserRepository
+	sessions     *MockSessionRepository
+	keys         *MockKeyInspector
+	revoker      *MockTokenRevoker
+	impersonator *MockImpersonator
+	audit        *MockAuditRepository
+	history      *MockLoginHistoryRepository
+	notifier     *MockNotifier
+	events       *recordingPublisher
+	tx           *fakeTxManager
+	service      AdminService
+}
+
+func newTestDeps() *testDeps {
+	d := &testDeps{
+		users:        new(MockUserRepository),
+		sessions:     new(MockSessionRepository),
+		keys:         new(MockKeyInspector),
+		revoker:      new(MockTokenRevoker),
+		impersonator: new(MockImpersonator),
+		audit:        new(MockAuditRepository),
+		history:      new(MockLoginHistoryRepository),
+		notifier:     new(MockNotifier),
+		events:       new(recordingPublisher),
+		tx:           new(fakeTxManager),
+	}
+	d.service = NewAdminService(d.users, d.sessions, d.keys, d.revoker, d.impersonator, d.audit, d.history, d.notifier, d.events, d.tx, idgen.GeneratorFunc(uuid.NewRandom))
+	return d
+}
+
+func auditEntry(actorID uuid.UUID, action domainAudit.Action, targetID uuid.UUID) interface{} {
+	return mock.MatchedBy(func(e *domainAudit.Entry) bool {
+		return e.ActorID == actorID && e.Action == action && e.TargetID == targetID && e.ID != uuid.Nil
+	})
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, Email: "jane@example.com", FirstName: "Jane", IsActive: true}, nil).Once()
+		d.users.On("Update", inTx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.ID == userID && u.PasswordResetRequired
+		})).Return(nil).Once()
+		d.revoker.On("Logout", ctx, userID).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionForcePasswordReset, userID)).Return(nil).Once()
+		d.notifier.On("Notify", ctx, domainNotification.KindPasswordResetRequired, domainNotification.Recipient{
+			UserID: userID, Email: "jane@example.com", Name: "Jane",
+		}).Once()
+
+		user, err := d.service.ForcePasswordReset(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, user.PasswordResetRequired)
+		assert.True(t, d.tx.committed)
+		d.users.AssertExpectations(t)
+		d.revoker.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+		d.notifier.AssertExpectations(t)
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: domainEvent.ReasonPasswordResetRequired}}, d.events.events)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		user, err := d.service.ForcePasswordReset(ctx, actorID, userID)
+
+		assert.Nil(t, user)
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Self Reset", func(t *testing.T) {
+		d := newTestDeps()
+
+		user, err := d.service.ForcePasswordReset(ctx, actorID, actorID)
+
+		assert.Nil(t, user)
+		assert.True(t, errors.Is(err, ErrSelfPasswordReset))
+		d.users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Audit Error Rolls Back", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.users.On("Update", inTx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+		d.audit.On("Create", inTx, mock.AnythingOfType("*audit.Entry")).Return(errors.New("db error")).Once()
+
+		_, err := d.service.ForcePasswordReset(ctx, actorID, userID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record audit log")
+		assert.True(t, d.tx.rolledBack)
+		d.revoker.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Revocation Error", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.users.On("Update", inTx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionForcePasswordReset, userID)).Return(nil).Once()
+		d.revoker.On("Logout", ctx, userID).Return(errors.New("redis down")).Once()
+
+		_, err := d.service.ForcePasswordReset(ctx, actorID, userID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to revoke sessions")
+		assert.True(t, d.tx.committed)
+		assert.Empty(t, d.events.events, "clients are not signed out while their sessions remain")
+	})
+}
+
+func TestExpirePassword(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+		d.users.On("Update", inTx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.ID == userID && u.PasswordExpiresAt != nil && !u.PasswordResetRequired
+		})).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionExpirePassword, userID)).Return(nil).Once()
+
+		before := time.Now()
+		user, err := d.service.ExpirePassword(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, user.PasswordExpired(0, time.Now()))
+		assert.False(t, user.PasswordExpiresAt.Before(before))
+		assert.True(t, d.tx.committed)
+		d.users.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+		d.revoker.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+		assert.Empty(t, d.events.events, "sessions are kept")
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		user, err := d.service.ExpirePassword(ctx, actorID, userID)
+
+		assert.Nil(t, user)
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeactivateUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+		d.users.On("Update", inTx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.ID == userID && !u.IsActive
+		})).Return(nil).Once()
+		d.revoker.On("Logout", ctx, userID).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionDeactivateUser, userID)).Return(nil).Once()
+
+		user, err := d.service.DeactivateUser(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.False(t, user.IsActive)
+		assert.True(t, d.tx.committed)
+		d.users.AssertExpectations(t)
+		d.revoker.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: domainEvent.ReasonDeactivated}}, d.events.events)
+	})
+
+	t.Run("Self Deactivation", func(t *testing.T) {
+		d := newTestDeps()
+
+		user, err := d.service.DeactivateUser(ctx, actorID, actorID)
+
+		assert.Nil(t, user)
+		assert.True(t, errors.Is(err, ErrSelfDeactivation))
+		d.users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update Error", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+		d.users.On("Update", inTx, mock.AnythingOfType("*user.User")).Return(errors.New("db error")).Once()
+
+		_, err := d.service.DeactivateUser(ctx, actorID, userID)
+
+		assert.Error(t, err)
+		assert.True(t, d.tx.rolledBack)
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		d.revoker.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+	})
+}
+
+func TestActivateUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.users.On("Update", inTx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.ID == userID && u.IsActive
+		})).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionActivateUser, userID)).Return(nil).Once()
+
+		user, err := d.service.ActivateUser(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, user.IsActive)
+		assert.True(t, d.tx.committed)
+		d.users.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+		d.revoker.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Active", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+
+		user, err := d.service.ActivateUser(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, user.IsActive)
+		d.users.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		user, err := d.service.ActivateUser(ctx, actorID, userID)
+
+		assert.Nil(t, user)
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+		d.users.On("Delete", inTx, userID).Return(nil).Once()
+		d.revoker.On("Logout", ctx, userID).Return(nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionDeleteUser, userID)).Return(nil).Once()
+
+		err := d.service.DeleteUser(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, d.tx.committed)
+		d.users.AssertExpectations(t)
+		d.revoker.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+		assert.Equal(t, []domainEvent.Event{
+			{Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: domainEvent.ReasonDeleted},
+			{Type: domainEvent.TypeUserDeleted, UserID: userID},
+		}, d.events.events)
+	})
+
+	t.Run("Self Deletion", func(t *testing.T) {
+		d := newTestDeps()
+
+		err := d.service.DeleteUser(ctx, actorID, actorID)
+
+		assert.True(t, errors.Is(err, ErrSelfDeletion))
+		d.users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		err := d.service.DeleteUser(ctx, actorID, userID)
+
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.users.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Delete Error", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.users.On("Delete", inTx, userID).Return(errors.New("db error")).Once()
+
+		err := d.service.DeleteUser(ctx, actorID, userID)
+
+		assert.Error(t, err)
+		assert.True(t, d.tx.rolledBack)
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		d.revoker.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
+		assert.Empty(t, d.events.events)
+	})
+}
+
+func TestListSessions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		sessions := []*domainAuth.Session{{ID: "s1", UserID: userID}}
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.sessions.On("ListSessions", ctx, userID).Return(sessions, nil).Once()
+
+		got, err := d.service.ListSessions(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, sessions, got)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		_, err := d.service.ListSessions(ctx, userID)
+
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.sessions.AssertNotCalled(t, "ListSessions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestListLoginHistory(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	filter := domainAuth.LoginHistoryFilter{UserID: userID, Offset: 20, Limit: 20}
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		records := []*domainAuth.LoginRecord{{ID: uuid.New(), UserID: userID, Result: domainAuth.LoginSucceeded}}
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.history.On("List", ctx, filter).Return(records, int64(21), nil).Once()
+
+		got, total, err := d.service.ListLoginHistory(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, records, got)
+		assert.Equal(t, int64(21), total)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		_, _, err := d.service.ListLoginHistory(ctx, filter)
+
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.history.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInspectAuthKeys(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		keys := []*domainAuth.KeyInfo{{Key: "go-user-service:prod:auth:v1:user:" + userID.String() + ":sessions", Namespace: domainAuth.KeyNamespaceSessions, Type: "hash"}}
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.keys.On("InspectUserKeys", ctx, userID).Return(keys, nil).Once()
+		d.audit.On("Create", ctx, auditEntry(actorID, domainAudit.ActionInspectAuthKeys, userID)).Return(nil).Once()
+
+		got, err := d.service.InspectAuthKeys(ctx, actorID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, keys, got)
+		d.audit.AssertExpectations(t)
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(nil, nil).Once()
+
+		_, err := d.service.InspectAuthKeys(ctx, actorID, userID)
+
+		assert.True(t, errors.Is(err, serviceUser.ErrUserNotFound))
+		d.keys.AssertNotCalled(t, "InspectUserKeys", mock.Anything, mock.Anything)
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Redis Error", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+		d.keys.On("InspectUserKeys", ctx, userID).Return(nil, errors.New("connection refused")).Once()
+
+		_, err := d.service.InspectAuthKeys(ctx, actorID, userID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to inspect auth keys")
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestImpersonateUser(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		token := &domainAuth.ImpersonationToken{
+			Impersonation: &domainAuth.Impersonation{ID: uuid.New(), ActorID: actorID, SubjectID: userID, Reason: "ticket 42", ExpiresAt: time.Now().Add(30 * time.Minute)},
+			AccessToken:   "impersonation-token",
+		}
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, Role: rbac.RoleUser, IsActive: true}, nil).Once()
+		d.impersonator.On("Impersonate", inTx, actorID, userID, "ticket 42").Return(token, nil).Once()
+		d.audit.On("Create", inTx, mock.MatchedBy(func(e *domainAudit.Entry) bool {
+			return e.ActorID == actorID && e.Action == domainAudit.ActionImpersonateUser && e.TargetID == userID &&
+				assert.Contains(t, e.Details, `"impersonation_id":"`+token.Impersonation.ID.String()+`"`) &&
+				assert.Contains(t, e.Details, `"reason":"ticket 42"`)
+		})).Return(nil).Once()
+
+		got, err := d.service.ImpersonateUser(ctx, actorID, userID, "ticket 42")
+
+		assert.NoError(t, err)
+		assert.Equal(t, token, got)
+		assert.True(t, d.tx.committed)
+		d.impersonator.AssertExpectations(t)
+		d.audit.AssertExpectations(t)
+	})
+
+	t.Run("Self Impersonation", func(t *testing.T) {
+		d := newTestDeps()
+
+		_, err := d.service.ImpersonateUser(ctx, actorID, actorID, "ticket 42")
+
+		assert.True(t, errors.Is(err, ErrSelfImpersonation))
+		d.users.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Administrator", func(t *testing.T) {
+		d := newTestDeps()
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, Role: rbac.RoleAdmin, IsActive: true}, nil).Once()
+
+		_, err := d.service.ImpersonateUser(ctx, actorID, userID, "ticket 42")
+
+		assert.True(t, errors.Is(err, ErrAdminImpersonation))
+		d.impersonator.AssertNotCalled(t, "Impersonate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Audit Error Rolls Back", func(t *testing.T) {
+		d := newTestDeps()
+		token := &domainAuth.ImpersonationToken{Impersonation: &domainAuth.Impersonation{ID: uuid.New()}}
+		d.users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, Role: rbac.RoleUser}, nil).Once()
+		d.impersonator.On("Impersonate", inTx, actorID, userID, "ticket 42").Return(token, nil).Once()
+		d.audit.On("Create", inTx, mock.AnythingOfType("*audit.Entry")).Return(errors.New("db error")).Once()
+
+		got, err := d.service.ImpersonateUser(ctx, actorID, userID, "ticket 42")
+
+		assert.Nil(t, got)
+		assert.Contains(t, err.Error(), "failed to record audit log")
+		assert.True(t, d.tx.rolledBack)
+	})
+}
+
+func TestRevokeImpersonation(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	userID := uuid.New()
+	impersonationID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		revokedAt := time.Now()
+		impersonation := &domainAuth.Impersonation{ID: impersonationID, ActorID: uuid.New(), SubjectID: userID, RevokedAt: &revokedAt}
+		d.impersonator.On("RevokeImpersonation", inTx, impersonationID).Return(impersonation, nil).Once()
+		d.audit.On("Create", inTx, auditEntry(actorID, domainAudit.ActionRevokeImpersonation, userID)).Return(nil).Once()
+
+		got, err := d.service.RevokeImpersonation(ctx, actorID, impersonationID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, impersonation, got)
+		assert.True(t, d.tx.committed)
+		d.audit.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		d := newTestDeps()
+		notFound := errors.New("impersonation not found")
+		d.impersonator.On("RevokeImpersonation", inTx, impersonationID).Return(nil, notFound).Once()
+
+		_, err := d.service.RevokeImpersonation(ctx, actorID, impersonationID)
+
+		assert.True(t, errors.Is(err, notFound))
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestListUsersAndAuditLogs(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDeps()
+
+	users := []*domainUser.User{{ID: uuid.New()}}
+	userFilter := domainUser.ListFilter{Offset: 20, Limit: 10}
+	d.users.On("List", ctx, userFilter).Return(users, int64(21), nil).Once()
+
+	gotUsers, total, err := d.service.ListUsers(ctx, userFilter)
+	assert.NoError(t, err)
+	assert.Equal(t, users, gotUsers)
+	assert.Equal(t, int64(21), total)
+
+	afterID := uuid.New()
+	pageFilter := domainUser.ListFilter{Limit: 10}
+	d.users.On("ListAfter", ctx, pageFilter, afterID).Return(users, nil).Once()
+
+	gotUsers, err = d.service.ListUsersAfter(ctx, pageFilter, afterID)
+	assert.NoError(t, err)
+	assert.Equal(t, users, gotUsers)
+
+	auditFilter := domainAudit.ListFilter{Action: domainAudit.ActionDeactivateUser, Limit: 10}
+	d.audit.On("List", ctx, auditFilter).Return(nil, int64(0), errors.New("db error")).Once()
+
+	_, _, err = d.service.ListAuditLogs(ctx, auditFilter)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list audit logs")
+}
+
+func TestReportSecurityEvent(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	eventID := uuid.New()
+	filter := domainAudit.ListFilter{ID: eventID, TargetID: userID, Limit: 1}
+
+	t.Run("Success", func(t *testing.T) {
+		d := newTestDeps()
+		event := &domainAudit.Entry{ID: eventID, ActorID: userID, Action: domainAudit.ActionNewDeviceLogin, TargetID: userID}
+		d.audit.On("List", ctx, filter).Return([]*domainAudit.Entry{event}, int64(1), nil).Once()
+		d.audit.On("Create", ctx, mock.MatchedBy(func(e *domainAudit.Entry) bool {
+			return e.ActorID == userID && e.TargetID == userID && e.Action == domainAudit.ActionReportActivity &&
+				e.Details == `{"event_id":"`+eventID.String()+`","action":"user.new_device_login","comment":"not me"}`
+		})).Return(nil).Once()
+
+		err := d.service.ReportSecurityEvent(ctx, userID, eventID, "not me")
+
+		assert.NoError(t, err)
+		d.audit.AssertExpectations(t)
+	})
+
+	t.Run("Event Of Another Account", func(t *testing.T) {
+		d := newTestDeps()
+		d.audit.On("List", ctx, filter).Return([]*domainAudit.Entry{}, int64(0), nil).Once()
+
+		err := d.service.ReportSecurityEvent(ctx, userID, eventID, "")
+
+		assert.ErrorIs(t, err, ErrSecurityEventNotFound)
+		d.audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
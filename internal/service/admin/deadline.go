@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// deadlineService runs the operations of an AdminService within the read or
+// write deadline, reporting a passed deadline as deadline.ErrTimeout
+type deadlineService struct {
+	AdminService
+	deadlines deadline.Deadlines
+}
+
+func (s *deadlineService) ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	users, total, err := s.AdminService.ListUsers(ctx, filter)
+	return users, total, deadline.Check(err)
+}
+
+func (s *deadlineService) ListUsersAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	users, err := s.AdminService.ListUsersAfter(ctx, filter, afterID)
+	return users, deadline.Check(err)
+}
+
+func (s *deadlineService) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.AdminService.ForcePasswordReset(ctx, actorID, userID)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) ExpirePassword(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.AdminService.ExpirePassword(ctx, actorID, userID)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.AdminService.DeactivateUser(ctx, actorID, userID)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.AdminService.ActivateUser(ctx, actorID, userID)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) DeleteUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.AdminService.DeleteUser(ctx, actorID, userID))
+}
+
+func (s *deadlineService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	sessions, err := s.AdminService.ListSessions(ctx, userID)
+	return sessions, deadline.Check(err)
+}
+
+// InspectAuthKeys writes an audit entry, so it gets the write deadline
+func (s *deadlineService) InspectAuthKeys(ctx context.Context, actorID, userID uuid.UUID) ([]*domainAuth.KeyInfo, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	keys, err := s.AdminService.InspectAuthKeys(ctx, actorID, userID)
+	return keys, deadline.Check(err)
+}
+
+func (s *deadlineService) ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	entries, total, err := s.AdminService.ListAuditLogs(ctx, filter)
+	return entries, total, deadline.Check(err)
+}
+
+func (s *deadlineService) ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	records, total, err := s.AdminService.ListLoginHistory(ctx, filter)
+	return records, total, deadline.Check(err)
+}
+
+func (s *deadlineService) ImpersonateUser(ctx context.Context, actorID, userID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	token, err := s.AdminService.ImpersonateUser(ctx, actorID, userID, reason)
+	return token, deadline.Check(err)
+}
+
+func (s *deadlineService) RevokeImpersonation(ctx context.Context, actorID, impersonationID uuid.UUID) (*domainAuth.Impersonation, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	impersonation, err := s.AdminService.RevokeImpersonation(ctx, actorID, impersonationID)
+	return impersonation, deadline.Check(err)
+}
+
+func (s *deadlineService) ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.AdminService.ReportSecurityEvent(ctx, userID, eventID, comment))
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+func TestWithDeadlines(t *testing.T) {
+	ctx := context.Background()
+	// The repository hangs until the deadline of the operation passes
+	waitForDeadline := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+	newService := func(d *testDeps, deadlines deadline.Deadlines) AdminService {
+		return NewAdminService(d.users, d.sessions, d.keys, d.revoker, d.impersonator, d.audit, d.history, d.notifier, d.events, d.tx, idgen.GeneratorFunc(uuid.NewRandom), WithDeadlines(deadlines))
+	}
+
+	t.Run("Read Deadline", func(t *testing.T) {
+		d := newTestDeps()
+		svc := newService(d, deadline.Deadlines{Read: 10 * time.Millisecond, Write: time.Minute})
+		d.users.On("List", mock.Anything, domainUser.ListFilter{}).Run(waitForDeadline).Return(nil, int64(0), context.DeadlineExceeded).Once()
+
+		start := time.Now()
+		_, _, err := svc.ListUsers(ctx, domainUser.ListFilter{})
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Write Deadline", func(t *testing.T) {
+		d := newTestDeps()
+		svc := newService(d, deadline.Deadlines{Read: time.Minute, Write: 10 * time.Millisecond})
+		userID := uuid.New()
+		d.users.On("GetByID", mock.Anything, userID).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+		err := svc.DeleteUser(ctx, uuid.New(), userID)
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+	})
+
+	t.Run("No Deadlines", func(t *testing.T) {
+		assert.IsType(t, &adminService{}, newService(newTestDeps(), deadline.Deadlines{}))
+	})
+}
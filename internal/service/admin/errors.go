@@ -0,0 +1,17 @@
+package admin
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for administrative operations
+var (
+	ErrSelfDeactivation  = apperror.New(apperror.CodeInvalidArgument, "administrators cannot deactivate their own account")
+	ErrSelfPasswordReset = apperror.New(apperror.CodeInvalidArgument, "administrators cannot force a password reset on their own account")
+	ErrSelfDeletion      = apperror.New(apperror.CodeInvalidArgument, "administrators cannot delete their own account")
+	ErrSelfImpersonation = apperror.New(apperror.CodeInvalidArgument, "administrators cannot impersonate themselves")
+	// ErrAdminImpersonation keeps an impersonation token from carrying
+	// administrator privileges that the audit trail would attribute to
+	// another administrator
+	ErrAdminImpersonation = apperror.New(apperror.CodePermissionDenied, "administrators cannot be impersonated")
+	// ErrSecurityEventNotFound is also returned for events of other accounts
+	ErrSecurityEventNotFound = apperror.New(apperror.CodeSecurityEventNotFound, "security event not found")
+)
@@ -0,0 +1,260 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// SecretPrefix starts every API key secret so that leaked keys are easy to
+// recognize, and so that they are never mistaken for access tokens
+const SecretPrefix = "usk_"
+
+// displayPrefixLen is the number of leading secret characters kept to tell keys apart
+const displayPrefixLen = 12
+
+// lastUsedResolution limits how often the last use of a key is written
+const lastUsedResolution = time.Minute
+
+// maxNameLen is the longest key name accepted
+const maxNameLen = 100
+
+// Service defines the interface for organization API key business logic.
+// Keys always belong to the tenant of the caller; keys of other tenants are
+// reported as not found.
+type Service interface {
+	// Create issues a new key for the tenant and returns it with its secret,
+	// which is not stored and cannot be shown again
+	Create(ctx context.Context, actorID uuid.UUID, tenant string, input domainAPIKey.Input) (*domainAPIKey.APIKey, string, error)
+
+	// List returns every key of the tenant, newest first
+	List(ctx context.Context, tenant string) ([]*domainAPIKey.APIKey, error)
+
+	// Rotate issues a replacement for a key with the same name and scopes.
+	// The old key stays valid for the configured overlap so that clients can
+	// switch over without downtime.
+	Rotate(ctx context.Context, actorID uuid.UUID, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, string, error)
+
+	// Revoke disables a key immediately
+	Revoke(ctx context.Context, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, error)
+
+	// Authenticate returns the active key matching secret and records its use
+	Authenticate(ctx context.Context, secret string) (*domainAPIKey.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo            domainAPIKey.Repository
+	ids             idgen.Generator
+	rotationOverlap time.Duration
+	logger          *zap.Logger
+	now             func() time.Time
+}
+
+// NewService creates a new instance of Service. Rotated keys stay valid for
+// rotationOverlap after their replacement is issued.
+func NewService(repo domainAPIKey.Repository, ids idgen.Generator, rotationOverlap time.Duration, logger *zap.Logger) Service {
+	return &apiKeyService{
+		repo:            repo,
+		ids:             ids,
+		rotationOverlap: rotationOverlap,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, actorID uuid.UUID, tenant string, input domainAPIKey.Input) (*domainAPIKey.APIKey, string, error) {
+	if tenant == "" {
+		return nil, "", ErrTenantRequired
+	}
+	input, err := s.normalizeInput(input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, secret, err := s.newKey(actorID, tenant)
+	if err != nil {
+		return nil, "", err
+	}
+	key.Name = input.Name
+	key.Scopes = input.Scopes
+	key.ExpiresAt = input.ExpiresAt
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, secret, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, tenant string) ([]*domainAPIKey.APIKey, error) {
+	if tenant == "" {
+		return nil, ErrTenantRequired
+	}
+	keys, err := s.repo.ListByTenant(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) Rotate(ctx context.Context, actorID uuid.UUID, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, string, error) {
+	old, err := s.get(ctx, tenant, id)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	if !old.IsActive(now) || old.RotatedTo != nil {
+		return nil, "", ErrAPIKeyInactive
+	}
+
+	replacement, secret, err := s.newKey(actorID, tenant)
+	if err != nil {
+		return nil, "", err
+	}
+	replacement.Name = old.Name
+	replacement.Scopes = old.Scopes
+	// The replacement gets the lifetime the old key was issued with
+	if old.ExpiresAt != nil {
+		expiresAt := now.Add(old.ExpiresAt.Sub(old.CreatedAt))
+		replacement.ExpiresAt = &expiresAt
+	}
+
+	graceEnd := now.Add(s.rotationOverlap)
+	if old.ExpiresAt == nil || graceEnd.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &graceEnd
+	}
+	old.RotatedTo = &replacement.ID
+
+	if err := s.repo.Rotate(ctx, old, replacement); err != nil {
+		return nil, "", fmt.Errorf("failed to rotate API key: %w", err)
+	}
+	return replacement, secret, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, error) {
+	key, err := s.get(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := s.now()
+	key.RevokedAt = &now
+	if err := s.repo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, secret string) (*domainAPIKey.APIKey, error) {
+	if !strings.HasPrefix(secret, SecretPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetBySecretHash(ctx, hashSecret(secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	now := s.now()
+	if key == nil || !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Last use is tracked to the minute so that busy keys do not cause a
+	// write per request; failing to record it does not reject the request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			s.logger.Warn("Failed to record API key use",
+				zap.String("api_key_id", key.ID.String()),
+				zap.Error(err))
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+// get returns a key of the tenant
+func (s *apiKeyService) get(ctx context.Context, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, error) {
+	if tenant == "" {
+		return nil, ErrTenantRequired
+	}
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if key == nil || key.Tenant != tenant {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// newKey creates a key of the tenant with a fresh ID and secret
+func (s *apiKeyService) newKey(actorID uuid.UUID, tenant string) (*domainAPIKey.APIKey, string, error) {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key id: %w", err)
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key secret: %w", err)
+	}
+	secret := SecretPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	return &domainAPIKey.APIKey{
+		ID:         id,
+		Tenant:     tenant,
+		Prefix:     secret[:displayPrefixLen],
+		SecretHash: hashSecret(secret),
+		CreatedBy:  actorID,
+		CreatedAt:  s.now(),
+	}, secret, nil
+}
+
+// normalizeInput validates the input and removes duplicate scopes
+func (s *apiKeyService) normalizeInput(input domainAPIKey.Input) (domainAPIKey.Input, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > maxNameLen {
+		return input, ErrNameRequired
+	}
+
+	if len(input.Scopes) == 0 {
+		return input, ErrScopesRequired
+	}
+	scopes := make([]domainAPIKey.Scope, 0, len(input.Scopes))
+	seen := make(map[domainAPIKey.Scope]struct{}, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !scope.Valid() {
+			return input, ErrUnknownScope
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		scopes = append(scopes, scope)
+	}
+	input.Scopes = scopes
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return input, ErrInvalidExpiry
+	}
+	return input, nil
+}
+
+// hashSecret returns the hex SHA-256 of a secret. Secrets are random, so a
+// fast unsalted hash is enough and lets keys be looked up by their hash.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
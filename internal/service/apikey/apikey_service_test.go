@@ -0,0 +1,275 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// memoryRepository is an in-memory domainAPIKey.Repository
+type memoryRepository struct {
+	keys     map[uuid.UUID]domainAPIKey.APIKey
+	touchErr error
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{keys: map[uuid.UUID]domainAPIKey.APIKey{}}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, key *domainAPIKey.APIKey) error {
+	r.keys[key.ID] = *key
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainAPIKey.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (r *memoryRepository) GetBySecretHash(ctx context.Context, hash string) (*domainAPIKey.APIKey, error) {
+	for _, key := range r.keys {
+		if key.SecretHash == hash {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) ListByTenant(ctx context.Context, tenant string) ([]*domainAPIKey.APIKey, error) {
+	var keys []*domainAPIKey.APIKey
+	for _, key := range r.keys {
+		if key.Tenant == tenant {
+			key := key
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, key *domainAPIKey.APIKey) error {
+	r.keys[key.ID] = *key
+	return nil
+}
+
+func (r *memoryRepository) Rotate(ctx context.Context, old, replacement *domainAPIKey.APIKey) error {
+	r.keys[replacement.ID] = *replacement
+	r.keys[old.ID] = *old
+	return nil
+}
+
+func (r *memoryRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if r.touchErr != nil {
+		return r.touchErr
+	}
+	key := r.keys[id]
+	key.LastUsedAt = &at
+	r.keys[id] = key
+	return nil
+}
+
+var testNow = time.Date(2025, 6, 27, 9, 0, 0, 0, time.UTC)
+
+func newTestService(repo *memoryRepository, now *time.Time) *apiKeyService {
+	s := NewService(repo, idgen.GeneratorFunc(uuid.NewRandom), time.Hour, zap.NewNop()).(*apiKeyService)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	now := testNow
+	expiresAt := testNow.Add(30 * 24 * time.Hour)
+	past := testNow.Add(-time.Minute)
+
+	t.Run("Success", func(t *testing.T) {
+		repo := newMemoryRepository()
+		key, secret, err := newTestService(repo, &now).Create(ctx, actorID, "acme", domainAPIKey.Input{
+			Name:      "  CI sync ",
+			Scopes:    []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead, domainAPIKey.ScopeUsersRead},
+			ExpiresAt: &expiresAt,
+		})
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(secret, SecretPrefix))
+		assert.Equal(t, secret[:displayPrefixLen], key.Prefix)
+		assert.Equal(t, "acme", key.Tenant)
+		assert.Equal(t, "CI sync", key.Name)
+		assert.Equal(t, []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}, key.Scopes)
+		assert.Equal(t, actorID, key.CreatedBy)
+		assert.NotContains(t, key.SecretHash, secret, "only a hash of the secret is stored")
+		assert.Len(t, repo.keys, 1)
+	})
+
+	tests := []struct {
+		name    string
+		tenant  string
+		input   domainAPIKey.Input
+		wantErr error
+	}{
+		{"No Tenant", "", domainAPIKey.Input{Name: "key", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}}, ErrTenantRequired},
+		{"Blank Name", "acme", domainAPIKey.Input{Name: " ", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}}, ErrNameRequired},
+		{"No Scopes", "acme", domainAPIKey.Input{Name: "key"}, ErrScopesRequired},
+		{"Unknown Scope", "acme", domainAPIKey.Input{Name: "key", Scopes: []domainAPIKey.Scope{"users:write"}}, ErrUnknownScope},
+		{"Expiry In The Past", "acme", domainAPIKey.Input{Name: "key", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}, ExpiresAt: &past}, ErrInvalidExpiry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryRepository()
+			_, _, err := newTestService(repo, &now).Create(ctx, actorID, tt.tenant, tt.input)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, repo.keys)
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	input := domainAPIKey.Input{Name: "CI sync", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}}
+
+	t.Run("Old Key Valid During Overlap", func(t *testing.T) {
+		now := testNow
+		repo := newMemoryRepository()
+		s := newTestService(repo, &now)
+		old, oldSecret, err := s.Create(ctx, actorID, "acme", input)
+		require.NoError(t, err)
+
+		now = testNow.Add(10 * 24 * time.Hour)
+		replacement, newSecret, err := s.Rotate(ctx, actorID, "acme", old.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, oldSecret, newSecret)
+		assert.Equal(t, old.Name, replacement.Name)
+		assert.Equal(t, old.Scopes, replacement.Scopes)
+		assert.Nil(t, replacement.ExpiresAt)
+
+		rotated, _ := repo.GetByID(ctx, old.ID)
+		assert.Equal(t, &replacement.ID, rotated.RotatedTo)
+		assert.Equal(t, now.Add(time.Hour), *rotated.ExpiresAt)
+
+		// Both keys work until the overlap ends
+		_, err = s.Authenticate(ctx, oldSecret)
+		assert.NoError(t, err)
+		_, err = s.Authenticate(ctx, newSecret)
+		assert.NoError(t, err)
+
+		now = now.Add(time.Hour)
+		_, err = s.Authenticate(ctx, oldSecret)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+		_, err = s.Authenticate(ctx, newSecret)
+		assert.NoError(t, err)
+
+		// A key can only be rotated once
+		_, _, err = s.Rotate(ctx, actorID, "acme", old.ID)
+		assert.ErrorIs(t, err, ErrAPIKeyInactive)
+	})
+
+	t.Run("Replacement Keeps Lifetime", func(t *testing.T) {
+		now := testNow
+		repo := newMemoryRepository()
+		s := newTestService(repo, &now)
+		expiresAt := testNow.Add(30 * time.Minute)
+		withExpiry := input
+		withExpiry.ExpiresAt = &expiresAt
+		old, _, err := s.Create(ctx, actorID, "acme", withExpiry)
+		require.NoError(t, err)
+
+		now = testNow.Add(10 * time.Minute)
+		replacement, _, err := s.Rotate(ctx, actorID, "acme", old.ID)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(30*time.Minute), *replacement.ExpiresAt)
+
+		// The overlap never extends the old key
+		rotated, _ := repo.GetByID(ctx, old.ID)
+		assert.Equal(t, expiresAt, *rotated.ExpiresAt)
+	})
+
+	t.Run("Other Tenant", func(t *testing.T) {
+		now := testNow
+		repo := newMemoryRepository()
+		s := newTestService(repo, &now)
+		old, _, err := s.Create(ctx, actorID, "acme", input)
+		require.NoError(t, err)
+
+		_, _, err = s.Rotate(ctx, actorID, "globex", old.ID)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+		_, err = s.Revoke(ctx, "globex", old.ID)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	now := testNow
+	repo := newMemoryRepository()
+	s := newTestService(repo, &now)
+	key, secret, err := s.Create(ctx, uuid.New(), "acme", domainAPIKey.Input{Name: "key", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}})
+	require.NoError(t, err)
+
+	revoked, err := s.Revoke(ctx, "acme", key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, testNow, *revoked.RevokedAt)
+
+	_, err = s.Authenticate(ctx, secret)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	_, _, err = s.Rotate(ctx, uuid.New(), "acme", key.ID)
+	assert.ErrorIs(t, err, ErrAPIKeyInactive)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	now := testNow
+	repo := newMemoryRepository()
+	s := newTestService(repo, &now)
+	key, secret, err := s.Create(ctx, uuid.New(), "acme", domainAPIKey.Input{Name: "key", Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead}})
+	require.NoError(t, err)
+
+	t.Run("Unknown Or Malformed", func(t *testing.T) {
+		_, err := s.Authenticate(ctx, "not-a-key")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+		_, err = s.Authenticate(ctx, SecretPrefix+"unknown")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Tracks Last Use To The Minute", func(t *testing.T) {
+		got, err := s.Authenticate(ctx, secret)
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, got.ID)
+		assert.Equal(t, testNow, *repo.keys[key.ID].LastUsedAt)
+
+		now = testNow.Add(30 * time.Second)
+		_, err = s.Authenticate(ctx, secret)
+		require.NoError(t, err)
+		assert.Equal(t, testNow, *repo.keys[key.ID].LastUsedAt)
+
+		now = testNow.Add(time.Minute)
+		_, err = s.Authenticate(ctx, secret)
+		require.NoError(t, err)
+		assert.Equal(t, now, *repo.keys[key.ID].LastUsedAt)
+	})
+
+	t.Run("Tracking Failure Does Not Reject", func(t *testing.T) {
+		repo.touchErr = errors.New("database is read-only")
+		now = testNow.Add(time.Hour)
+
+		_, err := s.Authenticate(ctx, secret)
+		assert.NoError(t, err)
+	})
+}
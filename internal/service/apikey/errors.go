@@ -0,0 +1,15 @@
+package apikey
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for API key operations
+var (
+	ErrAPIKeyNotFound = apperror.New(apperror.CodeAPIKeyNotFound, "API key not found")
+	ErrInvalidAPIKey  = apperror.New(apperror.CodeInvalidAPIKey, "API key is invalid, expired or revoked")
+	ErrTenantRequired = apperror.New(apperror.CodePermissionDenied, "API keys can only be managed by members of an organization")
+	ErrNameRequired   = apperror.New(apperror.CodeInvalidArgument, "name is required and must be at most 100 characters")
+	ErrScopesRequired = apperror.New(apperror.CodeInvalidArgument, "at least one scope is required")
+	ErrUnknownScope   = apperror.New(apperror.CodeInvalidArgument, "scopes must be known scope names")
+	ErrInvalidExpiry  = apperror.New(apperror.CodeInvalidArgument, "expires_at must be in the future")
+	ErrAPIKeyInactive = apperror.New(apperror.CodeInvalidArgument, "API key is expired, revoked or already rotated")
+)
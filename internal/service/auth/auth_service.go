@@ -2,41 +2,245 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	// "golang.org/x/crypto/bcrypt" // No longer used directly
+	"go.uber.org/zap"
 
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/cache"
 	"github.com/yi-tech/go-user-service/internal/config"
-	"strings" // Added for strings.Contains
-
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
 	userService "github.com/yi-tech/go-user-service/internal/service/user" // For user.ErrUserNotFound
+	"github.com/yi-tech/go-user-service/internal/useragent"
 )
 
 // Service implements the domainAuth.AuthService interface
 type Service struct {
 	userService domainUser.UserService
 	authRepo    domainAuth.AuthRepository
+	sessions    domainAuth.SessionRepository // Optional; nil disables session tracking
 	config      *config.Config
+	keys        *KeyRing
+	logger      *zap.Logger
+	tokens      *cache.Cache[[sha256.Size]byte, uuid.UUID] // Optional; validated access tokens by hash
+	metrics     *Metrics                                   // Optional; counts failed best-effort steps
+
+	loginHistory   domainAuth.LoginHistoryRepository  // Optional; nil disables the sign-in history
+	impersonations domainAuth.ImpersonationRepository // Optional; nil disables impersonation
+	events         domainEvent.Publisher              // Tells connected clients about sign-outs
+
+	// Alerts about sign-ins from new devices; disabled when security is nil
+	security domainAudit.SecurityLog
+	notifier domainNotification.Notifier
+
+	// CAPTCHA escalation of sign-ins; disabled when attempts is nil
+	attempts      domainAuth.LoginAttemptRepository
+	captcha       captcha.Verifier
+	captchaAfter  int64
+	failureWindow time.Duration
+
+	deadlines deadline.Deadlines // Bounds operations; zero sets no deadline
+}
+
+// Option configures optional behaviour of the auth service
+type Option func(*Service)
+
+// WithTokenCache remembers validated access tokens so repeated requests with
+// the same token skip signature verification. Entries never outlive the token,
+// but a token signed with a key retired from the ring keeps validating until
+// its entry expires.
+func WithTokenCache(tokens *cache.Cache[[sha256.Size]byte, uuid.UUID]) Option {
+	return func(s *Service) {
+		s.tokens = tokens
+	}
+}
+
+// WithMetrics counts the failures of best-effort steps, such as deleting a
+// replaced refresh token, which are otherwise only logged
+func WithMetrics(metrics *Metrics) Option {
+	return func(s *Service) {
+		s.metrics = metrics
+	}
 }
 
-// NewService creates a new auth service instance
-func NewService(userService domainUser.UserService, authRepo domainAuth.AuthRepository, config *config.Config) domainAuth.AuthService {
-	return &Service{
+// WithCaptchaEscalation requires a CAPTCHA token on sign-in once the client IP
+// or the account has after failed attempts, until no attempt has failed for
+// window. Counting is best effort: when attempts cannot be read or recorded
+// the sign-in proceeds without a challenge.
+func WithCaptchaEscalation(attempts domainAuth.LoginAttemptRepository, verifier captcha.Verifier, after int, window time.Duration) Option {
+	return func(s *Service) {
+		s.attempts = attempts
+		s.captcha = verifier
+		s.captchaAfter = int64(after)
+		s.failureWindow = window
+	}
+}
+
+// WithLoginHistory records the sign-in attempts made against each account,
+// with their client IP, user agent and outcome. Recording is best effort: a
+// failure to record does not fail the sign-in.
+func WithLoginHistory(history domainAuth.LoginHistoryRepository) Option {
+	return func(s *Service) {
+		s.loginHistory = history
+	}
+}
+
+// WithImpersonation lets administrators mint access tokens acting as other
+// users, recording each impersonation in impersonations so that it can be
+// revoked
+func WithImpersonation(impersonations domainAuth.ImpersonationRepository) Option {
+	return func(s *Service) {
+		s.impersonations = impersonations
+	}
+}
+
+// WithEventPublisher tells the clients connected by a user when they sign
+// out everywhere, so that other devices can drop their tokens, and admin
+// dashboards when they sign in
+func WithEventPublisher(events domainEvent.Publisher) Option {
+	return func(s *Service) {
+		s.events = events
+	}
+}
+
+// WithNewDeviceAlerts records a security event in security and notifies the
+// user through notifier when they sign in from a device or client IP none of
+// their recent successful sign-ins came from. Recent sign-ins are read from
+// the history of WithLoginHistory; the first sign-in of an account raises no
+// alert.
+func WithNewDeviceAlerts(security domainAudit.SecurityLog, notifier domainNotification.Notifier) Option {
+	return func(s *Service) {
+		s.security = security
+		s.notifier = notifier
+	}
+}
+
+// WithDeadlines runs token validation within the read deadline and
+// sign-ins, refreshes, sign-outs and impersonations within the write
+// deadline of deadlines. Operations that run out of time fail with
+// deadline.ErrTimeout.
+func WithDeadlines(deadlines deadline.Deadlines) Option {
+	return func(s *Service) {
+		s.deadlines = deadlines
+	}
+}
+
+// NewService creates a new auth service instance.
+// sessions may be nil to disable session tracking. When keys is nil the
+// signing key ring is built from the JWT configuration, and an error is
+// returned if the configuration contains no usable key.
+func NewService(userService domainUser.UserService, authRepo domainAuth.AuthRepository, sessions domainAuth.SessionRepository, config *config.Config, keys *KeyRing, logger *zap.Logger, opts ...Option) (domainAuth.AuthService, error) {
+	if keys == nil {
+		var err error
+		if keys, err = NewKeyRing(config.JWT); err != nil {
+			return nil, err
+		}
+	}
+	s := &Service{
 		userService: userService,
 		authRepo:    authRepo,
+		sessions:    sessions,
 		config:      config,
+		keys:        keys,
+		logger:      logger,
+		events:      domainEvent.Discard,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if !s.deadlines.IsZero() {
+		return &deadlineService{AuthService: s, deadlines: s.deadlines}, nil
+	}
+	return s, nil
 }
 
 // Login handles user authentication and token generation
 func (s *Service) Login(ctx context.Context, input domainAuth.LoginInput) (*domainAuth.TokenPair, error) {
+	if err := s.checkCaptcha(ctx, input); err != nil {
+		return nil, err
+	}
+
+	user, err := s.checkCredentials(ctx, input)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			s.recordFailure(ctx, input)
+			if user != nil {
+				s.recordLogin(ctx, user.ID, input.UserAgent, input.ClientIP, domainAuth.LoginInvalidPassword)
+			}
+		}
+		return nil, err
+	}
+	s.resetFailures(ctx, input)
+
+	// Only reveal the account state to callers who know the password
+	if err := checkAccount(user); err != nil {
+		s.recordLogin(ctx, user.ID, input.UserAgent, input.ClientIP, loginResultOf(err))
+		return nil, err
+	}
+	// An expired password only blocks signing in with it; sessions already
+	// issued keep working until the password is changed
+	if user.PasswordExpired(s.config.Password.MaxAge(), time.Now()) {
+		s.recordLogin(ctx, user.ID, input.UserAgent, input.ClientIP, domainAuth.LoginPasswordExpired)
+		return nil, ErrPasswordExpired
+	}
+
+	// Legacy hashes, such as bcrypt ones, are upgraded while the password is
+	// at hand; the sign-in goes ahead if that fails
+	if err := s.userService.RehashPassword(ctx, user, input.Password); err != nil {
+		s.warn(opRehashPassword, "Failed to rehash password", err, zap.String("user_id", user.ID.String()))
+	}
+
+	sessionType := domainAuth.SessionStandard
+	if input.RememberMe {
+		sessionType = domainAuth.SessionRememberMe
+	}
+	tokens, err := s.issueTokens(ctx, user, sessionType, input.UserAgent, input.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	s.signedIn(ctx, user, input.UserAgent, input.ClientIP)
+	return tokens, nil
+}
+
+// LoginExternal signs in a user authenticated by an external identity
+// provider. The account must still be allowed to use tokens, and the
+// sign-in is recorded and published like a password login.
+func (s *Service) LoginExternal(ctx context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	user, err := s.userService.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAccount(user); err != nil {
+		s.recordLogin(ctx, user.ID, input.UserAgent, input.ClientIP, loginResultOf(err))
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, user, domainAuth.SessionStandard, input.UserAgent, input.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	s.signedIn(ctx, user, input.UserAgent, input.ClientIP)
+	return tokens, nil
+}
+
+// checkCredentials returns the user signing in, or ErrInvalidCredentials when
+// the email or password is wrong. The user is returned along with the error
+// when only the password is wrong.
+func (s *Service) checkCredentials(ctx context.Context, input domainAuth.LoginInput) (*domainUser.User, error) {
 	// Find user by email
 	user, err := s.userService.GetByEmail(ctx, input.Email)
 	if err != nil {
@@ -49,53 +253,256 @@ func (s *Service) Login(ctx context.Context, input domainAuth.LoginInput) (*doma
 	// If we reach here, user should not be nil if GetByEmail contract is (*User, ErrUserNotFound) or (*User, nil)
 	// Adding a safeguard, though ideally GetByEmail guarantees non-nil user if err is nil.
 	if user == nil {
-	    return nil, ErrInvalidCredentials // Should be unreachable if GetByEmail is consistent
+		return nil, ErrInvalidCredentials // Should be unreachable if GetByEmail is consistent
 	}
 
 	// Verify password
 	if !user.CheckPassword(input.Password) {
-		return nil, ErrInvalidCredentials // Password incorrect
+		return user, ErrInvalidCredentials // Password incorrect
 	}
+	return user, nil
+}
 
-	// Generate JWT access token
-	expiresAt := time.Now().Add(time.Minute * time.Duration(s.config.JWT.AccessTokenExpireMinutes))
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"exp":     expiresAt.Unix(),
-		"iat":     time.Now().Unix(),
-	})
+// checkCaptcha verifies the CAPTCHA token of a sign-in once the client IP or
+// the account has reached the failed attempt threshold
+func (s *Service) checkCaptcha(ctx context.Context, input domainAuth.LoginInput) error {
+	if s.attempts == nil {
+		return nil
+	}
+	ipFailures, accountFailures, err := s.attempts.CountFailures(ctx, input.ClientIP, input.Email)
+	if err != nil {
+		s.warn(opCountLoginFailures, "Failed to count login failures; skipping captcha check", err)
+		return nil
+	}
+	if max(ipFailures, accountFailures) < s.captchaAfter {
+		return nil
+	}
+	if input.CaptchaToken == "" {
+		return ErrCaptchaRequired
+	}
+	return s.captcha.Verify(ctx, input.CaptchaToken, input.ClientIP)
+}
+
+// warn logs the failure of a best-effort step with the fields describing it
+// and counts it by operation
+func (s *Service) warn(operation, message string, err error, fields ...zap.Field) {
+	s.metrics.failed(operation)
+	s.logger.Warn(message, append(fields, zap.String("operation", operation), zap.Error(err))...)
+}
 
-	accessToken, err := claims.SignedString([]byte(s.config.JWT.Secret))
+// recordFailure counts a sign-in with invalid credentials towards the CAPTCHA threshold
+func (s *Service) recordFailure(ctx context.Context, input domainAuth.LoginInput) {
+	if s.attempts == nil {
+		return
+	}
+	if err := s.attempts.RecordFailure(ctx, input.ClientIP, input.Email, s.failureWindow); err != nil {
+		s.warn(opRecordLoginFailure, "Failed to record login failure", err)
+	}
+}
+
+// resetFailures forgets the failed attempts against an account once its
+// password was given. The client IP keeps its count, so one known password
+// does not clear the way for guessing others from the same address.
+func (s *Service) resetFailures(ctx context.Context, input domainAuth.LoginInput) {
+	if s.attempts == nil {
+		return
+	}
+	if err := s.attempts.ResetAccountFailures(ctx, input.Email); err != nil {
+		s.warn(opResetLoginFailures, "Failed to reset login failures", err)
+	}
+}
+
+// recordLogin adds a sign-in attempt to the history of the account it was made against
+func (s *Service) recordLogin(ctx context.Context, userID uuid.UUID, userAgent, clientIP string, result domainAuth.LoginResult) {
+	if s.loginHistory == nil {
+		return
+	}
+	record := &domainAuth.LoginRecord{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Result:    result,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+	if err := s.loginHistory.Create(ctx, record); err != nil {
+		s.warn(opRecordLoginHistory, "Failed to record login history", err,
+			zap.String("user_id", userID.String()),
+			zap.String("result", string(result)))
+	}
+}
+
+// signedIn records a successful sign-in, alerting the user first when it
+// comes from a new device, and tells admin dashboards about it
+func (s *Service) signedIn(ctx context.Context, user *domainUser.User, userAgent, clientIP string) {
+	s.alertNewDevice(ctx, user, userAgent, clientIP)
+	s.recordLogin(ctx, user.ID, userAgent, clientIP, domainAuth.LoginSucceeded)
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserLoggedIn, UserID: user.ID})
+}
+
+// newDeviceLookback is how many of the latest sign-in attempts a sign-in is
+// compared against to tell whether it comes from a new device
+const newDeviceLookback = 100
+
+// newDeviceDetails is the security event detail of a sign-in from a new device
+type newDeviceDetails struct {
+	Device    string `json:"device"`
+	UserAgent string `json:"user_agent"`
+	ClientIP  string `json:"client_ip"`
+}
+
+// alertNewDevice records a security event and notifies the user when no
+// recent successful sign-in came from the same device at the same client IP.
+// Sign-ins are compared by device label, so browser updates raise no alert.
+func (s *Service) alertNewDevice(ctx context.Context, user *domainUser.User, userAgent, clientIP string) {
+	if s.security == nil || s.loginHistory == nil {
+		return
+	}
+	records, _, err := s.loginHistory.List(ctx, domainAuth.LoginHistoryFilter{UserID: user.ID, Limit: newDeviceLookback})
+	if err != nil {
+		s.warn(opReadLoginHistory, "Failed to read login history; skipping new device check", err, zap.String("user_id", user.ID.String()))
+		return
+	}
+
+	device := useragent.Label(userAgent)
+	signedInBefore := false
+	for _, record := range records {
+		if !record.Succeeded() {
+			continue
+		}
+		if record.ClientIP == clientIP && useragent.Label(record.UserAgent) == device {
+			return
+		}
+		signedInBefore = true
+	}
+	if !signedInBefore {
+		return
+	}
+
+	s.security.Record(ctx, user.ID, domainAudit.ActionNewDeviceLogin, newDeviceDetails{Device: device, UserAgent: userAgent, ClientIP: clientIP})
+	s.notifier.Notify(ctx, domainNotification.KindNewDeviceLogin, domainNotification.RecipientOf(user))
+}
+
+// loginResultOf returns the recorded outcome of a sign-in rejected by checkAccount
+func loginResultOf(err error) domainAuth.LoginResult {
+	if errors.Is(err, ErrPasswordResetRequired) {
+		return domainAuth.LoginPasswordResetRequired
+	}
+	return domainAuth.LoginAccountDisabled
+}
+
+// CompletePasswordReset verifies the current credentials of a user flagged
+// for a password reset or whose password has expired, stores the new
+// password and signs the user in
+func (s *Service) CompletePasswordReset(ctx context.Context, input domainAuth.PasswordResetInput) (*domainAuth.TokenPair, error) {
+	user, err := s.userService.GetByEmail(ctx, input.Email)
+	if err != nil {
+		if errors.Is(err, userService.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("error retrieving user by email for password reset: %w", err)
+	}
+	if user == nil || !user.CheckPassword(input.CurrentPassword) {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if !user.PasswordResetRequired && !user.PasswordExpired(s.config.Password.MaxAge(), time.Now()) {
+		return nil, ErrNoPasswordReset
+	}
+	if input.NewPassword == input.CurrentPassword {
+		return nil, ErrPasswordReused
+	}
+
+	// UpdatePassword clears the reset flag and expiry along with the new hash
+	if err := s.userService.UpdatePassword(ctx, user.ID, input.CurrentPassword, input.NewPassword); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, user, domainAuth.SessionStandard, input.UserAgent, input.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	s.signedIn(ctx, user, input.UserAgent, input.ClientIP)
+	return tokens, nil
+}
+
+// issueTokens signs an access token and stores a new refresh token and session for user
+func (s *Service) issueTokens(ctx context.Context, user *domainUser.User, sessionType domainAuth.SessionType, userAgent, clientIP string) (*domainAuth.TokenPair, error) {
+	// Generate JWT access token
+	accessToken, accessTokenExpiresAt, err := s.generateAccessToken(user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign access token: %w", err)
 	}
 
 	// Generate refresh token and store in repository
-	refreshToken := uuid.New().String()
-	refreshTokenExpiry := time.Duration(s.config.JWT.RefreshTokenExpireDays) * 24 * time.Hour
+	refreshToken := generateRefreshToken(sessionType)
+	refreshTokenExpiry := s.config.JWT.RefreshTokenExpiry(sessionType == domainAuth.SessionRememberMe)
 
 	err = s.authRepo.SetUserRefreshToken(ctx, user.ID, refreshToken, refreshTokenExpiry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to store user refresh token: %w", err)
+		if errors.Is(err, domainAuth.ErrStoreUnavailable) && s.config.Redis.Failover.StatelessFallback {
+			// Access tokens validate without Redis, so the user can work
+			// until it expires; there is just no session to refresh
+			s.logger.Warn("Auth store unavailable; issuing access token only",
+				zap.String("user_id", user.ID.String()),
+				zap.Error(err))
+			return &domainAuth.TokenPair{AccessToken: accessToken, AccessTokenExpiresAt: accessTokenExpiresAt}, nil
+		}
+		return nil, storeError("failed to store user refresh token", err)
 	}
 	err = s.authRepo.SetRefreshTokenUserID(ctx, refreshToken, user.ID, refreshTokenExpiry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
 	}
 
+	if s.sessions != nil {
+		session := domainAuth.NewSession(user.ID, refreshToken, sessionType, userAgent, clientIP, refreshTokenExpiry)
+		if err := s.sessions.SaveSession(ctx, session); err != nil {
+			// Session tracking is informational; the tokens are already valid
+			s.warn(opRecordSession, "Failed to record session", err,
+				zap.String("user_id", user.ID.String()),
+				zap.String("session_type", string(sessionType)))
+		}
+	}
+
 	// Return token pair
 	return &domainAuth.TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshTokenExpiresAt: time.Now().Add(refreshTokenExpiry),
 	}, nil
 }
 
-// RefreshToken handles token refresh logic
+// rememberMeTokenPrefix marks the refresh tokens of "remember me" sessions,
+// so rotating one keeps the longer lifetime without another Redis lookup
+const rememberMeTokenPrefix = "rm-"
+
+// generateRefreshToken generates a refresh token for a session of sessionType
+func generateRefreshToken(sessionType domainAuth.SessionType) string {
+	if sessionType == domainAuth.SessionRememberMe {
+		return rememberMeTokenPrefix + uuid.New().String()
+	}
+	return uuid.New().String()
+}
+
+// sessionTypeOf returns the type of the session a refresh token was issued to
+func sessionTypeOf(refreshToken string) domainAuth.SessionType {
+	if strings.HasPrefix(refreshToken, rememberMeTokenPrefix) {
+		return domainAuth.SessionRememberMe
+	}
+	return domainAuth.SessionStandard
+}
+
+// RefreshToken handles token refresh logic. The new refresh token keeps the
+// session type, and so the lifetime, of the one it replaces.
 func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*domainAuth.TokenPair, error) {
 	// Get user ID from the refresh token
 	userID, err := s.authRepo.GetUserIDByRefreshToken(ctx, refreshToken) // userID is now uuid.UUID
-	if err != nil { // This catches actual errors from Redis communication, parsing, etc.
-		return nil, fmt.Errorf("failed to get user ID from refresh token: %w", err)
+	if err != nil {                                                      // This catches actual errors from Redis communication, parsing, etc.
+		return nil, storeError("failed to get user ID from refresh token", err)
 	}
 	if userID == uuid.Nil { // This indicates the token was not found in Redis (repo returned (uuid.Nil, nil))
 		return nil, ErrInvalidOrExpiredToken
@@ -110,23 +517,20 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*domai
 		}
 		return nil, fmt.Errorf("failed to get user by ID for refresh token: %w", err)
 	}
+	if err := checkAccount(user); err != nil {
+		return nil, err
+	}
 
 	// Generate new JWT access token
-	expiresAt := time.Now().Add(time.Minute * time.Duration(s.config.JWT.AccessTokenExpireMinutes))
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"exp":     expiresAt.Unix(),
-		"iat":     time.Now().Unix(),
-	})
-
-	newAccessToken, err := claims.SignedString([]byte(s.config.JWT.Secret))
+	newAccessToken, accessTokenExpiresAt, err := s.generateAccessToken(user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign new access token: %w", err)
 	}
 
 	// Generate new refresh token
-	newRefreshToken := uuid.New().String()
-	refreshTokenExpiry := time.Duration(s.config.JWT.RefreshTokenExpireDays) * 24 * time.Hour
+	sessionType := sessionTypeOf(refreshToken)
+	newRefreshToken := generateRefreshToken(sessionType)
+	refreshTokenExpiry := s.config.JWT.RefreshTokenExpiry(sessionType == domainAuth.SessionRememberMe)
 
 	// Store new refresh token
 	err = s.authRepo.SetUserRefreshToken(ctx, userID, newRefreshToken, refreshTokenExpiry) // userID is uuid.UUID
@@ -142,29 +546,72 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*domai
 	err = s.authRepo.DeleteRefreshTokenUserID(ctx, refreshToken)
 	if err != nil {
 		// Log this error but don't fail the whole operation, as the new token is already set
-		fmt.Printf("failed to delete old refresh token to user ID mapping: %v\n", err)
+		s.warn(opDeleteOldRefreshToken, "Failed to delete old refresh token to user ID mapping", err, zap.String("user_id", userID.String()))
 	}
+	s.rotateSession(ctx, userID, refreshToken, newRefreshToken, refreshTokenExpiry)
 
 	// Return new token pair
 	return &domainAuth.TokenPair{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
+		AccessToken:           newAccessToken,
+		RefreshToken:          newRefreshToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshTokenExpiresAt: time.Now().Add(refreshTokenExpiry),
 	}, nil
 }
 
-// Logout invalidates a user session
+// rotateSession moves the session of a replaced refresh token to the token
+// replacing it, so the session keeps naming the token that signs it out.
+// Failures only leave the session listed until it expires.
+func (s *Service) rotateSession(ctx context.Context, userID uuid.UUID, oldToken, newToken string, expiry time.Duration) {
+	if s.sessions == nil {
+		return
+	}
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		s.warn(opRotateSession, "Failed to list sessions to rotate", err, zap.String("user_id", userID.String()))
+		return
+	}
+	for _, session := range sessions {
+		if session.RefreshToken != oldToken {
+			continue
+		}
+		session.RefreshToken = newToken
+		session.ExpiresAt = time.Now().Add(expiry)
+		if err := s.sessions.SaveSession(ctx, session); err != nil {
+			s.warn(opRotateSession, "Failed to rotate session", err, zap.String("user_id", userID.String()))
+		}
+		return
+	}
+}
+
+// Logout invalidates every session of a user. The refresh tokens of all
+// listed sessions stop working, not only the one issued last.
 func (s *Service) Logout(ctx context.Context, userID uuid.UUID) error { // userID is uuid.UUID
 	// Get current refresh token for the user
 	refreshToken, err := s.authRepo.GetUserRefreshToken(ctx, userID)
 	if err != nil && err != redis.Nil {
-		return fmt.Errorf("failed to get refresh token during logout: %w", err)
+		return storeError("failed to get refresh token during logout", err)
+	}
+
+	tokens := []string{refreshToken}
+	if s.sessions != nil {
+		sessions, err := s.sessions.ListSessions(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to list sessions during logout: %w", err)
+		}
+		for _, session := range sessions {
+			tokens = append(tokens, session.RefreshToken)
+		}
 	}
 
 	// Delete refresh token mappings
-	if refreshToken != "" {
-		err = s.authRepo.DeleteRefreshTokenUserID(ctx, refreshToken)
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		err = s.authRepo.DeleteRefreshTokenUserID(ctx, token)
 		if err != nil {
-			fmt.Printf("failed to delete refresh token mapping during logout: %v\n", err)
+			s.warn(opDeleteRefreshToken, "Failed to delete refresh token mapping during logout", err, zap.String("user_id", userID.String()))
 		}
 	}
 
@@ -174,77 +621,306 @@ func (s *Service) Logout(ctx context.Context, userID uuid.UUID) error { // userI
 		return fmt.Errorf("failed to delete user refresh token during logout: %w", err)
 	}
 
+	if s.sessions != nil {
+		if err := s.sessions.DeleteSessions(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete sessions during logout: %w", err)
+		}
+	}
+
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeSessionRevoked, UserID: userID})
 	return nil
 }
 
-// ValidateToken validates a JWT token and returns the user ID if valid
-func (s *Service) ValidateToken(ctx context.Context, tokenString string) (uuid.UUID, error) { // Return uuid.UUID
-	// Parse the token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// LogoutByRefreshToken signs out the user a refresh token belongs to.
+// Unknown or expired tokens are ignored so that signing out twice succeeds.
+// A token replaced since by a newer sign-in only loses its own mapping; it
+// cannot end the session that replaced it.
+func (s *Service) LogoutByRefreshToken(ctx context.Context, refreshToken string) error {
+	userID, err := s.authRepo.GetUserIDByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return storeError("failed to get user ID from refresh token during logout", err)
+	}
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	current, err := s.authRepo.GetUserRefreshToken(ctx, userID)
+	if err != nil {
+		return storeError("failed to get refresh token during logout", err)
+	}
+	if current != refreshToken {
+		if err := s.authRepo.DeleteRefreshTokenUserID(ctx, refreshToken); err != nil {
+			return fmt.Errorf("failed to delete replaced refresh token during logout: %w", err)
 		}
+		return nil
+	}
+	return s.Logout(ctx, userID)
+}
 
-		// Return the secret key used for signing
-		return []byte(s.config.JWT.Secret), nil
-	})
+// ValidateToken validates a JWT token and returns the user ID if valid.
+// Impersonation tokens are rejected since the caller could not tell the
+// administrator acting from the user.
+func (s *Service) ValidateToken(ctx context.Context, tokenString string) (uuid.UUID, error) {
+	principal, err := s.validate(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if principal.Impersonated() {
+		return uuid.Nil, ErrImpersonationNotAccepted
+	}
+	return principal.UserID, nil
+}
+
+// validate verifies the signature and lifetime of an access token and
+// returns the principal it authenticates
+func (s *Service) validate(tokenString string) (domainAuth.Principal, error) {
+	key := sha256.Sum256([]byte(tokenString))
+	if userID, ok := s.tokens.Get(key); ok {
+		return domainAuth.Principal{UserID: userID}, nil
+	}
+
+	claims := &AccessClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Tokens issued before key rotation was introduced carry no kid;
+		// they were signed with the legacy secret, which keeps the default ID.
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = defaultKeyID
+		}
+		// Return the key that verifies tokens signed with kid
+		return s.keys.Lookup(kid)
+	}, jwt.WithValidMethods([]string{s.keys.Method().Alg()}))
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return domainAuth.Principal{}, ErrTokenExpired
+		case errors.Is(err, jwt.ErrTokenNotValidYet):
+			return domainAuth.Principal{}, ErrTokenNotYetValid
+		case errors.Is(err, jwt.ErrTokenMalformed):
+			return domainAuth.Principal{}, ErrTokenMalformed
+		default:
+			// Invalid signature, unexpected signing method and other validation failures
+			return domainAuth.Principal{}, ErrInvalidToken
+		}
+	}
+
+	if !token.Valid {
+		return domainAuth.Principal{}, ErrInvalidToken
+	}
+
+	parsedUserID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return domainAuth.Principal{}, ErrInvalidToken // user_id claim missing or not a valid UUID
+	}
+	principal := domainAuth.Principal{UserID: parsedUserID}
+
+	if claims.Actor != nil {
+		// Not cached: the cache only keeps the user, and revoking the
+		// impersonation must take effect at once
+		actorID, actorErr := uuid.Parse(claims.Actor.UserID)
+		impersonationID, idErr := uuid.Parse(claims.ID)
+		if actorErr != nil || idErr != nil {
+			return domainAuth.Principal{}, ErrInvalidToken
+		}
+		principal.ActorID = actorID
+		principal.ImpersonationID = impersonationID
+		return principal, nil
+	}
+
+	if claims.ExpiresAt != nil {
+		s.tokens.SetWithTTL(key, parsedUserID, time.Until(claims.ExpiresAt.Time))
+	}
+	return principal, nil
+}
 
+// Authenticate validates an access token and rejects it when its user has
+// been deactivated or flagged for a password reset since it was issued
+func (s *Service) Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	userID, err := s.ValidateToken(ctx, accessToken)
 	if err != nil {
-		// Check for specific JWT errors that indicate an invalid token
-		// WORKAROUND: Due to issues resolving jwt.ValidationError constants in the build environment,
-		// we are checking error strings. This is fragile and should be replaced with constant checks
-		// if the environment/dependency issue is resolved.
-		// Common error messages from dgrijalva/jwt-go:
-		// "token is expired", "token is not valid yet", "token is malformed", "signature is invalid"
+		return uuid.Nil, err
+	}
 
-		if strings.Contains(err.Error(), "token is malformed") {
-			return uuid.Nil, ErrInvalidToken
+	if err := s.checkUser(ctx, userID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// AuthenticatePrincipal is Authenticate for the HTTP API, which also accepts
+// impersonation tokens and so reports who is acting. Impersonation tokens
+// are rejected once revoked or expired, or once their administrator is no
+// longer an active administrator.
+func (s *Service) AuthenticatePrincipal(ctx context.Context, accessToken string) (domainAuth.Principal, error) {
+	principal, err := s.validate(accessToken)
+	if err != nil {
+		return domainAuth.Principal{}, err
+	}
+	if principal.Impersonated() {
+		if err := s.checkImpersonation(ctx, principal); err != nil {
+			return domainAuth.Principal{}, err
 		}
-		if strings.Contains(err.Error(), "token is expired") {
-			return uuid.Nil, ErrInvalidToken // Or a more specific "expired token" error
+	}
+	if err := s.checkUser(ctx, principal.UserID); err != nil {
+		return domainAuth.Principal{}, err
+	}
+	return principal, nil
+}
+
+// checkUser rejects the access tokens of deleted, deactivated and
+// reset-pending accounts
+func (s *Service) checkUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userService.ErrUserNotFound) {
+			// The account was deleted after the token was issued
+			return ErrInvalidToken
 		}
-		if strings.Contains(err.Error(), "token is not valid yet") {
-			return uuid.Nil, ErrInvalidToken // Or a more specific "token not yet valid" error
+		return fmt.Errorf("failed to get user for access token: %w", err)
+	}
+	return checkAccount(user)
+}
+
+// checkImpersonation reports whether the impersonation behind principal
+// still lets its administrator act as the user
+func (s *Service) checkImpersonation(ctx context.Context, principal domainAuth.Principal) error {
+	if s.impersonations == nil {
+		return ErrImpersonationEnded
+	}
+	impersonation, err := s.impersonations.GetByID(ctx, principal.ImpersonationID)
+	if err != nil {
+		return fmt.Errorf("failed to get impersonation: %w", err)
+	}
+	if impersonation == nil || !impersonation.IsActive(time.Now()) ||
+		impersonation.ActorID != principal.ActorID || impersonation.SubjectID != principal.UserID {
+		return ErrImpersonationEnded
+	}
+
+	actor, err := s.userService.GetByID(ctx, principal.ActorID)
+	if err != nil {
+		if errors.Is(err, userService.ErrUserNotFound) {
+			return ErrImpersonationEnded
 		}
-		if strings.Contains(err.Error(), "signature is invalid") {
-			return uuid.Nil, ErrInvalidToken
+		return fmt.Errorf("failed to get impersonating administrator: %w", err)
+	}
+	if actor.Role != rbac.RoleAdmin || checkAccount(actor) != nil {
+		return ErrImpersonationEnded
+	}
+	return nil
+}
+
+// Impersonate records an impersonation of subjectID by actorID and signs
+// its access token, valid for jwt.impersonation_token_expire_minutes. The
+// token comes without a refresh token, so the impersonation cannot outlive it.
+func (s *Service) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	if s.impersonations == nil {
+		return nil, errors.New("impersonation is not enabled")
+	}
+	if err := s.checkUser(ctx, subjectID); err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, userService.ErrUserNotFound
 		}
-		// The following block is removed due to persistent 'undefined: jwt.ValidationError'
-		// var jwtErr *jwt.ValidationError
-		// if errors.As(err, &jwtErr) {
-		// // If it's a ValidationError, but not caught by specific string checks above,
-		// // treat as generic invalid token. The constants are problematic in this env.
-		// return uuid.Nil, ErrInvalidToken
-		// }
-		// If none of the specific string checks caught the error, it might be another type of JWT error or a non-JWT error.
-		// We'll rely on the fact that if token.Valid is false later, it will be caught.
-		// For errors during parsing not caught by string checks, we'll return a generic parse error.
-		// This makes the string checks the primary filter for known JWT issue types.
-		return uuid.Nil, fmt.Errorf("failed to parse token (unhandled type or non-JWT error): %w", err)
+		return nil, err
 	}
 
-	// Validate the token
-	if !token.Valid {
-		return uuid.Nil, ErrInvalidToken
+	now := time.Now()
+	impersonation := &domainAuth.Impersonation{
+		ID:        uuid.New(),
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		Reason:    reason,
+		ExpiresAt: now.Add(s.config.JWT.ImpersonationTokenExpiry()).Truncate(time.Second), // As the exp claim holds it
+		CreatedAt: now,
+	}
+	accessToken, err := s.sign(AccessClaims{
+		UserID: subjectID.String(),
+		Actor:  &ActorClaims{UserID: actorID.String()},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        impersonation.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(impersonation.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign impersonation token: %w", err)
+	}
+
+	if err := s.impersonations.Create(ctx, impersonation); err != nil {
+		return nil, fmt.Errorf("failed to record impersonation: %w", err)
+	}
+	return &domainAuth.ImpersonationToken{Impersonation: impersonation, AccessToken: accessToken}, nil
+}
+
+// RevokeImpersonation ends an impersonation before its token expires.
+// Revoking an impersonation twice keeps the time it was first revoked.
+func (s *Service) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	if s.impersonations == nil {
+		return nil, ErrImpersonationNotFound
+	}
+	impersonation, err := s.impersonations.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation: %w", err)
+	}
+	if impersonation == nil {
+		return nil, ErrImpersonationNotFound
+	}
+	if impersonation.RevokedAt != nil {
+		return impersonation, nil
 	}
 
-	// Extract claims
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return uuid.Nil, ErrInvalidToken // Invalid claims structure
+	now := time.Now()
+	if err := s.impersonations.Revoke(ctx, id, now); err != nil {
+		return nil, fmt.Errorf("failed to revoke impersonation: %w", err)
 	}
+	impersonation.RevokedAt = &now
+	return impersonation, nil
+}
+
+// storeError wraps an auth repository error, reporting an unreachable store
+// as ErrAuthStoreUnavailable so clients are told to retry rather than given
+// an internal error
+func storeError(msg string, err error) error {
+	if errors.Is(err, domainAuth.ErrStoreUnavailable) {
+		return apperror.Wrap(apperror.CodeServiceUnavailable, ErrAuthStoreUnavailable.Message, fmt.Errorf("%s: %w", msg, err))
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
 
-	// Extract user ID from claims
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return uuid.Nil, ErrInvalidToken // user_id claim missing or not a string
+// checkAccount reports whether user may be issued or use tokens
+func checkAccount(user *domainUser.User) error {
+	if !user.IsActive {
+		return ErrAccountDisabled
 	}
+	if user.PasswordResetRequired {
+		return ErrPasswordResetRequired
+	}
+	return nil
+}
 
-	parsedUserID, err := uuid.Parse(userIDStr)
+// generateAccessToken creates a signed JWT access token for the given user
+// and returns it along with its expiry
+func (s *Service) generateAccessToken(userID uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.JWT.AccessTokenExpiry()).Truncate(time.Second) // As the exp claim holds it
+	signed, err := s.sign(AccessClaims{
+		UserID: userID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
 	if err != nil {
-		return uuid.Nil, ErrInvalidToken // user_id claim is not a valid UUID
+		return "", time.Time{}, err
 	}
+	return signed, expiresAt, nil
+}
+
+// sign signs access token claims with the current key
+func (s *Service) sign(claims AccessClaims) (string, error) {
+	token := jwt.NewWithClaims(s.keys.Method(), claims)
+
+	kid, signKey := s.keys.Current()
+	token.Header["kid"] = kid
 
-	return parsedUserID, nil
+	return token.SignedString(signKey)
 }
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// `go test` replays only the seed corpus; `make fuzz` explores beyond it
+
+// tokenErrors are the only errors ValidateToken may return for hostile input
+var tokenErrors = map[apperror.Code]bool{
+	apperror.CodeInvalidToken:     true,
+	apperror.CodeTokenExpired:     true,
+	apperror.CodeTokenNotYetValid: true,
+	apperror.CodeTokenMalformed:   true,
+}
+
+func signTestClaims(t testing.TB, method jwt.SigningMethod, claims jwt.Claims, header map[string]interface{}) string {
+	token := jwt.NewWithClaims(method, claims)
+	for k, v := range header {
+		token.Header[k] = v
+	}
+	var key interface{} = []byte(testConfig.JWT.Secret)
+	if method == jwt.SigningMethodNone {
+		key = jwt.UnsafeAllowNoneSignatureType
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func FuzzValidateToken(f *testing.F) {
+	now := time.Now()
+	userID := uuid.New()
+	valid := jwt.MapClaims{"user_id": userID.String(), "exp": now.Add(time.Hour).Unix()}
+
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, valid, nil))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, valid, map[string]interface{}{"kid": "unknown"}))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, valid, map[string]interface{}{"kid": 42}))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS512, valid, nil))
+	f.Add(signTestClaims(f, jwt.SigningMethodNone, valid, nil))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, jwt.MapClaims{"user_id": userID.String(), "exp": now.Add(-time.Hour).Unix()}, nil))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, jwt.MapClaims{"user_id": []string{"a"}, "exp": "soon"}, nil))
+	f.Add(signTestClaims(f, jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "not-a-uuid"}, nil))
+	f.Add("")
+	f.Add("..")
+	f.Add("a.b.c")
+	f.Add("Bearer " + strings.Repeat("A", 64))
+	f.Add("eyJhbGciOiJIUzI1NiJ9.eyJ1c2VyX2lkIjpudWxsfQ.")
+
+	authService, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, nil, zap.NewNop())
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, tokenString string) {
+		id, err := authService.ValidateToken(context.Background(), tokenString)
+		if err != nil {
+			appErr, ok := apperror.As(err)
+			require.True(t, ok, "unexpected error type: %v", err)
+			require.True(t, tokenErrors[appErr.Code], "unexpected error code %s", appErr.Code)
+			require.Equal(t, uuid.Nil, id)
+			return
+		}
+		require.NotEqual(t, uuid.Nil, id)
+	})
+}
+
+func FuzzAccessTokenRoundTrip(f *testing.F) {
+	f.Add(uuid.New().String())
+	f.Add("00000000-0000-0000-0000-000000000000")
+
+	authService, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, nil, zap.NewNop())
+	require.NoError(f, err)
+	s := authService.(*Service)
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			t.Skip()
+		}
+
+		token, _, err := s.generateAccessToken(userID)
+		require.NoError(t, err)
+
+		parsed, err := s.ValidateToken(context.Background(), token)
+		require.NoError(t, err)
+		require.Equal(t, userID, parsed)
+	})
+}
+
+func FuzzRefreshToken(f *testing.F) {
+	f.Add("")
+	f.Add(uuid.New().String())
+	f.Add("refresh_token:*")
+	f.Add("\x00\xff\n")
+	f.Add(strings.Repeat("x", 4096))
+
+	f.Fuzz(func(t *testing.T, refreshToken string) {
+		mockAuthRepo := new(MockAuthRepository)
+		// Unknown tokens must be rejected before the user is loaded or anything is written
+		mockAuthRepo.On("GetUserIDByRefreshToken", mock.Anything, refreshToken).Return(uuid.Nil, nil).Once()
+		authService, err := NewService(new(MockUserService), mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+		require.NoError(t, err)
+
+		pair, err := authService.RefreshToken(context.Background(), refreshToken)
+
+		require.ErrorIs(t, err, ErrInvalidOrExpiredToken)
+		require.Nil(t, pair)
+		mockAuthRepo.AssertExpectations(t)
+	})
+}
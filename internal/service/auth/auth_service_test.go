@@ -2,21 +2,37 @@ package auth
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
-	// "fmt" // Removed as unused
+	"fmt"
 	"testing"
 	"time"
 
-	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/cache"
 	"github.com/yi-tech/go-user-service/internal/config"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/password"
+	repoAuth "github.com/yi-tech/go-user-service/internal/repository/auth"
 	userService "github.com/yi-tech/go-user-service/internal/service/user" // For userService.ErrUserNotFound
+	"github.com/yi-tech/go-user-service/internal/useragent"
 )
 
 var _ domainAuth.TokenPair // Explicitly use domainAuth.TokenPair to satisfy import checker
@@ -44,6 +60,14 @@ func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUse
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
 func (m *MockUserService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -65,6 +89,11 @@ func (m *MockUserService) UpdatePassword(ctx context.Context, id uuid.UUID, curr
 	return args.Error(0)
 }
 
+func (m *MockUserService) RehashPassword(ctx context.Context, user *domainUser.User, password string) error {
+	args := m.Called(ctx, user, password)
+	return args.Error(0)
+}
+
 func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -105,25 +134,32 @@ func (m *MockAuthRepository) DeleteRefreshTokenUserID(ctx context.Context, token
 	return args.Error(0)
 }
 
+func (m *MockAuthRepository) PruneOrphanedRefreshTokens(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // --- Test Setup ---
 
 var testConfig = &config.Config{
 	JWT: config.JWTConfig{
 		Secret:                   "test-secret",
-		AccessTokenExpireMinutes: 1,  // Short expiry for testing
-		RefreshTokenExpireDays:   1,  // Short expiry for testing
+		AccessTokenExpireMinutes: 1, // Short expiry for testing
+		RefreshTokenExpireDays:   1, // Short expiry for testing
 	},
 }
 
 // Helper to create a new user for testing
-func newAuthTestUser(email, password string) *domainUser.User {
+func newAuthTestUser(email, plain string) *domainUser.User {
 	user := &domainUser.User{
 		ID:       uuid.New(),
 		Email:    email,
-		Password: password, // Raw password
+		Password: plain, // Raw password
+		IsActive: true,
 	}
 	// Simulate hashing that would happen during actual user creation/update
-	_ = user.HashPassword()
+	hasher, _ := password.NewBcryptHasher(bcrypt.MinCost)
+	_ = user.HashPassword(hasher)
 	return user
 }
 
@@ -132,12 +168,14 @@ func newAuthTestUser(email, password string) *domainUser.User {
 func TestLogin(t *testing.T) {
 	mockUserSvc := new(MockUserService)
 	mockAuthRepo := new(MockAuthRepository)
-	authService := NewService(mockUserSvc, mockAuthRepo, testConfig)
+	authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
 	ctx := context.Background()
 
 	email := "test@example.com"
 	correctPassword := "password123"
 	user := newAuthTestUser(email, correctPassword) // Password will be hashed inside
+	mockUserSvc.On("RehashPassword", ctx, user, correctPassword).Return(nil).Maybe()
 
 	t.Run("Success", func(t *testing.T) {
 		mockUserSvc.On("GetByEmail", ctx, email).Return(user, nil).Once()
@@ -193,6 +231,49 @@ func TestLogin(t *testing.T) {
 		mockUserSvc.AssertExpectations(t)
 	})
 
+	t.Run("Inactive User", func(t *testing.T) {
+		inactive := newAuthTestUser(email, correctPassword)
+		inactive.IsActive = false
+		mockUserSvc.On("GetByEmail", ctx, email).Return(inactive, nil).Once()
+
+		loginInput := domainAuth.LoginInput{Email: email, Password: correctPassword}
+		tokenPair, err := authService.Login(ctx, loginInput)
+
+		assert.Nil(t, tokenPair)
+		assert.True(t, errors.Is(err, ErrAccountDisabled))
+		mockUserSvc.AssertExpectations(t)
+		mockAuthRepo.AssertNotCalled(t, "SetUserRefreshToken", ctx, inactive.ID, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Password Reset Required", func(t *testing.T) {
+		flagged := newAuthTestUser(email, correctPassword)
+		flagged.PasswordResetRequired = true
+		mockUserSvc.On("GetByEmail", ctx, email).Return(flagged, nil).Once()
+
+		loginInput := domainAuth.LoginInput{Email: email, Password: correctPassword}
+		tokenPair, err := authService.Login(ctx, loginInput)
+
+		assert.Nil(t, tokenPair)
+		assert.True(t, errors.Is(err, ErrPasswordResetRequired))
+		mockUserSvc.AssertExpectations(t)
+		mockAuthRepo.AssertNotCalled(t, "SetUserRefreshToken", ctx, flagged.ID, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Password Expired", func(t *testing.T) {
+		expired := newAuthTestUser(email, correctPassword)
+		expiresAt := time.Now().Add(-time.Minute)
+		expired.PasswordExpiresAt = &expiresAt
+		mockUserSvc.On("GetByEmail", ctx, email).Return(expired, nil).Once()
+
+		loginInput := domainAuth.LoginInput{Email: email, Password: correctPassword}
+		tokenPair, err := authService.Login(ctx, loginInput)
+
+		assert.Nil(t, tokenPair)
+		assert.True(t, errors.Is(err, ErrPasswordExpired))
+		mockUserSvc.AssertExpectations(t)
+		mockAuthRepo.AssertNotCalled(t, "SetUserRefreshToken", ctx, expired.ID, mock.Anything, mock.Anything)
+	})
+
 	t.Run("Error from SetUserRefreshToken", func(t *testing.T) {
 		repoError := errors.New("repo error SetUserRefreshToken")
 		mockUserSvc.On("GetByEmail", ctx, email).Return(user, nil).Once()
@@ -226,11 +307,448 @@ func TestLogin(t *testing.T) {
 	})
 }
 
+func TestLogin_RehashFailureDoesNotBlockSignIn(t *testing.T) {
+	mockUserSvc := new(MockUserService)
+	mockAuthRepo := new(MockAuthRepository)
+	authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	user := newAuthTestUser("test@example.com", "password123")
+
+	mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+	mockUserSvc.On("RehashPassword", ctx, user, "password123").Return(errors.New("database is read-only")).Once()
+	mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+	mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), user.ID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+	tokenPair, err := authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokenPair.AccessToken)
+	mockUserSvc.AssertExpectations(t)
+}
+
+func TestLogin_RememberMe(t *testing.T) {
+	cfg := *testConfig
+	cfg.JWT.RememberMeRefreshTokenExpireDays = 30
+
+	tests := []struct {
+		name           string
+		rememberMe     bool
+		expectedExpiry time.Duration
+	}{
+		{name: "Standard", rememberMe: false, expectedExpiry: 24 * time.Hour},
+		{name: "Remember Me", rememberMe: true, expectedExpiry: 30 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserSvc := new(MockUserService)
+			mockAuthRepo := new(MockAuthRepository)
+			authService, err := NewService(mockUserSvc, mockAuthRepo, nil, &cfg, nil, zap.NewNop())
+			require.NoError(t, err)
+			ctx := context.Background()
+			user := newAuthTestUser("test@example.com", "password123")
+
+			mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+			mockUserSvc.On("RehashPassword", ctx, user, "password123").Return(nil).Once()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), tt.expectedExpiry).Return(nil).Once()
+			mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), user.ID, tt.expectedExpiry).Return(nil).Once()
+
+			before := time.Now()
+			tokenPair, err := authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123", RememberMe: tt.rememberMe})
+
+			require.NoError(t, err)
+			assert.WithinDuration(t, before.Add(time.Minute), tokenPair.AccessTokenExpiresAt, time.Second)
+			assert.WithinDuration(t, before.Add(tt.expectedExpiry), tokenPair.RefreshTokenExpiresAt, time.Second)
+			mockAuthRepo.AssertExpectations(t)
+
+			// Rotating the refresh token keeps the lifetime of the session
+			mockAuthRepo.On("GetUserIDByRefreshToken", ctx, tokenPair.RefreshToken).Return(user.ID, nil).Once()
+			mockUserSvc.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), tt.expectedExpiry).Return(nil).Once()
+			mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), user.ID, tt.expectedExpiry).Return(nil).Once()
+			mockAuthRepo.On("DeleteRefreshTokenUserID", ctx, tokenPair.RefreshToken).Return(nil).Once()
+
+			refreshed, err := authService.RefreshToken(ctx, tokenPair.RefreshToken)
+
+			require.NoError(t, err)
+			assert.Equal(t, sessionTypeOf(tokenPair.RefreshToken), sessionTypeOf(refreshed.RefreshToken))
+			assert.WithinDuration(t, time.Now().Add(tt.expectedExpiry), refreshed.RefreshTokenExpiresAt, time.Second)
+			mockAuthRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogin_AuthStoreUnavailable(t *testing.T) {
+	ctx := context.Background()
+	user := newAuthTestUser("test@example.com", "password123")
+	unavailable := fmt.Errorf("failed to set refresh token in redis: %w", domainAuth.ErrStoreUnavailable)
+
+	tests := []struct {
+		name     string
+		fallback bool
+	}{
+		{name: "Fails Without Fallback"},
+		{name: "Issues Access Token Only With Fallback", fallback: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := *testConfig
+			cfg.Redis.Failover.StatelessFallback = tc.fallback
+			mockUserSvc := new(MockUserService)
+			mockAuthRepo := new(MockAuthRepository)
+			authService, err := NewService(mockUserSvc, mockAuthRepo, nil, &cfg, nil, zap.NewNop())
+			require.NoError(t, err)
+
+			mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+			mockUserSvc.On("RehashPassword", ctx, user, "password123").Return(nil).Once()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(unavailable).Once()
+
+			tokenPair, err := authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123"})
+
+			if !tc.fallback {
+				assert.ErrorIs(t, err, ErrAuthStoreUnavailable)
+				assert.Nil(t, tokenPair)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokenPair.AccessToken)
+			assert.False(t, tokenPair.AccessTokenExpiresAt.IsZero())
+			assert.Empty(t, tokenPair.RefreshToken)
+			assert.True(t, tokenPair.RefreshTokenExpiresAt.IsZero())
+			mockAuthRepo.AssertNotCalled(t, "SetRefreshTokenUserID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+			// The access token keeps working without Redis
+			userID, err := authService.ValidateToken(ctx, tokenPair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, userID)
+		})
+	}
+}
+
+// fakeLoginHistory is an in-memory domainAuth.LoginHistoryRepository
+type fakeLoginHistory struct {
+	records []*domainAuth.LoginRecord
+	cutoff  time.Time
+}
+
+func (f *fakeLoginHistory) Create(_ context.Context, record *domainAuth.LoginRecord) error {
+	f.records = append(f.records, record)
+	return nil
+}
+
+func (f *fakeLoginHistory) List(_ context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	return f.records, int64(len(f.records)), nil
+}
+
+func (f *fakeLoginHistory) Prune(_ context.Context, cutoff time.Time) (int64, error) {
+	f.cutoff = cutoff
+	return 2, nil
+}
+
+func TestLogin_RecordsLoginHistory(t *testing.T) {
+	ctx := context.Background()
+	input := domainAuth.LoginInput{Email: "test@example.com", Password: "password123", UserAgent: "curl/8.0", ClientIP: "203.0.113.7"}
+
+	tests := []struct {
+		name     string
+		password string
+		setup    func(user *domainUser.User)
+		expected []domainAuth.LoginResult
+	}{
+		{name: "Success", password: "password123", expected: []domainAuth.LoginResult{domainAuth.LoginSucceeded}},
+		{name: "Wrong Password", password: "wrong", expected: []domainAuth.LoginResult{domainAuth.LoginInvalidPassword}},
+		{name: "Disabled Account", password: "password123", setup: func(u *domainUser.User) { u.IsActive = false }, expected: []domainAuth.LoginResult{domainAuth.LoginAccountDisabled}},
+		{name: "Password Reset Required", password: "password123", setup: func(u *domainUser.User) { u.PasswordResetRequired = true }, expected: []domainAuth.LoginResult{domainAuth.LoginPasswordResetRequired}},
+		{name: "Password Expired", password: "password123", setup: func(u *domainUser.User) { u.PasswordChangedAt = time.Now().AddDate(-1, 0, 0) }, expected: []domainAuth.LoginResult{domainAuth.LoginPasswordExpired}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := newAuthTestUser(input.Email, "password123")
+			if tc.setup != nil {
+				tc.setup(user)
+			}
+			history := &fakeLoginHistory{}
+			mockUserSvc := new(MockUserService)
+			mockAuthRepo := new(MockAuthRepository)
+			cfg := *testConfig
+			cfg.Password.MaxAgeDays = 90
+			authService, err := NewService(mockUserSvc, mockAuthRepo, nil, &cfg, nil, zap.NewNop(), WithLoginHistory(history))
+			require.NoError(t, err)
+
+			mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+			mockUserSvc.On("RehashPassword", ctx, user, mock.Anything).Return(nil).Maybe()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.Anything, user.ID, mock.Anything).Return(nil).Maybe()
+
+			attempt := input
+			attempt.Password = tc.password
+			_, _ = authService.Login(ctx, attempt)
+
+			require.Len(t, history.records, len(tc.expected))
+			for i, record := range history.records {
+				assert.Equal(t, tc.expected[i], record.Result)
+				assert.Equal(t, user.ID, record.UserID)
+				assert.Equal(t, "curl/8.0", record.UserAgent)
+				assert.Equal(t, "203.0.113.7", record.ClientIP)
+				assert.NotEqual(t, uuid.Nil, record.ID)
+			}
+		})
+	}
+
+	t.Run("Unknown Email Is Not Recorded", func(t *testing.T) {
+		history := &fakeLoginHistory{}
+		mockUserSvc := new(MockUserService)
+		authService, err := NewService(mockUserSvc, new(MockAuthRepository), nil, testConfig, nil, zap.NewNop(), WithLoginHistory(history))
+		require.NoError(t, err)
+		mockUserSvc.On("GetByEmail", ctx, input.Email).Return(nil, userService.ErrUserNotFound).Once()
+
+		_, err = authService.Login(ctx, input)
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		assert.Empty(t, history.records)
+	})
+}
+
+func TestLogin_PasswordMaxAge(t *testing.T) {
+	ctx := context.Background()
+	email := "test@example.com"
+	correctPassword := "password123"
+	cfg := *testConfig
+	cfg.Password.MaxAgeDays = 90
+
+	tests := []struct {
+		name      string
+		changedAt time.Time
+		expired   bool
+	}{
+		{name: "Older Than Max Age", changedAt: time.Now().AddDate(0, 0, -91), expired: true},
+		{name: "Within Max Age", changedAt: time.Now().AddDate(0, 0, -89)},
+		{name: "Never Recorded", changedAt: time.Time{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUserSvc := new(MockUserService)
+			mockAuthRepo := new(MockAuthRepository)
+			authService, err := NewService(mockUserSvc, mockAuthRepo, nil, &cfg, nil, zap.NewNop())
+			require.NoError(t, err)
+
+			user := newAuthTestUser(email, correctPassword)
+			user.PasswordChangedAt = tc.changedAt
+			mockUserSvc.On("GetByEmail", ctx, email).Return(user, nil).Once()
+			mockUserSvc.On("RehashPassword", ctx, user, correctPassword).Return(nil).Maybe()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.Anything, mock.Anything).Return(nil).Maybe()
+			mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.Anything, user.ID, mock.Anything).Return(nil).Maybe()
+
+			tokenPair, err := authService.Login(ctx, domainAuth.LoginInput{Email: email, Password: correctPassword})
+
+			if tc.expired {
+				assert.Nil(t, tokenPair)
+				assert.True(t, errors.Is(err, ErrPasswordExpired))
+				mockAuthRepo.AssertNotCalled(t, "SetUserRefreshToken", ctx, user.ID, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, tokenPair)
+		})
+	}
+}
+
+// recordingSecurityLog keeps the security events it is given
+type recordingSecurityLog struct {
+	actions []domainAudit.Action
+	details []any
+}
+
+func (l *recordingSecurityLog) Record(_ context.Context, _ uuid.UUID, action domainAudit.Action, details any) {
+	l.actions = append(l.actions, action)
+	l.details = append(l.details, details)
+}
+
+// recordingNotifier keeps the kinds of the notifications it is asked to send
+type recordingNotifier struct {
+	kinds []domainNotification.Kind
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, kind domainNotification.Kind, _ domainNotification.Recipient) {
+	n.kinds = append(n.kinds, kind)
+}
+
+func TestLogin_NewDeviceAlerts(t *testing.T) {
+	ctx := context.Background()
+	chrome := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	firefox := "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:127.0) Gecko/20100101 Firefox/127.0"
+	known := func(result domainAuth.LoginResult, userAgent, clientIP string) *domainAuth.LoginRecord {
+		return &domainAuth.LoginRecord{ID: uuid.New(), Result: result, UserAgent: userAgent, ClientIP: clientIP}
+	}
+
+	tests := []struct {
+		name      string
+		history   []*domainAuth.LoginRecord
+		userAgent string
+		clientIP  string
+		alerted   bool
+	}{
+		{name: "First Sign In", userAgent: chrome, clientIP: "203.0.113.7"},
+		{name: "Known Device", history: []*domainAuth.LoginRecord{known(domainAuth.LoginSucceeded, chrome, "203.0.113.7")}, userAgent: chrome, clientIP: "203.0.113.7"},
+		{name: "New Device", history: []*domainAuth.LoginRecord{known(domainAuth.LoginSucceeded, chrome, "203.0.113.7")}, userAgent: firefox, clientIP: "203.0.113.7", alerted: true},
+		{name: "New IP Address", history: []*domainAuth.LoginRecord{known(domainAuth.LoginSucceeded, chrome, "203.0.113.7")}, userAgent: chrome, clientIP: "198.51.100.2", alerted: true},
+		{name: "Only Failed Before", history: []*domainAuth.LoginRecord{known(domainAuth.LoginSucceeded, chrome, "203.0.113.7"), known(domainAuth.LoginInvalidPassword, firefox, "198.51.100.2")}, userAgent: firefox, clientIP: "198.51.100.2", alerted: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := newAuthTestUser("test@example.com", "password123")
+			history := &fakeLoginHistory{records: tc.history}
+			security := &recordingSecurityLog{}
+			notifier := &recordingNotifier{}
+			mockUserSvc := new(MockUserService)
+			mockAuthRepo := new(MockAuthRepository)
+			authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop(), WithLoginHistory(history), WithNewDeviceAlerts(security, notifier))
+			require.NoError(t, err)
+
+			mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil).Once()
+			mockUserSvc.On("RehashPassword", ctx, user, mock.Anything).Return(nil).Maybe()
+			mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.Anything, mock.Anything).Return(nil).Once()
+			mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.Anything, user.ID, mock.Anything).Return(nil).Once()
+
+			_, err = authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123", UserAgent: tc.userAgent, ClientIP: tc.clientIP})
+
+			require.NoError(t, err)
+			if !tc.alerted {
+				assert.Empty(t, security.actions)
+				assert.Empty(t, notifier.kinds)
+				return
+			}
+			assert.Equal(t, []domainAudit.Action{domainAudit.ActionNewDeviceLogin}, security.actions)
+			assert.Equal(t, newDeviceDetails{Device: useragent.Label(tc.userAgent), UserAgent: tc.userAgent, ClientIP: tc.clientIP}, security.details[0])
+			assert.Equal(t, []domainNotification.Kind{domainNotification.KindNewDeviceLogin}, notifier.kinds)
+		})
+	}
+}
+
+// fakeLoginAttempts is an in-memory domainAuth.LoginAttemptRepository
+type fakeLoginAttempts struct {
+	ip      map[string]int64
+	account map[string]int64
+}
+
+func newFakeLoginAttempts() *fakeLoginAttempts {
+	return &fakeLoginAttempts{ip: map[string]int64{}, account: map[string]int64{}}
+}
+
+func (f *fakeLoginAttempts) CountFailures(_ context.Context, clientIP, email string) (int64, int64, error) {
+	return f.ip[clientIP], f.account[email], nil
+}
+
+func (f *fakeLoginAttempts) RecordFailure(_ context.Context, clientIP, email string, _ time.Duration) error {
+	if clientIP != "" {
+		f.ip[clientIP]++
+	}
+	f.account[email]++
+	return nil
+}
+
+func (f *fakeLoginAttempts) ResetAccountFailures(_ context.Context, email string) error {
+	delete(f.account, email)
+	return nil
+}
+
+// fakeCaptcha accepts a single token
+type fakeCaptcha struct{ valid string }
+
+func (f fakeCaptcha) Verify(_ context.Context, token, _ string) error {
+	if token != f.valid {
+		return errors.New("captcha rejected")
+	}
+	return nil
+}
+
+func TestLogin_CaptchaEscalation(t *testing.T) {
+	ctx := context.Background()
+	email := "test@example.com"
+	correctPassword := "password123"
+	user := newAuthTestUser(email, correctPassword)
+
+	newService := func(t *testing.T, attempts *fakeLoginAttempts) domainAuth.AuthService {
+		mockUserSvc := new(MockUserService)
+		mockAuthRepo := new(MockAuthRepository)
+		mockUserSvc.On("GetByEmail", ctx, email).Return(user, nil)
+		mockUserSvc.On("RehashPassword", ctx, user, correctPassword).Return(nil)
+		mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
+		mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), user.ID, mock.AnythingOfType("time.Duration")).Return(nil)
+		s, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop(),
+			WithCaptchaEscalation(attempts, fakeCaptcha{valid: "solved"}, 3, time.Minute))
+		require.NoError(t, err)
+		return s
+	}
+	wrong := domainAuth.LoginInput{Email: email, Password: "wrongPassword", ClientIP: "203.0.113.7"}
+
+	t.Run("Required After Failures From IP", func(t *testing.T) {
+		attempts := newFakeLoginAttempts()
+		s := newService(t, attempts)
+
+		for i := 0; i < 3; i++ {
+			_, err := s.Login(ctx, wrong)
+			require.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+		assert.Equal(t, int64(3), attempts.ip["203.0.113.7"])
+
+		// The threshold blocks correct passwords too until a CAPTCHA is solved
+		input := domainAuth.LoginInput{Email: email, Password: correctPassword, ClientIP: "203.0.113.7"}
+		_, err := s.Login(ctx, input)
+		assert.ErrorIs(t, err, ErrCaptchaRequired)
+
+		input.CaptchaToken = "wrong"
+		_, err = s.Login(ctx, input)
+		assert.EqualError(t, err, "captcha rejected")
+
+		input.CaptchaToken = "solved"
+		tokenPair, err := s.Login(ctx, input)
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokenPair.AccessToken)
+		assert.Zero(t, attempts.account[email])
+		assert.Equal(t, int64(3), attempts.ip["203.0.113.7"], "a successful sign-in keeps the IP count")
+	})
+
+	t.Run("Required After Failures Against Account", func(t *testing.T) {
+		attempts := newFakeLoginAttempts()
+		s := newService(t, attempts)
+
+		for _, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+			input := wrong
+			input.ClientIP = ip
+			_, err := s.Login(ctx, input)
+			require.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := s.Login(ctx, domainAuth.LoginInput{Email: email, Password: correctPassword, ClientIP: "198.51.100.4"})
+		assert.ErrorIs(t, err, ErrCaptchaRequired)
+	})
+
+	t.Run("Not Required Below Threshold", func(t *testing.T) {
+		attempts := newFakeLoginAttempts()
+		s := newService(t, attempts)
+
+		for i := 0; i < 2; i++ {
+			_, err := s.Login(ctx, wrong)
+			require.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := s.Login(ctx, domainAuth.LoginInput{Email: email, Password: correctPassword, ClientIP: "203.0.113.7"})
+		assert.NoError(t, err)
+	})
+}
+
 // --- RefreshToken Tests ---
 func TestRefreshToken(t *testing.T) {
 	mockUserSvc := new(MockUserService)
 	mockAuthRepo := new(MockAuthRepository)
-	authService := NewService(mockUserSvc, mockAuthRepo, testConfig)
+	authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
 	ctx := context.Background()
 
 	refreshToken := "valid-refresh-token"
@@ -257,6 +775,18 @@ func TestRefreshToken(t *testing.T) {
 		mockAuthRepo.AssertExpectations(t)
 	})
 
+	t.Run("Auth Store Unavailable", func(t *testing.T) {
+		unavailable := fmt.Errorf("failed to get user ID by refresh token from redis: %w", domainAuth.ErrStoreUnavailable)
+		mockAuthRepo.On("GetUserIDByRefreshToken", ctx, refreshToken).Return(uuid.Nil, unavailable).Once()
+
+		tokenPair, err := authService.RefreshToken(ctx, refreshToken)
+
+		assert.ErrorIs(t, err, ErrAuthStoreUnavailable)
+		assert.Equal(t, apperror.CodeServiceUnavailable, apperror.CodeOf(err))
+		assert.Nil(t, tokenPair)
+		mockAuthRepo.AssertExpectations(t)
+	})
+
 	t.Run("Token Not Found in Repo - GetUserIDByRefreshToken returns (uuid.Nil, nil)", func(t *testing.T) {
 		mockAuthRepo.On("GetUserIDByRefreshToken", ctx, refreshToken).Return(uuid.Nil, nil).Once()
 
@@ -311,7 +841,8 @@ func TestRefreshToken(t *testing.T) {
 func TestLogout(t *testing.T) {
 	mockUserSvc := new(MockUserService) // Not directly used by Logout, but part of service struct
 	mockAuthRepo := new(MockAuthRepository)
-	authService := NewService(mockUserSvc, mockAuthRepo, testConfig)
+	authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
 	ctx := context.Background()
 	userID := uuid.New()
 
@@ -356,6 +887,140 @@ func TestLogout(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to delete user refresh token during logout")
 		mockAuthRepo.AssertExpectations(t)
 	})
+
+	t.Run("Failed Mapping Deletion Is Logged And Counted", func(t *testing.T) {
+		metrics, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		core, logs := observer.New(zap.WarnLevel)
+		counting, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.New(core), WithMetrics(metrics))
+		require.NoError(t, err)
+		mockAuthRepo.On("GetUserRefreshToken", ctx, userID).Return("some-token", nil).Once()
+		mockAuthRepo.On("DeleteRefreshTokenUserID", ctx, "some-token").Return(errors.New("redis down")).Once()
+		mockAuthRepo.On("DeleteUserRefreshToken", ctx, userID).Return(nil).Once()
+
+		assert.NoError(t, counting.Logout(ctx, userID), "the sign-out goes ahead")
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.failures.WithLabelValues(opDeleteRefreshToken)))
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, opDeleteRefreshToken, fields["operation"])
+		assert.Equal(t, userID.String(), fields["user_id"])
+		assert.Equal(t, "redis down", fields["error"])
+		mockAuthRepo.AssertExpectations(t)
+	})
+
+	t.Run("Publishes Session Revoked", func(t *testing.T) {
+		events := &recordingPublisher{}
+		publishing, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop(), WithEventPublisher(events))
+		require.NoError(t, err)
+		mockAuthRepo.On("GetUserRefreshToken", ctx, userID).Return("", redis.Nil).Once()
+		mockAuthRepo.On("DeleteUserRefreshToken", ctx, userID).Return(errors.New("redis down")).Once()
+		mockAuthRepo.On("GetUserRefreshToken", ctx, userID).Return("", redis.Nil).Once()
+		mockAuthRepo.On("DeleteUserRefreshToken", ctx, userID).Return(nil).Once()
+
+		assert.Error(t, publishing.Logout(ctx, userID))
+		assert.Empty(t, events.events, "a failed sign-out publishes nothing")
+
+		assert.NoError(t, publishing.Logout(ctx, userID))
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeSessionRevoked, UserID: userID}}, events.events)
+		mockAuthRepo.AssertExpectations(t)
+	})
+}
+
+func TestLogout_EndsEveryDevice(t *testing.T) {
+	mockUserSvc := new(MockUserService)
+	sessions := repoAuth.NewMemorySessionRepository()
+	authService, err := NewService(mockUserSvc, repoAuth.NewMemoryAuthRepository(sessions), sessions, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+	user := newAuthTestUser("test@example.com", "password123")
+	mockUserSvc.On("GetByEmail", ctx, user.Email).Return(user, nil)
+	mockUserSvc.On("GetByID", ctx, user.ID).Return(user, nil)
+	mockUserSvc.On("RehashPassword", ctx, user, "password123").Return(nil).Maybe()
+
+	laptop, err := authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123", UserAgent: "laptop"})
+	require.NoError(t, err)
+	laptop, err = authService.RefreshToken(ctx, laptop.RefreshToken)
+	require.NoError(t, err)
+	// The phone signs in last, so its token is the current one
+	phone, err := authService.Login(ctx, domainAuth.LoginInput{Email: user.Email, Password: "password123", UserAgent: "phone"})
+	require.NoError(t, err)
+
+	require.NoError(t, authService.Logout(ctx, user.ID))
+
+	_, err = authService.RefreshToken(ctx, laptop.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidOrExpiredToken, "the older device is signed out too")
+	_, err = authService.RefreshToken(ctx, phone.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidOrExpiredToken)
+}
+
+// recordingPublisher keeps the published events
+type recordingPublisher struct {
+	events []domainEvent.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domainEvent.Event) {
+	p.events = append(p.events, e)
+}
+
+func TestLogoutByRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name        string
+		setupMock   func(mockAuthRepo *MockAuthRepository)
+		expectedErr error
+	}{
+		{
+			name: "Current Token Ends The Session",
+			setupMock: func(mockAuthRepo *MockAuthRepository) {
+				mockAuthRepo.On("GetUserIDByRefreshToken", ctx, "refresh-token").Return(userID, nil).Once()
+				mockAuthRepo.On("GetUserRefreshToken", ctx, userID).Return("refresh-token", nil).Twice()
+				mockAuthRepo.On("DeleteRefreshTokenUserID", ctx, "refresh-token").Return(nil).Once()
+				mockAuthRepo.On("DeleteUserRefreshToken", ctx, userID).Return(nil).Once()
+			},
+		},
+		{
+			name: "Replaced Token Only Loses Its Mapping",
+			setupMock: func(mockAuthRepo *MockAuthRepository) {
+				mockAuthRepo.On("GetUserIDByRefreshToken", ctx, "refresh-token").Return(userID, nil).Once()
+				mockAuthRepo.On("GetUserRefreshToken", ctx, userID).Return("newer-refresh-token", nil).Once()
+				mockAuthRepo.On("DeleteRefreshTokenUserID", ctx, "refresh-token").Return(nil).Once()
+			},
+		},
+		{
+			name: "Unknown Token Is Ignored",
+			setupMock: func(mockAuthRepo *MockAuthRepository) {
+				mockAuthRepo.On("GetUserIDByRefreshToken", ctx, "refresh-token").Return(uuid.Nil, nil).Once()
+			},
+		},
+		{
+			name: "Auth Store Unavailable",
+			setupMock: func(mockAuthRepo *MockAuthRepository) {
+				unavailable := fmt.Errorf("failed to get user ID by refresh token from redis: %w", domainAuth.ErrStoreUnavailable)
+				mockAuthRepo.On("GetUserIDByRefreshToken", ctx, "refresh-token").Return(uuid.Nil, unavailable).Once()
+			},
+			expectedErr: ErrAuthStoreUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthRepo := new(MockAuthRepository)
+			authService, err := NewService(new(MockUserService), mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+			require.NoError(t, err)
+			tt.setupMock(mockAuthRepo)
+
+			err = authService.LogoutByRefreshToken(ctx, "refresh-token")
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockAuthRepo.AssertExpectations(t)
+		})
+	}
 }
 
 // --- ValidateToken Tests ---
@@ -364,24 +1029,26 @@ func generateTestToken(userID uuid.UUID, secret string, expiresAt, issuedAt, nbf
 	if malformed {
 		return "malformed.token.string"
 	}
-	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"exp":     expiresAt.Unix(),
-		"iat":     issuedAt.Unix(),
+	claims := AccessClaims{
+		UserID: userID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(*expiresAt),
+			IssuedAt:  jwt.NewNumericDate(*issuedAt),
+		},
 	}
 	if nbfClaim != nil {
-		claims["nbf"] = nbfClaim.Unix()
+		claims.NotBefore = jwt.NewNumericDate(*nbfClaim)
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, _ := token.SignedString([]byte(secret))
 	return signedToken
 }
 
-
 func TestValidateToken(t *testing.T) {
-	mockUserSvc := new(MockUserService) // Not used by ValidateToken
+	mockUserSvc := new(MockUserService)     // Not used by ValidateToken
 	mockAuthRepo := new(MockAuthRepository) // Not used by ValidateToken
-	authService := NewService(mockUserSvc, mockAuthRepo, testConfig)
+	authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+	require.NoError(t, err)
 	ctx := context.Background()
 	userID := uuid.New()
 	now := time.Now()
@@ -395,72 +1062,51 @@ func TestValidateToken(t *testing.T) {
 		assert.Equal(t, userID, parsedUserID)
 	})
 
-	t.Run("Malformed Token - String Check", func(t *testing.T) {
-		// This test relies on the string check workaround
-		// malformedToken := "this.is.not.a.jwt" // This variable was declared but not used after refactoring the test logic.
-		// For dgrijalva/jwt-go, a common error for this is "token is malformed"
-		// or "token contains an invalid number of segments"
-		// The service code specifically checks for "token is malformed"
-
-		// To ensure our workaround is hit, we need a token that Parse() fails on *and* contains "token is malformed"
-		// A simple non-JWT string might not produce that exact message from Parse itself.
-		// Let's use a token that will fail parsing in a way our string check expects.
-		// The string "token is malformed" is one of the workaround checks.
-		// Note: This is tricky because the actual error from jwt.Parse for "this.is.not.a.jwt" might be "token contains an invalid number of segments".
-		// The service's current workaround checks for "token is malformed", "token is expired", etc.
-		// We need to simulate an error that *contains* one of these strings.
-		// The current `generateTestToken` with `malformed=true` returns "malformed.token.string" which won't match.
-		// We'll directly test with an error string that our service expects to catch.
-		// This highlights the fragility of string matching for errors.
-		// For now, let's assume the library *could* return an error containing this substring
-		// if the token structure is fundamentally wrong.
-
-		// A better way to test the workaround would be to mock the parser or pass a specific error
-		// that contains the substring. Since we can't mock jwt.Parse easily, we rely on the error propagation.
-		// The test for "token is malformed" in service is: strings.Contains(err.Error(), "token is malformed")
-		// We can't easily *cause* jwt.Parse to return an error whose .Error() string is exactly "token is malformed".
-		// So this specific sub-test for malformed token via string check is hard to write perfectly without deeper library mocking.
-		// We will test other specific error types that are easier to generate.
-		// For "token is malformed", the `!token.Valid` check or other specific string checks might catch it.
-		// The service has `if strings.Contains(err.Error(), "token is malformed")`
-
-		// Let's assume a token that jwt.Parse would error on and include "malformed"
-		// This is a placeholder as causing the exact error string is hard.
-		// The current workaround in service is: if strings.Contains(err.Error(), "token is malformed")
-		// If jwt.Parse returns an error that doesn't contain this, this specific string check won't pass.
-		// The `default` path of `fmt.Errorf("failed to parse token: %w", err)` would be hit.
-		// And then `!token.Valid` would also be true.
-		// For now, we'll skip trying to precisely hit the "token is malformed" string match,
-		// as it's dependent on jwt.Parse's internal error messages.
-		// The general `ErrInvalidToken` will be returned by `!token.Valid` anyway for many malformed cases.
-
-		// Instead, let's test a token with an invalid signature, which should also result in ErrInvalidToken
-		// (due to !token.Valid or the "signature is invalid" string check).
+	t.Run("Malformed Token", func(t *testing.T) {
+		malformedToken := generateTestToken(userID, testConfig.JWT.Secret, nil, nil, nil, true)
+		_, err := authService.ValidateToken(ctx, malformedToken)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrTokenMalformed), "Error was: %v", err)
+	})
+
+	t.Run("Invalid Signature", func(t *testing.T) {
 		exp := now.Add(time.Minute * 5)
 		iat := now
 		invalidSignatureToken := generateTestToken(userID, "wrong-secret", &exp, &iat, nil, false)
 		_, err := authService.ValidateToken(ctx, invalidSignatureToken)
 		assert.Error(t, err)
-		assert.True(t, errors.Is(err, ErrInvalidToken), "Error was: %v", err) // Expect our sentinel error
+		assert.True(t, errors.Is(err, ErrInvalidToken), "Error was: %v", err)
 	})
 
-	t.Run("Expired Token - String Check", func(t *testing.T) {
+	t.Run("Unexpected Signing Method", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+			"user_id": userID.String(),
+			"exp":     now.Add(time.Minute * 5).Unix(),
+		})
+		signedToken, _ := token.SignedString([]byte(testConfig.JWT.Secret))
+
+		_, err := authService.ValidateToken(ctx, signedToken)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidToken), "Error was: %v", err)
+	})
+
+	t.Run("Expired Token", func(t *testing.T) {
 		exp := now.Add(-time.Minute * 1) // Expired 1 minute ago
 		iat := now.Add(-time.Minute * 2) // Issued 2 minutes ago
 		expiredToken := generateTestToken(userID, testConfig.JWT.Secret, &exp, &iat, nil, false)
 		_, err := authService.ValidateToken(ctx, expiredToken)
 		assert.Error(t, err)
-		assert.True(t, errors.Is(err, ErrInvalidToken), "Error was: %v", err)
+		assert.True(t, errors.Is(err, ErrTokenExpired), "Error was: %v", err)
 	})
 
-	t.Run("Token Not Valid Yet - String Check", func(t *testing.T) {
+	t.Run("Token Not Valid Yet", func(t *testing.T) {
 		exp := now.Add(time.Minute * 10) // Expires in 10 mins
-		iat := now                        // Issued now
-		nbf := now.Add(time.Minute * 5)   // Not valid before 5 mins from now
+		iat := now                       // Issued now
+		nbf := now.Add(time.Minute * 5)  // Not valid before 5 mins from now
 		notYetValidToken := generateTestToken(userID, testConfig.JWT.Secret, &exp, &iat, &nbf, false)
 		_, err := authService.ValidateToken(ctx, notYetValidToken)
 		assert.Error(t, err)
-		assert.True(t, errors.Is(err, ErrInvalidToken), "Error was: %v", err)
+		assert.True(t, errors.Is(err, ErrTokenNotYetValid), "Error was: %v", err)
 	})
 
 	t.Run("Token Missing user_id Claim", func(t *testing.T) {
@@ -479,7 +1125,7 @@ func TestValidateToken(t *testing.T) {
 
 	t.Run("Token user_id Claim Not a String", func(t *testing.T) {
 		claims := jwt.MapClaims{
-			"user_id": 12345, // Not a string
+			"user_id": 12345, // Not a string, cannot be decoded into AccessClaims
 			"exp":     time.Now().Add(time.Minute * 5).Unix(),
 			"iat":     time.Now().Unix(),
 		}
@@ -488,7 +1134,7 @@ func TestValidateToken(t *testing.T) {
 
 		_, err := authService.ValidateToken(ctx, signedToken)
 		assert.Error(t, err)
-		assert.True(t, errors.Is(err, ErrInvalidToken))
+		assert.True(t, errors.Is(err, ErrTokenMalformed))
 	})
 
 	t.Run("Token user_id Claim Not a UUID", func(t *testing.T) {
@@ -506,6 +1152,375 @@ func TestValidateToken(t *testing.T) {
 	})
 }
 
-// Note: The ValidateToken tests are exercising the string-matching workaround.
-// If the underlying jwt library error messages change, these tests might break.
-// Ideally, these would use the jwt.ValidationError constants if the environment allowed.
+func TestValidateToken_Cache(t *testing.T) {
+	tokens := cache.New[[sha256.Size]byte, uuid.UUID]("tokens", cache.Config{MaxEntries: 10, TTL: time.Hour}, nil)
+	authService, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, nil, zap.NewNop(), WithTokenCache(tokens))
+	require.NoError(t, err)
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+
+	exp := now.Add(time.Minute * 5)
+	validToken := generateTestToken(userID, testConfig.JWT.Secret, &exp, &now, nil, false)
+	for i := 0; i < 2; i++ {
+		parsedUserID, err := authService.ValidateToken(ctx, validToken)
+		require.NoError(t, err)
+		assert.Equal(t, userID, parsedUserID)
+	}
+	assert.Equal(t, 1, tokens.Len())
+
+	// Only tokens that validated are remembered
+	expired := now.Add(-time.Minute)
+	_, err = authService.ValidateToken(ctx, generateTestToken(userID, testConfig.JWT.Secret, &expired, &now, nil, false))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+	_, err = authService.ValidateToken(ctx, generateTestToken(userID, "wrong-secret", &exp, &now, nil, false))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, 1, tokens.Len())
+
+	// A cached token is accepted without verifying its signature again
+	tokens.Set(sha256.Sum256([]byte("not-a-jwt")), userID)
+	parsedUserID, err := authService.ValidateToken(ctx, "not-a-jwt")
+	require.NoError(t, err)
+	assert.Equal(t, userID, parsedUserID)
+}
+
+// --- Authenticate Tests ---
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	iat := time.Now()
+	exp := iat.Add(5 * time.Minute)
+
+	tests := []struct {
+		name    string
+		user    *domainUser.User
+		lookup  error
+		wantErr error
+	}{
+		{name: "Active User", user: &domainUser.User{IsActive: true}},
+		{name: "Deactivated User", user: &domainUser.User{IsActive: false}, wantErr: ErrAccountDisabled},
+		{name: "Password Reset Required", user: &domainUser.User{IsActive: true, PasswordResetRequired: true}, wantErr: ErrPasswordResetRequired},
+		{name: "Deleted User", lookup: userService.ErrUserNotFound, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserSvc := new(MockUserService)
+			authService, err := NewService(mockUserSvc, new(MockAuthRepository), nil, testConfig, nil, zap.NewNop())
+			require.NoError(t, err)
+
+			userID := uuid.New()
+			if tt.user != nil {
+				tt.user.ID = userID
+			}
+			mockUserSvc.On("GetByID", ctx, userID).Return(tt.user, tt.lookup).Once()
+
+			got, err := authService.Authenticate(ctx, generateTestToken(userID, testConfig.JWT.Secret, &exp, &iat, nil, false))
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "Error was: %v", err)
+				assert.Equal(t, uuid.Nil, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, userID, got)
+			}
+			mockUserSvc.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Invalid Token Skips Lookup", func(t *testing.T) {
+		mockUserSvc := new(MockUserService)
+		authService, err := NewService(mockUserSvc, new(MockAuthRepository), nil, testConfig, nil, zap.NewNop())
+		require.NoError(t, err)
+
+		_, err = authService.Authenticate(ctx, "not-a-token")
+
+		assert.True(t, errors.Is(err, ErrTokenMalformed), "Error was: %v", err)
+		mockUserSvc.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+// --- Impersonation Tests ---
+
+// fakeImpersonations keeps impersonations in memory
+type fakeImpersonations struct {
+	byID map[uuid.UUID]*domainAuth.Impersonation
+}
+
+func (f *fakeImpersonations) Create(ctx context.Context, impersonation *domainAuth.Impersonation) error {
+	stored := *impersonation
+	f.byID[impersonation.ID] = &stored
+	return nil
+}
+
+func (f *fakeImpersonations) GetByID(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	impersonation, ok := f.byID[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := *impersonation
+	return &stored, nil
+}
+
+func (f *fakeImpersonations) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if impersonation, ok := f.byID[id]; ok && impersonation.RevokedAt == nil {
+		impersonation.RevokedAt = &at
+	}
+	return nil
+}
+
+func TestImpersonation(t *testing.T) {
+	ctx := context.Background()
+	adminID := uuid.New()
+	userID := uuid.New()
+
+	setup := func(t *testing.T) (domainAuth.AuthService, *MockUserService, *fakeImpersonations) {
+		mockUserSvc := new(MockUserService)
+		impersonations := &fakeImpersonations{byID: map[uuid.UUID]*domainAuth.Impersonation{}}
+		tokens := cache.New[[sha256.Size]byte, uuid.UUID]("tokens", cache.Config{MaxEntries: 10, TTL: time.Hour}, nil)
+		authService, err := NewService(mockUserSvc, new(MockAuthRepository), nil, testConfig, nil, zap.NewNop(), WithImpersonation(impersonations), WithTokenCache(tokens))
+		require.NoError(t, err)
+		mockUserSvc.On("GetByID", mock.Anything, userID).Return(&domainUser.User{ID: userID, Role: rbac.RoleUser, IsActive: true}, nil)
+		return authService, mockUserSvc, impersonations
+	}
+	admin := &domainUser.User{ID: adminID, Role: rbac.RoleAdmin, IsActive: true}
+
+	t.Run("Token Acts As User", func(t *testing.T) {
+		authService, mockUserSvc, impersonations := setup(t)
+		mockUserSvc.On("GetByID", mock.Anything, adminID).Return(admin, nil)
+
+		token, err := authService.Impersonate(ctx, adminID, userID, "ticket 42")
+		require.NoError(t, err)
+		assert.Equal(t, adminID, token.Impersonation.ActorID)
+		assert.Equal(t, userID, token.Impersonation.SubjectID)
+		assert.Equal(t, "ticket 42", token.Impersonation.Reason)
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), token.Impersonation.ExpiresAt, 2*time.Second)
+		assert.Contains(t, impersonations.byID, token.Impersonation.ID)
+
+		claims := &AccessClaims{}
+		_, err = jwt.ParseWithClaims(token.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte(testConfig.JWT.Secret), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, userID.String(), claims.UserID)
+		require.NotNil(t, claims.Actor)
+		assert.Equal(t, adminID.String(), claims.Actor.UserID)
+		assert.Equal(t, token.Impersonation.ID.String(), claims.ID)
+
+		principal, err := authService.AuthenticatePrincipal(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, domainAuth.Principal{UserID: userID, ActorID: adminID, ImpersonationID: token.Impersonation.ID}, principal)
+		assert.True(t, principal.Impersonated())
+	})
+
+	t.Run("Rejected Outside The HTTP API", func(t *testing.T) {
+		authService, _, _ := setup(t)
+
+		token, err := authService.Impersonate(ctx, adminID, userID, "ticket 42")
+		require.NoError(t, err)
+
+		_, err = authService.ValidateToken(ctx, token.AccessToken)
+		assert.ErrorIs(t, err, ErrImpersonationNotAccepted)
+		_, err = authService.Authenticate(ctx, token.AccessToken)
+		assert.ErrorIs(t, err, ErrImpersonationNotAccepted)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		authService, mockUserSvc, _ := setup(t)
+		mockUserSvc.On("GetByID", mock.Anything, adminID).Return(admin, nil)
+
+		token, err := authService.Impersonate(ctx, adminID, userID, "ticket 42")
+		require.NoError(t, err)
+		revoked, err := authService.RevokeImpersonation(ctx, token.Impersonation.ID)
+		require.NoError(t, err)
+		require.NotNil(t, revoked.RevokedAt)
+
+		_, err = authService.AuthenticatePrincipal(ctx, token.AccessToken)
+		assert.ErrorIs(t, err, ErrImpersonationEnded)
+
+		// Revoking again keeps the first revocation
+		again, err := authService.RevokeImpersonation(ctx, token.Impersonation.ID)
+		require.NoError(t, err)
+		assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt))
+	})
+
+	t.Run("Administrator Demoted", func(t *testing.T) {
+		authService, mockUserSvc, _ := setup(t)
+		mockUserSvc.On("GetByID", mock.Anything, adminID).Return(&domainUser.User{ID: adminID, Role: rbac.RoleSupport, IsActive: true}, nil)
+
+		token, err := authService.Impersonate(ctx, adminID, userID, "ticket 42")
+		require.NoError(t, err)
+
+		_, err = authService.AuthenticatePrincipal(ctx, token.AccessToken)
+		assert.ErrorIs(t, err, ErrImpersonationEnded)
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		authService, mockUserSvc, _ := setup(t)
+		unknownID := uuid.New()
+		mockUserSvc.On("GetByID", mock.Anything, unknownID).Return(nil, userService.ErrUserNotFound)
+
+		_, err := authService.Impersonate(ctx, adminID, unknownID, "ticket 42")
+		assert.ErrorIs(t, err, userService.ErrUserNotFound)
+	})
+
+	t.Run("Revoke Unknown Impersonation", func(t *testing.T) {
+		authService, _, _ := setup(t)
+
+		_, err := authService.RevokeImpersonation(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrImpersonationNotFound)
+	})
+}
+
+// --- CompletePasswordReset Tests ---
+
+func TestCompletePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	email := "test@example.com"
+	currentPassword := "password123"
+	newPassword := "newPassword456"
+
+	newService := func() (domainAuth.AuthService, *MockUserService, *MockAuthRepository) {
+		mockUserSvc := new(MockUserService)
+		mockAuthRepo := new(MockAuthRepository)
+		authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop())
+		require.NoError(t, err)
+		return authService, mockUserSvc, mockAuthRepo
+	}
+	input := domainAuth.PasswordResetInput{Email: email, CurrentPassword: currentPassword, NewPassword: newPassword}
+
+	t.Run("Success", func(t *testing.T) {
+		authService, mockUserSvc, mockAuthRepo := newService()
+		flagged := newAuthTestUser(email, currentPassword)
+		flagged.PasswordResetRequired = true
+		mockUserSvc.On("GetByEmail", ctx, email).Return(flagged, nil).Once()
+		mockUserSvc.On("UpdatePassword", ctx, flagged.ID, currentPassword, newPassword).Return(nil).Once()
+		mockAuthRepo.On("SetUserRefreshToken", ctx, flagged.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), flagged.ID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		tokenPair, err := authService.CompletePasswordReset(ctx, input)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokenPair.AccessToken)
+		assert.NotEmpty(t, tokenPair.RefreshToken)
+		mockUserSvc.AssertExpectations(t)
+		mockAuthRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong Current Password", func(t *testing.T) {
+		authService, mockUserSvc, _ := newService()
+		flagged := newAuthTestUser(email, currentPassword)
+		flagged.PasswordResetRequired = true
+		mockUserSvc.On("GetByEmail", ctx, email).Return(flagged, nil).Once()
+
+		wrong := input
+		wrong.CurrentPassword = "wrongPassword"
+		_, err := authService.CompletePasswordReset(ctx, wrong)
+
+		assert.True(t, errors.Is(err, ErrInvalidCredentials))
+		mockUserSvc.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("No Reset Pending", func(t *testing.T) {
+		authService, mockUserSvc, _ := newService()
+		mockUserSvc.On("GetByEmail", ctx, email).Return(newAuthTestUser(email, currentPassword), nil).Once()
+
+		_, err := authService.CompletePasswordReset(ctx, input)
+
+		assert.True(t, errors.Is(err, ErrNoPasswordReset))
+		mockUserSvc.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired Password", func(t *testing.T) {
+		authService, mockUserSvc, mockAuthRepo := newService()
+		expired := newAuthTestUser(email, currentPassword)
+		expiresAt := time.Now().Add(-time.Minute)
+		expired.PasswordExpiresAt = &expiresAt
+		mockUserSvc.On("GetByEmail", ctx, email).Return(expired, nil).Once()
+		mockUserSvc.On("UpdatePassword", ctx, expired.ID, currentPassword, newPassword).Return(nil).Once()
+		mockAuthRepo.On("SetUserRefreshToken", ctx, expired.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), expired.ID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		tokenPair, err := authService.CompletePasswordReset(ctx, input)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokenPair.AccessToken)
+		mockUserSvc.AssertExpectations(t)
+		mockAuthRepo.AssertExpectations(t)
+	})
+
+	t.Run("Password Reused", func(t *testing.T) {
+		authService, mockUserSvc, _ := newService()
+		expired := newAuthTestUser(email, currentPassword)
+		expiresAt := time.Now().Add(-time.Minute)
+		expired.PasswordExpiresAt = &expiresAt
+		mockUserSvc.On("GetByEmail", ctx, email).Return(expired, nil).Once()
+
+		reused := input
+		reused.NewPassword = currentPassword
+		_, err := authService.CompletePasswordReset(ctx, reused)
+
+		assert.True(t, errors.Is(err, ErrPasswordReused))
+		mockUserSvc.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Deactivated User", func(t *testing.T) {
+		authService, mockUserSvc, _ := newService()
+		flagged := newAuthTestUser(email, currentPassword)
+		flagged.PasswordResetRequired = true
+		flagged.IsActive = false
+		mockUserSvc.On("GetByEmail", ctx, email).Return(flagged, nil).Once()
+
+		_, err := authService.CompletePasswordReset(ctx, input)
+
+		assert.True(t, errors.Is(err, ErrAccountDisabled))
+	})
+}
+
+func TestLoginExternal(t *testing.T) {
+	ctx := context.Background()
+
+	newService := func() (domainAuth.AuthService, *MockUserService, *MockAuthRepository, *fakeLoginHistory, *recordingPublisher) {
+		mockUserSvc := new(MockUserService)
+		mockAuthRepo := new(MockAuthRepository)
+		history := &fakeLoginHistory{}
+		events := &recordingPublisher{}
+		authService, err := NewService(mockUserSvc, mockAuthRepo, nil, testConfig, nil, zap.NewNop(), WithLoginHistory(history), WithEventPublisher(events))
+		require.NoError(t, err)
+		return authService, mockUserSvc, mockAuthRepo, history, events
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		authService, mockUserSvc, mockAuthRepo, history, events := newService()
+		user := newAuthTestUser("test@example.com", "password123")
+		mockUserSvc.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mockAuthRepo.On("SetUserRefreshToken", ctx, user.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil).Once()
+		mockAuthRepo.On("SetRefreshTokenUserID", ctx, mock.AnythingOfType("string"), user.ID, mock.AnythingOfType("time.Duration")).Return(nil).Once()
+
+		tokenPair, err := authService.LoginExternal(ctx, domainAuth.ExternalLoginInput{UserID: user.ID, UserAgent: "curl/8.0", ClientIP: "203.0.113.7"})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokenPair.AccessToken)
+		assert.NotEmpty(t, tokenPair.RefreshToken)
+		require.Len(t, history.records, 1)
+		assert.Equal(t, domainAuth.LoginSucceeded, history.records[0].Result)
+		assert.Equal(t, "203.0.113.7", history.records[0].ClientIP)
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeUserLoggedIn, UserID: user.ID}}, events.events)
+		mockAuthRepo.AssertExpectations(t)
+	})
+
+	t.Run("Deactivated User", func(t *testing.T) {
+		authService, mockUserSvc, mockAuthRepo, history, events := newService()
+		user := newAuthTestUser("test@example.com", "password123")
+		user.IsActive = false
+		mockUserSvc.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+
+		_, err := authService.LoginExternal(ctx, domainAuth.ExternalLoginInput{UserID: user.ID})
+
+		assert.True(t, errors.Is(err, ErrAccountDisabled))
+		require.Len(t, history.records, 1)
+		assert.Equal(t, domainAuth.LoginAccountDisabled, history.records[0].Result)
+		assert.Empty(t, events.events)
+		mockAuthRepo.AssertNotCalled(t, "SetUserRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,18 @@
+package auth
+
+import "github.com/golang-jwt/jwt/v5"
+
+// AccessClaims are the claims carried by an access token. The user is the
+// subject the token acts as; impersonation tokens name the administrator
+// acting as them in Actor and the impersonation in the jti claim.
+type AccessClaims struct {
+	UserID string       `json:"user_id"`
+	Actor  *ActorClaims `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaims identify who acts on behalf of the subject, as in the act
+// claim of RFC 8693
+type ActorClaims struct {
+	UserID string `json:"user_id"`
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// deadlineService runs the operations of an AuthService within the read or
+// write deadline, reporting a passed deadline as deadline.ErrTimeout
+type deadlineService struct {
+	domainAuth.AuthService
+	deadlines deadline.Deadlines
+}
+
+func (s *deadlineService) Login(ctx context.Context, input domainAuth.LoginInput) (*domainAuth.TokenPair, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	tokens, err := s.AuthService.Login(ctx, input)
+	return tokens, deadline.Check(err)
+}
+
+func (s *deadlineService) LoginExternal(ctx context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	tokens, err := s.AuthService.LoginExternal(ctx, input)
+	return tokens, deadline.Check(err)
+}
+
+func (s *deadlineService) RefreshToken(ctx context.Context, refreshToken string) (*domainAuth.TokenPair, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	tokens, err := s.AuthService.RefreshToken(ctx, refreshToken)
+	return tokens, deadline.Check(err)
+}
+
+func (s *deadlineService) Logout(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.AuthService.Logout(ctx, userID))
+}
+
+func (s *deadlineService) LogoutByRefreshToken(ctx context.Context, refreshToken string) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.AuthService.LogoutByRefreshToken(ctx, refreshToken))
+}
+
+func (s *deadlineService) ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	userID, err := s.AuthService.ValidateToken(ctx, accessToken)
+	return userID, deadline.Check(err)
+}
+
+func (s *deadlineService) Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	userID, err := s.AuthService.Authenticate(ctx, accessToken)
+	return userID, deadline.Check(err)
+}
+
+func (s *deadlineService) AuthenticatePrincipal(ctx context.Context, accessToken string) (domainAuth.Principal, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	principal, err := s.AuthService.AuthenticatePrincipal(ctx, accessToken)
+	return principal, deadline.Check(err)
+}
+
+func (s *deadlineService) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	token, err := s.AuthService.Impersonate(ctx, actorID, subjectID, reason)
+	return token, deadline.Check(err)
+}
+
+func (s *deadlineService) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	impersonation, err := s.AuthService.RevokeImpersonation(ctx, id)
+	return impersonation, deadline.Check(err)
+}
+
+func (s *deadlineService) CompletePasswordReset(ctx context.Context, input domainAuth.PasswordResetInput) (*domainAuth.TokenPair, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	tokens, err := s.AuthService.CompletePasswordReset(ctx, input)
+	return tokens, deadline.Check(err)
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+func TestWithDeadlines(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	// The store hangs until the deadline of the operation passes
+	waitForDeadline := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+	newService := func(t *testing.T, users *MockUserService, repo *MockAuthRepository, deadlines deadline.Deadlines) domainAuth.AuthService {
+		svc, err := NewService(users, repo, nil, testConfig, nil, zap.NewNop(), WithDeadlines(deadlines))
+		require.NoError(t, err)
+		return svc
+	}
+
+	t.Run("Read Deadline", func(t *testing.T) {
+		users := new(MockUserService)
+		svc := newService(t, users, new(MockAuthRepository), deadline.Deadlines{Read: 10 * time.Millisecond, Write: time.Minute})
+		token, _, err := svc.(*deadlineService).AuthService.(*Service).generateAccessToken(userID)
+		require.NoError(t, err)
+		users.On("GetByID", mock.Anything, userID).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+		start := time.Now()
+		_, err = svc.Authenticate(ctx, token)
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Write Deadline", func(t *testing.T) {
+		repo := new(MockAuthRepository)
+		svc := newService(t, new(MockUserService), repo, deadline.Deadlines{Read: time.Minute, Write: 10 * time.Millisecond})
+		repo.On("GetUserRefreshToken", mock.Anything, userID).Run(waitForDeadline).Return("", context.DeadlineExceeded).Once()
+
+		err := svc.Logout(ctx, userID)
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+	})
+
+	t.Run("Other Errors Unchanged", func(t *testing.T) {
+		repo := new(MockAuthRepository)
+		svc := newService(t, new(MockUserService), repo, deadline.Deadlines{Write: time.Minute})
+		failed := errors.New("connection refused")
+		repo.On("GetUserRefreshToken", mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		}), userID).Return("", failed).Once()
+
+		err := svc.Logout(ctx, userID)
+
+		assert.ErrorIs(t, err, failed)
+		assert.NotErrorIs(t, err, deadline.ErrTimeout)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("No Deadlines", func(t *testing.T) {
+		assert.IsType(t, &Service{}, newService(t, new(MockUserService), new(MockAuthRepository), deadline.Deadlines{}))
+	})
+}
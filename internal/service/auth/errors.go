@@ -1,10 +1,25 @@
 package auth
 
-import "errors"
+import "github.com/yi-tech/go-user-service/internal/apperror"
 
 // Service-level errors for authentication and authorization operations
 var (
-	ErrInvalidCredentials    = errors.New("invalid credentials")
-	ErrInvalidOrExpiredToken = errors.New("invalid or expired refresh token")
-	ErrInvalidToken          = errors.New("invalid token") // For general token validation issues
+	ErrInvalidCredentials    = apperror.New(apperror.CodeInvalidCredentials, "invalid credentials")
+	ErrInvalidOrExpiredToken = apperror.New(apperror.CodeInvalidOrExpiredToken, "invalid or expired refresh token")
+	ErrInvalidToken          = apperror.New(apperror.CodeInvalidToken, "invalid token") // For general token validation issues
+	ErrSessionNotFound       = apperror.New(apperror.CodeSessionNotFound, "session not found")
+	ErrTokenExpired          = apperror.New(apperror.CodeTokenExpired, "token is expired")
+	ErrTokenNotYetValid      = apperror.New(apperror.CodeTokenNotYetValid, "token is not valid yet")
+	ErrTokenMalformed        = apperror.New(apperror.CodeTokenMalformed, "token is malformed")
+	ErrAccountDisabled       = apperror.New(apperror.CodeAccountDisabled, "account is disabled")
+	ErrPasswordResetRequired = apperror.New(apperror.CodePasswordResetRequired, "password reset required; set a new password to sign in")
+	ErrCaptchaRequired       = apperror.New(apperror.CodeCaptchaRequired, "captcha required after repeated failed sign-in attempts")
+	ErrPasswordExpired       = apperror.New(apperror.CodePasswordExpired, "password has expired; set a new password to sign in")
+	ErrNoPasswordReset       = apperror.New(apperror.CodeInvalidArgument, "no password reset is pending for this account")
+	ErrPasswordReused        = apperror.New(apperror.CodeInvalidArgument, "the new password must differ from the current one")
+	ErrAuthStoreUnavailable  = apperror.New(apperror.CodeServiceUnavailable, "sessions are temporarily unavailable; please try again later")
+
+	ErrImpersonationNotFound    = apperror.New(apperror.CodeImpersonationNotFound, "impersonation not found")
+	ErrImpersonationEnded       = apperror.New(apperror.CodeInvalidToken, "the impersonation has ended")
+	ErrImpersonationNotAccepted = apperror.New(apperror.CodePermissionDenied, "impersonation tokens are only accepted by the HTTP API")
 )
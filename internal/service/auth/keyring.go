@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// defaultKeyID is the key ID used when only the legacy jwt.secret is configured.
+// Tokens without a kid header are verified with the key of this ID.
+const defaultKeyID = "default"
+
+// maxRetainedKeys bounds how many keys generated by rotation are kept in the
+// ring; tokens signed with older ones are no longer accepted
+const maxRetainedKeys = 5
+
+// minKeyReload is how long the key ring waits before loading the shared keys
+// again for a token signed with a key it does not know, so that tokens with
+// made-up key IDs cannot make it hit the store on every request
+const minKeyReload = time.Second
+
+// keyLoadTimeout bounds loading the shared keys outside of a request
+const keyLoadTimeout = 5 * time.Second
+
+// Errors returned by the key ring
+var (
+	ErrUnknownKeyID        = errors.New("unknown signing key id") // A token references a key that is not in the key ring
+	ErrRotationUnavailable = errors.New("signing key rotation requires a shared key store")
+)
+
+// signingKey is a named key used to sign and verify access tokens.
+// For HMAC both keys are the shared secret; for RSA and ECDSA they are the
+// private and public halves of the key pair.
+type signingKey struct {
+	id        string
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeyRing holds the keys used to sign and verify access tokens.
+// The current key signs new tokens; previous keys are still accepted for
+// verification so that rotating keys does not invalidate existing sessions.
+//
+// Keys are rotated through configuration, by adding the new key, making it
+// current_key_id and keeping the previous keys until the tokens they signed
+// have expired, or at runtime with Rotate. Rotate keeps the keys it
+// generates in a store shared by every instance, which loads them again
+// after the refresh interval or when it sees a token signed with a key it
+// does not know yet.
+type KeyRing struct {
+	mu      sync.RWMutex
+	method  jwt.SigningMethod
+	current string
+	keys    []signingKey // Ordered from newest to oldest
+
+	// Set when keys generated by rotation are shared through store
+	store             domainAuth.SigningKeyRepository
+	refresh           time.Duration
+	logger            *zap.Logger
+	configured        []signingKey // Keys of the configuration
+	configuredCurrent string
+	loadMu            sync.Mutex // Serializes loads of the shared keys
+	loadedAt          time.Time
+	now               func() time.Time
+}
+
+// NewKeyRing creates a key ring from the JWT configuration.
+// If no keys are configured, the legacy jwt.secret is used as the only HS256 key.
+func NewKeyRing(cfg config.JWTConfig) (*KeyRing, error) {
+	method, err := signingMethod(cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Keys) == 0 {
+		if method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("jwt: algorithm %s requires configured keys", method.Alg())
+		}
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt: no signing keys configured")
+		}
+		return &KeyRing{
+			method:  method,
+			current: defaultKeyID,
+			keys:    []signingKey{hmacKey(defaultKeyID, []byte(cfg.Secret))},
+		}, nil
+	}
+
+	ring := &KeyRing{method: method, current: cfg.CurrentKeyID}
+	seen := make(map[string]bool, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		if k.ID == "" {
+			return nil, errors.New("jwt: signing keys require an id")
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("jwt: duplicate signing key id %q", k.ID)
+		}
+		seen[k.ID] = true
+
+		key, err := loadKey(method, k)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %q: %w", k.ID, err)
+		}
+		ring.keys = append(ring.keys, key)
+	}
+
+	if ring.current == "" {
+		ring.current = ring.keys[0].id
+	}
+	if !seen[ring.current] {
+		return nil, fmt.Errorf("jwt: current_key_id %q is not among the configured keys", ring.current)
+	}
+
+	return ring, nil
+}
+
+// NewSharedKeyRing creates a key ring from the JWT configuration that also
+// uses the keys generated by Rotate, which store shares between instances.
+// The newest generated key signs new tokens, taking precedence over
+// current_key_id. Generated keys are loaded again once they are older than
+// refresh.
+func NewSharedKeyRing(ctx context.Context, cfg config.JWTConfig, store domainAuth.SigningKeyRepository, refresh time.Duration, logger *zap.Logger) (*KeyRing, error) {
+	ring, err := NewKeyRing(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ring.store = store
+	ring.refresh = refresh
+	ring.logger = logger
+	ring.configured = ring.keys
+	ring.configuredCurrent = ring.current
+	ring.now = time.Now
+
+	ring.loadMu.Lock()
+	defer ring.loadMu.Unlock()
+	if err := ring.load(ctx); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// Rotate generates a new random key, stores it for every instance and makes
+// it current. The previous keys remain valid for verification until they
+// fall out of the ring.
+func (r *KeyRing) Rotate(ctx context.Context) (string, error) {
+	if r.store == nil {
+		return "", ErrRotationUnavailable
+	}
+
+	kid := uuid.New().String()
+	_, material, err := generateKey(r.method, kid)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	key := &domainAuth.SigningKey{ID: kid, Algorithm: r.method.Alg(), Material: material, CreatedAt: r.now()}
+	if err := r.store.SaveSigningKey(ctx, key); err != nil {
+		return "", fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	if err := r.load(ctx); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// load replaces the keys generated by rotation with those in the store.
+// loadMu must be held.
+func (r *KeyRing) load(ctx context.Context) error {
+	r.loadedAt = r.now()
+	stored, err := r.store.ListSigningKeys(ctx, r.method.Alg(), maxRetainedKeys)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make([]signingKey, 0, len(stored)+len(r.configured))
+	for _, k := range stored {
+		key, err := storedKey(r.method, k)
+		if err != nil {
+			return fmt.Errorf("jwt: stored key %q: %w", k.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	current := r.configuredCurrent
+	if len(keys) > 0 {
+		current = keys[0].id
+	}
+	keys = append(keys, r.configured...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.current = current
+	return nil
+}
+
+// sync loads the shared keys again when they are older than the refresh
+// interval, or than minKeyReload when a key is missing. Failures keep the
+// keys loaded last.
+func (r *KeyRing) sync(missing bool) {
+	if r.store == nil {
+		return
+	}
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	age := r.now().Sub(r.loadedAt)
+	if age < r.refresh && (!missing || age < minKeyReload) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), keyLoadTimeout)
+	defer cancel()
+	if err := r.load(ctx); err != nil {
+		r.logger.Warn("Failed to load shared signing keys; using previous keys", zap.Error(err))
+	}
+}
+
+// Method returns the signing method used for every key in the ring
+func (r *KeyRing) Method() jwt.SigningMethod {
+	return r.method
+}
+
+// Current returns the ID and signing key of the key used to sign new tokens
+func (r *KeyRing) Current() (string, interface{}) {
+	r.sync(false)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.id == r.current {
+			return k.id, k.signKey
+		}
+	}
+	return "", nil // Unreachable: the current key is always present
+}
+
+// Lookup returns the verification key for the given key ID. A key ring
+// sharing generated keys looks for keys another instance generated when it
+// does not know the ID.
+func (r *KeyRing) Lookup(kid string) (interface{}, error) {
+	r.sync(false)
+	if key, ok := r.lookup(kid); ok {
+		return key, nil
+	}
+	r.sync(true)
+	if key, ok := r.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKeyID
+}
+
+func (r *KeyRing) lookup(kid string) (interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.id == kid {
+			return k.verifyKey, true
+		}
+	}
+	return nil, false
+}
+
+// ActiveKeyID returns the ID of the key used to sign new tokens
+func (r *KeyRing) ActiveKeyID() string {
+	r.sync(false)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// PublicKeys returns the public verification keys in JWK form.
+// HMAC secrets are never exposed, so the set is empty for HS256.
+func (r *KeyRing) PublicKeys() []domainAuth.JSONWebKey {
+	r.sync(false)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]domainAuth.JSONWebKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		if jwk, ok := toJWK(r.method, k); ok {
+			keys = append(keys, jwk)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	repoAuth "github.com/yi-tech/go-user-service/internal/repository/auth"
+)
+
+func TestNewKeyRing(t *testing.T) {
+	t.Run("Legacy Secret", func(t *testing.T) {
+		ring, err := NewKeyRing(config.JWTConfig{Secret: "legacy"})
+		require.NoError(t, err)
+		kid, secret := ring.Current()
+		assert.Equal(t, defaultKeyID, kid)
+		assert.Equal(t, []byte("legacy"), secret)
+	})
+
+	t.Run("Configured Keys", func(t *testing.T) {
+		ring, err := NewKeyRing(config.JWTConfig{
+			CurrentKeyID: "k2",
+			Keys:         []config.JWTKeyConfig{{ID: "k1", Secret: "s1"}, {ID: "k2", Secret: "s2"}},
+		})
+		require.NoError(t, err)
+		kid, _ := ring.Current()
+		assert.Equal(t, "k2", kid)
+		secret, err := ring.Lookup("k1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("s1"), secret)
+	})
+
+	t.Run("Unknown Current Key", func(t *testing.T) {
+		_, err := NewKeyRing(config.JWTConfig{
+			CurrentKeyID: "missing",
+			Keys:         []config.JWTKeyConfig{{ID: "k1", Secret: "s1"}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("No Keys", func(t *testing.T) {
+		_, err := NewKeyRing(config.JWTConfig{})
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTokenAcrossRotation(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	jwtConfig := func(current string, keys ...config.JWTKeyConfig) *config.Config {
+		cfg := *testConfig
+		cfg.JWT.CurrentKeyID = current
+		cfg.JWT.Keys = keys
+		return &cfg
+	}
+	legacyKey := config.JWTKeyConfig{ID: defaultKeyID, Secret: testConfig.JWT.Secret}
+	oldKey := config.JWTKeyConfig{ID: "2025-01", Secret: "old-secret"}
+	newKey := config.JWTKeyConfig{ID: "2025-06", Secret: "new-secret"}
+
+	before, err := NewService(new(MockUserService), new(MockAuthRepository), nil, jwtConfig(oldKey.ID, oldKey), nil, zap.NewNop())
+	require.NoError(t, err)
+	after, err := NewService(new(MockUserService), new(MockAuthRepository), nil, jwtConfig(newKey.ID, newKey, oldKey, legacyKey), nil, zap.NewNop())
+	require.NoError(t, err)
+
+	oldToken, _, err := before.(*Service).generateAccessToken(userID)
+	require.NoError(t, err)
+	newToken, _, err := after.(*Service).generateAccessToken(userID)
+	require.NoError(t, err)
+
+	for _, token := range []string{oldToken, newToken} {
+		parsed, err := after.ValidateToken(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, parsed)
+	}
+
+	// Tokens issued before kid headers were introduced are verified with the
+	// legacy key, not whichever key is current
+	exp, iat := time.Now().Add(time.Minute), time.Now()
+	legacy := generateTestToken(userID, testConfig.JWT.Secret, &exp, &iat, nil, false)
+	parsed, err := after.ValidateToken(ctx, legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, parsed)
+
+	_, err = before.ValidateToken(ctx, legacy)
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestAsymmetricKeyRing(t *testing.T) {
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodES256} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			ring, err := NewKeyRing(config.JWTConfig{
+				Algorithm: method.Alg(),
+				Keys:      []config.JWTKeyConfig{{ID: "k1", PrivateKeyFile: writeTestKey(t, method)}},
+			})
+			require.NoError(t, err)
+
+			svc, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, ring, zap.NewNop())
+			require.NoError(t, err)
+			userID := uuid.New()
+
+			token, _, err := svc.(*Service).generateAccessToken(userID)
+			require.NoError(t, err)
+			parsed, err := svc.ValidateToken(context.Background(), token)
+			assert.NoError(t, err)
+			assert.Equal(t, userID, parsed)
+
+			jwks := ring.PublicKeys()
+			require.Len(t, jwks, 1)
+			assert.Equal(t, "k1", jwks[0].KeyID)
+			assert.Equal(t, method.Alg(), jwks[0].Algorithm)
+		})
+	}
+
+	t.Run("HS256 Token Rejected By RS256 Ring", func(t *testing.T) {
+		ring, err := NewKeyRing(config.JWTConfig{
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			Keys:      []config.JWTKeyConfig{{ID: defaultKeyID, PrivateKeyFile: writeTestKey(t, jwt.SigningMethodRS256)}},
+		})
+		require.NoError(t, err)
+		svc, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, ring, zap.NewNop())
+		require.NoError(t, err)
+
+		exp, iat := time.Now().Add(time.Minute), time.Now()
+		hsToken := generateTestToken(uuid.New(), "secret", &exp, &iat, nil, false)
+		_, err = svc.ValidateToken(context.Background(), hsToken)
+		assert.True(t, errors.Is(err, ErrInvalidToken))
+	})
+
+	t.Run("HMAC Keys Are Not Published", func(t *testing.T) {
+		ring, err := NewKeyRing(config.JWTConfig{Secret: "legacy"})
+		require.NoError(t, err)
+		assert.Empty(t, ring.PublicKeys())
+	})
+}
+
+func TestSharedKeyRingRotate(t *testing.T) {
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodHS256, jwt.SigningMethodRS256, jwt.SigningMethodES256} {
+		t.Run(method.Alg(), func(t *testing.T) {
+			ctx := context.Background()
+			cfg := config.JWTConfig{Algorithm: method.Alg(), Keys: []config.JWTKeyConfig{{ID: defaultKeyID, Secret: "legacy"}}}
+			if method != jwt.SigningMethodHS256 {
+				cfg.Keys[0] = config.JWTKeyConfig{ID: defaultKeyID, PrivateKeyFile: writeTestKey(t, method)}
+			}
+			store := repoAuth.NewMemorySigningKeyRepository()
+
+			// Two instances share the store; the first rotates
+			rotating, err := NewSharedKeyRing(ctx, cfg, store, time.Minute, zap.NewNop())
+			require.NoError(t, err)
+			other, err := NewSharedKeyRing(ctx, cfg, store, time.Minute, zap.NewNop())
+			require.NoError(t, err)
+			now := time.Now()
+			other.now = func() time.Time { return now }
+
+			kid, err := rotating.Rotate(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, kid, rotating.ActiveKeyID())
+			_, err = rotating.Lookup(defaultKeyID)
+			assert.NoError(t, err, "the configured key still verifies")
+
+			signer, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, rotating, zap.NewNop())
+			require.NoError(t, err)
+			verifier, err := NewService(new(MockUserService), new(MockAuthRepository), nil, testConfig, other, zap.NewNop())
+			require.NoError(t, err)
+			userID := uuid.New()
+			token, _, err := signer.(*Service).generateAccessToken(userID)
+			require.NoError(t, err)
+
+			// The other instance loads the new key when it sees its kid
+			now = now.Add(minKeyReload)
+			parsed, err := verifier.ValidateToken(ctx, token)
+			assert.NoError(t, err)
+			assert.Equal(t, userID, parsed)
+			assert.Equal(t, kid, other.ActiveKeyID())
+		})
+	}
+
+	t.Run("Picks Up Rotation After Refresh", func(t *testing.T) {
+		ctx := context.Background()
+		cfg := config.JWTConfig{Secret: "legacy"}
+		store := repoAuth.NewMemorySigningKeyRepository()
+		rotating, err := NewSharedKeyRing(ctx, cfg, store, time.Minute, zap.NewNop())
+		require.NoError(t, err)
+		other, err := NewSharedKeyRing(ctx, cfg, store, time.Minute, zap.NewNop())
+		require.NoError(t, err)
+		now := time.Now()
+		other.now = func() time.Time { return now }
+
+		kid, err := rotating.Rotate(ctx)
+		require.NoError(t, err)
+		current, _ := other.Current()
+		assert.Equal(t, defaultKeyID, current)
+
+		now = now.Add(time.Minute)
+		current, _ = other.Current()
+		assert.Equal(t, kid, current)
+	})
+
+	t.Run("Retains Recent Keys", func(t *testing.T) {
+		ctx := context.Background()
+		ring, err := NewSharedKeyRing(ctx, config.JWTConfig{Secret: "legacy"}, repoAuth.NewMemorySigningKeyRepository(), time.Minute, zap.NewNop())
+		require.NoError(t, err)
+		first, err := ring.Rotate(ctx)
+		require.NoError(t, err)
+		for i := 0; i < maxRetainedKeys; i++ {
+			_, err = ring.Rotate(ctx)
+			require.NoError(t, err)
+		}
+
+		_, err = ring.Lookup(first)
+		assert.True(t, errors.Is(err, ErrUnknownKeyID))
+		_, err = ring.Lookup(defaultKeyID)
+		assert.NoError(t, err, "configured keys are never dropped")
+	})
+
+	t.Run("Without Store", func(t *testing.T) {
+		ring, err := NewKeyRing(config.JWTConfig{Secret: "legacy"})
+		require.NoError(t, err)
+		_, err = ring.Rotate(context.Background())
+		assert.True(t, errors.Is(err, ErrRotationUnavailable))
+	})
+}
+
+func TestNewServiceRejectsInvalidKeyConfig(t *testing.T) {
+	_, err := NewService(new(MockUserService), new(MockAuthRepository), nil, &config.Config{}, nil, zap.NewNop())
+	assert.Error(t, err)
+}
+
+// writeTestKey generates a private key for method and writes it as PEM to a temporary file
+func writeTestKey(t *testing.T, method jwt.SigningMethod) string {
+	t.Helper()
+
+	var block *pem.Block
+	switch method {
+	case jwt.SigningMethodRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}
+	default:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalECPrivateKey(priv)
+		require.NoError(t, err)
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
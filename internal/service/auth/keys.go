@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// rsaKeyBits is the modulus size of generated RSA keys
+const rsaKeyBits = 2048
+
+// signingMethod resolves the configured algorithm, defaulting to HS256
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	switch alg {
+	case "", jwt.SigningMethodHS256.Alg():
+		return jwt.SigningMethodHS256, nil
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256, nil
+	case jwt.SigningMethodES256.Alg():
+		return jwt.SigningMethodES256, nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", alg)
+	}
+}
+
+// hmacKey creates a signing key from a shared secret
+func hmacKey(id string, secret []byte) signingKey {
+	return signingKey{id: id, signKey: secret, verifyKey: secret}
+}
+
+// loadKey builds a signing key from configuration
+func loadKey(method jwt.SigningMethod, k config.JWTKeyConfig) (signingKey, error) {
+	if method == jwt.SigningMethodHS256 {
+		if k.Secret == "" {
+			return signingKey{}, errors.New("secret is required for HS256")
+		}
+		return hmacKey(k.ID, []byte(k.Secret)), nil
+	}
+
+	if k.PrivateKeyFile == "" {
+		return signingKey{}, fmt.Errorf("private_key_file is required for %s", method.Alg())
+	}
+	pemBytes, err := os.ReadFile(k.PrivateKeyFile)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("failed to read private key: %w", err)
+	}
+	return parsePrivateKey(method, k.ID, pemBytes)
+}
+
+// parsePrivateKey builds an RSA or ECDSA signing key from a PEM encoded private key
+func parsePrivateKey(method jwt.SigningMethod, id string, pemBytes []byte) (signingKey, error) {
+	switch method {
+	case jwt.SigningMethodRS256:
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("failed to parse RSA private key: %w", err)
+		}
+		return signingKey{id: id, signKey: priv, verifyKey: &priv.PublicKey}, nil
+	default:
+		priv, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("failed to parse EC private key: %w", err)
+		}
+		if priv.Curve != elliptic.P256() {
+			return signingKey{}, errors.New("ES256 requires a P-256 key")
+		}
+		return signingKey{id: id, signKey: priv, verifyKey: &priv.PublicKey}, nil
+	}
+}
+
+// generateKey creates a new random key for the signing method and returns
+// it along with the material it is stored as: the HMAC secret or the PEM
+// encoded private key
+func generateKey(method jwt.SigningMethod, id string) (signingKey, []byte, error) {
+	switch method {
+	case jwt.SigningMethodRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return signingKey{}, nil, err
+		}
+		material := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+		return signingKey{id: id, signKey: priv, verifyKey: &priv.PublicKey}, material, nil
+	case jwt.SigningMethodES256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return signingKey{}, nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			return signingKey{}, nil, err
+		}
+		material := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		return signingKey{id: id, signKey: priv, verifyKey: &priv.PublicKey}, material, nil
+	default:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return signingKey{}, nil, err
+		}
+		material := []byte(hex.EncodeToString(secret))
+		return hmacKey(id, material), material, nil
+	}
+}
+
+// storedKey builds a signing key from one generated by rotation
+func storedKey(method jwt.SigningMethod, k *domainAuth.SigningKey) (signingKey, error) {
+	if method == jwt.SigningMethodHS256 {
+		return hmacKey(k.ID, k.Material), nil
+	}
+	return parsePrivateKey(method, k.ID, k.Material)
+}
+
+// toJWK converts the public half of a key to a JSON Web Key (RFC 7517)
+func toJWK(method jwt.SigningMethod, k signingKey) (domainAuth.JSONWebKey, bool) {
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return domainAuth.JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     k.id,
+			Use:       "sig",
+			Algorithm: method.Alg(),
+			N:         b64(pub.N.Bytes()),
+			E:         b64(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return domainAuth.JSONWebKey{
+			KeyType:   "EC",
+			KeyID:     k.id,
+			Use:       "sig",
+			Algorithm: method.Alg(),
+			Curve:     pub.Curve.Params().Name,
+			X:         b64(pub.X.FillBytes(make([]byte, size))),
+			Y:         b64(pub.Y.FillBytes(make([]byte, size))),
+		}, true
+	default:
+		return domainAuth.JSONWebKey{}, false
+	}
+}
+
+// b64 encodes bytes as unpadded base64url, as required by JWK
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
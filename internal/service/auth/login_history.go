@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// LoginHistoryPruner deletes sign-in attempts older than the retention period
+type LoginHistoryPruner struct {
+	history   domainAuth.LoginHistoryRepository
+	retention time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewLoginHistoryPruner creates a pruner keeping attempts for retention and
+// pruning every interval
+func NewLoginHistoryPruner(history domainAuth.LoginHistoryRepository, retention, interval time.Duration, logger *zap.Logger) *LoginHistoryPruner {
+	return &LoginHistoryPruner{
+		history:   history,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Run prunes right away and then every interval until ctx is done. Failures
+// are logged and retried at the next interval.
+func (p *LoginHistoryPruner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Prune(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Warn("Failed to prune login history", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes the attempts that have outlived the retention period and
+// returns how many were deleted
+func (p *LoginHistoryPruner) Prune(ctx context.Context) (int64, error) {
+	deleted, err := p.history.Prune(ctx, p.now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		p.logger.Info("Pruned login history", zap.Int64("deleted", deleted))
+	}
+	return deleted, nil
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLoginHistoryPruner(t *testing.T) {
+	history := &fakeLoginHistory{}
+	pruner := NewLoginHistoryPruner(history, 30*24*time.Hour, time.Hour, zap.NewNop())
+	now := time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+	pruner.now = func() time.Time { return now }
+
+	deleted, err := pruner.Prune(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.Equal(t, now.Add(-30*24*time.Hour), history.cutoff)
+}
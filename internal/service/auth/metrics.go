@@ -0,0 +1,45 @@
+package auth
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Best-effort steps of sign-in, token refresh and sign-out. Their failures
+// are logged and counted but do not fail the request.
+const (
+	opRehashPassword        = "rehash_password"
+	opCountLoginFailures    = "count_login_failures"
+	opRecordLoginFailure    = "record_login_failure"
+	opResetLoginFailures    = "reset_login_failures"
+	opRecordLoginHistory    = "record_login_history"
+	opReadLoginHistory      = "read_login_history"
+	opRecordSession         = "record_session"
+	opRotateSession         = "rotate_session"
+	opDeleteOldRefreshToken = "delete_old_refresh_token"
+	opDeleteRefreshToken    = "delete_refresh_token"
+)
+
+// Metrics counts the failures of best-effort steps by operation
+type Metrics struct {
+	failures *prometheus.CounterVec
+}
+
+// NewMetrics creates auth service metrics and registers them
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_best_effort_failures_total",
+			Help: "Total number of best-effort auth steps, such as deleting a replaced refresh token, that failed without failing the request.",
+		}, []string{"operation"}),
+	}
+	if err := registerer.Register(m.failures); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// failed counts a failure of operation; a nil Metrics records nothing
+func (m *Metrics) failed(operation string) {
+	if m == nil {
+		return
+	}
+	m.failures.WithLabelValues(operation).Inc()
+}
@@ -0,0 +1,41 @@
+package captcha
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider names accepted in captcha.provider
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCaptcha = "recaptcha"
+	ProviderTurnstile = "turnstile"
+)
+
+// Provider describes a CAPTCHA vendor's siteverify API
+type Provider struct {
+	Name      string
+	VerifyURL string // Default siteverify endpoint
+	Scored    bool   // Responses carry a score, as reCAPTCHA v3 ones do
+}
+
+// providers lists the built-in providers by name
+var providers = map[string]Provider{
+	ProviderHCaptcha:  {Name: ProviderHCaptcha, VerifyURL: "https://api.hcaptcha.com/siteverify"},
+	ProviderReCaptcha: {Name: ProviderReCaptcha, VerifyURL: "https://www.google.com/recaptcha/api/siteverify", Scored: true},
+	ProviderTurnstile: {Name: ProviderTurnstile, VerifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify"},
+}
+
+// LookupProvider returns the built-in provider of a name, case-insensitively.
+// An empty name is a provider without a default endpoint that accepts any
+// compatible siteverify API.
+func LookupProvider(name string) (Provider, error) {
+	if name == "" {
+		return Provider{}, nil
+	}
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return Provider{}, fmt.Errorf("captcha: unknown provider %q", name)
+	}
+	return provider, nil
+}
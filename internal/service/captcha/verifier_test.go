@@ -0,0 +1,129 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+func TestNewVerifier(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		verifier, err := NewVerifier(config.CaptchaConfig{Enabled: false})
+		assert.NoError(t, err)
+		assert.Nil(t, verifier)
+	})
+
+	t.Run("Missing Verify URL", func(t *testing.T) {
+		_, err := NewVerifier(config.CaptchaConfig{Enabled: true, Secret: "s3cret"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing Secret", func(t *testing.T) {
+		_, err := NewVerifier(config.CaptchaConfig{Enabled: true, VerifyURL: "https://captcha.example/siteverify"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Provider Default Verify URL", func(t *testing.T) {
+		verifier, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: "hCaptcha", Secret: "s3cret"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.hcaptcha.com/siteverify", verifier.(*siteVerifier).verifyURL)
+	})
+
+	t.Run("Verify URL Overrides Provider", func(t *testing.T) {
+		verifier, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: ProviderReCaptcha, VerifyURL: "https://captcha.example/siteverify", Secret: "s3cret"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://captcha.example/siteverify", verifier.(*siteVerifier).verifyURL)
+	})
+
+	t.Run("Unknown Provider", func(t *testing.T) {
+		_, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: "friendlycaptcha", Secret: "s3cret"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Min Score Out Of Range", func(t *testing.T) {
+		_, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: ProviderReCaptcha, Secret: "s3cret", MinScore: 1.5})
+		assert.Error(t, err)
+	})
+
+	t.Run("Min Score Of Unscored Provider", func(t *testing.T) {
+		_, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: ProviderHCaptcha, Secret: "s3cret", MinScore: 0.5})
+		assert.Error(t, err)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("secret") != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("response") == "good" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false}`))
+	}))
+	defer server.Close()
+
+	verifier, err := NewVerifier(config.CaptchaConfig{Enabled: true, VerifyURL: server.URL, Secret: "s3cret"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+	}{
+		{name: "Accepted", token: "good", expectedErr: nil},
+		{name: "Rejected", token: "bad", expectedErr: ErrCaptchaFailed},
+		{name: "Missing Token", token: "", expectedErr: ErrCaptchaFailed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifier.Verify(context.Background(), tc.token, "127.0.0.1")
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tc.expectedErr))
+			}
+		})
+	}
+
+	t.Run("Provider Error", func(t *testing.T) {
+		broken, err := NewVerifier(config.CaptchaConfig{Enabled: true, VerifyURL: server.URL, Secret: "wrong"})
+		require.NoError(t, err)
+		err = broken.Verify(context.Background(), "good", "")
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCaptchaFailed))
+	})
+}
+
+func TestVerify_MinScore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.PostForm.Get("response") {
+		case "human":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.9}`))
+		case "bot":
+			_, _ = w.Write([]byte(`{"success":true,"score":0.1}`))
+		default:
+			_, _ = w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+
+	verifier, err := NewVerifier(config.CaptchaConfig{Enabled: true, Provider: ProviderReCaptcha, VerifyURL: server.URL, Secret: "s3cret", MinScore: 0.5})
+	require.NoError(t, err)
+
+	assert.NoError(t, verifier.Verify(context.Background(), "human", ""))
+	assert.ErrorIs(t, verifier.Verify(context.Background(), "bot", ""), ErrCaptchaFailed)
+	assert.ErrorIs(t, verifier.Verify(context.Background(), "unscored", ""), ErrCaptchaFailed, "a v2 token does not meet a minimum score")
+}
@@ -0,0 +1,87 @@
+package compliance
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainCompliance "github.com/yi-tech/go-user-service/internal/domain/compliance"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// anyRegion allows a destination to receive data from every region
+const anyRegion = "*"
+
+type residencyPolicy struct {
+	regions       map[string]bool
+	defaultRegion string
+	destinations  map[string]map[string]bool // destination -> allowed regions
+}
+
+// NewResidencyPolicy creates a ResidencyPolicy from configuration.
+// The default residency and every region named by a destination rule must be
+// among the configured regions. Destinations without a rule receive no data.
+func NewResidencyPolicy(cfg config.ComplianceConfig) (domainCompliance.ResidencyPolicy, error) {
+	regions := make(map[string]bool, len(cfg.Regions))
+	for _, region := range cfg.Regions {
+		regions[strings.ToUpper(region)] = true
+	}
+
+	defaultRegion := strings.ToUpper(cfg.DefaultResidency)
+	if defaultRegion != "" && !regions[defaultRegion] {
+		return nil, fmt.Errorf("compliance: default_residency %q is not a configured region", cfg.DefaultResidency)
+	}
+
+	destinations := make(map[string]map[string]bool, len(cfg.Destinations))
+	for destination, allowedRegions := range cfg.Destinations {
+		allowed := make(map[string]bool, len(allowedRegions))
+		for _, region := range allowedRegions {
+			region = strings.ToUpper(region)
+			if region != anyRegion && !regions[region] {
+				return nil, fmt.Errorf("compliance: destination %q allows unknown region %q", destination, region)
+			}
+			allowed[region] = true
+		}
+		destinations[destination] = allowed
+	}
+
+	return &residencyPolicy{
+		regions:       regions,
+		defaultRegion: defaultRegion,
+		destinations:  destinations,
+	}, nil
+}
+
+// KnownRegion reports whether region is one of the configured residency regions
+func (p *residencyPolicy) KnownRegion(region string) bool {
+	return p.regions[strings.ToUpper(region)]
+}
+
+// ResidencyOf returns the user's residency, falling back to the default region
+func (p *residencyPolicy) ResidencyOf(user *domainUser.User) string {
+	if user.Residency != "" {
+		return strings.ToUpper(user.Residency)
+	}
+	return p.defaultRegion
+}
+
+// Allows reports whether data resident in region may be sent to destination.
+// Users without a known region are only sent to destinations open to every region.
+func (p *residencyPolicy) Allows(region, destination string) bool {
+	allowed, ok := p.destinations[destination]
+	if !ok {
+		return false
+	}
+	return allowed[anyRegion] || (region != "" && allowed[strings.ToUpper(region)])
+}
+
+// FilterUsers returns the users whose data may be sent to destination
+func (p *residencyPolicy) FilterUsers(users []*domainUser.User, destination string) []*domainUser.User {
+	filtered := make([]*domainUser.User, 0, len(users))
+	for _, u := range users {
+		if p.Allows(p.ResidencyOf(u), destination) {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,86 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+func TestResidencyPolicy(t *testing.T) {
+	policy, err := NewResidencyPolicy(config.ComplianceConfig{
+		Regions:          []string{"us", "EU", "APAC"},
+		DefaultResidency: "us",
+		Destinations: map[string][]string{
+			"analytics":    {"US"},
+			"eu-warehouse": {"eu"},
+			"audit":        {"*"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		region      string
+		destination string
+		expected    bool
+	}{
+		{name: "Allowed Region", region: "US", destination: "analytics", expected: true},
+		{name: "Blocked Region", region: "EU", destination: "analytics", expected: false},
+		{name: "Case Insensitive", region: "eu", destination: "eu-warehouse", expected: true},
+		{name: "Wildcard", region: "APAC", destination: "audit", expected: true},
+		{name: "Unconfigured Destination", region: "EU", destination: "other", expected: false},
+		{name: "Unknown Region", region: "", destination: "analytics", expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policy.Allows(tc.region, tc.destination))
+		})
+	}
+
+	t.Run("Known Regions", func(t *testing.T) {
+		assert.True(t, policy.KnownRegion("apac"))
+		assert.False(t, policy.KnownRegion("MARS"))
+	})
+
+	t.Run("Filter Users", func(t *testing.T) {
+		eu := &domainUser.User{Email: "eu@example.com", Residency: "EU"}
+		us := &domainUser.User{Email: "us@example.com", Residency: "US"}
+		unset := &domainUser.User{Email: "unset@example.com"}
+
+		assert.Equal(t, "US", policy.ResidencyOf(unset))
+		assert.Equal(t, []*domainUser.User{us, unset}, policy.FilterUsers([]*domainUser.User{eu, us, unset}, "analytics"))
+		assert.Equal(t, []*domainUser.User{eu}, policy.FilterUsers([]*domainUser.User{eu, us, unset}, "eu-warehouse"))
+		assert.Empty(t, policy.FilterUsers([]*domainUser.User{eu, us, unset}, "other"))
+	})
+}
+
+func TestNewResidencyPolicyRejectsUnknownRegions(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ComplianceConfig
+	}{
+		{
+			name: "Default Residency",
+			cfg:  config.ComplianceConfig{Regions: []string{"EU"}, DefaultResidency: "US"},
+		},
+		{
+			name: "Destination Region",
+			cfg: config.ComplianceConfig{
+				Regions:      []string{"EU"},
+				Destinations: map[string][]string{"export": {"EU", "US"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewResidencyPolicy(tc.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package deadline bounds how long service operations wait on their
+// repositories, so a slow database fails a request quickly instead of
+// holding it until the request timeout or the client gives up.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+// ErrTimeout is returned for operations that ran out of time
+var ErrTimeout = apperror.New(apperror.CodeTimeout, "The operation took too long to complete. Please try again later.")
+
+// Deadlines holds how long reads and writes may take. A zero duration sets
+// no deadline of its own, so the operation keeps the deadline of its caller.
+type Deadlines struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+// FromConfig returns the deadlines of the deadlines configuration
+func FromConfig(cfg config.DeadlinesConfig) Deadlines {
+	return Deadlines{Read: cfg.Read(), Write: cfg.Write()}
+}
+
+// IsZero reports whether no operation has a deadline
+func (d Deadlines) IsZero() bool {
+	return d.Read <= 0 && d.Write <= 0
+}
+
+// ForRead returns ctx bounded by the read deadline. An earlier deadline of
+// ctx is kept.
+func (d Deadlines) ForRead(ctx context.Context) (context.Context, context.CancelFunc) {
+	return within(ctx, d.Read)
+}
+
+// ForWrite returns ctx bounded by the write deadline. An earlier deadline
+// of ctx is kept.
+func (d Deadlines) ForWrite(ctx context.Context) (context.Context, context.CancelFunc) {
+	return within(ctx, d.Write)
+}
+
+func within(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Check reports an error caused by a passed deadline as ErrTimeout, keeping
+// the error as its cause. Application errors and other errors are returned
+// unchanged.
+func Check(err error) error {
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if _, ok := apperror.As(err); ok {
+		return err
+	}
+	return apperror.Wrap(ErrTimeout.Code, ErrTimeout.Message, err)
+}
@@ -0,0 +1,63 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+func TestFromConfig(t *testing.T) {
+	assert.Equal(t, Deadlines{Read: 2 * time.Second, Write: 5 * time.Second}, FromConfig(config.DeadlinesConfig{}))
+	assert.Equal(t, Deadlines{Read: 300 * time.Millisecond}, FromConfig(config.DeadlinesConfig{ReadMs: 300, WriteMs: -1}))
+	assert.True(t, FromConfig(config.DeadlinesConfig{ReadMs: -1, WriteMs: -1}).IsZero())
+}
+
+func TestDeadlines(t *testing.T) {
+	d := Deadlines{Read: time.Minute}
+
+	ctx, cancel := d.ForRead(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// A zero deadline leaves the context as it is
+	parent := context.Background()
+	ctx, cancel = d.ForWrite(parent)
+	defer cancel()
+	assert.Equal(t, parent, ctx)
+
+	// An earlier deadline of the caller is kept
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Second)
+	defer cancelParent()
+	ctx, cancel = d.ForRead(parent)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(nil))
+
+	other := errors.New("db error")
+	assert.Equal(t, other, Check(other))
+
+	notFound := fmt.Errorf("%w: %w", apperror.New(apperror.CodeUserNotFound, "user not found"), context.DeadlineExceeded)
+	assert.Equal(t, notFound, Check(notFound), "application errors are kept")
+
+	err := Check(fmt.Errorf("failed to get user: %w", context.DeadlineExceeded))
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the cause is kept")
+	assert.Equal(t, http.StatusGatewayTimeout, apperror.HTTPStatus(apperror.CodeOf(err)))
+	assert.Equal(t, codes.DeadlineExceeded, apperror.GRPCCode(apperror.CodeOf(err)))
+}
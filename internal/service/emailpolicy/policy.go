@@ -0,0 +1,163 @@
+package emailpolicy
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// Errors returned for email addresses a policy rejects
+var (
+	ErrDomainNotAllowed = apperror.New(apperror.CodeEmailPolicyViolation, "email addresses of this domain may not sign up")
+	ErrDisposableEmail  = apperror.New(apperror.CodeEmailPolicyViolation, "disposable email addresses may not sign up")
+)
+
+//go:embed disposable_domains.txt
+var disposableList string
+
+// disposableDomains holds the domains of known disposable email services
+var disposableDomains = parseDomainList(disposableList)
+
+// Rules applies the allow and deny lists of config.EmailDomainRules to
+// every tenant alike. A nil Rules accepts every address.
+type Rules struct {
+	allow           map[string]bool
+	deny            map[string]bool
+	blockDisposable bool
+}
+
+// NewRules creates Rules from configuration. It returns nil when the rules
+// accept every address.
+func NewRules(cfg config.EmailDomainRules) (*Rules, error) {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 && !cfg.BlockDisposable {
+		return nil, nil
+	}
+	allow, err := domainSet(cfg.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed email domains: %w", err)
+	}
+	deny, err := domainSet(cfg.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denied email domains: %w", err)
+	}
+	return &Rules{allow: allow, deny: deny, blockDisposable: cfg.BlockDisposable}, nil
+}
+
+// CheckEmail implements domainUser.EmailPolicy. Denied domains win over
+// allowed ones, and an empty allow list allows every other domain.
+func (r *Rules) CheckEmail(_, email string) error {
+	if r == nil {
+		return nil
+	}
+	domain := domainOf(email)
+	if matches(r.deny, domain) {
+		return ErrDomainNotAllowed
+	}
+	if r.blockDisposable && matches(disposableDomains, domain) {
+		return ErrDisposableEmail
+	}
+	if len(r.allow) > 0 && !matches(r.allow, domain) {
+		return ErrDomainNotAllowed
+	}
+	return nil
+}
+
+// Policy checks addresses against global rules and the policy of their
+// tenant, if it has one
+type Policy struct {
+	global  domainUser.EmailPolicy
+	tenants map[string]domainUser.EmailPolicy
+}
+
+// New creates a Policy applying global to every address and tenants to the
+// addresses of the named tenants. Any EmailPolicy can be plugged in for a
+// tenant; a nil global policy accepts every address.
+func New(global domainUser.EmailPolicy, tenants map[string]domainUser.EmailPolicy) *Policy {
+	return &Policy{global: global, tenants: tenants}
+}
+
+// FromConfig creates the Policy of the registration.email_domains configuration
+func FromConfig(cfg config.EmailDomainsConfig) (*Policy, error) {
+	global, err := NewRules(cfg.Global)
+	if err != nil {
+		return nil, fmt.Errorf("registration.email_domains.global: %w", err)
+	}
+	tenants := make(map[string]domainUser.EmailPolicy, len(cfg.Tenants))
+	for tenant, tenantRules := range cfg.Tenants {
+		rules, err := NewRules(tenantRules)
+		if err != nil {
+			return nil, fmt.Errorf("registration.email_domains.tenants.%s: %w", tenant, err)
+		}
+		if rules != nil {
+			tenants[tenant] = rules
+		}
+	}
+	return New(global, tenants), nil
+}
+
+// CheckEmail implements domainUser.EmailPolicy
+func (p *Policy) CheckEmail(tenant, email string) error {
+	if p.global != nil {
+		if err := p.global.CheckEmail(tenant, email); err != nil {
+			return err
+		}
+	}
+	if policy, ok := p.tenants[tenant]; ok {
+		return policy.CheckEmail(tenant, email)
+	}
+	return nil
+}
+
+// domainOf returns the lower-case domain of an email address
+func domainOf(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	return strings.TrimSuffix(strings.ToLower(email[at+1:]), ".")
+}
+
+// matches reports whether domain or one of its parent domains is in set
+func matches(set map[string]bool, domain string) bool {
+	for domain != "" {
+		if set[domain] {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return false
+		}
+		domain = parent
+	}
+	return false
+}
+
+// domainSet normalizes a list of configured domains
+func domainSet(domains []string) (map[string]bool, error) {
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		normalized := strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if normalized == "" || strings.ContainsAny(normalized, "@/ \t") {
+			return nil, fmt.Errorf("%q is not a domain", domain)
+		}
+		set[normalized] = true
+	}
+	return set, nil
+}
+
+// parseDomainList reads one domain per line, skipping blank lines and
+// # comments
+func parseDomainList(list string) map[string]bool {
+	set := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = true
+	}
+	return set
+}
@@ -0,0 +1,123 @@
+package emailpolicy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+func TestNewRules(t *testing.T) {
+	t.Run("No Rules", func(t *testing.T) {
+		rules, err := NewRules(config.EmailDomainRules{})
+		require.NoError(t, err)
+		assert.Nil(t, rules)
+		assert.NoError(t, rules.CheckEmail("", "anyone@mailinator.com"))
+	})
+
+	t.Run("Invalid Domain", func(t *testing.T) {
+		_, err := NewRules(config.EmailDomainRules{Allow: []string{"user@example.com"}})
+		assert.Error(t, err)
+		_, err = NewRules(config.EmailDomainRules{Deny: []string{" "}})
+		assert.Error(t, err)
+	})
+}
+
+func TestRules_CheckEmail(t *testing.T) {
+	rules, err := NewRules(config.EmailDomainRules{
+		Allow:           []string{"Example.com", "partner.org"},
+		Deny:            []string{"legacy.example.com"},
+		BlockDisposable: true,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		email string
+		want  error
+	}{
+		{"jane@example.com", nil},
+		{"jane@EXAMPLE.COM", nil},
+		{"jane@eu.example.com", nil},
+		{"jane@partner.org", nil},
+		{"jane@legacy.example.com", ErrDomainNotAllowed},
+		{"jane@mail.legacy.example.com", ErrDomainNotAllowed},
+		{"jane@notexample.com", ErrDomainNotAllowed},
+		{"jane@gmail.com", ErrDomainNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := rules.CheckEmail("", tt.email)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Same(t, tt.want, err)
+		})
+	}
+}
+
+func TestRules_BlockDisposable(t *testing.T) {
+	rules, err := NewRules(config.EmailDomainRules{BlockDisposable: true})
+	require.NoError(t, err)
+
+	assert.Same(t, ErrDisposableEmail, rules.CheckEmail("", "bot@mailinator.com"))
+	assert.Same(t, ErrDisposableEmail, rules.CheckEmail("", "bot@inbox.Yopmail.com"))
+	assert.NoError(t, rules.CheckEmail("", "jane@example.com"))
+	assert.Equal(t, apperror.CodeEmailPolicyViolation, apperror.CodeOf(ErrDisposableEmail))
+}
+
+func TestDisposableDomains(t *testing.T) {
+	assert.True(t, disposableDomains["mailinator.com"])
+	assert.NotContains(t, disposableDomains, "", "blank lines are skipped")
+	for domain := range disposableDomains {
+		assert.NotContains(t, domain, "#")
+	}
+}
+
+// tenantPolicy is a custom policy plugged in for a tenant
+type tenantPolicy struct{ calls []string }
+
+func (p *tenantPolicy) CheckEmail(tenant, email string) error {
+	p.calls = append(p.calls, tenant+":"+email)
+	return errors.New("rejected by tenant")
+}
+
+func TestPolicy_CheckEmail(t *testing.T) {
+	global, err := NewRules(config.EmailDomainRules{Deny: []string{"competitor.com"}})
+	require.NoError(t, err)
+	custom := &tenantPolicy{}
+	policy := New(global, map[string]domainUser.EmailPolicy{"acme": custom})
+
+	assert.NoError(t, policy.CheckEmail("", "jane@example.com"))
+	assert.NoError(t, policy.CheckEmail("globex", "jane@example.com"))
+	assert.ErrorIs(t, policy.CheckEmail("", "spy@competitor.com"), ErrDomainNotAllowed)
+	assert.ErrorIs(t, policy.CheckEmail("acme", "spy@competitor.com"), ErrDomainNotAllowed, "global rules apply to every tenant")
+	assert.EqualError(t, policy.CheckEmail("acme", "jane@example.com"), "rejected by tenant")
+	assert.Equal(t, []string{"acme:jane@example.com"}, custom.calls)
+}
+
+func TestFromConfig(t *testing.T) {
+	policy, err := FromConfig(config.EmailDomainsConfig{
+		Global: config.EmailDomainRules{BlockDisposable: true},
+		Tenants: map[string]config.EmailDomainRules{
+			"acme":   {Allow: []string{"acme.com"}},
+			"globex": {},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, policy.CheckEmail("", "bot@mailinator.com"), ErrDisposableEmail)
+	assert.NoError(t, policy.CheckEmail("", "jane@example.com"))
+	assert.NoError(t, policy.CheckEmail("acme", "jane@acme.com"))
+	assert.ErrorIs(t, policy.CheckEmail("acme", "jane@example.com"), ErrDomainNotAllowed)
+	assert.NoError(t, policy.CheckEmail("globex", "jane@example.com"))
+	assert.NotContains(t, policy.tenants, "globex", "tenants without rules are skipped")
+
+	_, err = FromConfig(config.EmailDomainsConfig{Tenants: map[string]config.EmailDomainRules{"acme": {Deny: []string{"@"}}}})
+	assert.ErrorContains(t, err, "registration.email_domains.tenants.acme")
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics counts the runs of the housekeeping tasks and the rows they delete
+type Metrics struct {
+	deleted *prometheus.CounterVec
+	runs    *prometheus.CounterVec
+}
+
+// NewMetrics creates housekeeping metrics and registers them
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "maintenance_rows_deleted_total",
+			Help: "Total number of expired or orphaned rows and keys deleted by a housekeeping task.",
+		}, []string{"task"}),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "maintenance_runs_total",
+			Help: "Total number of housekeeping task runs by outcome (success or error).",
+		}, []string{"task", "outcome"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.deleted, m.runs} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// record counts a run of task; a nil Metrics records nothing
+func (m *Metrics) record(task string, deleted int64, err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.runs.WithLabelValues(task, "error").Inc()
+		return
+	}
+	m.runs.WithLabelValues(task, "success").Inc()
+	m.deleted.WithLabelValues(task).Add(float64(deleted))
+}
@@ -0,0 +1,106 @@
+// Package maintenance holds the housekeeping jobs run by cmd/worker
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// Job types of the housekeeping jobs; none takes a payload
+const (
+	SessionCleanupJob      = "sessions.cleanup"
+	RefreshTokenCleanupJob = "tokens.cleanup"
+	AuditPruneJob          = "audit.prune"
+)
+
+// Tasks deletes data the service no longer needs
+type Tasks struct {
+	sessions       domainAuth.SessionRepository
+	tokens         domainAuth.AuthRepository
+	audit          domainAudit.Repository
+	auditRetention time.Duration // Zero keeps the audit log forever
+	metrics        *Metrics      // Optional
+	logger         *zap.Logger
+	now            func() time.Time
+}
+
+// NewTasks creates the housekeeping tasks, keeping audit log entries for
+// auditRetention; zero keeps them forever. metrics may be nil.
+func NewTasks(sessions domainAuth.SessionRepository, tokens domainAuth.AuthRepository, audit domainAudit.Repository, auditRetention time.Duration, metrics *Metrics, logger *zap.Logger) *Tasks {
+	return &Tasks{
+		sessions:       sessions,
+		tokens:         tokens,
+		audit:          audit,
+		auditRetention: auditRetention,
+		metrics:        metrics,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Register adds the housekeeping jobs to w
+func (t *Tasks) Register(w *jobs.Worker) {
+	w.Register(SessionCleanupJob, t.CleanupSessions)
+	w.Register(RefreshTokenCleanupJob, t.CleanupRefreshTokens)
+	w.Register(AuditPruneJob, t.PruneAuditLog)
+}
+
+// Schedule enqueues the housekeeping jobs with s at the intervals of cfg.
+// The audit log is only pruned when a retention period is configured.
+func (t *Tasks) Schedule(s *jobs.Scheduler, cfg config.ScheduleConfig) {
+	s.Every(SessionCleanupJob, cfg.SessionCleanup())
+	s.Every(RefreshTokenCleanupJob, cfg.RefreshTokenCleanup())
+	if t.auditRetention > 0 {
+		s.Every(AuditPruneJob, cfg.AuditPrune())
+	}
+}
+
+// CleanupSessions removes the expired sessions of every user. Listing
+// sessions prunes those of one user lazily; this catches users who never
+// list them again.
+func (t *Tasks) CleanupSessions(ctx context.Context, _ *jobs.Job) error {
+	pruned, err := t.sessions.PruneExpired(ctx)
+	t.metrics.record(SessionCleanupJob, pruned, err)
+	if err != nil {
+		return fmt.Errorf("failed to prune expired sessions: %w", err)
+	}
+	t.logger.Info("Pruned expired sessions", zap.Int64("deleted", pruned))
+	return nil
+}
+
+// CleanupRefreshTokens removes the RefreshToken -> UserID mappings left
+// behind by tokens that were replaced or revoked. Tokens a live session
+// holds, such as those of the other devices of a user, are kept. Expired
+// tokens need no cleanup since Redis expires both mappings with the token.
+func (t *Tasks) CleanupRefreshTokens(ctx context.Context, _ *jobs.Job) error {
+	pruned, err := t.tokens.PruneOrphanedRefreshTokens(ctx)
+	t.metrics.record(RefreshTokenCleanupJob, pruned, err)
+	if err != nil {
+		return fmt.Errorf("failed to prune orphaned refresh tokens: %w", err)
+	}
+	t.logger.Info("Pruned orphaned refresh tokens", zap.Int64("deleted", pruned))
+	return nil
+}
+
+// PruneAuditLog deletes the audit log entries older than the retention
+// period, if one is configured
+func (t *Tasks) PruneAuditLog(ctx context.Context, _ *jobs.Job) error {
+	if t.auditRetention <= 0 {
+		return nil
+	}
+	deleted, err := t.audit.Prune(ctx, t.now().Add(-t.auditRetention))
+	t.metrics.record(AuditPruneJob, deleted, err)
+	if err != nil {
+		return fmt.Errorf("failed to prune audit log: %w", err)
+	}
+	t.logger.Info("Pruned audit log", zap.Int64("deleted", deleted))
+	return nil
+}
@@ -0,0 +1,145 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+type MockSessionRepository struct {
+	domainAuth.SessionRepository
+	mock.Mock
+}
+
+func (m *MockSessionRepository) PruneExpired(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockAuthRepository struct {
+	domainAuth.AuthRepository
+	mock.Mock
+}
+
+func (m *MockAuthRepository) PruneOrphanedRefreshTokens(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockAuditRepository struct {
+	domainAudit.Repository
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestCleanupSessions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		sessions := new(MockSessionRepository)
+		sessions.On("PruneExpired", ctx).Return(int64(3), nil).Once()
+
+		err := NewTasks(sessions, nil, nil, 0, nil, zap.NewNop()).CleanupSessions(ctx, &jobs.Job{})
+
+		assert.NoError(t, err)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("Redis Error", func(t *testing.T) {
+		sessions := new(MockSessionRepository)
+		sessions.On("PruneExpired", ctx).Return(int64(0), errors.New("connection refused")).Once()
+
+		err := NewTasks(sessions, nil, nil, 0, nil, zap.NewNop()).CleanupSessions(ctx, &jobs.Job{})
+
+		assert.ErrorContains(t, err, "failed to prune expired sessions")
+	})
+}
+
+func TestCleanupRefreshTokens(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Records Deleted Mappings", func(t *testing.T) {
+		tokens := new(MockAuthRepository)
+		tokens.On("PruneOrphanedRefreshTokens", ctx).Return(int64(4), nil).Twice()
+		metrics, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+		tasks := NewTasks(nil, tokens, nil, 0, metrics, zap.NewNop())
+
+		assert.NoError(t, tasks.CleanupRefreshTokens(ctx, &jobs.Job{}))
+		assert.NoError(t, tasks.CleanupRefreshTokens(ctx, &jobs.Job{}))
+
+		assert.Equal(t, 8.0, testutil.ToFloat64(metrics.deleted.WithLabelValues(RefreshTokenCleanupJob)))
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.runs.WithLabelValues(RefreshTokenCleanupJob, "success")))
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("Redis Error", func(t *testing.T) {
+		tokens := new(MockAuthRepository)
+		tokens.On("PruneOrphanedRefreshTokens", ctx).Return(int64(0), errors.New("connection refused")).Once()
+		metrics, err := NewMetrics(prometheus.NewRegistry())
+		require.NoError(t, err)
+
+		err = NewTasks(nil, tokens, nil, 0, metrics, zap.NewNop()).CleanupRefreshTokens(ctx, &jobs.Job{})
+
+		assert.ErrorContains(t, err, "failed to prune orphaned refresh tokens")
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.runs.WithLabelValues(RefreshTokenCleanupJob, "error")))
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	cfg := config.ScheduleConfig{SessionCleanupMinutes: 15, RefreshTokenCleanupMinutes: -1}
+
+	t.Run("Skips Disabled Jobs", func(t *testing.T) {
+		s := jobs.NewScheduler(nil, nil, zap.NewNop())
+		NewTasks(nil, nil, nil, 0, nil, zap.NewNop()).Schedule(s, cfg)
+
+		assert.Equal(t, map[string]time.Duration{SessionCleanupJob: 15 * time.Minute}, s.Scheduled())
+	})
+
+	t.Run("Prunes Audit Log With Retention", func(t *testing.T) {
+		s := jobs.NewScheduler(nil, nil, zap.NewNop())
+		NewTasks(nil, nil, nil, 30*24*time.Hour, nil, zap.NewNop()).Schedule(s, cfg)
+
+		assert.Equal(t, 24*time.Hour, s.Scheduled()[AuditPruneJob])
+	})
+}
+
+func TestPruneAuditLog(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Deletes Entries Past Retention", func(t *testing.T) {
+		audit := new(MockAuditRepository)
+		audit.On("Prune", ctx, now.Add(-30*24*time.Hour)).Return(int64(12), nil).Once()
+		tasks := NewTasks(nil, nil, audit, 30*24*time.Hour, nil, zap.NewNop())
+		tasks.now = func() time.Time { return now }
+
+		assert.NoError(t, tasks.PruneAuditLog(ctx, &jobs.Job{}))
+		audit.AssertExpectations(t)
+	})
+
+	t.Run("Keeps Everything Without Retention", func(t *testing.T) {
+		audit := new(MockAuditRepository)
+
+		assert.NoError(t, NewTasks(nil, nil, audit, 0, nil, zap.NewNop()).PruneAuditLog(ctx, &jobs.Job{}))
+		audit.AssertNotCalled(t, "Prune", mock.Anything, mock.Anything)
+	})
+}
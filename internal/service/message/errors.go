@@ -0,0 +1,13 @@
+package message
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for system message operations
+var (
+	ErrMessageNotFound  = apperror.New(apperror.CodeMessageNotFound, "system message not found")
+	ErrTitleRequired    = apperror.New(apperror.CodeInvalidArgument, "title is required")
+	ErrInvalidSeverity  = apperror.New(apperror.CodeInvalidArgument, "severity must be one of info, warning, critical")
+	ErrUnknownRole      = apperror.New(apperror.CodeInvalidArgument, "roles must be known role names")
+	ErrInvalidTenant    = apperror.New(apperror.CodeInvalidArgument, "tenants must be non-empty and must not contain commas")
+	ErrInvalidTimeRange = apperror.New(apperror.CodeInvalidArgument, "ends_at must be after starts_at")
+)
@@ -0,0 +1,178 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// MessageService defines the interface for system message business logic
+type MessageService interface {
+	// ListForAudience returns the active messages targeted at the audience, newest first
+	ListForAudience(ctx context.Context, audience domainMessage.Audience) ([]*domainMessage.SystemMessage, error)
+
+	// List returns every message regardless of schedule or audience, for administrators
+	List(ctx context.Context) ([]*domainMessage.SystemMessage, error)
+
+	// Create publishes a new message on behalf of an administrator
+	Create(ctx context.Context, actorID uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error)
+
+	// Update replaces the editable fields of a message
+	Update(ctx context.Context, id uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error)
+
+	// Delete removes a message
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type messageService struct {
+	repo domainMessage.Repository
+	ids  idgen.Generator
+	now  func() time.Time
+}
+
+// NewMessageService creates a new instance of MessageService
+func NewMessageService(repo domainMessage.Repository, ids idgen.Generator) MessageService {
+	return &messageService{repo: repo, ids: ids, now: time.Now}
+}
+
+func (s *messageService) ListForAudience(ctx context.Context, audience domainMessage.Audience) ([]*domainMessage.SystemMessage, error) {
+	active, err := s.repo.ListActive(ctx, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active system messages: %w", err)
+	}
+
+	visible := make([]*domainMessage.SystemMessage, 0, len(active))
+	for _, m := range active {
+		if m.VisibleTo(audience) {
+			visible = append(visible, m)
+		}
+	}
+	return visible, nil
+}
+
+func (s *messageService) List(ctx context.Context) ([]*domainMessage.SystemMessage, error) {
+	messages, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list system messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (s *messageService) Create(ctx context.Context, actorID uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error) {
+	input, err := normalizeInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate system message id: %w", err)
+	}
+
+	now := s.now()
+	message := &domainMessage.SystemMessage{ID: id, CreatedBy: actorID, CreatedAt: now, UpdatedAt: now}
+	apply(message, input)
+
+	if err := s.repo.Create(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to create system message: %w", err)
+	}
+	return message, nil
+}
+
+func (s *messageService) Update(ctx context.Context, id uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error) {
+	input, err := normalizeInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system message for update: %w", err)
+	}
+	if message == nil {
+		return nil, ErrMessageNotFound
+	}
+
+	apply(message, input)
+	message.UpdatedAt = s.now()
+
+	if err := s.repo.Update(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to update system message: %w", err)
+	}
+	return message, nil
+}
+
+func (s *messageService) Delete(ctx context.Context, id uuid.UUID) error {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get system message for delete: %w", err)
+	}
+	if message == nil {
+		return ErrMessageNotFound
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete system message: %w", err)
+	}
+	return nil
+}
+
+// normalizeInput validates the input and fills in defaults
+func normalizeInput(input domainMessage.Input) (domainMessage.Input, error) {
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" {
+		return input, ErrTitleRequired
+	}
+
+	if input.Severity == "" {
+		input.Severity = domainMessage.SeverityInfo
+	}
+	if !input.Severity.Valid() {
+		return input, ErrInvalidSeverity
+	}
+
+	for _, role := range input.Roles {
+		if !knownRole(role) {
+			return input, ErrUnknownRole
+		}
+	}
+
+	// Tenants are stored comma-separated
+	for _, tenant := range input.Tenants {
+		if strings.TrimSpace(tenant) == "" || strings.Contains(tenant, ",") {
+			return input, ErrInvalidTenant
+		}
+	}
+
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return input, ErrInvalidTimeRange
+	}
+	return input, nil
+}
+
+func knownRole(role domainRBAC.Role) bool {
+	for _, def := range domainRBAC.Roles {
+		if def.Name == role {
+			return true
+		}
+	}
+	return false
+}
+
+// apply copies the editable fields from input onto message
+func apply(message *domainMessage.SystemMessage, input domainMessage.Input) {
+	message.Title = input.Title
+	message.Body = input.Body
+	message.Severity = input.Severity
+	message.Roles = input.Roles
+	message.Tenants = input.Tenants
+	message.StartsAt = input.StartsAt
+	message.EndsAt = input.EndsAt
+}
@@ -0,0 +1,213 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// MockMessageRepository is a mock implementation of the domainMessage.Repository interface
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) Create(ctx context.Context, message *domainMessage.SystemMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageRepository) Update(ctx context.Context, message *domainMessage.SystemMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) List(ctx context.Context) ([]*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListActive(ctx context.Context, now time.Time) ([]*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainMessage.SystemMessage), args.Error(1)
+}
+
+var testNow = time.Date(2025, 6, 25, 9, 0, 0, 0, time.UTC)
+
+func newTestService(repo *MockMessageRepository) *messageService {
+	s := NewMessageService(repo, idgen.GeneratorFunc(uuid.NewRandom)).(*messageService)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestListForAudience(t *testing.T) {
+	ctx := context.Background()
+
+	everyone := &domainMessage.SystemMessage{Title: "Maintenance tonight"}
+	adminsOnly := &domainMessage.SystemMessage{Title: "New admin console", Roles: []domainRBAC.Role{domainRBAC.RoleAdmin}}
+	usersOnly := &domainMessage.SystemMessage{Title: "Updated terms", Roles: []domainRBAC.Role{domainRBAC.RoleUser}}
+	acmeOnly := &domainMessage.SystemMessage{Title: "Acme migration", Tenants: []string{"acme"}}
+	active := []*domainMessage.SystemMessage{everyone, adminsOnly, usersOnly, acmeOnly}
+
+	tests := []struct {
+		name     string
+		audience domainMessage.Audience
+		want     []*domainMessage.SystemMessage
+	}{
+		{
+			name:     "Anonymous",
+			audience: domainMessage.Audience{},
+			want:     []*domainMessage.SystemMessage{everyone},
+		},
+		{
+			name:     "Regular User",
+			audience: domainMessage.Audience{Role: domainRBAC.RoleUser},
+			want:     []*domainMessage.SystemMessage{everyone, usersOnly},
+		},
+		{
+			name:     "Admin In Tenant",
+			audience: domainMessage.Audience{Role: domainRBAC.RoleAdmin, Tenant: "acme"},
+			want:     []*domainMessage.SystemMessage{everyone, adminsOnly, acmeOnly},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMessageRepository)
+			repo.On("ListActive", ctx, testNow).Return(active, nil).Once()
+
+			got, err := newTestService(repo).ListForAudience(ctx, tt.audience)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	startsAt := testNow.Add(time.Hour)
+	endsAt := testNow.Add(3 * time.Hour)
+
+	t.Run("Success With Defaults", func(t *testing.T) {
+		repo := new(MockMessageRepository)
+		repo.On("Create", ctx, mock.AnythingOfType("*message.SystemMessage")).Return(nil).Once()
+
+		msg, err := newTestService(repo).Create(ctx, actorID, domainMessage.Input{
+			Title:    "  Scheduled maintenance ",
+			Body:     "The service will be read-only for 30 minutes.",
+			StartsAt: &startsAt,
+			EndsAt:   &endsAt,
+		})
+
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, msg.ID)
+		assert.Equal(t, "Scheduled maintenance", msg.Title)
+		assert.Equal(t, domainMessage.SeverityInfo, msg.Severity)
+		assert.Equal(t, actorID, msg.CreatedBy)
+		assert.Equal(t, testNow, msg.CreatedAt)
+		repo.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name  string
+		input domainMessage.Input
+		err   error
+	}{
+		{"Missing Title", domainMessage.Input{Title: " "}, ErrTitleRequired},
+		{"Unknown Severity", domainMessage.Input{Title: "x", Severity: "urgent"}, ErrInvalidSeverity},
+		{"Unknown Role", domainMessage.Input{Title: "x", Roles: []domainRBAC.Role{"superuser"}}, ErrUnknownRole},
+		{"Tenant With Comma", domainMessage.Input{Title: "x", Tenants: []string{"acme,globex"}}, ErrInvalidTenant},
+		{"Blank Tenant", domainMessage.Input{Title: "x", Tenants: []string{" "}}, ErrInvalidTenant},
+		{"Inverted Window", domainMessage.Input{Title: "x", StartsAt: &endsAt, EndsAt: &startsAt}, ErrInvalidTimeRange},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMessageRepository)
+
+			msg, err := newTestService(repo).Create(ctx, actorID, tt.input)
+
+			assert.Nil(t, msg)
+			assert.True(t, errors.Is(err, tt.err))
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("Update Success", func(t *testing.T) {
+		repo := new(MockMessageRepository)
+		existing := &domainMessage.SystemMessage{ID: id, Title: "Old", Severity: domainMessage.SeverityInfo}
+		repo.On("GetByID", ctx, id).Return(existing, nil).Once()
+		repo.On("Update", ctx, mock.MatchedBy(func(m *domainMessage.SystemMessage) bool {
+			return m.ID == id && m.Title == "New" && m.Severity == domainMessage.SeverityWarning
+		})).Return(nil).Once()
+
+		msg, err := newTestService(repo).Update(ctx, id, domainMessage.Input{Title: "New", Severity: domainMessage.SeverityWarning})
+
+		require.NoError(t, err)
+		assert.Equal(t, testNow, msg.UpdatedAt)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Update Not Found", func(t *testing.T) {
+		repo := new(MockMessageRepository)
+		repo.On("GetByID", ctx, id).Return(nil, nil).Once()
+
+		_, err := newTestService(repo).Update(ctx, id, domainMessage.Input{Title: "New"})
+
+		assert.True(t, errors.Is(err, ErrMessageNotFound))
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		repo := new(MockMessageRepository)
+		repo.On("GetByID", ctx, id).Return(nil, nil).Once()
+
+		err := newTestService(repo).Delete(ctx, id)
+
+		assert.True(t, errors.Is(err, ErrMessageNotFound))
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Delete Success", func(t *testing.T) {
+		repo := new(MockMessageRepository)
+		repo.On("GetByID", ctx, id).Return(&domainMessage.SystemMessage{ID: id}, nil).Once()
+		repo.On("Delete", ctx, id).Return(nil).Once()
+
+		assert.NoError(t, newTestService(repo).Delete(ctx, id))
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// SendJob is the type of the jobs delivering a notification through one channel
+const SendJob = "notification.send"
+
+// sendPayload is the payload of a SendJob
+type sendPayload struct {
+	Kind    domainNotification.Kind    `json:"kind"`
+	Channel domainNotification.Channel `json:"channel"`
+	UserID  uuid.UUID                  `json:"user_id"`
+	Email   string                     `json:"email"`
+	Name    string                     `json:"name,omitempty"`
+}
+
+// Enqueuer adds jobs to the background job queue. *jobs.Queue satisfies it.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload any) (*jobs.Job, error)
+}
+
+// JobNotifier is a domainNotification.Notifier that hands notifications to
+// cmd/worker, enqueueing a SendJob for each channel, so they survive a
+// restart of the server
+type JobNotifier struct {
+	queue    Enqueuer
+	channels []domainNotification.Channel
+	logger   *zap.Logger
+}
+
+// NewJobNotifier creates a notifier enqueueing a job for the channel of
+// every provider
+func NewJobNotifier(queue Enqueuer, providers []Provider, logger *zap.Logger) *JobNotifier {
+	channels := make([]domainNotification.Channel, 0, len(providers))
+	for _, p := range providers {
+		channels = append(channels, p.Channel())
+	}
+	return &JobNotifier{queue: queue, channels: channels, logger: logger}
+}
+
+// Notify enqueues the notification; a notification that cannot be enqueued is
+// logged and dropped
+func (n *JobNotifier) Notify(ctx context.Context, kind domainNotification.Kind, recipient domainNotification.Recipient) {
+	for _, channel := range n.channels {
+		payload := sendPayload{
+			Kind:    kind,
+			Channel: channel,
+			UserID:  recipient.UserID,
+			Email:   recipient.Email,
+			Name:    recipient.Name,
+		}
+		if _, err := n.queue.Enqueue(ctx, SendJob, payload); err != nil {
+			n.logger.Error("Failed to enqueue notification",
+				zap.String("kind", string(kind)),
+				zap.String("channel", string(channel)),
+				zap.String("user_id", recipient.UserID.String()),
+				zap.Error(err))
+		}
+	}
+}
+
+// HandleSendJob delivers a SendJob. The job queue retries failed sends in
+// place of the in-process queue; once the last attempt fails the
+// notification is dead-lettered like those sent in process.
+func (s *Service) HandleSendJob(ctx context.Context, job *jobs.Job) error {
+	var payload sendPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	provider := s.provider(payload.Channel)
+	if provider == nil {
+		return jobs.Permanent(errNoProvider(payload.Channel))
+	}
+
+	recipient := domainNotification.Recipient{UserID: payload.UserID, Email: payload.Email, Name: payload.Name}
+	subject, body, err := render(payload.Kind, recipient)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	n := &domainNotification.Notification{
+		ID:        job.ID,
+		Kind:      payload.Kind,
+		Recipient: recipient,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: job.EnqueuedAt,
+	}
+
+	err = provider.Send(ctx, n)
+	if err == nil {
+		return nil
+	}
+	if IsPermanent(err) || job.LastAttempt() {
+		s.deadLetter(ctx, delivery{notification: n, provider: provider}, job.Attempt, err)
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// provider returns the provider of channel, or nil when none is configured
+func (s *Service) provider(channel domainNotification.Channel) Provider {
+	for _, p := range s.providers {
+		if p.Channel() == channel {
+			return p
+		}
+	}
+	return nil
+}
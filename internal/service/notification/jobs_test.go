@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// fakeEnqueuer records the jobs it was given
+type fakeEnqueuer struct {
+	jobs []*jobs.Job
+	err  error
+}
+
+func (q *fakeEnqueuer) Enqueue(_ context.Context, jobType string, payload any) (*jobs.Job, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &jobs.Job{ID: uuid.New(), Type: jobType, Payload: data, MaxAttempts: 3}
+	q.jobs = append(q.jobs, job)
+	return job, nil
+}
+
+func TestJobNotifier(t *testing.T) {
+	t.Run("Enqueues A Job Per Channel", func(t *testing.T) {
+		queue := &fakeEnqueuer{}
+		providers := []Provider{NewSMTPProvider(config.SMTPConfig{Host: "mail.example.com"}), newFakeProvider(0, nil)}
+		n := NewJobNotifier(queue, providers, zap.NewNop())
+
+		n.Notify(context.Background(), domainNotification.KindPasswordChanged, testRecipient)
+
+		require.Len(t, queue.jobs, 2)
+		var payload sendPayload
+		require.NoError(t, queue.jobs[0].Decode(&payload))
+		assert.Equal(t, SendJob, queue.jobs[0].Type)
+		assert.Equal(t, domainNotification.ChannelEmail, payload.Channel)
+		assert.Equal(t, domainNotification.KindPasswordChanged, payload.Kind)
+		assert.Equal(t, testRecipient.UserID, payload.UserID)
+		assert.Equal(t, testRecipient.Email, payload.Email)
+		require.NoError(t, queue.jobs[1].Decode(&payload))
+		assert.Equal(t, domainNotification.ChannelWebhook, payload.Channel)
+	})
+
+	t.Run("Enqueue Failure Is Dropped", func(t *testing.T) {
+		queue := &fakeEnqueuer{err: errors.New("connection refused")}
+		n := NewJobNotifier(queue, []Provider{newFakeProvider(0, nil)}, zap.NewNop())
+
+		assert.NotPanics(t, func() {
+			n.Notify(context.Background(), domainNotification.KindPasswordChanged, testRecipient)
+		})
+	})
+}
+
+func TestHandleSendJob(t *testing.T) {
+	ctx := context.Background()
+
+	sendJob := func(t *testing.T, channel domainNotification.Channel, attempt int) *jobs.Job {
+		queue := &fakeEnqueuer{}
+		n := &JobNotifier{queue: queue, channels: []domainNotification.Channel{channel}, logger: zap.NewNop()}
+		n.Notify(ctx, domainNotification.KindPasswordResetRequired, testRecipient)
+		require.Len(t, queue.jobs, 1)
+		job := queue.jobs[0]
+		job.Attempt = attempt
+		return job
+	}
+
+	t.Run("Delivers Notification", func(t *testing.T) {
+		provider := newFakeProvider(0, nil)
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		job := sendJob(t, domainNotification.ChannelWebhook, 1)
+
+		require.NoError(t, s.HandleSendJob(ctx, job))
+
+		require.Len(t, provider.delivered, 1)
+		n := provider.delivered[0]
+		assert.Equal(t, job.ID, n.ID)
+		assert.Equal(t, domainNotification.KindPasswordResetRequired, n.Kind)
+		assert.Equal(t, testRecipient, n.Recipient)
+		assert.NotEmpty(t, n.Subject)
+		assert.Empty(t, deadLetters.letters)
+	})
+
+	t.Run("Transient Failure Is Retried", func(t *testing.T) {
+		provider := newFakeProvider(1, errors.New("connection reset"))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+
+		err := s.HandleSendJob(ctx, sendJob(t, domainNotification.ChannelWebhook, 1))
+
+		require.Error(t, err)
+		assert.False(t, jobs.IsPermanent(err))
+		assert.Empty(t, deadLetters.letters)
+	})
+
+	t.Run("Dead Letters After Last Attempt", func(t *testing.T) {
+		provider := newFakeProvider(1, errors.New("connection reset"))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		job := sendJob(t, domainNotification.ChannelWebhook, 3)
+
+		err := s.HandleSendJob(ctx, job)
+
+		assert.True(t, jobs.IsPermanent(err))
+		require.Len(t, deadLetters.letters, 1)
+		assert.Equal(t, job.ID, deadLetters.letters[0].NotificationID)
+		assert.Equal(t, 3, deadLetters.letters[0].Attempts)
+	})
+
+	t.Run("Permanent Failure Is Dead Lettered", func(t *testing.T) {
+		provider := newFakeProvider(1, Permanent(errors.New("mailbox unavailable")))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+
+		err := s.HandleSendJob(ctx, sendJob(t, domainNotification.ChannelWebhook, 1))
+
+		assert.True(t, jobs.IsPermanent(err))
+		assert.Len(t, deadLetters.letters, 1)
+	})
+
+	t.Run("Unknown Channel Is Permanent", func(t *testing.T) {
+		s := newTestService(newFakeProvider(0, nil), newFakeDeadLetters(), 10)
+
+		err := s.HandleSendJob(ctx, sendJob(t, domainNotification.ChannelEmail, 1))
+
+		assert.True(t, jobs.IsPermanent(err))
+		assert.Contains(t, err.Error(), `no notification provider for channel "email"`)
+	})
+}
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+)
+
+// Provider delivers notifications through one channel
+type Provider interface {
+	Channel() domainNotification.Channel
+
+	// Send delivers the notification. Errors wrapped with Permanent are not
+	// retried, e.g. a recipient the channel rejects.
+	Send(ctx context.Context, n *domainNotification.Notification) error
+}
+
+// permanentError marks a send failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure that retrying cannot fix
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// NewProviders creates the providers enabled in configuration
+func NewProviders(cfg config.NotificationConfig) []Provider {
+	var providers []Provider
+	if cfg.SMTP.Host != "" {
+		providers = append(providers, NewSMTPProvider(cfg.SMTP))
+	}
+	if cfg.Webhook.URL != "" {
+		providers = append(providers, NewWebhookProvider(cfg.Webhook))
+	}
+	return providers
+}
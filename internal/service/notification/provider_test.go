@@ -0,0 +1,138 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+)
+
+func testNotification() *domainNotification.Notification {
+	return &domainNotification.Notification{
+		ID:        uuid.New(),
+		Kind:      domainNotification.KindPasswordChanged,
+		Recipient: testRecipient,
+		Subject:   "Your password was changed",
+		Body:      "Hi Jane,\nYour password was changed.\n",
+		CreatedAt: time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhookProvider(t *testing.T) {
+	t.Run("Posts Signed Payload", func(t *testing.T) {
+		var payload webhookPayload
+		var signature string
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			signature = r.Header.Get(SignatureHeader)
+			_ = json.Unmarshal(body, &payload)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		p := NewWebhookProvider(config.WebhookConfig{URL: server.URL, Secret: "secret"})
+		n := testNotification()
+		require.NoError(t, p.Send(context.Background(), n))
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature)
+		assert.Equal(t, n.ID, payload.ID)
+		assert.Equal(t, "password_changed", payload.Kind)
+		assert.Equal(t, testRecipient.UserID, payload.UserID)
+		assert.Equal(t, "jane@example.com", payload.Email)
+	})
+
+	tests := []struct {
+		name      string
+		status    int
+		permanent bool
+	}{
+		{name: "Client Error Is Permanent", status: http.StatusBadRequest, permanent: true},
+		{name: "Rate Limit Is Retried", status: http.StatusTooManyRequests},
+		{name: "Server Error Is Retried", status: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookProvider(config.WebhookConfig{URL: server.URL}).Send(context.Background(), testNotification())
+
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, IsPermanent(err))
+		})
+	}
+}
+
+func TestSMTPProvider(t *testing.T) {
+	newProvider := func(send func(string, smtp.Auth, string, []string, []byte) error) *SMTPProvider {
+		p := NewSMTPProvider(config.SMTPConfig{Host: "mail.example.com", From: "no-reply@example.com"})
+		p.send = send
+		return p
+	}
+
+	t.Run("Sends Email", func(t *testing.T) {
+		var addr string
+		var to []string
+		var msg string
+		p := newProvider(func(a string, _ smtp.Auth, _ string, recipients []string, m []byte) error {
+			addr, to, msg = a, recipients, string(m)
+			return nil
+		})
+
+		require.NoError(t, p.Send(context.Background(), testNotification()))
+
+		assert.Equal(t, "mail.example.com:587", addr)
+		assert.Equal(t, []string{"jane@example.com"}, to)
+		assert.Contains(t, msg, "From: no-reply@example.com\r\n")
+		assert.Contains(t, msg, "Subject: Your password was changed\r\n")
+		assert.Contains(t, msg, "\r\n\r\nHi Jane,\r\nYour password was changed.\r\n")
+	})
+
+	t.Run("Rejected Recipient Is Permanent", func(t *testing.T) {
+		p := newProvider(func(string, smtp.Auth, string, []string, []byte) error {
+			return &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+		})
+
+		err := p.Send(context.Background(), testNotification())
+		assert.True(t, IsPermanent(err))
+	})
+
+	t.Run("Connection Error Is Retried", func(t *testing.T) {
+		p := newProvider(func(string, smtp.Auth, string, []string, []byte) error {
+			return errors.New("connection refused")
+		})
+
+		err := p.Send(context.Background(), testNotification())
+		require.Error(t, err)
+		assert.False(t, IsPermanent(err))
+	})
+
+	t.Run("Missing Address Is Permanent", func(t *testing.T) {
+		p := newProvider(func(string, smtp.Auth, string, []string, []byte) error { return nil })
+		n := testNotification()
+		n.Recipient.Email = ""
+
+		assert.True(t, IsPermanent(p.Send(context.Background(), n)))
+	})
+}
@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// Reasons recorded on dead letters that were never handed to a provider
+var (
+	errQueueFull = errors.New("notification queue is full")
+	errShutdown  = errors.New("service shut down before the notification was sent")
+)
+
+// errNoProvider is returned for jobs naming a channel without a provider,
+// e.g. after the provider was removed from the configuration
+func errNoProvider(channel domainNotification.Channel) error {
+	return fmt.Errorf("no notification provider for channel %q", channel)
+}
+
+// delivery is a notification waiting to be sent through one provider
+type delivery struct {
+	notification *domainNotification.Notification
+	provider     Provider
+}
+
+// Service is a domainNotification.Notifier that queues notifications and
+// sends them through every provider from worker goroutines started by Run.
+// Failed sends are retried with exponential backoff; notifications that
+// still fail are stored through the dead-letter repository.
+type Service struct {
+	providers   []Provider
+	deadLetters domainNotification.DeadLetterRepository
+	queue       chan delivery
+	workers     int
+	attempts    int
+	backoff     time.Duration
+	ids         idgen.Generator
+	logger      *zap.Logger
+	now         func() time.Time
+}
+
+// NewService creates a notification service sending through providers with
+// the queue and retry settings in cfg
+func NewService(providers []Provider, deadLetters domainNotification.DeadLetterRepository, cfg config.NotificationConfig, ids idgen.Generator, logger *zap.Logger) *Service {
+	return &Service{
+		providers:   providers,
+		deadLetters: deadLetters,
+		queue:       make(chan delivery, cfg.Queue()),
+		workers:     cfg.WorkerCount(),
+		attempts:    cfg.Attempts(),
+		backoff:     cfg.Backoff(),
+		ids:         ids,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Notify renders the notification and queues it for every provider. When the
+// queue is full the notification is dead-lettered instead of blocking the
+// caller.
+func (s *Service) Notify(ctx context.Context, kind domainNotification.Kind, recipient domainNotification.Recipient) {
+	if len(s.providers) == 0 {
+		return
+	}
+
+	subject, body, err := render(kind, recipient)
+	if err != nil {
+		s.logger.Error("Failed to render notification", zap.String("kind", string(kind)), zap.Error(err))
+		return
+	}
+	id, err := s.ids.NewID()
+	if err != nil {
+		s.logger.Error("Failed to generate notification id", zap.Error(err))
+		return
+	}
+	n := &domainNotification.Notification{
+		ID:        id,
+		Kind:      kind,
+		Recipient: recipient,
+		Subject:   subject,
+		Body:      body,
+		CreatedAt: s.now(),
+	}
+
+	for _, p := range s.providers {
+		select {
+		case s.queue <- delivery{notification: n, provider: p}:
+		default:
+			s.deadLetter(ctx, delivery{notification: n, provider: p}, 0, errQueueFull)
+		}
+	}
+}
+
+// Run sends queued notifications until ctx is done. Notifications still
+// queued or being retried at that point are dead-lettered.
+func (s *Service) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-s.queue:
+					s.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		select {
+		case d := <-s.queue:
+			s.deadLetter(ctx, d, 0, errShutdown)
+		default:
+			return nil
+		}
+	}
+}
+
+// deliver sends d, retrying with exponential backoff while the send fails
+// and the failure is not permanent
+func (s *Service) deliver(ctx context.Context, d delivery) {
+	backoff := s.backoff
+	for attempt := 1; ; attempt++ {
+		err := d.provider.Send(ctx, d.notification)
+		if err == nil {
+			return
+		}
+		if IsPermanent(err) || attempt >= s.attempts {
+			s.deadLetter(ctx, d, attempt, err)
+			return
+		}
+
+		s.logger.Debug("Retrying notification",
+			zap.String("notification_id", d.notification.ID.String()),
+			zap.String("channel", string(d.provider.Channel())),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			s.deadLetter(ctx, d, attempt, err)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// deadLetter records a notification that was not delivered after attempts
+// sends. It is stored even while ctx is being cancelled for shutdown.
+func (s *Service) deadLetter(ctx context.Context, d delivery, attempts int, cause error) {
+	n := d.notification
+	fields := []zap.Field{
+		zap.String("notification_id", n.ID.String()),
+		zap.String("kind", string(n.Kind)),
+		zap.String("channel", string(d.provider.Channel())),
+		zap.String("user_id", n.Recipient.UserID.String()),
+		zap.Int("attempts", attempts),
+		zap.Error(cause),
+	}
+	s.logger.Warn("Notification not delivered", fields...)
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		s.logger.Error("Failed to generate dead letter id", append(fields, zap.NamedError("dead_letter_error", err))...)
+		return
+	}
+	letter := &domainNotification.DeadLetter{
+		ID:             id,
+		NotificationID: n.ID,
+		Kind:           n.Kind,
+		Channel:        d.provider.Channel(),
+		UserID:         n.Recipient.UserID,
+		Email:          n.Recipient.Email,
+		Subject:        n.Subject,
+		Body:           n.Body,
+		Attempts:       attempts,
+		LastError:      cause.Error(),
+		CreatedAt:      s.now(),
+	}
+	if err := s.deadLetters.Create(context.WithoutCancel(ctx), letter); err != nil {
+		s.logger.Error("Failed to store dead letter", append(fields, zap.NamedError("dead_letter_error", err))...)
+	}
+}
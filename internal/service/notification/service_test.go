@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// fakeProvider fails the first failures sends with err and records the
+// notifications it delivered
+type fakeProvider struct {
+	mu        sync.Mutex
+	failures  int
+	err       error
+	calls     int
+	delivered []*domainNotification.Notification
+	done      chan struct{}
+}
+
+func newFakeProvider(failures int, err error) *fakeProvider {
+	return &fakeProvider{failures: failures, err: err, done: make(chan struct{}, 10)}
+}
+
+func (p *fakeProvider) Channel() domainNotification.Channel {
+	return domainNotification.ChannelWebhook
+}
+
+func (p *fakeProvider) Send(_ context.Context, n *domainNotification.Notification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls <= p.failures {
+		return p.err
+	}
+	p.delivered = append(p.delivered, n)
+	p.done <- struct{}{}
+	return nil
+}
+
+// fakeDeadLetters records dead letters
+type fakeDeadLetters struct {
+	mu      sync.Mutex
+	letters []*domainNotification.DeadLetter
+	created chan struct{}
+}
+
+func newFakeDeadLetters() *fakeDeadLetters {
+	return &fakeDeadLetters{created: make(chan struct{}, 10)}
+}
+
+func (r *fakeDeadLetters) Create(_ context.Context, letter *domainNotification.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.letters = append(r.letters, letter)
+	r.created <- struct{}{}
+	return nil
+}
+
+var testRecipient = domainNotification.Recipient{UserID: uuid.New(), Email: "jane@example.com", Name: "Jane"}
+
+func newTestService(provider Provider, deadLetters *fakeDeadLetters, queueSize int) *Service {
+	cfg := config.NotificationConfig{QueueSize: queueSize, Workers: 1, RetryAttempts: 3, RetryBackoffMs: 1}
+	return NewService([]Provider{provider}, deadLetters, cfg, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+}
+
+func wait(t *testing.T, ch chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestServiceDelivery(t *testing.T) {
+	t.Run("Retries Until Delivered", func(t *testing.T) {
+		provider := newFakeProvider(2, errors.New("connection reset"))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+
+		s.Notify(ctx, domainNotification.KindPasswordChanged, testRecipient)
+		wait(t, provider.done)
+
+		require.Len(t, provider.delivered, 1)
+		n := provider.delivered[0]
+		assert.Equal(t, 3, provider.calls)
+		assert.Equal(t, domainNotification.KindPasswordChanged, n.Kind)
+		assert.Equal(t, "Your password was changed", n.Subject)
+		assert.Contains(t, n.Body, "Hi Jane,")
+		assert.Contains(t, n.Body, "jane@example.com")
+		assert.Empty(t, deadLetters.letters)
+	})
+
+	t.Run("Dead Letters After Last Attempt", func(t *testing.T) {
+		provider := newFakeProvider(10, errors.New("connection reset"))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+
+		s.Notify(ctx, domainNotification.KindPasswordResetRequired, testRecipient)
+		wait(t, deadLetters.created)
+
+		require.Len(t, deadLetters.letters, 1)
+		letter := deadLetters.letters[0]
+		assert.Equal(t, 3, letter.Attempts)
+		assert.Equal(t, "connection reset", letter.LastError)
+		assert.Equal(t, domainNotification.ChannelWebhook, letter.Channel)
+		assert.Equal(t, testRecipient.UserID, letter.UserID)
+		assert.Equal(t, "Choose a new password", letter.Subject)
+	})
+
+	t.Run("Permanent Failure Is Not Retried", func(t *testing.T) {
+		provider := newFakeProvider(10, Permanent(errors.New("mailbox unavailable")))
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+
+		s.Notify(ctx, domainNotification.KindPasswordChanged, testRecipient)
+		wait(t, deadLetters.created)
+
+		assert.Equal(t, 1, provider.calls)
+		assert.Equal(t, 1, deadLetters.letters[0].Attempts)
+	})
+
+	t.Run("Full Queue Dead Letters", func(t *testing.T) {
+		provider := newFakeProvider(0, nil)
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 1)
+		ctx := context.Background()
+
+		// No worker is running, so the second notification finds the queue full
+		s.Notify(ctx, domainNotification.KindPasswordChanged, testRecipient)
+		s.Notify(ctx, domainNotification.KindPasswordChanged, testRecipient)
+
+		require.Len(t, deadLetters.letters, 1)
+		assert.Equal(t, errQueueFull.Error(), deadLetters.letters[0].LastError)
+		assert.Equal(t, 0, deadLetters.letters[0].Attempts)
+	})
+
+	t.Run("Shutdown Dead Letters Queued Notifications", func(t *testing.T) {
+		provider := newFakeProvider(0, nil)
+		deadLetters := newFakeDeadLetters()
+		s := newTestService(provider, deadLetters, 10)
+		s.workers = 0
+		ctx, cancel := context.WithCancel(context.Background())
+
+		s.Notify(ctx, domainNotification.KindPasswordChanged, testRecipient)
+		cancel()
+		assert.NoError(t, s.Run(ctx))
+
+		require.Len(t, deadLetters.letters, 1)
+		assert.Equal(t, errShutdown.Error(), deadLetters.letters[0].LastError)
+		assert.Equal(t, 0, provider.calls)
+	})
+
+	t.Run("Without Providers Nothing Is Queued", func(t *testing.T) {
+		deadLetters := newFakeDeadLetters()
+		s := NewService(nil, deadLetters, config.NotificationConfig{QueueSize: 1}, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+
+		s.Notify(context.Background(), domainNotification.KindPasswordChanged, testRecipient)
+		s.Notify(context.Background(), domainNotification.KindPasswordChanged, testRecipient)
+
+		assert.Empty(t, s.queue)
+		assert.Empty(t, deadLetters.letters)
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Run("Falls Back To Email For Name", func(t *testing.T) {
+		_, body, err := render(domainNotification.KindPasswordChanged, domainNotification.Recipient{Email: "jane@example.com"})
+		require.NoError(t, err)
+		assert.Contains(t, body, "Hi jane@example.com,")
+	})
+
+	t.Run("Unknown Kind", func(t *testing.T) {
+		_, _, err := render("unknown", testRecipient)
+		assert.Error(t, err)
+	})
+}
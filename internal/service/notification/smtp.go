@@ -0,0 +1,81 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+)
+
+// SMTPProvider sends notifications as plain-text emails through an SMTP relay
+type SMTPProvider struct {
+	addr string
+	from string
+	auth smtp.Auth
+	// send is smtp.SendMail, replaced in tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPProvider creates a provider for the relay in cfg, authenticating
+// with PLAIN when a username is configured
+func NewSMTPProvider(cfg config.SMTPConfig) *SMTPProvider {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return &SMTPProvider{
+		addr: cfg.Addr(),
+		from: cfg.From,
+		auth: auth,
+		send: smtp.SendMail,
+	}
+}
+
+func (p *SMTPProvider) Channel() domainNotification.Channel {
+	return domainNotification.ChannelEmail
+}
+
+// Send emails the notification to the recipient. Addresses the relay rejects
+// with a 5xx reply fail permanently; smtp.SendMail does not take a context,
+// so a send in progress is not interrupted by ctx.
+func (p *SMTPProvider) Send(ctx context.Context, n *domainNotification.Notification) error {
+	if n.Recipient.Email == "" {
+		return Permanent(errors.New("recipient has no email address"))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := p.send(p.addr, p.auth, p.from, []string{n.Recipient.Email}, p.message(n))
+	if err != nil {
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return Permanent(fmt.Errorf("smtp relay rejected the email: %w", err))
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message formats the notification as an RFC 5322 email
+func (p *SMTPProvider) message(n *domainNotification.Notification) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", p.from)
+	fmt.Fprintf(&b, "To: %s\r\n", n.Recipient.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", n.CreatedAt.UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+)
+
+// message is the subject and body template of a notification kind
+type message struct {
+	subject string
+	body    *template.Template
+}
+
+// messages holds the template of every kind; the body is executed with the
+// domainNotification.Recipient
+var messages = map[domainNotification.Kind]message{
+	domainNotification.KindPasswordResetRequired: {
+		subject: "Choose a new password",
+		body: template.Must(template.New("password_reset_required").Parse(`Hi {{.Name}},
+
+An administrator has asked you to choose a new password and signed you out
+of every device. You will be asked for a new password the next time you
+sign in.
+
+If you did not expect this, contact your administrator.
+`)),
+	},
+	domainNotification.KindPasswordChanged: {
+		subject: "Your password was changed",
+		body: template.Must(template.New("password_changed").Parse(`Hi {{.Name}},
+
+The password of your account {{.Email}} was just changed.
+
+If you did not change it, reset your password right away and review the
+active sessions of your account.
+`)),
+	},
+	domainNotification.KindNewDeviceLogin: {
+		subject: "New sign-in to your account",
+		body: template.Must(template.New("new_device_login").Parse(`Hi {{.Name}},
+
+Your account {{.Email}} was just signed in to from a device or network it
+has not been used from recently.
+
+If this was you, there is nothing to do. If not, change your password, sign
+out of every device and report the sign-in from the security events of your
+account.
+`)),
+	},
+}
+
+// render fills in the subject and body of a notification
+func render(kind domainNotification.Kind, recipient domainNotification.Recipient) (subject, body string, err error) {
+	m, ok := messages[kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification kind %q", kind)
+	}
+	if recipient.Name == "" {
+		recipient.Name = recipient.Email
+	}
+
+	var b strings.Builder
+	if err := m.body.Execute(&b, recipient); err != nil {
+		return "", "", fmt.Errorf("failed to render %s notification: %w", kind, err)
+	}
+	return m.subject, b.String(), nil
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the webhook body, keyed
+// with the configured secret
+const SignatureHeader = "X-Notification-Signature"
+
+// WebhookProvider posts notifications as JSON to a URL, e.g. an SMS gateway
+// or a messaging integration
+type WebhookProvider struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookProvider creates a provider posting to the URL in cfg
+func NewWebhookProvider(cfg config.WebhookConfig) *WebhookProvider {
+	return &WebhookProvider{
+		url:    cfg.URL,
+		secret: []byte(cfg.Secret),
+		client: &http.Client{Timeout: cfg.Timeout()},
+	}
+}
+
+// webhookPayload is the JSON body posted for each notification
+type webhookPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (p *WebhookProvider) Channel() domainNotification.Channel {
+	return domainNotification.ChannelWebhook
+}
+
+// Send posts the notification. 2xx responses are delivered; other 4xx
+// responses than 408 and 429 fail permanently, everything else is retried.
+func (p *WebhookProvider) Send(ctx context.Context, n *domainNotification.Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:        n.ID,
+		Kind:      string(n.Kind),
+		UserID:    n.Recipient.UserID,
+		Email:     n.Recipient.Email,
+		Name:      n.Recipient.Name,
+		Subject:   n.Subject,
+		Body:      n.Body,
+		CreatedAt: n.CreatedAt,
+	})
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to encode webhook payload: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to build webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(p.secret) > 0 {
+		mac := hmac.New(sha256.New, p.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return Permanent(fmt.Errorf("webhook rejected the notification with status %d", resp.StatusCode))
+	default:
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,78 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// deadlineService runs the operations of a Service within the read or write
+// deadline, reporting a passed deadline as deadline.ErrTimeout
+type deadlineService struct {
+	Service
+	deadlines deadline.Deadlines
+}
+
+func (s *deadlineService) Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	org, err := s.Service.Create(ctx, actorID, input)
+	return org, deadline.Check(err)
+}
+
+func (s *deadlineService) List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	orgs, err := s.Service.List(ctx, actorID)
+	return orgs, deadline.Check(err)
+}
+
+func (s *deadlineService) Get(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Organization, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	org, err := s.Service.Get(ctx, actorID, orgID)
+	return org, deadline.Check(err)
+}
+
+func (s *deadlineService) ListMembers(ctx context.Context, actorID, orgID uuid.UUID) ([]*domainOrg.Member, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	members, err := s.Service.ListMembers(ctx, actorID, orgID)
+	return members, deadline.Check(err)
+}
+
+func (s *deadlineService) Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	member, err := s.Service.Invite(ctx, actorID, orgID, email, role)
+	return member, deadline.Check(err)
+}
+
+func (s *deadlineService) ListInvitations(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Member, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	invitations, err := s.Service.ListInvitations(ctx, actorID)
+	return invitations, deadline.Check(err)
+}
+
+func (s *deadlineService) AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	member, err := s.Service.AcceptInvitation(ctx, actorID, orgID)
+	return member, deadline.Check(err)
+}
+
+func (s *deadlineService) UpdateMemberRole(ctx context.Context, actorID, orgID, userID uuid.UUID, role domainOrg.Role) (*domainOrg.Member, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	member, err := s.Service.UpdateMemberRole(ctx, actorID, orgID, userID, role)
+	return member, deadline.Check(err)
+}
+
+func (s *deadlineService) RemoveMember(ctx context.Context, actorID, orgID, userID uuid.UUID) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.Service.RemoveMember(ctx, actorID, orgID, userID))
+}
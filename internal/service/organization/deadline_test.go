@@ -0,0 +1,63 @@
+package organization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// hangingRepository is a repository whose lookups hang until the deadline of
+// the operation passes
+type hangingRepository struct {
+	*memoryRepository
+}
+
+func (r hangingRepository) GetBySlug(ctx context.Context, slug string) (*domainOrg.Organization, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r hangingRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domainOrg.Organization, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWithDeadlines(t *testing.T) {
+	ctx := context.Background()
+	newService := func(deadlines deadline.Deadlines) Service {
+		return NewService(hangingRepository{newMemoryRepository()}, userDirectory{}, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop(), WithDeadlines(deadlines))
+	}
+
+	t.Run("Read Deadline", func(t *testing.T) {
+		svc := newService(deadline.Deadlines{Read: 10 * time.Millisecond, Write: time.Minute})
+
+		start := time.Now()
+		_, err := svc.List(ctx, uuid.New())
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Write Deadline", func(t *testing.T) {
+		svc := newService(deadline.Deadlines{Read: time.Minute, Write: 10 * time.Millisecond})
+
+		_, err := svc.Create(ctx, uuid.New(), domainOrg.Input{Name: "Acme Corp."})
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Equal(t, apperror.CodeTimeout, apperror.CodeOf(err))
+	})
+
+	t.Run("No Deadlines", func(t *testing.T) {
+		assert.IsType(t, &organizationService{}, newService(deadline.Deadlines{}))
+	})
+}
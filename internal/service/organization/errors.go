@@ -0,0 +1,17 @@
+package organization
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for organization operations
+var (
+	ErrOrganizationNotFound = apperror.New(apperror.CodeOrganizationNotFound, "organization not found")
+	ErrSlugInUse            = apperror.New(apperror.CodeOrganizationSlugInUse, "organization slug already in use")
+	ErrMemberNotFound       = apperror.New(apperror.CodeMemberNotFound, "member not found")
+	ErrInvitationNotFound   = apperror.New(apperror.CodeInvitationNotFound, "invitation not found")
+	ErrAlreadyMember        = apperror.New(apperror.CodeAlreadyMember, "user is already a member or invited")
+	ErrLastOwner            = apperror.New(apperror.CodeLastOwner, "an organization must keep at least one owner")
+	ErrPermissionDenied     = apperror.New(apperror.CodePermissionDenied, "your role in the organization does not allow this")
+	ErrNameRequired         = apperror.New(apperror.CodeInvalidArgument, "name is required and must be at most 100 characters")
+	ErrInvalidSlug          = apperror.New(apperror.CodeInvalidArgument, "slug must be 3 to 64 lowercase letters, digits and single hyphens")
+	ErrInvalidRole          = apperror.New(apperror.CodeInvalidArgument, "role must be owner, admin or member")
+)
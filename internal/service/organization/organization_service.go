@@ -0,0 +1,373 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// maxNameLen is the longest organization name accepted
+const maxNameLen = 100
+
+// Slug length bounds
+const (
+	minSlugLen = 3
+	maxSlugLen = 64
+)
+
+// slugPattern matches lowercase words joined by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// UserLookup finds the account invited to an organization.
+// serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByEmail(ctx context.Context, email string) (*domainUser.User, error)
+}
+
+// Service defines the interface for organization business logic. Every
+// operation acts as actorID: organizations the actor is not an active member
+// of are reported as not found, and members are managed according to the
+// actor's role (see domainOrg.Role.CanManage).
+type Service interface {
+	// Create stores a new organization with the actor as its owner
+	Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error)
+
+	// List returns the organizations the actor is an active member of, by name
+	List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error)
+
+	// Get returns an organization of the actor
+	Get(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Organization, error)
+
+	// ListMembers returns the members and invitations of an organization of the actor, oldest first
+	ListMembers(ctx context.Context, actorID, orgID uuid.UUID) ([]*domainOrg.Member, error)
+
+	// Invite invites the account with email to the organization with role
+	Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error)
+
+	// ListInvitations returns the pending invitations of the actor, newest first
+	ListInvitations(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Member, error)
+
+	// AcceptInvitation makes the actor an active member of the organization that invited them
+	AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error)
+
+	// UpdateMemberRole changes the role of a member
+	UpdateMemberRole(ctx context.Context, actorID, orgID, userID uuid.UUID, role domainOrg.Role) (*domainOrg.Member, error)
+
+	// RemoveMember removes a member or withdraws an invitation. Actors may
+	// always remove themselves, to leave or to decline an invitation, as long
+	// as the organization keeps an owner.
+	RemoveMember(ctx context.Context, actorID, orgID, userID uuid.UUID) error
+}
+
+type organizationService struct {
+	repo   domainOrg.Repository
+	users  UserLookup
+	ids    idgen.Generator
+	logger *zap.Logger
+	now    func() time.Time
+
+	deadlines deadline.Deadlines
+}
+
+// Option configures optional behaviour of the organization service
+type Option func(*organizationService)
+
+// WithDeadlines runs lookups within the read deadline and changes to
+// organizations and their members within the write deadline of deadlines.
+// Operations that run out of time fail with deadline.ErrTimeout.
+func WithDeadlines(deadlines deadline.Deadlines) Option {
+	return func(s *organizationService) {
+		s.deadlines = deadlines
+	}
+}
+
+// NewService creates a new instance of Service
+func NewService(repo domainOrg.Repository, users UserLookup, ids idgen.Generator, logger *zap.Logger, opts ...Option) Service {
+	s := &organizationService{
+		repo:   repo,
+		users:  users,
+		ids:    ids,
+		logger: logger,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !s.deadlines.IsZero() {
+		return &deadlineService{Service: s, deadlines: s.deadlines}
+	}
+	return s
+}
+
+func (s *organizationService) Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error) {
+	input, err := normalizeInput(input)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetBySlug(ctx, input.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization by slug: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrSlugInUse
+	}
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate organization id: %w", err)
+	}
+	now := s.now()
+	org := &domainOrg.Organization{
+		ID:        id,
+		Name:      input.Name,
+		Slug:      input.Slug,
+		CreatedBy: actorID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	owner := &domainOrg.Member{
+		OrganizationID: id,
+		UserID:         actorID,
+		Role:           domainOrg.RoleOwner,
+		Status:         domainOrg.StatusActive,
+		CreatedAt:      now,
+		JoinedAt:       &now,
+	}
+	if err := s.repo.Create(ctx, org, owner); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return org, nil
+}
+
+func (s *organizationService) List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error) {
+	orgs, err := s.repo.ListByUser(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+func (s *organizationService) Get(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Organization, error) {
+	if _, err := s.membership(ctx, actorID, orgID); err != nil {
+		return nil, err
+	}
+	org, err := s.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, actorID, orgID uuid.UUID) ([]*domainOrg.Member, error) {
+	if _, err := s.membership(ctx, actorID, orgID); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *organizationService) Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	actor, err := s.membership(ctx, actorID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManage(role) {
+		return nil, ErrPermissionDenied
+	}
+
+	user, err := s.users.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetMember(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrAlreadyMember
+	}
+
+	member := &domainOrg.Member{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           role,
+		Status:         domainOrg.StatusInvited,
+		InvitedBy:      &actorID,
+		CreatedAt:      s.now(),
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *organizationService) ListInvitations(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Member, error) {
+	invitations, err := s.repo.ListInvitations(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (s *organizationService) AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error) {
+	member, err := s.repo.GetMember(ctx, orgID, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if member == nil || member.IsActive() {
+		return nil, ErrInvitationNotFound
+	}
+
+	now := s.now()
+	member.Status = domainOrg.StatusActive
+	member.JoinedAt = &now
+	if err := s.repo.UpdateMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *organizationService) UpdateMemberRole(ctx context.Context, actorID, orgID, userID uuid.UUID, role domainOrg.Role) (*domainOrg.Member, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	actor, err := s.membership(ctx, actorID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.member(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManage(member.Role) || !actor.Role.CanManage(role) {
+		return nil, ErrPermissionDenied
+	}
+	if member.Role == role {
+		return member, nil
+	}
+	if err := s.keepOwner(ctx, member); err != nil {
+		return nil, err
+	}
+
+	member.Role = role
+	if err := s.repo.UpdateMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, actorID, orgID, userID uuid.UUID) error {
+	member, err := s.member(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		actor, err := s.membership(ctx, actorID, orgID)
+		if err != nil {
+			return err
+		}
+		if !actor.Role.CanManage(member.Role) {
+			return ErrPermissionDenied
+		}
+	}
+	if err := s.keepOwner(ctx, member); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveMember(ctx, orgID, userID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	return nil
+}
+
+// membership returns the active membership of the actor, reporting the
+// organization as not found to anyone else
+func (s *organizationService) membership(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error) {
+	member, err := s.repo.GetMember(ctx, orgID, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if member == nil || !member.IsActive() {
+		return nil, ErrOrganizationNotFound
+	}
+	return member, nil
+}
+
+// member returns a membership or invitation of the organization
+func (s *organizationService) member(ctx context.Context, orgID, userID uuid.UUID) (*domainOrg.Member, error) {
+	member, err := s.repo.GetMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+	return member, nil
+}
+
+// keepOwner rejects taking the owner role away from member when they are
+// the last active owner
+func (s *organizationService) keepOwner(ctx context.Context, member *domainOrg.Member) error {
+	if member.Role != domainOrg.RoleOwner || !member.IsActive() {
+		return nil
+	}
+	owners, err := s.repo.CountOwners(ctx, member.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// normalizeInput validates the input, deriving the slug from the name when
+// it is not given
+func normalizeInput(input domainOrg.Input) (domainOrg.Input, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > maxNameLen {
+		return input, ErrNameRequired
+	}
+
+	input.Slug = strings.TrimSpace(input.Slug)
+	if input.Slug == "" {
+		input.Slug = slugify(input.Name)
+	}
+	if len(input.Slug) < minSlugLen || len(input.Slug) > maxSlugLen || !slugPattern.MatchString(input.Slug) {
+		return input, ErrInvalidSlug
+	}
+	return input, nil
+}
+
+// slugify lowercases name and joins its letters and digits with hyphens,
+// e.g. "Acme Corp." becomes "acme-corp"
+func slugify(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	slug := strings.Join(words, "-")
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimRight(slug[:maxSlugLen], "-")
+	}
+	return slug
+}
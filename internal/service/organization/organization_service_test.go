@@ -0,0 +1,342 @@
+package organization
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+type memberKey struct{ orgID, userID uuid.UUID }
+
+// memoryRepository is an in-memory domainOrg.Repository
+type memoryRepository struct {
+	orgs    map[uuid.UUID]domainOrg.Organization
+	members map[memberKey]domainOrg.Member
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{orgs: map[uuid.UUID]domainOrg.Organization{}, members: map[memberKey]domainOrg.Member{}}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, org *domainOrg.Organization, owner *domainOrg.Member) error {
+	r.orgs[org.ID] = *org
+	return r.AddMember(ctx, owner)
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainOrg.Organization, error) {
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &org, nil
+}
+
+func (r *memoryRepository) GetBySlug(ctx context.Context, slug string) (*domainOrg.Organization, error) {
+	for _, org := range r.orgs {
+		if org.Slug == slug {
+			return &org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domainOrg.Organization, error) {
+	var orgs []*domainOrg.Organization
+	for key, member := range r.members {
+		if key.userID == userID && member.IsActive() {
+			org := r.orgs[key.orgID]
+			orgs = append(orgs, &org)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+	return orgs, nil
+}
+
+func (r *memoryRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*domainOrg.Member, error) {
+	member, ok := r.members[memberKey{orgID, userID}]
+	if !ok {
+		return nil, nil
+	}
+	return &member, nil
+}
+
+func (r *memoryRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*domainOrg.Member, error) {
+	var members []*domainOrg.Member
+	for key, member := range r.members {
+		if key.orgID == orgID {
+			member := member
+			members = append(members, &member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].CreatedAt.Before(members[j].CreatedAt) })
+	return members, nil
+}
+
+func (r *memoryRepository) ListInvitations(ctx context.Context, userID uuid.UUID) ([]*domainOrg.Member, error) {
+	var invitations []*domainOrg.Member
+	for key, member := range r.members {
+		if key.userID == userID && !member.IsActive() {
+			member := member
+			invitations = append(invitations, &member)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *memoryRepository) AddMember(ctx context.Context, member *domainOrg.Member) error {
+	r.members[memberKey{member.OrganizationID, member.UserID}] = *member
+	return nil
+}
+
+func (r *memoryRepository) UpdateMember(ctx context.Context, member *domainOrg.Member) error {
+	return r.AddMember(ctx, member)
+}
+
+func (r *memoryRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	delete(r.members, memberKey{orgID, userID})
+	return nil
+}
+
+func (r *memoryRepository) CountOwners(ctx context.Context, orgID uuid.UUID) (int64, error) {
+	var owners int64
+	for key, member := range r.members {
+		if key.orgID == orgID && member.IsActive() && member.Role == domainOrg.RoleOwner {
+			owners++
+		}
+	}
+	return owners, nil
+}
+
+// userDirectory is a UserLookup over a fixed set of accounts
+type userDirectory map[string]*domainUser.User
+
+func (d userDirectory) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	user, ok := d[email]
+	if !ok {
+		return nil, serviceUser.ErrUserNotFound
+	}
+	return user, nil
+}
+
+var testNow = time.Date(2025, 6, 30, 9, 0, 0, 0, time.UTC)
+
+// fixture is an organization owned by owner, with users ready to invite
+type fixture struct {
+	service *organizationService
+	repo    *memoryRepository
+	users   userDirectory
+	org     *domainOrg.Organization
+	owner   uuid.UUID
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{repo: newMemoryRepository(), users: userDirectory{}, owner: uuid.New()}
+	f.service = NewService(f.repo, f.users, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop()).(*organizationService)
+	f.service.now = func() time.Time { return testNow }
+
+	org, err := f.service.Create(context.Background(), f.owner, domainOrg.Input{Name: "Acme Corp."})
+	require.NoError(t, err)
+	f.org = org
+	return f
+}
+
+// addUser registers an account that can be invited
+func (f *fixture) addUser(email string) uuid.UUID {
+	id := uuid.New()
+	f.users[email] = &domainUser.User{ID: id, Email: email}
+	return id
+}
+
+// join makes the account with email an active member holding role
+func (f *fixture) join(t *testing.T, email string, role domainOrg.Role) uuid.UUID {
+	t.Helper()
+	id := f.addUser(email)
+	_, err := f.service.Invite(context.Background(), f.owner, f.org.ID, email, role)
+	require.NoError(t, err)
+	_, err = f.service.AcceptInvitation(context.Background(), id, f.org.ID)
+	require.NoError(t, err)
+	return id
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Makes Creator Owner", func(t *testing.T) {
+		f := newFixture(t)
+		assert.Equal(t, "acme-corp", f.org.Slug, "the slug is derived from the name")
+
+		members, err := f.service.ListMembers(ctx, f.owner, f.org.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, domainOrg.RoleOwner, members[0].Role)
+		assert.True(t, members[0].IsActive())
+		assert.Nil(t, members[0].InvitedBy)
+	})
+
+	t.Run("Rejects Invalid Input", func(t *testing.T) {
+		f := newFixture(t)
+
+		_, err := f.service.Create(ctx, f.owner, domainOrg.Input{Name: "Acme", Slug: "acme-corp"})
+		assert.ErrorIs(t, err, ErrSlugInUse)
+		_, err = f.service.Create(ctx, f.owner, domainOrg.Input{Name: "  "})
+		assert.ErrorIs(t, err, ErrNameRequired)
+		for _, slug := range []string{"ab", "Acme", "acme--corp", "-acme", "acme_corp"} {
+			_, err = f.service.Create(ctx, f.owner, domainOrg.Input{Name: "Acme", Slug: slug})
+			assert.ErrorIs(t, err, ErrInvalidSlug, slug)
+		}
+	})
+}
+
+func TestGet_HidesOrganizationsOfOthers(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	invitee := f.addUser("bob@example.com")
+	_, err := f.service.Invite(ctx, f.owner, f.org.ID, "bob@example.com", domainOrg.RoleMember)
+	require.NoError(t, err)
+
+	for _, actorID := range []uuid.UUID{uuid.New(), invitee} {
+		_, err := f.service.Get(ctx, actorID, f.org.ID)
+		assert.ErrorIs(t, err, ErrOrganizationNotFound)
+		_, err = f.service.ListMembers(ctx, actorID, f.org.ID)
+		assert.ErrorIs(t, err, ErrOrganizationNotFound)
+	}
+
+	org, err := f.service.Get(ctx, f.owner, f.org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp.", org.Name)
+}
+
+func TestInvite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Invitation Is Pending Until Accepted", func(t *testing.T) {
+		f := newFixture(t)
+		bob := f.addUser("bob@example.com")
+
+		member, err := f.service.Invite(ctx, f.owner, f.org.ID, "bob@example.com", domainOrg.RoleAdmin)
+		require.NoError(t, err)
+		assert.Equal(t, domainOrg.StatusInvited, member.Status)
+		assert.Equal(t, &f.owner, member.InvitedBy)
+
+		invitations, err := f.service.ListInvitations(ctx, bob)
+		require.NoError(t, err)
+		require.Len(t, invitations, 1)
+		orgs, err := f.service.List(ctx, bob)
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+
+		member, err = f.service.AcceptInvitation(ctx, bob, f.org.ID)
+		require.NoError(t, err)
+		assert.True(t, member.IsActive())
+		assert.Equal(t, testNow, *member.JoinedAt)
+
+		orgs, err = f.service.List(ctx, bob)
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		_, err = f.service.AcceptInvitation(ctx, bob, f.org.ID)
+		assert.ErrorIs(t, err, ErrInvitationNotFound, "an accepted invitation cannot be accepted again")
+	})
+
+	t.Run("Rejects Existing Members And Unknown Users", func(t *testing.T) {
+		f := newFixture(t)
+		f.join(t, "bob@example.com", domainOrg.RoleMember)
+
+		_, err := f.service.Invite(ctx, f.owner, f.org.ID, "bob@example.com", domainOrg.RoleMember)
+		assert.ErrorIs(t, err, ErrAlreadyMember)
+		_, err = f.service.Invite(ctx, f.owner, f.org.ID, "nobody@example.com", domainOrg.RoleMember)
+		assert.ErrorIs(t, err, serviceUser.ErrUserNotFound)
+		_, err = f.service.Invite(ctx, f.owner, f.org.ID, "bob@example.com", domainOrg.Role("guest"))
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("Enforces Roles", func(t *testing.T) {
+		f := newFixture(t)
+		admin := f.join(t, "admin@example.com", domainOrg.RoleAdmin)
+		member := f.join(t, "member@example.com", domainOrg.RoleMember)
+		f.addUser("carol@example.com")
+
+		_, err := f.service.Invite(ctx, member, f.org.ID, "carol@example.com", domainOrg.RoleMember)
+		assert.ErrorIs(t, err, ErrPermissionDenied, "members cannot invite")
+		_, err = f.service.Invite(ctx, admin, f.org.ID, "carol@example.com", domainOrg.RoleOwner)
+		assert.ErrorIs(t, err, ErrPermissionDenied, "admins cannot invite owners")
+		_, err = f.service.Invite(ctx, admin, f.org.ID, "carol@example.com", domainOrg.RoleAdmin)
+		assert.NoError(t, err)
+	})
+}
+
+func TestUpdateMemberRole(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	admin := f.join(t, "admin@example.com", domainOrg.RoleAdmin)
+	member := f.join(t, "member@example.com", domainOrg.RoleMember)
+
+	updated, err := f.service.UpdateMemberRole(ctx, admin, f.org.ID, member, domainOrg.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, domainOrg.RoleAdmin, updated.Role)
+
+	_, err = f.service.UpdateMemberRole(ctx, admin, f.org.ID, f.owner, domainOrg.RoleMember)
+	assert.ErrorIs(t, err, ErrPermissionDenied, "admins cannot demote owners")
+	_, err = f.service.UpdateMemberRole(ctx, admin, f.org.ID, member, domainOrg.RoleOwner)
+	assert.ErrorIs(t, err, ErrPermissionDenied, "admins cannot grant ownership")
+	_, err = f.service.UpdateMemberRole(ctx, f.owner, f.org.ID, f.owner, domainOrg.RoleAdmin)
+	assert.ErrorIs(t, err, ErrLastOwner)
+	_, err = f.service.UpdateMemberRole(ctx, f.owner, f.org.ID, uuid.New(), domainOrg.RoleAdmin)
+	assert.ErrorIs(t, err, ErrMemberNotFound)
+
+	_, err = f.service.UpdateMemberRole(ctx, f.owner, f.org.ID, admin, domainOrg.RoleOwner)
+	require.NoError(t, err)
+	_, err = f.service.UpdateMemberRole(ctx, f.owner, f.org.ID, f.owner, domainOrg.RoleAdmin)
+	assert.NoError(t, err, "another owner remains")
+}
+
+func TestRemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Managers Remove Lower Roles", func(t *testing.T) {
+		f := newFixture(t)
+		admin := f.join(t, "admin@example.com", domainOrg.RoleAdmin)
+		member := f.join(t, "member@example.com", domainOrg.RoleMember)
+
+		assert.ErrorIs(t, f.service.RemoveMember(ctx, member, f.org.ID, admin), ErrPermissionDenied)
+		assert.ErrorIs(t, f.service.RemoveMember(ctx, admin, f.org.ID, f.owner), ErrPermissionDenied)
+		require.NoError(t, f.service.RemoveMember(ctx, admin, f.org.ID, member))
+
+		_, err := f.service.Get(ctx, member, f.org.ID)
+		assert.ErrorIs(t, err, ErrOrganizationNotFound)
+	})
+
+	t.Run("Members Leave And Decline", func(t *testing.T) {
+		f := newFixture(t)
+		member := f.join(t, "member@example.com", domainOrg.RoleMember)
+		invitee := f.addUser("bob@example.com")
+		_, err := f.service.Invite(ctx, f.owner, f.org.ID, "bob@example.com", domainOrg.RoleOwner)
+		require.NoError(t, err)
+
+		require.NoError(t, f.service.RemoveMember(ctx, member, f.org.ID, member))
+		require.NoError(t, f.service.RemoveMember(ctx, invitee, f.org.ID, invitee))
+		assert.ErrorIs(t, f.service.RemoveMember(ctx, f.owner, f.org.ID, f.owner), ErrLastOwner)
+
+		members, err := f.service.ListMembers(ctx, f.owner, f.org.ID)
+		require.NoError(t, err)
+		assert.Len(t, members, 1)
+	})
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "acme-corp", slugify("  Acme Corp. "))
+	assert.Equal(t, "r-d-team-2", slugify("R&D -- Team #2"))
+	assert.Equal(t, "", slugify("???"))
+}
@@ -0,0 +1,58 @@
+package rbac
+
+import (
+	"context"
+
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+)
+
+// Service implements the domainRBAC.RoleService interface using the
+// built-in role definitions.
+type Service struct {
+	roles []domainRBAC.RoleDefinition
+	perms []domainRBAC.PermissionDefinition
+	index map[domainRBAC.Role]map[domainRBAC.Permission]struct{}
+}
+
+// NewService creates a new role service backed by the built-in definitions
+func NewService() domainRBAC.RoleService {
+	return newService(domainRBAC.Roles, domainRBAC.Permissions)
+}
+
+func newService(roles []domainRBAC.RoleDefinition, perms []domainRBAC.PermissionDefinition) *Service {
+	index := make(map[domainRBAC.Role]map[domainRBAC.Permission]struct{}, len(roles))
+	for _, role := range roles {
+		granted := make(map[domainRBAC.Permission]struct{}, len(role.Permissions))
+		for _, p := range role.Permissions {
+			granted[p] = struct{}{}
+		}
+		index[role.Name] = granted
+	}
+	return &Service{roles: roles, perms: perms, index: index}
+}
+
+// ListRoles returns every role with the permissions it grants
+func (s *Service) ListRoles(ctx context.Context) ([]domainRBAC.RoleDefinition, error) {
+	return s.roles, nil
+}
+
+// ListPermissions returns every permission with the roles that grant it
+func (s *Service) ListPermissions(ctx context.Context) ([]domainRBAC.PermissionGrant, error) {
+	grants := make([]domainRBAC.PermissionGrant, 0, len(s.perms))
+	for _, perm := range s.perms {
+		grant := domainRBAC.PermissionGrant{Permission: perm, Roles: []domainRBAC.Role{}}
+		for _, role := range s.roles {
+			if s.HasPermission(role.Name, perm.Name) {
+				grant.Roles = append(grant.Roles, role.Name)
+			}
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// HasPermission reports whether the role grants the permission
+func (s *Service) HasPermission(role domainRBAC.Role, permission domainRBAC.Permission) bool {
+	_, ok := s.index[role][permission]
+	return ok
+}
@@ -0,0 +1,37 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+)
+
+func TestHasPermission(t *testing.T) {
+	svc := NewService()
+
+	assert.True(t, svc.HasPermission(domainRBAC.RoleAdmin, domainRBAC.PermissionUsersDelete))
+	assert.True(t, svc.HasPermission(domainRBAC.RoleSupport, domainRBAC.PermissionSessionsRevoke))
+	assert.False(t, svc.HasPermission(domainRBAC.RoleSupport, domainRBAC.PermissionUsersDelete))
+	assert.False(t, svc.HasPermission(domainRBAC.RoleUser, domainRBAC.PermissionRolesRead))
+	assert.False(t, svc.HasPermission(domainRBAC.Role("unknown"), domainRBAC.PermissionProfileRead))
+}
+
+func TestListPermissionsBuildsMatrix(t *testing.T) {
+	svc := newService(
+		[]domainRBAC.RoleDefinition{
+			{Name: "a", Permissions: []domainRBAC.Permission{"p1", "p2"}},
+			{Name: "b", Permissions: []domainRBAC.Permission{"p2"}},
+		},
+		[]domainRBAC.PermissionDefinition{{Name: "p1"}, {Name: "p2"}, {Name: "p3"}},
+	)
+
+	grants, err := svc.ListPermissions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, grants, 3)
+	assert.Equal(t, []domainRBAC.Role{"a"}, grants[0].Roles)
+	assert.Equal(t, []domainRBAC.Role{"a", "b"}, grants[1].Roles)
+	assert.Empty(t, grants[2].Roles)
+}
@@ -0,0 +1,19 @@
+package saml
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for SAML sign-in and identity provider operations
+var (
+	ErrSAMLDisabled             = apperror.New(apperror.CodeSAMLProviderNotFound, "SAML sign-in is not enabled")
+	ErrIdentityProviderNotFound = apperror.New(apperror.CodeSAMLProviderNotFound, "identity provider not found")
+	ErrInvalidAssertion         = apperror.New(apperror.CodeInvalidAssertion, "invalid SAML assertion")
+	ErrAssertionReplayed        = apperror.New(apperror.CodeInvalidAssertion, "SAML assertion has already been used")
+	ErrEmailMissing             = apperror.New(apperror.CodeInvalidAssertion, "SAML assertion carries no email address")
+	ErrAccountNotProvisioned    = apperror.New(apperror.CodePermissionDenied, "no account exists for this user")
+	ErrTenantMismatch           = apperror.New(apperror.CodePermissionDenied, "the account belongs to another tenant")
+	ErrTenantRequired           = apperror.New(apperror.CodeInvalidArgument, "tenant is required and must be at most 255 characters")
+	ErrEntityIDRequired         = apperror.New(apperror.CodeInvalidArgument, "entityId is required and must be at most 1024 characters")
+	ErrInvalidSSOURL            = apperror.New(apperror.CodeInvalidArgument, "ssoUrl must be an absolute http or https URL")
+	ErrInvalidCertificate       = apperror.New(apperror.CodeInvalidArgument, "certificate must be an RSA X.509 certificate in PEM or base64")
+	ErrAttributeTooLong         = apperror.New(apperror.CodeInvalidArgument, "attribute names must be at most 255 characters")
+)
@@ -0,0 +1,352 @@
+// Package saml signs the users of a tenant in through the SAML identity
+// provider of the tenant, as an alternative to their password
+package saml
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/saml"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// Column sizes of saml_identity_providers
+const (
+	maxTenantLength    = 255
+	maxEntityIDLength  = 1024
+	maxAttributeLength = 255
+)
+
+// Service defines the interface for SAML sign-in and the administration of
+// the identity providers of tenants
+type Service interface {
+	// Metadata returns the service provider metadata identity providers are
+	// configured with for tenant
+	Metadata(ctx context.Context, tenant string) ([]byte, error)
+
+	// SignIn validates a response posted by the identity provider of a
+	// tenant, creates the account of a new user when the identity provider
+	// allows it, and returns a token pair for the user
+	SignIn(ctx context.Context, input domainSAML.SignInInput) (*domainAuth.TokenPair, error)
+
+	// ServiceProviderURLs returns the entity ID and assertion consumer
+	// service URL of the service provider of tenant
+	ServiceProviderURLs(tenant string) (entityID, acsURL string)
+
+	// ListProviders returns the identity provider of every tenant that has one
+	ListProviders(ctx context.Context) ([]*domainSAML.IdentityProvider, error)
+
+	// GetProvider returns the identity provider of tenant
+	GetProvider(ctx context.Context, tenant string) (*domainSAML.IdentityProvider, error)
+
+	// SaveProvider creates or replaces the identity provider of tenant
+	SaveProvider(ctx context.Context, tenant string, input domainSAML.Input) (*domainSAML.IdentityProvider, error)
+
+	// DeleteProvider removes the identity provider of tenant; its users can
+	// no longer sign in through it
+	DeleteProvider(ctx context.Context, tenant string) error
+}
+
+// Users finds and creates the accounts SAML users sign in to.
+// serviceUser.UserService satisfies it.
+type Users interface {
+	GetByEmail(ctx context.Context, email string) (*domainUser.User, error)
+	PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error)
+	CreateUsers(ctx context.Context, users []*domainUser.User) error
+}
+
+type samlService struct {
+	repo       domainSAML.Repository
+	assertions domainSAML.AssertionCache
+	users      Users
+	auth       domainAuth.AuthService
+	events     domainEvent.Publisher
+	emails     domainUser.EmailPolicy // Optional; restricts the addresses provisioned
+	config     config.SAMLConfig
+	logger     *zap.Logger
+	now        func() time.Time
+	// parse validates a response for a service provider; replaced in tests
+	parse func(sp *saml.ServiceProvider, encoded string) (*saml.Assertion, error)
+}
+
+// Option customizes a SAML Service
+type Option func(*samlService)
+
+// WithEmailPolicy only provisions accounts for the addresses policy accepts
+// for the tenant of the identity provider
+func WithEmailPolicy(policy domainUser.EmailPolicy) Option {
+	return func(s *samlService) {
+		s.emails = policy
+	}
+}
+
+// NewSAMLService creates a new instance of Service
+func NewSAMLService(repo domainSAML.Repository, assertions domainSAML.AssertionCache, users Users, auth domainAuth.AuthService, events domainEvent.Publisher, cfg config.SAMLConfig, logger *zap.Logger, opts ...Option) Service {
+	s := &samlService{
+		repo:       repo,
+		assertions: assertions,
+		users:      users,
+		auth:       auth,
+		events:     events,
+		config:     cfg,
+		logger:     logger,
+		now:        time.Now,
+		parse:      (*saml.ServiceProvider).ParseResponse,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *samlService) ServiceProviderURLs(tenant string) (string, string) {
+	base := strings.TrimRight(s.config.BaseURL, "/") + "/api/v1/auth/saml/" + url.PathEscape(tenant)
+	return base + "/metadata", base + "/acs"
+}
+
+// Metadata is served for any tenant, so that the identity provider can be
+// set up before it is configured here
+func (s *samlService) Metadata(_ context.Context, tenant string) ([]byte, error) {
+	if !s.config.Enabled {
+		return nil, ErrSAMLDisabled
+	}
+	if err := validateTenant(tenant); err != nil {
+		return nil, err
+	}
+	entityID, acsURL := s.ServiceProviderURLs(tenant)
+	sp := &saml.ServiceProvider{EntityID: entityID, ACSURL: acsURL}
+	return sp.Metadata()
+}
+
+func (s *samlService) SignIn(ctx context.Context, input domainSAML.SignInInput) (*domainAuth.TokenPair, error) {
+	if !s.config.Enabled {
+		return nil, ErrSAMLDisabled
+	}
+	provider, err := s.repo.Get(ctx, input.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity provider: %w", err)
+	}
+	if provider == nil || !provider.Enabled {
+		return nil, ErrIdentityProviderNotFound
+	}
+	cert, err := saml.ParseCertificate(provider.Certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate of identity provider of tenant %q: %w", input.Tenant, err)
+	}
+
+	entityID, acsURL := s.ServiceProviderURLs(input.Tenant)
+	assertion, err := s.parse(&saml.ServiceProvider{
+		EntityID:       entityID,
+		ACSURL:         acsURL,
+		IdPEntityID:    provider.EntityID,
+		IdPCertificate: cert,
+		ClockSkew:      s.config.ClockSkew(),
+		Now:            s.now,
+	}, input.SAMLResponse)
+	if err != nil {
+		// The reason is for the administrators of the tenant, not the client
+		s.logger.Warn("Rejected SAML response",
+			zap.String("tenant", input.Tenant),
+			zap.String("client_ip", input.ClientIP),
+			zap.Error(err))
+		return nil, ErrInvalidAssertion
+	}
+
+	claimed, err := s.assertions.Claim(ctx, input.Tenant, assertion.ID, assertion.NotOnOrAfter)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		s.logger.Warn("Rejected replayed SAML assertion",
+			zap.String("tenant", input.Tenant),
+			zap.String("assertion_id", assertion.ID),
+			zap.String("client_ip", input.ClientIP))
+		return nil, ErrAssertionReplayed
+	}
+
+	user, err := s.findUser(ctx, provider, assertion)
+	if err != nil {
+		return nil, err
+	}
+	return s.auth.LoginExternal(ctx, domainAuth.ExternalLoginInput{UserID: user.ID, UserAgent: input.UserAgent, ClientIP: input.ClientIP})
+}
+
+// findUser returns the account of the user an assertion is about. Only an
+// account of the tenant of the identity provider is accepted, so the
+// identity provider of one tenant cannot sign in the users of another or
+// those outside any tenant.
+func (s *samlService) findUser(ctx context.Context, provider *domainSAML.IdentityProvider, assertion *saml.Assertion) (*domainUser.User, error) {
+	email := assertion.NameID
+	if provider.EmailAttribute != "" {
+		email = assertion.Attribute(provider.EmailAttribute)
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Name != "" {
+		return nil, ErrEmailMissing
+	}
+	email = address.Address
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err == nil {
+		if user.Tenant != provider.Tenant {
+			s.logger.Warn("Rejected SAML sign-in to an account of another tenant",
+				zap.String("tenant", provider.Tenant),
+				zap.String("user_id", user.ID.String()))
+			return nil, ErrTenantMismatch
+		}
+		return user, nil
+	}
+	if !errors.Is(err, serviceUser.ErrUserNotFound) {
+		return nil, err
+	}
+	if !provider.JITProvisioning {
+		return nil, ErrAccountNotProvisioned
+	}
+	return s.provision(ctx, provider, assertion, email)
+}
+
+// provision creates the account of a user signing in for the first time.
+// The account gets a random password nobody knows, so it can only be signed
+// in to through the identity provider.
+func (s *samlService) provision(ctx context.Context, provider *domainSAML.IdentityProvider, assertion *saml.Assertion, email string) (*domainUser.User, error) {
+	if s.emails != nil {
+		if err := s.emails.CheckEmail(provider.Tenant, email); err != nil {
+			return nil, err
+		}
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+	input := domainUser.RegisterUserInput{Email: email, Password: password}
+	if provider.FirstNameAttribute != "" {
+		input.FirstName = assertion.Attribute(provider.FirstNameAttribute)
+	}
+	if provider.LastNameAttribute != "" {
+		input.LastName = assertion.Attribute(provider.LastNameAttribute)
+	}
+	user, err := s.users.PrepareUser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	user.Tenant = provider.Tenant
+	if err := s.users.CreateUsers(ctx, []*domainUser.User{user}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Provisioned SAML user",
+		zap.String("tenant", provider.Tenant),
+		zap.String("user_id", user.ID.String()))
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserRegistered, UserID: user.ID})
+	return user, nil
+}
+
+func (s *samlService) ListProviders(ctx context.Context) ([]*domainSAML.IdentityProvider, error) {
+	providers, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identity providers: %w", err)
+	}
+	return providers, nil
+}
+
+func (s *samlService) GetProvider(ctx context.Context, tenant string) (*domainSAML.IdentityProvider, error) {
+	provider, err := s.repo.Get(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity provider: %w", err)
+	}
+	if provider == nil {
+		return nil, ErrIdentityProviderNotFound
+	}
+	return provider, nil
+}
+
+// SaveProvider stores the certificate in PEM whichever form it was given in
+func (s *samlService) SaveProvider(ctx context.Context, tenant string, input domainSAML.Input) (*domainSAML.IdentityProvider, error) {
+	if err := validateTenant(tenant); err != nil {
+		return nil, err
+	}
+	input.EntityID = strings.TrimSpace(input.EntityID)
+	if input.EntityID == "" || len(input.EntityID) > maxEntityIDLength {
+		return nil, ErrEntityIDRequired
+	}
+	input.SSOURL = strings.TrimSpace(input.SSOURL)
+	if input.SSOURL != "" {
+		u, err := url.Parse(input.SSOURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ErrInvalidSSOURL
+		}
+	}
+	cert, err := saml.ParseCertificate(input.Certificate)
+	if err != nil {
+		return nil, ErrInvalidCertificate
+	}
+	for _, name := range []string{input.EmailAttribute, input.FirstNameAttribute, input.LastNameAttribute} {
+		if len(name) > maxAttributeLength {
+			return nil, ErrAttributeTooLong
+		}
+	}
+
+	provider, err := s.repo.Get(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity provider: %w", err)
+	}
+	now := s.now()
+	if provider == nil {
+		provider = &domainSAML.IdentityProvider{Tenant: tenant, CreatedAt: now}
+	}
+	provider.EntityID = input.EntityID
+	provider.SSOURL = input.SSOURL
+	provider.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	provider.EmailAttribute = strings.TrimSpace(input.EmailAttribute)
+	provider.FirstNameAttribute = strings.TrimSpace(input.FirstNameAttribute)
+	provider.LastNameAttribute = strings.TrimSpace(input.LastNameAttribute)
+	provider.JITProvisioning = input.JITProvisioning
+	provider.Enabled = input.Enabled
+	provider.UpdatedAt = now
+
+	if err := s.repo.Save(ctx, provider); err != nil {
+		return nil, fmt.Errorf("failed to save identity provider: %w", err)
+	}
+	return provider, nil
+}
+
+func (s *samlService) DeleteProvider(ctx context.Context, tenant string) error {
+	if _, err := s.GetProvider(ctx, tenant); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenant); err != nil {
+		return fmt.Errorf("failed to delete identity provider: %w", err)
+	}
+	return nil
+}
+
+func validateTenant(tenant string) error {
+	if strings.TrimSpace(tenant) == "" || len(tenant) > maxTenantLength {
+		return ErrTenantRequired
+	}
+	return nil
+}
+
+// randomPassword returns a password long enough never to be guessed
+func randomPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
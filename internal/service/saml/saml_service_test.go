@@ -0,0 +1,419 @@
+package saml
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/saml"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// fakeRepository keeps identity providers in memory
+type fakeRepository struct {
+	providers map[string]*domainSAML.IdentityProvider
+}
+
+func newFakeRepository(providers ...*domainSAML.IdentityProvider) *fakeRepository {
+	r := &fakeRepository{providers: make(map[string]*domainSAML.IdentityProvider)}
+	for _, p := range providers {
+		r.providers[p.Tenant] = p
+	}
+	return r
+}
+
+func (r *fakeRepository) Get(_ context.Context, tenant string) (*domainSAML.IdentityProvider, error) {
+	provider, ok := r.providers[tenant]
+	if !ok {
+		return nil, nil
+	}
+	copied := *provider
+	return &copied, nil
+}
+
+func (r *fakeRepository) Save(_ context.Context, provider *domainSAML.IdentityProvider) error {
+	copied := *provider
+	r.providers[provider.Tenant] = &copied
+	return nil
+}
+
+func (r *fakeRepository) Delete(_ context.Context, tenant string) error {
+	delete(r.providers, tenant)
+	return nil
+}
+
+func (r *fakeRepository) List(_ context.Context) ([]*domainSAML.IdentityProvider, error) {
+	var providers []*domainSAML.IdentityProvider
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+// fakeAssertionCache remembers claimed assertions in memory
+type fakeAssertionCache struct {
+	claimed map[string]time.Time
+}
+
+func (c *fakeAssertionCache) Claim(_ context.Context, tenant, assertionID string, expiresAt time.Time) (bool, error) {
+	key := tenant + "/" + assertionID
+	if _, ok := c.claimed[key]; ok {
+		return false, nil
+	}
+	c.claimed[key] = expiresAt
+	return true, nil
+}
+
+// fakeUsers keeps users in memory
+type fakeUsers struct {
+	users   []*domainUser.User
+	created []*domainUser.User
+}
+
+func (u *fakeUsers) GetByEmail(_ context.Context, email string) (*domainUser.User, error) {
+	for _, user := range u.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, serviceUser.ErrUserNotFound
+}
+
+func (u *fakeUsers) PrepareUser(_ context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	return &domainUser.User{ID: uuid.New(), Email: input.Email, Password: input.Password, FirstName: input.FirstName, LastName: input.LastName, IsActive: true}, nil
+}
+
+func (u *fakeUsers) CreateUsers(_ context.Context, users []*domainUser.User) error {
+	u.users = append(u.users, users...)
+	u.created = append(u.created, users...)
+	return nil
+}
+
+// stubAuth issues a fixed token pair to the users it is asked to sign in
+type stubAuth struct {
+	domainAuth.AuthService
+	logins []domainAuth.ExternalLoginInput
+}
+
+func (a *stubAuth) LoginExternal(_ context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	a.logins = append(a.logins, input)
+	return &domainAuth.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+// recordingPublisher records the events it was given
+type recordingPublisher struct {
+	events []domainEvent.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domainEvent.Event) {
+	p.events = append(p.events, e)
+}
+
+var testNow = time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC)
+
+var testConfig = config.SAMLConfig{Enabled: true, BaseURL: "https://api.example.com/", ClockSkewSeconds: 30}
+
+// testCertificate returns the base64 DER of a self-signed RSA certificate
+func testCertificate(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "idp.example.com"}, NotBefore: testNow, NotAfter: testNow.Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// emailPolicyFunc adapts a function to domainUser.EmailPolicy
+type emailPolicyFunc func(tenant, email string) error
+
+func (f emailPolicyFunc) CheckEmail(tenant, email string) error {
+	return f(tenant, email)
+}
+
+var errPolicy = apperror.New(apperror.CodeEmailPolicyViolation, "email addresses of this domain may not sign up")
+
+type testDeps struct {
+	repo   *fakeRepository
+	cache  *fakeAssertionCache
+	users  *fakeUsers
+	auth   *stubAuth
+	events *recordingPublisher
+}
+
+func newTestService(cfg config.SAMLConfig, deps testDeps) *samlService {
+	s := NewSAMLService(deps.repo, deps.cache, deps.users, deps.auth, deps.events, cfg, zap.NewNop()).(*samlService)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestSignIn(t *testing.T) {
+	ctx := context.Background()
+	certificate := testCertificate(t)
+	member := &domainUser.User{ID: uuid.New(), Email: "ada@acme.example", Tenant: "acme", IsActive: true}
+	outsider := &domainUser.User{ID: uuid.New(), Email: "root@acme.example", IsActive: true}
+	input := domainSAML.SignInInput{Tenant: "acme", SAMLResponse: "PHJlc3BvbnNlLz4=", UserAgent: "Mozilla/5.0", ClientIP: "203.0.113.7"}
+
+	newDeps := func(provider domainSAML.IdentityProvider) testDeps {
+		provider.Tenant = "acme"
+		provider.EntityID = "https://idp.example.com"
+		provider.Certificate = certificate
+		return testDeps{
+			repo:   newFakeRepository(&provider),
+			cache:  &fakeAssertionCache{claimed: make(map[string]time.Time)},
+			users:  &fakeUsers{users: []*domainUser.User{member, outsider}},
+			auth:   &stubAuth{},
+			events: &recordingPublisher{},
+		}
+	}
+	assertionFor := func(nameID string, attributes map[string][]string) func(*saml.ServiceProvider, string) (*saml.Assertion, error) {
+		return func(*saml.ServiceProvider, string) (*saml.Assertion, error) {
+			return &saml.Assertion{ID: "_a1", NameID: nameID, Attributes: attributes, NotOnOrAfter: testNow.Add(5 * time.Minute)}, nil
+		}
+	}
+
+	t.Run("Existing Member", func(t *testing.T) {
+		deps := newDeps(domainSAML.IdentityProvider{Enabled: true})
+		s := newTestService(testConfig, deps)
+		var sp *saml.ServiceProvider
+		s.parse = func(provider *saml.ServiceProvider, encoded string) (*saml.Assertion, error) {
+			sp = provider
+			assert.Equal(t, input.SAMLResponse, encoded)
+			return assertionFor("ada@acme.example", nil)(provider, encoded)
+		}
+
+		tokens, err := s.SignIn(ctx, input)
+
+		require.NoError(t, err)
+		assert.Equal(t, "access", tokens.AccessToken)
+		assert.Equal(t, []domainAuth.ExternalLoginInput{{UserID: member.ID, UserAgent: "Mozilla/5.0", ClientIP: "203.0.113.7"}}, deps.auth.logins)
+		require.NotNil(t, sp)
+		assert.Equal(t, "https://api.example.com/api/v1/auth/saml/acme/metadata", sp.EntityID)
+		assert.Equal(t, "https://api.example.com/api/v1/auth/saml/acme/acs", sp.ACSURL)
+		assert.Equal(t, "https://idp.example.com", sp.IdPEntityID)
+		assert.Equal(t, 30*time.Second, sp.ClockSkew)
+		assert.Equal(t, testNow.Add(5*time.Minute), deps.cache.claimed["acme/_a1"])
+		assert.Empty(t, deps.users.created)
+
+		_, err = s.SignIn(ctx, input)
+		assert.ErrorIs(t, err, ErrAssertionReplayed)
+		assert.Len(t, deps.auth.logins, 1)
+	})
+
+	t.Run("Provisions New User", func(t *testing.T) {
+		deps := newDeps(domainSAML.IdentityProvider{Enabled: true, JITProvisioning: true, EmailAttribute: "mail", FirstNameAttribute: "givenName", LastNameAttribute: "sn"})
+		s := newTestService(testConfig, deps)
+		s.parse = assertionFor("G-12345", map[string][]string{"mail": {"grace@acme.example"}, "givenName": {"Grace"}, "sn": {"Hopper"}})
+
+		_, err := s.SignIn(ctx, input)
+
+		require.NoError(t, err)
+		require.Len(t, deps.users.created, 1)
+		created := deps.users.created[0]
+		assert.Equal(t, "grace@acme.example", created.Email)
+		assert.Equal(t, "acme", created.Tenant)
+		assert.Equal(t, "Grace", created.FirstName)
+		assert.Equal(t, "Hopper", created.LastName)
+		assert.GreaterOrEqual(t, len(created.Password), 32)
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeUserRegistered, UserID: created.ID}}, deps.events.events)
+		assert.Equal(t, created.ID, deps.auth.logins[0].UserID)
+	})
+
+	t.Run("Provisioning Follows The Email Policy Of The Tenant", func(t *testing.T) {
+		deps := newDeps(domainSAML.IdentityProvider{Enabled: true, JITProvisioning: true, EmailAttribute: "mail"})
+		s := newTestService(testConfig, deps)
+		var checked []string
+		s.emails = emailPolicyFunc(func(tenant, email string) error {
+			checked = append(checked, tenant+":"+email)
+			return errPolicy
+		})
+		s.parse = assertionFor("G-12345", map[string][]string{"mail": {"grace@mailinator.com"}})
+
+		_, err := s.SignIn(ctx, input)
+
+		assert.ErrorIs(t, err, errPolicy)
+		assert.Equal(t, []string{"acme:grace@mailinator.com"}, checked)
+		assert.Empty(t, deps.users.created)
+		assert.Empty(t, deps.auth.logins)
+	})
+
+	failureTests := []struct {
+		name        string
+		cfg         config.SAMLConfig
+		provider    domainSAML.IdentityProvider
+		tenant      string
+		parse       func(*saml.ServiceProvider, string) (*saml.Assertion, error)
+		expectedErr error
+	}{
+		{
+			name:        "Disabled",
+			provider:    domainSAML.IdentityProvider{Enabled: true},
+			parse:       assertionFor("ada@acme.example", nil),
+			expectedErr: ErrSAMLDisabled,
+		},
+		{
+			name:        "Unknown Tenant",
+			cfg:         testConfig,
+			provider:    domainSAML.IdentityProvider{Enabled: true},
+			tenant:      "globex",
+			parse:       assertionFor("ada@acme.example", nil),
+			expectedErr: ErrIdentityProviderNotFound,
+		},
+		{
+			name:        "Identity Provider Disabled",
+			cfg:         testConfig,
+			parse:       assertionFor("ada@acme.example", nil),
+			expectedErr: ErrIdentityProviderNotFound,
+		},
+		{
+			name:     "Invalid Response",
+			cfg:      testConfig,
+			provider: domainSAML.IdentityProvider{Enabled: true},
+			parse: func(*saml.ServiceProvider, string) (*saml.Assertion, error) {
+				return nil, saml.ErrSignature
+			},
+			expectedErr: ErrInvalidAssertion,
+		},
+		{
+			name:        "Name ID Is Not An Email",
+			cfg:         testConfig,
+			provider:    domainSAML.IdentityProvider{Enabled: true},
+			parse:       assertionFor("G-12345", nil),
+			expectedErr: ErrEmailMissing,
+		},
+		{
+			name:        "Account Outside The Tenant",
+			cfg:         testConfig,
+			provider:    domainSAML.IdentityProvider{Enabled: true, JITProvisioning: true},
+			parse:       assertionFor("root@acme.example", nil),
+			expectedErr: ErrTenantMismatch,
+		},
+		{
+			name:        "No Account Without Provisioning",
+			cfg:         testConfig,
+			provider:    domainSAML.IdentityProvider{Enabled: true},
+			parse:       assertionFor("grace@acme.example", nil),
+			expectedErr: ErrAccountNotProvisioned,
+		},
+	}
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDeps(tt.provider)
+			s := newTestService(tt.cfg, deps)
+			s.parse = tt.parse
+			attempt := input
+			if tt.tenant != "" {
+				attempt.Tenant = tt.tenant
+			}
+
+			_, err := s.SignIn(ctx, attempt)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Empty(t, deps.auth.logins)
+			assert.Empty(t, deps.users.created)
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	s := newTestService(testConfig, testDeps{})
+
+	metadata, err := s.Metadata(context.Background(), "acme corp")
+
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="https://api.example.com/api/v1/auth/saml/acme%20corp/metadata"`)
+	assert.Contains(t, string(metadata), `Location="https://api.example.com/api/v1/auth/saml/acme%20corp/acs"`)
+
+	_, err = newTestService(config.SAMLConfig{}, testDeps{}).Metadata(context.Background(), "acme")
+	assert.ErrorIs(t, err, ErrSAMLDisabled)
+}
+
+func TestSaveProvider(t *testing.T) {
+	ctx := context.Background()
+	certificate := testCertificate(t)
+	valid := domainSAML.Input{EntityID: " https://idp.example.com ", SSOURL: "https://idp.example.com/sso", Certificate: certificate, Enabled: true}
+
+	tests := []struct {
+		name        string
+		tenant      string
+		mutate      func(in *domainSAML.Input)
+		expectedErr error
+	}{
+		{name: "Valid", tenant: "acme"},
+		{name: "No Tenant", tenant: " ", expectedErr: ErrTenantRequired},
+		{name: "No Entity ID", tenant: "acme", mutate: func(in *domainSAML.Input) { in.EntityID = "" }, expectedErr: ErrEntityIDRequired},
+		{name: "Relative SSO URL", tenant: "acme", mutate: func(in *domainSAML.Input) { in.SSOURL = "/sso" }, expectedErr: ErrInvalidSSOURL},
+		{name: "Invalid Certificate", tenant: "acme", mutate: func(in *domainSAML.Input) { in.Certificate = "not a certificate" }, expectedErr: ErrInvalidCertificate},
+		{name: "Long Attribute", tenant: "acme", mutate: func(in *domainSAML.Input) { in.EmailAttribute = strings.Repeat("a", 256) }, expectedErr: ErrAttributeTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := newTestService(testConfig, testDeps{repo: repo})
+			input := valid
+			if tt.mutate != nil {
+				tt.mutate(&input)
+			}
+
+			provider, err := s.SaveProvider(ctx, tt.tenant, input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.providers)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://idp.example.com", provider.EntityID)
+			assert.True(t, strings.HasPrefix(provider.Certificate, "-----BEGIN CERTIFICATE-----"))
+			_, err = saml.ParseCertificate(provider.Certificate)
+			assert.NoError(t, err)
+			assert.Equal(t, testNow, provider.CreatedAt)
+			assert.Contains(t, repo.providers, "acme")
+		})
+	}
+
+	t.Run("Replaces And Keeps Creation Time", func(t *testing.T) {
+		created := testNow.Add(-24 * time.Hour)
+		repo := newFakeRepository(&domainSAML.IdentityProvider{Tenant: "acme", EntityID: "https://old.example.com", Enabled: true, CreatedAt: created})
+		s := newTestService(testConfig, testDeps{repo: repo})
+
+		input := valid
+		input.Enabled = false
+		provider, err := s.SaveProvider(ctx, "acme", input)
+
+		require.NoError(t, err)
+		assert.Equal(t, created, provider.CreatedAt)
+		assert.Equal(t, testNow, provider.UpdatedAt)
+		assert.False(t, repo.providers["acme"].Enabled)
+		assert.Equal(t, "https://idp.example.com", repo.providers["acme"].EntityID)
+	})
+}
+
+func TestDeleteProvider(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository(&domainSAML.IdentityProvider{Tenant: "acme"})
+	s := newTestService(testConfig, testDeps{repo: repo})
+
+	require.NoError(t, s.DeleteProvider(ctx, "acme"))
+	assert.Empty(t, repo.providers)
+	assert.True(t, errors.Is(s.DeleteProvider(ctx, "acme"), ErrIdentityProviderNotFound))
+}
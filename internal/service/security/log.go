@@ -0,0 +1,68 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// Log is a domainAudit.SecurityLog appending the security events of accounts
+// to the audit log, with the account owner as both actor and target
+type Log struct {
+	entries domainAudit.Repository
+	ids     idgen.Generator
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewLog creates a security log writing to entries
+func NewLog(entries domainAudit.Repository, ids idgen.Generator, logger *zap.Logger) *Log {
+	return &Log{
+		entries: entries,
+		ids:     ids,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Record appends the event, logging it when it cannot be stored
+func (l *Log) Record(ctx context.Context, userID uuid.UUID, action domainAudit.Action, details any) {
+	if err := l.record(ctx, userID, action, details); err != nil {
+		l.logger.Warn("Failed to record security event",
+			zap.String("user_id", userID.String()),
+			zap.String("action", string(action)),
+			zap.Error(err))
+	}
+}
+
+func (l *Log) record(ctx context.Context, userID uuid.UUID, action domainAudit.Action, details any) error {
+	id, err := l.ids.NewID()
+	if err != nil {
+		return fmt.Errorf("failed to generate audit log id: %w", err)
+	}
+
+	entry := &domainAudit.Entry{
+		ID:        id,
+		ActorID:   userID,
+		Action:    action,
+		TargetID:  userID,
+		ClientIP:  clientip.FromContext(ctx),
+		CreatedAt: l.now(),
+	}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit log details: %w", err)
+		}
+		entry.Details = string(data)
+	}
+	return l.entries.Create(ctx, entry)
+}
@@ -0,0 +1,79 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// recordingAuditRepository keeps the entries it is given
+type recordingAuditRepository struct {
+	domainAudit.Repository
+	entries []*domainAudit.Entry
+	err     error
+}
+
+func (r *recordingAuditRepository) Create(_ context.Context, entry *domainAudit.Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestLog_Record(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Date(2025, 7, 9, 8, 0, 0, 0, time.UTC)
+
+	newLog := func(entries domainAudit.Repository) *Log {
+		log := NewLog(entries, idgen.NewGenerator(idgen.StrategyUUIDv4), zap.NewNop())
+		log.now = func() time.Time { return now }
+		return log
+	}
+
+	t.Run("Owner Is Actor And Target", func(t *testing.T) {
+		entries := &recordingAuditRepository{}
+
+		newLog(entries).Record(clientip.NewContext(ctx, "203.0.113.7"), userID, domainAudit.ActionNewDeviceLogin, map[string]string{"client_ip": "203.0.113.7"})
+
+		require.Len(t, entries.entries, 1)
+		entry := entries.entries[0]
+		assert.NotEqual(t, uuid.Nil, entry.ID)
+		assert.Equal(t, userID, entry.ActorID)
+		assert.Equal(t, userID, entry.TargetID)
+		assert.Equal(t, domainAudit.ActionNewDeviceLogin, entry.Action)
+		assert.JSONEq(t, `{"client_ip":"203.0.113.7"}`, entry.Details)
+		assert.Equal(t, "203.0.113.7", entry.ClientIP)
+		assert.Equal(t, now, entry.CreatedAt)
+	})
+
+	t.Run("Nil Details Are Omitted", func(t *testing.T) {
+		entries := &recordingAuditRepository{}
+
+		newLog(entries).Record(ctx, userID, domainAudit.ActionChangePassword, nil)
+
+		require.Len(t, entries.entries, 1)
+		assert.Empty(t, entries.entries[0].Details)
+		assert.Empty(t, entries.entries[0].ClientIP, "no client outside of requests")
+	})
+
+	t.Run("Failure Is Not Returned", func(t *testing.T) {
+		entries := &recordingAuditRepository{err: errors.New("db down")}
+
+		assert.NotPanics(t, func() {
+			newLog(entries).Record(ctx, userID, domainAudit.ActionChangePassword, nil)
+		})
+		assert.Empty(t, entries.entries)
+	})
+}
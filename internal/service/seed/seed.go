@@ -0,0 +1,285 @@
+// Package seed loads development fixtures, such as an admin account, users
+// of every role and sample tenants, through the user and organization
+// services so they are stored exactly as if they had signed up.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	serviceOrganization "github.com/yi-tech/go-user-service/internal/service/organization"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// Fixtures describes the data a development environment starts with
+type Fixtures struct {
+	Users         []UserFixture         `yaml:"users"`
+	Organizations []OrganizationFixture `yaml:"organizations"`
+}
+
+// UserFixture is an account to create
+type UserFixture struct {
+	Email     string `yaml:"email"`
+	Password  string `yaml:"password"`
+	FirstName string `yaml:"first_name"`
+	LastName  string `yaml:"last_name"`
+	Role      string `yaml:"role"`      // One of the rbac roles; empty means user
+	Tenant    string `yaml:"tenant"`    // Organisation the account belongs to, if any
+	Residency string `yaml:"residency"` // Data residency region; empty means the default
+	Inactive  bool   `yaml:"inactive"`  // Create the account deactivated
+}
+
+// OrganizationFixture is an organization to create, with its members
+type OrganizationFixture struct {
+	Name    string          `yaml:"name"`
+	Slug    string          `yaml:"slug"`  // Derived from Name when empty
+	Owner   string          `yaml:"owner"` // Email of the user creating the organization
+	Members []MemberFixture `yaml:"members"`
+}
+
+// MemberFixture is a membership of an organization
+type MemberFixture struct {
+	Email   string `yaml:"email"`
+	Role    string `yaml:"role"`    // owner, admin or member; empty means member
+	Invited bool   `yaml:"invited"` // Leave the invitation pending instead of accepting it
+}
+
+// ReadFile reads fixtures from a YAML or JSON file
+func ReadFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// Parse decodes fixtures from YAML, or JSON since YAML is a superset of it,
+// and checks that they can be loaded. Unknown fields are rejected so typos
+// do not go unnoticed.
+func Parse(data []byte) (*Fixtures, error) {
+	var fixtures Fixtures
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fixtures); err != nil && !errors.Is(err, io.EOF) { // io.EOF: empty file
+		return nil, err
+	}
+	if err := fixtures.Validate(); err != nil {
+		return nil, err
+	}
+	return &fixtures, nil
+}
+
+// Validate reports every fixture that cannot be loaded
+func (f *Fixtures) Validate() error {
+	var errs []error
+	emails := map[string]bool{}
+	for i, user := range f.Users {
+		email := strings.ToLower(user.Email)
+		switch {
+		case user.Email == "":
+			errs = append(errs, fmt.Errorf("users[%d]: email is required", i))
+		case emails[email]:
+			errs = append(errs, fmt.Errorf("users[%d]: %s is listed twice", i, user.Email))
+		}
+		emails[email] = true
+		if user.Password == "" {
+			errs = append(errs, fmt.Errorf("users[%d]: password is required", i))
+		}
+		if user.Role != "" && !knownRole(rbac.Role(user.Role)) {
+			errs = append(errs, fmt.Errorf("users[%d]: role %q is not one of %s", i, user.Role, roleNames()))
+		}
+	}
+	for i, org := range f.Organizations {
+		if org.Name == "" {
+			errs = append(errs, fmt.Errorf("organizations[%d]: name is required", i))
+		}
+		if org.Owner == "" {
+			errs = append(errs, fmt.Errorf("organizations[%d]: owner is required", i))
+		}
+		for j, member := range org.Members {
+			if member.Email == "" {
+				errs = append(errs, fmt.Errorf("organizations[%d].members[%d]: email is required", i, j))
+			}
+			if member.Role != "" && !domainOrg.Role(member.Role).Valid() {
+				errs = append(errs, fmt.Errorf("organizations[%d].members[%d]: role %q is not one of owner, admin or member", i, j, member.Role))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// knownRole reports whether role is one of rbac.Roles
+func knownRole(role rbac.Role) bool {
+	return slices.ContainsFunc(rbac.Roles, func(d rbac.RoleDefinition) bool { return d.Name == role })
+}
+
+// roleNames lists the names of rbac.Roles for error messages
+func roleNames() string {
+	names := make([]string, 0, len(rbac.Roles))
+	for _, role := range rbac.Roles {
+		names = append(names, string(role.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// UserCreator builds, stores and finds users. serviceUser.UserService satisfies it.
+type UserCreator interface {
+	PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error)
+	CreateUsers(ctx context.Context, users []*domainUser.User) error
+	GetByEmail(ctx context.Context, email string) (*domainUser.User, error)
+}
+
+// OrganizationCreator creates organizations and adds their members.
+// serviceOrganization.Service satisfies it.
+type OrganizationCreator interface {
+	Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error)
+	List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error)
+	Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error)
+	AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error)
+}
+
+// Result counts what a load created and what already existed
+type Result struct {
+	UsersCreated          int
+	UsersExisting         int
+	OrganizationsCreated  int
+	OrganizationsExisting int
+	MembersAdded          int
+	MembersExisting       int
+}
+
+// Loader stores fixtures through the services
+type Loader struct {
+	users  UserCreator
+	orgs   OrganizationCreator
+	logger *zap.Logger
+}
+
+// NewLoader creates a Loader
+func NewLoader(users UserCreator, orgs OrganizationCreator, logger *zap.Logger) *Loader {
+	return &Loader{users: users, orgs: orgs, logger: logger}
+}
+
+// Load creates the users and then the organizations of fixtures. Loading is
+// idempotent: users whose email is taken, organizations their owner already
+// has under the same slug, and existing members are left as they are, so
+// fixtures can be loaded again after they are extended.
+func (l *Loader) Load(ctx context.Context, fixtures *Fixtures) (*Result, error) {
+	result := &Result{}
+	for _, fixture := range fixtures.Users {
+		if err := l.loadUser(ctx, fixture, result); err != nil {
+			return result, fmt.Errorf("user %s: %w", fixture.Email, err)
+		}
+	}
+	for _, fixture := range fixtures.Organizations {
+		if err := l.loadOrganization(ctx, fixture, result); err != nil {
+			return result, fmt.Errorf("organization %s: %w", fixture.Name, err)
+		}
+	}
+	return result, nil
+}
+
+func (l *Loader) loadUser(ctx context.Context, fixture UserFixture, result *Result) error {
+	user, err := l.users.PrepareUser(ctx, domainUser.RegisterUserInput{
+		Email:     fixture.Email,
+		Password:  fixture.Password,
+		FirstName: fixture.FirstName,
+		LastName:  fixture.LastName,
+		Residency: fixture.Residency,
+	})
+	if errors.Is(err, serviceUser.ErrUserAlreadyExists) {
+		result.UsersExisting++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fixture.Role != "" {
+		user.Role = rbac.Role(fixture.Role)
+	}
+	user.Tenant = fixture.Tenant
+	user.IsActive = !fixture.Inactive
+
+	if err := l.users.CreateUsers(ctx, []*domainUser.User{user}); err != nil {
+		return err
+	}
+	result.UsersCreated++
+	l.logger.Info("Seeded user", zap.String("email", user.Email), zap.String("role", string(user.Role)))
+	return nil
+}
+
+func (l *Loader) loadOrganization(ctx context.Context, fixture OrganizationFixture, result *Result) error {
+	owner, err := l.users.GetByEmail(ctx, fixture.Owner)
+	if err != nil {
+		return fmt.Errorf("owner %s: %w", fixture.Owner, err)
+	}
+
+	org, err := l.existingOrganization(ctx, owner.ID, fixture)
+	if err != nil {
+		return err
+	}
+	if org != nil {
+		result.OrganizationsExisting++
+	} else {
+		org, err = l.orgs.Create(ctx, owner.ID, domainOrg.Input{Name: fixture.Name, Slug: fixture.Slug})
+		if err != nil {
+			return err
+		}
+		result.OrganizationsCreated++
+		l.logger.Info("Seeded organization", zap.String("slug", org.Slug), zap.String("owner", owner.Email))
+	}
+
+	for _, member := range fixture.Members {
+		role := domainOrg.RoleMember
+		if member.Role != "" {
+			role = domainOrg.Role(member.Role)
+		}
+		invited, err := l.orgs.Invite(ctx, owner.ID, org.ID, member.Email, role)
+		if errors.Is(err, serviceOrganization.ErrAlreadyMember) {
+			result.MembersExisting++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("member %s: %w", member.Email, err)
+		}
+		if !member.Invited {
+			if _, err := l.orgs.AcceptInvitation(ctx, invited.UserID, org.ID); err != nil {
+				return fmt.Errorf("member %s: %w", member.Email, err)
+			}
+		}
+		result.MembersAdded++
+	}
+	return nil
+}
+
+// existingOrganization returns the organization of the owner matching the
+// fixture by slug, or by name when the fixture sets no slug; nil when there is none
+func (l *Loader) existingOrganization(ctx context.Context, ownerID uuid.UUID, fixture OrganizationFixture) (*domainOrg.Organization, error) {
+	orgs, err := l.orgs.List(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range orgs {
+		if (fixture.Slug != "" && org.Slug == fixture.Slug) || (fixture.Slug == "" && org.Name == fixture.Name) {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
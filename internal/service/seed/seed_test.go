@@ -0,0 +1,154 @@
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+	serviceOrganization "github.com/yi-tech/go-user-service/internal/service/organization"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// fakeOrganizations records organizations and memberships in memory
+type fakeOrganizations struct {
+	users   UserCreator
+	orgs    []*domainOrg.Organization
+	members map[uuid.UUID]map[uuid.UUID]*domainOrg.Member
+}
+
+func newFakeOrganizations(users UserCreator) *fakeOrganizations {
+	return &fakeOrganizations{users: users, members: map[uuid.UUID]map[uuid.UUID]*domainOrg.Member{}}
+}
+
+func (f *fakeOrganizations) Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error) {
+	org := &domainOrg.Organization{ID: uuid.New(), Name: input.Name, Slug: input.Slug}
+	f.orgs = append(f.orgs, org)
+	f.members[org.ID] = map[uuid.UUID]*domainOrg.Member{actorID: {OrganizationID: org.ID, UserID: actorID, Role: domainOrg.RoleOwner}}
+	return org, nil
+}
+
+func (f *fakeOrganizations) List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error) {
+	var orgs []*domainOrg.Organization
+	for _, org := range f.orgs {
+		if _, ok := f.members[org.ID][actorID]; ok {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs, nil
+}
+
+func (f *fakeOrganizations) Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error) {
+	user, err := f.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := f.members[orgID][user.ID]; ok {
+		return nil, serviceOrganization.ErrAlreadyMember
+	}
+	member := &domainOrg.Member{OrganizationID: orgID, UserID: user.ID, Role: role}
+	f.members[orgID][user.ID] = member
+	return member, nil
+}
+
+func (f *fakeOrganizations) AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error) {
+	return f.members[orgID][actorID], nil
+}
+
+const fixturesYAML = `
+users:
+  - email: admin@example.com
+    password: Admin123!
+    first_name: Ada
+    role: admin
+  - email: owner@example.com
+    password: Owner123!
+    tenant: acme
+  - email: member@example.com
+    password: Member123!
+    role: org_admin
+    tenant: acme
+    inactive: true
+organizations:
+  - name: Acme
+    slug: acme
+    owner: owner@example.com
+    members:
+      - email: member@example.com
+        role: admin
+      - email: admin@example.com
+        invited: true
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "Valid", data: fixturesYAML},
+		{name: "JSON", data: `{"users": [{"email": "a@example.com", "password": "x"}]}`},
+		{name: "Empty", data: ""},
+		{name: "Unknown Field", data: "users:\n  - email: a@example.com\n    passwd: x\n", err: "field passwd not found"},
+		{name: "Missing Password", data: "users:\n  - email: a@example.com\n", err: "users[0]: password is required"},
+		{name: "Duplicate Email", data: "users:\n  - {email: a@example.com, password: x}\n  - {email: A@example.com, password: x}\n", err: "users[1]: A@example.com is listed twice"},
+		{name: "Unknown Role", data: "users:\n  - {email: a@example.com, password: x, role: root}\n", err: `role "root" is not one of admin, support, org_admin, user`},
+		{name: "Missing Owner", data: "organizations:\n  - name: Acme\n", err: "organizations[0]: owner is required"},
+		{name: "Unknown Member Role", data: "organizations:\n  - {name: Acme, owner: a@example.com, members: [{email: b@example.com, role: boss}]}\n", err: "organizations[0].members[0]: role \"boss\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	fixtures, err := Parse([]byte(fixturesYAML))
+	require.NoError(t, err)
+
+	users := serviceUser.NewUserService(repoUser.NewInMemoryRepository())
+	orgs := newFakeOrganizations(users)
+	loader := NewLoader(users, orgs, zap.NewNop())
+
+	result, err := loader.Load(ctx, fixtures)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{UsersCreated: 3, OrganizationsCreated: 1, MembersAdded: 2}, result)
+
+	admin, err := users.GetByEmail(ctx, "admin@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, rbac.RoleAdmin, admin.Role)
+	assert.True(t, admin.CheckPassword("Admin123!"))
+
+	member, err := users.GetByEmail(ctx, "member@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, rbac.RoleOrgAdmin, member.Role)
+	assert.Equal(t, "acme", member.Tenant)
+	assert.False(t, member.IsActive)
+	assert.Equal(t, domainOrg.RoleAdmin, orgs.members[orgs.orgs[0].ID][member.ID].Role)
+
+	t.Run("Idempotent", func(t *testing.T) {
+		result, err := loader.Load(ctx, fixtures)
+		require.NoError(t, err)
+		assert.Equal(t, &Result{UsersExisting: 3, OrganizationsExisting: 1, MembersExisting: 2}, result)
+		assert.Len(t, orgs.orgs, 1)
+	})
+
+	t.Run("Unknown Owner", func(t *testing.T) {
+		_, err := loader.Load(ctx, &Fixtures{Organizations: []OrganizationFixture{{Name: "Globex", Owner: "nobody@example.com"}}})
+		assert.ErrorIs(t, err, serviceUser.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+)
+
+// AvailabilityChecker tells signup forms whether identifiers can still be used.
+type AvailabilityChecker interface {
+	// CheckAvailability reports whether the given email and/or username are free.
+	// Empty identifiers are skipped.
+	CheckAvailability(ctx context.Context, email, username string) (*domainUser.Availability, error)
+}
+
+type availabilityChecker struct {
+	userRepo   domainUser.Repository
+	addresses  emailaddr.Normalizer
+	minLatency time.Duration
+}
+
+// NewAvailabilityChecker creates a new AvailabilityChecker. Emails are looked
+// up in the form addresses normalizes them to, as registration does. Every
+// call takes at least minLatency so response times do not reveal whether a
+// lookup hit.
+func NewAvailabilityChecker(userRepo domainUser.Repository, addresses emailaddr.Normalizer, minLatency time.Duration) AvailabilityChecker {
+	return &availabilityChecker{userRepo: userRepo, addresses: addresses, minLatency: minLatency}
+}
+
+// CheckAvailability looks up every provided identifier and pads the call to minLatency
+func (c *availabilityChecker) CheckAvailability(ctx context.Context, email, username string) (*domainUser.Availability, error) {
+	deadline := time.Now().Add(c.minLatency)
+
+	result, err := c.lookup(ctx, email, username)
+
+	// Pad failures as well so errors are not a faster path than lookups
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return result, err
+}
+
+func (c *availabilityChecker) lookup(ctx context.Context, email, username string) (*domainUser.Availability, error) {
+	result := &domainUser.Availability{}
+
+	if email != "" {
+		existing, err := findByEmail(ctx, c.userRepo, c.addresses, email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check email availability: %w", err)
+		}
+		available := existing == nil
+		result.EmailAvailable = &available
+	}
+
+	if username != "" {
+		existing, err := c.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check username availability: %w", err)
+		}
+		available := existing == nil
+		result.UsernameAvailable = &available
+	}
+
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Email Taken, Username Free", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		checker := NewAvailabilityChecker(mockRepo, emailaddr.Normalizer{}, 0)
+
+		mockRepo.On("GetByEmail", ctx, "taken@example.com").Return(newTestUser("taken@example.com", "", "", ""), nil).Once()
+		mockRepo.On("GetByUsername", ctx, "newname").Return(nil, nil).Once()
+
+		result, err := checker.CheckAvailability(ctx, "taken@example.com", "newname")
+		require.NoError(t, err)
+		require.NotNil(t, result.EmailAvailable)
+		require.NotNil(t, result.UsernameAvailable)
+		assert.False(t, *result.EmailAvailable)
+		assert.True(t, *result.UsernameAvailable)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Only Email Checked", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		checker := NewAvailabilityChecker(mockRepo, emailaddr.Normalizer{}, 0)
+
+		mockRepo.On("GetByEmail", ctx, "free@example.com").Return(nil, nil).Once()
+
+		result, err := checker.CheckAvailability(ctx, "free@example.com", "")
+		require.NoError(t, err)
+		assert.True(t, *result.EmailAvailable)
+		assert.Nil(t, result.UsernameAvailable)
+		mockRepo.AssertNotCalled(t, "GetByUsername")
+	})
+
+	t.Run("Repository Error", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		checker := NewAvailabilityChecker(mockRepo, emailaddr.Normalizer{}, 0)
+
+		mockRepo.On("GetByEmail", ctx, "a@example.com").Return(nil, errors.New("db error")).Once()
+
+		result, err := checker.CheckAvailability(ctx, "a@example.com", "")
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Padded To Minimum Latency", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		minLatency := 50 * time.Millisecond
+		checker := NewAvailabilityChecker(mockRepo, emailaddr.Normalizer{}, minLatency)
+
+		mockRepo.On("GetByEmail", ctx, "free@example.com").Return(nil, nil).Once()
+
+		start := time.Now()
+		_, err := checker.CheckAvailability(ctx, "free@example.com", "")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), minLatency)
+	})
+}
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+// deadlineService runs the operations of a UserService within the read or
+// write deadline, reporting a passed deadline as deadline.ErrTimeout
+type deadlineService struct {
+	UserService
+	deadlines deadline.Deadlines
+}
+
+func (s *deadlineService) Register(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.UserService.Register(ctx, input)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	user, err := s.UserService.PrepareUser(ctx, input)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.UserService.CreateUsers(ctx, users))
+}
+
+func (s *deadlineService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	user, err := s.UserService.GetByID(ctx, id)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	users, err := s.UserService.GetByIDs(ctx, ids)
+	return users, deadline.Check(err)
+}
+
+func (s *deadlineService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForRead(ctx)
+	defer cancel()
+	user, err := s.UserService.GetByEmail(ctx, email)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) Update(ctx context.Context, id uuid.UUID, params domainUser.UpdateUserParams) (*domainUser.User, error) {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	user, err := s.UserService.Update(ctx, id, params)
+	return user, deadline.Check(err)
+}
+
+func (s *deadlineService) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.UserService.UpdatePassword(ctx, id, currentPassword, newPassword))
+}
+
+func (s *deadlineService) RehashPassword(ctx context.Context, user *domainUser.User, password string) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.UserService.RehashPassword(ctx, user, password))
+}
+
+func (s *deadlineService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := s.deadlines.ForWrite(ctx)
+	defer cancel()
+	return deadline.Check(s.UserService.DeleteUser(ctx, id))
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+)
+
+func TestWithDeadlines(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	// The repository hangs until the deadline of the operation passes
+	waitForDeadline := func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}
+
+	t.Run("Read Deadline", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo, WithDeadlines(deadline.Deadlines{Read: 10 * time.Millisecond, Write: time.Minute}))
+		mockRepo.On("GetByID", mock.Anything, id).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+		start := time.Now()
+		_, err := svc.GetByID(ctx, id)
+
+		assert.ErrorIs(t, err, deadline.ErrTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Write Deadline", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo, WithDeadlines(deadline.Deadlines{Read: time.Minute, Write: 10 * time.Millisecond}))
+		mockRepo.On("GetByID", mock.Anything, id).Run(waitForDeadline).Return(nil, context.DeadlineExceeded).Once()
+
+		assert.ErrorIs(t, svc.DeleteUser(ctx, id), deadline.ErrTimeout)
+	})
+
+	t.Run("Other Errors Unchanged", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo, WithDeadlines(deadline.Deadlines{Read: time.Minute}))
+		mockRepo.On("GetByID", mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := ctx.Deadline()
+			return ok
+		}), id).Return(nil, nil).Once()
+
+		_, err := svc.GetByID(ctx, id)
+
+		assert.Same(t, ErrUserNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("No Deadlines", func(t *testing.T) {
+		assert.IsType(t, &userService{}, NewUserService(new(MockUserRepository), WithDeadlines(deadline.Deadlines{})))
+	})
+}
@@ -1,11 +1,36 @@
 package user
 
-import "errors"
+import (
+	"fmt"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// MaxBatchIDs is the most users GetByIDs looks up at once
+const MaxBatchIDs = 100
+
+// MaxDisplayNameLength is the longest display name, in characters
+const MaxDisplayNameLength = 64
 
 // Service-level errors for user operations
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrEmailInUse        = errors.New("email already in use")
-	ErrIncorrectPassword = errors.New("incorrect current password")
-	ErrUserAlreadyExists = errors.New("user already exists") // Moved from user_service.go
+	ErrUserNotFound      = apperror.New(apperror.CodeUserNotFound, "user not found")
+	ErrEmailInUse        = apperror.New(apperror.CodeEmailInUse, "email already in use")
+	ErrEmailRequired     = apperror.New(apperror.CodeInvalidArgument, "email cannot be cleared")
+	ErrIncorrectPassword = apperror.New(apperror.CodeIncorrectPassword, "incorrect current password")
+	ErrUserAlreadyExists = apperror.New(apperror.CodeUserAlreadyExists, "user already exists") // Moved from user_service.go
+	ErrUnknownResidency  = apperror.New(apperror.CodeInvalidArgument, "residency must be a supported region")
+	ErrVersionMismatch   = apperror.New(apperror.CodeVersionMismatch, "user has been modified since it was read")
+	ErrUnsupportedLocale = apperror.New(apperror.CodeInvalidArgument, "locale must be a supported language")
+	ErrTooManyIDs        = apperror.New(apperror.CodeInvalidArgument, fmt.Sprintf("at most %d user IDs can be looked up at once", MaxBatchIDs))
+
+	ErrDisplayNameTooLong = apperror.New(apperror.CodeInvalidArgument,
+		fmt.Sprintf("display name must be at most %d characters", MaxDisplayNameLength))
+
+	ErrInvalidMetadataKey = apperror.New(apperror.CodeInvalidArgument,
+		fmt.Sprintf("metadata keys must be at most %d letters, digits, '_', '-' or '.'", MaxMetadataKeyLength))
+	ErrMetadataValueTooLong = apperror.New(apperror.CodeInvalidArgument,
+		fmt.Sprintf("metadata values must be at most %d characters", MaxMetadataValueLength))
+	ErrTooManyMetadataKeys = apperror.New(apperror.CodeInvalidArgument,
+		fmt.Sprintf("users can have at most %d metadata keys", MaxMetadataKeys))
 )
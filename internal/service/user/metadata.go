@@ -0,0 +1,44 @@
+package user
+
+import (
+	"maps"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Limits on the custom attributes of a user
+const (
+	MaxMetadataKeys        = 50
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 500
+)
+
+// metadataKey restricts keys to characters that need no escaping in query
+// parameters such as metadata[plan]=pro
+var metadataKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// mergeMetadata returns metadata with patch applied: non-nil values are set
+// and nil values removed. metadata itself is left unchanged.
+func mergeMetadata(metadata map[string]string, patch map[string]*string) (map[string]string, error) {
+	merged := maps.Clone(metadata)
+	if merged == nil {
+		merged = make(map[string]string, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		if len(key) > MaxMetadataKeyLength || !metadataKey.MatchString(key) {
+			return nil, ErrInvalidMetadataKey
+		}
+		if utf8.RuneCountInString(*value) > MaxMetadataValueLength {
+			return nil, ErrMetadataValueTooLong
+		}
+		merged[key] = *value
+	}
+	if len(merged) > MaxMetadataKeys {
+		return nil, ErrTooManyMetadataKeys
+	}
+	return merged, nil
+}
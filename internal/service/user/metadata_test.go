@@ -0,0 +1,78 @@
+package user
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	t.Run("Sets And Removes", func(t *testing.T) {
+		stored := map[string]string{"plan": "free", "team": "core"}
+
+		merged, err := mergeMetadata(stored, map[string]*string{"plan": stringPtr("pro"), "team": nil, "region": stringPtr("")})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"plan": "pro", "region": ""}, merged)
+		assert.Equal(t, map[string]string{"plan": "free", "team": "core"}, stored, "stored metadata must not change")
+	})
+
+	t.Run("Removing A Missing Key", func(t *testing.T) {
+		merged, err := mergeMetadata(nil, map[string]*string{"plan": nil})
+
+		require.NoError(t, err)
+		assert.Empty(t, merged)
+	})
+
+	tests := []struct {
+		name     string
+		stored   map[string]string
+		patch    map[string]*string
+		expected error
+	}{
+		{
+			name:     "Invalid Key",
+			patch:    map[string]*string{"plan tier": stringPtr("pro")},
+			expected: ErrInvalidMetadataKey,
+		},
+		{
+			name:     "Key Too Long",
+			patch:    map[string]*string{strings.Repeat("k", MaxMetadataKeyLength+1): stringPtr("v")},
+			expected: ErrInvalidMetadataKey,
+		},
+		{
+			name:     "Value Too Long",
+			patch:    map[string]*string{"bio": stringPtr(strings.Repeat("é", MaxMetadataValueLength+1))},
+			expected: ErrMetadataValueTooLong,
+		},
+		{
+			name:     "Too Many Keys",
+			stored:   metadataOfSize(MaxMetadataKeys),
+			patch:    map[string]*string{"extra": stringPtr("v")},
+			expected: ErrTooManyMetadataKeys,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mergeMetadata(tt.stored, tt.patch)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+
+	t.Run("Value At Limit", func(t *testing.T) {
+		_, err := mergeMetadata(metadataOfSize(MaxMetadataKeys), map[string]*string{"key0": stringPtr(strings.Repeat("é", MaxMetadataValueLength))})
+		assert.NoError(t, err)
+	})
+}
+
+// metadataOfSize returns metadata with n keys, key0 to key<n-1>
+func metadataOfSize(n int) map[string]string {
+	metadata := make(map[string]string, n)
+	for i := range n {
+		metadata["key"+strconv.Itoa(i)] = "v"
+	}
+	return metadata
+}
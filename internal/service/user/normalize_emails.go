@@ -0,0 +1,103 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+)
+
+// normalizeEmailsBatchSize is the number of users read per page by NormalizeEmails
+const normalizeEmailsBatchSize = 500
+
+// EmailNormalizationResult counts what NormalizeEmails did
+type EmailNormalizationResult struct {
+	Scanned int // Users read
+	Updated int // Users whose normalized email was rewritten
+	// Conflicts lists the emails left alone because another account already
+	// has their normalized form; those accounts must be merged by hand
+	Conflicts []string
+	// Completed reports that every stored address now has the form the
+	// normalization produces, which was recorded in the normalization
+	// repository
+	Completed bool
+}
+
+// ErrEmailNormalizationPending is returned by CheckEmailNormalization when
+// stored addresses may not have the form a normalization produces yet
+var ErrEmailNormalizationPending = errors.New("stored email addresses are not normalized yet")
+
+// NormalizeEmails rewrites the stored normalized email of every user to the
+// form addresses produces, so that lookups and the uniqueness of accounts
+// compare addresses normalized the same way. It backfills the column after
+// the migration adding it, which can only fold case in SQL, and must run
+// again whenever the normalization changes, e.g. when Gmail aliases start
+// being collapsed. It is safe to run again. When every address was
+// rewritten without conflicts, the normalization is recorded in
+// normalizations so that CheckEmailNormalization lets it be used. With
+// dryRun set nothing is changed and the result reports what would have been
+// rewritten.
+func NormalizeEmails(ctx context.Context, repo domainUser.Repository, normalizations domainUser.NormalizationRepository, addresses emailaddr.Normalizer, dryRun bool) (*EmailNormalizationResult, error) {
+	result := &EmailNormalizationResult{}
+	filter := domainUser.ListFilter{Limit: normalizeEmailsBatchSize}
+	afterID := uuid.Nil
+	for {
+		users, err := repo.ListAfter(ctx, filter, afterID)
+		if err != nil {
+			return result, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range users {
+			result.Scanned++
+			normalized := addresses.Normalize(user.Email)
+			if normalized == user.NormalizedEmail {
+				continue
+			}
+			if !dryRun {
+				user.NormalizedEmail = normalized
+				if err := repo.Update(ctx, user); err != nil {
+					if errors.Is(err, domainUser.ErrDuplicate) {
+						result.Conflicts = append(result.Conflicts, user.Email)
+						continue
+					}
+					return result, fmt.Errorf("failed to update user %s: %w", user.ID, err)
+				}
+			}
+			result.Updated++
+		}
+		if len(users) < filter.Limit {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+	if dryRun || len(result.Conflicts) > 0 {
+		return result, nil
+	}
+	if err := normalizations.RecordNormalization(ctx, addresses.Name()); err != nil {
+		return result, err
+	}
+	result.Completed = true
+	return result, nil
+}
+
+// CheckEmailNormalization returns ErrEmailNormalizationPending when
+// addresses collapses Gmail aliases but NormalizeEmails has not completed
+// with that normalization yet. Until then accounts stored with an alias are
+// not found by the address they were registered with, and the same mailbox
+// could register again under another alias.
+func CheckEmailNormalization(ctx context.Context, normalizations domainUser.NormalizationRepository, addresses emailaddr.Normalizer) error {
+	if !addresses.CollapseGmailAliases {
+		return nil
+	}
+	completed, err := normalizations.NormalizationCompleted(ctx, addresses.Name())
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return fmt.Errorf("%w with %s: run `make emails-normalize` before enabling collapse_gmail_aliases", ErrEmailNormalizationPending, addresses.Name())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+)
+
+func TestNormalizeEmails(t *testing.T) {
+	ctx := context.Background()
+	// Stored as the migration backfilled them, by folding case only
+	legacy := func(id, email, normalized string) *domainUser.User {
+		return &domainUser.User{ID: uuid.MustParse(id), Username: email, Email: email, NormalizedEmail: normalized}
+	}
+	repo := repoUser.NewInMemoryRepository(
+		legacy("00000000-0000-0000-0000-000000000001", "J.Doe@Gmail.com", "j.doe@gmail.com"),
+		legacy("00000000-0000-0000-0000-000000000002", "ana@Bücher.example.", "ana@bücher.example."),
+		legacy("00000000-0000-0000-0000-000000000003", "plain@example.com", "plain@example.com"),
+		legacy("00000000-0000-0000-0000-000000000004", "jdoe+shop@googlemail.com", "jdoe+shop@googlemail.com"),
+	)
+	normalizations := repoUser.NewInMemoryNormalizationRepository()
+	addresses := emailaddr.Normalizer{CollapseGmailAliases: true}
+
+	result, err := NormalizeEmails(ctx, repo, normalizations, addresses, true)
+	require.NoError(t, err)
+	assert.Equal(t, &EmailNormalizationResult{Scanned: 4, Updated: 3}, result)
+	user, err := repo.GetByEmail(ctx, "jdoe@gmail.com")
+	require.NoError(t, err)
+	assert.Nil(t, user, "a dry run changes nothing")
+
+	result, err = NormalizeEmails(ctx, repo, normalizations, addresses, false)
+	require.NoError(t, err)
+	assert.Equal(t, &EmailNormalizationResult{Scanned: 4, Updated: 2, Conflicts: []string{"jdoe+shop@googlemail.com"}}, result)
+
+	// Registering another spelling of an existing mailbox now finds the account
+	user, err = findByEmail(ctx, repo, addresses, "jdoe@gmail.com")
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, "J.Doe@Gmail.com", user.Email)
+	user, err = findByEmail(ctx, repo, addresses, "ana@bücher.example")
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, "ana@xn--bcher-kva.example", user.NormalizedEmail)
+
+	assert.ErrorIs(t, CheckEmailNormalization(ctx, normalizations, addresses), ErrEmailNormalizationPending, "conflicts leave the normalization incomplete")
+
+	// Once the conflicting account is merged away, the normalization completes
+	require.NoError(t, repo.Delete(ctx, uuid.MustParse("00000000-0000-0000-0000-000000000004")))
+	result, err = NormalizeEmails(ctx, repo, normalizations, addresses, false)
+	require.NoError(t, err)
+	assert.Equal(t, &EmailNormalizationResult{Scanned: 3, Completed: true}, result, "normalized emails are left alone")
+	assert.NoError(t, CheckEmailNormalization(ctx, normalizations, addresses))
+}
+
+func TestCheckEmailNormalization(t *testing.T) {
+	ctx := context.Background()
+	collapsing := emailaddr.Normalizer{CollapseGmailAliases: true}
+
+	err := CheckEmailNormalization(ctx, repoUser.NewInMemoryNormalizationRepository(), collapsing)
+	assert.ErrorIs(t, err, ErrEmailNormalizationPending)
+	assert.ErrorContains(t, err, "make emails-normalize")
+
+	assert.NoError(t, CheckEmailNormalization(ctx, repoUser.NewInMemoryNormalizationRepository(), emailaddr.Normalizer{}), "the standard normalization needs no backfill")
+	assert.NoError(t, CheckEmailNormalization(ctx, repoUser.NewInMemoryNormalizationRepository(emailaddr.NormalizationGmailAliases), collapsing))
+	assert.ErrorIs(t, CheckEmailNormalization(ctx, repoUser.NewInMemoryNormalizationRepository(emailaddr.NormalizationStandard), collapsing), ErrEmailNormalizationPending)
+}
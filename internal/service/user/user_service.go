@@ -4,10 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainCompliance "github.com/yi-tech/go-user-service/internal/domain/compliance"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/i18n"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/password"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	"github.com/yi-tech/go-user-service/internal/service/deadline"
+	"github.com/yi-tech/go-user-service/internal/tx"
 	"gorm.io/gorm"
 )
 
@@ -16,9 +31,20 @@ type UserService interface {
 	// Register creates a new user
 	Register(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error)
 
+	// PrepareUser validates input and builds a new user with a hashed
+	// password, as Register does, without storing it
+	PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error)
+
+	// CreateUsers stores users built by PrepareUser in a single transaction
+	CreateUsers(ctx context.Context, users []*domainUser.User) error
+
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
 
+	// GetByIDs retrieves up to MaxBatchIDs users in the order of ids. Users
+	// that do not exist are skipped and repeated IDs are returned once.
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error)
+
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (*domainUser.User, error)
 
@@ -28,23 +54,197 @@ type UserService interface {
 	// UpdatePassword changes a user's password
 	UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error
 
+	// RehashPassword upgrades the stored hash of a user who has just proven
+	// their password, when it was made with another algorithm or other
+	// parameters than new hashes are
+	RehashPassword(ctx context.Context, user *domainUser.User, password string) error
+
 	// DeleteUser removes a user
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
 
 type userService struct {
-	userRepo domainUser.Repository
+	userRepo  domainUser.Repository
+	ids       idgen.Generator
+	residency domainCompliance.ResidencyPolicy // Optional; nil accepts any residency
+	hasher    *password.Hasher                 // Hashes new passwords
+	notifier  domainNotification.Notifier      // Sends security alerts
+	events    domainEvent.Publisher            // Tells connected clients about profile changes
+	security  domainAudit.SecurityLog          // Records password changes for their owner to review
+	captcha   captcha.Verifier                 // Optional; checks the CAPTCHA token of registrations
+	emails    domainUser.EmailPolicy           // Optional; restricts the addresses accounts may use
+	addresses emailaddr.Normalizer             // Normalizes emails for lookups and uniqueness
+	deadlines deadline.Deadlines               // Bound each operation; zero leaves them to the caller
+	tx        tx.Manager                       // Optional; stores a registration and its security event together
+}
+
+// Option customizes a UserService
+type Option func(*userService)
+
+// WithIDGenerator assigns IDs to new users with the given generator instead of UUIDv4
+func WithIDGenerator(ids idgen.Generator) Option {
+	return func(s *userService) {
+		s.ids = ids
+	}
+}
+
+// WithPasswordHasher hashes new passwords with hasher instead of Argon2id
+// with password.DefaultArgon2Params
+func WithPasswordHasher(hasher *password.Hasher) Option {
+	return func(s *userService) {
+		s.hasher = hasher
+	}
+}
+
+// WithResidencyPolicy rejects registrations whose residency is not a
+// region known to the policy
+func WithResidencyPolicy(policy domainCompliance.ResidencyPolicy) Option {
+	return func(s *userService) {
+		s.residency = policy
+	}
+}
+
+// WithNotifier sends security alerts, such as after a password change,
+// through notifier instead of dropping them
+func WithNotifier(notifier domainNotification.Notifier) Option {
+	return func(s *userService) {
+		s.notifier = notifier
+	}
+}
+
+// WithSecurityLog records password changes among the security events of
+// the account instead of dropping them
+func WithSecurityLog(security domainAudit.SecurityLog) Option {
+	return func(s *userService) {
+		s.security = security
+	}
+}
+
+// WithEventPublisher tells the clients connected by a user when their
+// profile changes, and admin dashboards when accounts are registered or
+// deleted, through events instead of dropping them
+func WithEventPublisher(events domainEvent.Publisher) Option {
+	return func(s *userService) {
+		s.events = events
+	}
+}
+
+// WithRegistrationCaptcha requires every registration to carry a CAPTCHA
+// token that verifier accepts
+func WithRegistrationCaptcha(verifier captcha.Verifier) Option {
+	return func(s *userService) {
+		s.captcha = verifier
+	}
+}
+
+// WithEmailPolicy rejects registrations and email changes to addresses
+// policy does not accept
+func WithEmailPolicy(policy domainUser.EmailPolicy) Option {
+	return func(s *userService) {
+		s.emails = policy
+	}
+}
+
+// WithEmailNormalizer normalizes email addresses with normalizer instead of
+// only folding their case and encoding their domain
+func WithEmailNormalizer(normalizer emailaddr.Normalizer) Option {
+	return func(s *userService) {
+		s.addresses = normalizer
+	}
+}
+
+// withinTransaction runs fn in a transaction of the tx.Manager given with
+// WithTxManager, or directly without one
+func (s *userService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+	return s.tx.WithinTransaction(ctx, fn)
 }
 
-// NewUserService creates a new instance of UserService.
-func NewUserService(userRepo domainUser.Repository) UserService {
-	return &userService{userRepo: userRepo}
+// WithTxManager stores a new account and the registration event of its
+// security log in one transaction of tx, so neither is kept without the other
+func WithTxManager(tx tx.Manager) Option {
+	return func(s *userService) {
+		s.tx = tx
+	}
+}
+
+// WithDeadlines runs lookups within the read deadline and registrations,
+// updates and deletions within the write deadline of deadlines. Operations
+// that run out of time fail with deadline.ErrTimeout.
+func WithDeadlines(deadlines deadline.Deadlines) Option {
+	return func(s *userService) {
+		s.deadlines = deadlines
+	}
+}
+
+// NewUserService creates a new instance of UserService. New users get UUIDv4
+// IDs unless WithIDGenerator is given.
+func NewUserService(userRepo domainUser.Repository, opts ...Option) UserService {
+	s := &userService{
+		userRepo: userRepo,
+		ids:      idgen.GeneratorFunc(uuid.NewRandom),
+		hasher:   password.NewDefaultHasher(),
+		notifier: domainNotification.Discard,
+		events:   domainEvent.Discard,
+		security: domainAudit.DiscardSecurityLog,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if !s.deadlines.IsZero() {
+		return &deadlineService{UserService: s, deadlines: s.deadlines}
+	}
+	return s
 }
 
 // Register creates a new user with the provided credentials
 func (s *userService) Register(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
-	// Check if user already exists
-	existingUser, err := s.userRepo.GetByEmail(ctx, input.Email)
+	if s.captcha != nil {
+		if err := s.captcha.Verify(ctx, input.CaptchaToken, input.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+	if s.emails != nil {
+		if err := s.emails.CheckEmail("", input.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.PrepareUser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		// Save user to database; a concurrent registration of the address may
+		// have been stored since PrepareUser checked it
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		s.security.Record(ctx, user.ID, domainAudit.ActionRegister, nil)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domainUser.ErrDuplicate) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserRegistered, UserID: user.ID})
+	return user, nil
+}
+
+func (s *userService) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	residency := strings.ToUpper(input.Residency)
+	if residency != "" && s.residency != nil && !s.residency.KnownRegion(residency) {
+		return nil, ErrUnknownResidency
+	}
+
+	// Check if user already exists under any spelling of the address
+	existingUser, err := findByEmail(ctx, s.userRepo, s.addresses, input.Email)
 	if err != nil {
 		// If GORM's record not found, it's not an error for this check, means email is available
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -56,33 +256,49 @@ func (s *userService) Register(ctx context.Context, input domainUser.RegisterUse
 		return nil, ErrUserAlreadyExists
 	}
 
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate user id: %w", err)
+	}
+
 	// Create new user
+	now := time.Now()
 	user := &domainUser.User{
-		ID:        uuid.New(),
-		Username:  input.Email, // Set username to email to satisfy the not-null constraint
-		Email:     input.Email,
-		Password:  input.Password,
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                id,
+		Username:          input.Email, // Set username to email to satisfy the not-null constraint
+		Email:             input.Email,
+		NormalizedEmail:   s.addresses.Normalize(input.Email),
+		Password:          input.Password,
+		FirstName:         input.FirstName,
+		LastName:          input.LastName,
+		Residency:         residency,
+		Role:              rbac.RoleUser,
+		IsActive:          true,
+		PasswordChangedAt: now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	// Hash password
-	if err := user.HashPassword(); err != nil {
+	if err := user.HashPassword(s.hasher); err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Save user to database
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
 	return user, nil
 }
 
+func (s *userService) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	if err := s.userRepo.CreateBatch(ctx, users); err != nil {
+		if errors.Is(err, domainUser.ErrDuplicate) {
+			return ErrUserAlreadyExists
+		}
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+	return nil
+}
+
 func (s *userService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
-	user, err := s.userRepo.GetByEmail(ctx, email)
+	user, err := findByEmail(ctx, s.userRepo, s.addresses, email)
 	if err != nil {
 		// Assuming repo returns gorm.ErrRecordNotFound which should be translated
 		// For now, let's expect direct error or nil user from repo for not found
@@ -94,6 +310,18 @@ func (s *userService) GetByEmail(ctx context.Context, email string) (*domainUser
 	return user, nil
 }
 
+// findByEmail returns the user with email in any of its normalized forms,
+// or nil when there is none
+func findByEmail(ctx context.Context, repo domainUser.Repository, addresses emailaddr.Normalizer, email string) (*domainUser.User, error) {
+	for _, form := range addresses.Forms(email) {
+		user, err := repo.GetByEmail(ctx, form)
+		if err != nil || user != nil {
+			return user, err
+		}
+	}
+	return nil, nil
+}
+
 func (s *userService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
@@ -107,6 +335,39 @@ func (s *userService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.Us
 	return user, nil
 }
 
+func (s *userService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchIDs {
+		return nil, ErrTooManyIDs
+	}
+	if len(unique) == 0 {
+		return []*domainUser.User{}, nil
+	}
+
+	found, err := s.userRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by ids from repository: %w", err)
+	}
+	byID := make(map[uuid.UUID]*domainUser.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	users := make([]*domainUser.User, 0, len(found))
+	for _, id := range unique {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (s *userService) Update(ctx context.Context, id uuid.UUID, params domainUser.UpdateUserParams) (*domainUser.User, error) {
 	// Get existing user
 	existingUser, err := s.userRepo.GetByID(ctx, id)
@@ -116,37 +377,86 @@ func (s *userService) Update(ctx context.Context, id uuid.UUID, params domainUse
 	if existingUser == nil {
 		return nil, ErrUserNotFound
 	}
+	// Checked against the row just read, so an update landing between this
+	// read and the write below can still be overwritten
+	if len(params.Versions) > 0 && !slices.Contains(params.Versions, existingUser.Version()) {
+		return nil, ErrVersionMismatch
+	}
 
+	if params.Email != nil && *params.Email == "" {
+		return nil, ErrEmailRequired
+	}
 	// Check if email is being changed and if it's already in use
-	if params.Email != "" && params.Email != existingUser.Email {
+	if params.Email != nil && *params.Email != existingUser.Email {
+		if s.emails != nil {
+			if err := s.emails.CheckEmail(existingUser.Tenant, *params.Email); err != nil {
+				return nil, err
+			}
+		}
 		// Need to handle potential errors from GetByEmail itself
-		conflictingUser, err := s.userRepo.GetByEmail(ctx, params.Email)
+		conflictingUser, err := findByEmail(ctx, s.userRepo, s.addresses, *params.Email)
 		if err != nil {
 			// If GORM's record not found, it's not an error for this check, means email is available for use by current user
 			if !errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, fmt.Errorf("failed to check email availability: %w", err)
 			}
 		}
-		if conflictingUser != nil {
+		// Respelling the own address, e.g. changing its case, is no conflict
+		if conflictingUser != nil && conflictingUser.ID != existingUser.ID {
 			return nil, ErrEmailInUse
 		}
-		existingUser.Email = params.Email
+		existingUser.Email = *params.Email
+		existingUser.NormalizedEmail = s.addresses.Normalize(*params.Email)
 	}
 
-	// Update other fields if provided
-	if params.FirstName != "" {
-		existingUser.FirstName = params.FirstName
+	// Update other fields if provided, clearing them when empty
+	if params.FirstName != nil {
+		existingUser.FirstName = *params.FirstName
 	}
 
-	if params.LastName != "" {
-		existingUser.LastName = params.LastName
+	if params.LastName != nil {
+		existingUser.LastName = *params.LastName
+	}
+
+	if params.DisplayName != nil {
+		displayName := strings.TrimSpace(*params.DisplayName)
+		if utf8.RuneCountInString(displayName) > MaxDisplayNameLength {
+			return nil, ErrDisplayNameTooLong
+		}
+		existingUser.DisplayName = displayName
+	}
+
+	if params.Locale != nil {
+		// Kept as the primary language subtag, so "zh-CN" is stored as "zh"
+		locale := ""
+		if *params.Locale != "" {
+			lang, ok := i18n.Parse(*params.Locale)
+			if !ok {
+				return nil, ErrUnsupportedLocale
+			}
+			locale = string(lang)
+		}
+		existingUser.Locale = locale
+	}
+
+	if len(params.Metadata) > 0 {
+		metadata, err := mergeMetadata(existingUser.Metadata, params.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		existingUser.Metadata = metadata
 	}
 
 	// Update user
 	if err := s.userRepo.Update(ctx, existingUser); err != nil {
+		// Another user took the new email since it was checked
+		if errors.Is(err, domainUser.ErrDuplicate) {
+			return nil, ErrEmailInUse
+		}
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeProfileUpdated, UserID: id})
 	return existingUser, nil
 }
 
@@ -161,7 +471,12 @@ func (s *userService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// Delete user
-	return s.userRepo.Delete(ctx, id)
+	if err := s.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserDeleted, UserID: id})
+	return nil
 }
 
 func (s *userService) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
@@ -179,9 +494,13 @@ func (s *userService) UpdatePassword(ctx context.Context, id uuid.UUID, currentP
 		return ErrIncorrectPassword
 	}
 
-	// Update password
+	// Update password; this satisfies any administrator-forced reset and
+	// restarts the max age of the password
 	existingUser.Password = newPassword
-	if err := existingUser.HashPassword(); err != nil {
+	existingUser.PasswordResetRequired = false
+	existingUser.PasswordChangedAt = time.Now()
+	existingUser.PasswordExpiresAt = nil
+	if err := existingUser.HashPassword(s.hasher); err != nil {
 		return fmt.Errorf("failed to hash new password: %w", err)
 	}
 
@@ -189,5 +508,26 @@ func (s *userService) UpdatePassword(ctx context.Context, id uuid.UUID, currentP
 	if err := s.userRepo.Update(ctx, existingUser); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+
+	// Alert the user in case someone else knew their password
+	s.security.Record(ctx, existingUser.ID, domainAudit.ActionChangePassword, nil)
+	s.notifier.Notify(ctx, domainNotification.KindPasswordChanged, domainNotification.RecipientOf(existingUser))
+	return nil
+}
+
+func (s *userService) RehashPassword(ctx context.Context, user *domainUser.User, plain string) error {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return nil
+	}
+
+	rehashed := *user
+	rehashed.Password = plain
+	if err := rehashed.HashPassword(s.hasher); err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
+	}
+	if err := s.userRepo.Update(ctx, &rehashed); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	user.Password = rehashed.Password
 	return nil
 }
@@ -3,18 +3,38 @@ package user
 import (
 	"context"
 	"errors" // Added for errors.New
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt" // Added for bcrypt in TestUpdatePassword
 	"gorm.io/gorm"               // For gorm.ErrRecordNotFound
 
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainNotification "github.com/yi-tech/go-user-service/internal/domain/notification"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/password"
+	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	"github.com/yi-tech/go-user-service/internal/service/emailpolicy"
+	serviceCompliance "github.com/yi-tech/go-user-service/internal/service/compliance"
 )
 
+// stringPtr returns a pointer to s, for the optional fields of UpdateUserParams
+func stringPtr(s string) *string {
+	return &s
+}
+
 // MockUserRepository is a mock implementation of the domainUser.Repository interface
 type MockUserRepository struct {
 	mock.Mock
@@ -25,6 +45,11 @@ func (m *MockUserRepository) Create(ctx context.Context, user *domainUser.User)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*domainUser.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -33,6 +58,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -41,6 +74,14 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*dom
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*domainUser.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user *domainUser.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -51,6 +92,22 @@ func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) List(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainUser.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, filter, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
 // Helper to create a new user for testing
 func newTestUser(email, password, firstName, lastName string) *domainUser.User {
 	return &domainUser.User{
@@ -64,6 +121,24 @@ func newTestUser(email, password, firstName, lastName string) *domainUser.User {
 	}
 }
 
+// mustRules creates the email domain rules of cfg
+func mustRules(t *testing.T, cfg config.EmailDomainRules) *emailpolicy.Rules {
+	t.Helper()
+	rules, err := emailpolicy.NewRules(cfg)
+	require.NoError(t, err)
+	return rules
+}
+
+// fakeCaptcha accepts a single token
+type fakeCaptcha struct{ valid string }
+
+func (f fakeCaptcha) Verify(_ context.Context, token, _ string) error {
+	if token != f.valid {
+		return captcha.ErrCaptchaFailed
+	}
+	return nil
+}
+
 func TestRegister(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	userService := NewUserService(mockRepo)
@@ -93,6 +168,123 @@ func TestRegister(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Residency Is Normalized", func(t *testing.T) {
+		mockRepo.On("GetByEmail", ctx, "eu@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		userInput := domainUser.RegisterUserInput{
+			Email:     "eu@example.com",
+			Password:  "password123",
+			FirstName: "Eu",
+			LastName:  "User",
+			Residency: "eu",
+		}
+		createdUser, err := userService.Register(ctx, userInput)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "EU", createdUser.Residency)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Residency Is Rejected", func(t *testing.T) {
+		policy, err := serviceCompliance.NewResidencyPolicy(config.ComplianceConfig{Regions: []string{"US", "EU"}})
+		require.NoError(t, err)
+		svc := NewUserService(mockRepo, WithResidencyPolicy(policy))
+
+		_, err = svc.Register(ctx, domainUser.RegisterUserInput{
+			Email:     "mars@example.com",
+			Password:  "password123",
+			FirstName: "Mars",
+			LastName:  "User",
+			Residency: "mars",
+		})
+
+		assert.ErrorIs(t, err, ErrUnknownResidency)
+		mockRepo.AssertNotCalled(t, "GetByEmail", ctx, "mars@example.com")
+	})
+
+	t.Run("Captcha Is Required", func(t *testing.T) {
+		svc := NewUserService(mockRepo, WithRegistrationCaptcha(fakeCaptcha{valid: "human"}))
+		input := domainUser.RegisterUserInput{
+			Email:     "bot@example.com",
+			Password:  "password123",
+			FirstName: "Bot",
+			LastName:  "User",
+			ClientIP:  "203.0.113.7",
+		}
+
+		_, err := svc.Register(ctx, input)
+		assert.ErrorIs(t, err, captcha.ErrCaptchaFailed)
+
+		input.CaptchaToken = "robot"
+		_, err = svc.Register(ctx, input)
+		assert.ErrorIs(t, err, captcha.ErrCaptchaFailed)
+		mockRepo.AssertNotCalled(t, "GetByEmail", ctx, "bot@example.com")
+
+		mockRepo.On("GetByEmail", ctx, "bot@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+		input.CaptchaToken = "human"
+		_, err = svc.Register(ctx, input)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email Policy Is Enforced", func(t *testing.T) {
+		policy, err := emailpolicy.FromConfig(config.EmailDomainsConfig{Global: config.EmailDomainRules{BlockDisposable: true}})
+		require.NoError(t, err)
+		svc := NewUserService(mockRepo, WithEmailPolicy(policy))
+
+		_, err = svc.Register(ctx, domainUser.RegisterUserInput{
+			Email:     "bot@mailinator.com",
+			Password:  "password123",
+			FirstName: "Bot",
+			LastName:  "User",
+		})
+
+		assert.ErrorIs(t, err, emailpolicy.ErrDisposableEmail)
+		assert.Equal(t, apperror.CodeEmailPolicyViolation, apperror.CodeOf(err))
+		mockRepo.AssertNotCalled(t, "GetByEmail", ctx, "bot@mailinator.com")
+	})
+
+	t.Run("Uses Configured ID Generator", func(t *testing.T) {
+		fixedID := uuid.MustParse("01890a5d-ac96-774b-bcce-b302099a8057")
+		svc := NewUserService(mockRepo, WithIDGenerator(idgen.GeneratorFunc(func() (uuid.UUID, error) {
+			return fixedID, nil
+		})))
+		mockRepo.On("GetByEmail", ctx, "v7@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		createdUser, err := svc.Register(ctx, domainUser.RegisterUserInput{
+			Email:     "v7@example.com",
+			Password:  "password123",
+			FirstName: "Time",
+			LastName:  "Ordered",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, fixedID, createdUser.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Uses Configured Password Hasher", func(t *testing.T) {
+		svc := NewUserService(mockRepo, WithPasswordHasher(bcryptHasher(t)))
+		mockRepo.On("GetByEmail", ctx, "cost@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		createdUser, err := svc.Register(ctx, domainUser.RegisterUserInput{
+			Email:     "cost@example.com",
+			Password:  "password123",
+			FirstName: "Cost",
+			LastName:  "User",
+		})
+
+		require.NoError(t, err)
+		cost, err := bcrypt.Cost([]byte(createdUser.Password))
+		require.NoError(t, err)
+		assert.Equal(t, bcrypt.MinCost, cost)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("User Already Exists", func(t *testing.T) {
 		existingUser := newTestUser("exists@example.com", "password123", "Existing", "User")
 		mockRepo.On("GetByEmail", ctx, existingUser.Email).Return(existingUser, nil).Once() // User found
@@ -111,6 +303,27 @@ func TestRegister(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Email Is Normalized", func(t *testing.T) {
+		mockRepo.On("GetByEmail", ctx, "mixed@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		createdUser, err := userService.Register(ctx, domainUser.RegisterUserInput{Email: "Mixed@Example.COM", Password: "password123", FirstName: "Mixed", LastName: "Case"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Mixed@Example.COM", createdUser.Email, "the address is kept as typed")
+		assert.Equal(t, "mixed@example.com", createdUser.NormalizedEmail)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Differently Cased Email Already Exists", func(t *testing.T) {
+		mockRepo.On("GetByEmail", ctx, "exists@example.com").Return(newTestUser("exists@example.com", "password123", "Existing", "User"), nil).Once()
+
+		_, err := userService.Register(ctx, domainUser.RegisterUserInput{Email: "Exists@Example.com", Password: "newpass", FirstName: "New", LastName: "User"})
+
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Repository Error on GetByEmail", func(t *testing.T) {
 		mockRepo.On("GetByEmail", ctx, "error@example.com").Return(nil, errors.New("db error on get")).Once()
 
@@ -146,9 +359,133 @@ func TestRegister(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Concurrent Registration Of The Email", func(t *testing.T) {
+		// Both registrations passed the existence check; the database rejects the second
+		mockRepo.On("GetByEmail", ctx, "race@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*user.User")).Return(fmt.Errorf("%w: unique violation", domainUser.ErrDuplicate)).Once()
+
+		createdUser, err := userService.Register(ctx, domainUser.RegisterUserInput{Email: "race@example.com", Password: "password", FirstName: "Race", LastName: "User"})
+
+		assert.Same(t, ErrUserAlreadyExists, err)
+		assert.Nil(t, createdUser)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stored With Its Security Event In One Transaction", func(t *testing.T) {
+		tx := &fakeTxManager{}
+		security := &recordingSecurityLog{}
+		svc := NewUserService(mockRepo, WithTxManager(tx), WithSecurityLog(security))
+		mockRepo.On("GetByEmail", ctx, "tx@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		createdUser, err := svc.Register(ctx, domainUser.RegisterUserInput{Email: "tx@example.com", Password: "password", FirstName: "Tx", LastName: "User"})
+
+		require.NoError(t, err)
+		assert.True(t, tx.committed)
+		assert.Equal(t, []uuid.UUID{createdUser.ID}, security.users)
+		assert.Equal(t, []domainAudit.Action{domainAudit.ActionRegister}, security.actions)
+		assert.True(t, inTx(security.contexts[0]), "the security event is stored in the transaction")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed Create Rolls Back", func(t *testing.T) {
+		tx := &fakeTxManager{}
+		security := &recordingSecurityLog{}
+		svc := NewUserService(mockRepo, WithTxManager(tx), WithSecurityLog(security))
+		mockRepo.On("GetByEmail", ctx, "txrace@example.com").Return(nil, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*user.User")).Return(fmt.Errorf("%w: unique violation", domainUser.ErrDuplicate)).Once()
+
+		_, err := svc.Register(ctx, domainUser.RegisterUserInput{Email: "txrace@example.com", Password: "password", FirstName: "Tx", LastName: "Race"})
+
+		assert.Same(t, ErrUserAlreadyExists, err)
+		assert.True(t, tx.rolledBack)
+		assert.Empty(t, security.actions)
+		mockRepo.AssertExpectations(t)
+	})
+
 	// Test for password hashing error is hard to induce reliably without direct control over bcrypt or OS resources.
 }
 
+// txKey marks contexts handed out by fakeTxManager
+type txKey struct{}
+
+// fakeTxManager runs fn with a marked context and records whether the
+// transaction would have been committed
+type fakeTxManager struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (f *fakeTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		f.rolledBack = true
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+// inTx reports whether ctx belongs to a fakeTxManager transaction
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+func TestPrepareUserAndCreateUsers(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("Prepare Does Not Store", func(t *testing.T) {
+		mockRepo.On("GetByEmail", ctx, "new@example.com").Return(nil, nil).Once()
+
+		user, err := userService.PrepareUser(ctx, domainUser.RegisterUserInput{
+			Email:     "new@example.com",
+			Password:  "password123",
+			FirstName: "New",
+			LastName:  "User",
+		})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, user.ID)
+		assert.True(t, user.CheckPassword("password123"))
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Prepare Rejects Existing Email", func(t *testing.T) {
+		mockRepo.On("GetByEmail", ctx, "taken@example.com").Return(&domainUser.User{Email: "taken@example.com"}, nil).Once()
+
+		_, err := userService.PrepareUser(ctx, domainUser.RegisterUserInput{Email: "taken@example.com", Password: "password123"})
+
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	})
+
+	t.Run("Create Users", func(t *testing.T) {
+		users := []*domainUser.User{{ID: uuid.New()}, {ID: uuid.New()}}
+		mockRepo.On("CreateBatch", ctx, users).Return(nil).Once()
+
+		assert.NoError(t, userService.CreateUsers(ctx, users))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Users Error", func(t *testing.T) {
+		users := []*domainUser.User{{ID: uuid.New()}}
+		mockRepo.On("CreateBatch", ctx, users).Return(errors.New("duplicate key")).Once()
+
+		err := userService.CreateUsers(ctx, users)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create users")
+	})
+
+	t.Run("Create Users Duplicate", func(t *testing.T) {
+		users := []*domainUser.User{{ID: uuid.New()}}
+		mockRepo.On("CreateBatch", ctx, users).Return(domainUser.ErrDuplicate).Once()
+
+		assert.Same(t, ErrUserAlreadyExists, userService.CreateUsers(ctx, users))
+	})
+}
+
 func TestGetByID(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	userService := NewUserService(mockRepo)
@@ -205,6 +542,55 @@ func TestGetByID(t *testing.T) {
 	})
 }
 
+func TestGetByIDs(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo)
+	ctx := context.Background()
+
+	first := newTestUser("first@example.com", "password", "First", "User")
+	second := newTestUser("second@example.com", "password", "Second", "User")
+	missingID := uuid.New()
+
+	t.Run("In Request Order Without Duplicates", func(t *testing.T) {
+		mockRepo.On("GetByIDs", ctx, []uuid.UUID{second.ID, missingID, first.ID}).Return([]*domainUser.User{first, second}, nil).Once()
+
+		users, err := userService.GetByIDs(ctx, []uuid.UUID{second.ID, missingID, first.ID, second.ID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*domainUser.User{second, first}, users)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		users, err := userService.GetByIDs(ctx, nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("Too Many IDs", func(t *testing.T) {
+		ids := make([]uuid.UUID, MaxBatchIDs+1)
+		for i := range ids {
+			ids[i] = uuid.New()
+		}
+
+		users, err := userService.GetByIDs(ctx, ids)
+
+		assert.ErrorIs(t, err, ErrTooManyIDs)
+		assert.Nil(t, users)
+	})
+
+	t.Run("Repository Error", func(t *testing.T) {
+		mockRepo.On("GetByIDs", ctx, []uuid.UUID{missingID}).Return(nil, errors.New("db error")).Once()
+
+		users, err := userService.GetByIDs(ctx, []uuid.UUID{missingID})
+
+		assert.ErrorContains(t, err, "db error")
+		assert.Nil(t, users)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestGetByEmail(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	userService := NewUserService(mockRepo)
@@ -224,6 +610,20 @@ func TestGetByEmail(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Collapsed Gmail Alias", func(t *testing.T) {
+		svc := NewUserService(mockRepo, WithEmailNormalizer(emailaddr.Normalizer{CollapseGmailAliases: true}))
+		stored := newTestUser("j.doe@gmail.com", "password", "Jane", "Doe")
+		// Users stored before aliases were collapsed are found by their plain address
+		mockRepo.On("GetByEmail", ctx, "jdoe@gmail.com").Return(nil, nil).Once()
+		mockRepo.On("GetByEmail", ctx, "j.doe@gmail.com").Return(stored, nil).Once()
+
+		foundUser, err := svc.GetByEmail(ctx, "J.Doe@gmail.com")
+
+		require.NoError(t, err)
+		assert.Same(t, stored, foundUser)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("User Not Found - Repo Returns Nil, Nil", func(t *testing.T) {
 		notFoundEmail := "notfound@example.com"
 		mockRepo.On("GetByEmail", ctx, notFoundEmail).Return(nil, nil).Once()
@@ -269,11 +669,11 @@ func TestUpdate(t *testing.T) {
 	originalUser := &domainUser.User{
 		ID: originalUserID, Email: "original@example.com", FirstName: "Original", LastName: "User", Password: "somepassword", // Password will be hashed by HashPassword
 	}
-	_ = originalUser.HashPassword() // Pre-hash for consistent test data if needed by CheckPassword later, though Update doesn't use it.
+	_ = originalUser.HashPassword(bcryptHasher(t)) // Pre-hash for consistent test data if needed by CheckPassword later, though Update doesn't use it.
 
 
 	t.Run("Success", func(t *testing.T) {
-		updateParams := domainUser.UpdateUserParams{FirstName: "UpdatedFirst", LastName: "UpdatedLast"}
+		updateParams := domainUser.UpdateUserParams{FirstName: stringPtr("UpdatedFirst"), LastName: stringPtr("UpdatedLast")}
 		// Reset user state for this test if necessary, or use a fresh one.
 		// For this test, assume originalUser is the state before Update is called.
 		// The GetByID mock should return this pre-update state.
@@ -294,9 +694,123 @@ func TestUpdate(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Email Policy Is Enforced On Email Change", func(t *testing.T) {
+		policy := emailpolicy.New(nil, map[string]domainUser.EmailPolicy{"acme": mustRules(t, config.EmailDomainRules{Allow: []string{"acme.com"}})})
+		svc := NewUserService(mockRepo, WithEmailPolicy(policy))
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "jane@acme.com", Tenant: "acme"}, nil).Once()
+
+		_, err := svc.Update(ctx, originalUserID, domainUser.UpdateUserParams{Email: stringPtr("jane@example.com")})
+
+		assert.ErrorIs(t, err, emailpolicy.ErrDomainNotAllowed)
+		mockRepo.AssertNotCalled(t, "GetByEmail", ctx, "jane@example.com")
+	})
+
+	t.Run("Publishes Profile Updated", func(t *testing.T) {
+		events := &recordingPublisher{}
+		publishingService := NewUserService(mockRepo, WithEventPublisher(events))
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		_, err := publishingService.Update(ctx, originalUserID, domainUser.UpdateUserParams{FirstName: stringPtr("Renamed")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domainEvent.Event{{Type: domainEvent.TypeProfileUpdated, UserID: originalUserID}}, events.events)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty Field Cleared", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com", FirstName: "Original", LastName: "User"}, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.FirstName == "Original" && u.LastName == ""
+		})).Return(nil).Once()
+
+		updatedUser, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{LastName: stringPtr("")})
+		assert.NoError(t, err)
+		assert.Empty(t, updatedUser.LastName)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Metadata Merged", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com", FirstName: "Original",
+			Metadata: map[string]string{"plan": "free", "team": "core"}}, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.FirstName == "Original" && assert.ObjectsAreEqual(map[string]string{"plan": "pro"}, u.Metadata)
+		})).Return(nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Metadata: map[string]*string{"plan": stringPtr("pro"), "team": nil}})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Metadata", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Metadata: map[string]*string{"not a key": stringPtr("v")}})
+		assert.Equal(t, ErrInvalidMetadataKey, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Locale Normalized", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.Locale == "zh"
+		})).Return(nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Locale: stringPtr("zh-CN")})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Locale Cleared", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com", Locale: "zh"}, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.Locale == ""
+		})).Return(nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Locale: stringPtr("")})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Display Name Trimmed", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.DisplayName == "Ada"
+		})).Return(nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{DisplayName: stringPtr("  Ada ")})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Display Name Too Long", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+
+		// Counted in characters rather than bytes
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{DisplayName: stringPtr(strings.Repeat("名", MaxDisplayNameLength+1))})
+		assert.Equal(t, ErrDisplayNameTooLong, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported Locale", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Locale: stringPtr("tlh")})
+		assert.Equal(t, ErrUnsupportedLocale, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Email Cannot Be Cleared", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, originalUserID).Return(&domainUser.User{ID: originalUserID, Email: "original@example.com"}, nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Email: stringPtr("")})
+		assert.Equal(t, ErrEmailRequired, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Email In Use", func(t *testing.T) {
 		conflictingEmail := "taken@example.com"
-		updateParams := domainUser.UpdateUserParams{Email: conflictingEmail}
+		updateParams := domainUser.UpdateUserParams{Email: stringPtr(conflictingEmail)}
 
 		userForGetByID := &domainUser.User{ID: originalUserID, Email: "original@example.com", Password: "hashed"}
 		conflictingUser := &domainUser.User{ID: uuid.New(), Email: conflictingEmail}
@@ -312,7 +826,7 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("Email In Use - GetByEmail returns gorm.ErrRecordNotFound for current user's email change to available", func(t *testing.T) {
 		newEmail := "newavailable@example.com"
-		updateParams := domainUser.UpdateUserParams{Email: newEmail, FirstName: "NewFirst"}
+		updateParams := domainUser.UpdateUserParams{Email: stringPtr(newEmail), FirstName: stringPtr("NewFirst")}
 
 		userForGetByID := &domainUser.User{ID: originalUserID, Email: "original@example.com", Password: "hashed"}
 		mockRepo.On("GetByID", ctx, originalUserID).Return(userForGetByID, nil).Once()
@@ -329,9 +843,37 @@ func TestUpdate(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Email Respelled", func(t *testing.T) {
+		userForGetByID := &domainUser.User{ID: originalUserID, Email: "original@example.com", NormalizedEmail: "original@example.com", Password: "hashed"}
+		mockRepo.On("GetByID", ctx, originalUserID).Return(userForGetByID, nil).Once()
+		mockRepo.On("GetByEmail", ctx, "original@example.com").Return(userForGetByID, nil).Once()
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return u.Email == "Original@Example.com" && u.NormalizedEmail == "original@example.com"
+		})).Return(nil).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Email: stringPtr("Original@Example.com")})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		stored := &domainUser.User{ID: originalUserID, Email: "original@example.com", UpdatedAt: time.Now()}
+
+		mockRepo.On("GetByID", ctx, originalUserID).Return(stored, nil).Once()
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{FirstName: stringPtr("Stale"), Versions: []string{"stale"}})
+		assert.Equal(t, ErrVersionMismatch, err)
+
+		mockRepo.On("GetByID", ctx, originalUserID).Return(stored, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+		_, err = userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{FirstName: stringPtr("Current"), Versions: []string{"stale", stored.Version()}})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("User Not Found", func(t *testing.T) {
 		nonExistentID := uuid.New()
-		updateParams := domainUser.UpdateUserParams{FirstName: "Nobody"}
+		updateParams := domainUser.UpdateUserParams{FirstName: stringPtr("Nobody")}
 		mockRepo.On("GetByID", ctx, nonExistentID).Return(nil, nil).Once()
 
 		_, err := userService.Update(ctx, nonExistentID, updateParams)
@@ -341,7 +883,7 @@ func TestUpdate(t *testing.T) {
 	})
 
 	t.Run("Repository Error on GetByID", func(t *testing.T) {
-		updateParams := domainUser.UpdateUserParams{FirstName: "ErrorCase"}
+		updateParams := domainUser.UpdateUserParams{FirstName: stringPtr("ErrorCase")}
 		dbError := errors.New("db error on getbyid")
 		mockRepo.On("GetByID", ctx, originalUserID).Return(nil, dbError).Once()
 
@@ -352,7 +894,7 @@ func TestUpdate(t *testing.T) {
 	})
 
 	t.Run("Repository Error on Update", func(t *testing.T) {
-		updateParams := domainUser.UpdateUserParams{FirstName: "UpdateFail"}
+		updateParams := domainUser.UpdateUserParams{FirstName: stringPtr("UpdateFail")}
 		dbError := errors.New("db error on update")
 		userForGetByID := &domainUser.User{ID: originalUserID, Email: "original@example.com", Password: "hashed"}
 		mockRepo.On("GetByID", ctx, originalUserID).Return(userForGetByID, nil).Once()
@@ -363,6 +905,51 @@ func TestUpdate(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to update user")
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Email Taken Concurrently", func(t *testing.T) {
+		userForGetByID := &domainUser.User{ID: originalUserID, Email: "original@example.com", Password: "hashed"}
+		mockRepo.On("GetByID", ctx, originalUserID).Return(userForGetByID, nil).Once()
+		mockRepo.On("GetByEmail", ctx, "contested@example.com").Return(nil, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(fmt.Errorf("%w: unique violation", domainUser.ErrDuplicate)).Once()
+
+		_, err := userService.Update(ctx, originalUserID, domainUser.UpdateUserParams{Email: stringPtr("contested@example.com")})
+
+		assert.Same(t, ErrEmailInUse, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// recordingPublisher records the events it is asked to publish
+type recordingPublisher struct {
+	events []domainEvent.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domainEvent.Event) {
+	p.events = append(p.events, e)
+}
+
+// recordingNotifier records the notifications it is asked to send
+type recordingNotifier struct {
+	kinds      []domainNotification.Kind
+	recipients []domainNotification.Recipient
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, kind domainNotification.Kind, recipient domainNotification.Recipient) {
+	n.kinds = append(n.kinds, kind)
+	n.recipients = append(n.recipients, recipient)
+}
+
+// recordingSecurityLog records the security events it is given
+type recordingSecurityLog struct {
+	users    []uuid.UUID
+	actions  []domainAudit.Action
+	contexts []context.Context
+}
+
+func (l *recordingSecurityLog) Record(ctx context.Context, userID uuid.UUID, action domainAudit.Action, _ any) {
+	l.contexts = append(l.contexts, ctx)
+	l.users = append(l.users, userID)
+	l.actions = append(l.actions, action)
 }
 
 func TestUpdatePassword(t *testing.T) {
@@ -375,7 +962,7 @@ func TestUpdatePassword(t *testing.T) {
 	newPassword := "newPassword456"
 
 	testUser := &domainUser.User{ID: userID, Email: "user@example.com", Password: currentPassword}
-	errHashing := testUser.HashPassword()
+	errHashing := testUser.HashPassword(bcryptHasher(t))
 	assert.NoError(t, errHashing)
 
 	t.Run("Success", func(t *testing.T) {
@@ -384,7 +971,7 @@ func TestUpdatePassword(t *testing.T) {
 
 		mockRepo.On("GetByID", ctx, userID).Return(userForGetByID, nil).Once()
 		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
-			return u.ID == userID && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(newPassword)) == nil
+			return u.ID == userID && strings.HasPrefix(u.Password, "$argon2id$") && u.CheckPassword(newPassword)
 		})).Return(nil).Once()
 
 		err := userService.UpdatePassword(ctx, userID, currentPassword, newPassword)
@@ -392,6 +979,36 @@ func TestUpdatePassword(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Sends Security Alert", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		alertingService := NewUserService(mockRepo, WithNotifier(notifier))
+		userForGetByID := &domainUser.User{ID: userID, Email: "user@example.com", FirstName: "Jane", Password: testUser.Password}
+
+		mockRepo.On("GetByID", ctx, userID).Return(userForGetByID, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		err := alertingService.UpdatePassword(ctx, userID, currentPassword, newPassword)
+		assert.NoError(t, err)
+		assert.Equal(t, []domainNotification.Kind{domainNotification.KindPasswordChanged}, notifier.kinds)
+		assert.Equal(t, domainNotification.Recipient{UserID: userID, Email: "user@example.com", Name: "Jane"}, notifier.recipients[0])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Records Security Event", func(t *testing.T) {
+		security := &recordingSecurityLog{}
+		loggingService := NewUserService(mockRepo, WithSecurityLog(security))
+		userForGetByID := &domainUser.User{ID: userID, Email: "user@example.com", Password: testUser.Password}
+
+		mockRepo.On("GetByID", ctx, userID).Return(userForGetByID, nil).Once()
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(nil).Once()
+
+		err := loggingService.UpdatePassword(ctx, userID, currentPassword, newPassword)
+		assert.NoError(t, err)
+		assert.Equal(t, []domainAudit.Action{domainAudit.ActionChangePassword}, security.actions)
+		assert.Equal(t, []uuid.UUID{userID}, security.users)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("User Not Found", func(t *testing.T) {
 		nonExistentID := uuid.New()
 		mockRepo.On("GetByID", ctx, nonExistentID).Return(nil, nil).Once()
@@ -434,4 +1051,69 @@ func TestUpdatePassword(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to update user")
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Clears Forced Reset", func(t *testing.T) {
+		repo := repoUser.NewInMemoryRepository()
+		user, err := repoUser.NewUserBuilder().WithPassword(currentPassword).PasswordResetRequired().Create(ctx, repo)
+		require.NoError(t, err)
+
+		require.NoError(t, NewUserService(repo).UpdatePassword(ctx, user.ID, currentPassword, newPassword))
+
+		stored, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.CheckPassword(newPassword))
+		assert.False(t, stored.PasswordResetRequired)
+	})
+}
+
+// bcryptHasher makes cheap bcrypt hashes, standing in for legacy hashes
+func bcryptHasher(t *testing.T) *password.Hasher {
+	hasher, err := password.NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+	return hasher
+}
+
+func TestRehashPassword(t *testing.T) {
+	ctx := context.Background()
+	plain := "password123"
+
+	t.Run("Upgrades Legacy Hash", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo)
+		user := &domainUser.User{ID: uuid.New(), Password: plain}
+		require.NoError(t, user.HashPassword(bcryptHasher(t)))
+
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(u *domainUser.User) bool {
+			return strings.HasPrefix(u.Password, "$argon2id$") && u.CheckPassword(plain)
+		})).Return(nil).Once()
+
+		require.NoError(t, svc.RehashPassword(ctx, user, plain))
+		assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Keeps Current Hash", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo)
+		user := &domainUser.User{ID: uuid.New(), Password: plain}
+		require.NoError(t, user.HashPassword(password.NewDefaultHasher()))
+		hash := user.Password
+
+		require.NoError(t, svc.RehashPassword(ctx, user, plain))
+		assert.Equal(t, hash, user.Password)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update Error Keeps Old Hash", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		svc := NewUserService(mockRepo)
+		user := &domainUser.User{ID: uuid.New(), Password: plain}
+		require.NoError(t, user.HashPassword(bcryptHasher(t)))
+		hash := user.Password
+
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*user.User")).Return(errors.New("database is read-only")).Once()
+
+		assert.Error(t, svc.RehashPassword(ctx, user, plain))
+		assert.Equal(t, hash, user.Password)
+	})
 }
@@ -0,0 +1,71 @@
+package userexport
+
+import (
+	"strings"
+	"time"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// Column is a user attribute that can be exported. Passwords never are.
+type Column struct {
+	Name  string
+	value func(u *domainUser.User, ids idgen.Strategy) any // string or bool
+}
+
+// columns lists every exportable column in the default order. The names match
+// the CSV header accepted by the bulk import.
+var columns = []Column{
+	{Name: "id", value: func(u *domainUser.User, ids idgen.Strategy) any { return ids.Format(u.ID) }},
+	{Name: "email", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.Email }},
+	{Name: "username", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.Username }},
+	{Name: "first_name", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.FirstName }},
+	{Name: "last_name", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.LastName }},
+	{Name: "residency", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.Residency }},
+	{Name: "tenant", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.Tenant }},
+	{Name: "role", value: func(u *domainUser.User, _ idgen.Strategy) any { return string(u.Role) }},
+	{Name: "is_active", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.IsActive }},
+	{Name: "password_reset_required", value: func(u *domainUser.User, _ idgen.Strategy) any { return u.PasswordResetRequired }},
+	{Name: "created_at", value: func(u *domainUser.User, _ idgen.Strategy) any { return formatTime(u.CreatedAt) }},
+	{Name: "updated_at", value: func(u *domainUser.User, _ idgen.Strategy) any { return formatTime(u.UpdatedAt) }},
+}
+
+// ParseColumns resolves a comma-separated list of column names, in the order
+// given. An empty list selects every column.
+func ParseColumns(list string) ([]Column, error) {
+	if strings.TrimSpace(list) == "" {
+		return columns, nil
+	}
+
+	byName := make(map[string]Column, len(columns))
+	for _, col := range columns {
+		byName[col.Name] = col
+	}
+
+	names := strings.Split(list, ",")
+	selected := make([]Column, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		col, ok := byName[name]
+		if !ok || seen[name] {
+			return nil, ErrInvalidColumns
+		}
+		seen[name] = true
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+func columnNames() string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,157 @@
+package userexport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Format is the file format of an export
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat resolves a format name, defaulting to CSV
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// flusher is implemented by writers that buffer, such as http.ResponseWriter
+type flusher interface {
+	Flush()
+}
+
+// encoder writes users one row at a time. flush hands everything written so
+// far to the underlying writer so that memory use stays bounded by a page.
+type encoder interface {
+	begin() error
+	row(values []any) error
+	flush() error
+	end() error
+}
+
+func newEncoder(w io.Writer, format Format, cols []Column) encoder {
+	if format == FormatJSON {
+		return newJSONEncoder(w, cols)
+	}
+	return &csvEncoder{w: w, csv: csv.NewWriter(w), cols: cols}
+}
+
+// csvEncoder writes a header line followed by one line per user
+type csvEncoder struct {
+	w    io.Writer
+	csv  *csv.Writer
+	cols []Column
+}
+
+func (e *csvEncoder) begin() error {
+	header := make([]string, len(e.cols))
+	for i, col := range e.cols {
+		header[i] = col.Name
+	}
+	return e.csv.Write(header)
+}
+
+func (e *csvEncoder) row(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case string:
+			record[i] = v
+		}
+	}
+	return e.csv.Write(record)
+}
+
+func (e *csvEncoder) flush() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	if f, ok := e.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (e *csvEncoder) end() error {
+	return e.flush()
+}
+
+// jsonEncoder writes a JSON array with one object per user, keyed by column name
+type jsonEncoder struct {
+	w    io.Writer
+	buf  *bufio.Writer
+	keys [][]byte // Encoded object keys, including the colon
+	rows int
+}
+
+func newJSONEncoder(w io.Writer, cols []Column) *jsonEncoder {
+	keys := make([][]byte, len(cols))
+	for i, col := range cols {
+		key, _ := json.Marshal(col.Name)
+		keys[i] = append(key, ':')
+	}
+	return &jsonEncoder{w: w, buf: bufio.NewWriter(w), keys: keys}
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := e.buf.WriteString("[")
+	return err
+}
+
+func (e *jsonEncoder) row(values []any) error {
+	if e.rows > 0 {
+		e.buf.WriteByte(',')
+	}
+	e.rows++
+
+	e.buf.WriteString("\n{")
+	for i, v := range values {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		e.buf.Write(e.keys[i])
+		e.buf.Write(value)
+	}
+	_, err := e.buf.WriteString("}")
+	return err
+}
+
+func (e *jsonEncoder) flush() error {
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := e.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (e *jsonEncoder) end() error {
+	if e.rows > 0 {
+		e.buf.WriteByte('\n')
+	}
+	if _, err := e.buf.WriteString("]\n"); err != nil {
+		return err
+	}
+	return e.flush()
+}
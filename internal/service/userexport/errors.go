@@ -0,0 +1,12 @@
+package userexport
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for user exports
+var (
+	ErrJobNotFound       = apperror.New(apperror.CodeExportJobNotFound, "export job not found")
+	ErrExportNotReady    = apperror.New(apperror.CodeExportNotReady, "the export has not completed")
+	ErrUnsupportedFormat = apperror.New(apperror.CodeInvalidArgument, "exports must be CSV or JSON")
+	ErrInvalidColumns    = apperror.New(apperror.CodeInvalidArgument,
+		"columns must be a comma-separated list of distinct names from: "+columnNames())
+)
@@ -0,0 +1,172 @@
+package userexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainCompliance "github.com/yi-tech/go-user-service/internal/domain/compliance"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// defaultBatchSize is the number of users read per query when none is given
+const defaultBatchSize = 500
+
+// Service exports users in bulk on behalf of an administrator
+type Service interface {
+	// Export streams every user matching the request to w, reading them from the
+	// repository a page at a time. Users whose residency does not allow exports
+	// are withheld. Nothing is written to w unless the first page loads, so an
+	// error with nothing written can still be reported to the client.
+	Export(ctx context.Context, actorID uuid.UUID, req Request, w io.Writer) (*Result, error)
+}
+
+// Request selects the users and the shape of an export
+type Request struct {
+	Filter  domainUser.ListFilter // Criteria only; paging is done by the export
+	Format  Format
+	Columns []Column
+}
+
+// Result summarises an export
+type Result struct {
+	Exported int
+	Withheld int // Users the residency policy kept out of the export
+}
+
+type service struct {
+	users     domainUser.Repository
+	residency domainCompliance.ResidencyPolicy
+	auditRepo domainAudit.Repository
+	ids       idgen.Generator
+	idFormat  idgen.Strategy // Text form of exported IDs
+	batchSize int
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a new instance of the export Service.
+// A non-positive batchSize falls back to 500.
+func NewService(users domainUser.Repository, residency domainCompliance.ResidencyPolicy, auditRepo domainAudit.Repository, ids idgen.Generator, idFormat idgen.Strategy, batchSize int, logger *zap.Logger) Service {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &service{
+		users:     users,
+		residency: residency,
+		auditRepo: auditRepo,
+		ids:       ids,
+		idFormat:  idFormat,
+		batchSize: batchSize,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+func (s *service) Export(ctx context.Context, actorID uuid.UUID, req Request, w io.Writer) (*Result, error) {
+	filter := req.Filter
+	filter.Offset = 0
+	filter.Limit = s.batchSize
+
+	page, err := s.users.ListAfter(ctx, filter, uuid.Nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	result := &Result{}
+	if err := s.write(ctx, filter, page, newEncoder(w, req.Format, req.Columns), req.Columns, result); err != nil {
+		s.recordAborted(ctx, actorID, req.Format, result)
+		return nil, err
+	}
+
+	if err := s.record(ctx, actorID, req.Format, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// write encodes first and every following page, flushing after each one
+func (s *service) write(ctx context.Context, filter domainUser.ListFilter, first []*domainUser.User, enc encoder, cols []Column, result *Result) error {
+	if err := enc.begin(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	values := make([]any, len(cols))
+	page := first
+	for {
+		for _, u := range page {
+			if !s.residency.Allows(s.residency.ResidencyOf(u), domainCompliance.DestinationExport) {
+				result.Withheld++
+				continue
+			}
+			for i, col := range cols {
+				values[i] = col.value(u, s.idFormat)
+			}
+			if err := enc.row(values); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+			result.Exported++
+		}
+		if err := enc.flush(); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+
+		if len(page) < filter.Limit {
+			break
+		}
+		var err error
+		page, err = s.users.ListAfter(ctx, filter, page[len(page)-1].ID)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+	}
+
+	if err := enc.end(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// record appends an audit log entry summarising the export
+func (s *service) record(ctx context.Context, actorID uuid.UUID, format Format, result *Result) error {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return fmt.Errorf("failed to generate audit log id: %w", err)
+	}
+
+	entry := &domainAudit.Entry{
+		ID:        id,
+		ActorID:   actorID,
+		Action:    domainAudit.ActionExportUsers,
+		Details:   fmt.Sprintf("exported %d users as %s, %d withheld by residency policy", result.Exported, format, result.Withheld),
+		ClientIP:  clientip.FromContext(ctx),
+		CreatedAt: s.now(),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// recordAborted audits the users written before an export failed part way.
+// The export error is what the caller needs to see, so a failure here is only logged.
+func (s *service) recordAborted(ctx context.Context, actorID uuid.UUID, format Format, result *Result) {
+	if result.Exported == 0 {
+		return
+	}
+	// The client may have gone away, which is a common reason for the failure
+	ctx = context.WithoutCancel(ctx)
+	if err := s.record(ctx, actorID, format, result); err != nil {
+		s.logger.Error("Failed to audit aborted user export",
+			zap.String("actor_id", actorID.String()),
+			zap.Int("exported", result.Exported),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,280 @@
+package userexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainCompliance "github.com/yi-tech/go-user-service/internal/domain/compliance"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceCompliance "github.com/yi-tech/go-user-service/internal/service/compliance"
+)
+
+// MockAuditRepository is a mock implementation of the domainAudit.Repository interface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry *domainAudit.Entry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	args := m.Called(ctx, filter)
+	return nil, 0, args.Error(2)
+}
+
+func (m *MockAuditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeUsers pages through users sorted by ID, recording every query
+type fakeUsers struct {
+	domainUser.Repository
+	users   []*domainUser.User
+	calls   []uuid.UUID // afterID of each ListAfter call
+	failOn  int         // ListAfter call, counting from 1, that fails; 0 never fails
+	filters []domainUser.ListFilter
+}
+
+func (f *fakeUsers) ListAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	f.calls = append(f.calls, afterID)
+	f.filters = append(f.filters, filter)
+	if len(f.calls) == f.failOn {
+		return nil, errors.New("connection reset")
+	}
+
+	page := []*domainUser.User{}
+	for _, u := range f.users {
+		if bytes.Compare(u.ID[:], afterID[:]) > 0 && len(page) < filter.Limit {
+			page = append(page, u)
+		}
+	}
+	return page, nil
+}
+
+// newTestUsers returns n users with ascending IDs; every third one resides in the US
+func newTestUsers(n int) []*domainUser.User {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := make([]*domainUser.User, n)
+	for i := range users {
+		id := uuid.UUID{15: byte(i + 1)}
+		users[i] = &domainUser.User{
+			ID:        id,
+			Email:     fmt.Sprintf("user%d@example.com", i+1),
+			FirstName: "User",
+			LastName:  fmt.Sprint(i + 1),
+			Password:  "$2a$10$hash",
+			Role:      "user",
+			IsActive:  true,
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+		if (i+1)%3 == 0 {
+			users[i].Residency = "US"
+		}
+	}
+	return users
+}
+
+func newTestPolicy(t *testing.T) domainCompliance.ResidencyPolicy {
+	policy, err := serviceCompliance.NewResidencyPolicy(config.ComplianceConfig{
+		Regions:          []string{"EU", "US"},
+		DefaultResidency: "EU",
+		Destinations:     map[string][]string{domainCompliance.DestinationExport: {"EU"}},
+	})
+	require.NoError(t, err)
+	return policy
+}
+
+func newTestService(t *testing.T, users *fakeUsers, auditRepo *MockAuditRepository, batchSize int) Service {
+	return NewService(users, newTestPolicy(t), auditRepo, idgen.NewGenerator(idgen.StrategyUUIDv4), idgen.StrategyUUIDv4, batchSize, zaptest.NewLogger(t))
+}
+
+func TestExport_CSVInPages(t *testing.T) {
+	users := &fakeUsers{users: newTestUsers(7)}
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *domainAudit.Entry) bool {
+		return e.Action == domainAudit.ActionExportUsers &&
+			e.Details == "exported 5 users as csv, 2 withheld by residency policy"
+	})).Return(nil).Once()
+
+	cols, err := ParseColumns("email,is_active,created_at")
+	require.NoError(t, err)
+
+	active := true
+	var out bytes.Buffer
+	result, err := newTestService(t, users, auditRepo, 3).Export(context.Background(), uuid.New(), Request{
+		Filter:  domainUser.ListFilter{Query: "user", Active: &active, Offset: 40, Limit: 10},
+		Format:  FormatCSV,
+		Columns: cols,
+	}, &out)
+
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Exported: 5, Withheld: 2}, result)
+	// Pages of 3 continue after the last ID seen, ignoring the caller's paging
+	assert.Equal(t, []uuid.UUID{uuid.Nil, users.users[2].ID, users.users[5].ID}, users.calls)
+	for _, f := range users.filters {
+		assert.Equal(t, domainUser.ListFilter{Query: "user", Active: &active, Limit: 3}, f)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, []string{"email", "is_active", "created_at"}, records[0])
+	assert.Equal(t, []string{"user1@example.com", "true", "2026-01-02T03:04:05Z"}, records[1])
+	assert.Equal(t, "user7@example.com", records[5][0])
+	auditRepo.AssertExpectations(t)
+}
+
+func TestExport_JSON(t *testing.T) {
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	cols, err := ParseColumns("id, first_name ,last_name")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		users    []*domainUser.User
+		expected string
+	}{
+		{
+			name:  "users",
+			users: newTestUsers(2),
+			expected: `[{"id":"00000000-0000-0000-0000-000000000001","first_name":"User","last_name":"1"},
+			             {"id":"00000000-0000-0000-0000-000000000002","first_name":"User","last_name":"2"}]`,
+		},
+		{
+			name:     "no users",
+			users:    nil,
+			expected: `[]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := newTestService(t, &fakeUsers{users: tc.users}, auditRepo, 10).Export(context.Background(), uuid.New(), Request{
+				Format:  FormatJSON,
+				Columns: cols,
+			}, &out)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestExport_NeverExportsPasswords(t *testing.T) {
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	cols, err := ParseColumns("")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	_, err = newTestService(t, &fakeUsers{users: newTestUsers(1)}, auditRepo, 10).Export(context.Background(), uuid.New(), Request{
+		Format:  FormatJSON,
+		Columns: cols,
+	}, &out)
+
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "password\"")
+	assert.NotContains(t, out.String(), "$2a$10$hash")
+}
+
+func TestExport_FirstPageFails(t *testing.T) {
+	users := &fakeUsers{users: newTestUsers(2), failOn: 1}
+	auditRepo := new(MockAuditRepository)
+
+	var out bytes.Buffer
+	result, err := newTestService(t, users, auditRepo, 10).Export(context.Background(), uuid.New(), Request{
+		Format:  FormatCSV,
+		Columns: columns,
+	}, &out)
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Empty(t, out.String(), "nothing may be written before the first page loads")
+	auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestExport_LaterPageFailsStillAudits(t *testing.T) {
+	users := &fakeUsers{users: newTestUsers(4), failOn: 2}
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *domainAudit.Entry) bool {
+		return strings.HasPrefix(e.Details, "exported 2 users")
+	})).Return(nil).Once()
+
+	var out bytes.Buffer
+	result, err := newTestService(t, users, auditRepo, 2).Export(context.Background(), uuid.New(), Request{
+		Format:  FormatJSON,
+		Columns: columns,
+	}, &out)
+
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to list users")
+	assert.False(t, strings.HasSuffix(out.String(), "]\n"), "a failed JSON export must not look complete")
+	auditRepo.AssertExpectations(t)
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected []string
+		err      error
+	}{
+		{name: "default", list: "", expected: strings.Split(columnNames(), ", ")},
+		{name: "ordered", list: "last_name,EMAIL", expected: []string{"last_name", "email"}},
+		{name: "unknown", list: "email,password", err: ErrInvalidColumns},
+		{name: "duplicate", list: "email,email", err: ErrInvalidColumns},
+		{name: "empty entry", list: "email,", err: ErrInvalidColumns},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cols, err := ParseColumns(tc.list)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, len(cols))
+			for i, col := range cols {
+				names[i] = col.Name
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, format)
+
+	format, err = ParseFormat("json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
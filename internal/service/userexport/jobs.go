@@ -0,0 +1,280 @@
+package userexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// GenerateJob is the type of the jobs writing an export to a file
+const GenerateJob = "export.generate"
+
+// JobStatus is the progress of a background export
+type JobStatus string
+
+// Export job statuses
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is an export generated in the background
+type Job struct {
+	ID         uuid.UUID  `json:"id"`
+	Status     JobStatus  `json:"status"`
+	Format     Format     `json:"format"`
+	Result     *Result    `json:"result,omitempty"` // Set once the job has completed
+	Error      string     `json:"error,omitempty"`  // Set when the job failed
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Enqueuer adds jobs to the background job queue. *jobs.Queue satisfies it.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload any) (*jobs.Job, error)
+}
+
+// Generator exports users to files in the background, for exports too large
+// to stream within a request
+type Generator interface {
+	// Start queues an export for cmd/worker and returns its pending job
+	Start(ctx context.Context, actorID uuid.UUID, req Request) (*Job, error)
+
+	// Job returns the progress of an export
+	Job(ctx context.Context, id uuid.UUID) (*Job, error)
+
+	// Open returns a completed export for download; the caller closes it
+	Open(ctx context.Context, id uuid.UUID) (*Job, io.ReadCloser, error)
+}
+
+// generatePayload is the payload of a GenerateJob
+type generatePayload struct {
+	ActorID  uuid.UUID         `json:"actor_id"`
+	Query    string            `json:"query,omitempty"`
+	Role     rbac.Role         `json:"role,omitempty"`
+	Active   *bool             `json:"active,omitempty"`
+	Tenant   string            `json:"tenant,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Format   Format            `json:"format"`
+	Columns  []string          `json:"columns"`
+}
+
+// Files is the Generator keeping exports in a directory shared by the servers
+// and the workers. Each export is a <id>.<format> file next to a <id>.json
+// file holding its job.
+type Files struct {
+	exporter Service
+	queue    Enqueuer
+	dir      string
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewFiles creates a Generator writing exports to dir
+func NewFiles(exporter Service, queue Enqueuer, dir string, logger *zap.Logger) *Files {
+	return &Files{exporter: exporter, queue: queue, dir: dir, logger: logger, now: time.Now}
+}
+
+func (f *Files) Start(ctx context.Context, actorID uuid.UUID, req Request) (*Job, error) {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	names := make([]string, len(req.Columns))
+	for i, col := range req.Columns {
+		names[i] = col.Name
+	}
+	queued, err := f.queue.Enqueue(ctx, GenerateJob, generatePayload{
+		ActorID:  actorID,
+		Query:    req.Filter.Query,
+		Role:     req.Filter.Role,
+		Active:   req.Filter.Active,
+		Tenant:   req.Filter.Tenant,
+		Metadata: req.Filter.Metadata,
+		Format:   req.Format,
+		Columns:  names,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{ID: queued.ID, Status: JobPending, Format: req.Format, CreatedBy: actorID, CreatedAt: queued.EnqueuedAt}
+	// A worker may already have picked the job up, in which case its status stands
+	if err := f.create(job); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (f *Files) Job(_ context.Context, id uuid.UUID) (*Job, error) {
+	data, err := os.ReadFile(f.jobPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode export job: %w", err)
+	}
+	return &job, nil
+}
+
+func (f *Files) Open(ctx context.Context, id uuid.UUID) (*Job, io.ReadCloser, error) {
+	job, err := f.Job(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != JobCompleted {
+		return nil, nil, ErrExportNotReady
+	}
+
+	file, err := os.Open(f.exportPath(id, job.Format))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	return job, file, nil
+}
+
+// Generate runs a GenerateJob, writing the export to a temporary file that
+// is renamed into place once complete
+func (f *Files) Generate(ctx context.Context, queued *jobs.Job) error {
+	var payload generatePayload
+	if err := queued.Decode(&payload); err != nil {
+		return err
+	}
+	cols, err := ParseColumns(strings.Join(payload.Columns, ","))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	format, err := ParseFormat(string(payload.Format))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	job := &Job{ID: queued.ID, Status: JobRunning, Format: format, CreatedBy: payload.ActorID, CreatedAt: queued.EnqueuedAt}
+	if err := f.save(job); err != nil {
+		return err
+	}
+
+	result, err := f.write(ctx, job, Request{
+		Filter: domainUser.ListFilter{
+			Query:    payload.Query,
+			Role:     payload.Role,
+			Active:   payload.Active,
+			Tenant:   payload.Tenant,
+			Metadata: payload.Metadata,
+		},
+		Format:  format,
+		Columns: cols,
+	})
+	finished := f.now()
+	job.FinishedAt = &finished
+	if err != nil {
+		if jobs.IsPermanent(err) || queued.LastAttempt() {
+			job.Status = JobFailed
+			job.Error = "the export could not be completed"
+			if saveErr := f.save(job); saveErr != nil {
+				f.logger.Error("Failed to record failed export",
+					zap.String("job_id", job.ID.String()),
+					zap.Error(saveErr))
+			}
+		}
+		return err
+	}
+
+	job.Status = JobCompleted
+	job.Result = result
+	return f.save(job)
+}
+
+// write exports to <id>.<format>.part and renames it to <id>.<format>
+func (f *Files) write(ctx context.Context, job *Job, req Request) (*Result, error) {
+	path := f.exportPath(job.ID, job.Format)
+	file, err := os.OpenFile(path+".part", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	result, err := f.exporter.Export(ctx, job.CreatedBy, req, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export file: %w", closeErr)
+	}
+	if err == nil {
+		err = os.Rename(path+".part", path)
+	}
+	if err != nil {
+		os.Remove(path + ".part")
+		return nil, err
+	}
+	return result, nil
+}
+
+// create stores a new job, failing with fs.ErrExist when it is already stored
+func (f *Files) create(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode export job: %w", err)
+	}
+	file, err := os.OpenFile(f.jobPath(job.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export job: %w", err)
+	}
+	return nil
+}
+
+// save replaces the stored job atomically so readers never see a partial file
+func (f *Files) save(job *Job) error {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode export job: %w", err)
+	}
+	path := f.jobPath(job.ID)
+	if err := os.WriteFile(path+".part", data, 0o600); err != nil {
+		return fmt.Errorf("failed to write export job: %w", err)
+	}
+	if err := os.Rename(path+".part", path); err != nil {
+		return fmt.Errorf("failed to write export job: %w", err)
+	}
+	return nil
+}
+
+func (f *Files) jobPath(id uuid.UUID) string {
+	return filepath.Join(f.dir, id.String()+".json")
+}
+
+func (f *Files) exportPath(id uuid.UUID, format Format) string {
+	return filepath.Join(f.dir, id.String()+"."+string(format))
+}
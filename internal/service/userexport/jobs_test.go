@@ -0,0 +1,138 @@
+package userexport
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// fakeQueue records the jobs it was given
+type fakeQueue struct {
+	jobs []*jobs.Job
+}
+
+func (q *fakeQueue) Enqueue(_ context.Context, jobType string, payload any) (*jobs.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &jobs.Job{ID: uuid.New(), Type: jobType, Payload: data, MaxAttempts: 2, EnqueuedAt: time.Now()}
+	q.jobs = append(q.jobs, job)
+	return job, nil
+}
+
+func newTestFiles(t *testing.T, users *fakeUsers) (*Files, *fakeQueue, *MockAuditRepository) {
+	auditRepo := new(MockAuditRepository)
+	queue := &fakeQueue{}
+	files := NewFiles(newTestService(t, users, auditRepo, 2), queue, filepath.Join(t.TempDir(), "exports"), zaptest.NewLogger(t))
+	return files, queue, auditRepo
+}
+
+func TestFiles(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+	active := true
+
+	startExport := func(t *testing.T, files *Files, queue *fakeQueue) (*Job, *jobs.Job) {
+		cols, err := ParseColumns("email,role")
+		require.NoError(t, err)
+		job, err := files.Start(ctx, actorID, Request{
+			Filter:  domainUser.ListFilter{Query: "user", Active: &active},
+			Format:  FormatCSV,
+			Columns: cols,
+		})
+		require.NoError(t, err)
+		require.Len(t, queue.jobs, 1)
+		queued := queue.jobs[0]
+		queued.Attempt = 1
+		return job, queued
+	}
+
+	t.Run("Generates Export For Download", func(t *testing.T) {
+		users := &fakeUsers{users: newTestUsers(4)}
+		files, queue, auditRepo := newTestFiles(t, users)
+		auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+		job, queued := startExport(t, files, queue)
+		assert.Equal(t, GenerateJob, queued.Type)
+		assert.Equal(t, queued.ID, job.ID)
+		assert.Equal(t, JobPending, job.Status)
+
+		_, _, err := files.Open(ctx, job.ID)
+		assert.ErrorIs(t, err, ErrExportNotReady)
+
+		require.NoError(t, files.Generate(ctx, queued))
+
+		got, err := files.Job(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, JobCompleted, got.Status)
+		assert.Equal(t, actorID, got.CreatedBy)
+		assert.Equal(t, &Result{Exported: 3, Withheld: 1}, got.Result)
+		assert.NotNil(t, got.FinishedAt)
+		assert.Equal(t, domainUser.ListFilter{Query: "user", Active: &active, Limit: 2}, users.filters[0])
+
+		_, file, err := files.Open(ctx, job.ID)
+		require.NoError(t, err)
+		defer file.Close()
+		records, err := csv.NewReader(file).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, 4)
+		assert.Equal(t, []string{"email", "role"}, records[0])
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure Is Retried Then Recorded", func(t *testing.T) {
+		users := &fakeUsers{users: newTestUsers(4), failOn: 1}
+		files, queue, _ := newTestFiles(t, users)
+
+		job, queued := startExport(t, files, queue)
+		err := files.Generate(ctx, queued)
+		require.Error(t, err)
+
+		got, err := files.Job(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, JobRunning, got.Status)
+		entries, err := os.ReadDir(files.dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "a failed attempt leaves only the job file")
+
+		users.failOn = 2
+		queued.Attempt = 2
+		require.Error(t, files.Generate(ctx, queued))
+
+		got, err = files.Job(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, JobFailed, got.Status)
+		assert.Equal(t, "the export could not be completed", got.Error)
+	})
+
+	t.Run("Invalid Payload Is Permanent", func(t *testing.T) {
+		files, _, _ := newTestFiles(t, &fakeUsers{})
+
+		err := files.Generate(ctx, &jobs.Job{ID: uuid.New(), Type: GenerateJob, Payload: []byte(`{"columns":["password"]}`)})
+
+		assert.True(t, jobs.IsPermanent(err))
+	})
+
+	t.Run("Unknown Job", func(t *testing.T) {
+		files, _, _ := newTestFiles(t, &fakeUsers{})
+
+		_, err := files.Job(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		_, _, err = files.Open(ctx, uuid.New())
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
@@ -0,0 +1,21 @@
+package userimport
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for user imports
+var (
+	ErrJobNotFound       = apperror.New(apperror.CodeImportJobNotFound, "import job not found")
+	ErrUnsupportedFormat = apperror.New(apperror.CodeInvalidArgument, "import files must be CSV or JSON")
+	ErrMissingColumn     = apperror.New(apperror.CodeInvalidArgument, "the CSV header must include email, password, first_name and last_name")
+	ErrInvalidCSV        = apperror.New(apperror.CodeInvalidArgument, "the CSV file could not be parsed")
+	ErrInvalidJSON       = apperror.New(apperror.CodeInvalidArgument, "the JSON file must contain an array of user objects")
+)
+
+// Row-level validation errors, reported per row rather than failing the import
+var (
+	errInvalidEmail     = apperror.New(apperror.CodeInvalidArgument, "email must be a valid email address")
+	errPasswordTooShort = apperror.New(apperror.CodeInvalidArgument, "password must be at least 8 characters")
+	errNameRequired     = apperror.New(apperror.CodeInvalidArgument, "first_name and last_name are required")
+	errDuplicateEmail   = apperror.New(apperror.CodeUserAlreadyExists, "email appears earlier in the file")
+	errMalformedRow     = apperror.New(apperror.CodeInvalidArgument, "row could not be parsed")
+)
@@ -0,0 +1,284 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+const (
+	// defaultBatchSize is the number of users inserted per transaction when none is given
+	defaultBatchSize = 100
+
+	// maxRowErrors caps the per-row errors kept in a result so a file full of
+	// bad rows cannot grow the response without bound
+	maxRowErrors = 1000
+
+	minPasswordLength = 8
+)
+
+// Service imports users in bulk on behalf of an administrator
+type Service interface {
+	// Import reads every row and creates the valid ones, reporting the rest per row.
+	// Users are inserted in batches, each in its own transaction, so an aborted
+	// import keeps the batches stored before the failure.
+	Import(ctx context.Context, actorID uuid.UUID, rows RowReader) (*Result, error)
+
+	// Start runs Import in the background and returns the job to poll
+	Start(ctx context.Context, actorID uuid.UUID, rows RowReader) (*Job, error)
+
+	// Job returns the current state of a background import
+	Job(ctx context.Context, id uuid.UUID) (*Job, error)
+}
+
+// UserCreator builds and stores users. serviceUser.UserService satisfies it.
+type UserCreator interface {
+	PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error)
+	CreateUsers(ctx context.Context, users []*domainUser.User) error
+}
+
+// Result summarises an import
+type Result struct {
+	Total           int
+	Imported        int
+	Failed          int
+	Errors          []RowError
+	ErrorsTruncated bool // More rows failed than are listed
+}
+
+// RowError explains why a row was not imported
+type RowError struct {
+	Row     int
+	Email   string
+	Message string
+}
+
+func (r *Result) fail(row int, email string, err error) {
+	r.Failed++
+	if len(r.Errors) >= maxRowErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+
+	message := "user could not be saved"
+	if appErr, ok := apperror.As(err); ok {
+		message = appErr.Message
+	}
+	r.Errors = append(r.Errors, RowError{Row: row, Email: email, Message: message})
+}
+
+type service struct {
+	users     UserCreator
+	auditRepo domainAudit.Repository
+	ids       idgen.Generator
+	addresses emailaddr.Normalizer
+	batchSize int
+	jobs      *jobStore
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewService creates a new instance of the import Service. Rows repeating
+// an email of an earlier row in the form addresses normalizes it to are
+// rejected. A non-positive batchSize falls back to 100.
+func NewService(users UserCreator, auditRepo domainAudit.Repository, ids idgen.Generator, addresses emailaddr.Normalizer, batchSize int, logger *zap.Logger) Service {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &service{
+		users:     users,
+		auditRepo: auditRepo,
+		ids:       ids,
+		addresses: addresses,
+		batchSize: batchSize,
+		jobs:      newJobStore(time.Now),
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// pendingUser is a validated row waiting for its batch to be stored
+type pendingUser struct {
+	row  int
+	user *domainUser.User
+}
+
+func (s *service) Import(ctx context.Context, actorID uuid.UUID, rows RowReader) (*Result, error) {
+	result := &Result{Errors: []RowError{}}
+	seen := make(map[string]struct{})
+	batch := make([]pendingUser, 0, s.batchSize)
+
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.recordAborted(ctx, actorID, result)
+			return nil, err
+		}
+		result.Total++
+
+		email := s.addresses.Normalize(row.Input.Email)
+		if err := validate(row, email, seen); err != nil {
+			result.fail(row.Number, row.Input.Email, err)
+			continue
+		}
+		seen[email] = struct{}{}
+
+		user, err := s.users.PrepareUser(ctx, row.Input)
+		if err != nil {
+			if _, ok := apperror.As(err); !ok {
+				s.recordAborted(ctx, actorID, result)
+				return nil, fmt.Errorf("failed to prepare row %d: %w", row.Number, err)
+			}
+			result.fail(row.Number, row.Input.Email, err)
+			continue
+		}
+		// Administrators choose the initial passwords, so users pick their own on first sign-in
+		user.PasswordResetRequired = true
+
+		batch = append(batch, pendingUser{row: row.Number, user: user})
+		if len(batch) == s.batchSize {
+			s.flush(ctx, batch, result)
+			batch = batch[:0]
+		}
+	}
+	s.flush(ctx, batch, result)
+
+	if err := s.record(ctx, actorID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// flush stores a batch in one transaction. When the batch is rejected, the
+// users are retried one at a time so the failure is reported against the
+// row that caused it rather than the whole batch.
+func (s *service) flush(ctx context.Context, batch []pendingUser, result *Result) {
+	if len(batch) == 0 {
+		return
+	}
+
+	users := make([]*domainUser.User, len(batch))
+	for i, p := range batch {
+		users[i] = p.user
+	}
+	if err := s.users.CreateUsers(ctx, users); err == nil {
+		result.Imported += len(batch)
+		return
+	}
+
+	for _, p := range batch {
+		if err := s.users.CreateUsers(ctx, []*domainUser.User{p.user}); err != nil {
+			result.fail(p.row, p.user.Email, err)
+			continue
+		}
+		result.Imported++
+	}
+}
+
+// validate applies the checks the registration endpoint performs on its
+// request body; email is the normalized form of the row's email
+func validate(row Row, email string, seen map[string]struct{}) error {
+	if row.Err != nil {
+		return row.Err
+	}
+
+	input := row.Input
+	addr, err := mail.ParseAddress(input.Email)
+	if err != nil || addr.Address != input.Email {
+		return errInvalidEmail
+	}
+	if _, ok := seen[email]; ok {
+		return errDuplicateEmail
+	}
+	if utf8.RuneCountInString(input.Password) < minPasswordLength {
+		return errPasswordTooShort
+	}
+	if input.FirstName == "" || input.LastName == "" {
+		return errNameRequired
+	}
+	return nil
+}
+
+func (s *service) Start(ctx context.Context, actorID uuid.UUID, rows RowReader) (*Job, error) {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate import job id: %w", err)
+	}
+	job := s.jobs.add(id, actorID)
+
+	// The job outlives the request that started it
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		s.jobs.start(id)
+		result, err := s.Import(ctx, actorID, rows)
+		if err != nil {
+			s.logger.Error("User import job failed",
+				zap.String("job_id", id.String()),
+				zap.String("actor_id", actorID.String()),
+				zap.Error(err))
+		}
+		s.jobs.finish(id, result, err)
+	}()
+
+	return job, nil
+}
+
+func (s *service) Job(ctx context.Context, id uuid.UUID) (*Job, error) {
+	job, ok := s.jobs.get(id)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// record appends an audit log entry summarising the import
+func (s *service) record(ctx context.Context, actorID uuid.UUID, result *Result) error {
+	id, err := s.ids.NewID()
+	if err != nil {
+		return fmt.Errorf("failed to generate audit log id: %w", err)
+	}
+
+	entry := &domainAudit.Entry{
+		ID:        id,
+		ActorID:   actorID,
+		Action:    domainAudit.ActionImportUsers,
+		Details:   fmt.Sprintf("imported %d of %d users", result.Imported, result.Total),
+		ClientIP:  clientip.FromContext(ctx),
+		CreatedAt: s.now(),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// recordAborted audits the users stored before an import failed part way.
+// The import error is what the caller needs to see, so a failure here is only logged.
+func (s *service) recordAborted(ctx context.Context, actorID uuid.UUID, result *Result) {
+	if result.Imported == 0 {
+		return
+	}
+	if err := s.record(ctx, actorID, result); err != nil {
+		s.logger.Error("Failed to audit aborted user import",
+			zap.String("actor_id", actorID.String()),
+			zap.Int("imported", result.Imported),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,261 @@
+package userimport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/emailaddr"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// MockAuditRepository is a mock implementation of the domainAudit.Repository interface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry *domainAudit.Entry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	args := m.Called(ctx, filter)
+	return nil, 0, args.Error(2)
+}
+
+func (m *MockAuditRepository) Prune(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeUsers prepares users without hashing and stores them in memory.
+// A batch containing a rejected email fails as a whole, like a unique
+// constraint violation rolling back the transaction.
+type fakeUsers struct {
+	mu       sync.Mutex
+	existing map[string]bool // Emails PrepareUser reports as taken
+	rejected map[string]bool // Emails CreateUsers fails on
+	prepErr  error           // Returned by PrepareUser for every row when set
+	batches  [][]string
+	stored   []*domainUser.User
+}
+
+func (f *fakeUsers) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	if f.prepErr != nil {
+		return nil, f.prepErr
+	}
+	if f.existing[input.Email] {
+		return nil, serviceUser.ErrUserAlreadyExists
+	}
+	return &domainUser.User{ID: uuid.New(), Email: input.Email, IsActive: true}, nil
+}
+
+func (f *fakeUsers) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	emails := make([]string, len(users))
+	for i, u := range users {
+		emails[i] = u.Email
+	}
+	f.batches = append(f.batches, emails)
+
+	for _, u := range users {
+		if f.rejected[u.Email] {
+			return errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	f.stored = append(f.stored, users...)
+	return nil
+}
+
+func csvRows(t *testing.T, lines ...string) RowReader {
+	t.Helper()
+	input := "email,password,first_name,last_name\n" + strings.Join(lines, "\n")
+	rows, err := NewReader(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+	return rows
+}
+
+func importAudit(actorID uuid.UUID, details string) interface{} {
+	return mock.MatchedBy(func(e *domainAudit.Entry) bool {
+		return e.ActorID == actorID && e.Action == domainAudit.ActionImportUsers && e.TargetID == uuid.Nil && e.Details == details
+	})
+}
+
+var testActorID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+func TestImport(t *testing.T) {
+	t.Run("Per Row Errors", func(t *testing.T) {
+		users := &fakeUsers{existing: map[string]bool{"taken@example.com": true}}
+		audit := new(MockAuditRepository)
+		audit.On("Create", mock.Anything, importAudit(testActorID, "imported 2 of 7 users")).Return(nil)
+		service := NewService(users, audit, idgen.GeneratorFunc(uuid.NewRandom), emailaddr.Normalizer{}, 10, zaptest.NewLogger(t))
+
+		result, err := service.Import(context.Background(), testActorID, csvRows(t,
+			"ada@example.com,password1,Ada,Lovelace",
+			"not-an-email,password1,Bad,Email",
+			"short@example.com,short,Short,Password",
+			"noname@example.com,password1,,",
+			"taken@example.com,password1,Taken,Email",
+			"ADA@example.com,password1,Ada,Again",
+			"grace@example.com,password1,Grace,Hopper",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, &Result{
+			Total:    7,
+			Imported: 2,
+			Failed:   5,
+			Errors: []RowError{
+				{Row: 2, Email: "not-an-email", Message: "email must be a valid email address"},
+				{Row: 3, Email: "short@example.com", Message: "password must be at least 8 characters"},
+				{Row: 4, Email: "noname@example.com", Message: "first_name and last_name are required"},
+				{Row: 5, Email: "taken@example.com", Message: "user already exists"},
+				{Row: 6, Email: "ADA@example.com", Message: "email appears earlier in the file"},
+			},
+		}, result)
+		require.Len(t, users.stored, 2)
+		for _, u := range users.stored {
+			assert.True(t, u.PasswordResetRequired)
+		}
+		audit.AssertExpectations(t)
+	})
+
+	t.Run("Duplicates Compared Normalized", func(t *testing.T) {
+		users := &fakeUsers{}
+		audit := new(MockAuditRepository)
+		audit.On("Create", mock.Anything, importAudit(testActorID, "imported 2 of 4 users")).Return(nil)
+		addresses := emailaddr.Normalizer{CollapseGmailAliases: true}
+		service := NewService(users, audit, idgen.GeneratorFunc(uuid.NewRandom), addresses, 10, zaptest.NewLogger(t))
+
+		result, err := service.Import(context.Background(), testActorID, csvRows(t,
+			"j.doe@gmail.com,password1,Jane,Doe",
+			"jdoe+shop@googlemail.com,password1,Jane,Doe",
+			"ana@bücher.example,password1,Ana,Buch",
+			"ana@xn--bcher-kva.example,password1,Ana,Buch",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, []RowError{
+			{Row: 2, Email: "jdoe+shop@googlemail.com", Message: "email appears earlier in the file"},
+			{Row: 4, Email: "ana@xn--bcher-kva.example", Message: "email appears earlier in the file"},
+		}, result.Errors)
+	})
+
+	t.Run("Batches And Failed Batch Retry", func(t *testing.T) {
+		users := &fakeUsers{rejected: map[string]bool{"b@example.com": true}}
+		audit := new(MockAuditRepository)
+		audit.On("Create", mock.Anything, importAudit(testActorID, "imported 4 of 5 users")).Return(nil)
+		service := NewService(users, audit, idgen.GeneratorFunc(uuid.NewRandom), emailaddr.Normalizer{}, 2, zaptest.NewLogger(t))
+
+		result, err := service.Import(context.Background(), testActorID, csvRows(t,
+			"a@example.com,password1,A,User",
+			"b@example.com,password1,B,User",
+			"c@example.com,password1,C,User",
+			"d@example.com,password1,D,User",
+			"e@example.com,password1,E,User",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Imported)
+		assert.Equal(t, []RowError{{Row: 2, Email: "b@example.com", Message: "user could not be saved"}}, result.Errors)
+		assert.Equal(t, [][]string{
+			{"a@example.com", "b@example.com"},
+			{"a@example.com"},
+			{"b@example.com"},
+			{"c@example.com", "d@example.com"},
+			{"e@example.com"},
+		}, users.batches)
+	})
+
+	t.Run("Unexpected Error Aborts", func(t *testing.T) {
+		users := &fakeUsers{prepErr: errors.New("database unavailable")}
+		audit := new(MockAuditRepository)
+		service := NewService(users, audit, idgen.GeneratorFunc(uuid.NewRandom), emailaddr.Normalizer{}, 10, zaptest.NewLogger(t))
+
+		result, err := service.Import(context.Background(), testActorID, csvRows(t,
+			"a@example.com,password1,A,User",
+		))
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Empty(t, users.stored)
+		audit.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error List Is Capped", func(t *testing.T) {
+		lines := make([]string, maxRowErrors+5)
+		for i := range lines {
+			lines[i] = "invalid,password1,A,User"
+		}
+		audit := new(MockAuditRepository)
+		audit.On("Create", mock.Anything, mock.Anything).Return(nil)
+		service := NewService(&fakeUsers{}, audit, idgen.GeneratorFunc(uuid.NewRandom), emailaddr.Normalizer{}, 10, zaptest.NewLogger(t))
+
+		result, err := service.Import(context.Background(), testActorID, csvRows(t, lines...))
+
+		require.NoError(t, err)
+		assert.Equal(t, maxRowErrors+5, result.Failed)
+		assert.Len(t, result.Errors, maxRowErrors)
+		assert.True(t, result.ErrorsTruncated)
+	})
+}
+
+func TestStartAndJob(t *testing.T) {
+	users := &fakeUsers{}
+	audit := new(MockAuditRepository)
+	audit.On("Create", mock.Anything, importAudit(testActorID, "imported 1 of 1 users")).Return(nil)
+	service := NewService(users, audit, idgen.GeneratorFunc(uuid.NewRandom), emailaddr.Normalizer{}, 10, zaptest.NewLogger(t))
+
+	// The job keeps running after the request that started it is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	job, err := service.Start(ctx, testActorID, csvRows(t, "a@example.com,password1,A,User"))
+	cancel()
+	require.NoError(t, err)
+	assert.Equal(t, JobPending, job.Status)
+	assert.Equal(t, testActorID, job.CreatedBy)
+
+	require.Eventually(t, func() bool {
+		current, err := service.Job(context.Background(), job.ID)
+		return err == nil && current.Status == JobCompleted
+	}, time.Second, 5*time.Millisecond)
+
+	current, err := service.Job(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, current.Result.Imported)
+	assert.NotNil(t, current.FinishedAt)
+
+	_, err = service.Job(context.Background(), uuid.New())
+	assert.Equal(t, ErrJobNotFound, err)
+}
+
+func TestJobStore_PrunesFinishedJobs(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	store := newJobStore(func() time.Time { return now })
+
+	old := store.add(uuid.New(), testActorID)
+	store.finish(old.ID, &Result{}, nil)
+	running := store.add(uuid.New(), testActorID)
+
+	now = now.Add(jobRetention + time.Minute)
+	store.add(uuid.New(), testActorID)
+
+	_, ok := store.get(old.ID)
+	assert.False(t, ok)
+	_, ok = store.get(running.ID)
+	assert.True(t, ok)
+}
@@ -0,0 +1,107 @@
+package userimport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus is the progress of an asynchronous import
+type JobStatus string
+
+// Import job statuses
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// jobRetention is how long finished jobs stay available for polling
+const jobRetention = 24 * time.Hour
+
+// Job is an import running in the background
+type Job struct {
+	ID         uuid.UUID
+	Status     JobStatus
+	Result     *Result // Set once the job has completed
+	Error      string  // Set when the job failed
+	CreatedBy  uuid.UUID
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// jobStore keeps import jobs in memory. Jobs are local to the instance that
+// accepted the upload and do not survive a restart.
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*Job
+	now  func() time.Time
+}
+
+func newJobStore(now func() time.Time) *jobStore {
+	return &jobStore{jobs: make(map[uuid.UUID]*Job), now: now}
+}
+
+// add registers a new pending job, dropping jobs that finished long ago
+func (s *jobStore) add(id, actorID uuid.UUID) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for jobID, job := range s.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > jobRetention {
+			delete(s.jobs, jobID)
+		}
+	}
+
+	job := &Job{ID: id, Status: JobPending, CreatedBy: actorID, CreatedAt: now}
+	s.jobs[id] = job
+	return job.snapshot()
+}
+
+// get returns a copy of the job so callers never race with the worker
+func (s *jobStore) get(id uuid.UUID) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return job.snapshot(), true
+}
+
+func (s *jobStore) start(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[id]; ok {
+		job.Status = JobRunning
+	}
+}
+
+func (s *jobStore) finish(id uuid.UUID, result *Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	now := s.now()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = "the import could not be completed"
+		return
+	}
+	job.Status = JobCompleted
+	job.Result = result
+}
+
+func (j *Job) snapshot() *Job {
+	copied := *j
+	return &copied
+}
@@ -0,0 +1,200 @@
+package userimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// Format identifies the encoding of an import file
+type Format string
+
+// Supported import file formats
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// Row is a single user record read from an import file
+type Row struct {
+	Number int // 1-based position of the record in the file, not counting the CSV header
+	Input  domainUser.RegisterUserInput
+	Err    error // Set when the record could not be parsed; the import reports it and moves on
+}
+
+// RowReader yields the records of an import file one at a time, so large
+// files are never decoded in full. Next returns io.EOF after the last record.
+type RowReader interface {
+	Next() (Row, error)
+}
+
+// NewReader returns a RowReader for r in the given format
+func NewReader(r io.Reader, format Format) (RowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSON:
+		return newJSONReader(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// csvReader reads a CSV file whose first line names the columns. Column names
+// are matched case-insensitively with underscores ignored, so both first_name
+// and firstName are accepted; unknown columns are ignored.
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	number  int
+}
+
+var requiredColumns = []string{"email", "password", "firstname", "lastname"}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // Short rows are reported per row instead of aborting the file
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrMissingColumn
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, ErrInvalidCSV
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // Spreadsheet exports often start with a byte order mark
+		}
+		columns[normalizeColumn(name)] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrMissingColumn
+		}
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+func (c *csvReader) Next() (Row, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return Row{}, io.EOF
+	}
+	c.number++
+	row := Row{Number: c.number}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.Err = errMalformedRow
+		return row, nil
+	}
+	if err != nil {
+		return Row{}, fmt.Errorf("failed to read csv row %d: %w", c.number, err)
+	}
+
+	row.Input = domainUser.RegisterUserInput{
+		Email:     c.field(record, "email"),
+		Password:  c.raw(record, "password"),
+		FirstName: c.field(record, "firstname"),
+		LastName:  c.field(record, "lastname"),
+		Residency: c.field(record, "residency"),
+	}
+	return row, nil
+}
+
+// field returns the named column of record with surrounding spaces removed
+func (c *csvReader) field(record []string, name string) string {
+	return strings.TrimSpace(c.raw(record, name))
+}
+
+// raw returns the named column of record as written, or "" when the row is too short
+func (c *csvReader) raw(record []string, name string) string {
+	i, ok := c.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// jsonReader reads a JSON array of objects using the same field names as the
+// registration endpoint, decoding one element at a time.
+type jsonReader struct {
+	dec    *json.Decoder
+	number int
+	done   bool
+}
+
+type jsonRow struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Residency string `json:"residency"`
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, ErrInvalidJSON
+	}
+	return &jsonReader{dec: dec}, nil
+}
+
+func (j *jsonReader) Next() (Row, error) {
+	if j.done {
+		return Row{}, io.EOF
+	}
+	if !j.dec.More() {
+		// Consume the closing bracket so a truncated file is reported
+		if _, err := j.dec.Token(); err != nil {
+			return Row{}, ErrInvalidJSON
+		}
+		j.done = true
+		return Row{}, io.EOF
+	}
+
+	j.number++
+	row := Row{Number: j.number}
+
+	var element jsonRow
+	if err := j.dec.Decode(&element); err != nil {
+		// A value of the wrong type is skipped by the decoder; anything
+		// else leaves the stream unusable
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			row.Err = errMalformedRow
+			return row, nil
+		}
+		return Row{}, ErrInvalidJSON
+	}
+
+	row.Input = domainUser.RegisterUserInput{
+		Email:     strings.TrimSpace(element.Email),
+		Password:  element.Password,
+		FirstName: strings.TrimSpace(element.FirstName),
+		LastName:  strings.TrimSpace(element.LastName),
+		Residency: strings.TrimSpace(element.Residency),
+	}
+	return row, nil
+}
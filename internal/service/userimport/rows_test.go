@@ -0,0 +1,109 @@
+package userimport
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// readAll drains a RowReader
+func readAll(t *testing.T, r RowReader) []Row {
+	t.Helper()
+	var rows []Row
+	for {
+		row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Run("Header Variants And Short Rows", func(t *testing.T) {
+		input := "\ufeffEmail,Password,firstName,LAST_NAME,residency,notes\n" +
+			"a@example.com,pass word1, Ada ,Lovelace,eu,ignored\n" +
+			"b@example.com,password2\n"
+
+		r, err := NewReader(strings.NewReader(input), FormatCSV)
+		require.NoError(t, err)
+
+		rows := readAll(t, r)
+		assert.Equal(t, []Row{
+			{Number: 1, Input: domainUser.RegisterUserInput{Email: "a@example.com", Password: "pass word1", FirstName: "Ada", LastName: "Lovelace", Residency: "eu"}},
+			{Number: 2, Input: domainUser.RegisterUserInput{Email: "b@example.com", Password: "password2"}},
+		}, rows)
+	})
+
+	t.Run("Malformed Row", func(t *testing.T) {
+		input := "email,password,first_name,last_name\n" +
+			"a@\"example.com,password1,Ada,Lovelace\n" +
+			"b@example.com,password2,Grace,Hopper\n"
+
+		r, err := NewReader(strings.NewReader(input), FormatCSV)
+		require.NoError(t, err)
+
+		rows := readAll(t, r)
+		require.Len(t, rows, 2)
+		assert.Equal(t, errMalformedRow, rows[0].Err)
+		assert.Equal(t, "b@example.com", rows[1].Input.Email)
+		assert.Equal(t, 2, rows[1].Number)
+	})
+
+	t.Run("Missing Column", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("email,password,first_name\n"), FormatCSV)
+		assert.Equal(t, ErrMissingColumn, err)
+	})
+
+	t.Run("Empty File", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader(""), FormatCSV)
+		assert.Equal(t, ErrMissingColumn, err)
+	})
+}
+
+func TestJSONReader(t *testing.T) {
+	t.Run("Array Of Users", func(t *testing.T) {
+		input := `[
+			{"email": " a@example.com ", "password": "password1", "firstName": "Ada", "lastName": "Lovelace", "residency": "EU"},
+			{"email": 42, "password": "password2"},
+			{"email": "c@example.com", "password": "password3", "firstName": "Grace", "lastName": "Hopper"}
+		]`
+
+		r, err := NewReader(strings.NewReader(input), FormatJSON)
+		require.NoError(t, err)
+
+		rows := readAll(t, r)
+		require.Len(t, rows, 3)
+		assert.Equal(t, domainUser.RegisterUserInput{Email: "a@example.com", Password: "password1", FirstName: "Ada", LastName: "Lovelace", Residency: "EU"}, rows[0].Input)
+		assert.Equal(t, errMalformedRow, rows[1].Err)
+		assert.Equal(t, 3, rows[2].Number)
+		assert.Equal(t, "c@example.com", rows[2].Input.Email)
+	})
+
+	t.Run("Not An Array", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader(`{"email": "a@example.com"}`), FormatJSON)
+		assert.Equal(t, ErrInvalidJSON, err)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		r, err := NewReader(strings.NewReader(`[{"email": "a@example.com"}, {"email": `), FormatJSON)
+		require.NoError(t, err)
+
+		_, err = r.Next()
+		require.NoError(t, err)
+		_, err = r.Next()
+		assert.Equal(t, ErrInvalidJSON, err)
+	})
+}
+
+func TestNewReader_UnsupportedFormat(t *testing.T) {
+	_, err := NewReader(strings.NewReader(""), Format("xml"))
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
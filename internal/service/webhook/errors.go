@@ -0,0 +1,13 @@
+package webhook
+
+import "github.com/yi-tech/go-user-service/internal/apperror"
+
+// Service-level errors for webhook subscription operations
+var (
+	ErrWebhookNotFound    = apperror.New(apperror.CodeWebhookNotFound, "webhook not found")
+	ErrInvalidURL         = apperror.New(apperror.CodeInvalidArgument, "url must be an absolute http or https URL")
+	ErrEventTypesRequired = apperror.New(apperror.CodeInvalidArgument, "at least one event type is required")
+	ErrUnknownEventType   = apperror.New(apperror.CodeInvalidArgument, "event types must be user.registered, user.updated or user.deleted")
+	ErrSecretTooShort     = apperror.New(apperror.CodeInvalidArgument, "secret must be at least 16 characters")
+	ErrDescriptionTooLong = apperror.New(apperror.CodeInvalidArgument, "description must be at most 255 characters")
+)
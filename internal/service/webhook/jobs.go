@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// Types of the jobs delivering user events to webhook subscriptions
+const (
+	DispatchJob = "webhook.dispatch" // Fans an event out to the subscriptions receiving it
+	DeliverJob  = "webhook.deliver"  // Posts an event to one subscription
+)
+
+// Headers of every delivery. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret of
+// the subscription, so receivers can reject replayed deliveries.
+const (
+	IDHeader        = "X-Webhook-ID" // The event ID, the same on every attempt
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp" // Unix seconds the attempt was signed at
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Enqueuer adds jobs to the background job queue. *jobs.Queue satisfies it.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload any) (*jobs.Job, error)
+}
+
+// eventPayload is the event carried by the webhook jobs
+type eventPayload struct {
+	ID         uuid.UUID               `json:"id"`
+	Type       domainWebhook.EventType `json:"type"`
+	UserID     uuid.UUID               `json:"user_id"`
+	OccurredAt time.Time               `json:"occurred_at"`
+}
+
+// deliverPayload is the payload of a DeliverJob
+type deliverPayload struct {
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	Event          eventPayload `json:"event"`
+}
+
+// Body is the JSON posted to subscriptions
+type Body struct {
+	ID        uuid.UUID               `json:"id"`
+	Type      domainWebhook.EventType `json:"type"`
+	CreatedAt time.Time               `json:"created_at"`
+	Data      BodyData                `json:"data"`
+}
+
+// BodyData describes the user the event is about
+type BodyData struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Sign returns the value of the SignatureHeader of body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher runs the webhook jobs in cmd/worker
+type Dispatcher struct {
+	repo       domainWebhook.Repository
+	deliveries domainWebhook.DeliveryRepository
+	queue      Enqueuer
+	client     *http.Client
+	ids        idgen.Generator
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewDispatcher creates a dispatcher whose deliveries time out after timeout
+func NewDispatcher(repo domainWebhook.Repository, deliveries domainWebhook.DeliveryRepository, queue Enqueuer, timeout time.Duration, ids idgen.Generator, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:       repo,
+		deliveries: deliveries,
+		queue:      queue,
+		client:     &http.Client{Timeout: timeout},
+		ids:        ids,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Register registers the handlers of the webhook jobs with w
+func (d *Dispatcher) Register(w *jobs.Worker) {
+	w.Register(DispatchJob, d.HandleDispatchJob)
+	w.Register(DeliverJob, d.HandleDeliverJob)
+}
+
+// HandleDispatchJob enqueues a DeliverJob for every active subscription to
+// the event, so each subscription is retried on its own. A dispatch retried
+// after a partial failure enqueues some deliveries twice; receivers
+// deduplicate on the IDHeader.
+func (d *Dispatcher) HandleDispatchJob(ctx context.Context, job *jobs.Job) error {
+	var event eventPayload
+	if err := job.Decode(&event); err != nil {
+		return err
+	}
+	subscriptions, err := d.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Receives(event.Type) {
+			continue
+		}
+		payload := deliverPayload{SubscriptionID: subscription.ID, Event: event}
+		if _, err := d.queue.Enqueue(ctx, DeliverJob, payload); err != nil {
+			return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandleDeliverJob posts the event to the subscription and records the
+// attempt in its delivery log. 2xx responses are delivered; other 4xx
+// responses than 408 and 429 fail permanently, everything else is retried.
+// Deliveries to subscriptions deleted or deactivated since are dropped.
+func (d *Dispatcher) HandleDeliverJob(ctx context.Context, job *jobs.Job) error {
+	var payload deliverPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	subscription, err := d.repo.GetByID(ctx, payload.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if subscription == nil || !subscription.Receives(payload.Event.Type) {
+		return nil
+	}
+
+	start := d.now()
+	status, err := d.post(ctx, subscription, payload.Event)
+	delivery := &domainWebhook.Delivery{
+		SubscriptionID: subscription.ID,
+		EventID:        payload.Event.ID,
+		EventType:      payload.Event.Type,
+		Attempt:        job.Attempt,
+		StatusCode:     status,
+		Duration:       d.now().Sub(start),
+		CreatedAt:      start,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	d.record(ctx, delivery)
+	return err
+}
+
+// post sends the event to the subscription, returning the status code of
+// the response or 0 when none was received
+func (d *Dispatcher) post(ctx context.Context, subscription *domainWebhook.Subscription, event eventPayload) (int, error) {
+	body, err := json.Marshal(Body{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.OccurredAt,
+		Data:      BodyData{UserID: event.UserID},
+	})
+	if err != nil {
+		return 0, jobs.Permanent(fmt.Errorf("failed to encode webhook body: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, jobs.Permanent(fmt.Errorf("failed to build webhook request: %w", err))
+	}
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, event.ID.String())
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return resp.StatusCode, jobs.Permanent(fmt.Errorf("webhook rejected the event with status %d", resp.StatusCode))
+	default:
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
+
+// record appends the attempt to the delivery log; a failure is logged and
+// does not affect the delivery
+func (d *Dispatcher) record(ctx context.Context, delivery *domainWebhook.Delivery) {
+	fields := []zap.Field{
+		zap.String("webhook_id", delivery.SubscriptionID.String()),
+		zap.String("event_id", delivery.EventID.String()),
+	}
+	id, err := d.ids.NewID()
+	if err != nil {
+		d.logger.Error("Failed to generate webhook delivery ID", append(fields, zap.Error(err))...)
+		return
+	}
+	delivery.ID = id
+	if err := d.deliveries.Create(ctx, delivery); err != nil {
+		d.logger.Error("Failed to record webhook delivery", append(fields, zap.Error(err))...)
+	}
+}
@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/jobs"
+)
+
+// fakeEnqueuer records the jobs it was given
+type fakeEnqueuer struct {
+	jobs []*jobs.Job
+	err  error
+}
+
+func (q *fakeEnqueuer) Enqueue(_ context.Context, jobType string, payload any) (*jobs.Job, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &jobs.Job{ID: uuid.New(), Type: jobType, Payload: data, Attempt: 1, MaxAttempts: 3}
+	q.jobs = append(q.jobs, job)
+	return job, nil
+}
+
+// recordingPublisher records the events it was given
+type recordingPublisher struct {
+	events []domainEvent.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domainEvent.Event) {
+	p.events = append(p.events, e)
+}
+
+func newTestDispatcher(repo *fakeRepository, deliveries *fakeDeliveries, queue *fakeEnqueuer) *Dispatcher {
+	d := NewDispatcher(repo, deliveries, queue, time.Second, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+	d.now = func() time.Time { return testNow }
+	return d
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("Dispatches Lifecycle Events", func(t *testing.T) {
+		next := &recordingPublisher{}
+		queue := &fakeEnqueuer{}
+		p := NewPublisher(next, queue, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+		p.now = func() time.Time { return testNow }
+
+		p.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeProfileUpdated, UserID: userID})
+
+		require.Len(t, next.events, 1)
+		published := next.events[0]
+		assert.NotEqual(t, uuid.Nil, published.ID)
+		assert.Equal(t, testNow, published.OccurredAt)
+
+		require.Len(t, queue.jobs, 1)
+		assert.Equal(t, DispatchJob, queue.jobs[0].Type)
+		var payload eventPayload
+		require.NoError(t, queue.jobs[0].Decode(&payload))
+		assert.Equal(t, eventPayload{ID: published.ID, Type: domainWebhook.EventUserUpdated, UserID: userID, OccurredAt: testNow}, payload)
+	})
+
+	t.Run("Ignores Other Events", func(t *testing.T) {
+		next := &recordingPublisher{}
+		queue := &fakeEnqueuer{}
+		p := NewPublisher(next, queue, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+
+		p.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserLoggedIn, UserID: userID})
+
+		assert.Len(t, next.events, 1)
+		assert.Empty(t, queue.jobs)
+	})
+
+	t.Run("Enqueue Failure Still Publishes", func(t *testing.T) {
+		next := &recordingPublisher{}
+		p := NewPublisher(next, &fakeEnqueuer{err: errors.New("connection refused")}, idgen.GeneratorFunc(uuid.NewRandom), zap.NewNop())
+
+		p.Publish(ctx, domainEvent.Event{Type: domainEvent.TypeUserDeleted, UserID: userID})
+
+		assert.Len(t, next.events, 1)
+	})
+}
+
+func TestHandleDispatchJob(t *testing.T) {
+	ctx := context.Background()
+	registered := &domainWebhook.Subscription{ID: uuid.New(), URL: "https://a.example.com", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered}, Active: true}
+	everything := &domainWebhook.Subscription{ID: uuid.New(), URL: "https://b.example.com", EventTypes: domainWebhook.EventTypes(), Active: true}
+	inactive := &domainWebhook.Subscription{ID: uuid.New(), URL: "https://c.example.com", EventTypes: domainWebhook.EventTypes()}
+	deleted := &domainWebhook.Subscription{ID: uuid.New(), URL: "https://d.example.com", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserDeleted}, Active: true}
+
+	events := &fakeEnqueuer{}
+	_, err := events.Enqueue(ctx, DispatchJob, eventPayload{ID: uuid.New(), Type: domainWebhook.EventUserRegistered, UserID: uuid.New()})
+	require.NoError(t, err)
+
+	queue := &fakeEnqueuer{}
+	d := newTestDispatcher(newFakeRepository(registered, everything, inactive, deleted), &fakeDeliveries{}, queue)
+
+	require.NoError(t, d.HandleDispatchJob(ctx, events.jobs[0]))
+
+	require.Len(t, queue.jobs, 2)
+	var targets []uuid.UUID
+	for _, job := range queue.jobs {
+		assert.Equal(t, DeliverJob, job.Type)
+		var payload deliverPayload
+		require.NoError(t, job.Decode(&payload))
+		targets = append(targets, payload.SubscriptionID)
+	}
+	assert.Equal(t, []uuid.UUID{registered.ID, everything.ID}, targets)
+}
+
+func TestHandleDeliverJob(t *testing.T) {
+	ctx := context.Background()
+	event := eventPayload{ID: uuid.New(), Type: domainWebhook.EventUserDeleted, UserID: uuid.New(), OccurredAt: testNow.Add(-time.Minute)}
+
+	deliverJob := func(t *testing.T, subscriptionID uuid.UUID, attempt int) *jobs.Job {
+		queue := &fakeEnqueuer{}
+		_, err := queue.Enqueue(ctx, DeliverJob, deliverPayload{SubscriptionID: subscriptionID, Event: event})
+		require.NoError(t, err)
+		queue.jobs[0].Attempt = attempt
+		return queue.jobs[0]
+	}
+
+	t.Run("Posts Signed Event", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		subscription := &domainWebhook.Subscription{ID: uuid.New(), URL: server.URL, Secret: "0123456789abcdef", EventTypes: domainWebhook.EventTypes(), Active: true}
+		deliveries := &fakeDeliveries{}
+		d := newTestDispatcher(newFakeRepository(subscription), deliveries, &fakeEnqueuer{})
+
+		require.NoError(t, d.HandleDeliverJob(ctx, deliverJob(t, subscription.ID, 1)))
+
+		require.NotNil(t, received)
+		timestamp := received.Header.Get(TimestampHeader)
+		assert.Equal(t, "1751792400", timestamp)
+		assert.Equal(t, event.ID.String(), received.Header.Get(IDHeader))
+		assert.Equal(t, "user.deleted", received.Header.Get(EventHeader))
+		assert.Equal(t, Sign(subscription.Secret, timestamp, body), received.Header.Get(SignatureHeader))
+		var posted Body
+		require.NoError(t, json.Unmarshal(body, &posted))
+		assert.Equal(t, Body{ID: event.ID, Type: event.Type, CreatedAt: event.OccurredAt, Data: BodyData{UserID: event.UserID}}, posted)
+
+		require.Len(t, deliveries.deliveries, 1)
+		delivery := deliveries.deliveries[0]
+		assert.True(t, delivery.Succeeded())
+		assert.Equal(t, subscription.ID, delivery.SubscriptionID)
+		assert.Equal(t, event.ID, delivery.EventID)
+		assert.Equal(t, 1, delivery.Attempt)
+		assert.Empty(t, delivery.Error)
+	})
+
+	statusTests := []struct {
+		name              string
+		status            int
+		expectedPermanent bool
+	}{
+		{name: "Server Error Is Retried", status: http.StatusBadGateway},
+		{name: "Rate Limit Is Retried", status: http.StatusTooManyRequests},
+		{name: "Rejection Is Permanent", status: http.StatusGone, expectedPermanent: true},
+	}
+	for _, tt := range statusTests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			subscription := &domainWebhook.Subscription{ID: uuid.New(), URL: server.URL, Secret: "0123456789abcdef", EventTypes: domainWebhook.EventTypes(), Active: true}
+			deliveries := &fakeDeliveries{}
+			d := newTestDispatcher(newFakeRepository(subscription), deliveries, &fakeEnqueuer{})
+
+			err := d.HandleDeliverJob(ctx, deliverJob(t, subscription.ID, 2))
+
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedPermanent, jobs.IsPermanent(err))
+			require.Len(t, deliveries.deliveries, 1)
+			assert.Equal(t, tt.status, deliveries.deliveries[0].StatusCode)
+			assert.Equal(t, 2, deliveries.deliveries[0].Attempt)
+			assert.NotEmpty(t, deliveries.deliveries[0].Error)
+		})
+	}
+
+	t.Run("Unreachable Endpoint Is Retried", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		subscription := &domainWebhook.Subscription{ID: uuid.New(), URL: server.URL, EventTypes: domainWebhook.EventTypes(), Active: true}
+		deliveries := &fakeDeliveries{}
+		d := newTestDispatcher(newFakeRepository(subscription), deliveries, &fakeEnqueuer{})
+
+		err := d.HandleDeliverJob(ctx, deliverJob(t, subscription.ID, 1))
+
+		require.Error(t, err)
+		assert.False(t, jobs.IsPermanent(err))
+		require.Len(t, deliveries.deliveries, 1)
+		assert.Zero(t, deliveries.deliveries[0].StatusCode)
+	})
+
+	t.Run("Deleted Or Deactivated Subscription Is Dropped", func(t *testing.T) {
+		inactive := &domainWebhook.Subscription{ID: uuid.New(), URL: "http://127.0.0.1:0", EventTypes: domainWebhook.EventTypes()}
+		deliveries := &fakeDeliveries{}
+		d := newTestDispatcher(newFakeRepository(inactive), deliveries, &fakeEnqueuer{})
+
+		assert.NoError(t, d.HandleDeliverJob(ctx, deliverJob(t, inactive.ID, 1)))
+		assert.NoError(t, d.HandleDeliverJob(ctx, deliverJob(t, uuid.New(), 1)))
+		assert.Empty(t, deliveries.deliveries)
+	})
+}
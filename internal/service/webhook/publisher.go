@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// Publisher is a domainEvent.Publisher that, besides publishing every event
+// to next, enqueues a DispatchJob for the user lifecycle events webhooks
+// receive. The event ID is assigned first so clients and webhooks see the
+// same one. Like publishing, enqueueing is best effort: a failure is logged.
+type Publisher struct {
+	next   domainEvent.Publisher
+	queue  Enqueuer
+	ids    idgen.Generator
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewPublisher creates a publisher dispatching webhooks besides next
+func NewPublisher(next domainEvent.Publisher, queue Enqueuer, ids idgen.Generator, logger *zap.Logger) *Publisher {
+	return &Publisher{next: next, queue: queue, ids: ids, logger: logger, now: time.Now}
+}
+
+func (p *Publisher) Publish(ctx context.Context, e domainEvent.Event) {
+	eventType, ok := domainWebhook.EventTypeOf(e.Type)
+	if !ok {
+		p.next.Publish(ctx, e)
+		return
+	}
+
+	if e.ID == uuid.Nil {
+		id, err := p.ids.NewID()
+		if err != nil {
+			p.logger.Warn("Failed to generate event ID", zap.String("event_type", string(e.Type)), zap.Error(err))
+			p.next.Publish(ctx, e)
+			return
+		}
+		e.ID = id
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = p.now().UTC()
+	}
+	p.next.Publish(ctx, e)
+
+	payload := eventPayload{ID: e.ID, Type: eventType, UserID: e.UserID, OccurredAt: e.OccurredAt}
+	if _, err := p.queue.Enqueue(ctx, DispatchJob, payload); err != nil {
+		p.logger.Error("Failed to enqueue webhook dispatch",
+			zap.String("event_type", string(e.Type)),
+			zap.String("user_id", e.UserID.String()),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// minSecretLength is the shortest secret an administrator may choose
+const minSecretLength = 16
+
+// maxDescriptionLength matches the description column
+const maxDescriptionLength = 255
+
+// Service defines the interface for managing webhook subscriptions
+type Service interface {
+	// List returns every subscription, newest first
+	List(ctx context.Context) ([]*domainWebhook.Subscription, error)
+
+	// Get returns a subscription
+	Get(ctx context.Context, id uuid.UUID) (*domainWebhook.Subscription, error)
+
+	// Create registers a subscription on behalf of an administrator. The
+	// returned subscription carries its secret, generated when the input has
+	// none; it is the only time the secret is handed out.
+	Create(ctx context.Context, actorID uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error)
+
+	// Update replaces the editable fields of a subscription
+	Update(ctx context.Context, id uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error)
+
+	// Delete removes a subscription and its delivery log
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListDeliveries returns a page of the delivery log of a subscription, newest first
+	ListDeliveries(ctx context.Context, filter domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, int64, error)
+}
+
+type webhookService struct {
+	repo       domainWebhook.Repository
+	deliveries domainWebhook.DeliveryRepository
+	ids        idgen.Generator
+	now        func() time.Time
+}
+
+// NewWebhookService creates a new instance of Service
+func NewWebhookService(repo domainWebhook.Repository, deliveries domainWebhook.DeliveryRepository, ids idgen.Generator) Service {
+	return &webhookService{repo: repo, deliveries: deliveries, ids: ids, now: time.Now}
+}
+
+func (s *webhookService) List(ctx context.Context) ([]*domainWebhook.Subscription, error) {
+	subscriptions, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (s *webhookService) Get(ctx context.Context, id uuid.UUID) (*domainWebhook.Subscription, error) {
+	subscription, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) Create(ctx context.Context, actorID uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error) {
+	input, err := normalizeInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Secret == "" {
+		if input.Secret, err = newSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := s.ids.NewID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook id: %w", err)
+	}
+
+	now := s.now()
+	subscription := &domainWebhook.Subscription{ID: id, CreatedBy: actorID, CreatedAt: now, UpdatedAt: now}
+	apply(subscription, input)
+
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, id uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error) {
+	input, err := normalizeInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Secret == "" {
+		input.Secret = subscription.Secret
+	}
+
+	apply(subscription, input)
+	subscription.UpdatedAt = s.now()
+
+	if err := s.repo.Update(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, filter domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, int64, error) {
+	if _, err := s.Get(ctx, filter.SubscriptionID); err != nil {
+		return nil, 0, err
+	}
+	deliveries, total, err := s.deliveries.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// normalizeInput validates the input and removes duplicate event types
+func normalizeInput(input domainWebhook.Input) (domainWebhook.Input, error) {
+	input.URL = strings.TrimSpace(input.URL)
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return input, ErrInvalidURL
+	}
+
+	if len(input.EventTypes) == 0 {
+		return input, ErrEventTypesRequired
+	}
+	eventTypes := make([]domainWebhook.EventType, 0, len(input.EventTypes))
+	seen := make(map[domainWebhook.EventType]bool, len(input.EventTypes))
+	for _, t := range input.EventTypes {
+		if !t.Valid() {
+			return input, ErrUnknownEventType
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	input.EventTypes = eventTypes
+
+	if input.Secret != "" && len(input.Secret) < minSecretLength {
+		return input, ErrSecretTooShort
+	}
+	if len(input.Description) > maxDescriptionLength {
+		return input, ErrDescriptionTooLong
+	}
+	return input, nil
+}
+
+// newSecret generates a random signing secret
+func newSecret() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// apply copies the editable fields from input onto subscription
+func apply(subscription *domainWebhook.Subscription, input domainWebhook.Input) {
+	subscription.URL = input.URL
+	subscription.Secret = input.Secret
+	subscription.EventTypes = input.EventTypes
+	subscription.Description = input.Description
+	subscription.Active = input.Active
+}
@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// fakeRepository keeps subscriptions in memory
+type fakeRepository struct {
+	subscriptions map[uuid.UUID]*domainWebhook.Subscription
+}
+
+func newFakeRepository(subscriptions ...*domainWebhook.Subscription) *fakeRepository {
+	r := &fakeRepository{subscriptions: make(map[uuid.UUID]*domainWebhook.Subscription)}
+	for _, s := range subscriptions {
+		r.subscriptions[s.ID] = s
+	}
+	return r
+}
+
+func (r *fakeRepository) Create(_ context.Context, subscription *domainWebhook.Subscription) error {
+	copied := *subscription
+	r.subscriptions[subscription.ID] = &copied
+	return nil
+}
+
+func (r *fakeRepository) GetByID(_ context.Context, id uuid.UUID) (*domainWebhook.Subscription, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *subscription
+	return &copied, nil
+}
+
+func (r *fakeRepository) Update(ctx context.Context, subscription *domainWebhook.Subscription) error {
+	return r.Create(ctx, subscription)
+}
+
+func (r *fakeRepository) Delete(_ context.Context, id uuid.UUID) error {
+	delete(r.subscriptions, id)
+	return nil
+}
+
+func (r *fakeRepository) List(_ context.Context) ([]*domainWebhook.Subscription, error) {
+	subscriptions := make([]*domainWebhook.Subscription, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		copied := *s
+		subscriptions = append(subscriptions, &copied)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].URL < subscriptions[j].URL })
+	return subscriptions, nil
+}
+
+// fakeDeliveries keeps the delivery log in memory
+type fakeDeliveries struct {
+	deliveries []*domainWebhook.Delivery
+}
+
+func (r *fakeDeliveries) Create(_ context.Context, delivery *domainWebhook.Delivery) error {
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *fakeDeliveries) List(_ context.Context, filter domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, int64, error) {
+	var matches []*domainWebhook.Delivery
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == filter.SubscriptionID {
+			matches = append(matches, d)
+		}
+	}
+	total := int64(len(matches))
+	if filter.Offset >= len(matches) {
+		return nil, total, nil
+	}
+	matches = matches[filter.Offset:]
+	if len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+	return matches, total, nil
+}
+
+var testNow = time.Date(2025, 7, 6, 9, 0, 0, 0, time.UTC)
+
+func newTestService(repo *fakeRepository, deliveries *fakeDeliveries) *webhookService {
+	s := NewWebhookService(repo, deliveries, idgen.GeneratorFunc(uuid.NewRandom)).(*webhookService)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+
+	tests := []struct {
+		name        string
+		input       domainWebhook.Input
+		expectedErr error
+	}{
+		{
+			name:  "Valid",
+			input: domainWebhook.Input{URL: " https://hooks.example.com/users ", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered}, Active: true},
+		},
+		{
+			name:        "Relative URL",
+			input:       domainWebhook.Input{URL: "/users", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered}},
+			expectedErr: ErrInvalidURL,
+		},
+		{
+			name:        "Unsupported Scheme",
+			input:       domainWebhook.Input{URL: "ftp://hooks.example.com", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered}},
+			expectedErr: ErrInvalidURL,
+		},
+		{
+			name:        "No Event Types",
+			input:       domainWebhook.Input{URL: "https://hooks.example.com"},
+			expectedErr: ErrEventTypesRequired,
+		},
+		{
+			name:        "Unknown Event Type",
+			input:       domainWebhook.Input{URL: "https://hooks.example.com", EventTypes: []domainWebhook.EventType{"user.logged_in"}},
+			expectedErr: ErrUnknownEventType,
+		},
+		{
+			name:        "Short Secret",
+			input:       domainWebhook.Input{URL: "https://hooks.example.com", Secret: "short", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserDeleted}},
+			expectedErr: ErrSecretTooShort,
+		},
+		{
+			name:        "Long Description",
+			input:       domainWebhook.Input{URL: "https://hooks.example.com", EventTypes: []domainWebhook.EventType{domainWebhook.EventUserDeleted}, Description: strings.Repeat("a", 256)},
+			expectedErr: ErrDescriptionTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			s := newTestService(repo, &fakeDeliveries{})
+
+			subscription, err := s.Create(ctx, actorID, tt.input)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.subscriptions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://hooks.example.com/users", subscription.URL)
+			assert.True(t, strings.HasPrefix(subscription.Secret, "whsec_"))
+			assert.Equal(t, actorID, subscription.CreatedBy)
+			assert.Equal(t, testNow, subscription.CreatedAt)
+			assert.Contains(t, repo.subscriptions, subscription.ID)
+		})
+	}
+
+	t.Run("Keeps Chosen Secret And Removes Duplicate Event Types", func(t *testing.T) {
+		s := newTestService(newFakeRepository(), &fakeDeliveries{})
+
+		subscription, err := s.Create(ctx, actorID, domainWebhook.Input{
+			URL:        "https://hooks.example.com",
+			Secret:     "0123456789abcdef",
+			EventTypes: []domainWebhook.EventType{domainWebhook.EventUserUpdated, domainWebhook.EventUserUpdated},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", subscription.Secret)
+		assert.Equal(t, []domainWebhook.EventType{domainWebhook.EventUserUpdated}, subscription.EventTypes)
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	ctx := context.Background()
+	existing := &domainWebhook.Subscription{
+		ID:         uuid.New(),
+		URL:        "https://hooks.example.com/old",
+		Secret:     "current-secret-value",
+		EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered},
+		Active:     true,
+	}
+
+	t.Run("Keeps Secret When Omitted", func(t *testing.T) {
+		repo := newFakeRepository(existing)
+		s := newTestService(repo, &fakeDeliveries{})
+
+		updated, err := s.Update(ctx, existing.ID, domainWebhook.Input{
+			URL:        "https://hooks.example.com/new",
+			EventTypes: []domainWebhook.EventType{domainWebhook.EventUserDeleted},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://hooks.example.com/new", updated.URL)
+		assert.Equal(t, "current-secret-value", updated.Secret)
+		assert.False(t, updated.Active)
+		assert.Equal(t, testNow, updated.UpdatedAt)
+		assert.Equal(t, "https://hooks.example.com/new", repo.subscriptions[existing.ID].URL)
+	})
+
+	t.Run("Rotates Secret", func(t *testing.T) {
+		s := newTestService(newFakeRepository(existing), &fakeDeliveries{})
+
+		updated, err := s.Update(ctx, existing.ID, domainWebhook.Input{
+			URL:        existing.URL,
+			Secret:     "rotated-secret-value",
+			EventTypes: existing.EventTypes,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "rotated-secret-value", updated.Secret)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		s := newTestService(newFakeRepository(), &fakeDeliveries{})
+
+		_, err := s.Update(ctx, existing.ID, domainWebhook.Input{URL: existing.URL, EventTypes: existing.EventTypes})
+
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	ctx := context.Background()
+	existing := &domainWebhook.Subscription{ID: uuid.New()}
+	repo := newFakeRepository(existing)
+	s := newTestService(repo, &fakeDeliveries{})
+
+	require.NoError(t, s.Delete(ctx, existing.ID))
+	assert.Empty(t, repo.subscriptions)
+	assert.ErrorIs(t, s.Delete(ctx, existing.ID), ErrWebhookNotFound)
+}
+
+func TestListDeliveries(t *testing.T) {
+	ctx := context.Background()
+	existing := &domainWebhook.Subscription{ID: uuid.New()}
+	deliveries := &fakeDeliveries{deliveries: []*domainWebhook.Delivery{
+		{ID: uuid.New(), SubscriptionID: existing.ID, Attempt: 2},
+		{ID: uuid.New(), SubscriptionID: uuid.New(), Attempt: 1},
+		{ID: uuid.New(), SubscriptionID: existing.ID, Attempt: 1},
+	}}
+	s := newTestService(newFakeRepository(existing), deliveries)
+
+	page, total, err := s.ListDeliveries(ctx, domainWebhook.DeliveryFilter{SubscriptionID: existing.ID, Limit: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, page, 1)
+	assert.Equal(t, 2, page[0].Attempt)
+
+	_, _, err = s.ListDeliveries(ctx, domainWebhook.DeliveryFilter{SubscriptionID: uuid.New(), Limit: 10})
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
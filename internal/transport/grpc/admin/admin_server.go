@@ -0,0 +1,268 @@
+package admin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/buildinfo"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
+)
+
+// Page sizes of ListUsers, as on the REST user listing
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// StreamPageSize is the number of users StreamUsers reads per query
+const StreamPageSize = 500
+
+// UserLookup loads the caller to check their role. serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
+}
+
+// Settings describes the active configuration with its secrets redacted.
+// *config.Config satisfies it.
+type Settings interface {
+	Redacted() map[string]any
+}
+
+// AdminServer implements the AdminService gRPC service
+type AdminServer struct {
+	adminpb.UnimplementedAdminServiceServer
+	admin    serviceAdmin.AdminService
+	users    UserLookup
+	settings Settings       // nil when GetServerInfo reports no configuration
+	ids      idgen.Strategy // Text form of rendered IDs
+	logger   *zap.Logger
+}
+
+// NewAdminServer creates a new AdminServer
+func NewAdminServer(adminService serviceAdmin.AdminService, users UserLookup, settings Settings, ids idgen.Strategy, logger *zap.Logger) *AdminServer {
+	return &AdminServer{
+		admin:    adminService,
+		users:    users,
+		settings: settings,
+		ids:      ids,
+		logger:   logger,
+	}
+}
+
+// ListUsers lists user accounts, newest first
+func (s *AdminServer) ListUsers(ctx context.Context, req *adminpb.ListUsersRequest) (*adminpb.ListUsersResponse, error) {
+	if _, err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := int(req.Page), int(req.PageSize)
+	if page < 0 || pageSize < 0 || pageSize > MaxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page must be positive and page_size between 1 and %d", MaxPageSize)
+	}
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	filter, err := listFilter(req.Query, req.Role, req.Status, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	filter.Offset = (page - 1) * pageSize
+	filter.Limit = pageSize
+
+	users, total, err := s.admin.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, s.fail("List users failed", err)
+	}
+	resp := &adminpb.ListUsersResponse{
+		Users:    make([]*adminpb.User, 0, len(users)),
+		Total:    total,
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, mapper.AdminUserToPb(user, s.ids))
+	}
+	return resp, nil
+}
+
+// StreamUsers streams every user account matching the filters, ordered by
+// ID, reading StreamPageSize users per query
+func (s *AdminServer) StreamUsers(req *adminpb.StreamUsersRequest, stream adminpb.AdminService_StreamUsersServer) error {
+	ctx := stream.Context()
+	if _, err := s.authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	filter, err := listFilter(req.Query, req.Role, req.Status, req.Metadata)
+	if err != nil {
+		return err
+	}
+	filter.Limit = StreamPageSize
+
+	afterID := uuid.Nil
+	if req.AfterId != "" {
+		if afterID, err = idgen.Parse(req.AfterId); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid after_id: %v", err)
+		}
+	}
+
+	for {
+		users, err := s.admin.ListUsersAfter(ctx, filter, afterID)
+		if err != nil {
+			// A client that hung up is not a failure worth logging
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return s.fail("Stream users failed", err)
+		}
+		for _, user := range users {
+			if err := stream.Send(mapper.AdminUserToPb(user, s.ids)); err != nil {
+				return err
+			}
+		}
+		if len(users) < filter.Limit {
+			return nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// ForcePasswordReset requires a user to choose a new password
+func (s *AdminServer) ForcePasswordReset(ctx context.Context, req *adminpb.ForcePasswordResetRequest) (*adminpb.User, error) {
+	actorID, userID, err := s.actorAndTarget(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.admin.ForcePasswordReset(ctx, actorID, userID)
+	if err != nil {
+		return nil, s.fail("Force password reset failed", err)
+	}
+	s.logger.Info("Password reset forced",
+		zap.String("operation", "ForcePasswordReset"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()))
+	return mapper.AdminUserToPb(user, s.ids), nil
+}
+
+// DeleteUser deletes a user account
+func (s *AdminServer) DeleteUser(ctx context.Context, req *adminpb.DeleteUserRequest) (*emptypb.Empty, error) {
+	actorID, userID, err := s.actorAndTarget(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.admin.DeleteUser(ctx, actorID, userID); err != nil {
+		return nil, s.fail("Delete user failed", err)
+	}
+	s.logger.Info("User deleted",
+		zap.String("operation", "DeleteUser"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()))
+	return &emptypb.Empty{}, nil
+}
+
+// GetServerInfo describes the build and the configuration of this instance
+func (s *AdminServer) GetServerInfo(ctx context.Context, req *adminpb.GetServerInfoRequest) (*adminpb.ServerInfo, error) {
+	if _, err := s.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	info := buildinfo.Get()
+	resp := &adminpb.ServerInfo{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildDate: info.BuildDate,
+		GoVersion: info.GoVersion,
+	}
+	if s.settings != nil {
+		config, err := structpb.NewStruct(s.settings.Redacted())
+		if err != nil {
+			return nil, s.fail("Render configuration failed", err)
+		}
+		resp.Config = config
+	}
+	return resp, nil
+}
+
+// listFilter builds the criteria of a user listing from request fields
+func listFilter(query, role, accountStatus string, metadata map[string]string) (domainUser.ListFilter, error) {
+	filter := domainUser.ListFilter{
+		Query:    strings.TrimSpace(query),
+		Role:     rbac.Role(role),
+		Metadata: metadata,
+	}
+	switch accountStatus {
+	case "":
+	case "active", "inactive":
+		active := accountStatus == "active"
+		filter.Active = &active
+	default:
+		return domainUser.ListFilter{}, status.Error(codes.InvalidArgument, "status must be active or inactive")
+	}
+	return filter, nil
+}
+
+// actorAndTarget authorizes the caller as an administrator and parses the
+// ID of the user the request is about
+func (s *AdminServer) actorAndTarget(ctx context.Context, rawID string) (uuid.UUID, uuid.UUID, error) {
+	userID, err := idgen.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+	actorID, err := s.authorizeAdmin(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return actorID, userID, nil
+}
+
+// authorizeAdmin ensures the authenticated caller is an active administrator
+// and returns their ID. The role is loaded on every call so demotions take
+// effect immediately.
+func (s *AdminServer) authorizeAdmin(ctx context.Context) (uuid.UUID, error) {
+	callerID, ok := requestctx.UserID(ctx)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+
+	caller, err := s.users.GetByID(ctx, callerID)
+	if err != nil {
+		// A user deleted after their token was issued is simply not authorized
+		if apperror.CodeOf(err) != apperror.CodeUserNotFound {
+			s.logger.Error("Failed to load user for role check", zap.Error(err))
+			return uuid.Nil, apperror.GRPCStatus(err)
+		}
+		caller = nil
+	}
+	if caller == nil || !caller.IsActive || caller.Role != rbac.RoleAdmin {
+		return uuid.Nil, status.Error(codes.PermissionDenied, "administrator role is required")
+	}
+	return callerID, nil
+}
+
+// fail logs unexpected errors and converts err to a gRPC status
+func (s *AdminServer) fail(msg string, err error) error {
+	if _, ok := apperror.As(err); !ok {
+		s.logger.Error(msg, zap.Error(err))
+	}
+	return apperror.GRPCStatus(err)
+}
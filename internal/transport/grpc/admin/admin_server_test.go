@@ -0,0 +1,279 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	repoUser "github.com/yi-tech/go-user-service/internal/repository/user"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// stubService answers the calls a test makes; any other call panics on the
+// nil embedded AdminService
+type stubService struct {
+	serviceAdmin.AdminService
+	users   []*domainUser.User
+	filter  domainUser.ListFilter
+	cursors []uuid.UUID // afterID of each ListUsersAfter call
+	deleted uuid.UUID
+	err     error
+}
+
+// userStream collects the users a server-streaming RPC sends
+type userStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*adminpb.User
+}
+
+func (s *userStream) Context() context.Context { return s.ctx }
+
+func (s *userStream) Send(user *adminpb.User) error {
+	s.sent = append(s.sent, user)
+	return nil
+}
+
+func (s *stubService) ListUsers(_ context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	s.filter = filter
+	return s.users, int64(len(s.users)), s.err
+}
+
+// ListUsersAfter pages through users in their order, recording the cursor of each call
+func (s *stubService) ListUsersAfter(_ context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	s.filter = filter
+	s.cursors = append(s.cursors, afterID)
+	if s.err != nil {
+		return nil, s.err
+	}
+	start := 0
+	for i, u := range s.users {
+		if u.ID == afterID {
+			start = i + 1
+		}
+	}
+	end := min(start+filter.Limit, len(s.users))
+	return s.users[start:end], nil
+}
+
+func (s *stubService) DeleteUser(_ context.Context, _, userID uuid.UUID) error {
+	s.deleted = userID
+	return s.err
+}
+
+func TestAdminServer_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := repoUser.NewInMemoryRepository()
+	admin, err := repoUser.NewUserBuilder().WithRole(rbac.RoleAdmin).Create(ctx, repo)
+	require.NoError(t, err)
+	member, err := repoUser.NewUserBuilder().WithCreatedAt(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)).Create(ctx, repo)
+	require.NoError(t, err)
+	users := serviceUser.NewUserService(repo)
+
+	tests := []struct {
+		name     string
+		caller   uuid.UUID
+		req      *adminpb.ListUsersRequest
+		code     codes.Code
+		expected domainUser.ListFilter
+	}{
+		{name: "Defaults", caller: admin.ID, req: &adminpb.ListUsersRequest{}, expected: domainUser.ListFilter{Limit: DefaultPageSize}},
+		{name: "Filtered Page", caller: admin.ID, req: &adminpb.ListUsersRequest{Page: 3, PageSize: 10, Query: " smith ", Role: "support", Status: "inactive"},
+			expected: domainUser.ListFilter{Query: "smith", Role: rbac.RoleSupport, Active: new(bool), Offset: 20, Limit: 10}},
+		{name: "Page Size Too Large", caller: admin.ID, req: &adminpb.ListUsersRequest{PageSize: MaxPageSize + 1}, code: codes.InvalidArgument},
+		{name: "Unknown Status", caller: admin.ID, req: &adminpb.ListUsersRequest{Status: "deleted"}, code: codes.InvalidArgument},
+		{name: "Not An Administrator", caller: member.ID, req: &adminpb.ListUsersRequest{}, code: codes.PermissionDenied},
+		{name: "Unknown Caller", caller: uuid.New(), req: &adminpb.ListUsersRequest{}, code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubService{users: []*domainUser.User{member}}
+			server := NewAdminServer(service, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			resp, err := server.ListUsers(requestctx.WithUserID(ctx, tt.caller), tt.req)
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.code, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, service.filter)
+			require.Len(t, resp.Users, 1)
+			assert.Equal(t, member.ID.String(), resp.Users[0].Id)
+			assert.Equal(t, "user", resp.Users[0].Role)
+			assert.Equal(t, int64(1), resp.Total)
+		})
+	}
+}
+
+func TestAdminServer_StreamUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := repoUser.NewInMemoryRepository()
+	admin, err := repoUser.NewUserBuilder().WithRole(rbac.RoleAdmin).Create(ctx, repo)
+	require.NoError(t, err)
+	users := serviceUser.NewUserService(repo)
+	ctx = requestctx.WithUserID(ctx, admin.ID)
+
+	// One full page and a partial one
+	listed := make([]*domainUser.User, StreamPageSize+2)
+	for i := range listed {
+		listed[i] = &domainUser.User{ID: uuid.New(), Role: rbac.RoleUser}
+	}
+
+	t.Run("Pages Through Every User", func(t *testing.T) {
+		service := &stubService{users: listed}
+		server := NewAdminServer(service, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		stream := &userStream{ctx: ctx}
+
+		err := server.StreamUsers(&adminpb.StreamUsersRequest{Status: "active"}, stream)
+
+		require.NoError(t, err)
+		require.Len(t, stream.sent, len(listed))
+		assert.Equal(t, listed[len(listed)-1].ID.String(), stream.sent[len(listed)-1].Id)
+		assert.Equal(t, []uuid.UUID{uuid.Nil, listed[StreamPageSize-1].ID}, service.cursors)
+		active := true
+		assert.Equal(t, domainUser.ListFilter{Active: &active, Limit: StreamPageSize}, service.filter)
+	})
+
+	t.Run("Resumes After ID", func(t *testing.T) {
+		service := &stubService{users: listed}
+		server := NewAdminServer(service, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		stream := &userStream{ctx: ctx}
+
+		err := server.StreamUsers(&adminpb.StreamUsersRequest{AfterId: listed[StreamPageSize].ID.String()}, stream)
+
+		require.NoError(t, err)
+		require.Len(t, stream.sent, 1)
+		assert.Equal(t, listed[StreamPageSize+1].ID.String(), stream.sent[0].Id)
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  *adminpb.StreamUsersRequest
+		err  error
+		code codes.Code
+	}{
+		{name: "Invalid After ID", ctx: ctx, req: &adminpb.StreamUsersRequest{AfterId: "nope"}, code: codes.InvalidArgument},
+		{name: "Unknown Status", ctx: ctx, req: &adminpb.StreamUsersRequest{Status: "deleted"}, code: codes.InvalidArgument},
+		{name: "Not Authenticated", ctx: context.Background(), req: &adminpb.StreamUsersRequest{}, code: codes.Unauthenticated},
+		{name: "Listing Fails", ctx: ctx, req: &adminpb.StreamUsersRequest{}, err: errors.New("db down"), code: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewAdminServer(&stubService{users: listed, err: tt.err}, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+			stream := &userStream{ctx: tt.ctx}
+
+			err := server.StreamUsers(tt.req, stream)
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Empty(t, stream.sent)
+		})
+	}
+}
+
+func TestAdminServer_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	repo := repoUser.NewInMemoryRepository()
+	admin, err := repoUser.NewUserBuilder().WithRole(rbac.RoleAdmin).Create(ctx, repo)
+	require.NoError(t, err)
+	users := serviceUser.NewUserService(repo)
+	ctx = requestctx.WithUserID(ctx, admin.ID)
+	targetID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		service := &stubService{}
+		server := NewAdminServer(service, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.DeleteUser(ctx, &adminpb.DeleteUserRequest{Id: targetID.String()})
+
+		require.NoError(t, err)
+		assert.Equal(t, targetID, service.deleted)
+	})
+
+	t.Run("Self Deletion", func(t *testing.T) {
+		server := NewAdminServer(&stubService{err: serviceAdmin.ErrSelfDeletion}, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.DeleteUser(ctx, &adminpb.DeleteUserRequest{Id: admin.ID.String()})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		server := NewAdminServer(&stubService{err: serviceUser.ErrUserNotFound}, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.DeleteUser(ctx, &adminpb.DeleteUserRequest{Id: targetID.String()})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		server := NewAdminServer(&stubService{}, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.DeleteUser(ctx, &adminpb.DeleteUserRequest{Id: "nope"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+// stubSettings returns fixed redacted settings
+type stubSettings map[string]any
+
+func (s stubSettings) Redacted() map[string]any {
+	return s
+}
+
+func TestAdminServer_GetServerInfo(t *testing.T) {
+	ctx := context.Background()
+	repo := repoUser.NewInMemoryRepository()
+	admin, err := repoUser.NewUserBuilder().WithRole(rbac.RoleAdmin).Create(ctx, repo)
+	require.NoError(t, err)
+	member, err := repoUser.NewUserBuilder().Create(ctx, repo)
+	require.NoError(t, err)
+	users := serviceUser.NewUserService(repo)
+	settings := stubSettings{"jwt": map[string]any{"secret": "[REDACTED]"}}
+
+	t.Run("Success", func(t *testing.T) {
+		server := NewAdminServer(&stubService{}, users, settings, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.GetServerInfo(requestctx.WithUserID(ctx, admin.ID), &adminpb.GetServerInfoRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, runtime.Version(), resp.GoVersion)
+		assert.NotEmpty(t, resp.Version)
+		assert.Equal(t, map[string]any{"jwt": map[string]any{"secret": "[REDACTED]"}}, resp.Config.AsMap())
+	})
+
+	t.Run("Without Settings", func(t *testing.T) {
+		server := NewAdminServer(&stubService{}, users, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.GetServerInfo(requestctx.WithUserID(ctx, admin.ID), &adminpb.GetServerInfoRequest{})
+
+		require.NoError(t, err)
+		assert.Nil(t, resp.Config)
+	})
+
+	t.Run("Not An Administrator", func(t *testing.T) {
+		server := NewAdminServer(&stubService{}, users, settings, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.GetServerInfo(requestctx.WithUserID(ctx, member.ID), &adminpb.GetServerInfoRequest{})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
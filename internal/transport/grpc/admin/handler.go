@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"go.uber.org/zap"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+)
+
+// Handler is a wrapper for the AdminServer to match the wire.go expectations
+type Handler struct {
+	*AdminServer
+}
+
+// NewHandler creates a new admin gRPC handler
+func NewHandler(adminService serviceAdmin.AdminService, users UserLookup, settings Settings, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		AdminServer: NewAdminServer(adminService, users, settings, ids, logger),
+	}
+}
+
+// GetServer returns the underlying AdminServer for registration with gRPC
+func (h *Handler) GetServer() adminpb.AdminServiceServer {
+	return h.AdminServer
+}
@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -9,23 +12,40 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
 	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
 )
 
+// UserLookup loads the account an access token was issued to.
+// serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
+}
+
 // AuthServer implements the AuthService gRPC service
 type AuthServer struct {
 	authpb.UnimplementedAuthServiceServer
 	authService domainAuth.AuthService
+	users       UserLookup
+	ids         idgen.Strategy // Text form of rendered IDs
 	logger      *zap.Logger
 }
 
 // NewAuthServer creates a new AuthServer
-func NewAuthServer(authService domainAuth.AuthService, logger *zap.Logger) *AuthServer {
+func NewAuthServer(authService domainAuth.AuthService, users UserLookup, ids idgen.Strategy, logger *zap.Logger) *AuthServer {
 	return &AuthServer{
 		authService: authService,
+		users:       users,
+		ids:         ids,
 		logger:      logger,
 	}
 }
@@ -45,28 +65,56 @@ func (s *AuthServer) Login(ctx context.Context, req *authpb.LoginRequest) (*auth
 		return nil, status.Errorf(codes.InvalidArgument, "password is required")
 	}
 
+	userAgent, clientIP := clientInfo(ctx)
 	loginInput := domainAuth.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:        req.Email,
+		Password:     req.Password,
+		UserAgent:    userAgent,
+		ClientIP:     clientIP,
+		CaptchaToken: metadataValue(ctx, captchaMetadataKey),
+		RememberMe:   req.RememberMe,
 	}
 	// Call the auth service to authenticate the user
 	tokenPair, err := s.authService.Login(ctx, loginInput)
 	if err != nil {
 		s.logger.Error("Login failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
 
-		// Check for specific error types
-		if err.Error() == "invalid credentials" {
-			return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
-		}
+	return tokenResponse(tokenPair), nil
+}
+
+// tokenResponse describes an issued token pair along with when its tokens expire
+func tokenResponse(tokens *domainAuth.TokenPair) *authpb.TokenResponse {
+	resp := &authpb.TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    max(int64(time.Until(tokens.AccessTokenExpiresAt).Round(time.Second)/time.Second), 0),
+		ExpiresAt:    timestamppb.New(tokens.AccessTokenExpiresAt),
+	}
+	if !tokens.RefreshTokenExpiresAt.IsZero() {
+		resp.RefreshExpiresAt = timestamppb.New(tokens.RefreshTokenExpiresAt)
+	}
+	return resp
+}
 
-		// For other errors (like database errors), return Internal error code
-		return nil, status.Errorf(codes.Internal, "authentication failed: %v", err)
+// captchaMetadataKey carries the CAPTCHA token required after repeated failed sign-ins
+const captchaMetadataKey = "x-captcha-token"
+
+// metadataValue returns the first value of an incoming metadata key
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
 	}
+	return ""
+}
 
-	return &authpb.TokenResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-	}, nil
+// clientInfo returns the caller's user agent and IP address for session
+// tracking; the address is the one the client IP interceptor resolved
+func clientInfo(ctx context.Context) (userAgent, clientIP string) {
+	return metadataValue(ctx, "user-agent"), clientip.FromContext(ctx)
 }
 
 // RefreshToken refreshes an access token using a refresh token
@@ -83,20 +131,10 @@ func (s *AuthServer) RefreshToken(ctx context.Context, req *authpb.RefreshTokenR
 	tokenPair, err := s.authService.RefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		s.logger.Error("Token refresh failed", zap.Error(err))
-
-		// Check for specific error types
-		if err.Error() == "invalid token" || err.Error() == "session not found" {
-			return nil, status.Errorf(codes.Unauthenticated, "token refresh failed: %v", err)
-		}
-
-		// For other errors (like database errors), return Internal error code
-		return nil, status.Errorf(codes.Internal, "token refresh failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
-	return &authpb.TokenResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-	}, nil
+	return tokenResponse(tokenPair), nil
 }
 
 // Logout invalidates a refresh token
@@ -109,36 +147,11 @@ func (s *AuthServer) Logout(ctx context.Context, req *authpb.LogoutRequest) (*em
 		return nil, status.Errorf(codes.InvalidArgument, "refresh token is required")
 	}
 
-	// Extract user ID from context metadata
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		s.logger.Error("Logout failed: no metadata in context")
-		return nil, status.Errorf(codes.Unauthenticated, "no metadata in context")
-	}
-
-	userIDValues := md.Get("user-id")
-	if len(userIDValues) == 0 {
-		s.logger.Error("Logout failed: no user ID in metadata")
-		return nil, status.Errorf(codes.Unauthenticated, "no user ID in metadata")
-	}
-
-	userID, err := uuid.Parse(userIDValues[0])
-	if err != nil {
-		s.logger.Error("Invalid user ID format", zap.Error(err))
-		return nil, status.Errorf(codes.Unauthenticated, "invalid user ID format: %v", err)
-	}
-
-	// Call the auth service to logout the user
-	err = s.authService.Logout(ctx, userID)
-	if err != nil {
-		// Check if it's a "session not found" error, which we'll treat as a success
-		if err.Error() == "session not found" {
-			s.logger.Warn("Session not found during logout, treating as success")
-			return &emptypb.Empty{}, nil
-		}
-
+	// Logout is public so that clients whose access token has expired can
+	// still end their session
+	if err := s.authService.LogoutByRefreshToken(ctx, req.RefreshToken); err != nil {
 		s.logger.Error("Logout failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "logout failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return &emptypb.Empty{}, nil
@@ -154,24 +167,73 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *authpb.ValidateToke
 		return nil, status.Errorf(codes.InvalidArgument, "access token is required")
 	}
 
-	// Call the auth service to validate the token
-	userID, err := s.authService.ValidateToken(ctx, req.AccessToken)
+	// Tokens of deactivated or reset-pending accounts are reported invalid too
+	userID, err := s.authService.Authenticate(ctx, req.AccessToken)
 	if err != nil {
 		s.logger.Error("Token validation failed", zap.Error(err))
-		// Check if it's an invalid token error
-		if err.Error() == "invalid token" {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token")
-		}
-		return nil, status.Errorf(codes.Internal, "token validation failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return &authpb.ValidateTokenResponse{
 		Valid:  true,
-		UserId: userID.String(),
+		UserId: s.ids.Format(userID),
 	}, nil
 }
 
-// GetUserFromToken retrieves a user from an access token
+// ValidateTokens validates a stream of access tokens, answering each in the
+// order received. Rejected tokens are answered with valid false and the error
+// code; an unexpected failure, such as an unreachable store, ends the stream.
+func (s *AuthServer) ValidateTokens(stream authpb.AuthService_ValidateTokensServer) error {
+	s.logger.Info("ValidateTokens stream opened")
+
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.validateStreamed(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// validateStreamed answers one request of a ValidateTokens stream
+func (s *AuthServer) validateStreamed(ctx context.Context, req *authpb.ValidateTokensRequest) (*authpb.ValidateTokensResponse, error) {
+	resp := &authpb.ValidateTokensResponse{RequestId: req.RequestId}
+	if req.AccessToken == "" {
+		resp.ErrorCode = string(apperror.CodeInvalidArgument)
+		return resp, nil
+	}
+
+	// Tokens of deactivated or reset-pending accounts are reported invalid too
+	userID, err := s.authService.Authenticate(ctx, req.AccessToken)
+	if err != nil {
+		st := apperror.GRPCStatus(err)
+		switch status.Code(st) {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			resp.ErrorCode = string(apperror.CodeOf(err))
+			return resp, nil
+		}
+		s.logger.Error("Token validation failed", zap.Error(err))
+		return nil, st
+	}
+
+	resp.Valid = true
+	resp.UserId = s.ids.Format(userID)
+	return resp, nil
+}
+
+// GetUserFromToken retrieves the user an access token was issued to. A token
+// whose user has since been deleted is answered with NotFound.
 func (s *AuthServer) GetUserFromToken(ctx context.Context, req *authpb.GetUserFromTokenRequest) (*userpb.User, error) {
 	s.logger.Info("GetUserFromToken request received")
 
@@ -185,20 +247,20 @@ func (s *AuthServer) GetUserFromToken(ctx context.Context, req *authpb.GetUserFr
 	userID, err := s.authService.ValidateToken(ctx, req.AccessToken)
 	if err != nil {
 		s.logger.Error("Token validation failed", zap.Error(err))
-		// Check if it's an invalid token error
-		if err.Error() == "invalid token" {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token")
-		}
-		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
-	// Get the user service from the context or dependency injection
-	// For now, we'll return a minimal user object with just the ID
-	return &userpb.User{
-		Id:        userID.String(),
-		Email:     "user@example.com", // This is a placeholder
-		FirstName: "User",             // This is a placeholder
-		LastName:  "Name",             // This is a placeholder
-		IsActive:  true,               // Assuming active user
-	}, nil
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user for access token", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
+	switch {
+	case !user.IsActive:
+		return nil, apperror.GRPCStatus(serviceAuth.ErrAccountDisabled)
+	case user.PasswordResetRequired:
+		return nil, apperror.GRPCStatus(serviceAuth.ErrPasswordResetRequired)
+	}
+
+	return mapper.UserToPb(user, s.ids), nil
 }
@@ -5,6 +5,7 @@ import (
 
 	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/idgen"
 )
 
 // Handler is a wrapper for the AuthServer to match the wire.go expectations
@@ -13,9 +14,9 @@ type Handler struct {
 }
 
 // NewHandler creates a new auth gRPC handler
-func NewHandler(authService domainAuth.AuthService, logger *zap.Logger) *Handler {
+func NewHandler(authService domainAuth.AuthService, users UserLookup, ids idgen.Strategy, logger *zap.Logger) *Handler {
 	return &Handler{
-		AuthServer: NewAuthServer(authService, logger),
+		AuthServer: NewAuthServer(authService, users, ids, logger),
 	}
 }
 
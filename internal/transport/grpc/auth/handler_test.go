@@ -3,16 +3,24 @@ package auth
 import (
 	"context"
 	"errors"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth" // Alias for domain auth types
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -46,25 +54,108 @@ func (m *MockAuthService) Logout(ctx context.Context, userID uuid.UUID) error {
 	return args.Error(0)
 }
 
+// LogoutByRefreshToken mocks the LogoutByRefreshToken method.
+func (m *MockAuthService) LogoutByRefreshToken(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
 // ValidateToken mocks the ValidateToken method
 func (m *MockAuthService) ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
 	args := m.Called(ctx, accessToken)
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
+// Authenticate mocks the Authenticate method
+func (m *MockAuthService) Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// AuthenticatePrincipal mocks the AuthenticatePrincipal method
+func (m *MockAuthService) AuthenticatePrincipal(ctx context.Context, accessToken string) (domainAuth.Principal, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(domainAuth.Principal), args.Error(1)
+}
+
+// Impersonate mocks the Impersonate method
+func (m *MockAuthService) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	args := m.Called(ctx, actorID, subjectID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.ImpersonationToken), args.Error(1)
+}
+
+// RevokeImpersonation mocks the RevokeImpersonation method
+func (m *MockAuthService) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.Impersonation), args.Error(1)
+}
+
+// LoginExternal mocks the LoginExternal method
+func (m *MockAuthService) LoginExternal(ctx context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+// CompletePasswordReset mocks the CompletePasswordReset method
+func (m *MockAuthService) CompletePasswordReset(ctx context.Context, input domainAuth.PasswordResetInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+// MockUserLookup is a mock implementation of the UserLookup interface
+type MockUserLookup struct {
+	mock.Mock
+}
+
+// GetByID mocks the GetByID method
+func (m *MockUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
 // Helper function to create mock domain TokenPair
 func createMockDomainTokenPair() *domainAuth.TokenPair {
 	return &domainAuth.TokenPair{
-		AccessToken:  "mock-access-token",
-		RefreshToken: "mock-refresh-token",
+		AccessToken:           "mock-access-token",
+		RefreshToken:          "mock-refresh-token",
+		AccessTokenExpiresAt:  mockAccessTokenExpiresAt,
+		RefreshTokenExpiresAt: mockRefreshTokenExpiresAt,
 	}
 }
 
+var (
+	mockAccessTokenExpiresAt  = time.Now().Add(15 * time.Minute)
+	mockRefreshTokenExpiresAt = time.Now().Add(7 * 24 * time.Hour)
+)
+
+// assertTokenExpiry checks the expiry reported for a mock token pair
+func assertTokenExpiry(t *testing.T, response *authpb.TokenResponse) {
+	t.Helper()
+	assert.InDelta(t, 900, response.ExpiresIn, 1)
+	assert.True(t, mockAccessTokenExpiresAt.Equal(response.ExpiresAt.AsTime()))
+	assert.True(t, mockRefreshTokenExpiresAt.Equal(response.RefreshExpiresAt.AsTime()))
+}
+
 func TestNewHandler(t *testing.T) {
 	mockService := new(MockAuthService)
 	logger := zaptest.NewLogger(t)
 
-	handler := NewHandler(mockService, logger)
+	handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, logger)
 
 	assert.NotNil(t, handler)
 	assert.Equal(t, mockService, handler.authService)
@@ -96,6 +187,22 @@ func TestLogin(t *testing.T) {
 			checkResponse: func(response *authpb.TokenResponse) {
 				assert.Equal(t, "mock-access-token", response.AccessToken)
 				assert.Equal(t, "mock-refresh-token", response.RefreshToken)
+				assertTokenExpiry(t, response)
+			},
+		},
+		{
+			name: "Remember Me",
+			request: &authpb.LoginRequest{
+				Email:      "test@example.com",
+				Password:   "password123",
+				RememberMe: true,
+			},
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "test@example.com", Password: "password123", RememberMe: true}).Return(createMockDomainTokenPair(), nil)
+			},
+			expectedCode: codes.OK,
+			checkResponse: func(response *authpb.TokenResponse) {
+				assertTokenExpiry(t, response)
 			},
 		},
 		{
@@ -127,7 +234,7 @@ func TestLogin(t *testing.T) {
 				Password: "wrongpassword",
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "test@example.com", Password: "wrongpassword"}).Return(nil, errors.New("invalid credentials"))
+				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "test@example.com", Password: "wrongpassword"}).Return(nil, serviceAuth.ErrInvalidCredentials)
 			},
 			expectedCode: codes.Unauthenticated,
 		},
@@ -148,7 +255,7 @@ func TestLogin(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockAuthService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -196,6 +303,7 @@ func TestRefreshToken(t *testing.T) {
 			checkResponse: func(response *authpb.TokenResponse) {
 				assert.Equal(t, "mock-access-token", response.AccessToken)
 				assert.Equal(t, "mock-refresh-token", response.RefreshToken)
+				assertTokenExpiry(t, response)
 			},
 		},
 		{
@@ -214,7 +322,7 @@ func TestRefreshToken(t *testing.T) {
 				RefreshToken: "invalid-token",
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("RefreshToken", mock.Anything, "invalid-token").Return(nil, errors.New("invalid token"))
+				mockService.On("RefreshToken", mock.Anything, "invalid-token").Return(nil, serviceAuth.ErrInvalidOrExpiredToken)
 			},
 			expectedCode: codes.Unauthenticated,
 		},
@@ -224,7 +332,7 @@ func TestRefreshToken(t *testing.T) {
 				RefreshToken: "expired-token",
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("RefreshToken", mock.Anything, "expired-token").Return(nil, errors.New("session not found"))
+				mockService.On("RefreshToken", mock.Anything, "expired-token").Return(nil, serviceAuth.ErrSessionNotFound)
 			},
 			expectedCode: codes.Unauthenticated,
 		},
@@ -244,7 +352,7 @@ func TestRefreshToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockAuthService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -271,10 +379,9 @@ func TestRefreshToken(t *testing.T) {
 func TestLogout(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
-	// Create a context with user ID metadata
+	// Create a context with the user ID set by the auth interceptor
 	userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
-	md := metadata.New(map[string]string{"user-id": userID.String()})
-	ctx := metadata.NewIncomingContext(context.Background(), md)
+	ctx := requestctx.WithUserID(context.Background(), userID)
 
 	tests := []struct {
 		name         string
@@ -292,25 +399,12 @@ func TestLogout(t *testing.T) {
 				return ctx
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("Logout", mock.Anything, userID).Return(nil)
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(nil)
 			},
 			expectedCode: codes.OK,
 		},
 		{
-			name: "Missing Refresh Token",
-			request: &authpb.LogoutRequest{
-				RefreshToken: "",
-			},
-			setupContext: func() context.Context {
-				return ctx
-			},
-			setupMock: func(mockService *MockAuthService) {
-				// No mock setup needed as validation should fail
-			},
-			expectedCode: codes.InvalidArgument,
-		},
-		{
-			name: "No User ID in Context",
+			name: "Without Access Token",
 			request: &authpb.LogoutRequest{
 				RefreshToken: "valid-refresh-token",
 			},
@@ -318,36 +412,22 @@ func TestLogout(t *testing.T) {
 				return context.Background() // Empty context without user ID
 			},
 			setupMock: func(mockService *MockAuthService) {
-				// No mock setup needed as context validation should fail
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(nil)
 			},
-			expectedCode: codes.Unauthenticated,
-		},
-		{
-			name: "Invalid User ID Format",
-			request: &authpb.LogoutRequest{
-				RefreshToken: "valid-refresh-token",
-			},
-			setupContext: func() context.Context {
-				invalidMD := metadata.New(map[string]string{"user-id": "invalid-id"})
-				return metadata.NewIncomingContext(context.Background(), invalidMD)
-			},
-			setupMock: func(mockService *MockAuthService) {
-				// No mock setup needed as user ID parsing should fail
-			},
-			expectedCode: codes.Unauthenticated,
+			expectedCode: codes.OK,
 		},
 		{
-			name: "Session Not Found",
+			name: "Missing Refresh Token",
 			request: &authpb.LogoutRequest{
-				RefreshToken: "valid-refresh-token",
+				RefreshToken: "",
 			},
 			setupContext: func() context.Context {
 				return ctx
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("Logout", mock.Anything, userID).Return(errors.New("session not found"))
+				// No mock setup needed as validation should fail
 			},
-			expectedCode: codes.OK, // Still returns OK even if session not found
+			expectedCode: codes.InvalidArgument,
 		},
 		{
 			name: "Internal Error",
@@ -358,7 +438,7 @@ func TestLogout(t *testing.T) {
 				return ctx
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("Logout", mock.Anything, userID).Return(errors.New("database error"))
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(errors.New("database error"))
 			},
 			expectedCode: codes.Internal,
 		},
@@ -368,7 +448,7 @@ func TestLogout(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockAuthService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, logger)
 
 			// Setup the context and mock expectations
 			testCtx := tt.setupContext()
@@ -411,7 +491,7 @@ func TestValidateToken(t *testing.T) {
 			},
 			setupMock: func(mockService *MockAuthService) {
 				userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
-				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(userID, nil)
+				mockService.On("Authenticate", mock.Anything, "valid-access-token").Return(userID, nil)
 			},
 			expectedCode: codes.OK,
 			checkResponse: func(response *authpb.ValidateTokenResponse) {
@@ -434,7 +514,7 @@ func TestValidateToken(t *testing.T) {
 				AccessToken: "invalid-token",
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("ValidateToken", mock.Anything, "invalid-token").Return(uuid.Nil, errors.New("invalid token"))
+				mockService.On("Authenticate", mock.Anything, "invalid-token").Return(uuid.Nil, serviceAuth.ErrInvalidToken)
 			},
 			expectedCode: codes.Unauthenticated,
 		},
@@ -444,7 +524,7 @@ func TestValidateToken(t *testing.T) {
 				AccessToken: "valid-access-token",
 			},
 			setupMock: func(mockService *MockAuthService) {
-				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(uuid.Nil, errors.New("database error"))
+				mockService.On("Authenticate", mock.Anything, "valid-access-token").Return(uuid.Nil, errors.New("database error"))
 			},
 			expectedCode: codes.Internal,
 		},
@@ -454,7 +534,7 @@ func TestValidateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockAuthService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -477,3 +557,184 @@ func TestValidateToken(t *testing.T) {
 		})
 	}
 }
+
+// tokenStream feeds requests to a ValidateTokens call and collects its responses
+type tokenStream struct {
+	grpc.ServerStream
+	requests  []*authpb.ValidateTokensRequest
+	responses []*authpb.ValidateTokensResponse
+}
+
+func (s *tokenStream) Context() context.Context { return context.Background() }
+
+func (s *tokenStream) Recv() (*authpb.ValidateTokensRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *tokenStream) Send(resp *authpb.ValidateTokensResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestValidateTokens(t *testing.T) {
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000123")
+
+	t.Run("Answers Each Token In Order", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		mockService.On("Authenticate", mock.Anything, "valid-token").Return(userID, nil)
+		mockService.On("Authenticate", mock.Anything, "expired-token").Return(uuid.Nil, serviceAuth.ErrTokenExpired)
+		mockService.On("Authenticate", mock.Anything, "disabled-token").Return(uuid.Nil, serviceAuth.ErrAccountDisabled)
+		handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		stream := &tokenStream{requests: []*authpb.ValidateTokensRequest{
+			{RequestId: "1", AccessToken: "valid-token"},
+			{RequestId: "2", AccessToken: "expired-token"},
+			{RequestId: "3"},
+			{RequestId: "4", AccessToken: "disabled-token"},
+		}}
+
+		err := handler.ValidateTokens(stream)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []*authpb.ValidateTokensResponse{
+			{RequestId: "1", Valid: true, UserId: userID.String()},
+			{RequestId: "2", ErrorCode: "TOKEN_EXPIRED"},
+			{RequestId: "3", ErrorCode: "INVALID_ARGUMENT"},
+			{RequestId: "4", ErrorCode: "ACCOUNT_DISABLED"},
+		}, stream.responses)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unexpected Error Ends Stream", func(t *testing.T) {
+		mockService := new(MockAuthService)
+		mockService.On("Authenticate", mock.Anything, "valid-token").Return(uuid.Nil, errors.New("redis down")).Once()
+		handler := NewHandler(mockService, new(MockUserLookup), idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		stream := &tokenStream{requests: []*authpb.ValidateTokensRequest{
+			{RequestId: "1", AccessToken: "valid-token"},
+			{RequestId: "2", AccessToken: "valid-token"},
+		}}
+
+		err := handler.ValidateTokens(stream)
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Empty(t, stream.responses)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetUserFromToken(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx := context.Background()
+	userID := uuid.MustParse("00000000-0000-0000-0000-000000000123")
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	activeUser := func() *domainUser.User {
+		return &domainUser.User{
+			ID:        userID,
+			Email:     "jane@example.com",
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Residency: "NZ",
+			IsActive:  true,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		accessToken   string
+		setupMock     func(*MockAuthService, *MockUserLookup)
+		expectedCode  codes.Code
+		checkResponse func(*userpb.User)
+	}{
+		{
+			name:        "Success",
+			accessToken: "valid-access-token",
+			setupMock: func(mockService *MockAuthService, users *MockUserLookup) {
+				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(userID, nil)
+				users.On("GetByID", mock.Anything, userID).Return(activeUser(), nil)
+			},
+			expectedCode: codes.OK,
+			checkResponse: func(response *userpb.User) {
+				assert.Equal(t, "00000000-0000-0000-0000-000000000123", response.Id)
+				assert.Equal(t, "jane@example.com", response.Email)
+				assert.Equal(t, "Jane", response.FirstName)
+				assert.Equal(t, "Doe", response.LastName)
+				assert.Equal(t, "NZ", response.Residency)
+				assert.True(t, response.IsActive)
+				assert.True(t, createdAt.Equal(response.CreatedAt.AsTime()))
+			},
+		},
+		{
+			name:         "Missing Access Token",
+			setupMock:    func(*MockAuthService, *MockUserLookup) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:        "Invalid Token",
+			accessToken: "invalid-token",
+			setupMock: func(mockService *MockAuthService, users *MockUserLookup) {
+				mockService.On("ValidateToken", mock.Anything, "invalid-token").Return(uuid.Nil, serviceAuth.ErrInvalidToken)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:        "User Deleted",
+			accessToken: "valid-access-token",
+			setupMock: func(mockService *MockAuthService, users *MockUserLookup) {
+				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(userID, nil)
+				users.On("GetByID", mock.Anything, userID).Return(nil, serviceUser.ErrUserNotFound)
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:        "Account Disabled",
+			accessToken: "valid-access-token",
+			setupMock: func(mockService *MockAuthService, users *MockUserLookup) {
+				user := activeUser()
+				user.IsActive = false
+				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(userID, nil)
+				users.On("GetByID", mock.Anything, userID).Return(user, nil)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:        "Internal Error",
+			accessToken: "valid-access-token",
+			setupMock: func(mockService *MockAuthService, users *MockUserLookup) {
+				mockService.On("ValidateToken", mock.Anything, "valid-access-token").Return(userID, nil)
+				users.On("GetByID", mock.Anything, userID).Return(nil, errors.New("database error"))
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			users := new(MockUserLookup)
+			handler := NewHandler(mockService, users, idgen.StrategyUUIDv4, logger)
+			tt.setupMock(mockService, users)
+
+			response, err := handler.GetUserFromToken(ctx, &authpb.GetUserFromTokenRequest{AccessToken: tt.accessToken})
+
+			if tt.expectedCode != codes.OK {
+				assert.Error(t, err)
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectedCode, st.Code())
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, response)
+				tt.checkResponse(response)
+			}
+
+			mockService.AssertExpectations(t)
+			users.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/openapi"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// createdMethods lists the RPCs the gateway answers with 201 Created, with
+// the message the REST API uses for the same operation
+var createdMethods = map[string]string{
+	userpb.UserService_Register_FullMethodName: "User registered successfully",
+}
+
+// newGatewayMux creates the HTTP gateway mux. Responses and errors use the
+// REST API's envelope so clients can switch between the two freely.
+// clientIPHeaders are passed on so the gRPC server resolves the client
+// address as the REST API does.
+func newGatewayMux(clientIPHeaders []string) *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher(clientIPHeaders)),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithForwardResponseOption(gatewayResponseStatus),
+		runtime.WithForwardResponseRewriter(gatewayResponseBody),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+	)
+}
+
+// registerGatewayHandlers routes the gateway's HTTP bindings to the gRPC server
+// behind conn and serves their OpenAPI spec. A later registration shadows an
+// earlier binding of the same route, so AuthService is registered after
+// UserService to answer POST /v1/auth/login instead of the deprecated
+// UserService.Login. The order must match the one the spec is generated in.
+func registerGatewayHandlers(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error {
+	if err := userpb.RegisterUserServiceHandler(ctx, mux, conn); err != nil {
+		return fmt.Errorf("failed to register user service handler: %w", err)
+	}
+	if err := authpb.RegisterAuthServiceHandler(ctx, mux, conn); err != nil {
+		return fmt.Errorf("failed to register auth service handler: %w", err)
+	}
+	spec := openapi.Handler()
+	if err := mux.HandlePath(http.MethodGet, "/openapi.json", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		spec.ServeHTTP(w, r)
+	}); err != nil {
+		return fmt.Errorf("failed to register OpenAPI spec handler: %w", err)
+	}
+	return nil
+}
+
+// captchaHeader carries the CAPTCHA token of sign-ins and registrations,
+// which the servers read from the x-captcha-token metadata
+const captchaHeader = "X-Captcha-Token"
+
+// incomingHeaderMatcher maps gateway request headers to gRPC metadata. On
+// top of the defaults the CAPTCHA token and the client IP headers are passed
+// on under their lower-case names; the gateway forwards X-Forwarded-For
+// itself, with the address of its own peer appended.
+func incomingHeaderMatcher(clientIPHeaders []string) runtime.HeaderMatcherFunc {
+	return func(key string) (string, bool) {
+		key = http.CanonicalHeaderKey(key)
+		if key == captchaHeader || slices.Contains(clientIPHeaders, key) {
+			return strings.ToLower(key), true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// gatewayResponseStatus sets the status code of successful gateway responses
+func gatewayResponseStatus(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+	method, _ := runtime.RPCMethod(ctx)
+	if _, ok := createdMethods[method]; ok {
+		w.WriteHeader(http.StatusCreated)
+	}
+	return nil
+}
+
+// gatewayResponseBody wraps a successful RPC response in the REST envelope.
+// Responses that only wrap another message, such as UserResponse, are
+// unwrapped so data holds the resource itself as it does over REST.
+func gatewayResponseBody(ctx context.Context, resp proto.Message) (any, error) {
+	data, err := protojson.Marshal(unwrapResource(resp))
+	if err != nil {
+		return nil, err
+	}
+
+	method, _ := runtime.RPCMethod(ctx)
+	if message, ok := createdMethods[method]; ok {
+		return response.NewResponse(http.StatusCreated, message, json.RawMessage(data)), nil
+	}
+	return response.NewResponse(http.StatusOK, "Success", json.RawMessage(data)), nil
+}
+
+// unwrapResource returns the only field of msg when it is a message, and msg otherwise
+func unwrapResource(msg proto.Message) proto.Message {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	if fields.Len() != 1 {
+		return msg
+	}
+	field := fields.Get(0)
+	if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() || !m.Has(field) {
+		return msg
+	}
+	return m.Get(field).Message().Interface()
+}
+
+// gatewayErrorHandler renders RPC errors in the REST error envelope. Status
+// codes follow the shared error catalog when the status carries an
+// application error code.
+func gatewayErrorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+	body := &response.Response{Code: runtime.HTTPStatusFromCode(st.Code()), Message: st.Message()}
+	if code, ok := apperror.CodeFromGRPCStatus(st); ok {
+		body.Code = apperror.HTTPStatus(code)
+		body.ErrorCode = string(code)
+	}
+
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for key, values := range md.HeaderMD {
+			if header, ok := outgoingHeaderMatcher(key); ok {
+				for _, v := range values {
+					w.Header().Add(header, v)
+				}
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(body.Code)
+	_ = json.NewEncoder(w).Encode(body)
+}
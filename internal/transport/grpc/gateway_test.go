@@ -0,0 +1,444 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/openapi"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	httpUser "github.com/yi-tech/go-user-service/internal/transport/http/user"
+)
+
+// MockUserService is a mock implementation of the serviceUser.UserService interface
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Register(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	return m.Called(ctx, users).Error(0)
+}
+
+func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	return m.Called(ctx, id, currentPassword, newPassword).Error(0)
+}
+
+func (m *MockUserService) RehashPassword(ctx context.Context, user *domainUser.User, password string) error {
+	return m.Called(ctx, user, password).Error(0)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockUserService) Update(ctx context.Context, id uuid.UUID, params domainUser.UpdateUserParams) (*domainUser.User, error) {
+	args := m.Called(ctx, id, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+// MockAuthService is a mock implementation of the domainAuth.AuthService interface
+type MockAuthService struct {
+	mock.Mock
+}
+
+func (m *MockAuthService) Login(ctx context.Context, input domainAuth.LoginInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) RefreshToken(ctx context.Context, refreshToken string) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func (m *MockAuthService) LogoutByRefreshToken(ctx context.Context, refreshToken string) error {
+	return m.Called(ctx, refreshToken).Error(0)
+}
+
+func (m *MockAuthService) ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockAuthService) Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockAuthService) AuthenticatePrincipal(ctx context.Context, accessToken string) (domainAuth.Principal, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(domainAuth.Principal), args.Error(1)
+}
+
+func (m *MockAuthService) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	args := m.Called(ctx, actorID, subjectID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.ImpersonationToken), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.Impersonation), args.Error(1)
+}
+
+func (m *MockAuthService) LoginExternal(ctx context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) CompletePasswordReset(ctx context.Context, input domainAuth.PasswordResetInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+// gatewayOnlyFields are resource fields the gateway renders but REST does not
+var gatewayOnlyFields = []string{"isActive"}
+
+// restOnlyFields are resource fields REST renders but the gateway does not
+var restOnlyFields = []string{"_links"}
+
+// stringPtr returns a pointer to s, for the optional fields of UpdateUserParams
+func stringPtr(s string) *string {
+	return &s
+}
+
+// newTestGateway serves the gRPC server over an in-memory listener and
+// returns the gateway in front of it
+func newTestGateway(t *testing.T, users serviceUser.UserService, auth domainAuth.AuthService) http.Handler {
+	s := NewServer(users, auth, nil, nil, zaptest.NewLogger(t), &Config{})
+	lis := bufconn.Listen(1 << 20)
+	go s.server.Serve(lis)
+	t.Cleanup(s.server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, registerGatewayHandlers(context.Background(), s.gatewayMux, conn))
+	return s.gatewayMux
+}
+
+// newTestREST returns the REST user routes, authenticated as callerID
+func newTestREST(t *testing.T, users serviceUser.UserService, callerID uuid.UUID) http.Handler {
+	gin.SetMode(gin.TestMode)
+	h := httpUser.NewHandler(users, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+	router := gin.New()
+	router.POST("/api/v1/users/register", h.Register)
+	router.GET("/api/v1/users", h.GetUserByEmail)
+	authed := router.Group("/api/v1", func(c *gin.Context) { requestctx.SetUserID(c, callerID) })
+	authed.GET("/profile", h.GetProfile)
+	authed.PUT("/profile", h.UpdateCurrentUserProfile)
+	return router
+}
+
+func serve(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+	// REST updates are conditional; the gateway ignores the header
+	req.Header.Set("If-Match", "*")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// assertSameShape checks that every field of the REST response, including
+// those of the resource under data, has the same value in the gateway response
+func assertSameShape(t *testing.T, rest, gateway []byte) {
+	var restBody, gatewayBody map[string]any
+	require.NoError(t, json.Unmarshal(rest, &restBody))
+	require.NoError(t, json.Unmarshal(gateway, &gatewayBody))
+
+	restData, _ := restBody["data"].(map[string]any)
+	gatewayData, _ := gatewayBody["data"].(map[string]any)
+	delete(restBody, "data")
+	delete(gatewayBody, "data")
+	assert.Equal(t, restBody, gatewayBody)
+
+	for field, value := range restData {
+		if slices.Contains(restOnlyFields, field) {
+			continue
+		}
+		assert.Equal(t, value, gatewayData[field], "data.%s", field)
+	}
+	for field := range gatewayData {
+		if _, ok := restData[field]; !ok {
+			assert.Contains(t, gatewayOnlyFields, field, "data.%s is not rendered by REST", field)
+		}
+	}
+}
+
+func TestGatewayMatchesREST(t *testing.T) {
+	callerID := uuid.New()
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := &domainUser.User{
+		ID:          callerID,
+		Email:       "jane@example.com",
+		Username:    "jane",
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		DisplayName: "Jane D.",
+		Residency:   "EU",
+		IsActive:    true,
+		CreatedAt:   stamp,
+		UpdatedAt:   stamp,
+	}
+	updated := *user
+	updated.FirstName = "Janet"
+	updated.DisplayName = "JD"
+
+	tests := []struct {
+		name        string
+		method      string
+		restPath    string
+		gatewayPath string
+		body        string
+		mockSetup   func(users *MockUserService)
+		status      int
+	}{
+		{
+			name:        "Register",
+			method:      http.MethodPost,
+			restPath:    "/api/v1/users/register",
+			gatewayPath: "/v1/auth/register",
+			body:        `{"email":"jane@example.com","password":"password123","firstName":"Jane","lastName":"Doe"}`,
+			mockSetup: func(users *MockUserService) {
+				users.On("Register", mock.Anything, mock.Anything).Return(user, nil)
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name:        "Get User By Email",
+			method:      http.MethodGet,
+			restPath:    "/api/v1/users?email=jane@example.com",
+			gatewayPath: "/v1/users?email=jane@example.com",
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByEmail", mock.Anything, "jane@example.com").Return(user, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:        "Get Profile",
+			method:      http.MethodGet,
+			restPath:    "/api/v1/profile",
+			gatewayPath: "/v1/profile",
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByID", mock.Anything, callerID).Return(user, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:        "Update Profile",
+			method:      http.MethodPut,
+			restPath:    "/api/v1/profile",
+			gatewayPath: "/v1/profile",
+			body:        `{"firstName":"Janet","displayName":"JD"}`,
+			mockSetup: func(users *MockUserService) {
+				users.On("Update", mock.Anything, callerID, domainUser.UpdateUserParams{FirstName: stringPtr("Janet"), DisplayName: stringPtr("JD")}).Return(&updated, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:        "User Not Found",
+			method:      http.MethodGet,
+			restPath:    "/api/v1/users?email=missing@example.com",
+			gatewayPath: "/v1/users?email=missing@example.com",
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByEmail", mock.Anything, "missing@example.com").Return(nil, serviceUser.ErrUserNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			tt.mockSetup(users)
+			auth := new(MockAuthService)
+			auth.On("Authenticate", mock.Anything, "token").Return(callerID, nil)
+
+			rest := serve(newTestREST(t, users, callerID), tt.method, tt.restPath, tt.body)
+			gateway := serve(newTestGateway(t, users, auth), tt.method, tt.gatewayPath, tt.body)
+
+			assert.Equal(t, tt.status, rest.Code)
+			assert.Equal(t, tt.status, gateway.Code)
+			assertSameShape(t, rest.Body.Bytes(), gateway.Body.Bytes())
+		})
+	}
+}
+
+func TestGatewayErrorEnvelope(t *testing.T) {
+	users := new(MockUserService)
+	auth := new(MockAuthService)
+	gateway := newTestGateway(t, users, auth)
+
+	t.Run("Missing Email", func(t *testing.T) {
+		auth.On("Authenticate", mock.Anything, "token").Return(uuid.New(), nil).Once()
+
+		w := serve(gateway, http.MethodGet, "/v1/users", "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"code":400,"message":"email is required"}`, w.Body.String())
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/profile", nil)
+		w := httptest.NewRecorder()
+		gateway.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, float64(http.StatusUnauthorized), body["code"])
+	})
+}
+
+// TestGatewayMatchesOpenAPISpec checks that the gateway routes every operation
+// of the committed spec to the RPC the spec names, so a service whose handlers
+// are not registered, or a binding shadowed by another, fails the build
+func TestGatewayMatchesOpenAPISpec(t *testing.T) {
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	// Every RPC fails, so the gateway only has to route the request
+	var called string
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		called, _ = grpc.MethodFromServerStream(stream)
+		return status.Error(codes.Unimplemented, "not implemented")
+	}))
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	mux := newGatewayMux(nil)
+	require.NoError(t, registerGatewayHandlers(context.Background(), mux, conn))
+
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			t.Run(strings.ToUpper(method)+" "+path, func(t *testing.T) {
+				called = ""
+				target := path
+				for _, param := range op.Parameters {
+					if param.In == "path" {
+						target = strings.ReplaceAll(target, "{"+param.Name+"}", uuid.NewString())
+					}
+				}
+
+				w := serve(mux, strings.ToUpper(method), target, "{}")
+
+				require.Equal(t, http.StatusNotImplemented, w.Code, "route is not served by the gateway")
+				service, rpc, _ := strings.Cut(strings.TrimPrefix(called, "/"), "/")
+				service = service[strings.LastIndex(service, ".")+1:]
+				assert.Equal(t, strings.TrimRight(op.OperationID, "0123456789"), service+"_"+rpc)
+
+				success := strconv.Itoa(http.StatusOK)
+				if _, ok := createdMethods[called]; ok {
+					success = strconv.Itoa(http.StatusCreated)
+				}
+				assert.Contains(t, op.Responses, success)
+			})
+		}
+	}
+
+	t.Run("Spec", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Equal(t, openapi.Spec(), w.Body.Bytes())
+	})
+}
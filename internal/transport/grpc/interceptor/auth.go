@@ -0,0 +1,115 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// authorizationKey is the metadata key carrying the bearer token.
+// grpc-gateway forwards the HTTP Authorization header under the same key.
+const authorizationKey = "authorization"
+
+// Authenticator validates access tokens and the state of their account
+type Authenticator interface {
+	Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error)
+}
+
+// AuthInterceptor authenticates RPCs with a bearer access token
+type AuthInterceptor struct {
+	authenticator Authenticator
+	public        map[string]bool
+	logger        *zap.Logger
+}
+
+// NewAuthInterceptor creates an interceptor that requires a valid access token
+// of an active account for every RPC except the given public full method names
+// (e.g. "/user.v1.UserService/Register"), so new RPCs are authenticated by default.
+func NewAuthInterceptor(authenticator Authenticator, logger *zap.Logger, publicMethods ...string) *AuthInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = true
+	}
+	return &AuthInterceptor{
+		authenticator: authenticator,
+		public:        public,
+		logger:        logger,
+	}
+}
+
+// Unary returns the unary server interceptor
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate validates the bearer token and account for non-public methods and stores the user ID in the context
+func (i *AuthInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if i.public[method] {
+		return ctx, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	parts := strings.SplitN(values[0], " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || parts[1] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata format must be Bearer {token}")
+	}
+
+	userID, err := i.authenticator.Authenticate(ctx, parts[1])
+	if err != nil {
+		i.logger.Warn("Authentication failed", zap.String("method", method), zap.Error(err))
+		if _, ok := apperror.As(err); ok {
+			return nil, apperror.GRPCStatus(err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "Invalid or expired token")
+	}
+
+	errorreport.ScopeFromContext(ctx).SetUser(userID.String())
+	return requestctx.WithUserID(ctx, userID), nil
+}
+
+// contextStream overrides the stream context, e.g. with the authenticated one
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overriding context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,173 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+)
+
+const (
+	protectedMethod = "/user.v1.UserService/GetProfile"
+	publicMethod    = "/user.v1.UserService/Register"
+)
+
+// MockAuthenticator is a mock implementation of Authenticator
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func TestAuthInterceptorUnary(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		setupMock     func(*MockAuthenticator)
+		expectedCode  codes.Code
+		expectUserID  bool
+	}{
+		{
+			name:          "Valid Token",
+			method:        protectedMethod,
+			authorization: "Bearer good-token",
+			setupMock: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "good-token").Return(userID, nil)
+			},
+			expectedCode: codes.OK,
+			expectUserID: true,
+		},
+		{
+			name:         "Missing Metadata",
+			method:       protectedMethod,
+			setupMock:    func(m *MockAuthenticator) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "Wrong Scheme",
+			method:        protectedMethod,
+			authorization: "Basic abc",
+			setupMock:     func(m *MockAuthenticator) {},
+			expectedCode:  codes.Unauthenticated,
+		},
+		{
+			name:          "Expired Token",
+			method:        protectedMethod,
+			authorization: "Bearer expired-token",
+			setupMock: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "expired-token").Return(uuid.Nil, serviceAuth.ErrTokenExpired)
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:          "Deactivated Account",
+			method:        protectedMethod,
+			authorization: "Bearer disabled-token",
+			setupMock: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "disabled-token").Return(uuid.Nil, serviceAuth.ErrAccountDisabled)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:          "Password Reset Required",
+			method:        protectedMethod,
+			authorization: "Bearer flagged-token",
+			setupMock: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "flagged-token").Return(uuid.Nil, serviceAuth.ErrPasswordResetRequired)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:          "Unexpected Validation Error",
+			method:        protectedMethod,
+			authorization: "Bearer some-token",
+			setupMock: func(m *MockAuthenticator) {
+				m.On("Authenticate", mock.Anything, "some-token").Return(uuid.Nil, errors.New("boom"))
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Public Method",
+			method:       publicMethod,
+			setupMock:    func(m *MockAuthenticator) {},
+			expectedCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := new(MockAuthenticator)
+			tt.setupMock(authenticator)
+			interceptor := NewAuthInterceptor(authenticator, zaptest.NewLogger(t), publicMethod)
+
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			var gotUserID uuid.UUID
+			var gotOK bool
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotUserID, gotOK = requestctx.UserID(ctx)
+				return "ok", nil
+			}
+
+			_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectUserID {
+				assert.True(t, gotOK)
+				assert.Equal(t, userID, gotUserID)
+			} else {
+				assert.False(t, gotOK)
+			}
+			authenticator.AssertExpectations(t)
+		})
+	}
+}
+
+// fakeServerStream is a minimal grpc.ServerStream for interceptor tests
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuthInterceptorStream(t *testing.T) {
+	userID := uuid.New()
+	authenticator := new(MockAuthenticator)
+	authenticator.On("Authenticate", mock.Anything, "good-token").Return(userID, nil)
+	interceptor := NewAuthInterceptor(authenticator, zaptest.NewLogger(t), publicMethod)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer good-token"))
+	err := interceptor.Stream()(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: protectedMethod},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			got, ok := requestctx.UserID(stream.Context())
+			assert.True(t, ok)
+			assert.Equal(t, userID, got)
+			return nil
+		})
+
+	assert.NoError(t, err)
+	authenticator.AssertExpectations(t)
+}
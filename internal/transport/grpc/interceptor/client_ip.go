@@ -0,0 +1,52 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+)
+
+// ClientIPInterceptor resolves the client address of every call behind the
+// trusted proxies of its resolver and carries it in the call context, where
+// handlers and services read it with clientip.FromContext. Forwarding
+// headers arrive as metadata under their lower-case names.
+type ClientIPInterceptor struct {
+	resolver *clientip.Resolver
+}
+
+// NewClientIPInterceptor creates an interceptor resolving client addresses with resolver
+func NewClientIPInterceptor(resolver *clientip.Resolver) *ClientIPInterceptor {
+	return &ClientIPInterceptor{resolver: resolver}
+}
+
+// Unary returns the unary server interceptor
+func (i *ClientIPInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(i.withClientIP(ctx), req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *ClientIPInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: i.withClientIP(ss.Context())})
+	}
+}
+
+// withClientIP returns ctx carrying the client address of the call
+func (i *ClientIPInterceptor) withClientIP(ctx context.Context) context.Context {
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := func(header string) []string {
+		return md.Get(strings.ToLower(header))
+	}
+	return clientip.NewContext(ctx, i.resolver.Resolve(remote, values))
+}
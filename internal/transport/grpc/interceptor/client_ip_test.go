@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+)
+
+func TestClientIPInterceptorUnary(t *testing.T) {
+	resolver, err := clientip.NewResolver([]string{"127.0.0.1"}, nil)
+	require.NoError(t, err)
+	unary := NewClientIPInterceptor(resolver).Unary()
+
+	resolve := func(remote string, md metadata.MD) string {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(remote), Port: 4711}})
+		ctx = metadata.NewIncomingContext(ctx, md)
+		var ip string
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			ip = clientip.FromContext(ctx)
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		return ip
+	}
+
+	assert.Equal(t, "198.51.100.1", resolve("198.51.100.1", nil))
+	// Calls through the gateway carry the client address in metadata
+	assert.Equal(t, "203.0.113.7", resolve("127.0.0.1", metadata.Pairs("x-forwarded-for", "203.0.113.7")))
+	assert.Equal(t, "203.0.113.9", resolve("127.0.0.1", metadata.Pairs("x-forwarded-for", "127.0.0.1", "x-real-ip", "203.0.113.9")))
+	// Other peers cannot pick the address they are identified by
+	assert.Equal(t, "198.51.100.1", resolve("198.51.100.1", metadata.Pairs("x-forwarded-for", "203.0.113.7")))
+}
+
+func TestClientIPInterceptorStream(t *testing.T) {
+	resolver, err := clientip.NewResolver(nil, nil)
+	require.NoError(t, err)
+	stream := NewClientIPInterceptor(resolver).Stream()
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 4711}})
+	var ip string
+	err = stream(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/admin.v1.AdminService/StreamUsers"}, func(srv interface{}, ss grpc.ServerStream) error {
+		ip = clientip.FromContext(ss.Context())
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.1", ip)
+}
@@ -0,0 +1,65 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yi-tech/go-user-service/internal/deprecation"
+)
+
+// WarningMetadataKey carries the human-readable deprecation warning
+const WarningMetadataKey = "warning"
+
+// DeprecationInterceptor announces deprecated RPCs through response header
+// metadata: the headers of the notice (as lower-case keys) and a warning
+type DeprecationInterceptor struct {
+	notices map[string]metadata.MD
+	logger  *zap.Logger
+}
+
+// NewDeprecationInterceptor creates an interceptor for the given notices,
+// keyed by full method name
+func NewDeprecationInterceptor(notices map[string]deprecation.Notice, logger *zap.Logger) *DeprecationInterceptor {
+	mds := make(map[string]metadata.MD, len(notices))
+	for method, notice := range notices {
+		md := metadata.Pairs(WarningMetadataKey, notice.Warning())
+		for name, value := range notice.Headers() {
+			md.Set(strings.ToLower(name), value)
+		}
+		mds[method] = md
+	}
+	return &DeprecationInterceptor{
+		notices: mds,
+		logger:  logger,
+	}
+}
+
+// Unary returns the unary server interceptor
+func (i *DeprecationInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := i.notices[info.FullMethod]; ok {
+			i.logger.Info("Deprecated RPC called", zap.String("method", info.FullMethod))
+			if err := grpc.SetHeader(ctx, md); err != nil {
+				i.logger.Warn("Failed to set deprecation headers", zap.String("method", info.FullMethod), zap.Error(err))
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *DeprecationInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if md, ok := i.notices[info.FullMethod]; ok {
+			i.logger.Info("Deprecated RPC called", zap.String("method", info.FullMethod))
+			if err := ss.SetHeader(md); err != nil {
+				i.logger.Warn("Failed to set deprecation headers", zap.String("method", info.FullMethod), zap.Error(err))
+			}
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,75 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yi-tech/go-user-service/internal/deprecation"
+)
+
+// headerRecorder is a grpc.ServerTransportStream that records header metadata
+type headerRecorder struct {
+	header metadata.MD
+}
+
+func (r *headerRecorder) Method() string { return "" }
+
+func (r *headerRecorder) SetHeader(md metadata.MD) error {
+	r.header = metadata.Join(r.header, md)
+	return nil
+}
+
+func (r *headerRecorder) SendHeader(md metadata.MD) error { return r.SetHeader(md) }
+
+func (r *headerRecorder) SetTrailer(md metadata.MD) error { return nil }
+
+func TestDeprecationInterceptorUnary(t *testing.T) {
+	const deprecatedMethod = "/user.v1.UserService/Login"
+	interceptor := NewDeprecationInterceptor(map[string]deprecation.Notice{
+		deprecatedMethod: {
+			Since:       time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+			Replacement: "auth.v1.AuthService/Login",
+		},
+	}, zaptest.NewLogger(t))
+
+	tests := []struct {
+		name           string
+		method         string
+		expectedHeader metadata.MD
+	}{
+		{
+			name:   "Deprecated Method",
+			method: deprecatedMethod,
+			expectedHeader: metadata.MD{
+				"deprecation": []string{"@1792108800"},
+				"warning":     []string{"This endpoint is deprecated; use auth.v1.AuthService/Login instead."},
+			},
+		},
+		{
+			name:   "Current Method",
+			method: "/user.v1.UserService/GetProfile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &headerRecorder{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			}
+
+			resp, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", resp)
+			assert.Equal(t, tt.expectedHeader, stream.header)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+)
+
+// ErrorReportInterceptor reports RPCs that failed with an INTERNAL or
+// UNKNOWN status to the error tracker, with the method and signed-in user. Errors converted with apperror.GRPCStatus keep their
+// cause for the report. Panics are reported by RecoveryInterceptor instead.
+type ErrorReportInterceptor struct {
+	reporter errorreport.Reporter
+}
+
+// NewErrorReportInterceptor creates an interceptor reporting to reporter
+func NewErrorReportInterceptor(reporter errorreport.Reporter) *ErrorReportInterceptor {
+	return &ErrorReportInterceptor{reporter: reporter}
+}
+
+// Unary returns the unary server interceptor
+func (i *ErrorReportInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		i.report(ctx, info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *ErrorReportInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		i.report(ss.Context(), info.FullMethod, err)
+		return err
+	}
+}
+
+// report sends err to the error tracker if it is unexpected
+func (i *ErrorReportInterceptor) report(ctx context.Context, method string, err error) {
+	switch status.Code(err) {
+	case codes.Internal, codes.Unknown:
+	default:
+		return
+	}
+	if cause := errors.Unwrap(err); cause != nil {
+		err = cause
+	}
+	i.reporter.Report(errorreport.Event{
+		Level:     errorreport.LevelError,
+		Err:       err,
+		Transport: errorreport.TransportGRPC,
+		Route:     method,
+		UserID:    errorreport.ScopeFromContext(ctx).User(),
+	})
+}
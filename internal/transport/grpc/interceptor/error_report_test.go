@@ -0,0 +1,95 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+)
+
+// reportFunc adapts a function to an errorreport.Reporter
+type reportFunc func(event errorreport.Event)
+
+func (f reportFunc) Report(event errorreport.Event) { f(event) }
+
+func TestErrorReportInterceptorUnary(t *testing.T) {
+	const method = "/user.v1.UserService/GetProfile"
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		err           error
+		expectedCause error
+	}{
+		{name: "Internal Error", err: apperror.GRPCStatus(cause), expectedCause: cause},
+		{name: "Plain Internal Status", err: status.Error(codes.Internal, "boom")},
+		{name: "Application Error", err: apperror.GRPCStatus(apperror.New(apperror.CodeUserNotFound, "user not found"))},
+		{name: "Success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported []errorreport.Event
+			interceptor := NewErrorReportInterceptor(reportFunc(func(event errorreport.Event) { reported = append(reported, event) }))
+			ctx, scope := errorreport.NewScope(context.Background())
+			scope.SetUser("user-1")
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			}
+
+			_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+			assert.Equal(t, tt.err, err)
+			switch {
+			case tt.expectedCause != nil:
+				require.Len(t, reported, 1)
+				assert.Equal(t, errorreport.Event{
+					Level:     errorreport.LevelError,
+					Err:       cause,
+					Transport: errorreport.TransportGRPC,
+					Route:     method,
+					UserID:    "user-1",
+				}, reported[0])
+			case status.Code(tt.err) == codes.Internal:
+				assert.Len(t, reported, 1)
+			default:
+				assert.Empty(t, reported)
+			}
+		})
+	}
+}
+
+func TestRecoveryInterceptor_ReportsSignedInUser(t *testing.T) {
+	var reported []errorreport.Event
+	recorder, err := recovery.NewRecorder(zaptest.NewLogger(t), prometheus.NewRegistry(), reportFunc(func(event errorreport.Event) { reported = append(reported, event) }))
+	require.NoError(t, err)
+
+	// The auth interceptor runs inside recovery and records the user in its scope
+	authenticate := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		errorreport.ScopeFromContext(ctx).SetUser("user-1")
+		return handler(ctx, req)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}
+	_, err = NewRecoveryInterceptor(recorder).Unary()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authenticate(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, reported, 1)
+	assert.Equal(t, "user-1", reported[0].UserID)
+	assert.Equal(t, errorreport.LevelFatal, reported[0].Level)
+}
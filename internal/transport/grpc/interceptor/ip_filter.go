@@ -0,0 +1,74 @@
+package interceptor
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+)
+
+// IPFilterInterceptor rejects RPCs from client addresses the filters do not
+// serve with PERMISSION_DENIED. Every RPC must pass the global filter and
+// the filter of its service, if there is one. The client address is the
+// one ClientIPInterceptor resolved, so it must run after that.
+type IPFilterInterceptor struct {
+	global   *ipfilter.Filter
+	services map[string]*ipfilter.Filter
+	logger   *zap.Logger
+}
+
+// NewIPFilterInterceptor creates an interceptor applying global to every RPC
+// and services to the RPCs of the named services, e.g. "user.v1.UserService"
+func NewIPFilterInterceptor(global *ipfilter.Filter, services map[string]*ipfilter.Filter, logger *zap.Logger) *IPFilterInterceptor {
+	return &IPFilterInterceptor{
+		global:   global,
+		services: services,
+		logger:   logger,
+	}
+}
+
+// Unary returns the unary server interceptor
+func (i *IPFilterInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *IPFilterInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check returns the status to reject the call with, or nil when it is served
+func (i *IPFilterInterceptor) check(ctx context.Context, method string) error {
+	ip := clientip.FromContext(ctx)
+	addr, _ := netip.ParseAddr(ip)
+	if i.global.Allows(addr) && i.services[serviceName(method)].Allows(addr) {
+		return nil
+	}
+	i.logger.Warn("Rejected RPC from filtered address",
+		zap.String("client_ip", ip),
+		zap.String("method", method))
+	return apperror.GRPCStatus(ipfilter.ErrForbidden)
+}
+
+// serviceName returns the service of a full method name, e.g.
+// "user.v1.UserService" for "/user.v1.UserService/GetProfile"
+func serviceName(method string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return service
+}
@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+)
+
+func TestIPFilterInterceptorUnary(t *testing.T) {
+	const (
+		profileMethod   = "/user.v1.UserService/GetProfile"
+		listUsersMethod = "/admin.v1.AdminService/ListUsers"
+	)
+	global, err := ipfilter.New(nil, []string{"192.0.2.0/24"})
+	require.NoError(t, err)
+	admin, err := ipfilter.New([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+	unary := NewIPFilterInterceptor(global, map[string]*ipfilter.Filter{"admin.v1.AdminService": admin}, zaptest.NewLogger(t)).Unary()
+
+	call := func(method, ip string) error {
+		ctx := clientip.NewContext(context.Background(), ip)
+		_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		return err
+	}
+
+	assert.NoError(t, call(profileMethod, "198.51.100.1"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(profileMethod, "192.0.2.1")), "denied globally")
+	assert.NoError(t, call(listUsersMethod, "10.0.0.2"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(listUsersMethod, "198.51.100.1")), "not allowed for the service")
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(listUsersMethod, "")), "unresolved addresses are not allowed")
+}
+
+func TestIPFilterInterceptorStream(t *testing.T) {
+	global, err := ipfilter.New([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+	stream := NewIPFilterInterceptor(global, nil, zaptest.NewLogger(t)).Stream()
+
+	ctx := clientip.NewContext(context.Background(), "198.51.100.1")
+	called := false
+	err = stream(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/admin.v1.AdminService/StreamUsers"}, func(srv interface{}, ss grpc.ServerStream) error {
+		called = true
+		return nil
+	})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, called)
+}
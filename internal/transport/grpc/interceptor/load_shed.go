@@ -0,0 +1,66 @@
+package interceptor
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/loadshed"
+)
+
+// RetryAfterMetadataKey carries how many seconds a shed client should wait;
+// the gateway forwards it as the Grpc-Metadata-Retry-After header
+const RetryAfterMetadataKey = "retry-after"
+
+// LoadShedInterceptor caps the unary RPCs served at once and sheds the rest
+// with UNAVAILABLE. Auth RPCs have a budget of their own, so a burst of
+// sign-ins cannot starve profile reads and the other way around. Streams
+// are not limited since they hold a slot for as long as the client listens.
+type LoadShedInterceptor struct {
+	limiter     *loadshed.Limiter
+	authLimiter *loadshed.Limiter
+	auth        map[string]bool
+	logger      *zap.Logger
+}
+
+// NewLoadShedInterceptor creates an interceptor admitting maxInFlight RPCs
+// at a time, and authMaxInFlight of the given auth methods on top of them.
+// A limit of 0 or less admits every RPC.
+func NewLoadShedInterceptor(maxInFlight, authMaxInFlight int, logger *zap.Logger, authMethods ...string) *LoadShedInterceptor {
+	auth := make(map[string]bool, len(authMethods))
+	for _, method := range authMethods {
+		auth[method] = true
+	}
+	return &LoadShedInterceptor{
+		limiter:     loadshed.NewLimiter(maxInFlight),
+		authLimiter: loadshed.NewLimiter(authMaxInFlight),
+		auth:        auth,
+		logger:      logger,
+	}
+}
+
+// Unary returns the unary server interceptor
+func (i *LoadShedInterceptor) Unary() grpc.UnaryServerInterceptor {
+	retryAfter := metadata.Pairs(RetryAfterMetadataKey, strconv.Itoa(int(loadshed.RetryAfter.Seconds())))
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limiter := i.limiter
+		if i.auth[info.FullMethod] {
+			limiter = i.authLimiter
+		}
+		if !limiter.Acquire() {
+			i.logger.Warn("Shed RPC at concurrency limit",
+				zap.String("method", info.FullMethod),
+				zap.Int("max_in_flight", limiter.Max()))
+			if err := grpc.SetHeader(ctx, retryAfter); err != nil {
+				i.logger.Debug("Failed to set retry-after header", zap.String("method", info.FullMethod), zap.Error(err))
+			}
+			return nil, apperror.GRPCStatus(loadshed.ErrOverloaded)
+		}
+		defer limiter.Release()
+		return handler(ctx, req)
+	}
+}
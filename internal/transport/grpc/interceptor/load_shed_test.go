@@ -0,0 +1,52 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadShedInterceptorUnary(t *testing.T) {
+	const (
+		loginMethod   = "/auth.v1.AuthService/Login"
+		profileMethod = "/user.v1.UserService/GetProfile"
+	)
+	interceptor := NewLoadShedInterceptor(1, 1, zaptest.NewLogger(t), loginMethod)
+	unary := interceptor.Unary()
+
+	call := func(method string, handler grpc.UnaryHandler) error {
+		_, err := unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	// While a profile read and a sign-in are in flight, both budgets are used up
+	err := call(profileMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+		require.NoError(t, call(loginMethod, func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, codes.Unavailable, status.Code(call(profileMethod, ok)))
+			assert.Equal(t, codes.Unavailable, status.Code(call(loginMethod, ok)))
+			return "ok", nil
+		}))
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	// Slots are released once the RPCs return
+	assert.NoError(t, call(profileMethod, ok))
+	assert.NoError(t, call(loginMethod, ok))
+}
+
+func TestLoadShedInterceptorUnary_Unlimited(t *testing.T) {
+	interceptor := NewLoadShedInterceptor(0, 0, zaptest.NewLogger(t))
+
+	_, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+
+	assert.NoError(t, err)
+}
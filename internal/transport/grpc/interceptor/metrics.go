@@ -0,0 +1,171 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// RPC types reported in the grpc_type label
+const (
+	unaryRPC        = "unary"
+	clientStreamRPC = "client_stream"
+	serverStreamRPC = "server_stream"
+	bidiStreamRPC   = "bidi_stream"
+)
+
+// messageSizeBuckets spans 64B to 4MB, the default gRPC message size limit
+var messageSizeBuckets = prometheus.ExponentialBuckets(64, 4, 9)
+
+// MetricsInterceptor records per-method call counts, result codes and message
+// sizes using the grpc_type, grpc_service, grpc_method and grpc_code labels
+// conventional for gRPC servers, so existing dashboards apply
+type MetricsInterceptor struct {
+	started      *prometheus.CounterVec
+	handled      *prometheus.CounterVec
+	received     *prometheus.CounterVec
+	sent         *prometheus.CounterVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewMetricsInterceptor creates a MetricsInterceptor and registers its metrics
+func NewMetricsInterceptor(registerer prometheus.Registerer) (*MetricsInterceptor, error) {
+	labels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	i := &MetricsInterceptor{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "Total number of RPCs started on the server.",
+		}, labels),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, regardless of success or failure.",
+		}, append(labels, "grpc_code")),
+		received: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total number of RPC stream messages received on the server.",
+		}, labels),
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total number of gRPC stream messages sent by the server.",
+		}, labels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_request_size_bytes",
+			Help:    "Size in bytes of messages received by the server.",
+			Buckets: messageSizeBuckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_response_size_bytes",
+			Help:    "Size in bytes of messages sent by the server.",
+			Buckets: messageSizeBuckets,
+		}, labels),
+	}
+
+	for _, c := range []prometheus.Collector{i.started, i.handled, i.received, i.sent, i.requestSize, i.responseSize} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+// Unary returns the unary server interceptor
+func (i *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		rpc := newRPCLabels(unaryRPC, info.FullMethod)
+		i.started.WithLabelValues(rpc...).Inc()
+		i.recordReceived(rpc, req)
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			i.recordSent(rpc, resp)
+		}
+		i.handled.WithLabelValues(withCode(rpc, err)...).Inc()
+		return resp, err
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		rpc := newRPCLabels(streamType(info), info.FullMethod)
+		i.started.WithLabelValues(rpc...).Inc()
+
+		err := handler(srv, &measuredStream{ServerStream: ss, interceptor: i, rpc: rpc})
+		i.handled.WithLabelValues(withCode(rpc, err)...).Inc()
+		return err
+	}
+}
+
+// recordReceived counts a received message and its wire size
+func (i *MetricsInterceptor) recordReceived(rpc []string, msg interface{}) {
+	i.received.WithLabelValues(rpc...).Inc()
+	if m, ok := msg.(proto.Message); ok {
+		i.requestSize.WithLabelValues(rpc...).Observe(float64(proto.Size(m)))
+	}
+}
+
+// recordSent counts a sent message and its wire size
+func (i *MetricsInterceptor) recordSent(rpc []string, msg interface{}) {
+	i.sent.WithLabelValues(rpc...).Inc()
+	if m, ok := msg.(proto.Message); ok {
+		i.responseSize.WithLabelValues(rpc...).Observe(float64(proto.Size(m)))
+	}
+}
+
+// newRPCLabels returns the grpc_type, grpc_service and grpc_method label values
+// for a full method name such as "/user.v1.UserService/GetProfile"
+func newRPCLabels(rpcType, fullMethod string) []string {
+	service, method := "unknown", "unknown"
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		service, method = name[:i], name[i+1:]
+	}
+	return []string{rpcType, service, method}
+}
+
+// withCode returns the label values for the handled counter
+func withCode(rpc []string, err error) []string {
+	return []string{rpc[0], rpc[1], rpc[2], status.Code(err).String()}
+}
+
+// streamType classifies a streaming RPC for the grpc_type label
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return bidiStreamRPC
+	case info.IsClientStream:
+		return clientStreamRPC
+	default:
+		return serverStreamRPC
+	}
+}
+
+// measuredStream records every message on a stream
+type measuredStream struct {
+	grpc.ServerStream
+	interceptor *MetricsInterceptor
+	rpc         []string
+}
+
+// RecvMsg records received messages
+func (s *measuredStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.interceptor.recordReceived(s.rpc, m)
+	}
+	return err
+}
+
+// SendMsg records sent messages
+func (s *measuredStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.interceptor.recordSent(s.rpc, m)
+	}
+	return err
+}
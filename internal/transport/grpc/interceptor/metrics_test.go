@@ -0,0 +1,72 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMetricsInterceptorUnary(t *testing.T) {
+	mi, err := NewMetricsInterceptor(prometheus.NewRegistry())
+	require.NoError(t, err)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("response"), nil
+	}
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	_, err = mi.Unary()(context.Background(), wrapperspb.String("request"), info, ok)
+	assert.NoError(t, err)
+	_, err = mi.Unary()(context.Background(), wrapperspb.String("request"), info, notFound)
+	assert.Error(t, err)
+
+	labels := []string{"unary", "user.v1.UserService", "GetProfile"}
+	assert.Equal(t, float64(2), testutil.ToFloat64(mi.started.WithLabelValues(labels...)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mi.handled.WithLabelValues(append(labels, "OK")...)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mi.handled.WithLabelValues(append(labels, "NotFound")...)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(mi.received.WithLabelValues(labels...)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mi.sent.WithLabelValues(labels...)))
+	assert.Equal(t, 1, testutil.CollectAndCount(mi.requestSize))
+	assert.Equal(t, 1, testutil.CollectAndCount(mi.responseSize))
+}
+
+func TestMetricsInterceptorStream(t *testing.T) {
+	mi, err := NewMetricsInterceptor(prometheus.NewRegistry())
+	require.NoError(t, err)
+	info := &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/Watch", IsServerStream: true}
+
+	err = mi.Stream()(nil, &fakeServerStream{ctx: context.Background()}, info,
+		func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(codes.PermissionDenied, "denied")
+		})
+
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(mi.handled.WithLabelValues("server_stream", "user.v1.UserService", "Watch", "PermissionDenied")))
+}
+
+func TestNewMetricsInterceptorRejectsDuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := NewMetricsInterceptor(registry)
+	require.NoError(t, err)
+
+	_, err = NewMetricsInterceptor(registry)
+	assert.Error(t, err)
+}
+
+func TestNewRPCLabels(t *testing.T) {
+	assert.Equal(t, []string{"unary", "auth.v1.AuthService", "Login"}, newRPCLabels(unaryRPC, "/auth.v1.AuthService/Login"))
+	assert.Equal(t, []string{"unary", "unknown", "unknown"}, newRPCLabels(unaryRPC, "malformed"))
+	assert.Equal(t, bidiStreamRPC, streamType(&grpc.StreamServerInfo{IsClientStream: true, IsServerStream: true}))
+	assert.Equal(t, clientStreamRPC, streamType(&grpc.StreamServerInfo{IsClientStream: true}))
+}
@@ -0,0 +1,63 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+)
+
+// ReadOnlyInterceptor rejects RPCs that write to the database while
+// read-only mode is on
+type ReadOnlyInterceptor struct {
+	sw      *readonly.Switch
+	allowed map[string]bool
+	logger  *zap.Logger
+}
+
+// NewReadOnlyInterceptor creates an interceptor that, while sw is on, only
+// admits the given full method names. RPCs added later are rejected in
+// read-only mode until they are listed, so new writes cannot slip through.
+func NewReadOnlyInterceptor(sw *readonly.Switch, logger *zap.Logger, allowedMethods ...string) *ReadOnlyInterceptor {
+	allowed := make(map[string]bool, len(allowedMethods))
+	for _, method := range allowedMethods {
+		allowed[method] = true
+	}
+	return &ReadOnlyInterceptor{
+		sw:      sw,
+		allowed: allowed,
+		logger:  logger,
+	}
+}
+
+// Unary returns the unary server interceptor
+func (i *ReadOnlyInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.check(info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *ReadOnlyInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.check(info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// check returns a READ_ONLY status for methods that are not allowed in read-only mode
+func (i *ReadOnlyInterceptor) check(method string) error {
+	if !i.sw.Enabled() || i.allowed[method] {
+		return nil
+	}
+	i.logger.Info("Rejected RPC in read-only mode", zap.String("method", method))
+	return apperror.GRPCStatus(readonly.ErrReadOnly)
+}
@@ -0,0 +1,49 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/readonly"
+)
+
+func TestReadOnlyInterceptorUnary(t *testing.T) {
+	const (
+		readMethod  = "/user.v1.UserService/GetProfile"
+		writeMethod = "/user.v1.UserService/UpdateProfile"
+	)
+
+	tests := []struct {
+		name         string
+		readOnly     bool
+		method       string
+		expectedCode codes.Code
+	}{
+		{name: "Write Allowed When Off", method: writeMethod, expectedCode: codes.OK},
+		{name: "Allowed Method", readOnly: true, method: readMethod, expectedCode: codes.OK},
+		{name: "Write Rejected", readOnly: true, method: writeMethod, expectedCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewReadOnlyInterceptor(readonly.NewSwitch(tt.readOnly), zaptest.NewLogger(t), readMethod)
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return "ok", nil
+			}
+
+			_, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedCode == codes.OK, called)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+)
+
+// RecoveryInterceptor recovers from panics in RPC handlers, records them and
+// fails the RPC with an INTERNAL status instead of crashing the server. It
+// adds an error report scope to the context, which the auth interceptor
+// fills in with the signed-in user.
+type RecoveryInterceptor struct {
+	recorder *recovery.Recorder
+}
+
+// NewRecoveryInterceptor creates a recovery interceptor recording panics with recorder
+func NewRecoveryInterceptor(recorder *recovery.Recorder) *RecoveryInterceptor {
+	return &RecoveryInterceptor{recorder: recorder}
+}
+
+// Unary returns the unary server interceptor
+func (i *RecoveryInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, _ = errorreport.NewScope(ctx)
+		defer i.recover(ctx, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the stream server interceptor
+func (i *RecoveryInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, _ := errorreport.NewScope(ss.Context())
+		defer i.recover(ctx, info.FullMethod, &err)
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// recover records a panic of the RPC method and replaces its error. It must
+// be deferred directly so that the built-in recover stops the panic.
+func (i *RecoveryInterceptor) recover(ctx context.Context, method string, err *error) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	i.recorder.Record(ctx, recovery.Panic{
+		Value:     value,
+		Stack:     debug.Stack(),
+		Transport: errorreport.TransportGRPC,
+		Route:     method,
+	})
+	*err = apperror.GRPCStatus(recovery.ErrPanic)
+}
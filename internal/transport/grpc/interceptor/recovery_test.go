@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yi-tech/go-user-service/internal/recovery"
+)
+
+func TestRecoveryInterceptor(t *testing.T) {
+	recorder, err := recovery.NewRecorder(zaptest.NewLogger(t), prometheus.NewRegistry(), nil)
+	require.NoError(t, err)
+	interceptor := NewRecoveryInterceptor(recorder)
+
+	t.Run("Unary Panic", func(t *testing.T) {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		}
+
+		resp, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}, handler)
+
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.NotContains(t, err.Error(), "boom")
+	})
+
+	t.Run("Unary Success", func(t *testing.T) {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		}
+
+		resp, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetProfile"}, handler)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	t.Run("Stream Panic", func(t *testing.T) {
+		handler := func(srv interface{}, ss grpc.ServerStream) error {
+			panic("boom")
+		}
+
+		err := interceptor.Stream()(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/user.v1.UserService/Watch", IsServerStream: true}, handler)
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
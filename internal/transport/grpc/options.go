@@ -0,0 +1,196 @@
+package grpc
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+	grpcAdmin "github.com/yi-tech/go-user-service/internal/transport/grpc/admin"
+	"github.com/yi-tech/go-user-service/internal/transport/grpc/interceptor"
+)
+
+// KeepaliveConfig holds server-side keepalive parameters. Zero values keep
+// the gRPC defaults.
+type KeepaliveConfig struct {
+	Time                  time.Duration // ping clients after this much inactivity
+	Timeout               time.Duration // close the connection if a ping is not acked in time
+	MinTime               time.Duration // minimum interval allowed between client pings
+	PermitWithoutStream   bool          // allow client pings without active streams
+	MaxConnectionIdle     time.Duration
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+}
+
+// Option customizes a Server
+type Option func(*Server)
+
+// WithIDFormat renders IDs in responses in the text form of an ID strategy.
+// IDs are rendered as UUIDs by default.
+func WithIDFormat(strategy idgen.Strategy) Option {
+	return func(s *Server) {
+		s.ids = strategy
+	}
+}
+
+// WithReadOnly rejects RPCs that write to the database while sw is on.
+// The check runs after authentication.
+func WithReadOnly(sw *readonly.Switch) Option {
+	return func(s *Server) {
+		s.readOnly = interceptor.NewReadOnlyInterceptor(sw, s.logger, s.cfg.readOnlyMethods()...)
+	}
+}
+
+// WithClientIPResolver resolves the client addresses of calls with resolver,
+// which must trust GatewayProxies for gateway calls to be attributed to
+// their client. By default only the gateway is trusted.
+func WithClientIPResolver(resolver *clientip.Resolver) Option {
+	return func(s *Server) {
+		s.resolver = resolver
+	}
+}
+
+// WithIPFilter rejects calls from client addresses global, or the filter
+// of the route group of their service, does not serve. The check runs
+// before load shedding and authentication.
+func WithIPFilter(global *ipfilter.Filter, groups map[string]*ipfilter.Filter) Option {
+	return func(s *Server) {
+		services := make(map[string]*ipfilter.Filter, len(serviceGroups))
+		for service, group := range serviceGroups {
+			services[service] = groups[group]
+		}
+		s.ipFilter = interceptor.NewIPFilterInterceptor(global, services, s.logger)
+	}
+}
+
+// WithRecovery recovers from panics in RPC handlers, recording them with
+// recorder. It wraps all other interceptors, so panics in those are recovered
+// too.
+func WithRecovery(recorder *recovery.Recorder) Option {
+	return func(s *Server) {
+		s.recovery = interceptor.NewRecoveryInterceptor(recorder)
+	}
+}
+
+// WithErrorReporting reports RPCs failing with an internal error to
+// reporter. Like recovery it wraps all other interceptors; the signed-in
+// user is only attached when WithRecovery is used as well.
+func WithErrorReporting(reporter errorreport.Reporter) Option {
+	return func(s *Server) {
+		s.errorReport = interceptor.NewErrorReportInterceptor(reporter)
+	}
+}
+
+// WithSettings lets AdminService.GetServerInfo report the active
+// configuration, with its secrets redacted
+func WithSettings(settings grpcAdmin.Settings) Option {
+	return func(s *Server) {
+		s.settings = settings
+	}
+}
+
+// WithUnaryInterceptors appends unary interceptors. They wrap authentication,
+// so they also observe calls rejected as unauthenticated.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(s *Server) {
+		s.unaryInterceptors = append(s.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors appends stream interceptors. They wrap authentication,
+// so they also observe calls rejected as unauthenticated.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(s *Server) {
+		s.streamInterceptors = append(s.streamInterceptors, interceptors...)
+	}
+}
+
+// WithServerOptions appends raw grpc.ServerOptions, applied after the configured ones
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, opts...)
+	}
+}
+
+// WithGatewayMiddleware wraps the HTTP handler of the gateway, e.g. to
+// compress its responses. The first middleware is the outermost.
+func WithGatewayMiddleware(middleware ...func(http.Handler) http.Handler) Option {
+	return func(s *Server) {
+		s.gatewayMiddleware = append(s.gatewayMiddleware, middleware...)
+	}
+}
+
+// buildServerOptions translates the configuration and injected options into grpc.ServerOptions
+func (s *Server) buildServerOptions() []grpc.ServerOption {
+	// Deprecation headers go out even on calls rejected as unauthenticated.
+	// Load is shed before authentication, which costs a token validation.
+	// The client address is resolved first, so every other interceptor and
+	// the handlers see it.
+	unary := make([]grpc.UnaryServerInterceptor, 0, len(s.unaryInterceptors)+8)
+	stream := make([]grpc.StreamServerInterceptor, 0, len(s.streamInterceptors)+7)
+	if s.clientIP != nil {
+		unary = append(unary, s.clientIP.Unary())
+		stream = append(stream, s.clientIP.Stream())
+	}
+	if s.recovery != nil {
+		unary = append(unary, s.recovery.Unary())
+		stream = append(stream, s.recovery.Stream())
+	}
+	if s.errorReport != nil {
+		unary = append(unary, s.errorReport.Unary())
+		stream = append(stream, s.errorReport.Stream())
+	}
+	unary = append(unary, s.unaryInterceptors...)
+	stream = append(stream, s.streamInterceptors...)
+	if s.ipFilter != nil {
+		unary = append(unary, s.ipFilter.Unary())
+		stream = append(stream, s.ipFilter.Stream())
+	}
+	unary = append(unary, s.loadShed.Unary(), s.deprecation.Unary(), s.authInterceptor.Unary())
+	stream = append(stream, s.deprecation.Stream(), s.authInterceptor.Stream())
+	if s.readOnly != nil {
+		unary = append(unary, s.readOnly.Unary())
+		stream = append(stream, s.readOnly.Stream())
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+
+	if s.cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(s.cfg.MaxRecvMsgSize))
+	}
+	if s.cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(s.cfg.MaxSendMsgSize))
+	}
+	if s.cfg.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(s.cfg.ConnectionTimeout))
+	}
+
+	ka := s.cfg.Keepalive
+	if ka.Time > 0 || ka.Timeout > 0 || ka.MaxConnectionIdle > 0 || ka.MaxConnectionAge > 0 || ka.MaxConnectionAgeGrace > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  ka.Time,
+			Timeout:               ka.Timeout,
+			MaxConnectionIdle:     ka.MaxConnectionIdle,
+			MaxConnectionAge:      ka.MaxConnectionAge,
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+		}))
+	}
+	if ka.MinTime > 0 || ka.PermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}))
+	}
+
+	return append(opts, s.serverOptions...)
+}
@@ -0,0 +1,155 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/transport/grpc/interceptor"
+)
+
+func newTestServer(cfg *Config, opts ...Option) *Server {
+	s := &Server{
+		authInterceptor: interceptor.NewAuthInterceptor(nil, zap.NewNop()),
+		logger:          zap.NewNop(),
+		cfg:             cfg,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func TestBuildServerOptions(t *testing.T) {
+	noopUnary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *Config
+		opts     []Option
+		expected int
+	}{
+		{
+			name:     "Defaults Only Install Interceptors",
+			cfg:      &Config{},
+			expected: 2,
+		},
+		{
+			name: "Configured Limits And Keepalive",
+			cfg: &Config{
+				MaxRecvMsgSize:    1 << 20,
+				MaxSendMsgSize:    1 << 20,
+				ConnectionTimeout: 30 * time.Second,
+				Keepalive: KeepaliveConfig{
+					Time:    time.Hour,
+					MinTime: time.Minute,
+				},
+			},
+			expected: 7,
+		},
+		{
+			name:     "Injected Server Options",
+			cfg:      &Config{},
+			opts:     []Option{WithServerOptions(grpc.MaxConcurrentStreams(100)), WithUnaryInterceptors(noopUnary)},
+			expected: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestServer(tc.cfg, tc.opts...)
+			assert.Len(t, s.buildServerOptions(), tc.expected)
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	noopStream := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+	noopUnary := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+
+	s := newTestServer(&Config{},
+		WithUnaryInterceptors(noopUnary, noopUnary),
+		WithStreamInterceptors(noopStream),
+	)
+
+	assert.Len(t, s.unaryInterceptors, 2)
+	assert.Len(t, s.streamInterceptors, 1)
+	assert.Empty(t, s.serverOptions)
+}
+
+func TestWithGatewayMiddleware(t *testing.T) {
+	// Each middleware records its name before calling the handler it wraps
+	var calls []string
+	record := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	s := NewServer(nil, nil, nil, nil, zap.NewNop(), &Config{}, WithGatewayMiddleware(record("outer"), record("inner")))
+	s.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/profile", nil))
+
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+}
+
+func TestWithIPFilter(t *testing.T) {
+	admin, err := ipfilter.New([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	s := newTestServer(&Config{}, WithIPFilter(nil, map[string]*ipfilter.Filter{"admin": admin}))
+	require.NotNil(t, s.ipFilter)
+
+	// The admin group's rules apply to AdminService only
+	ctx := clientip.NewContext(context.Background(), "198.51.100.1")
+	call := func(method string) error {
+		_, err := s.ipFilter.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		return err
+	}
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(adminpb.AdminService_ListUsers_FullMethodName)))
+	assert.NoError(t, call(userpb.UserService_GetProfile_FullMethodName))
+}
+
+func TestNewServer_ResolvesClientIPBehindGateway(t *testing.T) {
+	resolver, err := clientip.NewResolver(append([]string{"10.0.0.0/8"}, GatewayProxies...), nil)
+	require.NoError(t, err)
+	s := NewServer(nil, nil, nil, nil, zap.NewNop(), &Config{}, WithClientIPResolver(resolver))
+
+	// A call the gateway forwards for a client behind a load balancer
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4711}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "203.0.113.7, 10.0.0.2"))
+	var resolved string
+	_, err = s.clientIP.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: userpb.UserService_GetProfile_FullMethodName}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		resolved = clientip.FromContext(ctx)
+		return "ok", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", resolved)
+}
@@ -0,0 +1,26 @@
+package organization
+
+import (
+	"go.uber.org/zap"
+
+	organizationpb "github.com/yi-tech/go-user-service/api/proto/organization/v1"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
+)
+
+// Handler is a wrapper for the OrganizationServer to match the wire.go expectations
+type Handler struct {
+	*OrganizationServer
+}
+
+// NewHandler creates a new organization gRPC handler
+func NewHandler(orgs serviceOrg.Service, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		OrganizationServer: NewOrganizationServer(orgs, ids, logger),
+	}
+}
+
+// GetServer returns the underlying OrganizationServer for registration with gRPC
+func (h *Handler) GetServer() organizationpb.OrganizationServiceServer {
+	return h.OrganizationServer
+}
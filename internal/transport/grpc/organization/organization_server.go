@@ -0,0 +1,249 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	organizationpb "github.com/yi-tech/go-user-service/api/proto/organization/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
+)
+
+// OrganizationServer implements the OrganizationService gRPC service
+type OrganizationServer struct {
+	organizationpb.UnimplementedOrganizationServiceServer
+	orgs   serviceOrg.Service
+	ids    idgen.Strategy // Text form of rendered IDs
+	logger *zap.Logger
+}
+
+// NewOrganizationServer creates a new OrganizationServer
+func NewOrganizationServer(orgs serviceOrg.Service, ids idgen.Strategy, logger *zap.Logger) *OrganizationServer {
+	return &OrganizationServer{
+		orgs:   orgs,
+		ids:    ids,
+		logger: logger,
+	}
+}
+
+// CreateOrganization creates an organization owned by the caller
+func (s *OrganizationServer) CreateOrganization(ctx context.Context, req *organizationpb.CreateOrganizationRequest) (*organizationpb.Organization, error) {
+	callerID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgs.Create(ctx, callerID, domainOrg.Input{Name: req.Name, Slug: req.Slug})
+	if err != nil {
+		return nil, s.fail("Create organization failed", err)
+	}
+	return s.organizationToPb(org), nil
+}
+
+// ListOrganizations lists the organizations of the caller
+func (s *OrganizationServer) ListOrganizations(ctx context.Context, _ *organizationpb.ListOrganizationsRequest) (*organizationpb.ListOrganizationsResponse, error) {
+	callerID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs, err := s.orgs.List(ctx, callerID)
+	if err != nil {
+		return nil, s.fail("List organizations failed", err)
+	}
+	resp := &organizationpb.ListOrganizationsResponse{
+		Organizations: make([]*organizationpb.Organization, 0, len(orgs)),
+	}
+	for _, org := range orgs {
+		resp.Organizations = append(resp.Organizations, s.organizationToPb(org))
+	}
+	return resp, nil
+}
+
+// GetOrganization retrieves an organization of the caller
+func (s *OrganizationServer) GetOrganization(ctx context.Context, req *organizationpb.GetOrganizationRequest) (*organizationpb.Organization, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgs.Get(ctx, callerID, orgID)
+	if err != nil {
+		return nil, s.fail("Get organization failed", err)
+	}
+	return s.organizationToPb(org), nil
+}
+
+// ListMembers lists the members and invitations of an organization
+func (s *OrganizationServer) ListMembers(ctx context.Context, req *organizationpb.ListMembersRequest) (*organizationpb.ListMembersResponse, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgs.ListMembers(ctx, callerID, orgID)
+	if err != nil {
+		return nil, s.fail("List members failed", err)
+	}
+	return &organizationpb.ListMembersResponse{Members: s.membersToPb(members)}, nil
+}
+
+// InviteMember invites a registered user to an organization
+func (s *OrganizationServer) InviteMember(ctx context.Context, req *organizationpb.InviteMemberRequest) (*organizationpb.Member, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	member, err := s.orgs.Invite(ctx, callerID, orgID, req.Email, domainOrg.Role(req.Role))
+	if err != nil {
+		return nil, s.fail("Invite member failed", err)
+	}
+	return s.memberToPb(member), nil
+}
+
+// ListInvitations lists the pending invitations of the caller
+func (s *OrganizationServer) ListInvitations(ctx context.Context, _ *organizationpb.ListInvitationsRequest) (*organizationpb.ListInvitationsResponse, error) {
+	callerID, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.orgs.ListInvitations(ctx, callerID)
+	if err != nil {
+		return nil, s.fail("List invitations failed", err)
+	}
+	return &organizationpb.ListInvitationsResponse{Invitations: s.membersToPb(invitations)}, nil
+}
+
+// AcceptInvitation accepts an invitation of the caller
+func (s *OrganizationServer) AcceptInvitation(ctx context.Context, req *organizationpb.AcceptInvitationRequest) (*organizationpb.Member, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.orgs.AcceptInvitation(ctx, callerID, orgID)
+	if err != nil {
+		return nil, s.fail("Accept invitation failed", err)
+	}
+	return s.memberToPb(member), nil
+}
+
+// UpdateMemberRole changes the role of a member
+func (s *OrganizationServer) UpdateMemberRole(ctx context.Context, req *organizationpb.UpdateMemberRoleRequest) (*organizationpb.Member, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := idgen.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+
+	member, err := s.orgs.UpdateMemberRole(ctx, callerID, orgID, userID, domainOrg.Role(req.Role))
+	if err != nil {
+		return nil, s.fail("Update member role failed", err)
+	}
+	return s.memberToPb(member), nil
+}
+
+// RemoveMember removes a member or withdraws an invitation
+func (s *OrganizationServer) RemoveMember(ctx context.Context, req *organizationpb.RemoveMemberRequest) (*emptypb.Empty, error) {
+	callerID, orgID, err := callerAndOrg(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := idgen.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+
+	if err := s.orgs.RemoveMember(ctx, callerID, orgID, userID); err != nil {
+		return nil, s.fail("Remove member failed", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// fail logs unexpected errors and converts err to a gRPC status
+func (s *OrganizationServer) fail(msg string, err error) error {
+	if _, ok := apperror.As(err); !ok {
+		s.logger.Error(msg, zap.Error(err))
+	}
+	return apperror.GRPCStatus(err)
+}
+
+// caller returns the ID of the authenticated caller
+func caller(ctx context.Context) (uuid.UUID, error) {
+	callerID, ok := requestctx.UserID(ctx)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+	return callerID, nil
+}
+
+// callerAndOrg returns the ID of the authenticated caller and parses the
+// organization ID of the request
+func callerAndOrg(ctx context.Context, rawOrgID string) (uuid.UUID, uuid.UUID, error) {
+	callerID, err := caller(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	orgID, err := idgen.Parse(rawOrgID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid organization ID format: %v", err)
+	}
+	return callerID, orgID, nil
+}
+
+// organizationToPb converts a domain organization to a protobuf Organization
+func (s *OrganizationServer) organizationToPb(org *domainOrg.Organization) *organizationpb.Organization {
+	return &organizationpb.Organization{
+		Id:        s.ids.Format(org.ID),
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedBy: s.ids.Format(org.CreatedBy),
+		CreatedAt: timestamppb.New(org.CreatedAt),
+		UpdatedAt: timestamppb.New(org.UpdatedAt),
+	}
+}
+
+// memberToPb converts a domain member to a protobuf Member
+func (s *OrganizationServer) memberToPb(member *domainOrg.Member) *organizationpb.Member {
+	msg := &organizationpb.Member{
+		OrganizationId: s.ids.Format(member.OrganizationID),
+		UserId:         s.ids.Format(member.UserID),
+		Email:          member.Email,
+		Role:           string(member.Role),
+		Status:         string(member.Status),
+		CreatedAt:      timestamppb.New(member.CreatedAt),
+	}
+	if member.InvitedBy != nil {
+		msg.InvitedBy = s.ids.Format(*member.InvitedBy)
+	}
+	if member.JoinedAt != nil {
+		msg.JoinedAt = timestamppb.New(*member.JoinedAt)
+	}
+	return msg
+}
+
+// membersToPb converts domain members to protobuf Members
+func (s *OrganizationServer) membersToPb(members []*domainOrg.Member) []*organizationpb.Member {
+	msgs := make([]*organizationpb.Member, 0, len(members))
+	for _, member := range members {
+		msgs = append(msgs, s.memberToPb(member))
+	}
+	return msgs
+}
@@ -0,0 +1,123 @@
+package organization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	organizationpb "github.com/yi-tech/go-user-service/api/proto/organization/v1"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
+)
+
+// stubService answers the calls a test makes; any other call panics on the
+// nil embedded Service
+type stubService struct {
+	serviceOrg.Service
+	members []*domainOrg.Member
+	err     error
+	invited domainOrg.Role
+}
+
+func (s *stubService) ListMembers(_ context.Context, _, _ uuid.UUID) ([]*domainOrg.Member, error) {
+	return s.members, s.err
+}
+
+func (s *stubService) Invite(_ context.Context, _, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.invited = role
+	return &domainOrg.Member{OrganizationID: orgID, UserID: uuid.New(), Email: email, Role: role, Status: domainOrg.StatusInvited}, nil
+}
+
+func TestOrganizationServer_ListMembers(t *testing.T) {
+	callerID := uuid.New()
+	orgID := uuid.New()
+	joined := time.Date(2025, 6, 30, 9, 0, 0, 0, time.UTC)
+	ctx := requestctx.WithUserID(context.Background(), callerID)
+
+	t.Run("Success", func(t *testing.T) {
+		orgs := &stubService{members: []*domainOrg.Member{
+			{OrganizationID: orgID, UserID: callerID, Role: domainOrg.RoleOwner, Status: domainOrg.StatusActive, CreatedAt: joined, JoinedAt: &joined},
+			{OrganizationID: orgID, UserID: uuid.New(), Role: domainOrg.RoleMember, Status: domainOrg.StatusInvited, InvitedBy: &callerID, CreatedAt: joined},
+		}}
+		server := NewOrganizationServer(orgs, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.ListMembers(ctx, &organizationpb.ListMembersRequest{OrganizationId: orgID.String()})
+
+		require.NoError(t, err)
+		require.Len(t, resp.Members, 2)
+		assert.Equal(t, "owner", resp.Members[0].Role)
+		assert.Empty(t, resp.Members[0].InvitedBy)
+		assert.NotNil(t, resp.Members[0].JoinedAt)
+		assert.Equal(t, callerID.String(), resp.Members[1].InvitedBy)
+		assert.Nil(t, resp.Members[1].JoinedAt)
+	})
+
+	t.Run("Not A Member", func(t *testing.T) {
+		server := NewOrganizationServer(&stubService{err: serviceOrg.ErrOrganizationNotFound}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.ListMembers(ctx, &organizationpb.ListMembersRequest{OrganizationId: orgID.String()})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Invalid Organization ID", func(t *testing.T) {
+		server := NewOrganizationServer(&stubService{}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.ListMembers(ctx, &organizationpb.ListMembersRequest{OrganizationId: "nope"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		server := NewOrganizationServer(&stubService{}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.ListMembers(context.Background(), &organizationpb.ListMembersRequest{OrganizationId: orgID.String()})
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestOrganizationServer_InviteMember(t *testing.T) {
+	ctx := requestctx.WithUserID(context.Background(), uuid.New())
+	orgID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		orgs := &stubService{}
+		server := NewOrganizationServer(orgs, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		member, err := server.InviteMember(ctx, &organizationpb.InviteMemberRequest{OrganizationId: orgID.String(), Email: "bob@example.com", Role: "admin"})
+
+		require.NoError(t, err)
+		assert.Equal(t, domainOrg.RoleAdmin, orgs.invited)
+		assert.Equal(t, "invited", member.Status)
+		assert.Equal(t, orgID.String(), member.OrganizationId)
+	})
+
+	t.Run("Permission Denied", func(t *testing.T) {
+		server := NewOrganizationServer(&stubService{err: serviceOrg.ErrPermissionDenied}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.InviteMember(ctx, &organizationpb.InviteMemberRequest{OrganizationId: orgID.String(), Email: "bob@example.com", Role: "owner"})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Missing Email", func(t *testing.T) {
+		server := NewOrganizationServer(&stubService{}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.InviteMember(ctx, &organizationpb.InviteMemberRequest{OrganizationId: orgID.String(), Role: "member"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
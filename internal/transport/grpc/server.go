@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -9,125 +10,293 @@ import (
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
 	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
+	organizationpb "github.com/yi-tech/go-user-service/api/proto/organization/v1"
 	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/deprecation"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
 	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	grpcAdmin "github.com/yi-tech/go-user-service/internal/transport/grpc/admin"
 	grpcAuth "github.com/yi-tech/go-user-service/internal/transport/grpc/auth"
+	"github.com/yi-tech/go-user-service/internal/transport/grpc/interceptor"
+	grpcOrg "github.com/yi-tech/go-user-service/internal/transport/grpc/organization"
 	grpcUser "github.com/yi-tech/go-user-service/internal/transport/grpc/user"
 )
 
+// publicMethods lists the RPCs callable without an access token. Every other
+// RPC, including ones added later, requires a valid access token.
+var publicMethods = []string{
+	userpb.UserService_Register_FullMethodName,
+	userpb.UserService_Login_FullMethodName,
+	authpb.AuthService_Login_FullMethodName,
+	authpb.AuthService_RefreshToken_FullMethodName,
+	authpb.AuthService_Logout_FullMethodName, // Identified by the refresh token
+}
+
+// readOnlyMethods lists the RPCs served while read-only mode is on: reads
+// and sign-ins, which only touch Redis. Every other RPC is rejected.
+var readOnlyMethods = []string{
+	userpb.UserService_Login_FullMethodName,
+	userpb.UserService_GetProfile_FullMethodName,
+	userpb.UserService_GetUserByEmail_FullMethodName,
+	userpb.UserService_BatchGetUsers_FullMethodName,
+	authpb.AuthService_Login_FullMethodName,
+	authpb.AuthService_RefreshToken_FullMethodName,
+	authpb.AuthService_Logout_FullMethodName,
+	authpb.AuthService_ValidateToken_FullMethodName,
+	authpb.AuthService_ValidateTokens_FullMethodName,
+	authpb.AuthService_GetUserFromToken_FullMethodName,
+	organizationpb.OrganizationService_ListOrganizations_FullMethodName,
+	organizationpb.OrganizationService_GetOrganization_FullMethodName,
+	organizationpb.OrganizationService_ListMembers_FullMethodName,
+	organizationpb.OrganizationService_ListInvitations_FullMethodName,
+	adminpb.AdminService_ListUsers_FullMethodName,
+	adminpb.AdminService_StreamUsers_FullMethodName,
+	adminpb.AdminService_GetServerInfo_FullMethodName,
+}
+
+// deprecatedMethods lists the RPCs clients should migrate away from. Calls
+// to them carry deprecation, sunset and warning header metadata.
+var deprecatedMethods = map[string]deprecation.Notice{
+	// Returns placeholder tokens; AuthService.Login issues real ones
+	userpb.UserService_Login_FullMethodName: {
+		Since:       time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC),
+		Replacement: authpb.AuthService_Login_FullMethodName,
+	},
+}
+
+// serviceGroups maps services to the REST route group whose IP filter they
+// share; services not listed only pass the global filter
+var serviceGroups = map[string]string{
+	userpb.UserService_ServiceDesc.ServiceName:                 "users",
+	authpb.AuthService_ServiceDesc.ServiceName:                 "auth",
+	organizationpb.OrganizationService_ServiceDesc.ServiceName: "orgs",
+	adminpb.AdminService_ServiceDesc.ServiceName:               "admin",
+}
+
+// GatewayProxies are the addresses the gateway calls the gRPC server from.
+// Client IP resolvers must trust them so that gateway calls are attributed
+// to the client address the gateway forwards.
+var GatewayProxies = []string{"127.0.0.1", "::1"}
+
+// reflectionMethods are public when server reflection is enabled
+var reflectionMethods = []string{
+	grpc_reflection_v1.ServerReflection_ServerReflectionInfo_FullMethodName,
+	grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfo_FullMethodName,
+}
+
 // Config represents the gRPC server configuration
 type Config struct {
-	GRPCPort int
-	HTTPPort int
+	GRPCPort          int
+	HTTPPort          int
+	Reflection        bool // register the server reflection service (for grpcurl etc.)
+	MaxRecvMsgSize    int  // bytes; 0 keeps the gRPC default (4MB)
+	MaxSendMsgSize    int  // bytes; 0 keeps the gRPC default
+	ConnectionTimeout time.Duration
+	Keepalive         KeepaliveConfig
+	MaxInFlight       int // unary RPCs served at once, sign-ins aside; 0 is unlimited
+	AuthMaxInFlight   int // sign-in, registration, refresh and sign-out RPCs served at once; 0 is unlimited
 }
 
-// Server represents the gRPC server
-type Server struct {
-	userHandler *grpcUser.Handler
-	authHandler *grpcAuth.Handler
-	logger      *zap.Logger
-	cfg         *Config
-	server      *grpc.Server
-	httpServer  *http.Server
+// publicMethods returns the RPCs that skip authentication under this configuration
+func (c *Config) publicMethods() []string {
+	if !c.Reflection {
+		return publicMethods
+	}
+	return append(append([]string{}, publicMethods...), reflectionMethods...)
 }
 
-// NewServer creates a new gRPC server
-func NewServer(userService serviceUser.UserService, authService domainAuth.AuthService, logger *zap.Logger, cfg *Config) *Server {
-	return &Server{
-		userHandler: grpcUser.NewHandler(userService, logger),
-		authHandler: grpcAuth.NewHandler(authService, logger),
-		logger:      logger,
-		cfg:         cfg,
+// readOnlyMethods returns the RPCs served in read-only mode under this configuration
+func (c *Config) readOnlyMethods() []string {
+	if !c.Reflection {
+		return readOnlyMethods
 	}
+	return append(append([]string{}, readOnlyMethods...), reflectionMethods...)
 }
 
-// Start starts the gRPC server and the HTTP gateway
-func (s *Server) Start() error {
-	// Create a listener for the gRPC server
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.GRPCPort))
-	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
-	}
+// Server represents the gRPC server
+type Server struct {
+	userHandler     *grpcUser.Handler
+	authHandler     *grpcAuth.Handler
+	orgHandler      *grpcOrg.Handler
+	adminHandler    *grpcAdmin.Handler
+	authInterceptor *interceptor.AuthInterceptor
+	deprecation     *interceptor.DeprecationInterceptor
+	loadShed        *interceptor.LoadShedInterceptor
+	clientIP        *interceptor.ClientIPInterceptor
+	ipFilter        *interceptor.IPFilterInterceptor    // nil when client addresses are not filtered
+	readOnly        *interceptor.ReadOnlyInterceptor    // nil when read-only mode is not wired in
+	recovery        *interceptor.RecoveryInterceptor    // nil when panics are not recovered
+	errorReport     *interceptor.ErrorReportInterceptor // nil when errors are not reported
+	logger          *zap.Logger
+	cfg             *Config
+	server          *grpc.Server
+	httpServer      *http.Server
+	gatewayMux      *runtime.ServeMux
+	gatewayCtx      context.Context
+	gatewayCancel   context.CancelFunc
 
-	// Create a new gRPC server
-	s.server = grpc.NewServer()
+	ids                idgen.Strategy
+	resolver           *clientip.Resolver
+	settings           grpcAdmin.Settings // nil when GetServerInfo reports no configuration
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOptions      []grpc.ServerOption
+	gatewayMiddleware  []func(http.Handler) http.Handler
+}
+
+// NewServer creates a new gRPC server. Authentication is always installed;
+// opts can add interceptors and server options on top of it.
+func NewServer(userService serviceUser.UserService, authService domainAuth.AuthService, adminService serviceAdmin.AdminService, organizations serviceOrg.Service, logger *zap.Logger, cfg *Config, opts ...Option) *Server {
+	// Trusting the gateway only; WithClientIPResolver adds the load balancers
+	resolver, _ := clientip.NewResolver(GatewayProxies, nil)
+	s := &Server{
+		resolver:        resolver,
+		authInterceptor: interceptor.NewAuthInterceptor(authService, logger, cfg.publicMethods()...),
+		deprecation:     interceptor.NewDeprecationInterceptor(deprecatedMethods, logger),
+		loadShed:        interceptor.NewLoadShedInterceptor(cfg.MaxInFlight, cfg.AuthMaxInFlight, logger, publicMethods...),
+		logger:          logger,
+		cfg:             cfg,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.clientIP = interceptor.NewClientIPInterceptor(s.resolver)
+	s.userHandler = grpcUser.NewHandler(userService, authService, adminService, s.ids, logger)
+	s.authHandler = grpcAuth.NewHandler(authService, userService, s.ids, logger)
+	s.orgHandler = grpcOrg.NewHandler(organizations, s.ids, logger)
+	s.adminHandler = grpcAdmin.NewHandler(adminService, userService, s.settings, s.ids, logger)
 
-	// Register services
+	// Servers are created up front so Shutdown is safe even if Serve has not started yet
+	s.server = grpc.NewServer(s.buildServerOptions()...)
 	authpb.RegisterAuthServiceServer(s.server, s.authHandler.GetServer())
 	userpb.RegisterUserServiceServer(s.server, s.userHandler.GetServer())
+	organizationpb.RegisterOrganizationServiceServer(s.server, s.orgHandler.GetServer())
+	adminpb.RegisterAdminServiceServer(s.server, s.adminHandler.GetServer())
+	if cfg.Reflection {
+		reflection.Register(s.server)
+	}
 
-	// Start the gRPC server in a goroutine
-	go func() {
-		s.logger.Info("Starting gRPC server", zap.Int("port", s.cfg.GRPCPort))
-		if err := s.server.Serve(lis); err != nil {
-			s.logger.Error("Failed to serve gRPC", zap.Error(err))
-		}
-	}()
+	s.gatewayMux = newGatewayMux(s.resolver.Headers())
+	var gateway http.Handler = s.gatewayMux
+	for i := len(s.gatewayMiddleware) - 1; i >= 0; i-- {
+		gateway = s.gatewayMiddleware[i](gateway)
+	}
+	s.httpServer = &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTPPort),
+		Handler: gateway,
+	}
+	s.gatewayCtx, s.gatewayCancel = context.WithCancel(context.Background())
 
-	// Start the HTTP gateway
-	return s.startHTTPGateway()
+	return s
 }
 
-// startHTTPGateway starts the HTTP gateway for the gRPC server
-func (s *Server) startHTTPGateway() error {
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	// Create a new mux for the HTTP gateway
-	mux := runtime.NewServeMux()
-
-	// Set up a connection to the gRPC server
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	grpcServerEndpoint := fmt.Sprintf("localhost:%d", s.cfg.GRPCPort)
-
-	// Register services
-	err := authpb.RegisterAuthServiceHandlerFromEndpoint(ctx, mux, grpcServerEndpoint, opts)
+// Serve runs the gRPC server and the HTTP gateway. It blocks until both have
+// stopped and returns the first startup or serve error. If either server
+// fails the other is stopped; a graceful Shutdown makes Serve return nil.
+func (s *Server) Serve() error {
+	// Listen before serving so port conflicts surface as startup errors
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.GRPCPort))
 	if err != nil {
-		return fmt.Errorf("failed to register auth service handler: %v", err)
+		return fmt.Errorf("failed to listen on gRPC port: %w", err)
 	}
 
-	err = userpb.RegisterUserServiceHandlerFromEndpoint(ctx, mux, grpcServerEndpoint, opts)
-	if err != nil {
-		return fmt.Errorf("failed to register user service handler: %v", err)
+	if err := s.registerGateway(); err != nil {
+		lis.Close()
+		return err
 	}
 
-	// Create a new HTTP server for the gateway
-	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.cfg.HTTPPort),
-		Handler: mux,
+	gatewayLis, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		lis.Close()
+		return fmt.Errorf("failed to listen on gateway port: %w", err)
 	}
 
-	// Start the HTTP server in a goroutine
+	g, gctx := errgroup.WithContext(context.Background())
 	go func() {
+		// gctx is cancelled with the first serve error, or with
+		// context.Canceled once both servers have returned cleanly. Stop the
+		// surviving server on failure so Serve reports the error instead of
+		// blocking on half of the listeners.
+		<-gctx.Done()
+		if cause := context.Cause(gctx); errors.Is(cause, context.Canceled) {
+			return
+		}
+		s.logger.Warn("Stopping gRPC server after listener failure")
+		s.httpServer.Close()
+		s.gatewayCancel()
+		s.server.Stop()
+	}()
+	g.Go(func() error {
+		s.logger.Info("Starting gRPC server", zap.Int("port", s.cfg.GRPCPort))
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			return fmt.Errorf("gRPC server error: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
 		s.logger.Info("Starting HTTP gateway", zap.Int("port", s.cfg.HTTPPort))
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.logger.Error("Failed to serve HTTP gateway", zap.Error(err))
+		if err := s.httpServer.Serve(gatewayLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("HTTP gateway error: %w", err)
 		}
+		return nil
+	})
+	return g.Wait()
+}
+
+// registerGateway connects the HTTP gateway to the gRPC server. The client
+// connections live until Shutdown so in-flight gateway requests can drain.
+func (s *Server) registerGateway() error {
+	grpcServerEndpoint := fmt.Sprintf("localhost:%d", s.cfg.GRPCPort)
+	conn, err := grpc.NewClient(grpcServerEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect gateway to gRPC server: %w", err)
+	}
+	go func() {
+		<-s.gatewayCtx.Done()
+		conn.Close()
 	}()
 
-	return nil
+	return registerGatewayHandlers(s.gatewayCtx, s.gatewayMux, conn)
 }
 
-// Shutdown gracefully shuts down the gRPC server and the HTTP gateway
+// Shutdown drains the HTTP gateway and then the gRPC server. In-flight RPCs
+// are cancelled if they do not finish before ctx expires.
 func (s *Server) Shutdown(ctx context.Context) error {
-	// Shutdown the HTTP gateway
-	if s.httpServer != nil {
-		if err := s.httpServer.Shutdown(ctx); err != nil {
-			s.logger.Error("Failed to shutdown HTTP gateway", zap.Error(err))
-		}
+	// Drain the gateway first; its requests are proxied to the gRPC server
+	gatewayErr := s.httpServer.Shutdown(ctx)
+	if gatewayErr != nil {
+		s.logger.Error("Failed to shutdown HTTP gateway", zap.Error(gatewayErr))
 	}
+	s.gatewayCancel()
 
-	// Gracefully stop the gRPC server
-	if s.server != nil {
+	stopped := make(chan struct{})
+	go func() {
 		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return gatewayErr
+	case <-ctx.Done():
+		s.logger.Warn("gRPC graceful stop timed out, forcing stop")
+		s.server.Stop()
+		return errors.Join(gatewayErr, ctx.Err())
 	}
-	return nil
 }
 
 // Stop gracefully shuts down the gRPC server and the HTTP gateway
@@ -138,3 +307,15 @@ func (s *Server) Stop() error {
 
 	return s.Shutdown(ctx)
 }
+
+// outgoingHeaderMatcher maps gRPC response metadata to gateway response
+// headers. Deprecation headers keep their standard names so HTTP clients
+// recognise them; other metadata gets the default Grpc-Metadata- prefix.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	switch key {
+	case "deprecation", "sunset", "link":
+		return http.CanonicalHeaderKey(key), true
+	default:
+		return runtime.MetadataHeaderPrefix + key, true
+	}
+}
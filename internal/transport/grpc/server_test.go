@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	authpb "github.com/yi-tech/go-user-service/api/proto/auth/v1"
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+)
+
+func TestConfigPublicMethods(t *testing.T) {
+	t.Run("Only Sign In And Sign Out Flows Are Public", func(t *testing.T) {
+		public := (&Config{}).publicMethods()
+
+		assert.ElementsMatch(t, []string{
+			userpb.UserService_Register_FullMethodName,
+			userpb.UserService_Login_FullMethodName,
+			authpb.AuthService_Login_FullMethodName,
+			authpb.AuthService_RefreshToken_FullMethodName,
+			authpb.AuthService_Logout_FullMethodName,
+		}, public)
+		assert.NotContains(t, public, userpb.UserService_GetProfile_FullMethodName)
+		assert.NotContains(t, public, authpb.AuthService_ValidateToken_FullMethodName)
+	})
+
+	t.Run("Reflection", func(t *testing.T) {
+		public := (&Config{Reflection: true}).publicMethods()
+
+		assert.Subset(t, public, reflectionMethods)
+		assert.Len(t, publicMethods, 5, "enabling reflection must not modify the shared list")
+	})
+}
+
+func TestConfigReadOnlyMethods(t *testing.T) {
+	allowed := (&Config{}).readOnlyMethods()
+
+	assert.Contains(t, allowed, authpb.AuthService_Login_FullMethodName)
+	assert.Contains(t, allowed, userpb.UserService_GetProfile_FullMethodName)
+	assert.NotContains(t, allowed, userpb.UserService_Register_FullMethodName)
+	assert.NotContains(t, allowed, userpb.UserService_UpdateProfile_FullMethodName)
+	assert.NotContains(t, allowed, userpb.UserService_DeleteUser_FullMethodName)
+}
+
+func TestOutgoingHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "deprecation", expected: "Deprecation"},
+		{key: "sunset", expected: "Sunset"},
+		{key: "warning", expected: "Grpc-Metadata-warning"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			header, ok := outgoingHeaderMatcher(tt.key)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, header)
+		})
+	}
+}
+
+func TestIncomingHeaderMatcher(t *testing.T) {
+	matcher := incomingHeaderMatcher([]string{"X-Real-Ip", "Cf-Connecting-Ip"})
+
+	tests := []struct {
+		header   string
+		expected string
+		ok       bool
+	}{
+		{header: "X-Real-IP", expected: "x-real-ip", ok: true},
+		{header: "cf-connecting-ip", expected: "cf-connecting-ip", ok: true},
+		{header: "Grpc-Metadata-Trace", expected: "Trace", ok: true},
+		{header: "X-Captcha-Token", expected: "x-captcha-token", ok: true},
+		{header: "X-Custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			key, ok := matcher(tt.header)
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, key)
+			}
+		})
+	}
+}
+
+func TestValidateTokensStream(t *testing.T) {
+	callerID, userID := uuid.New(), uuid.New()
+	auth := new(MockAuthService)
+	auth.On("Authenticate", mock.Anything, "sidecar-token").Return(callerID, nil)
+	auth.On("Authenticate", mock.Anything, "user-token").Return(userID, nil)
+	auth.On("Authenticate", mock.Anything, "stale-token").Return(uuid.Nil, serviceAuth.ErrInvalidToken)
+
+	s := NewServer(new(MockUserService), auth, nil, nil, zaptest.NewLogger(t), &Config{})
+	lis := bufconn.Listen(1 << 20)
+	go s.server.Serve(lis)
+	t.Cleanup(s.server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := authpb.NewAuthServiceClient(conn)
+
+	t.Run("Pipelined Tokens", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer sidecar-token")
+		stream, err := client.ValidateTokens(ctx)
+		require.NoError(t, err)
+
+		// Send every request before reading any response
+		require.NoError(t, stream.Send(&authpb.ValidateTokensRequest{RequestId: "a", AccessToken: "user-token"}))
+		require.NoError(t, stream.Send(&authpb.ValidateTokensRequest{RequestId: "b", AccessToken: "stale-token"}))
+		require.NoError(t, stream.CloseSend())
+
+		first, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "a", first.RequestId)
+		assert.True(t, first.Valid)
+		assert.Equal(t, userID.String(), first.UserId)
+
+		second, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "b", second.RequestId)
+		assert.False(t, second.Valid)
+		assert.Equal(t, "INVALID_TOKEN", second.ErrorCode)
+	})
+
+	t.Run("Stream Requires Authentication", func(t *testing.T) {
+		stream, err := client.ValidateTokens(context.Background())
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
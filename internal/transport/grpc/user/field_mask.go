@@ -0,0 +1,82 @@
+package user
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+)
+
+// validateReadMask ensures every path of a read mask names a User field
+func validateReadMask(mask *fieldmaskpb.FieldMask) error {
+	fields := (&userpb.User{}).ProtoReflect().Descriptor().Fields()
+	for _, path := range mask.GetPaths() {
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return status.Errorf(codes.InvalidArgument, "invalid read_mask path %q", path)
+		}
+	}
+	return nil
+}
+
+// applyReadMask clears the fields of user a validated read mask does not
+// name. An empty mask keeps every field.
+func applyReadMask(user *userpb.User, mask *fieldmaskpb.FieldMask) {
+	if len(mask.GetPaths()) == 0 {
+		return
+	}
+	keep := make(map[protoreflect.Name]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = true
+	}
+	msg := user.ProtoReflect()
+	msg.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !keep[field.Name()] {
+			msg.Clear(field)
+		}
+		return true
+	})
+}
+
+// updateParams converts an UpdateProfile request into the update of the user
+// service. Without an update mask every non-empty field is updated, as proto3
+// cannot tell an unset string from an empty one. With one exactly the named
+// fields are, so an empty value clears the field.
+func updateParams(req *userpb.UpdateProfileRequest) (domainUser.UpdateUserParams, error) {
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		return domainUser.UpdateUserParams{
+			FirstName:   nonEmpty(req.FirstName),
+			LastName:    nonEmpty(req.LastName),
+			DisplayName: nonEmpty(req.DisplayName),
+			Email:       nonEmpty(req.Email),
+		}, nil
+	}
+
+	var params domainUser.UpdateUserParams
+	for _, path := range paths {
+		switch path {
+		case "first_name":
+			params.FirstName = &req.FirstName
+		case "last_name":
+			params.LastName = &req.LastName
+		case "display_name":
+			params.DisplayName = &req.DisplayName
+		case "email":
+			params.Email = &req.Email
+		default:
+			return domainUser.UpdateUserParams{}, status.Errorf(codes.InvalidArgument, "invalid update_mask path %q", path)
+		}
+	}
+	return params, nil
+}
+
+// nonEmpty returns a pointer to value, or nil when it is empty
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -2,18 +2,18 @@ package user
 
 import (
 	"context"
-	"errors"
 
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
-	"google.golang.org/protobuf/types/known/timestamppb"
 
 	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
 	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
 )
 
 // Handler is a wrapper for the UserServer to match the wire.go expectations
@@ -22,9 +22,9 @@ type Handler struct {
 }
 
 // NewHandler creates a new user gRPC handler
-func NewHandler(userService serviceUser.UserService, logger *zap.Logger) *Handler {
+func NewHandler(userService serviceUser.UserService, auth Authenticator, accounts AccountManager, ids idgen.Strategy, logger *zap.Logger) *Handler {
 	return &Handler{
-		UserServer: NewUserServer(userService, logger),
+		UserServer: NewUserServer(userService, auth, accounts, ids, logger),
 	}
 }
 
@@ -41,33 +41,24 @@ func (h *Handler) Register(ctx context.Context, req *userpb.RegisterRequest) (*u
 		return nil, status.Error(codes.InvalidArgument, "Email, password, and first name are required")
 	}
 
-	userInput := domainUser.RegisterUserInput{
-		Email:     req.Email,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-	}
 	// Call the user service to register the user
-	user, err := h.userService.Register(ctx, userInput)
+	user, err := h.userService.Register(ctx, registerInput(ctx, req))
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user already exists")) || err.Error() == "user already exists" {
-			return nil, status.Error(codes.AlreadyExists, "User already exists")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("User registration failed", zap.Error(err))
 		}
-
-		h.logger.Error("User registration failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "user registration failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	// Convert domain user to protobuf user
-	return h.userToPb(user), nil
+	return mapper.UserToPb(user, h.ids), nil
 }
 
 // GetUserByID handles the GetUserByID gRPC request
 // This is a wrapper around GetProfile to maintain compatibility with tests
 func (h *Handler) GetUserByID(ctx context.Context, req *userpb.GetProfileRequest) (*userpb.User, error) {
 	// Validate UUID
-	userID, err := uuid.Parse(req.GetId())
+	userID, err := idgen.Parse(req.GetId())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
 	}
@@ -75,18 +66,14 @@ func (h *Handler) GetUserByID(ctx context.Context, req *userpb.GetProfileRequest
 	// Get user from service
 	user, err := h.userService.GetByID(ctx, userID)
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user not found")) || err.Error() == "user not found" {
-			return nil, status.Error(codes.NotFound, "User not found")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("Failed to get user by ID", zap.Error(err), zap.String("user_id", req.GetId()))
 		}
-
-		// Log the error
-		h.logger.Error("Failed to get user by ID", zap.Error(err), zap.String("user_id", req.GetId()))
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	// Convert domain user to protobuf user
-	return h.userToPb(user), nil
+	return mapper.UserToPb(user, h.ids), nil
 }
 
 // GetUserByEmailRequest is a custom type for the test
@@ -110,18 +97,14 @@ func (h *Handler) GetUserByEmail(ctx context.Context, req *GetUserByEmailRequest
 	// Get user from service
 	user, err := h.userService.GetByEmail(ctx, req.GetEmail())
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user not found")) || err.Error() == "user not found" {
-			return nil, status.Error(codes.NotFound, "User not found")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("Failed to get user by email", zap.Error(err), zap.String("email", req.GetEmail()))
 		}
-
-		// Log the error
-		h.logger.Error("Failed to get user by email", zap.Error(err), zap.String("email", req.GetEmail()))
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	// Convert domain user to protobuf user
-	return h.userToPb(user), nil
+	return mapper.UserToPb(user, h.ids), nil
 }
 
 // UpdateUserRequest is a custom type for the test
@@ -150,7 +133,7 @@ func (r *UpdateUserRequest) GetLastName() string {
 // This is a custom implementation for test compatibility
 func (h *Handler) UpdateUser(ctx context.Context, req *UpdateUserRequest) (*userpb.User, error) {
 	// Validate UUID
-	userID, err := uuid.Parse(req.GetId())
+	userID, err := idgen.Parse(req.GetId())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
 	}
@@ -161,24 +144,20 @@ func (h *Handler) UpdateUser(ctx context.Context, req *UpdateUserRequest) (*user
 	}
 
 	updateParams := domainUser.UpdateUserParams{
-		FirstName: req.GetFirstName(),
-		LastName:  req.GetLastName(),
+		FirstName: nonEmpty(req.GetFirstName()),
+		LastName:  nonEmpty(req.GetLastName()),
 	}
 	// Update user in service
 	user, err := h.userService.Update(ctx, userID, updateParams)
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user not found")) || err.Error() == "user not found" {
-			return nil, status.Error(codes.NotFound, "User not found")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", req.GetId()))
 		}
-
-		// Log the error
-		h.logger.Error("Failed to update user", zap.Error(err), zap.String("user_id", req.GetId()))
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	// Convert domain user to protobuf user
-	return h.userToPb(user), nil
+	return mapper.UserToPb(user, h.ids), nil
 }
 
 // UpdatePasswordRequest is a custom type for the test
@@ -207,7 +186,7 @@ func (r *UpdatePasswordRequest) GetNewPassword() string {
 // This is a custom implementation for test compatibility
 func (h *Handler) UpdatePassword(ctx context.Context, req *UpdatePasswordRequest) (*emptypb.Empty, error) {
 	// Validate UUID
-	userID, err := uuid.Parse(req.GetId())
+	userID, err := idgen.Parse(req.GetId())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
 	}
@@ -224,18 +203,10 @@ func (h *Handler) UpdatePassword(ctx context.Context, req *UpdatePasswordRequest
 	// Update password in service
 	err = h.userService.UpdatePassword(ctx, userID, req.GetCurrentPassword(), req.GetNewPassword())
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user not found")) || err.Error() == "user not found" {
-			return nil, status.Error(codes.NotFound, "User not found")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("Failed to update password", zap.Error(err), zap.String("user_id", req.GetId()))
 		}
-
-		if errors.Is(err, errors.New("invalid current password")) || err.Error() == "invalid current password" {
-			return nil, status.Error(codes.InvalidArgument, "Invalid current password")
-		}
-
-		// Log the error
-		h.logger.Error("Failed to update password", zap.Error(err), zap.String("user_id", req.GetId()))
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return &emptypb.Empty{}, nil
@@ -245,7 +216,7 @@ func (h *Handler) UpdatePassword(ctx context.Context, req *UpdatePasswordRequest
 // This is a custom implementation for test compatibility
 func (h *Handler) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*emptypb.Empty, error) {
 	// Validate UUID
-	userID, err := uuid.Parse(req.GetId())
+	userID, err := idgen.Parse(req.GetId())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "Invalid user ID format")
 	}
@@ -253,38 +224,11 @@ func (h *Handler) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest)
 	// Delete user in service
 	err = h.userService.DeleteUser(ctx, userID)
 	if err != nil {
-		// Check for specific error types
-		if errors.Is(err, errors.New("user not found")) || err.Error() == "user not found" {
-			return nil, status.Error(codes.NotFound, "User not found")
+		if _, ok := apperror.As(err); !ok {
+			h.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", req.GetId()))
 		}
-
-		// Log the error
-		h.logger.Error("Failed to delete user", zap.Error(err), zap.String("user_id", req.GetId()))
-		return nil, status.Error(codes.Internal, "Internal server error")
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return &emptypb.Empty{}, nil
 }
-
-// userToPb converts a domain user to a protobuf user
-func (h *Handler) userToPb(user *domainUser.User) *userpb.User {
-	var createdAt, updatedAt *timestamppb.Timestamp
-
-	if !user.CreatedAt.IsZero() {
-		createdAt = timestamppb.New(user.CreatedAt)
-	}
-
-	if !user.UpdatedAt.IsZero() {
-		updatedAt = timestamppb.New(user.UpdatedAt)
-	}
-
-	return &userpb.User{
-		Id:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  true, // Assuming all users are active by default
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
-	}
-}
@@ -9,14 +9,25 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
 )
 
 // MockUserService is a mock implementation of the domainUser.Service interface
@@ -32,6 +43,19 @@ func (m *MockUserService) Register(ctx context.Context, input domainUser.Registe
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserService) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -40,6 +64,14 @@ func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUse
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
 func (m *MockUserService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -53,6 +85,11 @@ func (m *MockUserService) UpdatePassword(ctx context.Context, id uuid.UUID, curr
 	return args.Error(0)
 }
 
+func (m *MockUserService) RehashPassword(ctx context.Context, user *domainUser.User, password string) error {
+	args := m.Called(ctx, user, password)
+	return args.Error(0)
+}
+
 func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -66,6 +103,50 @@ func (m *MockUserService) Update(ctx context.Context, id uuid.UUID, params domai
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+// MockAccountManager is a mock implementation of the AccountManager interface
+type MockAccountManager struct {
+	mock.Mock
+}
+
+func (m *MockAccountManager) ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockAccountManager) DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+// MockAuthenticator is a mock implementation of the Authenticator interface
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Login(ctx context.Context, input domainAuth.LoginInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthenticator) ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	args := m.Called(ctx, accessToken)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// stringPtr returns a pointer to s, for the optional fields of UpdateUserParams
+func stringPtr(s string) *string {
+	return &s
+}
+
 func createMockUser() *domainUser.User {
 	return &domainUser.User{
 		ID:        uuid.New(), // Or a fixed test UUID: uuid.MustParse("your-test-uuid-here")
@@ -82,7 +163,7 @@ func TestNewHandler(t *testing.T) {
 	mockService := new(MockUserService)
 	logger := zaptest.NewLogger(t)
 
-	handler := NewHandler(mockService, logger)
+	handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 
 	assert.NotNil(t, handler)
 	assert.Equal(t, mockService, handler.userService)
@@ -92,7 +173,7 @@ func TestNewHandler(t *testing.T) {
 func TestRegister(t *testing.T) {
 	mockService := new(MockUserService)
 	logger := zaptest.NewLogger(t)
-	handler := NewHandler(mockService, logger)
+	handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -145,7 +226,7 @@ func TestRegister(t *testing.T) {
 			},
 			setupMock: func() {
 				mockService.On("Register", ctx, domainUser.RegisterUserInput{Email: "existing@example.com", Password: "password123", FirstName: "Existing", LastName: "User"}).
-					Return(nil, errors.New("user already exists"))
+					Return(nil, serviceUser.ErrUserAlreadyExists)
 			},
 			expectedCode: codes.AlreadyExists,
 		},
@@ -185,6 +266,92 @@ func TestRegister(t *testing.T) {
 	}
 }
 
+func TestRegister_Captcha(t *testing.T) {
+	mockService := new(MockUserService)
+	server := NewUserServer(mockService, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(captchaMetadataKey, "robot"))
+	ctx = clientip.NewContext(ctx, "203.0.113.7")
+
+	mockService.On("Register", ctx, domainUser.RegisterUserInput{
+		Email:        "bot@example.com",
+		Password:     "password123",
+		FirstName:    "Bot",
+		LastName:     "User",
+		CaptchaToken: "robot",
+		ClientIP:     "203.0.113.7",
+	}).Return(nil, captcha.ErrCaptchaFailed).Once()
+
+	_, err := server.Register(ctx, &userpb.RegisterRequest{Email: "bot@example.com", Password: "password123", FirstName: "Bot", LastName: "User"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin(t *testing.T) {
+	userID := uuid.New()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "grpc-go/1.60", captchaMetadataKey, "human"))
+	ctx = clientip.NewContext(ctx, "203.0.113.7")
+	input := domainAuth.LoginInput{
+		Email:        "test@example.com",
+		Password:     "password123",
+		UserAgent:    "grpc-go/1.60",
+		ClientIP:     "203.0.113.7",
+		CaptchaToken: "human",
+	}
+
+	tests := []struct {
+		name         string
+		req          *userpb.LoginRequest
+		mockSetup    func(users *MockUserService, auth *MockAuthenticator)
+		expectedCode codes.Code
+	}{
+		{
+			name: "Success",
+			req:  &userpb.LoginRequest{Email: input.Email, Password: input.Password},
+			mockSetup: func(users *MockUserService, auth *MockAuthenticator) {
+				auth.On("Login", ctx, input).Return(&domainAuth.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil).Once()
+				auth.On("ValidateToken", ctx, "access").Return(userID, nil).Once()
+				users.On("GetByID", ctx, userID).Return(&domainUser.User{ID: userID, Email: input.Email, IsActive: true}, nil).Once()
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Invalid Credentials",
+			req:  &userpb.LoginRequest{Email: input.Email, Password: input.Password},
+			mockSetup: func(users *MockUserService, auth *MockAuthenticator) {
+				auth.On("Login", ctx, input).Return(nil, serviceAuth.ErrInvalidCredentials).Once()
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Missing Password",
+			req:          &userpb.LoginRequest{Email: input.Email},
+			mockSetup:    func(users *MockUserService, auth *MockAuthenticator) {},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			auth := new(MockAuthenticator)
+			tt.mockSetup(users, auth)
+			server := NewUserServer(users, auth, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			resp, err := server.Login(ctx, tt.req)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, "access", resp.AccessToken)
+				assert.Equal(t, "refresh", resp.RefreshToken)
+				assert.Equal(t, userID.String(), resp.User.Id)
+			}
+			users.AssertExpectations(t)
+			auth.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetUserByID(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	ctx := context.Background()
@@ -229,7 +396,7 @@ func TestGetUserByID(t *testing.T) {
 				Id: validUUID.String(),
 			},
 			setupMock: func(mockService *MockUserService) {
-				mockService.On("GetByID", ctx, validUUID).Return(nil, errors.New("user not found"))
+				mockService.On("GetByID", ctx, validUUID).Return(nil, serviceUser.ErrUserNotFound)
 			},
 			expectedCode: codes.NotFound,
 		},
@@ -249,7 +416,7 @@ func TestGetUserByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockUserService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -276,7 +443,7 @@ func TestGetUserByID(t *testing.T) {
 func TestGetUserByEmail(t *testing.T) {
 	mockService := new(MockUserService)
 	logger := zaptest.NewLogger(t)
-	handler := NewHandler(mockService, logger)
+	handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -317,7 +484,7 @@ func TestGetUserByEmail(t *testing.T) {
 				Email: "notfound@example.com",
 			},
 			setupMock: func() {
-				mockService.On("GetByEmail", ctx, "notfound@example.com").Return(nil, errors.New("user not found"))
+				mockService.On("GetByEmail", ctx, "notfound@example.com").Return(nil, serviceUser.ErrUserNotFound)
 			},
 			expectedCode: codes.NotFound,
 		},
@@ -378,7 +545,7 @@ func TestUpdateUser(t *testing.T) {
 				updatedUser.ID = validUUID      // Ensure the mock returns the expected ID
 				updatedUser.FirstName = "Updated"
 				updatedUser.LastName = "User" // Assuming LastName is also part of the update or should match createMockUser
-				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: "Updated", LastName: "User"}).Return(updatedUser, nil)
+				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: stringPtr("Updated"), LastName: stringPtr("User")}).Return(updatedUser, nil)
 			},
 			expectedCode: codes.OK,
 			checkResponse: func(user *userpb.User) {
@@ -418,7 +585,7 @@ func TestUpdateUser(t *testing.T) {
 				LastName:  "User",
 			},
 			setupMock: func(mockService *MockUserService) {
-				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: "Updated", LastName: "User"}).Return(nil, errors.New("user not found"))
+				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: stringPtr("Updated"), LastName: stringPtr("User")}).Return(nil, serviceUser.ErrUserNotFound)
 			},
 			expectedCode: codes.NotFound,
 		},
@@ -430,7 +597,7 @@ func TestUpdateUser(t *testing.T) {
 				LastName:  "User",
 			},
 			setupMock: func(mockService *MockUserService) {
-				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: "Updated", LastName: "User"}).Return(nil, errors.New("database error"))
+				mockService.On("Update", ctx, validUUID, domainUser.UpdateUserParams{FirstName: stringPtr("Updated"), LastName: stringPtr("User")}).Return(nil, errors.New("database error"))
 			},
 			expectedCode: codes.Internal,
 		},
@@ -440,7 +607,7 @@ func TestUpdateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockUserService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -520,7 +687,7 @@ func TestUpdatePassword(t *testing.T) {
 				NewPassword:     "newpassword",
 			},
 			setupMock: func(mockService *MockUserService) {
-				mockService.On("UpdatePassword", ctx, validUUID, "oldpassword", "newpassword").Return(errors.New("user not found"))
+				mockService.On("UpdatePassword", ctx, validUUID, "oldpassword", "newpassword").Return(serviceUser.ErrUserNotFound)
 			},
 			expectedCode: codes.NotFound,
 		},
@@ -542,7 +709,7 @@ func TestUpdatePassword(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockUserService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -604,7 +771,7 @@ func TestDeleteUser(t *testing.T) {
 				Id: validUUID.String(),
 			},
 			setupMock: func(mockService *MockUserService) {
-				mockService.On("DeleteUser", ctx, validUUID).Return(errors.New("user not found"))
+				mockService.On("DeleteUser", ctx, validUUID).Return(serviceUser.ErrUserNotFound)
 			},
 			expectedCode: codes.NotFound,
 		},
@@ -624,7 +791,7 @@ func TestDeleteUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a fresh handler and mock for each test to avoid interference
 			mockService := new(MockUserService)
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, nil, nil, idgen.StrategyUUIDv4, logger)
 
 			// Setup the mock expectations
 			tt.setupMock(mockService)
@@ -648,47 +815,350 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
-// toProtoUser converts a domain user to a protobuf user
-func toProtoUser(user *domainUser.User) *userpb.User {
-	var createdAt, updatedAt *timestamppb.Timestamp
+func TestSetUserStatus(t *testing.T) {
+	adminID := uuid.New()
+	userID := uuid.New()
+	admin := &domainUser.User{ID: adminID, Role: rbac.RoleAdmin, IsActive: true}
+	adminCtx := requestctx.WithUserID(context.Background(), adminID)
 
-	if !user.CreatedAt.IsZero() {
-		createdAt = timestamppb.New(user.CreatedAt)
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *userpb.SetUserStatusRequest
+		mockSetup    func(users *MockUserService, accounts *MockAccountManager)
+		expectedCode codes.Code
+		expectActive bool
+	}{
+		{
+			name: "Activate",
+			ctx:  adminCtx,
+			req:  &userpb.SetUserStatusRequest{Id: userID.String(), IsActive: true},
+			mockSetup: func(users *MockUserService, accounts *MockAccountManager) {
+				users.On("GetByID", mock.Anything, adminID).Return(admin, nil).Once()
+				accounts.On("ActivateUser", mock.Anything, adminID, userID).Return(&domainUser.User{ID: userID, IsActive: true}, nil).Once()
+			},
+			expectedCode: codes.OK,
+			expectActive: true,
+		},
+		{
+			name: "Deactivate",
+			ctx:  adminCtx,
+			req:  &userpb.SetUserStatusRequest{Id: userID.String(), IsActive: false},
+			mockSetup: func(users *MockUserService, accounts *MockAccountManager) {
+				users.On("GetByID", mock.Anything, adminID).Return(admin, nil).Once()
+				accounts.On("DeactivateUser", mock.Anything, adminID, userID).Return(&domainUser.User{ID: userID}, nil).Once()
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Invalid ID",
+			ctx:          adminCtx,
+			req:          &userpb.SetUserStatusRequest{Id: "not-a-uuid"},
+			mockSetup:    func(users *MockUserService, accounts *MockAccountManager) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Unauthenticated",
+			ctx:          context.Background(),
+			req:          &userpb.SetUserStatusRequest{Id: userID.String()},
+			mockSetup:    func(users *MockUserService, accounts *MockAccountManager) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Not An Administrator",
+			ctx:  adminCtx,
+			req:  &userpb.SetUserStatusRequest{Id: userID.String(), IsActive: true},
+			mockSetup: func(users *MockUserService, accounts *MockAccountManager) {
+				users.On("GetByID", mock.Anything, adminID).Return(&domainUser.User{ID: adminID, Role: rbac.RoleUser, IsActive: true}, nil).Once()
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "User Not Found",
+			ctx:  adminCtx,
+			req:  &userpb.SetUserStatusRequest{Id: userID.String(), IsActive: true},
+			mockSetup: func(users *MockUserService, accounts *MockAccountManager) {
+				users.On("GetByID", mock.Anything, adminID).Return(admin, nil).Once()
+				accounts.On("ActivateUser", mock.Anything, adminID, userID).Return(nil, serviceUser.ErrUserNotFound).Once()
+			},
+			expectedCode: codes.NotFound,
+		},
 	}
 
-	if !user.UpdatedAt.IsZero() {
-		updatedAt = timestamppb.New(user.UpdatedAt)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			accounts := new(MockAccountManager)
+			tt.mockSetup(users, accounts)
+			handler := NewHandler(users, nil, accounts, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			resp, err := handler.SetUserStatus(tt.ctx, tt.req)
+
+			if tt.expectedCode == codes.OK {
+				assert.NoError(t, err)
+				assert.Equal(t, userID.String(), resp.User.Id)
+				assert.Equal(t, tt.expectActive, resp.User.IsActive)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.expectedCode, status.Code(err))
+				accounts.AssertNotCalled(t, "DeactivateUser", mock.Anything, mock.Anything, mock.Anything)
+			}
+			users.AssertExpectations(t)
+			accounts.AssertExpectations(t)
+		})
 	}
+}
 
-	return &userpb.User{
-		Id:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  true, // Assuming all users are active by default
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+func TestUserServer_GetProfile(t *testing.T) {
+	callerID := uuid.New()
+	otherID := uuid.New()
+	callerCtx := requestctx.WithUserID(context.Background(), callerID)
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		req          *userpb.GetProfileRequest
+		mockSetup    func(users *MockUserService)
+		expectedCode codes.Code
+	}{
+		{
+			name: "Own Profile By ID",
+			ctx:  callerCtx,
+			req:  &userpb.GetProfileRequest{Id: callerID.String()},
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByID", mock.Anything, callerID).Return(&domainUser.User{ID: callerID}, nil).Once()
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Own Profile Without ID",
+			ctx:  callerCtx,
+			req:  &userpb.GetProfileRequest{},
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByID", mock.Anything, callerID).Return(&domainUser.User{ID: callerID}, nil).Once()
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Another User",
+			ctx:          callerCtx,
+			req:          &userpb.GetProfileRequest{Id: otherID.String()},
+			mockSetup:    func(users *MockUserService) {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "Unauthenticated",
+			ctx:          context.Background(),
+			req:          &userpb.GetProfileRequest{},
+			mockSetup:    func(users *MockUserService) {},
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			tt.mockSetup(users)
+			server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			resp, err := server.GetProfile(tt.ctx, tt.req)
+
+			if tt.expectedCode == codes.OK {
+				assert.NoError(t, err)
+				assert.Equal(t, callerID.String(), resp.User.Id)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.expectedCode, status.Code(err))
+			}
+			users.AssertExpectations(t)
+		})
 	}
 }
 
-func TestToProtoUser(t *testing.T) {
-	now := time.Now()
-	testUUID := uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")
-	domainUser := &domainUser.User{
-		ID:        testUUID,
-		FirstName: "Test",
-		LastName:  "User",
-		Email:     "test@example.com",
-		CreatedAt: now,
-		UpdatedAt: now,
+func TestUserServer_UpdateProfileWithoutID(t *testing.T) {
+	callerID := uuid.New()
+	users := new(MockUserService)
+	users.On("Update", mock.Anything, callerID, domainUser.UpdateUserParams{FirstName: stringPtr("Jane")}).
+		Return(&domainUser.User{ID: callerID, FirstName: "Jane"}, nil).Once()
+	server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	resp, err := server.UpdateProfile(requestctx.WithUserID(context.Background(), callerID), &userpb.UpdateProfileRequest{FirstName: "Jane"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane", resp.User.FirstName)
+	users.AssertExpectations(t)
+}
+
+func TestUserServer_UpdateProfileMask(t *testing.T) {
+	callerID := uuid.New()
+	tests := []struct {
+		name         string
+		mask         []string
+		expected     *domainUser.UpdateUserParams
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Only Masked Fields Updated",
+			mask:         []string{"last_name"},
+			expected:     &domainUser.UpdateUserParams{LastName: stringPtr("Doe")},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Unknown Path",
+			mask:         []string{"is_active"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "Masked Field Cleared",
+			mask:         []string{"email"},
+			expected:     &domainUser.UpdateUserParams{Email: stringPtr("")},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Display Name Cleared",
+			mask:         []string{"display_name"},
+			expected:     &domainUser.UpdateUserParams{DisplayName: stringPtr("")},
+			expectedCode: codes.OK,
+		},
 	}
 
-	protoUser := toProtoUser(domainUser)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			if tt.expected != nil {
+				users.On("Update", mock.Anything, callerID, *tt.expected).
+					Return(&domainUser.User{ID: callerID, FirstName: "Jane", LastName: "Doe"}, nil).Once()
+			}
+			server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			_, err := server.UpdateProfile(requestctx.WithUserID(context.Background(), callerID), &userpb.UpdateProfileRequest{
+				FirstName:  "Janet", // Not in the mask, so left unchanged
+				LastName:   "Doe",
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: tt.mask},
+			})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			users.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserServer_ReadMask(t *testing.T) {
+	user := createMockUser()
+	callerCtx := requestctx.WithUserID(context.Background(), user.ID)
+
+	t.Run("Only Masked Fields Returned", func(t *testing.T) {
+		users := new(MockUserService)
+		users.On("GetByID", mock.Anything, user.ID).Return(user, nil).Once()
+		server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.GetProfile(callerCtx, &userpb.GetProfileRequest{ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"id", "email"}}})
+
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&userpb.User{Id: user.ID.String(), Email: user.Email}, resp.User), "got %v", resp.User)
+	})
+
+	t.Run("Unknown Path", func(t *testing.T) {
+		server := NewUserServer(new(MockUserService), nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		_, err := server.BatchGetUsers(callerCtx, &userpb.BatchGetUsersRequest{
+			Ids:      []string{user.ID.String()},
+			ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}},
+		})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUserServer_GetUserByEmail(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		mockSetup    func(users *MockUserService)
+		expectedCode codes.Code
+	}{
+		{
+			name:  "Success",
+			email: "test@example.com",
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByEmail", mock.Anything, "test@example.com").Return(createMockUser(), nil).Once()
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Empty Email",
+			mockSetup:    func(users *MockUserService) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:  "User Not Found",
+			email: "notfound@example.com",
+			mockSetup: func(users *MockUserService) {
+				users.On("GetByEmail", mock.Anything, "notfound@example.com").Return(nil, serviceUser.ErrUserNotFound).Once()
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserService)
+			tt.mockSetup(users)
+			server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			resp, err := server.GetUserByEmail(context.Background(), &userpb.GetUserByEmailRequest{Email: tt.email})
+
+			if tt.expectedCode == codes.OK {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.email, resp.User.Email)
+			} else {
+				assert.Nil(t, resp)
+				assert.Equal(t, tt.expectedCode, status.Code(err))
+			}
+			users.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserServer_BatchGetUsers(t *testing.T) {
+	user := createMockUser()
+	missingID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		users := new(MockUserService)
+		users.On("GetByIDs", mock.Anything, []uuid.UUID{user.ID, missingID, missingID}).
+			Return([]*domainUser.User{user}, nil).Once()
+		server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.BatchGetUsers(context.Background(), &userpb.BatchGetUsersRequest{
+			Ids: []string{user.ID.String(), missingID.String(), missingID.String()},
+		})
+
+		assert.NoError(t, err)
+		if assert.Len(t, resp.Users, 1) {
+			assert.Equal(t, user.ID.String(), resp.Users[0].Id)
+		}
+		assert.Equal(t, []string{missingID.String()}, resp.MissingIds)
+		users.AssertExpectations(t)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		server := NewUserServer(new(MockUserService), nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.BatchGetUsers(context.Background(), &userpb.BatchGetUsersRequest{Ids: []string{"not-an-id"}})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Too Many IDs", func(t *testing.T) {
+		users := new(MockUserService)
+		users.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, serviceUser.ErrTooManyIDs).Once()
+		server := NewUserServer(users, nil, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		resp, err := server.BatchGetUsers(context.Background(), &userpb.BatchGetUsersRequest{Ids: []string{user.ID.String()}})
 
-	assert.Equal(t, testUUID.String(), protoUser.Id)
-	assert.Equal(t, "test@example.com", protoUser.Email)
-	assert.Equal(t, "Test", protoUser.FirstName)
-	assert.Equal(t, "User", protoUser.LastName)
-	assert.Equal(t, now.Unix(), protoUser.CreatedAt.AsTime().Unix())
-	assert.Equal(t, now.Unix(), protoUser.UpdatedAt.AsTime().Unix())
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
 }
@@ -6,25 +6,52 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/timestamppb"
 
 	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
 	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
 )
 
+// AccountManager changes the status of accounts on behalf of an administrator.
+// serviceAdmin.AdminService satisfies it.
+type AccountManager interface {
+	ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+	DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error)
+}
+
+// Authenticator signs users in on behalf of the deprecated UserService.Login.
+// domainAuth.AuthService satisfies it.
+type Authenticator interface {
+	Login(ctx context.Context, input domainAuth.LoginInput) (*domainAuth.TokenPair, error)
+	ValidateToken(ctx context.Context, accessToken string) (uuid.UUID, error)
+}
+
 // UserServer implements the UserService gRPC service
 type UserServer struct {
 	userpb.UnimplementedUserServiceServer
 	userService serviceUser.UserService
+	auth        Authenticator
+	accounts    AccountManager
+	ids         idgen.Strategy // Text form of rendered IDs
 	logger      *zap.Logger
 }
 
 // NewUserServer creates a new UserServer
-func NewUserServer(userService serviceUser.UserService, logger *zap.Logger) *UserServer {
+func NewUserServer(userService serviceUser.UserService, auth Authenticator, accounts AccountManager, ids idgen.Strategy, logger *zap.Logger) *UserServer {
 	return &UserServer{
 		userService: userService,
+		auth:        auth,
+		accounts:    accounts,
+		ids:         ids,
 		logger:      logger,
 	}
 }
@@ -33,48 +60,77 @@ func NewUserServer(userService serviceUser.UserService, logger *zap.Logger) *Use
 func (s *UserServer) Register(ctx context.Context, req *userpb.RegisterRequest) (*userpb.UserResponse, error) {
 	s.logger.Info("Register request received", zap.String("email", req.Email))
 
-	userInput := domainUser.RegisterUserInput{
-		Email:     req.Email,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-	}
 	// Call the user service to register the user
-	user, err := s.userService.Register(ctx, userInput)
+	user, err := s.userService.Register(ctx, registerInput(ctx, req))
 	if err != nil {
 		s.logger.Error("User registration failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "user registration failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return s.userToResponse(user), nil
 }
 
-// Login authenticates a user
+// captchaMetadataKey carries the CAPTCHA token required when registration
+// CAPTCHA is enabled
+const captchaMetadataKey = "x-captcha-token"
+
+// registerInput converts a RegisterRequest into the input of the user
+// service, with the CAPTCHA token and client address of the call
+func registerInput(ctx context.Context, req *userpb.RegisterRequest) domainUser.RegisterUserInput {
+	input := mapper.RegisterRequestToDomain(req)
+	input.CaptchaToken = metadataValue(ctx, captchaMetadataKey)
+	input.ClientIP = clientip.FromContext(ctx)
+	return input
+}
+
+// metadataValue returns the first value of an incoming metadata key
+func metadataValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// Login authenticates a user. It signs in through the auth service, as
+// auth.v1.AuthService/Login does, so lockouts, CAPTCHA, password reset
+// requirements, sessions and login history apply to it too.
 func (s *UserServer) Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error) {
 	s.logger.Info("Login request received", zap.String("email", req.Email))
 
-	// Get the user by email
-	user, err := s.userService.GetByEmail(ctx, req.Email)
-	if err != nil {
-		s.logger.Error("User not found", zap.Error(err))
-		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+	if req.Email == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
 	}
 
-	// Check password
-	if !user.CheckPassword(req.Password) {
-		s.logger.Error("Invalid password")
-		return nil, status.Errorf(codes.Unauthenticated, "invalid credentials")
+	tokens, err := s.auth.Login(ctx, domainAuth.LoginInput{
+		Email:        req.Email,
+		Password:     req.Password,
+		UserAgent:    metadataValue(ctx, "user-agent"),
+		ClientIP:     clientip.FromContext(ctx),
+		CaptchaToken: metadataValue(ctx, captchaMetadataKey),
+	})
+	if err != nil {
+		s.logger.Error("Login failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
 	}
 
-	// In a real implementation, you would generate tokens here
-	// For now, we'll just return placeholders
-	accessToken := "placeholder-access-token"
-	refreshToken := "placeholder-refresh-token"
+	// The response describes the user the access token was issued to
+	userID, err := s.auth.ValidateToken(ctx, tokens.AccessToken)
+	if err != nil {
+		s.logger.Error("Issued access token failed validation", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("User lookup failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
 
 	return &userpb.LoginResponse{
-		User:         s.userToPb(user),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		User:         mapper.UserToPb(user, s.ids),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
@@ -82,45 +138,110 @@ func (s *UserServer) Login(ctx context.Context, req *userpb.LoginRequest) (*user
 func (s *UserServer) GetProfile(ctx context.Context, req *userpb.GetProfileRequest) (*userpb.UserResponse, error) {
 	s.logger.Info("GetProfile request received", zap.String("id", req.Id))
 
-	// Parse the ID string to UUID
-	id, err := uuid.Parse(req.Id)
+	id, err := s.profileID(ctx, req.Id)
 	if err != nil {
-		s.logger.Error("Invalid user ID format", zap.Error(err))
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+		return nil, err
+	}
+	if err := validateReadMask(req.ReadMask); err != nil {
+		return nil, err
 	}
 
 	// Call the user service to get the user profile
 	user, err := s.userService.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Get user profile failed", zap.Error(err))
-		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
-	return s.userToResponse(user), nil
+	resp := s.userToResponse(user)
+	applyReadMask(resp.User, req.ReadMask)
+	return resp, nil
+}
+
+// GetUserByEmail looks up a user by email address
+func (s *UserServer) GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest) (*userpb.UserResponse, error) {
+	s.logger.Info("GetUserByEmail request received", zap.String("email", req.Email))
+
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	if err := validateReadMask(req.ReadMask); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.GetByEmail(ctx, req.Email)
+	if err != nil {
+		s.logger.Error("Get user by email failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
+
+	resp := s.userToResponse(user)
+	applyReadMask(resp.User, req.ReadMask)
+	return resp, nil
+}
+
+// BatchGetUsers looks up several users by ID in one call. IDs no user has
+// are listed in missing_ids rather than failing the call.
+func (s *UserServer) BatchGetUsers(ctx context.Context, req *userpb.BatchGetUsersRequest) (*userpb.BatchGetUsersResponse, error) {
+	s.logger.Info("BatchGetUsers request received", zap.Int("ids", len(req.Ids)))
+
+	ids := make([]uuid.UUID, 0, len(req.Ids))
+	for _, raw := range req.Ids {
+		id, err := idgen.Parse(raw)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID format")
+		}
+		ids = append(ids, id)
+	}
+	if err := validateReadMask(req.ReadMask); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userService.GetByIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error("Batch get users failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
+	}
+
+	found := make(map[uuid.UUID]bool, len(users))
+	resp := &userpb.BatchGetUsersResponse{
+		Users:      make([]*userpb.User, 0, len(users)),
+		MissingIds: []string{},
+	}
+	for _, user := range users {
+		found[user.ID] = true
+		msg := mapper.UserToPb(user, s.ids)
+		applyReadMask(msg, req.ReadMask)
+		resp.Users = append(resp.Users, msg)
+	}
+	for _, id := range ids {
+		if !found[id] {
+			found[id] = true // List each missing ID once
+			resp.MissingIds = append(resp.MissingIds, s.ids.Format(id))
+		}
+	}
+	return resp, nil
 }
 
-// UpdateProfile updates a user profile
+// UpdateProfile updates a user profile. With an update mask only the named
+// fields are changed.
 func (s *UserServer) UpdateProfile(ctx context.Context, req *userpb.UpdateProfileRequest) (*userpb.UserResponse, error) {
 	s.logger.Info("UpdateProfile request received", zap.String("id", req.Id))
 
-	// Parse the ID string to UUID
-	id, err := uuid.Parse(req.Id)
+	id, err := s.profileID(ctx, req.Id)
 	if err != nil {
-		s.logger.Error("Invalid user ID format", zap.Error(err))
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+		return nil, err
 	}
 
-	updateParams := domainUser.UpdateUserParams{
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Email:     req.Email, // Assuming UpdateProfileRequest has Email
+	params, err := updateParams(req)
+	if err != nil {
+		return nil, err
 	}
 	// Call the user service to update the user profile
-	user, err := s.userService.Update(ctx, id, updateParams)
+	user, err := s.userService.Update(ctx, id, params)
 	if err != nil {
 		s.logger.Error("Update user profile failed", zap.Error(err))
-		// Consider handling specific errors from service.Update, e.g., ErrUserNotFound, ErrEmailInUse
-		return nil, status.Errorf(codes.Internal, "update user profile failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return s.userToResponse(user), nil
@@ -131,17 +252,20 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *userpb.DeleteUserReque
 	s.logger.Info("DeleteUser request received", zap.String("id", req.Id))
 
 	// Parse the ID string to UUID
-	id, err := uuid.Parse(req.Id)
+	id, err := idgen.Parse(req.Id)
 	if err != nil {
 		s.logger.Error("Invalid user ID format", zap.Error(err))
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
 	}
+	if err := authorizeSelf(ctx, id); err != nil {
+		return nil, err
+	}
 
 	// Call the user service to delete the user
 	err = s.userService.DeleteUser(ctx, id)
 	if err != nil {
 		s.logger.Error("Delete user failed", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "delete user failed: %v", err)
+		return nil, apperror.GRPCStatus(err)
 	}
 
 	return &userpb.DeleteUserResponse{
@@ -149,32 +273,98 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *userpb.DeleteUserReque
 	}, nil
 }
 
-// userToResponse converts a domain user to a user response
-func (s *UserServer) userToResponse(user *domainUser.User) *userpb.UserResponse {
-	return &userpb.UserResponse{
-		User: s.userToPb(user),
+// SetUserStatus activates or deactivates a user account
+func (s *UserServer) SetUserStatus(ctx context.Context, req *userpb.SetUserStatusRequest) (*userpb.UserResponse, error) {
+	s.logger.Info("SetUserStatus request received", zap.String("id", req.Id), zap.Bool("is_active", req.IsActive))
+
+	// Parse the ID string to UUID
+	id, err := idgen.Parse(req.Id)
+	if err != nil {
+		s.logger.Error("Invalid user ID format", zap.Error(err))
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+	actorID, err := s.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *domainUser.User
+	if req.IsActive {
+		user, err = s.accounts.ActivateUser(ctx, actorID, id)
+	} else {
+		user, err = s.accounts.DeactivateUser(ctx, actorID, id)
+	}
+	if err != nil {
+		s.logger.Error("Set user status failed", zap.Error(err))
+		return nil, apperror.GRPCStatus(err)
 	}
+
+	return s.userToResponse(user), nil
 }
 
-// userToPb converts a domain user to a protobuf user
-func (s *UserServer) userToPb(user *domainUser.User) *userpb.User {
-	var createdAt, updatedAt *timestamppb.Timestamp
+// authorizeAdmin ensures the authenticated caller is an active administrator
+// and returns their ID. The role is loaded on every call so demotions take
+// effect immediately.
+func (s *UserServer) authorizeAdmin(ctx context.Context) (uuid.UUID, error) {
+	callerID, ok := requestctx.UserID(ctx)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
 
-	if !user.CreatedAt.IsZero() {
-		createdAt = timestamppb.New(user.CreatedAt)
+	caller, err := s.userService.GetByID(ctx, callerID)
+	if err != nil {
+		// A user deleted after their token was issued is simply not authorized
+		if apperror.CodeOf(err) != apperror.CodeUserNotFound {
+			s.logger.Error("Failed to load user for role check", zap.Error(err))
+			return uuid.Nil, apperror.GRPCStatus(err)
+		}
+		caller = nil
+	}
+	if caller == nil || !caller.IsActive || caller.Role != rbac.RoleAdmin {
+		return uuid.Nil, status.Error(codes.PermissionDenied, "administrator role is required")
 	}
+	return callerID, nil
+}
 
-	if !user.UpdatedAt.IsZero() {
-		updatedAt = timestamppb.New(user.UpdatedAt)
+// profileID resolves the account a profile request is about: the caller's own
+// when id is empty, as on the /v1/profile routes, and otherwise id, which must
+// also be the caller's
+func (s *UserServer) profileID(ctx context.Context, rawID string) (uuid.UUID, error) {
+	if rawID == "" {
+		callerID, ok := requestctx.UserID(ctx)
+		if !ok {
+			return uuid.Nil, status.Error(codes.Unauthenticated, "authentication is required")
+		}
+		return callerID, nil
 	}
 
-	return &userpb.User{
-		Id:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  true, // Assuming all users are active by default
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+	// Parse the ID string to UUID
+	id, err := idgen.Parse(rawID)
+	if err != nil {
+		s.logger.Error("Invalid user ID format", zap.Error(err))
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid user ID format: %v", err)
+	}
+	if err := authorizeSelf(ctx, id); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// authorizeSelf ensures the authenticated caller is acting on their own account
+func authorizeSelf(ctx context.Context, id uuid.UUID) error {
+	callerID, ok := requestctx.UserID(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication is required")
+	}
+	if callerID != id {
+		return status.Error(codes.PermissionDenied, "cannot access another user's account")
+	}
+	return nil
+}
+
+// userToResponse converts a domain user to a user response
+func (s *UserServer) userToResponse(user *domainUser.User) *userpb.UserResponse {
+	return &userpb.UserResponse{
+		User: mapper.UserToPb(user, s.ids),
 	}
 }
@@ -0,0 +1,69 @@
+package account
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PageQuery pages an account center listing
+type PageQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// normalize returns the requested page and page size, applying defaults
+func (q PageQuery) normalize() (int, int) {
+	page, pageSize := q.Page, q.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	return page, pageSize
+}
+
+// ChangePasswordRequest defines the request body for changing the caller's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// SessionResponse describes a sign-in session of the caller
+type SessionResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type,omitempty"` // standard or remember_me; empty for sessions older than session types
+	Device    string    `json:"device"`
+	UserAgent string    `json:"userAgent"`
+	ClientIP  string    `json:"clientIp"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SecurityEventResponse describes an audited change made to the caller's
+// account. Details of changes made by administrators stay in the admin
+// audit log.
+type SecurityEventResponse struct {
+	ID              string          `json:"id"`
+	Action          string          `json:"action"`
+	ByAdministrator bool            `json:"byAdministrator"`    // Whether someone other than the caller made the change
+	Details         json.RawMessage `json:"details,omitempty"`  // e.g. the device and client IP of a new device sign-in
+	ClientIP        string          `json:"clientIp,omitempty"` // Where the caller made the change from
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// ReportSecurityEventRequest defines the optional request body for reporting a security event
+type ReportSecurityEventRequest struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+// LoginRecordResponse describes a sign-in attempt against the caller's account
+type LoginRecordResponse struct {
+	ID        string    `json:"id"`
+	Result    string    `json:"result"` // success, invalid_password, account_disabled, password_reset_required or password_expired
+	Succeeded bool      `json:"succeeded"`
+	Device    string    `json:"device"`
+	UserAgent string    `json:"userAgent"`
+	ClientIP  string    `json:"clientIp"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,364 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"github.com/yi-tech/go-user-service/internal/useragent"
+)
+
+// DefaultPageSize is used when a listing request does not specify page_size
+const DefaultPageSize = 20
+
+// PasswordChanger changes the password of a user who knows the current one.
+// serviceUser.UserService satisfies it.
+type PasswordChanger interface {
+	UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error
+}
+
+// SessionLister lists the sign-in sessions of a user. serviceAdmin.AdminService satisfies it.
+type SessionLister interface {
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error)
+}
+
+// AuditLister pages through the audit log. serviceAdmin.AdminService satisfies it.
+type AuditLister interface {
+	ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error)
+}
+
+// LoginHistoryLister pages through a user's sign-in attempts. serviceAdmin.AdminService satisfies it.
+type LoginHistoryLister interface {
+	ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error)
+}
+
+// SecurityEventReporter flags a security event of a user's account as
+// suspicious. serviceAdmin.AdminService satisfies it.
+type SecurityEventReporter interface {
+	ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error
+}
+
+// SessionRevoker ends every session of a user. domainAuth.AuthService satisfies it.
+type SessionRevoker interface {
+	Logout(ctx context.Context, userID uuid.UUID) error
+}
+
+// Handler handles the account center: the settings of the authenticated
+// user, always acting on the caller and never on a user named in the request
+type Handler struct {
+	passwords PasswordChanger
+	sessions  SessionLister
+	audit     AuditLister
+	history   LoginHistoryLister
+	reporter  SecurityEventReporter
+	revoker   SessionRevoker
+	ids       idgen.Strategy // Text form of rendered IDs
+	logger    *zap.Logger
+}
+
+// NewHandler creates a new account center handler
+func NewHandler(passwords PasswordChanger, sessions SessionLister, audit AuditLister, history LoginHistoryLister, reporter SecurityEventReporter, revoker SessionRevoker, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		passwords: passwords,
+		sessions:  sessions,
+		audit:     audit,
+		history:   history,
+		reporter:  reporter,
+		revoker:   revoker,
+		ids:       ids,
+		logger:    logger,
+	}
+}
+
+// ChangePassword handles changing the caller's password
+// @Summary Change password
+// @Description Change the password of the current user, who must confirm the current one
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.Response "Password updated successfully"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required or current password is incorrect"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/account/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if err := h.passwords.UpdatePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		h.handleError(c, "ChangePassword", err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Password updated successfully"})
+}
+
+// ListSessions handles listing the caller's sign-in sessions
+// @Summary List sessions
+// @Description List the unexpired sign-in sessions of the current user
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=response.PaginatedResponse{data=[]SessionResponse}} "Sessions"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/account/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessions.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, "ListSessions", err)
+		return
+	}
+
+	// Users hold a handful of sessions, so they are paged in memory
+	start := min((page-1)*pageSize, len(sessions))
+	end := min(start+pageSize, len(sessions))
+	data := make([]SessionResponse, 0, end-start)
+	for _, session := range sessions[start:end] {
+		data = append(data, SessionResponse{
+			ID:        session.ID,
+			Type:      string(session.Type),
+			Device:    session.DeviceLabel,
+			UserAgent: session.UserAgent,
+			ClientIP:  session.ClientIP,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+		})
+	}
+
+	response.Paginated(c, data, response.PageMeta{Page: page, PageSize: pageSize, Total: int64(len(sessions))})
+}
+
+// RevokeSessions handles signing the caller out everywhere
+// @Summary Revoke sessions
+// @Description End every session of the current user and invalidate their refresh token. Access tokens already issued stay valid until they expire.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Sessions revoked"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/account/sessions [delete]
+func (h *Handler) RevokeSessions(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.revoker.Logout(c.Request.Context(), userID); err != nil {
+		h.handleError(c, "RevokeSessions", err)
+		return
+	}
+
+	h.logger.Info("Sessions revoked by user", zap.String("user_id", userID.String()))
+	response.Success(c, gin.H{"message": "Sessions revoked"})
+}
+
+// ListSecurityEvents handles listing the audited changes made to the caller's account
+// @Summary List security events
+// @Description List the audited changes made to the current user's account, newest first: those made by administrators, such as forced password resets and deactivation, and the user's own password changes and sign-ins from new devices or IP addresses. Details and the client IP are only included for events the user raised.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=response.PaginatedResponse{data=[]SecurityEventResponse}} "Security events"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/profile/security-events [get]
+// @Router /api/v1/account/security-events [get]
+func (h *Handler) ListSecurityEvents(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	filter := domainAudit.ListFilter{TargetID: userID, Offset: (page - 1) * pageSize, Limit: pageSize}
+	entries, total, err := h.audit.ListAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListSecurityEvents", err)
+		return
+	}
+
+	data := make([]SecurityEventResponse, 0, len(entries))
+	for _, entry := range entries {
+		event := SecurityEventResponse{
+			ID:              h.ids.Format(entry.ID),
+			Action:          string(entry.Action),
+			ByAdministrator: entry.ActorID != userID,
+			CreatedAt:       entry.CreatedAt,
+		}
+		if !event.ByAdministrator {
+			event.ClientIP = entry.ClientIP
+			if entry.Details != "" {
+				event.Details = json.RawMessage(entry.Details)
+			}
+		}
+		data = append(data, event)
+	}
+
+	response.Paginated(c, data, response.PageMeta{Page: page, PageSize: pageSize, Total: total})
+}
+
+// ReportSecurityEvent handles the caller flagging one of their security events as suspicious
+// @Summary Report security event
+// @Description Report a security event of the current user's account, such as a sign-in from a new device, as activity the user does not recognize. The report is recorded in the audit log for administrators to follow up; it does not sign anyone out, which DELETE /api/v1/account/sessions does.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Security event ID"
+// @Param request body ReportSecurityEventRequest false "Optional comment"
+// @Success 200 {object} response.Response "Security event reported"
+// @Failure 400 {object} response.Response "Invalid security event ID or request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 404 {object} response.Response "Security event not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/profile/security-events/{id}/report [post]
+// @Router /api/v1/account/security-events/{id}/report [post]
+func (h *Handler) ReportSecurityEvent(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	eventID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid security event ID")
+		return
+	}
+
+	var req ReportSecurityEventRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request data")
+			return
+		}
+	}
+
+	if err := h.reporter.ReportSecurityEvent(c.Request.Context(), userID, eventID, req.Comment); err != nil {
+		h.handleError(c, "ReportSecurityEvent", err)
+		return
+	}
+
+	h.logger.Warn("Suspicious activity reported by user",
+		zap.String("user_id", userID.String()),
+		zap.String("event_id", eventID.String()))
+	response.Success(c, gin.H{"message": "Security event reported"})
+}
+
+// ListLoginHistory handles listing the sign-in attempts made against the caller's account
+// @Summary List login history
+// @Description List the successful and failed sign-in attempts made against the current user's account, newest first, so unrecognized ones can be spotted
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=response.PaginatedResponse{data=[]LoginRecordResponse}} "Login history"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/profile/login-history [get]
+// @Router /api/v1/account/login-history [get]
+func (h *Handler) ListLoginHistory(c *gin.Context) {
+	userID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	filter := domainAuth.LoginHistoryFilter{UserID: userID, Offset: (page - 1) * pageSize, Limit: pageSize}
+	records, total, err := h.history.ListLoginHistory(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListLoginHistory", err)
+		return
+	}
+
+	data := make([]LoginRecordResponse, 0, len(records))
+	for _, record := range records {
+		data = append(data, LoginRecordResponse{
+			ID:        h.ids.Format(record.ID),
+			Result:    string(record.Result),
+			Succeeded: record.Succeeded(),
+			Device:    useragent.Label(record.UserAgent),
+			UserAgent: record.UserAgent,
+			ClientIP:  record.ClientIP,
+			CreatedAt: record.CreatedAt,
+		})
+	}
+
+	response.Paginated(c, data, response.PageMeta{Page: page, PageSize: pageSize, Total: total})
+}
+
+// caller returns the authenticated user, writing an error response if there is none
+func (h *Handler) caller(c *gin.Context) (uuid.UUID, bool) {
+	id, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// bindPage reads the requested page, writing an error response if it is invalid
+func bindPage(c *gin.Context) (int, int, bool) {
+	var query PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return 0, 0, false
+	}
+	page, pageSize := query.normalize()
+	return page, pageSize, true
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *Handler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Account operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
@@ -0,0 +1,304 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// stubAccount implements every dependency of the handler, recording the calls it receives
+type stubAccount struct {
+	passwordErr error
+	sessions    []*domainAuth.Session
+	entries     []*domainAudit.Entry
+	filter      domainAudit.ListFilter
+	logins      []*domainAuth.LoginRecord
+	loginFilter domainAuth.LoginHistoryFilter
+	loggedOut   uuid.UUID
+	reportErr   error
+	reported    uuid.UUID
+	comment     string
+}
+
+func (s *stubAccount) UpdatePassword(ctx context.Context, id uuid.UUID, currentPassword, newPassword string) error {
+	return s.passwordErr
+}
+
+func (s *stubAccount) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	return s.sessions, nil
+}
+
+func (s *stubAccount) ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	s.filter = filter
+	return s.entries, int64(len(s.entries)), nil
+}
+
+func (s *stubAccount) ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	s.loginFilter = filter
+	return s.logins, int64(len(s.logins)), nil
+}
+
+func (s *stubAccount) ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error {
+	s.reported = eventID
+	s.comment = comment
+	return s.reportErr
+}
+
+func (s *stubAccount) Logout(ctx context.Context, userID uuid.UUID) error {
+	s.loggedOut = userID
+	return nil
+}
+
+var (
+	testUserID  = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testAdminID = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testTime    = time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+)
+
+// serve routes a request to the handler method as the test user, or anonymously
+func serve(t *testing.T, stub *stubAccount, method, path, body string, handle func(*Handler) gin.HandlerFunc, authenticated bool) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(stub, stub, stub, stub, stub, stub, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.Handle(method, "/api/v1/account/*rest", func(c *gin.Context) {
+		if authenticated {
+			requestctx.SetUserID(c, testUserID)
+		}
+	}, handle(handler))
+
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		passwordErr    error
+		anonymous      bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			body:           `{"currentPassword":"old-password","newPassword":"new-password"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"message":"Password updated successfully"}}`,
+		},
+		{
+			name:           "Too Short",
+			body:           `{"currentPassword":"old-password","newPassword":"short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:           "Incorrect Current Password",
+			body:           `{"currentPassword":"wrong","newPassword":"new-password"}`,
+			passwordErr:    serviceUser.ErrIncorrectPassword,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":401,"message":"incorrect current password","errorCode":"INCORRECT_PASSWORD"}`,
+		},
+		{
+			name:           "Unexpected Error",
+			body:           `{"currentPassword":"old-password","newPassword":"new-password"}`,
+			passwordErr:    errors.New("database down"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+		{
+			name:           "Not Authenticated",
+			body:           `{"currentPassword":"old-password","newPassword":"new-password"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":401,"message":"User not authenticated"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubAccount{passwordErr: tt.passwordErr}
+			rr := serve(t, stub, http.MethodPut, "/api/v1/account/password", tt.body,
+				func(h *Handler) gin.HandlerFunc { return h.ChangePassword }, !tt.anonymous)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	stub := &stubAccount{}
+	for i := 0; i < 3; i++ {
+		stub.sessions = append(stub.sessions, &domainAuth.Session{
+			ID:          uuid.NewString(),
+			UserID:      testUserID,
+			DeviceLabel: "Chrome on macOS",
+			CreatedAt:   testTime,
+			ExpiresAt:   testTime.Add(time.Hour),
+		})
+	}
+	last := stub.sessions[2].ID
+
+	rr := serve(t, stub, http.MethodGet, "/api/v1/account/sessions?page=2&page_size=2", "",
+		func(h *Handler) gin.HandlerFunc { return h.ListSessions }, true)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[{"id":"`+last+`","device":"Chrome on macOS","userAgent":"","clientIp":"","createdAt":"2025-06-28T09:00:00Z","expiresAt":"2025-06-28T10:00:00Z"}],"page":2,"pageSize":2,"total":3,"totalPages":2}}`, rr.Body.String())
+
+	t.Run("Page Past The End", func(t *testing.T) {
+		rr := serve(t, stub, http.MethodGet, "/api/v1/account/sessions?page=5", "",
+			func(h *Handler) gin.HandlerFunc { return h.ListSessions }, true)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[],"page":5,"pageSize":20,"total":3,"totalPages":1}}`, rr.Body.String())
+	})
+
+	t.Run("Invalid Page Size", func(t *testing.T) {
+		rr := serve(t, stub, http.MethodGet, "/api/v1/account/sessions?page_size=500", "",
+			func(h *Handler) gin.HandlerFunc { return h.ListSessions }, true)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRevokeSessions(t *testing.T) {
+	stub := &stubAccount{}
+	rr := serve(t, stub, http.MethodDelete, "/api/v1/account/sessions", "",
+		func(h *Handler) gin.HandlerFunc { return h.RevokeSessions }, true)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, testUserID, stub.loggedOut)
+}
+
+func TestListSecurityEvents(t *testing.T) {
+	eventID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	stub := &stubAccount{entries: []*domainAudit.Entry{{
+		ID:        eventID,
+		ActorID:   testAdminID,
+		Action:    domainAudit.ActionForcePasswordReset,
+		TargetID:  testUserID,
+		Details:   `{"reason":"leaked"}`,
+		ClientIP:  "10.1.2.3",
+		CreatedAt: testTime,
+	}}}
+
+	rr := serve(t, stub, http.MethodGet, "/api/v1/account/security-events?page=3&page_size=10", "",
+		func(h *Handler) gin.HandlerFunc { return h.ListSecurityEvents }, true)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, domainAudit.ListFilter{TargetID: testUserID, Offset: 20, Limit: 10}, stub.filter)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[{"id":"33333333-3333-3333-3333-333333333333","action":"user.force_password_reset","byAdministrator":true,"createdAt":"2025-06-28T09:00:00Z"}],"page":3,"pageSize":10,"total":1,"totalPages":1}}`, rr.Body.String())
+}
+
+func TestListSecurityEvents_OwnEventDetails(t *testing.T) {
+	stub := &stubAccount{entries: []*domainAudit.Entry{{
+		ID:        uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+		ActorID:   testUserID,
+		Action:    domainAudit.ActionNewDeviceLogin,
+		TargetID:  testUserID,
+		Details:   `{"device":"Firefox on Windows","user_agent":"Mozilla/5.0","client_ip":"198.51.100.2"}`,
+		ClientIP:  "198.51.100.2",
+		CreatedAt: testTime,
+	}}}
+
+	rr := serve(t, stub, http.MethodGet, "/api/v1/account/security-events", "",
+		func(h *Handler) gin.HandlerFunc { return h.ListSecurityEvents }, true)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[{"id":"33333333-3333-3333-3333-333333333333","action":"user.new_device_login","byAdministrator":false,"details":{"device":"Firefox on Windows","user_agent":"Mozilla/5.0","client_ip":"198.51.100.2"},"clientIp":"198.51.100.2","createdAt":"2025-06-28T09:00:00Z"}],"page":1,"pageSize":20,"total":1,"totalPages":1}}`, rr.Body.String())
+}
+
+func TestReportSecurityEvent(t *testing.T) {
+	eventID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	report := func(stub *stubAccount, id, body string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		handler := NewHandler(stub, stub, stub, stub, stub, stub, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+		rr := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(rr)
+		router.POST("/api/v1/account/security-events/:id/report", func(c *gin.Context) {
+			requestctx.SetUserID(c, testUserID)
+		}, handler.ReportSecurityEvent)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/account/security-events/"+id+"/report", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		stub := &stubAccount{}
+
+		rr := report(stub, eventID.String(), `{"comment":"not me"}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, eventID, stub.reported)
+		assert.Equal(t, "not me", stub.comment)
+	})
+
+	t.Run("Without Body", func(t *testing.T) {
+		stub := &stubAccount{}
+
+		rr := report(stub, eventID.String(), "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, eventID, stub.reported)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		stub := &stubAccount{}
+
+		rr := report(stub, "not-an-id", "")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, uuid.Nil, stub.reported)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		stub := &stubAccount{reportErr: serviceAdmin.ErrSecurityEventNotFound}
+
+		rr := report(stub, eventID.String(), "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"security event not found","errorCode":"SECURITY_EVENT_NOT_FOUND"}`, rr.Body.String())
+	})
+}
+
+func TestListLoginHistory(t *testing.T) {
+	recordID := uuid.MustParse("44444444-4444-4444-4444-444444444444")
+	stub := &stubAccount{logins: []*domainAuth.LoginRecord{{
+		ID:        recordID,
+		UserID:    testUserID,
+		Result:    domainAuth.LoginSucceeded,
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+		ClientIP:  "203.0.113.7",
+		CreatedAt: testTime,
+	}}}
+
+	rr := serve(t, stub, http.MethodGet, "/api/v1/account/login-history?page=2&page_size=5", "",
+		func(h *Handler) gin.HandlerFunc { return h.ListLoginHistory }, true)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, domainAuth.LoginHistoryFilter{UserID: testUserID, Offset: 5, Limit: 5}, stub.loginFilter)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[{"id":"44444444-4444-4444-4444-444444444444","result":"success","succeeded":true,"device":"Chrome on macOS","userAgent":"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36","clientIp":"203.0.113.7","createdAt":"2025-06-28T09:00:00Z"}],"page":2,"pageSize":5,"total":1,"totalPages":1}}`, rr.Body.String())
+}
@@ -0,0 +1,660 @@
+package admin
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	"github.com/yi-tech/go-user-service/internal/transport/http/links"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"github.com/yi-tech/go-user-service/internal/useragent"
+)
+
+// DefaultPageSize is used when a listing request does not specify page_size
+const DefaultPageSize = 20
+
+// AccountHandler handles HTTP requests for administrative account management
+type AccountHandler struct {
+	adminService serviceAdmin.AdminService
+	ids          idgen.Strategy // Text form of rendered IDs
+	logger       *zap.Logger
+}
+
+// NewAccountHandler creates a new account management handler
+func NewAccountHandler(adminService serviceAdmin.AdminService, ids idgen.Strategy, logger *zap.Logger) *AccountHandler {
+	return &AccountHandler{
+		adminService: adminService,
+		ids:          ids,
+		logger:       logger,
+	}
+}
+
+// ListUsers handles listing user accounts
+// @Summary List users
+// @Description List user accounts, newest first, optionally filtered
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param q query string false "Substring of the email, username or name"
+// @Param role query string false "Role"
+// @Param status query string false "Account status" Enums(active, inactive)
+// @Param metadata[key] query string false "Custom attribute the users must have, e.g. metadata[plan]=pro; repeatable"
+// @Success 200 {object} response.Response{data=UserListResponse} "Users"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users [get]
+func (h *AccountHandler) ListUsers(c *gin.Context) {
+	var query UserListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	query.bindMetadata(c)
+	page, pageSize := query.normalize()
+
+	filter := query.toFilter()
+	filter.Offset = (page - 1) * pageSize
+	filter.Limit = pageSize
+	users, total, err := h.adminService.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListUsers", err)
+		return
+	}
+
+	data := make([]AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		data = append(data, h.toAdminUserResponse(user))
+	}
+
+	response.Success(c, UserListResponse{Users: data, Total: total, Page: page, PageSize: pageSize})
+}
+
+// ForcePasswordReset handles requiring a user to choose a new password
+// @Summary Force password reset
+// @Description Require the user to choose a new password and sign them out of every session
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=AdminUserResponse} "Password reset required"
+// @Failure 400 {object} response.Response "Invalid user ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/password-reset [post]
+func (h *AccountHandler) ForcePasswordReset(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ForcePasswordReset(c.Request.Context(), actorID, userID)
+	if err != nil {
+		h.handleError(c, "ForcePasswordReset", err)
+		return
+	}
+
+	h.logger.Info("Password reset forced",
+		zap.String("operation", "ForcePasswordReset"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()))
+
+	response.Success(c, h.toAdminUserResponse(user))
+}
+
+// ExpirePassword handles expiring the password of a user
+// @Summary Expire password
+// @Description Expire the password of the user now. Their sessions are kept, but signing in with the password fails with PASSWORD_EXPIRED until they choose a new one through POST /api/v1/auth/password-reset.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=AdminUserResponse} "Password expired"
+// @Failure 400 {object} response.Response "Invalid user ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/expire-password [post]
+func (h *AccountHandler) ExpirePassword(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ExpirePassword(c.Request.Context(), actorID, userID)
+	if err != nil {
+		h.handleError(c, "ExpirePassword", err)
+		return
+	}
+
+	h.logger.Info("Password expired",
+		zap.String("operation", "ExpirePassword"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()))
+
+	response.Success(c, h.toAdminUserResponse(user))
+}
+
+// DeactivateUser handles disabling a user account
+// @Summary Deactivate user
+// @Description Disable the account and sign the user out of every session. Deprecated: use PATCH /api/v1/users/{id}/status.
+// @Deprecated
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=AdminUserResponse} "User deactivated"
+// @Failure 400 {object} response.Response "Invalid user ID format or self-deactivation"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/deactivate [post]
+func (h *AccountHandler) DeactivateUser(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.DeactivateUser(c.Request.Context(), actorID, userID)
+	if err != nil {
+		h.handleError(c, "DeactivateUser", err)
+		return
+	}
+
+	h.logger.Info("User deactivated",
+		zap.String("operation", "DeactivateUser"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()))
+
+	response.Success(c, h.toAdminUserResponse(user))
+}
+
+// SetUserStatus handles activating or deactivating a user account
+// @Summary Set user status
+// @Description Activate or deactivate an account. Deactivating signs the user out of every session.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body UserStatusRequest true "Account status"
+// @Success 200 {object} response.Response{data=AdminUserResponse} "User status updated"
+// @Failure 400 {object} response.Response "Invalid request data or self-deactivation"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/users/{id}/status [patch]
+func (h *AccountHandler) SetUserStatus(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	var req UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	var (
+		user *domainUser.User
+		err  error
+	)
+	if *req.IsActive {
+		user, err = h.adminService.ActivateUser(c.Request.Context(), actorID, userID)
+	} else {
+		user, err = h.adminService.DeactivateUser(c.Request.Context(), actorID, userID)
+	}
+	if err != nil {
+		h.handleError(c, "SetUserStatus", err)
+		return
+	}
+
+	h.logger.Info("User status changed",
+		zap.String("operation", "SetUserStatus"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()),
+		zap.Bool("is_active", user.IsActive))
+
+	response.Success(c, h.toAdminUserResponse(user))
+}
+
+// ListSessions handles listing the active sessions of a user
+// @Summary List user sessions
+// @Description List the active sign-in sessions of a user, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]SessionResponse} "Sessions"
+// @Failure 400 {object} response.Response "Invalid user ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/sessions [get]
+func (h *AccountHandler) ListSessions(c *gin.Context) {
+	userID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	sessions, err := h.adminService.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, "ListSessions", err)
+		return
+	}
+
+	data := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		data = append(data, toSessionResponse(session))
+	}
+
+	response.Success(c, data)
+}
+
+// ListLoginHistory handles listing the sign-in attempts made against a user's account
+// @Summary List user login history
+// @Description List the successful and failed sign-in attempts made against a user's account, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=LoginHistoryListResponse} "Login history"
+// @Failure 400 {object} response.Response "Invalid user ID format or query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/login-history [get]
+func (h *AccountHandler) ListLoginHistory(c *gin.Context) {
+	userID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return
+	}
+	var query PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	page, pageSize := query.normalize()
+
+	filter := domainAuth.LoginHistoryFilter{UserID: userID, Offset: (page - 1) * pageSize, Limit: pageSize}
+	records, total, err := h.adminService.ListLoginHistory(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListLoginHistory", err)
+		return
+	}
+
+	data := make([]LoginRecordResponse, 0, len(records))
+	for _, record := range records {
+		data = append(data, h.toLoginRecordResponse(record))
+	}
+
+	response.Success(c, LoginHistoryListResponse{Entries: data, Total: total, Page: page, PageSize: pageSize})
+}
+
+// InspectAuthKeys handles inspecting the auth-related Redis keys of a user
+// @Summary Inspect user auth keys
+// @Description Describe the Redis keys holding the refresh token and sessions of a user, to debug token problems. Refresh tokens are shown as fingerprints. Every inspection is audited.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=[]AuthKeyResponse} "Auth keys"
+// @Failure 400 {object} response.Response "Invalid user ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/auth-keys [get]
+func (h *AccountHandler) InspectAuthKeys(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.adminService.InspectAuthKeys(c.Request.Context(), actorID, userID)
+	if err != nil {
+		h.handleError(c, "InspectAuthKeys", err)
+		return
+	}
+
+	data := make([]AuthKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		data = append(data, toAuthKeyResponse(key))
+	}
+
+	response.Success(c, data)
+}
+
+// ListAuditLogs handles listing audit log entries
+// @Summary List audit logs
+// @Description List audit log entries, newest first, optionally filtered by actor, target or action
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Param actor_id query string false "Only entries by this administrator"
+// @Param target_id query string false "Only entries about this user"
+// @Param action query string false "Only entries with this action"
+// @Success 200 {object} response.Response{data=AuditLogListResponse} "Audit log entries"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/audit-logs [get]
+func (h *AccountHandler) ListAuditLogs(c *gin.Context) {
+	var query AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	actorID, actorErr := parseOptionalID(query.ActorID)
+	targetID, targetErr := parseOptionalID(query.TargetID)
+	if actorErr != nil || targetErr != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	page, pageSize := query.normalize()
+
+	filter := domainAudit.ListFilter{
+		ActorID:  actorID,
+		TargetID: targetID,
+		Action:   domainAudit.Action(query.Action),
+		Offset:   (page - 1) * pageSize,
+		Limit:    pageSize,
+	}
+
+	entries, total, err := h.adminService.ListAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListAuditLogs", err)
+		return
+	}
+
+	data := make([]AuditLogResponse, 0, len(entries))
+	for _, entry := range entries {
+		data = append(data, h.toAuditLogResponse(entry))
+	}
+
+	response.Success(c, AuditLogListResponse{Entries: data, Total: total, Page: page, PageSize: pageSize})
+}
+
+// ImpersonateUser handles minting a token acting as a user
+// @Summary Impersonate user
+// @Description Mint a short-lived access token acting as the user, to reproduce what they see. The token carries the administrator in its act claim, cannot be refreshed and is only accepted by the HTTP API; every request made with it is audited. Administrators cannot be impersonated.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body ImpersonationRequest true "Reason for the impersonation"
+// @Success 201 {object} response.Response{data=ImpersonationTokenResponse} "Impersonation token"
+// @Failure 400 {object} response.Response "Invalid request data, user ID format or self-impersonation"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required, or the user is an administrator"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/{id}/impersonate [post]
+func (h *AccountHandler) ImpersonateUser(c *gin.Context) {
+	actorID, userID, ok := h.actorAndTarget(c)
+	if !ok {
+		return
+	}
+
+	var req ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	token, err := h.adminService.ImpersonateUser(c.Request.Context(), actorID, userID, strings.TrimSpace(req.Reason))
+	if err != nil {
+		h.handleError(c, "ImpersonateUser", err)
+		return
+	}
+
+	h.logger.Info("User impersonation started",
+		zap.String("operation", "ImpersonateUser"),
+		zap.String("actor_id", actorID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("impersonation_id", token.Impersonation.ID.String()))
+
+	response.Created(c, "Impersonation started", ImpersonationTokenResponse{
+		ImpersonationResponse: h.toImpersonationResponse(token.Impersonation),
+		AccessToken:           token.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             int64(time.Until(token.Impersonation.ExpiresAt).Seconds()),
+	})
+}
+
+// RevokeImpersonation handles ending an impersonation
+// @Summary Revoke impersonation
+// @Description Reject the token of an impersonation from now on, before it expires. Revoking an impersonation again has no further effect.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Impersonation ID"
+// @Success 200 {object} response.Response{data=ImpersonationResponse} "Impersonation revoked"
+// @Failure 400 {object} response.Response "Invalid impersonation ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Impersonation not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/impersonations/{id} [delete]
+func (h *AccountHandler) RevokeImpersonation(c *gin.Context) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	impersonationID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid impersonation ID format")
+		return
+	}
+
+	impersonation, err := h.adminService.RevokeImpersonation(c.Request.Context(), actorUUID, impersonationID)
+	if err != nil {
+		h.handleError(c, "RevokeImpersonation", err)
+		return
+	}
+
+	h.logger.Info("User impersonation revoked",
+		zap.String("operation", "RevokeImpersonation"),
+		zap.String("actor_id", actorUUID.String()),
+		zap.String("impersonation_id", impersonationID.String()))
+
+	response.Success(c, h.toImpersonationResponse(impersonation))
+}
+
+// actorAndTarget extracts the acting administrator from the context and the
+// target user from the path, writing an error response if either is missing
+func (h *AccountHandler) actorAndTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return actorUUID, userID, true
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *AccountHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Admin operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+// parseOptionalID parses an ID filter, returning uuid.Nil when it is empty
+func parseOptionalID(value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	return idgen.Parse(value)
+}
+
+// normalize applies the default page and page size
+func (q PageQuery) normalize() (page, pageSize int) {
+	page, pageSize = q.Page, q.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	return page, pageSize
+}
+
+// bindMetadata reads the metadata[key]=value parameters of c
+func (q *UserFilterQuery) bindMetadata(c *gin.Context) {
+	if metadata := c.QueryMap("metadata"); len(metadata) > 0 {
+		q.Metadata = metadata
+	}
+}
+
+// toFilter converts the query into the criteria of a user listing
+func (q UserFilterQuery) toFilter() domainUser.ListFilter {
+	filter := domainUser.ListFilter{
+		Query:    strings.TrimSpace(q.Query),
+		Role:     rbac.Role(q.Role),
+		Metadata: q.Metadata,
+	}
+	if q.Status != "" {
+		active := q.Status == "active"
+		filter.Active = &active
+	}
+	return filter
+}
+
+func (h *AccountHandler) toAdminUserResponse(user *domainUser.User) AdminUserResponse {
+	var passwordChangedAt *time.Time
+	if !user.PasswordChangedAt.IsZero() {
+		passwordChangedAt = &user.PasswordChangedAt
+	}
+	id := h.ids.Format(user.ID)
+	return AdminUserResponse{
+		ID:                    id,
+		Email:                 user.Email,
+		Username:              user.Username,
+		FirstName:             user.FirstName,
+		LastName:              user.LastName,
+		DisplayName:           user.DisplayName,
+		Role:                  string(user.Role),
+		IsActive:              user.IsActive,
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordChangedAt:     passwordChangedAt,
+		PasswordExpiresAt:     user.PasswordExpiresAt,
+		Metadata:              user.Metadata,
+		CreatedAt:             user.CreatedAt,
+		UpdatedAt:             user.UpdatedAt,
+		Links: AdminUserLinks{
+			Self:     links.API.Link("users", id),
+			Sessions: links.Admin.Link("users", id, "sessions"),
+		},
+	}
+}
+
+func toSessionResponse(session *domainAuth.Session) SessionResponse {
+	return SessionResponse{
+		ID:        session.ID,
+		Type:      string(session.Type),
+		Device:    session.DeviceLabel,
+		UserAgent: session.UserAgent,
+		ClientIP:  session.ClientIP,
+		CreatedAt: session.CreatedAt,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+func (h *AccountHandler) toLoginRecordResponse(record *domainAuth.LoginRecord) LoginRecordResponse {
+	return LoginRecordResponse{
+		ID:        h.ids.Format(record.ID),
+		Result:    string(record.Result),
+		Succeeded: record.Succeeded(),
+		Device:    useragent.Label(record.UserAgent),
+		UserAgent: record.UserAgent,
+		ClientIP:  record.ClientIP,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+func toAuthKeyResponse(key *domainAuth.KeyInfo) AuthKeyResponse {
+	resp := AuthKeyResponse{
+		Key:       key.Key,
+		Namespace: key.Namespace,
+		Type:      key.Type,
+		Exists:    key.Exists(),
+		Value:     key.Value,
+		Fields:    key.Fields,
+	}
+	if key.TTL > 0 {
+		ttl := int64(key.TTL.Round(time.Second) / time.Second)
+		resp.TTLSeconds = &ttl
+	}
+	return resp
+}
+
+func (h *AccountHandler) toImpersonationResponse(impersonation *domainAuth.Impersonation) ImpersonationResponse {
+	return ImpersonationResponse{
+		ID:             h.ids.Format(impersonation.ID),
+		ImpersonatorID: h.ids.Format(impersonation.ActorID),
+		UserID:         h.ids.Format(impersonation.SubjectID),
+		Reason:         impersonation.Reason,
+		ExpiresAt:      impersonation.ExpiresAt,
+		RevokedAt:      impersonation.RevokedAt,
+		CreatedAt:      impersonation.CreatedAt,
+	}
+}
+
+func (h *AccountHandler) toAuditLogResponse(entry *domainAudit.Entry) AuditLogResponse {
+	resp := AuditLogResponse{
+		ID:        h.ids.Format(entry.ID),
+		ActorID:   h.ids.Format(entry.ActorID),
+		Action:    string(entry.Action),
+		Details:   entry.Details,
+		ClientIP:  entry.ClientIP,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.TargetID != uuid.Nil {
+		resp.TargetID = h.ids.Format(entry.TargetID)
+	}
+	return resp
+}
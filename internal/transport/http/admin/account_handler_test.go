@@ -0,0 +1,641 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainAudit "github.com/yi-tech/go-user-service/internal/domain/audit"
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAdmin "github.com/yi-tech/go-user-service/internal/service/admin"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+	serviceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// MockAdminService is a mock implementation of serviceAdmin.AdminService
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainUser.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAdminService) ListUsersAfter(ctx context.Context, filter domainUser.ListFilter, afterID uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, filter, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
+func (m *MockAdminService) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockAdminService) ExpirePassword(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockAdminService) DeactivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockAdminService) ActivateUser(ctx context.Context, actorID, userID uuid.UUID) (*domainUser.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockAdminService) DeleteUser(ctx context.Context, actorID, userID uuid.UUID) error {
+	args := m.Called(ctx, actorID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*domainAuth.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAuth.Session), args.Error(1)
+}
+
+func (m *MockAdminService) InspectAuthKeys(ctx context.Context, actorID, userID uuid.UUID) ([]*domainAuth.KeyInfo, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAuth.KeyInfo), args.Error(1)
+}
+
+func (m *MockAdminService) ListAuditLogs(ctx context.Context, filter domainAudit.ListFilter) ([]*domainAudit.Entry, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainAudit.Entry), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAdminService) ListLoginHistory(ctx context.Context, filter domainAuth.LoginHistoryFilter) ([]*domainAuth.LoginRecord, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*domainAuth.LoginRecord), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAdminService) ImpersonateUser(ctx context.Context, actorID, userID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	args := m.Called(ctx, actorID, userID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.ImpersonationToken), args.Error(1)
+}
+
+func (m *MockAdminService) RevokeImpersonation(ctx context.Context, actorID, impersonationID uuid.UUID) (*domainAuth.Impersonation, error) {
+	args := m.Called(ctx, actorID, impersonationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.Impersonation), args.Error(1)
+}
+
+func (m *MockAdminService) ReportSecurityEvent(ctx context.Context, userID, eventID uuid.UUID, comment string) error {
+	args := m.Called(ctx, userID, eventID, comment)
+	return args.Error(0)
+}
+
+var (
+	testActorID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testUserID  = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testTime    = time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+)
+
+// serveAccount routes a single request to the handler with the admin identity set
+func serveAccount(t *testing.T, method, route, target string, handler func(h *AccountHandler) gin.HandlerFunc, setup func(m *MockAdminService)) *httptest.ResponseRecorder {
+	return serveAccountBody(t, method, route, target, "", handler, setup)
+}
+
+// serveAccountBody is serveAccount for requests with a JSON body
+func serveAccountBody(t *testing.T, method, route, target, body string, handler func(h *AccountHandler) gin.HandlerFunc, setup func(m *MockAdminService)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAdminService)
+	setup(mockService)
+	h := NewAccountHandler(mockService, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.Handle(method, route, func(c *gin.Context) {
+		requestctx.SetUserID(c, testActorID)
+	}, handler(h))
+
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rr, req)
+
+	mockService.AssertExpectations(t)
+	return rr
+}
+
+func TestAccountHandler_ListUsers(t *testing.T) {
+	listUsers := func(h *AccountHandler) gin.HandlerFunc { return h.ListUsers }
+
+	t.Run("Default Paging", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users", listUsers, func(m *MockAdminService) {
+			m.On("ListUsers", mock.Anything, domainUser.ListFilter{Offset: 0, Limit: DefaultPageSize}).Return([]*domainUser.User{
+				{
+					ID:        testUserID,
+					Email:     "user@example.com",
+					Username:  "user@example.com",
+					Role:      domainRBAC.RoleUser,
+					IsActive:  true,
+					CreatedAt: testTime,
+					UpdatedAt: testTime,
+				},
+			}, int64(1), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"users":[{"id":"22222222-2222-2222-2222-222222222222","email":"user@example.com","username":"user@example.com","role":"user","isActive":true,"passwordResetRequired":false,"createdAt":"2025-06-20T12:00:00Z","updatedAt":"2025-06-20T12:00:00Z","_links":{"self":{"href":"/api/v1/users/22222222-2222-2222-2222-222222222222"},"sessions":{"href":"/admin/v1/users/22222222-2222-2222-2222-222222222222/sessions"}}}],"total":1,"page":1,"pageSize":20}}`, rr.Body.String())
+	})
+
+	t.Run("Explicit Page", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users?page=3&page_size=10", listUsers, func(m *MockAdminService) {
+			m.On("ListUsers", mock.Anything, domainUser.ListFilter{Offset: 20, Limit: 10}).Return([]*domainUser.User{}, int64(5), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"users":[],"total":5,"page":3,"pageSize":10}}`, rr.Body.String())
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		inactive := false
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users?q=+Ada+&role=support&status=inactive", listUsers, func(m *MockAdminService) {
+			m.On("ListUsers", mock.Anything, domainUser.ListFilter{
+				Query:  "Ada",
+				Role:   domainRBAC.RoleSupport,
+				Active: &inactive,
+				Limit:  DefaultPageSize,
+			}).Return([]*domainUser.User{}, int64(0), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Metadata", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users?metadata[plan]=pro&metadata[team]=core", listUsers, func(m *MockAdminService) {
+			m.On("ListUsers", mock.Anything, domainUser.ListFilter{
+				Metadata: map[string]string{"plan": "pro", "team": "core"},
+				Limit:    DefaultPageSize,
+			}).Return([]*domainUser.User{}, int64(0), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid Status", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users?status=deleted", listUsers, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid query parameters"}`, rr.Body.String())
+	})
+
+	t.Run("Page Size Too Large", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users?page_size=500", listUsers, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid query parameters"}`, rr.Body.String())
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/users", "/admin/v1/users", listUsers, func(m *MockAdminService) {
+			m.On("ListUsers", mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("db error"))
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.JSONEq(t, `{"code":500,"message":"Something went wrong. Please try again later."}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_DeactivateUser(t *testing.T) {
+	deactivate := func(h *AccountHandler) gin.HandlerFunc { return h.DeactivateUser }
+	route := "/admin/v1/users/:id/deactivate"
+
+	t.Run("Success", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodPost, route, "/admin/v1/users/"+testUserID.String()+"/deactivate", deactivate, func(m *MockAdminService) {
+			m.On("DeactivateUser", mock.Anything, testActorID, testUserID).Return(&domainUser.User{
+				ID:        testUserID,
+				Email:     "user@example.com",
+				Username:  "user@example.com",
+				Role:      domainRBAC.RoleUser,
+				CreatedAt: testTime,
+				UpdatedAt: testTime,
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"22222222-2222-2222-2222-222222222222","email":"user@example.com","username":"user@example.com","role":"user","isActive":false,"passwordResetRequired":false,"createdAt":"2025-06-20T12:00:00Z","updatedAt":"2025-06-20T12:00:00Z","_links":{"self":{"href":"/api/v1/users/22222222-2222-2222-2222-222222222222"},"sessions":{"href":"/admin/v1/users/22222222-2222-2222-2222-222222222222/sessions"}}}}`, rr.Body.String())
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodPost, route, "/admin/v1/users/not-a-uuid/deactivate", deactivate, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid user ID format"}`, rr.Body.String())
+	})
+
+	t.Run("Self Deactivation", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodPost, route, "/admin/v1/users/"+testActorID.String()+"/deactivate", deactivate, func(m *MockAdminService) {
+			m.On("DeactivateUser", mock.Anything, testActorID, testActorID).Return(nil, serviceAdmin.ErrSelfDeactivation)
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"administrators cannot deactivate their own account","errorCode":"INVALID_ARGUMENT"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_SetUserStatus(t *testing.T) {
+	setStatus := func(h *AccountHandler) gin.HandlerFunc { return h.SetUserStatus }
+	route := "/api/v1/users/:id/status"
+	target := "/api/v1/users/" + testUserID.String() + "/status"
+	user := func(active bool) *domainUser.User {
+		return &domainUser.User{
+			ID:        testUserID,
+			Email:     "user@example.com",
+			Username:  "user@example.com",
+			Role:      domainRBAC.RoleUser,
+			IsActive:  active,
+			CreatedAt: testTime,
+			UpdatedAt: testTime,
+		}
+	}
+
+	t.Run("Activate", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPatch, route, target, `{"isActive":true}`, setStatus, func(m *MockAdminService) {
+			m.On("ActivateUser", mock.Anything, testActorID, testUserID).Return(user(true), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"22222222-2222-2222-2222-222222222222","email":"user@example.com","username":"user@example.com","role":"user","isActive":true,"passwordResetRequired":false,"createdAt":"2025-06-20T12:00:00Z","updatedAt":"2025-06-20T12:00:00Z","_links":{"self":{"href":"/api/v1/users/22222222-2222-2222-2222-222222222222"},"sessions":{"href":"/admin/v1/users/22222222-2222-2222-2222-222222222222/sessions"}}}}`, rr.Body.String())
+	})
+
+	t.Run("Deactivate", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPatch, route, target, `{"isActive":false}`, setStatus, func(m *MockAdminService) {
+			m.On("DeactivateUser", mock.Anything, testActorID, testUserID).Return(user(false), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"22222222-2222-2222-2222-222222222222","email":"user@example.com","username":"user@example.com","role":"user","isActive":false,"passwordResetRequired":false,"createdAt":"2025-06-20T12:00:00Z","updatedAt":"2025-06-20T12:00:00Z","_links":{"self":{"href":"/api/v1/users/22222222-2222-2222-2222-222222222222"},"sessions":{"href":"/admin/v1/users/22222222-2222-2222-2222-222222222222/sessions"}}}}`, rr.Body.String())
+	})
+
+	t.Run("Missing Status", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPatch, route, target, `{}`, setStatus, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid request data"}`, rr.Body.String())
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPatch, route, target, `{"isActive":true}`, setStatus, func(m *MockAdminService) {
+			m.On("ActivateUser", mock.Anything, testActorID, testUserID).Return(nil, serviceUser.ErrUserNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"user not found","errorCode":"USER_NOT_FOUND"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_ForcePasswordReset(t *testing.T) {
+	rr := serveAccount(t, http.MethodPost, "/admin/v1/users/:id/password-reset", "/admin/v1/users/"+testUserID.String()+"/password-reset",
+		func(h *AccountHandler) gin.HandlerFunc { return h.ForcePasswordReset },
+		func(m *MockAdminService) {
+			m.On("ForcePasswordReset", mock.Anything, testActorID, testUserID).Return(nil, serviceUser.ErrUserNotFound)
+		})
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"code":404,"message":"user not found","errorCode":"USER_NOT_FOUND"}`, rr.Body.String())
+}
+
+func TestAccountHandler_ExpirePassword(t *testing.T) {
+	expire := func(h *AccountHandler) gin.HandlerFunc { return h.ExpirePassword }
+	route := "/admin/v1/users/:id/expire-password"
+	target := "/admin/v1/users/" + testUserID.String() + "/expire-password"
+
+	t.Run("Success", func(t *testing.T) {
+		expiresAt := testTime.Add(time.Hour)
+		rr := serveAccount(t, http.MethodPost, route, target, expire, func(m *MockAdminService) {
+			m.On("ExpirePassword", mock.Anything, testActorID, testUserID).Return(&domainUser.User{
+				ID:                testUserID,
+				Email:             "user@example.com",
+				Username:          "user@example.com",
+				Role:              domainRBAC.RoleUser,
+				IsActive:          true,
+				PasswordChangedAt: testTime,
+				PasswordExpiresAt: &expiresAt,
+				CreatedAt:         testTime,
+				UpdatedAt:         testTime,
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"22222222-2222-2222-2222-222222222222","email":"user@example.com","username":"user@example.com","role":"user","isActive":true,"passwordResetRequired":false,"passwordChangedAt":"2025-06-20T12:00:00Z","passwordExpiresAt":"2025-06-20T13:00:00Z","createdAt":"2025-06-20T12:00:00Z","updatedAt":"2025-06-20T12:00:00Z","_links":{"self":{"href":"/api/v1/users/22222222-2222-2222-2222-222222222222"},"sessions":{"href":"/admin/v1/users/22222222-2222-2222-2222-222222222222/sessions"}}}}`, rr.Body.String())
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodPost, route, target, expire, func(m *MockAdminService) {
+			m.On("ExpirePassword", mock.Anything, testActorID, testUserID).Return(nil, serviceUser.ErrUserNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"user not found","errorCode":"USER_NOT_FOUND"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_ListSessions(t *testing.T) {
+	rr := serveAccount(t, http.MethodGet, "/admin/v1/users/:id/sessions", "/admin/v1/users/"+testUserID.String()+"/sessions",
+		func(h *AccountHandler) gin.HandlerFunc { return h.ListSessions },
+		func(m *MockAdminService) {
+			m.On("ListSessions", mock.Anything, testUserID).Return([]*domainAuth.Session{
+				{
+					ID:          "session-1",
+					UserID:      testUserID,
+					UserAgent:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Chrome/125.0.0.0 Safari/537.36",
+					DeviceLabel: "Chrome on macOS",
+					ClientIP:    "203.0.113.7",
+					CreatedAt:   testTime,
+					ExpiresAt:   testTime.Add(24 * time.Hour),
+				},
+			}, nil)
+		})
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":[{"id":"session-1","device":"Chrome on macOS","userAgent":"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Chrome/125.0.0.0 Safari/537.36","clientIp":"203.0.113.7","createdAt":"2025-06-20T12:00:00Z","expiresAt":"2025-06-21T12:00:00Z"}]}`, rr.Body.String())
+}
+
+func TestAccountHandler_ListLoginHistory(t *testing.T) {
+	listLoginHistory := func(h *AccountHandler) gin.HandlerFunc { return h.ListLoginHistory }
+	route := "/admin/v1/users/:id/login-history"
+	target := "/admin/v1/users/" + testUserID.String() + "/login-history"
+	recordID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	t.Run("Success", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, target+"?page=2&page_size=1", listLoginHistory, func(m *MockAdminService) {
+			m.On("ListLoginHistory", mock.Anything, domainAuth.LoginHistoryFilter{UserID: testUserID, Offset: 1, Limit: 1}).Return([]*domainAuth.LoginRecord{
+				{
+					ID:        recordID,
+					UserID:    testUserID,
+					Result:    domainAuth.LoginInvalidPassword,
+					UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+					ClientIP:  "203.0.113.7",
+					CreatedAt: testTime,
+				},
+			}, int64(2), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"entries":[{"id":"33333333-3333-3333-3333-333333333333","result":"invalid_password","succeeded":false,"device":"Chrome on macOS","userAgent":"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36","clientIp":"203.0.113.7","createdAt":"2025-06-20T12:00:00Z"}],"total":2,"page":2,"pageSize":1}}`, rr.Body.String())
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, target, listLoginHistory, func(m *MockAdminService) {
+			m.On("ListLoginHistory", mock.Anything, mock.Anything).Return(nil, int64(0), serviceUser.ErrUserNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, "/admin/v1/users/not-an-id/login-history", listLoginHistory, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAccountHandler_InspectAuthKeys(t *testing.T) {
+	inspectAuthKeys := func(h *AccountHandler) gin.HandlerFunc { return h.InspectAuthKeys }
+	route := "/admin/v1/users/:id/auth-keys"
+	target := "/admin/v1/users/" + testUserID.String() + "/auth-keys"
+
+	t.Run("Success", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, target, inspectAuthKeys, func(m *MockAdminService) {
+			m.On("InspectAuthKeys", mock.Anything, testActorID, testUserID).Return([]*domainAuth.KeyInfo{
+				{
+					Key:       "go-user-service:prod:auth:v1:user:" + testUserID.String() + ":refresh",
+					Namespace: domainAuth.KeyNamespaceRefreshToken,
+					Type:      "string",
+					TTL:       90 * time.Second,
+					Value:     "sha256:0123456789ab",
+				},
+				{
+					Key:       "go-user-service:prod:auth:v1:user:" + testUserID.String() + ":sessions",
+					Namespace: domainAuth.KeyNamespaceSessions,
+					Type:      "none",
+					TTL:       -2,
+				},
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":[{"key":"go-user-service:prod:auth:v1:user:22222222-2222-2222-2222-222222222222:refresh","namespace":"refresh_token","type":"string","exists":true,"ttlSeconds":90,"value":"sha256:0123456789ab"},{"key":"go-user-service:prod:auth:v1:user:22222222-2222-2222-2222-222222222222:sessions","namespace":"sessions","type":"none","exists":false}]}`, rr.Body.String())
+	})
+
+	t.Run("User Not Found", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, target, inspectAuthKeys, func(m *MockAdminService) {
+			m.On("InspectAuthKeys", mock.Anything, testActorID, testUserID).Return(nil, serviceUser.ErrUserNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"user not found","errorCode":"USER_NOT_FOUND"}`, rr.Body.String())
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, route, "/admin/v1/users/nope/auth-keys", inspectAuthKeys, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid user ID format"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_ImpersonateUser(t *testing.T) {
+	impersonateUser := func(h *AccountHandler) gin.HandlerFunc { return h.ImpersonateUser }
+	route := "/admin/v1/users/:id/impersonate"
+	target := "/admin/v1/users/" + testUserID.String() + "/impersonate"
+	impersonationID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	t.Run("Success", func(t *testing.T) {
+		expiresAt := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+		rr := serveAccountBody(t, http.MethodPost, route, target, `{"reason":" ticket 42 "}`, impersonateUser, func(m *MockAdminService) {
+			m.On("ImpersonateUser", mock.Anything, testActorID, testUserID, "ticket 42").Return(&domainAuth.ImpersonationToken{
+				Impersonation: &domainAuth.Impersonation{
+					ID:        impersonationID,
+					ActorID:   testActorID,
+					SubjectID: testUserID,
+					Reason:    "ticket 42",
+					ExpiresAt: expiresAt,
+					CreatedAt: testTime,
+				},
+				AccessToken: "impersonation-token",
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var body struct {
+			Data ImpersonationTokenResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, impersonationID.String(), body.Data.ID)
+		assert.Equal(t, testActorID.String(), body.Data.ImpersonatorID)
+		assert.Equal(t, testUserID.String(), body.Data.UserID)
+		assert.Equal(t, "ticket 42", body.Data.Reason)
+		assert.Equal(t, "impersonation-token", body.Data.AccessToken)
+		assert.Equal(t, "Bearer", body.Data.TokenType)
+		assert.InDelta(t, 30*60, body.Data.ExpiresIn, 2)
+		assert.True(t, expiresAt.Equal(body.Data.ExpiresAt))
+	})
+
+	t.Run("Reason Required", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPost, route, target, `{}`, impersonateUser, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid request data"}`, rr.Body.String())
+	})
+
+	t.Run("Administrator", func(t *testing.T) {
+		rr := serveAccountBody(t, http.MethodPost, route, target, `{"reason":"ticket 42"}`, impersonateUser, func(m *MockAdminService) {
+			m.On("ImpersonateUser", mock.Anything, testActorID, testUserID, "ticket 42").Return(nil, serviceAdmin.ErrAdminImpersonation)
+		})
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.JSONEq(t, `{"code":403,"message":"administrators cannot be impersonated","errorCode":"PERMISSION_DENIED"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_RevokeImpersonation(t *testing.T) {
+	revokeImpersonation := func(h *AccountHandler) gin.HandlerFunc { return h.RevokeImpersonation }
+	route := "/admin/v1/impersonations/:id"
+	impersonationID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	target := "/admin/v1/impersonations/" + impersonationID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodDelete, route, target, revokeImpersonation, func(m *MockAdminService) {
+			m.On("RevokeImpersonation", mock.Anything, testActorID, impersonationID).Return(&domainAuth.Impersonation{
+				ID:        impersonationID,
+				ActorID:   testActorID,
+				SubjectID: testUserID,
+				Reason:    "ticket 42",
+				ExpiresAt: testTime.Add(30 * time.Minute),
+				RevokedAt: &testTime,
+				CreatedAt: testTime,
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"33333333-3333-3333-3333-333333333333","impersonatorId":"11111111-1111-1111-1111-111111111111","userId":"22222222-2222-2222-2222-222222222222","reason":"ticket 42","expiresAt":"2025-06-20T12:30:00Z","revokedAt":"2025-06-20T12:00:00Z","createdAt":"2025-06-20T12:00:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodDelete, route, target, revokeImpersonation, func(m *MockAdminService) {
+			m.On("RevokeImpersonation", mock.Anything, testActorID, impersonationID).Return(nil, serviceAuth.ErrImpersonationNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"impersonation not found","errorCode":"IMPERSONATION_NOT_FOUND"}`, rr.Body.String())
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodDelete, route, "/admin/v1/impersonations/nope", revokeImpersonation, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid impersonation ID format"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_ListAuditLogs(t *testing.T) {
+	listAuditLogs := func(h *AccountHandler) gin.HandlerFunc { return h.ListAuditLogs }
+
+	t.Run("Filtered", func(t *testing.T) {
+		target := "/admin/v1/audit-logs?target_id=" + testUserID.String() + "&action=user.deactivate"
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/audit-logs", target, listAuditLogs, func(m *MockAdminService) {
+			m.On("ListAuditLogs", mock.Anything, domainAudit.ListFilter{
+				TargetID: testUserID,
+				Action:   domainAudit.ActionDeactivateUser,
+				Limit:    DefaultPageSize,
+			}).Return([]*domainAudit.Entry{
+				{
+					ID:        uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+					ActorID:   testActorID,
+					Action:    domainAudit.ActionDeactivateUser,
+					TargetID:  testUserID,
+					ClientIP:  "203.0.113.7",
+					CreatedAt: testTime,
+				},
+			}, int64(1), nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"entries":[{"id":"33333333-3333-3333-3333-333333333333","actorId":"11111111-1111-1111-1111-111111111111","action":"user.deactivate","targetId":"22222222-2222-2222-2222-222222222222","clientIp":"203.0.113.7","createdAt":"2025-06-20T12:00:00Z"}],"total":1,"page":1,"pageSize":20}}`, rr.Body.String())
+	})
+
+	t.Run("Invalid Actor ID", func(t *testing.T) {
+		rr := serveAccount(t, http.MethodGet, "/admin/v1/audit-logs", "/admin/v1/audit-logs?actor_id=nope", listAuditLogs, func(m *MockAdminService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid query parameters"}`, rr.Body.String())
+	})
+}
+
+func TestAccountHandler_ULIDStrategy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAdminService)
+	mockService.On("ListAuditLogs", mock.Anything, domainAudit.ListFilter{
+		ActorID: testActorID,
+		Limit:   DefaultPageSize,
+	}).Return([]*domainAudit.Entry{
+		{ID: testUserID, ActorID: testActorID, Action: domainAudit.ActionDeactivateUser, CreatedAt: testTime},
+	}, int64(1), nil)
+	h := NewAccountHandler(mockService, idgen.StrategyULID, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/admin/v1/audit-logs", h.ListAuditLogs)
+	req, _ := http.NewRequest(http.MethodGet, "/admin/v1/audit-logs?actor_id="+idgen.FormatULID(testActorID), nil)
+	router.ServeHTTP(rr, req)
+
+	// ULID filters are accepted and IDs are rendered as ULIDs
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"entries":[{"id":"`+idgen.FormatULID(testUserID)+`","actorId":"`+idgen.FormatULID(testActorID)+`","action":"user.deactivate","createdAt":"2025-06-20T12:00:00Z"}],"total":1,"page":1,"pageSize":20}}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
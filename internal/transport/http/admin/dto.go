@@ -0,0 +1,351 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/yi-tech/go-user-service/internal/transport/http/links"
+)
+
+// RoleResponse describes a role and the permissions it grants
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionResponse describes a permission and the roles that grant it
+type PermissionResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+}
+
+// KeyRotationResponse describes the outcome of a signing key rotation
+type KeyRotationResponse struct {
+	KeyID         string `json:"keyId"`
+	PreviousKeyID string `json:"previousKeyId"`
+}
+
+// PageQuery selects a page of a listing
+type PageQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// UserFilterQuery selects users by the criteria shared by the user listing and export
+type UserFilterQuery struct {
+	Query  string `form:"q" binding:"omitempty,max=100"` // Substring of the email, username or name
+	Role   string `form:"role" binding:"omitempty,max=32"`
+	Status string `form:"status" binding:"omitempty,oneof=active inactive"`
+	// Metadata holds the metadata[key]=value parameters; users must have
+	// every one of these attributes. Form binding cannot read them, so the
+	// handlers fill it in with bindMetadata.
+	Metadata map[string]string `form:"-"`
+}
+
+// UserListQuery filters and pages the user listing
+type UserListQuery struct {
+	PageQuery
+	UserFilterQuery
+}
+
+// UserExportQuery filters and shapes a user export
+type UserExportQuery struct {
+	UserFilterQuery
+	Format  string `form:"format" binding:"omitempty,oneof=csv json"`
+	Columns string `form:"columns"` // Comma-separated, validated by the export service
+}
+
+// AuditLogQuery filters the audit log listing
+type AuditLogQuery struct {
+	PageQuery
+	ActorID  string `form:"actor_id"`  // UUID or ULID, parsed by the handler
+	TargetID string `form:"target_id"` // UUID or ULID, parsed by the handler
+	Action   string `form:"action" binding:"omitempty,max=64"`
+}
+
+// AdminUserResponse describes a user account as seen by administrators
+type AdminUserResponse struct {
+	ID                    string            `json:"id"`
+	Email                 string            `json:"email"`
+	Username              string            `json:"username"`
+	FirstName             string            `json:"firstName,omitempty"`
+	LastName              string            `json:"lastName,omitempty"`
+	DisplayName           string            `json:"displayName,omitempty"`
+	Role                  string            `json:"role"`
+	IsActive              bool              `json:"isActive"`
+	PasswordResetRequired bool              `json:"passwordResetRequired"`
+	PasswordChangedAt     *time.Time        `json:"passwordChangedAt,omitempty"`
+	PasswordExpiresAt     *time.Time        `json:"passwordExpiresAt,omitempty"` // Set when an administrator expired the password
+	Metadata              map[string]string `json:"metadata,omitempty"`
+	CreatedAt             time.Time         `json:"createdAt"`
+	UpdatedAt             time.Time         `json:"updatedAt"`
+	Links                 AdminUserLinks    `json:"_links"`
+}
+
+// AdminUserLinks are the resources related to a user account that
+// administrators can follow
+type AdminUserLinks struct {
+	Self     links.Link `json:"self"`
+	Sessions links.Link `json:"sessions"`
+}
+
+// UserStatusRequest activates or deactivates a user account
+type UserStatusRequest struct {
+	IsActive *bool `json:"isActive" binding:"required"`
+}
+
+// UserListResponse is a page of user accounts
+type UserListResponse struct {
+	Users    []AdminUserResponse `json:"users"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+}
+
+// SessionResponse describes an active sign-in session
+type SessionResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type,omitempty"` // standard or remember_me; empty for sessions older than session types
+	Device    string    `json:"device"`
+	UserAgent string    `json:"userAgent"`
+	ClientIP  string    `json:"clientIp"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AuthKeyResponse describes an auth-related Redis key. Refresh tokens are
+// shown only as fingerprints.
+type AuthKeyResponse struct {
+	Key        string            `json:"key"`
+	Namespace  string            `json:"namespace"`
+	Type       string            `json:"type"`
+	Exists     bool              `json:"exists"`
+	TTLSeconds *int64            `json:"ttlSeconds,omitempty"` // Omitted when the key does not expire
+	Value      string            `json:"value,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+// AuditLogResponse describes an audit log entry
+type AuditLogResponse struct {
+	ID        string    `json:"id"`
+	ActorID   string    `json:"actorId"`
+	Action    string    `json:"action"`
+	TargetID  string    `json:"targetId,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	ClientIP  string    `json:"clientIp,omitempty"` // Where the request came from; omitted for background jobs
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditLogListResponse is a page of audit log entries
+type AuditLogListResponse struct {
+	Entries  []AuditLogResponse `json:"entries"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"pageSize"`
+}
+
+// LoginRecordResponse describes a sign-in attempt against an account
+type LoginRecordResponse struct {
+	ID        string    `json:"id"`
+	Result    string    `json:"result"`
+	Succeeded bool      `json:"succeeded"`
+	Device    string    `json:"device"`
+	UserAgent string    `json:"userAgent"`
+	ClientIP  string    `json:"clientIp"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LoginHistoryListResponse is a page of a user's sign-in attempts
+type LoginHistoryListResponse struct {
+	Entries  []LoginRecordResponse `json:"entries"`
+	Total    int64                 `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"pageSize"`
+}
+
+// ImpersonationRequest asks for a token acting as a user
+type ImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // Why the administrator needs to act as the user; kept in the audit log
+}
+
+// ImpersonationResponse describes an impersonation of a user by an administrator
+type ImpersonationResponse struct {
+	ID             string     `json:"id"`
+	ImpersonatorID string     `json:"impersonatorId"`
+	UserID         string     `json:"userId"`
+	Reason         string     `json:"reason"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// ImpersonationTokenResponse is a new impersonation with its access token.
+// The token cannot be refreshed and is only accepted by the HTTP API.
+type ImpersonationTokenResponse struct {
+	ImpersonationResponse
+	AccessToken string `json:"accessToken"`
+	TokenType   string `json:"tokenType"`
+	ExpiresIn   int64  `json:"expiresIn"` // Access token expiry time in seconds
+}
+
+// ReadOnlyRequest turns read-only mode on or off
+type ReadOnlyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// ReadOnlyResponse describes the current read-only mode
+type ReadOnlyResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// LogLevelRequest changes the log level of one module
+type LogLevelRequest struct {
+	Module string `json:"module"` // app (default), http, grpc or gorm
+	Level  string `json:"level" binding:"required"`
+}
+
+// LogLevelsResponse lists the log level of each module
+type LogLevelsResponse struct {
+	Levels map[string]string `json:"levels"`
+}
+
+// ImportQuery selects how an uploaded import file is processed
+type ImportQuery struct {
+	Async bool `form:"async"` // Import in the background even when the file is small
+}
+
+// ImportRowErrorResponse explains why a row of an import file was skipped
+type ImportRowErrorResponse struct {
+	Row     int    `json:"row"`
+	Email   string `json:"email,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResultResponse summarises a completed import
+type ImportResultResponse struct {
+	Total           int                      `json:"total"`
+	Imported        int                      `json:"imported"`
+	Failed          int                      `json:"failed"`
+	Errors          []ImportRowErrorResponse `json:"errors"`
+	ErrorsTruncated bool                     `json:"errorsTruncated,omitempty"`
+}
+
+// ExportResultResponse summarises a completed export
+type ExportResultResponse struct {
+	Exported int `json:"exported"`
+	Withheld int `json:"withheld"` // Users the residency policy kept out of the export
+}
+
+// ExportJobResponse describes an export generated in the background
+type ExportJobResponse struct {
+	ID         string                `json:"id"`
+	Status     string                `json:"status"`
+	Format     string                `json:"format"`
+	Result     *ExportResultResponse `json:"result,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	FinishedAt *time.Time            `json:"finishedAt,omitempty"`
+}
+
+// ImportJobResponse describes an import running in the background
+type ImportJobResponse struct {
+	ID         string                `json:"id"`
+	Status     string                `json:"status"`
+	Result     *ImportResultResponse `json:"result,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	FinishedAt *time.Time            `json:"finishedAt,omitempty"`
+}
+
+// FeatureFlagRequest sets the value of a feature flag
+type FeatureFlagRequest struct {
+	Enabled     *bool    `json:"enabled" binding:"required"` // On for everyone
+	Tenants     []string `json:"tenants" binding:"max=100"`  // On for these tenants even when enabled is false
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// FeatureFlagResponse describes a feature flag
+type FeatureFlagResponse struct {
+	Name        string     `json:"name"`
+	Enabled     bool       `json:"enabled"`
+	Tenants     []string   `json:"tenants"`
+	Description string     `json:"description,omitempty"`
+	Default     bool       `json:"default"`             // Value configured for this environment, used while the flag is not stored
+	Stored      bool       `json:"stored"`              // Whether the value was set through the admin API
+	UpdatedBy   string     `json:"updatedBy,omitempty"` // Administrator who last set the flag
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// WebhookRequest registers or replaces a webhook subscription
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	Secret      string   `json:"secret" binding:"max=255"`             // Signs deliveries; empty generates one on create and keeps the current one on update
+	EventTypes  []string `json:"eventTypes" binding:"required,max=10"` // user.registered, user.updated or user.deleted
+	Description string   `json:"description" binding:"max=255"`
+	Active      *bool    `json:"active"` // Defaults to true
+}
+
+// WebhookResponse describes a webhook subscription
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"` // Only returned when the subscription is created
+	EventTypes  []string  `json:"eventTypes"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookDeliveryResponse describes one attempt to deliver an event
+type WebhookDeliveryResponse struct {
+	ID         string    `json:"id"`
+	EventID    string    `json:"eventId"`
+	EventType  string    `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	Succeeded  bool      `json:"succeeded"`
+	StatusCode int       `json:"statusCode,omitempty"` // Absent when the endpoint could not be reached
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// WebhookDeliveryListResponse is a page of the delivery log of a subscription
+type WebhookDeliveryListResponse struct {
+	Entries  []WebhookDeliveryResponse `json:"entries"`
+	Total    int64                     `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"pageSize"`
+}
+
+// SAMLProviderRequest creates or replaces the SAML identity provider of a tenant
+type SAMLProviderRequest struct {
+	EntityID           string `json:"entityId" binding:"required,max=1024"`
+	SSOURL             string `json:"ssoUrl" binding:"max=2048"`
+	Certificate        string `json:"certificate" binding:"required"`   // PEM, or the base64 of the DER as found in identity provider metadata
+	EmailAttribute     string `json:"emailAttribute" binding:"max=255"` // Empty takes the email from the name identifier
+	FirstNameAttribute string `json:"firstNameAttribute" binding:"max=255"`
+	LastNameAttribute  string `json:"lastNameAttribute" binding:"max=255"`
+	JITProvisioning    bool   `json:"jitProvisioning"` // Create the accounts of users signing in for the first time
+	Enabled            *bool  `json:"enabled"`         // Defaults to true
+}
+
+// SAMLProviderResponse describes the SAML identity provider of a tenant along
+// with the service provider values to configure it with
+type SAMLProviderResponse struct {
+	Tenant             string    `json:"tenant"`
+	EntityID           string    `json:"entityId"`
+	SSOURL             string    `json:"ssoUrl,omitempty"`
+	Certificate        string    `json:"certificate"`
+	EmailAttribute     string    `json:"emailAttribute,omitempty"`
+	FirstNameAttribute string    `json:"firstNameAttribute,omitempty"`
+	LastNameAttribute  string    `json:"lastNameAttribute,omitempty"`
+	JITProvisioning    bool      `json:"jitProvisioning"`
+	Enabled            bool      `json:"enabled"`
+	SPEntityID         string    `json:"spEntityId"` // Audience the identity provider must address assertions to
+	ACSURL             string    `json:"acsUrl"`     // Where the identity provider posts responses
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
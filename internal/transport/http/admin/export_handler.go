@@ -0,0 +1,298 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceExport "github.com/yi-tech/go-user-service/internal/service/userexport"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// ExportHandler handles HTTP requests for bulk user exports
+type ExportHandler struct {
+	exporter  serviceExport.Service
+	generator serviceExport.Generator
+	ids       idgen.Strategy // Text form of rendered IDs
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewExportHandler creates a new bulk user export handler
+func NewExportHandler(exporter serviceExport.Service, generator serviceExport.Generator, ids idgen.Strategy, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		exporter:  exporter,
+		generator: generator,
+		ids:       ids,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// ExportUsers handles streaming user accounts as a CSV or JSON file
+// @Summary Export users
+// @Description Download every user matching the filters of the user listing as CSV or as a JSON array. Users are streamed from the database a page at a time. Users whose residency region is not allowed to leave the service for exports are withheld. Passwords are never exported. A JSON file without its closing bracket was cut short by a failure.
+// @Tags admin
+// @Produce text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "File format (default csv)" Enums(csv, json)
+// @Param columns query string false "Comma-separated columns (default all): id, email, username, first_name, last_name, residency, tenant, role, is_active, password_reset_required, created_at, updated_at"
+// @Param q query string false "Substring of the email, username or name"
+// @Param role query string false "Role"
+// @Param status query string false "Account status" Enums(active, inactive)
+// @Param metadata[key] query string false "Custom attribute the users must have, e.g. metadata[plan]=pro; repeatable"
+// @Success 200 {file} file "Exported users"
+// @Failure 400 {object} response.Response "Invalid query parameters or columns"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/export [get]
+func (h *ExportHandler) ExportUsers(c *gin.Context) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	req, ok := h.bindRequest(c, "ExportUsers")
+	if !ok {
+		return
+	}
+	format := req.Format
+
+	w := &exportWriter{
+		c:        c,
+		format:   format,
+		filename: fmt.Sprintf("users-%s.%s", h.now().UTC().Format("20060102-150405"), format),
+	}
+	result, err := h.exporter.Export(c.Request.Context(), actorUUID, req, w)
+	if err != nil {
+		if !w.started {
+			h.handleError(c, "ExportUsers", err)
+			return
+		}
+		// The status line is gone, so all that is left is to stop writing
+		h.logger.Error("User export failed part way",
+			zap.String("operation", "ExportUsers"),
+			zap.String("actor_id", actorUUID.String()),
+			zap.Error(err))
+		c.Abort()
+		return
+	}
+
+	h.logger.Info("Users exported",
+		zap.String("operation", "ExportUsers"),
+		zap.String("actor_id", actorUUID.String()),
+		zap.String("format", string(format)),
+		zap.Int("exported", result.Exported),
+		zap.Int("withheld", result.Withheld))
+}
+
+// StartExportJob handles queueing an export to be generated in the background
+// @Summary Start export job
+// @Description Queue an export of the users matching the filters of the user listing, to be written to a file by the worker. Poll the returned job and download the file once it has completed. Suited to exports too large to stream within one request.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "File format (default csv)" Enums(csv, json)
+// @Param columns query string false "Comma-separated columns (default all): id, email, username, first_name, last_name, residency, tenant, role, is_active, password_reset_required, created_at, updated_at"
+// @Param q query string false "Substring of the email, username or name"
+// @Param role query string false "Role"
+// @Param status query string false "Account status" Enums(active, inactive)
+// @Param metadata[key] query string false "Custom attribute the users must have, e.g. metadata[plan]=pro; repeatable"
+// @Success 202 {object} response.Response{data=ExportJobResponse} "Export queued"
+// @Failure 400 {object} response.Response "Invalid query parameters or columns"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/export/jobs [post]
+func (h *ExportHandler) StartExportJob(c *gin.Context) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	req, ok := h.bindRequest(c, "StartExportJob")
+	if !ok {
+		return
+	}
+
+	job, err := h.generator.Start(c.Request.Context(), actorUUID, req)
+	if err != nil {
+		h.handleError(c, "StartExportJob", err)
+		return
+	}
+
+	h.logger.Info("User export queued",
+		zap.String("operation", "StartExportJob"),
+		zap.String("actor_id", actorUUID.String()),
+		zap.String("job_id", job.ID.String()),
+		zap.String("format", string(job.Format)))
+
+	c.Header("Location", "/admin/v1/users/export/jobs/"+h.ids.Format(job.ID))
+	response.Accepted(c, "Export queued", h.toExportJobResponse(job))
+}
+
+// GetExportJob handles polling a background export
+// @Summary Get export job
+// @Description Report the progress of a background export and, once it has completed, its result
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export job ID"
+// @Success 200 {object} response.Response{data=ExportJobResponse} "Export job"
+// @Failure 400 {object} response.Response "Invalid job ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Export job not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/export/jobs/{id} [get]
+func (h *ExportHandler) GetExportJob(c *gin.Context) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID format")
+		return
+	}
+
+	job, err := h.generator.Job(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, "GetExportJob", err)
+		return
+	}
+
+	response.Success(c, h.toExportJobResponse(job))
+}
+
+// DownloadExport handles downloading a completed background export
+// @Summary Download export
+// @Description Download the file of a completed background export
+// @Tags admin
+// @Produce text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export job ID"
+// @Success 200 {file} file "Exported users"
+// @Failure 400 {object} response.Response "Invalid job ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Export job not found"
+// @Failure 409 {object} response.Response "Export has not completed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/export/jobs/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID format")
+		return
+	}
+
+	job, file, err := h.generator.Open(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, "DownloadExport", err)
+		return
+	}
+	defer file.Close()
+
+	w := &exportWriter{
+		c:        c,
+		format:   job.Format,
+		filename: fmt.Sprintf("users-%s.%s", job.CreatedAt.UTC().Format("20060102-150405"), job.Format),
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.Error("Export download failed part way",
+			zap.String("operation", "DownloadExport"),
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err))
+		c.Abort()
+	}
+}
+
+// bindRequest reads the filters, format and columns of an export, answering
+// the request when they are invalid
+func (h *ExportHandler) bindRequest(c *gin.Context, operation string) (serviceExport.Request, bool) {
+	var query UserExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return serviceExport.Request{}, false
+	}
+	query.bindMetadata(c)
+
+	format, err := serviceExport.ParseFormat(query.Format)
+	if err != nil {
+		h.handleError(c, operation, err)
+		return serviceExport.Request{}, false
+	}
+	cols, err := serviceExport.ParseColumns(query.Columns)
+	if err != nil {
+		h.handleError(c, operation, err)
+		return serviceExport.Request{}, false
+	}
+	return serviceExport.Request{Filter: query.toFilter(), Format: format, Columns: cols}, true
+}
+
+func (h *ExportHandler) toExportJobResponse(job *serviceExport.Job) ExportJobResponse {
+	resp := ExportJobResponse{
+		ID:         h.ids.Format(job.ID),
+		Status:     string(job.Status),
+		Format:     string(job.Format),
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != nil {
+		resp.Result = &ExportResultResponse{Exported: job.Result.Exported, Withheld: job.Result.Withheld}
+	}
+	return resp
+}
+
+// exportWriter sends the download headers with the first byte of the export,
+// leaving the response untouched for an error if the export fails before then
+type exportWriter struct {
+	c        *gin.Context
+	format   serviceExport.Format
+	filename string
+	started  bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		contentType := "text/csv; charset=utf-8"
+		if w.format == serviceExport.FormatJSON {
+			contentType = "application/json; charset=utf-8"
+		}
+		header := w.c.Writer.Header()
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+		header.Set("Cache-Control", "no-store")
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
+
+// Flush sends the buffered part of the export to the client
+func (w *exportWriter) Flush() {
+	w.c.Writer.Flush()
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *ExportHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Admin operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
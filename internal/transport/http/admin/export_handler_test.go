@@ -0,0 +1,263 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceExport "github.com/yi-tech/go-user-service/internal/service/userexport"
+)
+
+// MockExportService is a mock implementation of serviceExport.Service.
+// When the expectation supplies an output string it is written before returning.
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) Export(ctx context.Context, actorID uuid.UUID, req serviceExport.Request, w io.Writer) (*serviceExport.Result, error) {
+	args := m.Called(ctx, actorID, req, w)
+	if out := args.String(0); out != "" {
+		fmt.Fprint(w, out)
+	}
+	if args.Get(1) == nil {
+		return nil, args.Error(2)
+	}
+	return args.Get(1).(*serviceExport.Result), args.Error(2)
+}
+
+// MockExportGenerator is a mock implementation of serviceExport.Generator.
+// When the expectation for Open supplies a string it is served as the file.
+type MockExportGenerator struct {
+	mock.Mock
+}
+
+func (m *MockExportGenerator) Start(ctx context.Context, actorID uuid.UUID, req serviceExport.Request) (*serviceExport.Job, error) {
+	args := m.Called(ctx, actorID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceExport.Job), args.Error(1)
+}
+
+func (m *MockExportGenerator) Job(ctx context.Context, id uuid.UUID) (*serviceExport.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceExport.Job), args.Error(1)
+}
+
+func (m *MockExportGenerator) Open(ctx context.Context, id uuid.UUID) (*serviceExport.Job, io.ReadCloser, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*serviceExport.Job), io.NopCloser(strings.NewReader(args.String(1))), args.Error(2)
+}
+
+// serveExport requests an export with the admin identity set
+func serveExport(t *testing.T, target string, setup func(m *MockExportService)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockExportService)
+	setup(mockService)
+	h := NewExportHandler(mockService, new(MockExportGenerator), idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+	h.now = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/admin/v1/users/export", func(c *gin.Context) {
+		requestctx.SetUserID(c, testActorID)
+	}, h.ExportUsers)
+
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	router.ServeHTTP(rr, req)
+
+	mockService.AssertExpectations(t)
+	return rr
+}
+
+func TestExportHandler_ExportUsers(t *testing.T) {
+	t.Run("CSV By Default", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export", func(m *MockExportService) {
+			m.On("Export", mock.Anything, testActorID, mock.MatchedBy(func(req serviceExport.Request) bool {
+				return req.Format == serviceExport.FormatCSV && len(req.Columns) == 12 && assert.ObjectsAreEqual(domainUser.ListFilter{}, req.Filter)
+			}), mock.Anything).Return("id,email\n", &serviceExport.Result{Exported: 0}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="users-20261016-093000.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		assert.Equal(t, "id,email\n", rr.Body.String())
+	})
+
+	t.Run("JSON With Filters And Columns", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export?format=json&columns=email,role&q=ada&status=active", func(m *MockExportService) {
+			m.On("Export", mock.Anything, testActorID, mock.MatchedBy(func(req serviceExport.Request) bool {
+				return req.Format == serviceExport.FormatJSON &&
+					len(req.Columns) == 2 && req.Columns[0].Name == "email" && req.Columns[1].Name == "role" &&
+					req.Filter.Query == "ada" && req.Filter.Active != nil && *req.Filter.Active
+			}), mock.Anything).Return("[]\n", &serviceExport.Result{}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="users-20261016-093000.json"`, rr.Header().Get("Content-Disposition"))
+		assert.JSONEq(t, `[]`, rr.Body.String())
+	})
+
+	t.Run("Unsupported Format", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export?format=xml", func(m *MockExportService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid query parameters"}`, rr.Body.String())
+	})
+
+	t.Run("Unknown Column", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export?columns=email,password", func(m *MockExportService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"INVALID_ARGUMENT"`)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("Fails Before Writing", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export", func(m *MockExportService) {
+			m.On("Export", mock.Anything, testActorID, mock.Anything, mock.Anything).Return("", nil, errors.New("db error"))
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+		assert.JSONEq(t, `{"code":500,"message":"Something went wrong. Please try again later."}`, rr.Body.String())
+	})
+
+	t.Run("Fails Part Way", func(t *testing.T) {
+		rr := serveExport(t, "/admin/v1/users/export?format=json", func(m *MockExportService) {
+			m.On("Export", mock.Anything, testActorID, mock.Anything, mock.Anything).Return("[\n{\"id\":\"1\"}", nil, errors.New("db error"))
+		})
+
+		// The download has started, so the truncated body is all the client gets
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "[\n{\"id\":\"1\"}", rr.Body.String())
+	})
+}
+
+// serveExportJob sends a request to the export job routes with the admin identity set
+func serveExportJob(t *testing.T, method, target string, setup func(m *MockExportGenerator)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	mockGenerator := new(MockExportGenerator)
+	setup(mockGenerator)
+	h := NewExportHandler(new(MockExportService), mockGenerator, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	setActor := func(c *gin.Context) { requestctx.SetUserID(c, testActorID) }
+	router.POST("/admin/v1/users/export/jobs", setActor, h.StartExportJob)
+	router.GET("/admin/v1/users/export/jobs/:id", setActor, h.GetExportJob)
+	router.GET("/admin/v1/users/export/jobs/:id/download", setActor, h.DownloadExport)
+
+	req, _ := http.NewRequest(method, target, nil)
+	router.ServeHTTP(rr, req)
+
+	mockGenerator.AssertExpectations(t)
+	return rr
+}
+
+func TestExportHandler_ExportJobs(t *testing.T) {
+	jobID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	created := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	t.Run("Start Queues Export", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodPost, "/admin/v1/users/export/jobs?format=json&columns=email&role=admin", func(m *MockExportGenerator) {
+			m.On("Start", mock.Anything, testActorID, mock.MatchedBy(func(req serviceExport.Request) bool {
+				return req.Format == serviceExport.FormatJSON && len(req.Columns) == 1 && req.Filter.Role == "admin"
+			})).Return(&serviceExport.Job{ID: jobID, Status: serviceExport.JobPending, Format: serviceExport.FormatJSON, CreatedAt: created}, nil)
+		})
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "/admin/v1/users/export/jobs/"+jobID.String(), rr.Header().Get("Location"))
+		assert.JSONEq(t, `{"code":202,"message":"Export queued","data":{"id":"`+jobID.String()+`","status":"pending","format":"json","createdAt":"2026-10-16T09:30:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Start Rejects Unknown Column", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodPost, "/admin/v1/users/export/jobs?columns=password", func(m *MockExportGenerator) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"INVALID_ARGUMENT"`)
+	})
+
+	t.Run("Get Completed Job", func(t *testing.T) {
+		finished := created.Add(time.Minute)
+		rr := serveExportJob(t, http.MethodGet, "/admin/v1/users/export/jobs/"+jobID.String(), func(m *MockExportGenerator) {
+			m.On("Job", mock.Anything, jobID).Return(&serviceExport.Job{
+				ID:         jobID,
+				Status:     serviceExport.JobCompleted,
+				Format:     serviceExport.FormatCSV,
+				Result:     &serviceExport.Result{Exported: 3, Withheld: 1},
+				CreatedAt:  created,
+				FinishedAt: &finished,
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"status":"completed"`)
+		assert.Contains(t, rr.Body.String(), `"result":{"exported":3,"withheld":1}`)
+	})
+
+	t.Run("Get Unknown Job", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodGet, "/admin/v1/users/export/jobs/"+jobID.String(), func(m *MockExportGenerator) {
+			m.On("Job", mock.Anything, jobID).Return(nil, serviceExport.ErrJobNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"EXPORT_JOB_NOT_FOUND"`)
+	})
+
+	t.Run("Get Invalid ID", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodGet, "/admin/v1/users/export/jobs/nope", func(m *MockExportGenerator) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Download Completed Export", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodGet, "/admin/v1/users/export/jobs/"+jobID.String()+"/download", func(m *MockExportGenerator) {
+			m.On("Open", mock.Anything, jobID).Return(&serviceExport.Job{
+				ID:        jobID,
+				Status:    serviceExport.JobCompleted,
+				Format:    serviceExport.FormatCSV,
+				CreatedAt: created,
+			}, "email\nada@example.com\n", nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="users-20261016-093000.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "email\nada@example.com\n", rr.Body.String())
+	})
+
+	t.Run("Download Before Completion", func(t *testing.T) {
+		rr := serveExportJob(t, http.MethodGet, "/admin/v1/users/export/jobs/"+jobID.String()+"/download", func(m *MockExportGenerator) {
+			m.On("Open", mock.Anything, jobID).Return(nil, "", serviceExport.ErrExportNotReady)
+		})
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"EXPORT_NOT_READY"`)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+}
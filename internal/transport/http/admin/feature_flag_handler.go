@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// FeatureFlagManager lists and changes feature flags.
+// *featureflag.Flags satisfies it.
+type FeatureFlagManager interface {
+	List(ctx context.Context) ([]*featureflag.Flag, error)
+	Set(ctx context.Context, actorID uuid.UUID, name string, update featureflag.Update) (*featureflag.Flag, error)
+	Delete(ctx context.Context, name string) error
+	Default(name string) bool
+}
+
+// FeatureFlagHandler handles HTTP requests for inspecting and toggling feature flags
+type FeatureFlagHandler struct {
+	flags  FeatureFlagManager
+	ids    idgen.Strategy // Text form of rendered IDs
+	logger *zap.Logger
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(flags FeatureFlagManager, ids idgen.Strategy, logger *zap.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flags:  flags,
+		ids:    ids,
+		logger: logger,
+	}
+}
+
+// ListFeatureFlags handles listing feature flags
+// @Summary List feature flags
+// @Description List the flags set through this API and the flags configured for this environment, by name
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]FeatureFlagResponse} "Feature flags"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.flags.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "ListFeatureFlags", err)
+		return
+	}
+
+	data := make([]FeatureFlagResponse, 0, len(flags))
+	for _, flag := range flags {
+		data = append(data, h.toFeatureFlagResponse(flag))
+	}
+	response.Success(c, data)
+}
+
+// SetFeatureFlag handles setting a feature flag
+// @Summary Set feature flag
+// @Description Turn a flag on or off for everyone, or on for some tenants only. The value takes precedence over the configured default until the flag is deleted; other instances pick it up within feature_flags.refresh_seconds.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Flag name, e.g. new-login-flow"
+// @Param request body FeatureFlagRequest true "Flag value"
+// @Success 200 {object} response.Response{data=FeatureFlagResponse} "Flag updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/feature-flags/{name} [put]
+func (h *FeatureFlagHandler) SetFeatureFlag(c *gin.Context) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	var req FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	flag, err := h.flags.Set(c.Request.Context(), actorUUID, c.Param("name"), featureflag.Update{
+		Enabled:     *req.Enabled,
+		Tenants:     req.Tenants,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleError(c, "SetFeatureFlag", err)
+		return
+	}
+	h.logger.Info("Feature flag changed",
+		zap.String("flag", flag.Name),
+		zap.Bool("enabled", flag.Enabled),
+		zap.Strings("tenants", flag.Tenants),
+		zap.String("actor_id", actorUUID.String()))
+
+	response.Success(c, h.toFeatureFlagResponse(flag))
+}
+
+// DeleteFeatureFlag handles deleting a feature flag
+// @Summary Delete feature flag
+// @Description Delete the value set through this API, restoring the default configured for this environment
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Flag name"
+// @Success 200 {object} response.Response "Flag deleted"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Flag not set through this API"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/feature-flags/{name} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.flags.Delete(c.Request.Context(), name); err != nil {
+		h.handleError(c, "DeleteFeatureFlag", err)
+		return
+	}
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Info("Feature flag deleted",
+		zap.String("flag", name),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, gin.H{"message": "Feature flag deleted"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *FeatureFlagHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Feature flag operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func (h *FeatureFlagHandler) toFeatureFlagResponse(flag *featureflag.Flag) FeatureFlagResponse {
+	resp := FeatureFlagResponse{
+		Name:        flag.Name,
+		Enabled:     flag.Enabled,
+		Tenants:     flag.Tenants,
+		Description: flag.Description,
+		Default:     h.flags.Default(flag.Name),
+		Stored:      !flag.UpdatedAt.IsZero(),
+	}
+	if resp.Tenants == nil {
+		resp.Tenants = []string{}
+	}
+	if flag.UpdatedBy != uuid.Nil {
+		resp.UpdatedBy = h.ids.Format(flag.UpdatedBy)
+	}
+	if resp.Stored {
+		resp.UpdatedAt = &flag.UpdatedAt
+	}
+	return resp
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// stubFeatureFlags records the last change and answers with fixed flags
+type stubFeatureFlags struct {
+	flags    []*featureflag.Flag
+	err      error
+	defaults map[string]bool
+	set      featureflag.Update
+	deleted  string
+}
+
+func (s *stubFeatureFlags) List(_ context.Context) ([]*featureflag.Flag, error) {
+	return s.flags, s.err
+}
+
+func (s *stubFeatureFlags) Set(_ context.Context, actorID uuid.UUID, name string, update featureflag.Update) (*featureflag.Flag, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.set = update
+	return &featureflag.Flag{Name: name, Enabled: update.Enabled, Tenants: update.Tenants, UpdatedBy: actorID, UpdatedAt: time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)}, nil
+}
+
+func (s *stubFeatureFlags) Delete(_ context.Context, name string) error {
+	s.deleted = name
+	return s.err
+}
+
+func (s *stubFeatureFlags) Default(name string) bool {
+	return s.defaults[name]
+}
+
+func TestFeatureFlagHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	actorID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	serve := func(flags *stubFeatureFlags, method, path, body string) *httptest.ResponseRecorder {
+		handler := NewFeatureFlagHandler(flags, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		router := gin.New()
+		group := router.Group("/feature-flags", func(c *gin.Context) { requestctx.SetUserID(c, actorID) })
+		group.GET("", handler.ListFeatureFlags)
+		group.PUT("/:name", handler.SetFeatureFlag)
+		group.DELETE("/:name", handler.DeleteFeatureFlag)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("List", func(t *testing.T) {
+		flags := &stubFeatureFlags{
+			flags:    []*featureflag.Flag{{Name: "dark-mode", Enabled: true}},
+			defaults: map[string]bool{"dark-mode": true},
+		}
+
+		rr := serve(flags, http.MethodGet, "/feature-flags", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":[{"name":"dark-mode","enabled":true,"tenants":[],"default":true,"stored":false}]}`, rr.Body.String())
+	})
+
+	t.Run("Set For Tenants", func(t *testing.T) {
+		flags := &stubFeatureFlags{}
+
+		rr := serve(flags, http.MethodPut, "/feature-flags/new-login-flow", `{"enabled":false,"tenants":["acme"]}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, featureflag.Update{Enabled: false, Tenants: []string{"acme"}}, flags.set)
+		assert.Contains(t, rr.Body.String(), `"tenants":["acme"],"default":false,"stored":true,"updatedBy":"11111111-1111-1111-1111-111111111111"`)
+	})
+
+	t.Run("Set Invalid Name", func(t *testing.T) {
+		rr := serve(&stubFeatureFlags{err: featureflag.ErrInvalidName}, http.MethodPut, "/feature-flags/New%20Flow", `{"enabled":true}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"INVALID_ARGUMENT"`)
+	})
+
+	t.Run("Set Missing Enabled", func(t *testing.T) {
+		rr := serve(&stubFeatureFlags{}, http.MethodPut, "/feature-flags/new-login-flow", `{"tenants":["acme"]}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		flags := &stubFeatureFlags{}
+
+		rr := serve(flags, http.MethodDelete, "/feature-flags/new-login-flow", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "new-login-flow", flags.deleted)
+	})
+
+	t.Run("Delete Not Stored", func(t *testing.T) {
+		rr := serve(&stubFeatureFlags{err: featureflag.ErrFlagNotFound}, http.MethodDelete, "/feature-flags/dark-mode", "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"FEATURE_FLAG_NOT_FOUND"`)
+	})
+}
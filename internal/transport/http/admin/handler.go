@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// Handler handles HTTP requests for administrative operations
+type Handler struct {
+	roleService domainRBAC.RoleService
+	keyManager  domainAuth.KeyManager
+	logger      *zap.Logger
+}
+
+// NewHandler creates a new admin handler
+func NewHandler(roleService domainRBAC.RoleService, keyManager domainAuth.KeyManager, logger *zap.Logger) *Handler {
+	return &Handler{
+		roleService: roleService,
+		keyManager:  keyManager,
+		logger:      logger,
+	}
+}
+
+// ListRoles handles listing all roles with their permissions
+// @Summary List roles
+// @Description List every role together with the permissions it grants
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]RoleResponse} "Roles"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/roles [get]
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list roles",
+			zap.String("operation", "ListRoles"),
+			zap.Error(err))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	data := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		perms := make([]string, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			perms = append(perms, string(p))
+		}
+		data = append(data, RoleResponse{
+			Name:        string(role.Name),
+			Description: role.Description,
+			Permissions: perms,
+		})
+	}
+
+	response.Success(c, data)
+}
+
+// ListPermissions handles listing the effective permission matrix
+// @Summary List permissions
+// @Description List every permission together with the roles that grant it
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]PermissionResponse} "Permission matrix"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/permissions [get]
+func (h *Handler) ListPermissions(c *gin.Context) {
+	grants, err := h.roleService.ListPermissions(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list permissions",
+			zap.String("operation", "ListPermissions"),
+			zap.Error(err))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	data := make([]PermissionResponse, 0, len(grants))
+	for _, grant := range grants {
+		roles := make([]string, 0, len(grant.Roles))
+		for _, r := range grant.Roles {
+			roles = append(roles, string(r))
+		}
+		data = append(data, PermissionResponse{
+			Name:        string(grant.Permission.Name),
+			Description: grant.Permission.Description,
+			Roles:       roles,
+		})
+	}
+
+	response.Success(c, data)
+}
+
+// RotateSigningKey handles rotating the access token signing key
+// @Summary Rotate signing key
+// @Description Generate a new access token signing key and make it current on every instance. Tokens signed with previous keys remain valid until they expire.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=KeyRotationResponse} "Signing key rotated"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/keys/rotate [post]
+func (h *Handler) RotateSigningKey(c *gin.Context) {
+	previous := h.keyManager.ActiveKeyID()
+
+	kid, err := h.keyManager.Rotate(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to rotate signing key",
+			zap.String("operation", "RotateSigningKey"),
+			zap.Error(err))
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	h.logger.Info("Signing key rotated",
+		zap.String("operation", "RotateSigningKey"),
+		zap.String("previous_key_id", previous),
+		zap.String("key_id", kid))
+
+	response.Success(c, KeyRotationResponse{KeyID: kid, PreviousKeyID: previous})
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+)
+
+// MockRoleService is a mock implementation of domainRBAC.RoleService
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) ListRoles(ctx context.Context) ([]domainRBAC.RoleDefinition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domainRBAC.RoleDefinition), args.Error(1)
+}
+
+func (m *MockRoleService) ListPermissions(ctx context.Context) ([]domainRBAC.PermissionGrant, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domainRBAC.PermissionGrant), args.Error(1)
+}
+
+func (m *MockRoleService) HasPermission(role domainRBAC.Role, permission domainRBAC.Permission) bool {
+	args := m.Called(role, permission)
+	return args.Bool(0)
+}
+
+// MockKeyManager is a mock implementation of domainAuth.KeyManager
+type MockKeyManager struct {
+	mock.Mock
+}
+
+func (m *MockKeyManager) ActiveKeyID() string {
+	return m.Called().String(0)
+}
+
+func (m *MockKeyManager) Rotate(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKeyManager) PublicKeys() []domainAuth.JSONWebKey {
+	return m.Called().Get(0).([]domainAuth.JSONWebKey)
+}
+
+func TestListRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		setupMock      func(m *MockRoleService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			setupMock: func(m *MockRoleService) {
+				m.On("ListRoles", mock.Anything).Return([]domainRBAC.RoleDefinition{
+					{Name: domainRBAC.RoleUser, Description: "Regular end user", Permissions: []domainRBAC.Permission{domainRBAC.PermissionProfileRead}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":[{"name":"user","description":"Regular end user","permissions":["profile:read"]}]}`,
+		},
+		{
+			name: "Internal Server Error",
+			setupMock: func(m *MockRoleService) {
+				m.On("ListRoles", mock.Anything).Return(nil, errors.New("boom"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockRoleService)
+			tc.setupMock(mockService)
+			handler := NewHandler(mockService, nil, logger)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.GET("/admin/v1/roles", handler.ListRoles)
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin/v1/roles", nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zaptest.NewLogger(t)
+
+	mockService := new(MockRoleService)
+	mockService.On("ListPermissions", mock.Anything).Return([]domainRBAC.PermissionGrant{
+		{
+			Permission: domainRBAC.PermissionDefinition{Name: domainRBAC.PermissionUsersDelete, Description: "Delete any user account"},
+			Roles:      []domainRBAC.Role{domainRBAC.RoleAdmin},
+		},
+	}, nil)
+	handler := NewHandler(mockService, nil, logger)
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/admin/v1/permissions", handler.ListPermissions)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/v1/permissions", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":[{"name":"users:delete","description":"Delete any user account","roles":["admin"]}]}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestRotateSigningKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		setupMock      func(m *MockKeyManager)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			setupMock: func(m *MockKeyManager) {
+				m.On("ActiveKeyID").Return("old-key")
+				m.On("Rotate", mock.Anything).Return("new-key", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"keyId":"new-key","previousKeyId":"old-key"}}`,
+		},
+		{
+			name: "Rotation Fails",
+			setupMock: func(m *MockKeyManager) {
+				m.On("ActiveKeyID").Return("old-key")
+				m.On("Rotate", mock.Anything).Return("", errors.New("store unavailable"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keyManager := new(MockKeyManager)
+			tc.setupMock(keyManager)
+			handler := NewHandler(new(MockRoleService), keyManager, logger)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.POST("/admin/v1/keys/rotate", handler.RotateSigningKey)
+
+			req, _ := http.NewRequest(http.MethodPost, "/admin/v1/keys/rotate", nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			keyManager.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceImport "github.com/yi-tech/go-user-service/internal/service/userimport"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// multipartOverhead allows for the form boundaries and part headers around the file
+const multipartOverhead = 64 << 10
+
+// ImportHandler handles HTTP requests for bulk user imports
+type ImportHandler struct {
+	importer    serviceImport.Service
+	ids         idgen.Strategy // Text form of rendered IDs
+	maxFileSize int64
+	syncMax     int64 // Larger files are imported in the background
+	logger      *zap.Logger
+}
+
+// NewImportHandler creates a new bulk user import handler
+func NewImportHandler(importer serviceImport.Service, ids idgen.Strategy, maxFileSize, syncMax int64, logger *zap.Logger) *ImportHandler {
+	return &ImportHandler{
+		importer:    importer,
+		ids:         ids,
+		maxFileSize: maxFileSize,
+		syncMax:     syncMax,
+		logger:      logger,
+	}
+}
+
+// ImportUsers handles importing user accounts from a CSV or JSON file
+// @Summary Import users
+// @Description Create accounts from a CSV file (header: email,password,first_name,last_name[,residency]) or a JSON array of registration objects. Invalid rows are reported individually and the rest are imported. Imported users must choose a new password on first sign-in. Large files, or any file with async=true, are imported in the background and return a job to poll.
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or JSON file"
+// @Param async query bool false "Import in the background"
+// @Success 200 {object} response.Response{data=ImportResultResponse} "Import finished"
+// @Success 202 {object} response.Response{data=ImportJobResponse} "Import started"
+// @Header 202 {string} Location "URL of the import job"
+// @Failure 400 {object} response.Response "Missing, malformed or unsupported file"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 413 {object} response.Response "File too large"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/import [post]
+func (h *ImportHandler) ImportUsers(c *gin.Context) {
+	actorUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var query ImportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxFileSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.fileTooLarge(c)
+			return
+		}
+		response.BadRequest(c, "An import file is required")
+		return
+	}
+	if fileHeader.Size > h.maxFileSize {
+		h.fileTooLarge(c)
+		return
+	}
+
+	format, ok := importFormat(fileHeader.Filename, fileHeader.Header.Get("Content-Type"))
+	if !ok {
+		response.AppError(c, serviceImport.ErrUnsupportedFormat)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.handleError(c, "ImportUsers", err)
+		return
+	}
+	defer file.Close()
+
+	async := query.Async || fileHeader.Size > h.syncMax
+	var source io.Reader = file
+	if async {
+		// The upload is discarded when the request ends, so the job reads its own copy
+		data, err := io.ReadAll(file)
+		if err != nil {
+			h.handleError(c, "ImportUsers", err)
+			return
+		}
+		source = bytes.NewReader(data)
+	}
+
+	// The header is read here so a malformed file is rejected before any job starts
+	rows, err := serviceImport.NewReader(source, format)
+	if err != nil {
+		h.handleError(c, "ImportUsers", err)
+		return
+	}
+
+	if async {
+		job, err := h.importer.Start(c.Request.Context(), actorUUID, rows)
+		if err != nil {
+			h.handleError(c, "ImportUsers", err)
+			return
+		}
+
+		h.logger.Info("User import started",
+			zap.String("operation", "ImportUsers"),
+			zap.String("actor_id", actorUUID.String()),
+			zap.String("job_id", job.ID.String()),
+			zap.Int64("size", fileHeader.Size))
+
+		c.Header("Location", "/admin/v1/users/import/"+h.ids.Format(job.ID))
+		response.Accepted(c, "Import started", h.toImportJobResponse(job))
+		return
+	}
+
+	result, err := h.importer.Import(c.Request.Context(), actorUUID, rows)
+	if err != nil {
+		h.handleError(c, "ImportUsers", err)
+		return
+	}
+
+	h.logger.Info("Users imported",
+		zap.String("operation", "ImportUsers"),
+		zap.String("actor_id", actorUUID.String()),
+		zap.Int("imported", result.Imported),
+		zap.Int("failed", result.Failed))
+
+	response.Success(c, toImportResultResponse(result))
+}
+
+// GetImportJob handles polling a background import
+// @Summary Get import job
+// @Description Report the progress of a background import and, once it has completed, its result
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Import job ID"
+// @Success 200 {object} response.Response{data=ImportJobResponse} "Import job"
+// @Failure 400 {object} response.Response "Invalid job ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Import job not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/users/import/{id} [get]
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID format")
+		return
+	}
+
+	job, err := h.importer.Job(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, "GetImportJob", err)
+		return
+	}
+
+	response.Success(c, h.toImportJobResponse(job))
+}
+
+// importFormat picks the file format from the file extension, falling back to
+// the content type the client sent for the part
+func importFormat(filename, contentType string) (serviceImport.Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return serviceImport.FormatCSV, true
+	case ".json":
+		return serviceImport.FormatJSON, true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return serviceImport.FormatCSV, true
+	case "application/json":
+		return serviceImport.FormatJSON, true
+	}
+	return "", false
+}
+
+func (h *ImportHandler) fileTooLarge(c *gin.Context) {
+	response.Error(c, http.StatusRequestEntityTooLarge, "Import file is too large")
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *ImportHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Admin operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func (h *ImportHandler) toImportJobResponse(job *serviceImport.Job) ImportJobResponse {
+	resp := ImportJobResponse{
+		ID:         h.ids.Format(job.ID),
+		Status:     string(job.Status),
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != nil {
+		result := toImportResultResponse(job.Result)
+		resp.Result = &result
+	}
+	return resp
+}
+
+func toImportResultResponse(result *serviceImport.Result) ImportResultResponse {
+	errs := make([]ImportRowErrorResponse, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		errs = append(errs, ImportRowErrorResponse{Row: e.Row, Email: e.Email, Message: e.Message})
+	}
+	return ImportResultResponse{
+		Total:           result.Total,
+		Imported:        result.Imported,
+		Failed:          result.Failed,
+		Errors:          errs,
+		ErrorsTruncated: result.ErrorsTruncated,
+	}
+}
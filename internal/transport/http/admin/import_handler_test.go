@@ -0,0 +1,263 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceImport "github.com/yi-tech/go-user-service/internal/service/userimport"
+)
+
+// MockImportService is a mock implementation of serviceImport.Service
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) Import(ctx context.Context, actorID uuid.UUID, rows serviceImport.RowReader) (*serviceImport.Result, error) {
+	args := m.Called(ctx, actorID, rows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceImport.Result), args.Error(1)
+}
+
+func (m *MockImportService) Start(ctx context.Context, actorID uuid.UUID, rows serviceImport.RowReader) (*serviceImport.Job, error) {
+	args := m.Called(ctx, actorID, rows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceImport.Job), args.Error(1)
+}
+
+func (m *MockImportService) Job(ctx context.Context, id uuid.UUID) (*serviceImport.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceImport.Job), args.Error(1)
+}
+
+const importCSV = "email,password,first_name,last_name\nada@example.com,password1,Ada,Lovelace\n"
+
+// multipartFile builds a multipart form with a single file field
+func multipartFile(t *testing.T, filename, contentType, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return body, w.FormDataContentType()
+}
+
+// serveImport posts an import file to the handler with the admin identity set
+func serveImport(t *testing.T, target, filename, contentType, content string, syncMax int64, setup func(m *MockImportService)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockImportService)
+	setup(mockService)
+	h := NewImportHandler(mockService, idgen.StrategyUUIDv4, 1024, syncMax, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.POST("/admin/v1/users/import", func(c *gin.Context) {
+		requestctx.SetUserID(c, testActorID)
+	}, h.ImportUsers)
+
+	body, formType := multipartFile(t, filename, contentType, content)
+	req, _ := http.NewRequest(http.MethodPost, target, body)
+	req.Header.Set("Content-Type", formType)
+	router.ServeHTTP(rr, req)
+
+	mockService.AssertExpectations(t)
+	return rr
+}
+
+func TestImportHandler_ImportUsers(t *testing.T) {
+	jobID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	tests := []struct {
+		name           string
+		target         string
+		filename       string
+		contentType    string
+		content        string
+		syncMax        int64
+		setup          func(m *MockImportService)
+		expectedStatus int
+		expectedBody   string
+		expectedHeader string
+	}{
+		{
+			name:        "Sync CSV",
+			target:      "/admin/v1/users/import",
+			filename:    "users.csv",
+			contentType: "application/octet-stream",
+			content:     importCSV,
+			syncMax:     1024,
+			setup: func(m *MockImportService) {
+				m.On("Import", mock.Anything, testActorID, mock.Anything).Return(&serviceImport.Result{
+					Total:    2,
+					Imported: 1,
+					Failed:   1,
+					Errors:   []serviceImport.RowError{{Row: 2, Email: "bad", Message: "email must be a valid email address"}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"total":2,"imported":1,"failed":1,"errors":[{"row":2,"email":"bad","message":"email must be a valid email address"}]}}`,
+		},
+		{
+			name:        "Async Requested",
+			target:      "/admin/v1/users/import?async=true",
+			filename:    "users",
+			contentType: "application/json",
+			content:     `[{"email":"ada@example.com"}]`,
+			syncMax:     1024,
+			setup: func(m *MockImportService) {
+				m.On("Start", mock.Anything, testActorID, mock.Anything).Return(&serviceImport.Job{
+					ID:        jobID,
+					Status:    serviceImport.JobPending,
+					CreatedBy: testActorID,
+					CreatedAt: testTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"code":202,"message":"Import started","data":{"id":"33333333-3333-3333-3333-333333333333","status":"pending","createdAt":"2025-06-20T12:00:00Z"}}`,
+			expectedHeader: "/admin/v1/users/import/33333333-3333-3333-3333-333333333333",
+		},
+		{
+			name:        "Large File Runs Async",
+			target:      "/admin/v1/users/import",
+			filename:    "users.csv",
+			contentType: "text/csv",
+			content:     importCSV,
+			syncMax:     16,
+			setup: func(m *MockImportService) {
+				m.On("Start", mock.Anything, testActorID, mock.Anything).Return(&serviceImport.Job{
+					ID:        jobID,
+					Status:    serviceImport.JobPending,
+					CreatedAt: testTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"code":202,"message":"Import started","data":{"id":"33333333-3333-3333-3333-333333333333","status":"pending","createdAt":"2025-06-20T12:00:00Z"}}`,
+			expectedHeader: "/admin/v1/users/import/33333333-3333-3333-3333-333333333333",
+		},
+		{
+			name:           "Unsupported Format",
+			target:         "/admin/v1/users/import",
+			filename:       "users.xlsx",
+			contentType:    "application/octet-stream",
+			content:        "binary",
+			syncMax:        1024,
+			setup:          func(m *MockImportService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"import files must be CSV or JSON","errorCode":"INVALID_ARGUMENT"}`,
+		},
+		{
+			name:           "Missing Column",
+			target:         "/admin/v1/users/import",
+			filename:       "users.csv",
+			contentType:    "text/csv",
+			content:        "email,password\n",
+			syncMax:        1024,
+			setup:          func(m *MockImportService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"the CSV header must include email, password, first_name and last_name","errorCode":"INVALID_ARGUMENT"}`,
+		},
+		{
+			name:           "File Too Large",
+			target:         "/admin/v1/users/import",
+			filename:       "users.csv",
+			contentType:    "text/csv",
+			content:        importCSV + strings.Repeat("x", 1024),
+			syncMax:        1024,
+			setup:          func(m *MockImportService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"code":413,"message":"Import file is too large"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serveImport(t, tc.target, tc.filename, tc.contentType, tc.content, tc.syncMax, tc.setup)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			assert.Equal(t, tc.expectedHeader, rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestImportHandler_GetImportJob(t *testing.T) {
+	jobID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+	serve := func(t *testing.T, target string, setup func(m *MockImportService)) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		mockService := new(MockImportService)
+		setup(mockService)
+		h := NewImportHandler(mockService, idgen.StrategyUUIDv4, 1024, 1024, zaptest.NewLogger(t))
+
+		rr := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(rr)
+		router.GET("/admin/v1/users/import/:id", h.GetImportJob)
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		router.ServeHTTP(rr, req)
+
+		mockService.AssertExpectations(t)
+		return rr
+	}
+
+	t.Run("Completed", func(t *testing.T) {
+		finished := testTime.Add(2 * time.Minute)
+		rr := serve(t, "/admin/v1/users/import/"+jobID.String(), func(m *MockImportService) {
+			m.On("Job", mock.Anything, jobID).Return(&serviceImport.Job{
+				ID:         jobID,
+				Status:     serviceImport.JobCompleted,
+				Result:     &serviceImport.Result{Total: 1, Imported: 1},
+				CreatedAt:  testTime,
+				FinishedAt: &finished,
+			}, nil)
+		})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"33333333-3333-3333-3333-333333333333","status":"completed","result":{"total":1,"imported":1,"failed":0,"errors":[]},"createdAt":"2025-06-20T12:00:00Z","finishedAt":"2025-06-20T12:02:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		rr := serve(t, "/admin/v1/users/import/"+jobID.String(), func(m *MockImportService) {
+			m.On("Job", mock.Anything, jobID).Return(nil, serviceImport.ErrJobNotFound)
+		})
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"import job not found","errorCode":"IMPORT_JOB_NOT_FOUND"}`, rr.Body.String())
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		rr := serve(t, "/admin/v1/users/import/not-an-id", func(m *MockImportService) {})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"code":400,"message":"Invalid job ID format"}`, rr.Body.String())
+	})
+}
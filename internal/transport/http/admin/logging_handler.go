@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/logging"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// LoggingHandler handles HTTP requests for inspecting and changing log levels
+type LoggingHandler struct {
+	levels *logging.Levels
+	logger *zap.Logger
+}
+
+// NewLoggingHandler creates a new log level handler
+func NewLoggingHandler(levels *logging.Levels, logger *zap.Logger) *LoggingHandler {
+	return &LoggingHandler{
+		levels: levels,
+		logger: logger,
+	}
+}
+
+// GetLogLevels handles reporting the log levels
+// @Summary Get log levels
+// @Description Report the log level of the application, HTTP access, gRPC and GORM logs of this instance
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=LogLevelsResponse} "Log levels"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /admin/v1/logging/level [get]
+func (h *LoggingHandler) GetLogLevels(c *gin.Context) {
+	response.Success(c, LogLevelsResponse{Levels: h.levels.Levels()})
+}
+
+// SetLogLevel handles changing the log level of a module
+// @Summary Set log level
+// @Description Change the log level of one module on this instance until the next restart or config reload (SIGHUP or a change of the config file)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LogLevelRequest true "Log level"
+// @Success 200 {object} response.Response{data=LogLevelsResponse} "Log level updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /admin/v1/logging/level [put]
+func (h *LoggingHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+	module := req.Module
+	if module == "" {
+		module = logging.App
+	}
+
+	if err := h.levels.Set(module, req.Level); err != nil {
+		h.handleError(c, "SetLogLevel", err)
+		return
+	}
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Warn("Log level changed",
+		zap.String("module", module),
+		zap.String("level", req.Level),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, LogLevelsResponse{Levels: h.levels.Levels()})
+}
+
+// handleError maps log level errors to HTTP responses
+func (h *LoggingHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Log level operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
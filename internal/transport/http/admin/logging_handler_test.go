@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/yi-tech/go-user-service/internal/logging"
+)
+
+func TestLoggingHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Application Level",
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"levels":{"app":"debug","gorm":"warn","grpc":"info","http":"info"}}}`,
+		},
+		{
+			name:           "Module Level",
+			body:           `{"module":"http","level":"error"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"levels":{"app":"info","gorm":"warn","grpc":"info","http":"error"}}}`,
+		},
+		{
+			name:           "Unknown Module",
+			body:           `{"module":"redis","level":"debug"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"errorCode":"INVALID_ARGUMENT"`,
+		},
+		{
+			name:           "Unknown Level",
+			body:           `{"level":"verbose"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"errorCode":"INVALID_ARGUMENT"`,
+		},
+		{
+			name:           "Missing Level",
+			body:           `{"module":"gorm"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := logging.NewLevels(zapcore.InfoLevel, map[string]zapcore.Level{logging.GORM: zapcore.WarnLevel})
+			handler := NewLoggingHandler(levels, zaptest.NewLogger(t))
+			router := gin.New()
+			router.PUT("/logging/level", handler.SetLogLevel)
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/logging/level", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// ReadOnlyHandler handles HTTP requests for inspecting and toggling read-only mode
+type ReadOnlyHandler struct {
+	sw     *readonly.Switch
+	logger *zap.Logger
+}
+
+// NewReadOnlyHandler creates a new read-only mode handler
+func NewReadOnlyHandler(sw *readonly.Switch, logger *zap.Logger) *ReadOnlyHandler {
+	return &ReadOnlyHandler{
+		sw:     sw,
+		logger: logger,
+	}
+}
+
+// GetReadOnly handles reporting the read-only mode
+// @Summary Get read-only mode
+// @Description Report whether this instance rejects writes with 503 READ_ONLY
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ReadOnlyResponse} "Read-only mode"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /admin/v1/read-only [get]
+func (h *ReadOnlyHandler) GetReadOnly(c *gin.Context) {
+	response.Success(c, ReadOnlyResponse{Enabled: h.sw.Enabled()})
+}
+
+// SetReadOnly handles turning read-only mode on or off
+// @Summary Set read-only mode
+// @Description Turn read-only mode on or off for this instance, e.g. during a database failover. Reads and sign-ins keep working while it is on.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReadOnlyRequest true "Read-only mode"
+// @Success 200 {object} response.Response{data=ReadOnlyResponse} "Read-only mode updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /admin/v1/read-only [put]
+func (h *ReadOnlyHandler) SetReadOnly(c *gin.Context) {
+	var req ReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	h.sw.Set(*req.Enabled)
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Warn("Read-only mode changed",
+		zap.Bool("enabled", *req.Enabled),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, ReadOnlyResponse{Enabled: *req.Enabled})
+}
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/yi-tech/go-user-service/internal/readonly"
+)
+
+func TestReadOnlyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedMode   bool
+	}{
+		{
+			name:           "Enable",
+			body:           `{"enabled":true}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"enabled":true}}`,
+			expectedMode:   true,
+		},
+		{
+			name:           "Disable",
+			body:           `{"enabled":false}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"enabled":false}}`,
+		},
+		{
+			name:           "Missing Field",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw := readonly.NewSwitch(false)
+			handler := NewReadOnlyHandler(sw, zaptest.NewLogger(t))
+			router := gin.New()
+			router.GET("/read-only", handler.GetReadOnly)
+			router.PUT("/read-only", handler.SetReadOnly)
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/read-only", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			assert.Equal(t, tt.expectedMode, sw.Enabled())
+
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/read-only", nil))
+			assert.JSONEq(t, `{"code":200,"message":"Success","data":{"enabled":`+map[bool]string{true: "true", false: "false"}[tt.expectedMode]+`}}`, rr.Body.String())
+		})
+	}
+}
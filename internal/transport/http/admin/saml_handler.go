@@ -0,0 +1,178 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceSAML "github.com/yi-tech/go-user-service/internal/service/saml"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// SAMLProviderHandler handles HTTP requests for managing the SAML identity providers of tenants
+type SAMLProviderHandler struct {
+	saml   serviceSAML.Service
+	logger *zap.Logger
+}
+
+// NewSAMLProviderHandler creates a new SAML identity provider handler
+func NewSAMLProviderHandler(saml serviceSAML.Service, logger *zap.Logger) *SAMLProviderHandler {
+	return &SAMLProviderHandler{
+		saml:   saml,
+		logger: logger,
+	}
+}
+
+// ListSAMLProviders handles listing the SAML identity providers of tenants
+// @Summary List SAML identity providers
+// @Description List the SAML identity provider of every tenant that has one, ordered by tenant
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]SAMLProviderResponse} "Identity providers"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/saml/providers [get]
+func (h *SAMLProviderHandler) ListSAMLProviders(c *gin.Context) {
+	providers, err := h.saml.ListProviders(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "ListSAMLProviders", err)
+		return
+	}
+
+	data := make([]SAMLProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		data = append(data, h.toSAMLProviderResponse(provider))
+	}
+	response.Success(c, data)
+}
+
+// GetSAMLProvider handles retrieving the SAML identity provider of a tenant
+// @Summary Get SAML identity provider
+// @Description Get the SAML identity provider of a tenant, with the entity ID and assertion consumer service URL to configure it with
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} response.Response{data=SAMLProviderResponse} "Identity provider"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Identity provider not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/saml/providers/{tenant} [get]
+func (h *SAMLProviderHandler) GetSAMLProvider(c *gin.Context) {
+	provider, err := h.saml.GetProvider(c.Request.Context(), c.Param("tenant"))
+	if err != nil {
+		h.handleError(c, "GetSAMLProvider", err)
+		return
+	}
+	response.Success(c, h.toSAMLProviderResponse(provider))
+}
+
+// SaveSAMLProvider handles creating or replacing the SAML identity provider of a tenant
+// @Summary Save SAML identity provider
+// @Description Create or replace the SAML identity provider the users of a tenant sign in through. Assertions must be signed with the certificate; the email is read from emailAttribute, or from the name identifier when it is empty. With jitProvisioning, users signing in for the first time get an account in the tenant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param tenant path string true "Tenant"
+// @Param request body SAMLProviderRequest true "Identity provider"
+// @Success 200 {object} response.Response{data=SAMLProviderResponse} "Identity provider saved"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/saml/providers/{tenant} [put]
+func (h *SAMLProviderHandler) SaveSAMLProvider(c *gin.Context) {
+	var req SAMLProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	provider, err := h.saml.SaveProvider(c.Request.Context(), c.Param("tenant"), domainSAML.Input{
+		EntityID:           req.EntityID,
+		SSOURL:             req.SSOURL,
+		Certificate:        req.Certificate,
+		EmailAttribute:     req.EmailAttribute,
+		FirstNameAttribute: req.FirstNameAttribute,
+		LastNameAttribute:  req.LastNameAttribute,
+		JITProvisioning:    req.JITProvisioning,
+		Enabled:            req.Enabled == nil || *req.Enabled,
+	})
+	if err != nil {
+		h.handleError(c, "SaveSAMLProvider", err)
+		return
+	}
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Info("SAML identity provider saved",
+		zap.String("tenant", provider.Tenant),
+		zap.String("entity_id", provider.EntityID),
+		zap.Bool("enabled", provider.Enabled),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, h.toSAMLProviderResponse(provider))
+}
+
+// DeleteSAMLProvider handles removing the SAML identity provider of a tenant
+// @Summary Delete SAML identity provider
+// @Description Remove the SAML identity provider of a tenant; its users can no longer sign in through it. Sessions already issued are kept.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} response.Response "Identity provider deleted"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Identity provider not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/saml/providers/{tenant} [delete]
+func (h *SAMLProviderHandler) DeleteSAMLProvider(c *gin.Context) {
+	tenant := c.Param("tenant")
+	if err := h.saml.DeleteProvider(c.Request.Context(), tenant); err != nil {
+		h.handleError(c, "DeleteSAMLProvider", err)
+		return
+	}
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Info("SAML identity provider deleted",
+		zap.String("tenant", tenant),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, gin.H{"message": "Identity provider deleted"})
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SAMLProviderHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("SAML identity provider operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func (h *SAMLProviderHandler) toSAMLProviderResponse(provider *domainSAML.IdentityProvider) SAMLProviderResponse {
+	spEntityID, acsURL := h.saml.ServiceProviderURLs(provider.Tenant)
+	return SAMLProviderResponse{
+		Tenant:             provider.Tenant,
+		EntityID:           provider.EntityID,
+		SSOURL:             provider.SSOURL,
+		Certificate:        provider.Certificate,
+		EmailAttribute:     provider.EmailAttribute,
+		FirstNameAttribute: provider.FirstNameAttribute,
+		LastNameAttribute:  provider.LastNameAttribute,
+		JITProvisioning:    provider.JITProvisioning,
+		Enabled:            provider.Enabled,
+		SPEntityID:         spEntityID,
+		ACSURL:             acsURL,
+		CreatedAt:          provider.CreatedAt,
+		UpdatedAt:          provider.UpdatedAt,
+	}
+}
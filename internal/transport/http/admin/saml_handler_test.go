@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	serviceSAML "github.com/yi-tech/go-user-service/internal/service/saml"
+)
+
+// stubSAMLProviders records the last input and answers with a fixed identity provider
+type stubSAMLProviders struct {
+	serviceSAML.Service
+	provider *domainSAML.IdentityProvider
+	err      error
+	tenant   string
+	input    domainSAML.Input
+}
+
+func (s *stubSAMLProviders) ServiceProviderURLs(tenant string) (string, string) {
+	return "https://api.example.com/api/v1/auth/saml/" + tenant + "/metadata", "https://api.example.com/api/v1/auth/saml/" + tenant + "/acs"
+}
+
+func (s *stubSAMLProviders) ListProviders(_ context.Context) ([]*domainSAML.IdentityProvider, error) {
+	return []*domainSAML.IdentityProvider{s.provider}, s.err
+}
+
+func (s *stubSAMLProviders) GetProvider(_ context.Context, tenant string) (*domainSAML.IdentityProvider, error) {
+	s.tenant = tenant
+	return s.provider, s.err
+}
+
+func (s *stubSAMLProviders) SaveProvider(_ context.Context, tenant string, input domainSAML.Input) (*domainSAML.IdentityProvider, error) {
+	s.tenant = tenant
+	s.input = input
+	return s.provider, s.err
+}
+
+func (s *stubSAMLProviders) DeleteProvider(_ context.Context, tenant string) error {
+	s.tenant = tenant
+	return s.err
+}
+
+func TestSAMLProviderHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	createdAt := time.Date(2025, 7, 7, 9, 0, 0, 0, time.UTC)
+	provider := &domainSAML.IdentityProvider{
+		Tenant:          "acme",
+		EntityID:        "https://idp.example.com",
+		Certificate:     "-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n",
+		JITProvisioning: true,
+		Enabled:         true,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+	}
+
+	serve := func(providers *stubSAMLProviders, method, path, body string) *httptest.ResponseRecorder {
+		handler := NewSAMLProviderHandler(providers, zaptest.NewLogger(t))
+		router := gin.New()
+		group := router.Group("/saml/providers")
+		group.GET("", handler.ListSAMLProviders)
+		group.GET("/:tenant", handler.GetSAMLProvider)
+		group.PUT("/:tenant", handler.SaveSAMLProvider)
+		group.DELETE("/:tenant", handler.DeleteSAMLProvider)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Get Includes Service Provider URLs", func(t *testing.T) {
+		providers := &stubSAMLProviders{provider: provider}
+
+		rr := serve(providers, http.MethodGet, "/saml/providers/acme", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "acme", providers.tenant)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"tenant":"acme","entityId":"https://idp.example.com","certificate":"-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n","jitProvisioning":true,"enabled":true,"spEntityId":"https://api.example.com/api/v1/auth/saml/acme/metadata","acsUrl":"https://api.example.com/api/v1/auth/saml/acme/acs","createdAt":"2025-07-07T09:00:00Z","updatedAt":"2025-07-07T09:00:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Save Enables By Default", func(t *testing.T) {
+		providers := &stubSAMLProviders{provider: provider}
+
+		rr := serve(providers, http.MethodPut, "/saml/providers/acme", `{"entityId":"https://idp.example.com","certificate":"MIIB","emailAttribute":"mail"}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "acme", providers.tenant)
+		assert.Equal(t, domainSAML.Input{EntityID: "https://idp.example.com", Certificate: "MIIB", EmailAttribute: "mail", Enabled: true}, providers.input)
+	})
+
+	t.Run("Save Can Disable", func(t *testing.T) {
+		providers := &stubSAMLProviders{provider: provider}
+
+		rr := serve(providers, http.MethodPut, "/saml/providers/acme", `{"entityId":"https://idp.example.com","certificate":"MIIB","enabled":false}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, providers.input.Enabled)
+	})
+
+	t.Run("Save Without Certificate", func(t *testing.T) {
+		rr := serve(&stubSAMLProviders{provider: provider}, http.MethodPut, "/saml/providers/acme", `{"entityId":"https://idp.example.com"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Save Invalid Certificate", func(t *testing.T) {
+		rr := serve(&stubSAMLProviders{err: serviceSAML.ErrInvalidCertificate}, http.MethodPut, "/saml/providers/acme", `{"entityId":"https://idp.example.com","certificate":"MIIB"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "certificate must be an RSA X.509 certificate")
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		rr := serve(&stubSAMLProviders{err: serviceSAML.ErrIdentityProviderNotFound}, http.MethodDelete, "/saml/providers/acme", "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"identity provider not found","errorCode":"SAML_PROVIDER_NOT_FOUND"}`, rr.Body.String())
+	})
+}
@@ -0,0 +1,303 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceWebhook "github.com/yi-tech/go-user-service/internal/service/webhook"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// WebhookHandler handles HTTP requests for managing webhook subscriptions
+type WebhookHandler struct {
+	webhooks serviceWebhook.Service
+	ids      idgen.Strategy // Text form of rendered IDs
+	logger   *zap.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks serviceWebhook.Service, ids idgen.Strategy, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+		ids:      ids,
+		logger:   logger,
+	}
+}
+
+// ListWebhooks handles listing webhook subscriptions
+// @Summary List webhooks
+// @Description List the endpoints registered to receive user lifecycle events, newest first. Secrets are not included.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]WebhookResponse} "Webhooks"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhooks.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "ListWebhooks", err)
+		return
+	}
+
+	data := make([]WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		data = append(data, h.toWebhookResponse(subscription))
+	}
+	response.Success(c, data)
+}
+
+// CreateWebhook handles registering a webhook subscription
+// @Summary Create webhook
+// @Description Register an endpoint to receive user.registered, user.updated or user.deleted events as signed JSON POSTs. The signing secret is generated when none is given and is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WebhookRequest true "Webhook"
+// @Success 201 {object} response.Response{data=WebhookResponse} "Webhook created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	actorID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	subscription, err := h.webhooks.Create(c.Request.Context(), actorID, toWebhookInput(req))
+	if err != nil {
+		h.handleError(c, "CreateWebhook", err)
+		return
+	}
+	h.logger.Info("Webhook created",
+		zap.String("webhook_id", subscription.ID.String()),
+		zap.String("url", subscription.URL),
+		zap.String("actor_id", actorID.String()))
+
+	resp := h.toWebhookResponse(subscription)
+	resp.Secret = subscription.Secret
+	response.Created(c, "Webhook created", resp)
+}
+
+// GetWebhook handles retrieving a webhook subscription
+// @Summary Get webhook
+// @Description Get an endpoint registered to receive user lifecycle events. The secret is not included.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} response.Response{data=WebhookResponse} "Webhook"
+// @Failure 400 {object} response.Response "Invalid webhook ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhooks.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, "GetWebhook", err)
+		return
+	}
+	response.Success(c, h.toWebhookResponse(subscription))
+}
+
+// UpdateWebhook handles replacing a webhook subscription
+// @Summary Update webhook
+// @Description Replace the URL, event types, description and state of a webhook. A secret rotates the signing secret; without one the current secret is kept.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook"
+// @Success 200 {object} response.Response{data=WebhookResponse} "Webhook updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	subscription, err := h.webhooks.Update(c.Request.Context(), id, toWebhookInput(req))
+	if err != nil {
+		h.handleError(c, "UpdateWebhook", err)
+		return
+	}
+	response.Success(c, h.toWebhookResponse(subscription))
+}
+
+// DeleteWebhook handles removing a webhook subscription
+// @Summary Delete webhook
+// @Description Remove a webhook and its delivery log; deliveries still queued for it are dropped
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} response.Response "Webhook deleted"
+// @Failure 400 {object} response.Response "Invalid webhook ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, "DeleteWebhook", err)
+		return
+	}
+	actorID, _ := requestctx.UserID(c.Request.Context())
+	h.logger.Info("Webhook deleted",
+		zap.String("webhook_id", id.String()),
+		zap.Any("actor_id", actorID))
+
+	response.Success(c, gin.H{"message": "Webhook deleted"})
+}
+
+// ListWebhookDeliveries handles listing the delivery log of a webhook
+// @Summary List webhook deliveries
+// @Description List every attempt to deliver an event to a webhook, newest first, with the response status or the error, to debug an endpoint
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=WebhookDeliveryListResponse} "Delivery log"
+// @Failure 400 {object} response.Response "Invalid webhook ID format or query parameters"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "Webhook not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	var query PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	page, pageSize := query.normalize()
+
+	filter := domainWebhook.DeliveryFilter{SubscriptionID: id, Offset: (page - 1) * pageSize, Limit: pageSize}
+	deliveries, total, err := h.webhooks.ListDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListWebhookDeliveries", err)
+		return
+	}
+
+	data := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		data = append(data, h.toWebhookDeliveryResponse(delivery))
+	}
+	response.Success(c, WebhookDeliveryListResponse{Entries: data, Total: total, Page: page, PageSize: pageSize})
+}
+
+// webhookID parses the webhook ID of the path, responding with an error when it is invalid
+func (h *WebhookHandler) webhookID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *WebhookHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Webhook operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func toWebhookInput(req WebhookRequest) domainWebhook.Input {
+	eventTypes := make([]domainWebhook.EventType, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		eventTypes = append(eventTypes, domainWebhook.EventType(t))
+	}
+	return domainWebhook.Input{
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  eventTypes,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+	}
+}
+
+func (h *WebhookHandler) toWebhookResponse(s *domainWebhook.Subscription) WebhookResponse {
+	eventTypes := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	return WebhookResponse{
+		ID:          h.ids.Format(s.ID),
+		URL:         s.URL,
+		EventTypes:  eventTypes,
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedBy:   h.ids.Format(s.CreatedBy),
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func (h *WebhookHandler) toWebhookDeliveryResponse(d *domainWebhook.Delivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:         h.ids.Format(d.ID),
+		EventID:    h.ids.Format(d.EventID),
+		EventType:  string(d.EventType),
+		Attempt:    d.Attempt,
+		Succeeded:  d.Succeeded(),
+		StatusCode: d.StatusCode,
+		Error:      d.Error,
+		DurationMs: d.Duration.Milliseconds(),
+		CreatedAt:  d.CreatedAt,
+	}
+}
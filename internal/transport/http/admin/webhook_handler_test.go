@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	domainWebhook "github.com/yi-tech/go-user-service/internal/domain/webhook"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceWebhook "github.com/yi-tech/go-user-service/internal/service/webhook"
+)
+
+// stubWebhooks records the last input and answers with a fixed subscription
+type stubWebhooks struct {
+	subscription *domainWebhook.Subscription
+	deliveries   []*domainWebhook.Delivery
+	err          error
+	input        domainWebhook.Input
+	filter       domainWebhook.DeliveryFilter
+}
+
+func (s *stubWebhooks) List(_ context.Context) ([]*domainWebhook.Subscription, error) {
+	return []*domainWebhook.Subscription{s.subscription}, s.err
+}
+
+func (s *stubWebhooks) Get(_ context.Context, _ uuid.UUID) (*domainWebhook.Subscription, error) {
+	return s.subscription, s.err
+}
+
+func (s *stubWebhooks) Create(_ context.Context, _ uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error) {
+	s.input = input
+	return s.subscription, s.err
+}
+
+func (s *stubWebhooks) Update(_ context.Context, _ uuid.UUID, input domainWebhook.Input) (*domainWebhook.Subscription, error) {
+	s.input = input
+	return s.subscription, s.err
+}
+
+func (s *stubWebhooks) Delete(_ context.Context, _ uuid.UUID) error {
+	return s.err
+}
+
+func (s *stubWebhooks) ListDeliveries(_ context.Context, filter domainWebhook.DeliveryFilter) ([]*domainWebhook.Delivery, int64, error) {
+	s.filter = filter
+	return s.deliveries, int64(len(s.deliveries)), s.err
+}
+
+func TestWebhookHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	actorID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	subscriptionID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	createdAt := time.Date(2025, 7, 6, 9, 0, 0, 0, time.UTC)
+	subscription := &domainWebhook.Subscription{
+		ID:         subscriptionID,
+		URL:        "https://hooks.example.com/users",
+		Secret:     "whsec_generated",
+		EventTypes: []domainWebhook.EventType{domainWebhook.EventUserRegistered},
+		Active:     true,
+		CreatedBy:  actorID,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+
+	serve := func(webhooks *stubWebhooks, method, path, body string) *httptest.ResponseRecorder {
+		handler := NewWebhookHandler(webhooks, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+		router := gin.New()
+		group := router.Group("/webhooks", func(c *gin.Context) { requestctx.SetUserID(c, actorID) })
+		group.GET("", handler.ListWebhooks)
+		group.POST("", handler.CreateWebhook)
+		group.GET("/:id", handler.GetWebhook)
+		group.PUT("/:id", handler.UpdateWebhook)
+		group.DELETE("/:id", handler.DeleteWebhook)
+		group.GET("/:id/deliveries", handler.ListWebhookDeliveries)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Create Returns Secret Once", func(t *testing.T) {
+		webhooks := &stubWebhooks{subscription: subscription}
+
+		rr := serve(webhooks, http.MethodPost, "/webhooks", `{"url":"https://hooks.example.com/users","eventTypes":["user.registered"]}`)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.True(t, webhooks.input.Active)
+		assert.Equal(t, []domainWebhook.EventType{domainWebhook.EventUserRegistered}, webhooks.input.EventTypes)
+		assert.Contains(t, rr.Body.String(), `"secret":"whsec_generated"`)
+
+		rr = serve(webhooks, http.MethodGet, "/webhooks/"+subscriptionID.String(), "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"id":"22222222-2222-2222-2222-222222222222","url":"https://hooks.example.com/users","eventTypes":["user.registered"],"active":true,"createdBy":"11111111-1111-1111-1111-111111111111","createdAt":"2025-07-06T09:00:00Z","updatedAt":"2025-07-06T09:00:00Z"}}`, rr.Body.String())
+	})
+
+	t.Run("Create Without Event Types", func(t *testing.T) {
+		rr := serve(&stubWebhooks{subscription: subscription}, http.MethodPost, "/webhooks", `{"url":"https://hooks.example.com/users"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Update Can Deactivate", func(t *testing.T) {
+		webhooks := &stubWebhooks{subscription: subscription}
+
+		rr := serve(webhooks, http.MethodPut, "/webhooks/"+subscriptionID.String(), `{"url":"https://hooks.example.com/users","eventTypes":["user.deleted"],"active":false}`)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, webhooks.input.Active)
+		assert.NotContains(t, rr.Body.String(), "secret")
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		rr := serve(&stubWebhooks{err: serviceWebhook.ErrWebhookNotFound}, http.MethodDelete, "/webhooks/"+subscriptionID.String(), "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"code":404,"message":"webhook not found","errorCode":"WEBHOOK_NOT_FOUND"}`, rr.Body.String())
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		rr := serve(&stubWebhooks{}, http.MethodGet, "/webhooks/not-an-id", "")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("List Deliveries", func(t *testing.T) {
+		eventID := uuid.MustParse("33333333-3333-3333-3333-333333333333")
+		webhooks := &stubWebhooks{deliveries: []*domainWebhook.Delivery{{
+			ID:             uuid.MustParse("44444444-4444-4444-4444-444444444444"),
+			SubscriptionID: subscriptionID,
+			EventID:        eventID,
+			EventType:      domainWebhook.EventUserRegistered,
+			Attempt:        2,
+			StatusCode:     http.StatusServiceUnavailable,
+			Error:          "webhook responded with status 503",
+			Duration:       120 * time.Millisecond,
+			CreatedAt:      createdAt,
+		}}}
+
+		rr := serve(webhooks, http.MethodGet, "/webhooks/"+subscriptionID.String()+"/deliveries?page=2&page_size=10", "")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, domainWebhook.DeliveryFilter{SubscriptionID: subscriptionID, Offset: 10, Limit: 10}, webhooks.filter)
+		assert.JSONEq(t, `{"code":200,"message":"Success","data":{"entries":[{"id":"44444444-4444-4444-4444-444444444444","eventId":"33333333-3333-3333-3333-333333333333","eventType":"user.registered","attempt":2,"succeeded":false,"statusCode":503,"error":"webhook responded with status 503","durationMs":120,"createdAt":"2025-07-06T09:00:00Z"}],"total":1,"page":2,"pageSize":10}}`, rr.Body.String())
+	})
+}
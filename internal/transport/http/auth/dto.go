@@ -1,19 +1,56 @@
 package auth
 
+import (
+	"time"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
 // LoginRequest defines the user login request structure
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	RememberMe bool   `json:"rememberMe"` // Keeps the session signed in for longer
 }
 
 // LoginResponse defines the user login response structure
 type LoginResponse struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
-	ExpiresIn    int64  `json:"expiresIn"` // Access token expiry time in seconds
+	AccessToken      string     `json:"accessToken"`
+	RefreshToken     string     `json:"refreshToken"`
+	ExpiresIn        int64      `json:"expiresIn"` // Access token expiry time in seconds
+	ExpiresAt        time.Time  `json:"expiresAt"` // Access token expiry time
+	RefreshExpiresAt *time.Time `json:"refreshExpiresAt,omitempty"`
+}
+
+// newLoginResponse describes an issued token pair
+func newLoginResponse(tokens *domainAuth.TokenPair) LoginResponse {
+	resp := LoginResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    max(int64(time.Until(tokens.AccessTokenExpiresAt).Round(time.Second)/time.Second), 0),
+		ExpiresAt:    tokens.AccessTokenExpiresAt,
+	}
+	if !tokens.RefreshTokenExpiresAt.IsZero() {
+		resp.RefreshExpiresAt = &tokens.RefreshTokenExpiresAt
+	}
+	return resp
 }
 
 // RefreshTokenRequest defines the refresh token request structure
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
+
+// LogoutRequest defines the logout request structure. The body is optional
+// when the request carries an access token.
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// PasswordResetRequest defines the request to complete an administrator-forced
+// password reset or to replace an expired password
+type PasswordResetRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
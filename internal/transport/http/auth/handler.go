@@ -1,20 +1,23 @@
 package auth
 
 import (
-	"fmt"
-
-	"errors" // Added for errors.Is
+	"errors"
+	"io"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
-	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth" // Import for sentinel errors
+	"github.com/yi-tech/go-user-service/internal/requestctx"
 	// userService "github.com/yi-tech/go-user-service/internal/service/user" // For userService.ErrUserNotFound if needed directly
 	"github.com/yi-tech/go-user-service/internal/transport/http/response"
 )
 
+// CaptchaHeader carries the CAPTCHA token required after repeated failed sign-ins
+const CaptchaHeader = "X-Captcha-Token"
+
 // Handler handles HTTP requests for authentication operations
 type Handler struct {
 	authService domainAuth.AuthService
@@ -31,14 +34,16 @@ func NewHandler(authService domainAuth.AuthService, logger *zap.Logger) *Handler
 
 // Login handles user login
 // @Summary User login
-// @Description Authenticate a user and return access and refresh tokens
+// @Description Authenticate a user and return access and refresh tokens with their expiry. With rememberMe the refresh token lasts jwt.remember_me_refresh_token_expire_days instead of jwt.refresh_token_expire_days. After repeated failed attempts from the client or against the account, a CAPTCHA token is required in the X-Captcha-Token header.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
+// @Param X-Captcha-Token header string false "CAPTCHA token, required after repeated failed attempts"
 // @Success 200 {object} response.Response{data=LoginResponse} "Successfully authenticated"
 // @Failure 400 {object} response.Response "Invalid request data"
 // @Failure 401 {object} response.Response "Invalid email or password"
+// @Failure 403 {object} response.Response "CAPTCHA required or rejected, account disabled, or password reset required or expired (errorCode PASSWORD_EXPIRED; change it with /auth/password-reset)"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
@@ -53,42 +58,41 @@ func (h *Handler) Login(c *gin.Context) {
 
 	// Create domainAuth.LoginInput from the request
 	loginInput := domainAuth.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:        req.Email,
+		Password:     req.Password,
+		UserAgent:    c.Request.UserAgent(),
+		ClientIP:     clientip.FromRequest(c.Request),
+		CaptchaToken: c.GetHeader(CaptchaHeader),
+		RememberMe:   req.RememberMe,
 	}
 
 	// Authenticate user
 	tokenPair, err := h.authService.Login(c.Request.Context(), loginInput)
 	if err != nil {
-		if errors.Is(err, serviceAuth.ErrInvalidCredentials) {
-			h.logger.Info("Login attempt failed: invalid credentials", // This log is fine
+		if appErr, ok := apperror.As(err); ok {
+			h.logger.Info("Login attempt failed",
 				zap.String("operation", "Login"),
+				zap.String("error_code", string(appErr.Code)),
 				zap.String("email", req.Email))
-			response.Unauthorized(c, serviceAuth.ErrInvalidCredentials.Error())
-			return // This return was correctly placed. The issue might be in test expectation or mock.
+			response.AppError(c, appErr)
+			return
 		}
 		// For other (unexpected) errors, Error level is appropriate.
 		h.logger.Error("Login error (unexpected)", // Clarified log message
 			zap.String("operation", "Login"),
 			zap.Error(err), // This err is not ErrInvalidCredentials here
 			zap.String("email", req.Email))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	// Create response data
-	loginData := LoginResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    3600, // Placeholder for access token lifetime (e.g., 1 hour)
-	}
-
-	response.Success(c, loginData)
+	response.Success(c, newLoginResponse(tokenPair))
 }
 
 // RefreshToken handles refreshing an access token
 // @Summary Refresh access token
-// @Description Refresh an access token using a valid refresh token
+// @Description Refresh an access token using a valid refresh token. The new refresh token keeps the lifetime of the one it replaces.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -111,58 +115,120 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	// Refresh token
 	tokenPair, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		if errors.Is(err, serviceAuth.ErrInvalidOrExpiredToken) {
-			h.logger.Info("Refresh token failed: invalid or expired", // This log is fine
-				zap.String("operation", "RefreshToken"),
-				zap.Error(err)) // err here is ErrInvalidOrExpiredToken
-			response.Unauthorized(c, serviceAuth.ErrInvalidOrExpiredToken.Error())
-			return // This return was correctly placed.
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
+			return
 		}
 		// For other (unexpected) errors
 		h.logger.Error("Failed to refresh token (unexpected)", // Clarified log message
 			zap.String("operation", "RefreshToken"),
 			zap.Error(err)) // This err is not ErrInvalidOrExpiredToken here
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	// Create response data
-	responseData := LoginResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    3600, // Placeholder for access token lifetime
+	response.Success(c, newLoginResponse(tokenPair))
+}
+
+// CompletePasswordReset handles setting a new password after an administrator
+// forced a reset or the password expired
+// @Summary Complete a forced password reset
+// @Description Replace the password of an account flagged for a reset, or whose password has expired, and return access and refresh tokens. The new password must differ from the current one.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body PasswordResetRequest true "Current credentials and new password"
+// @Success 200 {object} response.Response{data=LoginResponse} "Password replaced and authenticated"
+// @Failure 400 {object} response.Response "Invalid request data, no reset pending or password reused"
+// @Failure 401 {object} response.Response "Invalid email or password"
+// @Failure 403 {object} response.Response "Account is disabled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /auth/password-reset [post]
+func (h *Handler) CompletePasswordReset(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid password reset request",
+			zap.String("operation", "CompletePasswordReset"),
+			zap.Error(err))
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	tokenPair, err := h.authService.CompletePasswordReset(c.Request.Context(), domainAuth.PasswordResetInput{
+		Email:           req.Email,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+		UserAgent:       c.Request.UserAgent(),
+		ClientIP:        clientip.FromRequest(c.Request),
+	})
+	if err != nil {
+		if appErr, ok := apperror.As(err); ok {
+			h.logger.Info("Password reset attempt failed",
+				zap.String("operation", "CompletePasswordReset"),
+				zap.String("error_code", string(appErr.Code)),
+				zap.String("email", req.Email))
+			response.AppError(c, appErr)
+			return
+		}
+		h.logger.Error("Password reset error (unexpected)",
+			zap.String("operation", "CompletePasswordReset"),
+			zap.Error(err),
+			zap.String("email", req.Email))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
 	}
 
-	response.Success(c, responseData)
+	response.Success(c, newLoginResponse(tokenPair))
 }
 
 // Logout handles user logout
 // @Summary User logout
-// @Description Invalidate the user's refresh token
+// @Description Invalidate the user's refresh token. Either present the refresh token in the body, which works once the access token has expired, or authenticate with the access token. Unknown or expired refresh tokens are ignored.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body LogoutRequest false "Refresh token of the session to end"
 // @Success 200 {object} response.Response "Logged out successfully"
+// @Failure 400 {object} response.Response "Invalid request data"
 // @Failure 401 {object} response.Response "Authentication required"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /auth/logout [post]
 func (h *Handler) Logout(c *gin.Context) {
-	// Get user ID from context (assuming it's set by auth middleware)
-	userIDRaw, exists := c.Get("userID") // Changed "user_id" to "userID"
-	if !exists {
-		response.Unauthorized(c, "Authentication required")
+	// The body is optional; without one the caller is identified by the access token
+	var req LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			h.logger.Warn("Invalid logout request",
+				zap.String("operation", "Logout"),
+				zap.Error(err))
+			response.BadRequest(c, "Invalid request data")
+			return
+		}
+	}
+	if req.RefreshToken != "" {
+		if err := h.authService.LogoutByRefreshToken(c.Request.Context(), req.RefreshToken); err != nil {
+			if appErr, ok := apperror.As(err); ok {
+				response.AppError(c, appErr)
+				return
+			}
+			h.logger.Error("Failed to logout by refresh token",
+				zap.String("operation", "Logout"),
+				zap.Error(err))
+			_ = c.Error(err)
+			response.InternalServerError(c, "Something went wrong. Please try again later.")
+			return
+		}
+		response.Success(c, gin.H{"message": "Logged out successfully"})
 		return
 	}
 
-	// Assert userID to uuid.UUID
-	userIDUUID, ok := userIDRaw.(uuid.UUID)
+	// Get user ID from context (set by the optional auth middleware)
+	userIDUUID, ok := requestctx.UserID(c.Request.Context())
 	if !ok {
-		h.logger.Error("Failed to assert user ID to uuid.UUID for logout",
-			zap.String("operation", "Logout"),
-			zap.Any("user_id_type", fmt.Sprintf("%T", userIDRaw)),
-			zap.Any("user_id_value", userIDRaw))
-		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		response.Unauthorized(c, "Authentication required")
 		return
 	}
 
@@ -173,6 +239,7 @@ func (h *Handler) Logout(c *gin.Context) {
 			zap.String("operation", "Logout"),
 			zap.Error(err),
 			zap.String("user_id", userIDUUID.String()))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth"
+)
+
+// fuzzAuthRequest posts body to a single route and checks the response is a JSON envelope
+func fuzzAuthRequest(t *testing.T, path string, handle gin.HandlerFunc, body string) int {
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.POST(path, handle)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rr, req)
+
+	require.True(t, json.Valid(rr.Body.Bytes()), "response is not JSON: %q", rr.Body.String())
+	return rr.Code
+}
+
+func FuzzLoginBinding(f *testing.F) {
+	gin.SetMode(gin.TestMode)
+
+	f.Add(`{"email":"test@example.com","password":"password123"}`)
+	f.Add(`{"email":"not-an-email","password":"password123"}`)
+	f.Add(`{"email":"test@example.com","password":""}`)
+	f.Add(`{"email":["test@example.com"],"password":{}}`)
+	f.Add(`{"email":"test@example.com","password":"x","email":null}`)
+	f.Add(`[]`)
+	f.Add(`null`)
+	f.Add(`{"email":"\ud800@example.com","password":"\u0000"}`)
+	f.Add(`{`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		mockService := new(MockAuthService)
+		mockService.On("Login", mock.Anything, mock.Anything).Return(nil, serviceAuth.ErrInvalidCredentials)
+		handler := NewHandler(mockService, zap.NewNop())
+
+		code := fuzzAuthRequest(t, "/auth/login", handler.Login, body)
+
+		require.Contains(t, []int{http.StatusBadRequest, http.StatusUnauthorized}, code)
+		if code == http.StatusUnauthorized {
+			// Only requests that passed binding may reach the service
+			input := mockService.Calls[0].Arguments.Get(1).(domainAuth.LoginInput)
+			require.NotEmpty(t, input.Email)
+			require.NotEmpty(t, input.Password)
+		}
+	})
+}
+
+func FuzzRefreshTokenBinding(f *testing.F) {
+	gin.SetMode(gin.TestMode)
+
+	f.Add(`{"refreshToken":"valid-refresh-token"}`)
+	f.Add(`{"refreshToken":""}`)
+	f.Add(`{"refreshToken":123}`)
+	f.Add(`{"refresh_token":"valid-refresh-token"}`)
+	f.Add(`{"refreshToken":"` + strings.Repeat("a", 1024) + `"}`)
+	f.Add(`""`)
+	f.Add(``)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		mockService := new(MockAuthService)
+		mockService.On("RefreshToken", mock.Anything, mock.Anything).Return(nil, serviceAuth.ErrInvalidOrExpiredToken)
+		handler := NewHandler(mockService, zap.NewNop())
+
+		code := fuzzAuthRequest(t, "/auth/refresh", handler.RefreshToken, body)
+
+		require.Contains(t, []int{http.StatusBadRequest, http.StatusUnauthorized}, code)
+		if code == http.StatusUnauthorized {
+			require.NotEmpty(t, mockService.Calls[0].Arguments.String(1))
+		}
+	})
+}
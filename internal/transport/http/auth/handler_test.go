@@ -5,17 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth" // Alias for domain auth types
+	"github.com/yi-tech/go-user-service/internal/requestctx"
 	serviceAuth "github.com/yi-tech/go-user-service/internal/service/auth" // Import for sentinel errors
 	"go.uber.org/zap/zaptest"
 )
@@ -49,6 +52,12 @@ func (m *MockAuthService) Logout(ctx context.Context, userID uuid.UUID) error {
 	return args.Error(0)
 }
 
+// LogoutByRefreshToken mocks the LogoutByRefreshToken method.
+func (m *MockAuthService) LogoutByRefreshToken(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
+	return args.Error(0)
+}
+
 // ValidateToken mocks the ValidateToken method.
 // This method is part of the auth.AuthService interface but not directly used by this HTTP handler.
 // We include it to fully implement the interface for the mock.
@@ -63,14 +72,70 @@ func (m *MockAuthService) ValidateToken(ctx context.Context, token string) (uuid
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
+// Authenticate mocks the Authenticate method.
+func (m *MockAuthService) Authenticate(ctx context.Context, token string) (uuid.UUID, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// AuthenticatePrincipal mocks the AuthenticatePrincipal method.
+func (m *MockAuthService) AuthenticatePrincipal(ctx context.Context, token string) (domainAuth.Principal, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(domainAuth.Principal), args.Error(1)
+}
+
+// Impersonate mocks the Impersonate method.
+func (m *MockAuthService) Impersonate(ctx context.Context, actorID, subjectID uuid.UUID, reason string) (*domainAuth.ImpersonationToken, error) {
+	args := m.Called(ctx, actorID, subjectID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.ImpersonationToken), args.Error(1)
+}
+
+// RevokeImpersonation mocks the RevokeImpersonation method.
+func (m *MockAuthService) RevokeImpersonation(ctx context.Context, id uuid.UUID) (*domainAuth.Impersonation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.Impersonation), args.Error(1)
+}
+
+// LoginExternal mocks the LoginExternal method.
+func (m *MockAuthService) LoginExternal(ctx context.Context, input domainAuth.ExternalLoginInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
+// CompletePasswordReset mocks the CompletePasswordReset method.
+func (m *MockAuthService) CompletePasswordReset(ctx context.Context, input domainAuth.PasswordResetInput) (*domainAuth.TokenPair, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAuth.TokenPair), args.Error(1)
+}
+
 // createMockTokenPair is a helper function to create a mock domainAuth.TokenPair for testing
 func createMockTokenPair() *domainAuth.TokenPair {
 	return &domainAuth.TokenPair{
-		AccessToken:  "mock-access-token",
-		RefreshToken: "mock-refresh-token",
+		AccessToken:           "mock-access-token",
+		RefreshToken:          "mock-refresh-token",
+		AccessTokenExpiresAt:  time.Now().Add(15 * time.Minute),
+		RefreshTokenExpiresAt: time.Now().Add(7 * 24 * time.Hour),
 	}
 }
 
+// tokenResponseBody is the expected response body for a mock token pair
+func tokenResponseBody(pair *domainAuth.TokenPair) string {
+	return fmt.Sprintf(`{"code":200,"message":"Success","data":{"accessToken":"mock-access-token","refreshToken":"mock-refresh-token","expiresIn":900,"expiresAt":%q,"refreshExpiresAt":%q}}`,
+		pair.AccessTokenExpiresAt.Format(time.RFC3339Nano), pair.RefreshTokenExpiresAt.Format(time.RFC3339Nano))
+}
+
 func TestNewHandler(t *testing.T) {
 	mockService := new(MockAuthService)
 	logger := zaptest.NewLogger(t)
@@ -87,6 +152,7 @@ func TestLogin(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	mockTokenPair := createMockTokenPair() // Use new helper
+	accessOnlyExpiresAt := time.Now().Add(15 * time.Minute)
 
 	tests := []struct {
 		name           string
@@ -102,7 +168,25 @@ func TestLogin(t *testing.T) {
 				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "test@example.com", Password: "password"}).Return(mockTokenPair, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"code":200,"message":"Success","data":{"accessToken":"mock-access-token","refreshToken":"mock-refresh-token","expiresIn":3600}}`,
+			expectedBody:   tokenResponseBody(mockTokenPair),
+		},
+		{
+			name: "Remember Me",
+			body: gin.H{"email": "test@example.com", "password": "password", "rememberMe": true},
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "test@example.com", Password: "password", RememberMe: true}).Return(mockTokenPair, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   tokenResponseBody(mockTokenPair),
+		},
+		{
+			name: "Access Token Only",
+			body: gin.H{"email": "stateless@example.com", "password": "password"},
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("Login", mock.Anything, domainAuth.LoginInput{Email: "stateless@example.com", Password: "password"}).Return(&domainAuth.TokenPair{AccessToken: "mock-access-token", AccessTokenExpiresAt: accessOnlyExpiresAt}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   fmt.Sprintf(`{"code":200,"message":"Success","data":{"accessToken":"mock-access-token","refreshToken":"","expiresIn":900,"expiresAt":%q}}`, accessOnlyExpiresAt.Format(time.RFC3339Nano)),
 		},
 		{
 			name:           "Invalid Request Data - Bad JSON",
@@ -129,7 +213,7 @@ func TestLogin(t *testing.T) {
 			},
 			expectedStatus: http.StatusUnauthorized,
 			// The message should now match ErrInvalidCredentials.Error()
-			expectedBody:   `{"code":401,"message":"invalid credentials"}`,
+			expectedBody:   `{"code":401,"message":"invalid credentials","errorCode":"INVALID_CREDENTIALS"}`,
 		},
 		{
 			name: "Internal ServerError",
@@ -197,7 +281,7 @@ func TestRefreshToken(t *testing.T) {
 				mockService.On("RefreshToken", mock.AnythingOfType("*gin.Context"), "valid-refresh-token").Return(mockTokenPair, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"code":200,"message":"Success","data":{"accessToken":"mock-access-token","refreshToken":"mock-refresh-token","expiresIn":3600}}`,
+			expectedBody:   tokenResponseBody(mockTokenPair),
 		},
 		{
 			name:           "Invalid Request Data - Bad JSON",
@@ -224,7 +308,7 @@ func TestRefreshToken(t *testing.T) {
 			},
 			expectedStatus: http.StatusUnauthorized,
 			// The message should now match ErrInvalidOrExpiredToken.Error()
-			expectedBody:   `{"code":401,"message":"invalid or expired refresh token"}`,
+			expectedBody:   `{"code":401,"message":"invalid or expired refresh token","errorCode":"INVALID_OR_EXPIRED_TOKEN"}`,
 		},
 		{
 			name: "Internal Server Error on Refresh",
@@ -272,12 +356,89 @@ func TestRefreshToken(t *testing.T) {
 	}
 }
 
+func TestCompletePasswordReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := zaptest.NewLogger(t)
+
+	mockTokenPair := createMockTokenPair()
+	body := gin.H{"email": "test@example.com", "currentPassword": "password123", "newPassword": "newPassword456"}
+	input := mock.MatchedBy(func(in domainAuth.PasswordResetInput) bool {
+		return in.Email == "test@example.com" && in.CurrentPassword == "password123" && in.NewPassword == "newPassword456"
+	})
+
+	tests := []struct {
+		name           string
+		body           gin.H
+		setupMock      func(mockService *MockAuthService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: body,
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("CompletePasswordReset", mock.Anything, input).Return(mockTokenPair, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   tokenResponseBody(mockTokenPair),
+		},
+		{
+			name:           "New Password Too Short",
+			body:           gin.H{"email": "test@example.com", "currentPassword": "password123", "newPassword": "short"},
+			setupMock:      func(mockService *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name: "No Reset Pending",
+			body: body,
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("CompletePasswordReset", mock.Anything, input).Return(nil, serviceAuth.ErrNoPasswordReset)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"no password reset is pending for this account","errorCode":"INVALID_ARGUMENT"}`,
+		},
+		{
+			name: "Internal Server Error",
+			body: body,
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("CompletePasswordReset", mock.Anything, input).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tc.setupMock(mockService)
+
+			handler := NewHandler(mockService, logger)
+			router := gin.New()
+			router.POST("/password-reset", handler.CompletePasswordReset)
+
+			jsonBody, _ := json.Marshal(tc.body)
+			req := httptest.NewRequest(http.MethodPost, "/password-reset", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestLogout(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger := zaptest.NewLogger(t)
 
 	tests := []struct {
 		name           string
+		body           string
 		setupContext   func(c *gin.Context) // Changed to modify the handler's actual context
 		setupMock      func(mockService *MockAuthService)
 		expectedStatus int
@@ -287,7 +448,7 @@ func TestLogout(t *testing.T) {
 			name: "Success",
 			setupContext: func(c *gin.Context) {
 				userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
-				c.Set("userID", userID) // Corrected context key
+				requestctx.SetUserID(c, userID) // Set by the optional auth middleware
 			},
 			setupMock: func(mockService *MockAuthService) {
 				userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
@@ -296,6 +457,50 @@ func TestLogout(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"code":200,"message":"Success","data":{"message":"Logged out successfully"}}`,
 		},
+		{
+			name: "Refresh Token Without Access Token",
+			body: `{"refreshToken": "valid-refresh-token"}`,
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"message":"Logged out successfully"}}`,
+		},
+		{
+			name: "Refresh Token Takes Precedence",
+			body: `{"refreshToken": "valid-refresh-token"}`,
+			setupContext: func(c *gin.Context) {
+				requestctx.SetUserID(c, uuid.New())
+			},
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"message":"Logged out successfully"}}`,
+		},
+		{
+			name: "Refresh Token Store Unavailable",
+			body: `{"refreshToken": "valid-refresh-token"}`,
+			setupMock: func(mockService *MockAuthService) {
+				mockService.On("LogoutByRefreshToken", mock.Anything, "valid-refresh-token").Return(serviceAuth.ErrAuthStoreUnavailable)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"code":503,"message":"` + serviceAuth.ErrAuthStoreUnavailable.Message + `","errorCode":"SERVICE_UNAVAILABLE"}`,
+		},
+		{
+			name:           "Invalid Request Data - Bad JSON",
+			body:           `{"refreshToken": "valid-refresh-token"`,
+			setupMock:      func(mockService *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:           "Authentication Required - Empty Refresh Token",
+			body:           `{"refreshToken": ""}`,
+			setupMock:      func(mockService *MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":401,"message":"Authentication required"}`,
+		},
 		{
 			name:           "Authentication Required - No User ID in Context",
 			setupContext:   nil, // No context setup needed, or func(c *gin.Context) {}
@@ -304,19 +509,19 @@ func TestLogout(t *testing.T) {
 			expectedBody:   `{"code":401,"message":"Authentication required"}`,
 		},
 		{
-			name: "Internal Server Error - Invalid User ID Type in Context",
+			name: "Authentication Required - Nil User ID in Context",
 			setupContext: func(c *gin.Context) {
-				c.Set("userID", "not-a-uuid") // Corrected context key, set user_id as string
+				requestctx.SetUserID(c, uuid.Nil)
 			},
 			setupMock:      func(mockService *MockAuthService) {},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"code":401,"message":"Authentication required"}`,
 		},
 		{
 			name: "Internal Server Error - Logout Fails",
 			setupContext: func(c *gin.Context) {
 				userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
-				c.Set("userID", userID) // Corrected context key
+				requestctx.SetUserID(c, userID) // Set by the optional auth middleware
 			},
 			setupMock: func(mockService *MockAuthService) {
 				userID, _ := uuid.Parse("00000000-0000-0000-0000-000000000123")
@@ -348,7 +553,14 @@ func TestLogout(t *testing.T) {
 
 			// Create a plain request. The context of this request (req.Context()) will be available
 			// to the service via c.Request.Context(), and is what mock.AnythingOfType("context.Context") matches.
-			req, _ := http.NewRequest(http.MethodPost, "/logout", nil)
+			var reqBody io.Reader
+			if tc.body != "" {
+				reqBody = strings.NewReader(tc.body)
+			}
+			req, _ := http.NewRequest(http.MethodPost, "/logout", reqBody)
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
 
 			router.ServeHTTP(rr, req)
 
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	serviceSAML "github.com/yi-tech/go-user-service/internal/service/saml"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// samlMetadataContentType is the media type of SAML metadata documents
+const samlMetadataContentType = "application/samlmetadata+xml"
+
+// SAMLHandler handles the SAML service provider endpoints of tenants
+type SAMLHandler struct {
+	saml   serviceSAML.Service
+	logger *zap.Logger
+}
+
+// NewSAMLHandler creates a new SAML handler
+func NewSAMLHandler(saml serviceSAML.Service, logger *zap.Logger) *SAMLHandler {
+	return &SAMLHandler{
+		saml:   saml,
+		logger: logger,
+	}
+}
+
+// Metadata handles serving the service provider metadata of a tenant
+// @Summary SAML service provider metadata
+// @Description Return the SAML metadata to configure the identity provider of the tenant with: the entity ID and the HTTP-POST assertion consumer service URL.
+// @Tags auth
+// @Produce application/samlmetadata+xml
+// @Param tenant path string true "Tenant"
+// @Success 200 {string} string "Service provider metadata"
+// @Failure 400 {object} response.Response "Invalid tenant"
+// @Failure 404 {object} response.Response "SAML sign-in is not enabled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /auth/saml/{tenant}/metadata [get]
+func (h *SAMLHandler) Metadata(c *gin.Context) {
+	metadata, err := h.saml.Metadata(c.Request.Context(), c.Param("tenant"))
+	if err != nil {
+		h.handleError(c, "Metadata", err)
+		return
+	}
+	c.Data(http.StatusOK, samlMetadataContentType, metadata)
+}
+
+// AssertionConsumer handles a SAML response posted by the identity provider of a tenant
+// @Summary SAML assertion consumer service
+// @Description Validate the signed SAML response the identity provider of the tenant posted and return access and refresh tokens for the user it asserts. Only IdP-initiated sign-in is supported. The account must belong to the tenant; unless the identity provider has just-in-time provisioning enabled it must already exist. Each assertion is accepted once.
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param SAMLResponse formData string true "Base64 SAML response"
+// @Success 200 {object} response.Response{data=LoginResponse} "Successfully authenticated"
+// @Failure 400 {object} response.Response "Missing SAMLResponse"
+// @Failure 401 {object} response.Response "Invalid, expired or replayed assertion"
+// @Failure 403 {object} response.Response "Account not provisioned, of another tenant, or disabled"
+// @Failure 404 {object} response.Response "No enabled identity provider for the tenant"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /auth/saml/{tenant}/acs [post]
+func (h *SAMLHandler) AssertionConsumer(c *gin.Context) {
+	samlResponse := c.PostForm("SAMLResponse")
+	if samlResponse == "" {
+		response.BadRequest(c, "Missing SAMLResponse")
+		return
+	}
+
+	tokenPair, err := h.saml.SignIn(c.Request.Context(), domainSAML.SignInInput{
+		Tenant:       c.Param("tenant"),
+		SAMLResponse: samlResponse,
+		UserAgent:    c.Request.UserAgent(),
+		ClientIP:     clientip.FromRequest(c.Request),
+	})
+	if err != nil {
+		h.handleError(c, "AssertionConsumer", err)
+		return
+	}
+
+	response.Success(c, newLoginResponse(tokenPair))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SAMLHandler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		h.logger.Info("SAML request rejected",
+			zap.String("operation", operation),
+			zap.String("tenant", c.Param("tenant")),
+			zap.String("error_code", string(appErr.Code)))
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("SAML operation failed",
+		zap.String("operation", operation),
+		zap.String("tenant", c.Param("tenant")),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
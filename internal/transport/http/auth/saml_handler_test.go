@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+	domainSAML "github.com/yi-tech/go-user-service/internal/domain/saml"
+	serviceSAML "github.com/yi-tech/go-user-service/internal/service/saml"
+)
+
+// stubSAML records the last sign-in and answers with fixed results
+type stubSAML struct {
+	serviceSAML.Service
+	metadata []byte
+	tokens   *domainAuth.TokenPair
+	err      error
+	input    domainSAML.SignInInput
+}
+
+func (s *stubSAML) Metadata(_ context.Context, _ string) ([]byte, error) {
+	return s.metadata, s.err
+}
+
+func (s *stubSAML) SignIn(_ context.Context, input domainSAML.SignInInput) (*domainAuth.TokenPair, error) {
+	s.input = input
+	return s.tokens, s.err
+}
+
+func TestSAMLHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockTokenPair := createMockTokenPair()
+
+	serve := func(saml *stubSAML, method, path string, form url.Values) *httptest.ResponseRecorder {
+		handler := NewSAMLHandler(saml, zaptest.NewLogger(t))
+		router := gin.New()
+		router.GET("/saml/:tenant/metadata", handler.Metadata)
+		router.POST("/saml/:tenant/acs", handler.AssertionConsumer)
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Metadata", func(t *testing.T) {
+		rr := serve(&stubSAML{metadata: []byte("<EntityDescriptor/>")}, http.MethodGet, "/saml/acme/metadata", nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/samlmetadata+xml", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "<EntityDescriptor/>", rr.Body.String())
+	})
+
+	t.Run("Metadata When Disabled", func(t *testing.T) {
+		rr := serve(&stubSAML{err: serviceSAML.ErrSAMLDisabled}, http.MethodGet, "/saml/acme/metadata", nil)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Sign In", func(t *testing.T) {
+		saml := &stubSAML{tokens: mockTokenPair}
+
+		rr := serve(saml, http.MethodPost, "/saml/acme/acs", url.Values{"SAMLResponse": {"PHJlc3BvbnNlLz4="}, "RelayState": {"/"}})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, tokenResponseBody(mockTokenPair), rr.Body.String())
+		assert.Equal(t, "acme", saml.input.Tenant)
+		assert.Equal(t, "PHJlc3BvbnNlLz4=", saml.input.SAMLResponse)
+		assert.Equal(t, "Mozilla/5.0", saml.input.UserAgent)
+	})
+
+	t.Run("Missing SAMLResponse", func(t *testing.T) {
+		saml := &stubSAML{tokens: mockTokenPair}
+
+		rr := serve(saml, http.MethodPost, "/saml/acme/acs", url.Values{})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Empty(t, saml.input.Tenant)
+	})
+
+	t.Run("Invalid Assertion", func(t *testing.T) {
+		rr := serve(&stubSAML{err: serviceSAML.ErrInvalidAssertion}, http.MethodPost, "/saml/acme/acs", url.Values{"SAMLResponse": {"PHJlc3BvbnNlLz4="}})
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.JSONEq(t, `{"code":401,"message":"invalid SAML assertion","errorCode":"INVALID_ASSERTION"}`, rr.Body.String())
+	})
+
+	t.Run("Unexpected Error", func(t *testing.T) {
+		rr := serve(&stubSAML{err: errors.New("redis down")}, http.MethodPost, "/saml/acme/acs", url.Values{"SAMLResponse": {"PHJlc3BvbnNlLz4="}})
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "redis")
+	})
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	"github.com/yi-tech/go-user-service/internal/buildinfo"
+	"github.com/yi-tech/go-user-service/internal/health"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// Reporter reports the latest health of each dependency. health.Monitor satisfies it.
+type Reporter interface {
+	Snapshot() []health.DependencyState
+}
+
+// Breakers reports the state of the circuit breakers. breaker.Group satisfies it.
+type Breakers interface {
+	Snapshot() []breaker.Snapshot
+}
+
+// Settings describes the active configuration with its secrets redacted.
+// *config.Config satisfies it.
+type Settings interface {
+	Redacted() map[string]any
+}
+
+// Handler serves the health of the service dependencies and diagnostics of this instance
+type Handler struct {
+	reporter Reporter
+	breakers Breakers
+	settings Settings
+}
+
+// NewHandler creates a new health handler; breakers may be nil
+func NewHandler(reporter Reporter, breakers Breakers, settings Settings) *Handler {
+	return &Handler{reporter: reporter, breakers: breakers, settings: settings}
+}
+
+// DetailsResponse describes the health of the service and its dependencies
+type DetailsResponse struct {
+	Status       string               `json:"status"` // Worst status among the dependencies; down while a breaker is open
+	Dependencies []DependencyResponse `json:"dependencies"`
+	Breakers     []BreakerResponse    `json:"breakers,omitempty"` // Omitted when circuit breakers are disabled
+}
+
+// DependencyResponse describes the health of a dependency
+type DependencyResponse struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Since     time.Time `json:"since"` // When the dependency entered its status
+	CheckedAt time.Time `json:"checkedAt"`
+	LatencyMs int64     `json:"latencyMs"` // Duration of the latest probe
+}
+
+// BreakerResponse describes the circuit breaker of a dependency
+type BreakerResponse struct {
+	Name  string    `json:"name"`
+	State string    `json:"state"` // closed, open or half_open
+	Since time.Time `json:"since"` // When the breaker entered its state
+}
+
+// InfoResponse describes the build and the configuration of an instance
+type InfoResponse struct {
+	buildinfo.Info
+	Config map[string]any `json:"config"` // Settings keyed as in the config files; secrets are [REDACTED]
+}
+
+// GetDetails handles reporting the health of each dependency
+// @Summary Dependency health
+// @Description Report the status of the database and Redis as of their latest probe, and since when they have had it. Statuses are healthy, degraded (slow) or down; dependencies not probed yet are omitted. The state of the circuit breakers around them is listed too, and an open breaker makes the overall status down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} response.Response{data=DetailsResponse} "Dependency health"
+// @Router /health/details [get]
+func (h *Handler) GetDetails(c *gin.Context) {
+	states := h.reporter.Snapshot()
+
+	data := DetailsResponse{
+		Status:       string(health.Overall(states)),
+		Dependencies: make([]DependencyResponse, 0, len(states)),
+	}
+	for _, s := range states {
+		data.Dependencies = append(data.Dependencies, DependencyResponse{
+			Name:      s.Name,
+			Status:    string(s.Status),
+			Since:     s.Since,
+			CheckedAt: s.CheckedAt,
+			LatencyMs: s.Latency.Milliseconds(),
+		})
+	}
+	if h.breakers != nil {
+		for _, b := range h.breakers.Snapshot() {
+			data.Breakers = append(data.Breakers, BreakerResponse{Name: b.Name, State: string(b.State), Since: b.Since})
+			if b.State == breaker.StateOpen {
+				data.Status = string(health.StatusDown)
+			}
+		}
+	}
+
+	response.Success(c, data)
+}
+
+// GetInfo handles reporting the build and configuration of this instance
+// @Summary Build and configuration
+// @Description Report the version, git commit, build date and Go version of the instance serving the request, and its active configuration with secrets redacted
+// @Tags health
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=InfoResponse} "Build and configuration"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /debug/info [get]
+func (h *Handler) GetInfo(c *gin.Context) {
+	response.Success(c, InfoResponse{
+		Info:   buildinfo.Get(),
+		Config: h.settings.Redacted(),
+	})
+}
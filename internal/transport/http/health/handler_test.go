@@ -0,0 +1,106 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/breaker"
+	"github.com/yi-tech/go-user-service/internal/health"
+)
+
+// stubReporter returns fixed dependency states
+type stubReporter []health.DependencyState
+
+func (s stubReporter) Snapshot() []health.DependencyState {
+	return s
+}
+
+func TestGetDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	since := time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+	reporter := stubReporter{
+		{Name: "database", Status: health.StatusHealthy, Since: since, CheckedAt: since.Add(time.Hour), Latency: 3 * time.Millisecond},
+		{Name: "redis", Status: health.StatusDegraded, Since: since.Add(50 * time.Minute), CheckedAt: since.Add(time.Hour), Latency: 640 * time.Millisecond},
+	}
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/health/details", NewHandler(reporter, nil, nil).GetDetails)
+
+	req, _ := http.NewRequest(http.MethodGet, "/health/details", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"status":"degraded","dependencies":[
+		{"name":"database","status":"healthy","since":"2025-06-28T09:00:00Z","checkedAt":"2025-06-28T10:00:00Z","latencyMs":3},
+		{"name":"redis","status":"degraded","since":"2025-06-28T09:50:00Z","checkedAt":"2025-06-28T10:00:00Z","latencyMs":640}]}}`, rr.Body.String())
+}
+
+// stubBreakers returns fixed breaker states
+type stubBreakers []breaker.Snapshot
+
+func (s stubBreakers) Snapshot() []breaker.Snapshot {
+	return s
+}
+
+func TestGetDetails_OpenBreakerIsDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	since := time.Date(2025, 6, 28, 9, 0, 0, 0, time.UTC)
+	reporter := stubReporter{{Name: "database", Status: health.StatusHealthy, Since: since, CheckedAt: since, Latency: time.Millisecond}}
+	breakers := stubBreakers{
+		{Name: "database", State: breaker.StateOpen, Since: since.Add(time.Minute)},
+		{Name: "redis", State: breaker.StateClosed, Since: since},
+	}
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/health/details", NewHandler(reporter, breakers, nil).GetDetails)
+
+	req, _ := http.NewRequest(http.MethodGet, "/health/details", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"status":"down","dependencies":[
+		{"name":"database","status":"healthy","since":"2025-06-28T09:00:00Z","checkedAt":"2025-06-28T09:00:00Z","latencyMs":1}],
+		"breakers":[
+		{"name":"database","state":"open","since":"2025-06-28T09:01:00Z"},
+		{"name":"redis","state":"closed","since":"2025-06-28T09:00:00Z"}]}}`, rr.Body.String())
+}
+
+// stubSettings returns fixed redacted settings
+type stubSettings map[string]any
+
+func (s stubSettings) Redacted() map[string]any {
+	return s
+}
+
+func TestGetInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	settings := stubSettings{"jwt": map[string]any{"secret": "[REDACTED]", "access_token_expire_minutes": 15}}
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/debug/info", NewHandler(stubReporter{}, nil, settings).GetInfo)
+
+	req, _ := http.NewRequest(http.MethodGet, "/debug/info", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, runtime.Version(), body.Data["goVersion"])
+	assert.NotEmpty(t, body.Data["version"])
+	assert.Contains(t, body.Data, "commit")
+	assert.Contains(t, body.Data, "buildDate")
+	assert.Equal(t, map[string]any{"secret": "[REDACTED]", "access_token_expire_minutes": float64(15)}, body.Data["config"].(map[string]any)["jwt"])
+}
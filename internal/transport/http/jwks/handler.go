@@ -0,0 +1,38 @@
+package jwks
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// Handler serves the public keys that verify access tokens
+type Handler struct {
+	keyManager domainAuth.KeyManager
+}
+
+// NewHandler creates a new JWKS handler
+func NewHandler(keyManager domainAuth.KeyManager) *Handler {
+	return &Handler{keyManager: keyManager}
+}
+
+// KeySet is a JSON Web Key Set (RFC 7517)
+type KeySet struct {
+	Keys []domainAuth.JSONWebKey `json:"keys"`
+}
+
+// GetJWKS handles serving the JSON Web Key Set
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens locally. Empty when tokens are signed with HS256.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} KeySet "JSON Web Key Set"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) GetJWKS(c *gin.Context) {
+	// Downstream services poll this endpoint; let them cache it briefly
+	c.Header("Cache-Control", "public, max-age=300")
+	// The key set format is defined by RFC 7517, so it is not wrapped in the response envelope
+	c.JSON(http.StatusOK, KeySet{Keys: h.keyManager.PublicKeys()})
+}
@@ -0,0 +1,72 @@
+package jwks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	domainAuth "github.com/yi-tech/go-user-service/internal/domain/auth"
+)
+
+// MockKeyManager is a mock implementation of domainAuth.KeyManager
+type MockKeyManager struct {
+	mock.Mock
+}
+
+func (m *MockKeyManager) ActiveKeyID() string {
+	return m.Called().String(0)
+}
+
+func (m *MockKeyManager) Rotate(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockKeyManager) PublicKeys() []domainAuth.JSONWebKey {
+	return m.Called().Get(0).([]domainAuth.JSONWebKey)
+}
+
+func TestGetJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		keys         []domainAuth.JSONWebKey
+		expectedBody string
+	}{
+		{
+			name:         "HMAC Keys Are Not Exposed",
+			keys:         []domainAuth.JSONWebKey{},
+			expectedBody: `{"keys":[]}`,
+		},
+		{
+			name:         "RSA Key",
+			keys:         []domainAuth.JSONWebKey{{KeyType: "RSA", KeyID: "k1", Use: "sig", Algorithm: "RS256", N: "abc", E: "AQAB"}},
+			expectedBody: `{"keys":[{"kty":"RSA","kid":"k1","use":"sig","alg":"RS256","n":"abc","e":"AQAB"}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keyManager := new(MockKeyManager)
+			keyManager.On("PublicKeys").Return(tc.keys)
+			handler := NewHandler(keyManager)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.GET("/.well-known/jwks.json", handler.GetJWKS)
+
+			req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			keyManager.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Package links builds hypermedia links to REST resources, so clients can
+// navigate between resources without hardcoding URLs.
+package links
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// APIBase is the versioned base path of the public REST API
+	APIBase = "/api/v1"
+	// AdminBase is the versioned base path of the admin API
+	AdminBase = "/admin/v1"
+)
+
+// API and Admin build links to the public REST API and the admin API
+var (
+	API   = NewBuilder(APIBase)
+	Admin = NewBuilder(AdminBase)
+)
+
+// Link is a link to a related resource, rendered in HAL style as {"href": ...}
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Builder builds links to the resources under a versioned base path
+type Builder struct {
+	base string
+}
+
+// NewBuilder returns a Builder for the routes under base, e.g. APIBase
+func NewBuilder(base string) Builder {
+	return Builder{base: strings.TrimRight(base, "/")}
+}
+
+// Link links to the resource at the path segments below the base path. Each
+// segment is escaped, so IDs cannot change the path.
+func (b Builder) Link(segments ...string) Link {
+	var path strings.Builder
+	path.WriteString(b.base)
+	for _, segment := range segments {
+		path.WriteByte('/')
+		path.WriteString(url.PathEscape(segment))
+	}
+	return Link{Href: path.String()}
+}
@@ -0,0 +1,28 @@
+package links
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Link(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  Builder
+		segments []string
+		expected string
+	}{
+		{name: "API", builder: API, segments: []string{"users", "42"}, expected: "/api/v1/users/42"},
+		{name: "Admin", builder: Admin, segments: []string{"users", "42", "sessions"}, expected: "/admin/v1/users/42/sessions"},
+		{name: "Trailing Slash In Base", builder: NewBuilder("/api/v2/"), segments: []string{"users"}, expected: "/api/v2/users"},
+		{name: "Base Only", builder: API, expected: "/api/v1"},
+		{name: "Segments Escaped", builder: API, segments: []string{"users", "a/../b c"}, expected: "/api/v1/users/a%2F..%2Fb%20c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, Link{Href: tt.expected}, tt.builder.Link(tt.segments...))
+		})
+	}
+}
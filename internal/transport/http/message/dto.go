@@ -0,0 +1,35 @@
+package message
+
+import "time"
+
+// MessageRequest defines the request structure for creating or updating a system message
+type MessageRequest struct {
+	Title    string     `json:"title" binding:"required,max=255"`
+	Body     string     `json:"body"`
+	Severity string     `json:"severity" binding:"omitempty,oneof=info warning critical"`
+	Roles    []string   `json:"roles"`   // Empty targets every role, including anonymous clients
+	Tenants  []string   `json:"tenants"` // Empty targets every tenant
+	StartsAt *time.Time `json:"startsAt"`
+	EndsAt   *time.Time `json:"endsAt"`
+}
+
+// MessageResponse describes a system message as shown to clients
+type MessageResponse struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Body     string     `json:"body"`
+	Severity string     `json:"severity"`
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+}
+
+// ManagedMessageResponse describes a system message as seen by administrators,
+// including its targeting
+type ManagedMessageResponse struct {
+	MessageResponse
+	Roles     []string  `json:"roles"`
+	Tenants   []string  `json:"tenants"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
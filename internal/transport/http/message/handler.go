@@ -0,0 +1,269 @@
+package message
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceMessage "github.com/yi-tech/go-user-service/internal/service/message"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// UserLookup loads the account of an authenticated user to determine its role and tenant.
+// serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
+}
+
+// Handler handles HTTP requests for system messages
+type Handler struct {
+	messageService serviceMessage.MessageService
+	users          UserLookup
+	ids            idgen.Strategy // Text form of rendered IDs
+	logger         *zap.Logger
+}
+
+// NewHandler creates a new system message handler
+func NewHandler(messageService serviceMessage.MessageService, users UserLookup, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		messageService: messageService,
+		users:          users,
+		ids:            ids,
+		logger:         logger,
+	}
+}
+
+// ListMessages handles listing the system messages targeted at the caller
+// @Summary List system messages
+// @Description List active announcements such as maintenance notices. Anonymous callers only receive messages that target neither a role nor a tenant.
+// @Tags system
+// @Produce json
+// @Success 200 {object} response.Response{data=[]MessageResponse} "Active system messages"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /system/messages [get]
+func (h *Handler) ListMessages(c *gin.Context) {
+	var audience domainMessage.Audience
+
+	// Set by the optional auth middleware when the caller sent a valid token.
+	// The tenant comes from the account so clients cannot claim another one.
+	if id, ok := requestctx.UserID(c.Request.Context()); ok {
+		user, err := h.users.GetByID(c.Request.Context(), id)
+		if err != nil && apperror.CodeOf(err) != apperror.CodeUserNotFound {
+			h.handleError(c, "ListMessages", err)
+			return
+		}
+		if user != nil {
+			audience.Role = user.Role
+			audience.Tenant = user.Tenant
+		}
+	}
+
+	messages, err := h.messageService.ListForAudience(c.Request.Context(), audience)
+	if err != nil {
+		h.handleError(c, "ListMessages", err)
+		return
+	}
+
+	data := make([]MessageResponse, 0, len(messages))
+	for _, m := range messages {
+		data = append(data, h.toMessageResponse(m))
+	}
+
+	response.Success(c, data)
+}
+
+// ListAllMessages handles listing every system message
+// @Summary List all system messages
+// @Description List every system message including scheduled and expired ones, with their targeting
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]ManagedMessageResponse} "System messages"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/system-messages [get]
+func (h *Handler) ListAllMessages(c *gin.Context) {
+	messages, err := h.messageService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "ListAllMessages", err)
+		return
+	}
+
+	data := make([]ManagedMessageResponse, 0, len(messages))
+	for _, m := range messages {
+		data = append(data, h.toManagedMessageResponse(m))
+	}
+
+	response.Success(c, data)
+}
+
+// CreateMessage handles publishing a new system message
+// @Summary Create system message
+// @Description Publish an announcement, optionally targeted by role and tenant and limited to a display window
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MessageRequest true "System message"
+// @Success 201 {object} response.Response{data=ManagedMessageResponse} "System message created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/system-messages [post]
+func (h *Handler) CreateMessage(c *gin.Context) {
+	actorID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	message, err := h.messageService.Create(c.Request.Context(), actorID, toInput(req))
+	if err != nil {
+		h.handleError(c, "CreateMessage", err)
+		return
+	}
+
+	response.Created(c, "System message created", h.toManagedMessageResponse(message))
+}
+
+// UpdateMessage handles replacing a system message
+// @Summary Update system message
+// @Description Replace the content, targeting and display window of a system message
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Param request body MessageRequest true "System message"
+// @Success 200 {object} response.Response{data=ManagedMessageResponse} "System message updated"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "System message not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/system-messages/{id} [put]
+func (h *Handler) UpdateMessage(c *gin.Context) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid message ID format")
+		return
+	}
+
+	var req MessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	message, err := h.messageService.Update(c.Request.Context(), id, toInput(req))
+	if err != nil {
+		h.handleError(c, "UpdateMessage", err)
+		return
+	}
+
+	response.Success(c, h.toManagedMessageResponse(message))
+}
+
+// DeleteMessage handles removing a system message
+// @Summary Delete system message
+// @Description Remove a system message so clients stop showing it
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Message ID"
+// @Success 200 {object} response.Response "System message deleted"
+// @Failure 400 {object} response.Response "Invalid message ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Failure 404 {object} response.Response "System message not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/v1/system-messages/{id} [delete]
+func (h *Handler) DeleteMessage(c *gin.Context) {
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid message ID format")
+		return
+	}
+
+	if err := h.messageService.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, "DeleteMessage", err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "System message deleted successfully"})
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *Handler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("System message operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func toInput(req MessageRequest) domainMessage.Input {
+	roles := make([]domainRBAC.Role, 0, len(req.Roles))
+	for _, r := range req.Roles {
+		roles = append(roles, domainRBAC.Role(r))
+	}
+	return domainMessage.Input{
+		Title:    req.Title,
+		Body:     req.Body,
+		Severity: domainMessage.Severity(req.Severity),
+		Roles:    roles,
+		Tenants:  req.Tenants,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+}
+
+func (h *Handler) toMessageResponse(m *domainMessage.SystemMessage) MessageResponse {
+	return MessageResponse{
+		ID:       h.ids.Format(m.ID),
+		Title:    m.Title,
+		Body:     m.Body,
+		Severity: string(m.Severity),
+		StartsAt: m.StartsAt,
+		EndsAt:   m.EndsAt,
+	}
+}
+
+func (h *Handler) toManagedMessageResponse(m *domainMessage.SystemMessage) ManagedMessageResponse {
+	roles := make([]string, 0, len(m.Roles))
+	for _, r := range m.Roles {
+		roles = append(roles, string(r))
+	}
+	tenants := m.Tenants
+	if tenants == nil {
+		tenants = []string{}
+	}
+	return ManagedMessageResponse{
+		MessageResponse: h.toMessageResponse(m),
+		Roles:           roles,
+		Tenants:         tenants,
+		CreatedBy:       h.ids.Format(m.CreatedBy),
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+}
@@ -0,0 +1,227 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainMessage "github.com/yi-tech/go-user-service/internal/domain/message"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceMessage "github.com/yi-tech/go-user-service/internal/service/message"
+)
+
+// MockMessageService is a mock implementation of serviceMessage.MessageService
+type MockMessageService struct {
+	mock.Mock
+}
+
+func (m *MockMessageService) ListForAudience(ctx context.Context, audience domainMessage.Audience) ([]*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx, audience)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageService) List(ctx context.Context) ([]*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageService) Create(ctx context.Context, actorID uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx, actorID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageService) Update(ctx context.Context, id uuid.UUID, input domainMessage.Input) (*domainMessage.SystemMessage, error) {
+	args := m.Called(ctx, id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainMessage.SystemMessage), args.Error(1)
+}
+
+func (m *MockMessageService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// stubUserLookup returns a fixed user
+type stubUserLookup struct {
+	user *domainUser.User
+}
+
+func (s stubUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	return s.user, nil
+}
+
+var (
+	testUserID    = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testMessageID = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testTime      = time.Date(2025, 6, 25, 9, 0, 0, 0, time.UTC)
+)
+
+func TestListMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	maintenance := &domainMessage.SystemMessage{
+		ID:       testMessageID,
+		Title:    "Scheduled maintenance",
+		Body:     "Read-only from 22:00 UTC",
+		Severity: domainMessage.SeverityWarning,
+		EndsAt:   &testTime,
+	}
+
+	tests := []struct {
+		name             string
+		authenticated    bool
+		tenant           string
+		expectedAudience domainMessage.Audience
+	}{
+		{
+			name:             "Anonymous Tenant Header Ignored",
+			tenant:           "acme",
+			expectedAudience: domainMessage.Audience{},
+		},
+		{
+			name:             "Authenticated",
+			authenticated:    true,
+			tenant:           "globex",
+			expectedAudience: domainMessage.Audience{Role: domainRBAC.RoleSupport, Tenant: "acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockMessageService)
+			mockService.On("ListForAudience", mock.Anything, tt.expectedAudience).Return([]*domainMessage.SystemMessage{maintenance}, nil)
+			handler := NewHandler(mockService, stubUserLookup{user: &domainUser.User{ID: testUserID, Role: domainRBAC.RoleSupport, Tenant: "acme"}}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.GET("/system/messages", func(c *gin.Context) {
+				if tt.authenticated {
+					requestctx.SetUserID(c, testUserID)
+				}
+			}, handler.ListMessages)
+
+			req, _ := http.NewRequest(http.MethodGet, "/system/messages", nil)
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{"code":200,"message":"Success","data":[{"id":"22222222-2222-2222-2222-222222222222","title":"Scheduled maintenance","body":"Read-only from 22:00 UTC","severity":"warning","endsAt":"2025-06-25T09:00:00Z"}]}`, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(m *MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: `{"title":"New terms","body":"Please review","roles":["user"]}`,
+			setupMock: func(m *MockMessageService) {
+				m.On("Create", mock.Anything, testUserID, domainMessage.Input{
+					Title: "New terms",
+					Body:  "Please review",
+					Roles: []domainRBAC.Role{domainRBAC.RoleUser},
+				}).Return(&domainMessage.SystemMessage{
+					ID:        testMessageID,
+					Title:     "New terms",
+					Body:      "Please review",
+					Severity:  domainMessage.SeverityInfo,
+					Roles:     []domainRBAC.Role{domainRBAC.RoleUser},
+					CreatedBy: testUserID,
+					CreatedAt: testTime,
+					UpdatedAt: testTime,
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"code":201,"message":"System message created","data":{"id":"22222222-2222-2222-2222-222222222222","title":"New terms","body":"Please review","severity":"info","roles":["user"],"tenants":[],"createdBy":"11111111-1111-1111-1111-111111111111","createdAt":"2025-06-25T09:00:00Z","updatedAt":"2025-06-25T09:00:00Z"}}`,
+		},
+		{
+			name:           "Invalid Severity",
+			body:           `{"title":"x","severity":"urgent"}`,
+			setupMock:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name: "Service Validation Error",
+			body: `{"title":"x","roles":["superuser"]}`,
+			setupMock: func(m *MockMessageService) {
+				m.On("Create", mock.Anything, testUserID, mock.Anything).Return(nil, serviceMessage.ErrUnknownRole)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"roles must be known role names","errorCode":"INVALID_ARGUMENT"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockMessageService)
+			tt.setupMock(mockService)
+			handler := NewHandler(mockService, stubUserLookup{}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.POST("/admin/v1/system-messages", func(c *gin.Context) {
+				requestctx.SetUserID(c, testUserID)
+			}, handler.CreateMessage)
+
+			req, _ := http.NewRequest(http.MethodPost, "/admin/v1/system-messages", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteMessage_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMessageService)
+	mockService.On("Delete", mock.Anything, testMessageID).Return(serviceMessage.ErrMessageNotFound)
+	handler := NewHandler(mockService, stubUserLookup{}, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.DELETE("/admin/v1/system-messages/:id", handler.DeleteMessage)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/v1/system-messages/"+testMessageID.String(), nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"code":404,"message":"system message not found","errorCode":"MESSAGE_NOT_FOUND"}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package org
+
+import "time"
+
+// CreateAPIKeyRequest defines the request structure for issuing an organization API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Omit for a key that never expires
+}
+
+// APIKeyResponse describes an organization API key; the secret is never included
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	RotatedTo  string     `json:"rotatedTo,omitempty"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// IssuedAPIKeyResponse describes a newly issued API key with its secret,
+// which is shown only once
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Secret string `json:"secret"`
+}
+
+// UserListQuery pages the organization user listing
+type UserListQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// OrgUserResponse describes a member of the organization
+type OrgUserResponse struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName,omitempty"`
+	LastName  string    `json:"lastName,omitempty"`
+	Role      string    `json:"role"`
+	IsActive  bool      `json:"isActive"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,329 @@
+package org
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAPIKey "github.com/yi-tech/go-user-service/internal/service/apikey"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// DefaultPageSize is used when a listing request does not specify page_size
+const DefaultPageSize = 20
+
+// UserLookup loads the account of an authenticated user to determine its tenant.
+// serviceUser.UserService satisfies it.
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error)
+}
+
+// UserLister pages through users. serviceAdmin.AdminService satisfies it.
+type UserLister interface {
+	ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error)
+}
+
+// Handler handles HTTP requests scoped to the caller's organization: API key
+// management by its admins, and the endpoints its API keys can call
+type Handler struct {
+	apiKeys serviceAPIKey.Service
+	users   UserLookup
+	members UserLister
+	ids     idgen.Strategy // Text form of rendered IDs
+	logger  *zap.Logger
+}
+
+// NewHandler creates a new organization handler
+func NewHandler(apiKeys serviceAPIKey.Service, users UserLookup, members UserLister, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		apiKeys: apiKeys,
+		users:   users,
+		members: members,
+		ids:     ids,
+		logger:  logger,
+	}
+}
+
+// ListAPIKeys handles listing the API keys of the caller's organization
+// @Summary List organization API keys
+// @Description List every API key of the caller's organization, including expired, revoked and rotated ones
+// @Tags org
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]APIKeyResponse} "API keys"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Organization admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/org/api-keys [get]
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	_, tenant, ok := h.caller(c, "ListAPIKeys")
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeys.List(c.Request.Context(), tenant)
+	if err != nil {
+		h.handleError(c, "ListAPIKeys", err)
+		return
+	}
+
+	data := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		data = append(data, h.toAPIKeyResponse(key))
+	}
+
+	response.Success(c, data)
+}
+
+// CreateAPIKey handles issuing an API key for the caller's organization
+// @Summary Create organization API key
+// @Description Issue an API key acting for the caller's organization. The secret is only returned in this response.
+// @Tags org
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "API key"
+// @Success 201 {object} response.Response{data=IssuedAPIKeyResponse} "API key created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Organization admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/org/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	actorID, tenant, ok := h.caller(c, "CreateAPIKey")
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	scopes := make([]domainAPIKey.Scope, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		scopes = append(scopes, domainAPIKey.Scope(s))
+	}
+	input := domainAPIKey.Input{Name: req.Name, Scopes: scopes, ExpiresAt: req.ExpiresAt}
+
+	key, secret, err := h.apiKeys.Create(c.Request.Context(), actorID, tenant, input)
+	if err != nil {
+		h.handleError(c, "CreateAPIKey", err)
+		return
+	}
+
+	h.logger.Info("API key created",
+		zap.String("actor_id", actorID.String()),
+		zap.String("api_key_id", key.ID.String()),
+		zap.String("tenant", tenant))
+
+	response.Created(c, "API key created", IssuedAPIKeyResponse{APIKeyResponse: h.toAPIKeyResponse(key), Secret: secret})
+}
+
+// RotateAPIKey handles replacing an API key of the caller's organization
+// @Summary Rotate organization API key
+// @Description Issue a replacement with the same name and scopes. The old key keeps working for the configured overlap, then expires. The new secret is only returned in this response.
+// @Tags org
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 201 {object} response.Response{data=IssuedAPIKeyResponse} "API key rotated"
+// @Failure 400 {object} response.Response "Invalid ID, or the key is expired, revoked or already rotated"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Organization admin role required"
+// @Failure 404 {object} response.Response "API key not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/org/api-keys/{id}/rotate [post]
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	actorID, tenant, ok := h.caller(c, "RotateAPIKey")
+	if !ok {
+		return
+	}
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID format")
+		return
+	}
+
+	key, secret, err := h.apiKeys.Rotate(c.Request.Context(), actorID, tenant, id)
+	if err != nil {
+		h.handleError(c, "RotateAPIKey", err)
+		return
+	}
+
+	h.logger.Info("API key rotated",
+		zap.String("actor_id", actorID.String()),
+		zap.String("api_key_id", id.String()),
+		zap.String("replacement_id", key.ID.String()),
+		zap.String("tenant", tenant))
+
+	response.Created(c, "API key rotated", IssuedAPIKeyResponse{APIKeyResponse: h.toAPIKeyResponse(key), Secret: secret})
+}
+
+// RevokeAPIKey handles disabling an API key of the caller's organization
+// @Summary Revoke organization API key
+// @Description Disable an API key immediately
+// @Tags org
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.Response{data=APIKeyResponse} "API key revoked"
+// @Failure 400 {object} response.Response "Invalid API key ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Organization admin role required"
+// @Failure 404 {object} response.Response "API key not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/org/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	actorID, tenant, ok := h.caller(c, "RevokeAPIKey")
+	if !ok {
+		return
+	}
+	id, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID format")
+		return
+	}
+
+	key, err := h.apiKeys.Revoke(c.Request.Context(), tenant, id)
+	if err != nil {
+		h.handleError(c, "RevokeAPIKey", err)
+		return
+	}
+
+	h.logger.Info("API key revoked",
+		zap.String("actor_id", actorID.String()),
+		zap.String("api_key_id", id.String()),
+		zap.String("tenant", tenant))
+
+	response.Success(c, h.toAPIKeyResponse(key))
+}
+
+// ListUsers handles listing the members of the organization an API key acts for
+// @Summary List organization users
+// @Description List the users of the API key's organization, newest first. Requires an API key with the users:read scope.
+// @Tags org
+// @Produce json
+// @Param X-API-Key header string true "Organization API key"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.Response{data=response.PaginatedResponse{data=[]OrgUserResponse}} "Users"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 401 {object} response.Response "API key missing, invalid, expired or revoked"
+// @Failure 403 {object} response.Response "API key does not grant users:read"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/org/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	// Set by the API key middleware from the key, never from client input
+	tenant := requestctx.Tenant(c.Request.Context())
+	if tenant == "" {
+		response.AppError(c, serviceAPIKey.ErrInvalidAPIKey)
+		return
+	}
+
+	var query UserListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+	page, pageSize := query.Page, query.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	filter := domainUser.ListFilter{Tenant: tenant, Offset: (page - 1) * pageSize, Limit: pageSize}
+	users, total, err := h.members.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "ListUsers", err)
+		return
+	}
+
+	data := make([]OrgUserResponse, 0, len(users))
+	for _, user := range users {
+		data = append(data, OrgUserResponse{
+			ID:        h.ids.Format(user.ID),
+			Email:     user.Email,
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Role:      string(user.Role),
+			IsActive:  user.IsActive,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+
+	response.Paginated(c, data, response.PageMeta{Page: page, PageSize: pageSize, Total: total})
+}
+
+// caller returns the authenticated user and their tenant. The tenant comes
+// from the account so admins cannot manage the keys of another organization.
+func (h *Handler) caller(c *gin.Context, operation string) (uuid.UUID, string, bool) {
+	actorID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, "", false
+	}
+
+	user, err := h.users.GetByID(c.Request.Context(), actorID)
+	if err != nil {
+		h.handleError(c, operation, err)
+		return uuid.Nil, "", false
+	}
+	if user == nil {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, "", false
+	}
+	if user.Tenant == "" {
+		response.AppError(c, serviceAPIKey.ErrTenantRequired)
+		return uuid.Nil, "", false
+	}
+	return actorID, user.Tenant, true
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *Handler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Organization operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func (h *Handler) toAPIKeyResponse(key *domainAPIKey.APIKey) APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, s := range key.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	resp := APIKeyResponse{
+		ID:         h.ids.Format(key.ID),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		Active:     key.IsActive(time.Now()),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedBy:  h.ids.Format(key.CreatedBy),
+		CreatedAt:  key.CreatedAt,
+	}
+	if key.RotatedTo != nil {
+		resp.RotatedTo = h.ids.Format(*key.RotatedTo)
+	}
+	return resp
+}
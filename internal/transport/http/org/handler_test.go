@@ -0,0 +1,208 @@
+package org
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainAPIKey "github.com/yi-tech/go-user-service/internal/domain/apikey"
+	domainRBAC "github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceAPIKey "github.com/yi-tech/go-user-service/internal/service/apikey"
+)
+
+// MockAPIKeyService is a mock implementation of serviceAPIKey.Service
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, actorID uuid.UUID, tenant string, input domainAPIKey.Input) (*domainAPIKey.APIKey, string, error) {
+	args := m.Called(ctx, actorID, tenant, input)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*domainAPIKey.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context, tenant string) ([]*domainAPIKey.APIKey, error) {
+	args := m.Called(ctx, tenant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainAPIKey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Rotate(ctx context.Context, actorID uuid.UUID, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, string, error) {
+	args := m.Called(ctx, actorID, tenant, id)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*domainAPIKey.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, tenant string, id uuid.UUID) (*domainAPIKey.APIKey, error) {
+	args := m.Called(ctx, tenant, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAPIKey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, secret string) (*domainAPIKey.APIKey, error) {
+	args := m.Called(ctx, secret)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainAPIKey.APIKey), args.Error(1)
+}
+
+// stubUserLookup returns a fixed user
+type stubUserLookup struct {
+	user *domainUser.User
+}
+
+func (s stubUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
+	return s.user, nil
+}
+
+// stubUserLister records the filter it was called with
+type stubUserLister struct {
+	users  []*domainUser.User
+	filter *domainUser.ListFilter
+}
+
+func (s stubUserLister) ListUsers(ctx context.Context, filter domainUser.ListFilter) ([]*domainUser.User, int64, error) {
+	*s.filter = filter
+	return s.users, int64(len(s.users)), nil
+}
+
+var (
+	testUserID = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testKeyID  = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testTime   = time.Date(2025, 6, 27, 9, 0, 0, 0, time.UTC)
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		tenant         string
+		body           string
+		setupMock      func(m *MockAPIKeyService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Success",
+			tenant: "acme",
+			body:   `{"name":"CI sync","scopes":["users:read"]}`,
+			setupMock: func(m *MockAPIKeyService) {
+				m.On("Create", mock.Anything, testUserID, "acme", domainAPIKey.Input{
+					Name:   "CI sync",
+					Scopes: []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead},
+				}).Return(&domainAPIKey.APIKey{
+					ID:        testKeyID,
+					Tenant:    "acme",
+					Name:      "CI sync",
+					Prefix:    "usk_abcdefgh",
+					Scopes:    []domainAPIKey.Scope{domainAPIKey.ScopeUsersRead},
+					CreatedBy: testUserID,
+					CreatedAt: testTime,
+				}, "usk_abcdefghsecret", nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"code":201,"message":"API key created","data":{"id":"22222222-2222-2222-2222-222222222222","name":"CI sync","prefix":"usk_abcdefgh","scopes":["users:read"],"active":true,"createdBy":"11111111-1111-1111-1111-111111111111","createdAt":"2025-06-27T09:00:00Z","secret":"usk_abcdefghsecret"}}`,
+		},
+		{
+			name:           "Missing Scopes",
+			tenant:         "acme",
+			body:           `{"name":"CI sync"}`,
+			setupMock:      func(m *MockAPIKeyService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:           "Caller Outside Any Organization",
+			body:           `{"name":"CI sync","scopes":["users:read"]}`,
+			setupMock:      func(m *MockAPIKeyService) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"code":403,"message":"API keys can only be managed by members of an organization","errorCode":"PERMISSION_DENIED"}`,
+		},
+		{
+			name:   "Service Validation Error",
+			tenant: "acme",
+			body:   `{"name":"CI sync","scopes":["users:write"]}`,
+			setupMock: func(m *MockAPIKeyService) {
+				m.On("Create", mock.Anything, testUserID, "acme", mock.Anything).Return(nil, "", serviceAPIKey.ErrUnknownScope)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"scopes must be known scope names","errorCode":"INVALID_ARGUMENT"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			tt.setupMock(mockService)
+			caller := &domainUser.User{ID: testUserID, Role: domainRBAC.RoleOrgAdmin, Tenant: tt.tenant}
+			handler := NewHandler(mockService, stubUserLookup{user: caller}, nil, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.POST("/api/v1/org/api-keys", func(c *gin.Context) {
+				requestctx.SetUserID(c, testUserID)
+			}, handler.CreateAPIKey)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/org/api-keys", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var filter domainUser.ListFilter
+	lister := stubUserLister{
+		users: []*domainUser.User{{
+			ID:        testUserID,
+			Email:     "ada@acme.example",
+			Username:  "ada",
+			Role:      domainRBAC.RoleUser,
+			IsActive:  true,
+			Tenant:    "acme",
+			CreatedAt: testTime,
+		}},
+		filter: &filter,
+	}
+	handler := NewHandler(new(MockAPIKeyService), stubUserLookup{}, lister, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/api/v1/org/users", func(c *gin.Context) {
+		requestctx.SetTenant(c, "acme") // Set by the API key middleware
+	}, handler.ListUsers)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/org/users?page=2&page_size=10", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, domainUser.ListFilter{Tenant: "acme", Offset: 10, Limit: 10}, filter)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"data":[{"id":"11111111-1111-1111-1111-111111111111","email":"ada@acme.example","username":"ada","role":"user","isActive":true,"createdAt":"2025-06-27T09:00:00Z"}],"page":2,"pageSize":10,"total":1,"totalPages":1}}`, rr.Body.String())
+}
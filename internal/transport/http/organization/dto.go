@@ -0,0 +1,42 @@
+package organization
+
+import "time"
+
+// CreateOrganizationRequest defines the request structure for creating an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"omitempty,max=64"` // Derived from the name when omitted
+}
+
+// InviteMemberRequest defines the request structure for inviting a user to an organization
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// UpdateMemberRoleRequest defines the request structure for changing the role of a member
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// OrganizationResponse describes an organization
+type OrganizationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MemberResponse describes a member of an organization or an invitation to it
+type MemberResponse struct {
+	OrganizationID string     `json:"organizationId"`
+	UserID         string     `json:"userId"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`   // owner, admin or member
+	Status         string     `json:"status"` // invited or active
+	InvitedBy      string     `json:"invitedBy,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	JoinedAt       *time.Time `json:"joinedAt,omitempty"`
+}
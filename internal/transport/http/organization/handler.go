@@ -0,0 +1,418 @@
+package organization
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// Handler handles HTTP requests for organizations the caller creates, is
+// invited to or is a member of
+type Handler struct {
+	orgs   serviceOrg.Service
+	ids    idgen.Strategy // Text form of rendered IDs
+	logger *zap.Logger
+}
+
+// NewHandler creates a new organization handler
+func NewHandler(orgs serviceOrg.Service, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		orgs:   orgs,
+		ids:    ids,
+		logger: logger,
+	}
+}
+
+// CreateOrganization handles creating an organization
+// @Summary Create organization
+// @Description Create an organization with the caller as its owner. The slug is derived from the name when omitted.
+// @Tags orgs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateOrganizationRequest true "Organization"
+// @Success 201 {object} response.Response{data=OrganizationResponse} "Organization created"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 409 {object} response.Response "Slug already in use"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs [post]
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	actorID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	org, err := h.orgs.Create(c.Request.Context(), actorID, domainOrg.Input{Name: req.Name, Slug: req.Slug})
+	if err != nil {
+		h.handleError(c, "CreateOrganization", err)
+		return
+	}
+
+	h.logger.Info("Organization created",
+		zap.String("actor_id", actorID.String()),
+		zap.String("organization_id", org.ID.String()))
+
+	response.Created(c, "Organization created", h.toOrganizationResponse(org))
+}
+
+// ListOrganizations handles listing the organizations of the caller
+// @Summary List organizations
+// @Description List the organizations the caller is a member of, by name. Pending invitations are listed by GET /api/v1/orgs/invitations.
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]OrganizationResponse} "Organizations"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs [get]
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	actorID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.orgs.List(c.Request.Context(), actorID)
+	if err != nil {
+		h.handleError(c, "ListOrganizations", err)
+		return
+	}
+
+	data := make([]OrganizationResponse, 0, len(orgs))
+	for _, org := range orgs {
+		data = append(data, h.toOrganizationResponse(org))
+	}
+
+	response.Success(c, data)
+}
+
+// GetOrganization handles retrieving an organization of the caller
+// @Summary Get organization
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Success 200 {object} response.Response{data=OrganizationResponse} "Organization"
+// @Failure 400 {object} response.Response "Invalid organization ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 404 {object} response.Response "Organization not found or the caller is not a member"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id} [get]
+func (h *Handler) GetOrganization(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgs.Get(c.Request.Context(), actorID, orgID)
+	if err != nil {
+		h.handleError(c, "GetOrganization", err)
+		return
+	}
+
+	response.Success(c, h.toOrganizationResponse(org))
+}
+
+// ListMembers handles listing the members of an organization
+// @Summary List organization members
+// @Description List the members and pending invitations of an organization, oldest first
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Success 200 {object} response.Response{data=[]MemberResponse} "Members"
+// @Failure 400 {object} response.Response "Invalid organization ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 404 {object} response.Response "Organization not found or the caller is not a member"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id}/members [get]
+func (h *Handler) ListMembers(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgs.ListMembers(c.Request.Context(), actorID, orgID)
+	if err != nil {
+		h.handleError(c, "ListMembers", err)
+		return
+	}
+
+	response.Success(c, h.toMemberResponses(members))
+}
+
+// InviteMember handles inviting a user to an organization
+// @Summary Invite organization member
+// @Description Invite a registered user by email. The user becomes a member after accepting. Owners invite any role; admins invite admins and members.
+// @Tags orgs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param request body InviteMemberRequest true "Invitation"
+// @Success 201 {object} response.Response{data=MemberResponse} "Invitation created"
+// @Failure 400 {object} response.Response "Invalid request data or role"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "The caller's role does not allow this"
+// @Failure 404 {object} response.Response "Organization or user not found"
+// @Failure 409 {object} response.Response "User already a member or invited"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id}/members [post]
+func (h *Handler) InviteMember(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	member, err := h.orgs.Invite(c.Request.Context(), actorID, orgID, req.Email, domainOrg.Role(req.Role))
+	if err != nil {
+		h.handleError(c, "InviteMember", err)
+		return
+	}
+
+	h.logger.Info("Organization member invited",
+		zap.String("actor_id", actorID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", member.UserID.String()),
+		zap.String("role", string(member.Role)))
+
+	response.Created(c, "Invitation created", h.toMemberResponse(member))
+}
+
+// ListInvitations handles listing the pending invitations of the caller
+// @Summary List invitations
+// @Description List the organizations that invited the caller and are waiting for an answer, newest first
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]MemberResponse} "Invitations"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/invitations [get]
+func (h *Handler) ListInvitations(c *gin.Context) {
+	actorID, ok := h.caller(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.orgs.ListInvitations(c.Request.Context(), actorID)
+	if err != nil {
+		h.handleError(c, "ListInvitations", err)
+		return
+	}
+
+	response.Success(c, h.toMemberResponses(invitations))
+}
+
+// AcceptInvitation handles accepting an invitation of the caller
+// @Summary Accept invitation
+// @Description Join an organization that invited the caller. To decline, remove yourself with DELETE /api/v1/orgs/{id}/members/{user_id}.
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Success 200 {object} response.Response{data=MemberResponse} "Invitation accepted"
+// @Failure 400 {object} response.Response "Invalid organization ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 404 {object} response.Response "No pending invitation"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id}/invitation/accept [post]
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.orgs.AcceptInvitation(c.Request.Context(), actorID, orgID)
+	if err != nil {
+		h.handleError(c, "AcceptInvitation", err)
+		return
+	}
+
+	h.logger.Info("Organization invitation accepted",
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", actorID.String()))
+
+	response.Success(c, h.toMemberResponse(member))
+}
+
+// UpdateMemberRole handles changing the role of a member
+// @Summary Change member role
+// @Description Change the role of a member or invitation. Owners change any role; admins change admins and members and cannot grant ownership. The last owner cannot step down.
+// @Tags orgs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param request body UpdateMemberRoleRequest true "Role"
+// @Success 200 {object} response.Response{data=MemberResponse} "Role changed"
+// @Failure 400 {object} response.Response "Invalid ID, request data or role"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "The caller's role does not allow this"
+// @Failure 404 {object} response.Response "Organization or member not found"
+// @Failure 409 {object} response.Response "The organization would have no owner"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id}/members/{user_id} [put]
+func (h *Handler) UpdateMemberRole(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+	userID, err := idgen.Parse(c.Param("user_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	member, err := h.orgs.UpdateMemberRole(c.Request.Context(), actorID, orgID, userID, domainOrg.Role(req.Role))
+	if err != nil {
+		h.handleError(c, "UpdateMemberRole", err)
+		return
+	}
+
+	h.logger.Info("Organization member role changed",
+		zap.String("actor_id", actorID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("role", string(member.Role)))
+
+	response.Success(c, h.toMemberResponse(member))
+}
+
+// RemoveMember handles removing a member from an organization
+// @Summary Remove member
+// @Description Remove a member or withdraw an invitation. Callers may remove themselves to leave or to decline an invitation, unless they are the last owner.
+// @Tags orgs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.Response "Member removed"
+// @Failure 400 {object} response.Response "Invalid ID format"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "The caller's role does not allow this"
+// @Failure 404 {object} response.Response "Organization or member not found"
+// @Failure 409 {object} response.Response "The organization would have no owner"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orgs/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveMember(c *gin.Context) {
+	actorID, orgID, ok := h.callerAndOrg(c)
+	if !ok {
+		return
+	}
+	userID, err := idgen.Parse(c.Param("user_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	if err := h.orgs.RemoveMember(c.Request.Context(), actorID, orgID, userID); err != nil {
+		h.handleError(c, "RemoveMember", err)
+		return
+	}
+
+	h.logger.Info("Organization member removed",
+		zap.String("actor_id", actorID.String()),
+		zap.String("organization_id", orgID.String()),
+		zap.String("user_id", userID.String()))
+
+	response.Success(c, gin.H{"message": "Member removed"})
+}
+
+// caller returns the authenticated user
+func (h *Handler) caller(c *gin.Context) (uuid.UUID, bool) {
+	actorID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+	return actorID, true
+}
+
+// callerAndOrg returns the authenticated user and the organization in the path
+func (h *Handler) callerAndOrg(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, ok := h.caller(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	orgID, err := idgen.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid organization ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return actorID, orgID, true
+}
+
+// handleError writes application errors as-is and hides anything else
+func (h *Handler) handleError(c *gin.Context, operation string, err error) {
+	if appErr, ok := apperror.As(err); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error("Organization operation failed",
+		zap.String("operation", operation),
+		zap.Error(err))
+	_ = c.Error(err)
+	response.InternalServerError(c, "Something went wrong. Please try again later.")
+}
+
+func (h *Handler) toOrganizationResponse(org *domainOrg.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        h.ids.Format(org.ID),
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedBy: h.ids.Format(org.CreatedBy),
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+}
+
+func (h *Handler) toMemberResponse(member *domainOrg.Member) MemberResponse {
+	resp := MemberResponse{
+		OrganizationID: h.ids.Format(member.OrganizationID),
+		UserID:         h.ids.Format(member.UserID),
+		Email:          member.Email,
+		Role:           string(member.Role),
+		Status:         string(member.Status),
+		CreatedAt:      member.CreatedAt,
+		JoinedAt:       member.JoinedAt,
+	}
+	if member.InvitedBy != nil {
+		resp.InvitedBy = h.ids.Format(*member.InvitedBy)
+	}
+	return resp
+}
+
+func (h *Handler) toMemberResponses(members []*domainOrg.Member) []MemberResponse {
+	data := make([]MemberResponse, 0, len(members))
+	for _, member := range members {
+		data = append(data, h.toMemberResponse(member))
+	}
+	return data
+}
@@ -0,0 +1,262 @@
+package organization
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainOrg "github.com/yi-tech/go-user-service/internal/domain/organization"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	serviceOrg "github.com/yi-tech/go-user-service/internal/service/organization"
+)
+
+// MockOrganizationService is a mock implementation of serviceOrg.Service
+type MockOrganizationService struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationService) Create(ctx context.Context, actorID uuid.UUID, input domainOrg.Input) (*domainOrg.Organization, error) {
+	args := m.Called(ctx, actorID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainOrg.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) List(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Organization, error) {
+	args := m.Called(ctx, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainOrg.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) Get(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Organization, error) {
+	args := m.Called(ctx, actorID, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainOrg.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) ListMembers(ctx context.Context, actorID, orgID uuid.UUID) ([]*domainOrg.Member, error) {
+	args := m.Called(ctx, actorID, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainOrg.Member), args.Error(1)
+}
+
+func (m *MockOrganizationService) Invite(ctx context.Context, actorID, orgID uuid.UUID, email string, role domainOrg.Role) (*domainOrg.Member, error) {
+	args := m.Called(ctx, actorID, orgID, email, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainOrg.Member), args.Error(1)
+}
+
+func (m *MockOrganizationService) ListInvitations(ctx context.Context, actorID uuid.UUID) ([]*domainOrg.Member, error) {
+	args := m.Called(ctx, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainOrg.Member), args.Error(1)
+}
+
+func (m *MockOrganizationService) AcceptInvitation(ctx context.Context, actorID, orgID uuid.UUID) (*domainOrg.Member, error) {
+	args := m.Called(ctx, actorID, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainOrg.Member), args.Error(1)
+}
+
+func (m *MockOrganizationService) UpdateMemberRole(ctx context.Context, actorID, orgID, userID uuid.UUID, role domainOrg.Role) (*domainOrg.Member, error) {
+	args := m.Called(ctx, actorID, orgID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainOrg.Member), args.Error(1)
+}
+
+func (m *MockOrganizationService) RemoveMember(ctx context.Context, actorID, orgID, userID uuid.UUID) error {
+	return m.Called(ctx, actorID, orgID, userID).Error(0)
+}
+
+var (
+	testUserID   = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	testOrgID    = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	testMemberID = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+	testTime     = time.Date(2025, 6, 30, 9, 0, 0, 0, time.UTC)
+)
+
+// serve handles one request as the test user, or anonymously when authenticated is false
+func serve(t *testing.T, orgs *MockOrganizationService, authenticated bool, method, path, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(orgs, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+
+	router := gin.New()
+	group := router.Group("/api/v1/orgs", func(c *gin.Context) {
+		if authenticated {
+			requestctx.SetUserID(c, testUserID)
+		}
+	})
+	group.POST("", handler.CreateOrganization)
+	group.GET("/invitations", handler.ListInvitations)
+	group.GET("/:id/members", handler.ListMembers)
+	group.POST("/:id/members", handler.InviteMember)
+	group.DELETE("/:id/members/:user_id", handler.RemoveMember)
+
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCreateOrganization(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		orgs := new(MockOrganizationService)
+		orgs.On("Create", mock.Anything, testUserID, domainOrg.Input{Name: "Acme Corp."}).Return(&domainOrg.Organization{
+			ID: testOrgID, Name: "Acme Corp.", Slug: "acme-corp", CreatedBy: testUserID, CreatedAt: testTime, UpdatedAt: testTime,
+		}, nil)
+
+		rr := serve(t, orgs, true, http.MethodPost, "/api/v1/orgs", `{"name":"Acme Corp."}`)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"slug":"acme-corp"`)
+		assert.Contains(t, rr.Body.String(), `"createdBy":"11111111-1111-1111-1111-111111111111"`)
+		orgs.AssertExpectations(t)
+	})
+
+	t.Run("Slug In Use", func(t *testing.T) {
+		orgs := new(MockOrganizationService)
+		orgs.On("Create", mock.Anything, testUserID, domainOrg.Input{Name: "Acme", Slug: "acme"}).Return(nil, serviceOrg.ErrSlugInUse)
+
+		rr := serve(t, orgs, true, http.MethodPost, "/api/v1/orgs", `{"name":"Acme","slug":"acme"}`)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"ORGANIZATION_SLUG_IN_USE"`)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		rr := serve(t, new(MockOrganizationService), false, http.MethodPost, "/api/v1/orgs", `{"name":"Acme"}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestInviteMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Success",
+			body:           `{"email":"bob@example.com","role":"admin"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"status":"invited"`,
+		},
+		{
+			name:           "Role Not Allowed",
+			body:           `{"email":"bob@example.com","role":"admin"}`,
+			err:            serviceOrg.ErrPermissionDenied,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `"errorCode":"PERMISSION_DENIED"`,
+		},
+		{
+			name:           "Already Member",
+			body:           `{"email":"bob@example.com","role":"admin"}`,
+			err:            serviceOrg.ErrAlreadyMember,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"errorCode":"ALREADY_MEMBER"`,
+		},
+		{
+			name:           "Invalid Email",
+			body:           `{"email":"bob","role":"admin"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := new(MockOrganizationService)
+			if tt.err != nil {
+				orgs.On("Invite", mock.Anything, testUserID, testOrgID, "bob@example.com", domainOrg.RoleAdmin).Return(nil, tt.err)
+			} else {
+				orgs.On("Invite", mock.Anything, testUserID, testOrgID, "bob@example.com", domainOrg.RoleAdmin).Return(&domainOrg.Member{
+					OrganizationID: testOrgID,
+					UserID:         testMemberID,
+					Email:          "bob@example.com",
+					Role:           domainOrg.RoleAdmin,
+					Status:         domainOrg.StatusInvited,
+					InvitedBy:      &testUserID,
+					CreatedAt:      testTime,
+				}, nil)
+			}
+
+			rr := serve(t, orgs, true, http.MethodPost, "/api/v1/orgs/"+testOrgID.String()+"/members", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestListMembers_HidesOrganizationsOfOthers(t *testing.T) {
+	orgs := new(MockOrganizationService)
+	orgs.On("ListMembers", mock.Anything, testUserID, testOrgID).Return(nil, serviceOrg.ErrOrganizationNotFound)
+
+	rr := serve(t, orgs, true, http.MethodGet, "/api/v1/orgs/"+testOrgID.String()+"/members", "")
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"errorCode":"ORGANIZATION_NOT_FOUND"`)
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("Last Owner", func(t *testing.T) {
+		orgs := new(MockOrganizationService)
+		orgs.On("RemoveMember", mock.Anything, testUserID, testOrgID, testUserID).Return(serviceOrg.ErrLastOwner)
+
+		rr := serve(t, orgs, true, http.MethodDelete, "/api/v1/orgs/"+testOrgID.String()+"/members/"+testUserID.String(), "")
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"errorCode":"LAST_OWNER"`)
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		rr := serve(t, new(MockOrganizationService), true, http.MethodDelete, "/api/v1/orgs/"+testOrgID.String()+"/members/nope", "")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestListInvitations(t *testing.T) {
+	orgs := new(MockOrganizationService)
+	orgs.On("ListInvitations", mock.Anything, testUserID).Return([]*domainOrg.Member{{
+		OrganizationID: testOrgID,
+		UserID:         testUserID,
+		Role:           domainOrg.RoleMember,
+		Status:         domainOrg.StatusInvited,
+		InvitedBy:      &testMemberID,
+		CreatedAt:      testTime,
+	}}, nil)
+
+	rr := serve(t, orgs, true, http.MethodGet, "/api/v1/orgs/invitations", "")
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"invitedBy":"33333333-3333-3333-3333-333333333333"`)
+}
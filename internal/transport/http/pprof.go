@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pprofProfiles are the runtime profiles served by name, as listed on the
+// pprof index page
+var pprofProfiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// registerPprof serves the net/http/pprof handlers on group, which must be
+// mounted at /debug/pprof for the index page links to resolve. Importing
+// net/http/pprof also registers them on http.DefaultServeMux, which this
+// service never serves.
+func registerPprof(group *gin.RouterGroup) {
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range pprofProfiles {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
@@ -0,0 +1,12 @@
+package realtime
+
+import "time"
+
+// EventMessage is an event as sent to WebSocket clients, one JSON text frame per event
+type EventMessage struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type" example:"session.forced_logout"`
+	UserID     string    `json:"user_id"`
+	Reason     string    `json:"reason,omitempty" example:"deactivated"` // Set on session.forced_logout
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,92 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+)
+
+// subscriber is one event stream of an admin dashboard
+type subscriber struct {
+	events  chan domainEvent.Event
+	dropped chan struct{} // Closed when the feed drops the stream for falling behind
+}
+
+// Feed hands the events meant for admin dashboards to their streams and
+// keeps the most recent ones, so that a dashboard reconnecting with the ID
+// of the last event it saw misses nothing. Every instance receives every
+// event through the event bus, so a dashboard may resume on any of them.
+type Feed struct {
+	mu          sync.Mutex
+	history     []domainEvent.Event // Oldest first
+	size        int
+	subscribers map[*subscriber]struct{}
+	logger      *zap.Logger
+}
+
+// NewFeed creates a feed remembering the last size events
+func NewFeed(size int, logger *zap.Logger) *Feed {
+	return &Feed{size: size, subscribers: make(map[*subscriber]struct{}), logger: logger}
+}
+
+// Dispatch records e and queues it on every stream, ignoring events not
+// meant for admins. It never blocks: a stream whose queue is full is
+// dropped, and its dashboard resumes from the history after reconnecting.
+func (f *Feed) Dispatch(e domainEvent.Event) {
+	if !e.Type.ForAdmins() {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.history) == f.size {
+		copy(f.history, f.history[1:])
+		f.history = f.history[:f.size-1]
+	}
+	f.history = append(f.history, e)
+
+	for s := range f.subscribers {
+		select {
+		case s.events <- e:
+		default:
+			f.logger.Warn("Dropping admin event stream that fell behind", zap.String("event_type", string(e.Type)))
+			delete(f.subscribers, s)
+			close(s.dropped)
+		}
+	}
+}
+
+// subscribe adds a stream queueing up to buffer events. With a lastEventID
+// it also returns the events recorded after that one; when the ID is no
+// longer remembered every remembered event is returned, so the dashboard
+// may see some twice but misses none that the feed still has.
+func (f *Feed) subscribe(lastEventID uuid.UUID, buffer int) (*subscriber, []domainEvent.Event) {
+	s := &subscriber{events: make(chan domainEvent.Event, buffer), dropped: make(chan struct{})}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscribers[s] = struct{}{}
+
+	if lastEventID == uuid.Nil {
+		return s, nil
+	}
+	missed := f.history
+	for i, e := range f.history {
+		if e.ID == lastEventID {
+			missed = f.history[i+1:]
+			break
+		}
+	}
+	return s, append([]domainEvent.Event(nil), missed...)
+}
+
+// unsubscribe removes a stream; it is a no-op once the feed dropped it
+func (f *Feed) unsubscribe(s *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subscribers, s)
+}
@@ -0,0 +1,140 @@
+package realtime
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// maxClientMessageBytes bounds the frames read from clients, which have
+// nothing to send; reading only notices when they disconnect
+const maxClientMessageBytes = 4096
+
+// Handler upgrades authenticated requests to WebSocket connections that
+// receive the events of the caller, and streams account activity to admin
+// dashboards
+type Handler struct {
+	hub          *Hub
+	feed         *Feed
+	origins      []string
+	buffer       int
+	writeTimeout time.Duration
+	heartbeat    time.Duration
+	ids          idgen.Strategy // Text form of rendered IDs
+	logger       *zap.Logger
+}
+
+// NewHandler creates a new realtime handler
+func NewHandler(hub *Hub, feed *Feed, cfg config.RealtimeConfig, ids idgen.Strategy, logger *zap.Logger) *Handler {
+	return &Handler{
+		hub:          hub,
+		feed:         feed,
+		origins:      cfg.AllowedOrigins,
+		buffer:       cfg.Buffer(),
+		writeTimeout: cfg.WriteTimeout(),
+		heartbeat:    cfg.Heartbeat(),
+		ids:          ids,
+		logger:       logger,
+	}
+}
+
+// Connect handles opening a WebSocket for the caller's events
+// @Summary Realtime events
+// @Description Upgrade to a WebSocket receiving the events of the current user as JSON text frames: profile.updated, session.revoked and session.forced_logout. The server closes the connection after a session.forced_logout event; clients send nothing.
+// @Tags account
+// @Security BearerAuth
+// @Success 101 {object} EventMessage "Switching protocols; each frame is one event"
+// @Failure 400 {string} string "Not a WebSocket request"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {string} string "Origin not allowed"
+// @Router /ws [get]
+func (h *Handler) Connect(c *gin.Context) {
+	userID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serve(ws, userID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin rejects browsers on origins that are not allowed. Requests
+// without an Origin header do not come from browsers, so they cannot be
+// forged cross-site.
+func (h *Handler) checkOrigin(_ *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" || len(h.origins) == 0 || slices.Contains(h.origins, origin) {
+		return nil
+	}
+	h.logger.Warn("Rejected realtime connection from disallowed origin", zap.String("origin", origin))
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+// serve sends the events of userID until the client disconnects, falls
+// behind or is signed out
+func (h *Handler) serve(ws *websocket.Conn, userID uuid.UUID) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = maxClientMessageBytes
+
+	c := h.hub.register(userID, h.buffer)
+	defer h.hub.unregister(userID, c)
+	h.logger.Debug("Realtime connection opened", zap.String("user_id", userID.String()))
+
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var frame []byte
+		for websocket.Message.Receive(ws, &frame) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case <-gone:
+			h.logger.Debug("Realtime connection closed by client", zap.String("user_id", userID.String()))
+			return
+		case <-c.dropped:
+			return
+		case e := <-c.events:
+			if err := ws.SetWriteDeadline(time.Now().Add(h.writeTimeout)); err != nil {
+				return
+			}
+			if err := websocket.JSON.Send(ws, h.message(e)); err != nil {
+				h.logger.Debug("Failed to send realtime event", zap.String("user_id", userID.String()), zap.Error(err))
+				return
+			}
+			// The client has been told why; its tokens no longer work
+			if e.Type == domainEvent.TypeForcedLogout {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) message(e domainEvent.Event) EventMessage {
+	return EventMessage{
+		ID:         h.ids.Format(e.ID),
+		Type:       string(e.Type),
+		UserID:     h.ids.Format(e.UserID),
+		Reason:     e.Reason,
+		OccurredAt: e.OccurredAt,
+	}
+}
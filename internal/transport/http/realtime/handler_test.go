@@ -0,0 +1,140 @@
+package realtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+)
+
+// newTestServer serves Connect as the user named by the X-Test-User header
+func newTestServer(t *testing.T, hub *Hub, cfg config.RealtimeConfig) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		if id, err := uuid.Parse(c.GetHeader("X-Test-User")); err == nil {
+			requestctx.SetUserID(c, id)
+		}
+	}, NewHandler(hub, NewFeed(10, zap.NewNop()), cfg, idgen.StrategyUUIDv4, zap.NewNop()).Connect)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dial connects to server as userID from origin
+func dial(server *httptest.Server, userID uuid.UUID, origin string) (*websocket.Conn, error) {
+	cfg, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", origin)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Header.Set("X-Test-User", userID.String())
+	return websocket.DialConfig(cfg)
+}
+
+func receive(t *testing.T, ws *websocket.Conn) EventMessage {
+	t.Helper()
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var msg EventMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &msg))
+	return msg
+}
+
+func TestConnect(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Streams Events Of The Caller", func(t *testing.T) {
+		hub := NewHub(zap.NewNop())
+		ws, err := dial(newTestServer(t, hub, config.RealtimeConfig{}), userID, "http://localhost")
+		require.NoError(t, err)
+		defer ws.Close()
+		require.Eventually(t, func() bool { return hub.Connections() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		occurred := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		hub.Dispatch(domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeProfileUpdated, UserID: uuid.New(), OccurredAt: occurred})
+		eventID := uuid.New()
+		hub.Dispatch(domainEvent.Event{ID: eventID, Type: domainEvent.TypeProfileUpdated, UserID: userID, OccurredAt: occurred})
+
+		assert.Equal(t, EventMessage{
+			ID:         eventID.String(),
+			Type:       "profile.updated",
+			UserID:     userID.String(),
+			OccurredAt: occurred,
+		}, receive(t, ws))
+	})
+
+	t.Run("Closes After Forced Logout", func(t *testing.T) {
+		hub := NewHub(zap.NewNop())
+		ws, err := dial(newTestServer(t, hub, config.RealtimeConfig{}), userID, "http://localhost")
+		require.NoError(t, err)
+		defer ws.Close()
+		require.Eventually(t, func() bool { return hub.Connections() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		hub.Dispatch(domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeForcedLogout, UserID: userID, Reason: domainEvent.ReasonDeactivated})
+
+		msg := receive(t, ws)
+		assert.Equal(t, "session.forced_logout", msg.Type)
+		assert.Equal(t, "deactivated", msg.Reason)
+
+		var next EventMessage
+		assert.Error(t, websocket.JSON.Receive(ws, &next), "the server closes the connection")
+		assert.Eventually(t, func() bool { return hub.Connections() == 0 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Rejects Disallowed Origin", func(t *testing.T) {
+		hub := NewHub(zap.NewNop())
+		server := newTestServer(t, hub, config.RealtimeConfig{AllowedOrigins: []string{"https://app.example.com"}})
+
+		_, err := dial(server, userID, "https://evil.example.com")
+		assert.Error(t, err)
+
+		ws, err := dial(server, userID, "https://app.example.com")
+		require.NoError(t, err)
+		ws.Close()
+	})
+
+	t.Run("Requires Authentication", func(t *testing.T) {
+		server := newTestServer(t, NewHub(zap.NewNop()), config.RealtimeConfig{})
+
+		resp, err := http.Get(server.URL + "/ws")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestHub_DropsConnectionsThatFallBehind(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	userID := uuid.New()
+	slow := hub.register(userID, 1)
+	other := hub.register(uuid.New(), 1)
+
+	hub.Dispatch(domainEvent.Event{Type: domainEvent.TypeProfileUpdated, UserID: userID})
+	hub.Dispatch(domainEvent.Event{Type: domainEvent.TypeProfileUpdated, UserID: userID})
+
+	select {
+	case <-slow.dropped:
+	default:
+		t.Fatal("the connection with a full queue should be dropped")
+	}
+	assert.Equal(t, 1, hub.Connections())
+	assert.Len(t, other.events, 0)
+
+	// Unregistering a dropped connection is harmless
+	hub.unregister(userID, slow)
+	assert.Equal(t, 1, hub.Connections())
+}
@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+)
+
+// client is one WebSocket connection of a user
+type client struct {
+	events  chan domainEvent.Event
+	dropped chan struct{} // Closed when the hub drops the connection for falling behind
+}
+
+// Hub hands the events of each user to the WebSocket connections the user
+// has open on this instance. It subscribes to the event bus, which brings
+// the events published on every instance.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[uuid.UUID]map[*client]struct{}
+	logger  *zap.Logger
+}
+
+// NewHub creates a hub without connections
+func NewHub(logger *zap.Logger) *Hub {
+	return &Hub{clients: make(map[uuid.UUID]map[*client]struct{}), logger: logger}
+}
+
+// Dispatch queues e on every connection of its user, ignoring events not
+// meant for users. It never blocks: a connection whose queue is full is
+// dropped, and its client reconnects.
+func (h *Hub) Dispatch(e domainEvent.Event) {
+	if !e.Type.ForUser() {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.clients[e.UserID] {
+		select {
+		case c.events <- e:
+		default:
+			h.logger.Warn("Dropping realtime connection that fell behind",
+				zap.String("user_id", e.UserID.String()),
+				zap.String("event_type", string(e.Type)))
+			h.remove(e.UserID, c)
+			close(c.dropped)
+		}
+	}
+}
+
+// Connections returns how many connections are open on this instance
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, clients := range h.clients {
+		n += len(clients)
+	}
+	return n
+}
+
+// register adds a connection of userID queueing up to buffer events
+func (h *Hub) register(userID uuid.UUID, buffer int) *client {
+	c := &client{events: make(chan domainEvent.Event, buffer), dropped: make(chan struct{})}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*client]struct{})
+	}
+	h.clients[userID][c] = struct{}{}
+	return c
+}
+
+// unregister removes a connection; it is a no-op once the hub dropped it
+func (h *Hub) unregister(userID uuid.UUID, c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(userID, c)
+}
+
+func (h *Hub) remove(userID uuid.UUID, c *client) {
+	delete(h.clients[userID], c)
+	if len(h.clients[userID]) == 0 {
+		delete(h.clients, userID)
+	}
+}
@@ -0,0 +1,111 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+)
+
+// StreamAdminEvents handles streaming account activity to admin dashboards
+// @Summary Stream account activity
+// @Description Stream user.registered, user.logged_in and user.deleted events as Server-Sent Events. Each event carries its ID, so a client reconnecting with the Last-Event-ID header receives the events it missed while they are still remembered; without the header only new events are sent. A stream falling behind is closed and resumes the same way. Idle streams receive a comment line every heartbeat.
+// @Tags admin
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} EventMessage "One event per data line"
+// @Failure 400 {object} response.Response "Invalid Last-Event-ID"
+// @Failure 401 {object} response.Response "Authentication required"
+// @Failure 403 {object} response.Response "Administrator role required"
+// @Router /admin/v1/events/stream [get]
+func (h *Handler) StreamAdminEvents(c *gin.Context) {
+	var lastEventID uuid.UUID
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		id, err := idgen.Parse(raw)
+		if err != nil {
+			response.BadRequest(c, "Invalid Last-Event-ID")
+			return
+		}
+		lastEventID = id
+	}
+
+	s, missed := h.feed.subscribe(lastEventID, h.buffer)
+	defer h.feed.unsubscribe(s)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Keep proxies from holding events back
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	write := func(frame func(io.Writer) error) bool {
+		if err := rc.SetWriteDeadline(time.Now().Add(h.writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return false
+		}
+		if err := frame(c.Writer); err != nil {
+			h.logger.Debug("Failed to send admin event", zap.Error(err))
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	// Tell the client how soon to reconnect, and flush the headers so that
+	// it knows the stream is open before the first event
+	if !write(func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", time.Second.Milliseconds())
+		return err
+	}) {
+		return
+	}
+	for _, e := range missed {
+		if !write(h.eventFrame(e)) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-s.dropped:
+			return
+		case e := <-s.events:
+			if !write(h.eventFrame(e)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			}) {
+				return
+			}
+		}
+	}
+}
+
+// eventFrame renders e as a Server-Sent Event named after its type
+func (h *Handler) eventFrame(e domainEvent.Event) func(io.Writer) error {
+	return func(w io.Writer) error {
+		data, err := json.Marshal(h.message(e))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", h.ids.Format(e.ID), e.Type, data)
+		return err
+	}
+}
@@ -0,0 +1,164 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainEvent "github.com/yi-tech/go-user-service/internal/domain/event"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// sseEvent is one event read from a stream
+type sseEvent struct {
+	id   string
+	name string
+	data EventMessage
+}
+
+func newStreamServer(t *testing.T, feed *Feed) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHandler(NewHub(zap.NewNop()), feed, config.RealtimeConfig{HeartbeatSeconds: 60}, idgen.StrategyUUIDv4, zap.NewNop())
+	router.GET("/admin/v1/events/stream", handler.StreamAdminEvents)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// openStream requests the stream, resuming after lastEventID when it is set
+func openStream(t *testing.T, server *httptest.Server, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/v1/events/stream", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// nextEvent reads frames until one carries an event
+func nextEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.id != "":
+			return e
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data))
+		}
+	}
+}
+
+func TestStreamAdminEvents(t *testing.T) {
+	registered := domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserRegistered, UserID: uuid.New()}
+	loggedIn := domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserLoggedIn, UserID: uuid.New()}
+	deleted := domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserDeleted, UserID: uuid.New()}
+
+	t.Run("Streams New Events", func(t *testing.T) {
+		feed := NewFeed(10, zap.NewNop())
+		feed.Dispatch(registered)
+		resp, r := openStream(t, newStreamServer(t, feed), "")
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		feed.Dispatch(domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeProfileUpdated, UserID: uuid.New()})
+		feed.Dispatch(loggedIn)
+
+		e := nextEvent(t, r)
+		assert.Equal(t, loggedIn.ID.String(), e.id, "events before connecting and user events are not sent")
+		assert.Equal(t, "user.logged_in", e.name)
+		assert.Equal(t, loggedIn.UserID.String(), e.data.UserID)
+	})
+
+	t.Run("Resumes After Last Event ID", func(t *testing.T) {
+		feed := NewFeed(10, zap.NewNop())
+		feed.Dispatch(registered)
+		feed.Dispatch(loggedIn)
+		_, r := openStream(t, newStreamServer(t, feed), registered.ID.String())
+
+		assert.Equal(t, loggedIn.ID.String(), nextEvent(t, r).id)
+		feed.Dispatch(deleted)
+		assert.Equal(t, deleted.ID.String(), nextEvent(t, r).id)
+	})
+
+	t.Run("Rejects Invalid Last Event ID", func(t *testing.T) {
+		resp, _ := openStream(t, newStreamServer(t, NewFeed(10, zap.NewNop())), "not-an-id")
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestFeed_Subscribe(t *testing.T) {
+	events := make([]domainEvent.Event, 3)
+	feed := NewFeed(2, zap.NewNop())
+	for i := range events {
+		events[i] = domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserRegistered}
+		feed.Dispatch(events[i])
+	}
+
+	t.Run("Returns Events After The Last One Seen", func(t *testing.T) {
+		s, missed := feed.subscribe(events[1].ID, 1)
+		defer feed.unsubscribe(s)
+		assert.Equal(t, events[2:], missed)
+	})
+
+	t.Run("Returns Whole History For Forgotten Event", func(t *testing.T) {
+		s, missed := feed.subscribe(events[0].ID, 1)
+		defer feed.unsubscribe(s)
+		assert.Equal(t, events[1:], missed, "only the last two events are kept")
+	})
+
+	t.Run("Returns Nothing Without Last Event", func(t *testing.T) {
+		s, missed := feed.subscribe(uuid.Nil, 1)
+		defer feed.unsubscribe(s)
+		assert.Empty(t, missed)
+	})
+}
+
+func TestFeed_DropsStreamsThatFallBehind(t *testing.T) {
+	feed := NewFeed(10, zap.NewNop())
+	slow, _ := feed.subscribe(uuid.Nil, 1)
+
+	feed.Dispatch(domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserLoggedIn})
+	feed.Dispatch(domainEvent.Event{ID: uuid.New(), Type: domainEvent.TypeUserLoggedIn})
+
+	select {
+	case <-slow.dropped:
+	case <-time.After(time.Second):
+		t.Fatal("the stream with a full queue should be dropped")
+	}
+
+	// The dropped stream can resume from the history
+	s, missed := feed.subscribe(uuid.New(), 1)
+	defer feed.unsubscribe(s)
+	assert.Len(t, missed, 2)
+
+	// Unsubscribing a dropped stream is harmless
+	feed.unsubscribe(slow)
+}
@@ -0,0 +1,73 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format identifies the envelope used to render responses
+type Format string
+
+// Supported response formats
+const (
+	FormatDefault Format = "default" // The unified Response envelope
+	FormatJSONAPI Format = "jsonapi" // JSON:API (https://jsonapi.org) documents
+)
+
+// formatKey is the gin context key holding the response format for a request
+const formatKey = "response_format"
+
+// SetFormat selects the response format for the current request
+func SetFormat(c *gin.Context, format Format) {
+	c.Set(formatKey, format)
+}
+
+// FormatOf returns the response format selected for the current request
+func FormatOf(c *gin.Context) Format {
+	if v, ok := c.Get(formatKey); ok {
+		if format, ok := v.(Format); ok {
+			return format
+		}
+	}
+	return FormatDefault
+}
+
+// warningKey is the gin context key holding the warning attached to a response
+const warningKey = "response_warning"
+
+// SetWarning attaches a warning, such as a deprecation notice, to the
+// response of the current request
+func SetWarning(c *gin.Context, warning string) {
+	c.Set(warningKey, warning)
+}
+
+// render writes resp in the format and language selected for the request
+func render(c *gin.Context, status int, resp *Response) {
+	resp.Warning = c.GetString(warningKey)
+	localize(c, resp)
+	if FormatOf(c) == FormatJSONAPI {
+		renderJSONAPI(c, status, resp)
+		return
+	}
+	c.JSON(status, resp)
+}
+
+// ParseFormat converts a configuration value to a Format.
+// An empty value selects FormatDefault; unknown values are rejected.
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatDefault:
+		return FormatDefault, nil
+	case FormatJSONAPI:
+		return FormatJSONAPI, nil
+	default:
+		return "", fmt.Errorf("unknown response format %q", value)
+	}
+}
+
+// isError reports whether the status code denotes an error response
+func isError(status int) bool {
+	return status >= http.StatusBadRequest
+}
@@ -0,0 +1,158 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents
+const JSONAPIContentType = "application/vnd.api+json"
+
+// Resource is implemented by DTOs that can be rendered as JSON:API resource objects
+type Resource interface {
+	ResourceType() string
+	ResourceID() string
+}
+
+// jsonAPIResource is a JSON:API resource object
+type jsonAPIResource struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Links      interface{}            `json:"links,omitempty"`
+}
+
+// jsonAPIError is a JSON:API error object
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title"`
+}
+
+// jsonAPIDocument is a top-level JSON:API document
+type jsonAPIDocument struct {
+	Data   interface{}            `json:"data,omitempty"`
+	Errors []jsonAPIError         `json:"errors,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// renderJSONAPI writes resp as a JSON:API document. Resources become resource
+// objects under "data"; any other payload and any warning are placed under "meta".
+// A page of resources keeps its paging details under "meta".
+func renderJSONAPI(c *gin.Context, status int, resp *Response) {
+	doc := jsonAPIDocument{}
+
+	if isError(status) {
+		doc.Errors = []jsonAPIError{{
+			Status: strconv.Itoa(status),
+			Code:   resp.ErrorCode,
+			Title:  resp.Message,
+		}}
+	} else if page, ok := resp.Data.(*PaginatedResponse); ok && isResourceList(page.Data) {
+		doc.Data, _ = toJSONAPIData(page.Data)
+		doc.Meta = map[string]interface{}{
+			"page":       page.Page,
+			"pageSize":   page.PageSize,
+			"total":      page.Total,
+			"totalPages": page.TotalPages,
+		}
+	} else if data, ok := toJSONAPIData(resp.Data); ok {
+		doc.Data = data
+	} else {
+		doc.Meta = map[string]interface{}{"message": resp.Message}
+		if resp.Data != nil {
+			doc.Meta["data"] = resp.Data
+		}
+	}
+
+	if resp.Warning != "" {
+		if doc.Meta == nil {
+			doc.Meta = map[string]interface{}{}
+		}
+		doc.Meta["warning"] = resp.Warning
+	}
+
+	c.Render(status, jsonAPIRender{doc: doc})
+}
+
+// toJSONAPIData converts a Resource, or a slice of Resources, into resource objects
+func toJSONAPIData(data interface{}) (interface{}, bool) {
+	if res, ok := data.(Resource); ok {
+		obj, err := toJSONAPIResource(res)
+		return obj, err == nil
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	objs := make([]jsonAPIResource, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		res, ok := v.Index(i).Interface().(Resource)
+		if !ok {
+			return nil, false
+		}
+		obj, err := toJSONAPIResource(res)
+		if err != nil {
+			return nil, false
+		}
+		objs = append(objs, obj)
+	}
+	return objs, true
+}
+
+// isResourceList reports whether data is a slice that renders as resource objects
+func isResourceList(data interface{}) bool {
+	if reflect.ValueOf(data).Kind() != reflect.Slice {
+		return false
+	}
+	_, ok := toJSONAPIData(data)
+	return ok
+}
+
+// toJSONAPIResource builds a resource object whose attributes are the JSON
+// fields of res, excluding the id. Links rendered under _links become the
+// links of the resource object.
+func toJSONAPIResource(res Resource) (jsonAPIResource, error) {
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return jsonAPIResource{}, err
+	}
+	var attrs map[string]interface{}
+	if err := json.Unmarshal(raw, &attrs); err != nil {
+		return jsonAPIResource{}, err
+	}
+	delete(attrs, "id")
+	links := attrs["_links"]
+	delete(attrs, "_links")
+
+	return jsonAPIResource{
+		Type:       res.ResourceType(),
+		ID:         res.ResourceID(),
+		Attributes: attrs,
+		Links:      links,
+	}, nil
+}
+
+// jsonAPIRender is a gin render.Render writing JSON:API documents
+type jsonAPIRender struct {
+	doc jsonAPIDocument
+}
+
+// Render writes the document as JSON
+func (r jsonAPIRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.doc)
+}
+
+// WriteContentType sets the JSON:API media type
+func (r jsonAPIRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{JSONAPIContentType}
+	}
+}
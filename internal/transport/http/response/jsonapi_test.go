@@ -0,0 +1,113 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+type testResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (r testResource) ResourceType() string { return "things" }
+func (r testResource) ResourceID() string   { return r.ID }
+
+type linkedResource struct {
+	ID    string            `json:"id"`
+	Links map[string]string `json:"_links"`
+}
+
+func (r linkedResource) ResourceType() string { return "things" }
+func (r linkedResource) ResourceID() string   { return r.ID }
+
+func serve(t *testing.T, format Format, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	rr := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(rr)
+	router.GET("/", func(c *gin.Context) {
+		SetFormat(c, format)
+		handler(c)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestJSONAPIRendering(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Single Resource",
+			handler:        func(c *gin.Context) { Success(c, testResource{ID: "1", Name: "one"}) },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"type":"things","id":"1","attributes":{"name":"one"}}}`,
+		},
+		{
+			name:           "Resource Collection",
+			handler:        func(c *gin.Context) { Success(c, []testResource{{ID: "1", Name: "one"}}) },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[{"type":"things","id":"1","attributes":{"name":"one"}}]}`,
+		},
+		{
+			name: "Resource Links",
+			handler: func(c *gin.Context) {
+				Success(c, linkedResource{ID: "1", Links: map[string]string{"self": "/things/1"}})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"type":"things","id":"1","links":{"self":"/things/1"}}}`,
+		},
+		{
+			name:           "Non-Resource Payload",
+			handler:        func(c *gin.Context) { Success(c, gin.H{"message": "done"}) },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"meta":{"message":"Success","data":{"message":"done"}}}`,
+		},
+		{
+			name: "Resource Page",
+			handler: func(c *gin.Context) {
+				Paginated(c, []testResource{{ID: "1", Name: "one"}}, PageMeta{Page: 2, PageSize: 1, Total: 3})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[{"type":"things","id":"1","attributes":{"name":"one"}}],"meta":{"page":2,"pageSize":1,"total":3,"totalPages":3}}`,
+		},
+		{
+			name: "Application Error",
+			handler: func(c *gin.Context) {
+				AppError(c, apperror.New(apperror.CodeUserNotFound, "user not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"errors":[{"status":"404","code":"USER_NOT_FOUND","title":"user not found"}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serve(t, FormatJSONAPI, tc.handler)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.Equal(t, JSONAPIContentType, rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestDefaultFormatUnchanged(t *testing.T) {
+	rr := serve(t, FormatDefault, func(c *gin.Context) { NotFound(c, "user not found") })
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.JSONEq(t, `{"code":404,"message":"user not found"}`, rr.Body.String())
+}
@@ -0,0 +1,50 @@
+package response
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/i18n"
+)
+
+// languageKey is the gin context key holding the language of the messages
+// of a request, or the LanguageFunc choosing it
+const languageKey = "response_language"
+
+// LanguageFunc chooses the language of the messages of a request. It is
+// called when the first response is rendered, once the caller has been
+// identified.
+type LanguageFunc func(c *gin.Context) i18n.Language
+
+// SetLanguage selects the language of the messages of the current request
+func SetLanguage(c *gin.Context, lang i18n.Language) {
+	c.Set(languageKey, lang)
+}
+
+// SetLanguageFunc defers choosing the language of the messages of the
+// current request to choose
+func SetLanguageFunc(c *gin.Context, choose LanguageFunc) {
+	c.Set(languageKey, choose)
+}
+
+// LanguageOf returns the language selected for the current request
+func LanguageOf(c *gin.Context) i18n.Language {
+	v, _ := c.Get(languageKey)
+	switch v := v.(type) {
+	case i18n.Language:
+		return v
+	case LanguageFunc:
+		lang := v(c)
+		SetLanguage(c, lang)
+		return lang
+	}
+	return i18n.Default
+}
+
+// localize translates the message of resp into the language of the request
+// and tells caches that the response depends on Accept-Language
+func localize(c *gin.Context, resp *Response) {
+	lang := LanguageOf(c)
+	resp.Message = i18n.Message(lang, apperror.Code(resp.ErrorCode), resp.Message)
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+}
@@ -0,0 +1,95 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/i18n"
+)
+
+func TestLocalizedMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		lang         i18n.Language
+		handler      gin.HandlerFunc
+		expectedBody string
+	}{
+		{
+			name:         "Default Language",
+			handler:      func(c *gin.Context) { Success(c, nil) },
+			expectedBody: `{"code":200,"message":"Success"}`,
+		},
+		{
+			name:         "Success",
+			lang:         i18n.Chinese,
+			handler:      func(c *gin.Context) { Success(c, nil) },
+			expectedBody: `{"code":200,"message":"成功"}`,
+		},
+		{
+			name:         "Plain Error",
+			lang:         i18n.Chinese,
+			handler:      func(c *gin.Context) { BadRequest(c, "Invalid request data") },
+			expectedBody: `{"code":400,"message":"请求数据无效"}`,
+		},
+		{
+			name: "Application Error Keeps Code",
+			lang: i18n.Chinese,
+			handler: func(c *gin.Context) {
+				AppError(c, apperror.New(apperror.CodeVersionMismatch, "user has been modified since it was read"))
+			},
+			expectedBody: `{"code":412,"message":"用户在读取后已被修改","errorCode":"VERSION_MISMATCH"}`,
+		},
+		{
+			name: "JSON:API Error",
+			lang: i18n.Chinese,
+			handler: func(c *gin.Context) {
+				SetFormat(c, FormatJSONAPI)
+				AppError(c, apperror.New(apperror.CodeUserNotFound, "user not found"))
+			},
+			expectedBody: `{"errors":[{"status":"404","code":"USER_NOT_FOUND","title":"用户不存在"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.lang != "" {
+				SetLanguage(c, tt.lang)
+			}
+
+			tt.handler(c)
+
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			expectedLang := tt.lang
+			if expectedLang == "" {
+				expectedLang = i18n.Default
+			}
+			assert.Equal(t, string(expectedLang), rr.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestLanguageFunc(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	calls := 0
+	SetLanguageFunc(c, func(c *gin.Context) i18n.Language {
+		calls++
+		return i18n.Chinese
+	})
+
+	assert.Equal(t, i18n.Chinese, LanguageOf(c))
+	assert.Equal(t, i18n.Chinese, LanguageOf(c))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,52 @@
+package response
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageMeta describes the page of a listing being returned
+type PageMeta struct {
+	Page     int   // 1-based page number
+	PageSize int   // Maximum number of items per page
+	Total    int64 // Number of items matching the request across all pages
+}
+
+// TotalPages returns the number of pages needed to list every matching item
+func (m PageMeta) TotalPages() int {
+	if m.PageSize <= 0 {
+		return 0
+	}
+	return int((m.Total + int64(m.PageSize) - 1) / int64(m.PageSize))
+}
+
+// PaginatedResponse is the payload of list and search endpoints. Filters and
+// sorting are taken from query parameters; the payload only describes the page.
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"pageSize"`
+	Total      int64       `json:"total"`
+	TotalPages int         `json:"totalPages"`
+}
+
+// NewPaginatedResponse wraps a page of items. A nil slice is rendered as an
+// empty array so clients always receive a list.
+func NewPaginatedResponse(items interface{}, meta PageMeta) *PaginatedResponse {
+	if v := reflect.ValueOf(items); !v.IsValid() || (v.Kind() == reflect.Slice && v.IsNil()) {
+		items = []interface{}{}
+	}
+	return &PaginatedResponse{
+		Data:       items,
+		Page:       meta.Page,
+		PageSize:   meta.PageSize,
+		Total:      meta.Total,
+		TotalPages: meta.TotalPages(),
+	}
+}
+
+// Paginated sends a successful response holding a page of items
+func Paginated(c *gin.Context, items interface{}, meta PageMeta) {
+	Success(c, NewPaginatedResponse(items, meta))
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginated(t *testing.T) {
+	tests := []struct {
+		name         string
+		items        interface{}
+		meta         PageMeta
+		expectedBody string
+	}{
+		{
+			name:         "Partial Last Page",
+			items:        []string{"a", "b"},
+			meta:         PageMeta{Page: 3, PageSize: 10, Total: 22},
+			expectedBody: `{"code":200,"message":"Success","data":{"data":["a","b"],"page":3,"pageSize":10,"total":22,"totalPages":3}}`,
+		},
+		{
+			name:         "Exact Pages",
+			items:        []string{"a"},
+			meta:         PageMeta{Page: 2, PageSize: 1, Total: 2},
+			expectedBody: `{"code":200,"message":"Success","data":{"data":["a"],"page":2,"pageSize":1,"total":2,"totalPages":2}}`,
+		},
+		{
+			name:         "Nil Slice",
+			items:        []string(nil),
+			meta:         PageMeta{Page: 1, PageSize: 20},
+			expectedBody: `{"code":200,"message":"Success","data":{"data":[],"page":1,"pageSize":20,"total":0,"totalPages":0}}`,
+		},
+		{
+			name:         "Nil Items",
+			items:        nil,
+			meta:         PageMeta{Page: 1, PageSize: 20},
+			expectedBody: `{"code":200,"message":"Success","data":{"data":[],"page":1,"pageSize":20,"total":0,"totalPages":0}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serve(t, FormatDefault, func(c *gin.Context) { Paginated(c, tc.items, tc.meta) })
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestPageMeta_TotalPages(t *testing.T) {
+	assert.Equal(t, 0, PageMeta{Page: 1, PageSize: 0, Total: 5}.TotalPages())
+	assert.Equal(t, 1, PageMeta{Page: 1, PageSize: 5, Total: 5}.TotalPages())
+	assert.Equal(t, 2, PageMeta{Page: 1, PageSize: 5, Total: 6}.TotalPages())
+}
@@ -4,13 +4,16 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
 )
 
 // Response represents the unified API response structure.
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"errorCode,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Warning   string      `json:"warning,omitempty"` // Set on responses from deprecated routes
 }
 
 // NewResponse creates a new Response instance.
@@ -20,12 +23,22 @@ func NewResponse(code int, message string, data interface{}) *Response {
 
 // Success sends a successful response.
 func Success(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, NewResponse(http.StatusOK, "Success", data))
+	render(c, http.StatusOK, NewResponse(http.StatusOK, "Success", data))
+}
+
+// Created sends a 201 Created response.
+func Created(c *gin.Context, message string, data interface{}) {
+	render(c, http.StatusCreated, NewResponse(http.StatusCreated, message, data))
+}
+
+// Accepted sends a 202 Accepted response for work that continues in the background.
+func Accepted(c *gin.Context, message string, data interface{}) {
+	render(c, http.StatusAccepted, NewResponse(http.StatusAccepted, message, data))
 }
 
 // Error sends an error response.
 func Error(c *gin.Context, code int, message string) {
-	c.JSON(code, NewResponse(code, message, nil))
+	render(c, code, NewResponse(code, message, nil))
 }
 
 // BadRequest sends a 400 Bad Request error response.
@@ -57,3 +70,10 @@ func InternalServerError(c *gin.Context, message string) {
 func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, message)
 }
+
+// AppError sends an error response for an application error, deriving the
+// HTTP status from the shared error-code catalog.
+func AppError(c *gin.Context, err *apperror.Error) {
+	status := apperror.HTTPStatus(err.Code)
+	render(c, status, &Response{Code: status, Message: err.Message, ErrorCode: string(err.Code)})
+}
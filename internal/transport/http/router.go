@@ -1,88 +1,472 @@
 package http
 
 import (
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/config"
+	"github.com/yi-tech/go-user-service/internal/deprecation"
+	"github.com/yi-tech/go-user-service/internal/domain/apikey"
+	"github.com/yi-tech/go-user-service/internal/domain/audit"
 	"github.com/yi-tech/go-user-service/internal/domain/auth"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	"github.com/yi-tech/go-user-service/internal/errorreport"
+	"github.com/yi-tech/go-user-service/internal/featureflag"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/ipfilter"
+	"github.com/yi-tech/go-user-service/internal/loadshed"
+	"github.com/yi-tech/go-user-service/internal/logging"
 	"github.com/yi-tech/go-user-service/internal/middleware"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	"github.com/yi-tech/go-user-service/internal/recovery"
+	accountCenter "github.com/yi-tech/go-user-service/internal/transport/http/account"
+	adminHandler "github.com/yi-tech/go-user-service/internal/transport/http/admin"
 	authHandler "github.com/yi-tech/go-user-service/internal/transport/http/auth"
+	healthHandler "github.com/yi-tech/go-user-service/internal/transport/http/health"
+	jwksHandler "github.com/yi-tech/go-user-service/internal/transport/http/jwks"
+	"github.com/yi-tech/go-user-service/internal/transport/http/links"
+	messageHandler "github.com/yi-tech/go-user-service/internal/transport/http/message"
+	orgHandler "github.com/yi-tech/go-user-service/internal/transport/http/org"
+	organizationHandler "github.com/yi-tech/go-user-service/internal/transport/http/organization"
+	realtimeHandler "github.com/yi-tech/go-user-service/internal/transport/http/realtime"
 	"github.com/yi-tech/go-user-service/internal/transport/http/response"
 	userHandler "github.com/yi-tech/go-user-service/internal/transport/http/user"
 	"go.uber.org/zap"
 )
 
-// SetupRouter configures the Gin router with all routes
+// SetupRouter configures the Gin router with all routes. The ops endpoints,
+// /health/details, /debug and the admin API, go to ops instead when it is
+// not nil. It fails if the configuration names an unknown response format.
 func SetupRouter(
 	router *gin.Engine,
+	ops *gin.Engine,
 	userHandler *userHandler.Handler,
+	availabilityHandler *userHandler.AvailabilityHandler,
+	availabilityLimiter *middleware.RateLimiter,
 	authHandler *authHandler.Handler,
+	adminHandler *adminHandler.Handler,
+	accountHandler *adminHandler.AccountHandler,
+	messageHandler *messageHandler.Handler,
+	jwksHandler *jwksHandler.Handler,
+	readOnlyHandler *adminHandler.ReadOnlyHandler,
+	importHandler *adminHandler.ImportHandler,
+	exportHandler *adminHandler.ExportHandler,
+	orgHandler *orgHandler.Handler,
+	organizationHandler *organizationHandler.Handler,
+	accountCenterHandler *accountCenter.Handler,
+	healthHandler *healthHandler.Handler,
+	realtimeHandler *realtimeHandler.Handler,
+	featureFlagHandler *adminHandler.FeatureFlagHandler,
+	loggingHandler *adminHandler.LoggingHandler,
+	webhookHandler *adminHandler.WebhookHandler,
+	samlHandler *authHandler.SAMLHandler,
+	samlProviderHandler *adminHandler.SAMLProviderHandler,
 	authService auth.AuthService,
+	userLookup middleware.UserLookup,
+	apiKeys middleware.APIKeyAuthenticator,
+	readOnlySwitch *readonly.Switch,
+	auditRepo audit.Repository,
+	ids idgen.Generator,
+	cfg *config.Config,
 	logger *zap.Logger,
-) {
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
+) error {
+	for group := range cfg.Response.Groups {
+		if !slices.Contains(routeGroups, group) {
+			return fmt.Errorf("response format configured for unknown route group %q", group)
+		}
+	}
+	for group := range cfg.CacheControl.Groups {
+		if !slices.Contains(routeGroups, group) {
+			return fmt.Errorf("cache control configured for unknown route group %q", group)
+		}
+	}
+	for group := range cfg.Limits.Groups {
+		if !slices.Contains(routeGroups, group) {
+			return fmt.Errorf("request limits configured for unknown route group %q", group)
+		}
+	}
+	for group := range cfg.IPFilter.Groups {
+		if !slices.Contains(routeGroups, group) {
+			return fmt.Errorf("IP filter configured for unknown route group %q", group)
+		}
+	}
+	globalFilter, groupFilters, err := ipfilter.FromConfig(cfg.IPFilter)
+	if err != nil {
+		return err
+	}
+	formats := make(map[string]response.Format, len(routeGroups))
+	for _, group := range routeGroups {
+		format, err := response.ParseFormat(cfg.Response.FormatFor(group))
+		if err != nil {
+			return fmt.Errorf("response format for route group %q: %w", group, err)
+		}
+		formats[group] = format
+	}
+
+	// responseFormat selects the configured response envelope for a route group.
+	// It must run before any middleware that can reject the request so that
+	// those errors use the group's envelope too.
+	responseFormat := func(group string) gin.HandlerFunc {
+		return middleware.ResponseFormatMiddleware(formats[group])
+	}
+	// ipFilter rejects the clients a route group does not serve; the global
+	// rules apply to every route on top
+	ipFilter := func(group string) gin.HandlerFunc {
+		return middleware.IPFilterMiddleware(groupFilters[group], logger)
+	}
+	// cacheControl sets the caching headers configured for a route group
+	cacheControl := func(group string) gin.HandlerFunc {
+		policy := cfg.CacheControl.PolicyFor(group)
+		return middleware.CacheControlMiddleware(middleware.CachePolicy{CacheControl: policy.CacheControl, Vary: policy.Vary})
+	}
+	// timeout bounds how long the requests of a route group may take. Streams
+	// run for as long as the client listens.
+	timeout := func(group string) gin.HandlerFunc {
+		return middleware.TimeoutMiddleware(cfg.Limits.LimitFor(group).Timeout(), logger,
+			"/admin/v1/events/stream",
+			"/admin/v1/users/export")
+	}
+	// bodyLimit bounds the request bodies of a route group. The user import
+	// enforces its own, larger limit.
+	bodyLimit := func(group string) gin.HandlerFunc {
+		return middleware.BodyLimitMiddleware(cfg.Limits.LimitFor(group).MaxBodyBytes,
+			"/admin/v1/users/import")
+	}
+	if ops == nil {
+		ops = router
+	}
+	// loadShed caps the requests a route group serves at once, so a burst on
+	// one group, such as sign-ins, cannot exhaust the database pool for the
+	// others. Streams would hold their slot for as long as a client listens.
+	shedLimiters := make(map[string]*loadshed.Limiter, len(routeGroups))
+	for _, group := range routeGroups {
+		shedLimiters[group] = loadshed.NewLimiter(cfg.Limits.LimitFor(group).MaxInFlight)
+	}
+	loadShed := func(group string) gin.HandlerFunc {
+		return middleware.LoadShedMiddleware(shedLimiters[group], logger,
+			"/admin/v1/events/stream",
+			"/admin/v1/users/export")
+	}
+	authMiddleware := middleware.AuthMiddleware(authService, logger)
+	// readOnly rejects writes while read-only mode is on. Sign-in and batch
+	// lookups stay open, as does the switch itself so that the mode can be
+	// turned off again.
+	readOnly := middleware.ReadOnlyMiddleware(readOnlySwitch, logger,
+		"/api/v1/users/batch-get",
+		"/api/v1/auth/login",
+		"/api/v1/auth/refresh",
+		"/api/v1/auth/logout",
+		"/api/v1/auth/saml/:tenant/acs",
+		"/admin/v1/read-only")
+	// requestAudit keeps the redacted bodies of admin mutations for forensic
+	// review. It runs before readOnly so that rejected writes are recorded too.
+	requestAudit := middleware.RequestAuditMiddleware(auditRepo, ids, logger)
+	// Response messages are rendered in the language of the caller on every route
+	language := middleware.LanguageMiddleware(userLookup, logger)
+	// Every request made with an impersonation token is audited, reads included
+	impersonationAudit := middleware.ImpersonationAuditMiddleware(auditRepo, ids, logger)
+	// Clients the global rules do not serve are turned away on every route
+	globalIPFilter := middleware.IPFilterMiddleware(globalFilter, logger)
+	router.Use(globalIPFilter, language, impersonationAudit)
+	if ops != router {
+		ops.Use(globalIPFilter, language, impersonationAudit)
+	}
+
+	// Health check; load balancers of either listener probe it
+	liveness := func(c *gin.Context) {
 		response.Success(c, gin.H{"status": "ok"})
-	})
+	}
+	router.GET("/health", liveness)
+	if ops != router {
+		ops.GET("/health", liveness)
+	}
+	ops.GET("/health/details", healthHandler.GetDetails)
+	// Build and configuration of this instance, for administrators
+	ops.GET("/debug/info", authMiddleware, middleware.RequireRole(userLookup, logger, rbac.RoleAdmin), healthHandler.GetInfo)
+	if cfg.Debug.Pprof {
+		registerPprof(ops.Group("/debug/pprof", authMiddleware, middleware.RequireRole(userLookup, logger, rbac.RoleAdmin)))
+	}
+
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	// Announcements; signed-in callers also see role-targeted messages
+	router.GET("/system/messages",
+		responseFormat("system"),
+		ipFilter("system"),
+		cacheControl("system"),
+		bodyLimit("system"),
+		timeout("system"),
+		loadShed("system"),
+		middleware.OptionalAuthMiddleware(authService, logger),
+		messageHandler.ListMessages)
+
+	// Events of the signed-in user over WebSocket
+	router.GET("/ws", authMiddleware, realtimeHandler.Connect)
 
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group(links.APIBase)
 	{
-		// Public routes
-		public := v1.Group("/")
+		// User routes
+		userGroup := v1.Group("/users", responseFormat("users"), ipFilter("users"), cacheControl("users"), bodyLimit("users"), timeout("users"), loadShed("users"), readOnly)
+		{
+			// Public
+			userGroup.POST("/register", userHandler.Register)
+			userGroup.GET("", userHandler.GetUserByEmail)
+			// Heavily throttled: this endpoint can be used to enumerate accounts.
+			// Both paths share one per-client budget.
+			availabilityLimit := middleware.RateLimitMiddleware(availabilityLimiter, logger)
+			userGroup.GET("/availability", availabilityLimit, availabilityHandler.CheckAvailability)
+			userGroup.GET("/check-availability", availabilityLimit, availabilityHandler.CheckAvailability)
+			userGroup.POST("/batch-get", userHandler.BatchGetUsers)
+			userGroup.GET("/:id", userHandler.GetUserByID)
+
+			// Protected (require authentication)
+			userGroup.PUT("/:id", authMiddleware, userHandler.UpdateProfile) // This remains PUT for admin/specific user update
+			userGroup.PATCH("/:id/password", authMiddleware, userHandler.UpdatePassword)
+			userGroup.PATCH("/:id/metadata", authMiddleware, middleware.RequireSelfOrRole(userLookup, logger, "id", rbac.RoleAdmin), userHandler.UpdateMetadata)
+			userGroup.DELETE("/:id", authMiddleware, userHandler.DeleteUser)
+			userGroup.PATCH("/:id/status", authMiddleware, middleware.RequireRole(userLookup, logger, rbac.RoleAdmin), requestAudit, accountHandler.SetUserStatus)
+		}
+
+		// Auth routes
+		authGroup := v1.Group("/auth", responseFormat("auth"), ipFilter("auth"), cacheControl("auth"), bodyLimit("auth"), timeout("auth"), loadShed("auth"), readOnly)
+		{
+			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/refresh", authHandler.RefreshToken)
+			authGroup.POST("/password-reset", authHandler.CompletePasswordReset)
+			// Either token identifies the session, so clients whose access
+			// token has expired can still sign out with the refresh token
+			authGroup.POST("/logout", middleware.OptionalAuthMiddleware(authService, logger), authHandler.Logout)
+			// SAML service provider of each tenant; the identity provider posts
+			// the response of the browser to the assertion consumer service
+			authGroup.GET("/saml/:tenant/metadata", samlHandler.Metadata)
+			authGroup.POST("/saml/:tenant/acs", samlHandler.AssertionConsumer)
+		}
+
+		// Profile routes (require authentication)
+		profileGroup := v1.Group("/profile", responseFormat("profile"), ipFilter("profile"), cacheControl("profile"), bodyLimit("profile"), timeout("profile"), loadShed("profile"), readOnly, authMiddleware)
+		{
+			profileGroup.GET("", userHandler.GetProfile)
+			profileGroup.PUT("", userHandler.UpdateCurrentUserProfile)
+			profileGroup.GET("/login-history", accountCenterHandler.ListLoginHistory)
+			profileGroup.GET("/security-events", accountCenterHandler.ListSecurityEvents)
+			profileGroup.POST("/security-events/:id/report", accountCenterHandler.ReportSecurityEvent)
+		}
+
+		// Account center: the settings page of the authenticated user in one
+		// place, with the profile routes repeated so clients need no other group
+		accountGroup := v1.Group("/account", responseFormat("account"), ipFilter("account"), cacheControl("account"), bodyLimit("account"), timeout("account"), loadShed("account"), readOnly, authMiddleware)
 		{
-			// User routes
-			userGroup := public.Group("/users")
-			{
-				userGroup.POST("/register", userHandler.Register)
-				userGroup.GET("", userHandler.GetUserByEmail)
-				userGroup.GET("/:id", userHandler.GetUserByID)
-			}
-
-			// Auth routes
-			authGroup := public.Group("/auth")
-			{
-				authGroup.POST("/login", authHandler.Login)
-				authGroup.POST("/refresh", authHandler.RefreshToken)
-				authGroup.POST("/logout", authHandler.Logout)
-			}
+			accountGroup.GET("/profile", userHandler.GetProfile)
+			accountGroup.PUT("/profile", userHandler.UpdateCurrentUserProfile)
+			accountGroup.PUT("/password", accountCenterHandler.ChangePassword)
+			accountGroup.GET("/sessions", accountCenterHandler.ListSessions)
+			accountGroup.DELETE("/sessions", accountCenterHandler.RevokeSessions)
+			accountGroup.GET("/security-events", accountCenterHandler.ListSecurityEvents)
+			accountGroup.POST("/security-events/:id/report", accountCenterHandler.ReportSecurityEvent)
+			accountGroup.GET("/login-history", accountCenterHandler.ListLoginHistory)
+		}
+
+		// Organization routes: organization admins manage the API keys of
+		// their own organization, which server-to-server clients call with
+		orgGroup := v1.Group("/org", responseFormat("org"), ipFilter("org"), cacheControl("org"), bodyLimit("org"), timeout("org"), loadShed("org"))
+		{
+			apiKeyGroup := orgGroup.Group("/api-keys",
+				authMiddleware,
+				middleware.RequireRole(userLookup, logger, rbac.RoleAdmin, rbac.RoleOrgAdmin),
+				requestAudit,
+				readOnly)
+			apiKeyGroup.GET("", orgHandler.ListAPIKeys)
+			apiKeyGroup.POST("", orgHandler.CreateAPIKey)
+			apiKeyGroup.POST("/:id/rotate", orgHandler.RotateAPIKey)
+			apiKeyGroup.DELETE("/:id", orgHandler.RevokeAPIKey)
+
+			orgGroup.GET("/users", middleware.APIKeyMiddleware(apiKeys, logger, apikey.ScopeUsersRead), orgHandler.ListUsers)
 		}
 
-		// Protected routes (require authentication)
-		authMiddleware := middleware.AuthMiddleware(authService, logger)
-		protected := v1.Group("/")
-		protected.Use(authMiddleware)
+		// Organizations and their members (require authentication). Roles
+		// within an organization are checked by the organization service.
+		organizationGroup := v1.Group("/orgs", responseFormat("orgs"), ipFilter("orgs"), cacheControl("orgs"), bodyLimit("orgs"), timeout("orgs"), loadShed("orgs"), readOnly, authMiddleware)
 		{
-			// User routes
-			userGroup := protected.Group("/users")
-			{
-				userGroup.PUT("/:id", userHandler.UpdateProfile) // This remains PUT for admin/specific user update
-				userGroup.PATCH("/:id/password", userHandler.UpdatePassword)
-				userGroup.DELETE("/:id", userHandler.DeleteUser)
-			}
-
-			// Profile routes
-			profileGroup := protected.Group("/profile")
-			{
-				profileGroup.GET("", userHandler.GetProfile)
-				profileGroup.PUT("", userHandler.UpdateCurrentUserProfile)
-			}
+			organizationGroup.GET("", organizationHandler.ListOrganizations)
+			organizationGroup.POST("", organizationHandler.CreateOrganization)
+			organizationGroup.GET("/invitations", organizationHandler.ListInvitations)
+			organizationGroup.GET("/:id", organizationHandler.GetOrganization)
+			organizationGroup.GET("/:id/members", organizationHandler.ListMembers)
+			organizationGroup.POST("/:id/members", organizationHandler.InviteMember)
+			organizationGroup.POST("/:id/invitation/accept", organizationHandler.AcceptInvitation)
+			organizationGroup.PUT("/:id/members/:user_id", organizationHandler.UpdateMemberRole)
+			organizationGroup.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
 		}
 	}
+
+	// Admin API v1: roles, signing keys, account management, system messages, read-only mode and feature flags, restricted to administrators
+	adminV1 := ops.Group(links.AdminBase,
+		responseFormat("admin"),
+		ipFilter("admin"),
+		cacheControl("admin"),
+		bodyLimit("admin"),
+		timeout("admin"),
+		loadShed("admin"),
+		authMiddleware,
+		middleware.RequireRole(userLookup, logger, rbac.RoleAdmin),
+		requestAudit,
+		readOnly)
+	{
+		adminV1.GET("/roles", adminHandler.ListRoles)
+		adminV1.GET("/permissions", adminHandler.ListPermissions)
+		adminV1.POST("/keys/rotate", adminHandler.RotateSigningKey)
+
+		adminV1.GET("/users", accountHandler.ListUsers)
+		adminV1.GET("/users/export", exportHandler.ExportUsers)
+		adminV1.POST("/users/export/jobs", exportHandler.StartExportJob)
+		adminV1.GET("/users/export/jobs/:id", exportHandler.GetExportJob)
+		adminV1.GET("/users/export/jobs/:id/download", exportHandler.DownloadExport)
+		adminV1.POST("/users/import", importHandler.ImportUsers)
+		adminV1.GET("/users/import/:id", importHandler.GetImportJob)
+		adminV1.POST("/users/:id/password-reset", accountHandler.ForcePasswordReset)
+		adminV1.POST("/users/:id/expire-password", accountHandler.ExpirePassword)
+		adminV1.POST("/users/:id/deactivate", middleware.DeprecationMiddleware(deactivateUserDeprecation, logger), accountHandler.DeactivateUser)
+		adminV1.GET("/users/:id/sessions", accountHandler.ListSessions)
+		adminV1.GET("/users/:id/login-history", accountHandler.ListLoginHistory)
+		adminV1.GET("/users/:id/auth-keys", accountHandler.InspectAuthKeys)
+		adminV1.POST("/users/:id/impersonate", accountHandler.ImpersonateUser)
+		adminV1.DELETE("/impersonations/:id", accountHandler.RevokeImpersonation)
+		adminV1.GET("/audit-logs", accountHandler.ListAuditLogs)
+		adminV1.GET("/events/stream", realtimeHandler.StreamAdminEvents)
+
+		adminV1.GET("/system-messages", messageHandler.ListAllMessages)
+		adminV1.POST("/system-messages", messageHandler.CreateMessage)
+		adminV1.PUT("/system-messages/:id", messageHandler.UpdateMessage)
+		adminV1.DELETE("/system-messages/:id", messageHandler.DeleteMessage)
+
+		adminV1.GET("/read-only", readOnlyHandler.GetReadOnly)
+		adminV1.PUT("/read-only", readOnlyHandler.SetReadOnly)
+
+		adminV1.GET("/feature-flags", featureFlagHandler.ListFeatureFlags)
+		adminV1.PUT("/feature-flags/:name", featureFlagHandler.SetFeatureFlag)
+		adminV1.DELETE("/feature-flags/:name", featureFlagHandler.DeleteFeatureFlag)
+
+		adminV1.GET("/logging/level", loggingHandler.GetLogLevels)
+		adminV1.PUT("/logging/level", loggingHandler.SetLogLevel)
+
+		adminV1.GET("/webhooks", webhookHandler.ListWebhooks)
+		adminV1.POST("/webhooks", webhookHandler.CreateWebhook)
+		adminV1.GET("/webhooks/:id", webhookHandler.GetWebhook)
+		adminV1.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+		adminV1.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		adminV1.GET("/webhooks/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+
+		adminV1.GET("/saml/providers", samlProviderHandler.ListSAMLProviders)
+		adminV1.GET("/saml/providers/:tenant", samlProviderHandler.GetSAMLProvider)
+		adminV1.PUT("/saml/providers/:tenant", samlProviderHandler.SaveSAMLProvider)
+		adminV1.DELETE("/saml/providers/:tenant", samlProviderHandler.DeleteSAMLProvider)
+	}
+
+	return nil
+}
+
+// deactivateUserDeprecation announces the move from the admin deactivate
+// action to the status endpoint, which can also re-activate accounts
+var deactivateUserDeprecation = deprecation.Notice{
+	Since:       time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	Sunset:      time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC),
+	Replacement: "PATCH /api/v1/users/{id}/status",
+}
+
+// routeGroups lists the route groups whose response format, caching, request limits and IP filter can be configured
+var routeGroups = []string{"system", "users", "auth", "profile", "account", "org", "orgs", "admin"}
+
+// Routers are the Gin engines of the HTTP listeners
+type Routers struct {
+	Public *gin.Engine // The API, on app.port
+	// Admin serves the ops endpoints on app.admin_port, so they can be
+	// firewalled apart from the API; nil when they are served on the public port
+	Admin *gin.Engine
 }
 
-// NewRouter creates a new Gin router and sets up routes
+// NewRouter creates the Gin routers and sets up routes
 func NewRouter(
 	userHandler *userHandler.Handler,
+	availabilityHandler *userHandler.AvailabilityHandler,
+	availabilityLimiter *middleware.RateLimiter,
 	authHandler *authHandler.Handler,
+	adminHandler *adminHandler.Handler,
+	accountHandler *adminHandler.AccountHandler,
+	messageHandler *messageHandler.Handler,
+	jwksHandler *jwksHandler.Handler,
+	readOnlyHandler *adminHandler.ReadOnlyHandler,
+	importHandler *adminHandler.ImportHandler,
+	exportHandler *adminHandler.ExportHandler,
+	orgHandler *orgHandler.Handler,
+	organizationHandler *organizationHandler.Handler,
+	accountCenterHandler *accountCenter.Handler,
+	healthHandler *healthHandler.Handler,
+	realtimeHandler *realtimeHandler.Handler,
+	featureFlagHandler *adminHandler.FeatureFlagHandler,
+	loggingHandler *adminHandler.LoggingHandler,
+	webhookHandler *adminHandler.WebhookHandler,
+	samlHandler *authHandler.SAMLHandler,
+	samlProviderHandler *adminHandler.SAMLProviderHandler,
 	authService auth.AuthService,
+	userLookup middleware.UserLookup,
+	apiKeys middleware.APIKeyAuthenticator,
+	readOnlySwitch *readonly.Switch,
+	auditRepo audit.Repository,
+	ids idgen.Generator,
+	panics *recovery.Recorder,
+	errorReporter errorreport.Reporter,
+	cfg *config.Config,
 	logger *zap.Logger,
-) *gin.Engine {
-	router := gin.New()
-
-	// Use middleware
-	router.Use(gin.Recovery())
+) (*Routers, error) {
+	routers := &Routers{}
+	var err error
+	if routers.Public, err = newEngine(panics, errorReporter, cfg, logger); err != nil {
+		return nil, err
+	}
+	if cfg.App.AdminPort != 0 {
+		if routers.Admin, err = newEngine(panics, errorReporter, cfg, logger); err != nil {
+			return nil, err
+		}
+	}
 
 	// Setup routes
-	SetupRouter(router, userHandler, authHandler, authService, logger)
+	if err := SetupRouter(routers.Public, routers.Admin, userHandler, availabilityHandler, availabilityLimiter, authHandler, adminHandler, accountHandler, messageHandler, jwksHandler, readOnlyHandler, importHandler, exportHandler, orgHandler, organizationHandler, accountCenterHandler, healthHandler, realtimeHandler, featureFlagHandler, loggingHandler, webhookHandler, samlHandler, samlProviderHandler, authService, userLookup, apiKeys, readOnlySwitch, auditRepo, ids, cfg, logger); err != nil {
+		return nil, err
+	}
 
-	return router
+	return routers, nil
+}
+
+// newEngine creates a Gin engine with the middleware shared by every listener
+func newEngine(panics *recovery.Recorder, errorReporter errorreport.Reporter, cfg *config.Config, logger *zap.Logger) (*gin.Engine, error) {
+	router := gin.New()
+
+	// Only trust forwarding headers set by our own proxies; otherwise clients
+	// could pick the IP their requests are rate limited by. The address is
+	// resolved once and read with clientip.FromRequest; Gin's ClientIP is
+	// configured alike for anything still calling it.
+	resolver, err := clientip.NewResolver(cfg.App.TrustedProxies, cfg.App.ClientIPHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	router.RemoteIPHeaders = resolver.Headers()
+
+	// Use middleware
+	router.Use(middleware.ClientIPMiddleware(resolver), middleware.RecoveryMiddleware(panics), middleware.RequestIDMiddleware(), middleware.LoggingMiddleware(logger.Named(logging.HTTP)),
+		middleware.FeatureOverrideMiddleware(featureflag.NewVerifier(cfg.FeatureFlags.OverrideSecret, cfg.FeatureFlags.OverrideMaxTTL()), logger))
+	if errorReporter != nil {
+		router.Use(middleware.ErrorReportMiddleware(errorReporter))
+	}
+	if cfg.Compression.Enabled {
+		router.Use(middleware.CompressionMiddleware(middleware.NewCompressor(cfg.Compression.MinSize(), cfg.Compression.Types())))
+	}
+	return router, nil
 }
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/middleware"
+	"github.com/yi-tech/go-user-service/internal/openapi"
+	"github.com/yi-tech/go-user-service/internal/readonly"
+	userHandler "github.com/yi-tech/go-user-service/internal/transport/http/user"
+)
+
+func TestSetupRouter_ResponseFormatAppliesToAuthErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Response.Groups = map[string]string{"admin": "jsonapi", "profile": "default"}
+
+	router := gin.New()
+	require.NoError(t, SetupRouter(router, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, readonly.NewSwitch(false), nil, nil, cfg, zap.NewNop()))
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedBody string
+	}{
+		{
+			name:         "JSON:API Group",
+			path:         "/admin/v1/users",
+			expectedBody: `{"errors":[{"status":"401","code":"UNAUTHENTICATED","title":"Authorization header is required"}]}`,
+		},
+		{
+			name:         "Default Group",
+			path:         "/api/v1/profile",
+			expectedBody: `{"code":401,"message":"Authorization header is required","errorCode":"UNAUTHENTICATED"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestSetupRouter_RejectsInvalidResponseConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		response config.ResponseConfig
+	}{
+		{name: "Unknown Global Format", response: config.ResponseConfig{Format: "xml"}},
+		{name: "Unknown Group Format", response: config.ResponseConfig{Groups: map[string]string{"users": "json-api"}}},
+		{name: "Unknown Group", response: config.ResponseConfig{Groups: map[string]string{"accounts": "jsonapi"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Response: tt.response}
+			err := SetupRouter(gin.New(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg, zap.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSetupRouter_RejectsCacheControlForUnknownGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.CacheControl.Groups = map[string]config.CachePolicyConfig{"accounts": {CacheControl: "no-store"}}
+
+	err := SetupRouter(gin.New(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg, zap.NewNop())
+
+	assert.ErrorContains(t, err, `cache control configured for unknown route group "accounts"`)
+}
+
+func TestSetupRouter_RejectsLimitsForUnknownGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Limits.Groups = map[string]config.LimitConfig{"uploads": {MaxBodyBytes: 1 << 20}}
+
+	err := SetupRouter(gin.New(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg, zap.NewNop())
+
+	assert.ErrorContains(t, err, `request limits configured for unknown route group "uploads"`)
+}
+
+func TestSetupRouter_RejectsIPFilterForUnknownGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.IPFilter.Groups = map[string]config.IPRules{"ops": {Allow: []string{"10.0.0.0/8"}}}
+
+	err := SetupRouter(gin.New(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg, zap.NewNop())
+
+	assert.ErrorContains(t, err, `IP filter configured for unknown route group "ops"`)
+}
+
+// freeIdentifiers reports every identifier as available
+type freeIdentifiers struct{}
+
+func (freeIdentifiers) CheckAvailability(ctx context.Context, email, username string) (*domainUser.Availability, error) {
+	available := true
+	return &domainUser.Availability{EmailAvailable: &available}, nil
+}
+
+func TestSetupRouter_AvailabilityPathsShareRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	availability := userHandler.NewAvailabilityHandler(freeIdentifiers{}, nil, zap.NewNop())
+	limiter := middleware.NewRateLimiter(1, time.Minute)
+
+	router := gin.New()
+	require.NoError(t, SetupRouter(router, nil, nil, availability, limiter, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, readonly.NewSwitch(false), nil, nil, &config.Config{}, zap.NewNop()))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/users/check-availability?email=jane@example.com", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"code":200,"message":"Success","data":{"emailAvailable":true}}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/users/availability?email=jane@example.com", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+}
+
+func TestSetupRouter_PprofRequiresAdministrator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		pprof    bool
+		expected int
+	}{
+		{name: "Disabled", pprof: false, expected: http.StatusNotFound},
+		{name: "Enabled Without Token", pprof: true, expected: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Debug: config.DebugConfig{Pprof: tt.pprof}}
+			router := gin.New()
+			require.NoError(t, SetupRouter(router, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, readonly.NewSwitch(false), nil, nil, cfg, zap.NewNop()))
+
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/debug/pprof/heap", nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expected, rr.Code)
+		})
+	}
+}
+
+func TestRegisterPprof(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerPprof(router.Group("/debug/pprof"))
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "Index", path: "/debug/pprof/", expected: "goroutine"},
+		{name: "Heap", path: "/debug/pprof/heap?debug=1", expected: "heap profile"},
+		{name: "Goroutines", path: "/debug/pprof/goroutine?debug=1", expected: "goroutine profile"},
+		{name: "Command Line", path: "/debug/pprof/cmdline", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expected)
+		})
+	}
+}
+
+func TestSetupRouter_OpsEndpointsOnAdminListener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Debug: config.DebugConfig{Pprof: true}}
+
+	router, ops := gin.New(), gin.New()
+	require.NoError(t, SetupRouter(router, ops, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, readonly.NewSwitch(false), nil, nil, cfg, zap.NewNop()))
+
+	tests := []struct {
+		name   string
+		path   string
+		public int
+		admin  int
+	}{
+		{name: "Liveness", path: "/health", public: http.StatusOK, admin: http.StatusOK},
+		{name: "Debug Info", path: "/debug/info", public: http.StatusNotFound, admin: http.StatusUnauthorized},
+		{name: "Pprof", path: "/debug/pprof/heap", public: http.StatusNotFound, admin: http.StatusUnauthorized},
+		{name: "Admin API", path: "/admin/v1/users", public: http.StatusNotFound, admin: http.StatusUnauthorized},
+		{name: "Public API", path: "/api/v1/profile", public: http.StatusUnauthorized, admin: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for engine, expected := range map[*gin.Engine]int{router: tt.public, ops: tt.admin} {
+				rr := httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+				engine.ServeHTTP(rr, req)
+
+				assert.Equal(t, expected, rr.Code)
+			}
+		})
+	}
+}
+
+// gatewayRESTRoutes maps the gateway routes the REST API serves at another
+// path than /api followed by the gateway path. An empty value marks a gateway
+// route without a REST equivalent.
+var gatewayRESTRoutes = map[string]string{
+	"POST /v1/auth/register": "POST /api/v1/users/register",
+	"POST /v1/auth/validate": "", // REST routes validate tokens in middleware
+	"GET /v1/auth/user":      "",
+}
+
+func TestSetupRouter_MatchesOpenAPISpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, SetupRouter(router, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, readonly.NewSwitch(false), nil, nil, &config.Config{}, zap.NewNop()))
+	routes := make(map[string]bool)
+	for _, route := range router.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+	gateway := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			route := strings.ToUpper(method) + " " + path
+			gateway[route] = true
+
+			rest, ok := gatewayRESTRoutes[route]
+			if !ok {
+				rest = strings.ToUpper(method) + " /api" + path
+			}
+			if rest == "" {
+				continue
+			}
+			// The spec writes path parameters as {id}, gin as :id
+			rest = strings.NewReplacer("{", ":", "}", "").Replace(rest)
+			assert.True(t, routes[rest], "gateway route %s has no REST route %s", route, rest)
+		}
+	}
+	for route := range gatewayRESTRoutes {
+		assert.True(t, gateway[route], "%s is no longer in the spec", route)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,19 +11,33 @@ import (
 	"github.com/yi-tech/go-user-service/internal/config"
 )
 
-// Server represents the HTTP server
+// Server represents the HTTP server: the public API listener and, when
+// app.admin_port is set, the admin listener of the ops endpoints
 type Server struct {
 	router *gin.Engine
 	server *http.Server
+	admin  *http.Server // nil when the ops endpoints are served on the public port
 	cfg    *config.Config
 }
 
 // NewServer creates a new HTTP server
-func NewServer(router *gin.Engine, cfg *config.Config) *Server {
-	return &Server{
-		router: router,
-		cfg:    cfg,
+func NewServer(routers *Routers, cfg *config.Config) *Server {
+	// Created up front so Shutdown is safe even if Serve has not started yet
+	s := &Server{
+		router: routers.Public,
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.App.Port),
+			Handler: routers.Public,
+		},
+		cfg: cfg,
 	}
+	if routers.Admin != nil {
+		s.admin = &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.App.AdminPort),
+			Handler: routers.Admin,
+		}
+	}
+	return s
 }
 
 // Router returns the Gin router
@@ -30,23 +45,39 @@ func (s *Server) Router() *gin.Engine {
 	return s.router
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.cfg.App.Port),
-		Handler: s.router,
+// Serve runs the HTTP server until it fails or is shut down. A graceful
+// Shutdown makes Serve return nil; either listener failing makes it return
+// the error.
+func (s *Server) Serve() error {
+	if s.admin == nil {
+		return serve(s.server, "HTTP server")
+	}
+	errs := make(chan error, 2)
+	go func() { errs <- serve(s.server, "HTTP server") }()
+	go func() { errs <- serve(s.admin, "admin HTTP server") }()
+	for range 2 {
+		if err := <-errs; err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return s.server.ListenAndServe()
+// serve runs one listener until it fails or is shut down
+func serve(server *http.Server, name string) error {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s error: %w", name, err)
+	}
+	return nil
 }
 
-// Shutdown gracefully shuts down the HTTP server
+// Shutdown gracefully shuts down the HTTP server, waiting for in-flight
+// requests until ctx expires
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.server == nil {
-		return nil
+	if s.admin == nil {
+		return s.server.Shutdown(ctx)
 	}
-
-	return s.server.Shutdown(ctx)
+	return errors.Join(s.server.Shutdown(ctx), s.admin.Shutdown(ctx))
 }
 
 // WithMiddleware adds middleware to the router
@@ -56,8 +87,6 @@ func (s *Server) WithMiddleware(middleware ...gin.HandlerFunc) {
 
 // WithTimeout sets the read/write timeout for the server
 func (s *Server) WithTimeout(read, write time.Duration) {
-	if s.server != nil {
-		s.server.ReadTimeout = read
-		s.server.WriteTimeout = write
-	}
+	s.server.ReadTimeout = read
+	s.server.WriteTimeout = write
 }
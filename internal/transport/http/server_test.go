@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yi-tech/go-user-service/internal/config"
+)
+
+func TestServer_ServeAfterShutdownReturnsNil(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.App.Port = 0
+
+	s := NewServer(&Routers{Public: gin.New(), Admin: gin.New()}, cfg)
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	assert.NoError(t, s.Serve())
+}
+
+func TestServer_ServeReturnsStartupError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Occupy a port so the server fails to bind
+	lis, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	cfg := &config.Config{}
+	cfg.App.Port = lis.Addr().(*net.TCPAddr).Port
+
+	s := NewServer(&Routers{Public: gin.New()}, cfg)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+
+	select {
+	case err := <-errCh:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return a startup error")
+	}
+}
+
+func TestServer_ServeReturnsAdminStartupError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Occupy the admin port; the public listener starts fine
+	lis, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	cfg := &config.Config{}
+	cfg.App.Port = 0
+	cfg.App.AdminPort = lis.Addr().(*net.TCPAddr).Port
+
+	s := NewServer(&Routers{Public: gin.New(), Admin: gin.New()}, cfg)
+	defer s.Shutdown(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+
+	select {
+	case err := <-errCh:
+		assert.ErrorContains(t, err, "admin HTTP server")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return the admin startup error")
+	}
+}
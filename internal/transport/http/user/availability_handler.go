@@ -0,0 +1,91 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+	realServiceUser "github.com/yi-tech/go-user-service/internal/service/user"
+	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"go.uber.org/zap"
+)
+
+// CaptchaHeader carries the CAPTCHA token when CAPTCHA gating is enabled
+const CaptchaHeader = "X-Captcha-Token"
+
+// AvailabilityHandler handles signup availability checks
+type AvailabilityHandler struct {
+	checker  realServiceUser.AvailabilityChecker
+	verifier captcha.Verifier // nil when CAPTCHA gating is disabled
+	logger   *zap.Logger
+}
+
+// NewAvailabilityHandler creates a new availability handler
+func NewAvailabilityHandler(checker realServiceUser.AvailabilityChecker, verifier captcha.Verifier, logger *zap.Logger) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		checker:  checker,
+		verifier: verifier,
+		logger:   logger,
+	}
+}
+
+// CheckAvailability handles checking whether an email or username can be used to sign up
+// @Summary Check signup availability
+// @Description Report whether an email and/or username is free. Rate limited per client and optionally CAPTCHA-gated via the X-Captcha-Token header.
+// @Tags users
+// @Produce json
+// @Param email query string false "Email to check"
+// @Param username query string false "Username to check"
+// @Param X-Captcha-Token header string false "CAPTCHA token, required when CAPTCHA gating is enabled"
+// @Success 200 {object} response.Response{data=AvailabilityResponse} "Availability"
+// @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 403 {object} response.Response "CAPTCHA verification failed"
+// @Failure 429 {object} response.Response "Too many requests"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/availability [get]
+// @Router /users/check-availability [get]
+func (h *AvailabilityHandler) CheckAvailability(c *gin.Context) {
+	var req AvailabilityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Warn("Invalid availability request",
+			zap.String("operation", "CheckAvailability"),
+			zap.Error(err))
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+	if req.Email == "" && req.Username == "" {
+		response.BadRequest(c, "Email or username is required")
+		return
+	}
+
+	if h.verifier != nil {
+		if err := h.verifier.Verify(c.Request.Context(), c.GetHeader(CaptchaHeader), clientip.FromRequest(c.Request)); err != nil {
+			if appErr, ok := apperror.As(err); ok {
+				response.AppError(c, appErr)
+				return
+			}
+			h.logger.Error("Failed to verify captcha",
+				zap.String("operation", "CheckAvailability"),
+				zap.Error(err))
+			_ = c.Error(err)
+			response.InternalServerError(c, "Something went wrong. Please try again later.")
+			return
+		}
+	}
+
+	result, err := h.checker.CheckAvailability(c.Request.Context(), req.Email, req.Username)
+	if err != nil {
+		// Identifiers are deliberately not logged; this endpoint is a probing target
+		h.logger.Error("Failed to check availability",
+			zap.String("operation", "CheckAvailability"),
+			zap.Error(err))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	response.Success(c, AvailabilityResponse{
+		EmailAvailable:    result.EmailAvailable,
+		UsernameAvailable: result.UsernameAvailable,
+	})
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
+)
+
+// MockAvailabilityChecker is a mock type for the AvailabilityChecker interface
+type MockAvailabilityChecker struct {
+	mock.Mock
+}
+
+func (m *MockAvailabilityChecker) CheckAvailability(ctx context.Context, email, username string) (*domainUser.Availability, error) {
+	args := m.Called(ctx, email, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.Availability), args.Error(1)
+}
+
+// MockCaptchaVerifier is a mock type for the captcha.Verifier interface
+type MockCaptchaVerifier struct {
+	mock.Mock
+}
+
+func (m *MockCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	args := m.Called(ctx, token, remoteIP)
+	return args.Error(0)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestCheckAvailability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		captchaToken   string
+		withCaptcha    bool
+		mockSetup      func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Both Identifiers",
+			query: "?email=new@example.com&username=newname",
+			mockSetup: func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {
+				checker.On("CheckAvailability", mock.Anything, "new@example.com", "newname").
+					Return(&domainUser.Availability{EmailAvailable: boolPtr(true), UsernameAvailable: boolPtr(false)}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"emailAvailable":true,"usernameAvailable":false}}`,
+		},
+		{
+			name:  "Email Only",
+			query: "?email=taken@example.com",
+			mockSetup: func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {
+				checker.On("CheckAvailability", mock.Anything, "taken@example.com", "").
+					Return(&domainUser.Availability{EmailAvailable: boolPtr(false)}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"emailAvailable":false}}`,
+		},
+		{
+			name:           "No Identifier",
+			query:          "",
+			mockSetup:      func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Email or username is required"}`,
+		},
+		{
+			name:           "Invalid Email",
+			query:          "?email=not-an-email",
+			mockSetup:      func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:         "Captcha Rejected",
+			query:        "?email=new@example.com",
+			captchaToken: "bad",
+			withCaptcha:  true,
+			mockSetup: func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {
+				verifier.On("Verify", mock.Anything, "bad", mock.Anything).Return(captcha.ErrCaptchaFailed).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"code":403,"message":"captcha verification failed","errorCode":"CAPTCHA_FAILED"}`,
+		},
+		{
+			name:         "Captcha Accepted",
+			query:        "?username=newname",
+			captchaToken: "good",
+			withCaptcha:  true,
+			mockSetup: func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {
+				verifier.On("Verify", mock.Anything, "good", mock.Anything).Return(nil).Once()
+				checker.On("CheckAvailability", mock.Anything, "", "newname").
+					Return(&domainUser.Availability{UsernameAvailable: boolPtr(true)}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"usernameAvailable":true}}`,
+		},
+		{
+			name:  "Checker Error",
+			query: "?email=new@example.com",
+			mockSetup: func(checker *MockAvailabilityChecker, verifier *MockCaptchaVerifier) {
+				checker.On("CheckAvailability", mock.Anything, "new@example.com", "").
+					Return(nil, errors.New("db error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := new(MockAvailabilityChecker)
+			verifier := new(MockCaptchaVerifier)
+			tc.mockSetup(checker, verifier)
+
+			var handler *AvailabilityHandler
+			if tc.withCaptcha {
+				handler = NewAvailabilityHandler(checker, verifier, zaptest.NewLogger(t))
+			} else {
+				handler = NewAvailabilityHandler(checker, nil, zaptest.NewLogger(t))
+			}
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.GET("/users/availability", handler.CheckAvailability)
+
+			req, _ := http.NewRequest(http.MethodGet, "/users/availability"+tc.query, nil)
+			if tc.captchaToken != "" {
+				req.Header.Set(CaptchaHeader, tc.captchaToken)
+			}
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			checker.AssertExpectations(t)
+			verifier.AssertExpectations(t)
+		})
+	}
+}
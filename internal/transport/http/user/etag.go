@@ -0,0 +1,70 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	realServiceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+// ErrIfMatchRequired rejects updates that do not say which version of the user they change
+var ErrIfMatchRequired = apperror.New(apperror.CodePreconditionRequired, "If-Match header with the ETag of the user is required")
+
+// userETag returns the entity tag of the current version of u
+func userETag(u *domainUser.User) string {
+	return `"` + u.Version() + `"`
+}
+
+// parseETags splits the value of an If-Match or If-None-Match header into
+// its entity tags, keeping the W/ prefix of weak tags
+func parseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// writeETag sets the ETag of u and reports whether the request's
+// If-None-Match already names it, in which case the response is a body-less
+// 304 Not Modified. Tags are compared weakly, as If-None-Match requires.
+func writeETag(c *gin.Context, u *domainUser.User) bool {
+	etag := userETag(u)
+	c.Header("ETag", etag)
+	for _, tag := range parseETags(c.GetHeader("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersions returns the user versions named by the request's If-Match
+// header; an empty list means any version, as If-Match: * does. Weak tags
+// never match, as If-Match requires.
+func ifMatchVersions(c *gin.Context) ([]string, *apperror.Error) {
+	tags := parseETags(c.GetHeader("If-Match"))
+	if len(tags) == 0 {
+		return nil, ErrIfMatchRequired
+	}
+	var versions []string
+	for _, tag := range tags {
+		if tag == "*" {
+			return nil, nil
+		}
+		if version, ok := strings.CutPrefix(tag, `"`); ok && strings.HasSuffix(version, `"`) {
+			versions = append(versions, strings.TrimSuffix(version, `"`))
+		}
+	}
+	if len(versions) == 0 {
+		return nil, realServiceUser.ErrVersionMismatch
+	}
+	return versions, nil
+}
@@ -0,0 +1,110 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
+	realServiceUser "github.com/yi-tech/go-user-service/internal/service/user"
+)
+
+func TestGetProfile_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+	current := createMockDomainUser(userID, "ada@example.com", "Ada", "Lovelace")
+	etag := userETag(current)
+
+	tests := []struct {
+		name           string
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{name: "Unconditional", expectedStatus: http.StatusOK},
+		{name: "Current Version", ifNoneMatch: etag, expectedStatus: http.StatusNotModified},
+		{name: "Weak Current Version", ifNoneMatch: `"other", W/` + etag, expectedStatus: http.StatusNotModified},
+		{name: "Any Version", ifNoneMatch: "*", expectedStatus: http.StatusNotModified},
+		{name: "Stale Version", ifNoneMatch: `"stale"`, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockUserService)
+			service.On("GetByID", mock.Anything, userID).Return(current, nil).Once()
+			handler := NewHandler(service, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+			router := gin.New()
+			router.GET("/profile", func(c *gin.Context) { requestctx.SetUserID(c, userID) }, handler.GetProfile)
+
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserETag(t *testing.T) {
+	stored := time.Date(2026, 10, 16, 8, 0, 0, 123456000, time.UTC)
+	user := &domainUser.User{ID: uuid.New(), UpdatedAt: stored}
+	etag := userETag(user)
+
+	// Postgres keeps microseconds, so finer differences are not new versions
+	finer := *user
+	finer.UpdatedAt = stored.Add(400 * time.Nanosecond)
+	assert.Equal(t, etag, userETag(&finer))
+
+	updated := *user
+	updated.UpdatedAt = stored.Add(time.Microsecond)
+	assert.NotEqual(t, etag, userETag(&updated))
+}
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		expected []string
+		err      error
+	}{
+		{name: "Missing", err: ErrIfMatchRequired},
+		{name: "Single", ifMatch: `"abc"`, expected: []string{"abc"}},
+		{name: "List", ifMatch: `"abc", "def"`, expected: []string{"abc", "def"}},
+		{name: "Any", ifMatch: "*"},
+		{name: "Weak Only", ifMatch: `W/"abc"`, err: realServiceUser.ErrVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/profile", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			versions, err := ifMatchVersions(c)
+
+			assert.Equal(t, tt.expected, versions)
+			if tt.err != nil {
+				assert.Equal(t, tt.err, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
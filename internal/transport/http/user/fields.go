@@ -0,0 +1,98 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yi-tech/go-user-service/internal/apperror"
+)
+
+// userFields maps the names the fields query parameter accepts to the JSON
+// names of UserResponse. Both the JSON name, e.g. firstName, and its
+// snake_case form, first_name, are accepted.
+var userFields = func() map[string]string {
+	names := []string{"_links"}
+	t := reflect.TypeOf(UserResponse{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	fields := make(map[string]string, 2*len(names))
+	for _, name := range names {
+		fields[name] = name
+		fields[snakeCase(name)] = name
+	}
+	return fields
+}()
+
+// snakeCase converts a camelCase name to snake_case
+func snakeCase(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			b.WriteByte('_')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseFields returns the JSON names of the user fields requested with the
+// fields query parameter, e.g. ?fields=id,email,first_name. Nil means every
+// field.
+func parseFields(c *gin.Context) ([]string, *apperror.Error) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		field, ok := userFields[strings.TrimSpace(name)]
+		if !ok {
+			return nil, apperror.New(apperror.CodeInvalidArgument, fmt.Sprintf("unknown field %q", strings.TrimSpace(name)))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// sparseUser is a UserResponse rendering only some of its fields
+type sparseUser struct {
+	UserResponse
+	fields []string
+}
+
+// userView renders user with only fields, or with every field when fields is nil
+func userView(user UserResponse, fields []string) any {
+	if fields == nil {
+		return user
+	}
+	return sparseUser{UserResponse: user, fields: fields}
+}
+
+// MarshalJSON renders the requested fields of the user
+func (u sparseUser) MarshalJSON() ([]byte, error) {
+	full, err := json.Marshal(u.UserResponse)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(full, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]json.RawMessage, len(u.fields))
+	for _, field := range u.fields {
+		if value, ok := all[field]; ok { // Empty optional fields stay omitted
+			selected[field] = value
+		}
+	}
+	return json.Marshal(selected)
+}
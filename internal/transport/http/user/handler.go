@@ -1,40 +1,46 @@
 package user
 
 import (
-	"errors" // Added for errors.Is
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/yi-tech/go-user-service/internal/apperror"
+	"github.com/yi-tech/go-user-service/internal/clientip"
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/requestctx"
 	realServiceUser "github.com/yi-tech/go-user-service/internal/service/user" // Renamed to avoid conflict with package name 'user'
 	"github.com/yi-tech/go-user-service/internal/transport/http/response"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
 	"go.uber.org/zap"
 )
 
 // Handler handles HTTP requests for user operations
 type Handler struct {
 	userService realServiceUser.UserService // Use the new alias
+	ids         idgen.Strategy              // Text form of rendered IDs
 	logger      *zap.Logger
 }
 
 // NewHandler creates a new user handler
-func NewHandler(userService realServiceUser.UserService, logger *zap.Logger) *Handler {
+func NewHandler(userService realServiceUser.UserService, ids idgen.Strategy, logger *zap.Logger) *Handler {
 	return &Handler{
 		userService: userService,
+		ids:         ids,
 		logger:      logger,
 	}
 }
 
 // Register handles user registration
 // @Summary Register a new user
-// @Description Register a new user with the provided information
+// @Description Register a new user with the provided information. When registration CAPTCHA is enabled, a CAPTCHA token is required in the X-Captcha-Token header.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body UserRegisterRequest true "User registration information"
+// @Param X-Captcha-Token header string false "CAPTCHA token, required when registration CAPTCHA is enabled"
 // @Success 201 {object} response.Response{data=UserResponse} "User registered successfully"
 // @Failure 400 {object} response.Response "Invalid request data"
+// @Failure 403 {object} response.Response "CAPTCHA verification failed, or the email address is not allowed to sign up (errorCode EMAIL_POLICY_VIOLATION)"
 // @Failure 409 {object} response.Response "Email already exists"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /users/register [post]
@@ -48,32 +54,27 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Populate RegisterUserInput from the request
-	userInput := domainUser.RegisterUserInput{
-		Email:     req.Email,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-	}
-
-	// Call domain service with the new input struct
-	newUser, err := h.userService.Register(c.Request.Context(), userInput)
+	// Call domain service with the input converted from the request
+	input := req.ToDomain()
+	input.CaptchaToken = c.GetHeader(CaptchaHeader)
+	input.ClientIP = clientip.FromRequest(c.Request)
+	newUser, err := h.userService.Register(c.Request.Context(), input)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserAlreadyExists) {
-			// No need to log ErrUserAlreadyExists as error, it's a known business logic case
-			response.Conflict(c, realServiceUser.ErrUserAlreadyExists.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		h.logger.Error("Failed to register user",
 			zap.String("operation", "Register"),
 			zap.Error(err),
 			zap.String("email", req.Email)) // Add email for context
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
 	// Use the response package with status code 201 (Created)
-	c.JSON(http.StatusCreated, response.NewResponse(http.StatusCreated, "User registered successfully", toUserResponse(newUser)))
+	response.Created(c, "User registered successfully", UserResponse(mapper.ToUserResponse(newUser, h.ids)))
 }
 
 // GetUserByID handles retrieving a user by ID
@@ -83,8 +84,11 @@ func (h *Handler) Register(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag of a previously read version; 304 when unchanged"
+// @Param fields query string false "Comma-separated user fields to return, e.g. id,email,first_name; all when omitted"
 // @Success 200 {object} response.Response{data=UserResponse} "User information"
-// @Failure 400 {object} response.Response "Invalid user ID format"
+// @Success 304 "User unchanged"
+// @Failure 400 {object} response.Response "Invalid user ID format or unknown field"
 // @Failure 404 {object} response.Response "User not found"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /users/{id} [get]
@@ -92,16 +96,21 @@ func (h *Handler) GetUserByID(c *gin.Context) {
 	idParam := c.Param("id")
 
 	// Convert string ID to UUID
-	userUUID, err := uuid.Parse(idParam)
+	userUUID, err := idgen.Parse(idParam)
 	if err != nil {
 		response.BadRequest(c, "Invalid user ID format")
 		return
 	}
+	fields, appErr := parseFields(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
+		return
+	}
 
 	user, err := h.userService.GetByID(c.Request.Context(), userUUID)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -109,11 +118,76 @@ func (h *Handler) GetUserByID(c *gin.Context) {
 			zap.String("operation", "GetUserByID"),
 			zap.Error(err),
 			zap.String("user_id", idParam))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	response.Success(c, toUserResponse(user))
+	if writeETag(c, user) {
+		return
+	}
+	response.Success(c, userView(UserResponse(mapper.ToUserResponse(user, h.ids)), fields))
+}
+
+// BatchGetUsers handles retrieving several users by ID
+// @Summary Get users by ID
+// @Description Retrieve up to 100 users in one request, in the order of their IDs. Repeated IDs are returned once; IDs no user has are listed in missingIds instead of failing the request.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body BatchGetUsersRequest true "User IDs"
+// @Success 200 {object} response.Response{data=BatchGetUsersResponse} "Users found and IDs not found"
+// @Failure 400 {object} response.Response "Invalid request data, user ID format or too many IDs"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/batch-get [post]
+func (h *Handler) BatchGetUsers(c *gin.Context) {
+	var req BatchGetUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Invalid batch get request",
+			zap.String("operation", "BatchGetUsers"),
+			zap.Error(err))
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := idgen.Parse(raw)
+		if err != nil {
+			response.BadRequest(c, "Invalid user ID format")
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	users, err := h.userService.GetByIDs(c.Request.Context(), ids)
+	if err != nil {
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		h.logger.Error("Failed to get users by ID",
+			zap.String("operation", "BatchGetUsers"),
+			zap.Error(err),
+			zap.Int("count", len(ids)))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	data := BatchGetUsersResponse{Users: make([]UserResponse, 0, len(users)), MissingIDs: []string{}}
+	found := make(map[uuid.UUID]bool, len(users))
+	for _, user := range users {
+		data.Users = append(data.Users, UserResponse(mapper.ToUserResponse(user, h.ids)))
+		found[user.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			found[id] = true // Report repeated missing IDs once
+			data.MissingIDs = append(data.MissingIDs, h.ids.Format(id))
+		}
+	}
+	response.Success(c, data)
 }
 
 // GetUserByEmail handles retrieving a user by email
@@ -123,8 +197,9 @@ func (h *Handler) GetUserByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param email query string true "User email"
+// @Param fields query string false "Comma-separated user fields to return, e.g. id,email,first_name; all when omitted"
 // @Success 200 {object} response.Response{data=UserResponse} "User information"
-// @Failure 400 {object} response.Response "Email is required"
+// @Failure 400 {object} response.Response "Email is required or unknown field"
 // @Failure 404 {object} response.Response "User not found"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /users [get]
@@ -134,11 +209,16 @@ func (h *Handler) GetUserByEmail(c *gin.Context) {
 		response.BadRequest(c, "Email is required")
 		return
 	}
+	fields, appErr := parseFields(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
+		return
+	}
 
 	user, err := h.userService.GetByEmail(c.Request.Context(), email)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -146,11 +226,12 @@ func (h *Handler) GetUserByEmail(c *gin.Context) {
 			zap.String("operation", "GetUserByEmail"),
 			zap.Error(err),
 			zap.String("email", email))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	response.Success(c, toUserResponse(user))
+	response.Success(c, userView(UserResponse(mapper.ToUserResponse(user, h.ids)), fields))
 }
 
 // UpdateProfile handles updating a user's profile
@@ -161,16 +242,19 @@ func (h *Handler) GetUserByEmail(c *gin.Context) {
 // @Produce json
 // @Param id path string true "User ID"
 // @Param request body UserUpdateRequest true "User update information"
+// @Param If-Match header string true "ETag of the version being updated, or *"
 // @Success 200 {object} response.Response{data=UserResponse} "User updated successfully"
 // @Failure 400 {object} response.Response "Invalid request data or user ID format"
 // @Failure 404 {object} response.Response "User not found"
+// @Failure 412 {object} response.Response "User modified since it was read"
+// @Failure 428 {object} response.Response "If-Match header missing"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /users/{id} [put]
 func (h *Handler) UpdateProfile(c *gin.Context) {
 	idParam := c.Param("id")
 
 	// Convert string ID to UUID
-	userUUID, err := uuid.Parse(idParam)
+	userUUID, err := idgen.Parse(idParam)
 	if err != nil {
 		response.BadRequest(c, "Invalid user ID format")
 		return
@@ -186,11 +270,17 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	versions, appErr := ifMatchVersions(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
+		return
+	}
+
 	// Get current user data
 	_, err = h.userService.GetByID(c.Request.Context(), userUUID) // Check if user exists before update
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -198,34 +288,26 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 			zap.String("operation", "UpdateProfile"),
 			zap.Error(err),
 			zap.String("user_id", idParam))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	// Apply updates (only if provided)
-	updates := domainUser.UpdateUserParams{}
-
-	if req.FirstName != nil {
-		updates.FirstName = *req.FirstName
-	}
-
-	if req.LastName != nil {
-		updates.LastName = *req.LastName
-	}
-
-	if req.Email != nil {
-		updates.Email = *req.Email
+	// Apply updates (only if provided; empty strings clear the field)
+	updates := domainUser.UpdateUserParams{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Locale:      req.Locale,
+		Versions:    versions,
 	}
 
 	// Update user
 	updatedUser, err := h.userService.Update(c.Request.Context(), userUUID, updates)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) { // Should ideally not happen if GetByID above passed
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
-			return
-		}
-		if errors.Is(err, realServiceUser.ErrEmailInUse) {
-			response.Conflict(c, realServiceUser.ErrEmailInUse.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -233,19 +315,75 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 			zap.String("operation", "UpdateProfile"),
 			zap.Error(err),
 			zap.String("user_id", idParam))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
 	// Return updated user data
-	response.Success(c, UserResponse{
-		ID:        updatedUser.ID.String(),
-		Email:     updatedUser.Email,
-		FirstName: updatedUser.FirstName,
-		LastName:  updatedUser.LastName,
-		CreatedAt: updatedUser.CreatedAt,
-		UpdatedAt: updatedUser.UpdatedAt,
-	})
+	c.Header("ETag", userETag(updatedUser))
+	response.Success(c, UserResponse(mapper.ToUserResponse(updatedUser, h.ids)))
+}
+
+// UpdateMetadata handles partially updating the custom attributes of a user
+// @Summary Update user metadata
+// @Description Merge attributes into a user's metadata: string values are set, null values removed and attributes not listed left unchanged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body MetadataPatchRequest true "Attributes to set, or null to remove"
+// @Param If-Match header string true "ETag of the version being updated, or *"
+// @Success 200 {object} response.Response{data=UserResponse} "User updated successfully"
+// @Failure 400 {object} response.Response "Invalid request data, user ID format or metadata"
+// @Failure 403 {object} response.Response "Not the caller's account and the caller is not an administrator"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 412 {object} response.Response "User modified since it was read"
+// @Failure 428 {object} response.Response "If-Match header missing"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /users/{id}/metadata [patch]
+func (h *Handler) UpdateMetadata(c *gin.Context) {
+	idParam := c.Param("id")
+
+	userUUID, err := idgen.Parse(idParam)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID format")
+		return
+	}
+
+	var req MetadataPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req) == 0 {
+		h.logger.Warn("Invalid update metadata request",
+			zap.String("operation", "UpdateMetadata"),
+			zap.Error(err),
+			zap.String("user_id", idParam))
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	versions, appErr := ifMatchVersions(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
+		return
+	}
+
+	updatedUser, err := h.userService.Update(c.Request.Context(), userUUID, domainUser.UpdateUserParams{Metadata: req, Versions: versions})
+	if err != nil {
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		h.logger.Error("Failed to update user metadata",
+			zap.String("operation", "UpdateMetadata"),
+			zap.Error(err),
+			zap.String("user_id", idParam))
+		_ = c.Error(err)
+		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		return
+	}
+
+	c.Header("ETag", userETag(updatedUser))
+	response.Success(c, UserResponse(mapper.ToUserResponse(updatedUser, h.ids)))
 }
 
 // UpdatePassword handles updating a user's password
@@ -266,7 +404,7 @@ func (h *Handler) UpdatePassword(c *gin.Context) {
 	idParam := c.Param("id")
 
 	// Convert string ID to UUID
-	userUUID, err := uuid.Parse(idParam)
+	userUUID, err := idgen.Parse(idParam)
 	if err != nil {
 		response.BadRequest(c, "Invalid user ID format")
 		return
@@ -285,12 +423,8 @@ func (h *Handler) UpdatePassword(c *gin.Context) {
 	// Update password
 	err = h.userService.UpdatePassword(c.Request.Context(), userUUID, req.CurrentPassword, req.NewPassword)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
-			return
-		}
-		if errors.Is(err, realServiceUser.ErrIncorrectPassword) {
-			response.Unauthorized(c, realServiceUser.ErrIncorrectPassword.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -298,6 +432,7 @@ func (h *Handler) UpdatePassword(c *gin.Context) {
 			zap.String("operation", "UpdatePassword"),
 			zap.Error(err),
 			zap.String("user_id", idParam))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
@@ -322,7 +457,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	idParam := c.Param("id")
 
 	// Convert string ID to UUID
-	userUUID, err := uuid.Parse(idParam)
+	userUUID, err := idgen.Parse(idParam)
 	if err != nil {
 		response.BadRequest(c, "Invalid user ID format")
 		return
@@ -331,8 +466,8 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	// Delete user
 	err = h.userService.DeleteUser(c.Request.Context(), userUUID)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -340,6 +475,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 			zap.String("operation", "DeleteUser"),
 			zap.Error(err),
 			zap.String("user_id", idParam))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
@@ -347,53 +483,38 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	response.Success(c, gin.H{"message": "User deleted successfully"})
 }
 
-// Helper function to convert domain user to response DTO
-func toUserResponse(user *domainUser.User) UserResponse {
-	return UserResponse{
-		ID:        user.ID.String(),
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}
-}
-
 // GetProfile handles retrieving the current user's profile
 // @Summary Get current user profile
 // @Description Retrieve the current user's profile information
 // @Tags profile
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag of a previously read version; 304 when unchanged"
+// @Param fields query string false "Comma-separated user fields to return, e.g. id,email,first_name; all when omitted"
 // @Success 200 {object} response.Response{data=UserResponse} "User profile information"
+// @Success 304 "Profile unchanged"
+// @Failure 400 {object} response.Response "Unknown field"
 // @Failure 401 {object} response.Response "Unauthorized"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /profile [get]
 func (h *Handler) GetProfile(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
-	if !exists {
+	userUUID, ok := requestctx.UserID(c.Request.Context())
+	if !ok {
 		response.Unauthorized(c, "User not authenticated")
 		return
 	}
-
-	// Convert to UUID
-	userUUID, ok := userID.(uuid.UUID)
-	if !ok {
-		h.logger.Error("Failed to convert userID to UUID",
-			zap.String("operation", "GetProfile"),
-			zap.Any("userID_type", userID), // Log the type of userID
-			zap.Any("userID_value", userID)) // Log the value of userID
-		response.InternalServerError(c, "Something went wrong. Please try again later.")
+	fields, appErr := parseFields(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
 		return
 	}
 
 	// Get user data
 	user, err := h.userService.GetByID(c.Request.Context(), userUUID)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) {
-			// No need to log ErrUserNotFound as error, it's a known case for GetProfile
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error()) // User from token not found
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		// Log the actual error for debugging but return a generic message
@@ -401,11 +522,15 @@ func (h *Handler) GetProfile(c *gin.Context) {
 			zap.String("operation", "GetProfile"),
 			zap.Error(err),
 			zap.String("user_id", userUUID.String()))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	response.Success(c, toUserResponse(user))
+	if writeETag(c, user) {
+		return
+	}
+	response.Success(c, userView(UserResponse(mapper.ToUserResponse(user, h.ids)), fields))
 }
 
 // UpdateCurrentUserProfile handles updating the currently authenticated user's profile
@@ -415,27 +540,19 @@ func (h *Handler) GetProfile(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body UpdateCurrentUserProfileRequest true "User profile update information"
+// @Param If-Match header string true "ETag of the version being updated, or *"
 // @Success 200 {object} response.Response{data=UserResponse} "Profile updated successfully"
 // @Failure 400 {object} response.Response "Invalid request data"
 // @Failure 401 {object} response.Response "User not authenticated"
+// @Failure 412 {object} response.Response "Profile modified since it was read"
+// @Failure 428 {object} response.Response "If-Match header missing"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /profile [put]
 func (h *Handler) UpdateCurrentUserProfile(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userIDRaw, exists := c.Get("userID")
-	if !exists {
-		response.Unauthorized(c, "User not authenticated")
-		return
-	}
-
-	// Convert to UUID
-	userUUID, ok := userIDRaw.(uuid.UUID)
+	userUUID, ok := requestctx.UserID(c.Request.Context())
 	if !ok {
-		h.logger.Error("Failed to convert userID to UUID from context for profile update",
-			zap.String("operation", "UpdateCurrentUserProfile"),
-			zap.Any("userID_type", userIDRaw),
-			zap.Any("userID_value", userIDRaw))
-		response.InternalServerError(c, "Something went wrong. Please try again later.")
+		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
@@ -449,35 +566,37 @@ func (h *Handler) UpdateCurrentUserProfile(c *gin.Context) {
 		return
 	}
 
-	updates := domainUser.UpdateUserParams{}
-	if req.FirstName != nil {
-		updates.FirstName = *req.FirstName
-	}
-	if req.LastName != nil {
-		updates.LastName = *req.LastName
+	versions, appErr := ifMatchVersions(c)
+	if appErr != nil {
+		response.AppError(c, appErr)
+		return
 	}
-	if req.Email != nil {
-		updates.Email = *req.Email
+
+	updates := domainUser.UpdateUserParams{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Locale:      req.Locale,
+		Versions:    versions,
 	}
 
 	// Call the existing Update method in the service
 	updatedUser, err := h.userService.Update(c.Request.Context(), userUUID, updates)
 	if err != nil {
-		if errors.Is(err, realServiceUser.ErrUserNotFound) { // Should not happen if userID from token is valid and user exists
-			response.NotFound(c, realServiceUser.ErrUserNotFound.Error())
-			return
-		}
-		if errors.Is(err, realServiceUser.ErrEmailInUse) {
-			response.Conflict(c, realServiceUser.ErrEmailInUse.Error())
+		if appErr, ok := apperror.As(err); ok {
+			response.AppError(c, appErr)
 			return
 		}
 		h.logger.Error("Failed to update current user profile",
 			zap.String("operation", "UpdateCurrentUserProfile"),
 			zap.Error(err),
 			zap.String("user_id", userUUID.String()))
+		_ = c.Error(err)
 		response.InternalServerError(c, "Something went wrong. Please try again later.")
 		return
 	}
 
-	response.Success(c, toUserResponse(updatedUser))
+	c.Header("ETag", userETag(updatedUser))
+	response.Success(c, UserResponse(mapper.ToUserResponse(updatedUser, h.ids)))
 }
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+func FuzzRegisterBinding(f *testing.F) {
+	gin.SetMode(gin.TestMode)
+
+	f.Add(`{"email":"test@example.com","password":"password123","firstName":"Test","lastName":"User"}`)
+	f.Add(`{"email":"test@example.com","password":"password123","firstName":"Test","lastName":"User","residency":"EU"}`)
+	f.Add(`{"email":"test@example.com","password":"password123","firstName":"Test","lastName":"User","residency":"E-U"}`)
+	f.Add(`{"email":"test@example.com","password":"short","firstName":"Test","lastName":"User"}`)
+	f.Add(`{"email":"test@example.com","password":"ÄÄÄÄ","firstName":"Test","lastName":"User"}`)
+	f.Add(`{"email":"a@b","password":"password123","firstName":"","lastName":"User"}`)
+	f.Add(`{"email":1,"password":true,"firstName":null,"lastName":[]}`)
+	f.Add(`{"email":"test@example.com","email":"other@example.com"}`)
+	f.Add(`[{"email":"test@example.com"}]`)
+	f.Add(`{"email":"test@example.com"`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		mockService := new(MockUserService)
+		mockService.On("Register", mock.Anything, mock.Anything).Return(
+			createMockDomainUser(uuid.New(), "test@example.com", "Test", "User"), nil)
+		handler := NewHandler(mockService, idgen.StrategyUUIDv4, zap.NewNop())
+
+		rr := httptest.NewRecorder()
+		_, router := gin.CreateTestContext(rr)
+		router.POST("/users/register", handler.Register)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, req)
+
+		require.True(t, json.Valid(rr.Body.Bytes()), "response is not JSON: %q", rr.Body.String())
+		require.Contains(t, []int{http.StatusCreated, http.StatusBadRequest}, rr.Code)
+		if rr.Code != http.StatusCreated {
+			mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+			return
+		}
+
+		// Whatever reached the service satisfied the binding rules
+		input := mockService.Calls[0].Arguments.Get(1).(domainUser.RegisterUserInput)
+		require.NotEmpty(t, input.Email)
+		require.GreaterOrEqual(t, utf8.RuneCountInString(input.Password), 8)
+		require.NotEmpty(t, input.FirstName)
+		require.NotEmpty(t, input.LastName)
+		require.LessOrEqual(t, utf8.RuneCountInString(input.Residency), 16)
+	})
+}
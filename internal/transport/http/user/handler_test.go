@@ -19,6 +19,8 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+	"github.com/yi-tech/go-user-service/internal/service/captcha"
 	realServiceUser "github.com/yi-tech/go-user-service/internal/service/user" // Import for sentinel errors
 )
 
@@ -40,6 +42,19 @@ func (m *MockUserService) Register(ctx context.Context, input domainUser.Registe
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserService) PrepareUser(ctx context.Context, input domainUser.RegisterUserInput) (*domainUser.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domainUser.User), args.Error(1)
+}
+
+func (m *MockUserService) CreateUsers(ctx context.Context, users []*domainUser.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUser.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -48,6 +63,14 @@ func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*domainUse
 	return args.Get(0).(*domainUser.User), args.Error(1)
 }
 
+func (m *MockUserService) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*domainUser.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domainUser.User), args.Error(1)
+}
+
 func (m *MockUserService) GetByEmail(ctx context.Context, email string) (*domainUser.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -69,6 +92,11 @@ func (m *MockUserService) UpdatePassword(ctx context.Context, id uuid.UUID, curr
 	return args.Error(0)
 }
 
+func (m *MockUserService) RehashPassword(ctx context.Context, user *domainUser.User, password string) error {
+	args := m.Called(ctx, user, password)
+	return args.Error(0)
+}
+
 func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -90,11 +118,35 @@ func createMockDomainUser(id uuid.UUID, email, firstName, lastName string) *doma
 
 func TestNewUserHandler(t *testing.T) {
 	service := new(MockUserService)
-	handler := NewHandler(service, zaptest.NewLogger(t))
+	handler := NewHandler(service, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
 	assert.NotNil(t, handler)
 	assert.Equal(t, service, handler.userService)
 }
 
+func TestRegister_Captcha(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserService)
+	handler := NewHandler(mockService, idgen.StrategyUUIDv4, zaptest.NewLogger(t))
+	router := gin.New()
+	router.POST("/users/register", handler.Register)
+
+	mockService.On("Register", mock.Anything, mock.MatchedBy(func(input domainUser.RegisterUserInput) bool {
+		return input.CaptchaToken == "robot" && input.ClientIP == "203.0.113.7"
+	})).Return(nil, captcha.ErrCaptchaFailed).Once()
+
+	body := `{"email":"bot@example.com","password":"password123","firstName":"Bot","lastName":"User"}`
+	req := httptest.NewRequest(http.MethodPost, "/users/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CaptchaHeader, "robot")
+	req.RemoteAddr = "203.0.113.7:4711"
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.JSONEq(t, `{"code":403,"message":"captcha verification failed","errorCode":"CAPTCHA_FAILED"}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
 func TestUpdateUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	logger := zaptest.NewLogger(t)
@@ -102,6 +154,7 @@ func TestUpdateUser(t *testing.T) {
 
 	baseUser := createMockDomainUser(mockUserUUID, "original@example.com", "OriginalFirst", "OriginalLast")
 
+	fixedTime := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
 	updatedFirstName := "UpdatedFirst"
 	updatedLastName := "UpdatedLast"
 
@@ -122,6 +175,7 @@ func TestUpdateUser(t *testing.T) {
 		name           string
 		userIDParam    string
 		requestBody    interface{}
+		ifMatch        string
 		setupMock      func(mockService *MockUserService)
 		expectedStatus int
 		expectedBody   string
@@ -134,12 +188,14 @@ func TestUpdateUser(t *testing.T) {
 				FirstName: &updatedFirstName,
 				LastName:  &updatedLastName,
 			},
+			ifMatch: userETag(baseUser),
 			setupMock: func(mockService *MockUserService) {
 				// Mock GetByID to return the user
 				mockService.On("GetByID", mock.Anything, mockUserUUID).Return(baseUser, nil).Once()
 				// Mock Update to return the updated user
 				mockService.On("Update", mock.Anything, mockUserUUID, mock.MatchedBy(func(params domainUser.UpdateUserParams) bool {
-					return params.FirstName == updatedFirstName && params.LastName == updatedLastName
+					return *params.FirstName == updatedFirstName && *params.LastName == updatedLastName &&
+						assert.ObjectsAreEqual([]string{baseUser.Version()}, params.Versions)
 				})).Return(successUserForMockReturn, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
@@ -150,6 +206,7 @@ func TestUpdateUser(t *testing.T) {
 			name:           "Invalid User ID Format",
 			userIDParam:    "not-a-uuid",
 			requestBody:    UserUpdateRequest{FirstName: stringPtr("Test"), LastName: stringPtr("User")},
+			ifMatch:        userETag(baseUser),
 			setupMock:      func(mockService *MockUserService) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `{"code":400,"message":"Invalid user ID format"}`,
@@ -158,10 +215,47 @@ func TestUpdateUser(t *testing.T) {
 			name:           "Invalid Request Data - Malformed JSON",
 			userIDParam:    mockUserUUID.String(),
 			requestBody:    "not json",
+			ifMatch:        userETag(baseUser),
 			setupMock:      func(mockService *MockUserService) {},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
 		},
+		{
+			name:        "Last Name Cleared",
+			userIDParam: mockUserUUID.String(),
+			requestBody: `{"lastName":""}`,
+			ifMatch:     "*",
+			setupMock: func(mockService *MockUserService) {
+				cleared := &domainUser.User{ID: mockUserUUID, Email: "original@example.com", FirstName: "OriginalFirst", CreatedAt: fixedTime, UpdatedAt: fixedTime}
+				mockService.On("GetByID", mock.Anything, mockUserUUID).Return(baseUser, nil).Once()
+				mockService.On("Update", mock.Anything, mockUserUUID, domainUser.UpdateUserParams{LastName: stringPtr("")}).Return(cleared, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"code":200,"message":"Success","data":{"id":"` + mockUserUUID.String() + `","email":"original@example.com","firstName":"OriginalFirst",
+				"createdAt":"2025-06-01T08:00:00Z","updatedAt":"2025-06-01T08:00:00Z","_links":{"self":{"href":"/api/v1/users/` + mockUserUUID.String() + `"},"password":{"href":"/api/v1/users/` + mockUserUUID.String() + `/password"}}}}`,
+		},
+		{
+			name:           "Missing If-Match",
+			userIDParam:    mockUserUUID.String(),
+			requestBody:    UserUpdateRequest{FirstName: stringPtr("Test"), LastName: stringPtr("User")},
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   `{"code":428,"message":"If-Match header with the ETag of the user is required","errorCode":"PRECONDITION_REQUIRED"}`,
+		},
+		{
+			name:        "Stale If-Match",
+			userIDParam: mockUserUUID.String(),
+			requestBody: UserUpdateRequest{FirstName: stringPtr("Test"), LastName: stringPtr("User")},
+			ifMatch:     `"stale"`,
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByID", mock.Anything, mockUserUUID).Return(baseUser, nil).Once()
+				mockService.On("Update", mock.Anything, mockUserUUID, mock.MatchedBy(func(params domainUser.UpdateUserParams) bool {
+					return assert.ObjectsAreEqual([]string{"stale"}, params.Versions)
+				})).Return(nil, realServiceUser.ErrVersionMismatch).Once()
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"code":412,"message":"user has been modified since it was read","errorCode":"VERSION_MISMATCH"}`,
+		},
 		// {
 		// 	name:        "Invalid Request Data - Missing FirstName",
 		// 	userIDParam: mockUserUUID.String(),
@@ -190,6 +284,7 @@ func TestUpdateUser(t *testing.T) {
 			name:        "User Not Found",
 			userIDParam: "00000000-0000-0000-0000-000000000001", // Fixed UUID for consistent testing
 			requestBody: UserUpdateRequest{FirstName: stringPtr("Test"), LastName: stringPtr("User")},
+			ifMatch:     "*",
 			setupMock: func(mockService *MockUserService) {
 				// Use the same UUID as in userIDParam
 				userUUID, err := uuid.Parse("00000000-0000-0000-0000-000000000001")
@@ -198,17 +293,18 @@ func TestUpdateUser(t *testing.T) {
 				// Update should not be called, so no mock for Update in this specific path.
 			},
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"code":404,"message":"user not found"}`, // Message from realServiceUser.ErrUserNotFound.Error()
+			expectedBody:   `{"code":404,"message":"user not found","errorCode":"USER_NOT_FOUND"}`, // Message from realServiceUser.ErrUserNotFound.Error()
 		},
 		{
 			name:        "Internal Server Error",
 			userIDParam: mockUserUUID.String(),
 			requestBody: UserUpdateRequest{FirstName: stringPtr("Test"), LastName: stringPtr("User")},
+			ifMatch:     userETag(baseUser),
 			setupMock: func(mockService *MockUserService) {
 				errUser := createMockDomainUser(mockUserUUID, "test@example.com", "Test", "User")
 				mockService.On("GetByID", mock.Anything, mockUserUUID).Return(errUser, nil).Once()
 				mockService.On("Update", mock.Anything, mockUserUUID, mock.MatchedBy(func(params domainUser.UpdateUserParams) bool {
-					return params.FirstName == "Test" && params.LastName == "User"
+					return *params.FirstName == "Test" && *params.LastName == "User"
 				})).Return(nil, errors.New("internal error")).Once()
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -221,7 +317,7 @@ func TestUpdateUser(t *testing.T) {
 			mockService := new(MockUserService)
 			tc.setupMock(mockService)
 
-			handler := NewHandler(mockService, logger)
+			handler := NewHandler(mockService, idgen.StrategyUUIDv4, logger)
 
 			rr := httptest.NewRecorder()
 			_, router := gin.CreateTestContext(rr)
@@ -242,6 +338,9 @@ func TestUpdateUser(t *testing.T) {
 			assert.NoError(t, err)
 
 			req.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
 
 			router.ServeHTTP(rr, req)
 
@@ -254,6 +353,7 @@ func TestUpdateUser(t *testing.T) {
 				assert.NoError(t, err)
 
 				// Check the response structure
+				assert.Equal(t, userETag(successUserForMockReturn), rr.Header().Get("ETag"))
 				assert.Equal(t, float64(http.StatusOK), responseBody["code"])
 				assert.Equal(t, "Success", responseBody["message"])
 
@@ -286,3 +386,232 @@ func TestUpdateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchGetUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	created := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	ada := &domainUser.User{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000a"), Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace", CreatedAt: created, UpdatedAt: created}
+	missing := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(mockService *MockUserService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			body: `{"ids":["` + ada.ID.String() + `","` + missing.String() + `","` + missing.String() + `"]}`,
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByIDs", mock.Anything, []uuid.UUID{ada.ID, missing, missing}).Return([]*domainUser.User{ada}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"code":200,"message":"Success","data":{"users":[{"id":"` + ada.ID.String() + `","email":"ada@example.com","firstName":"Ada","lastName":"Lovelace",
+				"createdAt":"2025-06-01T08:00:00Z","updatedAt":"2025-06-01T08:00:00Z","_links":{"self":{"href":"/api/v1/users/` + ada.ID.String() + `"},"password":{"href":"/api/v1/users/` + ada.ID.String() + `/password"}}}],
+				"missingIds":["` + missing.String() + `"]}}`,
+		},
+		{
+			name:           "Missing IDs",
+			body:           `{}`,
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:           "Invalid User ID Format",
+			body:           `{"ids":["not-a-uuid"]}`,
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid user ID format"}`,
+		},
+		{
+			name: "Too Many IDs",
+			body: `{"ids":["` + ada.ID.String() + `"]}`,
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, realServiceUser.ErrTooManyIDs).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"at most 100 user IDs can be looked up at once","errorCode":"INVALID_ARGUMENT"}`,
+		},
+		{
+			name: "Internal Server Error",
+			body: `{"ids":["` + ada.ID.String() + `"]}`,
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"code":500,"message":"Something went wrong. Please try again later."}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tc.setupMock(mockService)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.POST("/users/batch-get", NewHandler(mockService, idgen.StrategyUUIDv4, zaptest.NewLogger(t)).BatchGetUsers)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/batch-get", strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserByIDFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ada := createMockDomainUser(uuid.MustParse("00000000-0000-0000-0000-00000000000a"), "ada@example.com", "Ada", "Lovelace")
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(mockService *MockUserService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Selected Fields Only",
+			query: "?fields=id,email,first_name",
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByID", mock.Anything, ada.ID).Return(ada, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"id":"` + ada.ID.String() + `","email":"ada@example.com","firstName":"Ada"}}`,
+		},
+		{
+			name:  "Links",
+			query: "?fields=_links",
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("GetByID", mock.Anything, ada.ID).Return(ada, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"code":200,"message":"Success","data":{"_links":{"self":{"href":"/api/v1/users/` + ada.ID.String() + `"},"password":{"href":"/api/v1/users/` + ada.ID.String() + `/password"}}}}`,
+		},
+		{
+			name:           "Unknown Field",
+			query:          "?fields=id,password",
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"unknown field \"password\"","errorCode":"INVALID_ARGUMENT"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tc.setupMock(mockService)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.GET("/users/:id", NewHandler(mockService, idgen.StrategyUUIDv4, zaptest.NewLogger(t)).GetUserByID)
+
+			req, err := http.NewRequest(http.MethodGet, "/users/"+ada.ID.String()+tc.query, nil)
+			assert.NoError(t, err)
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	created := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	ada := &domainUser.User{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000a"), Email: "ada@example.com", Metadata: map[string]string{"plan": "pro"}, CreatedAt: created, UpdatedAt: created}
+
+	tests := []struct {
+		name           string
+		body           string
+		ifMatch        string
+		setupMock      func(mockService *MockUserService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:    "Success",
+			body:    `{"plan":"pro","team":null}`,
+			ifMatch: "*",
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("Update", mock.Anything, ada.ID, domainUser.UpdateUserParams{Metadata: map[string]*string{"plan": stringPtr("pro"), "team": nil}}).Return(ada, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"code":200,"message":"Success","data":{"id":"` + ada.ID.String() + `","email":"ada@example.com","metadata":{"plan":"pro"},
+				"createdAt":"2025-06-01T08:00:00Z","updatedAt":"2025-06-01T08:00:00Z","_links":{"self":{"href":"/api/v1/users/` + ada.ID.String() + `"},"password":{"href":"/api/v1/users/` + ada.ID.String() + `/password"}}}}`,
+		},
+		{
+			name:    "Conditional",
+			body:    `{"plan":"pro"}`,
+			ifMatch: `"v1"`,
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("Update", mock.Anything, ada.ID, domainUser.UpdateUserParams{Metadata: map[string]*string{"plan": stringPtr("pro")}, Versions: []string{"v1"}}).
+					Return(nil, realServiceUser.ErrVersionMismatch).Once()
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   `{"code":412,"message":"user has been modified since it was read","errorCode":"VERSION_MISMATCH"}`,
+		},
+		{
+			name:           "Missing If-Match",
+			body:           `{"plan":"pro"}`,
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   `{"code":428,"message":"If-Match header with the ETag of the user is required","errorCode":"PRECONDITION_REQUIRED"}`,
+		},
+		{
+			name:           "Empty Patch",
+			body:           `{}`,
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:           "Non-String Value",
+			body:           `{"seats":5}`,
+			setupMock:      func(mockService *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"Invalid request data"}`,
+		},
+		{
+			name:    "Invalid Metadata",
+			body:    `{"not a key":"v"}`,
+			ifMatch: "*",
+			setupMock: func(mockService *MockUserService) {
+				mockService.On("Update", mock.Anything, ada.ID, mock.Anything).Return(nil, realServiceUser.ErrInvalidMetadataKey).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"code":400,"message":"metadata keys must be at most 64 letters, digits, '_', '-' or '.'","errorCode":"INVALID_ARGUMENT"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tc.setupMock(mockService)
+
+			rr := httptest.NewRecorder()
+			_, router := gin.CreateTestContext(rr)
+			router.PATCH("/users/:id/metadata", NewHandler(mockService, idgen.StrategyUUIDv4, zaptest.NewLogger(t)).UpdateMetadata)
+
+			req, err := http.NewRequest(http.MethodPatch, "/users/"+ada.ID.String()+"/metadata", strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+			assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,47 +3,77 @@ package user
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/yi-tech/go-user-service/internal/transport/http/links"
+	"github.com/yi-tech/go-user-service/internal/transport/mapper"
 )
 
-// UserRegisterRequest defines the request body for user registration.
-type UserRegisterRequest struct {
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=8"`
-	FirstName string `json:"firstName" binding:"required"`
-	LastName  string `json:"lastName" binding:"required"`
+// UserRegisterRequest defines the request body for user registration. It is
+// generated from api/schema/user.yaml with the other user DTOs.
+type UserRegisterRequest = mapper.UserRegisterRequest
+
+// UserResponse defines the common response structure for a user. The fields
+// are those of mapper.UserResponse, so they match the gRPC User message; the
+// methods below add what only the HTTP representation has.
+type UserResponse mapper.UserResponse
+
+// ResourceType implements response.Resource
+func (u UserResponse) ResourceType() string {
+	return "users"
+}
+
+// ResourceID implements response.Resource
+func (u UserResponse) ResourceID() string {
+	return u.ID
+}
+
+// UserLinks are the resources related to a user. Avatars are not linked
+// while the API has no avatar resource, and admin API resources are only
+// linked from admin API responses.
+type UserLinks struct {
+	Self     links.Link `json:"self"`
+	Password links.Link `json:"password"`
 }
 
-// UserResponse defines the common response structure for a user.
-type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName,omitempty"`
-	LastName  string    `json:"lastName,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+// Links returns the links rendered under _links
+func (u UserResponse) Links() UserLinks {
+	return UserLinks{
+		Self:     links.API.Link("users", u.ID),
+		Password: links.API.Link("users", u.ID, "password"),
+	}
 }
 
-// MarshalJSON implements custom JSON marshaling for UserResponse to ensure consistent timestamp format
+// MarshalJSON implements custom JSON marshaling for UserResponse to ensure
+// consistent timestamp format and to add the resource links
 func (u UserResponse) MarshalJSON() ([]byte, error) {
 	type Alias UserResponse
 	return json.Marshal(&struct {
-		CreatedAt string `json:"createdAt"`
-		UpdatedAt string `json:"updatedAt"`
+		CreatedAt string    `json:"createdAt"`
+		UpdatedAt string    `json:"updatedAt"`
+		Links     UserLinks `json:"_links"`
 		*Alias
 	}{
 		CreatedAt: u.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
+		Links:     u.Links(),
 		Alias:     (*Alias)(&u),
 	})
 }
 
 // UserUpdateRequest defines the request body for updating user profile information.
+// Omitted or null fields are left unchanged; an empty string clears the field.
 type UserUpdateRequest struct {
-	FirstName *string `json:"firstName"`
-	LastName  *string `json:"lastName"`
-	Email     *string `json:"email" binding:"omitempty,email"`
+	FirstName   *string `json:"firstName"`
+	LastName    *string `json:"lastName"`
+	DisplayName *string `json:"displayName"` // At most 64 characters; surrounding spaces are trimmed
+	Email       *string `json:"email" binding:"omitempty,email"`
+	Locale      *string `json:"locale"` // Preferred language of API messages, e.g. "zh"
 }
 
+// MetadataPatchRequest defines the request body for updating user metadata,
+// a JSON merge patch: string values set an attribute and null values remove it.
+type MetadataPatchRequest map[string]*string
+
 // UpdatePasswordRequest defines the request body for updating a user's password.
 type UpdatePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword" binding:"required"`
@@ -51,8 +81,36 @@ type UpdatePasswordRequest struct {
 }
 
 // UpdateCurrentUserProfileRequest defines the request body for updating the current user's profile.
+// Omitted or null fields are left unchanged; an empty string clears the field.
 type UpdateCurrentUserProfileRequest struct {
-	FirstName *string `json:"firstName"`
-	LastName  *string `json:"lastName"`
-	Email     *string `json:"email" binding:"omitempty,email"`
+	FirstName   *string `json:"firstName"`
+	LastName    *string `json:"lastName"`
+	DisplayName *string `json:"displayName"` // At most 64 characters; surrounding spaces are trimmed
+	Email       *string `json:"email" binding:"omitempty,email"`
+	Locale      *string `json:"locale"` // Preferred language of API messages, e.g. "zh"
+}
+
+// BatchGetUsersRequest defines the request body for looking up several users by ID.
+type BatchGetUsersRequest struct {
+	IDs []string `json:"ids" binding:"required,max=100"`
+}
+
+// BatchGetUsersResponse lists the users found, in the order requested, and
+// the requested IDs no user has.
+type BatchGetUsersResponse struct {
+	Users      []UserResponse `json:"users"`
+	MissingIDs []string       `json:"missingIds"`
+}
+
+// AvailabilityRequest defines the query parameters for checking signup identifiers.
+type AvailabilityRequest struct {
+	Email    string `form:"email" binding:"omitempty,email"`
+	Username string `form:"username" binding:"omitempty,max=255"`
+}
+
+// AvailabilityResponse reports which identifiers can be used to sign up.
+// Identifiers that were not requested are omitted.
+type AvailabilityResponse struct {
+	EmailAvailable    *bool `json:"emailAvailable,omitempty"`
+	UsernameAvailable *bool `json:"usernameAvailable,omitempty"`
 }
@@ -0,0 +1,36 @@
+// Package mapper converts domain users to the DTOs and protobuf messages of
+// the HTTP and gRPC transports, so a field added to the domain shows up on
+// every surface. The user conversions are generated from api/schema/user.yaml
+// by dtogen; the admin API's richer user message is mapped here.
+package mapper
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	adminpb "github.com/yi-tech/go-user-service/api/proto/admin/v1"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// AdminUserToPb converts a domain user to the protobuf User of the admin API
+func AdminUserToPb(user *domainUser.User, ids idgen.Strategy) *adminpb.User {
+	msg := &adminpb.User{
+		Id:                    ids.Format(user.ID),
+		Email:                 user.Email,
+		Username:              user.Username,
+		FirstName:             user.FirstName,
+		LastName:              user.LastName,
+		DisplayName:           user.DisplayName,
+		Role:                  string(user.Role),
+		IsActive:              user.IsActive,
+		PasswordResetRequired: user.PasswordResetRequired,
+		Metadata:              user.Metadata,
+	}
+	if !user.CreatedAt.IsZero() {
+		msg.CreatedAt = timestamppb.New(user.CreatedAt)
+	}
+	if !user.UpdatedAt.IsZero() {
+		msg.UpdatedAt = timestamppb.New(user.UpdatedAt)
+	}
+	return msg
+}
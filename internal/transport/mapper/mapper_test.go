@@ -0,0 +1,143 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	userpb "github.com/yi-tech/go-user-service/api/proto/user/v1"
+	"github.com/yi-tech/go-user-service/internal/domain/rbac"
+	domainUser "github.com/yi-tech/go-user-service/internal/domain/user"
+	"github.com/yi-tech/go-user-service/internal/idgen"
+)
+
+// fullUser returns a user with every field the transports render set
+func fullUser() *domainUser.User {
+	createdAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	return &domainUser.User{
+		ID:                    uuid.MustParse("123e4567-e89b-12d3-a456-426614174000"),
+		Email:                 "test@example.com",
+		Username:              "test",
+		FirstName:             "Test",
+		LastName:              "User",
+		DisplayName:           "Tess",
+		Residency:             "EU",
+		Locale:                "zh",
+		Role:                  rbac.RoleAdmin,
+		IsActive:              true,
+		PasswordResetRequired: true,
+		Metadata:              map[string]string{"plan": "pro"},
+		CreatedAt:             createdAt,
+		UpdatedAt:             createdAt.Add(time.Hour),
+	}
+}
+
+func TestUserToPb(t *testing.T) {
+	user := fullUser()
+
+	msg := UserToPb(user, idgen.StrategyUUIDv4)
+
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", msg.Id)
+	assert.Equal(t, "test@example.com", msg.Email)
+	assert.Equal(t, "Test", msg.FirstName)
+	assert.Equal(t, "User", msg.LastName)
+	assert.Equal(t, "test", msg.Username)
+	assert.Equal(t, "Tess", msg.DisplayName)
+	assert.Equal(t, "EU", msg.Residency)
+	assert.True(t, msg.IsActive)
+	assert.Equal(t, map[string]string{"plan": "pro"}, msg.Metadata)
+	assert.Equal(t, user.CreatedAt, msg.CreatedAt.AsTime())
+	assert.Equal(t, user.UpdatedAt, msg.UpdatedAt.AsTime())
+
+	// Unknown timestamps are left unset rather than rendered as year 1
+	msg = UserToPb(&domainUser.User{ID: user.ID}, idgen.StrategyUUIDv4)
+	assert.Nil(t, msg.CreatedAt)
+	assert.Nil(t, msg.UpdatedAt)
+}
+
+func TestToUserResponse(t *testing.T) {
+	user := fullUser()
+
+	resp := ToUserResponse(user, idgen.StrategyULID)
+
+	assert.Equal(t, UserResponse{
+		ID:          idgen.StrategyULID.Format(user.ID),
+		Email:       "test@example.com",
+		Username:    "test",
+		FirstName:   "Test",
+		LastName:    "User",
+		DisplayName: "Tess",
+		Residency:   "EU",
+		Locale:      "zh",
+		Metadata:    map[string]string{"plan": "pro"},
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}, resp)
+}
+
+func TestRegisterRequestToDomain(t *testing.T) {
+	expected := domainUser.RegisterUserInput{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}
+
+	assert.Equal(t, expected, RegisterRequestToDomain(&userpb.RegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}))
+
+	expected.Residency = "EU"
+	assert.Equal(t, expected, UserRegisterRequest{
+		Email:     "test@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+		Residency: "EU",
+	}.ToDomain())
+}
+
+func TestAdminUserToPb(t *testing.T) {
+	user := fullUser()
+
+	msg := AdminUserToPb(user, idgen.StrategyUUIDv4)
+
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", msg.Id)
+	assert.Equal(t, "test", msg.Username)
+	assert.Equal(t, "Tess", msg.DisplayName)
+	assert.Equal(t, "admin", msg.Role)
+	assert.True(t, msg.PasswordResetRequired)
+	assert.Equal(t, map[string]string{"plan": "pro"}, msg.Metadata)
+	assert.Equal(t, user.CreatedAt, msg.CreatedAt.AsTime())
+}
+
+// A field added to a response or message must be filled in by its converter,
+// otherwise one surface silently renders it empty
+func TestEveryFieldIsMapped(t *testing.T) {
+	user := fullUser()
+
+	for _, msg := range []proto.Message{
+		UserToPb(user, idgen.StrategyUUIDv4),
+		AdminUserToPb(user, idgen.StrategyUUIDv4),
+	} {
+		m := msg.ProtoReflect()
+		fields := m.Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			// proto3 scalars only count as set when they are not zero
+			assert.True(t, m.Has(field), "%s.%s is not mapped", m.Descriptor().FullName(), field.Name())
+		}
+	}
+
+	resp := reflect.ValueOf(ToUserResponse(user, idgen.StrategyUUIDv4))
+	for i := 0; i < resp.NumField(); i++ {
+		assert.False(t, resp.Field(i).IsZero(), "UserResponse.%s is not mapped", resp.Type().Field(i).Name)
+	}
+}
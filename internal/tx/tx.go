@@ -0,0 +1,13 @@
+// Package tx declares the unit of work services run their writes in, so
+// that they do not depend on how the repository layer implements it.
+package tx
+
+import "context"
+
+// Manager runs a unit of work spanning several repositories: every
+// repository call made with the context passed to fn takes part in one
+// transaction, committed when fn returns nil and rolled back otherwise.
+// Services depend on it rather than on GORM.
+type Manager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,101 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mssola/useragent"
+)
+
+// Agent is the parsed form of a User-Agent header
+type Agent struct {
+	Browser string
+	OS      string
+	Mobile  bool
+	Bot     bool
+}
+
+// UnknownDevice is the label used when nothing useful can be parsed
+const UnknownDevice = "Unknown device"
+
+// browserNames maps product names reported by the parser to friendly names.
+// Names that are already friendly (Chrome, Firefox, curl, ...) pass through.
+var browserNames = map[string]string{
+	"PostmanRuntime": "Postman",
+	"okhttp":         "OkHttp",
+}
+
+// osNames maps operating system names reported by the parser to friendly
+// names. Linux distributions are folded into "Linux".
+var osNames = map[string]string{
+	"Mac OS X":  "macOS",
+	"iPhone OS": "iOS",
+	"Android":   "Android",
+	"Windows":   "Windows",
+	"Linux":     "Linux",
+	"Ubuntu":    "Linux",
+	"Debian":    "Linux",
+	"Fedora":    "Linux",
+}
+
+// botPattern matches crawler markers as whole words ("bot", "Yahoo! Slurp")
+// or as the suffix of a product token ("YandexBot/3.0"). A bare substring
+// check would flag device names such as "CUBOT" or "Abbott".
+var botPattern = regexp.MustCompile(`(?i)\b(bot|spider|crawler|slurp)\b|[a-z](bot|spider|crawler)/`)
+
+// Parse extracts the browser and operating system from a User-Agent header.
+// Unrecognised parts are left empty.
+func Parse(ua string) Agent {
+	parsed := useragent.New(ua)
+
+	var agent Agent
+	agent.Browser = browserName(parsed)
+	agent.OS = osName(parsed)
+	agent.Mobile = parsed.Mobile() || agent.OS == "iOS" || agent.OS == "iPadOS" || agent.OS == "Android"
+	agent.Bot = parsed.Bot() || botPattern.MatchString(ua)
+	return agent
+}
+
+func browserName(ua *useragent.UserAgent) string {
+	name, _ := ua.Browser()
+	if friendly, ok := browserNames[name]; ok {
+		return friendly
+	}
+	if strings.HasPrefix(name, "grpc-") {
+		return "gRPC client"
+	}
+	return name
+}
+
+func osName(ua *useragent.UserAgent) string {
+	// iPads report a generic "OS" name; only the platform identifies them
+	if ua.Platform() == "iPad" {
+		return "iPadOS"
+	}
+	name := ua.OSInfo().Name
+	if strings.HasPrefix(name, "CrOS") {
+		return "ChromeOS"
+	}
+	return osNames[name]
+}
+
+// Label returns a short human-readable description such as "Chrome on macOS"
+func (a Agent) Label() string {
+	switch {
+	case a.Bot:
+		return "Automated client"
+	case a.Browser != "" && a.OS != "":
+		return a.Browser + " on " + a.OS
+	case a.Browser != "":
+		return a.Browser
+	case a.OS != "":
+		return a.OS + " device"
+	default:
+		return UnknownDevice
+	}
+}
+
+// Label parses a User-Agent header and returns its device label
+func Label(ua string) string {
+	return Parse(ua).Label()
+}
@@ -0,0 +1,102 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want string
+	}{
+		{
+			name: "chrome on macOS",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+			want: "Chrome on macOS",
+		},
+		{
+			name: "safari on iOS",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want: "Safari on iOS",
+		},
+		{
+			name: "edge on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36 Edg/125.0.0.0",
+			want: "Edge on Windows",
+		},
+		{
+			name: "firefox on linux",
+			ua:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:126.0) Gecko/20100101 Firefox/126.0",
+			want: "Firefox on Linux",
+		},
+		{
+			name: "chrome on android",
+			ua:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Mobile Safari/537.36",
+			want: "Chrome on Android",
+		},
+		{
+			name: "cli client",
+			ua:   "curl/8.6.0",
+			want: "curl",
+		},
+		{
+			name: "crawler",
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: "Automated client",
+		},
+		{
+			name: "safari on iPadOS",
+			ua:   "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want: "Safari on iPadOS",
+		},
+		{
+			name: "chrome on ChromeOS",
+			ua:   "Mozilla/5.0 (X11; CrOS x86_64 14541.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+			want: "Chrome on ChromeOS",
+		},
+		{
+			name: "device name containing bot",
+			ua:   "Mozilla/5.0 (Linux; Android 9; CUBOT X19) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Mobile Safari/537.36",
+			want: "Chrome on Android",
+		},
+		{
+			name: "api client",
+			ua:   "PostmanRuntime/7.39.0",
+			want: "Postman",
+		},
+		{
+			name: "grpc client",
+			ua:   "grpc-go/1.64.0",
+			want: "gRPC client",
+		},
+		{
+			name: "crawler product token",
+			ua:   "Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)",
+			want: "Automated client",
+		},
+		{
+			name: "crawler word",
+			ua:   "Mozilla/5.0 (compatible; Yahoo! Slurp; http://help.yahoo.com/help/us/ysearch/slurp)",
+			want: "Automated client",
+		},
+		{
+			name: "empty",
+			ua:   "",
+			want: UnknownDevice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Label(tt.ua))
+		})
+	}
+}
+
+func TestParse_Mobile(t *testing.T) {
+	assert.True(t, Parse("Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Mobile/15E148").Mobile)
+	assert.False(t, Parse("Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/125.0.0.0").Mobile)
+}
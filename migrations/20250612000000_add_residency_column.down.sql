@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_residency;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS residency;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN residency VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_residency ON users (residency);
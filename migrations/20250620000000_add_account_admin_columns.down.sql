@@ -0,0 +1,4 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS password_reset_required,
+DROP COLUMN IF EXISTS is_active,
+DROP COLUMN IF EXISTS role;
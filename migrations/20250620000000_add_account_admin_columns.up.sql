@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user',
+ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE,
+ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
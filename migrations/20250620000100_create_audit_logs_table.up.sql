@@ -0,0 +1,12 @@
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_id UUID,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_id ON audit_logs (target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
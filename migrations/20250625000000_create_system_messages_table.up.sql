@@ -0,0 +1,15 @@
+CREATE TABLE system_messages (
+    id UUID PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    roles TEXT NOT NULL DEFAULT '',
+    tenants TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_system_messages_window ON system_messages (starts_at, ends_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_tenant;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS tenant;
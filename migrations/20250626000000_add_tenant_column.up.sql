@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN tenant VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant ON users (tenant);
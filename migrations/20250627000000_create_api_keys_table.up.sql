@@ -0,0 +1,17 @@
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    tenant VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    rotated_to UUID,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_secret_hash ON api_keys (secret_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant ON api_keys (tenant);
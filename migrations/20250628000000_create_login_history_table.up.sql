@@ -0,0 +1,11 @@
+CREATE TABLE login_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    result VARCHAR(32) NOT NULL,
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_history_user_id ON login_history (user_id);
+CREATE INDEX IF NOT EXISTS idx_login_history_created_at ON login_history (created_at);
@@ -0,0 +1,16 @@
+CREATE TABLE notification_dead_letters (
+    id UUID PRIMARY KEY,
+    notification_id UUID NOT NULL,
+    kind VARCHAR(64) NOT NULL,
+    channel VARCHAR(32) NOT NULL,
+    user_id UUID NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_user_id ON notification_dead_letters (user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_created_at ON notification_dead_letters (created_at);
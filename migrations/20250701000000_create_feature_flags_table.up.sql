@@ -0,0 +1,8 @@
+CREATE TABLE feature_flags (
+    name VARCHAR(64) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    tenants TEXT NOT NULL DEFAULT '',
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    updated_by UUID NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
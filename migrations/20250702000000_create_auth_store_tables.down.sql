@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS auth_sessions;
+DROP TABLE IF EXISTS auth_refresh_token_owners;
+DROP TABLE IF EXISTS auth_refresh_tokens;
@@ -0,0 +1,27 @@
+CREATE TABLE auth_refresh_tokens (
+    user_id UUID PRIMARY KEY,
+    token VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE auth_refresh_token_owners (
+    token VARCHAR(255) PRIMARY KEY,
+    user_id UUID NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_token_owners_user_id ON auth_refresh_token_owners (user_id);
+
+CREATE TABLE auth_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL,
+    type VARCHAR(32) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    device_label VARCHAR(255) NOT NULL DEFAULT '',
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id ON auth_sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_expires_at ON auth_sessions (expires_at);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_users_metadata ON users USING GIN (metadata jsonb_path_ops);
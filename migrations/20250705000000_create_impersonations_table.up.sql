@@ -0,0 +1,12 @@
+CREATE TABLE impersonations (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    subject_id UUID NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonations_actor_id ON impersonations (actor_id);
+CREATE INDEX IF NOT EXISTS idx_impersonations_subject_id ON impersonations (subject_id);
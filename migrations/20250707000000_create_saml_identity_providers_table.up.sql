@@ -0,0 +1,13 @@
+CREATE TABLE saml_identity_providers (
+    tenant VARCHAR(255) PRIMARY KEY,
+    entity_id VARCHAR(1024) NOT NULL,
+    sso_url VARCHAR(2048) NOT NULL DEFAULT '',
+    certificate TEXT NOT NULL,
+    email_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    first_name_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    last_name_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Existing passwords count as changed when the migration runs, so enabling
+-- password.max_age_days does not expire them all at once
+ALTER TABLE users
+ADD COLUMN password_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ADD COLUMN password_expires_at TIMESTAMP WITH TIME ZONE;
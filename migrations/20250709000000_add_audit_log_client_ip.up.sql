@@ -0,0 +1,4 @@
+-- Where the audited request came from; entries recorded before the column
+-- existed, and those written by background jobs, have none
+ALTER TABLE audit_logs
+ADD COLUMN client_ip VARCHAR(64) NOT NULL DEFAULT '';
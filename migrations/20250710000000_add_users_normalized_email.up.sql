@@ -0,0 +1,15 @@
+-- Accounts are unique by the normalized form of their email address.
+-- Existing addresses are normalized by case only; accounts whose addresses
+-- differ only in case must be merged before this migration can run. Run
+-- `make emails-normalize` afterwards to apply the rest of the normalization
+-- (punycode domains, trailing dots, Gmail aliases); until then such accounts
+-- are only found by the exact spelling they were stored with.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS normalized_email VARCHAR(255);
+
+UPDATE users SET normalized_email = LOWER(TRIM(email));
+
+ALTER TABLE users
+ALTER COLUMN normalized_email SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_normalized_email ON users (normalized_email);
@@ -0,0 +1,2 @@
+ALTER TABLE auth_sessions
+DROP COLUMN IF EXISTS refresh_token;
@@ -0,0 +1,2 @@
+ALTER TABLE auth_sessions
+ADD COLUMN refresh_token VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- `make emails-normalize` records each normalization it has rewritten every
+-- stored address with; the service refuses to start collapsing Gmail
+-- aliases before that normalization is recorded.
+CREATE TABLE email_normalizations (
+    name VARCHAR(32) PRIMARY KEY,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -46,4 +46,10 @@ protoc -I. -I$TMP_DIR \
   --openapiv2_out=./api/swagger --openapiv2_opt=logtostderr=true \
   api/proto/auth/v1/auth.proto
 
+# Generate organization proto v1 (gRPC only; REST is served by Gin under /api/v1/orgs)
+protoc -I. -I$TMP_DIR \
+  --go_out=. --go_opt=paths=source_relative \
+  --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+  api/proto/organization/v1/organization.proto
+
 echo "Proto generation completed successfully"